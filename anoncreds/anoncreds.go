@@ -0,0 +1,176 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package anoncreds exports emmy's CL keys and credentials in the formats of
+// Hyperledger AnonCreds (as used by Indy and Aries): schemas, credential
+// definitions, and credentials with raw and encoded values of attributes.
+//
+// In AnonCreds, every credential includes the holder's master secret as a hidden
+// attribute. Thus only keys of organizations generated with exactly one hidden
+// attribute, which is reserved for the master secret, can be exported as
+// credential definitions.
+//
+// This is an export format, and exported credentials are not accepted by AnonCreds
+// stacks: emmy signs internal values of attributes (see cl.CredAttr.InternalValue)
+// and commitments of committed attributes instead of values encoded by AnonCreds.
+// Encoded values of exported credentials hold the values that were signed, so that
+// the credentials verify under the exported credential definitions (see
+// Credential.Verify). Likewise, proofs of possession and non-revocation are exported
+// in the layout of AnonCreds presentations (see Presentation), which AnonCreds
+// verifiers do not accept.
+package anoncreds
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+// MasterSecretName is the name under which AnonCreds lists the master secret
+// amongst the attributes of a credential definition.
+const MasterSecretName = "master_secret"
+
+// Schema describes the attributes of credentials, as defined by AnonCreds.
+type Schema struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	AttrNames []string `json:"attrNames"`
+	Ver       string   `json:"ver"`
+}
+
+// NewSchema returns a schema with the given name and version, issued by
+// issuer (identified by DID).
+func NewSchema(issuer, name, version string, attrNames []string) *Schema {
+	return &Schema{
+		ID:        fmt.Sprintf("%s:2:%s:%s", unqualified(issuer), name, version),
+		Name:      name,
+		Version:   version,
+		AttrNames: attrNames,
+		Ver:       "1.0",
+	}
+}
+
+// CredDef is an AnonCreds credential definition, which holds the issuer's
+// CL public key.
+type CredDef struct {
+	ID       string       `json:"id"`
+	SchemaID string       `json:"schemaId"`
+	Type     string       `json:"type"`
+	Tag      string       `json:"tag"`
+	Value    CredDefValue `json:"value"`
+	Ver      string       `json:"ver"`
+}
+
+// CredDefValue holds the primary (CL) public key of a credential definition.
+type CredDefValue struct {
+	Primary PrimaryPubKey `json:"primary"`
+}
+
+// PrimaryPubKey is a CL public key in AnonCreds format. R maps attribute names
+// (and MasterSecretName) to their bases. Integers are encoded as decimal strings.
+// Emmy's keys have no base for the credential context (rctxt), so it is left out.
+type PrimaryPubKey struct {
+	N     string            `json:"n"`
+	S     string            `json:"s"`
+	R     map[string]string `json:"r"`
+	Rctxt string            `json:"rctxt,omitempty"`
+	Z     string            `json:"z"`
+}
+
+// NewCredDef returns a credential definition for schema, holding the CL public key
+// pubKey of issuer (identified by DID). Known and committed attributes of
+// the key are mapped to attribute names of the schema in order, while its
+// only hidden attribute is mapped to the master secret.
+func NewCredDef(issuer, tag string, schema *Schema, pubKey *cl.PubKey) (*CredDef, error) {
+	if len(pubKey.RsHidden) != 1 {
+		return nil, fmt.Errorf("key must have exactly one hidden attribute for the master secret")
+	}
+	rs := append(append([]*big.Int{}, pubKey.RsKnown...), pubKey.RsCommitted...)
+	if len(rs) != len(schema.AttrNames) {
+		return nil, fmt.Errorf("key has %d attributes, schema %d", len(rs), len(schema.AttrNames))
+	}
+
+	r := map[string]string{
+		MasterSecretName: pubKey.RsHidden[0].String(),
+	}
+	for i, name := range schema.AttrNames {
+		r[name] = rs[i].String()
+	}
+
+	return &CredDef{
+		ID:       fmt.Sprintf("%s:3:CL:%s:%s", unqualified(issuer), schema.ID, tag),
+		SchemaID: schema.ID,
+		Type:     "CL",
+		Tag:      tag,
+		Value: CredDefValue{
+			Primary: PrimaryPubKey{
+				N: pubKey.N.String(),
+				S: pubKey.S.String(),
+				R: r,
+				Z: pubKey.Z.String(),
+			},
+		},
+		Ver: "1.0",
+	}, nil
+}
+
+// Bases returns the bases of the credential definition's public key for the given
+// known and committed attribute names, and the base of the master secret.
+// It is meant to reconstruct cl.PubKey of a credential definition imported from
+// an AnonCreds stack.
+func (d *CredDef) Bases(known, committed []string) ([]*big.Int, []*big.Int, *big.Int, error) {
+	lookup := func(names []string) ([]*big.Int, error) {
+		bases := make([]*big.Int, len(names))
+		for i, name := range names {
+			r, ok := new(big.Int).SetString(d.Value.Primary.R[name], 10)
+			if !ok {
+				return nil, fmt.Errorf("credential definition has no valid base for %s", name)
+			}
+			bases[i] = r
+		}
+		return bases, nil
+	}
+
+	rsKnown, err := lookup(known)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rsCommitted, err := lookup(committed)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rMs, err := lookup([]string{MasterSecretName})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return rsKnown, rsCommitted, rMs[0], nil
+}
+
+// unqualified strips the did:<method>: prefix, as AnonCreds identifiers
+// use unqualified DIDs.
+func unqualified(did string) string {
+	if strings.HasPrefix(did, "did:") {
+		if parts := strings.SplitN(did, ":", 3); len(parts) == 3 {
+			return parts[2]
+		}
+	}
+	return did
+}
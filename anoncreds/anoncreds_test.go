@@ -0,0 +1,247 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package anoncreds
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+func TestCredDef(t *testing.T) {
+	params := cl.GetDefaultParamSizes()
+	keys, err := cl.GenerateKeyPair(params, cl.NewAttrCount(2, 1, 1))
+	if err != nil {
+		t.Fatalf("error when generating CL keys: %v", err)
+	}
+
+	schema := NewSchema("did:sov:NcYxiDXkpYi6ov5FcYDi1e", "degree", "1.0",
+		[]string{"name", "degree", "age"})
+	assert.Equal(t, "NcYxiDXkpYi6ov5FcYDi1e:2:degree:1.0", schema.ID)
+
+	credDef, err := NewCredDef("did:sov:NcYxiDXkpYi6ov5FcYDi1e", "default", schema, keys.Pub)
+	if err != nil {
+		t.Fatalf("error when creating credential definition: %v", err)
+	}
+	assert.Equal(t, "NcYxiDXkpYi6ov5FcYDi1e:3:CL:NcYxiDXkpYi6ov5FcYDi1e:2:degree:1.0:default",
+		credDef.ID)
+
+	data, _ := json.Marshal(credDef)
+	parsed := new(CredDef)
+	if err := json.Unmarshal(data, parsed); err != nil {
+		t.Fatalf("error when parsing credential definition: %v", err)
+	}
+	rsKnown, rsCommitted, rMs, err := parsed.Bases([]string{"name", "degree"}, []string{"age"})
+	if err != nil {
+		t.Fatalf("error when obtaining bases: %v", err)
+	}
+	assert.Equal(t, keys.Pub.RsKnown, rsKnown)
+	assert.Equal(t, keys.Pub.RsCommitted, rsCommitted)
+	assert.Equal(t, keys.Pub.RsHidden[0], rMs)
+
+	keys, _ = cl.GenerateKeyPair(params, cl.NewAttrCount(3, 0, 0))
+	_, err = NewCredDef("did:sov:NcYxiDXkpYi6ov5FcYDi1e", "default", schema, keys.Pub)
+	assert.Error(t, err, "key without a base for the master secret should not be accepted")
+}
+
+func TestCredential(t *testing.T) {
+	params := cl.GetDefaultParamSizes()
+	org, err := cl.NewOrg(params, cl.NewAttrCount(2, 1, 1))
+	require.NoError(t, err)
+	rawCred := cl.NewRawCred(cl.NewAttrCount(2, 1, 0))
+	require.NoError(t, rawCred.AddStrAttr("name", "Alice", true))
+	require.NoError(t, rawCred.AddStrAttr("degree", "MSc", true))
+	require.NoError(t, rawCred.AddInt64Attr("age", 25, false))
+	masterSecret := org.Keys.Pub.GenerateUserMasterSecret()
+	credMgr, err := cl.NewCredManager(params, org.Keys.Pub, masterSecret, rawCred)
+	require.NoError(t, err)
+	credReq, err := credMgr.GetCredRequest(org.GetCredIssueNonce())
+	require.NoError(t, err)
+	res, err := org.IssueCred(credReq)
+	require.NoError(t, err)
+	ok, err := credMgr.Verify(res.Cred, res.AProof)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	issuer := "did:sov:NcYxiDXkpYi6ov5FcYDi1e"
+	schema := NewSchema(issuer, "degree", "1.0", []string{"name", "degree", "age"})
+	credDef, err := NewCredDef(issuer, "default", schema, org.Keys.Pub)
+	require.NoError(t, err)
+	c, err := NewCredential(credDef, res.Cred, credMgr)
+	require.NoError(t, err)
+	assert.Equal(t, credDef.ID, c.CredDefID)
+	name, _ := rawCred.GetAttr("name")
+	assert.Equal(t, AttributeValue{Raw: "Alice", Encoded: name.InternalValue().String()},
+		c.Values["name"])
+	assert.Equal(t, AttributeValue{Raw: "25", Encoded: credMgr.CommitmentsOfAttrs[0].String()},
+		c.Values["age"], "committed attributes are signed as commitments")
+
+	// exported credentials verify under exported credential definitions
+	data, _ := json.Marshal(credDef)
+	parsedCredDef := new(CredDef)
+	require.NoError(t, json.Unmarshal(data, parsedCredDef))
+	data, _ = json.Marshal(c)
+	parsed := new(Credential)
+	require.NoError(t, json.Unmarshal(data, parsed))
+	assert.NoError(t, parsed.Verify(parsedCredDef, masterSecret))
+	assert.Error(t, parsed.Verify(parsedCredDef, org.Keys.Pub.GenerateUserMasterSecret()),
+		"the master secret of the holder is signed")
+
+	imported, err := parsed.Cred()
+	require.NoError(t, err)
+	assert.Equal(t, new(big.Int).Add(res.Cred.V11, credMgr.V1), imported.V11,
+		"v should be the sum of v11 and v1")
+
+	parsed.Values["degree"] = AttributeValue{Raw: "PhD",
+		Encoded: cl.DefaultAttrEncoder.EncodeString("PhD").String()}
+	assert.Error(t, parsed.Verify(parsedCredDef, masterSecret), "altered value should not be verified")
+	delete(parsed.Values, "degree")
+	assert.Error(t, parsed.Verify(parsedCredDef, masterSecret), "missing value should not be verified")
+}
+
+func TestPresentation(t *testing.T) {
+	params := cl.GetDefaultParamSizes()
+	attrCount := cl.NewAttrCount(2, 1, 0)
+	org, err := cl.NewOrg(params, attrCount)
+	if err != nil {
+		t.Fatalf("error when generating CL org: %v", err)
+	}
+	rawCred := cl.NewRawCred(attrCount)
+	_ = rawCred.AddStrAttr("name", "Alice", true)
+	_ = rawCred.AddStrAttr("degree", "MSc", true)
+	_ = rawCred.AddInt64Attr("age", 25, false)
+	credMgr, err := cl.NewCredManager(params, org.Keys.Pub,
+		org.Keys.Pub.GenerateUserMasterSecret(), rawCred)
+	if err != nil {
+		t.Fatalf("error when creating credential manager: %v", err)
+	}
+	credReq, err := credMgr.GetCredRequest(org.GetCredIssueNonce())
+	if err != nil {
+		t.Fatalf("error when generating credential request: %v", err)
+	}
+	res, err := org.IssueCred(credReq)
+	if err != nil {
+		t.Fatalf("error when issuing credential: %v", err)
+	}
+
+	revealedKnownAttrsIndices := []int{1}
+	revealedCommitmentsOfAttrsIndices := []int{0}
+	nonce := org.GetProveCredNonce()
+	randCred, proof, err := credMgr.BuildProof(res.Cred, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, nonce)
+	if err != nil {
+		t.Fatalf("error when building credential proof: %v", err)
+	}
+	knownAttrs, commitmentsOfAttrs := credMgr.FilterAttributes(revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices)
+	cp := &cl.CredProof{
+		A:                                 randCred.A,
+		Proof:                             proof,
+		RevealedKnownAttrsIndices:         revealedKnownAttrsIndices,
+		RevealedCommitmentsOfAttrsIndices: revealedCommitmentsOfAttrsIndices,
+		RevealedKnownAttrs:                knownAttrs,
+		RevealedCommitmentsOfAttrs:        commitmentsOfAttrs,
+	}
+
+	known, committed := []string{"name", "degree"}, []string{"age"}
+	credDef := &CredDef{ID: "credDef", SchemaID: "schema"}
+	p, err := NewPresentation(credDef, known, committed, cp, nil)
+	if err != nil {
+		t.Fatalf("error when exporting presentation: %v", err)
+	}
+	assert.Nil(t, p.Proof.Proofs[0].NonRevocProof)
+	eq := p.Proof.Proofs[0].PrimaryProof.EqProof
+	assert.Equal(t, knownAttrs[0].String(), eq.RevealedAttrs["degree"])
+	assert.Equal(t, commitmentsOfAttrs[0].String(), eq.RevealedAttrs["age"])
+	assert.Contains(t, eq.M, "name")
+	assert.Equal(t, "credDef", p.Identifiers[0].CredDefID)
+
+	data, _ := json.Marshal(p)
+	assert.Contains(t, string(data), `"c_list":[[`, "c_list should be an array of numbers")
+	parsed := new(Presentation)
+	if err := json.Unmarshal(data, parsed); err != nil {
+		t.Fatalf("error when parsing presentation: %v", err)
+	}
+	imported, err := parsed.CredProof(known, committed)
+	if err != nil {
+		t.Fatalf("error when importing presentation: %v", err)
+	}
+	verified, err := org.ProveCred(imported.A, imported.Proof,
+		imported.RevealedKnownAttrsIndices, imported.RevealedCommitmentsOfAttrsIndices,
+		imported.RevealedKnownAttrs, imported.RevealedCommitmentsOfAttrs)
+	if err != nil {
+		t.Errorf("error when verifying presentation: %v", err)
+	}
+	assert.True(t, verified, "imported presentation should be verified")
+
+	parsed.Proof.Proofs[0].PrimaryProof.EqProof.RevealedAttrs["degree"] =
+		cl.DefaultAttrEncoder.EncodeString("PhD").String()
+	imported, err = parsed.CredProof(known, committed)
+	if err != nil {
+		t.Fatalf("error when importing presentation: %v", err)
+	}
+	verified, _ = org.ProveCred(imported.A, imported.Proof,
+		imported.RevealedKnownAttrsIndices, imported.RevealedCommitmentsOfAttrsIndices,
+		imported.RevealedKnownAttrs, imported.RevealedCommitmentsOfAttrs)
+	assert.False(t, verified, "presentation with altered attribute should not be verified")
+
+	delete(parsed.Proof.Proofs[0].PrimaryProof.EqProof.M, "name")
+	_, err = parsed.CredProof(known, committed)
+	assert.Error(t, err, "presentation missing a response should not be accepted")
+
+	// revocable credentials are presented along with a non-revocation proof
+	ra, err := cl.NewRevocationAuthority(org.Keys)
+	require.NoError(t, err)
+	w, err := ra.Witness(res.Cred.E)
+	require.NoError(t, err)
+	nonRevoc, err := credMgr.BuildNonRevocationProof(randCred, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, w, ra.Acc, nonce)
+	require.NoError(t, err)
+	p, err = NewPresentation(credDef, known, committed, cp, nonRevoc)
+	require.NoError(t, err)
+	data, _ = json.Marshal(p)
+	parsed = new(Presentation)
+	require.NoError(t, json.Unmarshal(data, parsed))
+	importedNonRevoc, err := parsed.NonRevocationProof()
+	require.NoError(t, err)
+	imported, err = parsed.CredProof(known, committed)
+	require.NoError(t, err)
+	revealed := &cl.RevealedCred{
+		PubKey:                            org.Keys.Pub,
+		RevealedKnownAttrsIndices:         imported.RevealedKnownAttrsIndices,
+		RevealedCommitmentsOfAttrsIndices: imported.RevealedCommitmentsOfAttrsIndices,
+		RevealedKnownAttrs:                imported.RevealedKnownAttrs,
+		RevealedCommitmentsOfAttrs:        imported.RevealedCommitmentsOfAttrs,
+	}
+	verified, err = cl.VerifyNonRevocationProof(params, revealed, imported.A, ra.Acc,
+		importedNonRevoc, nonce)
+	require.NoError(t, err)
+	assert.True(t, verified, "imported non-revocation proof should be verified")
+	require.NoError(t, ra.Revoke(res.Cred.E))
+	verified, _ = cl.VerifyNonRevocationProof(params, revealed, imported.A, ra.Acc,
+		importedNonRevoc, nonce)
+	assert.False(t, verified, "proof should not be verified once the credential is revoked")
+
+	cp.RangeProofs = []*cl.AttrRangeProof{{}}
+	_, err = NewPresentation(credDef, known, committed, cp, nil)
+	assert.Error(t, err, "range proofs should not be exported")
+}
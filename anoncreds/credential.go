@@ -0,0 +1,144 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package anoncreds
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+// Credential is a credential in AnonCreds format.
+type Credential struct {
+	SchemaID  string                    `json:"schema_id"`
+	CredDefID string                    `json:"cred_def_id"`
+	Values    map[string]AttributeValue `json:"values"`
+	Signature Signature                 `json:"signature"`
+}
+
+// AttributeValue holds the raw value of an attribute and the integer that is signed
+// for it: the internal value of a known attribute (see cl.CredAttr.InternalValue) or
+// the commitment of a committed one.
+type AttributeValue struct {
+	Raw     string `json:"raw"`
+	Encoded string `json:"encoded"`
+}
+
+// Signature holds the primary (CL) signature of a credential.
+type Signature struct {
+	PCredential PrimarySignature `json:"p_credential"`
+}
+
+// PrimarySignature is a CL signature in AnonCreds format. Emmy's credentials
+// do not sign the credential context, so m_2 is left out.
+type PrimarySignature struct {
+	M2 string `json:"m_2,omitempty"`
+	A  string `json:"a"`
+	E  string `json:"e"`
+	V  string `json:"v"`
+}
+
+// NewCredential returns the CL credential cred, issued to the holder managing it with
+// credManager, in AnonCreds format. As emmy splits v between the issuer and the holder,
+// the full v is the sum of cred.V11 and credManager.V1.
+func NewCredential(credDef *CredDef, cred *cl.Cred,
+	credManager *cl.CredManager) (*Credential, error) {
+	values := make(map[string]AttributeValue)
+	attrs := credManager.RawCred.GetAttrs()
+	for i := 0; i < len(attrs); i++ {
+		a := attrs[i]
+		idx, err := credManager.RawCred.GetAttrInternalIndex(a.GetName())
+		if err != nil {
+			return nil, err
+		}
+		signed := credManager.CommitmentsOfAttrs
+		if a.IsKnown() {
+			signed = credManager.Attrs.Known
+		}
+		if idx >= len(signed) {
+			return nil, fmt.Errorf("no signed value of attribute %s", a.GetName())
+		}
+		values[a.GetName()] = AttributeValue{
+			Raw:     fmt.Sprint(a.GetValue()),
+			Encoded: signed[idx].String(),
+		}
+	}
+
+	return &Credential{
+		SchemaID:  credDef.SchemaID,
+		CredDefID: credDef.ID,
+		Values:    values,
+		Signature: Signature{
+			PCredential: PrimarySignature{
+				A: cred.A.String(),
+				E: cred.E.String(),
+				V: new(big.Int).Add(cred.V11, credManager.V1).String(),
+			},
+		},
+	}, nil
+}
+
+// Cred returns the CL signature of c. The returned credential's V11 holds
+// the full v, so the holder's v1 needs to be zero when using it.
+func (c *Credential) Cred() (*cl.Cred, error) {
+	p := c.Signature.PCredential
+	ints, err := parseInts(p.A, p.E, p.V)
+	if err != nil {
+		return nil, err
+	}
+
+	return cl.NewCred(ints[0], ints[1], ints[2]), nil
+}
+
+// Verify checks that the signature of c is valid under the public key of credDef for
+// the holder with the given master secret, that is Z = A^e * S^v * R_ms^ms *
+// R_1^m_1 * ... * R_l^m_l (mod n) for encoded values m_i of attributes.
+func (c *Credential) Verify(credDef *CredDef, masterSecret *big.Int) error {
+	pk := credDef.Value.Primary
+	sig := c.Signature.PCredential
+	ints, err := parseInts(pk.N, pk.S, pk.Z, pk.R[MasterSecretName], sig.A, sig.E, sig.V)
+	if err != nil {
+		return err
+	}
+	n, s, z, rMs, a, e, v := ints[0], ints[1], ints[2], ints[3], ints[4], ints[5], ints[6]
+	if n.Sign() <= 0 || len(c.Values) != len(pk.R)-1 {
+		return fmt.Errorf("credential does not match the credential definition")
+	}
+
+	q := new(big.Int).Exp(a, e, n)
+	q.Mul(q, new(big.Int).Exp(s, v, n))
+	q.Mul(q, new(big.Int).Exp(rMs, masterSecret, n))
+	for name, val := range c.Values {
+		r, ok := pk.R[name]
+		if !ok || name == MasterSecretName {
+			return fmt.Errorf("credential definition has no base for %s", name)
+		}
+		rm, err := parseInts(r, val.Encoded)
+		if err != nil {
+			return err
+		}
+		q.Mul(q, new(big.Int).Exp(rm[0], rm[1], n))
+		q.Mod(q, n)
+	}
+	if q.Mod(q, n).Cmp(z) != 0 {
+		return fmt.Errorf("invalid signature")
+	}
+
+	return nil
+}
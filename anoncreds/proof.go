@@ -0,0 +1,334 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package anoncreds
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/qr"
+)
+
+// Presentation is a proof of possession of a single credential, exported in the
+// layout of AnonCreds presentations. It is an export format only: challenges and
+// non-revocation proofs are computed by emmy's rules rather than those of AnonCreds,
+// so AnonCreds verifiers do not accept presentations. They are converted back with
+// CredProof and NonRevocationProof and verified by emmy.
+type Presentation struct {
+	Proof       Proof        `json:"proof"`
+	Identifiers []Identifier `json:"identifiers"`
+}
+
+// Proof holds the sub-proofs of a presentation and the challenge binding them.
+type Proof struct {
+	Proofs          []SubProof      `json:"proofs"`
+	AggregatedProof AggregatedProof `json:"aggregated_proof"`
+}
+
+// SubProof is the proof of possession of one credential. NonRevocProof is empty for
+// credentials that cannot be revoked.
+type SubProof struct {
+	PrimaryProof  PrimaryProof   `json:"primary_proof"`
+	NonRevocProof *NonRevocProof `json:"non_revoc_proof"`
+}
+
+// NonRevocProof is emmy's proof that a credential was not revoked from the
+// accumulator of its issuer (see cl.NonRevocationProof). CU and CR commit to the
+// witness of the credential, and Responses respond to challenge CHash. The proof
+// also proves possession of the credential, so it repeats the responses of EqProof.
+// Integers are encoded as decimal strings.
+type NonRevocProof struct {
+	CU        string   `json:"c_u"`
+	CR        string   `json:"c_r"`
+	CHash     string   `json:"c_hash"`
+	Responses []string `json:"responses"`
+}
+
+// PrimaryProof holds the equality proof of a credential. Emmy's range proofs
+// are constructed differently than AnonCreds predicates, so GeProofs is
+// always empty.
+type PrimaryProof struct {
+	EqProof  EqProof       `json:"eq_proof"`
+	GeProofs []interface{} `json:"ge_proofs"`
+}
+
+// EqProof is a CL proof of possession in AnonCreds format. RevealedAttrs maps
+// names of revealed attributes to their values, while M maps names of the
+// unrevealed ones (and MasterSecretName, if the key has a hidden attribute)
+// to their responses. E and V are the
+// responses for the randomized signature APrime. Integers are encoded as
+// decimal strings.
+type EqProof struct {
+	RevealedAttrs map[string]string `json:"revealed_attrs"`
+	APrime        string            `json:"a_prime"`
+	E             string            `json:"e"`
+	V             string            `json:"v"`
+	M             map[string]string `json:"m"`
+	M2            string            `json:"m2,omitempty"`
+}
+
+// AggregatedProof holds the challenge (CHash) and the commitments it was computed
+// from (CList), as big-endian bytes.
+type AggregatedProof struct {
+	CHash string  `json:"c_hash"`
+	CList [][]int `json:"c_list"`
+}
+
+// Identifier references the schema and the credential definition of a sub-proof.
+type Identifier struct {
+	SchemaID  string      `json:"schema_id"`
+	CredDefID string      `json:"cred_def_id"`
+	RevRegID  interface{} `json:"rev_reg_id"`
+	Timestamp interface{} `json:"timestamp"`
+}
+
+// NewPresentation returns the proof of possession p of a credential issued under
+// credDef in AnonCreds format, along with the proof nonRevoc that the credential was
+// not revoked, which is nil for credentials that cannot be revoked. Known and
+// committed are the names of the known and committed attributes of the credential,
+// as passed to CredDef's Bases. The values of revealed commitments of attributes are
+// presented as the values of revealed attributes.
+func NewPresentation(credDef *CredDef, known, committed []string, p *cl.CredProof,
+	nonRevoc *cl.NonRevocationProof) (*Presentation, error) {
+	if len(p.RangeProofs) > 0 {
+		return nil, fmt.Errorf("range proofs cannot be converted to AnonCreds predicates")
+	}
+	if len(p.RevealedKnownAttrs) != len(p.RevealedKnownAttrsIndices) ||
+		len(p.RevealedCommitmentsOfAttrs) != len(p.RevealedCommitmentsOfAttrsIndices) {
+		return nil, fmt.Errorf("invalid revealed attributes")
+	}
+
+	revealed := make(map[string]string)
+	for i, idx := range p.RevealedKnownAttrsIndices {
+		if idx < 0 || idx >= len(known) {
+			return nil, fmt.Errorf("invalid index of revealed known attribute: %d", idx)
+		}
+		revealed[known[idx]] = p.RevealedKnownAttrs[i].String()
+	}
+	for i, idx := range p.RevealedCommitmentsOfAttrsIndices {
+		if idx < 0 || idx >= len(committed) {
+			return nil, fmt.Errorf("invalid index of revealed commitment of attribute: %d", idx)
+		}
+		revealed[committed[idx]] = p.RevealedCommitmentsOfAttrs[i].String()
+	}
+
+	// proof data holds responses for unrevealed known attributes, unrevealed
+	// commitments of attributes, hidden attributes (if any, the master secret),
+	// e and v, in this order
+	unrevealed := append(unrevealedNames(known, p.RevealedKnownAttrsIndices),
+		unrevealedNames(committed, p.RevealedCommitmentsOfAttrsIndices)...)
+	data := p.Proof.ProofData
+	if len(data) == len(unrevealed)+3 {
+		unrevealed = append(unrevealed, MasterSecretName)
+	}
+	if len(data) != len(unrevealed)+2 {
+		return nil, fmt.Errorf("proof has %d responses, expected %d", len(data),
+			len(unrevealed)+2)
+	}
+	m := make(map[string]string, len(unrevealed))
+	for i, name := range unrevealed {
+		m[name] = data[i].String()
+	}
+
+	var nonRevocProof *NonRevocProof
+	if nonRevoc != nil {
+		nonRevocProof = &NonRevocProof{
+			CU:        nonRevoc.CU.String(),
+			CR:        nonRevoc.CR.String(),
+			CHash:     nonRevoc.Challenge.String(),
+			Responses: make([]string, len(nonRevoc.ProofData)),
+		}
+		for i, r := range nonRevoc.ProofData {
+			nonRevocProof.Responses[i] = r.String()
+		}
+	}
+
+	return &Presentation{
+		Proof: Proof{
+			Proofs: []SubProof{{
+				NonRevocProof: nonRevocProof,
+				PrimaryProof: PrimaryProof{
+					EqProof: EqProof{
+						RevealedAttrs: revealed,
+						APrime:        p.A.String(),
+						E:             data[len(data)-2].String(),
+						V:             data[len(data)-1].String(),
+						M:             m,
+					},
+					GeProofs: []interface{}{},
+				},
+			}},
+			AggregatedProof: AggregatedProof{
+				CHash: p.Proof.Challenge.String(),
+				CList: [][]int{toInts(p.Proof.ProofRandomData.Bytes())},
+			},
+		},
+		Identifiers: []Identifier{{
+			SchemaID:  credDef.SchemaID,
+			CredDefID: credDef.ID,
+		}},
+	}, nil
+}
+
+// CredProof returns the proof of possession of p, which can be verified with
+// cl.Org's ProveCred. Known and committed are the names of the known and committed
+// attributes of the credential, as passed to NewPresentation.
+func (p *Presentation) CredProof(known, committed []string) (*cl.CredProof, error) {
+	if len(p.Proof.Proofs) != 1 || len(p.Proof.AggregatedProof.CList) != 1 {
+		return nil, fmt.Errorf("presentation must hold exactly one sub-proof")
+	}
+	pp := p.Proof.Proofs[0].PrimaryProof
+	if len(pp.GeProofs) > 0 {
+		return nil, fmt.Errorf("predicates are not supported")
+	}
+	eq := pp.EqProof
+
+	cp := &cl.CredProof{
+		RevealedKnownAttrsIndices:         []int{},
+		RevealedCommitmentsOfAttrsIndices: []int{},
+		RevealedKnownAttrs:                []*big.Int{},
+		RevealedCommitmentsOfAttrs:        []*big.Int{},
+	}
+	var unrevealed []string
+	for i, name := range known {
+		val, ok := eq.RevealedAttrs[name]
+		if !ok {
+			unrevealed = append(unrevealed, name)
+			continue
+		}
+		ints, err := parseInts(val)
+		if err != nil {
+			return nil, err
+		}
+		cp.RevealedKnownAttrsIndices = append(cp.RevealedKnownAttrsIndices, i)
+		cp.RevealedKnownAttrs = append(cp.RevealedKnownAttrs, ints[0])
+	}
+	for i, name := range committed {
+		val, ok := eq.RevealedAttrs[name]
+		if !ok {
+			unrevealed = append(unrevealed, name)
+			continue
+		}
+		ints, err := parseInts(val)
+		if err != nil {
+			return nil, err
+		}
+		cp.RevealedCommitmentsOfAttrsIndices = append(cp.RevealedCommitmentsOfAttrsIndices, i)
+		cp.RevealedCommitmentsOfAttrs = append(cp.RevealedCommitmentsOfAttrs, ints[0])
+	}
+	if _, ok := eq.M[MasterSecretName]; ok {
+		unrevealed = append(unrevealed, MasterSecretName)
+	}
+
+	if len(eq.M) != len(unrevealed) {
+		return nil, fmt.Errorf("proof has %d responses for attributes, expected %d",
+			len(eq.M), len(unrevealed))
+	}
+	vals := make([]string, 0, len(unrevealed)+5)
+	for _, name := range unrevealed {
+		val, ok := eq.M[name]
+		if !ok {
+			return nil, fmt.Errorf("proof has no response for %s", name)
+		}
+		vals = append(vals, val)
+	}
+	vals = append(vals, eq.E, eq.V, eq.APrime, p.Proof.AggregatedProof.CHash)
+	ints, err := parseInts(vals...)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := fromInts(p.Proof.AggregatedProof.CList[0])
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(unrevealed) + 2
+	proofRandomData := new(big.Int).SetBytes(b)
+	cp.A = ints[n]
+	cp.Proof = qr.NewRepresentationProof(proofRandomData, ints[n+1], ints[:n])
+
+	return cp, nil
+}
+
+// NonRevocationProof returns the proof that the credential of p was not revoked,
+// which can be verified with cl.VerifyNonRevocationProof, or nil if p has none.
+func (p *Presentation) NonRevocationProof() (*cl.NonRevocationProof, error) {
+	if len(p.Proof.Proofs) != 1 {
+		return nil, fmt.Errorf("presentation must hold exactly one sub-proof")
+	}
+	nr := p.Proof.Proofs[0].NonRevocProof
+	if nr == nil {
+		return nil, nil
+	}
+
+	ints, err := parseInts(append([]string{nr.CU, nr.CR, nr.CHash}, nr.Responses...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &cl.NonRevocationProof{
+		CU:             ints[0],
+		CR:             ints[1],
+		AggregateProof: qr.NewAggregateProof(ints[2], ints[3:]),
+	}, nil
+}
+
+func unrevealedNames(names []string, revealedIndices []int) []string {
+	unrevealed := make([]string, 0, len(names))
+	for i, name := range names {
+		if !common.Contains(revealedIndices, i) {
+			unrevealed = append(unrevealed, name)
+		}
+	}
+	return unrevealed
+}
+
+func parseInts(vals ...string) ([]*big.Int, error) {
+	ints := make([]*big.Int, len(vals))
+	for i, v := range vals {
+		n, ok := new(big.Int).SetString(v, 10)
+		if !ok {
+			return nil, fmt.Errorf("values must be decimal integers")
+		}
+		ints[i] = n
+	}
+	return ints, nil
+}
+
+// toInts and fromInts convert bytes to and from integers, as JSON encoding of
+// byte slices to base64 would not match the arrays of numbers used by AnonCreds.
+func toInts(b []byte) []int {
+	ints := make([]int, len(b))
+	for i, v := range b {
+		ints[i] = int(v)
+	}
+	return ints
+}
+
+func fromInts(ints []int) ([]byte, error) {
+	b := make([]byte, len(ints))
+	for i, v := range ints {
+		if v < 0 || v > 255 {
+			return nil, fmt.Errorf("c_list must hold bytes")
+		}
+		b[i] = byte(v)
+	}
+	return b, nil
+}
@@ -0,0 +1,277 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package aries adapts emmy's credential presentations to Aries RFC 0454
+// (Present Proof Protocol 2.0) messages, so that emmy can act as a verifier
+// or a holder in Aries agent ecosystems. Presentations are attached as W3C
+// verifiable presentations (see package vc) holding a proof of possession
+// of a CL credential.
+package aries
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/vc"
+)
+
+// Message types of the Present Proof Protocol 2.0.
+const (
+	TypeRequestPresentation = "https://didcomm.org/present-proof/2.0/request-presentation"
+	TypePresentation        = "https://didcomm.org/present-proof/2.0/presentation"
+	TypeAck                 = "https://didcomm.org/present-proof/2.0/ack"
+)
+
+// Attachment formats used by emmy.
+const (
+	FormatRequest      = "emmy/cl-request@v1.0"
+	FormatPresentation = "emmy/cl-vp@v1.0"
+)
+
+// Format maps an attachment to its format.
+type Format struct {
+	AttachID string `json:"attach_id"`
+	Format   string `json:"format"`
+}
+
+// Attachment is a DIDComm attachment with base64 encoded data.
+type Attachment struct {
+	ID       string         `json:"@id"`
+	MimeType string         `json:"mime-type"`
+	Data     AttachmentData `json:"data"`
+}
+
+// AttachmentData holds base64 encoded data of an attachment.
+type AttachmentData struct {
+	Base64 string `json:"base64"`
+}
+
+// Thread identifies the thread (protocol instance) a message belongs to.
+type Thread struct {
+	ThID string `json:"thid"`
+}
+
+// RequestPresentation is a request-presentation message sent by the verifier.
+type RequestPresentation struct {
+	Type                 string       `json:"@type"`
+	ID                   string       `json:"@id"`
+	Comment              string       `json:"comment,omitempty"`
+	WillConfirm          bool         `json:"will_confirm"`
+	Formats              []Format     `json:"formats"`
+	RequestPresentations []Attachment `json:"request_presentations~attach"`
+}
+
+// Presentation is a presentation message sent by the holder.
+type Presentation struct {
+	Type          string       `json:"@type"`
+	ID            string       `json:"@id"`
+	Thread        Thread       `json:"~thread"`
+	Comment       string       `json:"comment,omitempty"`
+	Formats       []Format     `json:"formats"`
+	Presentations []Attachment `json:"presentations~attach"`
+}
+
+// Ack is an ack message sent by the verifier after verifying the presentation.
+type Ack struct {
+	Type   string `json:"@type"`
+	ID     string `json:"@id"`
+	Thread Thread `json:"~thread"`
+	Status string `json:"status"`
+}
+
+// PresentationRequest is what the verifier requests from the holder: a proof of
+// possession of a credential, revealing the given known attributes and commitments
+// of committed attributes (both identified by their indices), bound to the nonce.
+type PresentationRequest struct {
+	Nonce                             string `json:"nonce"`
+	RevealedKnownAttrsIndices         []int  `json:"revealed_known_attrs_indices"`
+	RevealedCommitmentsOfAttrsIndices []int  `json:"revealed_commitments_of_attrs_indices"`
+}
+
+// Verifier requests and verifies presentations of credentials issued by org.
+// A Verifier handles a single protocol instance at a time.
+type Verifier struct {
+	org     *cl.Org
+	request *RequestPresentation
+}
+
+// NewVerifier returns a Verifier of credentials issued by org.
+func NewVerifier(org *cl.Org) *Verifier {
+	return &Verifier{
+		org: org,
+	}
+}
+
+// RequestPresentation returns a request-presentation message asking for the given attributes.
+func (v *Verifier) RequestPresentation(revealedKnownAttrsIndices,
+	revealedCommitmentsOfAttrsIndices []int) (*RequestPresentation, error) {
+	req := &PresentationRequest{
+		Nonce:                             v.org.GetProveCredNonce().String(),
+		RevealedKnownAttrsIndices:         revealedKnownAttrsIndices,
+		RevealedCommitmentsOfAttrsIndices: revealedCommitmentsOfAttrsIndices,
+	}
+	attachment, err := newAttachment(req)
+	if err != nil {
+		return nil, err
+	}
+
+	v.request = &RequestPresentation{
+		Type:                 TypeRequestPresentation,
+		ID:                   newID(),
+		WillConfirm:          true,
+		Formats:              []Format{{AttachID: attachment.ID, Format: FormatRequest}},
+		RequestPresentations: []Attachment{*attachment},
+	}
+
+	return v.request, nil
+}
+
+// VerifyPresentation verifies the presentation sent in response to the last request
+// and returns an ack message if it is valid.
+func (v *Verifier) VerifyPresentation(msg *Presentation) (*Ack, error) {
+	if v.request == nil || msg.Thread.ThID != v.request.ID {
+		return nil, fmt.Errorf("presentation does not respond to the pending request")
+	}
+	v.request = nil
+
+	p := new(vc.Presentation)
+	if err := decodeAttachment(msg.Formats, msg.Presentations, FormatPresentation, p); err != nil {
+		return nil, err
+	}
+	cp, err := p.CredProof()
+	if err != nil {
+		return nil, err
+	}
+
+	verified, err := v.org.ProveCred(cp.A, cp.Proof, cp.RevealedKnownAttrsIndices,
+		cp.RevealedCommitmentsOfAttrsIndices, cp.RevealedKnownAttrs,
+		cp.RevealedCommitmentsOfAttrs)
+	if err != nil {
+		return nil, err
+	}
+	if !verified {
+		return nil, fmt.Errorf("presentation is not valid")
+	}
+
+	return &Ack{
+		Type:   TypeAck,
+		ID:     newID(),
+		Thread: Thread{ThID: msg.Thread.ThID},
+		Status: "OK",
+	}, nil
+}
+
+// Holder responds to presentation requests with proofs of possession
+// of its credential, issued by issuer.
+type Holder struct {
+	issuer  string
+	credMgr *cl.CredManager
+	cred    *cl.Cred
+}
+
+// NewHolder returns a Holder of the credential cred.
+func NewHolder(issuer string, credMgr *cl.CredManager, cred *cl.Cred) *Holder {
+	return &Holder{
+		issuer:  issuer,
+		credMgr: credMgr,
+		cred:    cred,
+	}
+}
+
+// Present returns a presentation message responding to req.
+func (h *Holder) Present(req *RequestPresentation) (*Presentation, error) {
+	pr := new(PresentationRequest)
+	if err := decodeAttachment(req.Formats, req.RequestPresentations, FormatRequest,
+		pr); err != nil {
+		return nil, err
+	}
+	nonce, ok := new(big.Int).SetString(pr.Nonce, 10)
+	if !ok {
+		return nil, fmt.Errorf("nonce must be a decimal integer")
+	}
+
+	randCred, proof, err := h.credMgr.BuildProof(h.cred, pr.RevealedKnownAttrsIndices,
+		pr.RevealedCommitmentsOfAttrsIndices, nonce)
+	if err != nil {
+		return nil, err
+	}
+	_, revealedCommitmentsOfAttrs := h.credMgr.FilterAttributes(pr.RevealedKnownAttrsIndices,
+		pr.RevealedCommitmentsOfAttrsIndices)
+	p, err := vc.NewPresentation(h.issuer, randCred.A, proof, pr.RevealedKnownAttrsIndices,
+		pr.RevealedCommitmentsOfAttrsIndices, revealedCommitmentsOfAttrs, h.credMgr.RawCred)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment, err := newAttachment(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Presentation{
+		Type:          TypePresentation,
+		ID:            newID(),
+		Thread:        Thread{ThID: req.ID},
+		Formats:       []Format{{AttachID: attachment.ID, Format: FormatPresentation}},
+		Presentations: []Attachment{*attachment},
+	}, nil
+}
+
+// newAttachment returns an attachment holding JSON encoding of v.
+func newAttachment(v interface{}) (*Attachment, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Attachment{
+		ID:       newID(),
+		MimeType: "application/json",
+		Data:     AttachmentData{Base64: base64.StdEncoding.EncodeToString(data)},
+	}, nil
+}
+
+// decodeAttachment decodes the attachment of the given format into v.
+func decodeAttachment(formats []Format, attachments []Attachment, format string,
+	v interface{}) error {
+	for _, f := range formats {
+		if f.Format != format {
+			continue
+		}
+		for _, a := range attachments {
+			if a.ID != f.AttachID {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(a.Data.Base64)
+			if err != nil {
+				return fmt.Errorf("invalid attachment %s: %v", a.ID, err)
+			}
+			return json.Unmarshal(data, v)
+		}
+	}
+
+	return fmt.Errorf("no attachment of format %s", format)
+}
+
+// newID returns a random message identifier.
+func newID() string {
+	return fmt.Sprintf("%x", common.GetRandomIntOfLength(128))
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package aries
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+func TestPresentProof(t *testing.T) {
+	params := cl.GetDefaultParamSizes()
+	attrCount := cl.NewAttrCount(5, 1, 0)
+	org, err := cl.NewOrg(params, attrCount)
+	if err != nil {
+		t.Fatalf("error when generating CL org: %v", err)
+	}
+
+	rawCred := cl.NewRawCred(attrCount)
+	_ = rawCred.AddStrAttr("Name", "Jack", true)
+	_ = rawCred.AddStrAttr("Gender", "M", true)
+	_ = rawCred.AddStrAttr("Graduated", "true", true)
+	_ = rawCred.AddInt64Attr("DateMin", 22342345, true)
+	_ = rawCred.AddInt64Attr("DateMax", 32342345, true)
+	_ = rawCred.AddInt64Attr("Age", 25, false)
+
+	credMgr, err := cl.NewCredManager(params, org.Keys.Pub,
+		org.Keys.Pub.GenerateUserMasterSecret(), rawCred)
+	if err != nil {
+		t.Fatalf("error when creating credential manager: %v", err)
+	}
+	credReq, err := credMgr.GetCredRequest(org.GetCredIssueNonce())
+	if err != nil {
+		t.Fatalf("error when generating credential request: %v", err)
+	}
+	res, err := org.IssueCred(credReq)
+	if err != nil {
+		t.Fatalf("error when issuing credential: %v", err)
+	}
+
+	verifier := NewVerifier(org)
+	holder := NewHolder("did:example:org1", credMgr, res.Cred)
+
+	req, err := verifier.RequestPresentation([]int{0}, []int{0})
+	if err != nil {
+		t.Fatalf("error when requesting presentation: %v", err)
+	}
+
+	// messages travel as JSON between agents
+	data, _ := json.Marshal(req)
+	req = new(RequestPresentation)
+	_ = json.Unmarshal(data, req)
+	assert.Equal(t, TypeRequestPresentation, req.Type)
+
+	p, err := holder.Present(req)
+	if err != nil {
+		t.Fatalf("error when presenting credential: %v", err)
+	}
+	data, _ = json.Marshal(p)
+	p = new(Presentation)
+	_ = json.Unmarshal(data, p)
+	assert.Equal(t, req.ID, p.Thread.ThID)
+
+	ack, err := verifier.VerifyPresentation(p)
+	if err != nil {
+		t.Fatalf("error when verifying presentation: %v", err)
+	}
+	assert.Equal(t, req.ID, ack.Thread.ThID)
+
+	_, err = verifier.VerifyPresentation(p)
+	assert.Error(t, err, "presentation should not be accepted twice")
+}
@@ -0,0 +1,215 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package bench measures the performance of emmy's cryptographic schemes, so that
+// regressions in the crypto layer can be caught by comparing runs.
+//
+// Unlike benchmarks of package testing, which scale the number of iterations to
+// the running time, each operation is run a fixed number of times on fixed inputs,
+// so that runs with the same options are directly comparable. Results are written
+// as JSON, together with the environment they were obtained in.
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// Result holds measurements of a single operation.
+type Result struct {
+	Name        string `json:"name"`   // operation, e.g. cl/issue
+	Params      string `json:"params"` // parameters preset
+	Iterations  int    `json:"iterations"`
+	NsPerOp     int64  `json:"ns_per_op"`
+	AllocsPerOp int64  `json:"allocs_per_op"`
+	BytesPerOp  int64  `json:"bytes_per_op"`
+}
+
+// key identifies the operation and parameters of r across reports.
+func (r *Result) key() string {
+	return r.Name + "@" + r.Params
+}
+
+// Report holds results of a run.
+type Report struct {
+	GoVersion string    `json:"go_version"`
+	GOOS      string    `json:"goos"`
+	GOARCH    string    `json:"goarch"`
+	NumCPU    int       `json:"num_cpu"`
+	Date      time.Time `json:"date"`
+	Results   []Result  `json:"results"`
+}
+
+// newReport returns an empty report of the current environment.
+func newReport() *Report {
+	return &Report{
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		NumCPU:    runtime.NumCPU(),
+		Date:      time.Now().UTC(),
+	}
+}
+
+// WriteJSON writes r to w as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// ReadReport reads a report written by Report.WriteJSON.
+func ReadReport(rd io.Reader) (*Report, error) {
+	r := new(Report)
+	if err := json.NewDecoder(rd).Decode(r); err != nil {
+		return nil, fmt.Errorf("malformed report: %v", err)
+	}
+	return r, nil
+}
+
+// Options determine what is measured.
+type Options struct {
+	Presets          []string // CL parameters presets
+	Iterations       int      // number of runs of each operation but key generation
+	KeygenIterations int      // number of runs of key generation
+}
+
+// DefaultOptions returns options that complete in a few seconds, measuring
+// with parameters of the test preset only.
+func DefaultOptions() *Options {
+	return &Options{
+		Presets:          []string{"test"},
+		Iterations:       20,
+		KeygenIterations: 1,
+	}
+}
+
+// Run measures all operations with opts.
+func Run(opts *Options) (*Report, error) {
+	if opts.Iterations < 1 || opts.KeygenIterations < 1 {
+		return nil, fmt.Errorf("number of iterations must be positive")
+	}
+
+	report := newReport()
+	for _, preset := range opts.Presets {
+		results, err := runCL(preset, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error when measuring CL (%s): %v", preset, err)
+		}
+		report.Results = append(report.Results, results...)
+	}
+
+	return report, nil
+}
+
+// measure runs f n times and returns its average running time and allocations.
+func measure(name, params string, n int, f func() error) (*Result, error) {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		if err := f(); err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return &Result{
+		Name:        name,
+		Params:      params,
+		Iterations:  n,
+		NsPerOp:     elapsed.Nanoseconds() / int64(n),
+		AllocsPerOp: int64(after.Mallocs-before.Mallocs) / int64(n),
+		BytesPerOp:  int64(after.TotalAlloc-before.TotalAlloc) / int64(n),
+	}, nil
+}
+
+// Metrics compared between reports.
+const (
+	MetricNsPerOp     = "ns/op"
+	MetricAllocsPerOp = "allocs/op"
+)
+
+// Regression describes an operation that got slower or allocates more than
+// in the base report.
+type Regression struct {
+	Name    string
+	Params  string
+	Metric  string
+	Base    int64
+	Current int64
+}
+
+// Ratio returns the ratio of the current and the base value.
+func (r *Regression) Ratio() float64 {
+	return float64(r.Current) / float64(r.Base)
+}
+
+func (r *Regression) String() string {
+	return fmt.Sprintf("%s (%s): %s %d -> %d (%+.1f%%)", r.Name, r.Params, r.Metric,
+		r.Base, r.Current, (r.Ratio()-1)*100)
+}
+
+// Compare returns operations whose running time or number of allocations in
+// current exceed those in base by more than the given tolerance (e.g. 0.1 for
+// 10%). Operations missing from either report are ignored.
+func Compare(base, current *Report, tolerance float64) []*Regression {
+	baseResults := make(map[string]Result, len(base.Results))
+	for _, r := range base.Results {
+		baseResults[r.key()] = r
+	}
+
+	var regressions []*Regression
+	for _, cur := range current.Results {
+		b, ok := baseResults[cur.key()]
+		if !ok {
+			continue
+		}
+		metrics := []struct {
+			name          string
+			base, current int64
+		}{
+			{MetricNsPerOp, b.NsPerOp, cur.NsPerOp},
+			{MetricAllocsPerOp, b.AllocsPerOp, cur.AllocsPerOp},
+		}
+		for _, m := range metrics {
+			if m.base > 0 && float64(m.current) > float64(m.base)*(1+tolerance) {
+				regressions = append(regressions, &Regression{
+					Name:    cur.Name,
+					Params:  cur.Params,
+					Metric:  m.name,
+					Base:    m.base,
+					Current: m.current,
+				})
+			}
+		}
+	}
+
+	sort.Slice(regressions, func(i, j int) bool {
+		return regressions[i].Ratio() > regressions[j].Ratio()
+	})
+	return regressions
+}
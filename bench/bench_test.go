@@ -0,0 +1,82 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bench
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Iterations = 2
+	report, err := Run(opts)
+	if err != nil {
+		t.Fatalf("error when running benchmarks: %v", err)
+	}
+
+	var names []string
+	for _, r := range report.Results {
+		names = append(names, r.Name)
+		assert.Equal(t, "test", r.Params)
+		assert.True(t, r.NsPerOp > 0, r.Name)
+	}
+	assert.Equal(t, []string{"cl/keygen", "cl/issue", "cl/prove", "cl/verify"}, names)
+	assert.Equal(t, 1, report.Results[0].Iterations)
+	assert.Equal(t, 2, report.Results[1].Iterations)
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.WriteJSON(&buf))
+	decoded, err := ReadReport(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, report.Results, decoded.Results)
+
+	_, err = Run(&Options{Presets: []string{"none"}, Iterations: 1, KeygenIterations: 1})
+	assert.Error(t, err)
+	_, err = Run(&Options{Presets: []string{"test"}})
+	assert.Error(t, err)
+}
+
+func TestCompare(t *testing.T) {
+	base := &Report{Results: []Result{
+		{Name: "cl/issue", Params: "test", NsPerOp: 1000, AllocsPerOp: 100},
+		{Name: "cl/prove", Params: "test", NsPerOp: 1000, AllocsPerOp: 100},
+		{Name: "cl/verify", Params: "test", NsPerOp: 1000, AllocsPerOp: 100},
+	}}
+	current := &Report{Results: []Result{
+		{Name: "cl/issue", Params: "test", NsPerOp: 1050, AllocsPerOp: 100},
+		{Name: "cl/prove", Params: "test", NsPerOp: 2000, AllocsPerOp: 100},
+		{Name: "cl/verify", Params: "test", NsPerOp: 900, AllocsPerOp: 150},
+		{Name: "cl/verify", Params: "cl-2048", NsPerOp: 9000, AllocsPerOp: 100},
+	}}
+
+	regressions := Compare(base, current, 0.1)
+	if assert.Len(t, regressions, 2) {
+		assert.Equal(t, "cl/prove", regressions[0].Name)
+		assert.Equal(t, MetricNsPerOp, regressions[0].Metric)
+		assert.Equal(t, 2.0, regressions[0].Ratio())
+		assert.Equal(t, "cl/verify", regressions[1].Name)
+		assert.Equal(t, MetricAllocsPerOp, regressions[1].Metric)
+		assert.Equal(t, "cl/verify (test): allocs/op 100 -> 150 (+50.0%)",
+			regressions[1].String())
+	}
+
+	assert.Empty(t, Compare(base, current, 1.0))
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bench
+
+import (
+	"fmt"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/qr"
+)
+
+// runCL measures key generation, issuance, proving and verification of CL
+// credentials with parameters of the given preset. Credentials hold three
+// known attributes and one committed attribute, of which the first known
+// attribute and the committed attribute are revealed in proofs.
+func runCL(preset string, opts *Options) ([]Result, error) {
+	params, err := cl.GetParamsPreset(preset)
+	if err != nil {
+		return nil, err
+	}
+	attrCount := cl.NewAttrCount(3, 1, 0)
+	newRawCred := func() *cl.RawCred {
+		c := cl.NewRawCred(attrCount)
+		_ = c.AddStrAttr("Name", "Jack", true)
+		_ = c.AddStrAttr("Gender", "M", true)
+		_ = c.AddInt64Attr("DateMin", 22342345, true)
+		_ = c.AddInt64Attr("Age", 25, false)
+		return c
+	}
+
+	var results []Result
+	var keys *cl.KeyPair
+	r, err := measure("cl/keygen", preset, opts.KeygenIterations, func() error {
+		keys, err = cl.GenerateKeyPair(params, attrCount)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, *r)
+
+	org, err := cl.NewOrgFromParams(params, keys)
+	if err != nil {
+		return nil, err
+	}
+	credMgr, err := cl.NewCredManager(params, keys.Pub, keys.Pub.GenerateUserMasterSecret(),
+		newRawCred())
+	if err != nil {
+		return nil, err
+	}
+
+	var res *cl.CredResult
+	r, err = measure("cl/issue", preset, opts.Iterations, func() error {
+		req, err := credMgr.GetCredRequest(org.GetCredIssueNonce())
+		if err != nil {
+			return err
+		}
+		if res, err = org.IssueCred(req); err != nil {
+			return err
+		}
+		if ok, err := credMgr.Verify(res.Cred, res.AProof); !ok {
+			return fmt.Errorf("credential not valid: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, *r)
+
+	revealedKnown, revealedCommitted := []int{0}, []int{0}
+	knownVals, commitments := credMgr.FilterAttributes(revealedKnown, revealedCommitted)
+	nonce := org.GetProveCredNonce()
+	var randCred *cl.Cred
+	var proof *qr.RepresentationProof
+	r, err = measure("cl/prove", preset, opts.Iterations, func() error {
+		randCred, proof, err = credMgr.BuildProof(res.Cred, revealedKnown, revealedCommitted, nonce)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, *r)
+
+	r, err = measure("cl/verify", preset, opts.Iterations, func() error {
+		ok, err := org.ProveCred(randCred.A, proof, revealedKnown, revealedCommitted,
+			knownVals, commitments)
+		if !ok {
+			return fmt.Errorf("proof not valid: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return append(results, *r), nil
+}
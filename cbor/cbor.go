@@ -0,0 +1,294 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package cbor encodes and decodes the subset of CBOR (RFC 7049) used by emmy:
+// integers, big integers (bignum tags 2 and 3), byte and text strings, arrays, maps,
+// booleans and null. It is used by WebAuthn attestations and encodings of credentials.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// CBOR major types.
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+)
+
+// Tags of big integers.
+const (
+	tagPosBignum = 2
+	tagNegBignum = 3
+)
+
+// maxCBORDepth limits nesting of decoded CBOR items.
+const maxCBORDepth = 16
+
+// Decode decodes the CBOR item at the beginning of data, returning the item and the
+// remaining data. Integers are decoded as int64, big integers as *big.Int and maps as
+// map[interface{}]interface{}.
+func Decode(data []byte) (interface{}, []byte, error) {
+	return decodeCBORItem(data, 0)
+}
+
+func decodeCBORItem(data []byte, depth int) (interface{}, []byte, error) {
+	if depth > maxCBORDepth {
+		return nil, nil, fmt.Errorf("CBOR item nested too deeply")
+	}
+	if len(data) == 0 {
+		return nil, nil, fmt.Errorf("unexpected end of CBOR data")
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+
+	if major == cborSimple {
+		switch info {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22:
+			return nil, data, nil
+		}
+		return nil, nil, fmt.Errorf("unsupported CBOR simple value %d", info)
+	}
+
+	n, data, err := decodeCBORArg(info, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case cborUint:
+		if n > 1<<63-1 {
+			return nil, nil, fmt.Errorf("CBOR integer out of range")
+		}
+		return int64(n), data, nil
+	case cborNegInt:
+		if n > 1<<63-1 {
+			return nil, nil, fmt.Errorf("CBOR integer out of range")
+		}
+		return -1 - int64(n), data, nil
+	case cborBytes, cborText:
+		if n > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("unexpected end of CBOR data")
+		}
+		b := data[:n]
+		if major == cborText {
+			return string(b), data[n:], nil
+		}
+		return append([]byte{}, b...), data[n:], nil
+	case cborArray:
+		if n > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("unexpected end of CBOR data")
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], data, err = decodeCBORItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+		}
+		return items, data, nil
+	case cborMap:
+		if n > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("unexpected end of CBOR data")
+		}
+		m := make(map[interface{}]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			var k, v interface{}
+			if k, data, err = decodeCBORItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, nil, fmt.Errorf("unsupported CBOR map key type %T", k)
+			}
+			if v, data, err = decodeCBORItem(data, depth+1); err != nil {
+				return nil, nil, err
+			}
+			m[k] = v
+		}
+		return m, data, nil
+	case cborTag:
+		if n != tagPosBignum && n != tagNegBignum {
+			return nil, nil, fmt.Errorf("unsupported CBOR tag %d", n)
+		}
+		item, data, err := decodeCBORItem(data, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		b, ok := item.([]byte)
+		if !ok {
+			return nil, nil, fmt.Errorf("CBOR bignum is not a byte string")
+		}
+		x := new(big.Int).SetBytes(b)
+		if n == tagNegBignum {
+			x.Neg(x).Sub(x, big.NewInt(1))
+		}
+		return x, data, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported CBOR major type %d", major)
+}
+
+// decodeCBORArg decodes the argument of a CBOR item with additional information info.
+func decodeCBORArg(info byte, data []byte) (uint64, []byte, error) {
+	var size int
+	switch {
+	case info < 24:
+		return uint64(info), data, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, nil, fmt.Errorf("unsupported CBOR additional information %d", info)
+	}
+	if len(data) < size {
+		return 0, nil, fmt.Errorf("unexpected end of CBOR data")
+	}
+
+	var n uint64
+	for _, b := range data[:size] {
+		n = n<<8 | uint64(b)
+	}
+	return n, data[size:], nil
+}
+
+// Encode encodes v, which can hold the same types Decode returns (as well as int), in
+// canonical CBOR.
+func Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeCBORItem(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeCBORItem(buf *bytes.Buffer, v interface{}) error {
+	switch x := v.(type) {
+	case int:
+		return encodeCBORItem(buf, int64(x))
+	case int64:
+		if x < 0 {
+			writeCBORHead(buf, cborNegInt, uint64(-1-x))
+		} else {
+			writeCBORHead(buf, cborUint, uint64(x))
+		}
+	case *big.Int:
+		// bignums are only used for integers that do not fit into 64 bits
+		if x.IsInt64() {
+			return encodeCBORItem(buf, x.Int64())
+		}
+		if x.Sign() < 0 {
+			writeCBORHead(buf, cborTag, tagNegBignum)
+			return encodeCBORItem(buf, new(big.Int).Sub(new(big.Int).Neg(x),
+				big.NewInt(1)).Bytes())
+		}
+		writeCBORHead(buf, cborTag, tagPosBignum)
+		return encodeCBORItem(buf, x.Bytes())
+	case []byte:
+		writeCBORHead(buf, cborBytes, uint64(len(x)))
+		buf.Write(x)
+	case string:
+		writeCBORHead(buf, cborText, uint64(len(x)))
+		buf.WriteString(x)
+	case bool:
+		if x {
+			buf.WriteByte(cborSimple<<5 | 21)
+		} else {
+			buf.WriteByte(cborSimple<<5 | 20)
+		}
+	case nil:
+		buf.WriteByte(cborSimple<<5 | 22)
+	case []interface{}:
+		writeCBORHead(buf, cborArray, uint64(len(x)))
+		for _, item := range x {
+			if err := encodeCBORItem(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[interface{}]interface{}:
+		// canonical CBOR sorts keys by the length and then by the value of
+		// their encodings
+		type entry struct {
+			key, val []byte
+		}
+		entries := make([]entry, 0, len(x))
+		for k, val := range x {
+			kb, err := Encode(k)
+			if err != nil {
+				return err
+			}
+			vb, err := Encode(val)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, entry{kb, vb})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			if len(entries[i].key) != len(entries[j].key) {
+				return len(entries[i].key) < len(entries[j].key)
+			}
+			return bytes.Compare(entries[i].key, entries[j].key) < 0
+		})
+		writeCBORHead(buf, cborMap, uint64(len(entries)))
+		for _, e := range entries {
+			buf.Write(e.key)
+			buf.Write(e.val)
+		}
+	default:
+		return fmt.Errorf("unsupported CBOR type %T", v)
+	}
+
+	return nil
+}
+
+func writeCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	switch {
+	case n < 24:
+		buf.WriteByte(major<<5 | byte(n))
+	case n <= 0xff:
+		buf.WriteByte(major<<5 | 24)
+		buf.WriteByte(byte(n))
+	case n <= 0xffff:
+		buf.WriteByte(major<<5 | 25)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= 0xffffffff:
+		buf.WriteByte(major<<5 | 26)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(major<<5 | 27)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cbor
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCBOR(t *testing.T) {
+	v := map[interface{}]interface{}{
+		"fmt":     "none",
+		int64(1):  int64(2),
+		int64(-3): []byte{1, 2, 3},
+		"list":    []interface{}{true, false, nil, int64(1000000)},
+	}
+	data, err := Encode(v)
+	if err != nil {
+		t.Fatalf("error when encoding: %v", err)
+	}
+	decoded, rest, err := Decode(append(data, 0xff))
+	assert.NoError(t, err)
+	assert.Equal(t, v, decoded)
+	assert.Equal(t, []byte{0xff}, rest)
+
+	// {1: 2} as encoded in RFC 7049
+	decoded, _, err = Decode([]byte{0xa1, 0x01, 0x02})
+	assert.NoError(t, err)
+	assert.Equal(t, map[interface{}]interface{}{int64(1): int64(2)}, decoded)
+
+	_, _, err = Decode([]byte{0x5a, 0xff, 0xff, 0xff, 0xff})
+	assert.Error(t, err, "truncated data should not be decoded")
+}
+
+func TestCBORBignum(t *testing.T) {
+	// 2^64 as encoded in RFC 7049
+	twoTo64 := new(big.Int).Lsh(big.NewInt(1), 64)
+	data, err := Encode(twoTo64)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xc2, 0x49, 0x01, 0, 0, 0, 0, 0, 0, 0, 0}, data)
+
+	for _, x := range []*big.Int{
+		twoTo64,
+		new(big.Int).Neg(twoTo64),
+		new(big.Int).Lsh(big.NewInt(-12345), 300),
+	} {
+		data, err := Encode(x)
+		assert.NoError(t, err)
+		decoded, _, err := Decode(data)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, x.Cmp(decoded.(*big.Int)), "bignum %v changed", x)
+	}
+
+	// integers that fit into 64 bits are encoded as such
+	data, err = Encode(big.NewInt(-500))
+	assert.NoError(t, err)
+	decoded, _, err := Decode(data)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-500), decoded)
+
+	_, _, err = Decode([]byte{0xc2, 0x01})
+	assert.Error(t, err, "bignum should hold a byte string")
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/server"
+)
+
+// memAuditLog keeps audit events in memory.
+type memAuditLog struct {
+	sync.Mutex
+	events []*server.AuditEvent
+}
+
+func (l *memAuditLog) Append(e *server.AuditEvent) error {
+	l.Lock()
+	defer l.Unlock()
+	l.events = append(l.events, e)
+	return nil
+}
+
+// TestAuditLog issues and proves a CL credential with a server that appends audit
+// events to a file and checks that they do not reveal attributes.
+func TestAuditLog(t *testing.T) {
+	srv, conn := newTestServer(t, &mockRegKeyDB{data: []string{"auditKey1"}})
+	defer conn.Close()
+	dir, err := ioutil.TempDir("", "emmy-audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	fileLog, err := server.NewFileAuditLog(filepath.Join(dir, "audit.log"))
+	require.NoError(t, err)
+	defer fileLog.Close()
+	memLog := new(memAuditLog)
+	require.NoError(t, srv.UseAuditLog(memLog, []byte("key")))
+
+	client, err := NewCLClient(conn)
+	require.NoError(t, err)
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+
+	cred, err := client.IssueCredential(context.Background(), cm, "auditKey1")
+	require.NoError(t, err)
+	_, err = client.IssueCredential(context.Background(), cm, "auditKey1")
+	require.Error(t, err)
+	acceptableCreds, err := client.GetAcceptableCreds(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, srv.UseAuditLog(fileLog, []byte("key")))
+	_, err = client.ProveCredential(context.Background(), cm, cred, acceptableCreds["org1"])
+	require.NoError(t, err)
+
+	require.Len(t, memLog.events, 2)
+	issued, rejected := memLog.events[0], memLog.events[1]
+	assert.Equal(t, server.AuditIssuance, issued.Type)
+	assert.Equal(t, "IssueCredential", issued.Protocol)
+	assert.Equal(t, server.AuditSuccess, issued.Outcome)
+	assert.NotEmpty(t, issued.Schema)
+	assert.NotEmpty(t, issued.Client)
+	assert.Equal(t, server.AuditFailure, rejected.Outcome)
+	assert.Equal(t, "INVALID_REG_KEY", rejected.Reason)
+	// the same client has the same pseudonym under the same key
+	assert.Equal(t, issued.Client, rejected.Client)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "audit.log"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"type":"verification"`)
+	assert.Contains(t, lines[0], `"protocol":"ProveCredential"`)
+	assert.Contains(t, lines[0], issued.Client)
+	assert.NotContains(t, lines[0], "Jack")
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/bbs"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc"
+)
+
+// BBSClient obtains BBS+ credentials and proves their possession. BBS+ credentials
+// are an alternative to CL credentials (see CLClient) with shorter proofs. Messages of
+// credentials are attributes of the credential structure of the server, known ones
+// followed by committed ones (as returned by cl.RawCred GetKnownVals and
+// GetCommittedVals).
+type BBSClient struct {
+	genericClient
+	grpcClient pb.BBSClient
+}
+
+func NewBBSClient(conn *grpc.ClientConn) (*BBSClient, error) {
+	return &BBSClient{
+		genericClient: newGenericClient(),
+		grpcClient:    pb.NewBBSClient(conn),
+	}, nil
+}
+
+// IssueCredential obtains a credential on the messages of credManager, of which the
+// server learns only the known ones.
+func (c *BBSClient) IssueCredential(ctx context.Context, credManager *bbs.CredManager,
+	regKey string) (*bbs.Signature, error) {
+	if err := c.openStream(ctx, c.grpcClient, "IssueBBSCredential"); err != nil {
+		return nil, err
+	}
+	defer c.closeStream()
+
+	initMsg := &pb.Message{
+		ClientId: c.id,
+		Content: &pb.Message_RegKey{
+			RegKey: &pb.RegKey{
+				RegKey: regKey,
+			},
+		},
+	}
+	resp, err := c.getResponseTo(initMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := new(big.Int).SetBytes(resp.GetBigint().X1)
+	credReq, err := credManager.GetCredRequest(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	credReqMsg := &pb.Message{
+		Content: &pb.Message_BBSCredRequest{
+			BBSCredRequest: pb.ToPbBBSCredRequest(credReq),
+		},
+	}
+	resp, err = c.getResponseTo(credReqMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	blindSig, err := resp.GetBBSSignature().GetNativeType()
+	if err != nil {
+		return nil, err
+	}
+	cred, err := credManager.Unblind(blindSig)
+	if err != nil {
+		return nil, fmt.Errorf("credential not valid: %v", err)
+	}
+
+	return cred, nil
+}
+
+// ProveCredential proves the possession of cred, revealing only messages at indices
+// revealed, and returns the session key obtained from the server.
+func (c *BBSClient) ProveCredential(ctx context.Context, credManager *bbs.CredManager,
+	cred *bbs.Signature, revealed []int) (*string, error) {
+	if err := c.openStream(ctx, c.grpcClient, "ProveBBSCredential"); err != nil {
+		return nil, err
+	}
+	defer c.closeStream()
+
+	initMsg := &pb.Message{
+		ClientId: c.id,
+	}
+	resp, err := c.getResponseTo(initMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := new(big.Int).SetBytes(resp.GetBigint().X1)
+	proof, err := credManager.BuildProof(cred, revealed, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("error when building credential proof: %v", err)
+	}
+
+	proveMsg := &pb.Message{
+		Content: &pb.Message_BBSProof{
+			BBSProof: pb.ToPbBBSProof(proof),
+		},
+	}
+	resp, err = c.getResponseTo(proveMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	sessKey := resp.GetSessionKey().Value
+	c.idToken = resp.GetSessionKey().IdToken
+	return &sessKey, nil
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/bbs"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+)
+
+// TestBBS obtains a BBS+ credential on the attributes of the configured credential
+// structure and proves its possession, revealing only the name.
+func TestBBS(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{data: []string{"testRegKey1"}}, cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+	store := server.NewMemSessionStore()
+	srv.UseSessionStore(store, time.Minute)
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	defer srv.Teardown()
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	conn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+
+	// credential structure is read from the main test server, which shares the
+	// configuration
+	clClient, err := NewCLClient(testGrpcClientConn)
+	require.NoError(t, err)
+	rc, err := clClient.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+
+	pubKey, err := bbs.ReadPubKey("testdata/bbsPubKey.gob")
+	require.NoError(t, err)
+	cm, err := bbs.NewCredManager(pubKey, rc.GetKnownVals(), rc.GetCommittedVals())
+	require.NoError(t, err)
+
+	client, err := NewBBSClient(conn)
+	require.NoError(t, err)
+	cred, err := client.IssueCredential(context.Background(), cm, "testRegKey1")
+	require.NoError(t, err)
+	_, err = client.IssueCredential(context.Background(), cm, "testRegKey1")
+	assert.Error(t, err, "registration key was used twice")
+
+	nameIndex, err := rc.GetAttrInternalIndex("Name")
+	require.NoError(t, err)
+	sessKey, err := client.ProveCredential(context.Background(), cm, cred, []int{nameIndex})
+	require.NoError(t, err)
+	session, err := store.Get(*sessKey)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"Name": "Jack"}, session.Claims)
+
+	// possession can also be proved through the gateway
+	endpoint := httptest.NewServer(server.NewGateway(srv, ""))
+	defer endpoint.Close()
+	client.UseStreamOpener(NewGatewayConn(endpoint.URL, nil))
+	_, err = client.ProveCredential(context.Background(), cm, cred, nil)
+	assert.NoError(t, err)
+
+	// the proof does not verify for a credential on other attributes
+	otherCm, err := bbs.NewCredManager(pubKey, rc.GetKnownVals(),
+		[]*big.Int{big.NewInt(18)})
+	require.NoError(t, err)
+	_, err = client.ProveCredential(context.Background(), otherCm, cred, []int{nameIndex})
+	assert.Error(t, err)
+}
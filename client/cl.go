@@ -21,10 +21,14 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/log"
 	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/webauthn"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -32,22 +36,62 @@ import (
 
 type CLClient struct {
 	genericClient
-	grpcClient pb.CLClient
+	grpcClient    pb.CLClient
+	revocation    pb.RevocationClient
+	authenticator webauthn.Authenticator
+	bindingAttr   string
 }
 
 func NewCLClient(conn *grpc.ClientConn) (*CLClient, error) {
 	return &CLClient{
 		genericClient: newGenericClient(),
 		grpcClient:    pb.NewCLClient(conn),
+		revocation:    pb.NewRevocationClient(conn),
 	}, nil
 }
 
-func (c *CLClient) GetCredentialStructure() (*cl.RawCred, error) {
-	cred, err := c.grpcClient.GetCredentialStructure(context.Background(), &empty.Empty{})
+// BindDevice binds credentials to a WebAuthn authenticator, as required by servers
+// with device binding enabled. IssueCredential sets the known string attribute attr
+// to the binding of a new credential of authenticator a, and ProveCredential reveals
+// attr along with an assertion of a.
+func (c *CLClient) BindDevice(a webauthn.Authenticator, attr string) {
+	c.authenticator = a
+	c.bindingAttr = attr
+}
+
+// GetCredentialStructure returns an empty credential with the default structure of
+// credentials issued by the server.
+func (c *CLClient) GetCredentialStructure(ctx context.Context) (*cl.RawCred, error) {
+	return c.GetCredentialSchema(ctx, "", "")
+}
+
+// GetCredentialSchema returns an empty credential with the structure given by version
+// of schema name, registered at the server. The default schema is used when name is
+// empty, and its latest version when version is empty.
+func (c *CLClient) GetCredentialSchema(ctx context.Context, name,
+	version string) (*cl.RawCred, error) {
+	req := &pb.CredStructureRequest{
+		Name:    name,
+		Version: version,
+	}
+	var cred *pb.CredStructure
+	var err error
+	ctx = c.withTenant(ctx)
+	if i, ok := c.invoker(); ok {
+		cred = new(pb.CredStructure)
+		err = i.Invoke(ctx, "/proto.CL/GetCredentialStructure", req, cred)
+	} else {
+		cred, err = c.grpcClient.GetCredentialStructure(ctx, req)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve credential structure info: %v", err)
+		return nil, wrapError("unable to retrieve credential structure info", err)
 	}
 
+	return rawCred(cred)
+}
+
+// rawCred returns an empty credential with structure cred.
+func rawCred(cred *pb.CredStructure) (*cl.RawCred, error) {
 	count := cl.NewAttrCount(
 		int(cred.NKnown),
 		int(cred.NCommitted),
@@ -59,29 +103,60 @@ func (c *CLClient) GetCredentialStructure() (*cl.RawCred, error) {
 	for _, a := range attrs {
 		switch u := a.Type.(type) { // TODO make more intuitive
 		case *pb.CredAttribute_StringAttr:
-			fmt.Println("Client received string attribute", u.StringAttr)
 			strA := a.GetStringAttr().Attr
+			log.With(logger, "attribute", strA.Name, "known", strA.Known).
+				Debug("Received string attribute")
 			err := rc.AddEmptyStrAttr(strA.Name, strA.Known)
 			if err != nil {
 				return nil, err
 			}
 		case *pb.CredAttribute_IntAttr:
-			fmt.Println("Client received int attribute", u.IntAttr)
 			intA := a.GetIntAttr().Attr
+			log.With(logger, "attribute", intA.Name, "known", intA.Known).
+				Debug("Received int attribute")
 			err := rc.AddEmptyInt64Attr(intA.Name, intA.Known)
 			if err != nil {
 				return nil, err
 			}
+		case *pb.CredAttribute_BlobAttr:
+			blobA := u.BlobAttr.Attr
+			if err := rc.AddEmptyBlobAttr(blobA.Name, blobA.Known); err != nil {
+				return nil, err
+			}
+		case *pb.CredAttribute_DateAttr:
+			dateA := u.DateAttr.Attr
+			if err := rc.AddEmptyDateAttr(dateA.Name, dateA.Known); err != nil {
+				return nil, err
+			}
+		case *pb.CredAttribute_BoolAttr:
+			boolA := u.BoolAttr.Attr
+			if err := rc.AddEmptyBoolAttr(boolA.Name, boolA.Known); err != nil {
+				return nil, err
+			}
+		case *pb.CredAttribute_EnumAttr:
+			enumA := u.EnumAttr.Attr
+			err := rc.AddEmptyEnumAttr(enumA.Name, u.EnumAttr.Values, enumA.Known)
+			if err != nil {
+				return nil, err
+			}
+		case *pb.CredAttribute_BytesAttr:
+			bytesA := u.BytesAttr.Attr
+			err := rc.AddEmptyBytesAttr(bytesA.Name, int(u.BytesAttr.Size), bytesA.Known)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported type of attribute %v", a)
 		}
 	}
 
 	return rc, nil
 }
 
-func (c *CLClient) GetAcceptableCreds() (map[string][]string, error) {
-	creds, err := c.grpcClient.GetAcceptableCredentials(context.Background(), &empty.Empty{})
+func (c *CLClient) GetAcceptableCreds(ctx context.Context) (map[string][]string, error) {
+	creds, err := c.acceptableCreds(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve acceptable credentials info: %v", err)
+		return nil, err
 	}
 
 	accCreds := make(map[string][]string)
@@ -95,8 +170,44 @@ func (c *CLClient) GetAcceptableCreds() (map[string][]string, error) {
 	return accCreds, nil
 }
 
-func (c *CLClient) IssueCredential(credManager *cl.CredManager, regKey string) (*cl.Cred, error) {
-	if err := c.openStream(c.grpcClient, "IssueCredential"); err != nil {
+// GetPolicies returns policies of the server, one of which proofs of credentials
+// need to satisfy (see ProveCredentialForPolicy). Predicates of policies hold
+// internal values of attributes. Servers without policies describe acceptable
+// credentials only by the attributes to be revealed (see GetAcceptableCreds).
+func (c *CLClient) GetPolicies(ctx context.Context) ([]*cl.Policy, error) {
+	creds, err := c.acceptableCreds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]*cl.Policy, len(creds.Creds))
+	for i, cred := range creds.Creds {
+		if policies[i], err = cred.GetNativeType(); err != nil {
+			return nil, invalidResponse(err)
+		}
+	}
+	return policies, nil
+}
+
+// acceptableCreds retrieves descriptions of credentials accepted by the server.
+func (c *CLClient) acceptableCreds(ctx context.Context) (*pb.AcceptableCreds, error) {
+	var creds *pb.AcceptableCreds
+	var err error
+	ctx = c.withTenant(ctx)
+	if i, ok := c.invoker(); ok {
+		creds = new(pb.AcceptableCreds)
+		err = i.Invoke(ctx, "/proto.CL/GetAcceptableCredentials", &empty.Empty{}, creds)
+	} else {
+		creds, err = c.grpcClient.GetAcceptableCredentials(ctx, &empty.Empty{})
+	}
+	if err != nil {
+		return nil, wrapError("unable to retrieve acceptable credentials info", err)
+	}
+	return creds, nil
+}
+
+func (c *CLClient) IssueCredential(ctx context.Context, credManager *cl.CredManager, regKey string) (*cl.Cred, error) {
+	if err := c.openStream(ctx, c.grpcClient, "IssueCredential"); err != nil {
 		return nil, err
 	}
 	defer c.closeStream()
@@ -119,13 +230,22 @@ func (c *CLClient) IssueCredential(credManager *cl.CredManager, regKey string) (
 
 	credIssueNonceOrg := new(big.Int).SetBytes(resp.GetBigint().X1)
 
+	var devReg *pb.WebAuthnRegistration
+	if c.authenticator != nil {
+		if devReg, err = c.registerDevice(credManager, credIssueNonceOrg); err != nil {
+			return nil, err
+		}
+	}
+
 	credReq, err := credManager.GetCredRequest(credIssueNonceOrg)
 	if err != nil {
 		return nil, err
 	}
 
+	pbCredReq := pb.ToPbCredRequest(credReq)
+	pbCredReq.DeviceRegistration = devReg
 	credReqMsg := &pb.Message{
-		Content: &pb.Message_CLCredReq{pb.ToPbCredRequest(credReq)},
+		Content: &pb.Message_CLCredReq{pbCredReq},
 	}
 	resp, err = c.getResponseTo(credReqMsg)
 	if err != nil {
@@ -150,13 +270,120 @@ func (c *CLClient) IssueCredential(credManager *cl.CredManager, regKey string) (
 	return nil, fmt.Errorf("credential not valid")
 }
 
-func (c *CLClient) UpdateCredential(credManager *cl.CredManager, rawCred *cl.RawCred) (*cl.Cred,
+// BatchCredRequest is a request for a credential of a batch issued with
+// IssueCredentialBatch.
+type BatchCredRequest struct {
+	CredManager *cl.CredManager
+	RegKey      string
+}
+
+// BatchCredResult holds the credential issued for a request of a batch, or the
+// error that occurred when issuing it.
+type BatchCredResult struct {
+	Cred *cl.Cred
+	Err  error
+}
+
+// IssueCredentialBatch obtains credentials for reqs over a single stream. The server
+// issues credentials for several requests at once and results correspond to reqs by
+// index. Failures of single requests are reported in their results, while err is
+// returned only when the batch as a whole fails.
+// Batch issuance is only available over gRPC.
+func (c *CLClient) IssueCredentialBatch(ctx context.Context, reqs []*BatchCredRequest) (
+	[]*BatchCredResult, error) {
+	if err := c.openStream(ctx, c.grpcClient, "IssueCredentialBatch"); err != nil {
+		return nil, err
+	}
+	defer c.closeStream()
+
+	resp, err := c.getResponseTo(&pb.Message{ClientId: c.id})
+	if err != nil {
+		return nil, err
+	}
+	nonce := new(big.Int).SetBytes(resp.GetBigint().X1)
+
+	results := make([]*BatchCredResult, len(reqs))
+	var items []*pb.Message
+	for i, r := range reqs {
+		credReq, err := r.CredManager.GetCredRequest(nonce)
+		if err != nil {
+			results[i] = &BatchCredResult{Err: err}
+			continue
+		}
+		items = append(items, &pb.Message{
+			Content: &pb.Message_CLCredBatchItem{
+				CLCredBatchItem: &pb.CLCredBatchItem{
+					Id:      int64(i),
+					RegKey:  r.RegKey,
+					CredReq: pb.ToPbCredRequest(credReq),
+				},
+			},
+		})
+	}
+
+	// requests are sent while results are received, so that neither side blocks
+	sendErr := make(chan error, 1)
+	go func() {
+		for _, item := range items {
+			if err := c.send(item); err != nil {
+				sendErr <- err
+				return
+			}
+		}
+		sendErr <- c.CloseSend()
+	}()
+
+	for range items {
+		resp, err := c.receive()
+		if err != nil {
+			return nil, err
+		}
+		r := resp.GetCLCredBatchResult()
+		if r == nil || r.Id < 0 || r.Id >= int64(len(reqs)) || results[r.Id] != nil {
+			return nil, fmt.Errorf("[client %v] unexpected batch result", c.id)
+		}
+		results[r.Id] = batchCredResult(reqs[r.Id].CredManager, r)
+	}
+	if err := <-sendErr; err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// batchCredResult verifies the credential of result r, obtained for the request
+// of credManager.
+func batchCredResult(credManager *cl.CredManager, r *pb.CLCredBatchResult) *BatchCredResult {
+	if r.Error != "" {
+		return &BatchCredResult{Err: fmt.Errorf("%s", r.Error)}
+	}
+	if r.Credential == nil {
+		return &BatchCredResult{Err: fmt.Errorf("no credential")}
+	}
+	credential, AProof, err := r.Credential.GetNativeType()
+	if err != nil {
+		return &BatchCredResult{Err: err}
+	}
+	userVerified, err := credManager.Verify(credential, AProof)
+	if err != nil {
+		return &BatchCredResult{Err: err}
+	}
+	if !userVerified {
+		return &BatchCredResult{Err: fmt.Errorf("credential not valid")}
+	}
+
+	return &BatchCredResult{Cred: credential}
+}
+
+func (c *CLClient) UpdateCredential(ctx context.Context, credManager *cl.CredManager, rawCred *cl.RawCred) (*cl.Cred,
 	error) {
-	// refresh credManager with new credential values, works only for known attributes
-	credManager.Update(rawCred)
-	newKnownAttrs := rawCred.GetKnownVals()
+	// refresh credManager with new credential values, committing afresh to committed
+	// attributes that changed
+	if err := credManager.Update(rawCred); err != nil {
+		return nil, err
+	}
 
-	if err := c.openStream(c.grpcClient, "UpdateCredential"); err != nil {
+	if err := c.openStream(ctx, c.grpcClient, "UpdateCredential"); err != nil {
 		return nil, err
 	}
 	defer c.closeStream()
@@ -164,7 +391,7 @@ func (c *CLClient) UpdateCredential(credManager *cl.CredManager, rawCred *cl.Raw
 	initMsg := &pb.Message{
 		ClientId: c.id,
 		Content: &pb.Message_UpdateClCredential{
-			pb.ToPbUpdateCLCredential(credManager.Nym, credManager.CredReqNonce, newKnownAttrs),
+			pb.ToPbCLCredUpdate(credManager.GetCredUpdate()),
 		},
 	}
 
@@ -193,10 +420,214 @@ func (c *CLClient) UpdateCredential(credManager *cl.CredManager, rawCred *cl.Raw
 
 // ProveCredential proves the possession of a valid credential and reveals only the attributes the user desires
 // to reveal.
-func (c *CLClient) ProveCredential(credManager *cl.CredManager, cred *cl.Cred,
+func (c *CLClient) ProveCredential(ctx context.Context, credManager *cl.CredManager, cred *cl.Cred,
 	revealedAttrs []string) (*string, error) {
+	return c.ProveCredentialWithRanges(ctx, credManager, cred, revealedAttrs, nil)
+}
+
+// AttrRange is a range [Min, Max] that a committed int64 attribute is proved to lie in,
+// without revealing its value. The proof is built with Backend, or with the default
+// backend for commitments of attributes when it is empty (see
+// cl.CredManager.BuildAttrRangeProofWithBackend).
+type AttrRange struct {
+	Attr    string
+	Min     int64
+	Max     int64
+	Backend cl.RangeProofBackend
+}
+
+// ProveCredentialWithRanges proves possession of cred like ProveCredential, along with
+// proofs that committed int64 attributes lie in the given ranges (for example that
+// the age is at least 18). Commitments of these attributes are revealed to the server.
+func (c *CLClient) ProveCredentialWithRanges(ctx context.Context, credManager *cl.CredManager,
+	cred *cl.Cred, revealedAttrs []string, ranges []AttrRange) (*string, error) {
+	return c.proveCredential(ctx, credManager, cred, revealedAttrs, ranges, nil)
+}
+
+// ProveCredentialWithPredicates proves possession of cred, showing predicates about
+// its attributes (see cl.Predicate). Predicates are compiled into revealed attributes
+// and range proofs, and sent to the server, which checks them against the proof.
+func (c *CLClient) ProveCredentialWithPredicates(ctx context.Context,
+	credManager *cl.CredManager, cred *cl.Cred, preds []*cl.Predicate) (*string, error) {
+	return c.provePredicates(ctx, credManager, cred, preds, "")
+}
+
+// ProveCredentialForPolicy proves possession of cred, showing what policy p of the
+// server (see GetPolicies) requires: its predicates and revealed attributes, with
+// range proofs of the backend it requires.
+func (c *CLClient) ProveCredentialForPolicy(ctx context.Context,
+	credManager *cl.CredManager, cred *cl.Cred, p *cl.Policy) (*string, error) {
+	return c.provePredicates(ctx, credManager, cred, p.AllPredicates(), p.RangeProofs)
+}
+
+// provePredicates proves possession of cred like ProveCredentialWithPredicates, with
+// range proofs of the given backend.
+func (c *CLClient) provePredicates(ctx context.Context, credManager *cl.CredManager,
+	cred *cl.Cred, preds []*cl.Predicate, backend cl.RangeProofBackend) (*string, error) {
+	if err := cl.ResolvePredicates(credManager.RawCred, preds); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var revealedAttrs []string
+	var ranges []AttrRange
+	for _, p := range preds {
+		a, _ := credManager.RawCred.GetAttr(p.Attr)
+		switch {
+		case p.Type == cl.PredicateHidden:
+		case a.IsKnown():
+			if !containsString(revealedAttrs, p.Attr) {
+				revealedAttrs = append(revealedAttrs, p.Attr)
+			}
+		default:
+			min, max := cl.PredicateRange(p)
+			ranges = append(ranges, AttrRange{Attr: p.Attr, Min: min.Int64(), Max: max.Int64(),
+				Backend: backend})
+		}
+	}
+	for _, p := range preds {
+		if p.Type == cl.PredicateHidden && containsString(revealedAttrs, p.Attr) {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"attribute %s is both hidden and revealed", p.Attr)
+		}
+	}
+
+	return c.proveCredential(ctx, credManager, cred, revealedAttrs, ranges, preds)
+}
+
+// proveCredential runs the protocol proving possession of cred, revealing
+// revealedAttrs, proving ranges and sending preds to the server.
+func (c *CLClient) proveCredential(ctx context.Context, credManager *cl.CredManager,
+	cred *cl.Cred, revealedAttrs []string, ranges []AttrRange,
+	preds []*cl.Predicate) (*string, error) {
+	revealed, err := c.revealedIndices(credManager, revealedAttrs, ranges)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.openStream(ctx, c.grpcClient, "ProveCredential"); err != nil {
+		return nil, err
+	}
+	defer c.closeStream()
+
+	initMsg := &pb.Message{
+		ClientId: c.id,
+	}
+
+	resp, err := c.getResponseTo(initMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := new(big.Int).SetBytes(resp.GetBigint().X1)
+	pbProof, err := c.buildProof(ctx, credManager, cred, revealed, ranges, preds, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	proveMsg := &pb.Message{
+		Content: &pb.Message_ProveClCredential{pbProof},
+	}
+	resp, err = c.getResponseTo(proveMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	sessKey := resp.GetSessionKey().Value
+	c.idToken = resp.GetSessionKey().IdToken
+	return &sessKey, nil
+}
+
+// BuildProofNI builds a non-interactive proof of possession of cred, revealing
+// revealedAttrs and proving ranges like ProveCredentialWithRanges. The proof is bound
+// to a new context instead of a nonce issued by the server, so it can be verified
+// without interaction, with ProveCredentialNI or, in its protobuf encoding, by the
+// HTTP/JSON gateway of the server. The context expires after the nonce TTL of the
+// server, and a proof is accepted only once.
+func (c *CLClient) BuildProofNI(ctx context.Context, credManager *cl.CredManager,
+	cred *cl.Cred, revealedAttrs []string, ranges []AttrRange) (*pb.ProveCLCredentialNI, error) {
+	revealed, err := c.revealedIndices(credManager, revealedAttrs, ranges)
+	if err != nil {
+		return nil, err
+	}
+
+	niContext := pb.NewNIContext()
+	nonce := cl.ContextNonce(credManager.Params, niContext.Value(pb.ProveCredentialNIMethod))
+	pbProof, err := c.buildProof(ctx, credManager, cred, revealed, ranges, nil, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ProveCLCredentialNI{
+		Proof:   pbProof,
+		Context: niContext,
+	}, nil
+}
+
+// ProveCredentialNI proves possession of cred like ProveCredentialWithRanges, with a
+// non-interactive proof (see BuildProofNI) that the server verifies in a single RPC.
+func (c *CLClient) ProveCredentialNI(ctx context.Context, credManager *cl.CredManager,
+	cred *cl.Cred, revealedAttrs []string, ranges []AttrRange) (*string, error) {
+	proof, err := c.BuildProofNI(ctx, credManager, cred, revealedAttrs, ranges)
+	if err != nil {
+		return nil, err
+	}
+
+	var key *pb.SessionKey
+	ctx = c.withTenant(ctx)
+	if i, ok := c.invoker(); ok {
+		key = new(pb.SessionKey)
+		err = i.Invoke(ctx, pb.ProveCredentialNIMethod, proof, key)
+	} else {
+		key, err = c.grpcClient.ProveCredentialNI(ctx, proof)
+	}
+	if err != nil {
+		return nil, wrapError("unable to prove credential", err)
+	}
+
+	c.idToken = key.IdToken
+	return &key.Value, nil
+}
+
+// revealedAttrIndices holds internal indices of attributes revealed in a proof of a
+// credential, and of attributes proved to lie in ranges.
+type revealedAttrIndices struct {
+	known     []int
+	committed []int
+	ranges    []int
+}
+
+// revealedIndices returns indices of revealedAttrs and of attributes in ranges. Besides
+// revealedAttrs, the binding attribute of the device and the expiration of the
+// credential are revealed.
+func (c *CLClient) revealedIndices(credManager *cl.CredManager, revealedAttrs []string,
+	ranges []AttrRange) (*revealedAttrIndices, error) {
 	var revealedKnownAttrsIndices []int
 	var revealedCommitmentsOfAttrsIndices []int
+	rangeIndices := make([]int, len(ranges))
+	for i, r := range ranges {
+		attr, err := credManager.RawCred.GetAttr(r.Attr)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"unexpected attribute: %s", r.Attr)
+		}
+		if _, ok := attr.(*cl.Int64Attr); !ok || attr.IsKnown() {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"attribute %s is not a committed int64 attribute", r.Attr)
+		}
+		if rangeIndices[i], err = credManager.RawCred.GetAttrInternalIndex(r.Attr); err != nil {
+			return nil, err
+		}
+		revealedCommitmentsOfAttrsIndices = append(revealedCommitmentsOfAttrsIndices,
+			rangeIndices[i])
+	}
+
+	if c.authenticator != nil && !containsString(revealedAttrs, c.bindingAttr) {
+		revealedAttrs = append(revealedAttrs, c.bindingAttr)
+	}
+	// the server needs to see that the credential has not expired
+	if credManager.RawCred.HasExpiration() && !containsString(revealedAttrs, cl.ExpirationAttr) {
+		revealedAttrs = append(revealedAttrs, cl.ExpirationAttr)
+	}
 
 	for _, a := range revealedAttrs {
 		attr, err := credManager.RawCred.GetAttr(a)
@@ -210,45 +641,141 @@ func (c *CLClient) ProveCredential(credManager *cl.CredManager, cred *cl.Cred,
 		}
 		if attr.IsKnown() {
 			revealedKnownAttrsIndices = append(revealedKnownAttrsIndices, ind)
-		} else {
+		} else if !common.Contains(revealedCommitmentsOfAttrsIndices, ind) {
 			revealedCommitmentsOfAttrsIndices = append(revealedCommitmentsOfAttrsIndices, ind)
 		}
 	}
+	// revealed attributes are sent in the order of their indices
+	sort.Ints(revealedKnownAttrsIndices)
+	sort.Ints(revealedCommitmentsOfAttrsIndices)
+
+	return &revealedAttrIndices{
+		known:     revealedKnownAttrsIndices,
+		committed: revealedCommitmentsOfAttrsIndices,
+		ranges:    rangeIndices,
+	}, nil
+}
 
-	if err := c.openStream(c.grpcClient, "ProveCredential"); err != nil {
-		return nil, err
+// buildProof builds the proof of possession of cred with the given nonce, revealing
+// attributes and proving ranges given by revealed, and holding preds.
+func (c *CLClient) buildProof(ctx context.Context, credManager *cl.CredManager,
+	cred *cl.Cred, revealed *revealedAttrIndices, ranges []AttrRange, preds []*cl.Predicate,
+	nonce *big.Int) (*pb.ProveCLCredential, error) {
+	revealedKnownAttrsIndices := revealed.known
+	revealedCommitmentsOfAttrsIndices := revealed.committed
+	rangeIndices := revealed.ranges
+
+	randCred, proof, err := credManager.BuildProof(cred, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("error when building credential proof: %v", err)
 	}
-	defer c.closeStream()
 
-	initMsg := &pb.Message{
-		ClientId: c.id,
+	filteredKnownAttrs, filteredCommitmentsOfAttrs := credManager.FilterAttributes(revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices)
+
+	pbProof := pb.ToPbProveCLCredential(randCred.A, proof, filteredKnownAttrs,
+		filteredCommitmentsOfAttrs, revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices)
+	// the server verifies the proof with the key the credential was issued under
+	pbProof.KeyID = cred.KeyID
+	for i, r := range ranges {
+		proof, err := credManager.BuildAttrRangeProofWithBackend(r.Backend, rangeIndices[i],
+			big.NewInt(r.Min), big.NewInt(r.Max), nonce)
+		if err != nil {
+			return nil, fmt.Errorf("error when building range proof of %s: %v", r.Attr, err)
+		}
+		pbProof.RangeProofs = append(pbProof.RangeProofs, pb.ToPbCLRangeProof(proof))
+	}
+	for _, p := range preds {
+		pbProof.Predicates = append(pbProof.Predicates, pb.ToPbCLPredicate(p))
+	}
+	if cred.Witness != nil {
+		if pbProof.NonRevocationProof, err = c.proveNonRevocation(ctx, credManager, cred,
+			randCred, revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices,
+			nonce); err != nil {
+			return nil, err
+		}
+	}
+	if c.authenticator != nil {
+		assertion, err := c.authenticator.GetAssertion(webauthn.Challenge(nonce, randCred.A))
+		if err != nil {
+			return nil, fmt.Errorf("error when obtaining device assertion: %v", err)
+		}
+		pbProof.DeviceAssertion = pb.ToPbWebAuthnAssertion(assertion)
 	}
 
-	resp, err := c.getResponseTo(initMsg)
+	return pbProof, nil
+}
+
+// proveNonRevocation updates the witness of non-revocation of cred to the current
+// accumulator of the server, and builds a non-revocation proof for the randomized
+// credential randCred.
+func (c *CLClient) proveNonRevocation(ctx context.Context, credManager *cl.CredManager,
+	cred, randCred *cl.Cred, revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices []int,
+	nonce *big.Int) (*pb.NonRevocationProof, error) {
+	version := &pb.AccumulatorVersion{
+		Version: int32(cred.Witness.Version),
+	}
+	var update *pb.AccumulatorUpdate
+	var err error
+	if i, ok := c.invoker(); ok {
+		update = new(pb.AccumulatorUpdate)
+		err = i.Invoke(ctx, "/proto.Revocation/GetAccumulatorUpdate", version, update)
+	} else {
+		update, err = c.revocation.GetAccumulatorUpdate(ctx, version)
+	}
+	if err != nil {
+		return nil, wrapError("unable to retrieve accumulator update", err)
+	}
+	acc, revoked, err := update.GetNativeType()
 	if err != nil {
 		return nil, err
 	}
+	if err := cred.Witness.Update(cred.E, acc, revoked); err != nil {
+		return nil, err
+	}
 
-	nonce := new(big.Int).SetBytes(resp.GetBigint().X1)
-
-	randCred, proof, err := credManager.BuildProof(cred, revealedKnownAttrsIndices,
-		revealedCommitmentsOfAttrsIndices, nonce)
+	proof, err := credManager.BuildNonRevocationProof(randCred, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, cred.Witness, acc, nonce)
 	if err != nil {
-		return nil, fmt.Errorf("error when building credential proof: %v", err)
+		return nil, fmt.Errorf("error when building non-revocation proof: %v", err)
 	}
+	return pb.ToPbNonRevocationProof(proof), nil
+}
 
-	filteredKnownAttrs, filteredCommitmentsOfAttrs := credManager.FilterAttributes(revealedKnownAttrsIndices,
-		revealedCommitmentsOfAttrsIndices)
-
-	proveMsg := &pb.Message{
-		Content: &pb.Message_ProveClCredential{pb.ToPbProveCLCredential(randCred.A, proof, filteredKnownAttrs,
-			filteredCommitmentsOfAttrs, revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices)},
+// registerDevice creates a new credential of the authenticator of c, with a challenge
+// of the issuance session with the given nonce, and sets the binding attribute of
+// the credential managed by credManager to its binding.
+func (c *CLClient) registerDevice(credManager *cl.CredManager,
+	nonce *big.Int) (*pb.WebAuthnRegistration, error) {
+	attr, err := credManager.RawCred.GetAttr(c.bindingAttr)
+	if err != nil {
+		return nil, err
 	}
-	resp, err = c.getResponseTo(proveMsg)
+	if _, ok := attr.(*cl.StrAttr); !ok || !attr.IsKnown() {
+		return nil, fmt.Errorf("device binding attribute %s is not a known string attribute",
+			c.bindingAttr)
+	}
+
+	r, err := c.authenticator.MakeCredential(webauthn.Challenge(nonce, credManager.Nym))
 	if err != nil {
+		return nil, fmt.Errorf("error when registering device: %v", err)
+	}
+	if err := attr.UpdateValue(webauthn.Binding(r.CredentialID)); err != nil {
+		return nil, err
+	}
+	if err := credManager.Update(credManager.RawCred); err != nil {
 		return nil, err
 	}
 
-	sessKey := resp.GetSessionKey().Value
-	return &sessKey, nil
+	return pb.ToPbWebAuthnRegistration(r), nil
+}
+
+func containsString(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+)
+
+func TestIssueCredentialBatch(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{data: []string{"batchKey1", "batchKey2", "batchKey3", "batchKey4"}},
+		cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+	srv.SetBatchIssuanceConcurrency(2)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	defer srv.Teardown()
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	conn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client, err := NewCLClient(conn)
+	require.NoError(t, err)
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	newRequest := func(regKey, name string) *BatchCredRequest {
+		rc, err := client.GetCredentialStructure(context.Background())
+		require.NoError(t, err)
+		for n, val := range map[string]interface{}{
+			"Name":      name,
+			"Gender":    "F",
+			"Graduated": "true",
+			"DateMin":   1512643000,
+			"DateMax":   1592643000,
+			"Age":       30,
+		} {
+			a, err := rc.GetAttr(n)
+			require.NoError(t, err)
+			require.NoError(t, a.UpdateValue(val))
+		}
+		cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+			pubKey.GenerateUserMasterSecret(), rc)
+		require.NoError(t, err)
+		return &BatchCredRequest{CredManager: cm, RegKey: regKey}
+	}
+
+	reqs := []*BatchCredRequest{
+		newRequest("batchKey1", "Alice"),
+		newRequest("batchKey2", "Ann"),
+		newRequest("unknownKey", "Amy"),
+		newRequest("batchKey3", "Ada"),
+		newRequest("batchKey1", "Ava"), // the key was already used in this batch
+	}
+	results, err := client.IssueCredentialBatch(context.Background(), reqs)
+	require.NoError(t, err)
+	require.Len(t, results, len(reqs))
+	for i, r := range results {
+		if i == 2 || i == 4 {
+			assert.Error(t, r.Err)
+			assert.Nil(t, r.Cred)
+			continue
+		}
+		require.NoError(t, r.Err)
+		_, err = client.ProveCredential(context.Background(), reqs[i].CredManager, r.Cred,
+			[]string{"Gender"})
+		assert.NoError(t, err)
+	}
+
+	// the remaining key can be used in another batch
+	results, err = client.IssueCredentialBatch(context.Background(),
+		[]*BatchCredRequest{newRequest("batchKey4", "Abby")})
+	require.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+}
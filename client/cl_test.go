@@ -18,11 +18,17 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
 	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/df"
 )
 
 // TestCL requires a running server.
@@ -36,7 +42,7 @@ func TestCL(t *testing.T) {
 		t.Errorf("Error when initializing NewCLClient")
 	}
 
-	rc, err := client.GetCredentialStructure()
+	rc, err := client.GetCredentialStructure(context.Background())
 	if err != nil {
 		t.Errorf("error when retrieving credential structure: %v", err)
 	}
@@ -67,10 +73,20 @@ func TestCL(t *testing.T) {
 	cm, err := cl.NewCredManager(params, pubKey, masterSecret, rc)
 	require.NoError(t, err)
 
+	// the credential is issued for a commitment to the age that the holder already holds,
+	// without the server learning the age
+	ageCommitter := df.NewCommitter(pubKey.N1, pubKey.G, pubKey.H, pubKey.N1, params.SecParam)
+	ageCommitment, err := ageCommitter.GetCommitMsg(age.InternalValue())
+	require.NoError(t, err)
+	_, ageR := ageCommitter.GetDecommitMsg()
+	c, err := cm.CommitAttr("Age", ageR)
+	require.NoError(t, err)
+	assert.Equal(t, ageCommitment, c)
+
 	credManagerPath := "../client/testdata/credManager.gob"
 	cl.WriteGob(credManagerPath, cm)
 
-	cred, err := client.IssueCredential(cm, "testRegKey5")
+	cred, err := client.IssueCredential(context.Background(), cm, "testRegKey5")
 	require.NoError(t, err)
 
 	// create new CredManager (updating or proving usually does not happen at the same time
@@ -78,26 +94,126 @@ func TestCL(t *testing.T) {
 	cl.ReadGob(credManagerPath, cm)
 	require.NoError(t, err)
 
-	acceptableCreds, err := client.GetAcceptableCreds()
+	acceptableCreds, err := client.GetAcceptableCreds(context.Background())
 	require.NoError(t, err)
 	revealedAttrs := acceptableCreds["org1"] // FIXME
 
 	//revealedAttrs = []string{"Name", "Gender"}
-	sessKey, err := client.ProveCredential(cm, cred, revealedAttrs)
+	sessKey, err := client.ProveCredential(context.Background(), cm, cred, revealedAttrs)
 	require.NoError(t, err)
 	assert.NotNil(t, sessKey, "possesion of a credential proof failed")
 
+	// revealed attributes are available to relying parties through the OIDC bridge
+	tokens, err := testOIDCProvider.Exchange(*sessKey, "testClient")
+	require.NoError(t, err)
+	claims, err := testOIDCProvider.UserInfo(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "Jack", claims["Name"])
+
+	// committed attributes can be proved to lie in a range without being revealed
+	sessKey, err = client.ProveCredentialWithRanges(context.Background(), cm, cred,
+		revealedAttrs, []AttrRange{{Attr: "Age", Min: 18, Max: 150}})
+	require.NoError(t, err)
+	tokens, err = testOIDCProvider.Exchange(*sessKey, "testClient")
+	require.NoError(t, err)
+	claims, err = testOIDCProvider.UserInfo(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"min": json.Number("18"), "max": json.Number("150")}, claims["Age"])
+	_, err = client.ProveCredentialWithRanges(context.Background(), cm, cred, revealedAttrs,
+		[]AttrRange{{Attr: "Age", Min: 60, Max: 150}})
+	assert.Error(t, err)
+	_, err = client.ProveCredentialWithRanges(context.Background(), cm, cred, revealedAttrs,
+		[]AttrRange{{Attr: "DateMin", Min: 0, Max: 1600000000}})
+	assert.Error(t, err, "range of a known attribute was proved")
+
+	// predicates requested by a policy of the server are compiled into revealed
+	// attributes and range proofs
+	config.Default().Set("policies", map[string]interface{}{
+		"predicates": map[string]interface{}{
+			"predicates": []string{"Age greater_than 17", "Gender equal M",
+				"Name in_set Jack,Jim", "DateMin hidden"},
+		},
+	})
+	sessKey, err = client.ProveCredentialWithPredicates(context.Background(), cm, cred,
+		[]*cl.Predicate{
+			cl.GreaterThan("Age", 17),
+			cl.Equal("Gender", "M"),
+			cl.InSet("Name", "Jack", "Jim"),
+			cl.Hidden("DateMin"),
+		})
+	require.NoError(t, err)
+	tokens, err = testOIDCProvider.Exchange(*sessKey, "testClient")
+	require.NoError(t, err)
+	claims, err = testOIDCProvider.UserInfo(tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "M", claims["Gender"])
+	assert.Nil(t, claims["DateMin"])
+	assert.Equal(t, json.Number("18"), claims["Age"].(map[string]interface{})["min"])
+	_, err = client.ProveCredentialWithPredicates(context.Background(), cm, cred,
+		[]*cl.Predicate{cl.InSet("Name", "Jim", "John")})
+	assert.Error(t, err)
+	_, err = client.ProveCredentialWithPredicates(context.Background(), cm, cred,
+		[]*cl.Predicate{cl.Revealed("Name"), cl.Hidden("Name")})
+	assert.Error(t, err)
+	// predicates, and ranges of their proofs, need to be those requested
+	_, err = client.ProveCredentialWithPredicates(context.Background(), cm, cred,
+		[]*cl.Predicate{
+			cl.GreaterThan("Age", 10),
+			cl.Equal("Gender", "M"),
+			cl.InSet("Name", "Jack", "Jim"),
+			cl.Hidden("DateMin"),
+		})
+	assert.True(t, errors.Is(err, ErrPolicyNotSatisfied), "unexpected error %v", err)
+	config.Default().Set("policies", map[string]interface{}{})
+
 	// modify some attributes and get updated credential
 	name, err = rc.GetAttr("Name")
 	err = name.UpdateValue("Jim")
 	assert.NoError(t, err)
 
-	cred1, err := client.UpdateCredential(cm, rc)
+	cred1, err := client.UpdateCredential(context.Background(), cm, rc)
 	require.NoError(t, err)
 
-	sessKey, err = client.ProveCredential(cm, cred1, revealedAttrs)
+	sessKey, err = client.ProveCredential(context.Background(), cm, cred1, revealedAttrs)
 	require.NoError(t, err)
 	assert.NotNil(t, sessKey,
 		"possesion of an updated credential proof failed")
 
 }
+
+// TestCLCancelled checks that protocols are aborted when their context is cancelled,
+// without using up the registration key.
+func TestCLCancelled(t *testing.T) {
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+
+	client, err := NewCLClient(testGrpcClientConn)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.GetCredentialStructure(ctx)
+	assert.Error(t, err)
+
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for _, a := range rc.GetAttrs() {
+		switch a.(type) {
+		case *cl.StrAttr:
+			require.NoError(t, a.UpdateValue("x"))
+		case *cl.Int64Attr:
+			require.NoError(t, a.UpdateValue(1))
+		}
+	}
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+
+	_, err = client.IssueCredential(ctx, cm, "testRegKey9")
+	assert.Error(t, err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, err = client.IssueCredential(ctx, cm, "testRegKey9")
+	assert.NoError(t, err)
+}
@@ -19,17 +19,29 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"math/rand"
+	"net"
+	"net/http"
+	"strings"
 	"time"
 
 	"reflect"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/xlab-si/emmy/log"
 	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/record"
+	"github.com/xlab-si/emmy/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // registers the gzip compressor
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
 )
 
 var logger log.Logger
@@ -56,12 +68,41 @@ func SetLogger(lgr log.Logger) {
 
 // ConnectionConfig holds all the details required for establishing a connection to the server.
 type ConnectionConfig struct {
-	Endpoint           string // Server's Endpoint
+	Endpoint           string // Server's Endpoint, or unix:<path> for a UNIX domain socket
 	ServerNameOverride string // When ServerNameOverride != "",
 	// server cert's CN will be compared with the provided ServerNameOverride instead of server's
 	// hostname
 	CACertificate []byte // CA certificate for validating the server
 	TimeoutMillis int    // timeout (in millis) for establishing initial connection with the server
+	// ClientCertificate and ClientKey hold the client's certificate and private key in PEM
+	// format, presented to servers that require clients to authenticate with certificates
+	// (see server.WithClientCAs). When nil, the client does not present a certificate.
+	ClientCertificate []byte
+	ClientKey         []byte
+	// KeepaliveMillis is the interval (in millis) after which the client pings the
+	// server when there is no activity on the connection, so that broken connections
+	// are detected. KeepaliveTimeoutMillis is how long it waits for the server to
+	// respond to a ping before closing the connection (20s when 0). Keepalive pings are
+	// disabled when KeepaliveMillis is 0.
+	KeepaliveMillis        int
+	KeepaliveTimeoutMillis int
+	// MaxReconnectDelayMillis bounds the exponential backoff (in millis) between
+	// attempts to reestablish a broken connection (120s when 0). Protocols that were
+	// running when the connection broke fail with a *RetryableError.
+	MaxReconnectDelayMillis int
+	// Insecure makes the client connect without TLS, to servers created with
+	// server.WithInsecure for development and tests.
+	Insecure bool
+	// RootCAs, when set, is used instead of CACertificate and the host system's
+	// certificate pool to validate the server's certificate.
+	RootCAs *x509.CertPool
+	// Compression is the name of the compressor applied to messages sent to the
+	// server, such as "gzip". Messages are not compressed when it is empty.
+	Compression string
+	// ProxyURL is the URL of a SOCKS5 (socks5://host:port) or HTTP (http://host:port)
+	// proxy through which the client connects to the server, optionally with user
+	// credentials. The client connects directly when it is empty.
+	ProxyURL string
 }
 
 func NewConnectionConfig(endpoint, serverNameOverride string, certificate []byte,
@@ -74,22 +115,40 @@ func NewConnectionConfig(endpoint, serverNameOverride string, certificate []byte
 	}
 }
 
-// GetConnection attempts to return a connection to a gRPC server based on the provided
-// configuration of connection details. Note that several clients can be passed the same
-// connection, as the gRPC framework is able to multiplex several RPCs on the same connection,
-// thus reducing the overhead.
-func GetConnection(connConfig *ConnectionConfig) (*grpc.ClientConn, error) {
+// NewConnection attempts to return a connection to a gRPC server based on the provided
+// configuration of connection details, which covers TLS, compression, keepalive and
+// proxies. Note that several clients can be passed the same connection, as the gRPC
+// framework is able to multiplex several RPCs on the same connection, thus reducing the
+// overhead.
+func NewConnection(connConfig *ConnectionConfig) (*grpc.ClientConn, error) {
 	logger.Info("Getting the connection")
 
+	if connConfig.Insecure {
+		logger.Warning("######## Connecting without TLS ########")
+		return dial(connConfig, grpc.WithInsecure())
+	}
+
 	var creds credentials.TransportCredentials
 	var err error
 
+	var clientCerts []tls.Certificate
+	if connConfig.ClientCertificate != nil {
+		cert, err := tls.X509KeyPair(connConfig.ClientCertificate, connConfig.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate or key: %s", err)
+		}
+		clientCerts = append(clientCerts, cert)
+	}
+
 	// If the client doesn't explicitly provide a CA certificate, build TLS credentials with
 	// the hosts' system certificate pool
-	if connConfig.CACertificate == nil {
+	if connConfig.RootCAs != nil {
+		creds = newClientTLSCredentials(connConfig.RootCAs, connConfig.ServerNameOverride,
+			clientCerts)
+	} else if connConfig.CACertificate == nil {
 		logger.Warning("######## No CA certificate was provided ########")
 		logger.Warning("Host system's certificate pool will be used to validate the server")
-		creds, err = getTLSCredentialsFromSysCertPool()
+		creds, err = getTLSCredentialsFromSysCertPool(clientCerts)
 		if err != nil {
 			return nil, fmt.Errorf("error creating TLS client credentials: %s", err)
 		}
@@ -101,17 +160,66 @@ func GetConnection(connConfig *ConnectionConfig) (*grpc.ClientConn, error) {
 			logger.Warningf("Expecting to find '%s' in the server certificate's CN",
 				connConfig.ServerNameOverride)
 		}
-		creds, err = getTLSCredentials(connConfig.CACertificate, connConfig.ServerNameOverride)
+		creds, err = getTLSCredentials(connConfig.CACertificate, connConfig.ServerNameOverride,
+			clientCerts)
 		if err != nil {
 			return nil, fmt.Errorf("error creating TLS client credentials: %s", err)
 		}
 	}
+	return dial(connConfig, grpc.WithTransportCredentials(creds))
+}
+
+// GetConnection returns a connection to a gRPC server, like NewConnection.
+//
+// Deprecated: use NewConnection.
+func GetConnection(connConfig *ConnectionConfig) (*grpc.ClientConn, error) {
+	return NewConnection(connConfig)
+}
+
+// dial connects to the server as configured by connConfig, securing the connection
+// as given by security.
+func dial(connConfig *ConnectionConfig, security grpc.DialOption) (*grpc.ClientConn, error) {
 	dialOptions := []grpc.DialOption{
-		grpc.WithTransportCredentials(creds),
+		security,
 		grpc.WithBlock(),
 		grpc.WithTimeout(time.Duration(connConfig.TimeoutMillis) * time.Millisecond),
 	}
-	conn, err := grpc.Dial(connConfig.Endpoint, dialOptions...)
+	if connConfig.KeepaliveMillis > 0 {
+		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                time.Duration(connConfig.KeepaliveMillis) * time.Millisecond,
+			Timeout:             time.Duration(connConfig.KeepaliveTimeoutMillis) * time.Millisecond,
+			PermitWithoutStream: true,
+		}))
+	}
+	if connConfig.MaxReconnectDelayMillis > 0 {
+		dialOptions = append(dialOptions, grpc.WithBackoffMaxDelay(
+			time.Duration(connConfig.MaxReconnectDelayMillis)*time.Millisecond))
+	}
+	if connConfig.Compression != "" {
+		if encoding.GetCompressor(connConfig.Compression) == nil {
+			return nil, fmt.Errorf("unsupported compression %s", connConfig.Compression)
+		}
+		dialOptions = append(dialOptions, grpc.WithDefaultCallOptions(
+			grpc.UseCompressor(connConfig.Compression)))
+	}
+	endpoint := connConfig.Endpoint
+	if strings.HasPrefix(endpoint, unixEndpointPrefix) {
+		if connConfig.ProxyURL != "" {
+			return nil, fmt.Errorf("cannot connect to a UNIX domain socket through a proxy")
+		}
+		endpoint = strings.TrimPrefix(endpoint, unixEndpointPrefix)
+		dialOptions = append(dialOptions, grpc.WithDialer(
+			func(addr string, timeout time.Duration) (net.Conn, error) {
+				return net.DialTimeout("unix", addr, timeout)
+			}))
+	} else if connConfig.ProxyURL != "" {
+		dialer, err := proxyDialer(connConfig.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		dialOptions = append(dialOptions, grpc.WithDialer(dialer))
+	}
+	conn, err := grpc.Dial(endpoint, dialOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("could not connect to server %v (%v)", connConfig.Endpoint, err)
 	}
@@ -119,9 +227,111 @@ func GetConnection(connConfig *ConnectionConfig) (*grpc.ClientConn, error) {
 	return conn, nil
 }
 
+// unixEndpointPrefix precedes paths of UNIX domain sockets in endpoints of servers.
+const unixEndpointPrefix = "unix:"
+
+// DialInsecure returns a connection without TLS to the server at endpoint, which needs
+// to be created with server.WithInsecure. It is meant for development and tests.
+func DialInsecure(endpoint string, timeoutMillis int) (*grpc.ClientConn, error) {
+	return NewConnection(&ConnectionConfig{
+		Endpoint:      endpoint,
+		TimeoutMillis: timeoutMillis,
+		Insecure:      true,
+	})
+}
+
+// DialUnix returns a connection without TLS to the server listening on the UNIX domain
+// socket at socketPath (see server.Server.StartUnix).
+func DialUnix(socketPath string, timeoutMillis int) (*grpc.ClientConn, error) {
+	return DialInsecure(unixEndpointPrefix+socketPath, timeoutMillis)
+}
+
+// StreamOpener opens streams of emmy protocols over a transport other than a direct
+// gRPC connection to the server (for example didcomm.Client). method is the name of
+// the protocol's stream, such as IssueCredential.
+type StreamOpener interface {
+	OpenStream(ctx context.Context, method string) (pb.ClientStream, error)
+}
+
+// unaryInvoker is implemented by stream openers that can also call unary RPC methods
+// of the server, such as GrpcWebConn. Clients make unary calls with it instead of
+// their gRPC connection, which may be nil then.
+type unaryInvoker interface {
+	Invoke(ctx context.Context, method string, in, out proto.Message) error
+}
+
 type genericClient struct {
 	id int32
 	pb.ClientStream
+	opener    StreamOpener
+	recordDir string
+	trace     *tracing.ClientStream
+	retry     *RetryPolicy
+	tenant    string
+	idToken   string // ID token issued along with the last session key
+	version   int32  // version of protocol messages negotiated for the current stream
+}
+
+// UseStreamOpener makes the client open streams of emmy protocols with o instead of
+// its gRPC connection.
+func (c *genericClient) UseStreamOpener(o StreamOpener) {
+	c.opener = o
+}
+
+// RecordSessions makes the client record sessions of protocols it runs and save
+// them to dir, to be replayed with record.Replay. Recording is disabled when dir
+// is empty.
+func (c *genericClient) RecordSessions(dir string) {
+	c.recordDir = dir
+}
+
+// UseRetryPolicy makes the client retry opening streams of protocols as configured by
+// p while the server cannot be reached (for example while the connection to it is
+// being reestablished). Protocols that fail after their stream was opened are not
+// retried, as the server aborts them; they return a *RetryableError instead, and can
+// be run again with Retry.
+func (c *genericClient) UseRetryPolicy(p RetryPolicy) {
+	c.retry = &p
+}
+
+// UseTenant makes the client run protocols with tenant id of the server, an issuer or
+// verifier with its own keys and credentials, instead of the default one. The tenant
+// is passed in gRPC metadata, or in a header with connections over HTTP.
+func (c *genericClient) UseTenant(id string) {
+	c.tenant = id
+}
+
+// IDToken returns the ID token the server issued along with the session key obtained
+// by the last proof the client ran, for services relying on the OpenID Connect bridge
+// of the server. It is empty when the server does not issue ID tokens directly.
+func (c *genericClient) IDToken() string {
+	return c.idToken
+}
+
+// withTenant returns ctx carrying the tenant of the client in its outgoing metadata.
+func (c *genericClient) withTenant(ctx context.Context) context.Context {
+	if c.tenant == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, pb.TenantMetadataKey, c.tenant)
+}
+
+// setTenantHeader sets the header of HTTP request h to the tenant in the outgoing
+// metadata of ctx, if any.
+func setTenantHeader(ctx context.Context, h http.Header) {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		return
+	}
+	if v := md[pb.TenantMetadataKey]; len(v) > 0 {
+		h.Set(pb.TenantMetadataKey, v[0])
+	}
+}
+
+// invoker returns the stream opener of the client if it can call unary RPC methods.
+func (c *genericClient) invoker() (unaryInvoker, bool) {
+	i, ok := c.opener.(unaryInvoker)
+	return i, ok
 }
 
 func newGenericClient() genericClient {
@@ -137,8 +347,20 @@ func newGenericClient() genericClient {
 }
 
 func (c *genericClient) send(msg *pb.Message) error {
+	// the server is given the highest version the client supports, until it responds
+	// with the negotiated version
+	msg.ProtocolVersion = pb.ProtocolVersion
+	if c.version != 0 {
+		msg.ProtocolVersion = c.version
+	}
 	if err := c.Send(msg); err != nil {
-		return fmt.Errorf("[client %v] Error sending message: %v", c.id, err)
+		// the reason of a broken stream is returned by Recv
+		if err == io.EOF {
+			if _, recvErr := c.Recv(); recvErr != nil && recvErr != io.EOF {
+				err = recvErr
+			}
+		}
+		return c.streamError("Error sending message", err)
 	}
 	logger.Infof("[client %v] Successfully sent request of type %T", c.id, msg.Content)
 	logger.Debugf("%+v", msg)
@@ -151,7 +373,10 @@ func (c *genericClient) receive() (*pb.Message, error) {
 	if err == io.EOF {
 		return nil, fmt.Errorf("[client %v] EOF error", c.id)
 	} else if err != nil {
-		return nil, fmt.Errorf("[client %v] An error occurred: %v", c.id, err)
+		return nil, c.streamError("An error occurred", err)
+	}
+	if err := c.negotiateVersion(resp.ProtocolVersion); err != nil {
+		return nil, err
 	}
 
 	logger.Infof("[client %v] Received response of type %T from the genericClient", c.id, resp.Content)
@@ -160,6 +385,27 @@ func (c *genericClient) receive() (*pb.Message, error) {
 	return resp, nil
 }
 
+// negotiateVersion sets the version of protocol messages of the current stream to
+// version v, given by the server in its response, after checking that the client
+// supports it and that the server did not change it during the protocol.
+func (c *genericClient) negotiateVersion(v int32) error {
+	if !pb.IsSupportedProtocolVersion(v) {
+		return fmt.Errorf("[client %v] %w", c.id, &ProtocolError{
+			Code: pb.ErrorCode_UNSUPPORTED_PROTOCOL_VERSION,
+			Message: fmt.Sprintf("protocol version %d of the server is not supported, "+
+				"the client supports versions %v", v, pb.ProtocolVersions()),
+		})
+	}
+	if c.version != 0 && v != 0 && v != c.version {
+		return invalidResponse(fmt.Errorf("protocol version changed from %d to %d",
+			c.version, v))
+	}
+	if v != 0 {
+		c.version = v
+	}
+	return nil
+}
+
 // getResponseTo sends a message msg to emmy server and retrieves the server's response.
 func (c *genericClient) getResponseTo(msg *pb.Message) (*pb.Message, error) {
 	if err := c.send(msg); err != nil {
@@ -170,32 +416,72 @@ func (c *genericClient) getResponseTo(msg *pb.Message) (*pb.Message, error) {
 
 // openStream is a generic function for opening a pb.ClientStream.
 // A pb.ClientStream is generated as a result of the function call of the form
-// stream, err := grpcClient.streamGenFunc(context.Background()). As we have different
+// stream, err := grpcClient.streamGenFunc(ctx), so the stream is aborted when ctx
+// is cancelled or its deadline passes. As we have different
 // grpcClients (each generated from its own RPC service), each has its own streamGenFunc(s)
 // (generated from the appropriate RPC within the service), it is the caller's responsibility
 // to provide appropriate grpcClient and streamGenFunc.
+// The protocol execution is traced in a span named streamGenFunc, whose trace context
+// is propagated to the server (see package tracing). Opening the stream is retried
+// according to the retry policy of the client (see UseRetryPolicy).
 // This function has to be called explicitly at the beginning of the protocol execution function.
-func (c *genericClient) openStream(grpcClient interface{}, streamGenFunc string) error {
+func (c *genericClient) openStream(ctx context.Context, grpcClient interface{},
+	streamGenFunc string) error {
+	ctx, span := tracing.StartClient(c.withTenant(ctx), streamGenFunc)
+	var stream pb.ClientStream
+	open := func() error {
+		var err error
+		stream, err = c.newStream(ctx, grpcClient, streamGenFunc)
+		return err
+	}
+
+	var err error
+	if c.retry != nil {
+		err = Retry(ctx, *c.retry, open)
+	} else {
+		err = open()
+	}
+	if err != nil {
+		tracing.End(span, err)
+		return err
+	}
+
+	// assign this client stream to our generic client, so that the stream can be
+	// used for communication with the server in subsequent send(), receive() calls;
+	// the version of its messages is negotiated with the first of them
+	c.setStream(ctx, stream, streamGenFunc)
+	c.version = 0
+	return nil
+}
+
+// newStream opens a stream of streamGenFunc with the stream opener of the client, or
+// calls streamGenFunc of grpcClient (see openStream).
+func (c *genericClient) newStream(ctx context.Context, grpcClient interface{},
+	streamGenFunc string) (pb.ClientStream, error) {
+	if c.opener != nil {
+		stream, err := c.opener.OpenStream(ctx, streamGenFunc)
+		if err != nil {
+			return nil, c.streamError("Error opening stream", err)
+		}
+		return stream, nil
+	}
+
 	// Create structs compatible with reflect package
-	client := reflect.ValueOf(grpcClient)                            // we want to call streamGenFunc on this struct
-	params := []reflect.Value{reflect.ValueOf(context.Background())} // we want to pass these params to streamGenFunc
+	client := reflect.ValueOf(grpcClient)           // we want to call streamGenFunc on this struct
+	params := []reflect.Value{reflect.ValueOf(ctx)} // we want to pass these params to streamGenFunc
 
 	// Safety check for existence of the requested stream generation method on a given grpc client
 	f := client.MethodByName(streamGenFunc)
 	if !f.IsValid() {
-		return fmt.Errorf("stream generation function '%s' not defined for %v", streamGenFunc, reflect.TypeOf(grpcClient))
+		return nil, fmt.Errorf("stream generation function '%s' not defined for %v", streamGenFunc, reflect.TypeOf(grpcClient))
 	}
 
 	// Call the client stream generation function
 	res := f.Call(params)
 
 	// First, check if an error occurred during creation of the client stream
-	var err error
 	if v := res[1].Interface(); v != nil {
-		err = v.(error)
-	}
-	if err != nil {
-		return fmt.Errorf("[client %v] Error opening stream: %v", c.id, err)
+		return nil, c.streamError("Error opening stream", v.(error))
 	}
 
 	// creation of the client stream was successful, make type assertion
@@ -203,11 +489,19 @@ func (c *genericClient) openStream(grpcClient interface{}, streamGenFunc string)
 	if v := res[0].Interface(); v != nil {
 		stream = v.(pb.ClientStream)
 	}
+	return stream, nil
+}
 
-	// assign this client stream to our generic client, so that the stream can be
-	// used for communication with the server in subsequent send(), receive() calls
+// setStream sets the stream the client communicates over, tracing rounds of the
+// protocol in children of the span in ctx and recording its session if recording
+// is enabled.
+func (c *genericClient) setStream(ctx context.Context, stream pb.ClientStream, method string) {
+	c.trace = tracing.NewClientStream(ctx, stream, method)
+	stream = c.trace
+	if c.recordDir != "" {
+		stream = record.NewClientStream(stream, record.NewRecorder(method, record.Client))
+	}
 	c.ClientStream = stream
-	return nil
 }
 
 // closeStream closes the gRPC communication genericClient with the server, indicating the end of
@@ -216,6 +510,17 @@ func (c *genericClient) openStream(grpcClient interface{}, streamGenFunc string)
 // Note that closing the genericClient does not closeStream the corresponding connection to the server,
 // as it should be done externally.
 func (c *genericClient) closeStream() error {
+	if c.trace != nil {
+		c.trace.End(nil)
+		c.trace = nil
+	}
+	if rs, ok := c.ClientStream.(*record.ClientStream); ok {
+		if file, err := rs.Recorder.Save(c.recordDir); err != nil {
+			logger.Warningf("[client %v] Cannot save session: %v", c.id, err)
+		} else {
+			logger.Debugf("[client %v] Saved session to %s", c.id, file)
+		}
+	}
 	if err := c.CloseSend(); err != nil {
 		return fmt.Errorf("[client %v] Error closing genericClient: %v", c.id, err)
 	}
@@ -18,7 +18,12 @@
 package client
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"fmt"
+	"net/http"
 	"os"
 	"testing"
 
@@ -30,8 +35,10 @@ import (
 
 	"github.com/go-redis/redis"
 	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/config"
 	"github.com/xlab-si/emmy/crypto/cl"
 	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/oidc"
 	"github.com/xlab-si/emmy/server"
 	"google.golang.org/grpc"
 )
@@ -41,6 +48,15 @@ var testGrpcServerEndpoint = "localhost:7008"
 // testGrpcClientConn is re-used for all the test clients
 var testGrpcClientConn *grpc.ClientConn
 
+// testGrpcWebHandler serves the test server to gRPC-Web clients
+var testGrpcWebHandler http.Handler
+
+// testGatewayHandler serves the test server to HTTP/JSON clients
+var testGatewayHandler http.Handler
+
+// testOIDCProvider issues tokens for users authenticated by the test server
+var testOIDCProvider *oidc.Provider
+
 var testRedis = flag.Bool(
 	"db",
 	false,
@@ -58,8 +74,18 @@ var testRedis = flag.Bool(
 func TestMain(m *testing.M) {
 	flag.Parse()
 
+	// use the fixture keys in testdata instead of the configuration file
+	testConfig, err := config.NewTestConfig("testdata")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	config.Default().Swap(testConfig)
+
 	var regKeyDB server.RegistrationManager
-	testRegKeys := []string{"testRegKey1", "testRegKey2", "testRegKey3", "testRegKey4", "testRegKey5"}
+	testRegKeys := []string{"testRegKey1", "testRegKey2", "testRegKey3", "testRegKey4", "testRegKey5",
+		"testRegKey6", "testRegKey7", "testRegKey8", "testRegKey9", "testRegKey10", "testRegKey11",
+		"testRegKey12"}
 
 	var recDB cl.ReceiverRecordManager
 
@@ -96,18 +122,24 @@ func TestMain(m *testing.M) {
 	}
 
 	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
-	server, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
 		regKeyDB, recDB, logger)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
+	oidcKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	testOIDCProvider, _ = oidc.NewProvider("https://localhost:8882", oidcKey, time.Minute)
+	srv.EnableOIDC(testOIDCProvider)
+	testGrpcWebHandler = server.NewGrpcWebHandler(srv, nil)
+	testGatewayHandler = server.NewGateway(srv, "")
+
 	// Configure a custom logger for the client package
 	clientLogger, _ := log.NewStdoutLogger("client", log.NOTICE, log.FORMAT_SHORT)
 	SetLogger(clientLogger)
 
-	go server.Start(7008)
+	go srv.Start(7008)
 
 	// Establish a connection to previously started server
 	testCert, err := ioutil.ReadFile("testdata/server.pem")
@@ -126,7 +158,7 @@ func TestMain(m *testing.M) {
 	returnCode := m.Run()
 
 	// Cleanup - close connection, stop the server and exit
-	server.Teardown()
+	srv.Teardown()
 	testGrpcClientConn.Close()
 	os.Exit(returnCode)
 }
@@ -140,7 +172,7 @@ func TestInvalidStreamGenerationFunction(t *testing.T) {
 	c, _ := NewPseudonymsysCAClient(testGrpcClientConn, nil)
 	// This is otherwise called implicitly at the beginning of any client's function
 	// for running a given cryptographic protocol
-	res := c.openStream(c.grpcClient, "InvalidFunc")
+	res := c.openStream(context.Background(), c.grpcClient, "InvalidFunc")
 	assert.NotNil(t, res, "stream generation function is invalid, error should be produced")
 }
 
@@ -175,6 +207,12 @@ func (m *mockRegKeyDB) insert(keys ...string) {
 	}
 }
 
+// AddRegistrationKey inserts registration key key.
+func (m *mockRegKeyDB) AddRegistrationKey(key string) error {
+	m.insert(key)
+	return nil
+}
+
 // CheckRegistrationKey checks for the presence of registration
 // key key, removing it and returning success if it was present.
 // If the key is not present in the slice, it returns false.
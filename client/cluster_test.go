@@ -0,0 +1,146 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/server"
+	"google.golang.org/grpc"
+)
+
+// roundRobinSteps calls Step of the servers of a cluster in turn, as a load balancer
+// without session affinity would.
+type roundRobinSteps struct {
+	servers []pb.StepsClient
+	next    int
+}
+
+func (r *roundRobinSteps) Step(ctx context.Context, in *pb.ProtocolStep,
+	opts ...grpc.CallOption) (*pb.ProtocolStep, error) {
+	c := r.servers[r.next%len(r.servers)]
+	r.next++
+	return c.Step(ctx, in, opts...)
+}
+
+// insertOnlySessionStore is a session store that rejects sessions under keys that are
+// already taken, as tables of SQL databases with a primary key would.
+type insertOnlySessionStore struct {
+	*server.MemSessionStore
+}
+
+func (s *insertOnlySessionStore) Put(key string, session *server.Session) error {
+	if _, err := s.Get(key); err == nil {
+		return fmt.Errorf("duplicate key %s", key)
+	}
+	return s.MemSessionStore.Put(key, session)
+}
+
+// TestCluster issues and proves a credential with steps of the protocols handled by
+// different servers of a cluster.
+func TestCluster(t *testing.T) {
+	for name, sessions := range map[string]server.SessionStore{
+		"mem":         server.NewMemSessionStore(),
+		"insert only": &insertOnlySessionStore{server.NewMemSessionStore()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			testCluster(t, sessions, "clusterKey "+name)
+		})
+	}
+}
+
+func testCluster(t *testing.T, sessions server.SessionStore, regKey string) {
+	db := &mockRegKeyDB{data: []string{regKey}}
+	nonces := server.NewMemNonceStore()
+	var servers []*server.Server
+	var conns []*grpc.ClientConn
+	steps := new(roundRobinSteps)
+	for i := 0; i < 2; i++ {
+		srv, conn := newTestServer(t, db)
+		defer conn.Close()
+		conns = append(conns, conn)
+		assert.Error(t, srv.UseCluster(), "cluster without a session store")
+		srv.UseSessionStore(sessions, time.Minute)
+		srv.UseNonceStore(nonces, time.Minute)
+		require.NoError(t, srv.UseCluster())
+		servers = append(servers, srv)
+		steps.servers = append(steps.servers, pb.NewStepsClient(conn))
+	}
+
+	client, err := NewCLClient(conns[0])
+	require.NoError(t, err)
+	client.UseStreamOpener(&StepConn{client: steps})
+	sessKey := proveTestCredential(t, client, regKey, []string{"Gender"})
+
+	// sessions are shared as well
+	for _, srv := range servers {
+		session, err := srv.ValidateSession(sessKey)
+		require.NoError(t, err)
+		assert.Equal(t, "M", session.Claims["Gender"])
+	}
+	// steps of unknown runs are rejected
+	_, err = steps.Step(context.Background(), &pb.ProtocolStep{
+		Method:  "ProveCredential",
+		Session: "unknown",
+		Message: &pb.Message{},
+	})
+	assert.Error(t, err)
+}
+
+// TestClusterRateLimit starts more protocol runs kept in the session store than
+// allowed by the rate limit of the server.
+func TestClusterRateLimit(t *testing.T) {
+	config.Default().Set("network.limits.rate", 0.001)
+	config.Default().Set("network.limits.burst", 1)
+	srv, conn := newTestServer(t, &mockRegKeyDB{})
+	config.Default().Set("network.limits.rate", 0)
+	config.Default().Set("network.limits.burst", 0)
+	defer conn.Close()
+	srv.UseSessionStore(server.NewMemSessionStore(), time.Minute)
+	require.NoError(t, srv.UseCluster())
+
+	steps := pb.NewStepsClient(conn)
+	resp, err := steps.Step(context.Background(), &pb.ProtocolStep{
+		Method:  "ProveCredential",
+		Message: &pb.Message{ClientId: 1},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.Session)
+
+	// later rounds of a run are not counted as new runs
+	_, err = steps.Step(context.Background(), &pb.ProtocolStep{
+		Method:  "ProveCredential",
+		Session: resp.Session,
+		Message: &pb.Message{},
+	})
+	assert.False(t, errors.Is(toProtocolError(err), ErrRateLimited), "unexpected error %v", err)
+
+	_, err = steps.Step(context.Background(), &pb.ProtocolStep{
+		Method:  "ProveCredential",
+		Message: &pb.Message{ClientId: 1},
+	})
+	assert.True(t, errors.Is(toProtocolError(err), ErrRateLimited), "unexpected error %v", err)
+}
@@ -49,7 +49,7 @@ type Connection struct {
 func NewConnection(cfg *ConnectionConfig) (*Connection, error) {
 	connCfg := client.NewConnectionConfig(cfg.Endpoint, cfg.ServerNameOverride,
 		cfg.CACertificate, cfg.TimeoutMillis)
-	conn, err := client.GetConnection(connCfg)
+	conn, err := client.NewConnection(connCfg)
 	if err != nil {
 		return nil, err
 	}
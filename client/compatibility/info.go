@@ -18,6 +18,7 @@
 package compatibility
 
 import (
+	"context"
 	"github.com/xlab-si/emmy/client"
 )
 
@@ -31,7 +32,7 @@ type ServiceInfo struct {
 // GetServiceInfo contacts emmy server to retrieve basic information about its secure service
 // offering.
 func GetServiceInfo(conn *Connection) (*ServiceInfo, error) {
-	serviceInfo, err := client.GetServiceInfo(conn.ClientConn)
+	serviceInfo, err := client.GetServiceInfo(context.Background(), conn.ClientConn)
 	if err != nil {
 		return nil, err
 	}
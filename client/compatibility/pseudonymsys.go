@@ -18,6 +18,7 @@
 package compatibility
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -174,7 +175,7 @@ func (c *PseudonymsysClient) GenerateNym(userSecret string,
 	}
 
 	// Call PseudonymsysClient client with translated parameters
-	nym, err := c.PseudonymsysClient.GenerateNym(secret, certificate, regKey)
+	nym, err := c.PseudonymsysClient.GenerateNym(context.Background(), secret, certificate, regKey)
 	if err != nil {
 		return nil, err
 	}
@@ -206,7 +207,7 @@ func (c *PseudonymsysClient) ObtainCredential(userSecret string,
 	}
 
 	// Call PseudonymsysClient client with translated parameters
-	credential, err := c.PseudonymsysClient.ObtainCredential(secret, pseudonym, pubKey)
+	credential, err := c.PseudonymsysClient.ObtainCredential(context.Background(), secret, pseudonym, pubKey)
 	if err != nil {
 		return nil, err
 	}
@@ -253,7 +254,7 @@ func (c *PseudonymsysClient) TransferCredential(orgName, userSecret string,
 	}
 
 	// Call PseudonymsysClient client with translated parameters
-	sessionKey, err := c.PseudonymsysClient.TransferCredential(orgName, secret, pseudonym,
+	sessionKey, err := c.PseudonymsysClient.TransferCredential(context.Background(), orgName, secret, pseudonym,
 		credential)
 	if err != nil {
 		return "", err
@@ -18,6 +18,7 @@
 package compatibility
 
 import (
+	"context"
 	"math/big"
 
 	"fmt"
@@ -133,7 +134,7 @@ func (c *PseudonymsysCAClient) GenerateCertificate(userSecret string,
 	}
 
 	// Call PseudonymsysCAClient client with translated parameters
-	cert, err := c.PseudonymsysCAClient.GenerateCertificate(secret, pseudonym)
+	cert, err := c.PseudonymsysCAClient.GenerateCertificate(context.Background(), secret, pseudonym)
 	if err != nil {
 		return nil, err
 	}
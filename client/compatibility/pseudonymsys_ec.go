@@ -18,6 +18,7 @@
 package compatibility
 
 import (
+	"context"
 	"math/big"
 
 	"fmt"
@@ -191,7 +192,7 @@ func (c *PseudonymsysClientEC) GenerateNym(userSecret string,
 	}
 
 	// Call PseudonymsysClientEC client with translated parameters
-	nym, err := c.PseudonymsysClientEC.GenerateNym(secret, certificate, regKey)
+	nym, err := c.PseudonymsysClientEC.GenerateNym(context.Background(), secret, certificate, regKey)
 	if err != nil {
 		return nil, err
 	}
@@ -223,7 +224,7 @@ func (c *PseudonymsysClientEC) ObtainCredential(userSecret string,
 	}
 
 	// Call PseudonymsysClientEC client with translated parameters
-	credential, err := c.PseudonymsysClientEC.ObtainCredential(secret, pseudonym, pubKey)
+	credential, err := c.PseudonymsysClientEC.ObtainCredential(context.Background(), secret, pseudonym, pubKey)
 	if err != nil {
 		return nil, err
 	}
@@ -284,7 +285,7 @@ func (c *PseudonymsysClientEC) TransferCredential(orgName, userSecret string,
 	}
 
 	// Call PseudonymsysClientEC client with translated parameters
-	sessionKey, err := c.PseudonymsysClientEC.TransferCredential(orgName, secret, pseudonym,
+	sessionKey, err := c.PseudonymsysClientEC.TransferCredential(context.Background(), orgName, secret, pseudonym,
 		credential)
 	if err != nil {
 		return "", err
@@ -18,6 +18,7 @@
 package compatibility
 
 import (
+	"context"
 	"math/big"
 
 	"fmt"
@@ -180,7 +181,7 @@ func (c *PseudonymsysCAClientEC) GenerateCertificate(userSecret string,
 	}
 
 	// Call PseudonymsysCAClientEC client with translated parameters
-	cert, err := c.PseudonymsysCAClientEC.GenerateCertificate(secret, pseudonym)
+	cert, err := c.PseudonymsysCAClientEC.GenerateCertificate(context.Background(), secret, pseudonym)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewConnection connects to the test server with gzip compression, validating its
+// certificate with a certificate pool.
+func TestNewConnection(t *testing.T) {
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(testCert))
+
+	conn, err := NewConnection(&ConnectionConfig{
+		Endpoint:      testGrpcServerEndpoint,
+		RootCAs:       pool,
+		TimeoutMillis: 500,
+		Compression:   "gzip",
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = GetServiceInfo(context.Background(), conn)
+	assert.NoError(t, err)
+
+	_, err = NewConnection(&ConnectionConfig{
+		Endpoint:      testGrpcServerEndpoint,
+		RootCAs:       pool,
+		TimeoutMillis: 500,
+		Compression:   "lz4",
+	})
+	assert.Error(t, err)
+}
+
+// TestNewConnectionProxy connects to the test server through an HTTP proxy that
+// requires authentication.
+func TestNewConnectionProxy(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	var tunnels int32
+	go http.Serve(listener, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:secret"))
+		if r.Method != http.MethodConnect || r.Header.Get("Proxy-Authorization") != auth {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			target.Close()
+			return
+		}
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		atomic.AddInt32(&tunnels, 1)
+		go func() {
+			io.Copy(target, conn)
+			target.Close()
+		}()
+		io.Copy(conn, target)
+		conn.Close()
+	}))
+
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	connCfg := NewConnectionConfig(testGrpcServerEndpoint, "", testCert, 500)
+	connCfg.ProxyURL = "http://user:secret@" + listener.Addr().String()
+	conn, err := NewConnection(connCfg)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = GetServiceInfo(context.Background(), conn)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&tunnels))
+
+	connCfg.ProxyURL = "http://user:wrong@" + listener.Addr().String()
+	_, err = NewConnection(connCfg)
+	assert.Error(t, err)
+
+	connCfg.ProxyURL = "ftp://" + listener.Addr().String()
+	_, err = NewConnection(connCfg)
+	assert.Error(t, err)
+}
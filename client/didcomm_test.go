@@ -0,0 +1,101 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/did"
+	"github.com/xlab-si/emmy/didcomm"
+)
+
+// TestCLOverDIDComm requires a running server.
+func TestCLOverDIDComm(t *testing.T) {
+	bridgeKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	bridge, err := didcomm.NewBridge(bridgeKey, testGrpcClientConn, did.KeyResolver{})
+	require.NoError(t, err)
+
+	httpEndpoint := httptest.NewServer(bridge)
+	defer httpEndpoint.Close()
+	wsEndpoint := httptest.NewServer(bridge.WebSocketHandler())
+	defer wsEndpoint.Close()
+
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// issue the credential over HTTP
+	dc, err := didcomm.NewClient(holderKey, bridge.DID(), did.KeyResolver{},
+		&didcomm.HTTPTransport{URL: httpEndpoint.URL})
+	require.NoError(t, err)
+	client, err := NewCLClient(testGrpcClientConn)
+	require.NoError(t, err)
+	client.UseStreamOpener(dc)
+
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+
+	cred, err := client.IssueCredential(context.Background(), cm, "testRegKey6")
+	require.NoError(t, err)
+
+	// registration keys are single-use, errors of the protocol are reported to the holder
+	_, err = client.IssueCredential(context.Background(), cm, "testRegKey6")
+	assert.Error(t, err)
+
+	// prove possession of the credential over WebSocket
+	ws, err := didcomm.DialWebSocket("ws"+strings.TrimPrefix(wsEndpoint.URL, "http"),
+		wsEndpoint.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+	dc, err = didcomm.NewClient(holderKey, bridge.DID(), did.KeyResolver{}, ws)
+	require.NoError(t, err)
+	client.UseStreamOpener(dc)
+
+	acceptableCreds, err := client.GetAcceptableCreds(context.Background())
+	require.NoError(t, err)
+	sessKey, err := client.ProveCredential(context.Background(), cm, cred, acceptableCreds["org1"])
+	require.NoError(t, err)
+	assert.NotNil(t, sessKey)
+}
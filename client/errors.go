@@ -0,0 +1,100 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"fmt"
+
+	pb "github.com/xlab-si/emmy/proto"
+)
+
+// ProtocolError is a failure of a protocol reported by the server, with the cause of
+// the failure given by Code. Errors returned by clients wrap it, so callers can
+// branch on the cause with errors.Is and the errors below:
+//
+//	if errors.Is(err, client.ErrRevoked) {
+//		// obtain a new credential
+//	}
+//
+// or obtain it with errors.As.
+type ProtocolError struct {
+	Code    pb.ErrorCode
+	Message string
+}
+
+func (e *ProtocolError) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is a *ProtocolError with the same Code as e.
+func (e *ProtocolError) Is(target error) bool {
+	t, ok := target.(*ProtocolError)
+	return ok && t.Code == e.Code
+}
+
+// Protocol errors by their cause, to be compared with errors.Is.
+var (
+	ErrInvalidProof        = &ProtocolError{pb.ErrorCode_INVALID_PROOF, "invalid proof"}
+	ErrExpiredNonce        = &ProtocolError{pb.ErrorCode_EXPIRED_NONCE, "expired nonce"}
+	ErrUnknownOrg          = &ProtocolError{pb.ErrorCode_UNKNOWN_ORG, "unknown organization"}
+	ErrRevoked             = &ProtocolError{pb.ErrorCode_REVOKED, "credential revoked"}
+	ErrInvalidRegKey       = &ProtocolError{pb.ErrorCode_INVALID_REG_KEY, "invalid registration key"}
+	ErrDeviceAuthFailed    = &ProtocolError{pb.ErrorCode_DEVICE_AUTH_FAILED, "device authentication failed"}
+	ErrInvalidRequest      = &ProtocolError{pb.ErrorCode_INVALID_REQUEST, "invalid request"}
+	ErrInternal            = &ProtocolError{pb.ErrorCode_INTERNAL, "internal server error"}
+	ErrUnknownSchema       = &ProtocolError{pb.ErrorCode_UNKNOWN_SCHEMA, "unknown credential schema"}
+	ErrCredExpired         = &ProtocolError{pb.ErrorCode_EXPIRED_CREDENTIAL, "credential expired"}
+	ErrUnknownTenant       = &ProtocolError{pb.ErrorCode_UNKNOWN_TENANT, "unknown tenant"}
+	ErrUnsupportedProfile  = &ProtocolError{pb.ErrorCode_UNSUPPORTED_PROFILE, "unsupported parameter profile"}
+	ErrRateLimited         = &ProtocolError{pb.ErrorCode_RATE_LIMITED, "rate limited"}
+	ErrUnknownKey          = &ProtocolError{pb.ErrorCode_UNKNOWN_KEY, "unknown key of the issuer"}
+	ErrInvalidGroupElement = &ProtocolError{pb.ErrorCode_INVALID_GROUP_ELEMENT, "invalid group element"}
+	ErrPolicyNotSatisfied  = &ProtocolError{pb.ErrorCode_POLICY_NOT_SATISFIED, "no policy satisfied"}
+	ErrInvalidSession      = &ProtocolError{pb.ErrorCode_INVALID_SESSION, "invalid session"}
+	ErrUnsupportedVersion  = &ProtocolError{pb.ErrorCode_UNSUPPORTED_PROTOCOL_VERSION, "unsupported protocol version"}
+)
+
+// toProtocolError returns err as a *ProtocolError if the server gave the cause of
+// err, and err otherwise.
+func toProtocolError(err error) error {
+	if e := pb.ToProtocolError(err); e != nil {
+		return &ProtocolError{
+			Code:    e.Code,
+			Message: e.Message,
+		}
+	}
+	return err
+}
+
+// wrapError returns err prefixed with desc. Causes of protocol errors given by the
+// server are kept as *ProtocolError in the chain of the returned error, and the
+// error is a *RetryableError if err is transient.
+func wrapError(desc string, err error) error {
+	e := fmt.Errorf("%s: %w", desc, toProtocolError(err))
+	if isTransient(err) {
+		return &RetryableError{Err: e}
+	}
+	return e
+}
+
+// invalidResponse returns err, caused by an invalid value in a response of the
+// server (see pb.Decoder), prefixed so that it is told apart from errors reported
+// by the server.
+func invalidResponse(err error) error {
+	return fmt.Errorf("invalid response of the server: %w", err)
+}
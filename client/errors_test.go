@@ -0,0 +1,67 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	pb "github.com/xlab-si/emmy/proto"
+)
+
+func TestProtocolErrors(t *testing.T) {
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+	caClient, err := NewPseudonymsysCAClient(testGrpcClientConn, group)
+	require.NoError(t, err)
+	c, err := NewPseudonymsysClient(testGrpcClientConn, group)
+	require.NoError(t, err)
+
+	secret := c.GenerateMasterKey()
+	cert, err := caClient.GenerateCertificate(context.Background(), secret,
+		caClient.GenerateMasterNym(secret))
+	require.NoError(t, err)
+
+	_, err = c.GenerateNym(context.Background(), secret, cert, "invalidRegKey")
+	assert.True(t, errors.Is(err, ErrInvalidRegKey), "unexpected error %v", err)
+	assert.False(t, errors.Is(err, ErrInvalidProof))
+	var protocolErr *ProtocolError
+	require.True(t, errors.As(err, &protocolErr))
+	assert.Equal(t, pb.ErrorCode_INVALID_REG_KEY, protocolErr.Code)
+	assert.Equal(t, "registration key verification failed", protocolErr.Message)
+
+	c.UseOrg("unknownOrg")
+	_, err = c.ObtainCredential(context.Background(), secret,
+		caClient.GenerateMasterNym(secret), pseudsys.NewPubKey(big.NewInt(1), big.NewInt(1)))
+	assert.True(t, errors.Is(err, ErrUnknownOrg), "unexpected error %v", err)
+
+	// causes are passed over gRPC-Web as well
+	endpoint := httptest.NewServer(testGrpcWebHandler)
+	defer endpoint.Close()
+	c.UseOrg("")
+	c.UseStreamOpener(NewGrpcWebConn(endpoint.URL, nil))
+	_, err = c.GenerateNym(context.Background(), secret, cert, "invalidRegKey")
+	assert.True(t, errors.Is(err, ErrInvalidRegKey), "unexpected error %v", err)
+}
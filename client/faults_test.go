@@ -0,0 +1,78 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+)
+
+func TestInjectedFaults(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("faultyServer", log.ERROR, log.FORMAT_SHORT)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		server.NewMemRegistrationManager("faultyRegKey"), cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+	delay := 50 * time.Millisecond
+	srv.InjectFaults(&config.FaultsConfig{
+		Delay:            delay,
+		DelayRate:        1,
+		StorageErrorRate: 1,
+	})
+
+	endpoint := httptest.NewServer(server.NewGrpcWebHandler(srv, nil))
+	defer endpoint.Close()
+
+	client, err := NewCLClient(testGrpcClientConn)
+	require.NoError(t, err)
+	client.UseStreamOpener(NewGrpcWebConn(endpoint.URL, nil))
+
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+
+	// the registration key is received with a delay, and cannot be checked
+	start := time.Now()
+	_, err = client.IssueCredential(context.Background(), cm, "faultyRegKey")
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) >= 2*delay)
+}
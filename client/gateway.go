@@ -0,0 +1,187 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/metadata"
+)
+
+// GatewayConn runs emmy protocols with HTTP/JSON requests to the gateway of emmy
+// server, for environments where neither gRPC nor gRPC-Web can be used. It can be
+// passed to UseStreamOpener of emmy clients in place of their gRPC connection.
+type GatewayConn struct {
+	url    string
+	client *http.Client
+}
+
+// gatewayStreamMessage is the body of requests and responses of protocol streams
+// of the gateway (see server.StreamRequest and server.StreamResponse).
+type gatewayStreamMessage struct {
+	Session string `json:"session,omitempty"`
+	Message []byte `json:"message,omitempty"`
+	Done    bool   `json:"done,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NewGatewayConn returns a connection to the gateway at url. When client is nil,
+// http.DefaultClient is used.
+func NewGatewayConn(url string, client *http.Client) *GatewayConn {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &GatewayConn{
+		url:    strings.TrimSuffix(url, "/"),
+		client: client,
+	}
+}
+
+// OpenStream opens a stream of the emmy protocol method (for example IssueCredential).
+func (c *GatewayConn) OpenStream(ctx context.Context, method string) (pb.ClientStream, error) {
+	fullMethod, ok := pb.StreamMethods[method]
+	if !ok {
+		return nil, fmt.Errorf("unknown method %s", method)
+	}
+
+	return &gatewayClientStream{
+		conn: c,
+		ctx:  ctx,
+		path: "/v1/streams/" + strings.TrimPrefix(fullMethod, "/proto."),
+	}, nil
+}
+
+// do posts msg (nil to end the client's side of the stream) to the stream at path
+// with the given session, and returns the response of the gateway.
+func (c *GatewayConn) do(ctx context.Context, path, session string,
+	msg *pb.Message) (*gatewayStreamMessage, error) {
+	body := &gatewayStreamMessage{Session: session}
+	if msg != nil {
+		data, err := proto.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		body.Message = data
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setTenantHeader(ctx, req.Header)
+
+	r, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+	resp := new(gatewayStreamMessage)
+	if err := json.NewDecoder(r.Body).Decode(resp); err != nil {
+		return nil, fmt.Errorf("gateway request failed with %s", r.Status)
+	}
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gateway request failed with %s: %s", r.Status, resp.Error)
+	}
+
+	return resp, nil
+}
+
+// gatewayClientStream implements pb.ClientStream with requests to the gateway. Send
+// posts a message and keeps the server's response, which is then returned by Recv.
+type gatewayClientStream struct {
+	conn    *GatewayConn
+	ctx     context.Context
+	path    string
+	session string
+	resp    *pb.Message
+	err     error
+}
+
+func (s *gatewayClientStream) Send(msg *pb.Message) error {
+	s.resp = nil
+	resp, err := s.conn.do(s.ctx, s.path, s.session, msg)
+	if err != nil {
+		s.err = err
+		return err
+	}
+	s.session = resp.Session
+	if len(resp.Message) > 0 {
+		s.resp = new(pb.Message)
+		s.err = proto.Unmarshal(resp.Message, s.resp)
+	}
+
+	return s.err
+}
+
+func (s *gatewayClientStream) Recv() (*pb.Message, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.resp == nil {
+		return nil, io.EOF
+	}
+	resp := s.resp
+	s.resp = nil
+
+	return resp, nil
+}
+
+func (s *gatewayClientStream) CloseSend() error {
+	if s.session == "" {
+		return nil
+	}
+	_, err := s.conn.do(s.ctx, s.path, s.session, nil)
+	return err
+}
+
+func (s *gatewayClientStream) Header() (metadata.MD, error) {
+	return metadata.MD{}, nil
+}
+
+func (s *gatewayClientStream) Trailer() metadata.MD {
+	return metadata.MD{}
+}
+
+func (s *gatewayClientStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *gatewayClientStream) SendMsg(m interface{}) error {
+	return s.Send(m.(*pb.Message))
+}
+
+func (s *gatewayClientStream) RecvMsg(m interface{}) error {
+	resp, err := s.Recv()
+	if err != nil {
+		return err
+	}
+	*m.(*pb.Message) = *resp
+	return nil
+}
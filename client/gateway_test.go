@@ -0,0 +1,101 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/server"
+)
+
+// TestCLOverGateway requires a running server.
+func TestCLOverGateway(t *testing.T) {
+	endpoint := httptest.NewServer(testGatewayHandler)
+	defer endpoint.Close()
+	conn := NewGatewayConn(endpoint.URL, nil)
+
+	client, err := NewCLClient(testGrpcClientConn)
+	require.NoError(t, err)
+	client.UseStreamOpener(conn)
+
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+
+	cred, err := client.IssueCredential(context.Background(), cm, "testRegKey10")
+	require.NoError(t, err)
+
+	// errors of the protocol are passed to the client
+	_, err = client.IssueCredential(context.Background(), cm, "testRegKey10")
+	assert.Error(t, err)
+
+	acceptableCreds, err := client.GetAcceptableCreds(context.Background())
+	require.NoError(t, err)
+	sessKey, err := client.ProveCredential(context.Background(), cm, cred, acceptableCreds["org1"])
+	require.NoError(t, err)
+	assert.NotNil(t, sessKey)
+
+	// non-interactive proofs are verified in a single request, and only once
+	proof, err := client.BuildProofNI(context.Background(), cm, cred, acceptableCreds["org1"], nil)
+	require.NoError(t, err)
+	data, err := proto.Marshal(proof)
+	require.NoError(t, err)
+	body, err := json.Marshal(&server.CredProof{Proof: data})
+	require.NoError(t, err)
+	for _, code := range []int{http.StatusOK, http.StatusGatewayTimeout} {
+		resp, err := http.Post(endpoint.URL+"/v1/cl/proofs", "application/json",
+			bytes.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, code, resp.StatusCode)
+	}
+
+	// sessions of streams cannot be guessed
+	_, err = conn.do(context.Background(), "/v1/streams/CL/ProveCredential", "unknown",
+		&pb.Message{})
+	assert.Error(t, err)
+	_, err = conn.OpenStream(context.Background(), "Unknown")
+	assert.Error(t, err)
+}
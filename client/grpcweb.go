@@ -0,0 +1,191 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/xlab-si/emmy/grpcweb"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/metadata"
+)
+
+// GrpcWebConn runs emmy protocols with gRPC-Web requests, as browser clients do.
+// It can be passed to UseStreamOpener of emmy clients in place of their gRPC
+// connection, which is then also used for their unary calls (so the clients can be
+// created with a nil connection).
+type GrpcWebConn struct {
+	url    string
+	client *http.Client
+}
+
+// NewGrpcWebConn returns a gRPC-Web connection to the server at url. When client is
+// nil, http.DefaultClient is used.
+func NewGrpcWebConn(url string, client *http.Client) *GrpcWebConn {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &GrpcWebConn{
+		url:    strings.TrimSuffix(url, "/"),
+		client: client,
+	}
+}
+
+// Invoke calls the unary RPC method (a full method name such as
+// /proto.CL/GetCredentialStructure) and stores the response in out.
+func (c *GrpcWebConn) Invoke(ctx context.Context, method string, in, out proto.Message) error {
+	_, hasMsg, err := c.do(ctx, method, "", in, out)
+	if err == nil && !hasMsg {
+		err = fmt.Errorf("no response to %s", method)
+	}
+	return err
+}
+
+// OpenStream opens a stream of the emmy protocol method (for example IssueCredential).
+func (c *GrpcWebConn) OpenStream(ctx context.Context, method string) (pb.ClientStream, error) {
+	fullMethod, ok := pb.StreamMethods[method]
+	if !ok {
+		return nil, fmt.Errorf("unknown method %s", method)
+	}
+
+	return &grpcWebClientStream{
+		conn:   c,
+		ctx:    ctx,
+		method: fullMethod,
+	}, nil
+}
+
+// do posts msg (if not nil) to method in the stream identified by streamID, stores
+// the response in resp and returns the ID of the stream. It reports whether the
+// response held a message.
+func (c *GrpcWebConn) do(ctx context.Context, method, streamID string, msg,
+	resp proto.Message) (string, bool, error) {
+	body, err := grpcweb.EncodeRequest(msg, grpcweb.ContentType)
+	if err != nil {
+		return "", false, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url+method, bytes.NewReader(body))
+	if err != nil {
+		return "", false, err
+	}
+	req.Header.Set("Content-Type", grpcweb.ContentType)
+	req.Header.Set("X-Grpc-Web", "1")
+	setTenantHeader(ctx, req.Header)
+	if streamID != "" {
+		req.Header.Set(grpcweb.StreamIDHeader, streamID)
+	}
+
+	r, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", false, err
+	}
+	defer r.Body.Close()
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", false, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("gRPC-Web request failed with %s", r.Status)
+	}
+	// errors may be sent in headers only
+	if err := grpcweb.StatusFromHeader(r.Header.Get("Grpc-Status"),
+		r.Header.Get("Grpc-Message"), r.Header.Get("Grpc-Status-Details-Bin")); err != nil {
+		return "", false, err
+	}
+
+	hasMsg, err := grpcweb.Decode(data, r.Header.Get("Content-Type"), resp)
+	return r.Header.Get(grpcweb.StreamIDHeader), hasMsg, err
+}
+
+// grpcWebClientStream implements pb.ClientStream with gRPC-Web requests. Send
+// posts a message and keeps the server's response, which is then returned by Recv.
+type grpcWebClientStream struct {
+	conn   *GrpcWebConn
+	ctx    context.Context
+	method string
+	id     string
+	resp   *pb.Message
+	err    error
+}
+
+func (s *grpcWebClientStream) Send(msg *pb.Message) error {
+	resp := new(pb.Message)
+	id, hasMsg, err := s.conn.do(s.ctx, s.method, s.id, msg, resp)
+	if id != "" {
+		s.id = id
+	}
+	s.resp, s.err = nil, err
+	if hasMsg {
+		s.resp = resp
+	}
+
+	return err
+}
+
+func (s *grpcWebClientStream) Recv() (*pb.Message, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.resp == nil {
+		return nil, io.EOF
+	}
+	resp := s.resp
+	s.resp = nil
+
+	return resp, nil
+}
+
+func (s *grpcWebClientStream) CloseSend() error {
+	if s.id == "" {
+		return nil
+	}
+	_, _, err := s.conn.do(s.ctx, s.method, s.id, nil, new(pb.Message))
+	return err
+}
+
+func (s *grpcWebClientStream) Header() (metadata.MD, error) {
+	return metadata.MD{}, nil
+}
+
+func (s *grpcWebClientStream) Trailer() metadata.MD {
+	return metadata.MD{}
+}
+
+func (s *grpcWebClientStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *grpcWebClientStream) SendMsg(m interface{}) error {
+	return s.Send(m.(*pb.Message))
+}
+
+func (s *grpcWebClientStream) RecvMsg(m interface{}) error {
+	resp, err := s.Recv()
+	if err != nil {
+		return err
+	}
+	*m.(*pb.Message) = *resp
+	return nil
+}
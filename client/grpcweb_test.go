@@ -0,0 +1,86 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestCLOverGrpcWeb requires a running server.
+func TestCLOverGrpcWeb(t *testing.T) {
+	endpoint := httptest.NewServer(testGrpcWebHandler)
+	defer endpoint.Close()
+	conn := NewGrpcWebConn(endpoint.URL, nil)
+
+	info := new(pb.ServiceInfo)
+	require.NoError(t, conn.Invoke(context.Background(), "/proto.Info/GetServiceInfo",
+		&empty.Empty{}, info))
+	assert.NotEmpty(t, info.Name)
+
+	// unary calls are made over gRPC-Web as well
+	client, err := NewCLClient(nil)
+	require.NoError(t, err)
+	client.UseStreamOpener(conn)
+
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+
+	cred, err := client.IssueCredential(context.Background(), cm, "testRegKey7")
+	require.NoError(t, err)
+
+	// errors of the protocol are passed to the client
+	_, err = client.IssueCredential(context.Background(), cm, "testRegKey7")
+	assert.Error(t, err)
+
+	acceptableCreds, err := client.GetAcceptableCreds(context.Background())
+	require.NoError(t, err)
+	sessKey, err := client.ProveCredential(context.Background(), cm, cred, acceptableCreds["org1"])
+	require.NoError(t, err)
+	assert.NotNil(t, sessKey)
+
+	err = conn.Invoke(context.Background(), "/proto.CL/Unknown", &empty.Empty{}, info)
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
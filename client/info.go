@@ -19,9 +19,15 @@ package client
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
 	"fmt"
+	"math/big"
+	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/xlab-si/emmy/crypto/cl"
 	pb "github.com/xlab-si/emmy/proto"
 	"google.golang.org/grpc"
 )
@@ -32,6 +38,19 @@ type ServiceInfo struct {
 	Name        string
 	Description string
 	Provider    string
+	// CL public keys the server verifies proofs of credentials with
+	CLPubKeys []*CLPubKey
+	// versions of protocol messages the server supports (see pb.ProtocolVersion)
+	ProtocolVersions []int32
+}
+
+// CLPubKey is a CL public key of the issuer, identified by ID (see cl.PubKey.ID).
+// Proofs built with a previous key, replaced when keys were rotated, are accepted
+// until Until, which is zero for the current key.
+type CLPubKey struct {
+	ID     string
+	PubKey *cl.PubKey
+	Until  time.Time
 }
 
 func NewServiceInfo(name, description, provider string) *ServiceInfo {
@@ -42,18 +61,126 @@ func NewServiceInfo(name, description, provider string) *ServiceInfo {
 	}
 }
 
-func GetServiceInfo(conn *grpc.ClientConn) (*ServiceInfo, error) {
+func GetServiceInfo(ctx context.Context, conn *grpc.ClientConn) (*ServiceInfo, error) {
 	logger.Debug("GetServiceInfo invoked")
 	client := pb.NewInfoClient(conn)
 
-	info, err := client.GetServiceInfo(context.Background(), &empty.Empty{})
+	info, err := client.GetServiceInfo(ctx, &empty.Empty{})
 	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve service info: %v", err)
+		return nil, wrapError("unable to retrieve service info", err)
 	}
 
 	serviceInfo := NewServiceInfo(info.GetName(), info.GetDescription(), info.GetProvider())
+	if serviceInfo.CLPubKeys, err = clPubKeys(info.GetClPubKeys()); err != nil {
+		return nil, err
+	}
+	serviceInfo.ProtocolVersions = info.GetProtocolVersions()
 	logger.Noticef("Retrieved service info:\n Name: %s\n Provider: %s\n Description: %s",
 		serviceInfo.Name, serviceInfo.Provider, serviceInfo.Description)
 
 	return serviceInfo, nil
 }
+
+// clPubKeys parses CL public keys received from the server, checking their IDs.
+func clPubKeys(keys []*pb.CLPubKey) ([]*CLPubKey, error) {
+	var pubKeys []*CLPubKey
+	for _, k := range keys {
+		pubKey, err := cl.ParsePubKeyPEM(k.PubKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CL public key %s: %v", k.KeyID, err)
+		}
+		if pubKey.ID() != k.KeyID {
+			return nil, fmt.Errorf("CL public key %s has ID %s", k.KeyID, pubKey.ID())
+		}
+		key := &CLPubKey{
+			ID:     k.KeyID,
+			PubKey: pubKey,
+		}
+		if k.Until != 0 {
+			key.Until = time.Unix(k.Until, 0)
+		}
+		pubKeys = append(pubKeys, key)
+	}
+
+	return pubKeys, nil
+}
+
+// PublicParameters are the public parameters of the issuer that clients need to
+// obtain and verify credentials (see GetPublicParameters).
+type PublicParameters struct {
+	// CL public keys the server verifies proofs of credentials with
+	CLPubKeys []*CLPubKey
+	Params    *cl.Params
+	// accumulator of revocation, nil when revocation is not enabled
+	Accumulator *cl.Accumulator
+	Schemas     []*CredSchema
+	// time when the server assembled the parameters
+	IssuedAt time.Time
+}
+
+// CredSchema is a named and versioned structure of CL credentials of the issuer,
+// given by an empty credential.
+type CredSchema struct {
+	Name    string
+	Version string
+	Cred    *cl.RawCred
+}
+
+// GetPublicParameters retrieves the public parameters of the issuer (of the tenant
+// selected in ctx, if any) and verifies that they were signed with key, the only
+// thing clients need to obtain out of band.
+func GetPublicParameters(ctx context.Context, conn *grpc.ClientConn,
+	key *ecdsa.PublicKey) (*PublicParameters, error) {
+	logger.Debug("GetPublicParameters invoked")
+	client := pb.NewInfoClient(conn)
+
+	signed, err := client.GetPublicParameters(ctx, &empty.Empty{})
+	if err != nil {
+		return nil, wrapError("unable to retrieve public parameters", err)
+	}
+
+	sig := signed.GetSignature()
+	if len(sig) != 64 {
+		return nil, fmt.Errorf("invalid signature length of public parameters")
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	digest := sha256.Sum256(signed.GetParameters())
+	if !ecdsa.Verify(key, digest[:], r, s) {
+		return nil, fmt.Errorf("invalid signature of public parameters")
+	}
+
+	var pbParams pb.PublicParameters
+	if err := proto.Unmarshal(signed.GetParameters(), &pbParams); err != nil {
+		return nil, err
+	}
+	params := &PublicParameters{
+		IssuedAt: time.Unix(pbParams.IssuedAt, 0),
+	}
+	if params.CLPubKeys, err = clPubKeys(pbParams.GetClPubKeys()); err != nil {
+		return nil, err
+	}
+	if params.Params, err = pbParams.GetClParams().GetNativeType(); err != nil {
+		return nil, err
+	}
+	if pbParams.Accumulator != nil {
+		if params.Accumulator, err = pbParams.Accumulator.GetNativeType(); err != nil {
+			return nil, err
+		}
+	}
+	for _, schema := range pbParams.GetSchemas() {
+		rc, err := rawCred(schema)
+		if err != nil {
+			return nil, fmt.Errorf("credential schema %s %s: %v", schema.Name,
+				schema.Version, err)
+		}
+		params.Schemas = append(params.Schemas, &CredSchema{
+			Name:    schema.Name,
+			Version: schema.Version,
+			Cred:    rc,
+		})
+	}
+	logger.Noticef("Retrieved public parameters signed with key %s", signed.KeyID)
+
+	return params, nil
+}
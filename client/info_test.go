@@ -18,12 +18,61 @@
 package client
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
 )
 
 func TestGetServiceInfo(t *testing.T) {
-	info, _ := GetServiceInfo(testGrpcClientConn)
+	info, _ := GetServiceInfo(context.Background(), testGrpcClientConn)
 	assert.NotNil(t, info, "expected non-nil service info")
 }
+
+// TestGetPublicParameters retrieves public parameters of the issuer and verifies their
+// signature.
+func TestGetPublicParameters(t *testing.T) {
+	srv, conn := newTestServer(t, &mockRegKeyDB{})
+	defer srv.Teardown()
+	defer conn.Close()
+
+	_, err := GetPublicParameters(context.Background(), conn, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not published")
+
+	dir, err := ioutil.TempDir("", "emmy-params")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, srv.EnableRevocation(filepath.Join(dir, "accumulator")))
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, srv.SignPublicParameters(key))
+
+	params, err := GetPublicParameters(context.Background(), conn, &key.PublicKey)
+	require.NoError(t, err)
+	pubKey := new(cl.PubKey)
+	require.NoError(t, cl.ReadGob("testdata/clPubKey.gob", pubKey))
+	require.NotEmpty(t, params.CLPubKeys)
+	assert.Equal(t, pubKey.ID(), params.CLPubKeys[0].ID)
+	clParams, err := cl.LoadParams()
+	require.NoError(t, err)
+	assert.Equal(t, clParams, params.Params)
+	assert.NotNil(t, params.Accumulator)
+	require.NotEmpty(t, params.Schemas)
+	_, err = params.Schemas[0].Cred.GetAttr("Name")
+	assert.NoError(t, err)
+	assert.False(t, params.IssuedAt.IsZero())
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = GetPublicParameters(context.Background(), conn, &other.PublicKey)
+	assert.Error(t, err)
+}
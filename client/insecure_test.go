@@ -0,0 +1,89 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"google.golang.org/grpc"
+)
+
+// TestInsecure runs protocols with a server without TLS, over a UNIX domain socket and
+// over TCP on localhost.
+func TestInsecure(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.New(server.WithInsecure(), server.WithLogger(logger))
+	require.NoError(t, err)
+	defer srv.GrpcServer.Stop()
+
+	dir, err := ioutil.TempDir("", "emmy")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "emmy.sock")
+	go srv.StartUnix(socketPath)
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+
+	// wait for the socket to be created
+	for i := 0; i < 50; i++ {
+		if _, err = os.Stat(socketPath); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, err)
+	fi, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	unixConn, err := DialUnix(socketPath, 500)
+	require.NoError(t, err)
+	defer unixConn.Close()
+	tcpConn, err := DialInsecure(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), 500)
+	require.NoError(t, err)
+	defer tcpConn.Close()
+
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+	for _, conn := range []*grpc.ClientConn{unixConn, tcpConn} {
+		_, err := GetServiceInfo(context.Background(), conn)
+		assert.NoError(t, err)
+
+		caClient, err := NewPseudonymsysCAClient(conn, group)
+		require.NoError(t, err)
+		userSecret := common.GetRandomInt(group.Q)
+		masterNym := caClient.GenerateMasterNym(userSecret)
+		_, err = caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+		assert.NoError(t, err)
+	}
+}
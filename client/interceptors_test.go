@@ -0,0 +1,106 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/log"
+	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TestInterceptors runs protocols with a server with interceptors of its own, which
+// reject protocols without an API key and count unary calls.
+func TestInterceptors(t *testing.T) {
+	var unaryCalls int32
+	var requestIDs sync.Map
+	auth := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		requestIDs.Store(server.RequestID(ss.Context()), true)
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		if v := md["api-key"]; len(v) == 0 || v[0] != "secret" {
+			return status.Error(codes.PermissionDenied, "missing API key")
+		}
+		return handler(srv, ss)
+	}
+	count := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		atomic.AddInt32(&unaryCalls, 1)
+		return handler(ctx, req)
+	}
+
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.New(server.WithTLS("testdata/server.pem", "testdata/server.key"),
+		server.WithLogger(logger), server.WithStreamInterceptors(auth),
+		server.WithUnaryInterceptors(count))
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	conn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// request IDs given by clients are returned to them
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		pb.RequestIDMetadataKey, "request1")
+	_, err = pb.NewInfoClient(conn).GetServiceInfo(ctx, &empty.Empty{}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"request1"}, header[pb.RequestIDMetadataKey])
+	assert.Equal(t, int32(1), atomic.LoadInt32(&unaryCalls))
+
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+	caClient, err := NewPseudonymsysCAClient(conn, group)
+	require.NoError(t, err)
+	userSecret := common.GetRandomInt(group.Q)
+	masterNym := caClient.GenerateMasterNym(userSecret)
+
+	_, err = caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(errors.Unwrap(err)),
+		"unexpected error %v", err)
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "api-key", "secret")
+	_, err = caClient.GenerateCertificate(ctx, userSecret, masterNym)
+	assert.NoError(t, err)
+
+	// requests without IDs get new ones
+	_, ok := requestIDs.Load("")
+	assert.False(t, ok)
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/keys"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+)
+
+// importKeys stores secret keys of issuers from the configuration in ks.
+func importKeys(t *testing.T, ks crypto.KeyStore) {
+	_, secKeyPath := config.LoadCLKeyPaths()
+	clSecKey, err := ioutil.ReadFile(secKeyPath)
+	require.NoError(t, err)
+	require.NoError(t, ks.Store(server.KeyLabelCLSecKey, clSecKey))
+
+	caPubKey := config.LoadPseudonymsysCAPubKey()
+	require.NoError(t, ks.StoreECDSAKey(server.KeyLabelPseudonymsysCA, &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: ec.GetCurve(ec.P256), X: caPubKey.H1, Y: caPubKey.H2},
+		D:         config.LoadPseudonymsysCASecret(),
+	}))
+
+	for _, name := range config.LoadPseudonymsysOrgNames() {
+		orgKeys, err := server.LoadOrgKeysFromConfig(name)
+		require.NoError(t, err)
+		if orgKeys.SecKey != nil {
+			data, err := keys.EncodePseudonymsysSecKey(orgKeys.SecKey)
+			require.NoError(t, err)
+			require.NoError(t, ks.Store(server.PseudonymsysOrgKeyLabel(name, "dlog"), data))
+		}
+		if orgKeys.SecKeyEC != nil {
+			data, err := keys.EncodePseudonymsysSecKey(orgKeys.SecKeyEC)
+			require.NoError(t, err)
+			require.NoError(t, ks.Store(server.PseudonymsysOrgKeyLabel(name, "ecdlog"), data))
+		}
+	}
+}
+
+func TestKeyStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emmy-keystore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ks := crypto.NewFileKeyStore(dir)
+
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{data: []string{"keyStoreKey1", "keyStoreKey2"}},
+		cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+	srv.UseKeyStore(ks)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	defer srv.Teardown()
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	conn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+	caClient, err := NewPseudonymsysCAClient(conn, group)
+	require.NoError(t, err)
+	c, err := NewPseudonymsysClient(conn, group)
+	require.NoError(t, err)
+	userSecret := c.GenerateMasterKey()
+	masterNym := caClient.GenerateMasterNym(userSecret)
+
+	// the key store does not hold the key of the CA yet
+	_, err = caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+	assert.Error(t, err)
+
+	importKeys(t, ks)
+	srv.UseKeyStore(ks)
+
+	// the CA signs the certificate with the key from the key store, which the
+	// organization verifies with the CA's public key
+	caCert, err := caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+	require.NoError(t, err)
+	nym, err := c.GenerateNym(context.Background(), userSecret, caCert, "keyStoreKey1")
+	require.NoError(t, err)
+	_, err = c.ObtainCredential(context.Background(), userSecret, nym,
+		config.LoadPseudonymsysOrgPubKeys("org1"))
+	require.NoError(t, err)
+
+	clClient, err := NewCLClient(conn)
+	require.NoError(t, err)
+	rc, err := clClient.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for n, val := range map[string]interface{}{
+		"Name":      "Alice",
+		"Gender":    "F",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       30,
+	} {
+		a, err := rc.GetAttr(n)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+	pubKey := new(cl.PubKey)
+	require.NoError(t, cl.ReadGob("testdata/clPubKey.gob", pubKey))
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+	cred, err := clClient.IssueCredential(context.Background(), cm, "keyStoreKey2")
+	require.NoError(t, err)
+	proved, err := clClient.ProveCredential(context.Background(), cm, cred, []string{"Name"})
+	require.NoError(t, err)
+	assert.NotNil(t, proved)
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/grpcweb"
+	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestIdleTimeout stalls protocol streams of a server that limits the time it waits
+// for messages of clients.
+func TestIdleTimeout(t *testing.T) {
+	config.Default().Set("network.timeouts.idle", 200)
+	_, conn := newTestServer(t, &mockRegKeyDB{})
+	config.Default().Set("network.timeouts.idle", 0)
+	defer conn.Close()
+
+	stream, err := pb.NewCLClient(conn).ProveCredential(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&pb.Message{ClientId: 1}))
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	// the server gives up waiting for the proof
+	start := time.Now()
+	_, err = stream.Recv()
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err), "unexpected error %v", err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+
+	// runs of the Steps service are aborted and reaped as well
+	steps := pb.NewStepsClient(conn)
+	resp, err := steps.Step(context.Background(), &pb.ProtocolStep{
+		Method:  "ProveCredential",
+		Message: &pb.Message{ClientId: 2},
+	})
+	require.NoError(t, err)
+	time.Sleep(500 * time.Millisecond)
+	_, err = steps.Step(context.Background(), &pb.ProtocolStep{
+		Method:  "ProveCredential",
+		Session: resp.Session,
+		Message: &pb.Message{ClientId: 2},
+	})
+	assert.Error(t, err)
+}
+
+// TestGrpcWebMaxMsgSize sends gRPC-Web requests larger than the maximum size of
+// messages that the server receives.
+func TestGrpcWebMaxMsgSize(t *testing.T) {
+	srv, conn := newTestServer(t, &mockRegKeyDB{})
+	defer conn.Close()
+	endpoint := httptest.NewServer(server.NewGrpcWebHandler(srv, nil))
+	defer endpoint.Close()
+
+	post := func(body []byte) error {
+		res, err := http.Post(endpoint.URL+"/proto.Info/GetServiceInfo",
+			"application/grpc-web+proto", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		data, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		_, err = grpcweb.Decode(data, "application/grpc-web+proto", new(pb.ServiceInfo))
+		return err
+	}
+
+	body, err := grpcweb.EncodeRequest(new(empty.Empty), "application/grpc-web+proto")
+	require.NoError(t, err)
+	assert.NoError(t, post(body))
+
+	maxSize := config.LoadNetworkConfig().Limits.MaxRecvMsgSize
+	err = post(make([]byte, maxSize+6))
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "unexpected error %v", err)
+}
@@ -0,0 +1,125 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+)
+
+// metricValue returns the value of the counter or the sample count of the histogram
+// name with the given labels, gathered from the default Prometheus registry.
+func metricValue(t *testing.T, name string, labels map[string]string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if v, ok := labels[l.GetName()]; ok && v != l.GetValue() {
+					continue metrics
+				}
+			}
+			if m.Counter != nil {
+				return m.Counter.GetValue()
+			}
+			return float64(m.Histogram.GetSampleCount())
+		}
+	}
+
+	return 0
+}
+
+func TestProtocolMetrics(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{data: []string{"metricsKey1", "metricsKey2"}},
+		cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	defer srv.Teardown()
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	conn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	nymGen := map[string]string{"protocol": "GenerateNym"}
+	transfer := map[string]string{"protocol": "TransferCredential"}
+	nymGenOk := metricValue(t, "emmy_protocol_runs_total",
+		map[string]string{"protocol": "GenerateNym", "result": "success"})
+	nymGenFailed := metricValue(t, "emmy_protocol_runs_total",
+		map[string]string{"protocol": "GenerateNym", "result": "failure"})
+	nymGenRoundTrips := metricValue(t, "emmy_protocol_round_trip_seconds", nymGen)
+	verificationFailures := metricValue(t, "emmy_protocol_verification_failures_total",
+		transfer)
+
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+	caClient, err := NewPseudonymsysCAClient(conn, group)
+	require.NoError(t, err)
+	c, err := NewPseudonymsysClient(conn, group)
+	require.NoError(t, err)
+	userSecret := c.GenerateMasterKey()
+	masterNym := caClient.GenerateMasterNym(userSecret)
+	caCert, err := caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+	require.NoError(t, err)
+
+	_, err = c.GenerateNym(context.Background(), userSecret, caCert, "unknownKey")
+	assert.Error(t, err)
+	nym1, err := c.GenerateNym(context.Background(), userSecret, caCert, "metricsKey1")
+	require.NoError(t, err)
+	cred, err := c.ObtainCredential(context.Background(), userSecret, nym1,
+		config.LoadPseudonymsysOrgPubKeys("org1"))
+	require.NoError(t, err)
+	caCert, err = caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+	require.NoError(t, err)
+	nym2, err := c.GenerateNym(context.Background(), userSecret, caCert, "metricsKey2")
+	require.NoError(t, err)
+	_, err = c.TransferCredential(context.Background(), "org1", big.NewInt(42), nym2, cred)
+	assert.Error(t, err)
+
+	assert.Equal(t, nymGenOk+2, metricValue(t, "emmy_protocol_runs_total",
+		map[string]string{"protocol": "GenerateNym", "result": "success"}))
+	assert.Equal(t, nymGenFailed+1, metricValue(t, "emmy_protocol_runs_total",
+		map[string]string{"protocol": "GenerateNym", "result": "failure"}))
+	// the client replies to the challenge of the server in each successful run
+	assert.True(t, metricValue(t, "emmy_protocol_round_trip_seconds", nymGen) >=
+		nymGenRoundTrips+2)
+	assert.Equal(t, verificationFailures+1,
+		metricValue(t, "emmy_protocol_verification_failures_total", transfer))
+}
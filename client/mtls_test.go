@@ -0,0 +1,108 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+)
+
+// selfSignedCert returns a self-signed client certificate and its private key in
+// PEM format.
+func selfSignedCert(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "untrustedOrg"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestMutualTLS(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	// clients authenticate with certificates issued by the CA of the test certificate
+	srv, err := server.NewMutualTLSServer("testdata/server.pem", "testdata/server.key",
+		"testdata/server.pem", &mockRegKeyDB{}, cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	defer srv.Teardown()
+	endpoint := fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port)
+
+	caCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	key, err := ioutil.ReadFile("testdata/server.key")
+	require.NoError(t, err)
+	untrustedCert, untrustedKey := selfSignedCert(t)
+
+	// getServiceInfo connects to the server with the given client certificate and runs
+	// a call over the connection, as the server might only reject the client's certificate
+	// after the connection is established
+	getServiceInfo := func(cert, key []byte) error {
+		cfg := NewConnectionConfig(endpoint, "", caCert, 500)
+		cfg.ClientCertificate = cert
+		cfg.ClientKey = key
+		conn, err := GetConnection(cfg)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, err = GetServiceInfo(ctx, conn)
+		return err
+	}
+
+	assert.NoError(t, getServiceInfo(caCert, key))
+	assert.Error(t, getServiceInfo(nil, nil), "client without certificate")
+	assert.Error(t, getServiceInfo(untrustedCert, untrustedKey), "untrusted client certificate")
+
+	cfg := NewConnectionConfig(endpoint, "", caCert, 500)
+	cfg.ClientCertificate = caCert
+	_, err = GetConnection(cfg)
+	assert.Error(t, err, "client certificate without key")
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/log"
+	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/server"
+)
+
+func TestMemNonceStore(t *testing.T) {
+	store := server.NewMemNonceStore()
+	n := big.NewInt(42)
+	require.NoError(t, store.Put(n, time.Now().Add(time.Minute)))
+	assert.NoError(t, store.Use(n))
+	// nonces can be used only once
+	assert.Equal(t, server.ErrNonceUsed, store.Use(n))
+	assert.Equal(t, server.ErrNonceUsed, store.Use(big.NewInt(43)))
+
+	require.NoError(t, store.Put(n, time.Now().Add(-time.Second)))
+	assert.Equal(t, server.ErrNonceUsed, store.Use(n))
+
+	// new nonces can be stored only once until they expire
+	assert.NoError(t, store.PutNew(n, time.Now().Add(time.Minute)))
+	assert.Equal(t, server.ErrNonceUsed, store.PutNew(n, time.Now().Add(time.Minute)))
+	m := big.NewInt(44)
+	require.NoError(t, store.Put(m, time.Now().Add(-time.Second)))
+	assert.NoError(t, store.PutNew(m, time.Now().Add(time.Minute)))
+}
+
+// TestNonceExpiry issues and proves a credential with nonces that expire before the
+// client responds to them.
+func TestNonceExpiry(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{data: []string{"nonceKey1", "nonceKey2"}}, cl.NewMockRecordManager(),
+		logger)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	defer srv.Teardown()
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	conn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client, err := NewCLClient(conn)
+	require.NoError(t, err)
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+
+	cred, err := client.IssueCredential(context.Background(), cm, "nonceKey1")
+	require.NoError(t, err)
+	_, err = client.ProveCredential(context.Background(), cm, cred, []string{"Gender"})
+	require.NoError(t, err)
+
+	srv.UseNonceStore(server.NewMemNonceStore(), time.Nanosecond)
+	_, err = client.IssueCredential(context.Background(), cm, "nonceKey2")
+	assert.True(t, errors.Is(err, ErrExpiredNonce), "unexpected error %v", err)
+	_, err = client.ProveCredential(context.Background(), cm, cred, []string{"Gender"})
+	assert.True(t, errors.Is(err, ErrExpiredNonce), "unexpected error %v", err)
+}
+
+// TestNonInteractiveProofs registers a nym and proves a credential with proofs bound
+// to contexts instead of nonces issued by the server.
+func TestNonInteractiveProofs(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{data: []string{"niKey1", "niKey2"}}, cl.NewMockRecordManager(),
+		logger)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	defer srv.Teardown()
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	conn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+	caClient, err := NewPseudonymsysCAClient(conn, group)
+	require.NoError(t, err)
+	c, err := NewPseudonymsysClient(conn, group)
+	require.NoError(t, err)
+	userSecret := c.GenerateMasterKey()
+	caCert, err := caClient.GenerateCertificate(context.Background(), userSecret,
+		caClient.GenerateMasterNym(userSecret))
+	require.NoError(t, err)
+	_, err = c.GenerateNymNI(context.Background(), userSecret, caCert, "unknownKey")
+	assert.True(t, errors.Is(err, ErrInvalidRegKey), "unexpected error %v", err)
+	_, err = c.GenerateNymNI(context.Background(), big.NewInt(3952123123), caCert, "niKey1")
+	assert.True(t, errors.Is(err, ErrInvalidProof), "unexpected error %v", err)
+	nym, err := c.GenerateNymNI(context.Background(), userSecret, caCert, "niKey1")
+	require.NoError(t, err)
+	_, err = c.ObtainCredential(context.Background(), userSecret, nym,
+		config.LoadPseudonymsysOrgPubKeys("org1"))
+	require.NoError(t, err)
+
+	client, err := NewCLClient(conn)
+	require.NoError(t, err)
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+	cred, err := client.IssueCredential(context.Background(), cm, "niKey2")
+	require.NoError(t, err)
+
+	sessKey, err := client.ProveCredentialNI(context.Background(), cm, cred,
+		[]string{"Gender"}, nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, *sessKey)
+
+	// the same proof is accepted only once
+	proof, err := client.BuildProofNI(context.Background(), cm, cred, []string{"Gender"}, nil)
+	require.NoError(t, err)
+	grpcClient := pb.NewCLClient(conn)
+	_, err = grpcClient.ProveCredentialNI(context.Background(), proof)
+	require.NoError(t, err)
+	_, err = grpcClient.ProveCredentialNI(context.Background(), proof)
+	assert.True(t, errors.Is(wrapError("", err), ErrExpiredNonce), "unexpected error %v", err)
+
+	// proofs are bound to the time of their creation
+	proof, err = client.BuildProofNI(context.Background(), cm, cred, []string{"Gender"}, nil)
+	require.NoError(t, err)
+	proof.Context.Timestamp -= 3600
+	_, err = grpcClient.ProveCredentialNI(context.Background(), proof)
+	assert.True(t, errors.Is(wrapError("", err), ErrExpiredNonce), "unexpected error %v", err)
+}
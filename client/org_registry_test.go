@@ -0,0 +1,112 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+)
+
+// TestOrgRegistry runs pseudonym system protocols against a server hosting two
+// organizations.
+func TestOrgRegistry(t *testing.T) {
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+
+	// keys of org2 are generated, and replaced later on
+	org2SecKey, org2PubKey := pseudsys.GenerateKeyPair(group)
+	load := func(name string) (*server.OrgKeys, error) {
+		if name != "org2" {
+			return server.LoadOrgKeysFromConfig(name)
+		}
+		return &server.OrgKeys{SecKey: org2SecKey, PubKey: org2PubKey}, nil
+	}
+	orgs, err := server.NewOrgRegistry(load, "org1", "org2")
+	require.NoError(t, err)
+
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{data: []string{"orgKey1", "orgKey2", "orgKey3", "orgKey4"}},
+		cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+	srv.UseOrgRegistry(orgs)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	defer srv.Teardown()
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	conn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	caClient, err := NewPseudonymsysCAClient(conn, group)
+	require.NoError(t, err)
+	c, err := NewPseudonymsysClient(conn, group)
+	require.NoError(t, err)
+	userSecret := c.GenerateMasterKey()
+	masterNym := caClient.GenerateMasterNym(userSecret)
+	newNym := func(regKey string) *pseudsys.Nym {
+		caCert, err := caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+		require.NoError(t, err)
+		nym, err := c.GenerateNym(context.Background(), userSecret, caCert, regKey)
+		require.NoError(t, err)
+		return nym
+	}
+
+	// obtain a credential from org2 and transfer it to org1
+	c.UseOrg("org2")
+	cred, err := c.ObtainCredential(context.Background(), userSecret, newNym("orgKey1"),
+		org2PubKey)
+	require.NoError(t, err)
+
+	c.UseOrg("org1")
+	nym := newNym("orgKey2")
+	sessKey, err := c.TransferCredential(context.Background(), "org2", userSecret, nym, cred)
+	require.NoError(t, err)
+	assert.NotNil(t, sessKey)
+	// the credential was not issued by org1
+	_, err = c.TransferCredential(context.Background(), "org1", userSecret, nym, cred)
+	assert.Error(t, err)
+
+	// organizations that are not hosted by the server are rejected
+	c.UseOrg("org3")
+	_, err = c.ObtainCredential(context.Background(), userSecret, newNym("orgKey3"), org2PubKey)
+	assert.Error(t, err)
+
+	// after keys of org2 are replaced, its previous credentials are not accepted
+	org2SecKey, org2PubKey = pseudsys.GenerateKeyPair(group)
+	require.NoError(t, srv.ReloadOrgs())
+	c.UseOrg("")
+	_, err = c.TransferCredential(context.Background(), "org2", userSecret, newNym("orgKey4"),
+		cred)
+	assert.Error(t, err)
+}
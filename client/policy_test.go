@@ -0,0 +1,126 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/server"
+)
+
+// TestPolicies checks that proofs of credentials are only accepted if they satisfy
+// one of the policies configured for a tenant.
+func TestPolicies(t *testing.T) {
+	srv, conn := newTestServer(t, &mockRegKeyDB{data: []string{"policyKey1", "policyKey2"}})
+	defer conn.Close()
+
+	dir := t.TempDir()
+	conf := config.New()
+	conf.Set("cl.pub_key", filepath.Join(dir, "clPubKey.gob"))
+	conf.Set("cl.sec_key", filepath.Join(dir, "clSecKey.gob"))
+	conf.Set("attributes", map[string]string{"0": "Name, string, true",
+		"1": "IssuedAt, int64, true", "2": "Age, int64, false"})
+	conf.Set("policies", map[string]interface{}{
+		"adult": map[string]interface{}{
+			"revealed":     []string{"Name"},
+			"predicates":   []string{"Age greater_than 17"},
+			"max_age":      "1h",
+			"range_proofs": "bulletproofs",
+		},
+		"staff": map[string]interface{}{
+			"issuers":  []string{"0000000000000000"},
+			"revealed": []string{"Name"},
+		},
+	})
+	tenant, err := server.NewTenant("club", conf)
+	require.NoError(t, err)
+	srv.AddTenant(tenant)
+
+	structure, err := conf.LoadCredentialStructure()
+	require.NoError(t, err)
+	_, attrCount, err := cl.ParseAttrs(structure)
+	require.NoError(t, err)
+	params, err := cl.LoadParams()
+	require.NoError(t, err)
+	org, err := cl.LoadOrCreateOrg(params, filepath.Join(dir, "clPubKey.gob"),
+		filepath.Join(dir, "clSecKey.gob"), attrCount)
+	require.NoError(t, err)
+
+	client, err := NewCLClient(conn)
+	require.NoError(t, err)
+	client.UseTenant("club")
+
+	policies, err := client.GetPolicies(context.Background())
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	adult, staff := policies[0], policies[1]
+	assert.Equal(t, "adult", adult.Name)
+	assert.Equal(t, time.Hour, adult.MaxAge)
+	assert.Equal(t, cl.RangeProofsBulletproofs, adult.RangeProofs)
+	assert.Equal(t, []string{"0000000000000000"}, staff.Issuers)
+
+	newCred := func(issuedAt time.Time) (*cl.CredManager, error) {
+		rc, err := client.GetCredentialStructure(context.Background())
+		require.NoError(t, err)
+		for name, val := range map[string]interface{}{
+			"Name":     "Jack",
+			"IssuedAt": issuedAt.Unix(),
+			"Age":      50,
+		} {
+			a, err := rc.GetAttr(name)
+			require.NoError(t, err)
+			require.NoError(t, a.UpdateValue(val))
+		}
+		return cl.NewCredManager(cl.GetDefaultParamSizes(), org.Keys.Pub,
+			org.Keys.Pub.GenerateUserMasterSecret(), rc)
+	}
+
+	// the issuance time of requested credentials needs to be current
+	cm, err := newCred(time.Now().Add(-2 * time.Hour))
+	require.NoError(t, err)
+	_, err = client.IssueCredential(context.Background(), cm, "policyKey1")
+	assert.True(t, errors.Is(err, ErrInvalidRequest), "unexpected error %v", err)
+
+	cm, err = newCred(time.Now())
+	require.NoError(t, err)
+	cred, err := client.IssueCredential(context.Background(), cm, "policyKey2")
+	require.NoError(t, err)
+
+	sessKey, err := client.ProveCredentialForPolicy(context.Background(), cm, cred, adult)
+	require.NoError(t, err)
+	assert.NotNil(t, sessKey)
+	// adult requires Age to be shown with Bulletproofs
+	_, err = client.ProveCredentialWithPredicates(context.Background(), cm, cred,
+		adult.AllPredicates())
+	assert.True(t, errors.Is(err, ErrPolicyNotSatisfied), "unexpected error %v", err)
+
+	// the credential is not issued under a key accepted by staff
+	_, err = client.ProveCredentialForPolicy(context.Background(), cm, cred, staff)
+	assert.True(t, errors.Is(err, ErrPolicyNotSatisfied), "unexpected error %v", err)
+	// nor is Age shown
+	_, err = client.ProveCredential(context.Background(), cm, cred, []string{"Name", "IssuedAt"})
+	assert.True(t, errors.Is(err, ErrPolicyNotSatisfied), "unexpected error %v", err)
+}
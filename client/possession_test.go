@@ -0,0 +1,111 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+)
+
+// TestPossessionProof transfers credentials to a server that requires proofs of
+// possession of the secret of the nym, bound to the organization.
+func TestPossessionProof(t *testing.T) {
+	config.Default().Set("pseudonymsys.transfer.require_possession", true)
+	defer config.Default().Set("pseudonymsys.transfer.require_possession", false)
+	_, conn := newTestServer(t, &mockRegKeyDB{data: []string{"possessionKey1",
+		"possessionKey2"}})
+	defer conn.Close()
+
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+	caClient, err := NewPseudonymsysCAClient(conn, group)
+	require.NoError(t, err)
+	c, err := NewPseudonymsysClient(conn, group)
+	require.NoError(t, err)
+	c.UseOrg("org1")
+
+	userSecret := c.GenerateMasterKey()
+	masterNym := caClient.GenerateMasterNym(userSecret)
+	caCert, err := caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+	require.NoError(t, err)
+	nym1, err := c.GenerateNym(context.Background(), userSecret, caCert, "possessionKey1")
+	require.NoError(t, err)
+	cred, err := c.ObtainCredential(context.Background(), userSecret, nym1,
+		config.LoadPseudonymsysOrgPubKeys("org1"))
+	require.NoError(t, err)
+	caCert, err = caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+	require.NoError(t, err)
+	nym2, err := c.GenerateNym(context.Background(), userSecret, caCert, "possessionKey2")
+	require.NoError(t, err)
+
+	sessionKey, err := c.TransferCredential(context.Background(), "org1", userSecret, nym2,
+		cred)
+	require.NoError(t, err)
+	assert.NotNil(t, sessionKey)
+
+	// proofs that do not name the organization are rejected
+	c.UseOrg("")
+	_, err = c.TransferCredential(context.Background(), "org1", userSecret, nym2, cred)
+	assert.True(t, errors.Is(err, ErrInvalidRequest), "unexpected error %v", err)
+}
+
+// TestPossessionProofEC is like TestPossessionProof, but for the pseudonym system in
+// EC arithmetic.
+func TestPossessionProofEC(t *testing.T) {
+	config.Default().Set("pseudonymsys.transfer.require_possession", true)
+	defer config.Default().Set("pseudonymsys.transfer.require_possession", false)
+	_, conn := newTestServer(t, &mockRegKeyDB{data: []string{"possessionKeyEC1",
+		"possessionKeyEC2"}})
+	defer conn.Close()
+
+	curve, err := config.LoadCurve("pseudonymsys")
+	require.NoError(t, err)
+	caClient, err := NewPseudonymsysCAClientEC(conn, curve)
+	require.NoError(t, err)
+	c, err := NewPseudonymsysClientEC(conn, curve)
+	require.NoError(t, err)
+	c.UseOrg("org1")
+
+	userSecret := c.GenerateMasterKey()
+	masterNym := caClient.GenerateMasterNym(userSecret)
+	caCert, err := caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+	require.NoError(t, err)
+	nym1, err := c.GenerateNym(context.Background(), userSecret, caCert, "possessionKeyEC1")
+	require.NoError(t, err)
+	cred, err := c.ObtainCredential(context.Background(), userSecret, nym1,
+		config.LoadPseudonymsysOrgPubKeysEC("org1"))
+	require.NoError(t, err)
+	caCert, err = caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+	require.NoError(t, err)
+	nym2, err := c.GenerateNym(context.Background(), userSecret, caCert, "possessionKeyEC2")
+	require.NoError(t, err)
+
+	sessionKey, err := c.TransferCredential(context.Background(), "org1", userSecret, nym2,
+		cred)
+	require.NoError(t, err)
+	assert.NotNil(t, sessionKey)
+
+	c.UseOrg("")
+	_, err = c.TransferCredential(context.Background(), "org1", userSecret, nym2, cred)
+	assert.True(t, errors.Is(err, ErrInvalidRequest), "unexpected error %v", err)
+}
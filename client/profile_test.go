@@ -0,0 +1,102 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+	"github.com/xlab-si/emmy/server"
+)
+
+// TestParameterProfiles runs protocols of the pseudonym system with parameters of
+// profiles that the server uses and that it does not use.
+func TestParameterProfiles(t *testing.T) {
+	srv, conn := newTestServer(t, &mockRegKeyDB{})
+	defer conn.Close()
+
+	// the tenant uses curve P-384, with a CA key on it
+	caKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	conf := newProfileTestConfig()
+	conf.Set("pseudonymsys.ec_profile", ec.CurvePresetP384)
+	conf.Set("pseudonymsys.ca", map[string]interface{}{
+		"d":  caKey.D.String(),
+		"x":  caKey.X.String(),
+		"y1": caKey.Y.String(),
+	})
+	tenant, err := server.NewTenant("p384", conf)
+	require.NoError(t, err)
+	srv.AddTenant(tenant)
+
+	caClient, err := NewPseudonymsysCAClientEC(conn, ec.P384)
+	require.NoError(t, err)
+	caClient.UseTenant("p384")
+	secret := common.GetRandomInt(ec.NewGroup(ec.P384).Q)
+	_, err = caClient.GenerateCertificate(context.Background(), secret,
+		caClient.GenerateMasterNym(secret))
+	assert.NoError(t, err)
+
+	// the server uses P-256 by default
+	caClient.UseTenant("")
+	_, err = caClient.GenerateCertificate(context.Background(), secret,
+		caClient.GenerateMasterNym(secret))
+	assert.True(t, errors.Is(err, ErrUnsupportedProfile), "unexpected error %v", err)
+
+	// the server uses a group that does not belong to a profile
+	group, err := schnorr.GetGroupPreset(schnorr.GroupPreset2048)
+	require.NoError(t, err)
+	dlogCAClient, err := NewPseudonymsysCAClient(conn, group)
+	require.NoError(t, err)
+	secret = common.GetRandomInt(group.Q)
+	_, err = dlogCAClient.GenerateCertificate(context.Background(), secret,
+		dlogCAClient.GenerateMasterNym(secret))
+	assert.True(t, errors.Is(err, ErrUnsupportedProfile), "unexpected error %v", err)
+
+	// tenants with invalid group parameters are rejected
+	conf = newProfileTestConfig()
+	conf.Set("pseudonymsys.group", map[string]string{
+		"p": group.P.String(),
+		"g": "1",
+		"q": group.Q.String(),
+	})
+	_, err = server.NewTenant("invalid", conf)
+	assert.Error(t, err)
+	conf = newProfileTestConfig()
+	conf.Set("pseudonymsys.ec_profile", "p192")
+	_, err = server.NewTenant("invalid", conf)
+	assert.Error(t, err)
+}
+
+// newProfileTestConfig returns a configuration of a tenant, to which parameters of the
+// pseudonym system are added.
+func newProfileTestConfig() *config.Config {
+	conf := config.New()
+	conf.Set("attributes", map[string]string{"0": "Name, string, true"})
+	return conf
+}
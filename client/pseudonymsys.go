@@ -18,6 +18,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -32,6 +33,7 @@ type PseudonymsysClient struct {
 	genericClient
 	grpcClient pb.PseudonymSystemClient
 	group      *schnorr.Group
+	org        string
 }
 
 func NewPseudonymsysClient(conn *grpc.ClientConn,
@@ -43,6 +45,13 @@ func NewPseudonymsysClient(conn *grpc.ClientConn,
 	}, nil
 }
 
+// UseOrg sets the organization of emmy server that the client obtains credentials
+// from and transfers credentials to. When not set, the server's default organization
+// is used.
+func (c *PseudonymsysClient) UseOrg(name string) {
+	c.org = name
+}
+
 // GenerateMasterKey generates a master secret key, representing a random integer betweeen
 // 0 and order of the group. This key will be used subsequently by all the protocols in the scheme.
 func (c *PseudonymsysClient) GenerateMasterKey() *big.Int {
@@ -51,10 +60,10 @@ func (c *PseudonymsysClient) GenerateMasterKey() *big.Int {
 
 // GenerateNym generates a nym and registers it to the organization. Do not use
 // the same CACert for different organizations - use it only once!
-func (c *PseudonymsysClient) GenerateNym(userSecret *big.Int,
+func (c *PseudonymsysClient) GenerateNym(ctx context.Context, userSecret *big.Int,
 	caCertificate *pseudsys.CACert, regKey string) (
 	*pseudsys.Nym, error) {
-	if err := c.openStream(c.grpcClient, "GenerateNym"); err != nil {
+	if err := c.openStream(ctx, c.grpcClient, "GenerateNym"); err != nil {
 		return nil, err
 	}
 	defer c.closeStream()
@@ -88,6 +97,7 @@ func (c *PseudonymsysClient) GenerateNym(userSecret *big.Int,
 
 	initMsg := &pb.Message{
 		ClientId: c.id,
+		Profile:  schnorr.GroupPresetName(c.group),
 		Content: &pb.Message_PseudonymsysNymGenProofRandomData{
 			&pRandomData,
 		},
@@ -97,8 +107,11 @@ func (c *PseudonymsysClient) GenerateNym(userSecret *big.Int,
 		return nil, err
 	}
 
-	pedersenDecommitment := resp.GetPedersenDecommitment()
-	challenge := new(big.Int).SetBytes(pedersenDecommitment.X)
+	d := pb.NewSchnorrDecoder(c.group)
+	challenge := d.Exponent("challenge", resp.GetPedersenDecommitment().GetX())
+	if err := d.Err(); err != nil {
+		return nil, invalidResponse(err)
+	}
 
 	z := prover.GetProofData(challenge)
 
@@ -124,11 +137,55 @@ func (c *PseudonymsysClient) GenerateNym(userSecret *big.Int,
 	}
 }
 
+// GenerateNymNI generates a nym and registers it to the organization like GenerateNym,
+// but proves that log_nymA(nymB) = log_blindedA(blindedB) with a non-interactive
+// proof, bound to a new context, in a single RPC.
+func (c *PseudonymsysClient) GenerateNymNI(ctx context.Context, userSecret *big.Int,
+	caCertificate *pseudsys.CACert, regKey string) (*pseudsys.Nym, error) {
+	gamma := common.GetRandomInt(c.group.Q)
+	nymA := c.group.Exp(c.group.G, gamma)
+	nymB := c.group.Exp(nymA, userSecret)
+
+	niContext := pb.NewNIContext()
+	proof := schnorr.ProveDLogEquality(c.group, userSecret, nymA, caCertificate.BlindedA,
+		nymB, caCertificate.BlindedB, niContext.Value(pb.GenerateNymNIMethod))
+	req := pb.ToPbPseudonymsysNymGenProofNI(nymA, nymB, caCertificate.BlindedA,
+		caCertificate.BlindedB, caCertificate.R, caCertificate.S, regKey, proof, niContext)
+
+	var status *pb.Status
+	var err error
+	ctx = c.withTenant(ctx)
+	if i, ok := c.invoker(); ok {
+		status = new(pb.Status)
+		err = i.Invoke(ctx, pb.GenerateNymNIMethod, req, status)
+	} else {
+		status, err = c.grpcClient.GenerateNymNI(ctx, req)
+	}
+	if err != nil {
+		return nil, wrapError("unable to generate nym", err)
+	}
+	if !status.Success {
+		return nil, fmt.Errorf("proof for nym registration failed")
+	}
+
+	return pseudsys.NewNym(nymA, nymB), nil
+}
+
 // ObtainCredential returns anonymous credential.
-func (c *PseudonymsysClient) ObtainCredential(userSecret *big.Int,
+func (c *PseudonymsysClient) ObtainCredential(ctx context.Context, userSecret *big.Int,
 	nym *pseudsys.Nym, orgPubKeys *pseudsys.PubKey) (
 	*pseudsys.Cred, error) {
-	if err := c.openStream(c.grpcClient, "ObtainCredential"); err != nil {
+	return c.ObtainDerivedCredential(ctx, userSecret, nym, orgPubKeys, nil)
+}
+
+// ObtainDerivedCredential is like ObtainCredential, but the organization derives the
+// credential from credentials of chain, the last of which was issued by an organization
+// it accepts. The credential is to be transferred along with chain extended with
+// the link of the last credential, see TransferCredentialChain.
+func (c *PseudonymsysClient) ObtainDerivedCredential(ctx context.Context, userSecret *big.Int,
+	nym *pseudsys.Nym, orgPubKeys *pseudsys.PubKey, chain []*pseudsys.ChainLink) (
+	*pseudsys.Cred, error) {
+	if err := c.openStream(ctx, c.grpcClient, "ObtainCredential"); err != nil {
 		return nil, err
 	}
 	defer c.closeStream()
@@ -138,21 +195,23 @@ func (c *PseudonymsysClient) ObtainCredential(userSecret *big.Int,
 	equalityVerifier2 := schnorr.NewBTEqualityVerifier(c.group, gamma)
 
 	// First we need to authenticate - prove that we know dlog_a(b) where (a, b) is a nym registered
-	// with this organization. Authentication is done via Schnorr.
-	schnorrProver, err := schnorr.NewProver(c.group, []*big.Int{userSecret}, []*big.Int{nym.A}, nym.B)
-	if err != nil {
-		return nil, err
-	}
-	x := schnorrProver.GetProofRandomData()
+	// with this organization. Authentication is done via Schnorr, where the same proof
+	// shows that credentials of the chain were issued to the same secret.
+	prover := pseudsys.NewChainProver(c.group)
+	x := prover.GetProofRandomData(userSecret,
+		append([]*big.Int{nym.A}, pseudsys.ChainBases(chain)...)...)
 
 	pRandomData := pb.SchnorrProofRandomData{
-		X: x.Bytes(),
-		A: nym.A.Bytes(),
-		B: nym.B.Bytes(),
+		X:       x[0].Bytes(),
+		A:       nym.A.Bytes(),
+		B:       nym.B.Bytes(),
+		OrgName: c.org,
+		Chain:   pb.ToPbPseudonymsysChain(chain, x[1:]),
 	}
 
 	initMsg := &pb.Message{
 		ClientId: c.id,
+		Profile:  schnorr.GroupPresetName(c.group),
 		Content: &pb.Message_SchnorrProofRandomData{
 			&pRandomData,
 		},
@@ -162,10 +221,13 @@ func (c *PseudonymsysClient) ObtainCredential(userSecret *big.Int,
 		return nil, err
 	}
 
-	ch := resp.GetBigint()
-	challenge := new(big.Int).SetBytes(ch.X1)
+	d := pb.NewSchnorrDecoder(c.group)
+	challenge := d.Exponent("challenge", resp.GetBigint().GetX1())
+	if err := d.Err(); err != nil {
+		return nil, invalidResponse(err)
+	}
 
-	z := schnorrProver.GetProofData(challenge)[0]
+	z := prover.GetProofData(challenge)
 	msg := &pb.Message{
 		Content: &pb.Message_Bigint{
 			&pb.BigInt{
@@ -184,12 +246,15 @@ func (c *PseudonymsysClient) ObtainCredential(userSecret *big.Int,
 	// And to prove that it knows log_aA(B), log_g(h1) and log_aA(B) = log_g(h1).
 	// g1 = dlog.G, g2 = nym.B, t1 = A, t2 = orgPubKeys.H2
 
-	x11 := new(big.Int).SetBytes(randomData.X11)
-	x12 := new(big.Int).SetBytes(randomData.X12)
-	x21 := new(big.Int).SetBytes(randomData.X21)
-	x22 := new(big.Int).SetBytes(randomData.X22)
-	A := new(big.Int).SetBytes(randomData.A)
-	B := new(big.Int).SetBytes(randomData.B)
+	x11 := d.Element("X11", randomData.GetX11())
+	x12 := d.Element("X12", randomData.GetX12())
+	x21 := d.Element("X21", randomData.GetX21())
+	x22 := d.Element("X22", randomData.GetX22())
+	A := d.Element("A", randomData.GetA())
+	B := d.Element("B", randomData.GetB())
+	if err := d.Err(); err != nil {
+		return nil, invalidResponse(err)
+	}
 
 	challenge1 := equalityVerifier1.GetChallenge(c.group.G, nym.B, orgPubKeys.H2, A, x11, x12)
 	aA := c.group.Mul(nym.A, A)
@@ -210,8 +275,11 @@ func (c *PseudonymsysClient) ObtainCredential(userSecret *big.Int,
 	}
 
 	proofData := resp.GetDoubleBigint()
-	z1 := new(big.Int).SetBytes(proofData.X1)
-	z2 := new(big.Int).SetBytes(proofData.X2)
+	z1 := d.Exponent("Z1", proofData.GetX1())
+	z2 := d.Exponent("Z2", proofData.GetX2())
+	if err := d.Err(); err != nil {
+		return nil, invalidResponse(err)
+	}
 
 	verified1, transcript1, bToGamma, AToGamma := equalityVerifier1.Verify(z1)
 	verified2, transcript2, aAToGamma, BToGamma := equalityVerifier2.Verify(z2)
@@ -236,9 +304,18 @@ func (c *PseudonymsysClient) ObtainCredential(userSecret *big.Int,
 // TransferCredential transfers orgName's credential to organization where the
 // authentication should happen (the organization takes credential issued by
 // another organization).
-func (c *PseudonymsysClient) TransferCredential(orgName string, userSecret *big.Int,
+func (c *PseudonymsysClient) TransferCredential(ctx context.Context, orgName string, userSecret *big.Int,
 	nym *pseudsys.Nym, credential *pseudsys.Cred) (*pb.SessionKey, error) {
-	if err := c.openStream(c.grpcClient, "TransferCredential"); err != nil {
+	return c.TransferCredentialChain(ctx, orgName, userSecret, nym, credential, nil)
+}
+
+// TransferCredentialChain is like TransferCredential, but it also proves chain of
+// credentials that credential was derived from (see ObtainDerivedCredential), so
+// that the organization verifies the whole chain in the same session.
+func (c *PseudonymsysClient) TransferCredentialChain(ctx context.Context, orgName string,
+	userSecret *big.Int, nym *pseudsys.Nym, credential *pseudsys.Cred,
+	chain []*pseudsys.ChainLink) (*pb.SessionKey, error) {
+	if err := c.openStream(ctx, c.grpcClient, "TransferCredential"); err != nil {
 		return nil, err
 	}
 	defer c.closeStream()
@@ -246,40 +323,39 @@ func (c *PseudonymsysClient) TransferCredential(orgName string, userSecret *big.
 	// First we need to authenticate - prove that we know dlog_a(b) where (a, b) is a nym registered
 	// with this organization. But we need also to prove that dlog_a(b) = dlog_a2(b2), where
 	// a2, b2 are a1, b1 exponentiated to gamma, and (a1, b1) is a nym for organization that
-	// issued a credential. So we can do both proofs at the same time using EqualityProver.
-	equalityProver := schnorr.NewEqualityProver(c.group)
-	x1, x2 := equalityProver.GetProofRandomData(userSecret, nym.A, credential.SmallAToGamma)
-
-	transcript1 := &pb.PseudonymsysTranscript{
-		A:      credential.T1.A.Bytes(),
-		B:      credential.T1.B.Bytes(),
-		Hash:   credential.T1.Hash.Bytes(),
-		ZAlpha: credential.T1.ZAlpha.Bytes(),
-	}
-	transcript2 := &pb.PseudonymsysTranscript{
-		A:      credential.T2.A.Bytes(),
-		B:      credential.T2.B.Bytes(),
-		Hash:   credential.T2.Hash.Bytes(),
-		ZAlpha: credential.T2.ZAlpha.Bytes(),
-	}
-	pbCredential := &pb.PseudonymsysCredential{
-		SmallAToGamma: credential.SmallAToGamma.Bytes(),
-		SmallBToGamma: credential.SmallBToGamma.Bytes(),
-		AToGamma:      credential.AToGamma.Bytes(),
-		BToGamma:      credential.BToGamma.Bytes(),
-		T1:            transcript1,
-		T2:            transcript2,
+	// issued a credential. So we can do both proofs at the same time, along with the proofs
+	// that credentials of the chain belong to the same secret, using ChainProver.
+	prover := pseudsys.NewChainProver(c.group)
+	x := prover.GetProofRandomData(userSecret, append([]*big.Int{nym.A,
+		credential.SmallAToGamma}, pseudsys.ChainBases(chain)...)...)
+	x1, x2 := x[0], x[1]
+
+	// Servers can also require a proof of possession of the secret of the nym, bound to
+	// the organization and the time of the transfer, so that the transcript cannot be
+	// replayed to another organization.
+	niContext := pb.NewNIContext()
+	niContext.Verifier = c.org
+	possession, err := pseudsys.ProvePossession(c.group, userSecret, nym,
+		niContext.Value(pb.TransferCredentialMethod))
+	if err != nil {
+		return nil, err
 	}
+
 	initMsg := &pb.Message{
 		ClientId: c.id,
+		Profile:  schnorr.GroupPresetName(c.group),
 		Content: &pb.Message_PseudonymsysTransferCredentialData{
 			&pb.PseudonymsysTransferCredentialData{
-				OrgName:    orgName,
-				X1:         x1.Bytes(),
-				X2:         x2.Bytes(),
-				NymA:       nym.A.Bytes(),
-				NymB:       nym.B.Bytes(),
-				Credential: pbCredential,
+				OrgName:       orgName,
+				TargetOrgName: c.org,
+				X1:            x1.Bytes(),
+				X2:            x2.Bytes(),
+				NymA:          nym.A.Bytes(),
+				NymB:          nym.B.Bytes(),
+				Credential:    pb.ToPbPseudonymsysCredential(credential),
+				Possession:    pb.ToPbFiatShamir(possession),
+				Context:       niContext,
+				Chain:         pb.ToPbPseudonymsysChain(chain, x[2:]),
 			},
 		},
 	}
@@ -288,10 +364,13 @@ func (c *PseudonymsysClient) TransferCredential(orgName string, userSecret *big.
 		return nil, err
 	}
 
-	ch := resp.GetBigint()
-	challenge := new(big.Int).SetBytes(ch.X1)
+	d := pb.NewSchnorrDecoder(c.group)
+	challenge := d.Exponent("challenge", resp.GetBigint().GetX1())
+	if err := d.Err(); err != nil {
+		return nil, invalidResponse(err)
+	}
 
-	z := equalityProver.GetProofData(challenge)
+	z := prover.GetProofData(challenge)
 	msg := &pb.Message{
 		Content: &pb.Message_Bigint{
 			&pb.BigInt{
@@ -309,5 +388,6 @@ func (c *PseudonymsysClient) TransferCredential(orgName string, userSecret *big.
 		return nil, err
 	}
 
+	c.idToken = resp.GetSessionKey().GetIdToken()
 	return resp.GetSessionKey(), nil
 }
@@ -18,6 +18,7 @@
 package client
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/xlab-si/emmy/crypto/pseudsys"
@@ -51,10 +52,10 @@ func (c *PseudonymsysCAClient) GenerateMasterNym(secret *big.Int) *pseudsys.Nym
 // GenerateCertificate provides a certificate from trusted CA to the user. Note that CA
 // needs to know the user. The certificate is then used for registering pseudonym (nym).
 // The certificate contains blinded user's master key pair and a signature of it.
-func (c *PseudonymsysCAClient) GenerateCertificate(userSecret *big.Int, nym *pseudsys.Nym) (
+func (c *PseudonymsysCAClient) GenerateCertificate(ctx context.Context, userSecret *big.Int, nym *pseudsys.Nym) (
 	*pseudsys.CACert, error) {
 
-	if err := c.openStream(c.grpcClient, "GenerateCertificate"); err != nil {
+	if err := c.openStream(ctx, c.grpcClient, "GenerateCertificate"); err != nil {
 		return nil, err
 	}
 	defer c.closeStream()
@@ -74,6 +75,7 @@ func (c *PseudonymsysCAClient) GenerateCertificate(userSecret *big.Int, nym *pse
 
 	initMsg := &pb.Message{
 		ClientId: c.id,
+		Profile:  schnorr.GroupPresetName(c.group),
 		Content: &pb.Message_SchnorrProofRandomData{
 			&pRandomData,
 		},
@@ -83,8 +85,11 @@ func (c *PseudonymsysCAClient) GenerateCertificate(userSecret *big.Int, nym *pse
 		return nil, err
 	}
 
-	ch := resp.GetBigint()
-	challenge := new(big.Int).SetBytes(ch.X1)
+	d := pb.NewSchnorrDecoder(c.group)
+	challenge := d.Exponent("challenge", resp.GetBigint().GetX1())
+	if err := d.Err(); err != nil {
+		return nil, invalidResponse(err)
+	}
 
 	z := c.prover.GetProofData(challenge)[0]
 	msg := &pb.Message{
@@ -99,10 +104,43 @@ func (c *PseudonymsysCAClient) GenerateCertificate(userSecret *big.Int, nym *pse
 	if err != nil {
 		return nil, err
 	}
-	cert := resp.GetPseudonymsysCaCertificate()
-	certificate := pseudsys.NewCACert(
-		new(big.Int).SetBytes(cert.BlindedA), new(big.Int).SetBytes(cert.BlindedB),
-		new(big.Int).SetBytes(cert.R), new(big.Int).SetBytes(cert.S))
+	certificate, err := resp.GetPseudonymsysCaCertificate().GetNativeType(c.group)
+	if err != nil {
+		return nil, invalidResponse(err)
+	}
+
+	return certificate, nil
+}
+
+// GenerateCertificateNI provides a certificate like GenerateCertificate, but proves
+// knowledge of userSecret with a non-interactive proof, bound to a new context, in
+// a single RPC. It suits CAs that run on a host of their own, apart from organizations.
+func (c *PseudonymsysCAClient) GenerateCertificateNI(ctx context.Context, userSecret *big.Int,
+	nym *pseudsys.Nym) (*pseudsys.CACert, error) {
+	niContext := pb.NewNIContext()
+	proof, err := schnorr.ProveDLogKnowledge(c.group, []*big.Int{userSecret},
+		[]*big.Int{nym.A}, nym.B, niContext.Value(pb.GenerateCertificateNIMethod))
+	if err != nil {
+		return nil, err
+	}
+	req := pb.ToPbPseudonymsysCACertificateRequestNI(nym.A, nym.B, proof, niContext)
+
+	var resp *pb.PseudonymsysCACertificate
+	ctx = c.withTenant(ctx)
+	if i, ok := c.invoker(); ok {
+		resp = new(pb.PseudonymsysCACertificate)
+		err = i.Invoke(ctx, pb.GenerateCertificateNIMethod, req, resp)
+	} else {
+		resp, err = c.grpcClient.GenerateCertificateNI(ctx, req)
+	}
+	if err != nil {
+		return nil, wrapError("unable to generate certificate", err)
+	}
+
+	certificate, err := resp.GetNativeType(c.group)
+	if err != nil {
+		return nil, invalidResponse(err)
+	}
 
 	return certificate, nil
 }
@@ -18,6 +18,7 @@
 package client
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/xlab-si/emmy/crypto/ec"
@@ -54,9 +55,9 @@ func (c *PseudonymsysCAClientEC) GenerateMasterNym(secret *big.Int) *ecpseudsys.
 // GenerateCertificate provides a certificate from trusted CA to the user. Note that CA
 // needs to know the user. The certificate is then used for registering pseudonym (nym).
 // The certificate contains blinded user's master key pair and a signature of it.
-func (c *PseudonymsysCAClientEC) GenerateCertificate(userSecret *big.Int, nym *ecpseudsys.Nym) (
+func (c *PseudonymsysCAClientEC) GenerateCertificate(ctx context.Context, userSecret *big.Int, nym *ecpseudsys.Nym) (
 	*ecpseudsys.CACert, error) {
-	if err := c.openStream(c.grpcClient, "GenerateCertificate_EC"); err != nil {
+	if err := c.openStream(ctx, c.grpcClient, "GenerateCertificate_EC"); err != nil {
 		return nil, err
 	}
 	defer c.closeStream()
@@ -70,6 +71,7 @@ func (c *PseudonymsysCAClientEC) GenerateCertificate(userSecret *big.Int, nym *e
 
 	initMsg := &pb.Message{
 		ClientId: c.id,
+		Profile:  ec.CurvePresetName(c.curve),
 		Content: &pb.Message_SchnorrEcProofRandomData{
 			&pRandomData,
 		},
@@ -79,8 +81,11 @@ func (c *PseudonymsysCAClientEC) GenerateCertificate(userSecret *big.Int, nym *e
 		return nil, err
 	}
 
-	ch := resp.GetBigint()
-	challenge := new(big.Int).SetBytes(ch.X1)
+	d := pb.NewECDecoder(c.curve)
+	challenge := d.Exponent("challenge", resp.GetBigint().GetX1())
+	if err := d.Err(); err != nil {
+		return nil, invalidResponse(err)
+	}
 
 	z := c.prover.GetProofData(challenge)
 	msg := &pb.Message{
@@ -98,9 +103,12 @@ func (c *PseudonymsysCAClientEC) GenerateCertificate(userSecret *big.Int, nym *e
 
 	cert := resp.GetPseudonymsysCaCertificateEc()
 	certificate := ecpseudsys.NewCACert(
-		cert.BlindedA.GetNativeType(),
-		cert.BlindedB.GetNativeType(),
-		new(big.Int).SetBytes(cert.R), new(big.Int).SetBytes(cert.S))
+		d.ECElement("BlindedA", cert.GetBlindedA()),
+		d.ECElement("BlindedB", cert.GetBlindedB()),
+		d.Int("R", cert.GetR()), d.Int("S", cert.GetS()))
+	if err := d.Err(); err != nil {
+		return nil, invalidResponse(err)
+	}
 
 	if err := c.genericClient.CloseSend(); err != nil {
 		return nil, err
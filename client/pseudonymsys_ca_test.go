@@ -0,0 +1,110 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+)
+
+// TestStandaloneCA obtains certificates from a server that only runs the CA, with the
+// key of the CA in a key store of its own, and registers nyms with them at another
+// server.
+func TestStandaloneCA(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emmy-ca-keystore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ks := crypto.NewFileKeyStore(dir)
+	caPubKey := config.LoadPseudonymsysCAPubKey()
+	require.NoError(t, ks.StoreECDSAKey(server.KeyLabelPseudonymsysCA, &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: ec.GetCurve(ec.P256), X: caPubKey.H1, Y: caPubKey.H2},
+		D:         config.LoadPseudonymsysCASecret(),
+	}))
+
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	_, err = server.New(server.WithTLS("testdata/server.pem", "testdata/server.key"),
+		server.WithLogger(logger), server.WithServices("NoSuchService"))
+	assert.Error(t, err)
+	caSrv, err := server.New(server.WithTLS("testdata/server.pem", "testdata/server.key"),
+		server.WithLogger(logger), server.WithServices("PseudonymSystemCA"))
+	require.NoError(t, err)
+	caSrv.UseCAKeyStore(ks)
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go caSrv.GrpcServer.Serve(listener)
+	defer caSrv.Teardown()
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	caConn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+	defer caConn.Close()
+
+	orgSrv, conn := newTestServer(t, &mockRegKeyDB{data: []string{"caKey1", "caKey2"}})
+	defer orgSrv.Teardown()
+	defer conn.Close()
+
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+	caClient, err := NewPseudonymsysCAClient(caConn, group)
+	require.NoError(t, err)
+	c, err := NewPseudonymsysClient(conn, group)
+	require.NoError(t, err)
+	userSecret := c.GenerateMasterKey()
+	masterNym := caClient.GenerateMasterNym(userSecret)
+
+	_, err = caClient.GenerateCertificateNI(context.Background(), big.NewInt(3952123123),
+		masterNym)
+	assert.True(t, errors.Is(err, ErrInvalidProof), "unexpected error %v", err)
+
+	// certificates issued non-interactively and over the stream are both accepted
+	// by the organization
+	caCert, err := caClient.GenerateCertificateNI(context.Background(), userSecret, masterNym)
+	require.NoError(t, err)
+	nym, err := c.GenerateNym(context.Background(), userSecret, caCert, "caKey1")
+	require.NoError(t, err)
+	_, err = c.ObtainCredential(context.Background(), userSecret, nym,
+		config.LoadPseudonymsysOrgPubKeys("org1"))
+	require.NoError(t, err)
+
+	caCert, err = caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+	require.NoError(t, err)
+	_, err = c.GenerateNymNI(context.Background(), userSecret, caCert, "caKey2")
+	require.NoError(t, err)
+
+	// the CA does not run services of organizations
+	caOrgClient, err := NewPseudonymsysClient(caConn, group)
+	require.NoError(t, err)
+	_, err = caOrgClient.GenerateNym(context.Background(), userSecret, caCert, "caKey2")
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+)
+
+// TestCredentialChain derives credentials of org2 from credentials of org1, and
+// credentials of org3 from credentials of org2, and transfers a credential of org3
+// along with its chain.
+func TestCredentialChain(t *testing.T) {
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+
+	org2SecKey, org2PubKey := pseudsys.GenerateKeyPair(group)
+	org3SecKey, org3PubKey := pseudsys.GenerateKeyPair(group)
+	load := func(name string) (*server.OrgKeys, error) {
+		switch name {
+		case "org2":
+			return &server.OrgKeys{SecKey: org2SecKey, PubKey: org2PubKey,
+				Accepts: []string{"org1"}}, nil
+		case "org3":
+			return &server.OrgKeys{SecKey: org3SecKey, PubKey: org3PubKey,
+				Accepts: []string{"org2"}}, nil
+		}
+		return server.LoadOrgKeysFromConfig(name)
+	}
+	orgs, err := server.NewOrgRegistry(load, "org1", "org2", "org3")
+	require.NoError(t, err)
+
+	var regKeys []string
+	for i := 0; i < 10; i++ {
+		regKeys = append(regKeys, fmt.Sprintf("chainKey%d", i))
+	}
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{data: regKeys}, cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+	srv.UseOrgRegistry(orgs)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	defer srv.Teardown()
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	conn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	caClient, err := NewPseudonymsysCAClient(conn, group)
+	require.NoError(t, err)
+	c, err := NewPseudonymsysClient(conn, group)
+	require.NoError(t, err)
+	userSecret := c.GenerateMasterKey()
+	masterNym := caClient.GenerateMasterNym(userSecret)
+	regKey := 0
+	newNym := func(secret *big.Int, masterNym *pseudsys.Nym) *pseudsys.Nym {
+		caCert, err := caClient.GenerateCertificate(context.Background(), secret, masterNym)
+		require.NoError(t, err)
+		nym, err := c.GenerateNym(context.Background(), secret, caCert, regKeys[regKey])
+		require.NoError(t, err)
+		regKey++
+		return nym
+	}
+
+	c.UseOrg("org1")
+	cred1, err := c.ObtainCredential(context.Background(), userSecret,
+		newNym(userSecret, masterNym), config.LoadPseudonymsysOrgPubKeys("org1"))
+	require.NoError(t, err)
+
+	// org2 issues only credentials derived from credentials of org1
+	c.UseOrg("org2")
+	nym2 := newNym(userSecret, masterNym)
+	_, err = c.ObtainCredential(context.Background(), userSecret, nym2, org2PubKey)
+	assert.True(t, errors.Is(err, ErrInvalidRequest), "unexpected error %v", err)
+	chain1 := []*pseudsys.ChainLink{pseudsys.NewChainLink("org1", cred1)}
+	cred2, err := c.ObtainDerivedCredential(context.Background(), userSecret, nym2,
+		org2PubKey, chain1)
+	require.NoError(t, err)
+
+	// credentials of another user are rejected
+	otherSecret := c.GenerateMasterKey()
+	otherMasterNym := caClient.GenerateMasterNym(otherSecret)
+	c.UseOrg("org1")
+	otherCred, err := c.ObtainCredential(context.Background(), otherSecret,
+		newNym(otherSecret, otherMasterNym), config.LoadPseudonymsysOrgPubKeys("org1"))
+	require.NoError(t, err)
+	c.UseOrg("org2")
+	_, err = c.ObtainDerivedCredential(context.Background(), userSecret, nym2, org2PubKey,
+		[]*pseudsys.ChainLink{pseudsys.NewChainLink("org1", otherCred)})
+	assert.True(t, errors.Is(err, ErrInvalidProof), "unexpected error %v", err)
+
+	c.UseOrg("org3")
+	chain2 := append(chain1, pseudsys.NewChainLink("org2", cred2))
+	cred3, err := c.ObtainDerivedCredential(context.Background(), userSecret,
+		newNym(userSecret, masterNym), org3PubKey, chain2)
+	require.NoError(t, err)
+
+	// the whole chain is verified when the credential of org3 is transferred
+	c.UseOrg("org1")
+	nym := newNym(userSecret, masterNym)
+	sessKey, err := c.TransferCredentialChain(context.Background(), "org3", userSecret, nym,
+		cred3, chain2)
+	require.NoError(t, err)
+	assert.NotNil(t, sessKey)
+	_, err = c.TransferCredential(context.Background(), "org3", userSecret, nym, cred3)
+	assert.True(t, errors.Is(err, ErrInvalidRequest), "unexpected error %v", err)
+	// org3 does not accept credentials of org1
+	_, err = c.TransferCredentialChain(context.Background(), "org3", userSecret, nym, cred3,
+		chain1)
+	assert.True(t, errors.Is(err, ErrInvalidRequest), "unexpected error %v", err)
+	// credentials of org1 are not derived, and thus transferred without a chain
+	sessKey, err = c.TransferCredential(context.Background(), "org1", userSecret, nym, cred1)
+	require.NoError(t, err)
+	assert.NotNil(t, sessKey)
+}
@@ -18,6 +18,7 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -33,6 +34,7 @@ type PseudonymsysClientEC struct {
 	genericClient
 	grpcClient pb.PseudonymSystemClient
 	curve      ec.Curve
+	org        string
 }
 
 func NewPseudonymsysClientEC(conn *grpc.ClientConn, curve ec.Curve) (*PseudonymsysClientEC, error) {
@@ -43,6 +45,13 @@ func NewPseudonymsysClientEC(conn *grpc.ClientConn, curve ec.Curve) (*Pseudonyms
 	}, nil
 }
 
+// UseOrg sets the organization of emmy server that the client obtains credentials
+// from and transfers credentials to. When not set, the server's default organization
+// is used.
+func (c *PseudonymsysClientEC) UseOrg(name string) {
+	c.org = name
+}
+
 // GenerateMasterKey generates a master secret key to be used subsequently by all the
 // protocols in the scheme.
 func (c *PseudonymsysClientEC) GenerateMasterKey() *big.Int {
@@ -52,10 +61,10 @@ func (c *PseudonymsysClientEC) GenerateMasterKey() *big.Int {
 
 // GenerateNym generates a nym and registers it to the organization. Do not
 // use the same CACert for different organizations - use it only once!
-func (c *PseudonymsysClientEC) GenerateNym(userSecret *big.Int,
+func (c *PseudonymsysClientEC) GenerateNym(ctx context.Context, userSecret *big.Int,
 	caCertificate *ecpseudsys.CACert, regKey string) (
 	*ecpseudsys.Nym, error) {
-	if err := c.openStream(c.grpcClient, "GenerateNym_EC"); err != nil {
+	if err := c.openStream(ctx, c.grpcClient, "GenerateNym_EC"); err != nil {
 		return nil, err
 	}
 	defer c.closeStream()
@@ -94,6 +103,7 @@ func (c *PseudonymsysClientEC) GenerateNym(userSecret *big.Int,
 
 	initMsg := &pb.Message{
 		ClientId: c.id,
+		Profile:  ec.CurvePresetName(c.curve),
 		Content: &pb.Message_PseudonymsysNymGenProofRandomDataEc{
 			&pRandomData,
 		},
@@ -103,8 +113,11 @@ func (c *PseudonymsysClientEC) GenerateNym(userSecret *big.Int,
 		return nil, err
 	}
 
-	pedersenDecommitment := resp.GetPedersenDecommitment()
-	challenge := new(big.Int).SetBytes(pedersenDecommitment.X)
+	d := pb.NewECDecoder(c.curve)
+	challenge := d.Exponent("challenge", resp.GetPedersenDecommitment().GetX())
+	if err := d.Err(); err != nil {
+		return nil, invalidResponse(err)
+	}
 
 	z := prover.GetProofData(challenge)
 
@@ -136,10 +149,10 @@ func (c *PseudonymsysClientEC) GenerateNym(userSecret *big.Int,
 }
 
 // ObtainCredential returns anonymous credential.
-func (c *PseudonymsysClientEC) ObtainCredential(userSecret *big.Int,
+func (c *PseudonymsysClientEC) ObtainCredential(ctx context.Context, userSecret *big.Int,
 	nym *ecpseudsys.Nym, orgPubKeys *ecpseudsys.PubKey) (
 	*ecpseudsys.Cred, error) {
-	if err := c.openStream(c.grpcClient, "ObtainCredential_EC"); err != nil {
+	if err := c.openStream(ctx, c.grpcClient, "ObtainCredential_EC"); err != nil {
 		return nil, err
 	}
 	defer c.closeStream()
@@ -151,13 +164,15 @@ func (c *PseudonymsysClientEC) ObtainCredential(userSecret *big.Int,
 	x := schnorrProver.GetProofRandomData(userSecret, nym.A)
 
 	pRandomData := pb.SchnorrECProofRandomData{
-		X: pb.ToPbECGroupElement(x),
-		A: pb.ToPbECGroupElement(nym.A),
-		B: pb.ToPbECGroupElement(nym.B),
+		X:       pb.ToPbECGroupElement(x),
+		A:       pb.ToPbECGroupElement(nym.A),
+		B:       pb.ToPbECGroupElement(nym.B),
+		OrgName: c.org,
 	}
 
 	initMsg := &pb.Message{
 		ClientId: c.id,
+		Profile:  ec.CurvePresetName(c.curve),
 		Content: &pb.Message_SchnorrEcProofRandomData{
 			&pRandomData,
 		},
@@ -167,8 +182,11 @@ func (c *PseudonymsysClientEC) ObtainCredential(userSecret *big.Int,
 		return nil, err
 	}
 
-	ch := resp.GetBigint()
-	challenge := new(big.Int).SetBytes(ch.X1)
+	d := pb.NewECDecoder(c.curve)
+	challenge := d.Exponent("challenge", resp.GetBigint().GetX1())
+	if err := d.Err(); err != nil {
+		return nil, invalidResponse(err)
+	}
 
 	z := schnorrProver.GetProofData(challenge)
 	msg := &pb.Message{
@@ -189,12 +207,15 @@ func (c *PseudonymsysClientEC) ObtainCredential(userSecret *big.Int,
 	// And to prove that it knows log_aA(B), log_g(h1) and log_aA(B) = log_g(h1).
 	// g1 = dlog.G, g2 = nym.B, t1 = A, t2 = orgPubKeys.H2
 
-	x11 := randomData.X11.GetNativeType()
-	x12 := randomData.X12.GetNativeType()
-	x21 := randomData.X21.GetNativeType()
-	x22 := randomData.X22.GetNativeType()
-	A := randomData.A.GetNativeType()
-	B := randomData.B.GetNativeType()
+	x11 := d.ECElement("X11", randomData.GetX11())
+	x12 := d.ECElement("X12", randomData.GetX12())
+	x21 := d.ECElement("X21", randomData.GetX21())
+	x22 := d.ECElement("X22", randomData.GetX22())
+	A := d.ECElement("A", randomData.GetA())
+	B := d.ECElement("B", randomData.GetB())
+	if err := d.Err(); err != nil {
+		return nil, invalidResponse(err)
+	}
 
 	gamma := common.GetRandomInt(schnorrProver.Group.Q)
 	equalityVerifier1 := ecschnorr.NewBTEqualityVerifier(c.curve, gamma)
@@ -222,8 +243,11 @@ func (c *PseudonymsysClientEC) ObtainCredential(userSecret *big.Int,
 	}
 
 	proofData := resp.GetDoubleBigint()
-	z1 := new(big.Int).SetBytes(proofData.X1)
-	z2 := new(big.Int).SetBytes(proofData.X2)
+	z1 := d.Exponent("Z1", proofData.GetX1())
+	z2 := d.Exponent("Z2", proofData.GetX2())
+	if err := d.Err(); err != nil {
+		return nil, invalidResponse(err)
+	}
 
 	verified1, transcript1, bToGamma, AToGamma := equalityVerifier1.Verify(z1)
 	verified2, transcript2, aAToGamma, BToGamma := equalityVerifier2.Verify(z2)
@@ -252,9 +276,9 @@ func (c *PseudonymsysClientEC) ObtainCredential(userSecret *big.Int,
 // TransferCredential transfers orgName's credential to organization where the
 // authentication should happen (the organization takes credential issued by
 // another organization).
-func (c *PseudonymsysClientEC) TransferCredential(orgName string, userSecret *big.Int,
+func (c *PseudonymsysClientEC) TransferCredential(ctx context.Context, orgName string, userSecret *big.Int,
 	nym *ecpseudsys.Nym, credential *ecpseudsys.Cred) (*pb.SessionKey, error) {
-	if err := c.openStream(c.grpcClient, "TransferCredential_EC"); err != nil {
+	if err := c.openStream(ctx, c.grpcClient, "TransferCredential_EC"); err != nil {
 		return nil, err
 	}
 	defer c.closeStream()
@@ -266,6 +290,12 @@ func (c *PseudonymsysClientEC) TransferCredential(orgName string, userSecret *bi
 	equalityProver := ecschnorr.NewEqualityProver(c.curve)
 	x1, x2 := equalityProver.GetProofRandomData(userSecret, nym.A, credential.SmallAToGamma)
 
+	// See PseudonymsysClient.TransferCredential for the proof of possession.
+	niContext := pb.NewNIContext()
+	niContext.Verifier = c.org
+	possession := ecpseudsys.ProvePossession(c.curve, userSecret, nym,
+		niContext.Value(pb.TransferCredentialECMethod))
+
 	transcript1 := &pb.PseudonymsysTranscriptEC{
 		A: pb.ToPbECGroupElement(ec.NewGroupElement(credential.T1.Alpha_1,
 			credential.T1.Alpha_2)),
@@ -292,14 +322,18 @@ func (c *PseudonymsysClientEC) TransferCredential(orgName string, userSecret *bi
 	}
 	initMsg := &pb.Message{
 		ClientId: c.id,
+		Profile:  ec.CurvePresetName(c.curve),
 		Content: &pb.Message_PseudonymsysTransferCredentialDataEc{
 			&pb.PseudonymsysTransferCredentialDataEC{
-				OrgName:    orgName,
-				X1:         pb.ToPbECGroupElement(x1),
-				X2:         pb.ToPbECGroupElement(x2),
-				NymA:       pb.ToPbECGroupElement(nym.A),
-				NymB:       pb.ToPbECGroupElement(nym.B),
-				Credential: pbCredential,
+				OrgName:       orgName,
+				TargetOrgName: c.org,
+				X1:            pb.ToPbECGroupElement(x1),
+				X2:            pb.ToPbECGroupElement(x2),
+				NymA:          pb.ToPbECGroupElement(nym.A),
+				NymB:          pb.ToPbECGroupElement(nym.B),
+				Credential:    pbCredential,
+				Possession:    pb.ToPbFiatShamirEC(possession),
+				Context:       niContext,
 			},
 		},
 	}
@@ -308,8 +342,11 @@ func (c *PseudonymsysClientEC) TransferCredential(orgName string, userSecret *bi
 		return nil, err
 	}
 
-	ch := resp.GetBigint()
-	challenge := new(big.Int).SetBytes(ch.X1)
+	d := pb.NewECDecoder(c.curve)
+	challenge := d.Exponent("challenge", resp.GetBigint().GetX1())
+	if err := d.Err(); err != nil {
+		return nil, invalidResponse(err)
+	}
 
 	z := equalityProver.GetProofData(challenge)
 	msg := &pb.Message{
@@ -329,5 +366,6 @@ func (c *PseudonymsysClientEC) TransferCredential(orgName string, userSecret *bi
 		return nil, err
 	}
 
+	c.idToken = resp.GetSessionKey().GetIdToken()
 	return resp.GetSessionKey(), nil
 }
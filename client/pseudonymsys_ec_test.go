@@ -18,6 +18,7 @@
 package client
 
 import (
+	"context"
 	"math/big"
 	"testing"
 
@@ -38,27 +39,27 @@ func TestPseudonymsysEC(t *testing.T) {
 	userSecret := c1.GenerateMasterKey()
 
 	masterNym := caClient.GenerateMasterNym(userSecret)
-	caCertificate, err := caClient.GenerateCertificate(userSecret, masterNym)
+	caCertificate, err := caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
 	if err != nil {
 		t.Errorf("Error when registering with CA: %s", err.Error())
 	}
 
 	//nym generation should fail with invalid registration key
-	_, err = c1.GenerateNym(userSecret, caCertificate, "029uywfh9udni")
+	_, err = c1.GenerateNym(context.Background(), userSecret, caCertificate, "029uywfh9udni")
 	assert.NotNil(t, err, "Should produce an error")
 
-	nym1, err := c1.GenerateNym(userSecret, caCertificate, "testRegKey3")
+	nym1, err := c1.GenerateNym(context.Background(), userSecret, caCertificate, "testRegKey3")
 	if err != nil {
 		t.Errorf(err.Error())
 	}
 
 	//nym generation should fail the second time with the same registration key
-	_, err = c1.GenerateNym(userSecret, caCertificate, "testRegKey3")
+	_, err = c1.GenerateNym(context.Background(), userSecret, caCertificate, "testRegKey3")
 	assert.NotNil(t, err, "Should produce an error")
 
 	orgName := "org1"
 	orgPubKeys := config.LoadPseudonymsysOrgPubKeysEC(orgName)
-	credential, err := c1.ObtainCredential(userSecret, nym1, orgPubKeys)
+	credential, err := c1.ObtainCredential(context.Background(), userSecret, nym1, orgPubKeys)
 	if err != nil {
 		t.Errorf(err.Error())
 	}
@@ -66,7 +67,7 @@ func TestPseudonymsysEC(t *testing.T) {
 	// register with org2
 	// create a client to communicate with org2
 	caClient1, _ := NewPseudonymsysCAClientEC(testGrpcClientConn, curveType)
-	caCertificate1, err := caClient1.GenerateCertificate(userSecret, masterNym)
+	caCertificate1, err := caClient1.GenerateCertificate(context.Background(), userSecret, masterNym)
 	if err != nil {
 		t.Errorf("Error when registering with CA")
 	}
@@ -75,19 +76,19 @@ func TestPseudonymsysEC(t *testing.T) {
 	// using transferCredential to authenticate with the same organization and not
 	// transferring credentials to another organization
 	c2, _ := NewPseudonymsysClientEC(testGrpcClientConn, curveType)
-	nym2, err := c2.GenerateNym(userSecret, caCertificate1, "testRegKey4")
+	nym2, err := c2.GenerateNym(context.Background(), userSecret, caCertificate1, "testRegKey4")
 	if err != nil {
 		t.Errorf(err.Error())
 	}
 
 	// Authentication should succeed
-	sessionKey1, err := c2.TransferCredential(orgName, userSecret, nym2, credential)
+	sessionKey1, err := c2.TransferCredential(context.Background(), orgName, userSecret, nym2, credential)
 	assert.NotNil(t, sessionKey1, "Should authenticate and obtain a valid (non-nil) session key")
 	assert.Nil(t, err, "Should not produce an error")
 
 	// Authentication should fail because the user doesn't have the right secret
 	wrongUserSecret := big.NewInt(3952123123)
-	sessionKey2, err := c2.TransferCredential(orgName, wrongUserSecret, nym2, credential)
+	sessionKey2, err := c2.TransferCredential(context.Background(), orgName, wrongUserSecret, nym2, credential)
 	assert.Nil(t, sessionKey2, "Authentication should fail, and session key should be nil")
 	assert.NotNil(t, err, "Should produce an error")
 }
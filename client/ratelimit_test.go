@@ -0,0 +1,78 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/common"
+	pb "github.com/xlab-si/emmy/proto"
+)
+
+// TestRateLimit starts more protocols than allowed by the rate limit of the server.
+func TestRateLimit(t *testing.T) {
+	config.Default().Set("network.limits.rate", 0.001)
+	config.Default().Set("network.limits.burst", 2)
+	_, conn := newTestServer(t, &mockRegKeyDB{})
+	config.Default().Set("network.limits.rate", 0)
+	config.Default().Set("network.limits.burst", 0)
+	defer conn.Close()
+
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+	caClient, err := NewPseudonymsysCAClient(conn, group)
+	require.NoError(t, err)
+	userSecret := common.GetRandomInt(group.Q)
+	masterNym := caClient.GenerateMasterNym(userSecret)
+
+	for i := 0; i < 2; i++ {
+		_, err := caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+		require.NoError(t, err)
+	}
+	_, err = caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+	assert.True(t, errors.Is(err, ErrRateLimited), "unexpected error %v", err)
+}
+
+// TestMaxStreamsPerPeer starts a protocol while another one of the same client is
+// in progress.
+func TestMaxStreamsPerPeer(t *testing.T) {
+	config.Default().Set("network.limits.max_streams_per_peer", 1)
+	_, conn := newTestServer(t, &mockRegKeyDB{})
+	config.Default().Set("network.limits.max_streams_per_peer", 0)
+	defer conn.Close()
+
+	// the stream of the protocol stays open after the first step
+	steps := pb.NewStepsClient(conn)
+	resp, err := steps.Step(context.Background(), &pb.ProtocolStep{
+		Method:  "ProveCredential",
+		Message: &pb.Message{ClientId: 1},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Message)
+
+	_, err = steps.Step(context.Background(), &pb.ProtocolStep{
+		Method:  "ProveCredential",
+		Message: &pb.Message{ClientId: 2},
+	})
+	assert.True(t, errors.Is(toProtocolError(err), ErrRateLimited), "unexpected error %v", err)
+}
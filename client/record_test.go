@@ -0,0 +1,97 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/record"
+)
+
+// TestRecordSessions requires a running server.
+func TestRecordSessions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emmy-sessions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	client, err := NewCLClient(testGrpcClientConn)
+	require.NoError(t, err)
+	client.RecordSessions(dir)
+
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+
+	_, err = client.IssueCredential(context.Background(), cm, "testRegKey8")
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	s, err := record.Load(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, "IssueCredential", s.Method)
+	assert.Equal(t, record.Client, s.Side)
+	assert.False(t, s.Failed())
+
+	kinds := make([]record.Kind, len(s.Events))
+	for i, e := range s.Events {
+		kinds[i] = e.Kind
+	}
+	assert.Equal(t, []record.Kind{record.KindSend, record.KindRecv, record.KindSend,
+		record.KindRecv}, kinds)
+
+	// the client replays the registration key as recorded, but its credential
+	// request depends on fresh randomness
+	var diverged []int
+	replay := record.NewReplay(s, func(st *record.Step) error {
+		if st.Diverged {
+			diverged = append(diverged, st.Index)
+		}
+		return nil
+	})
+	client.RecordSessions("")
+	client.UseStreamOpener(replay)
+	_, err = client.IssueCredential(context.Background(), cm, "testRegKey8")
+	assert.Error(t, err)
+	assert.Equal(t, []int{3}, diverged)
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/server"
+)
+
+// TestReloadConfig changes the configuration file of a tenant while the server is
+// running, which takes effect once the server reloads it.
+func TestReloadConfig(t *testing.T) {
+	srv, conn := newTestServer(t, &mockRegKeyDB{})
+	defer conn.Close()
+	defer srv.Teardown()
+
+	file := filepath.Join(t.TempDir(), "acme.yml")
+	writeConf := func(conf string) {
+		require.NoError(t, ioutil.WriteFile(file, []byte(conf), 0600))
+	}
+	writeConf(`
+attributes: {0: "Name, string, true"}
+credential_schema: {name: "acme", version: "1.0"}
+acceptable_credentials: {"acme": "Name"}
+`)
+	conf, err := config.NewFromFile(file)
+	require.NoError(t, err)
+	tenant, err := server.NewTenant("acme", conf)
+	require.NoError(t, err)
+	srv.AddTenant(tenant)
+
+	client, err := NewCLClient(conn)
+	require.NoError(t, err)
+	client.UseTenant("acme")
+	accCreds, err := client.GetAcceptableCreds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"acme": {"Name"}}, accCreds)
+	_, err = client.GetCredentialSchema(context.Background(), "acme", "2.0")
+	assert.Error(t, err)
+
+	writeConf(`
+attributes: {0: "Name, string, true"}
+credential_schema: {name: "acme", version: "1.0"}
+credential_schemas:
+  acme_v2:
+    name: "acme"
+    version: "2.0"
+    attributes: {0: "Name, string, true", 1: "Age, int64, false"}
+acceptable_credentials: {"acme": "Name, Age"}
+`)
+	require.NoError(t, srv.ReloadConfig())
+	accCreds, err = client.GetAcceptableCreds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"acme": {"Name", "Age"}}, accCreds)
+	rc, err := client.GetCredentialSchema(context.Background(), "acme", "2.0")
+	require.NoError(t, err)
+	_, err = rc.GetAttr("Age")
+	assert.NoError(t, err)
+
+	// an invalid configuration is rejected as a whole
+	writeConf(`
+attributes: {0: "Name, unknown, true"}
+acceptable_credentials: {"acme": "Name"}
+`)
+	assert.Error(t, srv.ReloadConfig())
+	accCreds, err = client.GetAcceptableCreds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"acme": {"Name", "Age"}}, accCreds)
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/url"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy configures how operations that failed because the server could not be
+// reached are retried, with exponentially growing delays between attempts.
+type RetryPolicy struct {
+	MaxAttempts    int           // maximum number of attempts, including the first one
+	InitialBackoff time.Duration // delay before the second attempt
+	MaxBackoff     time.Duration // upper bound of delays between attempts
+	Multiplier     float64       // factor by which the delay grows after each attempt
+}
+
+// DefaultRetryPolicy makes up to 5 attempts, waiting from 100ms up to 5s between them.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     1.6,
+}
+
+// Backoff returns the delay before attempt n+1 of an operation that failed n times.
+// Delays are randomized by up to 20%, so that clients that failed at the same time
+// do not retry at the same time.
+func (p RetryPolicy) Backoff(n int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < n && d < float64(p.MaxBackoff); i++ {
+		d *= p.Multiplier
+	}
+	if d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	d *= 1 + 0.2*(2*rand.Float64()-1)
+	return time.Duration(d)
+}
+
+// RetryableError is returned by protocols of emmy clients that failed because the
+// server could not be reached, or the connection to it broke during the protocol.
+// The protocol was aborted, and can be run again once the connection is restored
+// (gRPC connections reconnect automatically, see ConnectionConfig).
+type RetryableError struct {
+	Err error
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable reports whether err is or wraps a *RetryableError.
+func IsRetryable(err error) bool {
+	var re *RetryableError
+	return errors.As(err, &re)
+}
+
+// Retry runs f until it succeeds or returns an error that is not retryable, making at
+// most p.MaxAttempts attempts. It waits between attempts as configured by p, and
+// returns the error of the last attempt, or ctx.Err() if ctx is done while waiting.
+// Protocols of emmy clients can be retried as a whole with it:
+//
+//	err := client.Retry(ctx, client.DefaultRetryPolicy, func() error {
+//		cert, err = c.GenerateCertificate(ctx, secret, nym)
+//		return err
+//	})
+func Retry(ctx context.Context, p RetryPolicy, f func() error) error {
+	for n := 1; ; n++ {
+		err := f()
+		if err == nil || !IsRetryable(err) || n >= p.MaxAttempts {
+			return err
+		}
+		backoff := p.Backoff(n)
+		logger.Infof("Retrying in %v: %v", backoff, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// isTransient reports whether err is caused by the server being unreachable, either
+// over gRPC or over HTTP-based transports.
+func isTransient(err error) bool {
+	if status.Code(err) == codes.Unavailable {
+		return true
+	}
+	if e, ok := err.(*url.Error); ok {
+		err = e.Err
+	}
+	// errors of contexts are net.Errors too, but do not go away on retries
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return false
+	}
+	_, ok := err.(net.Error)
+	return ok
+}
+
+// streamError describes err of the stream of client c (see wrapError).
+func (c *genericClient) streamError(desc string, err error) error {
+	return wrapError(fmt.Sprintf("[client %v] %s", c.id, desc), err)
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{
+		MaxAttempts:    10,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+	within := func(d, expected time.Duration) bool {
+		return d >= expected*8/10 && d <= expected*12/10
+	}
+
+	assert.True(t, within(p.Backoff(1), 100*time.Millisecond))
+	assert.True(t, within(p.Backoff(2), 200*time.Millisecond))
+	assert.True(t, within(p.Backoff(3), 400*time.Millisecond))
+	assert.True(t, within(p.Backoff(9), time.Second))
+}
+
+func TestRetry(t *testing.T) {
+	p := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		Multiplier:     2,
+	}
+	retryable := &RetryableError{Err: fmt.Errorf("server unavailable")}
+
+	// succeeds on the last attempt
+	attempts := 0
+	err := Retry(context.Background(), p, func() error {
+		attempts++
+		if attempts < 3 {
+			return retryable
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	// gives up after p.MaxAttempts attempts
+	attempts = 0
+	err = Retry(context.Background(), p, func() error {
+		attempts++
+		return retryable
+	})
+	assert.True(t, IsRetryable(err))
+	assert.Equal(t, 3, attempts)
+
+	// other errors are not retried
+	attempts = 0
+	err = Retry(context.Background(), p, func() error {
+		attempts++
+		return fmt.Errorf("invalid proof")
+	})
+	assert.Error(t, err)
+	assert.False(t, IsRetryable(err))
+	assert.Equal(t, 1, attempts)
+
+	// stops waiting when the context is done
+	p.InitialBackoff, p.MaxBackoff = time.Hour, time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = Retry(ctx, p, func() error {
+		return retryable
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+// TestReconnect runs a protocol while the server is down, and after it is restarted
+// on the same address.
+func TestReconnect(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	startServer := func(addr string) (*server.Server, net.Listener) {
+		srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+			&mockRegKeyDB{}, cl.NewMockRecordManager(), logger)
+		require.NoError(t, err)
+		listener, err := net.Listen("tcp", addr)
+		require.NoError(t, err)
+		go srv.GrpcServer.Serve(listener)
+		return srv, listener
+	}
+
+	srv, listener := startServer("localhost:0")
+	addr := listener.Addr().String()
+
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	cfg := NewConnectionConfig(addr, "", testCert, 500)
+	cfg.KeepaliveMillis = 100
+	cfg.MaxReconnectDelayMillis = 100
+	conn, err := GetConnection(cfg)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+	c, err := NewPseudonymsysCAClient(conn, group)
+	require.NoError(t, err)
+	secret := common.GetRandomInt(group.Q)
+	nym := c.GenerateMasterNym(secret)
+
+	_, err = c.GenerateCertificate(context.Background(), secret, nym)
+	require.NoError(t, err)
+
+	srv.GrpcServer.Stop()
+	// wait for the client to notice that the connection broke
+	time.Sleep(200 * time.Millisecond)
+	_, err = c.GenerateCertificate(context.Background(), secret, nym)
+	assert.True(t, IsRetryable(err), "error %v should be retryable", err)
+
+	srv, _ = startServer(addr)
+	defer srv.Teardown()
+	c.UseRetryPolicy(RetryPolicy{
+		MaxAttempts:    20,
+		InitialBackoff: 50 * time.Millisecond,
+		MaxBackoff:     200 * time.Millisecond,
+		Multiplier:     1.6,
+	})
+	_, err = c.GenerateCertificate(context.Background(), secret, nym)
+	assert.NoError(t, err)
+}
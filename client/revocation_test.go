@@ -0,0 +1,112 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+)
+
+// TestCLWithRevocation runs CL protocols against a server that revokes credentials.
+// The server listens on a random port, as unary RPCs of the accumulator cannot be
+// reached over gRPC-Web.
+func TestCLWithRevocation(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{data: []string{"testRegKey1", "testRegKey2"}},
+		cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "emmy-revocation")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, srv.EnableRevocation(filepath.Join(dir, "accumulator")))
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	defer srv.Teardown()
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	conn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client, err := NewCLClient(conn)
+	require.NoError(t, err)
+	issue := func(regKey, name string) (*cl.CredManager, *cl.Cred) {
+		rc, err := client.GetCredentialStructure(context.Background())
+		require.NoError(t, err)
+		for n, val := range map[string]interface{}{
+			"Name":      name,
+			"Gender":    "M",
+			"Graduated": "true",
+			"DateMin":   1512643000,
+			"DateMax":   1592643000,
+			"Age":       50,
+		} {
+			a, err := rc.GetAttr(n)
+			require.NoError(t, err)
+			require.NoError(t, a.UpdateValue(val))
+		}
+		pubKey := new(cl.PubKey)
+		cl.ReadGob("testdata/clPubKey.gob", pubKey)
+		cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+			pubKey.GenerateUserMasterSecret(), rc)
+		require.NoError(t, err)
+		cred, err := client.IssueCredential(context.Background(), cm, regKey)
+		require.NoError(t, err)
+		require.NotNil(t, cred.Witness)
+		return cm, cred
+	}
+	prove := func(cm *cl.CredManager, cred *cl.Cred) error {
+		_, err := client.ProveCredential(context.Background(), cm, cred, []string{"Gender"})
+		return err
+	}
+
+	cm1, cred1 := issue("testRegKey1", "Jack")
+	cm2, cred2 := issue("testRegKey2", "John")
+	assert.NoError(t, prove(cm1, cred1))
+	assert.NoError(t, prove(cm2, cred2))
+
+	// proofs without a non-revocation proof are not accepted
+	assert.Error(t, prove(cm1, cl.NewCred(cred1.A, cred1.E, cred1.V11)))
+
+	require.NoError(t, srv.RevokeCredential(cm2.Nym))
+	assert.Error(t, srv.RevokeCredential(cm2.Nym))
+	assert.Error(t, prove(cm2, cred2))
+	assert.NoError(t, prove(cm1, cred1))
+
+	// updated credentials replace previous ones
+	newCred1, err := client.UpdateCredential(context.Background(), cm1, cm1.RawCred)
+	require.NoError(t, err)
+	assert.NoError(t, prove(cm1, newCred1))
+	assert.Error(t, prove(cm1, cred1))
+}
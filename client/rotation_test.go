@@ -0,0 +1,149 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/server"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestKeyRotation(t *testing.T) {
+	srv, conn := newTestServer(t, &mockRegKeyDB{data: []string{"rotKey1", "rotKey2"}})
+	defer conn.Close()
+
+	dir := t.TempDir()
+	pubKeyPath := filepath.Join(dir, "clPubKey.gob")
+	secKeyPath := filepath.Join(dir, "clSecKey.gob")
+	conf := config.New()
+	conf.Set("cl.pub_key", pubKeyPath)
+	conf.Set("cl.sec_key", secKeyPath)
+	tenant, err := server.NewTenant("rotating", conf)
+	require.NoError(t, err)
+	srv.AddTenant(tenant)
+
+	structure, err := conf.LoadCredentialStructure()
+	require.NoError(t, err)
+	_, attrCount, err := cl.ParseAttrs(structure)
+	require.NoError(t, err)
+	params, err := cl.LoadParams()
+	require.NoError(t, err)
+	prevOrg, err := cl.LoadOrCreateOrg(params, pubKeyPath, secKeyPath, attrCount)
+	require.NoError(t, err)
+
+	client, err := NewCLClient(conn)
+	require.NoError(t, err)
+	client.UseTenant("rotating")
+	issue := func(org *cl.Org, regKey string) (*cl.CredManager, *cl.Cred) {
+		rc, err := client.GetCredentialStructure(context.Background())
+		require.NoError(t, err)
+		for name, val := range map[string]interface{}{
+			"Name":      "Jack",
+			"Gender":    "M",
+			"Graduated": "true",
+			"DateMin":   1512643000,
+			"DateMax":   1592643000,
+			"Age":       50,
+		} {
+			a, err := rc.GetAttr(name)
+			require.NoError(t, err)
+			require.NoError(t, a.UpdateValue(val))
+		}
+		cm, err := cl.NewCredManager(params, org.Keys.Pub,
+			org.Keys.Pub.GenerateUserMasterSecret(), rc)
+		require.NoError(t, err)
+		cred, err := client.IssueCredential(context.Background(), cm, regKey)
+		require.NoError(t, err)
+		return cm, cred
+	}
+
+	prevCM, prevCred := issue(prevOrg, "rotKey1")
+	assert.Equal(t, prevOrg.Keys.Pub.ID(), prevCred.KeyID,
+		"credential should be tagged with the ID of the key")
+
+	// rotate keys, keeping the previous public key for a grace period
+	prevID := prevOrg.Keys.Pub.ID()
+	prevPEM, err := prevOrg.Keys.Pub.MarshalPEM()
+	require.NoError(t, err)
+	prevPath := filepath.Join(dir, "cl-"+prevID+".pub")
+	require.NoError(t, ioutil.WriteFile(prevPath, prevPEM, 0644))
+	require.NoError(t, os.Remove(pubKeyPath))
+	require.NoError(t, os.Remove(secKeyPath))
+	org, err := cl.LoadOrCreateOrg(params, pubKeyPath, secKeyPath, attrCount)
+	require.NoError(t, err)
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+	conf.Set("cl.previous_keys", map[string]interface{}{
+		prevID: map[string]interface{}{
+			"pub_key": prevPath,
+			"until":   until.Format(time.RFC3339),
+		},
+	})
+
+	// both keys are published
+	ctx := metadata.AppendToOutgoingContext(context.Background(), pb.TenantMetadataKey,
+		"rotating")
+	info, err := GetServiceInfo(ctx, conn)
+	require.NoError(t, err)
+	require.Len(t, info.CLPubKeys, 2)
+	assert.Equal(t, org.Keys.Pub.ID(), info.CLPubKeys[0].ID)
+	assert.True(t, info.CLPubKeys[0].Until.IsZero())
+	assert.Equal(t, prevID, info.CLPubKeys[1].ID)
+	assert.True(t, until.Equal(info.CLPubKeys[1].Until))
+
+	// credentials issued under either key are accepted
+	cm, cred := issue(org, "rotKey2")
+	assert.Equal(t, org.Keys.Pub.ID(), cred.KeyID)
+	_, err = client.ProveCredential(context.Background(), cm, cred, []string{"Name"})
+	assert.NoError(t, err)
+	_, err = client.ProveCredential(context.Background(), prevCM, prevCred, []string{"Name"})
+	assert.NoError(t, err, "proof with the previous key should be accepted in the grace period")
+
+	// but only credentials issued under the current key can be updated
+	_, err = client.UpdateCredential(context.Background(), prevCM, prevCM.RawCred)
+	assert.True(t, errors.Is(err, ErrUnknownKey), "unexpected error %v", err)
+
+	unknownCred := *prevCred
+	unknownCred.KeyID = "0123456789abcdef"
+	_, err = client.ProveCredential(context.Background(), prevCM, &unknownCred, []string{"Name"})
+	assert.True(t, errors.Is(err, ErrUnknownKey), "unexpected error %v", err)
+
+	// once the grace period ends, the previous key is no longer accepted nor published
+	conf.Set("cl.previous_keys", map[string]interface{}{
+		prevID: map[string]interface{}{
+			"pub_key": prevPath,
+			"until":   time.Now().Add(-time.Minute).Format(time.RFC3339),
+		},
+	})
+	_, err = client.ProveCredential(context.Background(), prevCM, prevCred, []string{"Name"})
+	assert.True(t, errors.Is(err, ErrUnknownKey), "unexpected error %v", err)
+	info, err = GetServiceInfo(ctx, conn)
+	require.NoError(t, err)
+	assert.Len(t, info.CLPubKeys, 1)
+}
@@ -0,0 +1,182 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+)
+
+func TestCredentialSchemas(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{data: []string{"schemaKey"}}, cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	defer srv.Teardown()
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	conn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+	defer conn.Close()
+	gateway := httptest.NewServer(server.NewGateway(srv, "adminToken"))
+	defer gateway.Close()
+
+	register := func(token, name, version string, attrs ...server.CredAttribute) int {
+		body, err := json.Marshal(&server.CredStructure{
+			Name:       name,
+			Version:    version,
+			Attributes: attrs,
+		})
+		require.NoError(t, err)
+		req, err := http.NewRequest(http.MethodPost, gateway.URL+"/v1/admin/schemas",
+			bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	name := server.CredAttribute{Name: "Name", Type: "string", Known: true}
+	age := server.CredAttribute{Name: "Age", Type: "int64", Known: false}
+	photo := server.CredAttribute{Name: "Photo", Type: "blob", Known: true}
+
+	assert.Equal(t, http.StatusUnauthorized, register("", "voter", "1.0", name))
+	assert.Equal(t, http.StatusOK, register("adminToken", "voter", "1.0", name, age))
+	assert.Equal(t, http.StatusOK, register("adminToken", "voter", "1.10", name, age, photo))
+	assert.Equal(t, http.StatusOK, register("adminToken", "voter", "1.2", name))
+	// versions cannot be changed, and schemas need valid attributes
+	assert.Equal(t, http.StatusBadRequest, register("adminToken", "voter", "1.0", name))
+	assert.Equal(t, http.StatusBadRequest, register("adminToken", "voter", "2.0",
+		server.CredAttribute{Name: "Name", Type: "float", Known: true}))
+	assert.Equal(t, http.StatusBadRequest, register("adminToken", "voter", "", name))
+
+	c, err := NewCLClient(conn)
+	require.NoError(t, err)
+
+	// the structure of the configuration is the default schema
+	rc, err := c.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, rc.GetAttrs(), 6)
+
+	rc, err = c.GetCredentialSchema(context.Background(), "voter", "1.0")
+	require.NoError(t, err)
+	assert.Len(t, rc.GetAttrs(), 2)
+	_, err = rc.GetAttr("Age")
+	assert.NoError(t, err)
+
+	// versions are compared by their numeric components
+	rc, err = c.GetCredentialSchema(context.Background(), "voter", "")
+	require.NoError(t, err)
+	assert.Len(t, rc.GetAttrs(), 3)
+
+	_, err = c.GetCredentialSchema(context.Background(), "voter", "3.0")
+	assert.True(t, errors.Is(err, ErrUnknownSchema), "unexpected error %v", err)
+	_, err = c.GetCredentialSchema(context.Background(), "unknown", "")
+	assert.True(t, errors.Is(err, ErrUnknownSchema), "unexpected error %v", err)
+
+	// schemas are requested over gRPC-Web as well
+	grpcWeb := httptest.NewServer(server.NewGrpcWebHandler(srv, nil))
+	defer grpcWeb.Close()
+	c, err = NewCLClient(nil)
+	require.NoError(t, err)
+	c.UseStreamOpener(NewGrpcWebConn(grpcWeb.URL, nil))
+	rc, err = c.GetCredentialSchema(context.Background(), "voter", "1.2")
+	require.NoError(t, err)
+	assert.Len(t, rc.GetAttrs(), 1)
+
+	// attributes of any type are issued in credentials
+	assert.Equal(t, http.StatusOK, register("adminToken", "passport", "1.0", name,
+		server.CredAttribute{Name: "BirthDate", Type: "date", Known: true},
+		server.CredAttribute{Name: "Adult", Type: "bool", Known: true},
+		server.CredAttribute{Name: "Status", Type: "enum", Known: true,
+			Values: []string{"single", "married"}},
+		server.CredAttribute{Name: "DocHash", Type: "bytes", Known: true, Size: 32}, age))
+	rc, err = c.GetCredentialSchema(context.Background(), "passport", "1.0")
+	require.NoError(t, err)
+	status, err := rc.GetAttr("Status")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"single", "married"}, status.(*cl.EnumAttr).Values)
+	for n, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"BirthDate": time.Date(1990, time.March, 12, 0, 0, 0, 0, time.UTC),
+		"Adult":     true,
+		"Status":    "married",
+		"DocHash":   make([]byte, 32),
+		"Age":       30,
+	} {
+		a, err := rc.GetAttr(n)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+	pubKey := new(cl.PubKey)
+	require.NoError(t, cl.ReadGob("testdata/clPubKey.gob", pubKey))
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+	cred, err := c.IssueCredential(context.Background(), cm, "schemaKey")
+	require.NoError(t, err)
+	assert.NotNil(t, cred)
+
+	// and over the gateway
+	resp, err := http.Get(gateway.URL + "/v1/cl/structure?name=voter&version=1.0")
+	require.NoError(t, err)
+	structure := new(server.CredStructure)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(structure))
+	resp.Body.Close()
+	assert.Equal(t, "1.0", structure.Version)
+	assert.Equal(t, []server.CredAttribute{name, age}, structure.Attributes)
+
+	resp, err = http.Get(gateway.URL + "/v1/cl/structure?name=unknown")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Get(gateway.URL + "/v1/cl/schemas")
+	require.NoError(t, err)
+	var structures []server.CredStructure
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&structures))
+	resp.Body.Close()
+	require.Len(t, structures, 5)
+	assert.Equal(t, "default", structures[0].Name)
+	assert.Equal(t, "passport", structures[1].Name)
+	assert.Equal(t, 32, structures[1].Attributes[4].Size)
+	for i, v := range []string{"1.0", "1.2", "1.10"} {
+		assert.Equal(t, v, structures[i+2].Version)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc"
+)
+
+// SessionClient validates, refreshes and revokes session keys that clients obtain by
+// authenticating with the server (for example with CLClient.ProveCredential).
+type SessionClient struct {
+	genericClient
+	grpcClient pb.SessionClient
+}
+
+func NewSessionClient(conn *grpc.ClientConn) (*SessionClient, error) {
+	return &SessionClient{
+		genericClient: newGenericClient(),
+		grpcClient:    pb.NewSessionClient(conn),
+	}, nil
+}
+
+// SessionStatus describes the session of a session key.
+type SessionStatus struct {
+	Valid     bool
+	ExpiresAt time.Time
+	// time the client authenticated at, zero when not known
+	AuthTime time.Time
+	// what the server learned about the client, such as revealed attributes
+	Claims map[string]interface{}
+	// why the session key is not valid
+	Reason string
+}
+
+// ValidateSession returns the status of the session of sessionKey. Invalid session
+// keys are reported by the status rather than an error.
+func (c *SessionClient) ValidateSession(ctx context.Context, sessionKey string) (*SessionStatus, error) {
+	req := &pb.SessionKey{Value: sessionKey}
+	var resp *pb.SessionStatus
+	var err error
+	ctx = c.withTenant(ctx)
+	if i, ok := c.invoker(); ok {
+		resp = new(pb.SessionStatus)
+		err = i.Invoke(ctx, "/proto.Session/ValidateSession", req, resp)
+	} else {
+		resp, err = c.grpcClient.ValidateSession(ctx, req)
+	}
+	if err != nil {
+		return nil, wrapError("unable to validate session", err)
+	}
+
+	status := &SessionStatus{
+		Valid:  resp.Valid,
+		Reason: resp.Reason,
+	}
+	if resp.ExpiresAt != 0 {
+		status.ExpiresAt = time.Unix(resp.ExpiresAt, 0)
+	}
+	if resp.AuthTime != 0 {
+		status.AuthTime = time.Unix(resp.AuthTime, 0)
+	}
+	if len(resp.Claims) > 0 {
+		if err := json.Unmarshal(resp.Claims, &status.Claims); err != nil {
+			return nil, invalidResponse(err)
+		}
+	}
+	return status, nil
+}
+
+// RefreshSession returns a new session key for the session of sessionKey, which is
+// no longer valid afterwards if the server keeps sessions in a store. ErrInvalidSession
+// is returned for sessions that are not valid or cannot be refreshed anymore.
+func (c *SessionClient) RefreshSession(ctx context.Context, sessionKey string) (*string, error) {
+	req := &pb.SessionKey{Value: sessionKey}
+	var resp *pb.SessionKey
+	var err error
+	ctx = c.withTenant(ctx)
+	if i, ok := c.invoker(); ok {
+		resp = new(pb.SessionKey)
+		err = i.Invoke(ctx, "/proto.Session/RefreshSession", req, resp)
+	} else {
+		resp, err = c.grpcClient.RefreshSession(ctx, req)
+	}
+	if err != nil {
+		return nil, wrapError("unable to refresh session", err)
+	}
+
+	return &resp.Value, nil
+}
+
+// RevokeSession ends the session of sessionKey before it expires. It requires a
+// server that keeps sessions in a store.
+func (c *SessionClient) RevokeSession(ctx context.Context, sessionKey string) error {
+	req := &pb.SessionKey{Value: sessionKey}
+	var err error
+	ctx = c.withTenant(ctx)
+	if i, ok := c.invoker(); ok {
+		err = i.Invoke(ctx, "/proto.Session/RevokeSession", req, new(empty.Empty))
+	} else {
+		_, err = c.grpcClient.RevokeSession(ctx, req)
+	}
+	if err != nil {
+		return wrapError("unable to revoke session", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/jose"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/oidc"
+	"github.com/xlab-si/emmy/server"
+)
+
+// TestSessionStore proves a credential to a server keeping sessions in a store and
+// validates the obtained session key through the gateway of the server.
+func TestSessionStore(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{data: []string{"testRegKey1"}}, cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+	store := server.NewMemSessionStore()
+	srv.UseSessionStore(store, time.Minute)
+	endpoint := httptest.NewServer(server.NewGateway(srv, ""))
+	defer endpoint.Close()
+
+	// credential structure is read from the main test server, which shares the
+	// configuration
+	client, err := NewCLClient(testGrpcClientConn)
+	require.NoError(t, err)
+	client.UseStreamOpener(NewGatewayConn(endpoint.URL, nil))
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+	cred, err := client.IssueCredential(context.Background(), cm, "testRegKey1")
+	require.NoError(t, err)
+	sessKey, err := client.ProveCredential(context.Background(), cm, cred, []string{"Gender"})
+	require.NoError(t, err)
+
+	post := func(path string) *server.SessionStatus {
+		body, err := json.Marshal(&server.SessionKey{SessionKey: *sessKey})
+		require.NoError(t, err)
+		resp, err := http.Post(endpoint.URL+path, "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		status := new(server.SessionStatus)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(status))
+		return status
+	}
+
+	status := post("/v1/sessions/validate")
+	assert.True(t, status.Valid)
+	assert.Equal(t, "M", status.Claims["Gender"])
+	assert.InDelta(t, time.Now().Add(time.Minute).Unix(), status.ExpiresAt, 5)
+
+	// sessions kept in the store are valid for other servers sharing it
+	other, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{}, cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+	other.UseSessionStore(store, time.Minute)
+	session, err := other.ValidateSession(*sessKey)
+	require.NoError(t, err)
+	assert.Equal(t, status.Claims, session.Claims)
+
+	post("/v1/sessions/end")
+	assert.False(t, post("/v1/sessions/validate").Valid)
+	_, err = other.ValidateSession(*sessKey)
+	assert.Equal(t, server.ErrSessionNotFound, err)
+}
+
+// proveTestCredential obtains a credential from the server of client with regKey and
+// proves it, revealing revealedAttrs. It returns the obtained session key.
+func proveTestCredential(t *testing.T, client *CLClient, regKey string,
+	revealedAttrs []string) string {
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+	cred, err := client.IssueCredential(context.Background(), cm, regKey)
+	require.NoError(t, err)
+	sessKey, err := client.ProveCredential(context.Background(), cm, cred, revealedAttrs)
+	require.NoError(t, err)
+	return *sessKey
+}
+
+// TestSessionLifecycle validates, refreshes and revokes session keys through the
+// Session service of a server keeping sessions in a store.
+func TestSessionLifecycle(t *testing.T) {
+	srv, conn := newTestServer(t, &mockRegKeyDB{data: []string{"lifecycleKey1",
+		"lifecycleKey2"}})
+	defer conn.Close()
+	srv.UseSessionStore(server.NewMemSessionStore(), time.Minute)
+	clClient, err := NewCLClient(conn)
+	require.NoError(t, err)
+	sessKey := proveTestCredential(t, clClient, "lifecycleKey1", []string{"Gender"})
+
+	client, err := NewSessionClient(conn)
+	require.NoError(t, err)
+	status, err := client.ValidateSession(context.Background(), sessKey)
+	require.NoError(t, err)
+	assert.True(t, status.Valid)
+	assert.Equal(t, "M", status.Claims["Gender"])
+	assert.WithinDuration(t, time.Now(), status.AuthTime, 5*time.Second)
+
+	// refreshing replaces the session key, keeping the session
+	refreshed, err := client.RefreshSession(context.Background(), sessKey)
+	require.NoError(t, err)
+	assert.NotEqual(t, sessKey, *refreshed)
+	status, err = client.ValidateSession(context.Background(), sessKey)
+	require.NoError(t, err)
+	assert.False(t, status.Valid)
+	_, err = client.RefreshSession(context.Background(), sessKey)
+	assert.True(t, errors.Is(err, ErrInvalidSession), "unexpected error %v", err)
+	refreshedStatus, err := client.ValidateSession(context.Background(), *refreshed)
+	require.NoError(t, err)
+	assert.True(t, refreshedStatus.Valid)
+	assert.Equal(t, "M", refreshedStatus.Claims["Gender"])
+
+	require.NoError(t, client.RevokeSession(context.Background(), *refreshed))
+	status, err = client.ValidateSession(context.Background(), *refreshed)
+	require.NoError(t, err)
+	assert.False(t, status.Valid)
+
+	// sessions cannot be refreshed after their maximum lifetime
+	srv.SetMaxSessionLifetime(time.Nanosecond)
+	sessKey = proveTestCredential(t, clClient, "lifecycleKey2", []string{"Gender"})
+	_, err = client.RefreshSession(context.Background(), sessKey)
+	assert.True(t, errors.Is(err, ErrInvalidSession), "unexpected error %v", err)
+}
+
+// TestJWTSessionClaims checks that session keys in JWT form carry revealed
+// attributes, so that sessions are validated without a session store.
+func TestJWTSessionClaims(t *testing.T) {
+	srv, conn := newTestServer(t, &mockRegKeyDB{data: []string{"jwtClaimsKey"}})
+	defer conn.Close()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	gen, err := server.NewJWTSessionKeyGen("emmy", "", time.Minute, key)
+	require.NoError(t, err)
+	gen.IncludeClaims()
+	srv.SessionManager = gen
+	clClient, err := NewCLClient(conn)
+	require.NoError(t, err)
+	sessKey := proveTestCredential(t, clClient, "jwtClaimsKey", []string{"Gender"})
+
+	parts := strings.Split(sessKey, ".")
+	require.Len(t, parts, 3)
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims struct {
+		Claims map[string]interface{} `json:"claims"`
+	}
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	assert.Equal(t, "M", claims.Claims["Gender"])
+
+	client, err := NewSessionClient(conn)
+	require.NoError(t, err)
+	refreshed, err := client.RefreshSession(context.Background(), sessKey)
+	require.NoError(t, err)
+	status, err := client.ValidateSession(context.Background(), *refreshed)
+	require.NoError(t, err)
+	assert.True(t, status.Valid)
+	assert.Equal(t, "M", status.Claims["Gender"])
+
+	// without a session store, sessions cannot be revoked
+	assert.Error(t, client.RevokeSession(context.Background(), sessKey))
+}
+
+// TestIDTokens checks that the server issues ID tokens of its OpenID Connect bridge
+// along with session keys, when configured to.
+func TestIDTokens(t *testing.T) {
+	srv, conn := newTestServer(t, &mockRegKeyDB{data: []string{"idTokenKey1",
+		"idTokenKey2"}})
+	defer conn.Close()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	p, err := oidc.NewProvider("https://localhost:8882", key, time.Minute)
+	require.NoError(t, err)
+	srv.EnableOIDC(p)
+
+	client, err := NewCLClient(conn)
+	require.NoError(t, err)
+	proveTestCredential(t, client, "idTokenKey1", []string{"Gender"})
+	assert.Empty(t, client.IDToken())
+
+	srv.IssueIDTokens("webapp")
+	sessKey := proveTestCredential(t, client, "idTokenKey2", []string{"Gender"})
+	require.NotEmpty(t, client.IDToken())
+
+	claims := make(map[string]interface{})
+	require.NoError(t, jose.Verify(client.IDToken(), &key.PublicKey, &claims))
+	assert.Equal(t, "M", claims["Gender"])
+	assert.Equal(t, "webapp", claims["aud"])
+	assert.Equal(t, "https://localhost:8882", claims["iss"])
+
+	// the session key can still be exchanged for tokens by other relying parties
+	tokens, err := p.Exchange(sessKey, "testClient")
+	require.NoError(t, err)
+	assert.NotEqual(t, client.IDToken(), tokens.IDToken)
+}
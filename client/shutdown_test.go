@@ -0,0 +1,172 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/log"
+	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/server"
+	"google.golang.org/grpc"
+)
+
+// closingRegKeyDB is a mockRegKeyDB that records whether it was closed.
+type closingRegKeyDB struct {
+	mockRegKeyDB
+	closed bool
+}
+
+func (m *closingRegKeyDB) Close() error {
+	m.closed = true
+	return nil
+}
+
+// hookOpener opens streams with its StreamOpener, calling hook once the first
+// message of the server is received.
+type hookOpener struct {
+	StreamOpener
+	hook func()
+}
+
+func (o *hookOpener) OpenStream(ctx context.Context, method string) (pb.ClientStream, error) {
+	st, err := o.StreamOpener.OpenStream(ctx, method)
+	if err != nil {
+		return nil, err
+	}
+	return &hookStream{st, o.hook}, nil
+}
+
+type hookStream struct {
+	pb.ClientStream
+	hook func()
+}
+
+func (s *hookStream) Recv() (*pb.Message, error) {
+	msg, err := s.ClientStream.Recv()
+	if s.hook != nil {
+		s.hook()
+		s.hook = nil
+	}
+	return msg, err
+}
+
+// newTestServer starts a server with registration keys in db, and returns it along with
+// a connection to it.
+func newTestServer(t *testing.T, db server.RegistrationManager) (*server.Server,
+	*grpc.ClientConn) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.New(server.WithTLS("testdata/server.pem", "testdata/server.key"),
+		server.WithRegistrationManager(db), server.WithLogger(logger))
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	conn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+
+	return srv, conn
+}
+
+// TestShutdown shuts the server down while a credential is being issued over the
+// gateway, which finishes before the server stops.
+func TestShutdown(t *testing.T) {
+	db := &closingRegKeyDB{mockRegKeyDB: mockRegKeyDB{data: []string{"shutdownKey1",
+		"shutdownKey2"}}}
+	srv, conn := newTestServer(t, db)
+	defer conn.Close()
+	endpoint := httptest.NewServer(server.NewGateway(srv, ""))
+	defer endpoint.Close()
+
+	client, err := NewCLClient(conn)
+	require.NoError(t, err)
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+
+	shutdown := make(chan error, 1)
+	client.UseStreamOpener(&hookOpener{
+		StreamOpener: NewGatewayConn(endpoint.URL, nil),
+		hook: func() {
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				shutdown <- srv.Shutdown(ctx)
+			}()
+			// let the shutdown begin
+			time.Sleep(100 * time.Millisecond)
+		},
+	})
+	_, err = client.IssueCredential(context.Background(), cm, "shutdownKey1")
+	assert.NoError(t, err)
+	assert.NoError(t, <-shutdown)
+	assert.True(t, db.closed, "stores should be closed")
+
+	// new protocol sessions are rejected
+	client.UseStreamOpener(NewGatewayConn(endpoint.URL, nil))
+	_, err = client.IssueCredential(context.Background(), cm, "shutdownKey2")
+	assert.Error(t, err)
+}
+
+// TestShutdownDeadline shuts the server down while a protocol session that does not
+// finish is in progress.
+func TestShutdownDeadline(t *testing.T) {
+	srv, conn := newTestServer(t, &mockRegKeyDB{})
+	defer conn.Close()
+
+	steps := pb.NewStepsClient(conn)
+	resp, err := steps.Step(context.Background(), &pb.ProtocolStep{
+		Method:  "ProveCredential",
+		Message: &pb.Message{ClientId: 1},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Message)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, srv.Shutdown(ctx))
+}
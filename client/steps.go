@@ -0,0 +1,133 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// StepConn runs emmy protocols with unary RPCs of the Steps service, one for each
+// message of the client, while the server keeps the state of the protocol between
+// them. It is meant for clients behind proxies that break long-lived gRPC streams,
+// and can be passed to UseStreamOpener of emmy clients.
+type StepConn struct {
+	client pb.StepsClient
+}
+
+// NewStepConn returns a StepConn that calls the server over conn.
+func NewStepConn(conn *grpc.ClientConn) *StepConn {
+	return &StepConn{
+		client: pb.NewStepsClient(conn),
+	}
+}
+
+// OpenStream opens a stream of the emmy protocol method (for example IssueCredential).
+func (c *StepConn) OpenStream(ctx context.Context, method string) (pb.ClientStream, error) {
+	if _, ok := pb.StreamMethods[method]; !ok {
+		return nil, fmt.Errorf("unknown method %s", method)
+	}
+
+	return &stepClientStream{
+		client: c.client,
+		ctx:    ctx,
+		method: method,
+	}, nil
+}
+
+// stepClientStream implements pb.ClientStream with calls of Step. Send passes a
+// message to the server and keeps its response, which is then returned by Recv.
+type stepClientStream struct {
+	client  pb.StepsClient
+	ctx     context.Context
+	method  string
+	session string
+	resp    *pb.Message
+	err     error
+}
+
+func (s *stepClientStream) Send(msg *pb.Message) error {
+	s.resp = nil
+	resp, err := s.client.Step(s.ctx, &pb.ProtocolStep{
+		Method:  s.method,
+		Session: s.session,
+		Message: msg,
+	})
+	if err != nil {
+		s.err = err
+		return err
+	}
+	s.session = resp.Session
+	s.resp = resp.Message
+
+	return nil
+}
+
+func (s *stepClientStream) Recv() (*pb.Message, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.resp == nil {
+		return nil, io.EOF
+	}
+	resp := s.resp
+	s.resp = nil
+
+	return resp, nil
+}
+
+func (s *stepClientStream) CloseSend() error {
+	if s.session == "" {
+		return nil
+	}
+	_, err := s.client.Step(s.ctx, &pb.ProtocolStep{
+		Method:  s.method,
+		Session: s.session,
+	})
+	return err
+}
+
+func (s *stepClientStream) Header() (metadata.MD, error) {
+	return metadata.MD{}, nil
+}
+
+func (s *stepClientStream) Trailer() metadata.MD {
+	return metadata.MD{}
+}
+
+func (s *stepClientStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *stepClientStream) SendMsg(m interface{}) error {
+	return s.Send(m.(*pb.Message))
+}
+
+func (s *stepClientStream) RecvMsg(m interface{}) error {
+	resp, err := s.Recv()
+	if err != nil {
+		return err
+	}
+	*m.(*pb.Message) = *resp
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+)
+
+// TestStepConn requires a running server.
+func TestStepConn(t *testing.T) {
+	conn := NewStepConn(testGrpcClientConn)
+
+	client, err := NewCLClient(testGrpcClientConn)
+	require.NoError(t, err)
+	client.UseStreamOpener(conn)
+
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+
+	cred, err := client.IssueCredential(context.Background(), cm, "testRegKey11")
+	require.NoError(t, err)
+
+	// errors of the protocol are passed to the client
+	_, err = client.IssueCredential(context.Background(), cm, "testRegKey11")
+	assert.True(t, errors.Is(err, ErrInvalidRegKey), "unexpected error %v", err)
+
+	acceptableCreds, err := client.GetAcceptableCreds(context.Background())
+	require.NoError(t, err)
+	sessKey, err := client.ProveCredential(context.Background(), cm, cred, acceptableCreds["org1"])
+	require.NoError(t, err)
+	assert.NotNil(t, sessKey)
+
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+	caClient, err := NewPseudonymsysCAClient(testGrpcClientConn, group)
+	require.NoError(t, err)
+	caClient.UseStreamOpener(conn)
+	c, err := NewPseudonymsysClient(testGrpcClientConn, group)
+	require.NoError(t, err)
+	c.UseStreamOpener(conn)
+	userSecret := c.GenerateMasterKey()
+	caCert, err := caClient.GenerateCertificate(context.Background(), userSecret,
+		caClient.GenerateMasterNym(userSecret))
+	require.NoError(t, err)
+	_, err = c.GenerateNym(context.Background(), userSecret, caCert, "testRegKey12")
+	require.NoError(t, err)
+
+	// runs of protocols cannot be guessed
+	steps := pb.NewStepsClient(testGrpcClientConn)
+	_, err = steps.Step(context.Background(), &pb.ProtocolStep{
+		Method:  "ProveCredential",
+		Session: "unknown",
+		Message: &pb.Message{},
+	})
+	assert.Error(t, err)
+	_, err = conn.OpenStream(context.Background(), "Unknown")
+	assert.Error(t, err)
+}
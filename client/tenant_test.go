@@ -0,0 +1,114 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/server"
+)
+
+func TestTenants(t *testing.T) {
+	srv, conn := newTestServer(t, &mockRegKeyDB{data: []string{"tenantKey1", "tenantKey2"}})
+	defer conn.Close()
+
+	dir := t.TempDir()
+	conf := config.New()
+	conf.Set("cl.pub_key", filepath.Join(dir, "clPubKey.gob"))
+	conf.Set("cl.sec_key", filepath.Join(dir, "clSecKey.gob"))
+	conf.Set("attributes", map[string]string{"0": "Name, string, true",
+		"1": "Age, int64, false"})
+	conf.Set("acceptable_credentials", map[string]string{"acme": "Name, Age"})
+	tenant, err := server.NewTenant("acme", conf)
+	require.NoError(t, err)
+	srv.AddTenant(tenant)
+
+	// keys of the tenant, which are otherwise created by the server
+	structure, err := conf.LoadCredentialStructure()
+	require.NoError(t, err)
+	_, attrCount, err := cl.ParseAttrs(structure)
+	require.NoError(t, err)
+	params, err := cl.LoadParams()
+	require.NoError(t, err)
+	org, err := cl.LoadOrCreateOrg(params, filepath.Join(dir, "clPubKey.gob"),
+		filepath.Join(dir, "clSecKey.gob"), attrCount)
+	require.NoError(t, err)
+
+	defaultClient, err := NewCLClient(conn)
+	require.NoError(t, err)
+	client, err := NewCLClient(conn)
+	require.NoError(t, err)
+	client.UseTenant("acme")
+
+	accCreds, err := defaultClient.GetAcceptableCreds(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, accCreds, "org1")
+	accCreds, err = client.GetAcceptableCreds(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"acme": {"Name", "Age"}}, accCreds)
+
+	// the tenant is also passed over gRPC-Web
+	endpoint := httptest.NewServer(server.NewGrpcWebHandler(srv, nil))
+	defer endpoint.Close()
+	webClient, err := NewCLClient(nil)
+	require.NoError(t, err)
+	webClient.UseStreamOpener(NewGrpcWebConn(endpoint.URL, nil))
+	webClient.UseTenant("acme")
+	accCreds, err = webClient.GetAcceptableCreds(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, accCreds, "acme")
+
+	unknownClient, err := NewCLClient(conn)
+	require.NoError(t, err)
+	unknownClient.UseTenant("unknown")
+	_, err = unknownClient.GetAcceptableCreds(context.Background())
+	assert.True(t, errors.Is(err, ErrUnknownTenant), "unexpected error %v", err)
+
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	// the tenant issues credentials with its own structure
+	for name, val := range map[string]interface{}{
+		"Name": "Jack",
+		"Age":  50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), org.Keys.Pub,
+		org.Keys.Pub.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+
+	cred, err := client.IssueCredential(context.Background(), cm, "tenantKey1")
+	require.NoError(t, err)
+	sessKey, err := client.ProveCredential(context.Background(), cm, cred, []string{"Name"})
+	require.NoError(t, err)
+	assert.NotNil(t, sessKey)
+
+	// credentials of the tenant are not accepted by the default tenant
+	_, err = defaultClient.ProveCredential(context.Background(), cm, cred, []string{"Name"})
+	assert.Error(t, err)
+}
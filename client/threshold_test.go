@@ -0,0 +1,124 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"google.golang.org/grpc"
+)
+
+// startThresholdServer starts a test server that accepts regKeys and returns it
+// along with a connection to it.
+func startThresholdServer(t *testing.T, regKeys ...string) (*server.Server,
+	*grpc.ClientConn) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{data: regKeys}, cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go srv.GrpcServer.Serve(listener)
+	testCert, err := ioutil.ReadFile("testdata/server.pem")
+	require.NoError(t, err)
+	conn, err := GetConnection(NewConnectionConfig(
+		fmt.Sprintf("localhost:%d", listener.Addr().(*net.TCPAddr).Port), "", testCert, 500))
+	require.NoError(t, err)
+
+	return srv, conn
+}
+
+func TestThresholdIssuance(t *testing.T) {
+	params, err := cl.LoadParams()
+	require.NoError(t, err)
+	pubKeyPath, secKeyPath := config.LoadCLKeyPaths()
+	org, err := cl.LoadOrg(params, pubKeyPath, secKeyPath)
+	require.NoError(t, err)
+	shares, err := cl.SplitSecKey(params, org.Keys.Sec, 2, 3)
+	require.NoError(t, err)
+
+	parties := make([]*server.Server, len(shares))
+	conns := make(map[int]*grpc.ClientConn, len(shares))
+	for i, share := range shares {
+		srv, conn := startThresholdServer(t)
+		defer conn.Close()
+		party, err := cl.NewThresholdParty(params, share)
+		require.NoError(t, err)
+		srv.UseThresholdShare(party)
+		parties[i] = srv
+		conns[share.Index] = conn
+	}
+	defer parties[1].Teardown()
+	defer parties[2].Teardown()
+
+	srv, conn := startThresholdServer(t, "thresholdKey1", "thresholdKey2")
+	defer srv.Teardown()
+	defer conn.Close()
+	coordinator, err := server.NewThresholdCoordinator(org.Keys.Pub, 2, conns)
+	require.NoError(t, err)
+	require.NoError(t, srv.UseThresholdCoordinator(coordinator))
+	assert.Error(t, srv.EnableRevocation("testdata/thresholdAcc.gob"),
+		"revocation should not be available with threshold issuance")
+
+	clClient, err := NewCLClient(conn)
+	require.NoError(t, err)
+	rc, err := clClient.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for n, val := range map[string]interface{}{
+		"Name":      "Alice",
+		"Gender":    "F",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       30,
+	} {
+		a, err := rc.GetAttr(n)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+	cm, err := cl.NewCredManager(params, org.Keys.Pub, org.Keys.Pub.GenerateUserMasterSecret(),
+		rc)
+	require.NoError(t, err)
+	cred, err := clClient.IssueCredential(context.Background(), cm, "thresholdKey1")
+	require.NoError(t, err)
+	proved, err := clClient.ProveCredential(context.Background(), cm, cred, []string{"Name"})
+	require.NoError(t, err)
+	assert.NotNil(t, proved)
+
+	// credentials are issued as long as threshold of parties are reachable
+	parties[0].Teardown()
+	cm, err = cl.NewCredManager(params, org.Keys.Pub, org.Keys.Pub.GenerateUserMasterSecret(),
+		rc)
+	require.NoError(t, err)
+	cred, err = clClient.IssueCredential(context.Background(), cm, "thresholdKey2")
+	require.NoError(t, err)
+	proved, err = clClient.ProveCredential(context.Background(), cm, cred, []string{"Name"})
+	require.NoError(t, err)
+	assert.NotNil(t, proved)
+}
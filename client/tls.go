@@ -18,6 +18,7 @@
 package client
 
 import (
+	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 
@@ -32,25 +33,38 @@ import (
 // If serverNameOverride != "", the provided serverNameOverride must match server certificate's
 //	CN in order for certificate validation to succeed. This can be used for testing and development
 //	purposes, where server's CN does not resolve to a real domain and doesn't.
-func getTLSCredentials(caCert []byte, serverNameOverride string) (credentials.TransportCredentials,
-	error) {
+// clientCerts are presented to the server if it requests a client certificate.
+func getTLSCredentials(caCert []byte, serverNameOverride string,
+	clientCerts []tls.Certificate) (credentials.TransportCredentials, error) {
 	certPool := x509.NewCertPool()
 	// Try to append the provided caCert to the cert pool
 	if success := certPool.AppendCertsFromPEM(caCert); !success {
 		return nil, fmt.Errorf("cannot append certs from PEM")
 	}
 
-	return credentials.NewClientTLSFromCert(certPool, serverNameOverride), nil
+	return newClientTLSCredentials(certPool, serverNameOverride, clientCerts), nil
 }
 
 // getTLSCredentialsFromSysCertPool retrieves TLS credentials based on host's system certificate
 // pool. This function should be used when the client does not provide a specific CA certificate
 // for validation of the target server.
-func getTLSCredentialsFromSysCertPool() (credentials.TransportCredentials, error) {
+func getTLSCredentialsFromSysCertPool(clientCerts []tls.Certificate) (credentials.TransportCredentials,
+	error) {
 	certPool, err := x509.SystemCertPool()
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve system cert pool (%s)", err)
 	}
 
-	return credentials.NewClientTLSFromCert(certPool, ""), nil
+	return newClientTLSCredentials(certPool, "", clientCerts), nil
+}
+
+// newClientTLSCredentials returns TLS credentials that validate the server's certificate
+// against certPool and present clientCerts to servers that request a client certificate.
+func newClientTLSCredentials(certPool *x509.CertPool, serverName string,
+	clientCerts []tls.Certificate) credentials.TransportCredentials {
+	return credentials.NewTLS(&tls.Config{
+		RootCAs:      certPool,
+		ServerName:   serverName,
+		Certificates: clientCerts,
+	})
 }
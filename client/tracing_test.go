@@ -0,0 +1,94 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	defer otel.SetTracerProvider(prev)
+
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+	caClient, err := NewPseudonymsysCAClient(testGrpcClientConn, group)
+	require.NoError(t, err)
+	c, err := NewPseudonymsysClient(testGrpcClientConn, group)
+	require.NoError(t, err)
+	userSecret := c.GenerateMasterKey()
+	masterNym := caClient.GenerateMasterNym(userSecret)
+	_, err = caClient.GenerateCertificate(context.Background(), userSecret, masterNym)
+	require.NoError(t, err)
+
+	spans := map[string][]sdktrace.ReadOnlySpan{}
+	for _, s := range rec.Ended() {
+		spans[s.Name()] = append(spans[s.Name()], s)
+	}
+	require.Len(t, spans["GenerateCertificate"], 2)
+	var client, server sdktrace.ReadOnlySpan
+	for _, s := range spans["GenerateCertificate"] {
+		if s.SpanKind() == trace.SpanKindClient {
+			client = s
+		} else {
+			server = s
+		}
+	}
+	require.NotNil(t, client)
+	require.NotNil(t, server)
+
+	// the server continues the trace of the client
+	traceID := client.SpanContext().TraceID()
+	assert.Equal(t, traceID, server.SpanContext().TraceID())
+	assert.Equal(t, client.SpanContext().SpanID(), server.Parent().SpanID())
+	assert.True(t, server.Parent().IsRemote())
+
+	// both sides trace the two rounds of the protocol
+	for _, round := range []string{"GenerateCertificate/SchnorrProofRandomData",
+		"GenerateCertificate/SchnorrProofData"} {
+		require.Len(t, spans[round], 2, round)
+		for _, s := range spans[round] {
+			assert.Equal(t, traceID, s.SpanContext().TraceID())
+		}
+	}
+
+	// verification of the proof is traced within the round of the server that
+	// received it
+	require.Len(t, spans["pseudsys.CA.Verify"], 1)
+	verify := spans["pseudsys.CA.Verify"][0]
+	assert.Equal(t, traceID, verify.SpanContext().TraceID())
+	var parent sdktrace.ReadOnlySpan
+	for _, s := range spans["GenerateCertificate/SchnorrProofData"] {
+		if s.SpanContext().SpanID() == verify.Parent().SpanID() {
+			parent = s
+		}
+	}
+	require.NotNil(t, parent)
+	assert.Equal(t, trace.SpanKindServer, parent.SpanKind())
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/record"
+)
+
+// TestTranscript proves a CL credential with a server that exports transcripts, and
+// verifies the transcript offline, intact and tampered with.
+func TestTranscript(t *testing.T) {
+	srv, conn := newTestServer(t, &mockRegKeyDB{data: []string{"transcriptKey1"}})
+	defer conn.Close()
+	var lock sync.Mutex
+	var transcripts []*record.Transcript
+	srv.ExportTranscripts(func(tr *record.Transcript) error {
+		lock.Lock()
+		defer lock.Unlock()
+		transcripts = append(transcripts, tr)
+		return nil
+	})
+
+	client, err := NewCLClient(conn)
+	require.NoError(t, err)
+	rc, err := client.GetCredentialStructure(context.Background())
+	require.NoError(t, err)
+	for name, val := range map[string]interface{}{
+		"Name":      "Jack",
+		"Gender":    "M",
+		"Graduated": "true",
+		"DateMin":   1512643000,
+		"DateMax":   1592643000,
+		"Age":       50,
+	} {
+		a, err := rc.GetAttr(name)
+		require.NoError(t, err)
+		require.NoError(t, a.UpdateValue(val))
+	}
+	pubKey := new(cl.PubKey)
+	cl.ReadGob("testdata/clPubKey.gob", pubKey)
+	cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+		pubKey.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+	cred, err := client.IssueCredential(context.Background(), cm, "transcriptKey1")
+	require.NoError(t, err)
+	acceptableCreds, err := client.GetAcceptableCreds(context.Background())
+	require.NoError(t, err)
+	_, err = client.ProveCredentialWithRanges(context.Background(), cm, cred,
+		acceptableCreds["org1"], []AttrRange{{Attr: "Age", Min: 18, Max: 150}})
+	require.NoError(t, err)
+
+	require.Len(t, transcripts, 1)
+	tr := transcripts[0]
+	assert.Equal(t, "ProveCredential", tr.Protocol)
+	assert.True(t, tr.Verified)
+	dir, err := ioutil.TempDir("", "emmy-transcripts")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path, err := tr.Save(dir)
+	require.NoError(t, err)
+	tr, err = record.LoadTranscript(path)
+	require.NoError(t, err)
+	assert.NoError(t, record.VerifyTranscript(tr))
+
+	// proofs are bound to the nonce
+	nonce := tr.Nonce
+	tr.Nonce = []byte{1, 2, 3}
+	assert.Error(t, record.VerifyTranscript(tr))
+	tr.Nonce = nonce
+
+	// neither can ranges of committed attributes
+	pReq := new(pb.ProveCLCredential)
+	require.NoError(t, proto.Unmarshal(tr.Proof, pReq))
+	require.Len(t, pReq.RangeProofs, 1)
+	pReq.RangeProofs[0].A = "60"
+	tr.Proof, err = proto.Marshal(pReq)
+	require.NoError(t, err)
+	assert.Error(t, record.VerifyTranscript(tr))
+
+	// revealed attributes cannot be changed
+	pReq = new(pb.ProveCLCredential)
+	require.NoError(t, proto.Unmarshal(tr.Proof, pReq))
+	require.NotEmpty(t, pReq.KnownAttrs)
+	pReq.KnownAttrs[0] = []byte("Jill")
+	tr.Proof, err = proto.Marshal(pReq)
+	require.NoError(t, err)
+	assert.Error(t, record.VerifyTranscript(tr))
+}
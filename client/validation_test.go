@@ -0,0 +1,58 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+)
+
+// TestInvalidGroupElement checks that the server rejects elements that are not in the
+// group of the protocol (it requires a running server, started in communication_test.go).
+func TestInvalidGroupElement(t *testing.T) {
+	group, err := config.LoadGroup("pseudonymsys")
+	require.NoError(t, err)
+	caClient, err := NewPseudonymsysCAClient(testGrpcClientConn, group)
+	require.NoError(t, err)
+
+	// P-1 is of order 2, thus not in the subgroup of order Q
+	pMinOne := new(big.Int).Sub(group.P, big.NewInt(1))
+	secret := common.GetRandomInt(group.Q)
+	_, err = caClient.GenerateCertificate(context.Background(), secret,
+		pseudsys.NewNym(pMinOne, pMinOne))
+	assert.True(t, errors.Is(err, ErrInvalidGroupElement), "unexpected error %v", err)
+
+	curve := ec.P256
+	caClientEC, err := NewPseudonymsysCAClientEC(testGrpcClientConn, curve)
+	require.NoError(t, err)
+	g := ec.NewGroup(curve)
+	_, err = caClientEC.GenerateCertificate(context.Background(), secret,
+		ecpseudsys.NewNym(g.ExpBaseG(big.NewInt(1)), ec.NewGroupElement(big.NewInt(1),
+			big.NewInt(1))))
+	assert.True(t, errors.Is(err, ErrInvalidGroupElement), "unexpected error %v", err)
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestProtocolVersion negotiates versions of protocol messages with clients supporting
+// different versions.
+func TestProtocolVersion(t *testing.T) {
+	info, err := GetServiceInfo(context.Background(), testGrpcClientConn)
+	require.NoError(t, err)
+	assert.Equal(t, pb.ProtocolVersions(), info.ProtocolVersions)
+
+	firstResponse := func(v int32) (pb.CL_ProveCredentialClient, *pb.Message, error) {
+		stream, err := pb.NewCLClient(testGrpcClientConn).ProveCredential(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&pb.Message{ClientId: 1, ProtocolVersion: v}))
+		resp, err := stream.Recv()
+		return stream, resp, err
+	}
+
+	// clients that do not give a version run the first one
+	_, resp, err := firstResponse(0)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), resp.ProtocolVersion)
+
+	// newer clients fall back to the version of the server, which cannot be changed
+	stream, resp, err := firstResponse(pb.ProtocolVersion + 1)
+	require.NoError(t, err)
+	assert.Equal(t, pb.ProtocolVersion, resp.ProtocolVersion)
+	require.NoError(t, stream.Send(&pb.Message{ClientId: 1, ProtocolVersion: pb.ProtocolVersion + 1}))
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "unexpected error %v", err)
+
+	_, _, err = firstResponse(-1)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "unexpected error %v", err)
+	assert.Equal(t, pb.ErrorCode_UNSUPPORTED_PROTOCOL_VERSION, pb.ToProtocolError(err).GetCode())
+
+	// the client rejects versions of the server it does not support
+	c := newGenericClient()
+	assert.True(t, errors.Is(c.negotiateVersion(pb.ProtocolVersion+1), ErrUnsupportedVersion))
+	assert.NoError(t, c.negotiateVersion(pb.ProtocolVersion))
+}
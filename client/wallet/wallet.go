@@ -0,0 +1,344 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package wallet keeps credentials obtained by emmy clients, along with the secrets
+// needed to prove them, in a file encrypted with a key derived from a passphrase.
+// Users can thus restart the client and prove previously issued credentials
+// without having them issued again.
+package wallet
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+)
+
+const (
+	saltSize = 16
+	// kdfIterations is the number of PBKDF2 iterations when deriving the encryption
+	// key from the passphrase.
+	kdfIterations = 100000
+)
+
+// Wallet is a store of credentials, backed by an encrypted file. Each change is
+// written to the file immediately.
+type Wallet struct {
+	sync.Mutex
+	path     string
+	salt     []byte
+	key      []byte
+	contents *contents
+}
+
+// contents is the decrypted content of the wallet file.
+type contents struct {
+	Secrets map[string]*big.Int
+	CLCreds map[string]*clCred
+	Nyms    map[string]*nym
+	NymsEC  map[string]*nymEC
+}
+
+type clCred struct {
+	Manager *cl.CredManagerState
+	Cred    *cl.Cred
+}
+
+type nym struct {
+	Nym   *pseudsys.Nym
+	Cred  *pseudsys.Cred
+	Chain []*pseudsys.ChainLink
+}
+
+type nymEC struct {
+	Nym  *ecpseudsys.Nym
+	Cred *ecpseudsys.Cred
+}
+
+// Open opens the wallet in the file at path with passphrase. If the file does not
+// exist, an empty wallet is returned, which is created at path once something is
+// put into it.
+func Open(path, passphrase string) (*Wallet, error) {
+	w := &Wallet{
+		path: path,
+		contents: &contents{
+			Secrets: make(map[string]*big.Int),
+			CLCreds: make(map[string]*clCred),
+			Nyms:    make(map[string]*nym),
+			NymsEC:  make(map[string]*nymEC),
+		},
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		w.salt = make([]byte, saltSize)
+		if _, err := rand.Read(w.salt); err != nil {
+			return nil, err
+		}
+		w.key = deriveKey(passphrase, w.salt, kdfIterations)
+		return w, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < saltSize {
+		return nil, fmt.Errorf("wallet file is corrupted")
+	}
+	w.salt = data[:saltSize]
+	w.key = deriveKey(passphrase, w.salt, kdfIterations)
+	aead, err := newGCM(w.key)
+	if err != nil {
+		return nil, err
+	}
+	data = data[saltSize:]
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("wallet file is corrupted")
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():],
+		w.salt)
+	if err != nil {
+		return nil, fmt.Errorf("wrong passphrase or corrupted wallet file")
+	}
+	if err := gob.NewDecoder(bytes.NewReader(plaintext)).Decode(w.contents); err != nil {
+		return nil, fmt.Errorf("error decoding wallet: %s", err)
+	}
+
+	return w, nil
+}
+
+// PutSecret stores a master secret of the user (for example the secret of the
+// pseudonym system) under name.
+func (w *Wallet) PutSecret(name string, secret *big.Int) error {
+	w.Lock()
+	defer w.Unlock()
+	w.contents.Secrets[name] = secret
+	return w.save()
+}
+
+// Secret returns the secret stored under name.
+func (w *Wallet) Secret(name string) (*big.Int, error) {
+	w.Lock()
+	defer w.Unlock()
+	s, ok := w.contents.Secrets[name]
+	if !ok {
+		return nil, fmt.Errorf("no secret %s in the wallet", name)
+	}
+	return s, nil
+}
+
+// PutCLCred stores the CL credential cred under name, along with the state of
+// the CredManager it was issued to.
+func (w *Wallet) PutCLCred(name string, m *cl.CredManager, cred *cl.Cred) error {
+	state, err := m.State()
+	if err != nil {
+		return err
+	}
+
+	w.Lock()
+	defer w.Unlock()
+	w.contents.CLCreds[name] = &clCred{
+		Manager: state,
+		Cred:    cred,
+	}
+	return w.save()
+}
+
+// CLCred returns the CL credential stored under name, along with a CredManager that
+// can prove or update it.
+func (w *Wallet) CLCred(name string) (*cl.CredManager, *cl.Cred, error) {
+	w.Lock()
+	c, ok := w.contents.CLCreds[name]
+	w.Unlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("no CL credential %s in the wallet", name)
+	}
+
+	m, err := cl.RestoreCredManager(c.Manager)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, c.Cred, nil
+}
+
+// PutNym stores a pseudonym of the pseudonym system under name, along with the
+// credential issued to it (nil if there is none yet).
+func (w *Wallet) PutNym(name string, n *pseudsys.Nym, cred *pseudsys.Cred) error {
+	w.Lock()
+	defer w.Unlock()
+	w.contents.Nyms[name] = &nym{
+		Nym:  n,
+		Cred: cred,
+	}
+	return w.save()
+}
+
+// Nym returns the pseudonym stored under name and its credential.
+func (w *Wallet) Nym(name string) (*pseudsys.Nym, *pseudsys.Cred, error) {
+	w.Lock()
+	defer w.Unlock()
+	n, ok := w.contents.Nyms[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("no nym %s in the wallet", name)
+	}
+	return n.Nym, n.Cred, nil
+}
+
+// PutChain stores chain of credentials that the credential of the nym stored under
+// name is derived from.
+func (w *Wallet) PutChain(name string, chain []*pseudsys.ChainLink) error {
+	w.Lock()
+	defer w.Unlock()
+	n, ok := w.contents.Nyms[name]
+	if !ok {
+		return fmt.Errorf("no nym %s in the wallet", name)
+	}
+	n.Chain = chain
+	return w.save()
+}
+
+// Chain returns the chain of credentials that the credential of the nym stored under
+// name is derived from, which is empty for credentials that are not derived.
+func (w *Wallet) Chain(name string) ([]*pseudsys.ChainLink, error) {
+	w.Lock()
+	defer w.Unlock()
+	n, ok := w.contents.Nyms[name]
+	if !ok {
+		return nil, fmt.Errorf("no nym %s in the wallet", name)
+	}
+	return n.Chain, nil
+}
+
+// PutNymEC is like PutNym, but for pseudonyms of the pseudonym system in EC
+// arithmetic.
+func (w *Wallet) PutNymEC(name string, n *ecpseudsys.Nym, cred *ecpseudsys.Cred) error {
+	w.Lock()
+	defer w.Unlock()
+	w.contents.NymsEC[name] = &nymEC{
+		Nym:  n,
+		Cred: cred,
+	}
+	return w.save()
+}
+
+// NymEC returns the EC pseudonym stored under name and its credential.
+func (w *Wallet) NymEC(name string) (*ecpseudsys.Nym, *ecpseudsys.Cred, error) {
+	w.Lock()
+	defer w.Unlock()
+	n, ok := w.contents.NymsEC[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("no EC nym %s in the wallet", name)
+	}
+	return n.Nym, n.Cred, nil
+}
+
+// Names returns sorted names of all the items in the wallet.
+func (w *Wallet) Names() []string {
+	w.Lock()
+	defer w.Unlock()
+	var names []string
+	for name := range w.contents.Secrets {
+		names = append(names, name)
+	}
+	for name := range w.contents.CLCreds {
+		names = append(names, name)
+	}
+	for name := range w.contents.Nyms {
+		names = append(names, name)
+	}
+	for name := range w.contents.NymsEC {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Delete removes the items stored under name.
+func (w *Wallet) Delete(name string) error {
+	w.Lock()
+	defer w.Unlock()
+	delete(w.contents.Secrets, name)
+	delete(w.contents.CLCreds, name)
+	delete(w.contents.Nyms, name)
+	delete(w.contents.NymsEC, name)
+	return w.save()
+}
+
+// save encrypts the contents of the wallet and replaces the wallet file.
+func (w *Wallet) save() error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(w.contents); err != nil {
+		return err
+	}
+	aead, err := newGCM(w.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data := append(append([]byte{}, w.salt...), nonce...)
+	data = aead.Seal(data, nonce, buf.Bytes(), w.salt)
+	tmp := w.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, w.path)
+}
+
+// deriveKey derives a 256-bit key from passphrase with PBKDF2-HMAC-SHA256. As the
+// key fits a single block of the output, only the first block is computed.
+func deriveKey(passphrase string, salt []byte, iterations int) []byte {
+	prf := hmac.New(sha256.New, []byte(passphrase))
+	prf.Write(salt)
+	binary.Write(prf, binary.BigEndian, uint32(1))
+	u := prf.Sum(nil)
+	key := append([]byte{}, u...)
+	for i := 1; i < iterations; i++ {
+		prf.Reset()
+		prf.Write(u)
+		u = prf.Sum(u[:0])
+		for j := range key {
+			key[j] ^= u[j]
+		}
+	}
+	return key
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
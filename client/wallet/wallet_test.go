@@ -0,0 +1,125 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package wallet
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+)
+
+func TestDeriveKey(t *testing.T) {
+	// test vector of PBKDF2-HMAC-SHA256 from RFC 7914
+	key := deriveKey("passwd", []byte("salt"), 1)
+	assert.Equal(t, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc",
+		hex.EncodeToString(key))
+}
+
+func TestWallet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emmy-wallet")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "wallet")
+
+	c, err := config.NewTestConfig("../testdata")
+	require.NoError(t, err)
+	params := cl.GetDefaultParamSizes()
+	pubKeyPath, secKeyPath := c.LoadCLKeyPaths()
+	org, err := cl.LoadOrg(params, pubKeyPath, secKeyPath)
+	require.NoError(t, err)
+	rc := cl.NewRawCred(cl.NewAttrCount(5, 1, 0))
+	require.NoError(t, rc.AddStrAttr("Name", "Jack", true))
+	require.NoError(t, rc.AddStrAttr("Gender", "M", true))
+	require.NoError(t, rc.AddStrAttr("Graduated", "true", true))
+	require.NoError(t, rc.AddInt64Attr("DateMin", 22342345, true))
+	require.NoError(t, rc.AddInt64Attr("DateMax", 32342345, true))
+	require.NoError(t, rc.AddInt64Attr("Age", 25, false))
+	cm, err := cl.NewCredManager(params, org.Keys.Pub, org.Keys.Pub.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+	credReq, err := cm.GetCredRequest(org.GetCredIssueNonce())
+	require.NoError(t, err)
+	res, err := org.IssueCred(credReq)
+	require.NoError(t, err)
+
+	w, err := Open(path, "passphrase")
+	require.NoError(t, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "empty wallet was written")
+	require.NoError(t, w.PutCLCred("degree", cm, res.Cred))
+	require.NoError(t, w.PutSecret("master", big.NewInt(42)))
+	n := pseudsys.NewNym(big.NewInt(2), big.NewInt(3))
+	require.NoError(t, w.PutNym("org1", n, nil))
+	bt := schnorr.NewBlindedTrans(big.NewInt(6), big.NewInt(7), big.NewInt(8), big.NewInt(9))
+	chain := []*pseudsys.ChainLink{pseudsys.NewChainLink("org0", pseudsys.NewCred(
+		big.NewInt(2), big.NewInt(3), big.NewInt(4), big.NewInt(5), bt, bt))}
+	require.NoError(t, w.PutChain("org1", chain))
+	assert.Error(t, w.PutChain("unknown", chain))
+	nEC := ecpseudsys.NewNym(ec.NewGroupElement(big.NewInt(2), big.NewInt(3)),
+		ec.NewGroupElement(big.NewInt(4), big.NewInt(5)))
+	require.NoError(t, w.PutNymEC("org2", nEC, nil))
+
+	// the wallet can be used after the process restarts
+	_, err = Open(path, "wrong")
+	assert.Error(t, err)
+	w, err = Open(path, "passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"degree", "master", "org1", "org2"}, w.Names())
+
+	secret, err := w.Secret("master")
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(42), secret)
+	nym, cred, err := w.Nym("org1")
+	require.NoError(t, err)
+	assert.Equal(t, n, nym)
+	assert.Nil(t, cred)
+	restoredChain, err := w.Chain("org1")
+	require.NoError(t, err)
+	assert.Equal(t, chain, restoredChain)
+	nymEC, credEC, err := w.NymEC("org2")
+	require.NoError(t, err)
+	assert.Equal(t, nEC, nymEC)
+	assert.Nil(t, credEC)
+
+	restored, clCred, err := w.CLCred("degree")
+	require.NoError(t, err)
+	nonce := org.GetProveCredNonce()
+	rCred, proof, err := restored.BuildProof(clCred, []int{0}, []int{}, nonce)
+	require.NoError(t, err)
+	known, committed := restored.FilterAttributes([]int{0}, []int{})
+	ok, err := org.ProveCred(rCred.A, proof, []int{0}, []int{}, known, committed)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, w.Delete("degree"))
+	_, _, err = w.CLCred("degree")
+	assert.Error(t, err)
+	_, err = w.Secret("unknown")
+	assert.Error(t, err)
+}
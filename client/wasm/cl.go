@@ -0,0 +1,243 @@
+//go:build js && wasm
+
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"syscall/js"
+
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+// newCLClient returns a CL client that runs protocols over conn.
+func newCLClient(conn *client.GrpcWebConn) (*client.CLClient, error) {
+	c, err := client.NewCLClient(nil)
+	if err != nil {
+		return nil, err
+	}
+	c.UseStreamOpener(conn)
+	return c, nil
+}
+
+func getCredentialStructure(conn *client.GrpcWebConn) func(js.Value, []js.Value) interface{} {
+	return func(_ js.Value, _ []js.Value) interface{} {
+		return promise(func() (interface{}, error) {
+			c, err := newCLClient(conn)
+			if err != nil {
+				return nil, err
+			}
+			rc, err := c.GetCredentialStructure(context.Background())
+			if err != nil {
+				return nil, err
+			}
+
+			attrs := rc.GetAttrs()
+			structure := make([]interface{}, len(attrs))
+			for i := range structure {
+				a := attrs[i]
+				desc := map[string]interface{}{
+					"name":  a.GetName(),
+					"type":  attrType(a),
+					"known": a.IsKnown(),
+				}
+				switch t := a.(type) {
+				case *cl.EnumAttr:
+					values := make([]interface{}, len(t.Values))
+					for j, v := range t.Values {
+						values[j] = v
+					}
+					desc["values"] = values
+				case *cl.BytesAttr:
+					desc["size"] = t.Size
+				}
+				structure[i] = desc
+			}
+			return structure, nil
+		})
+	}
+}
+
+func getAcceptableCredentials(conn *client.GrpcWebConn) func(js.Value, []js.Value) interface{} {
+	return func(_ js.Value, _ []js.Value) interface{} {
+		return promise(func() (interface{}, error) {
+			c, err := newCLClient(conn)
+			if err != nil {
+				return nil, err
+			}
+			creds, err := c.GetAcceptableCreds(context.Background())
+			if err != nil {
+				return nil, err
+			}
+
+			res := make(map[string]interface{}, len(creds))
+			for org, attrs := range creds {
+				revealed := make([]interface{}, len(attrs))
+				for i, a := range attrs {
+					revealed[i] = a
+				}
+				res[org] = revealed
+			}
+			return res, nil
+		})
+	}
+}
+
+func issueCredential(conn *client.GrpcWebConn) func(js.Value, []js.Value) interface{} {
+	return func(_ js.Value, args []js.Value) interface{} {
+		if len(args) != 3 {
+			return rejected(fmt.Errorf("issueCredential expects a public key, " +
+				"attributes and a registration key"))
+		}
+		pubKeyPEM, values, regKey := args[0].String(), args[1], args[2].String()
+
+		return promise(func() (interface{}, error) {
+			pubKey, err := cl.ParsePubKeyPEM([]byte(pubKeyPEM))
+			if err != nil {
+				return nil, err
+			}
+			c, err := newCLClient(conn)
+			if err != nil {
+				return nil, err
+			}
+			rc, err := c.GetCredentialStructure(context.Background())
+			if err != nil {
+				return nil, err
+			}
+			for _, a := range rc.GetAttrs() {
+				if err := setAttr(a, values.Get(a.GetName())); err != nil {
+					return nil, err
+				}
+			}
+
+			cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+				pubKey.GenerateUserMasterSecret(), rc)
+			if err != nil {
+				return nil, err
+			}
+			cred, err := c.IssueCredential(context.Background(), cm, regKey)
+			if err != nil {
+				return nil, err
+			}
+
+			state, err := cm.State()
+			if err != nil {
+				return nil, err
+			}
+			manager, err := encode(state)
+			if err != nil {
+				return nil, err
+			}
+			encCred, err := encode(cred)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{
+				"manager": manager,
+				"cred":    encCred,
+			}, nil
+		})
+	}
+}
+
+func proveCredential(conn *client.GrpcWebConn) func(js.Value, []js.Value) interface{} {
+	return func(_ js.Value, args []js.Value) interface{} {
+		if len(args) != 3 {
+			return rejected(fmt.Errorf("proveCredential expects a manager, " +
+				"a credential and revealed attributes"))
+		}
+		state := new(cl.CredManagerState)
+		if err := decode(args[0], state); err != nil {
+			return rejected(fmt.Errorf("invalid manager: %v", err))
+		}
+		cred := new(cl.Cred)
+		if err := decode(args[1], cred); err != nil {
+			return rejected(fmt.Errorf("invalid credential: %v", err))
+		}
+		revealedAttrs := stringSlice(args[2])
+
+		return promise(func() (interface{}, error) {
+			cm, err := cl.RestoreCredManager(state)
+			if err != nil {
+				return nil, err
+			}
+			c, err := newCLClient(conn)
+			if err != nil {
+				return nil, err
+			}
+			sessKey, err := c.ProveCredential(context.Background(), cm, cred, revealedAttrs)
+			if err != nil {
+				return nil, err
+			}
+			return *sessKey, nil
+		})
+	}
+}
+
+// attrType returns the name of the type of a in the JavaScript API.
+func attrType(a cl.CredAttr) string {
+	switch a.(type) {
+	case *cl.Int64Attr:
+		return "int64"
+	case *cl.BlobAttr:
+		return "blob"
+	case *cl.DateAttr:
+		return "date"
+	case *cl.BoolAttr:
+		return "bool"
+	case *cl.EnumAttr:
+		return "enum"
+	case *cl.BytesAttr:
+		return "bytes"
+	default:
+		return "string"
+	}
+}
+
+// setAttr sets the value of a to the JavaScript value v.
+func setAttr(a cl.CredAttr, v js.Value) error {
+	var val interface{}
+	switch a.(type) {
+	case *cl.Int64Attr:
+		if v.Type() != js.TypeNumber {
+			return fmt.Errorf("attribute %s must be a number", a.GetName())
+		}
+		val = v.Int()
+	case *cl.BlobAttr, *cl.BytesAttr:
+		if !v.InstanceOf(js.Global().Get("Uint8Array")) {
+			return fmt.Errorf("attribute %s must be a Uint8Array", a.GetName())
+		}
+		b := make([]byte, v.Length())
+		js.CopyBytesToGo(b, v)
+		val = b
+	case *cl.BoolAttr:
+		if v.Type() != js.TypeBoolean {
+			return fmt.Errorf("attribute %s must be a boolean", a.GetName())
+		}
+		val = v.Bool()
+	default:
+		if v.Type() != js.TypeString {
+			return fmt.Errorf("attribute %s must be a string", a.GetName())
+		}
+		val = v.String()
+	}
+	return a.UpdateValue(val)
+}
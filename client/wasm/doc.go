@@ -0,0 +1,86 @@
+//go:build js && wasm
+
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Command wasm exposes the CL and pseudonym system provers of package
+// github.com/xlab-si/emmy/client to JavaScript, so that browser applications can
+// obtain and prove credentials without a native helper. It is compiled to
+// WebAssembly and talks to emmy server over gRPC-Web (see client.GrpcWebConn), so
+// the server needs the gRPC-Web endpoint enabled, with the origin of the application
+// among its allowed origins.
+//
+// # Building
+//
+// To compile the bindings and copy the JavaScript support file of the Go
+// distribution next to them, run:
+//
+//	GOOS=js GOARCH=wasm go build -o emmy.wasm github.com/xlab-si/emmy/client/wasm
+//	cp "$(go env GOROOT)/lib/wasm/wasm_exec.js" .
+//
+// The module is loaded like any Go WebAssembly program:
+//
+//	const go = new Go();
+//	const {instance} = await WebAssembly.instantiateStreaming(fetch("emmy.wasm"),
+//		go.importObject);
+//	go.run(instance);
+//
+// after which the global object emmy holds the bindings.
+//
+// # JavaScript API
+//
+// emmy.connect(url) returns a connection to the gRPC-Web endpoint of emmy server at
+// url, with the following methods. Methods that run protocols with the server return
+// a Promise, which is rejected with an Error when the protocol fails.
+//
+//	getCredentialStructure()
+//		Promise of an array of {name, type, known} describing the attributes of
+//		CL credentials, where type is "string", "int64", "blob", "date", "bool",
+//		"enum" (with the allowed values in values) or "bytes" (with their size).
+//	getAcceptableCredentials()
+//		Promise of an object mapping organizations to the attributes they
+//		require to be revealed.
+//	issueCredential(pubKey, attrs, regKey)
+//		Obtains a CL credential with the attribute values in object attrs (strings,
+//		including dates in the form 2006-01-02, numbers, booleans or Uint8Arrays),
+//		for the organization with the public key pubKey in PEM format. Promise of
+//		{manager, cred}.
+//	proveCredential(manager, cred, revealedAttrs)
+//		Proves the possession of cred, revealing the attributes named in the array
+//		revealedAttrs. Promise of the session key.
+//	pseudonymsys(group, org)
+//		Returns the pseudonym system client of the organization org (the default
+//		organization of the server when org is empty) for the Schnorr group
+//		{p, g, q}, with the following methods.
+//	pseudonymsys(...).generateMasterKey()
+//		Returns a new master secret key.
+//	pseudonymsys(...).generateCertificate(secret)
+//		Promise of the CA certificate of the master pseudonym of secret.
+//	pseudonymsys(...).generateNym(secret, cert, regKey)
+//		Promise of a new pseudonym registered with the organization.
+//	pseudonymsys(...).obtainCredential(secret, nym, pubKey)
+//		Promise of a credential issued to nym by the organization with the
+//		public key {h1, h2}.
+//	pseudonymsys(...).transferCredential(issuer, secret, nym, cred)
+//		Transfers the credential cred of the organization issuer to the
+//		organization. Promise of the session key.
+//
+// Big integers (keys, group parameters) are decimal strings. Credentials, pseudonyms,
+// certificates and credential managers are opaque strings, which applications store
+// (for example in IndexedDB) and pass back to the bindings. Managers and master
+// secret keys hold the secrets of the user and need to be protected accordingly.
+package main
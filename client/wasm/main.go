@@ -0,0 +1,127 @@
+//go:build js && wasm
+
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/gob"
+	"fmt"
+	"math/big"
+	"syscall/js"
+
+	"github.com/xlab-si/emmy/client"
+)
+
+func main() {
+	js.Global().Set("emmy", map[string]interface{}{
+		"connect": js.FuncOf(connect),
+	})
+	// keep the bindings available to JavaScript
+	select {}
+}
+
+// connect returns the JavaScript object of a gRPC-Web connection to the server at
+// the URL in args[0].
+func connect(_ js.Value, args []js.Value) interface{} {
+	if len(args) != 1 {
+		return jsError(fmt.Errorf("connect expects the URL of emmy server"))
+	}
+	conn := client.NewGrpcWebConn(args[0].String(), nil)
+
+	return map[string]interface{}{
+		"getCredentialStructure":   js.FuncOf(getCredentialStructure(conn)),
+		"getAcceptableCredentials": js.FuncOf(getAcceptableCredentials(conn)),
+		"issueCredential":          js.FuncOf(issueCredential(conn)),
+		"proveCredential":          js.FuncOf(proveCredential(conn)),
+		"pseudonymsys":             js.FuncOf(pseudonymsys(conn)),
+	}
+}
+
+// promise returns a JavaScript Promise of the result of f, which runs in a new
+// goroutine as it may block on requests to the server.
+func promise(f func() (interface{}, error)) js.Value {
+	executor := js.FuncOf(func(_ js.Value, args []js.Value) interface{} {
+		resolve, reject := args[0], args[1]
+		go func() {
+			res, err := f()
+			if err != nil {
+				reject.Invoke(jsError(err))
+				return
+			}
+			resolve.Invoke(res)
+		}()
+		return nil
+	})
+	// the executor is called by the Promise constructor
+	defer executor.Release()
+
+	return js.Global().Get("Promise").New(executor)
+}
+
+// rejected returns a JavaScript Promise that is rejected with err.
+func rejected(err error) js.Value {
+	return promise(func() (interface{}, error) {
+		return nil, err
+	})
+}
+
+func jsError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
+
+// encode returns v as an opaque string to be stored by JavaScript applications.
+func encode(v interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// decode decodes the string s returned by encode into v.
+func decode(s js.Value, v interface{}) error {
+	if s.Type() != js.TypeString {
+		return fmt.Errorf("expected an encoded value, got %s", s.Type())
+	}
+	data, err := base64.StdEncoding.DecodeString(s.String())
+	if err != nil {
+		return err
+	}
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// bigInt parses the decimal string v.
+func bigInt(v js.Value) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(v.String(), 10)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a decimal integer", v.String())
+	}
+	return n, nil
+}
+
+// stringSlice returns the elements of the JavaScript array v.
+func stringSlice(v js.Value) []string {
+	s := make([]string, v.Length())
+	for i := range s {
+		s[i] = v.Index(i).String()
+	}
+	return s
+}
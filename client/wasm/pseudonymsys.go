@@ -0,0 +1,220 @@
+//go:build js && wasm
+
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"syscall/js"
+
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+)
+
+// pseudonymsys returns the JavaScript object of the pseudonym system client of the
+// organization args[1] in the Schnorr group args[0].
+func pseudonymsys(conn *client.GrpcWebConn) func(js.Value, []js.Value) interface{} {
+	return func(_ js.Value, args []js.Value) interface{} {
+		if len(args) < 1 {
+			return jsError(fmt.Errorf("pseudonymsys expects a Schnorr group"))
+		}
+		group, err := schnorrGroup(args[0])
+		if err != nil {
+			return jsError(err)
+		}
+		org := ""
+		if len(args) > 1 && args[1].Type() == js.TypeString {
+			org = args[1].String()
+		}
+		p := &pseudonymsysBindings{
+			conn:  conn,
+			group: group,
+			org:   org,
+		}
+
+		return map[string]interface{}{
+			"generateMasterKey":   js.FuncOf(p.generateMasterKey),
+			"generateCertificate": js.FuncOf(p.generateCertificate),
+			"generateNym":         js.FuncOf(p.generateNym),
+			"obtainCredential":    js.FuncOf(p.obtainCredential),
+			"transferCredential":  js.FuncOf(p.transferCredential),
+		}
+	}
+}
+
+type pseudonymsysBindings struct {
+	conn  *client.GrpcWebConn
+	group *schnorr.Group
+	org   string
+}
+
+func (p *pseudonymsysBindings) client() (*client.PseudonymsysClient, error) {
+	c, err := client.NewPseudonymsysClient(nil, p.group)
+	if err != nil {
+		return nil, err
+	}
+	c.UseStreamOpener(p.conn)
+	c.UseOrg(p.org)
+	return c, nil
+}
+
+func (p *pseudonymsysBindings) generateMasterKey(_ js.Value, _ []js.Value) interface{} {
+	c, err := p.client()
+	if err != nil {
+		return jsError(err)
+	}
+	return c.GenerateMasterKey().String()
+}
+
+func (p *pseudonymsysBindings) generateCertificate(_ js.Value, args []js.Value) interface{} {
+	if len(args) != 1 {
+		return rejected(fmt.Errorf("generateCertificate expects a master key"))
+	}
+	secret, err := bigInt(args[0])
+	if err != nil {
+		return rejected(err)
+	}
+
+	return promise(func() (interface{}, error) {
+		c, err := client.NewPseudonymsysCAClient(nil, p.group)
+		if err != nil {
+			return nil, err
+		}
+		c.UseStreamOpener(p.conn)
+		nym := c.GenerateMasterNym(secret)
+		cert, err := c.GenerateCertificate(context.Background(), secret, nym)
+		if err != nil {
+			return nil, err
+		}
+		return encode(cert)
+	})
+}
+
+func (p *pseudonymsysBindings) generateNym(_ js.Value, args []js.Value) interface{} {
+	if len(args) != 3 {
+		return rejected(fmt.Errorf("generateNym expects a master key, " +
+			"a certificate and a registration key"))
+	}
+	secret, err := bigInt(args[0])
+	if err != nil {
+		return rejected(err)
+	}
+	cert := new(pseudsys.CACert)
+	if err := decode(args[1], cert); err != nil {
+		return rejected(fmt.Errorf("invalid certificate: %v", err))
+	}
+	regKey := args[2].String()
+
+	return promise(func() (interface{}, error) {
+		c, err := p.client()
+		if err != nil {
+			return nil, err
+		}
+		nym, err := c.GenerateNym(context.Background(), secret, cert, regKey)
+		if err != nil {
+			return nil, err
+		}
+		return encode(nym)
+	})
+}
+
+func (p *pseudonymsysBindings) obtainCredential(_ js.Value, args []js.Value) interface{} {
+	if len(args) != 3 {
+		return rejected(fmt.Errorf("obtainCredential expects a master key, " +
+			"a pseudonym and a public key"))
+	}
+	secret, err := bigInt(args[0])
+	if err != nil {
+		return rejected(err)
+	}
+	nym := new(pseudsys.Nym)
+	if err := decode(args[1], nym); err != nil {
+		return rejected(fmt.Errorf("invalid pseudonym: %v", err))
+	}
+	h1, err := bigInt(args[2].Get("h1"))
+	if err != nil {
+		return rejected(err)
+	}
+	h2, err := bigInt(args[2].Get("h2"))
+	if err != nil {
+		return rejected(err)
+	}
+
+	return promise(func() (interface{}, error) {
+		c, err := p.client()
+		if err != nil {
+			return nil, err
+		}
+		cred, err := c.ObtainCredential(context.Background(), secret, nym,
+			pseudsys.NewPubKey(h1, h2))
+		if err != nil {
+			return nil, err
+		}
+		return encode(cred)
+	})
+}
+
+func (p *pseudonymsysBindings) transferCredential(_ js.Value, args []js.Value) interface{} {
+	if len(args) != 4 {
+		return rejected(fmt.Errorf("transferCredential expects an issuer, a master key, " +
+			"a pseudonym and a credential"))
+	}
+	issuer := args[0].String()
+	secret, err := bigInt(args[1])
+	if err != nil {
+		return rejected(err)
+	}
+	nym := new(pseudsys.Nym)
+	if err := decode(args[2], nym); err != nil {
+		return rejected(fmt.Errorf("invalid pseudonym: %v", err))
+	}
+	cred := new(pseudsys.Cred)
+	if err := decode(args[3], cred); err != nil {
+		return rejected(fmt.Errorf("invalid credential: %v", err))
+	}
+
+	return promise(func() (interface{}, error) {
+		c, err := p.client()
+		if err != nil {
+			return nil, err
+		}
+		sessKey, err := c.TransferCredential(context.Background(), issuer, secret, nym, cred)
+		if err != nil {
+			return nil, err
+		}
+		return sessKey.GetValue(), nil
+	})
+}
+
+// schnorrGroup returns the Schnorr group with the decimal parameters p, g and q of
+// the JavaScript object v.
+func schnorrGroup(v js.Value) (*schnorr.Group, error) {
+	params := make([]*big.Int, 3)
+	for i, name := range []string{"p", "g", "q"} {
+		n, err := bigInt(v.Get(name))
+		if err != nil {
+			return nil, fmt.Errorf("group parameter %s: %v", name, err)
+		}
+		params[i] = n
+	}
+	return schnorr.NewGroupFromParams(params[0], params[1], params[2]), nil
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package client
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"github.com/xlab-si/emmy/webauthn"
+)
+
+// TestCLWithDeviceBinding runs CL protocols against a server requiring credentials
+// to be bound to WebAuthn authenticators. The server is reached over gRPC-Web, so
+// that it does not need to listen on another port.
+func TestCLWithDeviceBinding(t *testing.T) {
+	logger, _ := log.NewStdoutLogger("testServer", log.NOTICE, log.FORMAT_LONG)
+	srv, err := server.NewServer("testdata/server.pem", "testdata/server.key",
+		&mockRegKeyDB{data: []string{"testRegKey8", "testRegKey9"}},
+		cl.NewMockRecordManager(), logger)
+	require.NoError(t, err)
+
+	rp := webauthn.NewRelyingParty("localhost", "https://localhost")
+	// Graduated attribute of the test credential structure holds the binding
+	require.NoError(t, srv.EnableDeviceBinding(rp, webauthn.NewMemCredentialStore(),
+		"Graduated"))
+	assert.Error(t, srv.EnableDeviceBinding(rp, webauthn.NewMemCredentialStore(), "Age"),
+		"committed attributes cannot hold the binding")
+
+	endpoint := httptest.NewServer(server.NewGrpcWebHandler(srv, nil))
+	defer endpoint.Close()
+
+	newClient := func(a webauthn.Authenticator) *CLClient {
+		client, err := NewCLClient(testGrpcClientConn)
+		require.NoError(t, err)
+		client.UseStreamOpener(NewGrpcWebConn(endpoint.URL, nil))
+		if a != nil {
+			client.BindDevice(a, "Graduated")
+		}
+		return client
+	}
+	newCredManager := func(client *CLClient) *cl.CredManager {
+		rc, err := client.GetCredentialStructure(context.Background())
+		require.NoError(t, err)
+		for name, val := range map[string]interface{}{
+			"Name":      "Jack",
+			"Gender":    "M",
+			"Graduated": "",
+			"DateMin":   1512643000,
+			"DateMax":   1592643000,
+			"Age":       50,
+		} {
+			a, err := rc.GetAttr(name)
+			require.NoError(t, err)
+			require.NoError(t, a.UpdateValue(val))
+		}
+		pubKey := new(cl.PubKey)
+		cl.ReadGob("testdata/clPubKey.gob", pubKey)
+		cm, err := cl.NewCredManager(cl.GetDefaultParamSizes(), pubKey,
+			pubKey.GenerateUserMasterSecret(), rc)
+		require.NoError(t, err)
+		return cm
+	}
+
+	// credentials cannot be issued without registering a device
+	_, err = newClient(nil).IssueCredential(context.Background(), newCredManager(newClient(nil)), "testRegKey8")
+	assert.Error(t, err)
+
+	device, err := webauthn.NewSoftAuthenticator(rp.ID, rp.Origin)
+	require.NoError(t, err)
+	client := newClient(device)
+	cm := newCredManager(client)
+	cred, err := client.IssueCredential(context.Background(), cm, "testRegKey9")
+	require.NoError(t, err)
+
+	sessKey, err := client.ProveCredential(context.Background(), cm, cred, []string{"Name"})
+	require.NoError(t, err)
+	assert.NotNil(t, sessKey)
+
+	// a stolen credential cannot be proved without the device
+	_, err = newClient(nil).ProveCredential(context.Background(), cm, cred, []string{"Name", "Graduated"})
+	assert.Error(t, err)
+	otherDevice, err := webauthn.NewSoftAuthenticator(rp.ID, rp.Origin)
+	require.NoError(t, err)
+	_, err = newClient(otherDevice).ProveCredential(context.Background(), cm, cred, []string{"Name"})
+	assert.Error(t, err)
+
+	// the binding cannot be removed by updating the credential
+	binding, _ := cm.RawCred.GetAttr("Graduated")
+	require.NoError(t, binding.UpdateValue("true"))
+	_, err = client.UpdateCredential(context.Background(), cm, cm.RawCred)
+	assert.Error(t, err)
+}
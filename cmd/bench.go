@@ -0,0 +1,134 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/bench"
+)
+
+var BenchCmd = cli.Command{
+	Name:  "bench",
+	Usage: "Measures performance of emmy's cryptographic schemes and compares measurements",
+	Subcommands: []cli.Command{
+		{
+			Name:  "run",
+			Usage: "Measures key generation, issuance, proving and verification, writing JSON results",
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:  "params, p",
+					Usage: "`PRESET` of CL parameters (repeatable, test by default)",
+				},
+				&cli.IntFlag{
+					Name:  "iterations, n",
+					Value: bench.DefaultOptions().Iterations,
+					Usage: "`N` runs of each operation but key generation",
+				},
+				&cli.IntFlag{
+					Name:  "keygen-iterations",
+					Value: bench.DefaultOptions().KeygenIterations,
+					Usage: "`N` runs of key generation",
+				},
+				&cli.StringFlag{
+					Name:  "out, o",
+					Usage: "`FILE` results are written to (standard output by default)",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				opts := bench.DefaultOptions()
+				if p := ctx.StringSlice("params"); len(p) > 0 {
+					opts.Presets = p
+				}
+				opts.Iterations = ctx.Int("iterations")
+				opts.KeygenIterations = ctx.Int("keygen-iterations")
+				return exitOnError(runBench(opts, ctx.String("out")))
+			},
+		},
+		{
+			Name:      "compare",
+			Usage:     "Compares results of two runs, failing if any operation regressed",
+			ArgsUsage: "BASE CURRENT",
+			Flags: []cli.Flag{
+				&cli.Float64Flag{
+					Name:  "tolerance, t",
+					Value: 0.1,
+					Usage: "`RATIO` by which an operation may get slower before it is reported",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				if ctx.NArg() != 2 {
+					return exitOnError(fmt.Errorf("expected two result files"))
+				}
+				return exitOnError(compareBench(ctx.Args().Get(0), ctx.Args().Get(1),
+					ctx.Float64("tolerance")))
+			},
+		},
+	},
+}
+
+// runBench measures operations with opts and writes results to file out,
+// or to standard output if out is empty.
+func runBench(opts *bench.Options, out string) error {
+	report, err := bench.Run(opts)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return report.WriteJSON(w)
+}
+
+// compareBench compares results in files base and current, returning an error
+// if any operation regressed by more than tolerance.
+func compareBench(base, current string, tolerance float64) error {
+	reports := make([]*bench.Report, 2)
+	for i, path := range []string{base, current} {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		reports[i], err = bench.ReadReport(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	regressions := bench.Compare(reports[0], reports[1], tolerance)
+	for _, r := range regressions {
+		fmt.Println(r)
+	}
+	if len(regressions) > 0 {
+		return fmt.Errorf("%d regressions found", len(regressions))
+	}
+
+	fmt.Println("no regressions found")
+	return nil
+}
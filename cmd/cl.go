@@ -0,0 +1,314 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/client/wallet"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"google.golang.org/grpc"
+)
+
+// clWalletFlags select the wallet file and the credential in it that CL subcommands
+// store or read.
+var clWalletFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "wallet",
+		Value: "emmy.wallet",
+		Usage: "`PATH` to the wallet file holding credentials",
+	},
+	&cli.StringFlag{
+		Name:   "passphrase",
+		EnvVar: "EMMY_WALLET_PASSPHRASE",
+		Usage:  "passphrase of the wallet",
+	},
+	&cli.StringFlag{
+		Name:  "cred",
+		Value: "cl",
+		Usage: "`NAME` of the credential in the wallet",
+	},
+}
+
+// clAttrFlags give values of attributes of credentials to be issued or updated.
+var clAttrFlags = []cli.Flag{
+	&cli.StringSliceFlag{
+		Name:  "attr, a",
+		Usage: "value of an attribute as `NAME=VALUE`, may be repeated",
+	},
+	&cli.StringFlag{
+		Name:  "attrs",
+		Usage: "`PATH` to a JSON file with an object mapping names of attributes to their values",
+	},
+}
+
+var clCmd = cli.Command{
+	Name:     "cl",
+	Usage:    "Obtain, update and prove CL anonymous credentials",
+	Category: "Anonymous credentials",
+	Subcommands: []cli.Command{
+		{
+			Name:  "structure",
+			Usage: "Print the structure of credentials issued by the server",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "schema",
+					Usage: "`NAME` of the credential schema, the default one when empty",
+				},
+				&cli.StringFlag{
+					Name:  "version",
+					Usage: "`VERSION` of the credential schema, the latest one when empty",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				return runCL(ctx, func(ctx *cli.Context, c *client.CLClient) error {
+					rc, err := c.GetCredentialSchema(context.Background(), ctx.String("schema"),
+						ctx.String("version"))
+					if err != nil {
+						return err
+					}
+					printAttrs(rc)
+					return nil
+				})
+			},
+		},
+		{
+			Name:  "issue",
+			Usage: "Obtain a new credential and store it to the wallet",
+			Flags: append(append([]cli.Flag{
+				&cli.StringFlag{
+					Name:  "regkey",
+					Usage: "registration `KEY` obtained from the issuer",
+				},
+				&cli.StringFlag{
+					Name:  "pubkey",
+					Value: clPubKeyPath(),
+					Usage: "`PATH` to the CL public key of the issuer",
+				},
+			}, clWalletFlags...), clAttrFlags...),
+			Action: func(ctx *cli.Context) error {
+				return runCL(ctx, func(ctx *cli.Context, c *client.CLClient) error {
+					return issueCLCred(ctx, c)
+				})
+			},
+		},
+		{
+			Name:  "update",
+			Usage: "Update known attributes of a credential in the wallet",
+			Flags: append(append([]cli.Flag{}, clWalletFlags...), clAttrFlags...),
+			Action: func(ctx *cli.Context) error {
+				return runCL(ctx, func(ctx *cli.Context, c *client.CLClient) error {
+					return updateCLCred(ctx, c)
+				})
+			},
+		},
+		{
+			Name:  "prove",
+			Usage: "Prove possession of a credential in the wallet and obtain a session key",
+			Flags: append([]cli.Flag{
+				&cli.StringSliceFlag{
+					Name:  "reveal, r",
+					Usage: "`NAME` of an attribute to reveal, may be repeated",
+				},
+			}, clWalletFlags...),
+			Action: func(ctx *cli.Context) error {
+				return runCL(ctx, func(ctx *cli.Context, c *client.CLClient) error {
+					w, err := openWallet(ctx)
+					if err != nil {
+						return err
+					}
+					cm, cred, err := w.CLCred(ctx.String("cred"))
+					if err != nil {
+						return err
+					}
+					sessionKey, err := c.ProveCredential(context.Background(), cm, cred,
+						ctx.StringSlice("reveal"))
+					if err != nil {
+						return err
+					}
+					fmt.Println("Session key:", *sessionKey)
+					return nil
+				})
+			},
+		},
+	},
+}
+
+// runCL runs f with a CL client, connected to the server given by flags of the
+// client command.
+func runCL(ctx *cli.Context, f func(ctx *cli.Context, c *client.CLClient) error) error {
+	return run(ctx.Parent().Parent(), ctx, func(ctx *cli.Context, conn *grpc.ClientConn) error {
+		c, err := client.NewCLClient(conn)
+		if err != nil {
+			return err
+		}
+		return f(ctx, c)
+	})
+}
+
+// clPubKeyPath returns the configured path to the CL public key.
+func clPubKeyPath() string {
+	pubKeyPath, _ := config.LoadCLKeyPaths()
+	return pubKeyPath
+}
+
+// issueCLCred obtains a credential with attributes given by flags and stores it to
+// the wallet.
+func issueCLCred(ctx *cli.Context, c *client.CLClient) error {
+	if ctx.String("regkey") == "" {
+		return fmt.Errorf("registration key is required")
+	}
+	w, err := openWallet(ctx)
+	if err != nil {
+		return err
+	}
+	pubKey, err := cl.ReadPubKey(ctx.String("pubkey"))
+	if err != nil {
+		return err
+	}
+	params, err := cl.LoadParams()
+	if err != nil {
+		return err
+	}
+
+	rc, err := c.GetCredentialStructure(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := setAttrs(ctx, rc); err != nil {
+		return err
+	}
+	cm, err := cl.NewCredManager(params, pubKey, pubKey.GenerateUserMasterSecret(), rc)
+	if err != nil {
+		return err
+	}
+
+	cred, err := c.IssueCredential(context.Background(), cm, ctx.String("regkey"))
+	if err != nil {
+		return err
+	}
+	if err := w.PutCLCred(ctx.String("cred"), cm, cred); err != nil {
+		return err
+	}
+	fmt.Printf("Credential stored in the wallet as %s\n", ctx.String("cred"))
+	return nil
+}
+
+// updateCLCred updates the credential in the wallet with attributes given by flags,
+// keeping values of the remaining attributes.
+func updateCLCred(ctx *cli.Context, c *client.CLClient) error {
+	w, err := openWallet(ctx)
+	if err != nil {
+		return err
+	}
+	cm, _, err := w.CLCred(ctx.String("cred"))
+	if err != nil {
+		return err
+	}
+	if err := setAttrs(ctx, cm.RawCred); err != nil {
+		return err
+	}
+
+	cred, err := c.UpdateCredential(context.Background(), cm, cm.RawCred)
+	if err != nil {
+		return err
+	}
+	if err := w.PutCLCred(ctx.String("cred"), cm, cred); err != nil {
+		return err
+	}
+	fmt.Printf("Credential %s updated\n", ctx.String("cred"))
+	return nil
+}
+
+func openWallet(ctx *cli.Context) (*wallet.Wallet, error) {
+	if ctx.String("passphrase") == "" {
+		return nil, fmt.Errorf("passphrase of the wallet is required")
+	}
+	return wallet.Open(ctx.String("wallet"), ctx.String("passphrase"))
+}
+
+// setAttrs sets attributes of rc to values read from the JSON file given by flag attrs
+// and then to values given by flags attr.
+func setAttrs(ctx *cli.Context, rc *cl.RawCred) error {
+	vals := make(map[string]string)
+	if path := ctx.String("attrs"); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+		var m map[string]interface{}
+		if err := d.Decode(&m); err != nil {
+			return fmt.Errorf("cannot read attributes from %s: %v", path, err)
+		}
+		for name, v := range m {
+			vals[name] = fmt.Sprint(v)
+		}
+	}
+	for _, a := range ctx.StringSlice("attr") {
+		kv := strings.SplitN(a, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("attribute %s not given as NAME=VALUE", a)
+		}
+		vals[kv[0]] = kv[1]
+	}
+
+	for name, v := range vals {
+		a, err := rc.GetAttr(name)
+		if err != nil {
+			return err
+		}
+		if err := setAttr(a, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setAttr sets the value of a to v, parsed according to the type of a (see
+// cl.ParseAttrValue).
+func setAttr(a cl.CredAttr, v string) error {
+	val, err := cl.ParseAttrValue(a, v)
+	if err != nil {
+		return err
+	}
+	return a.UpdateValue(val)
+}
+
+// printAttrs prints attributes of rc ordered by their index.
+func printAttrs(rc *cl.RawCred) {
+	attrs := rc.GetAttrs()
+	indices := make([]int, 0, len(attrs))
+	for i := range attrs {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	for _, i := range indices {
+		fmt.Printf("%d: %s\n", i, attrs[i])
+	}
+}
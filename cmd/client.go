@@ -18,14 +18,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
 	"io/ioutil"
 
-	"path/filepath"
-
 	"github.com/urfave/cli"
 	"github.com/xlab-si/emmy/client"
 	"github.com/xlab-si/emmy/config"
@@ -66,16 +65,28 @@ var clientFlags = []cli.Flag{
 	// serverNameOverride flag.
 	&cli.StringFlag{
 		Name:  "servername",
-		Value: "",
+		Value: config.LoadNetworkConfig().TLS.ServerName,
 		Usage: "Name of emmy server for overriding the server name stated in cert's CN",
 	},
 	// caCertFlag keeps the path to CA's certificate in PEM format
 	// (for establishing a secure channel with the server).
 	&cli.StringFlag{
 		Name:  "cacert",
-		Value: filepath.Join(config.LoadTestdataDir(), "server.pem"),
+		Value: config.LoadNetworkConfig().TLS.CACertFile,
 		Usage: "`PATH` to certificate file of the CA that issued emmy server's certificate",
 	},
+	// clientCertFlag and clientKeyFlag keep paths to the client's certificate and private
+	// key in PEM format, for servers that require clients to authenticate with certificates.
+	&cli.StringFlag{
+		Name:  "clientcert",
+		Value: config.LoadNetworkConfig().TLS.ClientCertFile,
+		Usage: "`PATH` to client's certificate file, presented to the server",
+	},
+	&cli.StringFlag{
+		Name:  "clientkey",
+		Value: config.LoadNetworkConfig().TLS.ClientKeyFile,
+		Usage: "`PATH` to client's private key file",
+	},
 
 	// sysCertPoolFlag indicates whether a client should use system's certificate pool to validate
 	// the server's certificate..
@@ -83,12 +94,29 @@ var clientFlags = []cli.Flag{
 		Name:  "syscertpool",
 		Usage: "Whether to use host system's certificate pool to validate the server",
 	},
-	// timeoutFlag indicates the timeout (in seconds) for establishing connection to the server.
-	// If connection cannot be established before the timeout, the client fails.
+	// insecureFlag makes the client connect without TLS, to a server started with --insecure.
+	&cli.BoolFlag{
+		Name:  "insecure",
+		Usage: "Whether to connect to the server without TLS (development only)",
+	},
+	// compressionFlag names the compressor applied to messages sent to the server.
+	&cli.StringFlag{
+		Name:  "compression",
+		Value: "",
+		Usage: "Compression of messages sent to the server (gzip), none by default",
+	},
+	// proxyFlag points to a proxy through which the client connects to the server.
+	&cli.StringFlag{
+		Name:  "proxy",
+		Value: "",
+		Usage: "`URL` of a SOCKS5 (socks5://host:port) or HTTP (http://host:port) proxy to connect through",
+	},
+	// timeoutFlag indicates the timeout (in milliseconds) for establishing connection to the
+	// server. If connection cannot be established before the timeout, the client fails.
 	&cli.IntFlag{
 		Name:  "timeout, t",
-		Value: config.LoadTimeout(),
-		Usage: "timeout (in seconds) for establishing connection with the server",
+		Value: int(config.LoadNetworkConfig().Timeouts.Connect / time.Millisecond),
+		Usage: "timeout (in milliseconds) for establishing connection with the server",
 	},
 	logLevelFlag,
 }
@@ -114,11 +142,13 @@ var clientSubcommands = []cli.Command{
 		Category: "Info",
 		Action: func(ctx *cli.Context) error {
 			return run(ctx.Parent(), ctx, func(ctx *cli.Context, conn *grpc.ClientConn) error {
-				_, err := client.GetServiceInfo(conn)
+				_, err := client.GetServiceInfo(context.Background(), conn)
 				return err
 			})
 		},
 	},
+	clCmd,
+	pseudonymsysCmd,
 }
 
 // run accepts pointers to parent (command) and child (subcommand) contexts in order to read
@@ -133,18 +163,18 @@ func run(ctx, subCmdCtx *cli.Context, f func(ctx *cli.Context, conn *grpc.Client
 	}
 	client.SetLogger(logger)
 
-	// configure how clients will access emmy server via TLS.
-	var connCfg *client.ConnectionConfig
-	if ctx.Bool("syscertpool") {
-		connCfg = client.NewConnectionConfig(ctx.String("server"), "", nil,
-			ctx.Int("t"))
-	} else {
-		caCert, err := ioutil.ReadFile(ctx.String("cacert"))
-		if err != nil {
-			return cli.NewExitError(err.Error(), 2)
-		}
-		connCfg = client.NewConnectionConfig(ctx.String("server"), ctx.String("servername"),
-			caCert, ctx.Int("t"))
+	connCfg, err := connectionConfig(ctx, ctx.String("server"))
+	if err != nil {
+		return cli.NewExitError(err.Error(), 2)
+	}
+
+	netConf := config.LoadNetworkConfig()
+	// clients that fail because the server cannot be reached are run again
+	retry := client.RetryPolicy{
+		MaxAttempts:    netConf.Retry.MaxAttempts,
+		InitialBackoff: netConf.Retry.InitialBackoff,
+		MaxBackoff:     netConf.Retry.MaxBackoff,
+		Multiplier:     netConf.Retry.Multiplier,
 	}
 
 	// conn is a connection to emmy server.
@@ -153,11 +183,16 @@ func run(ctx, subCmdCtx *cli.Context, f func(ctx *cli.Context, conn *grpc.Client
 	var conn *grpc.ClientConn
 
 	// Establish a connection to emmy server
-	conn, err = client.GetConnection(connCfg)
+	conn, err = client.NewConnection(connCfg)
 	if err != nil {
 		return cli.NewExitError(fmt.Sprintf("Cannot connect to gRPC server: %v", err), 2)
 	}
 	defer conn.Close()
+	run := func() error {
+		return client.Retry(context.Background(), retry, func() error {
+			return f(subCmdCtx, conn)
+		})
+	}
 
 	var wg sync.WaitGroup
 	start := time.Now()
@@ -166,11 +201,11 @@ func run(ctx, subCmdCtx *cli.Context, f func(ctx *cli.Context, conn *grpc.Client
 		if ctx.Bool("concurrent") {
 			wg.Add(1)
 			go func() {
-				err = f(subCmdCtx, conn)
+				err = run()
 				defer wg.Done()
 			}()
 		} else {
-			err = f(subCmdCtx, conn)
+			err = run()
 		}
 	}
 	wg.Wait()
@@ -185,3 +220,41 @@ func run(ctx, subCmdCtx *cli.Context, f func(ctx *cli.Context, conn *grpc.Client
 	fmt.Printf("***Time: %v seconds***\n", elapsed.Seconds())
 	return nil
 }
+
+// connectionConfig returns the configuration of connections to emmy server at endpoint,
+// given by flags of the client command in ctx.
+func connectionConfig(ctx *cli.Context, endpoint string) (*client.ConnectionConfig, error) {
+	// configure how clients will access emmy server via TLS.
+	var connCfg *client.ConnectionConfig
+	if ctx.Bool("insecure") {
+		connCfg = client.NewConnectionConfig(endpoint, "", nil, ctx.Int("t"))
+		connCfg.Insecure = true
+	} else if ctx.Bool("syscertpool") {
+		connCfg = client.NewConnectionConfig(endpoint, "", nil, ctx.Int("t"))
+	} else {
+		caCert, err := ioutil.ReadFile(ctx.String("cacert"))
+		if err != nil {
+			return nil, err
+		}
+		connCfg = client.NewConnectionConfig(endpoint, ctx.String("servername"), caCert,
+			ctx.Int("t"))
+	}
+	if certPath := ctx.String("clientcert"); certPath != "" {
+		var err error
+		if connCfg.ClientCertificate, err = ioutil.ReadFile(certPath); err != nil {
+			return nil, err
+		}
+		if connCfg.ClientKey, err = ioutil.ReadFile(ctx.String("clientkey")); err != nil {
+			return nil, err
+		}
+	}
+
+	connCfg.Compression = ctx.String("compression")
+	connCfg.ProxyURL = ctx.String("proxy")
+
+	netConf := config.LoadNetworkConfig()
+	connCfg.KeepaliveMillis = int(netConf.Timeouts.Keepalive / time.Millisecond)
+	connCfg.KeepaliveTimeoutMillis = int(netConf.Timeouts.KeepaliveTimeout / time.Millisecond)
+	connCfg.MaxReconnectDelayMillis = int(netConf.Timeouts.MaxReconnectDelay / time.Millisecond)
+	return connCfg, nil
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/did"
+	"github.com/xlab-si/emmy/didcomm"
+	"github.com/xlab-si/emmy/log"
+)
+
+// startDIDCommEndpoint serves the DIDComm endpoint over HTTPS with the given certificate
+// and key in a separate goroutine. The endpoint forwards messages to the gRPC server
+// listening on port. When mutualTLS is set, it authenticates to the gRPC server with the
+// same certificate.
+func startDIDCommEndpoint(port int, cfg *config.DIDCommConfig, certPath, keyPath string,
+	mutualTLS bool, logger log.Logger) error {
+	key, err := loadECDSAKey(cfg.KeyFile, "decrypting DIDComm messages", logger)
+	if err != nil {
+		return err
+	}
+	cert, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return err
+	}
+	serverName, err := certServerName(cert)
+	if err != nil {
+		return err
+	}
+	connCfg := client.NewConnectionConfig(fmt.Sprintf("localhost:%d", port), serverName, cert,
+		config.LoadTimeout())
+	if mutualTLS {
+		connCfg.ClientCertificate = cert
+		if connCfg.ClientKey, err = ioutil.ReadFile(keyPath); err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		// the gRPC server is started after this function returns, so we connect in the
+		// background
+		conn, err := client.NewConnection(connCfg)
+		if err != nil {
+			logger.Errorf("DIDComm endpoint cannot connect to the server: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		bridge, err := didcomm.NewBridge(key, conn, did.NewResolver())
+		if err != nil {
+			logger.Errorf("Cannot start DIDComm endpoint: %v", err)
+			return
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/", bridge)
+		mux.Handle("/ws", bridge.WebSocketHandler())
+
+		logger.Noticef("DIDComm endpoint of %s listening on %s", bridge.DID(), cfg.Address)
+		err = http.ListenAndServeTLS(cfg.Address, certPath, keyPath, mux)
+		logger.Errorf("DIDComm endpoint stopped: %v", err)
+	}()
+
+	return nil
+}
+
+// certServerName returns the name the server is identified with in the PEM encoded
+// certificate cert.
+func certServerName(cert []byte) (string, error) {
+	block, _ := pem.Decode(cert)
+	if block == nil {
+		return "", fmt.Errorf("no certificate found")
+	}
+	c, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", err
+	}
+	if len(c.DNSNames) > 0 {
+		return c.DNSNames[0], nil
+	}
+
+	return c.Subject.CommonName, nil
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/examples"
+	"github.com/xlab-si/emmy/examples/agecheck"
+	"github.com/xlab-si/emmy/examples/forum"
+	"github.com/xlab-si/emmy/examples/issuer"
+	"github.com/xlab-si/emmy/log"
+)
+
+var ExamplesCmd = cli.Command{
+	Name:  "examples",
+	Usage: "Runs example applications built on emmy: an issuer portal, an age-verification service and an anonymous forum",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "address",
+			Value: "localhost",
+			Usage: "`HOST` the applications listen on",
+		},
+		&cli.IntFlag{
+			Name:  "port",
+			Value: 8100,
+			Usage: "`PORT` of the issuer portal, the age-verification service and the forum listen on the next two",
+		},
+		&cli.StringFlag{
+			Name:  "params",
+			Value: cl.ParamsPresetTest,
+			Usage: "`PRESET` of CL parameters of the issuer's keys",
+		},
+		&cli.StringFlag{
+			Name:  "admin-token",
+			Usage: "`TOKEN` required by admin endpoints of the portal (random by default)",
+		},
+		&cli.DurationFlag{
+			Name:  "session-ttl",
+			Value: 10 * time.Minute,
+			Usage: "`DURATION` of validity of session keys",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		return exitOnError(runExamples(ctx.String("address"), ctx.Int("port"),
+			ctx.String("params"), ctx.String("admin-token"), ctx.Duration("session-ttl")))
+	},
+}
+
+// runExamples generates keys of the issuer and serves the example applications
+// over HTTP on consecutive ports, starting with port.
+func runExamples(address string, port int, preset, adminToken string,
+	sessionTTL time.Duration) error {
+	logger, err := log.NewStdoutLogger("examples", log.INFO, log.FORMAT_SHORT)
+	if err != nil {
+		return err
+	}
+	if adminToken == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+		adminToken = hex.EncodeToString(b)
+	}
+
+	params, err := cl.GetParamsPreset(preset)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Generating CL keys of the issuer (%s parameters)", preset)
+	org, err := cl.NewOrg(params, examples.AttrCount())
+	if err != nil {
+		return err
+	}
+	p, err := issuer.NewPortal(org, cl.NewMockRecordManager(), adminToken, sessionTTL)
+	if err != nil {
+		return err
+	}
+
+	portalAddress := fmt.Sprintf("%s:%d", address, port)
+	portal := examples.NewPortal("http://" + portalAddress)
+	apps := []struct {
+		name    string
+		address string
+		handler http.Handler
+	}{
+		{"issuer portal", portalAddress, p},
+		{"age-verification service", fmt.Sprintf("%s:%d", address, port+1),
+			agecheck.NewService(portal, "Welcome, you are over 18.")},
+		{"anonymous forum", fmt.Sprintf("%s:%d", address, port+2), forum.NewForum(portal)},
+	}
+
+	errs := make(chan error, len(apps))
+	for _, app := range apps {
+		go func(name, address string, handler http.Handler) {
+			logger.Noticef("%s listening on http://%s", name, address)
+			errs <- fmt.Errorf("%s stopped: %v", name, http.ListenAndServe(address, handler))
+		}(app.name, app.address, app.handler)
+	}
+	logger.Noticef("Admin token of the issuer portal: %s", adminToken)
+
+	return <-errs
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/keys"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+)
+
+// useJWTSessionKeys makes srv issue session keys in JWT form, and serves the keys
+// they can be verified with at the JWKS endpoint over HTTPS with the given
+// certificate and key in a separate goroutine.
+func useJWTSessionKeys(srv *server.Server, cfg *config.SessionJWTConfig, certPath,
+	keyPath string, logger log.Logger) error {
+	key, err := loadECDSAKey(cfg.KeyFile, "signing session keys", logger)
+	if err != nil {
+		return err
+	}
+
+	gen, err := server.NewJWTSessionKeyGen(cfg.Issuer, cfg.Audience, cfg.TTL, key)
+	if err != nil {
+		return err
+	}
+	if cfg.Claims {
+		gen.IncludeClaims()
+	}
+	srv.SessionManager = gen
+
+	mux := http.NewServeMux()
+	mux.Handle("/jwks", gen.JWKS())
+	go func() {
+		logger.Noticef("Keys for verifying session keys published at %s/jwks", cfg.JWKSAddress)
+		err := http.ListenAndServeTLS(cfg.JWKSAddress, certPath, keyPath, mux)
+		logger.Errorf("JWKS endpoint stopped: %v", err)
+	}()
+
+	return nil
+}
+
+// loadECDSAKey reads the key used for what (for example signing JWTs) from path,
+// in PEM or JWK form.
+// When path is empty, an ephemeral key is generated.
+func loadECDSAKey(path, what string, logger log.Logger) (*ecdsa.PrivateKey, error) {
+	var key *ecdsa.PrivateKey
+	var err error
+	if path == "" {
+		logger.Warningf("No key for %s configured, generating an ephemeral one", what)
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	} else {
+		var data []byte
+		if data, err = ioutil.ReadFile(path); err == nil {
+			key, err = keys.DecodeECDSAPrivateKey(data)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot obtain key for %s (%s)", what, err)
+	}
+
+	return key, nil
+}
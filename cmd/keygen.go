@@ -0,0 +1,520 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/bbs"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	"github.com/xlab-si/emmy/keys"
+	"github.com/xlab-si/emmy/pki"
+)
+
+var KeygenCmd = cli.Command{
+	Name:  "keygen",
+	Usage: "Generates keys of organizations and writes them in standard formats",
+	Flags: keygenFlags,
+	Subcommands: []cli.Command{
+		{
+			Name:  "ca",
+			Usage: "Generates a key pair of the CA in the pseudonym system (ECDSA P-256)",
+			Action: func(ctx *cli.Context) error {
+				return exitOnError(generateCAKeys(ctx.Parent().String("out"),
+					keys.Format(ctx.Parent().String("format"))))
+			},
+		},
+		{
+			Name:  "org",
+			Usage: "Generates a key pair of an organization in the pseudonym system",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "name",
+					Value: "org1",
+					Usage: "`NAME` of the organization, used as a prefix of file names",
+				},
+				&cli.BoolFlag{
+					Name:  "ec",
+					Usage: "Whether to generate keys for the pseudonym system in EC arithmetic",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				return exitOnError(generateOrgKeys(ctx.Parent().String("out"),
+					keys.Format(ctx.Parent().String("format")), ctx.String("name"),
+					ctx.Bool("ec")))
+			},
+		},
+		{
+			Name:  "cl",
+			Usage: "Generates a key pair of the organization issuing CL credentials",
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "workers, w",
+					Usage: "`N` goroutines searching for primes (all cores by default)",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				return exitOnError(generateCLKeys(ctx.Parent().String("out"), ctx.Int("workers")))
+			},
+		},
+		{
+			Name: "cl-rotate",
+			Usage: "Replaces the configured CL key pair with a new one, keeping the previous " +
+				"public key to verify proofs of credentials issued under it",
+			Flags: []cli.Flag{
+				&cli.DurationFlag{
+					Name:  "grace",
+					Value: 30 * 24 * time.Hour,
+					Usage: "`DURATION` for which proofs built with the previous key are accepted",
+				},
+				&cli.IntFlag{
+					Name:  "workers, w",
+					Usage: "`N` goroutines searching for primes (all cores by default)",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				return exitOnError(rotateCLKeys(ctx.Duration("grace"), ctx.Int("workers")))
+			},
+		},
+		{
+			Name:  "bbs",
+			Usage: "Generates a BBS+ key pair of the organization issuing BBS+ credentials",
+			Action: func(ctx *cli.Context) error {
+				return exitOnError(generateBBSKeys(ctx.Parent().String("out")))
+			},
+		},
+		{
+			Name: "cl-threshold",
+			Usage: "Splits the configured CL secret key into shares for threshold issuance, " +
+				"written to files clShare<i>.gob",
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "threshold, t",
+					Value: 2,
+					Usage: "`T` parties needed to issue a credential",
+				},
+				&cli.IntFlag{
+					Name:  "parties, n",
+					Value: 3,
+					Usage: "`N` parties holding shares",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				return exitOnError(splitCLKey(ctx.Parent().String("out"), ctx.Int("threshold"),
+					ctx.Int("parties")))
+			},
+		},
+		{
+			Name: "cert",
+			Usage: "Generates an X.509 certificate of an organization, embedding its public keys, " +
+				"signed by the given CA or self-signed",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "name",
+					Value: "org1",
+					Usage: "`NAME` of the organization, used as common name and a prefix of file names",
+				},
+				&cli.StringFlag{
+					Name:  "cl",
+					Usage: "`FILE` holding the CL public key",
+				},
+				&cli.StringFlag{
+					Name:  "pseudonymsys",
+					Usage: "`FILE` holding the public key in the pseudonym system",
+				},
+				&cli.StringFlag{
+					Name:  "pseudonymsys-ec",
+					Usage: "`FILE` holding the public key in the pseudonym system in EC arithmetic",
+				},
+				&cli.StringFlag{
+					Name:  "ca-cert",
+					Usage: "`FILE` holding the certificate of the issuing CA in PEM form",
+				},
+				&cli.StringFlag{
+					Name:  "ca-key",
+					Usage: "`FILE` holding the ECDSA private key of the issuing CA",
+				},
+				&cli.IntFlag{
+					Name:  "days",
+					Value: 365,
+					Usage: "`DAYS` the certificate is valid for",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				return exitOnError(generateOrgCert(ctx.Parent().String("out"), ctx))
+			},
+		},
+	},
+}
+
+// keygenFlags are flags common to all keygen subcommands.
+var keygenFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "out, o",
+		Value: ".",
+		Usage: "`DIR` where keys are written",
+	},
+	&cli.StringFlag{
+		Name:  "format, f",
+		Value: string(keys.FormatPEM),
+		Usage: "`FORMAT` of keys, pem or jwk (where supported)",
+	},
+}
+
+// generateCAKeys writes a new key pair of the CA to files ca.key and ca.pub in dir.
+func generateCAKeys(dir string, f keys.Format) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	sk, err := keys.EncodeECDSAPrivateKey(key, f)
+	if err != nil {
+		return err
+	}
+	pk, err := keys.EncodeECDSAPublicKey(&key.PublicKey, f)
+	if err != nil {
+		return err
+	}
+
+	return writeKeys(dir, "ca", sk, pk)
+}
+
+// generateOrgKeys writes a new key pair of organization name to files <name>.key
+// and <name>.pub in dir. Secret keys are always written in PEM form, and so are
+// public keys in modular arithmetic. Modular arithmetic keys are generated in the
+// group configured for the pseudonym system.
+func generateOrgKeys(dir string, f keys.Format, name string, useEC bool) error {
+	var sk *pseudsys.SecKey
+	var pk []byte
+	var err error
+
+	if useEC {
+		var ecPk *ecpseudsys.PubKey
+		sk, ecPk = ecpseudsys.GenerateKeyPair(ec.NewGroup(ec.P256))
+		pk, err = keys.EncodeECPseudonymsysPubKey(ecPk, f)
+	} else {
+		if f != keys.FormatPEM {
+			return fmt.Errorf("keys in modular arithmetic can only be written in PEM form")
+		}
+		group, e := config.LoadGroup("pseudonymsys")
+		if e != nil {
+			return e
+		}
+		var dlogPk *pseudsys.PubKey
+		sk, dlogPk = pseudsys.GenerateKeyPair(group)
+		pk, err = keys.EncodePseudonymsysPubKey(dlogPk)
+	}
+	if err != nil {
+		return err
+	}
+
+	skBytes, err := keys.EncodePseudonymsysSecKey(sk)
+	if err != nil {
+		return err
+	}
+
+	return writeKeys(dir, name, skBytes, pk)
+}
+
+// generateCLKeys writes a new key pair of the organization issuing CL credentials,
+// for the configured parameters and credential structure, to files cl.key and cl.pub
+// in dir. The public key is written in PEM form, the secret key is gob encoded.
+func generateCLKeys(dir string, workers int) error {
+	params, err := cl.LoadParams()
+	if err != nil {
+		return err
+	}
+	structure, err := config.LoadCredentialStructure()
+	if err != nil {
+		return err
+	}
+	_, attrCount, err := cl.ParseAttrs(structure)
+	if err != nil {
+		return err
+	}
+
+	keyPair, err := searchCLKeyPair(params, attrCount, workers)
+	if err != nil {
+		return err
+	}
+	_, _, err = writeCLKeys(dir, keyPair)
+	return err
+}
+
+// searchCLKeyPair generates a CL key pair for params and attrCount. Primes are searched
+// for with the given number of workers, reporting progress to standard error, until
+// they are found or the process is interrupted.
+func searchCLKeyPair(params *cl.Params, attrCount *cl.AttrCount, workers int) (*cl.KeyPair,
+	error) {
+	ctx, cancel := interruptibleContext()
+	defer cancel()
+
+	search := &common.PrimeSearch{
+		Workers: workers,
+		Progress: func(tested int64, found int) {
+			fmt.Fprintf(os.Stderr, "\rsearching for safe primes: %d/4 found, %d candidates tested",
+				found, tested)
+		},
+	}
+	keyPair, err := cl.GenerateKeyPairContext(ctx, params, attrCount, search)
+	fmt.Fprintln(os.Stderr)
+	return keyPair, err
+}
+
+// interruptibleContext returns a context that is done once the process is
+// interrupted (for example with Ctrl+C), or when the returned function is called.
+func interruptibleContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(interrupt)
+	}()
+
+	return ctx, cancel
+}
+
+// writeCLKeys writes keyPair to files cl.pub (in PEM form) and cl.key (gob encoded)
+// in dir, and returns their paths.
+func writeCLKeys(dir string, keyPair *cl.KeyPair) (string, string, error) {
+	pk, err := keyPair.Pub.MarshalPEM()
+	if err != nil {
+		return "", "", err
+	}
+	pubKeyPath := filepath.Join(dir, "cl.pub")
+	if err := ioutil.WriteFile(pubKeyPath, pk, 0644); err != nil {
+		return "", "", err
+	}
+
+	secKeyPath := filepath.Join(dir, "cl.key")
+	return pubKeyPath, secKeyPath, cl.WriteGob(secKeyPath, keyPair.Sec)
+}
+
+// rotateCLKeys replaces the configured CL key pair with a new one. The previous public
+// key is written in PEM form to cl-<ID>.pub next to the configured public key, and
+// the configuration that keeps accepting it for the grace period is printed.
+func rotateCLKeys(grace time.Duration, workers int) error {
+	params, err := cl.LoadParams()
+	if err != nil {
+		return err
+	}
+	structure, err := config.LoadCredentialStructure()
+	if err != nil {
+		return err
+	}
+	_, attrCount, err := cl.ParseAttrs(structure)
+	if err != nil {
+		return err
+	}
+
+	pubKeyPath, secKeyPath := config.LoadCLKeyPaths()
+	data, err := ioutil.ReadFile(pubKeyPath)
+	if err != nil {
+		return err
+	}
+	prev, err := cl.ReadPubKey(pubKeyPath)
+	if err != nil {
+		return err
+	}
+	prevPEM, err := prev.MarshalPEM()
+	if err != nil {
+		return err
+	}
+	prevPath := filepath.Join(filepath.Dir(pubKeyPath), "cl-"+prev.ID()+".pub")
+	if err := ioutil.WriteFile(prevPath, prevPEM, 0644); err != nil {
+		return err
+	}
+
+	keyPair, err := searchCLKeyPair(params, attrCount, workers)
+	if err != nil {
+		return err
+	}
+	// the public key is written in the form of the previous one
+	if block, _ := pem.Decode(data); block != nil {
+		pk, err := keyPair.Pub.MarshalPEM()
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(pubKeyPath, pk, 0644)
+	} else {
+		err = cl.WriteGob(pubKeyPath, keyPair.Pub)
+	}
+	if err != nil {
+		return err
+	}
+	if err := cl.WriteGob(secKeyPath, keyPair.Sec); err != nil {
+		return err
+	}
+
+	fmt.Printf("Rotated CL keys from %s to %s. Add the previous key to the configuration:\n\n",
+		prev.ID(), keyPair.Pub.ID())
+	fmt.Printf("cl:\n  previous_keys:\n    %s:\n      pub_key: %s\n      until: \"%s\"\n",
+		prev.ID(), prevPath, time.Now().Add(grace).UTC().Format(time.RFC3339))
+	return nil
+}
+
+// generateBBSKeys writes a new BBS+ key pair of the organization, for credentials
+// with the configured structure, to bbsPubKey.gob and bbsSecKey.gob in dir.
+func generateBBSKeys(dir string) error {
+	structure, err := config.LoadCredentialStructure()
+	if err != nil {
+		return err
+	}
+	_, attrCount, err := cl.ParseAttrs(structure)
+	if err != nil {
+		return err
+	}
+
+	keyPair, err := bbs.GenerateKeyPair(attrCount.Known + attrCount.Committed +
+		attrCount.Hidden)
+	if err != nil {
+		return err
+	}
+	if err := cl.WriteGob(filepath.Join(dir, "bbsPubKey.gob"), keyPair.Pub); err != nil {
+		return err
+	}
+
+	return cl.WriteGob(filepath.Join(dir, "bbsSecKey.gob"), keyPair.Sec)
+}
+
+// generateOrgCert writes a new certificate of the organization and its private key
+// to files <name>.crt and <name>.crt.key in dir. The certificate embeds public keys
+// read from files given in ctx and is signed by the CA given in ctx. When no CA
+// is given, the certificate is self-signed and can serve as a root of trust.
+func generateOrgCert(dir string, ctx *cli.Context) error {
+	orgKeys := new(pki.OrgKeys)
+	var err error
+	if path := ctx.String("cl"); path != "" {
+		if orgKeys.CL, err = cl.ReadPubKey(path); err != nil {
+			return err
+		}
+	}
+	if path := ctx.String("pseudonymsys"); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if orgKeys.Pseudonymsys, err = keys.DecodePseudonymsysPubKey(data); err != nil {
+			return err
+		}
+	}
+	if path := ctx.String("pseudonymsys-ec"); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if orgKeys.ECPseudonymsys, err = keys.DecodeECPseudonymsysPubKey(data); err != nil {
+			return err
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+	name := ctx.String("name")
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().AddDate(0, 0, ctx.Int("days")),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	parent, signer := template, key
+	if ctx.String("ca-cert") != "" || ctx.String("ca-key") != "" {
+		certData, err := ioutil.ReadFile(ctx.String("ca-cert"))
+		if err != nil {
+			return err
+		}
+		certs, err := pki.ParseCertificates(certData)
+		if err != nil {
+			return err
+		}
+		keyData, err := ioutil.ReadFile(ctx.String("ca-key"))
+		if err != nil {
+			return err
+		}
+		if signer, err = keys.DecodeECDSAPrivateKey(keyData); err != nil {
+			return err
+		}
+		parent = certs[0]
+	} else {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+
+	cert, err := pki.CreateOrgCertificate(template, parent, &key.PublicKey, signer, orgKeys)
+	if err != nil {
+		return err
+	}
+	sk, err := keys.EncodeECDSAPrivateKey(key, keys.FormatPEM)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".crt.key"), sk, 0600); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, name+".crt"), cert, 0644)
+}
+
+// writeKeys writes secret key sk and public key pk to files <name>.key and <name>.pub in dir.
+func writeKeys(dir, name string, sk, pk []byte) error {
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".key"), sk, 0600); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, name+".pub"), pk, 0644)
+}
+
+// exitOnError wraps err for urfave/cli to exit with a non-zero status.
+func exitOnError(err error) error {
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	"github.com/xlab-si/emmy/keys"
+	"github.com/xlab-si/emmy/server"
+)
+
+// Supported types of key stores.
+const (
+	keyStoreFile   = "file"
+	keyStorePKCS11 = "pkcs11"
+)
+
+// newKeyStore returns the key store described in cfg, or nil if secret keys are read
+// from the configuration.
+func newKeyStore(cfg *config.KeyStoreConfig) (crypto.KeyStore, error) {
+	switch cfg.Type {
+	case "":
+		return nil, nil
+	case keyStoreFile:
+		return crypto.NewFileKeyStore(cfg.Dir), nil
+	case keyStorePKCS11:
+		return newPKCS11KeyStore(&cfg.PKCS11)
+	}
+
+	return nil, fmt.Errorf("unsupported key store type: %s", cfg.Type)
+}
+
+// importKeys stores secret keys of issuers from the configuration in the configured
+// key store, under the labels emmy server looks them up with. The key of the CA is
+// stored in the key store of the CA, if one is configured.
+func importKeys() error {
+	ks, err := newKeyStore(config.LoadKeyStoreConfig())
+	if err != nil {
+		return err
+	}
+	caKs, err := newKeyStore(config.LoadCAKeyStoreConfig())
+	if err != nil {
+		return err
+	}
+	if ks == nil && caKs == nil {
+		return fmt.Errorf("no key store configured")
+	}
+	for _, s := range []crypto.KeyStore{ks, caKs} {
+		if c, ok := s.(io.Closer); ok {
+			defer c.Close()
+		}
+	}
+
+	if caKs == nil {
+		caKs = ks
+	}
+	if err := importCAKey(caKs); err != nil {
+		return err
+	}
+	if ks == nil {
+		return nil
+	}
+	return importIssuerKeys(ks)
+}
+
+// importCAKey stores the key of the pseudonym system CA from the configuration in ks.
+func importCAKey(ks crypto.KeyStore) error {
+	caPubKey := config.LoadPseudonymsysCAPubKey()
+	caKey := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: ec.GetCurve(ec.P256), X: caPubKey.H1, Y: caPubKey.H2},
+		D:         config.LoadPseudonymsysCASecret(),
+	}
+	if err := ks.StoreECDSAKey(server.KeyLabelPseudonymsysCA, caKey); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "imported %s\n", server.KeyLabelPseudonymsysCA)
+	return nil
+}
+
+// importIssuerKeys stores the CL secret key and secret keys of organizations in the
+// pseudonym system from the configuration in ks.
+func importIssuerKeys(ks crypto.KeyStore) error {
+	_, secKeyPath := config.LoadCLKeyPaths()
+	clSecKey, err := ioutil.ReadFile(secKeyPath)
+	if err != nil {
+		return fmt.Errorf("cannot read CL secret key: %v", err)
+	}
+	if err := ks.Store(server.KeyLabelCLSecKey, clSecKey); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "imported %s\n", server.KeyLabelCLSecKey)
+
+	for _, name := range config.LoadPseudonymsysOrgNames() {
+		orgKeys, err := server.LoadOrgKeysFromConfig(name)
+		if err != nil {
+			return err
+		}
+		secKeys := []struct {
+			dlogType string
+			key      *pseudsys.SecKey
+		}{{"dlog", orgKeys.SecKey}, {"ecdlog", orgKeys.SecKeyEC}}
+		for _, sk := range secKeys {
+			if sk.key == nil {
+				continue
+			}
+			data, err := keys.EncodePseudonymsysSecKey(sk.key)
+			if err != nil {
+				return err
+			}
+			label := server.PseudonymsysOrgKeyLabel(name, sk.dlogType)
+			if err := ks.Store(label, data); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "imported %s\n", label)
+		}
+	}
+
+	return nil
+}
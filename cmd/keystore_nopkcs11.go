@@ -0,0 +1,34 @@
+//go:build !pkcs11
+// +build !pkcs11
+
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto"
+)
+
+// newPKCS11KeyStore fails, as PKCS#11 key stores are only available in emmy built
+// with tag pkcs11.
+func newPKCS11KeyStore(cfg *config.PKCS11Config) (crypto.KeyStore, error) {
+	return nil, fmt.Errorf("emmy was built without PKCS#11 support (build with tag pkcs11)")
+}
@@ -0,0 +1,31 @@
+//go:build pkcs11
+// +build pkcs11
+
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto"
+)
+
+// newPKCS11KeyStore returns a key store backed by the PKCS#11 token described in cfg.
+func newPKCS11KeyStore(cfg *config.PKCS11Config) (crypto.KeyStore, error) {
+	return crypto.NewPKCS11KeyStore(cfg.Module, cfg.Token, cfg.PIN)
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"net/http"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/oidc"
+	"github.com/xlab-si/emmy/server"
+)
+
+// startOIDCBridge enables the OpenID Connect bridge on srv and serves its endpoints
+// over HTTPS with the given certificate and key in a separate goroutine.
+func startOIDCBridge(srv *server.Server, cfg *config.OIDCConfig, certPath, keyPath string,
+	logger log.Logger) error {
+	key, err := loadECDSAKey(cfg.KeyFile, "signing tokens", logger)
+	if err != nil {
+		return err
+	}
+
+	p, err := oidc.NewProvider(cfg.Issuer, key, cfg.TokenTTL)
+	if err != nil {
+		return err
+	}
+	srv.EnableOIDC(p)
+	if cfg.Audience != "" {
+		srv.IssueIDTokens(cfg.Audience)
+	}
+
+	go func() {
+		logger.Noticef("OpenID Connect bridge listening on %s", cfg.Address)
+		err := http.ListenAndServeTLS(cfg.Address, certPath, keyPath, p.Handler())
+		logger.Errorf("OpenID Connect bridge stopped: %v", err)
+	}()
+
+	return nil
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/crypto/params"
+)
+
+var ParamsCmd = cli.Command{
+	Name:  "params",
+	Usage: "Generates fresh group parameters",
+	Subcommands: []cli.Command{
+		{
+			Name: "generate",
+			Usage: "Generates a CL modulus, a Schnorr group or an RSA group for proofs of " +
+				"quadratic residuosity, writing its parameters as JSON",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "kind, k",
+					Value: params.KindSchnorr,
+					Usage: "`KIND` of parameters: cl (CL modulus), schnorr (Schnorr group) or " +
+						"qr (RSA group for quadratic residuosity)",
+				},
+				&cli.IntFlag{
+					Name:  "bits, b",
+					Value: 2048,
+					Usage: "`BITS` of the modulus",
+				},
+				&cli.IntFlag{
+					Name:  "order-bits",
+					Value: 256,
+					Usage: "`BITS` of the order of a Schnorr group, one less than --bits for " +
+						"a safe prime modulus",
+				},
+				&cli.IntFlag{
+					Name:  "workers, w",
+					Usage: "`N` goroutines searching for primes (all cores by default)",
+				},
+				&cli.StringFlag{
+					Name:  "out, o",
+					Usage: "`FILE` parameters are written to (standard output by default)",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				return exitOnError(generateParams(ctx.String("kind"), ctx.Int("bits"),
+					ctx.Int("order-bits"), ctx.Int("workers"), ctx.String("out")))
+			},
+		},
+	},
+}
+
+// generateParams generates parameters of the given kind and writes them to file
+// out, or to standard output if out is empty, as a JSON object of decimal
+// values. Schnorr groups are written in the form of <protocol>_group.json files
+// in key_folder, and RSA groups in the form of configuration section qr.
+func generateParams(kind string, bits, orderBits, workers int, out string) error {
+	ctx, cancel := interruptibleContext()
+	defer cancel()
+
+	g := &params.Generator{
+		Workers: workers,
+		Progress: func(p params.Progress) {
+			fmt.Fprintf(os.Stderr, "\rgenerating %s parameters: %d/%d primes found, "+
+				"%d candidates tested", p.Kind, p.Found, p.Needed, p.Tested)
+		},
+	}
+
+	values, err := generateParamValues(ctx, g, kind, bits, orderBits)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(values)
+}
+
+// generateParamValues generates parameters of the given kind with g and returns
+// decimal representations of their values.
+func generateParamValues(ctx context.Context, g *params.Generator, kind string, bits,
+	orderBits int) (map[string]string, error) {
+	switch kind {
+	case params.KindCL:
+		group, err := g.CLModulus(ctx, bits)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{
+			"n": group.N.String(),
+			"p": group.P.String(),
+			"q": group.Q.String(),
+		}, nil
+	case params.KindSchnorr:
+		group, err := g.SchnorrGroup(ctx, bits, orderBits)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{
+			"p": group.P.String(),
+			"g": group.G.String(),
+			"q": group.Q.String(),
+		}, nil
+	case params.KindQR:
+		group, err := g.QRGroup(ctx, bits)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{
+			"p": group.P.String(),
+			"q": group.Q.String(),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown kind of parameters %s", kind)
+	}
+}
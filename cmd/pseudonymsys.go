@@ -0,0 +1,450 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/client/wallet"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc"
+)
+
+// pseudonymsysFlags select the organization, the arithmetic and the wallet items that
+// pseudonym system subcommands use.
+var pseudonymsysFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "wallet",
+		Value: "emmy.wallet",
+		Usage: "`PATH` to the wallet file holding nyms and credentials",
+	},
+	&cli.StringFlag{
+		Name:   "passphrase",
+		EnvVar: "EMMY_WALLET_PASSPHRASE",
+		Usage:  "passphrase of the wallet",
+	},
+	&cli.StringFlag{
+		Name:  "org",
+		Usage: "`NAME` of the organization, the first configured one when empty",
+	},
+	&cli.StringFlag{
+		Name:  "nym",
+		Value: "nym",
+		Usage: "`NAME` of the nym in the wallet",
+	},
+	&cli.BoolFlag{
+		Name:  "ec",
+		Usage: "use the pseudonym system in EC arithmetic",
+	},
+}
+
+// issuerFlag names the organization that issued the credential of the nym given by
+// flag from.
+var issuerFlag = &cli.StringFlag{
+	Name:  "issuer",
+	Usage: "`NAME` of the organization that issued the credential, the first configured one when empty",
+}
+
+var pseudonymsysCmd = cli.Command{
+	Name:     "pseudonymsys",
+	Usage:    "Register nyms, obtain and transfer credentials of the pseudonym system",
+	Category: "Anonymous credentials",
+	Subcommands: []cli.Command{
+		{
+			Name:  "nym",
+			Usage: "Register a new nym with the organization and store it to the wallet",
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{
+					Name:  "regkey",
+					Usage: "registration `KEY` obtained from the organization",
+				},
+				&cli.StringFlag{
+					Name:  "ca",
+					Usage: "`URI` of a server running the CA, the server of the client command when empty",
+				},
+			}, pseudonymsysFlags...),
+			Action: func(ctx *cli.Context) error {
+				return runPseudonymsys(ctx, generateNym)
+			},
+		},
+		{
+			Name:  "issue",
+			Usage: "Obtain a credential for a nym in the wallet",
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{
+					Name: "from",
+					Usage: "`NAME` of the nym in the wallet holding the credential to derive " +
+						"the credential from",
+				},
+				issuerFlag,
+			}, pseudonymsysFlags...),
+			Action: func(ctx *cli.Context) error {
+				return runPseudonymsys(ctx, obtainPseudonymsysCred)
+			},
+		},
+		{
+			Name: "transfer",
+			Usage: "Transfer a credential of a nym in the wallet to the organization of " +
+				"another nym and obtain a session key",
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{
+					Name:  "from",
+					Usage: "`NAME` of the nym in the wallet holding the credential",
+				},
+				issuerFlag,
+			}, pseudonymsysFlags...),
+			Action: func(ctx *cli.Context) error {
+				return runPseudonymsys(ctx, transferPseudonymsysCred)
+			},
+		},
+	},
+}
+
+// runPseudonymsys runs f with the wallet given by flags and a connection to the
+// server given by flags of the client command.
+func runPseudonymsys(ctx *cli.Context,
+	f func(ctx *cli.Context, conn *grpc.ClientConn, w *wallet.Wallet) error) error {
+	w, err := openWallet(ctx)
+	if err != nil {
+		return err
+	}
+	return run(ctx.Parent().Parent(), ctx, func(ctx *cli.Context, conn *grpc.ClientConn) error {
+		return f(ctx, conn, w)
+	})
+}
+
+// pseudonymsysOrg returns the organization given by flag name, or the one the server
+// uses by default.
+func pseudonymsysOrg(ctx *cli.Context, name string) (string, error) {
+	if org := ctx.String(name); org != "" {
+		return org, nil
+	}
+	orgs := config.LoadPseudonymsysOrgNames()
+	if len(orgs) == 0 {
+		return "", fmt.Errorf("no organizations of the pseudonym system are configured")
+	}
+	return orgs[0], nil
+}
+
+// pseudonymsysSecret returns the master secret of the user from the wallet. If there
+// is none, it is generated with newSecret and stored. Secrets for Schnorr groups and
+// elliptic curves are kept apart, as they are taken from groups of different order.
+func pseudonymsysSecret(ctx *cli.Context, w *wallet.Wallet,
+	newSecret func() *big.Int) (*big.Int, error) {
+	name := "pseudonymsys"
+	if ctx.Bool("ec") {
+		name = "pseudonymsys_ec"
+	}
+	if secret, err := w.Secret(name); err == nil {
+		return secret, nil
+	}
+	secret := newSecret()
+	if err := w.PutSecret(name, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// generateNym obtains a certificate from the CA and registers a nym with the
+// organization, storing the nym to the wallet.
+func generateNym(ctx *cli.Context, conn *grpc.ClientConn, w *wallet.Wallet) error {
+	if ctx.String("regkey") == "" {
+		return fmt.Errorf("registration key is required")
+	}
+	org, err := pseudonymsysOrg(ctx, "org")
+	if err != nil {
+		return err
+	}
+	caConn, err := caConnection(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if caConn != conn {
+		defer caConn.Close()
+	}
+
+	if ctx.Bool("ec") {
+		curve, err := config.LoadCurve("pseudonymsys")
+		if err != nil {
+			return err
+		}
+		c, err := client.NewPseudonymsysClientEC(conn, curve)
+		if err != nil {
+			return err
+		}
+		c.UseOrg(org)
+		caClient, err := client.NewPseudonymsysCAClientEC(caConn, curve)
+		if err != nil {
+			return err
+		}
+		secret, err := pseudonymsysSecret(ctx, w, c.GenerateMasterKey)
+		if err != nil {
+			return err
+		}
+		caCert, err := caClient.GenerateCertificate(context.Background(), secret,
+			caClient.GenerateMasterNym(secret))
+		if err != nil {
+			return err
+		}
+		nym, err := c.GenerateNym(context.Background(), secret, caCert, ctx.String("regkey"))
+		if err != nil {
+			return err
+		}
+		if err := w.PutNymEC(ctx.String("nym"), nym, nil); err != nil {
+			return err
+		}
+	} else {
+		group, err := config.LoadGroup("pseudonymsys")
+		if err != nil {
+			return err
+		}
+		c, err := client.NewPseudonymsysClient(conn, group)
+		if err != nil {
+			return err
+		}
+		c.UseOrg(org)
+		caClient, err := client.NewPseudonymsysCAClient(caConn, group)
+		if err != nil {
+			return err
+		}
+		secret, err := pseudonymsysSecret(ctx, w, c.GenerateMasterKey)
+		if err != nil {
+			return err
+		}
+		caCert, err := caClient.GenerateCertificate(context.Background(), secret,
+			caClient.GenerateMasterNym(secret))
+		if err != nil {
+			return err
+		}
+		nym, err := c.GenerateNym(context.Background(), secret, caCert, ctx.String("regkey"))
+		if err != nil {
+			return err
+		}
+		if err := w.PutNym(ctx.String("nym"), nym, nil); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Nym registered with %s stored in the wallet as %s\n", org, ctx.String("nym"))
+	return nil
+}
+
+// caConnection returns a connection to the server running the CA given by flag ca,
+// with the settings of the client command, or conn if the flag is not set.
+func caConnection(ctx *cli.Context, conn *grpc.ClientConn) (*grpc.ClientConn, error) {
+	endpoint := ctx.String("ca")
+	if endpoint == "" {
+		return conn, nil
+	}
+	connCfg, err := connectionConfig(ctx.Parent().Parent(), endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return client.NewConnection(connCfg)
+}
+
+// obtainPseudonymsysCred obtains a credential for the nym in the wallet from the
+// organization the nym is registered with. With flag from, the credential is derived
+// from the credential of another nym in the wallet.
+func obtainPseudonymsysCred(ctx *cli.Context, conn *grpc.ClientConn, w *wallet.Wallet) error {
+	org, err := pseudonymsysOrg(ctx, "org")
+	if err != nil {
+		return err
+	}
+
+	if ctx.Bool("ec") {
+		if ctx.String("from") != "" {
+			return fmt.Errorf("credentials in EC arithmetic cannot be derived")
+		}
+		curve, err := config.LoadCurve("pseudonymsys")
+		if err != nil {
+			return err
+		}
+		secret, err := w.Secret("pseudonymsys_ec")
+		if err != nil {
+			return err
+		}
+		nym, _, err := w.NymEC(ctx.String("nym"))
+		if err != nil {
+			return err
+		}
+		c, err := client.NewPseudonymsysClientEC(conn, curve)
+		if err != nil {
+			return err
+		}
+		c.UseOrg(org)
+		cred, err := c.ObtainCredential(context.Background(), secret, nym,
+			config.LoadPseudonymsysOrgPubKeysEC(org))
+		if err != nil {
+			return err
+		}
+		if err := w.PutNymEC(ctx.String("nym"), nym, cred); err != nil {
+			return err
+		}
+	} else {
+		group, err := config.LoadGroup("pseudonymsys")
+		if err != nil {
+			return err
+		}
+		secret, err := w.Secret("pseudonymsys")
+		if err != nil {
+			return err
+		}
+		nym, _, err := w.Nym(ctx.String("nym"))
+		if err != nil {
+			return err
+		}
+		chain, err := derivedChain(ctx, w)
+		if err != nil {
+			return err
+		}
+		c, err := client.NewPseudonymsysClient(conn, group)
+		if err != nil {
+			return err
+		}
+		c.UseOrg(org)
+		cred, err := c.ObtainDerivedCredential(context.Background(), secret, nym,
+			config.LoadPseudonymsysOrgPubKeys(org), chain)
+		if err != nil {
+			return err
+		}
+		if err := w.PutNym(ctx.String("nym"), nym, cred); err != nil {
+			return err
+		}
+		if err := w.PutChain(ctx.String("nym"), chain); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Credential issued by %s stored with nym %s\n", org, ctx.String("nym"))
+	return nil
+}
+
+// derivedChain returns the chain of credentials that a credential derived from the
+// credential of the nym given by flag from is derived from, or nil without the flag.
+func derivedChain(ctx *cli.Context, w *wallet.Wallet) ([]*pseudsys.ChainLink, error) {
+	if ctx.String("from") == "" {
+		return nil, nil
+	}
+	issuer, err := pseudonymsysOrg(ctx, "issuer")
+	if err != nil {
+		return nil, err
+	}
+	_, cred, err := w.Nym(ctx.String("from"))
+	if err != nil {
+		return nil, err
+	}
+	if cred == nil {
+		return nil, fmt.Errorf("nym %s holds no credential", ctx.String("from"))
+	}
+	chain, err := w.Chain(ctx.String("from"))
+	if err != nil {
+		return nil, err
+	}
+	return append(chain, pseudsys.NewChainLink(issuer, cred)), nil
+}
+
+// transferPseudonymsysCred transfers the credential of the nym given by flag from to
+// the organization that the nym given by flag nym is registered with.
+func transferPseudonymsysCred(ctx *cli.Context, conn *grpc.ClientConn, w *wallet.Wallet) error {
+	if ctx.String("from") == "" {
+		return fmt.Errorf("nym holding the credential is required")
+	}
+	org, err := pseudonymsysOrg(ctx, "org")
+	if err != nil {
+		return err
+	}
+	issuer, err := pseudonymsysOrg(ctx, "issuer")
+	if err != nil {
+		return err
+	}
+
+	var sessionKey *pb.SessionKey
+	if ctx.Bool("ec") {
+		curve, err := config.LoadCurve("pseudonymsys")
+		if err != nil {
+			return err
+		}
+		secret, err := w.Secret("pseudonymsys_ec")
+		if err != nil {
+			return err
+		}
+		_, cred, err := w.NymEC(ctx.String("from"))
+		if err != nil {
+			return err
+		}
+		if cred == nil {
+			return fmt.Errorf("nym %s holds no credential", ctx.String("from"))
+		}
+		nym, _, err := w.NymEC(ctx.String("nym"))
+		if err != nil {
+			return err
+		}
+		c, err := client.NewPseudonymsysClientEC(conn, curve)
+		if err != nil {
+			return err
+		}
+		c.UseOrg(org)
+		if sessionKey, err = c.TransferCredential(context.Background(), issuer, secret, nym,
+			cred); err != nil {
+			return err
+		}
+	} else {
+		group, err := config.LoadGroup("pseudonymsys")
+		if err != nil {
+			return err
+		}
+		secret, err := w.Secret("pseudonymsys")
+		if err != nil {
+			return err
+		}
+		_, cred, err := w.Nym(ctx.String("from"))
+		if err != nil {
+			return err
+		}
+		if cred == nil {
+			return fmt.Errorf("nym %s holds no credential", ctx.String("from"))
+		}
+		chain, err := w.Chain(ctx.String("from"))
+		if err != nil {
+			return err
+		}
+		nym, _, err := w.Nym(ctx.String("nym"))
+		if err != nil {
+			return err
+		}
+		c, err := client.NewPseudonymsysClient(conn, group)
+		if err != nil {
+			return err
+		}
+		c.UseOrg(org)
+		if sessionKey, err = c.TransferCredentialChain(context.Background(), issuer, secret,
+			nym, cred, chain); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("Session key:", sessionKey.GetValue())
+	return nil
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/record"
+	"github.com/xlab-si/emmy/server"
+)
+
+var ReplayCmd = cli.Command{
+	Name:  "replay",
+	Usage: "Inspects and replays recorded protocol sessions",
+	Subcommands: []cli.Command{
+		{
+			Name:      "show",
+			Usage:     "Prints events of a recorded session",
+			ArgsUsage: "FILE",
+			Action: func(ctx *cli.Context) error {
+				if ctx.NArg() != 1 {
+					return exitOnError(fmt.Errorf("expected a file with a recorded session"))
+				}
+				return exitOnError(showSession(ctx.Args().First()))
+			},
+		},
+		{
+			Name: "server",
+			Usage: "Replays a session recorded by the server through server logic, " +
+				"using the configured keys",
+			ArgsUsage: "FILE",
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:  "step",
+					Usage: "Wait for enter after each step (q aborts the replay)",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				if ctx.NArg() != 1 {
+					return exitOnError(fmt.Errorf("expected a file with a recorded session"))
+				}
+				return exitOnError(replayServerSession(ctx.Args().First(), ctx.Bool("step")))
+			},
+		},
+	},
+}
+
+// showSession prints the session in file path.
+func showSession(path string) error {
+	s, err := record.Load(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s session of %s, started %v\n", s.Side, s.Method, s.Start)
+	for i := range s.Events {
+		fmt.Printf("#%d %v\n", i+1, &s.Events[i])
+	}
+
+	return nil
+}
+
+// replayServerSession replays the session in file path through handlers of a
+// server with in-memory storage, printing steps of the replay. When step is set,
+// it waits for the user after each step.
+func replayServerSession(path string, step bool) error {
+	s, err := record.Load(path)
+	if err != nil {
+		return err
+	}
+
+	logger, err := log.NewStdoutLogger("replay", log.ERROR, log.FORMAT_SHORT)
+	if err != nil {
+		return err
+	}
+	certPath, keyPath, err := writeReplayCert()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(filepath.Dir(certPath))
+
+	// registration keys the client sent are accepted
+	regMgr := server.NewMemRegistrationManager()
+	for _, e := range s.Events {
+		if e.Kind != record.KindRecv {
+			continue
+		}
+		if msg, err := e.Msg(); err == nil && msg.GetRegKey() != nil {
+			regMgr.AddRegistrationKey(msg.GetRegKey().RegKey)
+		}
+	}
+
+	srv, err := server.New(server.WithTLS(certPath, keyPath), server.WithRegistrationManager(regMgr),
+		server.WithLogger(logger))
+	if err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	err = srv.Replay(s, func(st *record.Step) error {
+		printStep(st)
+		if !step {
+			return nil
+		}
+		line, _ := in.ReadString('\n')
+		if strings.TrimSpace(line) == "q" {
+			return fmt.Errorf("replay aborted")
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("replay ended with error: %v", err)
+	}
+	fmt.Println("replay completed")
+
+	return nil
+}
+
+// printStep prints a step of a replay, along with the replayed message if it
+// differs from the recorded one.
+func printStep(st *record.Step) {
+	if st.Event == nil {
+		fmt.Printf("#%d unexpected send %s\n", st.Index, proto.CompactTextString(st.Message))
+		return
+	}
+
+	fmt.Printf("#%d %v\n", st.Index, st.Event)
+	if st.Diverged {
+		fmt.Printf("    diverged, replayed %s\n", proto.CompactTextString(st.Message))
+	}
+}
+
+// writeReplayCert writes a self-signed certificate and its key, which the server
+// needs to be instantiated, to a temporary directory and returns their paths.
+func writeReplayCert() (string, string, error) {
+	dir, err := ioutil.TempDir("", "emmy-replay")
+	if err != nil {
+		return "", "", err
+	}
+	cert, key, err := server.GenerateSelfSignedCert("localhost", "127.0.0.1")
+	if err != nil {
+		return "", "", err
+	}
+
+	certPath := filepath.Join(dir, "server.pem")
+	keyPath := filepath.Join(dir, "server.key")
+	if err := ioutil.WriteFile(certPath, cert, 0644); err != nil {
+		return "", "", err
+	}
+	if err := ioutil.WriteFile(keyPath, key, 0600); err != nil {
+		return "", "", err
+	}
+
+	return certPath, keyPath, nil
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"crypto/rand"
+	"io"
+	"os"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/rng"
+	"github.com/xlab-si/emmy/log"
+)
+
+// useRNG makes the source of randomness described in cfg, checked with health tests,
+// the source of randomness of the protocols.
+func useRNG(cfg *config.RNGConfig, logger log.Logger) error {
+	var src io.Reader = rand.Reader
+	if cfg.Source != config.RNGSourceSystem {
+		// the device stays open while the server runs
+		f, err := os.Open(cfg.Source)
+		if err != nil {
+			return err
+		}
+		src = f
+	}
+
+	opts := []rng.Option{rng.WithMinEntropy(cfg.MinEntropy)}
+	if cfg.PanicOnFailure {
+		opts = append(opts, rng.WithPanicOnFailure())
+	}
+	r, err := rng.New(src, opts...)
+	if err != nil {
+		return err
+	}
+	common.SetRandReader(r)
+	logger.Noticef("Drawing randomness from %s source, checked with health tests", cfg.Source)
+
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/sdk"
+)
+
+var SdkCmd = cli.Command{
+	Name:  "sdk",
+	Usage: "Generates client stubs and helpers for other languages (requires protoc and gRPC plugins)",
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "lang, l",
+			Usage: "`LANGUAGE` of generated code, python or typescript (repeatable, all by default)",
+		},
+		&cli.StringFlag{
+			Name:  "proto",
+			Value: "proto",
+			Usage: "`DIR` holding emmy's protocol buffer definitions",
+		},
+		&cli.StringFlag{
+			Name:  "out, o",
+			Value: "sdk-out",
+			Usage: "`DIR` where generated code is written",
+		},
+		&cli.StringFlag{
+			Name:  "api-version",
+			Value: sdk.APIVersion,
+			Usage: "`VERSION` of the API, naming the directory of generated code",
+		},
+		&cli.BoolFlag{
+			Name:  "helpers-only",
+			Usage: "Whether to only write helpers, without running protoc",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		g := sdk.NewGenerator(ctx.String("proto"), ctx.String("out"))
+		g.Version = ctx.String("api-version")
+
+		langs := sdk.Languages
+		if l := ctx.StringSlice("lang"); len(l) > 0 {
+			langs = nil
+			for _, lang := range l {
+				langs = append(langs, sdk.Language(lang))
+			}
+		}
+		for _, lang := range langs {
+			var err error
+			if ctx.Bool("helpers-only") {
+				err = g.WriteHelpers(lang)
+			} else {
+				err = g.Generate(lang)
+			}
+			if err != nil {
+				return exitOnError(err)
+			}
+		}
+		return nil
+	},
+}
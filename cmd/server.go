@@ -18,16 +18,32 @@
 package cmd
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
+	"time"
 
 	"fmt"
 
-	"github.com/go-redis/redis"
 	"github.com/urfave/cli"
 	"github.com/xlab-si/emmy/config"
 	"github.com/xlab-si/emmy/crypto/cl"
 	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/record"
 	"github.com/xlab-si/emmy/server"
+	"github.com/xlab-si/emmy/webauthn"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 var ServerCmd = cli.Command{
@@ -43,15 +59,27 @@ var ServerCmd = cli.Command{
 					ctx.Int("port"),
 					ctx.String("cert"),
 					ctx.String("key"),
+					ctx.String("clientca"),
 					ctx.String("db"),
 					ctx.String("logfile"),
-					ctx.String("loglevel"))
+					ctx.String("loglevel"),
+					ctx.Bool("dev"),
+					ctx.Bool("insecure"),
+					ctx.String("socket"),
+					ctx.StringSlice("services"))
 				if err != nil {
 					return cli.NewExitError(err, 1)
 				}
 				return nil
 			},
 		},
+		{
+			Name:  "import-keys",
+			Usage: "Imports secret keys of issuers from the configuration into the configured key store",
+			Action: func(ctx *cli.Context) error {
+				return exitOnError(importKeys())
+			},
+		},
 	},
 }
 
@@ -67,21 +95,29 @@ var serverFlags = []cli.Flag{
 	// (for establishing a secure channel with the server).
 	&cli.StringFlag{
 		Name:  "cert",
-		Value: filepath.Join(config.LoadTestdataDir(), "server.pem"),
+		Value: config.LoadNetworkConfig().TLS.CertFile,
 		Usage: "`PATH` to servers certificate file",
 	},
 	// keyFlag keeps the path to server's private key in PEM format
 	// (for establishing a secure channel with the server).
 	&cli.StringFlag{
 		Name:  "key",
-		Value: filepath.Join(config.LoadTestdataDir(), "server.key"),
+		Value: config.LoadNetworkConfig().TLS.KeyFile,
 		Usage: "`PATH` to server key file",
 	},
+	// clientCAFlag keeps the path to certificates of CAs in PEM format. When set, clients
+	// have to authenticate with a certificate issued by one of these CAs.
+	&cli.StringFlag{
+		Name:  "clientca",
+		Value: config.LoadNetworkConfig().TLS.ClientCAFile,
+		Usage: "`PATH` to CA certificates for verifying client certificates (enables mutual TLS)",
+	},
 	// dbEndpointFlag points to the endpoint at which emmy server will contact redis database.
+	// When set, it overrides addresses from the storage section of the configuration.
 	&cli.StringFlag{
 		Name:  "db",
-		Value: config.LoadRegistrationDBAddress(),
-		Usage: "`URI` of redis database to hold registration keys, in the form redisHost:redisPort",
+		Value: "",
+		Usage: "`URI` of redis database to hold registration keys, in the form redisHost:redisPort (overrides storage configuration)",
 	},
 	// logFilePathFlag indicates a path to the log file used by the server (optional).
 	&cli.StringFlag{
@@ -89,15 +125,45 @@ var serverFlags = []cli.Flag{
 		Value: "",
 		Usage: "`PATH` to the file where server logs will be written (created if it doesn't exist)",
 	},
+	// devFlag runs the server in development mode, with ephemeral keys and parameters,
+	// a self-signed certificate and in-memory storage.
+	&cli.BoolFlag{
+		Name:  "dev",
+		Usage: "Run in development mode (no certificates, keys or database required)",
+	},
+	// insecureFlag runs the server without TLS. The server then only listens on localhost,
+	// or on the UNIX socket given by socketFlag.
+	&cli.BoolFlag{
+		Name:  "insecure",
+		Usage: "Serve without TLS, on localhost or a UNIX socket only (development only)",
+	},
+	// socketFlag makes the server listen on a UNIX domain socket instead of a TCP port.
+	&cli.StringFlag{
+		Name:  "socket",
+		Value: "",
+		Usage: "`PATH` of a UNIX domain socket to listen on instead of the TCP port",
+	},
+	// servicesFlag limits the gRPC services the server provides, for example to run the
+	// pseudonym system CA on a host of its own.
+	&cli.StringSliceFlag{
+		Name:  "services",
+		Usage: "`NAME` of a gRPC service to provide (e.g. PseudonymSystemCA), may be repeated; all by default",
+	},
 	logLevelFlag,
 }
 
+// devRegKeysNum is the number of registration keys generated in development mode.
+const devRegKeysNum = 10
+
 // startEmmyServer configures and starts the gRPC server at the desired port
-func startEmmyServer(port int, certPath, keyPath, dbAddress, logFilePath, logLevel string) error {
+func startEmmyServer(port int, certPath, keyPath, clientCAPath, dbAddress, logFilePath,
+	logLevel string, dev, insecure bool, socket string, services []string) error {
 	var err error
 	var logger log.Logger
 
-	if logFilePath == "" {
+	if logConf := config.LoadLoggingConfig(); logConf.Enabled {
+		logger, err = newStructuredLogger(logConf)
+	} else if logFilePath == "" {
 		logger, err = log.NewStdoutLogger("server", logLevel, log.FORMAT_LONG)
 	} else {
 		logger, err = log.NewStdoutFileLogger("server", logFilePath, logLevel, log.FORMAT_LONG,
@@ -106,27 +172,383 @@ func startEmmyServer(port int, certPath, keyPath, dbAddress, logFilePath, logLev
 	if err != nil {
 		return err
 	}
+	// keys generated on first start draw from the source as well
+	if err := useRNG(config.LoadRNGConfig(), logger); err != nil {
+		return fmt.Errorf("source of randomness: %v", err)
+	}
+
+	var registrationManager server.RegistrationManager
+	var recordManager cl.ReceiverRecordManager
+	var devStorage, sessionStorage, nonceStorage *config.StorageConfig
+
+	if dev || config.LoadDevMode() {
+		logger.Warning("######## Running in development mode, do not use in production ########")
+		certPath, keyPath, registrationManager, err = setupDevMode(logger)
+		if err != nil {
+			return err
+		}
+		recordManager = cl.NewMockRecordManager()
+		devStorage = &config.StorageConfig{Driver: storageDriverMemory}
+		sessionStorage = devStorage
+		nonceStorage = devStorage
+	} else {
+		regStorage := config.LoadStorageConfig("registration")
+		recStorage := config.LoadStorageConfig("records")
+		devStorage = config.LoadStorageConfig("devices")
+		sessionStorage = config.LoadStorageConfig("sessions")
+		nonceStorage = config.LoadStorageConfig("nonces")
+		// --db flag takes precedence over the configuration
+		if dbAddress != "" {
+			regStorage.DSN = dbAddress
+			recStorage.DSN = dbAddress
+			devStorage.DSN = dbAddress
+			sessionStorage.DSN = dbAddress
+			nonceStorage.DSN = dbAddress
+		}
 
-	c := redis.NewClient(&redis.Options{
-		Addr: dbAddress,
-	})
-	err = c.Ping().Err()
+		registrationManager, err = newRegistrationManager(regStorage)
+		if err != nil {
+			return err
+		}
+		recordManager, err = newRecordManager(recStorage)
+		if err != nil {
+			return err
+		}
+	}
+
+	opts := []server.ServerOption{
+		server.WithTLS(certPath, keyPath), server.WithClientCAs(clientCAPath),
+		server.WithRegistrationManager(registrationManager), server.WithRecordManager(recordManager),
+		server.WithLogger(log.Component(logger, "server")),
+	}
+	if insecure {
+		logger.Warning("######## Serving without TLS, do not use in production ########")
+		opts = append(opts, server.WithInsecure())
+	}
+	if len(services) > 0 {
+		opts = append(opts, server.WithServices(services...))
+	}
+	srv, err := server.New(opts...)
 	if err != nil {
-		return fmt.Errorf("unable to connect to redis database (%s)", err)
+		return err
 	}
 
-	registrationManager := server.NewRedisClient(c)
+	ks, err := newKeyStore(config.LoadKeyStoreConfig())
+	if err != nil {
+		return err
+	}
+	if ks != nil {
+		srv.UseKeyStore(ks)
+	}
+	caKs, err := newKeyStore(config.LoadCAKeyStoreConfig())
 	if err != nil {
 		return err
 	}
+	if caKs != nil {
+		srv.UseCAKeyStore(caKs)
+	}
+
+	sessionConf := config.LoadSessionConfig()
+	switch sessionConf.Format {
+	case config.SessionKeyFormatRandom:
+		// server generates random session keys by default
+	case config.SessionKeyFormatJWT:
+		if err := useJWTSessionKeys(srv, &sessionConf.JWT, certPath, keyPath,
+			logger); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported session key format %s", sessionConf.Format)
+	}
+	if sessionConf.Store.Enabled {
+		store, err := newSessionStore(sessionStorage)
+		if err != nil {
+			return err
+		}
+		srv.UseSessionStore(store, sessionConf.Store.TTL)
+	}
+	srv.SetMaxSessionLifetime(sessionConf.MaxLifetime)
+	if nonceConf := config.LoadNonceConfig(); nonceConf.Shared {
+		store, err := newNonceStore(nonceStorage)
+		if err != nil {
+			return err
+		}
+		srv.UseNonceStore(store, nonceConf.TTL)
+	}
+	if config.LoadClusterConfig().Enabled {
+		if !sessionConf.Store.Enabled || !config.LoadNonceConfig().Shared {
+			return fmt.Errorf("servers of a cluster need to share sessions and nonces " +
+				"(session.store.enabled and nonces.shared)")
+		}
+		if err := srv.UseCluster(); err != nil {
+			return err
+		}
+	}
+
+	if auditConf := config.LoadAuditConfig(); auditConf.Enabled {
+		auditLog, err := newAuditLog(auditConf, config.LoadStorageConfig("audit"))
+		if err != nil {
+			return err
+		}
+		if err := srv.UseAuditLog(auditLog, []byte(auditConf.Key)); err != nil {
+			return err
+		}
+	}
+
+	if trConf := config.LoadTranscriptConfig(); trConf.Enabled {
+		if err := os.MkdirAll(trConf.Dir, 0700); err != nil {
+			return err
+		}
+		srv.ExportTranscripts(func(t *record.Transcript) error {
+			_, err := t.Save(trConf.Dir)
+			return err
+		})
+		logger.Noticef("Exporting transcripts of credential verifications to %s", trConf.Dir)
+	}
+
+	if waConf := config.LoadWebAuthnConfig(); waConf.Enabled {
+		store, err := newCredentialStore(devStorage)
+		if err != nil {
+			return err
+		}
+		rp := webauthn.NewRelyingParty(waConf.RPID, waConf.Origin)
+		rp.RequireUserVerification = waConf.UserVerification
+		if err := srv.EnableDeviceBinding(rp, store, waConf.Attribute); err != nil {
+			return err
+		}
+	}
+
+	if thConf := config.LoadCLThresholdConfig(); thConf.Share != "" || len(thConf.Parties) > 0 {
+		if err := useCLThreshold(srv, thConf, log.Component(logger, "cl")); err != nil {
+			return err
+		}
+	}
+
+	if revConf := config.LoadRevocationConfig(); revConf.Enabled {
+		if err := srv.EnableRevocation(revConf.Accumulator); err != nil {
+			return err
+		}
+	}
+
+	for _, tc := range config.LoadTenants() {
+		conf, err := config.NewFromFile(tc.File)
+		if err != nil {
+			return fmt.Errorf("tenant %s: %v", tc.ID, err)
+		}
+		t, err := server.NewTenant(tc.ID, conf)
+		if err != nil {
+			return err
+		}
+		srv.AddTenant(t)
+	}
 
-	recordManager := cl.NewRedisClient(c)
+	srv.SetBatchIssuanceConcurrency(config.LoadBatchIssuanceConfig().Concurrency)
+	srv.SetMaxCredValidity(config.LoadCredExpirationConfig().MaxValidity)
 
-	srv, err := server.NewServer(certPath, keyPath, registrationManager, recordManager, logger)
+	if ppConf := config.LoadPublicParamsConfig(); ppConf.Enabled {
+		key, err := loadECDSAKey(ppConf.KeyFile, "signing public parameters", logger)
+		if err != nil {
+			return err
+		}
+		if err := srv.SignPublicParameters(key); err != nil {
+			return err
+		}
+	}
+
+	if oidcConf := config.LoadOIDCConfig(); oidcConf.Enabled {
+		if err := startOIDCBridge(srv, oidcConf, certPath, keyPath,
+			log.Component(logger, "oidc")); err != nil {
+			return err
+		}
+	}
+
+	if gwConf := config.LoadGatewayConfig(); gwConf.Enabled {
+		gw := server.NewGateway(srv, gwConf.AdminToken)
+		go func() {
+			logger.Noticef("HTTP/JSON gateway listening on %s", gwConf.Address)
+			err := http.ListenAndServeTLS(gwConf.Address, certPath, keyPath, gw)
+			logger.Errorf("HTTP/JSON gateway stopped: %v", err)
+		}()
+	}
+
+	if webConf := config.LoadGrpcWebConfig(); webConf.Enabled {
+		h := server.NewGrpcWebHandler(srv, webConf.AllowedOrigins)
+		go func() {
+			logger.Noticef("gRPC-Web endpoint listening on %s", webConf.Address)
+			err := http.ListenAndServeTLS(webConf.Address, certPath, keyPath, h)
+			logger.Errorf("gRPC-Web endpoint stopped: %v", err)
+		}()
+	}
+
+	if dcConf := config.LoadDIDCommConfig(); dcConf.Enabled {
+		if err := startDIDCommEndpoint(port, dcConf, certPath, keyPath, clientCAPath != "",
+			log.Component(logger, "didcomm")); err != nil {
+			return err
+		}
+	}
+
+	if trConf := config.LoadTracingConfig(); trConf.Enabled {
+		tp, err := newTracerProvider(trConf)
+		if err != nil {
+			return err
+		}
+		defer tp.Shutdown(context.Background())
+		otel.SetTracerProvider(tp)
+		logger.Notice("Tracing protocol executions with OpenTelemetry")
+	}
+
+	go reloadConfigOnSignal(srv, logger)
+	stopped := shutdownOnSignal(srv, config.LoadNetworkConfig().Timeouts.Shutdown, logger)
+
+	if socket != "" {
+		err = srv.StartUnix(socket)
+	} else {
+		err = srv.Start(port)
+	}
 	if err != nil {
 		return err
 	}
+	// the server stops serving as soon as the shutdown begins
+	<-stopped
+	return nil
+}
+
+// newTracerProvider returns an OpenTelemetry tracer provider that writes spans of
+// emmy server as JSON to the file configured in conf, or to standard output.
+func newTracerProvider(conf *config.TracingConfig) (*sdktrace.TracerProvider, error) {
+	out := os.Stdout
+	if conf.File != "" {
+		f, err := os.OpenFile(conf.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open file for traces: %v", err)
+		}
+		out = f
+	}
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(out))
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(
+			sdktrace.TraceIDRatioBased(conf.SampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "emmy-server"))),
+	), nil
+}
+
+// newStructuredLogger returns a structured logger of emmy server with sinks and
+// sampling configured in conf.
+func newStructuredLogger(conf *config.LoggingConfig) (*log.StructuredLogger, error) {
+	var sinks []io.Writer
+	if conf.Stderr {
+		sinks = append(sinks, os.Stderr)
+	}
+	if conf.File != "" {
+		f, err := log.NewRotatingFile(conf.File, int64(conf.FileMaxSize)<<20, conf.FileMaxBackups)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open log file: %v", err)
+		}
+		sinks = append(sinks, f)
+	}
+	if conf.Syslog {
+		w, err := log.NewSyslogSink(conf.SyslogNetwork, conf.SyslogAddress, conf.SyslogTag)
+		if err != nil {
+			return nil, fmt.Errorf("cannot connect to syslog: %v", err)
+		}
+		sinks = append(sinks, w)
+	}
+	if len(sinks) == 0 {
+		return nil, fmt.Errorf("no log sinks enabled")
+	}
+
+	opts := &log.Options{
+		Format: conf.Format,
+		Level:  conf.Level,
+		Levels: conf.Levels,
+		Sinks:  sinks,
+	}
+	if conf.SampleInitial > 0 {
+		opts.Sampling = &log.Sampling{
+			Tick:       time.Second,
+			Initial:    conf.SampleInitial,
+			Thereafter: conf.SampleThereafter,
+		}
+	}
+	return log.NewStructuredLogger(opts)
+}
+
+// shutdownOnSignal shuts srv down gracefully when the process receives SIGINT or
+// SIGTERM, waiting at most timeout for protocol sessions in progress to finish. The
+// returned channel is closed once the shutdown completes.
+func shutdownOnSignal(srv *server.Server, timeout time.Duration,
+	logger log.Logger) <-chan struct{} {
+	stopped := make(chan struct{})
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		s := <-sig
+		signal.Stop(sig)
+		logger.Noticef("Received %v, shutting down", s)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			logger.Warningf("Server did not shut down cleanly: %v", err)
+		}
+		close(stopped)
+	}()
+	return stopped
+}
+
+// reloadConfigOnSignal reloads the configuration of srv, including keys of
+// organizations of the pseudonym system, credential schemas and acceptable
+// credentials, whenever the process receives SIGHUP.
+func reloadConfigOnSignal(srv *server.Server, logger log.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		if err := srv.ReloadConfig(); err != nil {
+			logger.Warningf("Cannot reload configuration, keeping the previous one: %v", err)
+		}
+	}
+}
+
+// setupDevMode prepares a temporary directory for ephemeral keys and parameters,
+// writes a self-signed certificate for localhost into it, and creates an in-memory
+// registration manager holding a few generated registration keys.
+// It returns paths to the certificate and its key, and the registration manager.
+func setupDevMode(logger log.Logger) (string, string, *server.MemRegistrationManager, error) {
+	dir, err := ioutil.TempDir("", "emmy-dev")
+	if err != nil {
+		return "", "", nil, err
+	}
+	config.UseEphemeralKeys(dir)
+	logger.Noticef("Ephemeral keys and parameters will be stored in %s", dir)
+
+	cert, key, err := server.GenerateSelfSignedCert("localhost", "127.0.0.1")
+	if err != nil {
+		return "", "", nil, fmt.Errorf("cannot generate self-signed certificate (%s)", err)
+	}
+	certPath := filepath.Join(dir, "server.pem")
+	keyPath := filepath.Join(dir, "server.key")
+	if err := ioutil.WriteFile(certPath, cert, 0644); err != nil {
+		return "", "", nil, err
+	}
+	if err := ioutil.WriteFile(keyPath, key, 0600); err != nil {
+		return "", "", nil, err
+	}
+	logger.Noticef("Self-signed certificate written to %s", certPath)
+
+	regMgr := server.NewMemRegistrationManager()
+	for i := 0; i < devRegKeysNum; i++ {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return "", "", nil, err
+		}
+		regKey := hex.EncodeToString(b)
+		regMgr.AddRegistrationKey(regKey)
+		logger.Noticef("Registration key: %s", regKey)
+	}
 
-	srv.EnableTracing()
-	return srv.Start(port)
+	return certPath, keyPath, regMgr, nil
 }
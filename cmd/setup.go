@@ -0,0 +1,288 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/keys"
+	"gopkg.in/yaml.v2"
+)
+
+var SetupCmd = cli.Command{
+	Name: "setup",
+	Usage: "Generates keys of emmy server and writes them, along with settings referencing " +
+		"them, to a configuration file that emmy server can use in place of config/defaults.yml " +
+		"or as a configuration file of a tenant",
+	Flags: setupFlags,
+	Subcommands: []cli.Command{
+		{
+			Name:  "cl",
+			Usage: "Generates a key pair of the organization issuing CL credentials",
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "modulus, m",
+					Value: 2048,
+					Usage: "`BITS` of the RSA modulus (256 for testing, 2048 or 3072)",
+				},
+				&cli.IntFlag{
+					Name:  "known",
+					Usage: "`N` attributes known to the issuer (the configured credential structure by default)",
+				},
+				&cli.IntFlag{
+					Name:  "committed",
+					Usage: "`N` attributes the issuer only knows commitments of",
+				},
+				&cli.IntFlag{
+					Name:  "workers, w",
+					Usage: "`N` goroutines searching for primes (all cores by default)",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				return exitOnError(setupCL(ctx.Parent().String("out"),
+					ctx.Parent().String("config"), ctx))
+			},
+		},
+		{
+			Name:  "org",
+			Usage: "Generates secrets and public keys of an organization in the pseudonym system",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "name",
+					Value: "org1",
+					Usage: "`NAME` of the organization",
+				},
+				&cli.BoolFlag{
+					Name:  "ec",
+					Usage: "Whether to generate keys for the pseudonym system in EC arithmetic",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				return exitOnError(setupOrg(ctx.Parent().String("out"),
+					ctx.Parent().String("config"), ctx.String("name"), ctx.Bool("ec")))
+			},
+		},
+		{
+			Name:  "ca",
+			Usage: "Generates a key pair of the CA in the pseudonym system (ECDSA P-256)",
+			Action: func(ctx *cli.Context) error {
+				return exitOnError(setupCA(ctx.Parent().String("out"),
+					ctx.Parent().String("config")))
+			},
+		},
+	},
+}
+
+// setupFlags are flags common to all setup subcommands.
+var setupFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "out, o",
+		Value: ".",
+		Usage: "`DIR` where keys and the configuration file are written",
+	},
+	&cli.StringFlag{
+		Name:  "config, c",
+		Value: "emmy.yml",
+		Usage: "`FILE` in DIR holding the configuration, updated when it exists",
+	},
+}
+
+// setupCL generates a CL key pair with the modulus size and attribute counts given in
+// ctx, writes it to dir and references it from the configuration file, along with the
+// credential structure. Unless attribute counts are given, keys are generated for the
+// configured credential structure, otherwise the structure consists of string
+// attributes Attr<i>, known attributes first.
+func setupCL(dir, file string, ctx *cli.Context) error {
+	params, preset, err := clParamsForModulus(ctx.Int("modulus"))
+	if err != nil {
+		return err
+	}
+
+	var attrCount *cl.AttrCount
+	attrs := config.LoadAttributeSpecs()
+	if ctx.IsSet("known") || ctx.IsSet("committed") {
+		attrCount = cl.NewAttrCount(ctx.Int("known"), ctx.Int("committed"), 0)
+		if attrCount.Known < 0 || attrCount.Committed < 0 {
+			return fmt.Errorf("number of attributes must not be negative")
+		}
+		attrs = make(map[string]string)
+		for i := 0; i < attrCount.Known+attrCount.Committed; i++ {
+			attrs[strconv.Itoa(i)] = fmt.Sprintf("Attr%d, string, %t", i, i < attrCount.Known)
+		}
+	} else {
+		structure, err := config.LoadCredentialStructure()
+		if err != nil {
+			return err
+		}
+		if _, attrCount, err = cl.ParseAttrs(structure); err != nil {
+			return err
+		}
+	}
+
+	keyPair, err := searchCLKeyPair(params, attrCount, ctx.Int("workers"))
+	if err != nil {
+		return err
+	}
+	pubKeyPath, secKeyPath, err := writeCLKeys(dir, keyPair)
+	if err != nil {
+		return err
+	}
+
+	return updateSetupConfig(dir, file, func(conf map[string]interface{}) error {
+		setKey(conf, "cl.params", preset)
+		setKey(conf, "attributes", attrs)
+		if err := setPath(conf, "cl.pub_key", pubKeyPath); err != nil {
+			return err
+		}
+		return setPath(conf, "cl.sec_key", secKeyPath)
+	})
+}
+
+// clParamsForModulus returns CL parameters of the preset with RSA modulus of the
+// given bit length, along with the name of the preset.
+func clParamsForModulus(bits int) (*cl.Params, string, error) {
+	for _, name := range []string{cl.ParamsPresetTest, cl.ParamsPreset2048,
+		cl.ParamsPreset3072} {
+		params, err := cl.GetParamsPreset(name)
+		if err != nil {
+			return nil, "", err
+		}
+		if params.NLength == bits {
+			return params, name, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("unsupported modulus size: %d bits", bits)
+}
+
+// setupOrg generates keys of organization name in the pseudonym system, writes them to
+// dir and references them from the configuration file. Keys in modular arithmetic are
+// generated in the configured group of the pseudonym system, which is also written to
+// the configuration.
+func setupOrg(dir, file, name string, useEC bool) error {
+	dlogType, fileName := "dlog", name
+	if useEC {
+		dlogType, fileName = "ecdlog", name+"-ec"
+	}
+	if err := generateOrgKeys(dir, keys.FormatPEM, fileName, useEC); err != nil {
+		return err
+	}
+
+	return updateSetupConfig(dir, file, func(conf map[string]interface{}) error {
+		if !useEC {
+			group, err := config.LoadGroup("pseudonymsys")
+			if err != nil {
+				return err
+			}
+			setKey(conf, "pseudonymsys.group", map[string]string{
+				"p": group.P.String(),
+				"g": group.G.String(),
+				"q": group.Q.String(),
+			})
+		}
+		prefix := fmt.Sprintf("pseudonymsys.%s.%s", name, dlogType)
+		if err := setPath(conf, prefix+".key", filepath.Join(dir, fileName+".key")); err != nil {
+			return err
+		}
+		return setPath(conf, prefix+".pub_key", filepath.Join(dir, fileName+".pub"))
+	})
+}
+
+// setupCA generates a key pair of the CA in the pseudonym system, writes it to dir
+// and references it from the configuration file.
+func setupCA(dir, file string) error {
+	if err := generateCAKeys(dir, keys.FormatPEM); err != nil {
+		return err
+	}
+
+	return updateSetupConfig(dir, file, func(conf map[string]interface{}) error {
+		return setPath(conf, "pseudonymsys.ca.key", filepath.Join(dir, "ca.key"))
+	})
+}
+
+// updateSetupConfig reads the configuration file in dir (if it exists), applies update
+// to it and writes it back in YAML.
+func updateSetupConfig(dir, file string, update func(conf map[string]interface{}) error) error {
+	path := filepath.Join(dir, file)
+	conf := make(map[string]interface{})
+	data, err := ioutil.ReadFile(path)
+	if err == nil {
+		if err := yaml.Unmarshal(data, &conf); err != nil {
+			return fmt.Errorf("cannot read configuration file: %s", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := update(conf); err != nil {
+		return err
+	}
+	if data, err = yaml.Marshal(conf); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// setKey sets the value of key (nested keys are separated by dots) in conf, replacing
+// any value it had.
+func setKey(conf map[string]interface{}, key string, value interface{}) {
+	names := strings.Split(key, ".")
+	if len(names) == 1 {
+		conf[key] = value
+		return
+	}
+	sub, ok := conf[names[0]].(map[interface{}]interface{})
+	if !ok {
+		sub = make(map[interface{}]interface{})
+		conf[names[0]] = sub
+	}
+	setNested(sub, names[1:], value)
+}
+
+// setNested sets the value at the path of names in m, creating maps along the path.
+func setNested(m map[interface{}]interface{}, names []string, value interface{}) {
+	if len(names) == 1 {
+		m[names[0]] = value
+		return
+	}
+	sub, ok := m[names[0]].(map[interface{}]interface{})
+	if !ok {
+		sub = make(map[interface{}]interface{})
+		m[names[0]] = sub
+	}
+	setNested(sub, names[1:], value)
+}
+
+// setPath sets key of conf to the absolute form of path, so that the configuration can
+// be used from any working directory.
+func setPath(conf map[string]interface{}, key, path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	setKey(conf, key, abs)
+	return nil
+}
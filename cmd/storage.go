@@ -0,0 +1,195 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"crypto/tls"
+	"database/sql"
+	"fmt"
+	"net"
+
+	"github.com/go-redis/redis"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/server"
+	"github.com/xlab-si/emmy/webauthn"
+)
+
+// Supported storage drivers.
+const (
+	storageDriverRedis  = "redis"
+	storageDriverMemory = "memory"
+	storageDriverSQL    = "sql"
+)
+
+// newRegistrationManager returns a RegistrationManager backed by the storage
+// described in cfg.
+func newRegistrationManager(cfg *config.StorageConfig) (server.RegistrationManager, error) {
+	switch cfg.Driver {
+	case storageDriverRedis:
+		c, err := newRedisClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return server.NewRedisClient(c), nil
+	case storageDriverMemory:
+		return server.NewMemRegistrationManager(), nil
+	case storageDriverSQL:
+		db, err := newSQLDB(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return server.NewSQLRegistrationManager(db, cfg.SQLDriver)
+	}
+
+	return nil, fmt.Errorf("unsupported storage driver for registration keys: %s", cfg.Driver)
+}
+
+// newRecordManager returns a cl.ReceiverRecordManager backed by the storage
+// described in cfg.
+func newRecordManager(cfg *config.StorageConfig) (cl.ReceiverRecordManager, error) {
+	switch cfg.Driver {
+	case storageDriverRedis:
+		c, err := newRedisClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return cl.NewRedisClient(c), nil
+	case storageDriverMemory:
+		return cl.NewMockRecordManager(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported storage driver for CL records: %s", cfg.Driver)
+}
+
+// newCredentialStore returns a webauthn.CredentialStore backed by the storage
+// described in cfg.
+func newCredentialStore(cfg *config.StorageConfig) (webauthn.CredentialStore, error) {
+	switch cfg.Driver {
+	case storageDriverRedis:
+		c, err := newRedisClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return webauthn.NewRedisCredentialStore(c), nil
+	case storageDriverMemory:
+		return webauthn.NewMemCredentialStore(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported storage driver for WebAuthn devices: %s", cfg.Driver)
+}
+
+// newSessionStore returns a server.SessionStore backed by the storage described
+// in cfg.
+func newSessionStore(cfg *config.StorageConfig) (server.SessionStore, error) {
+	switch cfg.Driver {
+	case storageDriverRedis:
+		c, err := newRedisClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return server.NewRedisSessionStore(c), nil
+	case storageDriverMemory:
+		return server.NewMemSessionStore(), nil
+	case storageDriverSQL:
+		db, err := newSQLDB(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return server.NewSQLSessionStore(db, cfg.SQLDriver)
+	}
+
+	return nil, fmt.Errorf("unsupported storage driver for sessions: %s", cfg.Driver)
+}
+
+// newNonceStore returns a server.NonceStore backed by the storage described in
+// cfg.
+func newNonceStore(cfg *config.StorageConfig) (server.NonceStore, error) {
+	switch cfg.Driver {
+	case storageDriverRedis:
+		c, err := newRedisClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return server.NewRedisNonceStore(c), nil
+	case storageDriverMemory:
+		return server.NewMemNonceStore(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported storage driver for nonces: %s", cfg.Driver)
+}
+
+// newAuditLog returns a server.AuditLog appending to the backend of conf, with
+// backend "sql" in the database described in cfg.
+func newAuditLog(conf *config.AuditConfig, cfg *config.StorageConfig) (server.AuditLog, error) {
+	switch conf.Backend {
+	case config.AuditBackendFile:
+		return server.NewFileAuditLog(conf.File)
+	case config.AuditBackendSQL:
+		db, err := newSQLDB(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return server.NewSQLAuditLog(db, cfg.SQLDriver)
+	}
+
+	return nil, fmt.Errorf("unsupported audit log backend: %s", conf.Backend)
+}
+
+// newSQLDB opens the SQL database described in cfg and makes sure that it is
+// reachable.
+func newSQLDB(cfg *config.StorageConfig) (*sql.DB, error) {
+	db, err := sql.Open(cfg.SQLDriver, cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s database (%s)", cfg.SQLDriver, err)
+	}
+	if cfg.PoolSize > 0 {
+		db.SetMaxOpenConns(cfg.PoolSize)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to connect to %s database (%s)", cfg.SQLDriver, err)
+	}
+
+	return db, nil
+}
+
+// newRedisClient connects to the redis database described in cfg and makes sure
+// that it is reachable.
+func newRedisClient(cfg *config.StorageConfig) (*redis.Client, error) {
+	opts := &redis.Options{
+		Addr:     cfg.DSN,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+		PoolSize: cfg.PoolSize,
+	}
+	if cfg.TLS {
+		host, _, err := net.SplitHostPort(cfg.DSN)
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = &tls.Config{ServerName: host}
+	}
+
+	c := redis.NewClient(opts)
+	if err := c.Ping().Err(); err != nil {
+		return nil, fmt.Errorf("unable to connect to redis database at %s (%s)", cfg.DSN, err)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/xlab-si/emmy/client"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/server"
+	"google.golang.org/grpc"
+)
+
+// splitCLKey runs the key generation ceremony of threshold issuance: it splits the
+// configured CL secret key into shares for parties of issuance, t of which are needed
+// to issue a credential, and writes them to files clShare<i>.gob in dir.
+func splitCLKey(dir string, t, n int) error {
+	params, err := cl.LoadParams()
+	if err != nil {
+		return err
+	}
+	pubKeyPath, secKeyPath := config.LoadCLKeyPaths()
+	org, err := cl.LoadOrg(params, pubKeyPath, secKeyPath)
+	if err != nil {
+		return err
+	}
+
+	shares, err := cl.SplitSecKey(params, org.Keys.Sec, t, n)
+	if err != nil {
+		return err
+	}
+	for _, share := range shares {
+		path := filepath.Join(dir, fmt.Sprintf("clShare%d.gob", share.Index))
+		if err := cl.WriteGob(path, share); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// useCLThreshold makes srv a party of threshold issuance of CL credentials when it
+// is configured with a key share, and the coordinator of issuance when it is
+// configured with parties, which it connects to.
+func useCLThreshold(srv *server.Server, cfg *config.CLThresholdConfig, logger log.Logger) error {
+	params, err := cl.LoadParams()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Share != "" {
+		share := new(cl.ThresholdKeyShare)
+		if err := cl.ReadGob(cfg.Share, share); err != nil {
+			return fmt.Errorf("cannot read key share: %v", err)
+		}
+		party, err := cl.NewThresholdParty(params, share)
+		if err != nil {
+			return err
+		}
+		srv.UseThresholdShare(party)
+	}
+
+	if len(cfg.Parties) == 0 {
+		return nil
+	}
+	pubKeyPath, _ := config.LoadCLKeyPaths()
+	pubKey, err := cl.ReadPubKey(pubKeyPath)
+	if err != nil {
+		return err
+	}
+
+	var caCert []byte
+	if cfg.CACert != "" {
+		if caCert, err = ioutil.ReadFile(cfg.CACert); err != nil {
+			return err
+		}
+	}
+	connCfg := client.NewConnectionConfig("", config.LoadNetworkConfig().TLS.ServerName,
+		caCert, config.LoadTimeout())
+	if cfg.ClientCert != "" {
+		if connCfg.ClientCertificate, err = ioutil.ReadFile(cfg.ClientCert); err != nil {
+			return err
+		}
+		if connCfg.ClientKey, err = ioutil.ReadFile(cfg.ClientKey); err != nil {
+			return err
+		}
+	}
+
+	// parties that cannot be reached are left out, as long as threshold of them remain
+	conns := make(map[int]*grpc.ClientConn, len(cfg.Parties))
+	for i, endpoint := range cfg.Parties {
+		connCfg.Endpoint = endpoint
+		conn, err := client.NewConnection(connCfg)
+		if err != nil {
+			logger.Warningf("Party %d of threshold issuance not available: %v", i+1, err)
+			continue
+		}
+		conns[i+1] = conn
+	}
+
+	coordinator, err := server.NewThresholdCoordinator(pubKey, cfg.Threshold, conns)
+	if err != nil {
+		return err
+	}
+	return srv.UseThresholdCoordinator(coordinator)
+}
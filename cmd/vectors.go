@@ -0,0 +1,139 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/vectors"
+)
+
+var VectorsCmd = cli.Command{
+	Name:  "vectors",
+	Usage: "Generates deterministic test vectors of proof systems and verifies emmy against them",
+	Subcommands: []cli.Command{
+		{
+			Name:  "generate",
+			Usage: "Runs proof systems with randomness derived from a seed, writing JSON vectors",
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "seed",
+					Value: "emmy test vectors",
+					Usage: "`SEED` randomness of runs is derived from",
+				},
+				&cli.StringSliceFlag{
+					Name:  "scheme, s",
+					Usage: "`SCHEME` to generate vectors for (repeatable, all by default)",
+				},
+				&cli.StringFlag{
+					Name:  "out, o",
+					Usage: "`FILE` vectors are written to (standard output by default)",
+				},
+			},
+			Action: func(ctx *cli.Context) error {
+				return exitOnError(generateVectors([]byte(ctx.String("seed")),
+					ctx.StringSlice("scheme"), ctx.String("out")))
+			},
+		},
+		{
+			Name:      "verify",
+			Usage:     "Verifies that emmy reproduces stored vectors",
+			ArgsUsage: "FILE",
+			Action: func(ctx *cli.Context) error {
+				if ctx.NArg() != 1 {
+					return exitOnError(fmt.Errorf("expected a file with vectors"))
+				}
+				return exitOnError(verifyVectors(ctx.Args().First()))
+			},
+		},
+		{
+			Name:  "schemes",
+			Usage: "Lists schemes vectors can be generated for",
+			Action: func(ctx *cli.Context) error {
+				for _, s := range vectors.Schemes() {
+					fmt.Println(s)
+				}
+				return nil
+			},
+		},
+	},
+}
+
+// generateVectors generates vectors of schemes (all if empty) with seed and
+// writes them to file out, or to standard output if out is empty.
+func generateVectors(seed []byte, schemes []string, out string) error {
+	var s *vectors.Suite
+	var err error
+	if len(schemes) == 0 {
+		if s, err = vectors.GenerateAll(seed); err != nil {
+			return err
+		}
+	} else {
+		s = &vectors.Suite{Version: vectors.FormatVersion}
+		for _, scheme := range schemes {
+			v, err := vectors.Generate(scheme, seed)
+			if err != nil {
+				return err
+			}
+			s.Vectors = append(s.Vectors, v)
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return s.WriteJSON(w)
+}
+
+// verifyVectors verifies all vectors in file path, returning an error if any
+// of them is not reproduced.
+func verifyVectors(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	s, err := vectors.ReadSuite(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, v := range s.Vectors {
+		if err := vectors.Verify(v); err != nil {
+			fmt.Println("FAIL", err)
+			failed++
+			continue
+		}
+		fmt.Println("ok  ", v.Scheme)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d vectors failed", failed, len(s.Vectors))
+	}
+
+	return nil
+}
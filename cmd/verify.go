@@ -0,0 +1,76 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli"
+	"github.com/xlab-si/emmy/record"
+)
+
+var VerifyCmd = cli.Command{
+	Name:  "verify",
+	Usage: "Verifies artifacts exported by emmy server offline",
+	Subcommands: []cli.Command{
+		{
+			Name: "transcript",
+			Usage: "Verifies proofs in transcripts of credential verifications exported by " +
+				"the server",
+			ArgsUsage: "FILE...",
+			Action: func(ctx *cli.Context) error {
+				if ctx.NArg() == 0 {
+					return exitOnError(fmt.Errorf("expected files with transcripts"))
+				}
+				return exitOnError(verifyTranscripts(ctx.Args()))
+			},
+		},
+	},
+}
+
+// verifyTranscripts verifies proofs in transcripts in files paths, printing the
+// outcome of each verification at the server and offline. It fails if a proof the
+// server accepted does not verify.
+func verifyTranscripts(paths []string) error {
+	failed := 0
+	for _, path := range paths {
+		t, err := record.LoadTranscript(path)
+		if err != nil {
+			return err
+		}
+
+		outcome := "accepted"
+		if !t.Verified {
+			outcome = fmt.Sprintf("rejected (%s)", t.Reason)
+		}
+		fmt.Printf("%s: %s at %v, %s by the server\n", path, t.Protocol, t.Time, outcome)
+		if err := record.VerifyTranscript(t); err != nil {
+			fmt.Printf("  proof does not verify: %v\n", err)
+			if t.Verified {
+				failed++
+			}
+			continue
+		}
+		fmt.Println("  proof verifies")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d accepted proofs do not verify", failed)
+	}
+	return nil
+}
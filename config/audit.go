@@ -0,0 +1,50 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+const (
+	AuditBackendFile = "file"
+	AuditBackendSQL  = "sql"
+)
+
+type AuditConfig struct {
+	Enabled bool
+	Backend string // AuditBackendFile or AuditBackendSQL (in the store of storage.audit)
+	File    string // file that events are appended to with AuditBackendFile
+	Key     string // key of pseudonymous client identifiers, random when empty
+}
+
+func (c *Config) LoadAuditConfig() *AuditConfig {
+	return &AuditConfig{
+		Enabled: c.viper().GetBool("audit.enabled"),
+		Backend: c.viper().GetString("audit.backend"),
+		File:    c.viper().GetString("audit.file"),
+		Key:     c.viper().GetString("audit.key"),
+	}
+}
+
+func setAuditDefaults(v *viper.Viper) {
+	v.SetDefault("audit.enabled", false)
+	v.SetDefault("audit.backend", AuditBackendFile)
+	v.SetDefault("audit.file", "/var/log/emmy/audit.log")
+	v.SetDefault("audit.key", "")
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// CLPreviousKey is a CL public key that the organization issued credentials under
+// before its keys were rotated. Proofs of such credentials are accepted until Until.
+type CLPreviousKey struct {
+	ID     string    // ID of the key (see cl.PubKey.ID)
+	PubKey string    // path to the file holding the key
+	Until  time.Time // end of the grace period, zero when it does not end
+}
+
+// LoadCLPreviousKeys returns previous CL public keys of the organization from section
+// cl.previous_keys of the configuration, which maps IDs of keys to the path of the
+// file holding the key (pub_key) and the end of its grace period in RFC 3339 form
+// (until). Keys are returned ordered by ID.
+func (c *Config) LoadCLPreviousKeys() ([]*CLPreviousKey, error) {
+	entries := c.viper().GetStringMap("cl.previous_keys")
+	ids := make([]string, 0, len(entries))
+	for id := range entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	keys := make([]*CLPreviousKey, len(ids))
+	for i, id := range ids {
+		prefix := "cl.previous_keys." + id + "."
+		k := &CLPreviousKey{
+			ID:     id,
+			PubKey: c.viper().GetString(prefix + "pub_key"),
+		}
+		if k.PubKey == "" {
+			return nil, fmt.Errorf("previous CL key %s has no pub_key", id)
+		}
+		// YAML timestamps may already be parsed
+		switch until := c.viper().Get(prefix + "until").(type) {
+		case time.Time:
+			k.Until = until
+		case string:
+			t, err := time.Parse(time.RFC3339, until)
+			if err != nil {
+				return nil, fmt.Errorf("end of grace period of CL key %s: %v", id, err)
+			}
+			k.Until = t
+		}
+		keys[i] = k
+	}
+
+	return keys, nil
+}
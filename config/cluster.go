@@ -0,0 +1,40 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// ClusterConfig holds settings of clusters of emmy servers behind a load balancer.
+type ClusterConfig struct {
+	Enabled bool // protocol runs are kept in the session store between their rounds
+}
+
+// LoadClusterConfig returns settings of clustering from section cluster of the
+// configuration.
+func (c *Config) LoadClusterConfig() *ClusterConfig {
+	return &ClusterConfig{
+		Enabled: c.viper().GetBool("cluster.enabled"),
+	}
+}
+
+// setClusterDefaults sets default values of clustering settings.
+func setClusterDefaults(v *viper.Viper) {
+	v.SetDefault("cluster.enabled", false)
+}
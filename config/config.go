@@ -18,10 +18,15 @@
 package config
 
 import (
+	"crypto/ecdsa"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"os"
 	"path/filepath"
@@ -32,36 +37,148 @@ import (
 	"github.com/xlab-si/emmy/crypto/pseudsys"
 	"github.com/xlab-si/emmy/crypto/qr"
 	"github.com/xlab-si/emmy/crypto/schnorr"
+	"github.com/xlab-si/emmy/keys"
 )
 
+// Config holds emmy configuration. Package level Load* functions read the default
+// configuration, which is populated from the configuration file at initialization.
+// Other instances, created with New, are independent of it and of any files.
+type Config struct {
+	v         *viper.Viper
+	overrides map[string]interface{} // values set with Set, kept when reloaded
+	lock      sync.RWMutex
+}
+
+// global is the default configuration, backed by viper's global instance.
+var global = &Config{v: viper.GetViper()}
+
+// New returns a new configuration that holds only default values. It can be
+// populated programmatically with Set and does not read any files.
+func New() *Config {
+	v := viper.New()
+	setDefaults(v)
+	return &Config{v: v}
+}
+
+// Default returns the default configuration.
+func Default() *Config {
+	return global
+}
+
+// Set sets the value for the key (nested keys are separated by dots),
+// overriding the default value and the value from the configuration file.
+func (c *Config) Set(key string, value interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.v.Set(key, value)
+	if c.overrides == nil {
+		c.overrides = make(map[string]interface{})
+	}
+	c.overrides[key] = value
+}
+
+// viper returns the viper instance currently holding values of c.
+func (c *Config) viper() *viper.Viper {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.v
+}
+
+// Reloaded returns a new configuration holding default values, overridden by those
+// read again from the file that c was read from, and by values set on c with Set.
+// It fails when c was not read from a file. Use Swap to make c hold the values.
+func (c *Config) Reloaded() (*Config, error) {
+	c.lock.RLock()
+	file := c.v.ConfigFileUsed()
+	overrides := make(map[string]interface{}, len(c.overrides))
+	for k, val := range c.overrides {
+		overrides[k] = val
+	}
+	c.lock.RUnlock()
+	if file == "" {
+		return nil, fmt.Errorf("configuration was not read from a file")
+	}
+
+	v := viper.New()
+	setDefaults(v)
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("cannot read configuration file: %s", err)
+	}
+	for k, val := range overrides {
+		v.Set(k, val)
+	}
+	return &Config{v: v, overrides: overrides}, nil
+}
+
+// Swap makes c hold the values of other at once, so that the values are replaced
+// atomically for all users of c, and returns a configuration holding the previous
+// values of c, which can be swapped back.
+func (c *Config) Swap(other *Config) *Config {
+	other.lock.RLock()
+	v, overrides := other.v, other.overrides
+	other.lock.RUnlock()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	prev := &Config{v: c.v, overrides: c.overrides}
+	c.v, c.overrides = v, overrides
+	return prev
+}
+
 // init loads the default config file
 func init() {
 	// set reasonable defaults
-	setDefaults()
+	setDefaults(global.v)
 
 	// override defaults with configuration read from configuration file
-	viper.AddConfigPath("$GOPATH/src/github.com/xlab-si/emmy/config")
-	err := loadConfig("defaults", "yml")
+	global.v.AddConfigPath("$GOPATH/src/github.com/xlab-si/emmy/config")
+	err := loadConfig(global.v, "defaults", "yml")
 	if err != nil {
 		fmt.Println(err)
 	}
 }
 
 // setDefaults sets default values for various parts of emmy library.
-func setDefaults() {
-	viper.SetDefault("ip", "localhost")
-	viper.SetDefault("port", 7007)
-	viper.SetDefault("timeout", 5000)
-	viper.SetDefault("key_folder", "/tmp")
-
-	viper.SetDefault("schnorr_group",
-		map[string]string{
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("ip", "localhost")
+	v.SetDefault("port", 7007)
+	v.SetDefault("timeout", 5000)
+	v.SetDefault("key_folder", "/tmp")
+	v.SetDefault("cl.params", "test")
+	v.SetDefault("cl.commitments", "qr")
+	v.SetDefault("storage.driver", "redis")
+	v.SetDefault("storage.dsn", "localhost:6379")
+	setNetworkDefaults(v)
+	setOIDCDefaults(v)
+	setSessionDefaults(v)
+	setNonceDefaults(v)
+	setClusterDefaults(v)
+	setRNGDefaults(v)
+	setGatewayDefaults(v)
+	setDIDCommDefaults(v)
+	setPublicParamsDefaults(v)
+	setGrpcWebDefaults(v)
+	setWebAuthnDefaults(v)
+	setPKIDefaults(v)
+	setRecordingDefaults(v)
+	setAuditDefaults(v)
+	setTranscriptDefaults(v)
+	setFaultsDefaults(v)
+	setRevocationDefaults(v)
+	setBatchIssuanceDefaults(v)
+	setSchemaDefaults(v)
+	setMetricsDefaults(v)
+	setTracingDefaults(v)
+	setLoggingDefaults(v)
+	setKeyStoreDefaults(v)
+
+	pseudonymSysConfig := map[string]interface{}{
+		"group": map[string]string{
 			"p": "16714772973240639959372252262788596420406994288943442724185217359247384753656472309049760952976644136858333233015922583099687128195321947212684779063190875332970679291085543110146729439665070418750765330192961290161474133279960593149307037455272278582955789954847238104228800942225108143276152223829168166008095539967222363070565697796008563529948374781419181195126018918350805639881625937503224895840081959848677868603567824611344898153185576740445411565094067875133968946677861528581074542082733743513314354002186235230287355796577107626422168586230066573268163712626444511811717579062108697723640288393001520781671",
 			"g": "13435884250597730820988673213378477726569723275417649800394889054421903151074346851880546685189913185057745735207225301201852559405644051816872014272331570072588339952516472247887067226166870605704408444976351128304008060633104261817510492686675023829741899954314711345836179919335915048014505501663400445038922206852759960184725596503593479528001139942112019453197903890937374833630960726290426188275709258277826157649744326468681842975049888851018287222105796254410594654201885455104992968766625052811929321868035475972753772676518635683328238658266898993508045858598874318887564488464648635977972724303652243855656",
 			"q": "98208916160055856584884864196345443685461747768186057136819930381973920107591",
-		})
-
-	pseudonymSysConfig := map[string]interface{}{
+		},
 		"user1": "10501840420714326611674814933629820564884994433464121609699657686381725481917946560951300989428757857663890749444810669658158959171443678666294156633031855300155147813954782039163197859065107569638424682758546743970421679581497316473363590677852615245790857416631205041294470157319811083478928657427332727532272060990285330797695681228920548209293494826378319240408357619741465896984159808329187249915415180748872721286083954030337580803742552856969769146625693488160927221403705265205532491725454404938155197720048433342625635727130205282673205600167729513490481034307616261949529737060447713783467988717455504863857",
 		"org1": map[string]string{
 			"h1": "11253748020267515701977135421640400742511414782332660443524776235731592618314865082641495270379529602832564697632543178140373575666207325449816651443326295587329200580969897900340682863137274403743213121482058992744156278265298975875832815615008349379091580640663544863825594755871212120449589876097254391036951735135790415340694042060640287135597503154554767593490141558733646631257590898412097094878970047567251318564175378758713497120310233239160479122314980866111775954564694480706227862890375180173977176588970220883117212300621045744043530072238840577201003052170999723878986905807102656657527667244456412473985",
@@ -75,16 +192,16 @@ func setDefaults() {
 			"y1": "37526396936964061204061100652712760357856013823850948443144488667237183893571",
 		},
 	}
-	viper.SetDefault("pseudonymsys", pseudonymSysConfig)
+	v.SetDefault("pseudonymsys", pseudonymSysConfig)
 }
 
 // loadConfig reads in the config file with configName being the name of the file (without suffix)
 // and configType being "yml" or "json".
-func loadConfig(configName string, configType string) error {
-	viper.SetConfigName(configName)
-	viper.SetConfigType(configType)
+func loadConfig(v *viper.Viper, configName string, configType string) error {
+	v.SetConfigName(configName)
+	v.SetConfigType(configType)
 
-	err := viper.ReadInConfig()
+	err := v.ReadInConfig()
 	if err != nil {
 		return fmt.Errorf("cannot read configuration file: %s\n", err)
 	}
@@ -93,48 +210,156 @@ func loadConfig(configName string, configType string) error {
 }
 
 // LoadServerPort returns the port where emmy server will be listening.
-func LoadServerPort() int {
-	return viper.GetInt("port")
+func (c *Config) LoadServerPort() int {
+	return c.viper().GetInt("port")
 }
 
 // LoadServerEndpoint returns the endpoint of the emmy server where clients will be contacting it.
-func LoadServerEndpoint() string {
-	ip := viper.GetString("ip")
-	port := LoadServerPort()
+func (c *Config) LoadServerEndpoint() string {
+	ip := c.viper().GetString("ip")
+	port := c.LoadServerPort()
 	return fmt.Sprintf("%v:%v", ip, port)
 }
 
 // LoadTimeout returns the specified number of seconds that clients wait before giving up
 // on connection to emmy server
-func LoadTimeout() int {
-	return viper.GetInt("timeout")
+func (c *Config) LoadTimeout() int {
+	return c.viper().GetInt("timeout")
+}
+
+// LoadDevMode returns whether emmy server should run in development mode, where
+// keys, parameters, certificates and storage are set up automatically.
+func (c *Config) LoadDevMode() bool {
+	return c.viper().GetBool("dev")
+}
+
+// UseEphemeralKeys makes emmy keep generated keys and group parameters in dir
+// instead of the configured locations. As dir is expected to be empty, a fresh
+// set of keys and parameters is generated. This is used in development mode.
+func (c *Config) UseEphemeralKeys(dir string) {
+	c.Set("key_folder", dir)
+	c.Set("cl.pub_key", filepath.Join(dir, "clPubKey.gob"))
+	c.Set("cl.sec_key", filepath.Join(dir, "clSecKey.gob"))
+	c.Set("bbs.pub_key", filepath.Join(dir, "bbsPubKey.gob"))
+	c.Set("bbs.sec_key", filepath.Join(dir, "bbsSecKey.gob"))
 }
 
-func LoadKeyDirFromConfig() string {
-	key_path := viper.GetString("key_folder")
+func (c *Config) LoadKeyDirFromConfig() string {
+	key_path := c.viper().GetString("key_folder")
 	return key_path
 }
 
-func LoadTestdataDir() string {
+// LoadTestdataDir returns testdata_dir, relative to the emmy sources in GOPATH unless
+// it is an absolute path.
+func (c *Config) LoadTestdataDir() string {
+	dir := c.viper().GetString("testdata_dir")
+	if filepath.IsAbs(dir) {
+		return dir
+	}
 	prefix := filepath.Join(os.Getenv("GOPATH"), "src", "github.com", "xlab-si", "emmy")
-	return filepath.Join(prefix, viper.GetString("testdata_dir"))
+	return filepath.Join(prefix, dir)
 }
 
-func LoadTestKeyDirFromConfig() string {
-	key_path := viper.GetString("key_folder")
+func (c *Config) LoadTestKeyDirFromConfig() string {
+	key_path := c.viper().GetString("key_folder")
 	return key_path
 }
 
-func LoadSchnorrGroup() *schnorr.Group {
-	groupMap := viper.GetStringMapString("schnorr_group")
-	p, _ := new(big.Int).SetString(groupMap["p"], 10)
-	g, _ := new(big.Int).SetString(groupMap["g"], 10)
-	q, _ := new(big.Int).SetString(groupMap["q"], 10)
-	return schnorr.NewGroupFromParams(p, g, q)
+// LoadGroup returns the Schnorr group used by the given protocol (for example
+// "schnorr" or "pseudonymsys"). Group parameters are read from the protocol's
+// own configuration section (<protocol>.group). When they are absent, the
+// group that was generated for the protocol on a previous start is read from
+// key_folder. If there is none, a fresh group is generated and persisted to
+// key_folder, so that subsequent starts keep using the same parameters.
+// A named group preset (<protocol>.profile, see schnorr.GetGroupPreset) takes
+// precedence over all of these. Parameters that are read are validated.
+func (c *Config) LoadGroup(protocol string) (*schnorr.Group, error) {
+	if name := c.viper().GetString(fmt.Sprintf("%s.profile", protocol)); name != "" {
+		return schnorr.GetGroupPreset(name)
+	}
+
+	key := fmt.Sprintf("%s.group", protocol)
+	if c.viper().IsSet(key) {
+		return groupFromMap(c.viper().GetStringMapString(key))
+	}
+
+	groupPath := filepath.Join(c.LoadKeyDirFromConfig(), fmt.Sprintf("%s_group.json", protocol))
+	data, err := ioutil.ReadFile(groupPath)
+	if err == nil {
+		var groupMap map[string]string
+		if err := json.Unmarshal(data, &groupMap); err != nil {
+			return nil, fmt.Errorf("cannot parse group parameters in %s: %s", groupPath, err)
+		}
+		return groupFromMap(groupMap)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	group, err := schnorr.NewGroup(256)
+	if err != nil {
+		return nil, fmt.Errorf("error when generating group for %s: %s", protocol, err)
+	}
+	data, err = json.Marshal(map[string]string{
+		"p": group.P.String(),
+		"g": group.G.String(),
+		"q": group.Q.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(groupPath, data, 0600); err != nil {
+		return nil, fmt.Errorf("cannot persist group parameters to %s: %s", groupPath, err)
+	}
+
+	return group, nil
+}
+
+// groupFromMap constructs a Schnorr group from decimal string representations
+// of its parameters p, g and q.
+func groupFromMap(groupMap map[string]string) (*schnorr.Group, error) {
+	p, okP := new(big.Int).SetString(groupMap["p"], 10)
+	g, okG := new(big.Int).SetString(groupMap["g"], 10)
+	q, okQ := new(big.Int).SetString(groupMap["q"], 10)
+	if !okP || !okG || !okQ {
+		return nil, fmt.Errorf("group parameters p, g and q must be decimal integers")
+	}
+	group := schnorr.NewGroupFromParams(p, g, q)
+	if err := validateGroup(group); err != nil {
+		return nil, fmt.Errorf("invalid group parameters: %s", err)
+	}
+	return group, nil
 }
 
-func LoadQRRSA() *qr.RSA {
-	x := viper.GetStringMapString("qr")
+// validGroups holds string representations of groups that were already validated,
+// so that groups loaded on each request are not validated over and over again.
+var validGroups sync.Map
+
+// validateGroup validates group unless it was validated before.
+func validateGroup(group *schnorr.Group) error {
+	key := fmt.Sprintf("%s,%s,%s", group.P, group.G, group.Q)
+	if _, ok := validGroups.Load(key); ok {
+		return nil
+	}
+	if err := group.Validate(); err != nil {
+		return err
+	}
+	validGroups.Store(key, true)
+	return nil
+}
+
+// LoadCurve returns the elliptic curve used by the given protocol in EC arithmetic,
+// which is set by the name of a curve preset (<protocol>.ec_profile, see
+// ec.GetCurvePreset). P-256 is used when it is not set.
+func (c *Config) LoadCurve(protocol string) (ec.Curve, error) {
+	name := c.viper().GetString(fmt.Sprintf("%s.ec_profile", protocol))
+	if name == "" {
+		return ec.P256, nil
+	}
+	return ec.GetCurvePreset(name)
+}
+
+func (c *Config) LoadQRRSA() *qr.RSA {
+	x := c.viper().GetStringMapString("qr")
 	p, _ := new(big.Int).SetString(x["p"], 10)
 	q, _ := new(big.Int).SetString(x["q"], 10)
 	qr, err := qr.NewRSA(p, q)
@@ -144,22 +369,59 @@ func LoadQRRSA() *qr.RSA {
 	return qr
 }
 
-func LoadPseudonymsysOrgSecrets(orgName, dlogType string) *pseudsys.SecKey {
-	org := viper.GetStringMap(fmt.Sprintf("pseudonymsys.%s.%s", orgName, dlogType))
+// LoadPseudonymsysOrgSecrets returns the secret key of organization orgName in the
+// pseudonym system of the given type (dlog or ecdlog). When pseudonymsys.<org>.<type>.key
+// is set, the key is read from this file in PEM form, otherwise from values s1 and s2.
+func (c *Config) LoadPseudonymsysOrgSecrets(orgName, dlogType string) *pseudsys.SecKey {
+	prefix := fmt.Sprintf("pseudonymsys.%s.%s", orgName, dlogType)
+	if data := c.readKeyFile(prefix + ".key"); data != nil {
+		sk, err := keys.DecodePseudonymsysSecKey(data)
+		if err != nil {
+			panic(fmt.Errorf("error when loading secret key of %s: %s", orgName, err))
+		}
+		return sk
+	}
+
+	org := c.viper().GetStringMap(prefix)
 	s1, _ := new(big.Int).SetString(org["s1"].(string), 10)
 	s2, _ := new(big.Int).SetString(org["s2"].(string), 10)
 	return pseudsys.NewSecKey(s1, s2)
 }
 
-func LoadPseudonymsysOrgPubKeys(orgName string) *pseudsys.PubKey {
-	org := viper.GetStringMap(fmt.Sprintf("pseudonymsys.%s.%s", orgName, "dlog"))
+// LoadPseudonymsysOrgPubKeys returns the public key of organization orgName in the
+// pseudonym system. When pseudonymsys.<org>.dlog.pub_key is set, the key is read
+// from this file in PEM form, otherwise from values h1 and h2.
+func (c *Config) LoadPseudonymsysOrgPubKeys(orgName string) *pseudsys.PubKey {
+	prefix := fmt.Sprintf("pseudonymsys.%s.%s", orgName, "dlog")
+	if data := c.readKeyFile(prefix + ".pub_key"); data != nil {
+		pk, err := keys.DecodePseudonymsysPubKey(data)
+		if err != nil {
+			panic(fmt.Errorf("error when loading public key of %s: %s", orgName, err))
+		}
+		return pk
+	}
+
+	org := c.viper().GetStringMap(prefix)
 	h1, _ := new(big.Int).SetString(org["h1"].(string), 10)
 	h2, _ := new(big.Int).SetString(org["h2"].(string), 10)
 	return pseudsys.NewPubKey(h1, h2)
 }
 
-func LoadPseudonymsysOrgPubKeysEC(orgName string) *ecpseudsys.PubKey {
-	org := viper.GetStringMap(fmt.Sprintf("pseudonymsys.%s.%s", orgName, "ecdlog"))
+// LoadPseudonymsysOrgPubKeysEC returns the public key of organization orgName in the
+// pseudonym system in EC arithmetic. When pseudonymsys.<org>.ecdlog.pub_key is set,
+// the key is read from this file in PEM or JWK set form, otherwise from values h1x,
+// h1y, h2x and h2y.
+func (c *Config) LoadPseudonymsysOrgPubKeysEC(orgName string) *ecpseudsys.PubKey {
+	prefix := fmt.Sprintf("pseudonymsys.%s.%s", orgName, "ecdlog")
+	if data := c.readKeyFile(prefix + ".pub_key"); data != nil {
+		pk, err := keys.DecodeECPseudonymsysPubKey(data)
+		if err != nil {
+			panic(fmt.Errorf("error when loading public key of %s: %s", orgName, err))
+		}
+		return pk
+	}
+
+	org := c.viper().GetStringMap(prefix)
 	h1X, _ := new(big.Int).SetString(org["h1x"].(string), 10)
 	h1Y, _ := new(big.Int).SetString(org["h1y"].(string), 10)
 	h2X, _ := new(big.Int).SetString(org["h2x"].(string), 10)
@@ -170,29 +432,122 @@ func LoadPseudonymsysOrgPubKeysEC(orgName string) *ecpseudsys.PubKey {
 	)
 }
 
-func LoadPseudonymsysCASecret() *big.Int {
-	ca := viper.GetStringMap("pseudonymsys.ca")
+// LoadPseudonymsysOrgNames returns sorted names of organizations in the pseudonym
+// system, that is sections of pseudonymsys holding keys of type dlog or ecdlog.
+func (c *Config) LoadPseudonymsysOrgNames() []string {
+	var names []string
+	for name := range c.viper().GetStringMap("pseudonymsys") {
+		if c.HasPseudonymsysOrgKeys(name, "dlog") || c.HasPseudonymsysOrgKeys(name, "ecdlog") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadPseudonymsysOrgAccepts returns names of organizations whose credentials
+// organization orgName derives its credentials from, given in pseudonymsys.<org>.accepts.
+func (c *Config) LoadPseudonymsysOrgAccepts(orgName string) []string {
+	return c.viper().GetStringSlice(fmt.Sprintf("pseudonymsys.%s.accepts", orgName))
+}
+
+// LoadPseudonymsysRequirePossession returns whether organizations of the pseudonym
+// system require users that transfer credentials to them to prove possession of the
+// secret of their nym with a proof bound to the organization and the time of transfer.
+func (c *Config) LoadPseudonymsysRequirePossession() bool {
+	return c.viper().GetBool("pseudonymsys.transfer.require_possession")
+}
+
+// HasPseudonymsysOrgKeys reports whether keys of organization orgName in the pseudonym
+// system of the given type (dlog or ecdlog) are configured.
+func (c *Config) HasPseudonymsysOrgKeys(orgName, dlogType string) bool {
+	return c.viper().IsSet(fmt.Sprintf("pseudonymsys.%s.%s", orgName, dlogType))
+}
+
+// LoadPseudonymsysCASecret returns the secret key of the CA in the pseudonym system.
+// When pseudonymsys.ca.key is set, the key is read from this file, which holds
+// an ECDSA P-256 private key in PEM (PKCS#8 or SEC 1) or JWK form, otherwise from value d.
+func (c *Config) LoadPseudonymsysCASecret() *big.Int {
+	if key := c.loadCAKey(); key != nil {
+		return key.D
+	}
+
+	ca := c.viper().GetStringMap("pseudonymsys.ca")
 	s, _ := new(big.Int).SetString(ca["d"].(string), 10)
 	return s
 }
 
-func LoadPseudonymsysCAPubKey() *pseudsys.PubKey {
-	ca := viper.GetStringMap("pseudonymsys.ca")
+// LoadPseudonymsysCAPubKey returns the public key of the CA in the pseudonym system,
+// from the key file (see LoadPseudonymsysCASecret), from the file set in
+// pseudonymsys.ca.pub_key, which holds an ECDSA public key in PEM or JWK form,
+// or from values x and y1. With the public key file, organizations can verify
+// certificates of a CA run on another host without holding its secret key.
+func (c *Config) LoadPseudonymsysCAPubKey() *pseudsys.PubKey {
+	if key := c.loadCAKey(); key != nil {
+		return pseudsys.NewPubKey(key.X, key.Y)
+	}
+	if data := c.readKeyFile("pseudonymsys.ca.pub_key"); data != nil {
+		key, err := keys.DecodeECDSAPublicKey(data)
+		if err != nil {
+			panic(fmt.Errorf("error when loading CA public key: %s", err))
+		}
+		return pseudsys.NewPubKey(key.X, key.Y)
+	}
+
+	ca := c.viper().GetStringMap("pseudonymsys.ca")
 	x, _ := new(big.Int).SetString(ca["x"].(string), 10)
 	y, _ := new(big.Int).SetString(ca["y1"].(string), 10)
 	return pseudsys.NewPubKey(x, y)
 }
 
-func LoadServiceInfo() (string, string, string) {
-	serviceName := viper.GetString("service_info.name")
-	serviceProvider := viper.GetString("service_info.provider")
-	serviceDescription := viper.GetString("service_info.description")
+// loadCAKey returns the CA key read from the file set in pseudonymsys.ca.key,
+// or nil if it is not set.
+func (c *Config) loadCAKey() *ecdsa.PrivateKey {
+	data := c.readKeyFile("pseudonymsys.ca.key")
+	if data == nil {
+		return nil
+	}
+	key, err := keys.DecodeECDSAPrivateKey(data)
+	if err != nil {
+		panic(fmt.Errorf("error when loading CA key: %s", err))
+	}
+	return key
+}
+
+// readKeyFile returns contents of the file with the path set in key, or nil
+// if key is not set.
+func (c *Config) readKeyFile(key string) []byte {
+	path := c.viper().GetString(key)
+	if path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		panic(fmt.Errorf("error when reading key file: %s", err))
+	}
+	return data
+}
+
+func (c *Config) LoadServiceInfo() (string, string, string) {
+	serviceName := c.viper().GetString("service_info.name")
+	serviceProvider := c.viper().GetString("service_info.provider")
+	serviceDescription := c.viper().GetString("service_info.description")
 	return serviceName, serviceProvider, serviceDescription
 }
 
-func LoadCredentialStructure() (map[string]interface{}, error) {
-	m := viper.GetStringMapString("attributes")
+func (c *Config) LoadCredentialStructure() (map[string]interface{}, error) {
+	return parseCredentialStructure(c.LoadAttributeSpecs()), nil
+}
 
+// LoadAttributeSpecs returns attributes of credentials as they are configured, that is
+// specifications "name, type, known" by index of the attribute.
+func (c *Config) LoadAttributeSpecs() map[string]string {
+	return c.viper().GetStringMapString("attributes")
+}
+
+// parseCredentialStructure parses attributes of credentials, given by their index as
+// "name, type, known", into the structure accepted by cl.ParseAttrs.
+func parseCredentialStructure(m map[string]string) map[string]interface{} {
 	attrs := make(map[string]interface{})
 	for k, v := range m {
 		vs := strings.Split(v, ",")
@@ -203,11 +558,11 @@ func LoadCredentialStructure() (map[string]interface{}, error) {
 		}
 	}
 
-	return attrs, nil
+	return attrs
 }
 
-func LoadAcceptableCredentials() (map[string][]string, error) {
-	m := viper.GetStringMapString("acceptable_credentials")
+func (c *Config) LoadAcceptableCredentials() (map[string][]string, error) {
+	m := c.viper().GetStringMapString("acceptable_credentials")
 	accCreds := make(map[string][]string)
 	for k, v := range m {
 		vs := strings.Split(v, ",")
@@ -222,10 +577,10 @@ func LoadAcceptableCredentials() (map[string][]string, error) {
 	return accCreds, nil
 }
 
-func LoadConditions() (map[int]string, map[int]int, map[int]string, error) {
-	conditions := viper.GetStringMapString("conditions")
-	intValues := viper.GetStringMapString("int_values")
-	strValues := viper.GetStringMapString("str_values")
+func (c *Config) LoadConditions() (map[int]string, map[int]int, map[int]string, error) {
+	conditions := c.viper().GetStringMapString("conditions")
+	intValues := c.viper().GetStringMapString("int_values")
+	strValues := c.viper().GetStringMapString("str_values")
 
 	conds := make(map[int]string)
 	for k, v := range conditions {
@@ -259,10 +614,89 @@ func LoadConditions() (map[int]string, map[int]int, map[int]string, error) {
 	return conds, intVals, strVals, nil
 }
 
-func LoadSessionKeyMinByteLen() int {
-	return viper.GetInt("session_key_bytelen")
+// LoadSessionKeyMinByteLen returns the byte length of random session keys.
+func (c *Config) LoadSessionKeyMinByteLen() int {
+	return c.viper().GetInt("session_key_bytelen")
 }
 
-func LoadRegistrationDBAddress() string {
-	return viper.GetString("registration_db_address")
+// LoadCLKeyPaths returns paths to the files holding CL public and secret key
+// of the organization issuing credentials. Unless configured otherwise, keys
+// are expected in the testdata directory.
+func (c *Config) LoadCLKeyPaths() (string, string) {
+	pubKeyPath := c.viper().GetString("cl.pub_key")
+	if pubKeyPath == "" {
+		pubKeyPath = filepath.Join(c.LoadTestdataDir(), "clPubKey.gob")
+	}
+	secKeyPath := c.viper().GetString("cl.sec_key")
+	if secKeyPath == "" {
+		secKeyPath = filepath.Join(c.LoadTestdataDir(), "clSecKey.gob")
+	}
+	return pubKeyPath, secKeyPath
+}
+
+// LoadBBSKeyPaths returns paths to the files holding BBS+ public and secret key
+// of the organization issuing credentials. Unless configured otherwise, keys
+// are expected in the testdata directory.
+func (c *Config) LoadBBSKeyPaths() (string, string) {
+	pubKeyPath := c.viper().GetString("bbs.pub_key")
+	if pubKeyPath == "" {
+		pubKeyPath = filepath.Join(c.LoadTestdataDir(), "bbsPubKey.gob")
+	}
+	secKeyPath := c.viper().GetString("bbs.sec_key")
+	if secKeyPath == "" {
+		secKeyPath = filepath.Join(c.LoadTestdataDir(), "bbsSecKey.gob")
+	}
+	return pubKeyPath, secKeyPath
+}
+
+// LoadCLParamsPreset returns the name of the CL parameters preset to be used
+// (key cl.params). Defaults to "test".
+func (c *Config) LoadCLParamsPreset() string {
+	return c.viper().GetString("cl.params")
+}
+
+// LoadCLCommitmentScheme returns the name of the scheme of commitments of committed
+// attributes of CL credentials (key cl.commitments). Defaults to "qr".
+func (c *Config) LoadCLCommitmentScheme() string {
+	return c.viper().GetString("cl.commitments")
+}
+
+// LoadRegistrationDBAddress returns the address of the database holding registration keys.
+//
+// Deprecated: use LoadStorageConfig("registration").DSN instead.
+func (c *Config) LoadRegistrationDBAddress() string {
+	return c.LoadStorageConfig("registration").DSN
+}
+
+// StorageConfig holds settings of a storage backend used by one of emmy's stores.
+type StorageConfig struct {
+	Driver    string // "redis", "memory" or "sql"
+	SQLDriver string // name of the database/sql driver when Driver is "sql"
+	DSN       string // address of the database (for redis in the form host:port)
+	Password  string
+	DB        int  // index of the database to select
+	PoolSize  int  // maximum number of connections, 0 means the driver's default
+	TLS       bool // whether to connect to the database over TLS
+}
+
+// LoadStorageConfig returns the storage configuration for the given store
+// (e.g. "registration" or "records"). Settings in section storage.<store>
+// override those in section storage.
+func (c *Config) LoadStorageConfig(store string) *StorageConfig {
+	key := func(name string) string {
+		if k := fmt.Sprintf("storage.%s.%s", store, name); c.viper().IsSet(k) {
+			return k
+		}
+		return "storage." + name
+	}
+
+	return &StorageConfig{
+		Driver:    c.viper().GetString(key("driver")),
+		SQLDriver: c.viper().GetString(key("sql_driver")),
+		DSN:       c.viper().GetString(key("dsn")),
+		Password:  c.viper().GetString(key("password")),
+		DB:        c.viper().GetInt(key("db")),
+		PoolSize:  c.viper().GetInt(key("pool_size")),
+		TLS:       c.viper().GetBool(key("tls")),
+	}
 }
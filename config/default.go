@@ -0,0 +1,343 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	"github.com/xlab-si/emmy/crypto/qr"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+)
+
+// Functions below read the default configuration, see Default.
+
+// LoadServerPort calls Config.LoadServerPort on the default configuration.
+func LoadServerPort() int {
+	return global.LoadServerPort()
+}
+
+// LoadServerEndpoint calls Config.LoadServerEndpoint on the default configuration.
+func LoadServerEndpoint() string {
+	return global.LoadServerEndpoint()
+}
+
+// LoadTimeout calls Config.LoadTimeout on the default configuration.
+func LoadTimeout() int {
+	return global.LoadTimeout()
+}
+
+// LoadDevMode calls Config.LoadDevMode on the default configuration.
+func LoadDevMode() bool {
+	return global.LoadDevMode()
+}
+
+// UseEphemeralKeys calls Config.UseEphemeralKeys on the default configuration.
+func UseEphemeralKeys(dir string) {
+	global.UseEphemeralKeys(dir)
+}
+
+// LoadKeyDirFromConfig calls Config.LoadKeyDirFromConfig on the default configuration.
+func LoadKeyDirFromConfig() string {
+	return global.LoadKeyDirFromConfig()
+}
+
+// LoadTestdataDir calls Config.LoadTestdataDir on the default configuration.
+func LoadTestdataDir() string {
+	return global.LoadTestdataDir()
+}
+
+// LoadTestKeyDirFromConfig calls Config.LoadTestKeyDirFromConfig on the default configuration.
+func LoadTestKeyDirFromConfig() string {
+	return global.LoadTestKeyDirFromConfig()
+}
+
+// LoadGroup calls Config.LoadGroup on the default configuration.
+func LoadGroup(protocol string) (*schnorr.Group, error) {
+	return global.LoadGroup(protocol)
+}
+
+// LoadCurve calls Config.LoadCurve on the default configuration.
+func LoadCurve(protocol string) (ec.Curve, error) {
+	return global.LoadCurve(protocol)
+}
+
+// LoadQRRSA calls Config.LoadQRRSA on the default configuration.
+func LoadQRRSA() *qr.RSA {
+	return global.LoadQRRSA()
+}
+
+// LoadPseudonymsysOrgSecrets calls Config.LoadPseudonymsysOrgSecrets on the default configuration.
+func LoadPseudonymsysOrgSecrets(orgName, dlogType string) *pseudsys.SecKey {
+	return global.LoadPseudonymsysOrgSecrets(orgName, dlogType)
+}
+
+// LoadPseudonymsysOrgPubKeys calls Config.LoadPseudonymsysOrgPubKeys on the default configuration.
+func LoadPseudonymsysOrgPubKeys(orgName string) *pseudsys.PubKey {
+	return global.LoadPseudonymsysOrgPubKeys(orgName)
+}
+
+// LoadPseudonymsysOrgPubKeysEC calls Config.LoadPseudonymsysOrgPubKeysEC on the default configuration.
+func LoadPseudonymsysOrgPubKeysEC(orgName string) *ecpseudsys.PubKey {
+	return global.LoadPseudonymsysOrgPubKeysEC(orgName)
+}
+
+// LoadPseudonymsysOrgNames calls Config.LoadPseudonymsysOrgNames on the default configuration.
+func LoadPseudonymsysOrgNames() []string {
+	return global.LoadPseudonymsysOrgNames()
+}
+
+// LoadPseudonymsysOrgAccepts calls Config.LoadPseudonymsysOrgAccepts on the default
+// configuration.
+func LoadPseudonymsysOrgAccepts(orgName string) []string {
+	return global.LoadPseudonymsysOrgAccepts(orgName)
+}
+
+// LoadPseudonymsysRequirePossession calls Config.LoadPseudonymsysRequirePossession on the
+// default configuration.
+func LoadPseudonymsysRequirePossession() bool {
+	return global.LoadPseudonymsysRequirePossession()
+}
+
+// HasPseudonymsysOrgKeys calls Config.HasPseudonymsysOrgKeys on the default configuration.
+func HasPseudonymsysOrgKeys(orgName, dlogType string) bool {
+	return global.HasPseudonymsysOrgKeys(orgName, dlogType)
+}
+
+// LoadPseudonymsysCASecret calls Config.LoadPseudonymsysCASecret on the default configuration.
+func LoadPseudonymsysCASecret() *big.Int {
+	return global.LoadPseudonymsysCASecret()
+}
+
+// LoadPseudonymsysCAPubKey calls Config.LoadPseudonymsysCAPubKey on the default configuration.
+func LoadPseudonymsysCAPubKey() *pseudsys.PubKey {
+	return global.LoadPseudonymsysCAPubKey()
+}
+
+// LoadServiceInfo calls Config.LoadServiceInfo on the default configuration.
+func LoadServiceInfo() (string, string, string) {
+	return global.LoadServiceInfo()
+}
+
+// LoadCredentialStructure calls Config.LoadCredentialStructure on the default configuration.
+func LoadCredentialStructure() (map[string]interface{}, error) {
+	return global.LoadCredentialStructure()
+}
+
+// LoadAttributeSpecs calls Config.LoadAttributeSpecs on the default configuration.
+func LoadAttributeSpecs() map[string]string {
+	return global.LoadAttributeSpecs()
+}
+
+// LoadAcceptableCredentials calls Config.LoadAcceptableCredentials on the default configuration.
+func LoadAcceptableCredentials() (map[string][]string, error) {
+	return global.LoadAcceptableCredentials()
+}
+
+// LoadConditions calls Config.LoadConditions on the default configuration.
+func LoadConditions() (map[int]string, map[int]int, map[int]string, error) {
+	return global.LoadConditions()
+}
+
+// LoadSessionKeyMinByteLen calls Config.LoadSessionKeyMinByteLen on the default configuration.
+func LoadSessionKeyMinByteLen() int {
+	return global.LoadSessionKeyMinByteLen()
+}
+
+// LoadSessionConfig calls Config.LoadSessionConfig on the default configuration.
+func LoadSessionConfig() *SessionConfig {
+	return global.LoadSessionConfig()
+}
+
+// LoadCLKeyPaths calls Config.LoadCLKeyPaths on the default configuration.
+func LoadCLKeyPaths() (string, string) {
+	return global.LoadCLKeyPaths()
+}
+
+// LoadCLPreviousKeys calls Config.LoadCLPreviousKeys on the default configuration.
+func LoadCLPreviousKeys() ([]*CLPreviousKey, error) {
+	return global.LoadCLPreviousKeys()
+}
+
+// LoadPolicies calls Config.LoadPolicies on the default configuration.
+func LoadPolicies() ([]*PolicyConfig, error) {
+	return global.LoadPolicies()
+}
+
+// LoadBBSKeyPaths calls Config.LoadBBSKeyPaths on the default configuration.
+func LoadBBSKeyPaths() (string, string) {
+	return global.LoadBBSKeyPaths()
+}
+
+// LoadCLParamsPreset calls Config.LoadCLParamsPreset on the default configuration.
+func LoadCLParamsPreset() string {
+	return global.LoadCLParamsPreset()
+}
+
+// LoadCLCommitmentScheme calls Config.LoadCLCommitmentScheme on the default configuration.
+func LoadCLCommitmentScheme() string {
+	return global.LoadCLCommitmentScheme()
+}
+
+// LoadRegistrationDBAddress calls Config.LoadRegistrationDBAddress on the default configuration.
+func LoadRegistrationDBAddress() string {
+	return global.LoadRegistrationDBAddress()
+}
+
+// LoadStorageConfig calls Config.LoadStorageConfig on the default configuration.
+func LoadStorageConfig(store string) *StorageConfig {
+	return global.LoadStorageConfig(store)
+}
+
+// LoadNetworkConfig calls Config.LoadNetworkConfig on the default configuration.
+func LoadNetworkConfig() *NetworkConfig {
+	return global.LoadNetworkConfig()
+}
+
+// LoadOIDCConfig calls Config.LoadOIDCConfig on the default configuration.
+func LoadOIDCConfig() *OIDCConfig {
+	return global.LoadOIDCConfig()
+}
+
+// LoadGatewayConfig calls Config.LoadGatewayConfig on the default configuration.
+func LoadGatewayConfig() *GatewayConfig {
+	return global.LoadGatewayConfig()
+}
+
+// LoadDIDCommConfig calls Config.LoadDIDCommConfig on the default configuration.
+func LoadDIDCommConfig() *DIDCommConfig {
+	return global.LoadDIDCommConfig()
+}
+
+// LoadPublicParamsConfig calls Config.LoadPublicParamsConfig on the default
+// configuration.
+func LoadPublicParamsConfig() *PublicParamsConfig {
+	return global.LoadPublicParamsConfig()
+}
+
+// LoadGrpcWebConfig calls Config.LoadGrpcWebConfig on the default configuration.
+func LoadGrpcWebConfig() *GrpcWebConfig {
+	return global.LoadGrpcWebConfig()
+}
+
+// LoadWebAuthnConfig calls Config.LoadWebAuthnConfig on the default configuration.
+func LoadWebAuthnConfig() *WebAuthnConfig {
+	return global.LoadWebAuthnConfig()
+}
+
+// LoadPKIConfig calls Config.LoadPKIConfig on the default configuration.
+func LoadPKIConfig() *PKIConfig {
+	return global.LoadPKIConfig()
+}
+
+// LoadRecordingConfig calls Config.LoadRecordingConfig on the default configuration.
+func LoadRecordingConfig() *RecordingConfig {
+	return global.LoadRecordingConfig()
+}
+
+// LoadAuditConfig calls Config.LoadAuditConfig on the default configuration.
+func LoadAuditConfig() *AuditConfig {
+	return global.LoadAuditConfig()
+}
+
+// LoadTranscriptConfig calls Config.LoadTranscriptConfig on the default configuration.
+func LoadTranscriptConfig() *TranscriptConfig {
+	return global.LoadTranscriptConfig()
+}
+
+// LoadFaultsConfig calls Config.LoadFaultsConfig on the default configuration.
+func LoadFaultsConfig() *FaultsConfig {
+	return global.LoadFaultsConfig()
+}
+
+// LoadNonceConfig calls Config.LoadNonceConfig on the default configuration.
+func LoadNonceConfig() *NonceConfig {
+	return global.LoadNonceConfig()
+}
+
+// LoadClusterConfig calls Config.LoadClusterConfig on the default configuration.
+func LoadClusterConfig() *ClusterConfig {
+	return global.LoadClusterConfig()
+}
+
+// LoadRNGConfig calls Config.LoadRNGConfig on the default configuration.
+func LoadRNGConfig() *RNGConfig {
+	return global.LoadRNGConfig()
+}
+
+// LoadRevocationConfig calls Config.LoadRevocationConfig on the default configuration.
+func LoadRevocationConfig() *RevocationConfig {
+	return global.LoadRevocationConfig()
+}
+
+// LoadCredentialSchema calls Config.LoadCredentialSchema on the default configuration.
+func LoadCredentialSchema() (string, string) {
+	return global.LoadCredentialSchema()
+}
+
+// LoadCredentialSchemas calls Config.LoadCredentialSchemas on the default configuration.
+func LoadCredentialSchemas() ([]*CredentialSchema, error) {
+	return global.LoadCredentialSchemas()
+}
+
+// LoadBatchIssuanceConfig calls Config.LoadBatchIssuanceConfig on the default configuration.
+func LoadBatchIssuanceConfig() *BatchIssuanceConfig {
+	return global.LoadBatchIssuanceConfig()
+}
+
+// LoadCredExpirationConfig calls Config.LoadCredExpirationConfig on the default configuration.
+func LoadCredExpirationConfig() *CredExpirationConfig {
+	return global.LoadCredExpirationConfig()
+}
+
+// LoadCLThresholdConfig calls Config.LoadCLThresholdConfig on the default configuration.
+func LoadCLThresholdConfig() *CLThresholdConfig {
+	return global.LoadCLThresholdConfig()
+}
+
+// LoadMetricsConfig calls Config.LoadMetricsConfig on the default configuration.
+func LoadMetricsConfig() *MetricsConfig {
+	return global.LoadMetricsConfig()
+}
+
+// LoadTracingConfig calls Config.LoadTracingConfig on the default configuration.
+func LoadTracingConfig() *TracingConfig {
+	return global.LoadTracingConfig()
+}
+
+// LoadLoggingConfig calls Config.LoadLoggingConfig on the default configuration.
+func LoadLoggingConfig() *LoggingConfig {
+	return global.LoadLoggingConfig()
+}
+
+// LoadKeyStoreConfig calls Config.LoadKeyStoreConfig on the default configuration.
+func LoadKeyStoreConfig() *KeyStoreConfig {
+	return global.LoadKeyStoreConfig()
+}
+
+// LoadCAKeyStoreConfig calls Config.LoadCAKeyStoreConfig on the default configuration.
+func LoadCAKeyStoreConfig() *KeyStoreConfig {
+	return global.LoadCAKeyStoreConfig()
+}
+
+// LoadTenants calls Config.LoadTenants on the default configuration.
+func LoadTenants() []*TenantConfig {
+	return global.LoadTenants()
+}
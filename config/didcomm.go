@@ -0,0 +1,45 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// DIDCommConfig holds settings of the DIDComm endpoint of emmy server.
+type DIDCommConfig struct {
+	Enabled bool
+	Address string // address where the endpoint listens for HTTPS requests
+	KeyFile string // path to the key agreement key of the endpoint
+}
+
+// LoadDIDCommConfig returns settings of the DIDComm endpoint from section didcomm
+// of the configuration.
+func (c *Config) LoadDIDCommConfig() *DIDCommConfig {
+	return &DIDCommConfig{
+		Enabled: c.viper().GetBool("didcomm.enabled"),
+		Address: c.viper().GetString("didcomm.address"),
+		KeyFile: c.viper().GetString("didcomm.key"),
+	}
+}
+
+// setDIDCommDefaults sets default values of the DIDComm endpoint settings.
+func setDIDCommDefaults(v *viper.Viper) {
+	v.SetDefault("didcomm.enabled", false)
+	v.SetDefault("didcomm.address", ":8883")
+}
@@ -0,0 +1,59 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// FaultsConfig holds settings of faults the server injects into protocol streams
+// and storage, for testing how clients and integrations handle failures. Rates are
+// probabilities between 0 and 1. Faults must never be enabled in production.
+type FaultsConfig struct {
+	Enabled          bool
+	Delay            time.Duration // delay of messages and storage operations
+	DelayRate        float64       // rate of delayed messages and storage operations
+	DropRate         float64       // rate of messages that are silently dropped
+	StorageErrorRate float64       // rate of failed storage operations
+	Seed             int64         // seed of the choice of faults, 0 means random
+}
+
+// LoadFaultsConfig returns settings of fault injection from section faults of the
+// configuration. The delay is read in milliseconds.
+func (c *Config) LoadFaultsConfig() *FaultsConfig {
+	return &FaultsConfig{
+		Enabled:          c.viper().GetBool("faults.enabled"),
+		Delay:            time.Duration(c.viper().GetInt("faults.delay")) * time.Millisecond,
+		DelayRate:        c.viper().GetFloat64("faults.delay_rate"),
+		DropRate:         c.viper().GetFloat64("faults.drop_rate"),
+		StorageErrorRate: c.viper().GetFloat64("faults.storage_error_rate"),
+		Seed:             c.viper().GetInt64("faults.seed"),
+	}
+}
+
+// setFaultsDefaults sets default values of fault injection settings.
+func setFaultsDefaults(v *viper.Viper) {
+	v.SetDefault("faults.enabled", false)
+	v.SetDefault("faults.delay", 0)
+	v.SetDefault("faults.delay_rate", 0)
+	v.SetDefault("faults.drop_rate", 0)
+	v.SetDefault("faults.storage_error_rate", 0)
+	v.SetDefault("faults.seed", 0)
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"path/filepath"
+
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+)
+
+// NewTestConfig returns an in-memory configuration, populated with everything that emmy
+// server and clients need to run the supported protocols. Keys for pseudonym systems are
+// freshly generated, while groups and CL parameters are small, so that protocols run fast.
+// CL and BBS keys are read from keyDir, which also serves as testdata_dir, so keys, TLS
+// certificates and the accumulator of the repository's client/testdata fixtures are used
+// when keyDir points to it. Missing group parameters are generated on first use and
+// stored in keyDir. The configuration is NOT secure and is meant for tests only.
+func NewTestConfig(keyDir string) (*Config, error) {
+	keyDir, err := filepath.Abs(keyDir)
+	if err != nil {
+		return nil, err
+	}
+
+	c := New()
+	c.Set("key_folder", keyDir)
+	c.Set("testdata_dir", keyDir)
+	c.Set("cl.params", "test")
+	c.Set("cl.pub_key", filepath.Join(keyDir, "clPubKey.gob"))
+	c.Set("cl.sec_key", filepath.Join(keyDir, "clSecKey.gob"))
+	c.Set("bbs.pub_key", filepath.Join(keyDir, "bbsPubKey.gob"))
+	c.Set("bbs.sec_key", filepath.Join(keyDir, "bbsSecKey.gob"))
+	c.Set("session_key_bytelen", 32)
+
+	c.Set("qr", map[string]interface{}{
+		"p": "109225465622713471254760277521351470678135997982392036687958353565687721648227",
+		"q": "97677263194688858676678458934032316999260513482681814794753295129431734941099",
+	})
+
+	group, err := c.LoadGroup("pseudonymsys")
+	if err != nil {
+		return nil, err
+	}
+	secKey, pubKey := pseudsys.GenerateKeyPair(group)
+	c.Set("pseudonymsys.org1.dlog", map[string]interface{}{
+		"h1": pubKey.H1.String(),
+		"h2": pubKey.H2.String(),
+		"s1": secKey.S1.String(),
+		"s2": secKey.S2.String(),
+	})
+
+	ecGroup := ec.NewGroup(ec.P256)
+	ecSecKey, ecPubKey := ecpseudsys.GenerateKeyPair(ecGroup)
+	c.Set("pseudonymsys.org1.ecdlog", map[string]interface{}{
+		"h1x": ecPubKey.H1.X.String(),
+		"h1y": ecPubKey.H1.Y.String(),
+		"h2x": ecPubKey.H2.X.String(),
+		"h2y": ecPubKey.H2.Y.String(),
+		"s1":  ecSecKey.S1.String(),
+		"s2":  ecSecKey.S2.String(),
+	})
+
+	d := common.GetRandomInt(ecGroup.Q)
+	caPubKey := ecGroup.ExpBaseG(d)
+	c.Set("pseudonymsys.ca", map[string]interface{}{
+		"d":  d.String(),
+		"x":  caPubKey.X.String(),
+		"y1": caPubKey.Y.String(),
+	})
+
+	c.Set("service_info", map[string]interface{}{
+		"name":        "Test service",
+		"provider":    "Test provider",
+		"description": "Service for testing emmy",
+	})
+
+	c.Set("attributes", map[string]interface{}{
+		"0": "Name, string, true",
+		"1": "Gender, string, true",
+		"2": "Graduated, string, true",
+		"3": "DateMin, int64, true",
+		"4": "DateMax, int64, true",
+		"5": "Age, int64, false",
+	})
+	c.Set("acceptable_credentials", map[string]interface{}{
+		"Org1": "Name, DateMin, DateMax",
+		"Org2": "Gender",
+	})
+	c.Set("conditions", map[string]interface{}{"3": "greater", "4": "lesser"})
+	c.Set("int_values", map[string]interface{}{"3": "1562643000", "4": "1562643000"})
+	c.Set("str_values", map[string]interface{}{})
+
+	return c, nil
+}
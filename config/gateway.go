@@ -0,0 +1,45 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// GatewayConfig holds settings of the HTTP/JSON gateway of emmy server.
+type GatewayConfig struct {
+	Enabled    bool
+	Address    string // address where the gateway listens for HTTPS requests
+	AdminToken string // bearer token for admin endpoints, which are disabled when empty
+}
+
+// LoadGatewayConfig returns settings of the HTTP/JSON gateway from section gateway
+// of the configuration.
+func (c *Config) LoadGatewayConfig() *GatewayConfig {
+	return &GatewayConfig{
+		Enabled:    c.viper().GetBool("gateway.enabled"),
+		Address:    c.viper().GetString("gateway.address"),
+		AdminToken: c.viper().GetString("gateway.admin_token"),
+	}
+}
+
+// setGatewayDefaults sets default values of the gateway settings.
+func setGatewayDefaults(v *viper.Viper) {
+	v.SetDefault("gateway.enabled", false)
+	v.SetDefault("gateway.address", ":8080")
+}
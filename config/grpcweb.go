@@ -0,0 +1,46 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// GrpcWebConfig holds settings of the gRPC-Web endpoint of emmy server.
+type GrpcWebConfig struct {
+	Enabled        bool
+	Address        string   // address where the endpoint listens for HTTPS requests
+	AllowedOrigins []string // origins of browser clients allowed to call the endpoint
+}
+
+// LoadGrpcWebConfig returns settings of the gRPC-Web endpoint from section grpcweb
+// of the configuration.
+func (c *Config) LoadGrpcWebConfig() *GrpcWebConfig {
+	return &GrpcWebConfig{
+		Enabled:        c.viper().GetBool("grpcweb.enabled"),
+		Address:        c.viper().GetString("grpcweb.address"),
+		AllowedOrigins: c.viper().GetStringSlice("grpcweb.allowed_origins"),
+	}
+}
+
+// setGrpcWebDefaults sets default values of the gRPC-Web endpoint settings.
+func setGrpcWebDefaults(v *viper.Viper) {
+	v.SetDefault("grpcweb.enabled", false)
+	v.SetDefault("grpcweb.address", ":8884")
+	v.SetDefault("grpcweb.allowed_origins", []string{})
+}
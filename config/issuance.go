@@ -0,0 +1,82 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// BatchIssuanceConfig holds settings of batch issuance of CL credentials.
+type BatchIssuanceConfig struct {
+	Concurrency int // number of credential requests of a batch processed at once
+}
+
+// LoadBatchIssuanceConfig returns settings of batch issuance from section
+// batch_issuance of the configuration.
+func (c *Config) LoadBatchIssuanceConfig() *BatchIssuanceConfig {
+	return &BatchIssuanceConfig{
+		Concurrency: c.viper().GetInt("batch_issuance.concurrency"),
+	}
+}
+
+// CredExpirationConfig holds settings of expiration of CL credentials.
+type CredExpirationConfig struct {
+	MaxValidity time.Duration // longest validity of issued or updated credentials, 0 for no limit
+}
+
+// LoadCredExpirationConfig returns settings of expiration of credentials from
+// section credential_expiration of the configuration.
+func (c *Config) LoadCredExpirationConfig() *CredExpirationConfig {
+	return &CredExpirationConfig{
+		MaxValidity: time.Duration(c.viper().GetInt("credential_expiration.max_validity")) * time.Second,
+	}
+}
+
+// CLThresholdConfig holds settings of threshold issuance of CL credentials.
+type CLThresholdConfig struct {
+	Share      string   // path to the key share of the server as a party of issuance
+	Threshold  int      // number of parties needed to issue a credential
+	Parties    []string // endpoints of parties, in order of indices of their shares
+	CACert     string   // path to the CA certificate of parties
+	ClientCert string   // path to the certificate presented to parties
+	ClientKey  string   // path to the key of ClientCert
+}
+
+// LoadCLThresholdConfig returns settings of threshold issuance from section
+// cl_threshold of the configuration.
+func (c *Config) LoadCLThresholdConfig() *CLThresholdConfig {
+	return &CLThresholdConfig{
+		Share:      c.viper().GetString("cl_threshold.share"),
+		Threshold:  c.viper().GetInt("cl_threshold.threshold"),
+		Parties:    c.viper().GetStringSlice("cl_threshold.parties"),
+		CACert:     c.viper().GetString("cl_threshold.ca_cert"),
+		ClientCert: c.viper().GetString("cl_threshold.client_cert"),
+		ClientKey:  c.viper().GetString("cl_threshold.client_key"),
+	}
+}
+
+// setBatchIssuanceDefaults sets default values of batch issuance settings.
+func setBatchIssuanceDefaults(v *viper.Viper) {
+	v.SetDefault("batch_issuance.concurrency", 8)
+	v.SetDefault("credential_expiration.max_validity", 0)
+	v.SetDefault("cl_threshold.share", "")
+	v.SetDefault("cl_threshold.threshold", 0)
+	v.SetDefault("cl_threshold.parties", []string{})
+}
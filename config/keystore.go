@@ -0,0 +1,69 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// KeyStoreConfig holds settings of the key store holding secret keys of issuers.
+type KeyStoreConfig struct {
+	Type   string // file or pkcs11, keys are read from the configuration when empty
+	Dir    string // directory of a key store of type file
+	PKCS11 PKCS11Config
+}
+
+// PKCS11Config holds settings of a key store backed by a PKCS#11 token.
+type PKCS11Config struct {
+	Module string // path to the PKCS#11 module (shared library)
+	Token  string // label of the token
+	PIN    string // user PIN of the token
+}
+
+// LoadKeyStoreConfig returns key store settings from section keystore of the
+// configuration.
+func (c *Config) LoadKeyStoreConfig() *KeyStoreConfig {
+	return c.loadKeyStoreConfig("keystore")
+}
+
+// LoadCAKeyStoreConfig returns settings of the key store holding the key of the
+// pseudonym system CA, from section pseudonymsys.ca.keystore of the configuration.
+// When its type is empty, the key of the CA is held by the key store of issuers.
+func (c *Config) LoadCAKeyStoreConfig() *KeyStoreConfig {
+	return c.loadKeyStoreConfig("pseudonymsys.ca.keystore")
+}
+
+// loadKeyStoreConfig returns key store settings from the given section of the
+// configuration.
+func (c *Config) loadKeyStoreConfig(section string) *KeyStoreConfig {
+	return &KeyStoreConfig{
+		Type: c.viper().GetString(section + ".type"),
+		Dir:  c.viper().GetString(section + ".dir"),
+		PKCS11: PKCS11Config{
+			Module: c.viper().GetString(section + ".pkcs11.module"),
+			Token:  c.viper().GetString(section + ".pkcs11.token"),
+			PIN:    c.viper().GetString(section + ".pkcs11.pin"),
+		},
+	}
+}
+
+// setKeyStoreDefaults sets default values of key store settings.
+func setKeyStoreDefaults(v *viper.Viper) {
+	v.SetDefault("keystore.type", "")
+	v.SetDefault("keystore.dir", "keystore")
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// LoggingConfig holds settings of structured logging of emmy server, replacing the
+// --logfile and --loglevel flags when enabled.
+type LoggingConfig struct {
+	Enabled bool
+	// Format is text or json.
+	Format string
+	// Level is the default log level, Levels holds log levels of components.
+	Level  string
+	Levels map[string]string
+	// SampleInitial and SampleThereafter configure sampling of repeated messages,
+	// a SampleInitial of 0 disables sampling.
+	SampleInitial    int
+	SampleThereafter int
+	// Stderr enables output to standard error.
+	Stderr bool
+	// File is the path of a log file, rotated once it grows over FileMaxSize
+	// megabytes, keeping FileMaxBackups rotated files.
+	File           string
+	FileMaxSize    int
+	FileMaxBackups int
+	// Syslog enables output to the syslog daemon at SyslogAddress, reached over
+	// SyslogNetwork (the local daemon when empty), with tag SyslogTag.
+	Syslog        bool
+	SyslogNetwork string
+	SyslogAddress string
+	SyslogTag     string
+}
+
+// LoadLoggingConfig returns logging settings from section logging of the configuration.
+func (c *Config) LoadLoggingConfig() *LoggingConfig {
+	return &LoggingConfig{
+		Enabled:          c.viper().GetBool("logging.enabled"),
+		Format:           c.viper().GetString("logging.format"),
+		Level:            c.viper().GetString("logging.level"),
+		Levels:           c.viper().GetStringMapString("logging.levels"),
+		SampleInitial:    c.viper().GetInt("logging.sampling.initial"),
+		SampleThereafter: c.viper().GetInt("logging.sampling.thereafter"),
+		Stderr:           c.viper().GetBool("logging.sinks.stderr"),
+		File:             c.viper().GetString("logging.sinks.file.path"),
+		FileMaxSize:      c.viper().GetInt("logging.sinks.file.max_size"),
+		FileMaxBackups:   c.viper().GetInt("logging.sinks.file.max_backups"),
+		Syslog:           c.viper().GetBool("logging.sinks.syslog.enabled"),
+		SyslogNetwork:    c.viper().GetString("logging.sinks.syslog.network"),
+		SyslogAddress:    c.viper().GetString("logging.sinks.syslog.address"),
+		SyslogTag:        c.viper().GetString("logging.sinks.syslog.tag"),
+	}
+}
+
+// setLoggingDefaults sets default values of logging settings.
+func setLoggingDefaults(v *viper.Viper) {
+	v.SetDefault("logging.enabled", false)
+	v.SetDefault("logging.format", "text")
+	v.SetDefault("logging.level", "info")
+	v.SetDefault("logging.sampling.initial", 0)
+	v.SetDefault("logging.sampling.thereafter", 0)
+	v.SetDefault("logging.sinks.stderr", true)
+	v.SetDefault("logging.sinks.file.path", "")
+	v.SetDefault("logging.sinks.file.max_size", 100)
+	v.SetDefault("logging.sinks.file.max_backups", 5)
+	v.SetDefault("logging.sinks.syslog.enabled", false)
+	v.SetDefault("logging.sinks.syslog.tag", "emmy")
+}
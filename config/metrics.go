@@ -0,0 +1,43 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// MetricsConfig holds settings of the endpoint serving Prometheus metrics.
+type MetricsConfig struct {
+	Enabled bool
+	Address string
+}
+
+// LoadMetricsConfig returns settings of the metrics endpoint from section metrics of
+// the configuration.
+func (c *Config) LoadMetricsConfig() *MetricsConfig {
+	return &MetricsConfig{
+		Enabled: c.viper().GetBool("metrics.enabled"),
+		Address: c.viper().GetString("metrics.address"),
+	}
+}
+
+// setMetricsDefaults sets default values of metrics settings.
+func setMetricsDefaults(v *viper.Viper) {
+	v.SetDefault("metrics.enabled", true)
+	v.SetDefault("metrics.address", ":8881")
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// NetworkConfig gathers settings of the communication between emmy server and
+// its clients, shared by the server and the CLI.
+type NetworkConfig struct {
+	TLS      TLSConfig
+	Timeouts TimeoutsConfig
+	Limits   LimitsConfig
+	Retry    RetryConfig
+}
+
+// TLSConfig holds paths to certificates and keys used to secure the connection.
+type TLSConfig struct {
+	CertFile       string // server's certificate in PEM format
+	KeyFile        string // server's private key in PEM format
+	CACertFile     string // certificate of the CA that issued server's certificate, used by clients
+	ServerName     string // when set, clients expect it in server certificate instead of the hostname
+	ClientCAFile   string // CA certificates in PEM format; when set, clients must present a cert issued by them
+	ClientCertFile string // client's certificate in PEM format, presented to the server
+	ClientKeyFile  string // client's private key in PEM format
+}
+
+// TimeoutsConfig holds timeouts and deadlines of connections and streams.
+type TimeoutsConfig struct {
+	Connect           time.Duration // for establishing a connection with the server
+	Stream            time.Duration // for completing a single protocol stream, 0 means no deadline
+	Idle              time.Duration // for the next message of a client in a protocol stream, 0 means no limit
+	Keepalive         time.Duration // interval of clients' pings of idle connections, 0 disables them
+	KeepaliveTimeout  time.Duration // for the server to acknowledge a ping before the connection is closed
+	MinKeepalive      time.Duration // shortest interval of clients' pings allowed by the server
+	MaxReconnectDelay time.Duration // upper bound of the backoff between attempts to reconnect
+	Shutdown          time.Duration // for protocol sessions in progress to finish when the server shuts down
+}
+
+// RetryConfig holds the policy by which clients retry protocols that failed because
+// the server could not be reached.
+type RetryConfig struct {
+	MaxAttempts    int           // including the first attempt, 1 means no retries
+	InitialBackoff time.Duration // delay before the second attempt
+	MaxBackoff     time.Duration // upper bound of delays between attempts
+	Multiplier     float64       // factor by which the delay grows after each attempt
+}
+
+// LimitsConfig holds limits that protect emmy server from being overloaded.
+type LimitsConfig struct {
+	MaxRecvMsgSize       int     // maximum size of a received message in bytes
+	MaxSendMsgSize       int     // maximum size of a sent message in bytes
+	MaxConcurrentStreams uint32  // maximum number of concurrent streams per connection, 0 means unlimited
+	RateLimit            float64 // allowed protocol starts per second per IP address, 0 means unlimited
+	RateBurst            int     // maximum burst of protocol starts per IP address
+	ClientRateLimit      float64 // allowed protocol starts per second per client ID, 0 means unlimited
+	ClientRateBurst      int     // maximum burst of protocol starts per client ID
+	MaxStreamsPerPeer    int     // maximum number of concurrent streams per IP address, 0 means unlimited
+	Workers              int     // goroutines for CPU-intensive tasks, 0 means number of CPUs
+}
+
+// LoadNetworkConfig returns network settings from section network of the configuration.
+// The connection timeout is read from key timeout, and like other durations it is given
+// in milliseconds. When certificate and key paths are not set, those from testdata_dir
+// are used.
+func (c *Config) LoadNetworkConfig() *NetworkConfig {
+	pathOrTestdata := func(key, name string) string {
+		if path := c.viper().GetString(key); path != "" {
+			return path
+		}
+		return filepath.Join(c.LoadTestdataDir(), name)
+	}
+	millis := func(key string) time.Duration {
+		return time.Duration(c.viper().GetInt(key)) * time.Millisecond
+	}
+
+	return &NetworkConfig{
+		TLS: TLSConfig{
+			CertFile:       pathOrTestdata("network.tls.cert", "server.pem"),
+			KeyFile:        pathOrTestdata("network.tls.key", "server.key"),
+			CACertFile:     pathOrTestdata("network.tls.ca_cert", "server.pem"),
+			ServerName:     c.viper().GetString("network.tls.server_name"),
+			ClientCAFile:   c.viper().GetString("network.tls.client_ca"),
+			ClientCertFile: c.viper().GetString("network.tls.client_cert"),
+			ClientKeyFile:  c.viper().GetString("network.tls.client_key"),
+		},
+		Timeouts: TimeoutsConfig{
+			Connect:           time.Duration(c.LoadTimeout()) * time.Millisecond,
+			Stream:            millis("network.timeouts.stream"),
+			Idle:              millis("network.timeouts.idle"),
+			Keepalive:         millis("network.timeouts.keepalive"),
+			KeepaliveTimeout:  millis("network.timeouts.keepalive_timeout"),
+			MinKeepalive:      millis("network.timeouts.min_keepalive"),
+			MaxReconnectDelay: millis("network.timeouts.max_reconnect_delay"),
+			Shutdown:          millis("network.timeouts.shutdown"),
+		},
+		Limits: LimitsConfig{
+			MaxRecvMsgSize:       c.viper().GetInt("network.limits.max_recv_msg_size"),
+			MaxSendMsgSize:       c.viper().GetInt("network.limits.max_send_msg_size"),
+			MaxConcurrentStreams: uint32(c.viper().GetInt64("network.limits.max_concurrent_streams")),
+			RateLimit:            c.viper().GetFloat64("network.limits.rate"),
+			RateBurst:            c.viper().GetInt("network.limits.burst"),
+			ClientRateLimit:      c.viper().GetFloat64("network.limits.client_rate"),
+			ClientRateBurst:      c.viper().GetInt("network.limits.client_burst"),
+			MaxStreamsPerPeer:    c.viper().GetInt("network.limits.max_streams_per_peer"),
+			Workers:              c.viper().GetInt("network.limits.workers"),
+		},
+		Retry: RetryConfig{
+			MaxAttempts:    c.viper().GetInt("network.retry.max_attempts"),
+			InitialBackoff: millis("network.retry.initial_backoff"),
+			MaxBackoff:     millis("network.retry.max_backoff"),
+			Multiplier:     c.viper().GetFloat64("network.retry.multiplier"),
+		},
+	}
+}
+
+// setNetworkDefaults sets default values of network settings.
+func setNetworkDefaults(v *viper.Viper) {
+	v.SetDefault("network.timeouts.stream", 0)
+	v.SetDefault("network.timeouts.idle", 0)
+	v.SetDefault("network.timeouts.keepalive", 0)
+	v.SetDefault("network.timeouts.keepalive_timeout", 20000)
+	v.SetDefault("network.timeouts.min_keepalive", 10000)
+	v.SetDefault("network.timeouts.max_reconnect_delay", 0)
+	v.SetDefault("network.timeouts.shutdown", 30000)
+	v.SetDefault("network.retry.max_attempts", 1)
+	v.SetDefault("network.retry.initial_backoff", 100)
+	v.SetDefault("network.retry.max_backoff", 5000)
+	v.SetDefault("network.retry.multiplier", 1.6)
+	v.SetDefault("network.limits.max_recv_msg_size", 4*1024*1024)
+	v.SetDefault("network.limits.max_send_msg_size", 4*1024*1024)
+}
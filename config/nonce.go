@@ -0,0 +1,46 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// NonceConfig holds settings of nonces that emmy server issues to clients in
+// protocols with CL credentials.
+type NonceConfig struct {
+	TTL    time.Duration // validity of nonces
+	Shared bool          // nonces are kept in the backend of section storage.nonces
+}
+
+// LoadNonceConfig returns settings of nonces from section nonces of the
+// configuration.
+func (c *Config) LoadNonceConfig() *NonceConfig {
+	return &NonceConfig{
+		TTL:    time.Duration(c.viper().GetInt("nonces.ttl")) * time.Second,
+		Shared: c.viper().GetBool("nonces.shared"),
+	}
+}
+
+// setNonceDefaults sets default values of nonce settings.
+func setNonceDefaults(v *viper.Viper) {
+	v.SetDefault("nonces.ttl", 300)
+	v.SetDefault("nonces.shared", false)
+}
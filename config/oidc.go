@@ -0,0 +1,58 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// OIDCConfig holds settings of the OpenID Connect bridge, which issues ID and access
+// tokens to relying parties for users authenticated by emmy server.
+type OIDCConfig struct {
+	Enabled  bool
+	Issuer   string        // issuer identifier, the URL where the bridge is reachable
+	Address  string        // address where the bridge listens for HTTPS requests
+	KeyFile  string        // EC P-256 private key in PEM format for signing tokens
+	TokenTTL time.Duration // validity of issued tokens
+	Audience string        // audience of ID tokens issued along with session keys, if any
+}
+
+// LoadOIDCConfig returns settings of the OpenID Connect bridge from section oidc
+// of the configuration. When no signing key is configured, an ephemeral key is
+// expected to be used.
+func (c *Config) LoadOIDCConfig() *OIDCConfig {
+	return &OIDCConfig{
+		Enabled:  c.viper().GetBool("oidc.enabled"),
+		Issuer:   c.viper().GetString("oidc.issuer"),
+		Address:  c.viper().GetString("oidc.address"),
+		KeyFile:  c.viper().GetString("oidc.key"),
+		TokenTTL: time.Duration(c.viper().GetInt("oidc.token_ttl")) * time.Second,
+		Audience: c.viper().GetString("oidc.audience"),
+	}
+}
+
+// setOIDCDefaults sets default values of the OpenID Connect bridge settings.
+func setOIDCDefaults(v *viper.Viper) {
+	v.SetDefault("oidc.enabled", false)
+	v.SetDefault("oidc.issuer", "https://localhost:8882")
+	v.SetDefault("oidc.address", ":8882")
+	v.SetDefault("oidc.token_ttl", 300)
+	v.SetDefault("oidc.audience", "")
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// PKIConfig holds settings of trusting keys of organizations through X.509
+// certificates.
+type PKIConfig struct {
+	Roots string            // path to the PEM file holding trusted root certificates
+	Certs map[string]string // paths to PEM files holding certificate chains, by organization name
+}
+
+// LoadPKIConfig returns settings of X.509 trust from section pki of the configuration.
+func (c *Config) LoadPKIConfig() *PKIConfig {
+	return &PKIConfig{
+		Roots: c.viper().GetString("pki.roots"),
+		Certs: c.viper().GetStringMapString("pki.certs"),
+	}
+}
+
+// setPKIDefaults sets default values of X.509 trust settings.
+func setPKIDefaults(v *viper.Viper) {
+	v.SetDefault("pki.roots", "")
+	v.SetDefault("pki.certs", map[string]string{})
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PolicyConfig is a policy that proofs of CL credentials need to satisfy to be
+// accepted, as configured under the policies section.
+type PolicyConfig struct {
+	Name       string
+	Issuers    []string // IDs of CL keys of accepted issuers, any when empty
+	Revealed   []string // attributes that need to be revealed
+	Predicates []*PredicateConfig
+	MaxAge     time.Duration // maximum time since issuance, not limited when 0
+	// backend of range proofs that need to be used (see cl.RangeProofBackend), any
+	// when empty
+	RangeProofs string
+}
+
+// PredicateConfig is a predicate about attribute Attr of a credential, with values
+// in their string form.
+type PredicateConfig struct {
+	Attr   string
+	Type   string
+	Values []string
+}
+
+// LoadPolicies returns policies from section policies of the configuration, which
+// maps names of policies to accepted issuers (issuers), attributes that need to be
+// revealed (revealed), predicates (predicates), the maximum age of credentials
+// (max_age) and the backend of range proofs (range_proofs). Predicates are given as
+// "ATTR TYPE [VALUE[,VALUE...]]". Policies are returned ordered by name.
+func (c *Config) LoadPolicies() ([]*PolicyConfig, error) {
+	entries := c.viper().GetStringMap("policies")
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	policies := make([]*PolicyConfig, len(names))
+	for i, name := range names {
+		prefix := "policies." + name + "."
+		p := &PolicyConfig{
+			Name:        name,
+			Issuers:     c.viper().GetStringSlice(prefix + "issuers"),
+			Revealed:    c.viper().GetStringSlice(prefix + "revealed"),
+			RangeProofs: c.viper().GetString(prefix + "range_proofs"),
+		}
+		for _, s := range c.viper().GetStringSlice(prefix + "predicates") {
+			pred, err := parsePredicateConfig(s)
+			if err != nil {
+				return nil, fmt.Errorf("policy %s: %v", name, err)
+			}
+			p.Predicates = append(p.Predicates, pred)
+		}
+		if s := c.viper().GetString(prefix + "max_age"); s != "" {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return nil, fmt.Errorf("max_age of policy %s: %v", name, err)
+			}
+			p.MaxAge = d
+		}
+		policies[i] = p
+	}
+
+	return policies, nil
+}
+
+// parsePredicateConfig parses predicate s, given as "ATTR TYPE [VALUE[,VALUE...]]".
+func parsePredicateConfig(s string) (*PredicateConfig, error) {
+	fields := strings.SplitN(strings.TrimSpace(s), " ", 3)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("predicate %q not given as ATTR TYPE [VALUES]", s)
+	}
+	p := &PredicateConfig{
+		Attr: fields[0],
+		Type: strings.TrimSpace(fields[1]),
+	}
+	if len(fields) == 3 {
+		for _, v := range strings.Split(fields[2], ",") {
+			p.Values = append(p.Values, strings.TrimSpace(v))
+		}
+	}
+	return p, nil
+}
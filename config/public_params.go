@@ -0,0 +1,44 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// PublicParamsConfig holds settings of the public parameters of the issuer that
+// emmy server gives to clients in a signed bundle.
+type PublicParamsConfig struct {
+	Enabled bool
+	KeyFile string // EC P-256 private key in PEM or JWK format for signing the bundle
+}
+
+// LoadPublicParamsConfig returns settings of public parameters from section
+// public_parameters of the configuration. When no signing key is configured, an
+// ephemeral key is expected to be used.
+func (c *Config) LoadPublicParamsConfig() *PublicParamsConfig {
+	return &PublicParamsConfig{
+		Enabled: c.viper().GetBool("public_parameters.enabled"),
+		KeyFile: c.viper().GetString("public_parameters.key"),
+	}
+}
+
+// setPublicParamsDefaults sets default values of the public parameters settings.
+func setPublicParamsDefaults(v *viper.Viper) {
+	v.SetDefault("public_parameters.enabled", false)
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// RecordingConfig holds settings of recording sessions of protocols served by the
+// server (see package record).
+type RecordingConfig struct {
+	Enabled    bool
+	Dir        string   // directory where sessions are saved
+	Methods    []string // names of streams to record, e.g. IssueCredential; all when empty
+	FailedOnly bool     // only save sessions that ended with an error
+}
+
+// LoadRecordingConfig returns settings of session recording from section recording
+// of the configuration.
+func (c *Config) LoadRecordingConfig() *RecordingConfig {
+	return &RecordingConfig{
+		Enabled:    c.viper().GetBool("recording.enabled"),
+		Dir:        c.viper().GetString("recording.dir"),
+		Methods:    c.viper().GetStringSlice("recording.methods"),
+		FailedOnly: c.viper().GetBool("recording.failed_only"),
+	}
+}
+
+// setRecordingDefaults sets default values of session recording settings.
+func setRecordingDefaults(v *viper.Viper) {
+	v.SetDefault("recording.enabled", false)
+	v.SetDefault("recording.dir", "/tmp/emmy-sessions")
+	v.SetDefault("recording.methods", []string{})
+	v.SetDefault("recording.failed_only", false)
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// RevocationConfig holds settings of revocation of CL credentials.
+type RevocationConfig struct {
+	Enabled     bool
+	Accumulator string // path to the file holding the accumulator and revoked credentials
+}
+
+// LoadRevocationConfig returns settings of revocation from section revocation of
+// the configuration. Unless configured otherwise, the accumulator is kept in the
+// testdata directory, next to the default CL keys.
+func (c *Config) LoadRevocationConfig() *RevocationConfig {
+	accumulator := c.viper().GetString("revocation.accumulator")
+	if accumulator == "" {
+		accumulator = filepath.Join(c.LoadTestdataDir(), "clAccumulator.gob")
+	}
+
+	return &RevocationConfig{
+		Enabled:     c.viper().GetBool("revocation.enabled"),
+		Accumulator: accumulator,
+	}
+}
+
+// setRevocationDefaults sets default values of revocation settings.
+func setRevocationDefaults(v *viper.Viper) {
+	v.SetDefault("revocation.enabled", false)
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// RNGSourceSystem is the source of randomness of the operating system (crypto/rand).
+const RNGSourceSystem = "system"
+
+// RNGConfig holds settings of the source of randomness of emmy server.
+type RNGConfig struct {
+	Source         string  // RNGSourceSystem or the path of a device of a hardware RNG
+	MinEntropy     float64 // assessed entropy of the source in bits per byte
+	PanicOnFailure bool    // panic rather than fail reads once a health test fails
+}
+
+// LoadRNGConfig returns settings of the source of randomness from section rng of the
+// configuration.
+func (c *Config) LoadRNGConfig() *RNGConfig {
+	return &RNGConfig{
+		Source:         c.viper().GetString("rng.source"),
+		MinEntropy:     c.viper().GetFloat64("rng.min_entropy"),
+		PanicOnFailure: c.viper().GetBool("rng.panic_on_failure"),
+	}
+}
+
+// setRNGDefaults sets default values of settings of the source of randomness.
+func setRNGDefaults(v *viper.Viper) {
+	v.SetDefault("rng.source", RNGSourceSystem)
+	v.SetDefault("rng.min_entropy", 8)
+	v.SetDefault("rng.panic_on_failure", false)
+}
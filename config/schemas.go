@@ -0,0 +1,74 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+// CredentialSchema is a named and versioned structure of CL credentials.
+type CredentialSchema struct {
+	Name    string
+	Version string
+	// Structure holds attributes in the form returned by LoadCredentialStructure.
+	Structure map[string]interface{}
+}
+
+// LoadCredentialSchema returns the name and version of the credential structure
+// given by attributes, from section credential_schema of the configuration.
+func (c *Config) LoadCredentialSchema() (string, string) {
+	v := c.viper()
+	return v.GetString("credential_schema.name"), v.GetString("credential_schema.version")
+}
+
+// LoadCredentialSchemas returns credential structures from section credential_schemas
+// of the configuration, besides the one given by attributes. Each entry holds the name,
+// version and attributes (in the same form as attributes) of a structure, and schemas
+// are returned in the order of keys of the entries.
+func (c *Config) LoadCredentialSchemas() ([]*CredentialSchema, error) {
+	var keys []string
+	for k := range c.viper().GetStringMap("credential_schemas") {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	schemas := make([]*CredentialSchema, len(keys))
+	for i, k := range keys {
+		prefix := "credential_schemas." + k
+		attrs := c.viper().GetStringMapString(prefix + ".attributes")
+		if len(attrs) == 0 {
+			return nil, fmt.Errorf("credential schema %s has no attributes", k)
+		}
+		schemas[i] = &CredentialSchema{
+			Name:      c.viper().GetString(prefix + ".name"),
+			Version:   c.viper().GetString(prefix + ".version"),
+			Structure: parseCredentialStructure(attrs),
+		}
+	}
+
+	return schemas, nil
+}
+
+// setSchemaDefaults sets default values of credential schema settings.
+func setSchemaDefaults(v *viper.Viper) {
+	v.SetDefault("credential_schema.name", "default")
+	v.SetDefault("credential_schema.version", "1.0")
+}
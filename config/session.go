@@ -0,0 +1,90 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Formats of session keys.
+const (
+	SessionKeyFormatRandom = "random"
+	SessionKeyFormatJWT    = "jwt"
+)
+
+// SessionConfig holds settings of session keys that emmy server issues to
+// successfully authenticated clients.
+type SessionConfig struct {
+	Format      string // SessionKeyFormatRandom or SessionKeyFormatJWT
+	JWT         SessionJWTConfig
+	Store       SessionStoreConfig
+	MaxLifetime time.Duration // how long after authentication sessions can be refreshed
+}
+
+// SessionStoreConfig holds settings of the store of sessions, whose backend is
+// configured in section storage.sessions (see LoadStorageConfig).
+type SessionStoreConfig struct {
+	Enabled bool
+	TTL     time.Duration // validity of random session keys
+}
+
+// SessionJWTConfig holds settings of session keys in JWT form.
+type SessionJWTConfig struct {
+	Issuer      string        // iss claim
+	Audience    string        // aud claim, omitted when empty
+	KeyFile     string        // EC P-256 private key in PEM format for signing session keys
+	TTL         time.Duration // validity of session keys
+	JWKSAddress string        // address where the JWKS endpoint is served over HTTPS
+	Claims      bool          // whether session keys carry claims of their sessions
+}
+
+// LoadSessionConfig returns settings of session keys from section session of the
+// configuration. Byte length of random session keys is read separately, see
+// LoadSessionKeyMinByteLen.
+func (c *Config) LoadSessionConfig() *SessionConfig {
+	return &SessionConfig{
+		Format: c.viper().GetString("session.format"),
+		JWT: SessionJWTConfig{
+			Issuer:      c.viper().GetString("session.jwt.issuer"),
+			Audience:    c.viper().GetString("session.jwt.audience"),
+			KeyFile:     c.viper().GetString("session.jwt.key"),
+			TTL:         time.Duration(c.viper().GetInt("session.jwt.ttl")) * time.Second,
+			JWKSAddress: c.viper().GetString("session.jwt.jwks_address"),
+			Claims:      c.viper().GetBool("session.jwt.claims"),
+		},
+		Store: SessionStoreConfig{
+			Enabled: c.viper().GetBool("session.store.enabled"),
+			TTL:     time.Duration(c.viper().GetInt("session.store.ttl")) * time.Second,
+		},
+		MaxLifetime: time.Duration(c.viper().GetInt("session.max_lifetime")) * time.Second,
+	}
+}
+
+// setSessionDefaults sets default values of session key settings.
+func setSessionDefaults(v *viper.Viper) {
+	v.SetDefault("session.format", SessionKeyFormatRandom)
+	v.SetDefault("session.jwt.issuer", "emmy")
+	v.SetDefault("session.jwt.ttl", 3600)
+	v.SetDefault("session.jwt.jwks_address", ":8883")
+	v.SetDefault("session.jwt.claims", false)
+	v.SetDefault("session.store.enabled", false)
+	v.SetDefault("session.store.ttl", 3600)
+	v.SetDefault("session.max_lifetime", 0)
+}
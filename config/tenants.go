@@ -0,0 +1,65 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/viper"
+)
+
+// TenantConfig names a tenant of emmy server, an issuer or verifier with its own
+// configuration.
+type TenantConfig struct {
+	ID   string
+	File string // configuration file of the tenant
+}
+
+// LoadTenants returns tenants from section tenants of the configuration, which maps
+// IDs of tenants to their configuration files. Tenants are returned ordered by ID.
+func (c *Config) LoadTenants() []*TenantConfig {
+	files := c.viper().GetStringMapString("tenants")
+	ids := make([]string, 0, len(files))
+	for id := range files {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	tenants := make([]*TenantConfig, len(ids))
+	for i, id := range ids {
+		tenants[i] = &TenantConfig{
+			ID:   id,
+			File: files[id],
+		}
+	}
+	return tenants
+}
+
+// NewFromFile returns a new configuration holding default values, overridden by
+// those read from file (in YAML or JSON, as given by its extension). Like a
+// configuration created with New, it is independent of the default one.
+func NewFromFile(file string) (*Config, error) {
+	v := viper.New()
+	setDefaults(v)
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("cannot read configuration file: %s", err)
+	}
+	return &Config{v: v}, nil
+}
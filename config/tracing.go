@@ -0,0 +1,47 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// TracingConfig holds settings of the export of OpenTelemetry spans of protocol
+// executions.
+type TracingConfig struct {
+	Enabled     bool
+	File        string
+	SampleRatio float64
+}
+
+// LoadTracingConfig returns tracing settings from section tracing of the
+// configuration.
+func (c *Config) LoadTracingConfig() *TracingConfig {
+	return &TracingConfig{
+		Enabled:     c.viper().GetBool("tracing.enabled"),
+		File:        c.viper().GetString("tracing.file"),
+		SampleRatio: c.viper().GetFloat64("tracing.sample_ratio"),
+	}
+}
+
+// setTracingDefaults sets default values of tracing settings.
+func setTracingDefaults(v *viper.Viper) {
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.file", "")
+	v.SetDefault("tracing.sample_ratio", 1.0)
+}
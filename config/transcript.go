@@ -0,0 +1,44 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// TranscriptConfig holds settings of exporting transcripts of verifications of
+// credential proofs (see package transcript).
+type TranscriptConfig struct {
+	Enabled bool
+	Dir     string // directory where transcripts are saved
+}
+
+// LoadTranscriptConfig returns settings of transcript export from section transcripts
+// of the configuration.
+func (c *Config) LoadTranscriptConfig() *TranscriptConfig {
+	return &TranscriptConfig{
+		Enabled: c.viper().GetBool("transcripts.enabled"),
+		Dir:     c.viper().GetString("transcripts.dir"),
+	}
+}
+
+// setTranscriptDefaults sets default values of transcript export settings.
+func setTranscriptDefaults(v *viper.Viper) {
+	v.SetDefault("transcripts.enabled", false)
+	v.SetDefault("transcripts.dir", "/tmp/emmy-transcripts")
+}
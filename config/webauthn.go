@@ -0,0 +1,53 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// WebAuthnConfig holds settings of binding CL credentials to WebAuthn authenticators
+// of users' devices.
+type WebAuthnConfig struct {
+	Enabled          bool
+	RPID             string // relying party identifier, usually the domain of the server
+	Origin           string // origin of clients making registrations and assertions
+	Attribute        string // known string attribute of credentials holding the binding
+	UserVerification bool   // whether authenticators need to verify users, not only their presence
+}
+
+// LoadWebAuthnConfig returns settings of device binding from section webauthn
+// of the configuration.
+func (c *Config) LoadWebAuthnConfig() *WebAuthnConfig {
+	return &WebAuthnConfig{
+		Enabled:          c.viper().GetBool("webauthn.enabled"),
+		RPID:             c.viper().GetString("webauthn.rp_id"),
+		Origin:           c.viper().GetString("webauthn.origin"),
+		Attribute:        c.viper().GetString("webauthn.attribute"),
+		UserVerification: c.viper().GetBool("webauthn.user_verification"),
+	}
+}
+
+// setWebAuthnDefaults sets default values of device binding settings.
+func setWebAuthnDefaults(v *viper.Viper) {
+	v.SetDefault("webauthn.enabled", false)
+	v.SetDefault("webauthn.rp_id", "localhost")
+	v.SetDefault("webauthn.origin", "https://localhost")
+	v.SetDefault("webauthn.attribute", "DeviceBinding")
+	v.SetDefault("webauthn.user_verification", false)
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package bbs implements BBS+ signatures on blocks of messages over the pairing-friendly
+// curve BLS12-381, as described in "Anonymous Attestation Using the Strong Diffie Hellman
+// Assumption Revisited" (Camenisch, Drijvers, Lehmann). Like CL signatures (see package
+// cl), they can be issued on messages hidden from the organization, and users can prove
+// possession of a signature revealing only some of the messages. As the proofs consist
+// of a few group elements and elements of Z_q, they are much shorter than proofs of CL
+// credentials.
+package bbs
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/pairing"
+)
+
+// domain separates hashing to the curve of BBS+ bases from other uses of the curve.
+var domain = []byte("EMMY-BBS-BLS12381G1_XMD:SHA-256_SSWU_RO_")
+
+// GroupOrder returns the order q of the groups of the pairing. Messages that are
+// signed need to be elements of Z_q.
+func GroupOrder() *big.Int {
+	return new(big.Int).Set(pairing.Q)
+}
+
+// checkMsgs checks that there are n messages and that all of them are in Z_q.
+func checkMsgs(msgs []*big.Int, n int) error {
+	if len(msgs) != n {
+		return fmt.Errorf("expected %d messages, got %d", n, len(msgs))
+	}
+	q := GroupOrder()
+	for i, m := range msgs {
+		if m == nil || m.Sign() < 0 || m.Cmp(q) >= 0 {
+			return fmt.Errorf("message %d is not in Z_q", i)
+		}
+	}
+	return nil
+}
+
+// allSet reports whether none of xs is nil.
+func allSet(xs ...*big.Int) bool {
+	for _, x := range xs {
+		if x == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// randomScalar returns a random non-zero element of Z_q.
+func randomScalar() *big.Int {
+	q := GroupOrder()
+	for {
+		r := common.GetRandomInt(q)
+		if r.Sign() != 0 {
+			return r
+		}
+	}
+}
+
+// response returns the response alpha + c * w mod q of a Schnorr-like proof of
+// knowledge of w.
+func response(alpha, c, w *big.Int) *big.Int {
+	r := new(big.Int).Mul(c, w)
+	r.Add(r, alpha)
+	return r.Mod(r, GroupOrder())
+}
+
+// challenge returns the Fiat-Shamir challenge computed from elements of G1 (as
+// integers of their encodings) and integers.
+func challenge(elements []*pairing.G1Element, ints ...*big.Int) *big.Int {
+	numbers := make([]*big.Int, 0, len(elements)+len(ints))
+	for _, e := range elements {
+		numbers = append(numbers, new(big.Int).SetBytes(e.Bytes()))
+	}
+	numbers = append(numbers, ints...)
+	c := common.Hash(numbers...)
+	return c.Mod(c, GroupOrder())
+}
@@ -0,0 +1,142 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bbs
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature(t *testing.T) {
+	keys, err := GenerateKeyPair(3)
+	require.NoError(t, err)
+
+	msgs := []*big.Int{big.NewInt(7), big.NewInt(42), big.NewInt(0)}
+	sig, err := Sign(keys, msgs)
+	require.NoError(t, err)
+	assert.NoError(t, sig.Verify(keys.Pub, msgs))
+
+	msgs[1] = big.NewInt(43)
+	assert.Error(t, sig.Verify(keys.Pub, msgs))
+	assert.Error(t, sig.Verify(keys.Pub, msgs[:2]))
+
+	_, err = Sign(keys, []*big.Int{big.NewInt(1), big.NewInt(2), GroupOrder()})
+	assert.Error(t, err)
+}
+
+func TestIssueAndProve(t *testing.T) {
+	org, err := NewOrg(4)
+	require.NoError(t, err)
+
+	known := []*big.Int{big.NewInt(25), new(big.Int).SetBytes([]byte("Jack"))}
+	hidden := []*big.Int{big.NewInt(123456789), big.NewInt(1)}
+	credMgr, err := NewCredManager(org.Keys.Pub, known, hidden)
+	require.NoError(t, err)
+
+	credReq, err := credMgr.GetCredRequest(org.GetCredIssueNonce())
+	require.NoError(t, err)
+	blindSig, err := org.IssueCred(credReq)
+	require.NoError(t, err)
+	sig, err := credMgr.Unblind(blindSig)
+	require.NoError(t, err)
+
+	// the proof reveals a known and a hidden message
+	nonce := org.GetProveCredNonce()
+	proof, err := credMgr.BuildProof(sig, []int{1, 3}, nonce)
+	require.NoError(t, err)
+	assert.Equal(t, []*big.Int{known[1], hidden[1]}, proof.RevealedMsgs)
+	verified, err := org.ProveCred(proof)
+	require.NoError(t, err)
+	assert.True(t, verified)
+
+	// the proof is bound to the nonce
+	org.GetProveCredNonce()
+	verified, _ = org.ProveCred(proof)
+	assert.False(t, verified)
+
+	// revealed messages cannot be changed
+	nonce = org.GetProveCredNonce()
+	proof, err = credMgr.BuildProof(sig, []int{0}, nonce)
+	require.NoError(t, err)
+	proof.RevealedMsgs[0] = big.NewInt(18)
+	verified, _ = org.ProveCred(proof)
+	assert.False(t, verified)
+
+	// nothing needs to be revealed
+	org.GetProveCredNonce()
+	proof, err = credMgr.BuildProof(sig, nil, org.proveCredNonce)
+	require.NoError(t, err)
+	verified, err = org.ProveCred(proof)
+	require.NoError(t, err)
+	assert.True(t, verified)
+
+	_, err = credMgr.BuildProof(sig, []int{2, 1}, nonce)
+	assert.Error(t, err)
+}
+
+func TestIssueCredInvalidRequest(t *testing.T) {
+	org, err := NewOrg(2)
+	require.NoError(t, err)
+	credMgr, err := NewCredManager(org.Keys.Pub, []*big.Int{big.NewInt(1)},
+		[]*big.Int{big.NewInt(2)})
+	require.NoError(t, err)
+
+	credReq, err := credMgr.GetCredRequest(org.GetCredIssueNonce())
+	require.NoError(t, err)
+	org.GetCredIssueNonce()
+	_, err = org.IssueCred(credReq)
+	assert.Error(t, err, "request bound to another nonce")
+
+	credReq, err = credMgr.GetCredRequest(org.GetCredIssueNonce())
+	require.NoError(t, err)
+	credReq.KnownMsgs = []*big.Int{big.NewInt(3)}
+	_, err = org.IssueCred(credReq)
+	assert.Error(t, err, "known messages changed")
+}
+
+func TestKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emmy-bbs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	pubPath := filepath.Join(dir, "bbsPubKey.gob")
+	secPath := filepath.Join(dir, "bbsSecKey.gob")
+
+	org, err := LoadOrCreateOrg(pubPath, secPath, 3)
+	require.NoError(t, err)
+	loaded, err := LoadOrCreateOrg(pubPath, secPath, 3)
+	require.NoError(t, err)
+	assert.Equal(t, org.Keys.Sec.X, loaded.Keys.Sec.X)
+	assert.True(t, org.Keys.Pub.H[2].Equals(loaded.Keys.Pub.H[2]))
+	_, err = LoadOrCreateOrg(pubPath, secPath, 4)
+	assert.Error(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(org.Keys.Pub))
+	pub := new(PubKey)
+	require.NoError(t, gob.NewDecoder(&buf).Decode(pub))
+	assert.Equal(t, 3, pub.MsgCount())
+	assert.True(t, org.Keys.Pub.H0.Equals(pub.H0))
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bbs
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/pairing"
+)
+
+// CredManager manages messages of the user's credential. Known messages are revealed
+// to the organization when the credential is issued, while the organization signs
+// hidden messages (which follow known ones in the block) without learning them.
+type CredManager struct {
+	Pub    *PubKey
+	Known  []*big.Int
+	Hidden []*big.Int
+	// s is the randomness of the commitment to hidden messages in the credential request
+	s *big.Int
+}
+
+func NewCredManager(pub *PubKey, known, hidden []*big.Int) (*CredManager, error) {
+	m := &CredManager{
+		Pub:    pub,
+		Known:  known,
+		Hidden: hidden,
+	}
+	if err := checkMsgs(m.msgs(), pub.MsgCount()); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// msgs returns all messages of the credential.
+func (m *CredManager) msgs() []*big.Int {
+	msgs := make([]*big.Int, 0, len(m.Known)+len(m.Hidden))
+	msgs = append(msgs, m.Known...)
+	return append(msgs, m.Hidden...)
+}
+
+// CredRequest is a request for a credential on known messages and hidden messages
+// committed to in U = h0^s * prod h_i^m_i. The request includes a proof of knowledge
+// of the opening of U.
+type CredRequest struct {
+	KnownMsgs    []*big.Int
+	U            *pairing.G1Element
+	Challenge    *big.Int
+	SResponse    *big.Int
+	MsgResponses []*big.Int
+}
+
+// hiddenBases returns h0 and the bases of hidden messages.
+func (k *PubKey) hiddenBases(known int) []*pairing.G1Element {
+	return append([]*pairing.G1Element{k.H0}, k.H[known:]...)
+}
+
+// GetCredRequest returns the request for a credential, bound to nonceOrg.
+func (m *CredManager) GetCredRequest(nonceOrg *big.Int) (*CredRequest, error) {
+	m.s = randomScalar()
+	bases := m.Pub.hiddenBases(len(m.Known))
+	secrets := append([]*big.Int{m.s}, m.Hidden...)
+	g1 := pairing.NewG1()
+	U := g1.MultiExp(bases, secrets)
+
+	alphas := make([]*big.Int, len(secrets))
+	for i := range alphas {
+		alphas[i] = randomScalar()
+	}
+	t := g1.MultiExp(bases, alphas)
+	c := credReqChallenge(U, t, m.Known, nonceOrg)
+
+	responses := make([]*big.Int, len(secrets))
+	for i, alpha := range alphas {
+		responses[i] = response(alpha, c, secrets[i])
+	}
+
+	return &CredRequest{
+		KnownMsgs:    m.Known,
+		U:            U,
+		Challenge:    c,
+		SResponse:    responses[0],
+		MsgResponses: responses[1:],
+	}, nil
+}
+
+// credReqChallenge returns the challenge of the proof in the credential request.
+func credReqChallenge(U, t *pairing.G1Element, known []*big.Int, nonceOrg *big.Int) *big.Int {
+	ints := make([]*big.Int, 0, len(known)+1)
+	ints = append(ints, known...)
+	return challenge([]*pairing.G1Element{U, t}, append(ints, nonceOrg)...)
+}
+
+// Unblind returns the signature on all messages of the credential from the signature
+// issued on the last credential request, and verifies it.
+func (m *CredManager) Unblind(blindSig *Signature) (*Signature, error) {
+	if m.s == nil {
+		return nil, fmt.Errorf("no credential was requested")
+	}
+	s := new(big.Int).Add(m.s, blindSig.S)
+	sig := &Signature{
+		A: blindSig.A,
+		E: blindSig.E,
+		S: s.Mod(s, GroupOrder()),
+	}
+	if err := sig.Verify(m.Pub, m.msgs()); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// BuildProof builds a proof of possession of sig, bound to nonceOrg, revealing
+// messages at indices revealed (which need to be sorted).
+func (m *CredManager) BuildProof(sig *Signature, revealed []int,
+	nonceOrg *big.Int) (*Proof, error) {
+	return NewProof(m.Pub, sig, m.msgs(), revealed, nonceOrg)
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bbs
+
+import (
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/xlab-si/emmy/crypto/pairing"
+)
+
+// g2Size is the byte length of encoded elements of G2.
+const g2Size = 96
+
+type KeyPair struct {
+	Sec *SecKey
+	Pub *PubKey
+}
+
+// SecKey is the secret key x of the organization.
+type SecKey struct {
+	X *big.Int
+}
+
+// PubKey is the public key W = g2^x of the organization, along with bases H0 (for the
+// randomness of signatures) and H (one for each message of signed blocks).
+type PubKey struct {
+	W  *pairing.G2Element
+	H0 *pairing.G1Element
+	H  []*pairing.G1Element
+}
+
+// GenerateKeyPair generates keys for signing blocks of l messages.
+func GenerateKeyPair(l int) (*KeyPair, error) {
+	if l < 1 {
+		return nil, fmt.Errorf("blocks need to contain at least one message")
+	}
+
+	x := randomScalar()
+	pub, err := NewPubKey(pairing.NewG2().ExpBaseG(x), l)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyPair{
+		Sec: &SecKey{X: x},
+		Pub: pub,
+	}, nil
+}
+
+// NewPubKey returns the public key W for signing blocks of l messages. Bases are
+// hashed to the curve from W, thus nobody knows discrete logarithms between them.
+func NewPubKey(w *pairing.G2Element, l int) (*PubKey, error) {
+	g1 := pairing.NewG1()
+	wBytes := w.Bytes()
+	bases := make([]*pairing.G1Element, l+1)
+	for i := range bases {
+		msg := make([]byte, len(wBytes)+4)
+		copy(msg, wBytes)
+		binary.BigEndian.PutUint32(msg[len(wBytes):], uint32(i))
+		b, err := g1.HashToElement(msg, domain)
+		if err != nil {
+			return nil, err
+		}
+		bases[i] = b
+	}
+
+	return &PubKey{
+		W:  w,
+		H0: bases[0],
+		H:  bases[1:],
+	}, nil
+}
+
+// MsgCount returns the number of messages in blocks signed with the key.
+func (k *PubKey) MsgCount() int {
+	return len(k.H)
+}
+
+// MarshalBinary encodes the key as W along with the number of messages, from which
+// the bases are derived.
+func (k *PubKey) MarshalBinary() ([]byte, error) {
+	data := make([]byte, g2Size+4)
+	copy(data, k.W.Bytes())
+	binary.BigEndian.PutUint32(data[g2Size:], uint32(len(k.H)))
+	return data, nil
+}
+
+// UnmarshalBinary decodes the key encoded by MarshalBinary.
+func (k *PubKey) UnmarshalBinary(data []byte) error {
+	if len(data) != g2Size+4 {
+		return fmt.Errorf("invalid length of BBS+ public key")
+	}
+	w, err := pairing.NewG2ElementFromBytes(data[:g2Size])
+	if err != nil {
+		return err
+	}
+	l := int(binary.BigEndian.Uint32(data[g2Size:]))
+	if l < 1 {
+		return fmt.Errorf("blocks need to contain at least one message")
+	}
+	pub, err := NewPubKey(w, l)
+	if err != nil {
+		return err
+	}
+	*k = *pub
+	return nil
+}
+
+// ReadPubKey reads the public key from the gob file written by LoadOrCreateOrg.
+func ReadPubKey(filePath string) (*PubKey, error) {
+	pub := new(PubKey)
+	if err := readGob(filePath, pub); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+func writeGob(filePath string, object interface{}) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return gob.NewEncoder(file).Encode(object)
+}
+
+func readGob(filePath string, object interface{}) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return gob.NewDecoder(file).Decode(object)
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bbs
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/xlab-si/emmy/crypto/pairing"
+)
+
+// Org is the organization issuing BBS+ credentials and verifying proofs of their
+// possession.
+type Org struct {
+	Keys *KeyPair
+
+	credIssueNonce *big.Int
+	proveCredNonce *big.Int
+}
+
+// NewOrg returns the organization with fresh keys for credentials of l messages.
+func NewOrg(l int) (*Org, error) {
+	keys, err := GenerateKeyPair(l)
+	if err != nil {
+		return nil, err
+	}
+	return &Org{Keys: keys}, nil
+}
+
+// LoadOrg returns the organization with keys read from gob files.
+func LoadOrg(pubKeyPath, secKeyPath string) (*Org, error) {
+	pub, err := ReadPubKey(pubKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error when reading BBS+ public key: %v", err)
+	}
+	sec := new(SecKey)
+	if err := readGob(secKeyPath, sec); err != nil {
+		return nil, fmt.Errorf("error when reading BBS+ secret key: %v", err)
+	}
+	return &Org{
+		Keys: &KeyPair{
+			Sec: sec,
+			Pub: pub,
+		},
+	}, nil
+}
+
+// LoadOrCreateOrg loads the organization from key files, or generates keys for
+// credentials of l messages and writes them to these files if they do not exist yet.
+func LoadOrCreateOrg(pubKeyPath, secKeyPath string, l int) (*Org, error) {
+	_, errPub := os.Stat(pubKeyPath)
+	_, errSec := os.Stat(secKeyPath)
+	if !os.IsNotExist(errPub) || !os.IsNotExist(errSec) {
+		org, err := LoadOrg(pubKeyPath, secKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		if org.Keys.Pub.MsgCount() != l {
+			return nil, fmt.Errorf("BBS+ keys are for credentials of %d messages, not %d",
+				org.Keys.Pub.MsgCount(), l)
+		}
+		return org, nil
+	}
+
+	org, err := NewOrg(l)
+	if err != nil {
+		return nil, fmt.Errorf("error when generating BBS+ org: %v", err)
+	}
+	if err := writeGob(pubKeyPath, org.Keys.Pub); err != nil {
+		return nil, err
+	}
+	if err := writeGob(secKeyPath, org.Keys.Sec); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+// GetCredIssueNonce returns a fresh nonce that the next credential request needs to
+// be bound to.
+func (o *Org) GetCredIssueNonce() *big.Int {
+	o.credIssueNonce = randomScalar()
+	return o.credIssueNonce
+}
+
+// IssueCred verifies the proof of knowledge of hidden messages in the credential
+// request and signs the block of known and hidden messages. The returned signature
+// needs to be completed by the user (see CredManager.Unblind).
+func (o *Org) IssueCred(req *CredRequest) (*Signature, error) {
+	if o.credIssueNonce == nil {
+		return nil, fmt.Errorf("no nonce was issued")
+	}
+	pub := o.Keys.Pub
+	if req.U == nil || !allSet(req.Challenge, req.SResponse) ||
+		!allSet(req.MsgResponses...) {
+		return nil, fmt.Errorf("incomplete credential request")
+	}
+	known := len(req.KnownMsgs)
+	if err := checkMsgs(req.KnownMsgs, known); err != nil {
+		return nil, err
+	}
+	if known+len(req.MsgResponses) != pub.MsgCount() {
+		return nil, fmt.Errorf("expected %d messages, got %d", pub.MsgCount(),
+			known+len(req.MsgResponses))
+	}
+
+	g1 := pairing.NewG1()
+	bases := append(pub.hiddenBases(known), req.U)
+	exps := append([]*big.Int{req.SResponse}, req.MsgResponses...)
+	t := g1.MultiExp(bases, append(exps, new(big.Int).Neg(req.Challenge)))
+	c := credReqChallenge(req.U, t, req.KnownMsgs, o.credIssueNonce)
+	if c.Cmp(req.Challenge) != 0 {
+		return nil, fmt.Errorf("proof of knowledge of hidden messages failed")
+	}
+
+	s := randomScalar()
+	b := g1.Mul(pub.b(s, indices(known), req.KnownMsgs), req.U)
+	A, e := o.Keys.Sec.sign(b)
+
+	return &Signature{
+		A: A,
+		E: e,
+		S: s,
+	}, nil
+}
+
+// GetProveCredNonce returns a fresh nonce that the next proof of possession of a
+// credential needs to be bound to.
+func (o *Org) GetProveCredNonce() *big.Int {
+	o.proveCredNonce = randomScalar()
+	return o.proveCredNonce
+}
+
+// ProveCred verifies the proof of possession of a credential.
+func (o *Org) ProveCred(proof *Proof) (bool, error) {
+	if o.proveCredNonce == nil {
+		return false, fmt.Errorf("no nonce was issued")
+	}
+	if err := proof.Verify(o.Keys.Pub, o.proveCredNonce); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
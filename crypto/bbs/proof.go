@@ -0,0 +1,207 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bbs
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/pairing"
+)
+
+// Proof is a zero-knowledge proof of possession of a signature (A, e, s) on a block of
+// messages, revealing messages at indices Revealed. The signature is randomized into
+// A' = A^r1, Abar = A'^(-e) * b^r1 and d = b^r1 * h0^(-r2), where b = A^(x+e), and
+// the proof shows knowledge of e, r2, r3 = 1/r1, s' = s - r2 * r3 and hidden messages
+// such that:
+//
+//	Abar / d = A'^(-e) * h0^r2
+//	g1 * prod_{i revealed} h_i^m_i = d^r3 * h0^(-s') * prod_{i hidden} h_i^(-m_i)
+//
+// Additionally, e(A', W) = e(Abar, g2) shows that A' is a valid randomized signature.
+type Proof struct {
+	APrime     *pairing.G1Element
+	ABar       *pairing.G1Element
+	D          *pairing.G1Element
+	Challenge  *big.Int
+	EResponse  *big.Int
+	R2Response *big.Int
+	R3Response *big.Int
+	SResponse  *big.Int
+	// MsgResponses are responses for hidden messages, in the order of their indices
+	MsgResponses []*big.Int
+	Revealed     []int
+	RevealedMsgs []*big.Int
+}
+
+// hiddenIndices checks that revealed are sorted indices of a block of l messages and
+// returns the indices of the remaining (hidden) messages.
+func hiddenIndices(revealed []int, l int) ([]int, error) {
+	var hidden []int
+	next := 0
+	for i, ind := range revealed {
+		if ind < 0 || ind >= l || (i > 0 && ind <= revealed[i-1]) {
+			return nil, fmt.Errorf("invalid indices of revealed messages")
+		}
+		for ; next < ind; next++ {
+			hidden = append(hidden, next)
+		}
+		next = ind + 1
+	}
+	for ; next < l; next++ {
+		hidden = append(hidden, next)
+	}
+	return hidden, nil
+}
+
+// NewProof builds a proof of possession of sig on msgs, bound to nonceOrg, which
+// reveals messages at sorted indices revealed.
+func NewProof(pub *PubKey, sig *Signature, msgs []*big.Int, revealed []int,
+	nonceOrg *big.Int) (*Proof, error) {
+	if err := checkMsgs(msgs, pub.MsgCount()); err != nil {
+		return nil, err
+	}
+	hidden, err := hiddenIndices(revealed, len(msgs))
+	if err != nil {
+		return nil, err
+	}
+	revealedMsgs := make([]*big.Int, len(revealed))
+	for i, ind := range revealed {
+		revealedMsgs[i] = msgs[ind]
+	}
+
+	q := GroupOrder()
+	g1 := pairing.NewG1()
+	r1, r2 := randomScalar(), randomScalar()
+	r3 := new(big.Int).ModInverse(r1, q)
+
+	bR1 := g1.Exp(pub.b(sig.S, indices(len(msgs)), msgs), r1)
+	aPrime := g1.Exp(sig.A, r1)
+	aBar := g1.Mul(g1.Exp(aPrime, new(big.Int).Neg(sig.E)), bR1)
+	d := g1.Mul(g1.Exp(pub.H0, new(big.Int).Neg(r2)), bR1)
+	sPrime := new(big.Int).Mul(r2, r3)
+	sPrime.Sub(sig.S, sPrime)
+	sPrime.Mod(sPrime, q)
+
+	alphaE, alphaR2, alphaR3, alphaS := randomScalar(), randomScalar(), randomScalar(),
+		randomScalar()
+	t1 := g1.MultiExp([]*pairing.G1Element{aPrime, pub.H0},
+		[]*big.Int{new(big.Int).Neg(alphaE), alphaR2})
+
+	bases := []*pairing.G1Element{d, pub.H0}
+	exps := []*big.Int{alphaR3, new(big.Int).Neg(alphaS)}
+	alphaMsgs := make([]*big.Int, len(hidden))
+	for i, ind := range hidden {
+		alphaMsgs[i] = randomScalar()
+		bases = append(bases, pub.H[ind])
+		exps = append(exps, new(big.Int).Neg(alphaMsgs[i]))
+	}
+	t2 := g1.MultiExp(bases, exps)
+
+	c := proofChallenge(aPrime, aBar, d, t1, t2, revealed, revealedMsgs, nonceOrg)
+
+	msgResponses := make([]*big.Int, len(hidden))
+	for i, ind := range hidden {
+		msgResponses[i] = response(alphaMsgs[i], c, msgs[ind])
+	}
+
+	return &Proof{
+		APrime:       aPrime,
+		ABar:         aBar,
+		D:            d,
+		Challenge:    c,
+		EResponse:    response(alphaE, c, sig.E),
+		R2Response:   response(alphaR2, c, r2),
+		R3Response:   response(alphaR3, c, r3),
+		SResponse:    response(alphaS, c, sPrime),
+		MsgResponses: msgResponses,
+		Revealed:     revealed,
+		RevealedMsgs: revealedMsgs,
+	}, nil
+}
+
+// proofChallenge returns the challenge of the proof of possession of a signature.
+func proofChallenge(aPrime, aBar, d, t1, t2 *pairing.G1Element, revealed []int,
+	revealedMsgs []*big.Int, nonceOrg *big.Int) *big.Int {
+	ints := make([]*big.Int, 0, 2*len(revealed)+1)
+	for _, ind := range revealed {
+		ints = append(ints, big.NewInt(int64(ind)))
+	}
+	ints = append(ints, revealedMsgs...)
+	ints = append(ints, nonceOrg)
+	return challenge([]*pairing.G1Element{aPrime, aBar, d, t1, t2}, ints...)
+}
+
+// Verify checks the proof of possession of a signature under pub, bound to nonceOrg.
+func (p *Proof) Verify(pub *PubKey, nonceOrg *big.Int) error {
+	if p.APrime == nil || p.ABar == nil || p.D == nil || !allSet(p.Challenge,
+		p.EResponse, p.R2Response, p.R3Response, p.SResponse) ||
+		!allSet(p.MsgResponses...) {
+		return fmt.Errorf("incomplete proof")
+	}
+	hidden, err := hiddenIndices(p.Revealed, pub.MsgCount())
+	if err != nil {
+		return err
+	}
+	if err := checkMsgs(p.RevealedMsgs, len(p.Revealed)); err != nil {
+		return err
+	}
+	if len(p.MsgResponses) != len(hidden) {
+		return fmt.Errorf("expected responses for %d hidden messages, got %d",
+			len(hidden), len(p.MsgResponses))
+	}
+
+	g1 := pairing.NewG1()
+	g2 := pairing.NewG2()
+	if p.APrime.IsIdentity() || !pairing.PairingProductIsOne(
+		[]*pairing.G1Element{p.APrime, g1.Inv(p.ABar)},
+		[]*pairing.G2Element{pub.W, g2.ExpBaseG(big.NewInt(1))}) {
+		return fmt.Errorf("invalid randomized signature")
+	}
+
+	negC := new(big.Int).Neg(p.Challenge)
+	x1 := g1.Mul(p.ABar, g1.Inv(p.D))
+	t1 := g1.MultiExp([]*pairing.G1Element{p.APrime, pub.H0, x1},
+		[]*big.Int{new(big.Int).Neg(p.EResponse), p.R2Response, negC})
+
+	x2 := g1.Mul(g1.ExpBaseG(big.NewInt(1)),
+		g1.MultiExp(revealedBases(pub, p.Revealed), p.RevealedMsgs))
+	bases := []*pairing.G1Element{p.D, pub.H0, x2}
+	exps := []*big.Int{p.R3Response, new(big.Int).Neg(p.SResponse), negC}
+	for i, ind := range hidden {
+		bases = append(bases, pub.H[ind])
+		exps = append(exps, new(big.Int).Neg(p.MsgResponses[i]))
+	}
+	t2 := g1.MultiExp(bases, exps)
+
+	c := proofChallenge(p.APrime, p.ABar, p.D, t1, t2, p.Revealed, p.RevealedMsgs,
+		nonceOrg)
+	if c.Cmp(p.Challenge) != 0 {
+		return fmt.Errorf("proof of possession of signature failed")
+	}
+	return nil
+}
+
+// revealedBases returns the bases of messages at indices.
+func revealedBases(pub *PubKey, indices []int) []*pairing.G1Element {
+	bases := make([]*pairing.G1Element, len(indices))
+	for i, ind := range indices {
+		bases[i] = pub.H[ind]
+	}
+	return bases
+}
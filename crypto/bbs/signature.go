@@ -0,0 +1,105 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bbs
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/pairing"
+)
+
+// Signature is a BBS+ signature (A, e, s) on messages m_1, ..., m_l, where
+// A = (g1 * h0^s * h1^m_1 * ... * hl^m_l)^(1/(x+e)).
+type Signature struct {
+	A *pairing.G1Element
+	E *big.Int
+	S *big.Int
+}
+
+// b returns g1 * h0^s * prod h_i^m_i for messages at indices of the block.
+func (k *PubKey) b(s *big.Int, indices []int, msgs []*big.Int) *pairing.G1Element {
+	bases := make([]*pairing.G1Element, len(indices)+1)
+	exps := make([]*big.Int, len(indices)+1)
+	bases[0], exps[0] = k.H0, s
+	for i, ind := range indices {
+		bases[i+1], exps[i+1] = k.H[ind], msgs[i]
+	}
+
+	g1 := pairing.NewG1()
+	return g1.Mul(g1.ExpBaseG(big.NewInt(1)), g1.MultiExp(bases, exps))
+}
+
+// sign computes the signature on b = g1 * h0^s * prod h_i^m_i with a fresh e.
+func (k *SecKey) sign(b *pairing.G1Element) (*pairing.G1Element, *big.Int) {
+	q := GroupOrder()
+	for {
+		e := randomScalar()
+		exp := new(big.Int).Add(k.X, e)
+		if exp.ModInverse(exp, q) == nil {
+			continue
+		}
+		return pairing.NewG1().Exp(b, exp), e
+	}
+}
+
+// Sign signs a block of messages.
+func Sign(keys *KeyPair, msgs []*big.Int) (*Signature, error) {
+	if err := checkMsgs(msgs, keys.Pub.MsgCount()); err != nil {
+		return nil, err
+	}
+
+	s := randomScalar()
+	A, e := keys.Sec.sign(keys.Pub.b(s, indices(len(msgs)), msgs))
+
+	return &Signature{
+		A: A,
+		E: e,
+		S: s,
+	}, nil
+}
+
+// Verify checks that sig is a valid signature on msgs, that is
+// e(A, W * g2^e) = e(g1 * h0^s * prod h_i^m_i, g2).
+func (sig *Signature) Verify(pub *PubKey, msgs []*big.Int) error {
+	if err := checkMsgs(msgs, pub.MsgCount()); err != nil {
+		return err
+	}
+	if sig.A.IsIdentity() {
+		return fmt.Errorf("invalid signature")
+	}
+
+	g2 := pairing.NewG2()
+	w := g2.Mul(pub.W, g2.ExpBaseG(sig.E))
+	b := pub.b(sig.S, indices(len(msgs)), msgs)
+	if !pairing.PairingProductIsOne(
+		[]*pairing.G1Element{sig.A, pairing.NewG1().Inv(b)},
+		[]*pairing.G2Element{w, g2.ExpBaseG(big.NewInt(1))}) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
+
+// indices returns indices 0, ..., n-1.
+func indices(n int) []int {
+	ind := make([]int, n)
+	for i := range ind {
+		ind[i] = i
+	}
+	return ind
+}
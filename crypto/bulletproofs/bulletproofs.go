@@ -0,0 +1,578 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package bulletproofs implements Bulletproofs, short non-interactive range proofs
+// about Pedersen commitments over elliptic curves without a trusted setup. A proof
+// that m values lie in [0, 2^n) has 2*log2(n*m) + 4 elements of the group and 5
+// scalars, which is much shorter than range proofs of integer commitments (see
+// package df). Proofs about integer commitments can be built with the help of a
+// proof of equality with a Pedersen commitment (see ProveDFEquality).
+package bulletproofs
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/ec"
+)
+
+// Params hold public parameters of range proofs about Pedersen commitments
+// V = g^v * h^gamma over an elliptic curve, where g is the generator of Group.
+type Params struct {
+	Group *ec.Group
+	H     *ec.GroupElement
+	// values are proved to lie in [0, 2^BitLen)
+	BitLen int
+	// generators of vector commitments, BitLen of them for each of at most
+	// maxValues values of an aggregated proof
+	gs []*ec.GroupElement
+	hs []*ec.GroupElement
+	// generator that inner products are committed with
+	u *ec.GroupElement
+}
+
+// NewParams returns parameters of proofs that values committed with generators of
+// group and h lie in [0, 2^bitLen), aggregating proofs of up to maxValues values.
+// Both bitLen and maxValues need to be powers of two. Generators of vector
+// commitments are derived with ec.Group.HashToElement, so nobody knows discrete
+// logarithms between them and proofs can be verified without a trusted setup.
+func NewParams(group *ec.Group, h *ec.GroupElement, bitLen, maxValues int) (*Params, error) {
+	if !isPowerOfTwo(bitLen) || !isPowerOfTwo(maxValues) {
+		return nil, fmt.Errorf("bit length and the number of values need to be powers of two")
+	}
+	if bitLen >= group.Q.BitLen() {
+		return nil, fmt.Errorf("bit length needs to be smaller than the bit length of the group order")
+	}
+
+	n := bitLen * maxValues
+	gs := make([]*ec.GroupElement, n)
+	hs := make([]*ec.GroupElement, n)
+	for i := 0; i < n; i++ {
+		gs[i] = group.HashToElement(generatorSeed("G", i))
+		hs[i] = group.HashToElement(generatorSeed("H", i))
+	}
+
+	return &Params{
+		Group:  group,
+		H:      h,
+		BitLen: bitLen,
+		gs:     gs,
+		hs:     hs,
+		u:      group.HashToElement(generatorSeed("u", 0)),
+	}, nil
+}
+
+// MaxValues returns the maximum number of values of an aggregated proof.
+func (p *Params) MaxValues() int {
+	return len(p.gs) / p.BitLen
+}
+
+// Commit returns the commitment g^v * h^gamma.
+func (p *Params) Commit(v, gamma *big.Int) *ec.GroupElement {
+	return p.Group.Mul(p.exp(nil, v), p.exp(p.H, gamma))
+}
+
+// Proof is an aggregated proof that values committed in commitments V_1, ..., V_m
+// lie in [0, 2^n), where n is Params.BitLen. It holds 2*log2(n*m) + 4 elements of
+// the group, encoded with ec.Group.Encode, and 5 scalars, regardless of the size
+// of the range.
+type Proof struct {
+	A    *big.Int
+	S    *big.Int
+	T1   *big.Int
+	T2   *big.Int
+	TauX *big.Int
+	Mu   *big.Int
+	T    *big.Int
+	// inner product argument
+	L      []*big.Int
+	R      []*big.Int
+	InnerA *big.Int
+	InnerB *big.Int
+}
+
+// Prove returns a non-interactive proof that values, committed as
+// g^values[j] * h^gammas[j], lie in [0, 2^BitLen). The number of values needs to be
+// a power of two, at most MaxValues. Context (for example a nonce of the verifier)
+// is bound to the proof to prevent its reuse in another context.
+//
+// Based on:
+// B. Bunz, J. Bootle, D. Boneh, A. Poelstra, P. Wuille, G. Maxwell. Bulletproofs: Short
+// Proofs for Confidential Transactions and More. https://eprint.iacr.org/2017/1066
+func Prove(p *Params, values, gammas []*big.Int, context *big.Int) (*Proof, error) {
+	m := len(values)
+	if m == 0 || !isPowerOfTwo(m) || m > p.MaxValues() {
+		return nil, fmt.Errorf("the number of values needs to be a power of two, at most %d",
+			p.MaxValues())
+	}
+	if len(gammas) != m {
+		return nil, fmt.Errorf("randomness of each commitment is needed")
+	}
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(p.BitLen))
+	for _, v := range values {
+		if v.Sign() < 0 || v.Cmp(bound) >= 0 {
+			return nil, fmt.Errorf("value is not in [0, 2^%d)", p.BitLen)
+		}
+	}
+
+	q := p.Group.Q
+	n, nm := p.BitLen, p.BitLen*m
+	gs, hs := p.gs[:nm], p.hs[:nm]
+	commitments := make([]*ec.GroupElement, m)
+	for j := range values {
+		commitments[j] = p.Commit(values[j], gammas[j])
+	}
+
+	// aL holds bits of values, aR = aL - 1
+	aL := make([]*big.Int, nm)
+	aR := make([]*big.Int, nm)
+	for j, v := range values {
+		for i := 0; i < n; i++ {
+			aL[j*n+i] = big.NewInt(int64(v.Bit(i)))
+			aR[j*n+i] = new(big.Int).Sub(aL[j*n+i], one)
+		}
+	}
+	alpha := common.GetRandomInt(q)
+	sL := randomVector(q, nm)
+	sR := randomVector(q, nm)
+	rho := common.GetRandomInt(q)
+
+	// A = h^alpha * G^aL * H^aR, S = h^rho * G^sL * H^sR
+	a := p.Group.Mul(p.exp(p.H, alpha), p.Group.Mul(p.multiExp(gs, aL), p.multiExp(hs, aR)))
+	s := p.Group.Mul(p.exp(p.H, rho), p.Group.Mul(p.multiExp(gs, sL), p.multiExp(hs, sR)))
+	enc := p.encode(a, s)
+	if enc == nil {
+		return nil, fmt.Errorf("failed to encode the proof")
+	}
+	y, z := p.challengesYZ(commitments, enc[0], enc[1], context)
+	if y == nil {
+		return nil, fmt.Errorf("invalid challenge")
+	}
+
+	yn := powers(y, nm, q)
+	zs := p.valueCoefficients(z, m)
+	// l(X) = l0 + l1*X, r(X) = r0 + r1*X
+	l0 := make([]*big.Int, nm)
+	r0 := make([]*big.Int, nm)
+	r1 := make([]*big.Int, nm)
+	for i := 0; i < nm; i++ {
+		l0[i] = mod(new(big.Int).Sub(aL[i], z), q)
+		r0[i] = new(big.Int).Add(aR[i], z)
+		r0[i].Mul(r0[i], yn[i])
+		r0[i].Add(r0[i], zs[i])
+		mod(r0[i], q)
+		r1[i] = mod(new(big.Int).Mul(yn[i], sR[i]), q)
+	}
+	// t(X) = <l(X), r(X)> = t0 + t1*X + t2*X^2
+	t1 := mod(new(big.Int).Add(innerProduct(l0, r1, q), innerProduct(sL, r0, q)), q)
+	t2 := innerProduct(sL, r1, q)
+	tau1 := common.GetRandomInt(q)
+	tau2 := common.GetRandomInt(q)
+	encT := p.encode(p.Commit(t1, tau1), p.Commit(t2, tau2))
+	if encT == nil {
+		return nil, fmt.Errorf("failed to encode the proof")
+	}
+	x := p.challenge(z, encT[0], encT[1])
+	if x == nil {
+		return nil, fmt.Errorf("invalid challenge")
+	}
+
+	l := make([]*big.Int, nm)
+	r := make([]*big.Int, nm)
+	for i := 0; i < nm; i++ {
+		l[i] = mod(new(big.Int).Add(l0[i], new(big.Int).Mul(sL[i], x)), q)
+		r[i] = mod(new(big.Int).Add(r0[i], new(big.Int).Mul(r1[i], x)), q)
+	}
+	t := innerProduct(l, r, q)
+	// tauX = tau2*x^2 + tau1*x + sum_j z^(2+j) * gamma_j, mu = alpha + rho*x
+	tauX := new(big.Int).Mul(tau2, x)
+	tauX.Add(tauX, tau1)
+	tauX.Mul(tauX, x)
+	zj := new(big.Int).Mul(z, z)
+	for _, gamma := range gammas {
+		tauX.Add(tauX, new(big.Int).Mul(zj, gamma))
+		zj = mod(zj.Mul(zj, z), q)
+	}
+	mod(tauX, q)
+	mu := mod(new(big.Int).Add(alpha, new(big.Int).Mul(rho, x)), q)
+
+	// the inner product argument is for generators G and H' = H^(y^-i)
+	w := p.challenge(x, tauX, mu, t)
+	if w == nil {
+		return nil, fmt.Errorf("invalid challenge")
+	}
+	yInv := new(big.Int).ModInverse(y, q)
+	yInvN := powers(yInv, nm, q)
+	hPrime := make([]*ec.GroupElement, nm)
+	for i := range hPrime {
+		hPrime[i] = p.exp(hs[i], yInvN[i])
+	}
+	proof := &Proof{
+		A:    enc[0],
+		S:    enc[1],
+		T1:   encT[0],
+		T2:   encT[1],
+		TauX: tauX,
+		Mu:   mu,
+		T:    t,
+	}
+	if err := p.proveInnerProduct(proof, gs, hPrime, p.exp(p.u, w), l, r, w); err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+// proveInnerProduct adds to proof the argument of the knowledge of vectors a and b
+// such that P = G^a * H^b * u^<a, b>, halving the vectors in each round.
+func (p *Params) proveInnerProduct(proof *Proof, gs, hs []*ec.GroupElement,
+	u *ec.GroupElement, a, b []*big.Int, prev *big.Int) error {
+	q := p.Group.Q
+	for len(a) > 1 {
+		k := len(a) / 2
+		cL := innerProduct(a[:k], b[k:], q)
+		cR := innerProduct(a[k:], b[:k], q)
+		// L = G_hi^a_lo * H_lo^b_hi * u^cL, R = G_lo^a_hi * H_hi^b_lo * u^cR
+		l := p.Group.Mul(p.Group.Mul(p.multiExp(gs[k:], a[:k]), p.multiExp(hs[:k], b[k:])),
+			p.exp(u, cL))
+		r := p.Group.Mul(p.Group.Mul(p.multiExp(gs[:k], a[k:]), p.multiExp(hs[k:], b[:k])),
+			p.exp(u, cR))
+		enc := p.encode(l, r)
+		if enc == nil {
+			return fmt.Errorf("failed to encode the proof")
+		}
+		proof.L = append(proof.L, enc[0])
+		proof.R = append(proof.R, enc[1])
+
+		x := p.challenge(prev, enc[0], enc[1])
+		if x == nil {
+			return fmt.Errorf("invalid challenge")
+		}
+		xInv := new(big.Int).ModInverse(x, q)
+		gs2 := make([]*ec.GroupElement, k)
+		hs2 := make([]*ec.GroupElement, k)
+		a2 := make([]*big.Int, k)
+		b2 := make([]*big.Int, k)
+		for i := 0; i < k; i++ {
+			gs2[i] = p.Group.Mul(p.exp(gs[i], xInv), p.exp(gs[k+i], x))
+			hs2[i] = p.Group.Mul(p.exp(hs[i], x), p.exp(hs[k+i], xInv))
+			a2[i] = mod(new(big.Int).Add(new(big.Int).Mul(a[i], x),
+				new(big.Int).Mul(a[k+i], xInv)), q)
+			b2[i] = mod(new(big.Int).Add(new(big.Int).Mul(b[i], xInv),
+				new(big.Int).Mul(b[k+i], x)), q)
+		}
+		gs, hs, a, b, prev = gs2, hs2, a2, b2, x
+	}
+	proof.InnerA = a[0]
+	proof.InnerB = b[0]
+
+	return nil
+}
+
+// Verify verifies a proof built with Prove that values committed in commitments lie
+// in [0, 2^BitLen). An error is returned if the proof is malformed.
+func Verify(p *Params, commitments []*ec.GroupElement, proof *Proof,
+	context *big.Int) (bool, error) {
+	m := len(commitments)
+	if m == 0 || !isPowerOfTwo(m) || m > p.MaxValues() {
+		return false, fmt.Errorf("the number of commitments needs to be a power of two, at most %d",
+			p.MaxValues())
+	}
+	if proof == nil || proof.TauX == nil || proof.Mu == nil || proof.T == nil ||
+		proof.InnerA == nil || proof.InnerB == nil {
+		return false, fmt.Errorf("incomplete proof")
+	}
+	q := p.Group.Q
+	n, nm := p.BitLen, p.BitLen*m
+	rounds := 0
+	for 1<<uint(rounds) < nm {
+		rounds++
+	}
+	if len(proof.L) != rounds || len(proof.R) != rounds {
+		return false, fmt.Errorf("the inner product argument needs %d rounds", rounds)
+	}
+	for _, s := range []*big.Int{proof.TauX, proof.Mu, proof.T, proof.InnerA, proof.InnerB} {
+		if s.Sign() < 0 || s.Cmp(q) >= 0 {
+			return false, fmt.Errorf("scalars of the proof need to be in Z_q")
+		}
+	}
+	enc := []*big.Int{proof.A, proof.S, proof.T1, proof.T2}
+	enc = append(enc, proof.L...)
+	points, err := p.decode(append(enc, proof.R...))
+	if err != nil {
+		return false, err
+	}
+	a, s, t1, t2 := points[0], points[1], points[2], points[3]
+	ls, rs := points[4:4+rounds], points[4+rounds:]
+	for _, c := range commitments {
+		if err := p.Group.CheckElement(c); err != nil {
+			return false, err
+		}
+	}
+
+	y, z := p.challengesYZ(commitments, proof.A, proof.S, context)
+	x := p.challenge(z, proof.T1, proof.T2)
+	w := p.challenge(x, proof.TauX, proof.Mu, proof.T)
+	if y == nil || x == nil || w == nil {
+		return false, nil
+	}
+	xs := make([]*big.Int, rounds)
+	prev := w
+	for k := 0; k < rounds; k++ {
+		if xs[k] = p.challenge(prev, proof.L[k], proof.R[k]); xs[k] == nil {
+			return false, nil
+		}
+		prev = xs[k]
+	}
+
+	// g^t * h^tauX = V^(z^2 * z^m) * g^delta(y, z) * T1^x * T2^(x^2), where
+	// delta(y, z) = (z - z^2) * <1, y^nm> - sum_j z^(3+j) * <1, 2^n>
+	yn := powers(y, nm, q)
+	twoN := powers(big.NewInt(2), n, q)
+	zz := mod(new(big.Int).Mul(z, z), q)
+	delta := new(big.Int).Sub(z, zz)
+	delta.Mul(delta, sum(yn))
+	sumTwoN := sum(twoN)
+	zj := new(big.Int).Mul(zz, z)
+	vExps := make([]*big.Int, m)
+	for j := 0; j < m; j++ {
+		vExps[j] = new(big.Int).Mul(zz, powerOf(z, j, q))
+		delta.Sub(delta, new(big.Int).Mul(zj, sumTwoN))
+		zj = mod(zj.Mul(zj, z), q)
+	}
+	left := p.Commit(proof.T, proof.TauX)
+	right := p.Group.Mul(p.multiExp(commitments, vExps), p.exp(nil, delta))
+	right = p.Group.Mul(right, p.Group.Mul(p.exp(t1, x), p.exp(t2, new(big.Int).Mul(x, x))))
+	if !left.Equals(right) {
+		return false, nil
+	}
+
+	// The inner product argument: for P = A * S^x * G^-z * H'^(z*y^nm + zs) * h^-mu,
+	// P * u^(w*t) * prod_k L_k^(x_k^2) * R_k^(x_k^-2) needs to equal
+	// G^(a*s) * H'^(b*s^-1) * u^(w*a*b), where H' = H^(y^-i) and s_i is the product
+	// of x_k or x_k^-1, depending on bit k (from the top) of i.
+	yInvN := powers(new(big.Int).ModInverse(y, q), nm, q)
+	zs := p.valueCoefficients(z, m)
+	sv := foldingCoefficients(xs, nm, q)
+	gExps := make([]*big.Int, nm)
+	hExps := make([]*big.Int, nm)
+	for i := 0; i < nm; i++ {
+		// exponents of G_i and H_i in G^(a*s) * H'^(b*s^-1) / P
+		gExps[i] = mod(new(big.Int).Add(new(big.Int).Mul(proof.InnerA, sv[i]), z), q)
+		hExp := new(big.Int).Mul(proof.InnerB, sv[nm-1-i])
+		hExp.Sub(hExp, zs[i])
+		hExp.Mul(hExp, yInvN[i])
+		hExp.Sub(hExp, z)
+		hExps[i] = mod(hExp, q)
+	}
+	uExp := new(big.Int).Mul(proof.InnerA, proof.InnerB)
+	uExp.Sub(uExp, proof.T)
+	uExp.Mul(uExp, w)
+
+	left = p.Group.Mul(a, p.exp(s, x))
+	for k := 0; k < rounds; k++ {
+		xx := mod(new(big.Int).Mul(xs[k], xs[k]), q)
+		left = p.Group.Mul(left, p.Group.Mul(p.exp(ls[k], xx),
+			p.exp(rs[k], new(big.Int).ModInverse(xx, q))))
+	}
+	right = p.Group.Mul(p.multiExp(p.gs[:nm], gExps), p.multiExp(p.hs[:nm], hExps))
+	right = p.Group.Mul(right, p.Group.Mul(p.exp(p.u, uExp), p.exp(p.H, proof.Mu)))
+
+	return left.Equals(right), nil
+}
+
+// challengesYZ returns the first two challenges of the proof about commitments.
+func (p *Params) challengesYZ(commitments []*ec.GroupElement, a, s,
+	context *big.Int) (*big.Int, *big.Int) {
+	input := []*big.Int{context, big.NewInt(int64(p.BitLen))}
+	for _, c := range commitments {
+		input = append(input, p.Group.Encode(c))
+	}
+	y := p.challenge(append(input, a, s)...)
+	if y == nil {
+		return nil, nil
+	}
+	z := p.challenge(y)
+	if z == nil {
+		return nil, nil
+	}
+
+	return y, z
+}
+
+// challenge derives a challenge in Z_q from the transcript so far, given by the
+// previous challenge and the messages of the prover since then. Nil is returned in
+// the unlikely case of a zero challenge, which has no inverse.
+func (p *Params) challenge(input ...*big.Int) *big.Int {
+	input = append([]*big.Int{big.NewInt(int64(len(input)))}, input...)
+	c := mod(common.Hash(input...), p.Group.Q)
+	if c.Sign() == 0 {
+		return nil
+	}
+
+	return c
+}
+
+// valueCoefficients returns the vector of z^(2+j) * 2^i at index j*n+i, which binds
+// bits of the j-th value to the value.
+func (p *Params) valueCoefficients(z *big.Int, m int) []*big.Int {
+	q := p.Group.Q
+	n := p.BitLen
+	twoN := powers(big.NewInt(2), n, q)
+	zs := make([]*big.Int, n*m)
+	zj := mod(new(big.Int).Mul(z, z), q)
+	for j := 0; j < m; j++ {
+		for i := 0; i < n; i++ {
+			zs[j*n+i] = mod(new(big.Int).Mul(zj, twoN[i]), q)
+		}
+		zj = mod(new(big.Int).Mul(zj, z), q)
+	}
+
+	return zs
+}
+
+// exp returns base^e for e reduced modulo the order of the group, or g^e when base
+// is nil.
+func (p *Params) exp(base *ec.GroupElement, e *big.Int) *ec.GroupElement {
+	e = mod(new(big.Int).Set(e), p.Group.Q)
+	if base == nil {
+		return p.Group.ExpBaseG(e)
+	}
+	return p.Group.Exp(base, e)
+}
+
+// multiExp returns the product of bases[i]^exps[i].
+func (p *Params) multiExp(bases []*ec.GroupElement, exps []*big.Int) *ec.GroupElement {
+	res := ec.NewGroupElement(new(big.Int), new(big.Int)) // the point at infinity
+	for i, b := range bases {
+		if exps[i].Sign() != 0 {
+			res = p.Group.Mul(res, p.exp(b, exps[i]))
+		}
+	}
+
+	return res
+}
+
+// encode returns encodings of elements, or nil if any of them is the point at
+// infinity, which has no encoding.
+func (p *Params) encode(elements ...*ec.GroupElement) []*big.Int {
+	enc := make([]*big.Int, len(elements))
+	for i, el := range elements {
+		if el.X.Sign() == 0 && el.Y.Sign() == 0 {
+			return nil
+		}
+		enc[i] = p.Group.Encode(el)
+	}
+
+	return enc
+}
+
+// decode returns elements encoded as enc.
+func (p *Params) decode(enc []*big.Int) ([]*ec.GroupElement, error) {
+	elements := make([]*ec.GroupElement, len(enc))
+	for i, v := range enc {
+		el, err := p.Group.Decode(v)
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = el
+	}
+
+	return elements, nil
+}
+
+// foldingCoefficients returns s_i, the product over rounds k of x_k if bit k of i,
+// counted from the top, is 1, and x_k^-1 otherwise. The final generators of the
+// inner product argument are G^s and H^(s^-1), and s_i^-1 = s_(n-1-i).
+func foldingCoefficients(xs []*big.Int, n int, q *big.Int) []*big.Int {
+	rounds := len(xs)
+	xInvs := make([]*big.Int, rounds)
+	for k, x := range xs {
+		xInvs[k] = new(big.Int).ModInverse(x, q)
+	}
+	s := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		s[i] = big.NewInt(1)
+		for k := 0; k < rounds; k++ {
+			if (i>>uint(rounds-1-k))&1 == 1 {
+				s[i].Mul(s[i], xs[k])
+			} else {
+				s[i].Mul(s[i], xInvs[k])
+			}
+			s[i].Mod(s[i], q)
+		}
+	}
+
+	return s
+}
+
+var one = big.NewInt(1)
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+func generatorSeed(name string, i int) []byte {
+	return []byte(fmt.Sprintf("emmy bulletproofs %s %d", name, i))
+}
+
+func mod(x, q *big.Int) *big.Int {
+	return x.Mod(x, q)
+}
+
+// powers returns 1, x, ..., x^(n-1) modulo q.
+func powers(x *big.Int, n int, q *big.Int) []*big.Int {
+	v := make([]*big.Int, n)
+	v[0] = big.NewInt(1)
+	for i := 1; i < n; i++ {
+		v[i] = mod(new(big.Int).Mul(v[i-1], x), q)
+	}
+
+	return v
+}
+
+func powerOf(x *big.Int, e int, q *big.Int) *big.Int {
+	return new(big.Int).Exp(x, big.NewInt(int64(e)), q)
+}
+
+func randomVector(q *big.Int, n int) []*big.Int {
+	v := make([]*big.Int, n)
+	for i := range v {
+		v[i] = common.GetRandomInt(q)
+	}
+
+	return v
+}
+
+func innerProduct(a, b []*big.Int, q *big.Int) *big.Int {
+	res := new(big.Int)
+	for i := range a {
+		res.Add(res, new(big.Int).Mul(a[i], b[i]))
+	}
+
+	return mod(res, q)
+}
+
+func sum(v []*big.Int) *big.Int {
+	res := new(big.Int)
+	for _, x := range v {
+		res.Add(res, x)
+	}
+
+	return res
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bulletproofs
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/ec"
+)
+
+func testParams(t *testing.T, curve ec.Curve, bitLen, maxValues int) *Params {
+	group := ec.NewGroup(curve)
+	p, err := NewParams(group, group.HashToElement([]byte("test")), bitLen, maxValues)
+	require.NoError(t, err)
+	return p
+}
+
+func TestBulletproofs(t *testing.T) {
+	p := testParams(t, ec.P256, 32, 4)
+	context := big.NewInt(1234)
+	values := []*big.Int{big.NewInt(0), big.NewInt(42), big.NewInt(1<<32 - 1), big.NewInt(1 << 20)}
+	gammas := make([]*big.Int, len(values))
+	commitments := make([]*ec.GroupElement, len(values))
+	for i, v := range values {
+		gammas[i] = common.GetRandomInt(p.Group.Q)
+		commitments[i] = p.Commit(v, gammas[i])
+	}
+
+	proof, err := Prove(p, values, gammas, context)
+	require.NoError(t, err)
+	assert.Len(t, proof.L, 7)
+	ok, err := Verify(p, commitments, proof, context)
+	assert.NoError(t, err)
+	assert.True(t, ok, "aggregated proof should be accepted")
+
+	// a single value
+	single, err := Prove(p, values[1:2], gammas[1:2], context)
+	require.NoError(t, err)
+	ok, err = Verify(p, commitments[1:2], single, context)
+	assert.NoError(t, err)
+	assert.True(t, ok, "proof of a single value should be accepted")
+
+	ok, _ = Verify(p, commitments, proof, big.NewInt(1))
+	assert.False(t, ok, "proof should not be accepted in another context")
+	swapped := []*ec.GroupElement{commitments[1], commitments[0], commitments[2], commitments[3]}
+	ok, _ = Verify(p, swapped, proof, context)
+	assert.False(t, ok, "proof should not be accepted for other commitments")
+	tampered := *proof
+	tampered.T = new(big.Int).Add(proof.T, big.NewInt(1))
+	ok, _ = Verify(p, commitments, &tampered, context)
+	assert.False(t, ok, "tampered proof should not be accepted")
+	tampered = *proof
+	tampered.InnerA = new(big.Int).Add(proof.InnerA, big.NewInt(1))
+	ok, _ = Verify(p, commitments, &tampered, context)
+	assert.False(t, ok, "tampered proof should not be accepted")
+	tampered = *proof
+	tampered.L = proof.L[1:]
+	_, err = Verify(p, commitments, &tampered, context)
+	assert.Error(t, err, "proof with missing rounds should be rejected")
+
+	_, err = Prove(p, []*big.Int{big.NewInt(1 << 32)}, gammas[:1], context)
+	assert.Error(t, err, "value out of range should be rejected")
+	_, err = Prove(p, values[:3], gammas[:3], context)
+	assert.Error(t, err, "number of values should be a power of two")
+	_, err = NewParams(p.Group, p.H, 48, 1)
+	assert.Error(t, err, "bit length should be a power of two")
+}
+
+func TestBulletproofsRange(t *testing.T) {
+	for _, curve := range []ec.Curve{ec.P256, ec.P384} {
+		p := testParams(t, curve, 64, 2)
+		context := big.NewInt(1234)
+		x := big.NewInt(-20)
+		r := common.GetRandomInt(p.Group.Q)
+		c := p.Commit(x, r)
+		a, b := big.NewInt(-100), big.NewInt(1<<62)
+
+		proof, err := ProveRange(p, x, r, a, b, context)
+		require.NoError(t, err)
+		ok, err := VerifyRange(p, c, proof, a, b, context)
+		assert.NoError(t, err)
+		assert.True(t, ok, "range proof should be accepted")
+
+		ok, _ = VerifyRange(p, c, proof, big.NewInt(-10), b, context)
+		assert.False(t, ok, "proof should not be accepted for another range")
+		ok, _ = VerifyRange(p, p.Commit(big.NewInt(-21), r), proof, a, b, context)
+		assert.False(t, ok, "proof should not be accepted for another commitment")
+
+		_, err = ProveRange(p, x, r, big.NewInt(-10), b, context)
+		assert.Error(t, err, "value out of range should be rejected")
+		_, err = ProveRange(p, x, r, a, new(big.Int).Lsh(big.NewInt(1), 64), context)
+		assert.Error(t, err, "too wide range should be rejected")
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bulletproofs
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/df"
+	"github.com/xlab-si/emmy/crypto/ec"
+)
+
+// DFEqualityProof is a non-interactive proof that a Damgard-Fujisaki integer
+// commitment (see package df) and a Pedersen commitment over an elliptic curve hide
+// the same integer x. It allows Bulletproofs to be used for integer commitments,
+// which are then proved about the Pedersen commitment.
+//
+// The committed value is bound by the integer commitment, and the response that
+// the verifier checks in both groups is computed over integers and bounded. Thus
+// the value is known to be shorter than the order of the elliptic curve group,
+// and ranges of the value modulo the order are also its ranges as an integer.
+type DFEqualityProof struct {
+	ProofRandomData1 *big.Int // in QR_N
+	ProofRandomData2 *big.Int // element of the curve, encoded with ec.Group.Encode
+	Challenge        *big.Int
+	ProofData1       *big.Int
+	ProofData21      *big.Int
+	ProofData22      *big.Int
+}
+
+// ProveDFEquality returns a proof that the integer commitment held by committer and
+// the commitment g^x * h^r over the elliptic curve of p hide the same x, where
+// |x| < 2^bitLen. Context is bound to the proof like in Prove.
+func ProveDFEquality(committer *df.Committer, p *Params, r *big.Int, bitLen int,
+	context *big.Int, challengeSpace int) (*DFEqualityProof, error) {
+	x, r1 := committer.GetDecommitMsg()
+	if x == nil {
+		return nil, fmt.Errorf("no committed value")
+	}
+	if x.BitLen() > bitLen {
+		return nil, fmt.Errorf("committed value is longer than %d bits", bitLen)
+	}
+	bound, err := p.dfResponseBound(bitLen, committer.K, challengeSpace)
+	if err != nil {
+		return nil, err
+	}
+	c1 := committer.ComputeCommit(x, r1)
+	c2 := p.encode(p.Commit(x, r))
+	if c2 == nil {
+		return nil, fmt.Errorf("failed to encode the commitment")
+	}
+
+	// r1 is from [0, 2^(B + K)), the randomness for it is longer by the challenge
+	// and K bits to hide it statistically
+	rx := common.GetRandomIntAlsoNeg(new(big.Int).Rsh(bound, 1))
+	rr1 := common.GetRandomInt(new(big.Int).Lsh(big.NewInt(1),
+		uint(committer.B+2*committer.K+challengeSpace)))
+	rr2 := common.GetRandomInt(p.Group.Q)
+	t1 := committer.ComputeCommit(rx, rr1)
+	t2 := p.encode(p.Commit(rx, rr2))
+	if t2 == nil {
+		return nil, fmt.Errorf("failed to encode the proof")
+	}
+	challenge := dfEqualityChallenge(c1, c2[0], t1, t2[0], context, challengeSpace)
+
+	// s1 = rx + challenge*x, s21 = rr1 + challenge*r1 (in Z, not modulo),
+	// s22 = rr2 + challenge*r mod q
+	s1 := new(big.Int).Mul(challenge, x)
+	s1.Add(s1, rx)
+	s21 := new(big.Int).Mul(challenge, r1)
+	s21.Add(s21, rr1)
+	s22 := new(big.Int).Mul(challenge, r)
+	s22.Add(s22, rr2)
+	s22.Mod(s22, p.Group.Q)
+
+	return &DFEqualityProof{
+		ProofRandomData1: t1,
+		ProofRandomData2: t2[0],
+		Challenge:        challenge,
+		ProofData1:       s1,
+		ProofData21:      s21,
+		ProofData22:      s22,
+	}, nil
+}
+
+// VerifyDFEquality verifies a proof built with ProveDFEquality that the commitment
+// set in receiver and commitment c over the elliptic curve of p hide the same
+// integer x. Then |x| is known to be below a quarter of the order of the curve
+// group, so ranges of the value modulo the order (see VerifyRange) with bounds
+// much smaller than the order are also ranges of x.
+func VerifyDFEquality(receiver *df.Receiver, p *Params, c *ec.GroupElement,
+	proof *DFEqualityProof, bitLen int, context *big.Int, challengeSpace int) (bool, error) {
+	if proof == nil || proof.ProofRandomData1 == nil || proof.Challenge == nil ||
+		proof.ProofData1 == nil || proof.ProofData21 == nil || proof.ProofData22 == nil {
+		return false, fmt.Errorf("incomplete proof")
+	}
+	if receiver.Commitment == nil {
+		return false, fmt.Errorf("missing integer commitment")
+	}
+	bound, err := p.dfResponseBound(bitLen, receiver.K, challengeSpace)
+	if err != nil {
+		return false, err
+	}
+	if err := p.Group.CheckElement(c); err != nil {
+		return false, err
+	}
+	t2, err := p.Group.Decode(proof.ProofRandomData2)
+	if err != nil {
+		return false, err
+	}
+	enc := p.encode(c)
+	if enc == nil {
+		return false, nil
+	}
+	challenge := dfEqualityChallenge(receiver.Commitment, enc[0], proof.ProofRandomData1,
+		proof.ProofRandomData2, context, challengeSpace)
+	if challenge.Cmp(proof.Challenge) != 0 {
+		return false, fmt.Errorf("challenge is not correct")
+	}
+	// bounded response implies a bounded committed value
+	if new(big.Int).Abs(proof.ProofData1).Cmp(bound) >= 0 {
+		return false, nil
+	}
+
+	// G^s1 * H^s21 = t1 * c1^challenge in QR_N
+	group := receiver.QRSpecialRSA
+	left := receiver.ComputeCommit(proof.ProofData1, proof.ProofData21)
+	right := group.Mul(proof.ProofRandomData1, group.Exp(receiver.Commitment, challenge))
+	if left.Cmp(right) != 0 {
+		return false, nil
+	}
+
+	// g^s1 * h^s22 = t2 * c^challenge over the curve
+	return p.Commit(proof.ProofData1, proof.ProofData22).Equals(
+		p.Group.Mul(t2, p.exp(c, challenge))), nil
+}
+
+// dfResponseBound returns the bound on the absolute value of responses of proofs of
+// equality of values of at most bitLen bits. Values are extracted from proofs as
+// differences of responses divided by differences of challenges, thus they are
+// bounded by twice the bound, which needs to be below a quarter of the group order.
+func (p *Params) dfResponseBound(bitLen, secParam, challengeSpace int) (*big.Int, error) {
+	l := bitLen + secParam + challengeSpace + 1
+	if l+4 > p.Group.Q.BitLen() {
+		return nil, fmt.Errorf("values of %d bits are too long for the curve group", bitLen)
+	}
+
+	return new(big.Int).Lsh(big.NewInt(1), uint(l)), nil
+}
+
+// dfEqualityChallenge derives the challenge of the proof of equality by hashing
+// commitments and the first messages of the proof.
+func dfEqualityChallenge(c1, c2, t1, t2, context *big.Int, challengeSpace int) *big.Int {
+	h := common.Hash(context, c1, c2, t1, t2)
+	return h.Mod(h, new(big.Int).Lsh(big.NewInt(1), uint(challengeSpace)))
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bulletproofs
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/df"
+	"github.com/xlab-si/emmy/crypto/ec"
+)
+
+func TestDFEquality(t *testing.T) {
+	receiver, err := df.NewReceiver(128, 80)
+	require.NoError(t, err)
+	committer := df.NewCommitter(receiver.QRSpecialRSA.N, receiver.G, receiver.H,
+		receiver.QRSpecialRSA.N, receiver.K)
+	p := testParams(t, ec.P256, 64, 2)
+
+	x := big.NewInt(-123456789)
+	c1, err := committer.GetCommitMsg(x)
+	require.NoError(t, err)
+	receiver.SetCommitment(c1)
+	r := common.GetRandomInt(p.Group.Q)
+	c2 := p.Commit(x, r)
+	context := big.NewInt(1234)
+
+	proof, err := ProveDFEquality(committer, p, r, 64, context, 80)
+	require.NoError(t, err)
+	ok, err := VerifyDFEquality(receiver, p, c2, proof, 64, context, 80)
+	assert.NoError(t, err)
+	assert.True(t, ok, "proof of equality should be accepted")
+
+	// the range is proved about the commitment over the curve
+	a, b := big.NewInt(-200000000), big.NewInt(0)
+	rangeProof, err := ProveRange(p, x, r, a, b, context)
+	require.NoError(t, err)
+	ok, err = VerifyRange(p, c2, rangeProof, a, b, context)
+	assert.NoError(t, err)
+	assert.True(t, ok, "range proof should be accepted")
+
+	other := p.Commit(big.NewInt(123456789), r)
+	_, err = VerifyDFEquality(receiver, p, other, proof, 64, context, 80)
+	assert.Error(t, err, "proof should not be accepted for another commitment")
+	_, err = VerifyDFEquality(receiver, p, c2, proof, 64, big.NewInt(1), 80)
+	assert.Error(t, err, "proof should not be accepted in another context")
+	tampered := *proof
+	tampered.ProofData1 = new(big.Int).Lsh(big.NewInt(1), 300)
+	ok, _ = VerifyDFEquality(receiver, p, c2, &tampered, 64, context, 80)
+	assert.False(t, ok, "too large response should not be accepted")
+
+	_, err = ProveDFEquality(committer, p, r, 16, context, 80)
+	assert.Error(t, err, "too long value should be rejected")
+	_, err = ProveDFEquality(committer, p, r, 128, context, 80)
+	assert.Error(t, err, "too long values should not be accepted for the curve")
+}
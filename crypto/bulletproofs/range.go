@@ -0,0 +1,76 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package bulletproofs
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/ec"
+)
+
+// ProveRange returns a proof that x, committed as g^x * h^r, lies in [a, b], where
+// b - a < 2^BitLen. It is an aggregated proof (see Prove) that x - a and b - x,
+// committed in c * g^-a and g^b * c^-1, lie in [0, 2^BitLen).
+func ProveRange(p *Params, x, r, a, b, context *big.Int) (*Proof, error) {
+	if err := p.checkRange(a, b); err != nil {
+		return nil, err
+	}
+	if x.Cmp(a) < 0 || x.Cmp(b) > 0 {
+		return nil, fmt.Errorf("committed value is not in the range")
+	}
+
+	return Prove(p, []*big.Int{new(big.Int).Sub(x, a), new(big.Int).Sub(b, x)},
+		[]*big.Int{r, new(big.Int).Neg(r)}, context)
+}
+
+// VerifyRange verifies a proof built with ProveRange that the value committed in c
+// lies in [a, b].
+func VerifyRange(p *Params, c *ec.GroupElement, proof *Proof, a, b,
+	context *big.Int) (bool, error) {
+	if err := p.checkRange(a, b); err != nil {
+		return false, err
+	}
+	if err := p.Group.CheckElement(c); err != nil {
+		return false, err
+	}
+
+	// c * g^-a, g^b * c^-1
+	c1 := p.Group.Mul(c, p.exp(nil, new(big.Int).Neg(a)))
+	c2 := p.Group.Mul(p.exp(nil, b), p.Group.Inv(c))
+	if p.encode(c1, c2) == nil {
+		return false, nil
+	}
+
+	return Verify(p, []*ec.GroupElement{c1, c2}, proof, context)
+}
+
+// checkRange checks that [a, b] is not empty and shorter than 2^BitLen.
+func (p *Params) checkRange(a, b *big.Int) error {
+	if p.MaxValues() < 2 {
+		return fmt.Errorf("range proofs need aggregation of two values")
+	}
+	if a == nil || b == nil || a.Cmp(b) > 0 {
+		return fmt.Errorf("empty range")
+	}
+	if new(big.Int).Sub(b, a).BitLen() > p.BitLen {
+		return fmt.Errorf("range needs to be shorter than 2^%d", p.BitLen)
+	}
+
+	return nil
+}
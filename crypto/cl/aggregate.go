@@ -0,0 +1,319 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/qr"
+)
+
+// AggregateCred is a credential to be proved within an aggregate proof, along with
+// indices of attributes to be revealed.
+type AggregateCred struct {
+	Manager                           *CredManager
+	Cred                              *Cred
+	RevealedKnownAttrsIndices         []int
+	RevealedCommitmentsOfAttrsIndices []int
+}
+
+// AttrRef refers to the known attribute with index Attr of the credential with
+// index Cred within an aggregate proof.
+type AttrRef struct {
+	Cred int
+	Attr int
+}
+
+// AggregateProof proves the possession of several credentials, possibly issued by
+// different organizations, with a single challenge and without proof random data
+// (see qr.AggregateProof). Unrevealed known attributes referred to by each link
+// have a single response, which proves they are equal without revealing them.
+// Compared to separate proofs built with BuildProof, it saves a challenge and
+// proof random data per credential, and a response per linked attribute.
+type AggregateProof struct {
+	As    []*big.Int // randomized A of each credential
+	Links [][]AttrRef
+	*qr.AggregateProof
+}
+
+// RevealedCred is what the verifier of an aggregate proof learns about one of its
+// credentials: the public key of the issuer and the revealed attributes (given in
+// the order of their indices, as returned by CredManager.FilterAttributes).
+type RevealedCred struct {
+	PubKey                            *PubKey
+	RevealedKnownAttrsIndices         []int
+	RevealedCommitmentsOfAttrsIndices []int
+	RevealedKnownAttrs                []*big.Int
+	RevealedCommitmentsOfAttrs        []*big.Int
+}
+
+// BuildAggregateProof builds a proof of possession of creds, linking their
+// unrevealed known attributes as given by links. The nonce is obtained from the
+// verifier.
+func BuildAggregateProof(creds []*AggregateCred, links [][]AttrRef,
+	nonce *big.Int) (*AggregateProof, error) {
+	revealed := make([]*RevealedCred, len(creds))
+	for i, c := range creds {
+		if c.Manager.V1 == nil {
+			return nil, fmt.Errorf("credential %d: v1 is not set (generated in GetCredRequest)", i)
+		}
+		known, committed := c.Manager.FilterAttributes(c.RevealedKnownAttrsIndices,
+			c.RevealedCommitmentsOfAttrsIndices)
+		revealed[i] = &RevealedCred{
+			PubKey:                            c.Manager.PubKey,
+			RevealedKnownAttrsIndices:         c.RevealedKnownAttrsIndices,
+			RevealedCommitmentsOfAttrsIndices: c.RevealedCommitmentsOfAttrsIndices,
+			RevealedKnownAttrs:                known,
+			RevealedCommitmentsOfAttrs:        committed,
+		}
+	}
+
+	layout, nSecrets, err := aggregateLayout(revealed, links)
+	if err != nil {
+		return nil, err
+	}
+
+	As := make([]*big.Int, len(creds))
+	statements := make([]*qr.RepresentationStatement, len(creds))
+	secrets := make([]*big.Int, nSecrets)
+	boundaries := make([]int, nSecrets)
+	for i, c := range creds {
+		m := c.Manager
+		rCred := m.randomize(c.Cred)
+		As[i] = rCred.A
+		statements[i], err = credStatement(revealed[i], rCred.A, layout[i])
+		if err != nil {
+			return nil, err
+		}
+
+		vals, bounds := credSecrets(m, rCred, c.RevealedKnownAttrsIndices,
+			c.RevealedCommitmentsOfAttrsIndices)
+		if len(vals) != len(layout[i]) {
+			return nil, fmt.Errorf("credential %d does not match the public key", i)
+		}
+		for k, s := range layout[i] {
+			if secrets[s] != nil && secrets[s].Cmp(vals[k]) != 0 {
+				return nil, fmt.Errorf("linked attributes are not equal")
+			}
+			secrets[s] = vals[k]
+			if bounds[k] > boundaries[s] {
+				boundaries[s] = bounds[k]
+			}
+		}
+	}
+
+	prover, err := qr.NewAggregateProver(statements, secrets)
+	if err != nil {
+		return nil, err
+	}
+	proofRandomData, err := prover.GetProofRandomData(boundaries, true)
+	if err != nil {
+		return nil, fmt.Errorf("error when generating aggregate proof random data: %s", err)
+	}
+	challenge := aggregateChallenge(revealed, As, proofRandomData, nonce)
+
+	return &AggregateProof{
+		As:             As,
+		Links:          links,
+		AggregateProof: qr.NewAggregateProof(challenge, prover.GetProofData(challenge)),
+	}, nil
+}
+
+// VerifyAggregateProof verifies an aggregate proof of possession of credentials
+// that reveal creds. Verifiers need to check that links of the proof are those
+// they require.
+func VerifyAggregateProof(creds []*RevealedCred, proof *AggregateProof,
+	nonce *big.Int) (bool, error) {
+	if proof == nil || proof.AggregateProof == nil || len(proof.As) != len(creds) {
+		return false, fmt.Errorf("incomplete proof")
+	}
+	for i, c := range creds {
+		if err := checkRevealed(c.RevealedKnownAttrsIndices, c.RevealedKnownAttrs,
+			len(c.PubKey.RsKnown)); err != nil {
+			return false, fmt.Errorf("credential %d: known attributes: %v", i, err)
+		}
+		if err := checkRevealed(c.RevealedCommitmentsOfAttrsIndices,
+			c.RevealedCommitmentsOfAttrs, len(c.PubKey.RsCommitted)); err != nil {
+			return false, fmt.Errorf("credential %d: commitments of attributes: %v", i, err)
+		}
+		if proof.As[i] == nil {
+			return false, fmt.Errorf("incomplete proof")
+		}
+	}
+
+	layout, _, err := aggregateLayout(creds, proof.Links)
+	if err != nil {
+		return false, err
+	}
+	statements := make([]*qr.RepresentationStatement, len(creds))
+	for i, c := range creds {
+		if statements[i], err = credStatement(c, proof.As[i], layout[i]); err != nil {
+			return false, err
+		}
+	}
+
+	return proof.Verify(statements, func(proofRandomData []*big.Int) *big.Int {
+		return aggregateChallenge(creds, proof.As, proofRandomData, nonce)
+	}), nil
+}
+
+// credSecrets returns the secrets of the statement about the randomized credential
+// rCred (see credStatement), that is unrevealed attributes, e and v, along with
+// bit lengths of the random values used to prove their knowledge.
+func credSecrets(m *CredManager, rCred *Cred, revealedKnownAttrsIndices,
+	revealedCommitmentsOfAttrsIndices []int) ([]*big.Int, []int) {
+	vals := make([]*big.Int, 0, len(m.Attrs.Known)+len(m.CommitmentsOfAttrs)+
+		len(m.Attrs.Hidden)+2)
+	for j, a := range m.Attrs.Known {
+		if !common.Contains(revealedKnownAttrsIndices, j) {
+			vals = append(vals, a)
+		}
+	}
+	for j, a := range m.CommitmentsOfAttrs {
+		if !common.Contains(revealedCommitmentsOfAttrsIndices, j) {
+			vals = append(vals, a)
+		}
+	}
+	vals = append(vals, m.Attrs.Hidden...)
+	vals = append(vals, rCred.E, new(big.Int).Add(rCred.V11, m.V1))
+
+	bounds := make([]int, len(vals))
+	for k := range bounds {
+		bounds[k] = m.Params.AttrBitLen + m.Params.SecParam + m.Params.HashBitLen
+	}
+	bounds[len(vals)-2] = m.Params.EBitLen + m.Params.SecParam + m.Params.HashBitLen
+	bounds[len(vals)-1] = m.Params.VBitLen + m.Params.SecParam + m.Params.HashBitLen
+
+	return vals, bounds
+}
+
+// aggregateLayout assigns indices of secrets of an aggregate proof to each
+// credential's unrevealed attributes, e and v, in the order of bases of
+// credStatement. Attributes in the same link share an index. It returns the
+// indices for each credential and the number of secrets.
+func aggregateLayout(creds []*RevealedCred, links [][]AttrRef) ([][]int, int, error) {
+	linked := make(map[AttrRef]int)
+	for l, link := range links {
+		if len(link) < 2 {
+			return nil, 0, fmt.Errorf("link %d refers to less than two attributes", l)
+		}
+		for _, ref := range link {
+			if ref.Cred < 0 || ref.Cred >= len(creds) || ref.Attr < 0 ||
+				ref.Attr >= len(creds[ref.Cred].PubKey.RsKnown) {
+				return nil, 0, fmt.Errorf("link %d refers to a nonexistent attribute", l)
+			}
+			if common.Contains(creds[ref.Cred].RevealedKnownAttrsIndices, ref.Attr) {
+				return nil, 0, fmt.Errorf("link %d refers to a revealed attribute", l)
+			}
+			if _, ok := linked[ref]; ok {
+				return nil, 0, fmt.Errorf("attribute %d of credential %d is linked more than once",
+					ref.Attr, ref.Cred)
+			}
+			linked[ref] = l
+		}
+	}
+
+	n := 0
+	linkSecrets := make(map[int]int)
+	layout := make([][]int, len(creds))
+	for i, c := range creds {
+		for j := range c.PubKey.RsKnown {
+			if common.Contains(c.RevealedKnownAttrsIndices, j) {
+				continue
+			}
+			if l, ok := linked[AttrRef{Cred: i, Attr: j}]; ok {
+				if s, ok := linkSecrets[l]; ok {
+					layout[i] = append(layout[i], s)
+					continue
+				}
+				linkSecrets[l] = n
+			}
+			layout[i] = append(layout[i], n)
+			n++
+		}
+		nOther := len(c.PubKey.RsCommitted) - len(c.RevealedCommitmentsOfAttrsIndices) +
+			len(c.PubKey.RsHidden) + 2 // e and v
+		for k := 0; k < nOther; k++ {
+			layout[i] = append(layout[i], n)
+			n++
+		}
+	}
+
+	return layout, n, nil
+}
+
+// credStatement returns the statement Z / (R_i^attr_i * ...) = R_j^attr_j * ... * A^e * S^v,
+// where the left side holds revealed attributes of c and the right side the
+// unrevealed ones, with secrets given by indices.
+func credStatement(c *RevealedCred, A *big.Int, indices []int) (*qr.RepresentationStatement,
+	error) {
+	k := c.PubKey
+	group := qr.NewRSApecialPublic(k.N)
+
+	bases := make([]*big.Int, 0, len(indices))
+	for i, R := range k.RsKnown {
+		if !common.Contains(c.RevealedKnownAttrsIndices, i) {
+			bases = append(bases, R)
+		}
+	}
+	for i, R := range k.RsCommitted {
+		if !common.Contains(c.RevealedCommitmentsOfAttrsIndices, i) {
+			bases = append(bases, R)
+		}
+	}
+	bases = append(bases, k.RsHidden...)
+	bases = append(bases, A, k.S)
+
+	revealedBases := make([]*big.Int, 0, len(c.RevealedKnownAttrs)+len(c.RevealedCommitmentsOfAttrs))
+	revealedVals := make([]*big.Int, 0, cap(revealedBases))
+	for i, ind := range c.RevealedKnownAttrsIndices {
+		revealedBases = append(revealedBases, k.RsKnown[ind])
+		revealedVals = append(revealedVals, c.RevealedKnownAttrs[i])
+	}
+	for i, ind := range c.RevealedCommitmentsOfAttrsIndices {
+		revealedBases = append(revealedBases, k.RsCommitted[ind])
+		revealedVals = append(revealedVals, c.RevealedCommitmentsOfAttrs[i])
+	}
+	denom := common.MultiExp(new(big.Int), revealedBases, revealedVals, k.N)
+	if denom == nil {
+		return nil, fmt.Errorf("revealed attributes not valid")
+	}
+
+	return &qr.RepresentationStatement{
+		Group:   group,
+		Bases:   bases,
+		Y:       group.Mul(k.Z, group.Inv(denom)),
+		Secrets: indices,
+	}, nil
+}
+
+// aggregateChallenge derives the challenge of an aggregate proof from contexts of
+// the issuers' public keys, randomized credentials, proof random data and the nonce.
+func aggregateChallenge(creds []*RevealedCred, As, proofRandomData []*big.Int,
+	nonce *big.Int) *big.Int {
+	l := make([]*big.Int, 0, 2*len(creds)+len(proofRandomData)+1)
+	for i, c := range creds {
+		l = append(l, c.PubKey.GetContext(), As[i])
+	}
+	l = append(l, proofRandomData...)
+	l = append(l, nonce)
+
+	return common.Hash(l...)
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issueTestCred issues a credential with the given name and gender by org.
+func issueTestCred(t *testing.T, params *Params, org *Org, name, gender string) (*CredManager,
+	*Cred) {
+	cred := NewRawCred(NewAttrCount(5, 1, 0))
+	_ = cred.AddStrAttr("Name", name, true)
+	_ = cred.AddStrAttr("Gender", gender, true)
+	_ = cred.AddStrAttr("Graduated", "true", true)
+	_ = cred.AddInt64Attr("DateMin", 22342345, true)
+	_ = cred.AddInt64Attr("DateMax", 32342345, true)
+	_ = cred.AddInt64Attr("Age", 25, false)
+
+	cm, err := NewCredManager(params, org.Keys.Pub, org.Keys.Pub.GenerateUserMasterSecret(), cred)
+	require.NoError(t, err)
+	credReq, err := cm.GetCredRequest(org.GetCredIssueNonce())
+	require.NoError(t, err)
+	res, err := org.IssueCred(credReq)
+	require.NoError(t, err)
+	ok, err := cm.Verify(res.Cred, res.AProof)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	return cm, res.Cred
+}
+
+func TestAggregateProof(t *testing.T) {
+	params, err := LoadParams()
+	require.NoError(t, err)
+	org := loadTestOrg(t, params)
+
+	cm1, cred1 := issueTestCred(t, params, org, "Jack", "M")
+	cm2, cred2 := issueTestCred(t, params, org, "Jack", "F")
+	creds := []*AggregateCred{
+		{Manager: cm1, Cred: cred1, RevealedKnownAttrsIndices: []int{1},
+			RevealedCommitmentsOfAttrsIndices: []int{0}},
+		{Manager: cm2, Cred: cred2, RevealedKnownAttrsIndices: []int{1, 2}},
+	}
+	revealed := make([]*RevealedCred, len(creds))
+	for i, c := range creds {
+		known, committed := c.Manager.FilterAttributes(c.RevealedKnownAttrsIndices,
+			c.RevealedCommitmentsOfAttrsIndices)
+		revealed[i] = &RevealedCred{
+			PubKey:                            org.Keys.Pub,
+			RevealedKnownAttrsIndices:         c.RevealedKnownAttrsIndices,
+			RevealedCommitmentsOfAttrsIndices: c.RevealedCommitmentsOfAttrsIndices,
+			RevealedKnownAttrs:                known,
+			RevealedCommitmentsOfAttrs:        committed,
+		}
+	}
+	// both credentials hold the same unrevealed name
+	links := [][]AttrRef{{{Cred: 0, Attr: 0}, {Cred: 1, Attr: 0}}}
+
+	nonce := org.GetProveCredNonce()
+	proof, err := BuildAggregateProof(creds, links, nonce)
+	require.NoError(t, err)
+	verified, err := VerifyAggregateProof(revealed, proof, nonce)
+	require.NoError(t, err)
+	assert.True(t, verified, "aggregate proof should be verified")
+
+	// the aggregate proof is smaller than separate proofs
+	_, proof1, err := cm1.BuildProof(cred1, creds[0].RevealedKnownAttrsIndices,
+		creds[0].RevealedCommitmentsOfAttrsIndices, nonce)
+	require.NoError(t, err)
+	_, proof2, err := cm2.BuildProof(cred2, creds[1].RevealedKnownAttrsIndices,
+		creds[1].RevealedCommitmentsOfAttrsIndices, nonce)
+	require.NoError(t, err)
+	assert.Equal(t, len(proof1.ProofData)+len(proof2.ProofData)-1, len(proof.ProofData))
+
+	verified, err = VerifyAggregateProof(revealed, proof, big.NewInt(42))
+	require.NoError(t, err)
+	assert.False(t, verified, "aggregate proof for another nonce should not be verified")
+
+	proof.Links = nil
+	verified, err = VerifyAggregateProof(revealed, proof, nonce)
+	assert.False(t, verified, "aggregate proof with removed links should not be verified")
+	proof.Links = links
+
+	revealed[1].RevealedKnownAttrs[0] = cm1.Attrs.Known[1]
+	verified, err = VerifyAggregateProof(revealed, proof, nonce)
+	require.NoError(t, err)
+	assert.False(t, verified, "aggregate proof with changed attributes should not be verified")
+
+	_, err = BuildAggregateProof(creds[:1], [][]AttrRef{{{Cred: 0, Attr: 1}, {Cred: 0, Attr: 2}}}, nonce)
+	assert.Error(t, err, "revealed attributes cannot be linked")
+	creds[1].RevealedKnownAttrsIndices = []int{2}
+	_, err = BuildAggregateProof(creds, [][]AttrRef{{{Cred: 0, Attr: 2}, {Cred: 1, Attr: 1}}}, nonce)
+	assert.Error(t, err, "attributes with different values cannot be linked")
+}
@@ -0,0 +1,252 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// AttrEncodingVersion is the version of the encoding of attribute values into
+// integers implemented by AttrEncoder. Credentials can only be shown to verifiers
+// using the same version of the encoding.
+const AttrEncodingVersion = 1
+
+// AttrHashDomain separates hashes of string attributes from other uses of SHA-256.
+const AttrHashDomain = "emmy/cl/attr/v1/string"
+
+// ErrHashedAttr is returned when decoding a string attribute whose value was too
+// long to be encoded directly and was hashed instead.
+var ErrHashedAttr = errors.New("value of attribute is hashed")
+
+// dateEpoch is the date encoded dates count days from.
+var dateEpoch = time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// maxDateDays is the encoding of 31 December 9999, the latest supported date.
+const maxDateDays = 3652058
+
+// AttrEncoder canonically encodes values of attributes into the integers that CL
+// credentials are issued on, so that independently built clients and servers agree
+// on the representation of attributes. Version 1 of the encoding (see
+// AttrEncodingVersion), for attributes of bit length L, is:
+//
+//	int64   the integer itself
+//	bool    1 for true and 0 for false
+//	date    the number of days since 1 January of year 1 (UTC)
+//	enum    the position of the value in the list of values, starting with 0
+//	bytes   the big-endian unsigned integer of the bytes
+//	string  the big-endian unsigned integer of its UTF-8 bytes, if it has less than
+//	        L bits and the string does not start with a NUL byte; otherwise the
+//	        hash H(s) mod 2^(L-1) + 2^(L-1), where H(s) is the big-endian integer of
+//	        the first ceil(L/8) bytes of SHA-256(D || 0 || s) || SHA-256(D || 1 || s)
+//	        || ..., with D the ASCII string AttrHashDomain and counters encoded as
+//	        4-byte big-endian integers
+//
+// Hashed strings have exactly L bits, so they never clash with directly encoded
+// ones. Strings are encoded as given, without Unicode normalization.
+type AttrEncoder struct {
+	bitLen int
+}
+
+// NewAttrEncoder returns an encoder of attributes for credentials with params.
+func NewAttrEncoder(params *Params) *AttrEncoder {
+	return &AttrEncoder{
+		bitLen: params.AttrBitLen,
+	}
+}
+
+// DefaultAttrEncoder encodes attributes for credentials with parameters of any of
+// the presets (see GetParamsPreset), which share the bit length of attributes.
+var DefaultAttrEncoder = NewAttrEncoder(GetDefaultParamSizes())
+
+// Encode returns the encoding of val as a value of attribute a.
+func (e *AttrEncoder) Encode(a CredAttr, val interface{}) (*big.Int, error) {
+	switch t := a.(type) {
+	case *StrAttr:
+		s, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("value of %s must be a string", a.GetName())
+		}
+		return e.EncodeString(s), nil
+	case *Int64Attr:
+		switch n := val.(type) {
+		case int:
+			return e.EncodeInt64(int64(n)), nil
+		case int64:
+			return e.EncodeInt64(n), nil
+		}
+		return nil, fmt.Errorf("value of %s must be an integer", a.GetName())
+	case *BoolAttr:
+		b, ok := val.(bool)
+		if !ok {
+			return nil, fmt.Errorf("value of %s must be a boolean", a.GetName())
+		}
+		return e.EncodeBool(b), nil
+	case *EnumAttr:
+		s, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("value of %s must be a string", a.GetName())
+		}
+		return e.EncodeEnum(t.Values, s)
+	}
+
+	// dates, blobs and bytes accept several representations of their values
+	var tmp CredAttr
+	switch t := a.(type) {
+	case *BlobAttr:
+		tmp = NewEmptyBlobAttr(a.GetName(), a.IsKnown())
+	case *DateAttr:
+		tmp = NewEmptyDateAttr(a.GetName(), a.IsKnown())
+	case *BytesAttr:
+		tmp = NewEmptyBytesAttr(a.GetName(), t.Size, a.IsKnown())
+	default:
+		return nil, fmt.Errorf("unsupported attribute type: %T", a)
+	}
+	if err := tmp.UpdateValue(val); err != nil {
+		return nil, err
+	}
+
+	return tmp.InternalValue(), nil
+}
+
+// EncodeString returns the encoding of string s.
+func (e *AttrEncoder) EncodeString(s string) *big.Int {
+	v := new(big.Int).SetBytes([]byte(s))
+	if s == "" || (s[0] != 0 && v.BitLen() < e.bitLen) {
+		return v
+	}
+
+	n := (e.bitLen + 7) / 8
+	var digest []byte
+	var counter [4]byte
+	for i := uint32(0); len(digest) < n; i++ {
+		binary.BigEndian.PutUint32(counter[:], i)
+		h := sha256.New()
+		h.Write([]byte(AttrHashDomain))
+		h.Write(counter[:])
+		h.Write([]byte(s))
+		digest = h.Sum(digest)
+	}
+
+	top := new(big.Int).Lsh(big.NewInt(1), uint(e.bitLen-1))
+	v.SetBytes(digest[:n])
+	v.Mod(v, top)
+	return v.Add(v, top)
+}
+
+// DecodeString returns the string encoded as v. It returns ErrHashedAttr if the
+// string was hashed.
+func (e *AttrEncoder) DecodeString(v *big.Int) (string, error) {
+	if v.Sign() < 0 || v.BitLen() > e.bitLen {
+		return "", fmt.Errorf("value is not a string")
+	}
+	if e.IsHashed(v) {
+		return "", ErrHashedAttr
+	}
+	return string(v.Bytes()), nil
+}
+
+// IsHashed returns true if v is the encoding of a hashed string.
+func (e *AttrEncoder) IsHashed(v *big.Int) bool {
+	return v.BitLen() == e.bitLen
+}
+
+// EncodeInt64 returns the encoding of integer n.
+func (e *AttrEncoder) EncodeInt64(n int64) *big.Int {
+	return big.NewInt(n)
+}
+
+// DecodeInt64 returns the integer encoded as v.
+func (e *AttrEncoder) DecodeInt64(v *big.Int) (int64, error) {
+	if !v.IsInt64() {
+		return 0, fmt.Errorf("value is not a 64-bit integer")
+	}
+	return v.Int64(), nil
+}
+
+// EncodeBool returns the encoding of flag b.
+func (e *AttrEncoder) EncodeBool(b bool) *big.Int {
+	if b {
+		return big.NewInt(1)
+	}
+	return big.NewInt(0)
+}
+
+// DecodeBool returns the flag encoded as v.
+func (e *AttrEncoder) DecodeBool(v *big.Int) (bool, error) {
+	if !v.IsInt64() || (v.Int64() != 0 && v.Int64() != 1) {
+		return false, fmt.Errorf("value is not a boolean")
+	}
+	return v.Int64() == 1, nil
+}
+
+// EncodeDate returns the encoding of the calendar date of t in its location.
+func (e *AttrEncoder) EncodeDate(t time.Time) (*big.Int, error) {
+	if t.Year() < 1 || t.Year() > 9999 {
+		return nil, fmt.Errorf("date out of range")
+	}
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	days := d.Unix()/(24*60*60) - dateEpoch.Unix()/(24*60*60)
+	return big.NewInt(days), nil
+}
+
+// DecodeDate returns the date encoded as v, at midnight UTC.
+func (e *AttrEncoder) DecodeDate(v *big.Int) (time.Time, error) {
+	if v.Sign() < 0 || !v.IsInt64() || v.Int64() > maxDateDays {
+		return time.Time{}, fmt.Errorf("value is not a date")
+	}
+	return dateEpoch.AddDate(0, 0, int(v.Int64())), nil
+}
+
+// EncodeEnum returns the encoding of val, one of values.
+func (e *AttrEncoder) EncodeEnum(values []string, val string) (*big.Int, error) {
+	for i, v := range values {
+		if v == val {
+			return big.NewInt(int64(i)), nil
+		}
+	}
+	return nil, fmt.Errorf("%s is not one of the values", val)
+}
+
+// DecodeEnum returns the value of values encoded as v.
+func (e *AttrEncoder) DecodeEnum(values []string, v *big.Int) (string, error) {
+	if v.Sign() < 0 || !v.IsInt64() || v.Int64() >= int64(len(values)) {
+		return "", fmt.Errorf("value is not one of the values")
+	}
+	return values[v.Int64()], nil
+}
+
+// EncodeBytes returns the encoding of b.
+func (e *AttrEncoder) EncodeBytes(b []byte) *big.Int {
+	return new(big.Int).SetBytes(b)
+}
+
+// DecodeBytes returns the size bytes encoded as v.
+func (e *AttrEncoder) DecodeBytes(v *big.Int, size int) ([]byte, error) {
+	if v.Sign() < 0 || v.BitLen() > 8*size {
+		return nil, fmt.Errorf("value does not fit into %d bytes", size)
+	}
+	b := make([]byte, size)
+	vb := v.Bytes()
+	copy(b[size-len(vb):], vb)
+	return b, nil
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAttrEncoder checks the encoding against test vectors, which implementations in
+// other languages are expected to reproduce.
+func TestAttrEncoder(t *testing.T) {
+	e := DefaultAttrEncoder
+	hex := func(v *big.Int) string { return v.Text(16) }
+
+	assert.Equal(t, "0", hex(e.EncodeString("")))
+	assert.Equal(t, "4a61636b", hex(e.EncodeString("Jack")))
+	assert.Equal(t, strings.Repeat("61", 32), hex(e.EncodeString(strings.Repeat("a", 32))))
+	assert.Equal(t, "ca88174b07142cff94c85baec6bb8fc5dac05ee9a8e292d6def18be10e61f963",
+		hex(e.EncodeString(strings.Repeat("a", 40))))
+	assert.Equal(t, "81296646740df518560bf589954430f32a0222b04f74eadabad3573672fa0ab9",
+		hex(e.EncodeString("\x00a")), "strings starting with NUL are hashed")
+	assert.Equal(t, "9b168cfd5039c3bd2486589de957eeda89f5e812912a7d4f18059227716a7692",
+		hex(e.EncodeString("ž"+strings.Repeat("a", 30))))
+	assert.Equal(t, "a88174b07142cff94c85baec6bb8fc5dac05ee9a8e292d6def18be10e61f9630cef1457806e",
+		hex(NewAttrEncoder(&Params{AttrBitLen: 300}).EncodeString(strings.Repeat("a", 40))))
+
+	s, err := e.DecodeString(e.EncodeString("Jack"))
+	assert.NoError(t, err)
+	assert.Equal(t, "Jack", s)
+	_, err = e.DecodeString(e.EncodeString(strings.Repeat("a", 40)))
+	assert.Equal(t, ErrHashedAttr, err)
+
+	d, err := e.EncodeDate(time.Date(2017, time.December, 7, 15, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(736669), d)
+	_, err = e.EncodeDate(time.Time{}.AddDate(-1, 0, 0))
+	assert.Error(t, err)
+
+	v, err := e.EncodeEnum([]string{"single", "married"}, "married")
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), v)
+	_, err = e.EncodeEnum([]string{"single", "married"}, "divorced")
+	assert.Error(t, err)
+
+	assert.Equal(t, big.NewInt(1), e.EncodeBool(true))
+	assert.Equal(t, big.NewInt(-5), e.EncodeInt64(-5))
+	b, err := e.DecodeBytes(e.EncodeBytes([]byte{0, 1}), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0, 1}, b)
+}
+
+func TestAttrEncoderEncode(t *testing.T) {
+	e := DefaultAttrEncoder
+	long := strings.Repeat("Jack", 10)
+
+	a, err := NewStrAttr("Name", long, true)
+	assert.NoError(t, err)
+	v, err := e.Encode(a, long)
+	assert.NoError(t, err)
+	assert.Equal(t, a.InternalValue(), v, "attributes are encoded by the encoder")
+	assert.Equal(t, 256, v.BitLen())
+	_, err = e.Encode(a, 42)
+	assert.Error(t, err)
+
+	date := NewEmptyDateAttr("BirthDate", true)
+	v, err = e.Encode(date, "1970-01-01")
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(719162), v)
+
+	enum := NewEmptyEnumAttr("Status", []string{"single", "married"}, true)
+	v, err = e.Encode(enum, "single")
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(0), v)
+}
@@ -18,9 +18,12 @@
 package cl
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"math/big"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // AttrCount holds the number of known, committed and
@@ -125,7 +128,7 @@ func NewInt64Attr(name string, val int64, known bool) (*Int64Attr,
 }
 
 func (a *Int64Attr) SetInternalValue() error {
-	a.attr.val = big.NewInt(int64(a.val)) // FIXME
+	a.attr.val = DefaultAttrEncoder.EncodeInt64(a.val)
 	a.valSet = true
 	return nil
 }
@@ -134,8 +137,10 @@ func (a *Int64Attr) GetValue() interface{} {
 	return a.val
 }
 
+// FromInternalValue returns the value as int.
 func (a *Int64Attr) FromInternalValue(val *big.Int) (interface{}, error) {
-	return strconv.Atoi(val.String())
+	n, err := DefaultAttrEncoder.DecodeInt64(val)
+	return int(n), err
 }
 
 func (a *Int64Attr) UpdateValue(n interface{}) error {
@@ -176,8 +181,10 @@ func NewStrAttr(name, val string, known bool) (*StrAttr,
 	return a, nil
 }
 
+// SetInternalValue encodes the value with DefaultAttrEncoder. Values that are too
+// long to be encoded directly are hashed.
 func (a *StrAttr) SetInternalValue() error {
-	a.attr.val = new(big.Int).SetBytes([]byte(a.val)) // FIXME
+	a.attr.val = DefaultAttrEncoder.EncodeString(a.val)
 	a.valSet = true
 	return nil
 }
@@ -186,8 +193,18 @@ func (a *StrAttr) GetValue() interface{} {
 	return a.val
 }
 
+// FromInternalValue returns the string encoded as val, or ErrHashedAttr if it was
+// hashed.
 func (a *StrAttr) FromInternalValue(val *big.Int) (interface{}, error) {
-	return string(val.Bytes()), nil
+	return DefaultAttrEncoder.DecodeString(val)
+}
+
+// setHashed sets the internal value to v, the encoding of a hashed string, which
+// leaves the value of the attribute empty.
+func (a *StrAttr) setHashed(v *big.Int) {
+	a.val = ""
+	a.attr.val = v
+	a.valSet = true
 }
 
 func (a *StrAttr) UpdateValue(s interface{}) error {
@@ -199,6 +216,310 @@ func (a *StrAttr) String() string {
 	return fmt.Sprintf("%s, type = %T", a.attr.String(), a.val)
 }
 
+// BlobRootSize is the size of roots of commitments held by BlobAttr.
+const BlobRootSize = sha256.Size
+
+// BlobAttr is an attribute backed by a large binary object, such as a biometric
+// template or a document. It holds the root of a chunked commitment to the object
+// (see package merkle), while the object itself is kept separately. Revealing the
+// attribute allows the holder to prove that selected chunks are part of the object.
+type BlobAttr struct {
+	val []byte
+	*attr
+}
+
+func NewEmptyBlobAttr(name string, known bool) *BlobAttr {
+	return &BlobAttr{
+		attr: newAttr(name, known),
+	}
+}
+
+func NewBlobAttr(name string, root []byte, known bool) (*BlobAttr, error) {
+	a := NewEmptyBlobAttr(name, known)
+	if err := a.UpdateValue(root); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (a *BlobAttr) SetInternalValue() error {
+	a.attr.val = DefaultAttrEncoder.EncodeBytes(a.val)
+	a.valSet = true
+	return nil
+}
+
+func (a *BlobAttr) GetValue() interface{} {
+	return a.val
+}
+
+func (a *BlobAttr) FromInternalValue(val *big.Int) (interface{}, error) {
+	root, err := DefaultAttrEncoder.DecodeBytes(val, BlobRootSize)
+	if err != nil {
+		return nil, fmt.Errorf("value is not a root of a commitment")
+	}
+	return root, nil
+}
+
+func (a *BlobAttr) UpdateValue(root interface{}) error {
+	r, ok := root.([]byte)
+	if !ok || len(r) != BlobRootSize {
+		return fmt.Errorf("value of %s must be a root of a commitment", a.Name)
+	}
+	a.val = r
+	return a.SetInternalValue()
+}
+
+func (a *BlobAttr) String() string {
+	return fmt.Sprintf("%s, type = blob", a.attr.String())
+}
+
+// DateAttr is an attribute holding a calendar date, such as a date of birth. Its
+// internal value is the number of days since 1 January of year 1, so that dates
+// compare the same way as their internal values.
+type DateAttr struct {
+	val time.Time
+	*attr
+}
+
+func NewEmptyDateAttr(name string, known bool) *DateAttr {
+	return &DateAttr{
+		attr: newAttr(name, known),
+	}
+}
+
+func NewDateAttr(name string, val time.Time, known bool) (*DateAttr, error) {
+	a := NewEmptyDateAttr(name, known)
+	if err := a.UpdateValue(val); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (a *DateAttr) SetInternalValue() error {
+	days, err := DefaultAttrEncoder.EncodeDate(a.val)
+	if err != nil {
+		return err
+	}
+	a.attr.val = days
+	a.valSet = true
+	return nil
+}
+
+// GetValue returns the date as time.Time at midnight UTC.
+func (a *DateAttr) GetValue() interface{} {
+	return a.val
+}
+
+func (a *DateAttr) FromInternalValue(val *big.Int) (interface{}, error) {
+	return DefaultAttrEncoder.DecodeDate(val)
+}
+
+// UpdateValue sets the date to d, given as time.Time (whose calendar date in its
+// location is taken) or as a string in the form 2006-01-02.
+func (a *DateAttr) UpdateValue(d interface{}) error {
+	var t time.Time
+	switch v := d.(type) {
+	case time.Time:
+		t = v
+	case string:
+		var err error
+		if t, err = time.Parse("2006-01-02", v); err != nil {
+			return fmt.Errorf("value of %s must be a date: %v", a.Name, err)
+		}
+	default:
+		return fmt.Errorf("value of %s must be a date", a.Name)
+	}
+	if t.Year() < 1 || t.Year() > 9999 {
+		return fmt.Errorf("date of %s out of range", a.Name)
+	}
+	a.val = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return a.SetInternalValue()
+}
+
+func (a *DateAttr) String() string {
+	return fmt.Sprintf("%s, type = date", a.attr.String())
+}
+
+// BoolAttr is an attribute holding a flag. Its internal value is 1 for true and 0
+// for false.
+type BoolAttr struct {
+	val bool
+	*attr
+}
+
+func NewEmptyBoolAttr(name string, known bool) *BoolAttr {
+	return &BoolAttr{
+		attr: newAttr(name, known),
+	}
+}
+
+func NewBoolAttr(name string, val bool, known bool) (*BoolAttr, error) {
+	a := NewEmptyBoolAttr(name, known)
+	if err := a.UpdateValue(val); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (a *BoolAttr) SetInternalValue() error {
+	a.attr.val = DefaultAttrEncoder.EncodeBool(a.val)
+	a.valSet = true
+	return nil
+}
+
+func (a *BoolAttr) GetValue() interface{} {
+	return a.val
+}
+
+func (a *BoolAttr) FromInternalValue(val *big.Int) (interface{}, error) {
+	return DefaultAttrEncoder.DecodeBool(val)
+}
+
+func (a *BoolAttr) UpdateValue(b interface{}) error {
+	v, ok := b.(bool)
+	if !ok {
+		return fmt.Errorf("value of %s must be a boolean", a.Name)
+	}
+	a.val = v
+	return a.SetInternalValue()
+}
+
+func (a *BoolAttr) String() string {
+	return fmt.Sprintf("%s, type = bool", a.attr.String())
+}
+
+// EnumAttr is an attribute holding one of a fixed list of strings, such as a marital
+// status. Its internal value is the position of its value in the list.
+type EnumAttr struct {
+	Values []string
+	val    string
+	*attr
+}
+
+func NewEmptyEnumAttr(name string, values []string, known bool) *EnumAttr {
+	return &EnumAttr{
+		Values: values,
+		attr:   newAttr(name, known),
+	}
+}
+
+func NewEnumAttr(name string, values []string, val string, known bool) (*EnumAttr, error) {
+	a := NewEmptyEnumAttr(name, values, known)
+	if err := a.UpdateValue(val); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (a *EnumAttr) SetInternalValue() error {
+	v, err := DefaultAttrEncoder.EncodeEnum(a.Values, a.val)
+	if err != nil {
+		return fmt.Errorf("%s is not a value of %s", a.val, a.Name)
+	}
+	a.attr.val = v
+	a.valSet = true
+	return nil
+}
+
+func (a *EnumAttr) GetValue() interface{} {
+	return a.val
+}
+
+func (a *EnumAttr) FromInternalValue(val *big.Int) (interface{}, error) {
+	v, err := DefaultAttrEncoder.DecodeEnum(a.Values, val)
+	if err != nil {
+		return nil, fmt.Errorf("value is not a value of %s", a.Name)
+	}
+	return v, nil
+}
+
+func (a *EnumAttr) UpdateValue(s interface{}) error {
+	v, ok := s.(string)
+	if !ok {
+		return fmt.Errorf("value of %s must be a string", a.Name)
+	}
+	a.val = v
+	return a.SetInternalValue()
+}
+
+func (a *EnumAttr) String() string {
+	return fmt.Sprintf("%s, type = enum(%s)", a.attr.String(), strings.Join(a.Values, "|"))
+}
+
+// BytesAttrMaxSize is the largest size of values of BytesAttr, for which their
+// internal values fit into attributes of default parameters.
+const BytesAttrMaxSize = 32
+
+// BytesAttr is an attribute holding a byte slice of a fixed size, such as a hash.
+// Its internal value is the big-endian integer of the slice, which is padded with
+// leading zeros to its size when converted back.
+type BytesAttr struct {
+	Size int
+	val  []byte
+	*attr
+}
+
+func NewEmptyBytesAttr(name string, size int, known bool) *BytesAttr {
+	return &BytesAttr{
+		Size: size,
+		attr: newAttr(name, known),
+	}
+}
+
+func NewBytesAttr(name string, val []byte, known bool) (*BytesAttr, error) {
+	a := NewEmptyBytesAttr(name, len(val), known)
+	if err := a.UpdateValue(val); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (a *BytesAttr) SetInternalValue() error {
+	a.attr.val = DefaultAttrEncoder.EncodeBytes(a.val)
+	a.valSet = true
+	return nil
+}
+
+func (a *BytesAttr) GetValue() interface{} {
+	return a.val
+}
+
+func (a *BytesAttr) FromInternalValue(val *big.Int) (interface{}, error) {
+	return DefaultAttrEncoder.DecodeBytes(val, a.Size)
+}
+
+func (a *BytesAttr) UpdateValue(b interface{}) error {
+	if a.Size < 1 || a.Size > BytesAttrMaxSize {
+		return fmt.Errorf("size of %s must be between 1 and %d bytes", a.Name,
+			BytesAttrMaxSize)
+	}
+	v, ok := b.([]byte)
+	if !ok || len(v) != a.Size {
+		return fmt.Errorf("value of %s must be %d bytes", a.Name, a.Size)
+	}
+	a.val = append([]byte{}, v...)
+	return a.SetInternalValue()
+}
+
+func (a *BytesAttr) String() string {
+	return fmt.Sprintf("%s, type = bytes(%d)", a.attr.String(), a.Size)
+}
+
+// parseAttrType splits type t of an attribute into its name and argument, given in
+// parentheses (for example enum(single|married) or bytes(32)).
+func parseAttrType(t string) (string, string) {
+	i := strings.Index(t, "(")
+	if i < 0 || !strings.HasSuffix(t, ")") {
+		return t, ""
+	}
+	return t[:i], t[i+1 : len(t)-1]
+}
+
 // FIXME make nicer
 // Hook to organization?
 func ParseAttrs(specs map[string]interface{}) ([]CredAttr, *AttrCount, error) {
@@ -224,6 +545,9 @@ func ParseAttrs(specs map[string]interface{}) ([]CredAttr, *AttrCount, error) {
 		if err != nil {
 			return nil, nil, fmt.Errorf("index must be string")
 		}
+		if index < 0 || index >= len(attrs) || attrs[index] != nil {
+			return nil, nil, fmt.Errorf("invalid index of attribute %s", name)
+		}
 
 		known := true
 		k, ok := data["known"]
@@ -241,7 +565,8 @@ func ParseAttrs(specs map[string]interface{}) ([]CredAttr, *AttrCount, error) {
 			nCommitted++
 		}
 
-		switch t {
+		typ, arg := parseAttrType(fmt.Sprint(t))
+		switch typ {
 		case "string":
 			a, err := NewStrAttr(name, "", known) // FIXME
 			if err != nil {
@@ -254,6 +579,23 @@ func ParseAttrs(specs map[string]interface{}) ([]CredAttr, *AttrCount, error) {
 				return nil, nil, err
 			}
 			attrs[index] = a
+		case "blob":
+			attrs[index] = NewEmptyBlobAttr(name, known)
+		case "date":
+			attrs[index] = NewEmptyDateAttr(name, known)
+		case "bool":
+			attrs[index] = NewEmptyBoolAttr(name, known)
+		case "enum":
+			if arg == "" {
+				return nil, nil, fmt.Errorf("missing values of enum %s", name)
+			}
+			attrs[index] = NewEmptyEnumAttr(name, strings.Split(arg, "|"), known)
+		case "bytes":
+			size, err := strconv.Atoi(arg)
+			if err != nil || size < 1 || size > BytesAttrMaxSize {
+				return nil, nil, fmt.Errorf("invalid size of bytes %s", name)
+			}
+			attrs[index] = NewEmptyBytesAttr(name, size, known)
 		default:
 			return nil, nil, fmt.Errorf("unsupported attribute type: %s", t)
 		}
@@ -18,10 +18,13 @@
 package cl
 
 import (
+	"bytes"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/merkle"
 )
 
 func TestNewIntAttribute(t *testing.T) {
@@ -30,3 +33,140 @@ func TestNewIntAttribute(t *testing.T) {
 	assert.Equal(t, big.NewInt(100).Cmp(a.InternalValue()), 0)
 	assert.True(t, a.IsKnown())
 }
+
+func TestBlobAttribute(t *testing.T) {
+	blob := bytes.Repeat([]byte("emmy"), 1000)
+	c, err := merkle.Commit(bytes.NewReader(blob), 256)
+	assert.NoError(t, err)
+
+	a, err := NewBlobAttr("template", c.Root, true)
+	assert.NoError(t, err)
+	assert.True(t, a.IsKnown())
+
+	// the root has to be recovered from the revealed value of the attribute
+	root, err := a.FromInternalValue(a.InternalValue())
+	assert.NoError(t, err)
+	assert.Equal(t, c.Root, root)
+
+	openings, err := c.Open(bytes.NewReader(blob), 0, 7)
+	assert.NoError(t, err)
+	assert.NoError(t, merkle.VerifyOpenings(root.([]byte), openings))
+
+	assert.Error(t, a.UpdateValue([]byte("short")))
+	_, err = a.FromInternalValue(new(big.Int).Lsh(big.NewInt(1), 8*BlobRootSize))
+	assert.Error(t, err)
+}
+
+func TestDateAttribute(t *testing.T) {
+	a, err := NewDateAttr("BirthDate", time.Date(1969, time.December, 31, 23, 0, 0, 0,
+		time.UTC), true)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(719161), a.InternalValue())
+	d, err := a.FromInternalValue(a.InternalValue())
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(1969, time.December, 31, 0, 0, 0, 0, time.UTC), d)
+
+	// dates are ordered as their internal values
+	assert.NoError(t, a.UpdateValue("1970-01-01"))
+	assert.Equal(t, big.NewInt(719162), a.InternalValue())
+
+	assert.Error(t, a.UpdateValue("01.01.1970"))
+	assert.Error(t, a.UpdateValue(int64(0)))
+	_, err = a.FromInternalValue(big.NewInt(-1))
+	assert.Error(t, err)
+}
+
+func TestBoolAttribute(t *testing.T) {
+	a, err := NewBoolAttr("Adult", true, true)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), a.InternalValue())
+	assert.NoError(t, a.UpdateValue(false))
+	assert.Equal(t, big.NewInt(0), a.InternalValue())
+	b, err := a.FromInternalValue(big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, true, b)
+
+	assert.Error(t, a.UpdateValue("true"))
+	_, err = a.FromInternalValue(big.NewInt(2))
+	assert.Error(t, err)
+}
+
+func TestEnumAttribute(t *testing.T) {
+	values := []string{"single", "married", "divorced"}
+	a, err := NewEnumAttr("Status", values, "married", true)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), a.InternalValue())
+	v, err := a.FromInternalValue(big.NewInt(2))
+	assert.NoError(t, err)
+	assert.Equal(t, "divorced", v)
+
+	assert.Error(t, a.UpdateValue("widowed"))
+	_, err = NewEnumAttr("Status", values, "widowed", true)
+	assert.Error(t, err)
+	_, err = a.FromInternalValue(big.NewInt(3))
+	assert.Error(t, err)
+}
+
+func TestBytesAttribute(t *testing.T) {
+	hash := make([]byte, 32)
+	hash[1] = 42
+	a, err := NewBytesAttr("Hash", hash, true)
+	assert.NoError(t, err)
+	assert.Equal(t, new(big.Int).Lsh(big.NewInt(42), 8*30), a.InternalValue())
+
+	// leading zeros are kept
+	b, err := a.FromInternalValue(a.InternalValue())
+	assert.NoError(t, err)
+	assert.Equal(t, hash, b)
+
+	assert.Error(t, a.UpdateValue(hash[1:]))
+	_, err = NewBytesAttr("Hash", make([]byte, BytesAttrMaxSize+1), true)
+	assert.Error(t, err)
+	_, err = a.FromInternalValue(new(big.Int).Lsh(big.NewInt(1), 8*32))
+	assert.Error(t, err)
+}
+
+func TestParseAttrs(t *testing.T) {
+	spec := func(index, typ, known string) map[string]interface{} {
+		return map[string]interface{}{"index": index, "type": typ, "known": known}
+	}
+
+	attrs, count, err := ParseAttrs(map[string]interface{}{
+		"Name": spec("0", "string", "true"),
+		"Age":  spec("1", "int64", "false"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Name", attrs[0].GetName())
+	assert.Equal(t, "Age", attrs[1].GetName())
+	assert.Equal(t, NewAttrCount(1, 1, 0), count)
+
+	attrs, _, err = ParseAttrs(map[string]interface{}{
+		"BirthDate": spec("0", "date", "true"),
+		"Adult":     spec("1", "bool", "true"),
+		"Status":    spec("2", "enum(single|married)", "true"),
+		"Hash":      spec("3", "bytes(32)", "true"),
+	})
+	assert.NoError(t, err)
+	assert.IsType(t, &DateAttr{}, attrs[0])
+	assert.IsType(t, &BoolAttr{}, attrs[1])
+	assert.Equal(t, []string{"single", "married"}, attrs[2].(*EnumAttr).Values)
+	assert.Equal(t, 32, attrs[3].(*BytesAttr).Size)
+
+	for _, typ := range []string{"enum", "bytes", "bytes(33)", "float"} {
+		_, _, err = ParseAttrs(map[string]interface{}{
+			"Name": spec("0", typ, "true"),
+		})
+		assert.Error(t, err, typ)
+	}
+
+	_, _, err = ParseAttrs(map[string]interface{}{
+		"Name": spec("0", "string", "true"),
+		"Age":  spec("0", "int64", "false"),
+	})
+	assert.Error(t, err, "duplicated index should fail")
+
+	_, _, err = ParseAttrs(map[string]interface{}{
+		"Name": spec("2", "string", "true"),
+	})
+	assert.Error(t, err, "index out of range should fail")
+}
@@ -18,23 +18,37 @@
 package cl
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/commitments"
 )
 
+// testKeyDir holds the CL keys that tests use instead of generating keys, for
+// credentials with five known attributes and one committed attribute (see
+// GetDefaultParamSizes).
+const testKeyDir = "../../client/testdata"
+
+// loadTestOrg returns an organization with params and the keys of the test
+// configuration (see config.NewTestConfig).
+func loadTestOrg(t *testing.T, params *Params) *Org {
+	c, err := config.NewTestConfig(testKeyDir)
+	require.NoError(t, err)
+	pubKeyPath, secKeyPath := c.LoadCLKeyPaths()
+	org, err := LoadOrg(params, pubKeyPath, secKeyPath)
+	require.NoError(t, err)
+
+	return org
+}
+
 func TestCL(t *testing.T) {
 	params := GetDefaultParamSizes()
 	attrCount := NewAttrCount(5, 1, 0) // TODO: integrate this into GetDefaultParamSizes
 
-	org, err := NewOrg(params, attrCount)
-	if err != nil {
-		t.Errorf("error when generating CL org: %v", err)
-	}
-
-	// to generate new testing keys
-	//WriteGob("../../client/testdata/clPubKey.gob", org.Keys.Pub)
-	//WriteGob("../../client/testdata/clSecKey.gob", org.Keys.Sec)
+	org := loadTestOrg(t, params)
 
 	masterSecret := org.Keys.Pub.GenerateUserMasterSecret()
 
@@ -141,4 +155,192 @@ func TestCL(t *testing.T) {
 	}
 
 	assert.Equal(t, true, cVerified, "credential verification failed")
+
+	// the proof can be verified again with only the public key of the organization
+	pubOrg, err := NewOrgFromParams(params, &KeyPair{Pub: org.Keys.Pub})
+	assert.NoError(t, err)
+	pubOrg.SetProveCredNonce(nonce)
+	cVerified, err = pubOrg.VerifyCredProof(randCred.A, proof, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, revealedKnownAttrs, revealedCommitmentsOfAttrs)
+	assert.NoError(t, err)
+	assert.True(t, cVerified, "credential proof verification with public key failed")
+
+	// prove that the committed attribute (Age), whose commitment has been revealed,
+	// is at least 18
+	rangeProof, err := credMgr.BuildRangeProof(0, big.NewInt(18), big.NewInt(150), nonce)
+	if err != nil {
+		t.Errorf("error when building range proof: %v", err)
+	}
+	rVerified, err := org.VerifyRangeProof(revealedCommitmentsOfAttrs[0], rangeProof,
+		big.NewInt(18), big.NewInt(150), nonce)
+	if err != nil {
+		t.Errorf("error when verifying range proof: %v", err)
+	}
+	assert.Equal(t, true, rVerified, "range proof verification failed")
+
+	_, err = credMgr.BuildRangeProof(0, big.NewInt(30), big.NewInt(150), nonce)
+	assert.Error(t, err, "attribute out of the range should not be proved")
+
+	// in a non-interactive proof, the nonce is derived from a context agreed on by the
+	// user and the organization instead of being generated by the organization
+	context := big.NewInt(1234567)
+	niNonce := ContextNonce(params, context)
+	assert.True(t, niNonce.BitLen() <= params.SecParam, "nonce of a non-interactive proof too long")
+	randCred, proof, err = credMgr.BuildProof(res1.Cred, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, niNonce)
+	if err != nil {
+		t.Errorf("error when building credential proof: %v", err)
+	}
+	org.SetProveCredNonce(ContextNonce(params, context))
+	cVerified, err = org.ProveCred(randCred.A, proof, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, revealedKnownAttrs, revealedCommitmentsOfAttrs)
+	assert.Nil(t, err)
+	assert.True(t, cVerified, "non-interactive credential verification failed")
+
+	org.SetProveCredNonce(ContextNonce(params, big.NewInt(7654321)))
+	cVerified, _ = org.ProveCred(randCred.A, proof, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, revealedKnownAttrs, revealedCommitmentsOfAttrs)
+	assert.False(t, cVerified, "proof should be bound to its context")
+}
+
+func TestUpdateCommittedAttr(t *testing.T) {
+	params := GetDefaultParamSizes()
+	attrCount := NewAttrCount(5, 1, 0)
+	org := loadTestOrg(t, params)
+
+	cred := NewRawCred(attrCount)
+	_ = cred.AddStrAttr("Name", "Jack", true)
+	_ = cred.AddStrAttr("Gender", "M", true)
+	_ = cred.AddStrAttr("Graduated", "true", true)
+	_ = cred.AddInt64Attr("DateMin", 22342345, true)
+	_ = cred.AddInt64Attr("DateMax", 32342345, true)
+	_ = cred.AddInt64Attr("Age", 16, false)
+	credMgr, err := NewCredManager(params, org.Keys.Pub,
+		org.Keys.Pub.GenerateUserMasterSecret(), cred)
+	assert.NoError(t, err)
+
+	credReq, err := credMgr.GetCredRequest(org.GetCredIssueNonce())
+	assert.NoError(t, err)
+	res, err := org.IssueCred(credReq)
+	assert.NoError(t, err)
+	oldCommitment := credMgr.CommitmentsOfAttrs[0]
+
+	// the committed attribute changes without the credential being issued again
+	a, _ := cred.GetAttr("Age")
+	assert.NoError(t, a.UpdateValue(30))
+	assert.NoError(t, credMgr.Update(cred))
+	assert.NotEqual(t, oldCommitment, credMgr.CommitmentsOfAttrs[0])
+
+	// the commitment cannot be changed without a proof of its opening
+	u := credMgr.GetCredUpdate()
+	forged := *u
+	forged.CommitmentsOfAttrs = []*big.Int{new(big.Int).Add(u.CommitmentsOfAttrs[0],
+		big.NewInt(1))}
+	_, err = org.UpdateCredAttrs(res.Record, &forged)
+	assert.Error(t, err)
+
+	org, err = NewOrgFromParams(params, org.Keys)
+	assert.NoError(t, err)
+	res1, err := org.UpdateCredAttrs(res.Record, u)
+	assert.NoError(t, err)
+	assert.Equal(t, u.CommitmentsOfAttrs, res1.Record.CommitmentsOfAttrs)
+	userVerified, err := credMgr.Verify(res1.Cred, res1.AProof)
+	assert.NoError(t, err)
+	assert.True(t, userVerified, "update of committed attribute failed")
+
+	// the updated credential proves the new commitment, which opens to the new value
+	nonce := org.GetProveCredNonce()
+	randCred, proof, err := credMgr.BuildProof(res1.Cred, []int{0}, []int{0}, nonce)
+	assert.NoError(t, err)
+	revealedKnownAttrs, revealedCommitmentsOfAttrs := credMgr.FilterAttributes([]int{0},
+		[]int{0})
+	cVerified, err := org.ProveCred(randCred.A, proof, []int{0}, []int{0}, revealedKnownAttrs,
+		revealedCommitmentsOfAttrs)
+	assert.NoError(t, err)
+	assert.True(t, cVerified, "proof of updated credential not valid")
+	rangeProof, err := credMgr.BuildRangeProof(0, big.NewInt(18), big.NewInt(150), nonce)
+	assert.NoError(t, err)
+	rVerified, err := org.VerifyRangeProof(revealedCommitmentsOfAttrs[0], rangeProof,
+		big.NewInt(18), big.NewInt(150), nonce)
+	assert.NoError(t, err)
+	assert.True(t, rVerified, "range proof of updated attribute not valid")
+}
+
+func TestCLECCommitments(t *testing.T) {
+	params := GetDefaultParamSizes()
+	params.CommitmentScheme = commitments.SchemeECP256
+	attrCount := NewAttrCount(5, 1, 0)
+	org := loadTestOrg(t, params)
+
+	cred := NewRawCred(attrCount)
+	_ = cred.AddStrAttr("Name", "Jack", true)
+	_ = cred.AddStrAttr("Gender", "M", true)
+	_ = cred.AddStrAttr("Graduated", "true", true)
+	_ = cred.AddInt64Attr("DateMin", 22342345, true)
+	_ = cred.AddInt64Attr("DateMax", 32342345, true)
+	_ = cred.AddInt64Attr("Age", 25, false)
+	credMgr, err := NewCredManager(params, org.Keys.Pub,
+		org.Keys.Pub.GenerateUserMasterSecret(), cred)
+	require.NoError(t, err)
+	assert.True(t, credMgr.CommitmentsOfAttrs[0].BitLen() <= 257,
+		"commitment should be an encoded point of P-256")
+
+	credReq, err := credMgr.GetCredRequest(org.GetCredIssueNonce())
+	require.NoError(t, err)
+	forged := *credReq
+	forged.CommitmentsOfAttrs = []*big.Int{new(big.Int).Add(credReq.CommitmentsOfAttrs[0],
+		big.NewInt(2))}
+	_, err = org.IssueCred(&forged)
+	assert.Error(t, err, "commitment without a proof of its opening should be rejected")
+	res, err := org.IssueCred(credReq)
+	require.NoError(t, err)
+	userVerified, err := credMgr.Verify(res.Cred, res.AProof)
+	require.NoError(t, err)
+	assert.True(t, userVerified)
+
+	// the committed attribute is updated with a new commitment over the curve
+	a, _ := cred.GetAttr("Age")
+	require.NoError(t, a.UpdateValue(30))
+	require.NoError(t, credMgr.Update(cred))
+	org, err = NewOrgFromParams(params, org.Keys)
+	require.NoError(t, err)
+	res1, err := org.UpdateCredAttrs(res.Record, credMgr.GetCredUpdate())
+	require.NoError(t, err)
+	userVerified, err = credMgr.Verify(res1.Cred, res1.AProof)
+	require.NoError(t, err)
+	assert.True(t, userVerified, "update of committed attribute failed")
+
+	// the commitment is hidden as well as revealed in proofs
+	for _, revealed := range [][]int{{}, {0}} {
+		nonce := org.GetProveCredNonce()
+		randCred, proof, err := credMgr.BuildProof(res1.Cred, []int{0}, revealed, nonce)
+		require.NoError(t, err)
+		revealedKnownAttrs, revealedCommitmentsOfAttrs := credMgr.FilterAttributes([]int{0},
+			revealed)
+		cVerified, err := org.ProveCred(randCred.A, proof, []int{0}, revealed,
+			revealedKnownAttrs, revealedCommitmentsOfAttrs)
+		require.NoError(t, err)
+		assert.True(t, cVerified, "proof with revealed commitments %v not valid", revealed)
+	}
+
+	// range proofs of DF commitments need commitments in QR_N, Bulletproofs are
+	// built about commitments over the curve instead
+	_, err = credMgr.BuildRangeProof(0, big.NewInt(18), big.NewInt(150), big.NewInt(1))
+	assert.Error(t, err)
+	nonce := org.GetProveCredNonce()
+	rangeProof, err := credMgr.BuildAttrRangeProof(0, big.NewInt(18), big.NewInt(150), nonce)
+	require.NoError(t, err)
+	assert.Nil(t, rangeProof.Bulletproof.Commitment)
+	_, committed := credMgr.FilterAttributes([]int{}, []int{0})
+	ok, err := org.VerifyAttrRangeProofs([]*AttrRangeProof{rangeProof}, []int{0}, committed,
+		nonce)
+	require.NoError(t, err)
+	assert.True(t, ok, "Bulletproof about commitment over the curve should be accepted")
+
+	// the credential manager is restored with commitments over the curve
+	state, err := credMgr.State()
+	require.NoError(t, err)
+	restored, err := RestoreCredManager(state)
+	require.NoError(t, err)
+	assert.Equal(t, credMgr.CommitmentsOfAttrs, restored.CommitmentsOfAttrs)
 }
@@ -20,10 +20,11 @@ package cl
 import (
 	"fmt"
 	"math/big"
+	"sync"
 
 	"github.com/pkg/errors"
+	"github.com/xlab-si/emmy/crypto/commitments"
 	"github.com/xlab-si/emmy/crypto/common"
-	"github.com/xlab-si/emmy/crypto/df"
 	"github.com/xlab-si/emmy/crypto/pedersen"
 	"github.com/xlab-si/emmy/crypto/qr"
 	"github.com/xlab-si/emmy/crypto/schnorr"
@@ -48,10 +49,13 @@ type CredManager struct {
 	CommitmentsOfAttrs []*big.Int // commitments of committedAttrs
 	// V1 is a random element in credential - it is generated in GetCredRequest and needed when
 	// proving the possesion of a credential - this is why it is stored in User and not in UserCredentialReceiver
-	V1                        *big.Int            // v1 is random element in U; U = S^v1 * R_i^m_i where m_i are hidden attributes
-	attrsCommitters           []*df.Committer     // committers for committedAttrs
-	commitmentsOfAttrsProvers []*df.OpeningProver // for proving that you know how to open CommitmentsOfAttrs
-	CredReqNonce              *big.Int
+	V1 *big.Int // v1 is random element in U; U = S^v1 * R_i^m_i where m_i are hidden attributes
+	// committers for committedAttrs, which also prove that you know how to open CommitmentsOfAttrs
+	attrsCommitters []commitments.Committer
+	attrsScheme     commitments.Scheme // scheme of CommitmentsOfAttrs (see Params.CommitmentScheme)
+	CredReqNonce    *big.Int
+	precomputed     map[string][]*preparedProof // proofs prepared by Precompute
+	precomputedLock sync.Mutex
 }
 
 type Attrs struct {
@@ -89,6 +93,18 @@ func checkBitLen(s []*big.Int, len int) bool {
 	return true
 }
 
+// hiddenAttrs returns hidden attributes of credentials under pubKey. As in AnonCreds,
+// the only hidden attribute that keys can have holds the master secret of the holder.
+func hiddenAttrs(pubKey *PubKey, masterSecret *big.Int) ([]*big.Int, error) {
+	switch len(pubKey.RsHidden) {
+	case 0:
+		return []*big.Int{}, nil
+	case 1:
+		return []*big.Int{masterSecret}, nil
+	}
+	return nil, fmt.Errorf("keys with more than one hidden attribute are not supported")
+}
+
 func NewCredManager(params *Params, pubKey *PubKey,
 	masterSecret *big.Int, rawCred *RawCred) (*CredManager, error) {
 	if err := rawCred.missingAttrs(); err != nil {
@@ -98,18 +114,24 @@ func NewCredManager(params *Params, pubKey *PubKey,
 
 	known := rawCred.GetKnownVals()
 	committed := rawCred.GetCommittedVals()
-	hidden := []*big.Int{} // currently not used
+	hidden, err := hiddenAttrs(pubKey, masterSecret)
+	if err != nil {
+		return nil, err
+	}
 
 	attrs := NewAttrs(known, committed, hidden)
 	if !checkBitLen(attrs.join(), int(params.AttrBitLen)) {
 		return nil, fmt.Errorf("attributes length not ok")
 	}
 
-	attrsCommitters := make([]*df.Committer, len(attrs.Committed))
+	attrsScheme, err := attrsCommitmentScheme(params, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	attrsCommitters := make([]commitments.Committer, len(attrs.Committed))
 	commitmentsOfAttrs := make([]*big.Int, len(attrs.Committed))
 	for i, attr := range attrs.Committed {
-		committer := df.NewCommitter(pubKey.N1, pubKey.G, pubKey.H,
-			pubKey.N1, int(params.SecParam))
+		committer := attrsScheme.NewCommitter()
 		com, err := committer.GetCommitMsg(attr)
 		if err != nil {
 			return nil, fmt.Errorf("error when creating Pedersen commitment: %s", err)
@@ -117,22 +139,16 @@ func NewCredManager(params *Params, pubKey *PubKey,
 		commitmentsOfAttrs[i] = com
 		attrsCommitters[i] = committer
 	}
-	commitmentsOfAttrsProvers := make([]*df.OpeningProver, len(commitmentsOfAttrs))
-	for i, _ := range commitmentsOfAttrs {
-		prover := df.NewOpeningProver(attrsCommitters[i],
-			int(params.ChallengeSpace))
-		commitmentsOfAttrsProvers[i] = prover
-	}
 
 	credManager := CredManager{
-		Params:                    params,
-		PubKey:                    pubKey,
-		RawCred:                   rawCred,
-		Attrs:                     attrs,
-		CommitmentsOfAttrs:        commitmentsOfAttrs,
-		attrsCommitters:           attrsCommitters,
-		commitmentsOfAttrsProvers: commitmentsOfAttrsProvers,
-		masterSecret:              masterSecret,
+		Params:             params,
+		PubKey:             pubKey,
+		RawCred:            rawCred,
+		Attrs:              attrs,
+		CommitmentsOfAttrs: commitmentsOfAttrs,
+		attrsCommitters:    attrsCommitters,
+		attrsScheme:        attrsScheme,
+		masterSecret:       masterSecret,
 	}
 	credManager.generateNym()
 
@@ -166,8 +182,10 @@ func (m *CredManager) GetCredRequest(nonceOrg *big.Int) (*CredRequest, error) {
 		return nil, err
 	}
 
-	challenge := m.getCredReqChallenge(U, m.Nym, nonceOrg)
-	commitmentsOfAttrsProofs := m.getCommitmentsOfAttrsProof(challenge)
+	commitmentsOfAttrsRandomData := m.getCommitmentsOfAttrsProofRandomData()
+	challenge := m.getCredReqChallenge(U, m.Nym, nonceOrg, commitmentsOfAttrsRandomData)
+	commitmentsOfAttrsProofs := m.getCommitmentsOfAttrsProof(commitmentsOfAttrsRandomData,
+		challenge)
 
 	b := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(m.Params.SecParam)), nil)
 	nonce := common.GetRandomInt(b)
@@ -205,21 +223,24 @@ func (m *CredManager) Verify(cred *Cred, AProof *qr.RepresentationProof) (bool,
 
 	v := new(big.Int).Add(m.V1, cred.V11)
 	group := qr.NewRSApecialPublic(m.PubKey.N)
-	// denom = S^v * R_1^attr_1 * ... * R_j^attr_j
-	denom := group.Exp(m.PubKey.S, v) // s^v
-	for i := 0; i < len(m.Attrs.Known); i++ {
-		t1 := group.Exp(m.PubKey.RsKnown[i], m.Attrs.Known[i])
-		denom = group.Mul(denom, t1)
+	if err := group.CheckElement(cred.A); err != nil {
+		return false, fmt.Errorf("A: %w", err)
 	}
-
-	for i := 0; i < len(m.Attrs.Committed); i++ {
-		t1 := group.Exp(m.PubKey.RsCommitted[i], m.CommitmentsOfAttrs[i])
-		denom = group.Mul(denom, t1)
-	}
-
-	for i := 0; i < len(m.Attrs.Hidden); i++ {
-		t1 := group.Exp(m.PubKey.RsHidden[i], m.Attrs.Hidden[i])
-		denom = group.Mul(denom, t1)
+	// denom = S^v * R_1^attr_1 * ... * R_j^attr_j
+	n := 1 + len(m.Attrs.Known) + len(m.Attrs.Committed) + len(m.Attrs.Hidden)
+	bases := make([]*big.Int, 0, n)
+	exps := make([]*big.Int, 0, n)
+	bases = append(bases, m.PubKey.S)
+	exps = append(exps, v)
+	bases = append(bases, m.PubKey.RsKnown[:len(m.Attrs.Known)]...)
+	exps = append(exps, m.Attrs.Known...)
+	bases = append(bases, m.PubKey.RsCommitted[:len(m.Attrs.Committed)]...)
+	exps = append(exps, m.CommitmentsOfAttrs[:len(m.Attrs.Committed)]...)
+	bases = append(bases, m.PubKey.RsHidden[:len(m.Attrs.Hidden)]...)
+	exps = append(exps, m.Attrs.Hidden...)
+	denom := common.MultiExp(new(big.Int), bases, exps, group.N)
+	if denom == nil {
+		return false, fmt.Errorf("attributes not valid")
 	}
 
 	denomInv := group.Inv(denom)
@@ -244,10 +265,111 @@ func (m *CredManager) Verify(cred *Cred, AProof *qr.RepresentationProof) (bool,
 	return ver.Verify(AProof.ProofData), nil
 }
 
-// Update updates credential.
-func (m *CredManager) Update(c *RawCred) {
+// Update updates credential to the values of attributes of c. Committed attributes
+// whose values changed are committed to afresh, so that the issuer can be given the
+// new commitments along with proofs of their opening (see GetCredUpdate).
+func (m *CredManager) Update(c *RawCred) error {
+	committed := c.GetCommittedVals()
+	if len(committed) != len(m.Attrs.Committed) {
+		return fmt.Errorf("expected %d committed attributes, got %d",
+			len(m.Attrs.Committed), len(committed))
+	}
+	known := c.GetKnownVals()
+	if !checkBitLen(NewAttrs(known, committed, m.Attrs.Hidden).join(),
+		int(m.Params.AttrBitLen)) {
+		return fmt.Errorf("attributes length not ok")
+	}
+
+	// commitments are replaced rather than modified in place, as the slice of previous
+	// commitments may be held by others, such as in a credential request
+	commitmentsOfAttrs := append([]*big.Int(nil), m.CommitmentsOfAttrs...)
+	for i, attr := range committed {
+		if attr.Cmp(m.Attrs.Committed[i]) == 0 {
+			continue
+		}
+		committer := m.attrsScheme.NewCommitter()
+		com, err := committer.GetCommitMsg(attr)
+		if err != nil {
+			return fmt.Errorf("error when creating Pedersen commitment: %s", err)
+		}
+		m.attrsCommitters[i] = committer
+		commitmentsOfAttrs[i] = com
+	}
+	m.CommitmentsOfAttrs = commitmentsOfAttrs
 	m.RawCred = c
-	m.Attrs.Known = m.RawCred.GetKnownVals()
+	m.Attrs.Known = known
+	m.Attrs.Committed = committed
+	m.discardPrecomputed()
+
+	return nil
+}
+
+// GetCredUpdate returns the request to update the credential to the current values of
+// attributes (see Update). Commitments of attributes are sent along with proofs that
+// the receiver can open them, computed for a challenge bound to the nym and nonce.
+func (m *CredManager) GetCredUpdate() *CredUpdate {
+	randomData := m.getCommitmentsOfAttrsProofRandomData()
+	challenge := credUpdateChallenge(m.PubKey.GetContext(), m.Nym, m.CredReqNonce,
+		m.CommitmentsOfAttrs, randomData)
+
+	return &CredUpdate{
+		Nym:                      m.Nym,
+		Nonce:                    m.CredReqNonce,
+		KnownAttrs:               m.Attrs.Known,
+		CommitmentsOfAttrs:       m.CommitmentsOfAttrs,
+		CommitmentsOfAttrsProofs: m.getCommitmentsOfAttrsProof(randomData, challenge),
+	}
+}
+
+// committedAttrIndex returns the index of the committed attribute name amongst
+// committed attributes.
+func (m *CredManager) committedAttrIndex(name string) (int, error) {
+	a, err := m.RawCred.GetAttr(name)
+	if err != nil {
+		return -1, err
+	}
+	if a.IsKnown() {
+		return -1, fmt.Errorf("attribute %s is not committed", name)
+	}
+	return m.RawCred.GetAttrInternalIndex(name)
+}
+
+// CommitAttr commits to the value of the committed attribute name with randomness r
+// instead of a random one, and returns the commitment. This way the credential is
+// issued for a commitment that the receiver already holds, for example one that has
+// been shown to others, while the issuer only learns the commitment and a proof that
+// the receiver can open it. It needs to be called before GetCredRequest.
+func (m *CredManager) CommitAttr(name string, r *big.Int) (*big.Int, error) {
+	i, err := m.committedAttrIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil || r.Sign() < 0 {
+		return nil, fmt.Errorf("randomness of the commitment needs to be non-negative")
+	}
+
+	committer := m.attrsScheme.NewCommitter()
+	com, err := committer.GetCommitMsgWithGivenR(m.Attrs.Committed[i], r)
+	if err != nil {
+		return nil, err
+	}
+	m.attrsCommitters[i] = committer
+	m.CommitmentsOfAttrs[i] = com
+
+	return com, nil
+}
+
+// AttrCommitment returns the commitment of the committed attribute name, which the
+// credential is issued for, and the randomness r that opens it along with the value
+// of the attribute.
+func (m *CredManager) AttrCommitment(name string) (*big.Int, *big.Int, error) {
+	i, err := m.committedAttrIndex(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, r := m.attrsCommitters[i].GetDecommitMsg()
+
+	return m.CommitmentsOfAttrs[i], r, nil
 }
 
 // FilterAttributes returns only attributes to be revealed to the verifier.
@@ -293,21 +415,44 @@ func (m *CredManager) GetProofChallenge(credProofRandomData, nonceOrg *big.Int)
 	return common.Hash(l...)
 }
 
-// BuildProof builds a proof of knowledge for the given credential.
+// BuildProof builds a proof of knowledge for the given credential. A proof prepared
+// with Precompute for the same arguments is used if there is one, so that only the
+// challenge and responses are computed for nonceOrg.
 func (m *CredManager) BuildProof(cred *Cred, revealedKnownAttrsIndices,
 	revealedCommitmentsOfAttrsIndices []int, nonceOrg *big.Int) (*Cred,
 	*qr.RepresentationProof, error) {
+	p := m.takePrecomputed(cred, revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices)
+	if p == nil {
+		var err error
+		p, err = m.prepareProof(cred, revealedKnownAttrsIndices,
+			revealedCommitmentsOfAttrsIndices)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	challenge := m.GetProofChallenge(p.proofRandomData, nonceOrg)
+	proofData := p.prover.GetProofData(challenge)
+
+	return p.rCred, qr.NewRepresentationProof(p.proofRandomData, challenge, proofData), nil
+}
+
+// prepareProof randomizes cred and computes the random data of a proof of its
+// possession, which reveals the given attributes.
+func (m *CredManager) prepareProof(cred *Cred, revealedKnownAttrsIndices,
+	revealedCommitmentsOfAttrsIndices []int) (*preparedProof, error) {
 	if m.V1 == nil {
-		return nil, nil, fmt.Errorf("v1 is not set (generated in GetCredRequest)")
+		return nil, fmt.Errorf("v1 is not set (generated in GetCredRequest)")
 	}
 	rCred := m.randomize(cred)
 	// Z = cred.A^cred.e * S^cred.v11 * R_1^m_1 * ... * R_l^m_l
 	// Z = rCred.A^rCred.e * S^rCred.v11 * R_1^m_1 * ... * R_l^m_l
 	group := qr.NewRSApecialPublic(m.PubKey.N)
 
-	bases := []*big.Int{}
-	unrevealedKnownAttrs := []*big.Int{}
-	unrevealedCommitmentsOfAttrs := []*big.Int{}
+	n := len(m.Attrs.Known) + len(m.CommitmentsOfAttrs) + len(m.PubKey.RsHidden) + 2
+	bases := make([]*big.Int, 0, n)
+	unrevealedKnownAttrs := make([]*big.Int, 0, len(m.Attrs.Known))
+	unrevealedCommitmentsOfAttrs := make([]*big.Int, 0, len(m.CommitmentsOfAttrs))
 	for i := 0; i < len(m.Attrs.Known); i++ {
 		if !common.Contains(revealedKnownAttrsIndices, i) {
 			bases = append(bases, m.PubKey.RsKnown[i])
@@ -325,26 +470,30 @@ func (m *CredManager) BuildProof(cred *Cred, revealedKnownAttrsIndices,
 	bases = append(bases, rCred.A)
 	bases = append(bases, m.PubKey.S)
 
-	secrets := append(unrevealedKnownAttrs, unrevealedCommitmentsOfAttrs...)
+	secrets := make([]*big.Int, 0, n)
+	secrets = append(secrets, unrevealedKnownAttrs...)
+	secrets = append(secrets, unrevealedCommitmentsOfAttrs...)
 	secrets = append(secrets, m.Attrs.Hidden...)
 	secrets = append(secrets, rCred.E)
 	v := new(big.Int).Add(rCred.V11, m.V1)
 	secrets = append(secrets, v)
 
 	denom := big.NewInt(1)
+	t1 := common.GetInt()
 	for i := 0; i < len(m.Attrs.Known); i++ {
 		if common.Contains(revealedKnownAttrsIndices, i) {
-			t1 := group.Exp(m.PubKey.RsKnown[i], m.Attrs.Known[i])
-			denom = group.Mul(denom, t1)
+			common.ExpMod(t1, m.PubKey.RsKnown[i], m.Attrs.Known[i], group.N)
+			common.MulMod(denom, denom, t1, group.N)
 		}
 	}
 
 	for i := 0; i < len(m.Attrs.Committed); i++ {
 		if common.Contains(revealedCommitmentsOfAttrsIndices, i) {
-			t1 := group.Exp(m.PubKey.RsCommitted[i], m.CommitmentsOfAttrs[i])
-			denom = group.Mul(denom, t1)
+			common.ExpMod(t1, m.PubKey.RsCommitted[i], m.CommitmentsOfAttrs[i], group.N)
+			common.MulMod(denom, denom, t1, group.N)
 		}
 	}
+	common.PutInt(t1)
 	denomInv := group.Inv(denom)
 	y := group.Mul(m.PubKey.Z, denomInv)
 
@@ -353,17 +502,20 @@ func (m *CredManager) BuildProof(cred *Cred, revealedKnownAttrsIndices,
 
 	// boundary for m_tilde
 	b_m := int(m.Params.AttrBitLen + m.Params.SecParam + m.Params.HashBitLen)
+	// boundary for randomness of unrevealed commitments of attributes, which are
+	// shorter with commitments over elliptic curves than in QR_N
+	b_c := m.attrsScheme.BitLen() + int(m.Params.SecParam+m.Params.HashBitLen)
 	// boundary for e
 	b_e := int(m.Params.EBitLen + m.Params.SecParam + m.Params.HashBitLen)
 	// boundary for v1
 	b_v1 := int(m.Params.VBitLen + m.Params.SecParam + m.Params.HashBitLen)
 
-	boundaries := []int{}
+	boundaries := make([]int, 0, len(bases))
 	for i := 0; i < len(unrevealedKnownAttrs); i++ {
 		boundaries = append(boundaries, b_m)
 	}
 	for i := 0; i < len(unrevealedCommitmentsOfAttrs); i++ {
-		boundaries = append(boundaries, b_m)
+		boundaries = append(boundaries, b_c)
 	}
 	for _, _ = range m.PubKey.RsHidden {
 		boundaries = append(boundaries, b_m)
@@ -373,11 +525,12 @@ func (m *CredManager) BuildProof(cred *Cred, revealedKnownAttrsIndices,
 
 	proofRandomData, err := prover.GetProofRandomDataGivenBoundaries(boundaries, true)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error when generating representation proof random data: %s", err)
+		return nil, fmt.Errorf("error when generating representation proof random data: %s", err)
 	}
 
-	challenge := m.GetProofChallenge(proofRandomData, nonceOrg)
-	proofData := prover.GetProofData(challenge)
-
-	return rCred, qr.NewRepresentationProof(proofRandomData, challenge, proofData), nil
+	return &preparedProof{
+		rCred:           rCred,
+		prover:          prover,
+		proofRandomData: proofRandomData,
+	}, nil
 }
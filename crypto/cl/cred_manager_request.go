@@ -21,8 +21,8 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/xlab-si/emmy/crypto/commitments"
 	"github.com/xlab-si/emmy/crypto/common"
-	"github.com/xlab-si/emmy/crypto/df"
 	"github.com/xlab-si/emmy/crypto/qr"
 	"github.com/xlab-si/emmy/crypto/schnorr"
 )
@@ -34,13 +34,13 @@ type CredRequest struct {
 	NymProof                 *schnorr.Proof
 	U                        *big.Int
 	UProof                   *qr.RepresentationProof
-	CommitmentsOfAttrsProofs []*df.OpeningProof
+	CommitmentsOfAttrsProofs []*commitments.OpeningProof
 	Nonce                    *big.Int
 }
 
 func NewCredRequest(nym *big.Int, knownAttrs, commitmentsOfAttrs []*big.Int, nymProof *schnorr.Proof,
 	U *big.Int, UProof *qr.RepresentationProof,
-	commitmentsOfAttrsProofs []*df.OpeningProof, nonce *big.Int) *CredRequest {
+	commitmentsOfAttrsProofs []*commitments.OpeningProof, nonce *big.Int) *CredRequest {
 	return &CredRequest{
 		Nym:                nym,
 		KnownAttrs:         knownAttrs,
@@ -53,6 +53,30 @@ func NewCredRequest(nym *big.Int, knownAttrs, commitmentsOfAttrs []*big.Int, nym
 	}
 }
 
+// CredUpdate is a request to update the credential issued to Nym with new values of
+// known attributes and new commitments of committed attributes. Proofs that the
+// receiver can open the commitments are computed for a challenge bound to Nym and
+// Nonce, which is also the nonce of the proof of the updated credential. Committed
+// attributes are left as they are when CommitmentsOfAttrs is empty.
+type CredUpdate struct {
+	Nym                      *big.Int
+	Nonce                    *big.Int
+	KnownAttrs               []*big.Int
+	CommitmentsOfAttrs       []*big.Int
+	CommitmentsOfAttrsProofs []*commitments.OpeningProof
+}
+
+// credUpdateChallenge returns the challenge of proofs of opening of commitments of
+// attributes in a credential update, computed with random data of the proofs.
+func credUpdateChallenge(context, nym, nonce *big.Int, commitmentsOfAttrs,
+	randomData []*big.Int) *big.Int {
+	l := []*big.Int{context, nym, nonce}
+	l = append(l, commitmentsOfAttrs...)
+	l = append(l, randomData...)
+
+	return common.Hash(l...)
+}
+
 // computeU computes U = S^v1 * R_1^m_1 * ... * R_NumAttrs^m_NumAttrs (mod n) where only hiddenAttrs are used and
 // where v1 is random from +-{0,1}^(NLength + SecParam)
 func (m *CredManager) computeU() (*big.Int, *big.Int) {
@@ -60,13 +84,9 @@ func (m *CredManager) computeU() (*big.Int, *big.Int) {
 	b := new(big.Int).Exp(big.NewInt(2), exp, nil)
 	v1 := common.GetRandomIntAlsoNeg(b)
 
-	group := qr.NewRSApecialPublic(m.PubKey.N)
-	U := group.Exp(m.PubKey.S, v1)
-
-	for i, attr := range m.Attrs.Hidden {
-		t := group.Exp(m.PubKey.RsHidden[i], attr) // R_i^m_i
-		U = group.Mul(U, t)
-	}
+	bases := append([]*big.Int{m.PubKey.S}, m.PubKey.RsHidden[:len(m.Attrs.Hidden)]...)
+	exps := append([]*big.Int{v1}, m.Attrs.Hidden...)
+	U := common.MultiExp(new(big.Int), bases, exps, m.PubKey.N)
 
 	return U, v1
 }
@@ -123,10 +143,14 @@ func (m *CredManager) getUProofRandomData(prover *qr.RepresentationProver) (*big
 }
 
 // Fiat-Shamir is used to generate a challenge, instead of asking verifier to generate it.
-func (m *CredManager) getCredReqChallenge(U, nym, nonceOrg *big.Int) *big.Int {
+// The challenge is shared by proofs of opening of commitments of attributes, whose random
+// data is hashed along with commitments.
+func (m *CredManager) getCredReqChallenge(U, nym, nonceOrg *big.Int,
+	commitmentsOfAttrsRandomData []*big.Int) *big.Int {
 	context := m.PubKey.GetContext()
 	l := []*big.Int{context, U, nym, nonceOrg}
-	l = append(l, m.CommitmentsOfAttrs...) // TODO: add other values
+	l = append(l, m.CommitmentsOfAttrs...)
+	l = append(l, commitmentsOfAttrsRandomData...) // TODO: add other values
 
 	return common.Hash(l...)
 }
@@ -142,12 +166,21 @@ func (m *CredManager) getCredReqProvers(U *big.Int) (*schnorr.Prover,
 	return nymProver, uProver, nil
 }
 
-func (m *CredManager) getCommitmentsOfAttrsProof(challenge *big.Int) []*df.OpeningProof {
-	commitmentsOfAttrsProofs := make([]*df.OpeningProof, len(m.commitmentsOfAttrsProvers))
-	for i, prover := range m.commitmentsOfAttrsProvers {
-		proofRandomData := prover.GetProofRandomData()
+func (m *CredManager) getCommitmentsOfAttrsProofRandomData() []*big.Int {
+	proofRandomData := make([]*big.Int, len(m.attrsCommitters))
+	for i, prover := range m.attrsCommitters {
+		proofRandomData[i] = prover.GetProofRandomData()
+	}
+
+	return proofRandomData
+}
+
+func (m *CredManager) getCommitmentsOfAttrsProof(proofRandomData []*big.Int,
+	challenge *big.Int) []*commitments.OpeningProof {
+	commitmentsOfAttrsProofs := make([]*commitments.OpeningProof, len(m.attrsCommitters))
+	for i, prover := range m.attrsCommitters {
 		proofData1, proofData2 := prover.GetProofData(challenge)
-		commitmentsOfAttrsProofs[i] = df.NewOpeningProof(proofRandomData, challenge,
+		commitmentsOfAttrsProofs[i] = commitments.NewOpeningProof(proofRandomData[i], challenge,
 			proofData1, proofData2)
 	}
 
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/pedersen"
+)
+
+// CredManagerState holds everything needed to restore a CredManager, including its
+// secrets, so that a credential can be proved or updated after the process that
+// obtained it exits. It can be encoded with encoding/gob.
+type CredManagerState struct {
+	Params       *Params
+	PubKey       *PubKey
+	AttrCount    *AttrCount
+	RawAttrs     []AttrState
+	MasterSecret *big.Int
+	NymR         *big.Int // randomness of the commitment to MasterSecret
+	// randomness of CommitmentsOfAttrs
+	CommitmentsOfAttrsR []*big.Int
+	V1                  *big.Int
+	CredReqNonce        *big.Int
+}
+
+// AttrState is an attribute of a raw credential along with its value.
+type AttrState struct {
+	Name  string
+	Type  string // string, int64 or blob, as in the credential structure
+	Known bool
+	Value *big.Int // internal value, nil if not set
+}
+
+// State returns the state of m. It fails if the raw credential of m holds attributes
+// of types defined outside of this package.
+func (m *CredManager) State() (*CredManagerState, error) {
+	attrs := m.RawCred.GetAttrs()
+	rawAttrs := make([]AttrState, len(attrs))
+	for i := 0; i < len(attrs); i++ {
+		a := attrs[i]
+		var t string
+		switch a.(type) {
+		case *StrAttr:
+			t = "string"
+		case *Int64Attr:
+			t = "int64"
+		case *BlobAttr:
+			t = "blob"
+		default:
+			return nil, fmt.Errorf("unsupported attribute type: %T", a)
+		}
+		rawAttrs[i] = AttrState{
+			Name:  a.GetName(),
+			Type:  t,
+			Known: a.IsKnown(),
+		}
+		if a.HasVal() {
+			rawAttrs[i].Value = a.InternalValue()
+		}
+	}
+
+	_, nymR := m.nymCommitter.GetDecommitMsg()
+	commitmentsOfAttrsR := make([]*big.Int, len(m.attrsCommitters))
+	for i, c := range m.attrsCommitters {
+		_, commitmentsOfAttrsR[i] = c.GetDecommitMsg()
+	}
+
+	return &CredManagerState{
+		Params:              m.Params,
+		PubKey:              m.PubKey,
+		AttrCount:           m.RawCred.attrCount,
+		RawAttrs:            rawAttrs,
+		MasterSecret:        m.masterSecret,
+		NymR:                nymR,
+		CommitmentsOfAttrsR: commitmentsOfAttrsR,
+		V1:                  m.V1,
+		CredReqNonce:        m.CredReqNonce,
+	}, nil
+}
+
+// RestoreCredManager returns the CredManager with state s, as returned by State.
+func RestoreCredManager(s *CredManagerState) (*CredManager, error) {
+	rc := NewRawCred(s.AttrCount)
+	for _, a := range s.RawAttrs {
+		var err error
+		switch a.Type {
+		case "string":
+			err = rc.AddEmptyStrAttr(a.Name, a.Known)
+		case "int64":
+			err = rc.AddEmptyInt64Attr(a.Name, a.Known)
+		case "blob":
+			err = rc.AddEmptyBlobAttr(a.Name, a.Known)
+		default:
+			err = fmt.Errorf("unsupported attribute type: %s", a.Type)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if a.Value == nil {
+			continue
+		}
+		attr, _ := rc.GetAttr(a.Name)
+		val, err := attr.FromInternalValue(a.Value)
+		if s, ok := attr.(*StrAttr); ok && err == ErrHashedAttr {
+			// the string cannot be recovered, but proofs only need its encoding
+			s.setHashed(a.Value)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := attr.UpdateValue(val); err != nil {
+			return nil, err
+		}
+	}
+	if err := rc.missingAttrs(); err != nil {
+		return nil, fmt.Errorf("attribute %s has no value", err)
+	}
+
+	known := rc.GetKnownVals()
+	committed := rc.GetCommittedVals()
+	if len(s.CommitmentsOfAttrsR) != len(committed) {
+		return nil, fmt.Errorf("wrong number of commitments of attributes")
+	}
+	attrsScheme, err := attrsCommitmentScheme(s.Params, s.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	attrsCommitters := make([]commitments.Committer, len(committed))
+	commitmentsOfAttrs := make([]*big.Int, len(committed))
+	for i, attr := range committed {
+		committer := attrsScheme.NewCommitter()
+		com, err := committer.GetCommitMsgWithGivenR(attr, s.CommitmentsOfAttrsR[i])
+		if err != nil {
+			return nil, fmt.Errorf("error when creating Pedersen commitment: %s", err)
+		}
+		attrsCommitters[i] = committer
+		commitmentsOfAttrs[i] = com
+	}
+
+	nymCommitter := pedersen.NewCommitter(s.PubKey.PedersenParams)
+	nym, err := nymCommitter.GetCommitMsgWithGivenR(s.MasterSecret, s.NymR)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating Pedersen commitment: %s", err)
+	}
+
+	hidden, err := hiddenAttrs(s.PubKey, s.MasterSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CredManager{
+		Params:             s.Params,
+		PubKey:             s.PubKey,
+		RawCred:            rc,
+		nymCommitter:       nymCommitter,
+		Nym:                nym,
+		masterSecret:       s.MasterSecret,
+		Attrs:              NewAttrs(known, committed, hidden),
+		CommitmentsOfAttrs: commitmentsOfAttrs,
+		V1:                 s.V1,
+		attrsCommitters:    attrsCommitters,
+		attrsScheme:        attrsScheme,
+		CredReqNonce:       s.CredReqNonce,
+	}, nil
+}
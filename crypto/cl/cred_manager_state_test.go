@@ -0,0 +1,99 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreCredManager(t *testing.T) {
+	params, err := LoadParams()
+	require.NoError(t, err)
+	org := loadTestOrg(t, params)
+	cm, cred := issueTestCred(t, params, org, "Jack", "M")
+
+	state, err := cm.State()
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(state))
+	decoded := new(CredManagerState)
+	require.NoError(t, gob.NewDecoder(&buf).Decode(decoded))
+	restored, err := RestoreCredManager(decoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, cm.Nym, restored.Nym)
+	assert.Equal(t, cm.Attrs, restored.Attrs)
+	assert.Equal(t, cm.CommitmentsOfAttrs, restored.CommitmentsOfAttrs)
+	name, err := restored.RawCred.GetAttr("Name")
+	require.NoError(t, err)
+	assert.Equal(t, "Jack", name.GetValue())
+	age, err := restored.RawCred.GetAttr("Age")
+	require.NoError(t, err)
+	assert.EqualValues(t, 25, age.GetValue())
+
+	// the restored manager proves the credential issued to the original one
+	nonce := org.GetProveCredNonce()
+	rCred, proof, err := restored.BuildProof(cred, []int{0}, []int{0}, nonce)
+	require.NoError(t, err)
+	known, committed := restored.FilterAttributes([]int{0}, []int{0})
+	ok, err := org.ProveCred(rCred.A, proof, []int{0}, []int{0}, known, committed)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	rangeProof, err := restored.BuildRangeProof(0, big.NewInt(18), big.NewInt(150), nonce)
+	require.NoError(t, err)
+	ok, err = org.VerifyRangeProof(committed[0], rangeProof, big.NewInt(18),
+		big.NewInt(150), nonce)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// and can request a credential under the same nym
+	credReq, err := restored.GetCredRequest(org.GetCredIssueNonce())
+	require.NoError(t, err)
+	_, err = org.IssueCred(credReq)
+	assert.NoError(t, err)
+
+	state.RawAttrs[0].Type = "unknown"
+	_, err = RestoreCredManager(state)
+	assert.Error(t, err)
+}
+
+func TestRestoreCredManagerHashedAttr(t *testing.T) {
+	params, err := LoadParams()
+	require.NoError(t, err)
+	org := loadTestOrg(t, params)
+	cm, _ := issueTestCred(t, params, org, "Jack Jack Jack Jack Jack Jack Jack", "M")
+
+	state, err := cm.State()
+	require.NoError(t, err)
+	restored, err := RestoreCredManager(state)
+	require.NoError(t, err)
+
+	// the name was hashed, so only its encoding is restored
+	assert.Equal(t, cm.Attrs, restored.Attrs)
+	name, err := restored.RawCred.GetAttr("Name")
+	require.NoError(t, err)
+	assert.Equal(t, "", name.GetValue())
+	assert.True(t, DefaultAttrEncoder.IsHashed(name.InternalValue()))
+}
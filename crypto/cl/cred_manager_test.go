@@ -0,0 +1,129 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/df"
+	"github.com/xlab-si/emmy/crypto/qr"
+)
+
+func TestBlindIssuance(t *testing.T) {
+	params, err := LoadParams()
+	require.NoError(t, err)
+	org := loadTestOrg(t, params)
+	pub := org.Keys.Pub
+
+	rc := NewRawCred(NewAttrCount(5, 1, 0))
+	_ = rc.AddStrAttr("Name", "Jack", true)
+	_ = rc.AddStrAttr("Gender", "M", true)
+	_ = rc.AddStrAttr("Graduated", "true", true)
+	_ = rc.AddInt64Attr("DateMin", 22342345, true)
+	_ = rc.AddInt64Attr("DateMax", 32342345, true)
+	_ = rc.AddInt64Attr("Age", 25, false)
+	cm, err := NewCredManager(params, pub, pub.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+
+	// the receiver already holds a commitment to its age
+	committer := df.NewCommitter(pub.N1, pub.G, pub.H, pub.N1, params.SecParam)
+	c, err := committer.GetCommitMsg(big.NewInt(25))
+	require.NoError(t, err)
+	_, r := committer.GetDecommitMsg()
+
+	_, err = cm.CommitAttr("Name", r)
+	assert.Error(t, err, "known attributes cannot be committed")
+	_, err = cm.CommitAttr("Height", r)
+	assert.Error(t, err)
+	com, err := cm.CommitAttr("Age", r)
+	require.NoError(t, err)
+	assert.Equal(t, c, com)
+	com, comR, err := cm.AttrCommitment("Age")
+	require.NoError(t, err)
+	assert.Equal(t, c, com)
+	assert.Equal(t, r, comR)
+
+	nonce := org.GetCredIssueNonce()
+	credReq, err := cm.GetCredRequest(nonce)
+	require.NoError(t, err)
+
+	// the opening proof needs to be computed for the challenge of the request, which
+	// is not possible without knowing the opening
+	valid := credReq.CommitmentsOfAttrsProofs[0]
+	challenge := credReq.UProof.Challenge
+	s1 := common.GetRandomInt(pub.N1)
+	s2 := common.GetRandomInt(pub.N1)
+	group := qr.NewRSApecialPublic(pub.N1)
+	T := group.Mul(group.Mul(group.Exp(pub.G, s1), group.Exp(pub.H, s2)),
+		group.Inv(group.Exp(c, challenge)))
+	credReq.CommitmentsOfAttrsProofs[0] = commitments.NewOpeningProof(T, challenge, s1, s2)
+	_, err = org.IssueCred(credReq)
+	assert.Error(t, err, "forged opening proof should be rejected")
+	credReq.CommitmentsOfAttrsProofs[0] = commitments.NewOpeningProof(valid.ProofRandomData,
+		big.NewInt(1), valid.ProofData1, valid.ProofData2)
+	_, err = org.IssueCred(credReq)
+	assert.Error(t, err, "opening proof for another challenge should be rejected")
+
+	credReq.CommitmentsOfAttrsProofs[0] = valid
+	res, err := org.IssueCred(credReq)
+	require.NoError(t, err)
+	ok, err := cm.Verify(res.Cred, res.AProof)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []*big.Int{c}, res.Record.CommitmentsOfAttrs,
+		"credential should be issued for the commitment of the receiver")
+}
+
+func TestHiddenMasterSecret(t *testing.T) {
+	params := GetDefaultParamSizes()
+	org, err := NewOrg(params, NewAttrCount(1, 0, 1))
+	require.NoError(t, err)
+	rc := NewRawCred(NewAttrCount(1, 0, 0))
+	require.NoError(t, rc.AddStrAttr("Name", "Jack", true))
+	masterSecret := org.Keys.Pub.GenerateUserMasterSecret()
+	cm, err := NewCredManager(params, org.Keys.Pub, masterSecret, rc)
+	require.NoError(t, err)
+	assert.Equal(t, []*big.Int{masterSecret}, cm.Attrs.Hidden,
+		"the hidden attribute should hold the master secret")
+
+	credReq, err := cm.GetCredRequest(org.GetCredIssueNonce())
+	require.NoError(t, err)
+	res, err := org.IssueCred(credReq)
+	require.NoError(t, err)
+	ok, err := cm.Verify(res.Cred, res.AProof)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	nonce := org.GetProveCredNonce()
+	randCred, proof, err := cm.BuildProof(res.Cred, []int{0}, []int{}, nonce)
+	require.NoError(t, err)
+	known, committed := cm.FilterAttributes([]int{0}, []int{})
+	ok, err = org.ProveCred(randCred.A, proof, []int{0}, []int{}, known, committed)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	keys, err := GenerateKeyPair(params, NewAttrCount(1, 0, 2))
+	require.NoError(t, err)
+	_, err = NewCredManager(params, keys.Pub, masterSecret, rc)
+	assert.Error(t, err, "keys with several hidden attributes are not supported")
+}
@@ -0,0 +1,747 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/cbor"
+	"github.com/xlab-si/emmy/crypto/bulletproofs"
+	"github.com/xlab-si/emmy/crypto/df"
+	"github.com/xlab-si/emmy/crypto/pedersen"
+	"github.com/xlab-si/emmy/crypto/qr"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+)
+
+// EncodingVersion is the version of envelopes of credentials, states of credential
+// managers and proofs encoded with EncodeJSON and EncodeCBOR.
+const EncodingVersion = 1
+
+// Types of values encoded in envelopes.
+const (
+	EncodingTypeCred             = "emmy.cl.credential"
+	EncodingTypeCredManagerState = "emmy.cl.cred_manager_state"
+	EncodingTypeCredProof        = "emmy.cl.proof"
+)
+
+// CredProof is a proof of the possession of a credential, built with
+// CredManager.BuildProof, along with the attributes it reveals and proofs that
+// committed attributes lie in ranges. It is what a verifier needs to check the proof
+// with Org.ProveCred and Org.VerifyAttrRangeProofs.
+type CredProof struct {
+	A                                 *big.Int // randomized A of the credential
+	Proof                             *qr.RepresentationProof
+	RevealedKnownAttrsIndices         []int
+	RevealedCommitmentsOfAttrsIndices []int
+	RevealedKnownAttrs                []*big.Int
+	RevealedCommitmentsOfAttrs        []*big.Int
+	RangeProofs                       []*AttrRangeProof
+}
+
+// EncodeJSON encodes v, a *Cred, *CredManagerState or *CredProof, as JSON in a
+// versioned envelope:
+//
+//	{"version": 1, "type": "emmy.cl.credential", "value": {"a": "123...", ...}}
+//
+// Integers of the value are encoded as strings holding their decimal representation,
+// so that they are not rounded by implementations that parse JSON numbers as floats.
+func EncodeJSON(v interface{}) ([]byte, error) {
+	env, err := envelope(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(toJSONTree(env))
+}
+
+// DecodeJSON decodes a value encoded with EncodeJSON into v, which needs to point to
+// a value of the type in the envelope.
+func DecodeJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var env interface{}
+	if err := dec.Decode(&env); err != nil {
+		return fmt.Errorf("malformed JSON: %v", err)
+	}
+	return openEnvelope(env, v)
+}
+
+// EncodeCBOR encodes v like EncodeJSON, but in canonical CBOR. Integers that do not
+// fit into 64 bits are encoded as bignums (tags 2 and 3).
+func EncodeCBOR(v interface{}) ([]byte, error) {
+	env, err := envelope(v)
+	if err != nil {
+		return nil, err
+	}
+	return cbor.Encode(toCBORTree(env))
+}
+
+// DecodeCBOR decodes a value encoded with EncodeCBOR into v, which needs to point to
+// a value of the type in the envelope.
+func DecodeCBOR(data []byte, v interface{}) error {
+	env, rest, err := cbor.Decode(data)
+	if err != nil {
+		return fmt.Errorf("malformed CBOR: %v", err)
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("trailing data after CBOR item")
+	}
+	return openEnvelope(fromCBORTree(env), v)
+}
+
+// tree is the representation of encoded values shared by JSON and CBOR. Its values
+// are *big.Int, int64, string, bool, nil, []interface{} and tree.
+type tree map[string]interface{}
+
+// envelope returns the envelope of v.
+func envelope(v interface{}) (tree, error) {
+	var t string
+	var value tree
+	switch x := v.(type) {
+	case *Cred:
+		t, value = EncodingTypeCred, credTree(x)
+	case *CredManagerState:
+		if x.Params == nil || x.PubKey == nil || x.AttrCount == nil {
+			return nil, fmt.Errorf("incomplete state of credential manager")
+		}
+		t, value = EncodingTypeCredManagerState, credManagerStateTree(x)
+	case *CredProof:
+		if x.Proof == nil {
+			return nil, fmt.Errorf("incomplete credential proof")
+		}
+		t, value = EncodingTypeCredProof, credProofTree(x)
+	default:
+		return nil, fmt.Errorf("cannot encode %T", v)
+	}
+
+	return tree{
+		"version": int64(EncodingVersion),
+		"type":    t,
+		"value":   value,
+	}, nil
+}
+
+// openEnvelope decodes the value in envelope env into v.
+func openEnvelope(env interface{}, v interface{}) error {
+	e, ok := env.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("envelope is not a map")
+	}
+	r := new(treeReader)
+	version := r.int(e, "version")
+	t := r.string(e, "type")
+	value := r.tree(e, "value")
+	if r.err != nil {
+		return fmt.Errorf("malformed envelope: %v", r.err)
+	}
+	if version != EncodingVersion {
+		return fmt.Errorf("unsupported version %d", version)
+	}
+
+	var expected string
+	switch v.(type) {
+	case *Cred:
+		expected = EncodingTypeCred
+	case *CredManagerState:
+		expected = EncodingTypeCredManagerState
+	case *CredProof:
+		expected = EncodingTypeCredProof
+	default:
+		return fmt.Errorf("cannot decode into %T", v)
+	}
+	if t != expected {
+		return fmt.Errorf("cannot decode %s into %T", t, v)
+	}
+
+	switch x := v.(type) {
+	case *Cred:
+		*x = *readCred(r, value)
+	case *CredManagerState:
+		*x = *readCredManagerState(r, value)
+	case *CredProof:
+		*x = *readCredProof(r, value)
+	}
+	if r.err != nil {
+		return fmt.Errorf("malformed %s: %v", t, r.err)
+	}
+
+	return nil
+}
+
+func credTree(c *Cred) tree {
+	t := tree{
+		"a":   c.A,
+		"e":   c.E,
+		"v11": c.V11,
+	}
+	if c.Witness != nil {
+		t["witness"] = tree{
+			"w":       c.Witness.W,
+			"version": int64(c.Witness.Version),
+		}
+	}
+	if c.KeyID != "" {
+		t["key_id"] = c.KeyID
+	}
+	return t
+}
+
+func readCred(r *treeReader, t map[string]interface{}) *Cred {
+	c := &Cred{
+		A:   r.bigInt(t, "a"),
+		E:   r.bigInt(t, "e"),
+		V11: r.bigInt(t, "v11"),
+	}
+	if w := r.optTree(t, "witness"); w != nil {
+		c.Witness = &Witness{
+			W:       r.bigInt(w, "w"),
+			Version: r.int(w, "version"),
+		}
+	}
+	if r.value(t, "key_id", true) != nil {
+		c.KeyID = r.string(t, "key_id")
+	}
+	return c
+}
+
+func credManagerStateTree(s *CredManagerState) tree {
+	attrs := make([]interface{}, len(s.RawAttrs))
+	for i, a := range s.RawAttrs {
+		at := tree{
+			"name":  a.Name,
+			"type":  a.Type,
+			"known": a.Known,
+		}
+		if a.Value != nil {
+			at["value"] = a.Value
+		}
+		attrs[i] = at
+	}
+
+	t := tree{
+		"params":                 paramsTree(s.Params),
+		"pub_key":                pubKeyTree(s.PubKey),
+		"attr_count":             attrCountTree(s.AttrCount),
+		"attrs":                  attrs,
+		"master_secret":          s.MasterSecret,
+		"nym_r":                  s.NymR,
+		"commitments_of_attrs_r": bigInts(s.CommitmentsOfAttrsR),
+	}
+	if s.V1 != nil {
+		t["v1"] = s.V1
+	}
+	if s.CredReqNonce != nil {
+		t["cred_req_nonce"] = s.CredReqNonce
+	}
+	return t
+}
+
+func readCredManagerState(r *treeReader, t map[string]interface{}) *CredManagerState {
+	s := &CredManagerState{
+		Params:              readParams(r, r.tree(t, "params")),
+		PubKey:              readPubKey(r, r.tree(t, "pub_key")),
+		AttrCount:           readAttrCount(r, r.tree(t, "attr_count")),
+		MasterSecret:        r.bigInt(t, "master_secret"),
+		NymR:                r.bigInt(t, "nym_r"),
+		CommitmentsOfAttrsR: r.bigInts(t, "commitments_of_attrs_r"),
+		V1:                  r.optBigInt(t, "v1"),
+		CredReqNonce:        r.optBigInt(t, "cred_req_nonce"),
+	}
+	for _, a := range r.trees(t, "attrs") {
+		s.RawAttrs = append(s.RawAttrs, AttrState{
+			Name:  r.string(a, "name"),
+			Type:  r.string(a, "type"),
+			Known: r.bool(a, "known"),
+			Value: r.optBigInt(a, "value"),
+		})
+	}
+	return s
+}
+
+func paramsTree(p *Params) tree {
+	t := tree{
+		"rho_bit_len":         int64(p.RhoBitLen),
+		"n_length":            int64(p.NLength),
+		"known_attrs_num":     int64(p.KnownAttrsNum),
+		"committed_attrs_num": int64(p.CommittedAttrsNum),
+		"hidden_attrs_num":    int64(p.HiddenAttrsNum),
+		"attr_bit_len":        int64(p.AttrBitLen),
+		"hash_bit_len":        int64(p.HashBitLen),
+		"sec_param":           int64(p.SecParam),
+		"e_bit_len":           int64(p.EBitLen),
+		"e1_bit_len":          int64(p.E1BitLen),
+		"v_bit_len":           int64(p.VBitLen),
+		"challenge_space":     int64(p.ChallengeSpace),
+	}
+	if p.CommitmentScheme != "" {
+		t["commitment_scheme"] = p.CommitmentScheme
+	}
+	return t
+}
+
+func readParams(r *treeReader, t map[string]interface{}) *Params {
+	p := &Params{
+		RhoBitLen:         r.int(t, "rho_bit_len"),
+		NLength:           r.int(t, "n_length"),
+		KnownAttrsNum:     r.int(t, "known_attrs_num"),
+		CommittedAttrsNum: r.int(t, "committed_attrs_num"),
+		HiddenAttrsNum:    r.int(t, "hidden_attrs_num"),
+		AttrBitLen:        r.int(t, "attr_bit_len"),
+		HashBitLen:        r.int(t, "hash_bit_len"),
+		SecParam:          r.int(t, "sec_param"),
+		EBitLen:           r.int(t, "e_bit_len"),
+		E1BitLen:          r.int(t, "e1_bit_len"),
+		VBitLen:           r.int(t, "v_bit_len"),
+		ChallengeSpace:    r.int(t, "challenge_space"),
+	}
+	if r.value(t, "commitment_scheme", true) != nil {
+		p.CommitmentScheme = r.string(t, "commitment_scheme")
+	}
+	return p
+}
+
+func pubKeyTree(k *PubKey) tree {
+	return tree{
+		"n":            k.N,
+		"s":            k.S,
+		"z":            k.Z,
+		"rs_known":     bigInts(k.RsKnown),
+		"rs_committed": bigInts(k.RsCommitted),
+		"rs_hidden":    bigInts(k.RsHidden),
+		"pedersen": tree{
+			"p": k.PedersenParams.Group.P,
+			"q": k.PedersenParams.Group.Q,
+			"g": k.PedersenParams.Group.G,
+			"h": k.PedersenParams.H,
+		},
+		"n1": k.N1,
+		"g":  k.G,
+		"h":  k.H,
+	}
+}
+
+func readPubKey(r *treeReader, t map[string]interface{}) *PubKey {
+	p := r.tree(t, "pedersen")
+	group := schnorr.NewGroupFromParams(r.bigInt(p, "p"), r.bigInt(p, "g"), r.bigInt(p, "q"))
+	return &PubKey{
+		N:              r.bigInt(t, "n"),
+		S:              r.bigInt(t, "s"),
+		Z:              r.bigInt(t, "z"),
+		RsKnown:        r.bigInts(t, "rs_known"),
+		RsCommitted:    r.bigInts(t, "rs_committed"),
+		RsHidden:       r.bigInts(t, "rs_hidden"),
+		PedersenParams: pedersen.NewParams(group, r.bigInt(p, "h"), nil),
+		N1:             r.bigInt(t, "n1"),
+		G:              r.bigInt(t, "g"),
+		H:              r.bigInt(t, "h"),
+	}
+}
+
+func attrCountTree(c *AttrCount) tree {
+	return tree{
+		"known":     int64(c.Known),
+		"committed": int64(c.Committed),
+		"hidden":    int64(c.Hidden),
+	}
+}
+
+func readAttrCount(r *treeReader, t map[string]interface{}) *AttrCount {
+	return NewAttrCount(r.int(t, "known"), r.int(t, "committed"), r.int(t, "hidden"))
+}
+
+func credProofTree(p *CredProof) tree {
+	rangeProofs := make([]interface{}, len(p.RangeProofs))
+	for i, rp := range p.RangeProofs {
+		rangeProofs[i] = attrRangeProofTree(rp)
+	}
+
+	return tree{
+		"a": p.A,
+		"proof": tree{
+			"proof_random_data": p.Proof.ProofRandomData,
+			"challenge":         p.Proof.Challenge,
+			"proof_data":        bigInts(p.Proof.ProofData),
+		},
+		"revealed_known_attrs_indices":          ints(p.RevealedKnownAttrsIndices),
+		"revealed_commitments_of_attrs_indices": ints(p.RevealedCommitmentsOfAttrsIndices),
+		"revealed_known_attrs":                  bigInts(p.RevealedKnownAttrs),
+		"revealed_commitments_of_attrs":         bigInts(p.RevealedCommitmentsOfAttrs),
+		"range_proofs":                          rangeProofs,
+	}
+}
+
+func readCredProof(r *treeReader, t map[string]interface{}) *CredProof {
+	proof := r.tree(t, "proof")
+	p := &CredProof{
+		A: r.bigInt(t, "a"),
+		Proof: qr.NewRepresentationProof(r.bigInt(proof, "proof_random_data"),
+			r.bigInt(proof, "challenge"), r.bigInts(proof, "proof_data")),
+		RevealedKnownAttrsIndices:         r.ints(t, "revealed_known_attrs_indices"),
+		RevealedCommitmentsOfAttrsIndices: r.ints(t, "revealed_commitments_of_attrs_indices"),
+		RevealedKnownAttrs:                r.bigInts(t, "revealed_known_attrs"),
+		RevealedCommitmentsOfAttrs:        r.bigInts(t, "revealed_commitments_of_attrs"),
+	}
+	for _, rp := range r.trees(t, "range_proofs") {
+		p.RangeProofs = append(p.RangeProofs, readAttrRangeProof(r, rp))
+	}
+	return p
+}
+
+func attrRangeProofTree(p *AttrRangeProof) tree {
+	t := tree{
+		"index": int64(p.Index),
+		"a":     p.A,
+		"b":     p.B,
+	}
+	if p.Bulletproof != nil {
+		t["bulletproof"] = bulletproofTree(p.Bulletproof)
+		return t
+	}
+	t["proof_random_data1"] = bigInts(p.ProofRandomData1)
+	t["proof_random_data2"] = bigInts(p.ProofRandomData2)
+	t["challenges1"] = bigInts(p.Challenges1)
+	t["challenges2"] = bigInts(p.Challenges2)
+	t["proof_data1"] = bigInts(p.ProofData1)
+	t["proof_data2"] = bigInts(p.ProofData2)
+	t["small_commitments1"] = bigInts(p.SmallCommitments1)
+	t["big_commitments1"] = bigInts(p.BigCommitments1)
+	t["small_commitments2"] = bigInts(p.SmallCommitments2)
+	t["big_commitments2"] = bigInts(p.BigCommitments2)
+	return t
+}
+
+func readAttrRangeProof(r *treeReader, t map[string]interface{}) *AttrRangeProof {
+	p := &AttrRangeProof{
+		Index: r.int(t, "index"),
+		A:     r.bigInt(t, "a"),
+		B:     r.bigInt(t, "b"),
+	}
+	if bp := r.optTree(t, "bulletproof"); bp != nil {
+		p.Bulletproof = readBulletproof(r, bp)
+		return p
+	}
+	p.RangeProofNI = &df.RangeProofNI{
+		RangeProof: df.NewRangeProof(r.bigInts(t, "proof_random_data1"),
+			r.bigInts(t, "proof_random_data2"), r.bigInts(t, "challenges1"),
+			r.bigInts(t, "challenges2"), r.bigInts(t, "proof_data1"),
+			r.bigInts(t, "proof_data2")),
+		SmallCommitments1: r.bigInts(t, "small_commitments1"),
+		BigCommitments1:   r.bigInts(t, "big_commitments1"),
+		SmallCommitments2: r.bigInts(t, "small_commitments2"),
+		BigCommitments2:   r.bigInts(t, "big_commitments2"),
+	}
+	return p
+}
+
+func bulletproofTree(p *Bulletproof) tree {
+	t := tree{
+		"a":       p.A,
+		"s":       p.S,
+		"t1":      p.T1,
+		"t2":      p.T2,
+		"tau_x":   p.TauX,
+		"mu":      p.Mu,
+		"t":       p.T,
+		"l":       bigInts(p.L),
+		"r":       bigInts(p.R),
+		"inner_a": p.InnerA,
+		"inner_b": p.InnerB,
+	}
+	if p.Equality != nil {
+		t["commitment"] = p.Commitment
+		t["equality"] = tree{
+			"proof_random_data1": p.Equality.ProofRandomData1,
+			"proof_random_data2": p.Equality.ProofRandomData2,
+			"challenge":          p.Equality.Challenge,
+			"proof_data1":        p.Equality.ProofData1,
+			"proof_data21":       p.Equality.ProofData21,
+			"proof_data22":       p.Equality.ProofData22,
+		}
+	}
+	return t
+}
+
+func readBulletproof(r *treeReader, t map[string]interface{}) *Bulletproof {
+	p := &Bulletproof{
+		Proof: &bulletproofs.Proof{
+			A:      r.bigInt(t, "a"),
+			S:      r.bigInt(t, "s"),
+			T1:     r.bigInt(t, "t1"),
+			T2:     r.bigInt(t, "t2"),
+			TauX:   r.bigInt(t, "tau_x"),
+			Mu:     r.bigInt(t, "mu"),
+			T:      r.bigInt(t, "t"),
+			L:      r.bigInts(t, "l"),
+			R:      r.bigInts(t, "r"),
+			InnerA: r.bigInt(t, "inner_a"),
+			InnerB: r.bigInt(t, "inner_b"),
+		},
+	}
+	if eq := r.optTree(t, "equality"); eq != nil {
+		p.Commitment = r.bigInt(t, "commitment")
+		p.Equality = &bulletproofs.DFEqualityProof{
+			ProofRandomData1: r.bigInt(eq, "proof_random_data1"),
+			ProofRandomData2: r.bigInt(eq, "proof_random_data2"),
+			Challenge:        r.bigInt(eq, "challenge"),
+			ProofData1:       r.bigInt(eq, "proof_data1"),
+			ProofData21:      r.bigInt(eq, "proof_data21"),
+			ProofData22:      r.bigInt(eq, "proof_data22"),
+		}
+	}
+	return p
+}
+
+func bigInts(xs []*big.Int) []interface{} {
+	l := make([]interface{}, len(xs))
+	for i, x := range xs {
+		l[i] = x
+	}
+	return l
+}
+
+func ints(xs []int) []interface{} {
+	l := make([]interface{}, len(xs))
+	for i, x := range xs {
+		l[i] = int64(x)
+	}
+	return l
+}
+
+// toJSONTree returns a copy of v where integers are replaced by their decimal
+// representation.
+func toJSONTree(v interface{}) interface{} {
+	switch x := v.(type) {
+	case *big.Int:
+		if x == nil {
+			return nil
+		}
+		return x.String()
+	case int64:
+		return x
+	case []interface{}:
+		l := make([]interface{}, len(x))
+		for i, item := range x {
+			l[i] = toJSONTree(item)
+		}
+		return l
+	case tree:
+		m := make(map[string]interface{}, len(x))
+		for k, item := range x {
+			m[k] = toJSONTree(item)
+		}
+		return m
+	}
+	return v
+}
+
+// toCBORTree returns a copy of v with maps that can be encoded with cbor.Encode.
+func toCBORTree(v interface{}) interface{} {
+	switch x := v.(type) {
+	case *big.Int:
+		if x == nil {
+			return nil
+		}
+		return x
+	case []interface{}:
+		l := make([]interface{}, len(x))
+		for i, item := range x {
+			l[i] = toCBORTree(item)
+		}
+		return l
+	case tree:
+		m := make(map[interface{}]interface{}, len(x))
+		for k, item := range x {
+			m[k] = toCBORTree(item)
+		}
+		return m
+	}
+	return v
+}
+
+// fromCBORTree returns a copy of v, as returned by cbor.Decode, with maps keyed by
+// strings. Maps with other keys are left as they are, and rejected when read.
+func fromCBORTree(v interface{}) interface{} {
+	switch x := v.(type) {
+	case []interface{}:
+		l := make([]interface{}, len(x))
+		for i, item := range x {
+			l[i] = fromCBORTree(item)
+		}
+		return l
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, item := range x {
+			key, ok := k.(string)
+			if !ok {
+				return x
+			}
+			m[key] = fromCBORTree(item)
+		}
+		return m
+	}
+	return v
+}
+
+// treeReader reads values of decoded trees, keeping the first error that occurs, so
+// that values can be read field by field and checked once.
+type treeReader struct {
+	err error
+}
+
+// value returns the value of key in t, which is required unless optional is set.
+func (r *treeReader) value(t map[string]interface{}, key string, optional bool) interface{} {
+	v, ok := t[key]
+	if !ok && !optional && r.err == nil {
+		r.err = fmt.Errorf("missing %s", key)
+	}
+	return v
+}
+
+func (r *treeReader) fail(key string, v interface{}) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%s has unexpected type %T", key, v)
+	}
+}
+
+func (r *treeReader) bigInt(t map[string]interface{}, key string) *big.Int {
+	return r.toBigInt(key, r.value(t, key, false))
+}
+
+func (r *treeReader) optBigInt(t map[string]interface{}, key string) *big.Int {
+	if v := r.value(t, key, true); v != nil {
+		return r.toBigInt(key, v)
+	}
+	return nil
+}
+
+// toBigInt converts v, an integer decoded from CBOR or JSON, where integers are
+// usually given by their decimal representation, to *big.Int.
+func (r *treeReader) toBigInt(key string, v interface{}) *big.Int {
+	switch x := v.(type) {
+	case *big.Int:
+		return x
+	case int64:
+		return big.NewInt(x)
+	case string, json.Number:
+		if i, ok := new(big.Int).SetString(fmt.Sprint(x), 10); ok {
+			return i
+		}
+		if r.err == nil {
+			r.err = fmt.Errorf("%s is not an integer", key)
+		}
+		return nil
+	}
+	r.fail(key, v)
+	return nil
+}
+
+func (r *treeReader) bigInts(t map[string]interface{}, key string) []*big.Int {
+	l := r.list(t, key)
+	xs := make([]*big.Int, len(l))
+	for i, v := range l {
+		xs[i] = r.toBigInt(key, v)
+	}
+	return xs
+}
+
+// int reads an integer that fits into int, as decoded from CBOR or JSON.
+func (r *treeReader) int(t map[string]interface{}, key string) int {
+	return r.toInt(key, r.value(t, key, false))
+}
+
+func (r *treeReader) toInt(key string, v interface{}) int {
+	switch x := v.(type) {
+	case int64:
+		if int64(int(x)) == x {
+			return int(x)
+		}
+	case json.Number:
+		if i, err := x.Int64(); err == nil && int64(int(i)) == i {
+			return int(i)
+		}
+	}
+	r.fail(key, v)
+	return 0
+}
+
+func (r *treeReader) ints(t map[string]interface{}, key string) []int {
+	l := r.list(t, key)
+	xs := make([]int, len(l))
+	for i, v := range l {
+		xs[i] = r.toInt(key, v)
+	}
+	return xs
+}
+
+func (r *treeReader) string(t map[string]interface{}, key string) string {
+	v := r.value(t, key, false)
+	s, ok := v.(string)
+	if !ok {
+		r.fail(key, v)
+	}
+	return s
+}
+
+func (r *treeReader) bool(t map[string]interface{}, key string) bool {
+	v := r.value(t, key, false)
+	b, ok := v.(bool)
+	if !ok {
+		r.fail(key, v)
+	}
+	return b
+}
+
+func (r *treeReader) list(t map[string]interface{}, key string) []interface{} {
+	v := r.value(t, key, false)
+	l, ok := v.([]interface{})
+	if !ok {
+		r.fail(key, v)
+	}
+	return l
+}
+
+func (r *treeReader) tree(t map[string]interface{}, key string) map[string]interface{} {
+	return r.toTree(key, r.value(t, key, false))
+}
+
+func (r *treeReader) optTree(t map[string]interface{}, key string) map[string]interface{} {
+	if v := r.value(t, key, true); v != nil {
+		return r.toTree(key, v)
+	}
+	return nil
+}
+
+func (r *treeReader) toTree(key string, v interface{}) map[string]interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		r.fail(key, v)
+		return map[string]interface{}{}
+	}
+	return m
+}
+
+func (r *treeReader) trees(t map[string]interface{}, key string) []map[string]interface{} {
+	l := r.list(t, key)
+	ms := make([]map[string]interface{}, len(l))
+	for i, v := range l {
+		ms[i] = r.toTree(key, v)
+	}
+	return ms
+}
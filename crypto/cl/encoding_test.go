@@ -0,0 +1,133 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodings are the encodings of credentials, states of credential managers and
+// proofs, by name.
+var encodings = map[string]struct {
+	encode func(interface{}) ([]byte, error)
+	decode func([]byte, interface{}) error
+}{
+	"JSON": {EncodeJSON, DecodeJSON},
+	"CBOR": {EncodeCBOR, DecodeCBOR},
+}
+
+func TestEncoding(t *testing.T) {
+	params, err := LoadParams()
+	require.NoError(t, err)
+	org := loadTestOrg(t, params)
+	cm, cred := issueTestCred(t, params, org, "Jack", "M")
+	state, err := cm.State()
+	require.NoError(t, err)
+
+	for name, enc := range encodings {
+		t.Run(name, func(t *testing.T) {
+			data, err := enc.encode(cred)
+			require.NoError(t, err)
+			decodedCred := new(Cred)
+			require.NoError(t, enc.decode(data, decodedCred))
+			assert.Equal(t, cred, decodedCred)
+
+			data, err = enc.encode(state)
+			require.NoError(t, err)
+			decodedState := new(CredManagerState)
+			require.NoError(t, enc.decode(data, decodedState))
+			restored, err := RestoreCredManager(decodedState)
+			require.NoError(t, err)
+			assert.Equal(t, cm.Nym, restored.Nym)
+
+			// the restored manager proves the decoded credential, and the decoded
+			// proof verifies
+			nonce := org.GetProveCredNonce()
+			rCred, proof, err := restored.BuildProof(decodedCred, []int{0}, []int{0}, nonce)
+			require.NoError(t, err)
+			known, committed := restored.FilterAttributes([]int{0}, []int{0})
+			rangeProof, err := restored.BuildAttrRangeProof(0, big.NewInt(18),
+				big.NewInt(150), nonce)
+			require.NoError(t, err)
+			bulletproof, err := restored.BuildAttrRangeProofWithBackend(
+				RangeProofsBulletproofs, 0, big.NewInt(18), big.NewInt(150), nonce)
+			require.NoError(t, err)
+			data, err = enc.encode(&CredProof{
+				A:                                 rCred.A,
+				Proof:                             proof,
+				RevealedKnownAttrsIndices:         []int{0},
+				RevealedCommitmentsOfAttrsIndices: []int{0},
+				RevealedKnownAttrs:                known,
+				RevealedCommitmentsOfAttrs:        committed,
+				RangeProofs:                       []*AttrRangeProof{rangeProof, bulletproof},
+			})
+			require.NoError(t, err)
+			p := new(CredProof)
+			require.NoError(t, enc.decode(data, p))
+			ok, err := org.ProveCred(p.A, p.Proof, p.RevealedKnownAttrsIndices,
+				p.RevealedCommitmentsOfAttrsIndices, p.RevealedKnownAttrs,
+				p.RevealedCommitmentsOfAttrs)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			ok, err = org.VerifyAttrRangeProofs(p.RangeProofs,
+				p.RevealedCommitmentsOfAttrsIndices, p.RevealedCommitmentsOfAttrs, nonce)
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			// values are only decoded into their own type
+			assert.Error(t, enc.decode(data, new(Cred)))
+			assert.Error(t, enc.decode(data[:len(data)-1], new(CredProof)))
+		})
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	cred := new(Cred)
+	require.NoError(t, DecodeJSON([]byte(`{"version": 1, "type": "emmy.cl.credential",
+		"value": {"a": "123456789012345678901234567890", "e": "-5", "v11": 7,
+			"witness": {"w": "11", "version": 2}}}`), cred))
+	a, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	assert.Equal(t, &Cred{
+		A:       a,
+		E:       big.NewInt(-5),
+		V11:     big.NewInt(7),
+		Witness: &Witness{W: big.NewInt(11), Version: 2},
+	}, cred)
+
+	// integers are encoded as strings
+	data, err := EncodeJSON(cred)
+	require.NoError(t, err)
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &env))
+	assert.Equal(t, "123456789012345678901234567890", env["value"].(map[string]interface{})["a"])
+
+	for _, data := range []string{
+		`{"version": 2, "type": "emmy.cl.credential", "value": {}}`,
+		`{"version": 1, "type": "emmy.cl.credential", "value": {"a": "1", "e": "2"}}`,
+		`{"version": 1, "type": "emmy.cl.credential", "value": {"a": "x", "e": "2", "v11": "3"}}`,
+		`{"version": 1, "type": "emmy.cl.proof", "value": {}}`,
+		`[]`,
+	} {
+		assert.Error(t, DecodeJSON([]byte(data), new(Cred)), "%s should not be decoded", data)
+	}
+}
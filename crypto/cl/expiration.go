@@ -0,0 +1,176 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ExpirationAttr is the name of the attribute holding the expiration of a
+// credential. By convention, it is a known int64 attribute with the Unix time
+// (in seconds) after which the credential is no longer valid. Credentials whose
+// structure includes it need to reveal it in proofs, and are extended by updating
+// it (see RawCred.SetExpiration).
+const ExpirationAttr = "Expiration"
+
+// IssuedAtAttr is the name of the attribute holding the time a credential was
+// issued or last updated at. Like ExpirationAttr, it is a known int64 attribute with a Unix time,
+// which is revealed in proofs checked against a maximum age of credentials (see
+// Policy.MaxAge).
+const IssuedAtAttr = "IssuedAt"
+
+// ErrCredExpired is returned for credentials whose expiration has passed.
+var ErrCredExpired = errors.New("credential expired")
+
+// AddExpirationAttr adds a known attribute holding the expiration of the
+// credential (see ExpirationAttr).
+func (c *RawCred) AddExpirationAttr(expiresAt time.Time) error {
+	return c.AddInt64Attr(ExpirationAttr, expiresAt.Unix(), true)
+}
+
+// AddIssuedAtAttr adds a known attribute holding the time the credential was
+// issued at (see IssuedAtAttr).
+func (c *RawCred) AddIssuedAtAttr(issuedAt time.Time) error {
+	return c.AddInt64Attr(IssuedAtAttr, issuedAt.Unix(), true)
+}
+
+// HasExpiration returns true if the credential has the expiration attribute.
+func (c *RawCred) HasExpiration() bool {
+	return c.hasAttr(ExpirationAttr)
+}
+
+// ExpiresAt returns the expiration of the credential.
+func (c *RawCred) ExpiresAt() (time.Time, error) {
+	a, err := c.expirationAttr()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !a.valSet {
+		return time.Time{}, fmt.Errorf("expiration not set")
+	}
+	return time.Unix(a.val, 0), nil
+}
+
+// SetExpiration sets the expiration of the credential to expiresAt. To extend
+// validity of an issued credential, set a new expiration and update the
+// credential with the organization.
+func (c *RawCred) SetExpiration(expiresAt time.Time) error {
+	a, err := c.expirationAttr()
+	if err != nil {
+		return err
+	}
+	return a.UpdateValue(expiresAt.Unix())
+}
+
+// expirationAttr returns the expiration attribute of the credential.
+func (c *RawCred) expirationAttr() (*Int64Attr, error) {
+	return c.timeAttr(ExpirationAttr)
+}
+
+// timeAttr returns attribute name of the credential, which holds a Unix time.
+func (c *RawCred) timeAttr(name string) (*Int64Attr, error) {
+	attr, err := c.GetAttr(name)
+	if err != nil {
+		return nil, err
+	}
+	a, ok := attr.(*Int64Attr)
+	if !ok || !a.IsKnown() {
+		return nil, fmt.Errorf("attribute %s is not a known int64 attribute", name)
+	}
+	return a, nil
+}
+
+// RevealedExpiration returns the expiration of a credential with the structure
+// of rc from known attributes revealedKnownAttrs with indices
+// revealedKnownAttrsIndices. The second return value is false if the credential
+// has no expiration attribute. An error is returned if it has one, but it was
+// not revealed.
+func RevealedExpiration(rc *RawCred, revealedKnownAttrsIndices []int,
+	revealedKnownAttrs []*big.Int) (time.Time, bool, error) {
+	return revealedTime(rc, ExpirationAttr, revealedKnownAttrsIndices, revealedKnownAttrs)
+}
+
+// revealedTime returns the Unix time held by attribute name of a credential with
+// the structure of rc, from its revealed known attributes. The second return value
+// is false if the credential has no such attribute.
+func revealedTime(rc *RawCred, name string, revealedKnownAttrsIndices []int,
+	revealedKnownAttrs []*big.Int) (time.Time, bool, error) {
+	if !rc.hasAttr(name) {
+		return time.Time{}, false, nil
+	}
+	if _, err := rc.timeAttr(name); err != nil {
+		return time.Time{}, true, err
+	}
+	ind, err := rc.GetAttrInternalIndex(name)
+	if err != nil {
+		return time.Time{}, true, err
+	}
+	for i, j := range revealedKnownAttrsIndices {
+		if j == ind && i < len(revealedKnownAttrs) {
+			if !revealedKnownAttrs[i].IsInt64() {
+				return time.Time{}, true, fmt.Errorf("invalid %s", name)
+			}
+			return time.Unix(revealedKnownAttrs[i].Int64(), 0), true, nil
+		}
+	}
+	return time.Time{}, true, fmt.Errorf("%s not revealed", name)
+}
+
+// RevealedIssuedAt returns the issuance time of a credential with the structure
+// of rc from its revealed known attributes, like RevealedExpiration.
+func RevealedIssuedAt(rc *RawCred, revealedKnownAttrsIndices []int,
+	revealedKnownAttrs []*big.Int) (time.Time, bool, error) {
+	return revealedTime(rc, IssuedAtAttr, revealedKnownAttrsIndices, revealedKnownAttrs)
+}
+
+// CheckExpiration checks that a credential with the structure of rc, whose known
+// attributes revealedKnownAttrs with indices revealedKnownAttrsIndices were
+// revealed, has not expired by now. Credentials without the expiration attribute
+// never expire. ErrCredExpired is returned for expired credentials.
+func CheckExpiration(rc *RawCred, revealedKnownAttrsIndices []int,
+	revealedKnownAttrs []*big.Int, now time.Time) error {
+	expiresAt, ok, err := RevealedExpiration(rc, revealedKnownAttrsIndices,
+		revealedKnownAttrs)
+	if err != nil || !ok {
+		return err
+	}
+	if !now.Before(expiresAt) {
+		return ErrCredExpired
+	}
+	return nil
+}
+
+// ExpiresAt returns the expiration of the credential managed by m.
+func (m *CredManager) ExpiresAt() (time.Time, error) {
+	return m.RawCred.ExpiresAt()
+}
+
+// RemainingValidity returns how long the credential managed by m remains valid
+// after now, which is not positive once the credential expired. Credentials
+// that need to remain valid for a while are to be updated with an extended
+// expiration (see RawCred.SetExpiration) before they are proved.
+func (m *CredManager) RemainingValidity(now time.Time) (time.Duration, error) {
+	expiresAt, err := m.ExpiresAt()
+	if err != nil {
+		return 0, err
+	}
+	return expiresAt.Sub(now), nil
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiration(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	rc := NewRawCred(NewAttrCount(2, 1, 0))
+	require.NoError(t, rc.AddStrAttr("Name", "Jack", true))
+	assert.False(t, rc.HasExpiration())
+	require.NoError(t, rc.AddExpirationAttr(now.Add(time.Hour)))
+	require.NoError(t, rc.AddInt64Attr("Age", 25, false))
+	assert.True(t, rc.HasExpiration())
+
+	m := &CredManager{RawCred: rc}
+	expiresAt, err := m.ExpiresAt()
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), expiresAt)
+	remaining, err := m.RemainingValidity(now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, remaining)
+
+	// the expiration is the second known attribute
+	known := rc.GetKnownVals()
+	assert.NoError(t, CheckExpiration(rc, []int{0, 1}, known, now))
+	assert.Equal(t, ErrCredExpired, CheckExpiration(rc, []int{1}, known[1:],
+		now.Add(time.Hour)))
+	assert.Error(t, CheckExpiration(rc, []int{0}, known[:1], now),
+		"expiration needs to be revealed")
+
+	// validity is extended by updating the expiration
+	require.NoError(t, rc.SetExpiration(now.Add(48*time.Hour)))
+	expiresAt, ok, err := RevealedExpiration(rc, []int{1}, rc.GetKnownVals()[1:])
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(48*time.Hour), expiresAt)
+
+	// credentials without the expiration attribute never expire
+	other := NewRawCred(NewAttrCount(1, 0, 0))
+	require.NoError(t, other.AddStrAttr("Name", "Jack", true))
+	_, ok, err = RevealedExpiration(other, nil, nil)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	_, err = other.ExpiresAt()
+	assert.Error(t, err)
+
+	// the expiration needs to be a known int64 attribute
+	invalid := NewRawCred(NewAttrCount(0, 1, 0))
+	require.NoError(t, invalid.AddInt64Attr(ExpirationAttr, now.Unix(), false))
+	assert.Error(t, CheckExpiration(invalid, nil, []*big.Int{}, now))
+}
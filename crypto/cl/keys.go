@@ -18,6 +18,7 @@
 package cl
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/pkg/errors"
@@ -112,13 +113,24 @@ func (k *PubKey) GetContext() *big.Int {
 // GenerateKeyPair takes and constructs a keypair containing public and
 // secret key for the CL scheme.
 func GenerateKeyPair(p *Params, attrs *AttrCount) (*KeyPair, error) {
-	g, err := qr.NewRSASpecial(int(p.NLength) / 2)
+	return GenerateKeyPairContext(context.Background(), p, attrs, nil)
+}
+
+// GenerateKeyPairContext is like GenerateKeyPair, but the search for primes,
+// which takes most of the time, is stopped once ctx is done. Progress of the
+// search is reported to s (if not nil) - four safe primes are needed.
+func GenerateKeyPairContext(ctx context.Context, p *Params, attrs *AttrCount,
+	s *common.PrimeSearch) (*KeyPair, error) {
+	if s == nil {
+		s = new(common.PrimeSearch)
+	}
+	g, err := qr.NewRSASpecialContext(ctx, int(p.NLength)/2, s)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating RSASpecial group")
 	}
 
 	// receiver for commitments of (committed) attributes:
-	commRecv, err := df.NewReceiver(int(p.NLength/2), int(p.SecParam))
+	commRecv, err := df.NewReceiverContext(ctx, int(p.NLength/2), int(p.SecParam), s)
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating DF commitment receiver")
 	}
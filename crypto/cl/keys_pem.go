@@ -0,0 +1,141 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/pedersen"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+)
+
+// PEMTypePubKey is the type of PEM blocks holding CL public keys.
+const PEMTypePubKey = "EMMY CL PUBLIC KEY"
+
+// pubKeyASN1 is the DER representation of PubKey.
+type pubKeyASN1 struct {
+	N, S, Z     *big.Int
+	RsKnown     []*big.Int
+	RsCommitted []*big.Int
+	RsHidden    []*big.Int
+	// Pedersen parameters
+	P, Q, G, H *big.Int
+	// parameters for commitments of the (committed) attributes
+	N1, CommG, CommH *big.Int
+}
+
+// MarshalPEM returns k as a DER sequence of its components in a PEM block.
+func (k *PubKey) MarshalPEM() ([]byte, error) {
+	der, err := k.marshalDER()
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: PEMTypePubKey, Bytes: der}), nil
+}
+
+// ID returns the ID of k: the first 8 bytes of the SHA-256 hash of its DER form,
+// in hex. Credentials are tagged with the ID of the key they were issued under,
+// so that verifiers can select the key of a proof while keys are rotated.
+// The ID of an incomplete key is empty.
+func (k *PubKey) ID() string {
+	der, err := k.marshalDER()
+	if err != nil {
+		return ""
+	}
+	h := sha256.Sum256(der)
+	return hex.EncodeToString(h[:8])
+}
+
+func (k *PubKey) marshalDER() ([]byte, error) {
+	if k.PedersenParams == nil || k.PedersenParams.Group == nil {
+		return nil, fmt.Errorf("incomplete public key")
+	}
+	group := k.PedersenParams.Group
+	return asn1.Marshal(pubKeyASN1{
+		N:           k.N,
+		S:           k.S,
+		Z:           k.Z,
+		RsKnown:     k.RsKnown,
+		RsCommitted: k.RsCommitted,
+		RsHidden:    k.RsHidden,
+		P:           group.P,
+		Q:           group.Q,
+		G:           group.G,
+		H:           k.PedersenParams.H,
+		N1:          k.N1,
+		CommG:       k.G,
+		CommH:       k.H,
+	})
+}
+
+// ParsePubKeyPEM parses a public key returned by PubKey.MarshalPEM.
+func ParsePubKeyPEM(data []byte) (*PubKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != PEMTypePubKey {
+		return nil, fmt.Errorf("no %s PEM block found", PEMTypePubKey)
+	}
+
+	k := new(pubKeyASN1)
+	rest, err := asn1.Unmarshal(block.Bytes, k)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("trailing data after public key")
+	}
+
+	return &PubKey{
+		N:              k.N,
+		S:              k.S,
+		Z:              k.Z,
+		RsKnown:        k.RsKnown,
+		RsCommitted:    k.RsCommitted,
+		RsHidden:       k.RsHidden,
+		PedersenParams: pedersen.NewParams(schnorr.NewGroupFromParams(k.P, k.G, k.Q), k.H, nil),
+		N1:             k.N1,
+		G:              k.CommG,
+		H:              k.CommH,
+	}, nil
+}
+
+// ReadPubKey reads a public key from filePath, either in PEM form or gob encoded.
+func ReadPubKey(filePath string) (*PubKey, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if block, _ := pem.Decode(data); block != nil {
+		return ParsePubKeyPEM(data)
+	}
+
+	pubKey := new(PubKey)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(pubKey); err != nil {
+		return nil, err
+	}
+
+	return pubKey, nil
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPubKeyPEM(t *testing.T) {
+	pk := loadTestOrg(t, GetDefaultParamSizes()).Keys.Pub
+
+	data, err := pk.MarshalPEM()
+	assert.NoError(t, err)
+	decoded, err := ParsePubKeyPEM(data)
+	if err != nil {
+		t.Fatalf("error when parsing public key: %v", err)
+	}
+	assert.Equal(t, pk.GetContext(), decoded.GetContext())
+	assert.Equal(t, pk.PedersenParams.Group, decoded.PedersenParams.Group)
+	assert.Equal(t, pk.PedersenParams.H, decoded.PedersenParams.H)
+	assert.Equal(t, pk.N1, decoded.N1)
+	assert.Equal(t, pk.G, decoded.G)
+	assert.Equal(t, pk.H, decoded.H)
+	assert.Len(t, pk.ID(), 16)
+	assert.Equal(t, pk.ID(), decoded.ID(), "IDs of the same key should match")
+	assert.Empty(t, new(PubKey).ID())
+
+	otherKeys, err := GenerateKeyPair(GetDefaultParamSizes(), NewAttrCount(2, 1, 1))
+	if err != nil {
+		t.Fatalf("error when generating key pair: %v", err)
+	}
+	assert.NotEqual(t, pk.ID(), otherKeys.Pub.ID())
+
+	// ReadPubKey accepts both PEM and gob encoded keys
+	dir, _ := ioutil.TempDir("", "emmy-cl")
+	defer os.RemoveAll(dir)
+	pemPath := filepath.Join(dir, "pub.pem")
+	gobPath := filepath.Join(dir, "pub.gob")
+	assert.NoError(t, ioutil.WriteFile(pemPath, data, 0644))
+	assert.NoError(t, WriteGob(gobPath, pk))
+
+	for _, path := range []string{pemPath, gobPath} {
+		read, err := ReadPubKey(path)
+		assert.NoError(t, err)
+		assert.Equal(t, pk.GetContext(), read.GetContext())
+	}
+}
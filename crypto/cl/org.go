@@ -18,15 +18,17 @@
 package cl
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"math/big"
 
-	"crypto/rand"
 	"encoding/gob"
 	"os"
 
 	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto"
+	"github.com/xlab-si/emmy/crypto/commitments"
 	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/df"
 	"github.com/xlab-si/emmy/crypto/pedersen"
@@ -45,9 +47,10 @@ type Org struct {
 	Keys               *KeyPair
 	commitmentsOfAttrs []*big.Int
 	knownAttrs         []*big.Int
-	attrsVerifiers     []*df.OpeningVerifier // user proves the knowledge of commitment opening (committedAttrs)
+	attrsScheme        commitments.Scheme // user proves the knowledge of commitment opening (committedAttrs)
 	credIssueNonceOrg  *big.Int
 	proveCredNonceOrg  *big.Int
+	signer             Signer
 }
 
 func NewOrg(params *Params, attrCount *AttrCount) (*Org, error) {
@@ -75,19 +78,24 @@ func NewOrgFromParams(params *Params, keys *KeyPair) (*Org, error) {
 	}
 
 	pedersenReceiver := pedersen.NewReceiverFromParams(keys.Pub.PedersenParams)
+	attrsScheme, err := attrsCommitmentScheme(params, keys.Pub)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Org{
 		Params:           params,
 		Keys:             keys,
 		Group:            group,
 		pedersenReceiver: pedersenReceiver,
+		attrsScheme:      attrsScheme,
 	}, nil
 }
 
 // FIXME
-func LoadOrg(pubKeyPath, secKeyPath string) (*Org, error) {
-	pubKey := new(PubKey)
-	if err := ReadGob(pubKeyPath, pubKey); err != nil {
+func LoadOrg(params *Params, pubKeyPath, secKeyPath string) (*Org, error) {
+	pubKey, err := ReadPubKey(pubKeyPath)
+	if err != nil {
 		return nil, err
 	}
 	secKey := new(SecKey)
@@ -100,7 +108,6 @@ func LoadOrg(pubKeyPath, secKeyPath string) (*Org, error) {
 		Pub: pubKey,
 	}
 
-	params := GetDefaultParamSizes()
 	org, err := NewOrgFromParams(params, keys)
 	if err != nil {
 		return nil, fmt.Errorf("error when loading CL org: %v", err)
@@ -109,6 +116,57 @@ func LoadOrg(pubKeyPath, secKeyPath string) (*Org, error) {
 	return org, nil
 }
 
+// LoadOrgFromKeyStore loads the organization's public key from pubKeyPath and its
+// secret key from ks, where it is held under label serialized as by WriteGob.
+func LoadOrgFromKeyStore(params *Params, pubKeyPath string, ks crypto.KeyStore,
+	label string) (*Org, error) {
+	pubKey, err := ReadPubKey(pubKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ks.Load(label)
+	if err != nil {
+		return nil, fmt.Errorf("error when loading CL secret key: %v", err)
+	}
+	secKey := new(SecKey)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(secKey); err != nil {
+		return nil, fmt.Errorf("error when decoding CL secret key: %v", err)
+	}
+
+	org, err := NewOrgFromParams(params, &KeyPair{Sec: secKey, Pub: pubKey})
+	if err != nil {
+		return nil, fmt.Errorf("error when loading CL org: %v", err)
+	}
+
+	return org, nil
+}
+
+// LoadOrCreateOrg loads the organization's keys from pubKeyPath and secKeyPath.
+// If the key files do not exist yet, a new key pair for the given parameters and
+// attribute count is generated and written to these paths, so that subsequent loads
+// use the same keys.
+func LoadOrCreateOrg(params *Params, pubKeyPath, secKeyPath string,
+	attrCount *AttrCount) (*Org, error) {
+	_, errPub := os.Stat(pubKeyPath)
+	_, errSec := os.Stat(secKeyPath)
+	if !os.IsNotExist(errPub) || !os.IsNotExist(errSec) {
+		return LoadOrg(params, pubKeyPath, secKeyPath)
+	}
+
+	org, err := NewOrg(params, attrCount)
+	if err != nil {
+		return nil, fmt.Errorf("error when generating CL org: %v", err)
+	}
+	if err := WriteGob(pubKeyPath, org.Keys.Pub); err != nil {
+		return nil, err
+	}
+	if err := WriteGob(secKeyPath, org.Keys.Sec); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
 func (o *Org) GenNonce() *big.Int {
 	secParam := big.NewInt(int64(o.Params.SecParam))
 	b := new(big.Int).Exp(big.NewInt(2), secParam, nil)
@@ -116,19 +174,35 @@ func (o *Org) GenNonce() *big.Int {
 	return common.GetRandomInt(b)
 }
 
+// attrsProduct returns S^v * R_1^attr_1 * ... * R_j^attr_j where attributes are
+// from known and committed, computed as a single multi-exponentiation. Nil is
+// returned if a base with a negative exponent is not invertible.
+func (o *Org) attrsProduct(known, committed []*big.Int, v *big.Int) *big.Int {
+	bases := make([]*big.Int, 0, len(known)+len(committed)+1)
+	bases = append(bases, o.Keys.Pub.RsKnown[:len(known)]...)
+	bases = append(bases, o.Keys.Pub.RsCommitted[:len(committed)]...)
+	bases = append(bases, o.Keys.Pub.S)
+	exps := make([]*big.Int, 0, len(bases))
+	exps = append(exps, known...)
+	exps = append(exps, committed...)
+	exps = append(exps, v)
+
+	return common.MultiExp(new(big.Int), bases, exps, o.Group.N)
+}
+
 func (o *Org) genCredRandoms() (*big.Int, *big.Int) {
 	exp := big.NewInt(int64(o.Params.EBitLen - 1))
 	b := new(big.Int).Exp(big.NewInt(2), exp, nil)
 	var e *big.Int
 	for {
-		er, _ := rand.Prime(rand.Reader, int(o.Params.E1BitLen)-1)
+		er, _ := common.GetRandomPrime(int(o.Params.E1BitLen) - 1)
 		e = new(big.Int).Add(er, b)
 		if e.ProbablyPrime(20) { // e needs to be prime
 			break
 		}
 	}
 
-	vr, _ := rand.Prime(rand.Reader, int(o.Params.VBitLen)-1)
+	vr, _ := common.GetRandomPrime(int(o.Params.VBitLen) - 1)
 	exp = big.NewInt(int64(o.Params.VBitLen - 1))
 	b = new(big.Int).Exp(big.NewInt(2), exp, nil)
 	v11 := new(big.Int).Add(vr, b)
@@ -147,6 +221,30 @@ func (o *Org) genAProof(nonceUser, context, eInv, Q, A *big.Int) *qr.Representat
 	return qr.NewRepresentationProof(proofRandomData, challenge, proofData)
 }
 
+// UseSigner makes the organization compute signatures of credentials with s (for
+// example with ThresholdSign), instead of its secret key.
+func (o *Org) UseSigner(s Signer) {
+	o.signer = s
+}
+
+// sign returns A = Q^(1/e) mod N and the proof that A is correctly formed for
+// nonceUser.
+func (o *Org) sign(Q, e, nonceUser *big.Int) (*big.Int, *qr.RepresentationProof, error) {
+	if o.signer != nil {
+		return o.signer.Sign(Q, e, nonceUser)
+	}
+	if o.Group.P1 == nil {
+		return nil, nil, fmt.Errorf("secret key of the organization not available")
+	}
+
+	phiN := new(big.Int).Mul(o.Group.P1, o.Group.Q1)
+	eInv := new(big.Int).ModInverse(e, phiN)
+	A := common.BlindedExpMod(new(big.Int), Q, eInv, phiN, o.Group.N)
+	context := o.Keys.Pub.GetContext()
+
+	return A, o.genAProof(nonceUser, context, eInv, Q, A), nil
+}
+
 type CredResult struct {
 	Cred   *Cred
 	AProof *qr.RepresentationProof
@@ -154,15 +252,16 @@ type CredResult struct {
 }
 
 func (o *Org) IssueCred(cr *CredRequest) (*CredResult, error) {
+	if err := o.checkCredRequest(cr); err != nil {
+		return nil, err
+	}
+
 	o.nymVerifier = schnorr.NewVerifier(o.pedersenReceiver.Params.Group)
 	o.UVerifier = qr.NewRepresentationVerifier(o.Group, int(o.Params.SecParam))
 
 	o.nym = cr.Nym
 	o.knownAttrs = cr.KnownAttrs
-	err := o.setUpAttrVerifiers(cr.CommitmentsOfAttrs)
-	if err != nil {
-		return nil, err
-	}
+	o.commitmentsOfAttrs = cr.CommitmentsOfAttrs
 	o.U = cr.U
 
 	if verified := o.verifyCredRequest(cr); !verified {
@@ -172,43 +271,58 @@ func (o *Org) IssueCred(cr *CredRequest) (*CredResult, error) {
 	e, v11 := o.genCredRandoms()
 
 	// denom = U * S^v11 * R_1^attr_1 * ... * R_j^attr_j where only attributes from knownAttrs and committedAttrs
-	acc := big.NewInt(1)
-	for ind := 0; ind < len(o.knownAttrs); ind++ {
-		t1 := o.Group.Exp(o.Keys.Pub.RsKnown[ind], o.knownAttrs[ind])
-		acc = o.Group.Mul(acc, t1)
-	}
-
-	for ind := 0; ind < len(o.commitmentsOfAttrs); ind++ {
-		t1 := o.Group.Exp(o.Keys.Pub.RsCommitted[ind], o.commitmentsOfAttrs[ind])
-		acc = o.Group.Mul(acc, t1)
-	}
-
-	t := o.Group.Exp(o.Keys.Pub.S, v11) // s^v11
-	denom := o.Group.Mul(t, o.U)        // U * s^v11
-	denom = o.Group.Mul(denom, acc)     // U * s^v11 * acc
+	acc := o.attrsProduct(o.knownAttrs, o.commitmentsOfAttrs, v11)
+	denom := o.Group.Mul(acc, o.U)
 	denomInv := o.Group.Inv(denom)
 	Q := o.Group.Mul(o.Keys.Pub.Z, denomInv)
 
-	phiN := new(big.Int).Mul(o.Group.P1, o.Group.Q1)
-	eInv := new(big.Int).ModInverse(e, phiN)
-	A := o.Group.Exp(Q, eInv)
-
+	A, AProof, err := o.sign(Q, e, cr.Nonce) // nonceUser!
+	if err != nil {
+		return nil, err
+	}
 	context := o.Keys.Pub.GetContext()
-	AProof := o.genAProof(cr.Nonce, context, eInv, Q, A) // nonceUser!
 
+	cred := NewCred(A, e, v11)
+	cred.KeyID = o.Keys.Pub.ID()
 	res := &CredResult{
-		Cred:   NewCred(A, e, v11),
+		Cred:   cred,
 		AProof: AProof,
-		Record: NewReceiverRecord(o.knownAttrs, o.commitmentsOfAttrs, Q, v11, context),
+		Record: NewReceiverRecord(o.knownAttrs, o.commitmentsOfAttrs, Q, v11, e, context),
 	}
 
 	return res, nil
 }
 
+// UpdateCred updates the credential issued to nym, whose receiver record is rec, with
+// new values of known attributes. Committed attributes are left as they are.
 func (o *Org) UpdateCred(nym *big.Int, rec *ReceiverRecord, nonceUser *big.Int, newKnownAttrs []*big.Int) (*CredResult, error) {
+	return o.UpdateCredAttrs(rec, &CredUpdate{
+		Nym:        nym,
+		Nonce:      nonceUser,
+		KnownAttrs: newKnownAttrs,
+	})
+}
+
+// UpdateCredAttrs updates the credential whose receiver record is rec as requested by
+// u. New commitments of committed attributes are accepted only along with valid proofs
+// that the receiver can open them, so that committed attributes can change without
+// issuing the credential again.
+func (o *Org) UpdateCredAttrs(rec *ReceiverRecord, u *CredUpdate) (*CredResult, error) {
+	if len(u.KnownAttrs) != len(rec.KnownAttrs) {
+		return nil, fmt.Errorf("expected %d known attributes, got %d", len(rec.KnownAttrs),
+			len(u.KnownAttrs))
+	}
+	commitmentsOfAttrs := rec.CommitmentsOfAttrs
+	if len(u.CommitmentsOfAttrs) > 0 {
+		if err := o.verifyCommitmentsUpdate(rec, u); err != nil {
+			return nil, err
+		}
+		commitmentsOfAttrs = u.CommitmentsOfAttrs
+	}
+
 	if o.knownAttrs == nil { // for example when Org is instantiated and there is no call to IssueCred
-		o.knownAttrs = newKnownAttrs
-		o.setUpAttrVerifiers(rec.CommitmentsOfAttrs)
+		o.knownAttrs = u.KnownAttrs
+		o.commitmentsOfAttrs = commitmentsOfAttrs
 		o.nymVerifier = schnorr.NewVerifier(o.pedersenReceiver.Params.Group) // pubKey.Params.Group
 		o.UVerifier = qr.NewRepresentationVerifier(o.Group,
 			int(o.Params.SecParam))
@@ -217,34 +331,69 @@ func (o *Org) UpdateCred(nym *big.Int, rec *ReceiverRecord, nonceUser *big.Int,
 	e, v11 := o.genCredRandoms()
 	v11Diff := new(big.Int).Sub(v11, rec.V11)
 
-	acc := big.NewInt(1)
-	for ind := 0; ind < len(o.knownAttrs); ind++ {
-		t1 := o.Group.Exp(o.Keys.Pub.RsKnown[ind],
-			new(big.Int).Sub(newKnownAttrs[ind], rec.KnownAttrs[ind]))
-		acc = o.Group.Mul(acc, t1)
+	knownDiffs := make([]*big.Int, len(u.KnownAttrs))
+	for i, a := range u.KnownAttrs {
+		knownDiffs[i] = new(big.Int).Sub(a, rec.KnownAttrs[i])
+	}
+	commitmentsDiffs := make([]*big.Int, len(commitmentsOfAttrs))
+	for i, c := range commitmentsOfAttrs {
+		commitmentsDiffs[i] = new(big.Int).Sub(c, rec.CommitmentsOfAttrs[i])
+	}
+	denom := o.attrsProduct(knownDiffs, commitmentsDiffs, v11Diff)
+	if denom == nil {
+		return nil, fmt.Errorf("public key bases are not invertible")
 	}
-	t := o.Group.Exp(o.Keys.Pub.S, v11Diff)
-	denom := o.Group.Mul(acc, t)
 	denomInv := o.Group.Inv(denom)
 	newQ := o.Group.Mul(rec.Q, denomInv)
 
-	phiN := new(big.Int).Mul(o.Group.P1, o.Group.Q1)
-	eInv := new(big.Int).ModInverse(e, phiN)
-	newA := o.Group.Exp(newQ, eInv)
-
+	newA, AProof, err := o.sign(newQ, e, u.Nonce)
+	if err != nil {
+		return nil, err
+	}
 	context := o.Keys.Pub.GetContext()
-	AProof := o.genAProof(nonceUser, context, eInv, newQ, newA)
-	// currently commitmentsOfAttrs cannot be updated
 
+	cred := NewCred(newA, e, v11)
+	cred.KeyID = o.Keys.Pub.ID()
 	res := &CredResult{
-		Cred:   NewCred(newA, e, v11),
+		Cred:   cred,
 		AProof: AProof,
-		Record: NewReceiverRecord(newKnownAttrs, rec.CommitmentsOfAttrs, newQ, v11, context),
+		Record: NewReceiverRecord(u.KnownAttrs, commitmentsOfAttrs, newQ, v11, e, context),
 	}
 
 	return res, nil
 }
 
+// verifyCommitmentsUpdate verifies proofs of opening of new commitments of attributes
+// in the credential update u of the credential whose receiver record is rec.
+func (o *Org) verifyCommitmentsUpdate(rec *ReceiverRecord, u *CredUpdate) error {
+	switch {
+	case u.Nym == nil || u.Nonce == nil:
+		return fmt.Errorf("incomplete credential update")
+	case len(u.CommitmentsOfAttrs) != len(rec.CommitmentsOfAttrs):
+		return fmt.Errorf("expected %d commitments of attributes, got %d",
+			len(rec.CommitmentsOfAttrs), len(u.CommitmentsOfAttrs))
+	case len(u.CommitmentsOfAttrsProofs) != len(u.CommitmentsOfAttrs):
+		return fmt.Errorf("expected %d proofs of commitments of attributes, got %d",
+			len(u.CommitmentsOfAttrs), len(u.CommitmentsOfAttrsProofs))
+	}
+
+	randomData := make([]*big.Int, len(u.CommitmentsOfAttrsProofs))
+	for i, proof := range u.CommitmentsOfAttrsProofs {
+		if u.CommitmentsOfAttrs[i] == nil || proof == nil {
+			return fmt.Errorf("missing commitment of attribute %d", i)
+		}
+		randomData[i] = proof.ProofRandomData
+	}
+	challenge := credUpdateChallenge(o.Keys.Pub.GetContext(), u.Nym, u.Nonce,
+		u.CommitmentsOfAttrs, randomData)
+	o.commitmentsOfAttrs = u.CommitmentsOfAttrs
+	if !o.verifyCommitmentsOfAttrs(u.CommitmentsOfAttrsProofs, challenge) {
+		return fmt.Errorf("proofs of commitments of attributes not valid")
+	}
+
+	return nil
+}
+
 func (o *Org) GetProveCredNonce() *big.Int {
 	nonce := o.GenNonce()
 	o.proveCredNonceOrg = nonce
@@ -252,6 +401,22 @@ func (o *Org) GetProveCredNonce() *big.Int {
 	return nonce
 }
 
+// SetProveCredNonce sets the nonce that proofs of credentials need to be built with.
+// It enables non-interactive proofs, where the nonce is derived from a context with
+// ContextNonce instead of being obtained with GetProveCredNonce.
+func (o *Org) SetProveCredNonce(nonce *big.Int) {
+	o.proveCredNonceOrg = nonce
+}
+
+// ContextNonce derives the nonce of a non-interactive proof of a credential from context,
+// a value that the user and the organization agree on (for example, a hash of the time of
+// the proof and a random value chosen by the user). Like nonces generated by the
+// organization, it is smaller than 2^SecParam.
+func ContextNonce(params *Params, context *big.Int) *big.Int {
+	h := common.Hash(context)
+	return h.Mod(h, new(big.Int).Lsh(big.NewInt(1), uint(params.SecParam)))
+}
+
 // ProveCred proves the possession of a valid credential and reveals only the attributes the user desires
 // to reveal. Which knownAttrs and commitmentsOfAttrs are to be revealed are given by revealedKnownAttrsIndices and
 // revealedCommitmentsOfAttrsIndices parameters. Parameters knownAttrs and commitmentsOfAttrs must contain only
@@ -260,6 +425,17 @@ func (o *Org) GetProveCredNonce() *big.Int {
 func (o *Org) ProveCred(A *big.Int, proof *qr.RepresentationProof,
 	revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices []int,
 	revealedKnownAttrs, revealedCommitmentsOfAttrs []*big.Int) (bool, error) {
+	if proof == nil || A == nil {
+		return false, fmt.Errorf("incomplete proof")
+	}
+	if err := checkRevealed(revealedKnownAttrsIndices, revealedKnownAttrs,
+		len(o.Keys.Pub.RsKnown)); err != nil {
+		return false, fmt.Errorf("known attributes: %v", err)
+	}
+	if err := checkRevealed(revealedCommitmentsOfAttrsIndices, revealedCommitmentsOfAttrs,
+		len(o.Keys.Pub.RsCommitted)); err != nil {
+		return false, fmt.Errorf("commitments of attributes: %v", err)
+	}
 
 	structure, err := config.LoadCredentialStructure()
 	if err != nil {
@@ -290,6 +466,9 @@ func (o *Org) ProveCred(A *big.Int, proof *qr.RepresentationProof,
 	// TODO: check values in a separate component
 	count = 0
 	for _, ind := range revealedKnownAttrsIndices {
+		if ind >= len(knownAttrs) {
+			return false, fmt.Errorf("no known attribute with index %d", ind)
+		}
 		a := knownAttrs[ind]
 		val, err := a.FromInternalValue(revealedKnownAttrs[count])
 		if err != nil {
@@ -320,8 +499,36 @@ func (o *Org) ProveCred(A *big.Int, proof *qr.RepresentationProof,
 		}
 	}
 
+	return o.VerifyCredProof(A, proof, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, revealedKnownAttrs, revealedCommitmentsOfAttrs)
+}
+
+// VerifyCredProof checks the equations of a proof of the possession of a credential, built
+// with the nonce obtained with GetProveCredNonce or set with SetProveCredNonce, like
+// ProveCred, but does not check whether revealed attributes are acceptable. It only needs
+// the public key of the organization, so that proofs can be verified again offline.
+func (o *Org) VerifyCredProof(A *big.Int, proof *qr.RepresentationProof,
+	revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices []int,
+	revealedKnownAttrs, revealedCommitmentsOfAttrs []*big.Int) (bool, error) {
+	if proof == nil || A == nil {
+		return false, fmt.Errorf("incomplete proof")
+	}
+	if err := checkRevealed(revealedKnownAttrsIndices, revealedKnownAttrs,
+		len(o.Keys.Pub.RsKnown)); err != nil {
+		return false, fmt.Errorf("known attributes: %v", err)
+	}
+	if err := checkRevealed(revealedCommitmentsOfAttrsIndices, revealedCommitmentsOfAttrs,
+		len(o.Keys.Pub.RsCommitted)); err != nil {
+		return false, fmt.Errorf("commitments of attributes: %v", err)
+	}
+	// A is randomized with a power of S, thus it is in QR_N like the issued A
+	if err := o.Group.CheckElement(A); err != nil {
+		return false, fmt.Errorf("A: %w", err)
+	}
+
 	ver := qr.NewRepresentationVerifier(o.Group, int(o.Params.SecParam))
-	bases := []*big.Int{}
+	bases := make([]*big.Int, 0, len(o.Keys.Pub.RsKnown)+len(o.Keys.Pub.RsCommitted)+
+		len(o.Keys.Pub.RsHidden)+2)
 	for i := 0; i < len(o.Keys.Pub.RsKnown); i++ {
 		if !common.Contains(revealedKnownAttrsIndices, i) {
 			bases = append(bases, o.Keys.Pub.RsKnown[i])
@@ -336,17 +543,21 @@ func (o *Org) ProveCred(A *big.Int, proof *qr.RepresentationProof,
 	bases = append(bases, A)
 	bases = append(bases, o.Keys.Pub.S)
 
-	denom := big.NewInt(1)
-	for i := 0; i < len(revealedKnownAttrs); i++ {
-		rInd := revealedKnownAttrsIndices[i]
-		t1 := o.Group.Exp(o.Keys.Pub.RsKnown[rInd], revealedKnownAttrs[i])
-		denom = o.Group.Mul(denom, t1)
+	// denom = R_i^attr_i * ... for revealed attributes and commitments of attributes
+	nRevealed := len(revealedKnownAttrs) + len(revealedCommitmentsOfAttrs)
+	revealedBases := make([]*big.Int, 0, nRevealed)
+	revealedVals := make([]*big.Int, 0, nRevealed)
+	for i, rInd := range revealedKnownAttrsIndices {
+		revealedBases = append(revealedBases, o.Keys.Pub.RsKnown[rInd])
+		revealedVals = append(revealedVals, revealedKnownAttrs[i])
 	}
-
-	for i := 0; i < len(revealedCommitmentsOfAttrs); i++ {
-		rInd := revealedCommitmentsOfAttrsIndices[i]
-		t1 := o.Group.Exp(o.Keys.Pub.RsCommitted[rInd], revealedCommitmentsOfAttrs[i])
-		denom = o.Group.Mul(denom, t1)
+	for i, rInd := range revealedCommitmentsOfAttrsIndices {
+		revealedBases = append(revealedBases, o.Keys.Pub.RsCommitted[rInd])
+		revealedVals = append(revealedVals, revealedCommitmentsOfAttrs[i])
+	}
+	denom := common.MultiExp(new(big.Int), revealedBases, revealedVals, o.Group.N)
+	if denom == nil {
+		return false, fmt.Errorf("revealed attributes not valid")
 	}
 	denomInv := o.Group.Inv(denom)
 	y := o.Group.Mul(o.Keys.Pub.Z, denomInv)
@@ -371,6 +582,10 @@ type Cred struct {
 	A   *big.Int
 	E   *big.Int
 	V11 *big.Int
+	// Witness of non-revocation, given by issuers that revoke credentials
+	Witness *Witness
+	// ID of the public key the credential was issued under (see PubKey.ID)
+	KeyID string
 }
 
 func NewCred(A, e, v11 *big.Int) *Cred {
@@ -389,21 +604,26 @@ func generateQuadraticResidues(group *qr.RSASpecial, knownAttrsNum, committedAtt
 	if err != nil {
 		return nil, nil, nil, nil, nil, fmt.Errorf("error when searching for RSASpecial generator: %s", err)
 	}
-	Z := group.Exp(S, common.GetRandomInt(group.Order))
+	// exponents of S are secret, since they are discrete logarithms of the bases
+	randomPower := func() *big.Int {
+		return common.BlindedExpMod(new(big.Int), S, common.GetRandomInt(group.Order),
+			group.Order, group.N)
+	}
+	Z := randomPower()
 
 	RsKnown := make([]*big.Int, knownAttrsNum)
 	for i, _ := range RsKnown {
-		RsKnown[i] = group.Exp(S, common.GetRandomInt(group.Order))
+		RsKnown[i] = randomPower()
 	}
 
 	RsCommitted := make([]*big.Int, committedAttrsNum)
 	for i, _ := range RsCommitted {
-		RsCommitted[i] = group.Exp(S, common.GetRandomInt(group.Order))
+		RsCommitted[i] = randomPower()
 	}
 
 	RsHidden := make([]*big.Int, hiddenAttrsNum)
 	for i, _ := range RsHidden {
-		RsHidden[i] = group.Exp(S, common.GetRandomInt(group.Order))
+		RsHidden[i] = randomPower()
 	}
 
 	return S, Z, RsKnown, RsCommitted, RsHidden, nil
@@ -416,11 +636,65 @@ func (o *Org) GetCredIssueNonce() *big.Int {
 	return nonce
 }
 
+// SetCredIssueNonce sets the nonce that credential requests need to be built with,
+// when it was generated by another instance of Org (for example, one serving a batch
+// of requests).
+func (o *Org) SetCredIssueNonce(nonce *big.Int) {
+	o.credIssueNonceOrg = nonce
+}
+
+// checkCredRequest checks that cr holds all the values needed for its verification,
+// so that malformed requests are rejected before they are verified.
+func (o *Org) checkCredRequest(cr *CredRequest) error {
+	switch {
+	case cr.Nym == nil || cr.U == nil || cr.Nonce == nil || cr.NymProof == nil || cr.UProof == nil:
+		return fmt.Errorf("incomplete credential request")
+	case len(cr.KnownAttrs) > len(o.Keys.Pub.RsKnown):
+		return fmt.Errorf("too many known attributes")
+	case len(cr.CommitmentsOfAttrs) > len(o.Keys.Pub.RsCommitted):
+		return fmt.Errorf("too many commitments of attributes")
+	case len(cr.CommitmentsOfAttrsProofs) != len(cr.CommitmentsOfAttrs):
+		return fmt.Errorf("expected %d proofs of commitments of attributes, got %d",
+			len(cr.CommitmentsOfAttrs), len(cr.CommitmentsOfAttrsProofs))
+	case len(cr.UProof.ProofData) != len(o.Keys.Pub.RsHidden)+1:
+		return fmt.Errorf("malformed proof of U")
+	}
+	// nym is a Pedersen commitment, U is a product of powers of elements of QR_N
+	if err := o.pedersenReceiver.Params.Group.CheckElement(cr.Nym); err != nil {
+		return fmt.Errorf("nym: %w", err)
+	}
+	if err := o.Group.CheckElement(cr.U); err != nil {
+		return fmt.Errorf("U: %w", err)
+	}
+
+	return nil
+}
+
+// checkRevealed checks that there is a value for each of the indices of revealed
+// attributes, which must be distinct and lower than n.
+func checkRevealed(indices []int, vals []*big.Int, n int) error {
+	if len(indices) != len(vals) {
+		return fmt.Errorf("%d indices for %d values", len(indices), len(vals))
+	}
+	seen := make(map[int]bool, len(indices))
+	for i, ind := range indices {
+		if ind < 0 || ind >= n || seen[ind] {
+			return fmt.Errorf("invalid index %d", ind)
+		}
+		if vals[i] == nil {
+			return fmt.Errorf("missing value at index %d", ind)
+		}
+		seen[ind] = true
+	}
+
+	return nil
+}
+
 func (o *Org) verifyCredRequest(cr *CredRequest) bool {
 	return o.verifyNym(cr.NymProof) &&
 		o.verifyU(cr.UProof) &&
-		o.verifyCommitmentsOfAttrs(cr.CommitmentsOfAttrs, cr.CommitmentsOfAttrsProofs) &&
-		o.verifyChallenge(cr.UProof.Challenge) &&
+		o.verifyCommitmentsOfAttrs(cr.CommitmentsOfAttrsProofs, cr.UProof.Challenge) &&
+		o.verifyChallenge(cr.UProof.Challenge, cr.CommitmentsOfAttrsProofs) &&
 		o.verifyUProofDataLengths(cr.UProof.ProofData)
 }
 
@@ -444,36 +718,25 @@ func (o *Org) verifyU(UProof *qr.RepresentationProof) bool {
 	return o.UVerifier.Verify(UProof.ProofData)
 }
 
-func (o *Org) setUpAttrVerifiers(commitmentsOfAttrs []*big.Int) error {
-	attrsVerifiers := make([]*df.OpeningVerifier, len(commitmentsOfAttrs))
-	for i, attr := range commitmentsOfAttrs {
-		receiver, err := df.NewReceiverFromParams(
-			o.Keys.Sec.AttributesSpecialRSAPrimes, o.Keys.Pub.G, o.Keys.Pub.H,
-			int(o.Params.SecParam))
-		if err != nil {
-			return err
-		}
-		receiver.SetCommitment(attr)
-
-		verifier := df.NewOpeningVerifier(receiver,
-			int(o.Params.ChallengeSpace))
-		attrsVerifiers[i] = verifier
+// newAttrReceiver returns a receiver of commitments of attributes. Organizations
+// without the secret key (see UseSigner) use the public modulus of commitments.
+func (o *Org) newAttrReceiver() (*df.Receiver, error) {
+	if o.Keys.Sec == nil {
+		return df.NewPublicReceiver(o.Keys.Pub.N1, o.Keys.Pub.G, o.Keys.Pub.H,
+			o.Params.SecParam), nil
 	}
-
-	o.attrsVerifiers = attrsVerifiers
-	o.commitmentsOfAttrs = commitmentsOfAttrs
-
-	return nil
+	return df.NewReceiverFromParams(o.Keys.Sec.AttributesSpecialRSAPrimes, o.Keys.Pub.G,
+		o.Keys.Pub.H, o.Params.SecParam)
 }
 
-// commitments ... commitmentsOfAttrs
-// proofs ... commitmentsOfAttrsProofs
-func (o *Org) verifyCommitmentsOfAttrs(commitmentsOfAttrs []*big.Int, proofs []*df.OpeningProof) bool {
-	//o.setUpAttrVerifiers(commitmentsOfAttrs)
-	for i, v := range o.attrsVerifiers {
-		v.SetProofRandomData(proofs[i].ProofRandomData)
-		v.SetChallenge(proofs[i].Challenge)
-		if !v.Verify(proofs[i].ProofData1, proofs[i].ProofData2) {
+// verifyCommitmentsOfAttrs verifies proofs of opening of commitments of attributes,
+// which need to be computed for the challenge of the credential request.
+func (o *Org) verifyCommitmentsOfAttrs(proofs []*commitments.OpeningProof, challenge *big.Int) bool {
+	for i, c := range o.commitmentsOfAttrs {
+		if proofs[i].Challenge == nil || proofs[i].Challenge.Cmp(challenge) != 0 {
+			return false
+		}
+		if !o.attrsScheme.VerifyOpening(c, proofs[i]) {
 			return false
 		}
 	}
@@ -481,10 +744,13 @@ func (o *Org) verifyCommitmentsOfAttrs(commitmentsOfAttrs []*big.Int, proofs []*
 	return true
 }
 
-func (o *Org) verifyChallenge(challenge *big.Int) bool {
+func (o *Org) verifyChallenge(challenge *big.Int, commitmentsOfAttrsProofs []*commitments.OpeningProof) bool {
 	context := o.Keys.Pub.GetContext()
 	l := []*big.Int{context, o.U, o.nym, o.credIssueNonceOrg}
 	l = append(l, o.commitmentsOfAttrs...)
+	for _, proof := range commitmentsOfAttrsProofs {
+		l = append(l, proof.ProofRandomData)
+	}
 	c := common.Hash(l...)
 	return c.Cmp(challenge) == 0
 }
@@ -518,16 +784,19 @@ type ReceiverRecord struct {
 	CommitmentsOfAttrs []*big.Int
 	Q                  *big.Int
 	V11                *big.Int
+	E                  *big.Int // prime of the last credential, its revocation handle
 	Context            *big.Int
 }
 
 // Returns ReceiverRecord which contains user data needed when updating the credential for this user.
-func NewReceiverRecord(knownAttrs, commitmentsOfAttrs []*big.Int, Q, v11, context *big.Int) *ReceiverRecord {
+func NewReceiverRecord(knownAttrs, commitmentsOfAttrs []*big.Int, Q, v11, e,
+	context *big.Int) *ReceiverRecord {
 	return &ReceiverRecord{
 		KnownAttrs:         knownAttrs,
 		CommitmentsOfAttrs: commitmentsOfAttrs,
 		Q:                  Q,
 		V11:                v11,
+		E:                  e,
 		Context:            context,
 	}
 }
@@ -17,6 +17,24 @@
 
 package cl
 
+import (
+	"fmt"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/commitments"
+)
+
+// Names of the available CL parameter presets.
+const (
+	// ParamsPresetTest holds small parameters that allow for fast key generation.
+	// They are NOT secure and are meant for testing only.
+	ParamsPresetTest = "test"
+	// ParamsPreset2048 holds parameters for a 2048-bit RSA modulus.
+	ParamsPreset2048 = "cl-2048"
+	// ParamsPreset3072 holds parameters for a 3072-bit RSA modulus.
+	ParamsPreset3072 = "cl-3072"
+)
+
 // Params presents parameters that organization (which is issuing credentials) needs to set.
 type Params struct {
 	// There are only a few possibilities for RhoBitLen. 256 implies that the modulus
@@ -33,9 +51,12 @@ type Params struct {
 	E1BitLen          int // size of the interval the e values are taken from
 	VBitLen           int // size of the v values of the certificates
 	ChallengeSpace    int // bit length of challenges for DF commitment proofs
+	// name of the scheme of commitments of committed attributes (see commitments.New),
+	// commitments.SchemeQR when empty
+	CommitmentScheme string
 }
 
-// TODO: add method to load params from file or blockchain or wherever they will be stored.
+// GetDefaultParamSizes returns parameters of the ParamsPresetTest preset.
 func GetDefaultParamSizes() *Params {
 	return &Params{
 		RhoBitLen:         256,
@@ -50,5 +71,110 @@ func GetDefaultParamSizes() *Params {
 		E1BitLen:          120,
 		VBitLen:           2724,
 		ChallengeSpace:    80,
+		CommitmentScheme:  commitments.SchemeQR,
+	}
+}
+
+// GetParamsPreset returns parameters of the preset with the given name.
+func GetParamsPreset(name string) (*Params, error) {
+	switch name {
+	case ParamsPresetTest:
+		return GetDefaultParamSizes(), nil
+	case ParamsPreset2048:
+		p := GetDefaultParamSizes()
+		p.NLength = 2048
+		return p, nil
+	case ParamsPreset3072:
+		p := GetDefaultParamSizes()
+		p.NLength = 3072
+		// v needs to be larger than the modulus by at least the same margin as
+		// with 2048-bit modulus
+		p.VBitLen = 3748
+		return p, nil
+	}
+
+	return nil, fmt.Errorf("unknown CL parameters preset: %s", name)
+}
+
+// LoadParams returns parameters of the preset set in the configuration
+// (key cl.params), with the scheme of commitments of committed attributes set in
+// the configuration (key cl.commitments), after making sure that they are valid.
+func LoadParams() (*Params, error) {
+	p, err := GetParamsPreset(config.LoadCLParamsPreset())
+	if err != nil {
+		return nil, err
+	}
+	p.CommitmentScheme = config.LoadCLCommitmentScheme()
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Validate checks whether parameters are consistent with each other.
+func (p *Params) Validate() error {
+	lengths := map[string]int{
+		"RhoBitLen":      p.RhoBitLen,
+		"NLength":        p.NLength,
+		"AttrBitLen":     p.AttrBitLen,
+		"HashBitLen":     p.HashBitLen,
+		"SecParam":       p.SecParam,
+		"EBitLen":        p.EBitLen,
+		"E1BitLen":       p.E1BitLen,
+		"VBitLen":        p.VBitLen,
+		"ChallengeSpace": p.ChallengeSpace,
+	}
+	for name, l := range lengths {
+		if l <= 0 {
+			return fmt.Errorf("%s must be positive, got %d", name, l)
+		}
+	}
+
+	if p.KnownAttrsNum < 0 || p.CommittedAttrsNum < 0 || p.HiddenAttrsNum < 0 {
+		return fmt.Errorf("number of attributes must not be negative")
+	}
+	// modulus is a product of two safe primes of equal length
+	if p.NLength%2 != 0 {
+		return fmt.Errorf("NLength must be even, got %d", p.NLength)
+	}
+	// e is chosen from [2^(EBitLen-1), 2^(EBitLen-1) + 2^(E1BitLen-1)] and
+	// needs to be larger than any attribute
+	if p.E1BitLen >= p.EBitLen {
+		return fmt.Errorf("E1BitLen (%d) must be smaller than EBitLen (%d)",
+			p.E1BitLen, p.EBitLen)
+	}
+	if p.EBitLen <= p.AttrBitLen+1 {
+		return fmt.Errorf("EBitLen (%d) must be larger than AttrBitLen + 1 (%d)",
+			p.EBitLen, p.AttrBitLen+1)
 	}
+	// v needs to statistically hide the value chosen by the receiver, which is
+	// of length NLength + SecParam
+	if p.VBitLen <= p.NLength+p.SecParam {
+		return fmt.Errorf("VBitLen (%d) must be larger than NLength + SecParam (%d)",
+			p.VBitLen, p.NLength+p.SecParam)
+	}
+	if p.CommitmentScheme != "" && !commitments.IsRegistered(p.CommitmentScheme) {
+		return fmt.Errorf("unknown commitment scheme: %s", p.CommitmentScheme)
+	}
+
+	return nil
+}
+
+// attrsCommitmentScheme returns the scheme of commitments of committed attributes,
+// instantiated with the modulus and generators of pubKey, which are used by
+// commitments.SchemeQR.
+func attrsCommitmentScheme(p *Params, pubKey *PubKey) (commitments.Scheme, error) {
+	name := p.CommitmentScheme
+	if name == "" {
+		name = commitments.SchemeQR
+	}
+
+	return commitments.New(name, &commitments.Params{
+		N:              pubKey.N1,
+		G:              pubKey.G,
+		H:              pubKey.H,
+		SecParam:       p.SecParam,
+		ChallengeSpace: p.ChallengeSpace,
+	})
 }
@@ -0,0 +1,62 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/commitments"
+)
+
+func TestParamsPresets(t *testing.T) {
+	for _, name := range []string{ParamsPresetTest, ParamsPreset2048, ParamsPreset3072} {
+		p, err := GetParamsPreset(name)
+		if err != nil {
+			t.Fatalf("error when getting preset %s: %v", name, err)
+		}
+		assert.NoError(t, p.Validate(), "preset %s should be valid", name)
+	}
+
+	_, err := GetParamsPreset("cl-1024")
+	assert.Error(t, err, "unknown preset should not be accepted")
+}
+
+func TestParamsValidate(t *testing.T) {
+	p := GetDefaultParamSizes()
+	p.NLength = 255
+	assert.Error(t, p.Validate(), "odd modulus length should not be accepted")
+
+	p = GetDefaultParamSizes()
+	p.VBitLen = p.NLength
+	assert.Error(t, p.Validate(), "too short v should not be accepted")
+
+	p = GetDefaultParamSizes()
+	p.E1BitLen = p.EBitLen
+	assert.Error(t, p.Validate(), "e interval should be smaller than e")
+}
+
+func TestParamsCommitmentScheme(t *testing.T) {
+	p := GetDefaultParamSizes()
+	p.CommitmentScheme = commitments.SchemeECP256
+	assert.NoError(t, p.Validate())
+	p.CommitmentScheme = "pedersen-p192"
+	assert.Error(t, p.Validate(), "unknown commitment scheme should not be accepted")
+	p.CommitmentScheme = ""
+	assert.NoError(t, p.Validate(), "commitments in QR_N should be used by default")
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// Policy holds rules that a proof of a credential needs to satisfy to be accepted
+// by a verifier.
+type Policy struct {
+	Name string
+	// IDs of keys (see PubKey.ID) of accepted issuers, credentials issued under any
+	// key are accepted when empty
+	Issuers []string
+	// attributes that need to be revealed
+	Revealed   []string
+	Predicates []*Predicate
+	// maximum time since the credential was issued (see IssuedAtAttr), not limited
+	// when 0
+	MaxAge time.Duration
+	// backend of range proofs that provers need to use, any backend is accepted
+	// when empty
+	RangeProofs RangeProofBackend
+}
+
+// AllPredicates returns predicates that proofs satisfying p need to show: its
+// predicates, along with the attributes it requires to be revealed.
+func (p *Policy) AllPredicates() []*Predicate {
+	preds := make([]*Predicate, 0, len(p.Predicates)+len(p.Revealed)+1)
+	preds = append(preds, p.Predicates...)
+	for _, attr := range p.Revealed {
+		preds = append(preds, Revealed(attr))
+	}
+	if p.MaxAge > 0 {
+		preds = append(preds, Revealed(IssuedAtAttr))
+	}
+	return preds
+}
+
+// Requests returns true if preds, with resolved values (see ResolvePredicates), are
+// exactly the predicates that proofs satisfying p need to show (see AllPredicates).
+func (p *Policy) Requests(preds []*Predicate) bool {
+	requested := p.AllPredicates()
+	contains := func(preds []*Predicate, pred *Predicate) bool {
+		for _, q := range preds {
+			if q.Equals(pred) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, pred := range preds {
+		if !contains(requested, pred) {
+			return false
+		}
+	}
+	for _, pred := range requested {
+		if !contains(preds, pred) {
+			return false
+		}
+	}
+	return true
+}
+
+// Check checks that a proof of a credential with the structure of rc, issued under
+// the key with ID keyID, satisfies p at time now, given its revealed known attributes
+// revealedKnownAttrs with indices revealedKnownAttrsIndices and verified range proofs,
+// which need to be of the backend that p requires.
+func (p *Policy) Check(rc *RawCred, keyID string, revealedKnownAttrsIndices []int,
+	revealedKnownAttrs []*big.Int, rangeProofs []*AttrRangeProof, now time.Time) error {
+	if len(p.Issuers) > 0 {
+		accepted := false
+		for _, id := range p.Issuers {
+			if id == keyID {
+				accepted = true
+			}
+		}
+		if !accepted {
+			return fmt.Errorf("issuer %s is not accepted", keyID)
+		}
+	}
+
+	if p.RangeProofs != "" {
+		for _, r := range rangeProofs {
+			if r.Backend() != p.RangeProofs {
+				return fmt.Errorf("range proofs need to be built with %s", p.RangeProofs)
+			}
+		}
+	}
+
+	if err := CheckPredicates(rc, p.AllPredicates(), revealedKnownAttrsIndices,
+		revealedKnownAttrs, rangeProofs); err != nil {
+		return err
+	}
+
+	if p.MaxAge > 0 {
+		issuedAt, ok, err := RevealedIssuedAt(rc, revealedKnownAttrsIndices,
+			revealedKnownAttrs)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("credential has no %s attribute", IssuedAtAttr)
+		}
+		if now.Sub(issuedAt) > p.MaxAge {
+			return fmt.Errorf("credential issued more than %v ago", p.MaxAge)
+		}
+	}
+
+	return nil
+}
+
+// ParsePredicate returns a predicate of type typ about attribute attr of credentials
+// with the structure of rc, with values vals given in string form (see
+// ParseAttrValue).
+func ParsePredicate(rc *RawCred, typ PredicateType, attr string,
+	vals []string) (*Predicate, error) {
+	a, err := rc.GetAttr(attr)
+	if err != nil {
+		return nil, err
+	}
+	p := &Predicate{Type: typ, Attr: attr}
+	if len(vals) > 0 {
+		p.raw = make([]interface{}, len(vals))
+		for i, v := range vals {
+			if p.raw[i], err = ParseAttrValue(a, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := ResolvePredicates(rc, []*Predicate{p}); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ParseAttrValue parses v into a value of attribute a (see CredAttr.UpdateValue),
+// according to the type of a. Values of binary attributes are given in hex, and
+// dates in the form 2006-01-02.
+func ParseAttrValue(a CredAttr, v string) (interface{}, error) {
+	var val interface{}
+	var err error
+	switch a.(type) {
+	case *Int64Attr:
+		val, err = strconv.ParseInt(v, 10, 64)
+	case *BoolAttr:
+		val, err = strconv.ParseBool(v)
+	case *BlobAttr, *BytesAttr:
+		val, err = hex.DecodeString(v)
+	default:
+		val = v
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid value of attribute %s: %v", a.GetName(), err)
+	}
+	return val, nil
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy(t *testing.T) {
+	issuedAt := time.Unix(1600000000, 0)
+	rc := NewRawCred(NewAttrCount(3, 0, 0))
+	require.NoError(t, rc.AddStrAttr("Name", "Jack", true))
+	require.NoError(t, rc.AddIssuedAtAttr(issuedAt))
+	require.NoError(t, rc.AddInt64Attr("Age", 25, true))
+
+	adult, err := ParsePredicate(rc, PredicateGreaterThan, "Age", []string{"17"})
+	require.NoError(t, err)
+	p := &Policy{
+		Name:       "adult",
+		Issuers:    []string{"key1"},
+		Revealed:   []string{"Name"},
+		Predicates: []*Predicate{adult},
+		MaxAge:     time.Hour,
+	}
+	assert.Len(t, p.AllPredicates(), 3)
+
+	// proofs need to show exactly the requested predicates
+	requested := []*Predicate{Revealed(IssuedAtAttr), GreaterThan("Age", 17), Revealed("Name")}
+	require.NoError(t, ResolvePredicates(rc, requested))
+	assert.True(t, p.Requests(requested))
+	assert.False(t, p.Requests(requested[:2]), "predicate missing")
+	other := []*Predicate{GreaterThan("Age", 10), Revealed(IssuedAtAttr), Revealed("Name")}
+	require.NoError(t, ResolvePredicates(rc, other))
+	assert.False(t, p.Requests(other), "predicate not requested")
+	assert.False(t, p.Requests(append(requested, Hidden("Age"))), "predicate not requested")
+
+	known := rc.GetKnownVals()
+	indices := []int{0, 1, 2}
+	now := issuedAt.Add(30 * time.Minute)
+	assert.NoError(t, p.Check(rc, "key1", indices, known, nil, now))
+
+	assert.Error(t, p.Check(rc, "key2", indices, known, nil, now), "issuer not accepted")
+	assert.Error(t, p.Check(rc, "key1", indices, known, nil, now.Add(time.Hour)),
+		"credential too old")
+	assert.Error(t, p.Check(rc, "key1", indices[1:], known[1:], nil, now),
+		"name not revealed")
+	assert.Error(t, p.Check(rc, "key1", indices[:2], known[:2], nil, now),
+		"age not revealed")
+
+	// range proofs need to be of the required backend
+	p.RangeProofs = RangeProofsBulletproofs
+	rangeProofs := []*AttrRangeProof{{Index: 2, A: big.NewInt(18), B: big.NewInt(150)}}
+	assert.Error(t, p.Check(rc, "key1", indices, known, rangeProofs, now))
+	rangeProofs[0].Bulletproof = &Bulletproof{}
+	assert.NoError(t, p.Check(rc, "key1", indices, known, rangeProofs, now))
+	b, err := ParseRangeProofBackend("bulletproofs")
+	assert.NoError(t, err)
+	assert.Equal(t, RangeProofsBulletproofs, b)
+	_, err = ParseRangeProofBackend("unknown")
+	assert.Error(t, err)
+
+	for _, vals := range [][]string{{"many"}, {}} {
+		_, err := ParsePredicate(rc, PredicateGreaterThan, "Age", vals)
+		assert.Error(t, err, "%v", vals)
+	}
+	_, err = ParsePredicate(rc, PredicateRevealed, "Unknown", nil)
+	assert.Error(t, err)
+}
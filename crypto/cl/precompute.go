@@ -0,0 +1,108 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/qr"
+)
+
+// preparedProof is a proof of possession of a credential up to its challenge: the
+// randomized credential and the random data of the proof, which do not depend on the
+// nonce of the verifier.
+type preparedProof struct {
+	rCred           *Cred
+	prover          *qr.RepresentationProver
+	proofRandomData *big.Int
+}
+
+// Precompute prepares n proofs of possession of cred that reveal the given attributes,
+// so that BuildProof with the same arguments only computes the challenge and the
+// responses for the nonce of the verifier. All the modular exponentiations, of the
+// randomization of the credential and of the random data of the proof, are done here,
+// for example while the user is idle, which lowers the latency of interactive proofs.
+//
+// Each prepared proof is used only once, since reusing its randomness would reveal the
+// attributes, and all of them are discarded when attributes are updated (see Update).
+// Precompute can be called concurrently with BuildProof.
+func (m *CredManager) Precompute(cred *Cred, revealedKnownAttrsIndices,
+	revealedCommitmentsOfAttrsIndices []int, n int) error {
+	key := precomputedKey(cred, revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices)
+	for i := 0; i < n; i++ {
+		p, err := m.prepareProof(cred, revealedKnownAttrsIndices,
+			revealedCommitmentsOfAttrsIndices)
+		if err != nil {
+			return err
+		}
+
+		m.precomputedLock.Lock()
+		if m.precomputed == nil {
+			m.precomputed = make(map[string][]*preparedProof)
+		}
+		m.precomputed[key] = append(m.precomputed[key], p)
+		m.precomputedLock.Unlock()
+	}
+
+	return nil
+}
+
+// Precomputed returns the number of proofs of cred revealing the given attributes
+// that were prepared with Precompute and are not used yet.
+func (m *CredManager) Precomputed(cred *Cred, revealedKnownAttrsIndices,
+	revealedCommitmentsOfAttrsIndices []int) int {
+	key := precomputedKey(cred, revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices)
+	m.precomputedLock.Lock()
+	defer m.precomputedLock.Unlock()
+
+	return len(m.precomputed[key])
+}
+
+// takePrecomputed removes a proof of cred revealing the given attributes from those
+// prepared with Precompute and returns it, or nil if there is none.
+func (m *CredManager) takePrecomputed(cred *Cred, revealedKnownAttrsIndices,
+	revealedCommitmentsOfAttrsIndices []int) *preparedProof {
+	key := precomputedKey(cred, revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices)
+	m.precomputedLock.Lock()
+	defer m.precomputedLock.Unlock()
+
+	proofs := m.precomputed[key]
+	if len(proofs) == 0 {
+		return nil
+	}
+	p := proofs[len(proofs)-1]
+	proofs[len(proofs)-1] = nil
+	m.precomputed[key] = proofs[:len(proofs)-1]
+
+	return p
+}
+
+// discardPrecomputed discards all the proofs prepared with Precompute.
+func (m *CredManager) discardPrecomputed() {
+	m.precomputedLock.Lock()
+	m.precomputed = nil
+	m.precomputedLock.Unlock()
+}
+
+// precomputedKey identifies proofs of cred that reveal the given attributes.
+func precomputedKey(cred *Cred, revealedKnownAttrsIndices,
+	revealedCommitmentsOfAttrsIndices []int) string {
+	return fmt.Sprintf("%x/%v/%v", cred.A.Bytes(), revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices)
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrecompute(t *testing.T) {
+	params := GetDefaultParamSizes()
+	attrCount := NewAttrCount(5, 1, 0)
+	org := loadTestOrg(t, params)
+
+	rc := NewRawCred(attrCount)
+	_ = rc.AddStrAttr("Name", "Jack", true)
+	_ = rc.AddStrAttr("Gender", "M", true)
+	_ = rc.AddStrAttr("Graduated", "true", true)
+	_ = rc.AddInt64Attr("DateMin", 22342345, true)
+	_ = rc.AddInt64Attr("DateMax", 32342345, true)
+	_ = rc.AddInt64Attr("Age", 25, false)
+	cm, err := NewCredManager(params, org.Keys.Pub, org.Keys.Pub.GenerateUserMasterSecret(),
+		rc)
+	require.NoError(t, err)
+	credReq, err := cm.GetCredRequest(org.GetCredIssueNonce())
+	require.NoError(t, err)
+	res, err := org.IssueCred(credReq)
+	require.NoError(t, err)
+
+	known, committed := []int{0}, []int{0}
+	require.NoError(t, cm.Precompute(res.Cred, known, committed, 2))
+	assert.Equal(t, 2, cm.Precomputed(res.Cred, known, committed))
+	assert.Equal(t, 0, cm.Precomputed(res.Cred, []int{1}, committed))
+
+	// prepared proofs are completed for nonces of verifiers, and used only once
+	revealedKnownAttrs, revealedCommitmentsOfAttrs := cm.FilterAttributes(known, committed)
+	var randCreds []*Cred
+	for i := 0; i < 3; i++ {
+		nonce := org.GetProveCredNonce()
+		randCred, proof, err := cm.BuildProof(res.Cred, known, committed, nonce)
+		require.NoError(t, err)
+		verified, err := org.ProveCred(randCred.A, proof, known, committed,
+			revealedKnownAttrs, revealedCommitmentsOfAttrs)
+		assert.NoError(t, err)
+		assert.True(t, verified, "proof %d not valid", i)
+		randCreds = append(randCreds, randCred)
+	}
+	assert.Equal(t, 0, cm.Precomputed(res.Cred, known, committed))
+	assert.NotEqual(t, randCreds[0].A, randCreds[1].A)
+
+	// prepared proofs do not hold after attributes are updated
+	require.NoError(t, cm.Precompute(res.Cred, known, committed, 1))
+	require.NoError(t, cm.Update(rc))
+	assert.Equal(t, 0, cm.Precomputed(res.Cred, known, committed))
+}
@@ -0,0 +1,241 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+// PredicateType is the type of a predicate about an attribute of a credential.
+type PredicateType string
+
+// Supported predicates. For known attributes, all predicates except Hidden are shown
+// by revealing the attribute. For committed int64 and date attributes, Equal and
+// GreaterThan are shown with range proofs, without revealing the attribute, as is
+// Equal for committed bool and enum attributes.
+const (
+	PredicateRevealed    PredicateType = "revealed"
+	PredicateHidden      PredicateType = "hidden"
+	PredicateEqual       PredicateType = "equal"
+	PredicateGreaterThan PredicateType = "greater_than"
+	PredicateInSet       PredicateType = "in_set"
+)
+
+// Predicate is a statement about attribute Attr of a credential, which a prover
+// shows along with the proof of the credential. Values hold internal values of
+// attributes (see CredAttr.InternalValue) the predicate refers to.
+type Predicate struct {
+	Type   PredicateType
+	Attr   string
+	Values []*big.Int
+	// values as given to the constructor, converted to Values by ResolvePredicates
+	raw []interface{}
+}
+
+// Revealed states that the value of attribute attr is revealed.
+func Revealed(attr string) *Predicate {
+	return &Predicate{Type: PredicateRevealed, Attr: attr}
+}
+
+// Hidden states that attribute attr is not revealed.
+func Hidden(attr string) *Predicate {
+	return &Predicate{Type: PredicateHidden, Attr: attr}
+}
+
+// Equal states that attribute attr equals val.
+func Equal(attr string, val interface{}) *Predicate {
+	return &Predicate{Type: PredicateEqual, Attr: attr, raw: []interface{}{val}}
+}
+
+// GreaterThan states that int64 attribute attr is greater than n.
+func GreaterThan(attr string, n int64) *Predicate {
+	return &Predicate{Type: PredicateGreaterThan, Attr: attr, raw: []interface{}{n}}
+}
+
+// After states that date attribute attr is later than d.
+func After(attr string, d time.Time) *Predicate {
+	return &Predicate{Type: PredicateGreaterThan, Attr: attr, raw: []interface{}{d}}
+}
+
+// InSet states that attribute attr equals one of vals.
+func InSet(attr string, vals ...interface{}) *Predicate {
+	return &Predicate{Type: PredicateInSet, Attr: attr, raw: vals}
+}
+
+func (p *Predicate) String() string {
+	return fmt.Sprintf("%s %s %v", p.Attr, p.Type, p.Values)
+}
+
+// Equals returns true if p and q, with resolved values (see ResolvePredicates), state
+// the same about the same attribute.
+func (p *Predicate) Equals(q *Predicate) bool {
+	if p.Type != q.Type || p.Attr != q.Attr || len(p.Values) != len(q.Values) {
+		return false
+	}
+	for i, v := range p.Values {
+		if v.Cmp(q.Values[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolvePredicates converts values of preds to internal values of the corresponding
+// attributes of rc, and checks that the predicates can be shown for these attributes.
+func ResolvePredicates(rc *RawCred, preds []*Predicate) error {
+	for _, p := range preds {
+		a, err := rc.GetAttr(p.Attr)
+		if err != nil {
+			return err
+		}
+		if p.raw != nil {
+			p.Values = make([]*big.Int, len(p.raw))
+			for i, v := range p.raw {
+				if p.Values[i], err = DefaultAttrEncoder.Encode(a, v); err != nil {
+					return err
+				}
+			}
+			p.raw = nil
+		}
+		if err := checkPredicate(p, a); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkPredicate checks that p is well formed and can be shown for attribute a.
+func checkPredicate(p *Predicate, a CredAttr) error {
+	// attributes with ordered internal values, to which range proofs apply
+	var isInt, isOrdinal bool
+	switch a.(type) {
+	case *Int64Attr, *DateAttr:
+		isInt, isOrdinal = true, true
+	case *BoolAttr, *EnumAttr:
+		isOrdinal = true
+	}
+	switch p.Type {
+	case PredicateRevealed:
+		if !a.IsKnown() {
+			return fmt.Errorf("committed attribute %s cannot be revealed", p.Attr)
+		}
+	case PredicateHidden:
+	case PredicateEqual:
+		if len(p.Values) != 1 {
+			return fmt.Errorf("predicate %s needs a single value", p.Type)
+		}
+		if !a.IsKnown() && !isOrdinal {
+			return fmt.Errorf("equality of committed attribute %s cannot be proved", p.Attr)
+		}
+	case PredicateGreaterThan:
+		if len(p.Values) != 1 || !p.Values[0].IsInt64() || p.Values[0].Int64() == math.MaxInt64 {
+			return fmt.Errorf("predicate %s needs a single int64 value", p.Type)
+		}
+		if !isInt {
+			return fmt.Errorf("attribute %s is not an int64 or date attribute", p.Attr)
+		}
+	case PredicateInSet:
+		if len(p.Values) == 0 {
+			return fmt.Errorf("predicate %s needs values", p.Type)
+		}
+		if !a.IsKnown() {
+			return fmt.Errorf("membership of committed attribute %s cannot be proved", p.Attr)
+		}
+	default:
+		return fmt.Errorf("unsupported predicate %s", p.Type)
+	}
+
+	return nil
+}
+
+// PredicateRange returns the range [a, b] that committed int64 attribute of p needs
+// to be proved to lie in, or nil for predicates that are not shown by range proofs.
+func PredicateRange(p *Predicate) (*big.Int, *big.Int) {
+	switch p.Type {
+	case PredicateEqual:
+		return p.Values[0], p.Values[0]
+	case PredicateGreaterThan:
+		return new(big.Int).Add(p.Values[0], big.NewInt(1)), big.NewInt(math.MaxInt64)
+	}
+
+	return nil, nil
+}
+
+// CheckPredicates checks that preds about attributes of credentials with the structure
+// of rc are shown by revealed known attributes and (verified) range proofs of a
+// proof of a credential. Range proofs need to be of exactly the ranges of predicates
+// (see PredicateRange).
+func CheckPredicates(rc *RawCred, preds []*Predicate, revealedKnownAttrsIndices []int,
+	revealedKnownAttrs []*big.Int, rangeProofs []*AttrRangeProof) error {
+	for _, p := range preds {
+		a, err := rc.GetAttr(p.Attr)
+		if err != nil {
+			return err
+		}
+		if err := checkPredicate(p, a); err != nil {
+			return err
+		}
+		ind, err := rc.GetAttrInternalIndex(p.Attr)
+		if err != nil {
+			return err
+		}
+
+		var revealed *big.Int
+		if a.IsKnown() {
+			for i, j := range revealedKnownAttrsIndices {
+				if j == ind && i < len(revealedKnownAttrs) {
+					revealed = revealedKnownAttrs[i]
+				}
+			}
+		}
+
+		ok := false
+		switch {
+		case p.Type == PredicateHidden:
+			ok = revealed == nil
+		case !a.IsKnown():
+			// some proof needs to be of the range of the predicate
+			min, max := PredicateRange(p)
+			for _, r := range rangeProofs {
+				if r.Index == ind && r.A.Cmp(min) == 0 && r.B.Cmp(max) == 0 {
+					ok = true
+				}
+			}
+		case revealed == nil:
+		case p.Type == PredicateRevealed:
+			ok = true
+		case p.Type == PredicateGreaterThan:
+			ok = revealed.Cmp(p.Values[0]) > 0
+		default: // equal and in_set
+			for _, v := range p.Values {
+				if revealed.Cmp(v) == 0 {
+					ok = true
+				}
+			}
+		}
+		if !ok {
+			return fmt.Errorf("predicate %s is not shown", p)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvePredicates(t *testing.T) {
+	rc := NewRawCred(NewAttrCount(2, 1, 0))
+	_ = rc.AddStrAttr("Name", "Jack", true)
+	_ = rc.AddInt64Attr("DateMin", 22342345, true)
+	_ = rc.AddInt64Attr("Age", 25, false)
+
+	preds := []*Predicate{
+		Revealed("Name"),
+		InSet("Name", "Jack", "Jim"),
+		GreaterThan("DateMin", 10),
+		Equal("Age", 25),
+		Hidden("Age"),
+	}
+	require.NoError(t, ResolvePredicates(rc, preds))
+	name, _ := rc.GetAttr("Name")
+	assert.Equal(t, name.InternalValue(), preds[1].Values[0])
+	assert.Equal(t, big.NewInt(10), preds[2].Values[0])
+
+	a, b := PredicateRange(preds[2])
+	assert.Equal(t, big.NewInt(11), a)
+	assert.True(t, b.IsInt64())
+	a, b = PredicateRange(preds[3])
+	assert.Equal(t, big.NewInt(25), a)
+	assert.Equal(t, big.NewInt(25), b)
+
+	// committed dates and enums are compared with range proofs as well
+	rc = NewRawCred(NewAttrCount(1, 2, 0))
+	_ = rc.AddDateAttr("Expiry", time.Date(2030, time.June, 1, 0, 0, 0, 0, time.UTC), false)
+	_ = rc.AddEnumAttr("Status", []string{"single", "married"}, "married", false)
+	_ = rc.AddBoolAttr("Adult", true, true)
+	dated := []*Predicate{
+		After("Expiry", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		Equal("Status", "married"),
+		Equal("Adult", true),
+	}
+	require.NoError(t, ResolvePredicates(rc, dated))
+	expiry, _ := rc.GetAttr("Expiry")
+	a, _ = PredicateRange(dated[0])
+	assert.True(t, expiry.InternalValue().Cmp(a) > 0)
+	assert.Equal(t, big.NewInt(1), dated[1].Values[0])
+	assert.Error(t, ResolvePredicates(rc, []*Predicate{After("Adult", time.Now())}))
+	assert.Error(t, ResolvePredicates(rc, []*Predicate{GreaterThan("Status", 0)}))
+
+	for _, p := range []*Predicate{
+		Revealed("Age"),              // committed attributes cannot be revealed
+		InSet("Age", 25, 26),         // nor shown to be in a set
+		GreaterThan("Name", 3),       // not an int64 attribute
+		Equal("Name", 3),             // value of a wrong type
+		InSet("DateMin"),             // no values
+		Equal("Unknown", "Jack"),     // no such attribute
+		{Type: "like", Attr: "Name"}, // unsupported type
+	} {
+		assert.Error(t, ResolvePredicates(rc, []*Predicate{p}), p.String())
+	}
+}
+
+func TestCheckPredicates(t *testing.T) {
+	params, err := LoadParams()
+	require.NoError(t, err)
+	org := loadTestOrg(t, params)
+	cm, _ := issueTestCred(t, params, org, "Jack", "M") // Age is 25
+
+	preds := []*Predicate{
+		Equal("Gender", "M"),
+		InSet("Name", "Jack", "Jim"),
+		GreaterThan("Age", 17),
+		Hidden("DateMin"),
+	}
+	require.NoError(t, ResolvePredicates(cm.RawCred, preds))
+
+	nonce := org.GetProveCredNonce()
+	min, max := PredicateRange(preds[2])
+	rangeProof, err := cm.BuildAttrRangeProof(0, min, max, nonce)
+	require.NoError(t, err)
+	rangeProofs := []*AttrRangeProof{rangeProof}
+	known, _ := cm.FilterAttributes([]int{0, 1}, []int{0})
+
+	assert.NoError(t, CheckPredicates(cm.RawCred, preds, []int{0, 1}, known, rangeProofs))
+
+	// known attributes need to be revealed
+	assert.Error(t, CheckPredicates(cm.RawCred, preds, []int{1}, known[1:], rangeProofs))
+	// hidden attributes must not be revealed
+	known, _ = cm.FilterAttributes([]int{0, 1, 3}, []int{0})
+	assert.Error(t, CheckPredicates(cm.RawCred, preds, []int{0, 1, 3}, known, rangeProofs))
+	// committed attributes need a range proof of the range of the predicate
+	assert.Error(t, CheckPredicates(cm.RawCred, preds[2:3], nil, nil, nil))
+	tooWide, err := cm.BuildAttrRangeProof(0, big.NewInt(10), max, nonce)
+	require.NoError(t, err)
+	assert.Error(t, CheckPredicates(cm.RawCred, preds[2:3], nil, nil,
+		[]*AttrRangeProof{tooWide}))
+	narrower, err := cm.BuildAttrRangeProof(0, min, big.NewInt(150), nonce)
+	require.NoError(t, err)
+	assert.Error(t, CheckPredicates(cm.RawCred, preds[2:3], nil, nil,
+		[]*AttrRangeProof{narrower}))
+
+	same := []*Predicate{GreaterThan("Age", 17), GreaterThan("Age", 18)}
+	require.NoError(t, ResolvePredicates(cm.RawCred, same))
+	assert.True(t, preds[2].Equals(same[0]))
+	assert.False(t, preds[2].Equals(same[1]))
+	assert.False(t, preds[2].Equals(preds[3]))
+
+	// revealed values need to satisfy predicates
+	other := []*Predicate{InSet("Name", "Jim", "John")}
+	require.NoError(t, ResolvePredicates(cm.RawCred, other))
+	known, _ = cm.FilterAttributes([]int{0}, []int{})
+	assert.Error(t, CheckPredicates(cm.RawCred, other, []int{0}, known, nil))
+}
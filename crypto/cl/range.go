@@ -0,0 +1,307 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/bulletproofs"
+	"github.com/xlab-si/emmy/crypto/commitments"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/df"
+)
+
+// RangeProofBackend is a system of proofs that committed attributes lie in a range.
+type RangeProofBackend string
+
+// Supported backends of range proofs. RangeProofsDF are proofs about Damgard-Fujisaki
+// integer commitments (see package df), available with commitments of attributes of
+// scheme commitments.SchemeQR. RangeProofsBulletproofs (see package bulletproofs) are
+// much shorter and available with any scheme: attributes committed in QR_N are
+// committed to anew over an elliptic curve for them.
+const (
+	RangeProofsDF           RangeProofBackend = "df"
+	RangeProofsBulletproofs RangeProofBackend = "bulletproofs"
+)
+
+// ParseRangeProofBackend returns the backend of range proofs with name s. Empty s
+// stands for no particular backend.
+func ParseRangeProofBackend(s string) (RangeProofBackend, error) {
+	switch b := RangeProofBackend(s); b {
+	case "", RangeProofsDF, RangeProofsBulletproofs:
+		return b, nil
+	}
+	return "", fmt.Errorf("unsupported backend of range proofs %s", s)
+}
+
+// defaultRangeProofBackend returns the backend of range proofs built when none is
+// requested: RangeProofsDF with commitments of attributes in QR_N, where it is
+// available, and RangeProofsBulletproofs otherwise.
+func defaultRangeProofBackend(p *Params) RangeProofBackend {
+	if s := p.CommitmentScheme; s == "" || s == commitments.SchemeQR {
+		return RangeProofsDF
+	}
+	return RangeProofsBulletproofs
+}
+
+// bulletproofsBitLen is the bit length of ranges of Bulletproofs, which covers
+// ranges between any int64 values.
+const bulletproofsBitLen = 64
+
+// bulletproofsScheme is the scheme of commitments that attributes committed in QR_N
+// are committed to anew for Bulletproofs.
+const bulletproofsScheme = commitments.SchemeECP256
+
+// BuildRangeProof returns a non-interactive proof that the committed attribute with
+// index i (among committed attributes) lies in [a, b]. The proof refers to the
+// commitment CommitmentsOfAttrs[i], which needs to be revealed to the verifier
+// (for example in BuildProof). The nonce is obtained from the verifier. These proofs
+// (of backend RangeProofsDF) require commitments of attributes of scheme
+// commitments.SchemeQR, see BuildBulletproof for other schemes.
+func (m *CredManager) BuildRangeProof(i int, a, b, nonce *big.Int) (*df.RangeProofNI, error) {
+	if i < 0 || i >= len(m.attrsCommitters) || m.attrsCommitters[i] == nil {
+		return nil, fmt.Errorf("no commitment of committed attribute %d", i)
+	}
+	committer, ok := commitments.DFCommitter(m.attrsCommitters[i])
+	if !ok {
+		return nil, fmt.Errorf("range proofs are not supported with %s commitments",
+			m.Params.CommitmentScheme)
+	}
+	x, _ := committer.GetDecommitMsg()
+	if x.Cmp(a) < 0 || x.Cmp(b) > 0 {
+		return nil, fmt.Errorf("committed attribute %d is not in the range", i)
+	}
+
+	return df.ProveRange(committer, x, a, b, nonce, m.Params.ChallengeSpace)
+}
+
+// VerifyRangeProof verifies a proof built with BuildRangeProof that the attribute
+// committed in commitment lies in [a, b].
+func (o *Org) VerifyRangeProof(commitment *big.Int, proof *df.RangeProofNI, a, b,
+	nonce *big.Int) (bool, error) {
+	if s := o.Params.CommitmentScheme; s != "" && s != commitments.SchemeQR {
+		return false, fmt.Errorf("range proofs are not supported with %s commitments", s)
+	}
+	receiver, err := o.newAttrReceiver()
+	if err != nil {
+		return false, err
+	}
+	receiver.SetCommitment(commitment)
+
+	return df.VerifyRange(receiver, proof, a, b, nonce, o.Params.ChallengeSpace)
+}
+
+// Bulletproof is a range proof of a committed attribute with Bulletproofs. With
+// commitments of attributes over elliptic curves, it refers to the commitment of
+// the attribute. With commitments in QR_N, it refers to Commitment, which commits to
+// the attribute anew over an elliptic curve, and Equality proves that both
+// commitments hide the same value.
+type Bulletproof struct {
+	*bulletproofs.Proof
+	Commitment *big.Int
+	Equality   *bulletproofs.DFEqualityProof
+}
+
+// BuildBulletproof returns a non-interactive proof with Bulletproofs that the
+// committed attribute with index i lies in [a, b], where a and b are int64 values.
+// Like with BuildRangeProof, the commitment CommitmentsOfAttrs[i] needs to be
+// revealed to the verifier.
+func (m *CredManager) BuildBulletproof(i int, a, b, nonce *big.Int) (*Bulletproof, error) {
+	if i < 0 || i >= len(m.attrsCommitters) || m.attrsCommitters[i] == nil {
+		return nil, fmt.Errorf("no commitment of committed attribute %d", i)
+	}
+	if !a.IsInt64() || !b.IsInt64() {
+		return nil, fmt.Errorf("bounds of the range need to be int64 values")
+	}
+	params, err := bulletproofsParams(m.attrsScheme)
+	if err != nil {
+		return nil, err
+	}
+	x, r := m.attrsCommitters[i].GetDecommitMsg()
+	if x.Cmp(a) < 0 || x.Cmp(b) > 0 {
+		return nil, fmt.Errorf("committed attribute %d is not in the range", i)
+	}
+
+	proof := new(Bulletproof)
+	if committer, ok := commitments.DFCommitter(m.attrsCommitters[i]); ok {
+		r = common.GetRandomInt(params.Group.Q)
+		proof.Commitment = params.Group.Encode(params.Commit(x, r))
+		if proof.Equality, err = bulletproofs.ProveDFEquality(committer, params, r,
+			bulletproofsBitLen, nonce, m.Params.ChallengeSpace); err != nil {
+			return nil, err
+		}
+	}
+	if proof.Proof, err = bulletproofs.ProveRange(params, x, r, a, b, nonce); err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+// VerifyBulletproof verifies a proof built with BuildBulletproof that the attribute
+// committed in commitment lies in [a, b].
+func (o *Org) VerifyBulletproof(commitment *big.Int, proof *Bulletproof, a, b,
+	nonce *big.Int) (bool, error) {
+	if proof == nil || proof.Proof == nil {
+		return false, fmt.Errorf("missing range proof")
+	}
+	if !a.IsInt64() || !b.IsInt64() {
+		return false, fmt.Errorf("bounds of the range need to be int64 values")
+	}
+	params, err := bulletproofsParams(o.attrsScheme)
+	if err != nil {
+		return false, err
+	}
+
+	// with commitments in QR_N, the commitment over the curve needs to hide the
+	// committed attribute
+	_, isEC := commitments.ECParams(o.attrsScheme)
+	ecCommitment := commitment
+	if !isEC {
+		if proof.Commitment == nil || proof.Equality == nil {
+			return false, fmt.Errorf("missing commitment over elliptic curve")
+		}
+		ecCommitment = proof.Commitment
+	}
+	c, err := params.Group.Decode(ecCommitment)
+	if err != nil {
+		return false, err
+	}
+	if !isEC {
+		receiver, err := o.newAttrReceiver()
+		if err != nil {
+			return false, err
+		}
+		receiver.SetCommitment(commitment)
+		ok, err := bulletproofs.VerifyDFEquality(receiver, params, c, proof.Equality,
+			bulletproofsBitLen, nonce, o.Params.ChallengeSpace)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return bulletproofs.VerifyRange(params, c, proof.Proof, a, b, nonce)
+}
+
+// bulletproofsParams returns parameters of Bulletproofs about attributes committed
+// with scheme, over the curve of scheme or the curve of bulletproofsScheme.
+func bulletproofsParams(scheme commitments.Scheme) (*bulletproofs.Params, error) {
+	ecParams, ok := commitments.ECParams(scheme)
+	if !ok {
+		s, err := commitments.New(bulletproofsScheme, nil)
+		if err != nil {
+			return nil, err
+		}
+		ecParams, _ = commitments.ECParams(s)
+	}
+
+	return bulletproofs.NewParams(ecParams.Group, ecParams.H, bulletproofsBitLen, 2)
+}
+
+// AttrRangeProof is a proof that the committed attribute with index Index (among
+// committed attributes) lies in [A, B], built along with a proof of a credential.
+// It holds either a proof of backend RangeProofsDF or a Bulletproof.
+type AttrRangeProof struct {
+	Index int
+	A     *big.Int
+	B     *big.Int
+	*df.RangeProofNI
+	Bulletproof *Bulletproof
+}
+
+// Backend returns the backend of range proofs that p was built with.
+func (p *AttrRangeProof) Backend() RangeProofBackend {
+	if p.Bulletproof != nil {
+		return RangeProofsBulletproofs
+	}
+	return RangeProofsDF
+}
+
+// BuildAttrRangeProof returns a proof that the committed attribute with index i lies
+// in [a, b], with the default backend for commitments of attributes (RangeProofsDF
+// for commitments in QR_N, see BuildRangeProof).
+func (m *CredManager) BuildAttrRangeProof(i int, a, b, nonce *big.Int) (*AttrRangeProof, error) {
+	return m.BuildAttrRangeProofWithBackend("", i, a, b, nonce)
+}
+
+// BuildAttrRangeProofWithBackend is like BuildAttrRangeProof, but the proof is built
+// with the given backend, for example the one requested by a policy of the verifier
+// (see Policy.RangeProofs). The default backend is used when backend is empty.
+func (m *CredManager) BuildAttrRangeProofWithBackend(backend RangeProofBackend, i int,
+	a, b, nonce *big.Int) (*AttrRangeProof, error) {
+	p := &AttrRangeProof{
+		Index: i,
+		A:     a,
+		B:     b,
+	}
+	if backend == "" {
+		backend = defaultRangeProofBackend(m.Params)
+	}
+
+	var err error
+	switch backend {
+	case RangeProofsDF:
+		p.RangeProofNI, err = m.BuildRangeProof(i, a, b, nonce)
+	case RangeProofsBulletproofs:
+		p.Bulletproof, err = m.BuildBulletproof(i, a, b, nonce)
+	default:
+		err = fmt.Errorf("unsupported backend of range proofs %s", backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// VerifyAttrRangeProofs verifies proofs built with BuildAttrRangeProof. Commitments
+// of the attributes need to be among revealedCommitmentsOfAttrs, whose indices are
+// revealedCommitmentsOfAttrsIndices (as passed to ProveCred).
+func (o *Org) VerifyAttrRangeProofs(proofs []*AttrRangeProof,
+	revealedCommitmentsOfAttrsIndices []int, revealedCommitmentsOfAttrs []*big.Int,
+	nonce *big.Int) (bool, error) {
+	for _, p := range proofs {
+		var commitment *big.Int
+		for i, ind := range revealedCommitmentsOfAttrsIndices {
+			if ind == p.Index && i < len(revealedCommitmentsOfAttrs) {
+				commitment = revealedCommitmentsOfAttrs[i]
+			}
+		}
+		if commitment == nil {
+			return false, fmt.Errorf("commitment of attribute %d is not revealed", p.Index)
+		}
+		if p.A.Cmp(p.B) > 0 {
+			return false, fmt.Errorf("empty range of attribute %d", p.Index)
+		}
+
+		var ok bool
+		var err error
+		switch p.Backend() {
+		case RangeProofsBulletproofs:
+			ok, err = o.VerifyBulletproof(commitment, p.Bulletproof, p.A, p.B, nonce)
+		default:
+			ok, err = o.VerifyRangeProof(commitment, p.RangeProofNI, p.A, p.B, nonce)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttrRangeProofs(t *testing.T) {
+	params, err := LoadParams()
+	require.NoError(t, err)
+	org := loadTestOrg(t, params)
+	cm, cred := issueTestCred(t, params, org, "Jack", "M") // Age is 25
+
+	nonce := org.GetProveCredNonce()
+	rCred, proof, err := cm.BuildProof(cred, []int{}, []int{0}, nonce)
+	require.NoError(t, err)
+	known, committed := cm.FilterAttributes([]int{}, []int{0})
+	ok, err := org.ProveCred(rCred.A, proof, []int{}, []int{0}, known, committed)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	rangeProof, err := cm.BuildAttrRangeProof(0, big.NewInt(18), big.NewInt(150), nonce)
+	require.NoError(t, err)
+	ok, err = org.VerifyAttrRangeProofs([]*AttrRangeProof{rangeProof}, []int{0}, committed,
+		nonce)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// the commitment of the attribute needs to be revealed
+	_, err = org.VerifyAttrRangeProofs([]*AttrRangeProof{rangeProof}, []int{}, []*big.Int{},
+		nonce)
+	assert.Error(t, err)
+
+	// the proof does not hold for another range or nonce
+	rangeProof.A = big.NewInt(30)
+	ok, _ = org.VerifyAttrRangeProofs([]*AttrRangeProof{rangeProof}, []int{0}, committed,
+		nonce)
+	assert.False(t, ok)
+	rangeProof.A = big.NewInt(18)
+	ok, _ = org.VerifyAttrRangeProofs([]*AttrRangeProof{rangeProof}, []int{0}, committed,
+		org.GetProveCredNonce())
+	assert.False(t, ok)
+
+	_, err = cm.BuildAttrRangeProof(0, big.NewInt(30), big.NewInt(150), nonce)
+	assert.Error(t, err)
+	_, err = cm.BuildAttrRangeProof(1, big.NewInt(18), big.NewInt(150), nonce)
+	assert.Error(t, err)
+}
+
+func TestAttrBulletproofs(t *testing.T) {
+	params, err := LoadParams()
+	require.NoError(t, err)
+	org := loadTestOrg(t, params)
+	cm, _ := issueTestCred(t, params, org, "Jack", "M") // Age is 25
+	_, committed := cm.FilterAttributes([]int{}, []int{0})
+
+	nonce := org.GetProveCredNonce()
+	bulletproof, err := cm.BuildAttrRangeProofWithBackend(RangeProofsBulletproofs, 0,
+		big.NewInt(18), big.NewInt(150), nonce)
+	require.NoError(t, err)
+	assert.Equal(t, RangeProofsBulletproofs, bulletproof.Backend())
+	rangeProof, err := cm.BuildAttrRangeProof(0, big.NewInt(20), big.NewInt(30), nonce)
+	require.NoError(t, err)
+	assert.Equal(t, RangeProofsDF, rangeProof.Backend())
+	ok, err := org.VerifyAttrRangeProofs([]*AttrRangeProof{bulletproof, rangeProof},
+		[]int{0}, committed, nonce)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// the proof does not hold for another range or nonce
+	bulletproof.A = big.NewInt(30)
+	ok, _ = org.VerifyAttrRangeProofs([]*AttrRangeProof{bulletproof}, []int{0}, committed,
+		nonce)
+	assert.False(t, ok)
+	bulletproof.A = big.NewInt(18)
+	ok, _ = org.VerifyAttrRangeProofs([]*AttrRangeProof{bulletproof}, []int{0}, committed,
+		org.GetProveCredNonce())
+	assert.False(t, ok)
+
+	// the commitment over the curve needs to hide the committed attribute
+	other, err := cm.BuildBulletproof(0, big.NewInt(18), big.NewInt(150), nonce)
+	require.NoError(t, err)
+	other.Commitment = bulletproof.Bulletproof.Commitment
+	ok, _ = org.VerifyBulletproof(committed[0], other, big.NewInt(18), big.NewInt(150), nonce)
+	assert.False(t, ok)
+	other.Equality = nil
+	_, err = org.VerifyBulletproof(committed[0], other, big.NewInt(18), big.NewInt(150), nonce)
+	assert.Error(t, err)
+
+	_, err = cm.BuildBulletproof(0, big.NewInt(30), big.NewInt(150), nonce)
+	assert.Error(t, err)
+	_, err = cm.BuildAttrRangeProofWithBackend("unknown", 0, big.NewInt(18),
+		big.NewInt(150), nonce)
+	assert.Error(t, err)
+}
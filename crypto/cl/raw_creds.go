@@ -20,6 +20,7 @@ package cl
 import (
 	"fmt"
 	"math/big"
+	"time"
 )
 
 // RawCred represents a credential to be used by application that
@@ -47,10 +48,8 @@ func NewRawCred(c *AttrCount) *RawCred {
 func (c *RawCred) missingAttrs() error {
 	for _, a := range c.attrs {
 		if !a.HasVal() {
-			fmt.Println(a.GetName(), " missing")
 			return fmt.Errorf(a.GetName())
 		}
-		fmt.Println(a.GetName(), " ok")
 	}
 	return nil
 }
@@ -102,6 +101,108 @@ func (c *RawCred) AddEmptyInt64Attr(name string, known bool) error {
 	return nil
 }
 
+// AddBlobAttr adds an attribute holding root, the root of a chunked commitment to a
+// large binary object (see BlobAttr).
+func (c *RawCred) AddBlobAttr(name string, root []byte, known bool) error {
+	if err := c.AddEmptyBlobAttr(name, known); err != nil {
+		return err
+	}
+
+	a, _ := c.GetAttr(name)
+	return a.UpdateValue(root)
+}
+
+func (c *RawCred) AddEmptyBlobAttr(name string, known bool) error {
+	if err := c.validateAttr(name, known); err != nil {
+		return err
+	}
+	c.insertAttr(len(c.attrs), NewEmptyBlobAttr(name, known))
+	return nil
+}
+
+// AddDateAttr adds an attribute holding date d (see DateAttr).
+func (c *RawCred) AddDateAttr(name string, d time.Time, known bool) error {
+	if err := c.AddEmptyDateAttr(name, known); err != nil {
+		return err
+	}
+
+	a, _ := c.GetAttr(name)
+	return a.UpdateValue(d)
+}
+
+func (c *RawCred) AddEmptyDateAttr(name string, known bool) error {
+	if err := c.validateAttr(name, known); err != nil {
+		return err
+	}
+	c.insertAttr(len(c.attrs), NewEmptyDateAttr(name, known))
+	return nil
+}
+
+func (c *RawCred) AddBoolAttr(name string, val bool, known bool) error {
+	if err := c.AddEmptyBoolAttr(name, known); err != nil {
+		return err
+	}
+
+	a, _ := c.GetAttr(name)
+	return a.UpdateValue(val)
+}
+
+func (c *RawCred) AddEmptyBoolAttr(name string, known bool) error {
+	if err := c.validateAttr(name, known); err != nil {
+		return err
+	}
+	c.insertAttr(len(c.attrs), NewEmptyBoolAttr(name, known))
+	return nil
+}
+
+// AddEnumAttr adds an attribute holding val, one of values (see EnumAttr).
+func (c *RawCred) AddEnumAttr(name string, values []string, val string, known bool) error {
+	if err := c.AddEmptyEnumAttr(name, values, known); err != nil {
+		return err
+	}
+
+	a, _ := c.GetAttr(name)
+	return a.UpdateValue(val)
+}
+
+func (c *RawCred) AddEmptyEnumAttr(name string, values []string, known bool) error {
+	if err := c.validateAttr(name, known); err != nil {
+		return err
+	}
+	c.insertAttr(len(c.attrs), NewEmptyEnumAttr(name, values, known))
+	return nil
+}
+
+// AddBytesAttr adds an attribute holding byte slice val, whose size is the size of
+// the attribute (see BytesAttr).
+func (c *RawCred) AddBytesAttr(name string, val []byte, known bool) error {
+	if err := c.AddEmptyBytesAttr(name, len(val), known); err != nil {
+		return err
+	}
+
+	a, _ := c.GetAttr(name)
+	return a.UpdateValue(val)
+}
+
+func (c *RawCred) AddEmptyBytesAttr(name string, size int, known bool) error {
+	if err := c.validateAttr(name, known); err != nil {
+		return err
+	}
+	c.insertAttr(len(c.attrs), NewEmptyBytesAttr(name, size, known))
+	return nil
+}
+
+// AddAttr adds attribute a, which can be of a type defined outside of this package
+// (for example to encode values the way other credential systems do).
+func (c *RawCred) AddAttr(a CredAttr) error {
+	if err := c.validateAttr(a.GetName(), a.IsKnown()); err != nil {
+		return err
+	}
+	c.insertAttr(len(c.attrs), a)
+
+	return nil
+}
+
 // GetKnownVals returns *big.Int values of known attributes.
 // The returned elements are ordered by attribute's index.
 func (c *RawCred) GetKnownVals() []*big.Int {
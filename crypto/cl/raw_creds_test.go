@@ -51,6 +51,17 @@ func TestRawCred_AddInt64Attr(t *testing.T) {
 	assert.Len(t, c.GetAttrs(), 1)
 }
 
+func TestRawCred_AddAttr(t *testing.T) {
+	c := NewRawCred(NewAttrCount(1, 1, 0))
+	a, _ := NewStrAttr("Name", "John", true)
+	assert.NoError(t, c.AddAttr(a))
+	assert.Error(t, c.AddAttr(a), "duplicate attribute should not be added")
+	idx, err := c.GetAttrInternalIndex("Name")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, idx)
+	assert.Equal(t, a.InternalValue(), c.GetKnownVals()[0])
+}
+
 /*
  func TestRawCred_AddStringAttribute(t *testing.T) {
 	 rc := NewRawCred()
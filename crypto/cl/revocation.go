@@ -0,0 +1,377 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/qr"
+)
+
+// Revocation of credentials is based on the dynamic accumulator of Camenisch and
+// Lysyanskaya in QR_N, where N is the modulus of the issuer's key. The revocation
+// handle of a credential is its prime e, which is never revealed in proofs. The
+// accumulator value V is the e-th power of the witness of each credential that
+// was not revoked. As the issuer knows the factorization of N, it computes
+// witnesses of new credentials as V^(1/e) and V only changes when credentials are
+// revoked: revoking e sets V to V^(1/e). Holders update their witnesses from the
+// primes revoked since the witness was computed (see Witness.Update), which the
+// holder of a revoked credential is unable to do.
+
+// Accumulator is the public state of revocation: the accumulator value V with the
+// modulus and bases G, H of the commitments of non-revocation proofs. Version is
+// the number of credentials revoked so far.
+type Accumulator struct {
+	N       *big.Int
+	G       *big.Int
+	H       *big.Int
+	V       *big.Int
+	Version int
+}
+
+// Witness proves that a credential is accumulated in the accumulator of the given
+// version, that is W^e = V.
+type Witness struct {
+	W       *big.Int
+	Version int
+}
+
+// RevocationAuthority maintains the accumulator of the organization holding keys,
+// along with the list of revoked primes.
+type RevocationAuthority struct {
+	Acc     *Accumulator
+	Revoked []*big.Int // revoked primes, in the order of revocation
+	group   *qr.RSASpecial
+}
+
+// NewRevocationAuthority returns a revocation authority with a new accumulator for
+// credentials issued with keys.
+func NewRevocationAuthority(keys *KeyPair) (*RevocationAuthority, error) {
+	if keys.Sec == nil {
+		return nil, fmt.Errorf("revocation requires the secret key")
+	}
+	group, err := qr.NewRSASpecialFromParams(keys.Sec.RsaPrimes)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating RSASpecial group: %s", err)
+	}
+
+	bases := make([]*big.Int, 3)
+	for i := range bases {
+		if bases[i], err = group.GetRandomGenerator(); err != nil {
+			return nil, err
+		}
+	}
+
+	return &RevocationAuthority{
+		Acc: &Accumulator{
+			N: group.N,
+			G: bases[0],
+			H: bases[1],
+			V: bases[2],
+		},
+		group: group,
+	}, nil
+}
+
+// LoadOrCreateRevocationAuthority loads the accumulator and revoked primes from path.
+// If the file does not exist yet, a new revocation authority is created and written
+// to path.
+func LoadOrCreateRevocationAuthority(keys *KeyPair, path string) (*RevocationAuthority,
+	error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		a, err := NewRevocationAuthority(keys)
+		if err != nil {
+			return nil, err
+		}
+		return a, a.Save(path)
+	}
+
+	a := new(RevocationAuthority)
+	if err := ReadGob(path, a); err != nil {
+		return nil, err
+	}
+	if a.Acc == nil || keys.Sec == nil || a.Acc.N.Cmp(keys.Pub.N) != 0 {
+		return nil, fmt.Errorf("accumulator in %s does not match the keys", path)
+	}
+	group, err := qr.NewRSASpecialFromParams(keys.Sec.RsaPrimes)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating RSASpecial group: %s", err)
+	}
+	a.group = group
+
+	return a, nil
+}
+
+// Save writes the accumulator and revoked primes to path.
+func (a *RevocationAuthority) Save(path string) error {
+	return WriteGob(path, a)
+}
+
+// root returns x^(1/e), computed with the secret order of the group.
+func (a *RevocationAuthority) root(x, e *big.Int) (*big.Int, error) {
+	order := new(big.Int).Mul(a.group.P1, a.group.Q1)
+	eInv := new(big.Int).ModInverse(e, order)
+	if eInv == nil {
+		return nil, fmt.Errorf("revocation handle not invertible")
+	}
+	return common.BlindedExpMod(new(big.Int), x, eInv, order, a.group.N), nil
+}
+
+// isRevoked returns whether e was revoked.
+func (a *RevocationAuthority) isRevoked(e *big.Int) bool {
+	for _, r := range a.Revoked {
+		if r.Cmp(e) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Witness returns the witness of the credential with prime e for the current
+// accumulator.
+func (a *RevocationAuthority) Witness(e *big.Int) (*Witness, error) {
+	if a.isRevoked(e) {
+		return nil, fmt.Errorf("credential was revoked")
+	}
+	W, err := a.root(a.Acc.V, e)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Witness{
+		W:       W,
+		Version: a.Acc.Version,
+	}, nil
+}
+
+// Revoke revokes the credential with prime e, which changes the accumulator.
+func (a *RevocationAuthority) Revoke(e *big.Int) error {
+	if a.isRevoked(e) {
+		return fmt.Errorf("credential was already revoked")
+	}
+	V, err := a.root(a.Acc.V, e)
+	if err != nil {
+		return err
+	}
+
+	a.Acc.V = V
+	a.Acc.Version++
+	a.Revoked = append(a.Revoked, e)
+
+	return nil
+}
+
+// RevokedSince returns primes revoked after the given version of the accumulator.
+func (a *RevocationAuthority) RevokedSince(version int) ([]*big.Int, error) {
+	if version < 0 || version > a.Acc.Version {
+		return nil, fmt.Errorf("no accumulator with version %d", version)
+	}
+	return a.Revoked[version:], nil
+}
+
+// Update updates the witness of the credential with prime e to accumulator acc,
+// given primes revoked since the version of w. It fails if e was revoked.
+func (w *Witness) Update(e *big.Int, acc *Accumulator, revoked []*big.Int) error {
+	if len(revoked) != acc.Version-w.Version {
+		return fmt.Errorf("expected %d revoked primes, got %d", acc.Version-w.Version,
+			len(revoked))
+	}
+
+	// with a*e + b*prod = 1, where prod is the product of revoked primes,
+	// W' = W^b * V^a, as V^prod is the value of the accumulator W was computed for
+	prod := big.NewInt(1)
+	for _, r := range revoked {
+		prod.Mul(prod, r)
+	}
+	a, b := new(big.Int), new(big.Int)
+	if new(big.Int).GCD(a, b, e, prod).Cmp(big.NewInt(1)) != 0 {
+		return fmt.Errorf("credential was revoked")
+	}
+	W := common.MultiExp(new(big.Int), []*big.Int{w.W, acc.V}, []*big.Int{b, a}, acc.N)
+	if W == nil || new(big.Int).Exp(W, e, acc.N).Cmp(acc.V) != 0 {
+		return fmt.Errorf("witness not valid")
+	}
+
+	w.W = W
+	w.Version = acc.Version
+	return nil
+}
+
+// NonRevocationProof proves the possession of a credential randomized into A, as
+// BuildProof does, and that the credential was not revoked from the accumulator.
+// The statement about the credential is part of the non-revocation proof, so that
+// a single response for e proves both. CU and CR commit to the witness:
+// CU = W * H^r2, CR = G^r2 * H^r3.
+type NonRevocationProof struct {
+	CU *big.Int
+	CR *big.Int
+	*qr.AggregateProof
+}
+
+// BuildNonRevocationProof builds a non-revocation proof for the randomized credential
+// rCred, returned by BuildProof along with a proof that reveals the attributes with
+// the given indices. Witness w needs to be updated to accumulator acc. The nonce is
+// obtained from the verifier.
+func (m *CredManager) BuildNonRevocationProof(rCred *Cred, revealedKnownAttrsIndices,
+	revealedCommitmentsOfAttrsIndices []int, w *Witness, acc *Accumulator,
+	nonce *big.Int) (*NonRevocationProof, error) {
+	if m.V1 == nil {
+		return nil, fmt.Errorf("v1 is not set (generated in GetCredRequest)")
+	}
+	if w.Version != acc.Version {
+		return nil, fmt.Errorf("witness is not updated to the accumulator")
+	}
+
+	known, committed := m.FilterAttributes(revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices)
+	c := &RevealedCred{
+		PubKey:                            m.PubKey,
+		RevealedKnownAttrsIndices:         revealedKnownAttrsIndices,
+		RevealedCommitmentsOfAttrsIndices: revealedCommitmentsOfAttrsIndices,
+		RevealedKnownAttrs:                known,
+		RevealedCommitmentsOfAttrs:        committed,
+	}
+	secrets, boundaries := credSecrets(m, rCred, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices)
+
+	b := new(big.Int).Lsh(big.NewInt(1), uint(m.Params.NLength+m.Params.SecParam))
+	r2, r3 := common.GetRandomInt(b), common.GetRandomInt(b)
+	group := qr.NewRSApecialPublic(acc.N)
+	CU := group.Mul(w.W, group.Exp(acc.H, r2))
+	CR := common.MultiExp(new(big.Int), []*big.Int{acc.G, acc.H}, []*big.Int{r2, r3}, acc.N)
+
+	bR := m.Params.NLength + 2*m.Params.SecParam + m.Params.HashBitLen
+	bAlpha := bR + m.Params.EBitLen
+	secrets = append(secrets, r2, r3, new(big.Int).Mul(rCred.E, r2),
+		new(big.Int).Mul(rCred.E, r3))
+	boundaries = append(boundaries, bR, bR, bAlpha, bAlpha)
+
+	statements, err := nonRevocationStatements(c, rCred.A, acc, CU, CR)
+	if err != nil {
+		return nil, err
+	}
+	prover, err := qr.NewAggregateProver(statements, secrets)
+	if err != nil {
+		return nil, err
+	}
+	proofRandomData, err := prover.GetProofRandomData(boundaries, true)
+	if err != nil {
+		return nil, fmt.Errorf("error when generating non-revocation proof random data: %s", err)
+	}
+	challenge := nonRevocationChallenge(c.PubKey, rCred.A, acc, CU, CR, proofRandomData, nonce)
+
+	return &NonRevocationProof{
+		CU:             CU,
+		CR:             CR,
+		AggregateProof: qr.NewAggregateProof(challenge, prover.GetProofData(challenge)),
+	}, nil
+}
+
+// VerifyNonRevocationProof verifies a non-revocation proof of the credential
+// randomized into A, which reveals c, against the current accumulator acc.
+func VerifyNonRevocationProof(params *Params, c *RevealedCred, A *big.Int,
+	acc *Accumulator, proof *NonRevocationProof, nonce *big.Int) (bool, error) {
+	if proof == nil || proof.AggregateProof == nil || proof.CU == nil || proof.CR == nil ||
+		A == nil {
+		return false, fmt.Errorf("incomplete proof")
+	}
+	if err := checkRevealed(c.RevealedKnownAttrsIndices, c.RevealedKnownAttrs,
+		len(c.PubKey.RsKnown)); err != nil {
+		return false, fmt.Errorf("known attributes: %v", err)
+	}
+	if err := checkRevealed(c.RevealedCommitmentsOfAttrsIndices,
+		c.RevealedCommitmentsOfAttrs, len(c.PubKey.RsCommitted)); err != nil {
+		return false, fmt.Errorf("commitments of attributes: %v", err)
+	}
+
+	statements, err := nonRevocationStatements(c, A, acc, proof.CU, proof.CR)
+	if err != nil {
+		return false, err
+	}
+	// the accumulator only proves that e is not revoked if e is not too large,
+	// which is checked on its response
+	eIndex := statements[2].Secrets[0]
+	if eIndex >= len(proof.ProofData) || proof.ProofData[eIndex] == nil ||
+		proof.ProofData[eIndex].BitLen() > params.EBitLen+params.SecParam+params.HashBitLen+1 {
+		return false, nil
+	}
+
+	return proof.Verify(statements, func(proofRandomData []*big.Int) *big.Int {
+		return nonRevocationChallenge(c.PubKey, A, acc, proof.CU, proof.CR, proofRandomData,
+			nonce)
+	}), nil
+}
+
+// nonRevocationStatements returns the statement about the credential randomized into
+// A, followed by statements CR = G^r2 * H^r3, V = CU^e * (1/H)^(e*r2) and
+// 1 = CR^e * (1/G)^(e*r2) * (1/H)^(e*r3).
+func nonRevocationStatements(c *RevealedCred, A *big.Int, acc *Accumulator,
+	CU, CR *big.Int) ([]*qr.RepresentationStatement, error) {
+	layout, n, err := aggregateLayout([]*RevealedCred{c}, nil)
+	if err != nil {
+		return nil, err
+	}
+	credSt, err := credStatement(c, A, layout[0])
+	if err != nil {
+		return nil, err
+	}
+
+	group := qr.NewRSApecialPublic(acc.N)
+	GInv, HInv := group.Inv(acc.G), group.Inv(acc.H)
+	if GInv == nil || HInv == nil {
+		return nil, fmt.Errorf("accumulator not valid")
+	}
+	e, r2, r3, alpha, beta := n-2, n, n+1, n+2, n+3
+
+	return []*qr.RepresentationStatement{
+		credSt,
+		{
+			Group:   group,
+			Bases:   []*big.Int{acc.G, acc.H},
+			Y:       CR,
+			Secrets: []int{r2, r3},
+		},
+		{
+			Group:   group,
+			Bases:   []*big.Int{CU, HInv},
+			Y:       acc.V,
+			Secrets: []int{e, alpha},
+		},
+		{
+			Group:   group,
+			Bases:   []*big.Int{CR, GInv, HInv},
+			Y:       big.NewInt(1),
+			Secrets: []int{e, alpha, beta},
+		},
+	}, nil
+}
+
+// nonRevocationChallenge derives the challenge of a non-revocation proof from the
+// context of the issuer's public key, the randomized credential, the accumulator,
+// commitments, proof random data and the nonce.
+func nonRevocationChallenge(k *PubKey, A *big.Int, acc *Accumulator, CU, CR *big.Int,
+	proofRandomData []*big.Int, nonce *big.Int) *big.Int {
+	l := []*big.Int{k.GetContext(), A, acc.N, acc.G, acc.H, acc.V, CU, CR}
+	l = append(l, proofRandomData...)
+	l = append(l, nonce)
+
+	return common.Hash(l...)
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// proveNonRevocation builds a proof of cred with a non-revocation proof and verifies
+// the latter against acc.
+func proveNonRevocation(t *testing.T, org *Org, cm *CredManager, cred *Cred, w *Witness,
+	acc *Accumulator) bool {
+	nonce := org.GenNonce()
+	rCred, _, err := cm.BuildProof(cred, []int{1}, nil, nonce)
+	require.NoError(t, err)
+	proof, err := cm.BuildNonRevocationProof(rCred, []int{1}, nil, w, acc, nonce)
+	require.NoError(t, err)
+
+	known, _ := cm.FilterAttributes([]int{1}, nil)
+	ok, err := VerifyNonRevocationProof(org.Params, &RevealedCred{
+		PubKey:                    org.Keys.Pub,
+		RevealedKnownAttrsIndices: []int{1},
+		RevealedKnownAttrs:        known,
+	}, rCred.A, acc, proof, nonce)
+	require.NoError(t, err)
+	return ok
+}
+
+func TestRevocation(t *testing.T) {
+	params, err := LoadParams()
+	require.NoError(t, err)
+	org := loadTestOrg(t, params)
+
+	dir, err := ioutil.TempDir("", "emmy-revocation")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "accumulator")
+	ra, err := LoadOrCreateRevocationAuthority(org.Keys, path)
+	require.NoError(t, err)
+
+	cm1, cred1 := issueTestCred(t, params, org, "Jack", "M")
+	cm2, cred2 := issueTestCred(t, params, org, "Jane", "F")
+	cm3, cred3 := issueTestCred(t, params, org, "John", "M")
+	w1, err := ra.Witness(cred1.E)
+	require.NoError(t, err)
+	w2, err := ra.Witness(cred2.E)
+	require.NoError(t, err)
+	w3, err := ra.Witness(cred3.E)
+	require.NoError(t, err)
+	assert.True(t, proveNonRevocation(t, org, cm1, cred1, w1, ra.Acc))
+
+	// a witness of another credential does not prove non-revocation
+	assert.False(t, proveNonRevocation(t, org, cm1, cred1, w2, ra.Acc))
+
+	require.NoError(t, ra.Revoke(cred2.E))
+	assert.Error(t, ra.Revoke(cred2.E))
+	require.NoError(t, ra.Save(path))
+	_, err = ra.Witness(cred2.E)
+	assert.Error(t, err)
+
+	// the revocation survives reloading
+	ra, err = LoadOrCreateRevocationAuthority(org.Keys, path)
+	require.NoError(t, err)
+	require.Equal(t, 1, ra.Acc.Version)
+	require.NoError(t, ra.Revoke(cred3.E))
+
+	// witnesses need to be updated to the current accumulator
+	stale := *w1
+	stale.Version = ra.Acc.Version
+	assert.False(t, proveNonRevocation(t, org, cm1, cred1, &stale, ra.Acc))
+
+	revoked, err := ra.RevokedSince(w1.Version)
+	require.NoError(t, err)
+	assert.Len(t, revoked, 2)
+	require.NoError(t, w1.Update(cred1.E, ra.Acc, revoked))
+	assert.True(t, proveNonRevocation(t, org, cm1, cred1, w1, ra.Acc))
+
+	assert.Error(t, w2.Update(cred2.E, ra.Acc, revoked), "revoked credential was updated")
+	revoked, err = ra.RevokedSince(w3.Version)
+	require.NoError(t, err)
+	assert.Error(t, w3.Update(cred3.E, ra.Acc, revoked), "revoked credential was updated")
+	// an outdated witness of a revoked credential does not prove non-revocation
+	w2.Version = ra.Acc.Version
+	assert.False(t, proveNonRevocation(t, org, cm2, cred2, w2, ra.Acc))
+
+	_, err = ra.RevokedSince(ra.Acc.Version + 1)
+	assert.Error(t, err)
+	_, err = cm3.BuildNonRevocationProof(cred3, nil, nil, w3, ra.Acc, big.NewInt(1))
+	assert.Error(t, err, "outdated witness was accepted")
+}
@@ -0,0 +1,589 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/encryption"
+	"github.com/xlab-si/emmy/crypto/qr"
+)
+
+// Threshold issuance splits the secret key of the organization among n parties,
+// t of which cooperate to issue a credential, while no party (nor the coordinator
+// of issuance) learns the secret key.
+//
+// The order m = p'q' of QR_N is shared with Shamir's scheme over the integers in
+// a key generation ceremony (see SplitSecKey): party i holds f(i), where
+// f(0) = m, and parties of a set S hold additive shares c_i = Delta * l_i * f(i)
+// of Delta * m, where l_i are Lagrange coefficients of S and Delta = n!.
+//
+// To sign Q with a fresh prime e, that is to compute A = Q^(1/e) mod N, parties
+// compute 1/e mod m following Catalano, Gennaro and Halevi (Computing inverses
+// over a shared secret modulus). Each party i picks random lambda_i and R_i, and
+// parties compute additive shares of Delta * m * lambda, where lambda is the sum
+// of lambda_i, with pairwise multiplications based on Paillier encryption. The
+// coordinator learns only z = e * R + Delta * m * lambda, where R is the sum of
+// R_i. It computes a, b such that a * z + b * e = 1, so that d = a * R + b is the
+// inverse of e modulo m, and A = (Q^a)^R * Q^b is obtained from partial
+// signatures (Q^a)^R_i. The proof that A is correctly formed is computed jointly
+// in the same way.
+//
+// Parties are assumed to follow the protocol (honest-but-curious), the
+// coordinator verifies the signature and the proof before they are sent to the
+// receiver of the credential.
+
+// Signer computes the CL signature A = Q^(1/e) mod N and the proof that A is
+// correctly formed for the nonce of the receiver, for organizations that do not
+// hold the secret key (see Org.UseSigner).
+type Signer interface {
+	Sign(Q, e, nonceUser *big.Int) (*big.Int, *qr.RepresentationProof, error)
+}
+
+// ThresholdKeyShare is a share of the secret key of the organization, held by
+// one of the parties that jointly issue credentials.
+type ThresholdKeyShare struct {
+	Index     int      // index of the party, from 1 to Parties
+	Threshold int      // number of parties needed to issue a credential
+	Parties   int      // number of parties holding shares
+	N         *big.Int // modulus of the public key of the organization
+	Share     *big.Int // value of the sharing polynomial at Index
+}
+
+// SplitSecKey splits secret key sec of the organization into shares for n
+// parties, t of which are needed to issue credentials. The key generation
+// ceremony is run by a trusted dealer, which needs to destroy sec once the shares
+// are handed over to the parties.
+func SplitSecKey(params *Params, sec *SecKey, t, n int) ([]*ThresholdKeyShare, error) {
+	if t < 2 {
+		return nil, fmt.Errorf("the threshold should be at least 2")
+	}
+	if t > n {
+		return nil, fmt.Errorf("the threshold should not exceed the number of parties")
+	}
+	if sec == nil || sec.RsaPrimes == nil {
+		return nil, fmt.Errorf("missing secret key")
+	}
+
+	p := sec.RsaPrimes
+	m := new(big.Int).Mul(p.P1, p.Q1)
+	N := new(big.Int).Mul(p.P, p.Q)
+	delta := factorial(n)
+	// coefficients statistically hide m in any t-1 shares
+	bound := new(big.Int).Lsh(big.NewInt(1),
+		uint(m.BitLen()+2*delta.BitLen()+params.SecParam))
+	coeffs := make([]*big.Int, t)
+	coeffs[0] = m
+	for k := 1; k < t; k++ {
+		coeffs[k] = common.GetRandomInt(bound)
+	}
+
+	shares := make([]*ThresholdKeyShare, n)
+	for i := range shares {
+		x := big.NewInt(int64(i + 1))
+		// Horner's method
+		y := new(big.Int)
+		for k := t - 1; k >= 0; k-- {
+			y.Mul(y, x)
+			y.Add(y, coeffs[k])
+		}
+		shares[i] = &ThresholdKeyShare{
+			Index:     i + 1,
+			Threshold: t,
+			Parties:   n,
+			N:         N,
+			Share:     y,
+		}
+	}
+
+	return shares, nil
+}
+
+// factorial returns n!.
+func factorial(n int) *big.Int {
+	return new(big.Int).MulRange(1, int64(n))
+}
+
+// lagrangeCoeff returns Delta times the Lagrange coefficient at 0 of party i
+// among signers, which is an integer.
+func lagrangeCoeff(signers []int, i, parties int) (*big.Int, error) {
+	num := factorial(parties)
+	den := big.NewInt(1)
+	for _, j := range signers {
+		if j == i {
+			continue
+		}
+		num.Mul(num, big.NewInt(int64(j)))
+		den.Mul(den, big.NewInt(int64(j-i)))
+	}
+	coeff, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() != 0 {
+		return nil, fmt.Errorf("invalid set of signers")
+	}
+	return coeff, nil
+}
+
+// checkSigners checks that signers are distinct indices of parties, at least
+// threshold of them.
+func checkSigners(signers []int, threshold, parties int) error {
+	if len(signers) < threshold {
+		return fmt.Errorf("at least %d signers needed, got %d", threshold, len(signers))
+	}
+	seen := make(map[int]bool, len(signers))
+	for _, i := range signers {
+		if i < 1 || i > parties || seen[i] {
+			return fmt.Errorf("invalid signer %d", i)
+		}
+		seen[i] = true
+	}
+	return nil
+}
+
+// ThresholdCommitment is the first message of a party in threshold signing: its
+// Paillier public key and its additive share of Delta * m encrypted under it.
+type ThresholdCommitment struct {
+	Index     int
+	PaillierN *big.Int
+	PaillierG *big.Int
+	EncShare  *big.Int
+}
+
+// ThresholdCiphertext is a ciphertext of the pairwise multiplication of shares,
+// sent by party From to party To.
+type ThresholdCiphertext struct {
+	From  int
+	To    int
+	Value *big.Int
+}
+
+// PartialSignature is a party's part of the signature, A_i = B^R_i, and of the
+// first message of the proof, T_i = B^rho_i, where B = Q^a.
+type PartialSignature struct {
+	A *big.Int
+	T *big.Int
+}
+
+// ThresholdSession is a signing session of the coordinator with one party, who
+// might be reached over the network. Methods are called in the order of rounds
+// of the protocol.
+type ThresholdSession interface {
+	// Commit starts signing with prime e by the given signers.
+	Commit(e *big.Int, signers []int) (*ThresholdCommitment, error)
+
+	// MtA multiplies the party's share of lambda with shares of other signers,
+	// given by their commitments.
+	MtA(commitments []*ThresholdCommitment) ([]*ThresholdCiphertext, error)
+
+	// Mask returns the party's share of z, given ciphertexts of other signers
+	// addressed to the party.
+	Mask(ciphertexts []*ThresholdCiphertext) (*big.Int, error)
+
+	// PartialSign returns the partial signature for base B = Q^a.
+	PartialSign(B *big.Int) (*PartialSignature, error)
+
+	// Respond returns the party's share of the response of the proof for the
+	// given challenge, and ends the session.
+	Respond(challenge *big.Int) (*big.Int, error)
+}
+
+// ThresholdParty holds a share of the secret key of the organization and takes
+// part in threshold signing.
+type ThresholdParty struct {
+	params   *Params
+	share    *ThresholdKeyShare
+	paillier *encryption.Paillier
+	// bit lengths of random values chosen in sessions
+	lambdaBitLen int
+	rBitLen      int
+}
+
+// NewThresholdParty returns a party holding share. A Paillier key pair large enough
+// for multiplications of the party's share is generated, so this might take a
+// while.
+func NewThresholdParty(params *Params, share *ThresholdKeyShare) (*ThresholdParty, error) {
+	if share == nil || share.Share == nil || share.N == nil {
+		return nil, fmt.Errorf("incomplete key share")
+	}
+	if err := checkSigners([]int{share.Index}, 1, share.Parties); err != nil {
+		return nil, err
+	}
+
+	deltaBitLen := factorial(share.Parties).BitLen()
+	// lambda mod e needs to be close to uniform
+	lambdaBitLen := params.EBitLen + params.SecParam
+	// e * R statistically hides Delta * m * lambda
+	rBitLen := share.N.BitLen() + deltaBitLen + lambdaBitLen + params.SecParam
+	// |Delta * l_i| < Delta^2
+	shareBitLen := share.Share.BitLen() + 2*deltaBitLen
+	// plaintexts of MtA are below N/4, products of shares and lambdas below N/8
+	// are masked by random values
+	maskBitLen := shareBitLen + lambdaBitLen + params.SecParam
+	paillier := encryption.NewPaillier((maskBitLen+4)/2 + 1)
+
+	return &ThresholdParty{
+		params:       params,
+		share:        share,
+		paillier:     paillier,
+		lambdaBitLen: lambdaBitLen,
+		rBitLen:      rBitLen,
+	}, nil
+}
+
+// Index returns the index of the party.
+func (p *ThresholdParty) Index() int {
+	return p.share.Index
+}
+
+// NewSession starts a signing session of the party.
+func (p *ThresholdParty) NewSession() *ThresholdPartySession {
+	return &ThresholdPartySession{
+		party: p,
+	}
+}
+
+// ThresholdPartySession is a signing session of a party, which implements
+// ThresholdSession for coordinators running in the same process.
+type ThresholdPartySession struct {
+	party   *ThresholdParty
+	round   int
+	e       *big.Int
+	signers []int
+	c       *big.Int         // additive share of Delta * m
+	lambda  *big.Int         // additive share of lambda
+	R       *big.Int         // additive share of R
+	rho     *big.Int         // random value of the proof
+	betas   map[int]*big.Int // masks of multiplications with shares of other signers
+}
+
+// next checks that the session is in the given round and moves it to the next one.
+func (s *ThresholdPartySession) next(round int) error {
+	if s.round != round {
+		return fmt.Errorf("unexpected message in round %d of threshold signing", s.round)
+	}
+	s.round++
+	return nil
+}
+
+// Commit starts signing with prime e by the given signers.
+func (s *ThresholdPartySession) Commit(e *big.Int, signers []int) (*ThresholdCommitment,
+	error) {
+	if err := s.next(0); err != nil {
+		return nil, err
+	}
+	share := s.party.share
+	if err := checkSigners(signers, share.Threshold, share.Parties); err != nil {
+		return nil, err
+	}
+	// e needs to be coprime with Delta
+	if e == nil || e.Cmp(big.NewInt(int64(share.Parties))) <= 0 || !e.ProbablyPrime(20) {
+		return nil, fmt.Errorf("e is not a valid prime")
+	}
+	own := false
+	for _, i := range signers {
+		own = own || i == share.Index
+	}
+	if !own {
+		return nil, fmt.Errorf("party %d is not among signers", share.Index)
+	}
+
+	coeff, err := lagrangeCoeff(signers, share.Index, share.Parties)
+	if err != nil {
+		return nil, err
+	}
+	s.e = e
+	s.signers = signers
+	s.c = coeff.Mul(coeff, share.Share)
+	s.lambda = randomBits(s.party.lambdaBitLen)
+	s.R = randomBits(s.party.rBitLen)
+
+	pubKey := s.party.paillier.GetPubKey()
+	encShare, err := s.party.paillier.Encrypt(new(big.Int).Mod(s.c, pubKey.N()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ThresholdCommitment{
+		Index:     share.Index,
+		PaillierN: pubKey.N(),
+		PaillierG: pubKey.G(),
+		EncShare:  encShare,
+	}, nil
+}
+
+// MtA returns, for each other signer i, an encryption of c_i * lambda_j + beta
+// under the key of i, where c_i is the share of i and lambda_j the share of this
+// party. The party keeps -beta as its share of the product.
+func (s *ThresholdPartySession) MtA(commitments []*ThresholdCommitment) (
+	[]*ThresholdCiphertext, error) {
+	if err := s.next(1); err != nil {
+		return nil, err
+	}
+	own := s.party.share.Index
+	s.betas = make(map[int]*big.Int, len(s.signers)-1)
+	var cts []*ThresholdCiphertext
+	for _, c := range commitments {
+		if c.Index == own {
+			continue
+		}
+		if !common.Contains(s.signers, c.Index) || s.betas[c.Index] != nil {
+			return nil, fmt.Errorf("unexpected commitment of party %d", c.Index)
+		}
+		if c.PaillierN == nil || c.PaillierG == nil || c.EncShare == nil {
+			return nil, fmt.Errorf("incomplete commitment of party %d", c.Index)
+		}
+		// products need to be masked by beta, which is below N/8
+		if c.PaillierN.BitLen() < s.party.lambdaBitLen+s.party.params.SecParam+4 {
+			return nil, fmt.Errorf("Paillier key of party %d is too small", c.Index)
+		}
+		pub := encryption.NewPubPaillier(encryption.NewPaillierPubKey(c.PaillierN,
+			c.PaillierG))
+		beta := randomBits(c.PaillierN.BitLen() - 4)
+		encBeta, err := pub.Encrypt(beta)
+		if err != nil {
+			return nil, err
+		}
+		s.betas[c.Index] = beta
+		cts = append(cts, &ThresholdCiphertext{
+			From:  own,
+			To:    c.Index,
+			Value: pub.Add(pub.MulConst(c.EncShare, s.lambda), encBeta),
+		})
+	}
+	if len(s.betas) != len(s.signers)-1 {
+		return nil, fmt.Errorf("missing commitments of signers")
+	}
+
+	return cts, nil
+}
+
+// Mask returns w_i = e * R_i + u_i, where u_i is the party's share of
+// Delta * m * lambda.
+func (s *ThresholdPartySession) Mask(ciphertexts []*ThresholdCiphertext) (*big.Int, error) {
+	if err := s.next(2); err != nil {
+		return nil, err
+	}
+	own := s.party.share.Index
+	n := s.party.paillier.GetPubKey().N()
+	half := new(big.Int).Rsh(n, 1)
+	seen := make(map[int]bool, len(ciphertexts))
+	u := new(big.Int).Mul(s.c, s.lambda)
+	for _, ct := range ciphertexts {
+		if ct.To != own || ct.From == own || !common.Contains(s.signers, ct.From) ||
+			seen[ct.From] || ct.Value == nil {
+			return nil, fmt.Errorf("unexpected ciphertext from party %d", ct.From)
+		}
+		seen[ct.From] = true
+		alpha, err := s.party.paillier.Decrypt(new(big.Int).Set(ct.Value))
+		if err != nil {
+			return nil, err
+		}
+		// shares can be negative
+		if alpha.Cmp(half) > 0 {
+			alpha.Sub(alpha, n)
+		}
+		u.Add(u, alpha)
+	}
+	if len(seen) != len(s.signers)-1 {
+		return nil, fmt.Errorf("missing ciphertexts of signers")
+	}
+	for _, beta := range s.betas {
+		u.Sub(u, beta)
+	}
+
+	w := new(big.Int).Mul(s.e, s.R)
+	return w.Add(w, u), nil
+}
+
+// PartialSign returns A_i = B^R_i and T_i = B^rho_i.
+func (s *ThresholdPartySession) PartialSign(B *big.Int) (*PartialSignature, error) {
+	if err := s.next(3); err != nil {
+		return nil, err
+	}
+	N := s.party.share.N
+	if B == nil || B.Sign() <= 0 || B.Cmp(N) >= 0 {
+		return nil, fmt.Errorf("invalid base")
+	}
+	// rho statistically hides challenge * R_i
+	s.rho = randomBits(s.party.rBitLen + s.party.params.HashBitLen +
+		s.party.params.SecParam)
+
+	return &PartialSignature{
+		A: new(big.Int).Exp(B, s.R, N),
+		T: new(big.Int).Exp(B, s.rho, N),
+	}, nil
+}
+
+// Respond returns rho_i + challenge * R_i, and clears secrets of the session.
+func (s *ThresholdPartySession) Respond(challenge *big.Int) (*big.Int, error) {
+	if err := s.next(4); err != nil {
+		return nil, err
+	}
+	if challenge == nil {
+		return nil, fmt.Errorf("missing challenge")
+	}
+	resp := new(big.Int).Mul(challenge, s.R)
+	resp.Add(resp, s.rho)
+	s.c, s.lambda, s.R, s.rho, s.betas = nil, nil, nil, nil, nil
+
+	return resp, nil
+}
+
+// randomBits returns a random integer from [0, 2^bitLen).
+func randomBits(bitLen int) *big.Int {
+	return common.GetRandomInt(new(big.Int).Lsh(big.NewInt(1), uint(bitLen)))
+}
+
+// ThresholdSign computes signature A = Q^(1/e) mod N with the public key pub and
+// the proof that A is correctly formed for nonceUser, jointly with parties of
+// sessions (indexed by parties), which need to be at least the threshold of
+// parties. The coordinator calling ThresholdSign learns nothing about the secret
+// key.
+func ThresholdSign(pub *PubKey, Q, e, nonceUser *big.Int,
+	sessions map[int]ThresholdSession) (*big.Int, *qr.RepresentationProof, error) {
+	signers := make([]int, 0, len(sessions))
+	for i := range sessions {
+		signers = append(signers, i)
+	}
+	sort.Ints(signers)
+	N := pub.N
+
+	commitments := make([]*ThresholdCommitment, len(signers))
+	if err := forEachSigner(signers, func(k, i int) (err error) {
+		commitments[k], err = sessions[i].Commit(e, signers)
+		if err == nil && commitments[k].Index != i {
+			err = fmt.Errorf("commitment of party %d instead of %d", commitments[k].Index, i)
+		}
+		return
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	var mu sync.Mutex
+	received := make(map[int][]*ThresholdCiphertext, len(signers))
+	if err := forEachSigner(signers, func(k, i int) error {
+		cts, err := sessions[i].MtA(commitments)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, ct := range cts {
+			if ct.From != i {
+				return fmt.Errorf("ciphertext of party %d instead of %d", ct.From, i)
+			}
+			received[ct.To] = append(received[ct.To], ct)
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	ws := make([]*big.Int, len(signers))
+	if err := forEachSigner(signers, func(k, i int) (err error) {
+		ws[k], err = sessions[i].Mask(received[i])
+		return
+	}); err != nil {
+		return nil, nil, err
+	}
+	z := new(big.Int)
+	for _, w := range ws {
+		z.Add(z, w)
+	}
+
+	// a * z + b * e = 1, where z is negative with negligible probability
+	a, b := new(big.Int), new(big.Int)
+	if z.Sign() <= 0 || new(big.Int).GCD(a, b, z, e).Cmp(big.NewInt(1)) != 0 {
+		return nil, nil, fmt.Errorf("cannot invert e, signing needs to be repeated")
+	}
+	B := common.ExpMod(new(big.Int), Q, a, N)
+	if B == nil {
+		return nil, nil, fmt.Errorf("Q is not invertible")
+	}
+
+	partials := make([]*PartialSignature, len(signers))
+	if err := forEachSigner(signers, func(k, i int) (err error) {
+		partials[k], err = sessions[i].PartialSign(B)
+		return
+	}); err != nil {
+		return nil, nil, err
+	}
+	A := common.ExpMod(new(big.Int), Q, b, N)
+	T := big.NewInt(1)
+	for _, p := range partials {
+		if p.A == nil || p.T == nil {
+			return nil, nil, fmt.Errorf("incomplete partial signature")
+		}
+		common.MulMod(A, A, p.A, N)
+		common.MulMod(T, T, p.T, N)
+	}
+	if new(big.Int).Exp(A, e, N).Cmp(Q) != 0 {
+		return nil, nil, fmt.Errorf("partial signatures not valid")
+	}
+
+	// challenge = hash(context||Q||A||AProofRandomData||nonceUser)
+	challenge := common.Hash(pub.GetContext(), Q, A, T, nonceUser)
+	resps := make([]*big.Int, len(signers))
+	if err := forEachSigner(signers, func(k, i int) (err error) {
+		resps[k], err = sessions[i].Respond(challenge)
+		return
+	}); err != nil {
+		return nil, nil, err
+	}
+	// Q^(a * s + challenge * b) = B^rho * A^challenge, where s = rho + challenge * R
+	s := new(big.Int)
+	for _, r := range resps {
+		s.Add(s, r)
+	}
+	resp := new(big.Int).Mul(a, s)
+	resp.Add(resp, new(big.Int).Mul(challenge, b))
+	proof := qr.NewRepresentationProof(T, challenge, []*big.Int{resp})
+
+	ver := qr.NewRepresentationVerifier(qr.NewRSApecialPublic(N), 0)
+	ver.SetProofRandomData(T, []*big.Int{Q}, A)
+	ver.SetChallenge(challenge)
+	if !ver.Verify(proof.ProofData) {
+		return nil, nil, fmt.Errorf("proof of signature not valid")
+	}
+
+	return A, proof, nil
+}
+
+// forEachSigner calls f concurrently for each of signers with its position k and
+// index i, and returns the first error.
+func forEachSigner(signers []int, f func(k, i int) error) error {
+	errs := make([]error, len(signers))
+	var wg sync.WaitGroup
+	for k, i := range signers {
+		wg.Add(1)
+		go func(k, i int) {
+			defer wg.Done()
+			errs[k] = f(k, i)
+		}(k, i)
+	}
+	wg.Wait()
+	for k, err := range errs {
+		if err != nil {
+			return fmt.Errorf("party %d: %v", signers[k], err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cl
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/qr"
+)
+
+// thresholdSigner signs with parties running in the same process.
+type thresholdSigner struct {
+	pub     *PubKey
+	parties []*ThresholdParty
+}
+
+func (s *thresholdSigner) Sign(Q, e, nonceUser *big.Int) (*big.Int, *qr.RepresentationProof,
+	error) {
+	sessions := make(map[int]ThresholdSession, len(s.parties))
+	for _, p := range s.parties {
+		sessions[p.Index()] = p.NewSession()
+	}
+	return ThresholdSign(s.pub, Q, e, nonceUser, sessions)
+}
+
+func TestThresholdIssuance(t *testing.T) {
+	params := GetDefaultParamSizes()
+	attrCount := NewAttrCount(5, 1, 0)
+	org := loadTestOrg(t, params)
+
+	_, err := SplitSecKey(params, org.Keys.Sec, 1, 3)
+	assert.Error(t, err)
+	_, err = SplitSecKey(params, org.Keys.Sec, 4, 3)
+	assert.Error(t, err)
+	shares, err := SplitSecKey(params, org.Keys.Sec, 2, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 3)
+	parties := make([]*ThresholdParty, len(shares))
+	for i, share := range shares {
+		parties[i], err = NewThresholdParty(params, share)
+		require.NoError(t, err)
+	}
+
+	// the issuing organization knows only the public key
+	issuer, err := NewOrgFromParams(params, &KeyPair{Pub: org.Keys.Pub})
+	require.NoError(t, err)
+	rc := NewRawCred(attrCount)
+	require.NoError(t, rc.AddStrAttr("Name", "Jack", true))
+	require.NoError(t, rc.AddStrAttr("Gender", "M", true))
+	require.NoError(t, rc.AddStrAttr("Graduated", "true", true))
+	require.NoError(t, rc.AddInt64Attr("DateMin", 22342345, true))
+	require.NoError(t, rc.AddInt64Attr("Age", 25, true))
+	require.NoError(t, rc.AddInt64Attr("DateMax", 32342345, false))
+	credMgr, err := NewCredManager(params, org.Keys.Pub,
+		org.Keys.Pub.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+	credReq, err := credMgr.GetCredRequest(issuer.GetCredIssueNonce())
+	require.NoError(t, err)
+	Q, e := org.Keys.Pub.Z, big.NewInt(1000033)
+	_, _, err = issuer.sign(Q, e, credReq.Nonce)
+	assert.Error(t, err, "signing should fail without the secret key")
+	_, _, err = (&thresholdSigner{pub: org.Keys.Pub, parties: parties[1:2]}).Sign(Q, e,
+		credReq.Nonce)
+	assert.Error(t, err, "signing should fail with fewer parties than the threshold")
+
+	issuer.UseSigner(&thresholdSigner{pub: org.Keys.Pub, parties: parties[:2]})
+	res, err := issuer.IssueCred(credReq)
+	require.NoError(t, err)
+	ok, err := credMgr.Verify(res.Cred, res.AProof)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// credentials are updated by any other parties reaching the threshold
+	issuer.UseSigner(&thresholdSigner{pub: org.Keys.Pub,
+		parties: []*ThresholdParty{parties[0], parties[2]}})
+	a, err := rc.GetAttr("Age")
+	require.NoError(t, err)
+	require.NoError(t, a.UpdateValue(26))
+	credMgr.Update(rc)
+	res, err = issuer.UpdateCred(credMgr.Nym, res.Record, credReq.Nonce, rc.GetKnownVals())
+	require.NoError(t, err)
+	ok, err = credMgr.Verify(res.Cred, res.AProof)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestThresholdPartySession(t *testing.T) {
+	params := GetDefaultParamSizes()
+	share := &ThresholdKeyShare{
+		Index:     2,
+		Threshold: 2,
+		Parties:   3,
+		N:         big.NewInt(1000003),
+		Share:     big.NewInt(123456),
+	}
+	p, err := NewThresholdParty(params, share)
+	require.NoError(t, err)
+	e := big.NewInt(1000033)
+
+	_, err = p.NewSession().Commit(e, []int{1, 3})
+	assert.Error(t, err, "party needs to be among signers")
+	_, err = p.NewSession().Commit(e, []int{2})
+	assert.Error(t, err, "signers need to reach the threshold")
+	_, err = p.NewSession().Commit(e, []int{2, 2})
+	assert.Error(t, err, "signers need to be distinct")
+	_, err = p.NewSession().Commit(big.NewInt(1000032), []int{1, 2})
+	assert.Error(t, err, "e needs to be prime")
+
+	s := p.NewSession()
+	_, err = s.PartialSign(big.NewInt(4))
+	assert.Error(t, err, "rounds need to be in order")
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package commitments provides commitment schemes behind a common interface, so
+// that protocols committing to values, such as CL credentials with committed
+// attributes, can be used with any of them. Schemes are registered by name and
+// instantiated with New:
+//
+//   - SchemeQR: Damgard-Fujisaki commitments in the group of quadratic residues
+//     modulo a special RSA modulus (an integer variant of Pedersen commitments),
+//   - SchemeECP256, SchemeECP384, SchemeECSecp256k1: Pedersen commitments over
+//     elliptic curves, which are much shorter than commitments in QR_N.
+//
+// Commitments and the first messages of proofs of their opening are represented
+// by integers, elements of elliptic curves are encoded with ec.Group.Encode.
+package commitments
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/xlab-si/emmy/crypto/ec"
+)
+
+// Names of the built-in commitment schemes.
+const (
+	SchemeQR          = "qr"
+	SchemeECP256      = "ec-p256"
+	SchemeECP384      = "ec-p384"
+	SchemeECSecp256k1 = "ec-secp256k1"
+)
+
+// Scheme is a commitment scheme instantiated with public parameters.
+type Scheme interface {
+	// NewCommitter returns a committer, which commits to a single value.
+	NewCommitter() Committer
+	// VerifyOpening verifies that proof, computed for the challenge of proof,
+	// proves the knowledge of the opening of commitment c.
+	VerifyOpening(c *big.Int, proof *OpeningProof) bool
+	// BitLen returns the upper bound on bit length of commitments.
+	BitLen() int
+}
+
+// Committer commits to a value and proves the knowledge of the opening of the
+// commitment, using sigma protocol where the challenge is given by the caller
+// (for example computed via Fiat-Shamir).
+type Committer interface {
+	// GetCommitMsg commits to val with random randomness and returns the commitment.
+	GetCommitMsg(val *big.Int) (*big.Int, error)
+	// GetCommitMsgWithGivenR commits to val with randomness r.
+	GetCommitMsgWithGivenR(val, r *big.Int) (*big.Int, error)
+	// GetDecommitMsg returns the committed value and randomness.
+	GetDecommitMsg() (*big.Int, *big.Int)
+	// GetProofRandomData returns the first message of the proof of opening.
+	GetProofRandomData() *big.Int
+	// GetProofData returns the responses to challenge.
+	GetProofData(challenge *big.Int) (*big.Int, *big.Int)
+}
+
+// OpeningProof presents all three messages of the proof of the knowledge of the
+// opening of a commitment.
+type OpeningProof struct {
+	ProofRandomData *big.Int
+	Challenge       *big.Int
+	ProofData1      *big.Int
+	ProofData2      *big.Int
+}
+
+func NewOpeningProof(proofRandomData, challenge, proofData1, proofData2 *big.Int) *OpeningProof {
+	return &OpeningProof{
+		ProofRandomData: proofRandomData,
+		Challenge:       challenge,
+		ProofData1:      proofData1,
+		ProofData2:      proofData2,
+	}
+}
+
+// complete tells whether none of the values of p is missing.
+func (p *OpeningProof) complete() bool {
+	return p != nil && p.ProofRandomData != nil && p.Challenge != nil &&
+		p.ProofData1 != nil && p.ProofData2 != nil
+}
+
+// Params holds public parameters that schemes are instantiated with, each scheme
+// uses only those it needs.
+type Params struct {
+	// modulus and generators of QR_N, for SchemeQR
+	N *big.Int
+	G *big.Int
+	H *big.Int
+	// security parameter, on which the hiding property of SchemeQR depends
+	SecParam int
+	// bit length of challenges of proofs of opening, for SchemeQR
+	ChallengeSpace int
+}
+
+// Factory instantiates a scheme with public parameters p.
+type Factory func(p *Params) (Scheme, error)
+
+var (
+	schemes = map[string]Factory{
+		SchemeQR:          newQRScheme,
+		SchemeECP256:      ecSchemeFactory(ec.P256),
+		SchemeECP384:      ecSchemeFactory(ec.P384),
+		SchemeECSecp256k1: ecSchemeFactory(ec.Secp256k1),
+	}
+	schemesLock sync.RWMutex
+)
+
+// Register makes the scheme instantiated by f available under name, replacing
+// the scheme registered under the same name, if any.
+func Register(name string, f Factory) {
+	schemesLock.Lock()
+	defer schemesLock.Unlock()
+	schemes[name] = f
+}
+
+// IsRegistered tells whether a scheme is registered under name.
+func IsRegistered(name string) bool {
+	schemesLock.RLock()
+	defer schemesLock.RUnlock()
+	_, ok := schemes[name]
+	return ok
+}
+
+// Names returns names of the registered schemes, in alphabetical order.
+func Names() []string {
+	schemesLock.RLock()
+	defer schemesLock.RUnlock()
+	names := make([]string, 0, len(schemes))
+	for name := range schemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the scheme registered under name, instantiated with p.
+func New(name string, p *Params) (Scheme, error) {
+	schemesLock.RLock()
+	f, ok := schemes[name]
+	schemesLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown commitment scheme: %s", name)
+	}
+
+	return f(p)
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package commitments
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/df"
+	"github.com/xlab-si/emmy/crypto/ec"
+)
+
+func testSchemes(t *testing.T) map[string]Scheme {
+	receiver, err := df.NewReceiver(128, 80)
+	require.NoError(t, err)
+	p := &Params{
+		N:              receiver.QRSpecialRSA.N,
+		G:              receiver.G,
+		H:              receiver.H,
+		SecParam:       80,
+		ChallengeSpace: 80,
+	}
+
+	schemes := make(map[string]Scheme)
+	for _, name := range Names() {
+		s, err := New(name, p)
+		require.NoError(t, err, name)
+		schemes[name] = s
+	}
+	return schemes
+}
+
+func TestCommitmentSchemes(t *testing.T) {
+	challenge := common.GetRandomInt(new(big.Int).Lsh(big.NewInt(1), 512))
+	for name, s := range testSchemes(t) {
+		committer := s.NewCommitter()
+		val := big.NewInt(123456789)
+		c, err := committer.GetCommitMsg(val)
+		require.NoError(t, err, name)
+		assert.True(t, c.BitLen() <= s.BitLen(), name)
+
+		t1 := committer.GetProofRandomData()
+		s1, s2 := committer.GetProofData(challenge)
+		proof := NewOpeningProof(t1, challenge, s1, s2)
+		assert.True(t, s.VerifyOpening(c, proof), name)
+
+		// the same value committed to with the same randomness
+		committedVal, r := committer.GetDecommitMsg()
+		assert.Equal(t, val, committedVal, name)
+		c1, err := s.NewCommitter().GetCommitMsgWithGivenR(val, r)
+		require.NoError(t, err, name)
+		assert.Equal(t, c, c1, name)
+
+		// commitment to another value
+		c2, err := s.NewCommitter().GetCommitMsg(big.NewInt(987654321))
+		require.NoError(t, err, name)
+		assert.False(t, s.VerifyOpening(c2, proof), name)
+		// proof for another challenge
+		proof = NewOpeningProof(t1, new(big.Int).Add(challenge, big.NewInt(1)), s1, s2)
+		assert.False(t, s.VerifyOpening(c, proof), name)
+		assert.False(t, s.VerifyOpening(c, NewOpeningProof(t1, challenge, s1, nil)), name)
+		assert.False(t, s.VerifyOpening(c, nil), name)
+	}
+}
+
+func TestECCommitments(t *testing.T) {
+	schemes := testSchemes(t)
+	assert.Equal(t, 257, schemes[SchemeECP256].BitLen())
+	assert.Equal(t, 257, schemes[SchemeECSecp256k1].BitLen())
+
+	s := schemes[SchemeECP256]
+	committer := s.NewCommitter()
+	c, err := committer.GetCommitMsg(big.NewInt(42))
+	require.NoError(t, err)
+	t1 := committer.GetProofRandomData()
+	s1, s2 := committer.GetProofData(big.NewInt(1))
+	assert.True(t, s.VerifyOpening(c, NewOpeningProof(t1, big.NewInt(1), s1, s2)))
+	// not an encoding of a point on the curve
+	notOnCurve := new(big.Int).Lsh(ec.GetCurve(ec.P256).Params().P, 1)
+	assert.False(t, s.VerifyOpening(notOnCurve, NewOpeningProof(t1, big.NewInt(1), s1, s2)))
+
+	// values need to be in Z_q
+	_, err = committer.GetCommitMsg(new(big.Int).Lsh(big.NewInt(1), 256))
+	assert.Error(t, err)
+
+	params, ok := ECParams(s)
+	require.True(t, ok)
+	assert.Equal(t, ec.GetCurve(ec.P256), params.Group.Curve)
+	_, ok = ECParams(schemes[SchemeQR])
+	assert.False(t, ok)
+}
+
+func TestRegister(t *testing.T) {
+	_, err := New("unknown", &Params{})
+	assert.Error(t, err)
+	assert.False(t, IsRegistered("unknown"))
+	_, err = New(SchemeQR, &Params{})
+	assert.Error(t, err, "QR scheme needs modulus and generators")
+
+	Register("p256", ecSchemeFactory(ec.P256))
+	defer func() {
+		schemesLock.Lock()
+		delete(schemes, "p256")
+		schemesLock.Unlock()
+	}()
+	assert.True(t, IsRegistered("p256"))
+	assert.Contains(t, Names(), "p256")
+	_, err = New("p256", nil)
+	assert.NoError(t, err)
+}
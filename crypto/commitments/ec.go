@@ -0,0 +1,142 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package commitments
+
+import (
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/ecpedersen"
+)
+
+// ecGeneratorSeed is the seed that the second generator of Pedersen commitments over
+// elliptic curves is derived from (see ec.Group.HashToElement), so that nobody knows
+// its discrete logarithm and commitments are binding for everyone.
+const ecGeneratorSeed = "emmy Pedersen commitments"
+
+// ecScheme holds Pedersen commitments over an elliptic curve, c = g^x * h^r. Values
+// in Z_q can be committed to, where q is the order of the group.
+type ecScheme struct {
+	params *ecpedersen.Params
+}
+
+func ecSchemeFactory(curve ec.Curve) Factory {
+	return func(*Params) (Scheme, error) {
+		group := ec.NewGroup(curve)
+		h := group.HashToElement([]byte(ecGeneratorSeed))
+		return &ecScheme{
+			params: ecpedersen.NewParams(group, h, nil),
+		}, nil
+	}
+}
+
+func (s *ecScheme) NewCommitter() Committer {
+	return &ecCommitter{
+		committer: ecpedersen.NewCommitter(s.params),
+	}
+}
+
+func (s *ecScheme) VerifyOpening(c *big.Int, proof *OpeningProof) bool {
+	if c == nil || !proof.complete() {
+		return false
+	}
+	group := s.params.Group
+	comm, err := group.Decode(c)
+	if err != nil {
+		return false
+	}
+	t, err := group.Decode(proof.ProofRandomData)
+	if err != nil {
+		return false
+	}
+	if proof.ProofData1.Sign() < 0 || proof.ProofData2.Sign() < 0 {
+		return false
+	}
+
+	// g^s1 * h^s2 = t * c^challenge
+	challenge := new(big.Int).Mod(proof.Challenge, group.Q)
+	left := group.Mul(group.ExpBaseG(proof.ProofData1),
+		group.Exp(s.params.H, proof.ProofData2))
+	right := group.Mul(t, group.Exp(comm, challenge))
+
+	return left.Equals(right)
+}
+
+func (s *ecScheme) BitLen() int {
+	return s.params.Group.Curve.Params().P.BitLen() + 1
+}
+
+type ecCommitter struct {
+	committer *ecpedersen.Committer
+	r1        *big.Int
+	r2        *big.Int
+}
+
+func (c *ecCommitter) GetCommitMsg(val *big.Int) (*big.Int, error) {
+	comm, err := c.committer.GetCommitMsg(val)
+	if err != nil {
+		return nil, err
+	}
+	return c.committer.Params.Group.Encode(comm), nil
+}
+
+func (c *ecCommitter) GetCommitMsgWithGivenR(val, r *big.Int) (*big.Int, error) {
+	comm, err := c.committer.GetCommitMsgWithGivenR(val, r)
+	if err != nil {
+		return nil, err
+	}
+	return c.committer.Params.Group.Encode(comm), nil
+}
+
+func (c *ecCommitter) GetDecommitMsg() (*big.Int, *big.Int) {
+	return c.committer.GetDecommitMsg()
+}
+
+func (c *ecCommitter) GetProofRandomData() *big.Int {
+	group := c.committer.Params.Group
+	c.r1 = common.GetRandomInt(group.Q)
+	c.r2 = common.GetRandomInt(group.Q)
+	// g^r1 * h^r2
+	t := group.Mul(group.ExpBaseG(c.r1), group.Exp(c.committer.Params.H, c.r2))
+	return group.Encode(t)
+}
+
+func (c *ecCommitter) GetProofData(challenge *big.Int) (*big.Int, *big.Int) {
+	// s1 = r1 + challenge*a mod q, s2 = r2 + challenge*r mod q
+	q := c.committer.Params.Group.Q
+	a, r := c.committer.GetDecommitMsg()
+	s1 := new(big.Int).Mul(challenge, a)
+	s1.Add(s1, c.r1)
+	s1.Mod(s1, q)
+	s2 := new(big.Int).Mul(challenge, r)
+	s2.Add(s2, c.r2)
+	s2.Mod(s2, q)
+	return s1, s2
+}
+
+// ECParams returns parameters of Pedersen commitments of s, if s is a scheme over an
+// elliptic curve, for proofs that are specific to these commitments, such as
+// Bulletproofs.
+func ECParams(s Scheme) (*ecpedersen.Params, bool) {
+	es, ok := s.(*ecScheme)
+	if !ok {
+		return nil, false
+	}
+	return es.params, true
+}
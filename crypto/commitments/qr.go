@@ -0,0 +1,91 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package commitments
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/df"
+)
+
+// qrScheme holds Damgard-Fujisaki commitments in QR_N, where the order of the group
+// is hidden from the committer. Any integer in (-N, N) can be committed to.
+type qrScheme struct {
+	params *Params
+}
+
+func newQRScheme(p *Params) (Scheme, error) {
+	if p == nil || p.N == nil || p.G == nil || p.H == nil {
+		return nil, fmt.Errorf("missing modulus or generators of QR_N")
+	}
+
+	return &qrScheme{
+		params: p,
+	}, nil
+}
+
+func (s *qrScheme) NewCommitter() Committer {
+	committer := df.NewCommitter(s.params.N, s.params.G, s.params.H, s.params.N,
+		s.params.SecParam)
+	return &qrCommitter{
+		Committer: committer,
+		prover:    df.NewOpeningProver(committer, s.params.ChallengeSpace),
+	}
+}
+
+func (s *qrScheme) VerifyOpening(c *big.Int, proof *OpeningProof) bool {
+	if c == nil || !proof.complete() {
+		return false
+	}
+	receiver := df.NewPublicReceiver(s.params.N, s.params.G, s.params.H,
+		s.params.SecParam)
+	receiver.SetCommitment(c)
+	verifier := df.NewOpeningVerifier(receiver, s.params.ChallengeSpace)
+	verifier.SetProofRandomData(proof.ProofRandomData)
+	verifier.SetChallenge(proof.Challenge)
+
+	return verifier.Verify(proof.ProofData1, proof.ProofData2)
+}
+
+func (s *qrScheme) BitLen() int {
+	return s.params.N.BitLen()
+}
+
+type qrCommitter struct {
+	*df.Committer
+	prover *df.OpeningProver
+}
+
+func (c *qrCommitter) GetProofRandomData() *big.Int {
+	return c.prover.GetProofRandomData()
+}
+
+func (c *qrCommitter) GetProofData(challenge *big.Int) (*big.Int, *big.Int) {
+	return c.prover.GetProofData(challenge)
+}
+
+// DFCommitter returns the Damgard-Fujisaki committer that c wraps, if c is a committer
+// of SchemeQR, for proofs that are specific to these commitments, such as range proofs.
+func DFCommitter(c Committer) (*df.Committer, bool) {
+	qc, ok := c.(*qrCommitter)
+	if !ok {
+		return nil, false
+	}
+	return qc.Committer, true
+}
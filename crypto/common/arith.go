@@ -0,0 +1,122 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package common
+
+import (
+	"crypto/rand"
+	"log"
+	"math/big"
+	"sync"
+)
+
+// intPool holds big.Int values used as temporaries of modular arithmetic. Reusing
+// them (and their backing arrays) instead of allocating new values on each
+// operation considerably reduces pressure on the garbage collector when many
+// proofs are generated or verified.
+var intPool = sync.Pool{
+	New: func() interface{} {
+		return new(big.Int)
+	},
+}
+
+// GetInt returns a big.Int of undefined value from the pool. It is meant for
+// temporary values, which are to be returned to the pool with PutInt.
+func GetInt() *big.Int {
+	return intPool.Get().(*big.Int)
+}
+
+// PutInt returns xs to the pool. None of them may be used afterwards.
+func PutInt(xs ...*big.Int) {
+	for _, x := range xs {
+		intPool.Put(x)
+	}
+}
+
+// MulMod sets z to x * y mod m for m > 0 and returns z. z may be the same as
+// x or y.
+func MulMod(z, x, y, m *big.Int) *big.Int {
+	t := GetInt()
+	q := GetInt()
+	t.Mul(x, y)
+	q.QuoRem(t, m, z)
+	if z.Sign() < 0 {
+		z.Add(z, m)
+	}
+	PutInt(t, q)
+
+	return z
+}
+
+// ExpMod sets z to x^y mod m and returns z. Negative y are supported; if x is
+// not invertible modulo m in that case, nil is returned. z may be the same as
+// x or y.
+func ExpMod(z, x, y, m *big.Int) *big.Int {
+	if y.Sign() >= 0 {
+		return z.Exp(x, y, m)
+	}
+
+	t := GetInt()
+	t.Neg(y)
+	z.Exp(x, t, m)
+	PutInt(t)
+
+	return z.ModInverse(z, m)
+}
+
+// blindingBitLen is the bit length of random multiples of the order that
+// BlindedExpMod adds to secret exponents.
+const blindingBitLen = 64
+
+// blindingBound is 2^(blindingBitLen-1).
+var blindingBound = new(big.Int).Lsh(big.NewInt(1), blindingBitLen-1)
+
+// BlindedExpMod sets z to x^y mod m for a secret exponent y and returns z. order
+// must be a multiple of the order of x modulo m, for example the order of the
+// group x belongs to. Negative y are supported.
+//
+// Exponentiation in math/big is not constant-time: its duration depends on the
+// bit length of the exponent and varies with its bits. Instead of y, the
+// exponent y mod order + k * order with a fresh random k of blindingBitLen bits
+// is used, which gives the same result, but makes the bit length independent of
+// y and the bits different on each call. Thus the timing of repeated
+// exponentiations with the same secret (a secret key or a master secret) can not
+// be accumulated to learn it. z may be the same as x or y.
+func BlindedExpMod(z, x, y, order, m *big.Int) *big.Int {
+	b := GetInt()
+	defer PutInt(b)
+
+	return z.Exp(x, BlindExponent(b, y, order), m)
+}
+
+// BlindExponent sets z to y mod order + k * order for a fresh random k of
+// blindingBitLen bits and returns z. It blinds secret exponents (see
+// BlindedExpMod) of operations other than modular exponentiation, such as scalar
+// multiplication on elliptic curves. z may be the same as y.
+func BlindExponent(z, y, order *big.Int) *big.Int {
+	// k is drawn from crypto/rand instead of the source set by SetRandReader, as it
+	// does not affect the result; runs with a deterministic source (like test
+	// vectors) thus draw the same values as without blinding.
+	k, err := rand.Int(rand.Reader, blindingBound)
+	if err != nil {
+		log.Fatal(err)
+	}
+	k.Add(k, blindingBound) // of exactly blindingBitLen bits
+	z.Mod(y, order)
+
+	return z.Add(z, k.Mul(k, order))
+}
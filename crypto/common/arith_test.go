@@ -0,0 +1,113 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package common
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMulMod(t *testing.T) {
+	m := GetRandomIntOfLength(256)
+	x, y := GetRandomIntAlsoNeg(m), GetRandomIntAlsoNeg(m)
+	expected := new(big.Int).Mul(x, y)
+	expected.Mod(expected, m)
+
+	assert.Equal(t, expected, MulMod(new(big.Int), x, y, m))
+	assert.Equal(t, expected, MulMod(x, x, y, m), "z should be allowed to be x")
+}
+
+func TestExpMod(t *testing.T) {
+	m, _ := GetSafePrime(128)
+	x := GetRandomInt(m)
+	y := GetRandomInt(m)
+	expected := new(big.Int).Exp(x, y, m)
+	assert.Equal(t, expected, ExpMod(new(big.Int), x, y, m))
+
+	yNeg := new(big.Int).Neg(y)
+	expected.ModInverse(expected, m)
+	assert.Equal(t, expected, ExpMod(new(big.Int), x, yNeg, m))
+	assert.Equal(t, expected, ExpMod(yNeg, x, yNeg, m), "z should be allowed to be y")
+
+	assert.Nil(t, ExpMod(new(big.Int), new(big.Int).Mul(m, big.NewInt(2)), big.NewInt(-1), m),
+		"non-invertible base should not be accepted")
+}
+
+func TestMultiExp(t *testing.T) {
+	m, _ := GetSafePrime(128)
+	bases := []*big.Int{GetRandomInt(m), GetRandomInt(m), GetRandomInt(m)}
+	exps := []*big.Int{GetRandomIntAlsoNeg(m), GetRandomInt(m), big.NewInt(0)}
+	expected := big.NewInt(1)
+	for i := range bases {
+		expected.Mul(expected, Exponentiate(bases[i], exps[i], m))
+		expected.Mod(expected, m)
+	}
+	assert.Equal(t, expected, MultiExp(new(big.Int), bases, exps, m))
+	assert.Equal(t, big.NewInt(1), MultiExp(new(big.Int), nil, nil, m))
+
+	bases[0] = new(big.Int).Set(m)
+	exps[0] = big.NewInt(-2)
+	assert.Nil(t, MultiExp(new(big.Int), bases, exps, m))
+}
+
+func BenchmarkMultiExp(b *testing.B) {
+	m := GetRandomIntOfLength(2048)
+	bases := make([]*big.Int, 8)
+	exps := make([]*big.Int, len(bases))
+	for i := range bases {
+		bases[i] = GetRandomInt(m)
+		exps[i] = GetRandomIntOfLength(512)
+	}
+	z := new(big.Int)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MultiExp(z, bases, exps, m)
+	}
+}
+
+func TestBlindedExpMod(t *testing.T) {
+	p, _ := GetSafePrime(128)
+	q := new(big.Int).Rsh(p, 1) // order of quadratic residues modulo p
+	x := GetRandomInt(p)
+	x.Mul(x, x).Mod(x, p)
+	y := GetRandomInt(q)
+	expected := new(big.Int).Exp(x, y, p)
+
+	assert.Equal(t, expected, BlindedExpMod(new(big.Int), x, y, q, p))
+	assert.Equal(t, expected, BlindedExpMod(new(big.Int), x, new(big.Int).Add(y, q), q, p),
+		"exponents should be reduced modulo order")
+	assert.Equal(t, expected, BlindedExpMod(y, x, y, q, p), "z should be allowed to be y")
+
+	yNeg := GetRandomInt(q)
+	expected.ModInverse(new(big.Int).Exp(x, yNeg, p), p)
+	assert.Equal(t, expected, BlindedExpMod(new(big.Int), x, yNeg.Neg(yNeg), q, p))
+}
+
+func TestBlindExponent(t *testing.T) {
+	q := GetRandomIntOfLength(128)
+	y := GetRandomInt(q)
+	z := BlindExponent(new(big.Int), y, q)
+
+	assert.Equal(t, y, new(big.Int).Mod(z, q))
+	assert.True(t, z.BitLen() >= q.BitLen()+blindingBitLen-1, "bit length should not depend on y")
+	assert.NotEqual(t, z, BlindExponent(new(big.Int), y, q), "exponents should differ on each call")
+}
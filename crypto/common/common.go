@@ -18,7 +18,10 @@
 package common
 
 import (
+	"crypto"
+	"crypto/rand"
 	"crypto/sha512"
+	"encoding/asn1"
 	"math/big"
 )
 
@@ -49,13 +52,8 @@ func Hash(numbers ...*big.Int) *big.Int {
 
 // It computes x^y mod m. Negative y are supported.
 func Exponentiate(x, y, m *big.Int) *big.Int {
-	var r *big.Int
-	if y.Cmp(big.NewInt(0)) >= 0 {
-		r = new(big.Int).Exp(x, y, m)
-	} else {
-		r = new(big.Int).Exp(x, new(big.Int).Abs(y), m)
-		r.ModInverse(r, m)
-	}
+	r := new(big.Int)
+	ExpMod(r, x, y, m) // for non-invertible x and negative y, r is left as x^|y|
 	return r
 }
 
@@ -80,3 +78,18 @@ func Contains(arr []int, el int) bool {
 
 	return false
 }
+
+// SignECDSA signs digest with signer, which holds an ECDSA key (an *ecdsa.PrivateKey
+// or a key held by a crypto.KeyStore), and returns the signature as integers r and s.
+func SignECDSA(signer crypto.Signer, digest []byte) (*big.Int, *big.Int, error) {
+	sig, err := signer.Sign(rand.Reader, digest, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var rs struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(sig, &rs); err != nil {
+		return nil, nil, err
+	}
+
+	return rs.R, rs.S, nil
+}
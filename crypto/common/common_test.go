@@ -18,6 +18,7 @@
 package common
 
 import (
+	"bytes"
 	"math/big"
 	"testing"
 
@@ -30,3 +31,12 @@ func TestLCM(t *testing.T) {
 	lcm := LCM(a, b)
 	assert.Equal(t, lcm, big.NewInt(24), "LCM returned wrong value")
 }
+
+func TestRandReader(t *testing.T) {
+	r := bytes.NewReader(make([]byte, 64))
+	prev := SetRandReader(r)
+	defer SetRandReader(prev)
+
+	assert.Equal(t, r, RandReader())
+	assert.Zero(t, GetRandomInt(big.NewInt(1000)).Sign(), "should draw from r")
+}
@@ -0,0 +1,26 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package common
+
+import "errors"
+
+// ErrNotInGroup is returned (possibly wrapped) when a value received from another
+// party is not an element of the group it is expected to belong to. Such values
+// must never be used in computations, as they may leak secrets (for example in
+// small subgroup attacks) or make proofs about them trivial.
+var ErrNotInGroup = errors.New("not an element of the group")
@@ -0,0 +1,234 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package common
+
+import (
+	"math/big"
+	"math/bits"
+	"runtime"
+	"sync"
+)
+
+const (
+	// strausWindow is the width (in bits) of exponent windows in Straus' method.
+	// Each base gets a table of 2^strausWindow - 1 precomputed powers.
+	strausWindow = 4
+
+	// pippengerWindow is the width (in bits) of exponent windows in Pippenger's
+	// bucket method.
+	pippengerWindow = 6
+
+	// pippengerThreshold is the number of bases from which Pippenger's bucket
+	// method is used instead of Straus' method. Below it precomputed tables of
+	// Straus' method are cheaper than the buckets of Pippenger's method.
+	pippengerThreshold = 128
+
+	// parallelChunk is the minimal number of bases handled by one goroutine, so
+	// that squarings are still shared when a multi-exponentiation is split.
+	parallelChunk = 2
+)
+
+// MultiExp sets z to bases[0]^exps[0] * ... * bases[k-1]^exps[k-1] mod m and
+// returns z. Negative exponents are supported; if the corresponding base is not
+// invertible modulo m, nil is returned. z must not be any of the bases or exps.
+//
+// Instead of computing each power separately, squarings are shared among all
+// bases by processing exponents in windows (Straus' method, or Pippenger's
+// bucket method for many bases). With enough bases, the product is split into
+// chunks that are computed in parallel, one goroutine per available CPU.
+func MultiExp(z *big.Int, bases, exps []*big.Int, m *big.Int) *big.Int {
+	bases, exps, ok := nonNegativeExps(bases, exps, m)
+	if !ok {
+		return nil
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if max := len(bases) / parallelChunk; workers > max {
+		workers = max
+	}
+	if workers < 2 {
+		return multiExp(z, bases, exps, m)
+	}
+
+	partial := make([]*big.Int, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		from, to := i*len(bases)/workers, (i+1)*len(bases)/workers
+		partial[i] = new(big.Int)
+		wg.Add(1)
+		go func(p *big.Int, bases, exps []*big.Int) {
+			defer wg.Done()
+			multiExp(p, bases, exps, m)
+		}(partial[i], bases[from:to], exps[from:to])
+	}
+	wg.Wait()
+
+	z.SetInt64(1)
+	for _, p := range partial {
+		MulMod(z, z, p, m)
+	}
+
+	return z
+}
+
+// nonNegativeExps returns bases and exponents with the same product of powers
+// modulo m, where negative exponents are replaced by their absolute values and
+// the corresponding bases by their inverses. Input slices are not modified.
+// False is returned if a base with a negative exponent is not invertible.
+func nonNegativeExps(bases, exps []*big.Int, m *big.Int) ([]*big.Int, []*big.Int, bool) {
+	var bs, es []*big.Int
+	for i, e := range exps {
+		if e.Sign() >= 0 {
+			continue
+		}
+		if bs == nil {
+			bs = append([]*big.Int(nil), bases...)
+			es = append([]*big.Int(nil), exps...)
+		}
+		bs[i] = new(big.Int).ModInverse(bases[i], m)
+		if bs[i] == nil {
+			return nil, nil, false
+		}
+		es[i] = new(big.Int).Neg(e)
+	}
+	if bs == nil {
+		return bases, exps, true
+	}
+
+	return bs, es, true
+}
+
+// multiExp sets z to the product of powers for non-negative exps and returns z.
+func multiExp(z *big.Int, bases, exps []*big.Int, m *big.Int) *big.Int {
+	switch {
+	case len(bases) == 1:
+		return z.Exp(bases[0], exps[0], m)
+	case len(bases) < pippengerThreshold:
+		return straus(z, bases, exps, m)
+	default:
+		return pippenger(z, bases, exps, m)
+	}
+}
+
+// straus sets z to the product of powers for non-negative exps using Straus'
+// method and returns z: powers base^1, ..., base^(2^w - 1) are precomputed for
+// each base, and for each window of exponents (from the most significant one)
+// the accumulator is raised to 2^w and multiplied by the powers that correspond
+// to the windows' values.
+func straus(z *big.Int, bases, exps []*big.Int, m *big.Int) *big.Int {
+	const size = 1 << strausWindow
+	tables := make([][size]*big.Int, len(bases))
+	for i, b := range bases {
+		if exps[i].Sign() == 0 {
+			continue
+		}
+		tables[i][1] = new(big.Int).Mod(b, m)
+		for j := 2; j < size; j++ {
+			tables[i][j] = MulMod(new(big.Int), tables[i][j-1], tables[i][1], m)
+		}
+	}
+
+	z.SetInt64(1)
+	for w := numWindows(exps, strausWindow) - 1; w >= 0; w-- {
+		for s := 0; s < strausWindow; s++ {
+			MulMod(z, z, z, m)
+		}
+		for i, e := range exps {
+			if d := window(e, w, strausWindow); d != 0 {
+				MulMod(z, z, tables[i][d], m)
+			}
+		}
+	}
+
+	return z.Mod(z, m)
+}
+
+// pippenger sets z to the product of powers for non-negative exps using
+// Pippenger's bucket method and returns z: for each window of exponents, bases
+// are multiplied into buckets by the windows' values, and the buckets are
+// combined as bucket[1]^1 * ... * bucket[2^w - 1]^(2^w - 1) with a running
+// product, which needs no precomputed powers of bases.
+func pippenger(z *big.Int, bases, exps []*big.Int, m *big.Int) *big.Int {
+	const size = 1 << pippengerWindow
+	var buckets [size]*big.Int
+	running := new(big.Int)
+	sum := new(big.Int)
+
+	z.SetInt64(1)
+	for w := numWindows(exps, pippengerWindow) - 1; w >= 0; w-- {
+		for s := 0; s < pippengerWindow; s++ {
+			MulMod(z, z, z, m)
+		}
+
+		for i := range buckets {
+			buckets[i] = nil
+		}
+		for i, e := range exps {
+			d := window(e, w, pippengerWindow)
+			if d == 0 {
+				continue
+			}
+			if buckets[d] == nil {
+				buckets[d] = new(big.Int).Mod(bases[i], m)
+			} else {
+				MulMod(buckets[d], buckets[d], bases[i], m)
+			}
+		}
+
+		running.SetInt64(1)
+		sum.SetInt64(1)
+		for d := size - 1; d > 0; d-- {
+			if buckets[d] != nil {
+				MulMod(running, running, buckets[d], m)
+			}
+			MulMod(sum, sum, running, m)
+		}
+		MulMod(z, z, sum, m)
+	}
+
+	return z.Mod(z, m)
+}
+
+// numWindows returns the number of windows of the given width needed to cover
+// the longest of exps.
+func numWindows(exps []*big.Int, width int) int {
+	maxLen := 0
+	for _, e := range exps {
+		if l := e.BitLen(); l > maxLen {
+			maxLen = l
+		}
+	}
+
+	return (maxLen + width - 1) / width
+}
+
+// window returns the value of bits [w*width, (w+1)*width) of a non-negative e.
+func window(e *big.Int, w, width int) int {
+	words := e.Bits()
+	pos := w * width
+	i, off := pos/bits.UintSize, uint(pos%bits.UintSize)
+	if i >= len(words) {
+		return 0
+	}
+	d := uint(words[i]) >> off
+	if off+uint(width) > bits.UintSize && i+1 < len(words) {
+		d |= uint(words[i+1]) << (bits.UintSize - off)
+	}
+
+	return int(d & (1<<uint(width) - 1))
+}
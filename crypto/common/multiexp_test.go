@@ -0,0 +1,117 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package common
+
+import (
+	"fmt"
+	"math/big"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// naiveMultiExp computes the product of powers with one exponentiation per base.
+func naiveMultiExp(bases, exps []*big.Int, m *big.Int) *big.Int {
+	z := big.NewInt(1)
+	t := new(big.Int)
+	for i := range bases {
+		if ExpMod(t, bases[i], exps[i], m) == nil {
+			return nil
+		}
+		MulMod(z, z, t, m)
+	}
+
+	return z
+}
+
+func randomMultiExp(m *big.Int, n, expBitLen int) ([]*big.Int, []*big.Int) {
+	bases := make([]*big.Int, n)
+	exps := make([]*big.Int, n)
+	for i := range bases {
+		bases[i] = GetRandomZnInvertibleElement(m)
+		exps[i] = GetRandomIntOfLength(expBitLen)
+		if i%3 == 1 {
+			exps[i].Neg(exps[i])
+		}
+	}
+
+	return bases, exps
+}
+
+func TestMultiExpMethods(t *testing.T) {
+	m, _ := GetSafePrime(128)
+	for _, n := range []int{1, 2, 7, pippengerThreshold + 3} {
+		bases, exps := randomMultiExp(m, n, 300)
+		exps[0] = big.NewInt(0)
+		expected := naiveMultiExp(bases, exps, m)
+
+		assert.Equal(t, expected, MultiExp(new(big.Int), bases, exps, m), "%d bases", n)
+		bs, es, ok := nonNegativeExps(bases, exps, m)
+		assert.True(t, ok)
+		assert.Equal(t, expected, straus(new(big.Int), bs, es, m), "Straus, %d bases", n)
+		assert.Equal(t, expected, pippenger(new(big.Int), bs, es, m), "Pippenger, %d bases", n)
+	}
+}
+
+func TestMultiExpParallel(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+
+	m := GetRandomIntOfLength(512)
+	for _, n := range []int{3, 4, 9, 40} {
+		bases, exps := randomMultiExp(m, n, 160)
+		bases[n-1] = new(big.Int).Add(m, big.NewInt(5)) // bases need not be reduced
+		assert.Equal(t, naiveMultiExp(bases, exps, m), MultiExp(new(big.Int), bases, exps, m),
+			"%d bases", n)
+	}
+
+	bases, exps := randomMultiExp(m, 10, 160)
+	bases[7] = new(big.Int).Mul(m, big.NewInt(3))
+	exps[7] = big.NewInt(-1)
+	assert.Nil(t, MultiExp(new(big.Int), bases, exps, m),
+		"non-invertible base should not be accepted")
+}
+
+func TestMultiExpKeepsInput(t *testing.T) {
+	m, _ := GetSafePrime(128)
+	bases, exps := randomMultiExp(m, 5, 100)
+	exps[1] = big.NewInt(-12345)
+	bases1, exps1 := new(big.Int).Set(bases[1]), new(big.Int).Set(exps[1])
+
+	MultiExp(new(big.Int), bases, exps, m)
+	assert.Equal(t, bases1, bases[1])
+	assert.Equal(t, exps1, exps[1])
+}
+
+func BenchmarkMultiExpBases(b *testing.B) {
+	m := GetRandomIntOfLength(2048)
+	for _, n := range []int{4, 16, 64, 256} {
+		bases, exps := randomMultiExp(m, n, 600)
+		z := new(big.Int)
+		b.Run(fmt.Sprintf("naive-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				naiveMultiExp(bases, exps, m)
+			}
+		})
+		b.Run(fmt.Sprintf("multiexp-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				MultiExp(z, bases, exps, m)
+			}
+		})
+	}
+}
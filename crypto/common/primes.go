@@ -18,15 +18,20 @@
 package common
 
 import (
-	"crypto/rand"
+	"context"
 	"fmt"
 	"io"
 	"math/big"
+	"runtime"
+	"sync"
 )
 
 // GetSafePrime returns a safe prime p (p = 2*p1 + 2 where p1 is prime too).
 func GetSafePrime(bits int) (p *big.Int, err error) {
 	p1 := GetGermainPrime(bits - 1)
+	if p1 == nil {
+		return nil, fmt.Errorf("prime size must be at least 3-bit")
+	}
 	p = big.NewInt(0)
 	p.Mul(p1, big.NewInt(2))
 	p.Add(p, big.NewInt(1))
@@ -39,22 +44,209 @@ func GetSafePrime(bits int) (p *big.Int, err error) {
 	}
 }
 
+// GetRandomPrime returns a random prime of exactly the given bit length. As opposed
+// to crypto/rand.Prime, it draws randomness from the source set by SetRandReader.
+func GetRandomPrime(bits int) (*big.Int, error) {
+	if bits < 2 {
+		return nil, fmt.Errorf("prime size must be at least 2-bit")
+	}
+	min := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+	for {
+		p := GetRandomInt(min) // p from [2^(bits-1), 2^bits)
+		p.Add(p, min)
+		p.SetBit(p, 0, 1)
+		if p.ProbablyPrime(20) {
+			return p, nil
+		}
+	}
+}
+
 // GetGermainPrime returns a prime number p for which 2*p + 1 is also prime. Note that conversely p
-// is called safe prime.
+// is called safe prime. The search runs on all available cores, see PrimeSearch.
 func GetGermainPrime(bits int) (p *big.Int) {
-	// multiple germainPrime goroutines are called and we assume at least one will compute a
-	// safe prime and send it to the channel, thus we do not handle errors in germainPrime
-	var c chan *big.Int = make(chan *big.Int)
-	var quit chan int = make(chan int)
-	for j := int(0); j < 8; j++ {
-		go germainPrime(bits, c, quit)
-	}
-	msg := <-c
-	// for small values for parameter bits (which should be small only for testing) it sometimes
-	// happen "send on closed channel" - so we leave the channel c to a garbage collector
-	//close(c)
-	close(quit)
-	return msg
+	primes, err := new(PrimeSearch).GermainPrimes(context.Background(), bits, 1)
+	if err != nil {
+		return nil
+	}
+	return primes[0]
+}
+
+// progressInterval is the number of tested candidates between two calls
+// of PrimeSearch.Progress.
+const progressInterval = 256
+
+// PrimeSearch searches for Germain primes concurrently on several cores. A
+// zero PrimeSearch is ready to use. Counters of tested candidates and found
+// primes accumulate over all searches with the same PrimeSearch, so a single
+// one can report progress of generating parameters that require several primes.
+type PrimeSearch struct {
+	// Workers is the number of goroutines searching concurrently. If it is not
+	// positive, runtime.NumCPU() goroutines are used.
+	Workers int
+	// Progress, if not nil, is called with the number of candidates tested and
+	// primes found so far, every few hundred candidates and whenever a prime is
+	// found. Calls are serialized.
+	Progress func(tested int64, found int)
+
+	mu     sync.Mutex
+	tested int64
+	found  int
+}
+
+// GermainPrimes returns n distinct Germain primes of the given bit length. If
+// ctx is done before they are found, the search is stopped and ctx.Err() is
+// returned. All searching goroutines have exited when GermainPrimes returns.
+func (s *PrimeSearch) GermainPrimes(ctx context.Context, bits, n int) ([]*big.Int, error) {
+	if bits < 2 {
+		return nil, fmt.Errorf("prime size must be at least 2-bit")
+	}
+
+	return s.search(ctx, n, func(ctx context.Context) (*big.Int, error) {
+		return germainPrime(ctx, bits, s)
+	})
+}
+
+// Primes returns n distinct random primes of the given bit length, like
+// GermainPrimes.
+func (s *PrimeSearch) Primes(ctx context.Context, bits, n int) ([]*big.Int, error) {
+	if bits < 2 {
+		return nil, fmt.Errorf("prime size must be at least 2-bit")
+	}
+
+	return s.search(ctx, n, func(ctx context.Context) (*big.Int, error) {
+		return s.prime(ctx, func() *big.Int {
+			p := GetRandomIntOfLength(bits)
+			return p.SetBit(p, 0, 1)
+		})
+	})
+}
+
+// SchnorrPrime returns a random prime p of the given bit length such that q
+// divides p-1, which is the modulus of a Schnorr group of order q. The search is
+// stopped like in GermainPrimes.
+func (s *PrimeSearch) SchnorrPrime(ctx context.Context, q *big.Int, bits int) (*big.Int, error) {
+	// candidates are p = 2*k*q + 1 for k from [2^(bits-1) / 2q + 1, (2^bits - 1) / 2q)
+	q2 := new(big.Int).Lsh(q, 1)
+	min := new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+	min.Div(min, q2).Add(min, big.NewInt(1))
+	max := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	max.Sub(max, big.NewInt(1)).Div(max, q2)
+	if min.Cmp(max) >= 0 {
+		return nil, fmt.Errorf("prime size must be at least 2 bits greater than the size of q")
+	}
+
+	primes, err := s.search(ctx, 1, func(ctx context.Context) (*big.Int, error) {
+		return s.prime(ctx, func() *big.Int {
+			p, _ := GetRandomIntFromRange(min, max)
+			p.Mul(p, q2)
+			return p.Add(p, big.NewInt(1))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return primes[0], nil
+}
+
+// search runs find on s.Workers goroutines until n distinct primes are found
+// or ctx is done.
+func (s *PrimeSearch) search(ctx context.Context, n int,
+	find func(context.Context) (*big.Int, error)) ([]*big.Int, error) {
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	c := make(chan *big.Int)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				p, err := find(ctx)
+				if err != nil {
+					return
+				}
+				select {
+				case c <- p:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	primes := make([]*big.Int, 0, n)
+	for len(primes) < n {
+		select {
+		case p := <-c:
+			if containsInt(primes, p) {
+				continue
+			}
+			primes = append(primes, p)
+			s.report(0, 1)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return primes, nil
+}
+
+// prime returns the first prime among values returned by candidate. Tested
+// candidates are reported to s, and ctx.Err() is returned once ctx is done.
+func (s *PrimeSearch) prime(ctx context.Context, candidate func() *big.Int) (*big.Int, error) {
+	tested := int64(0)
+	defer func() {
+		if tested > 0 {
+			s.report(tested, 0)
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		tested++
+		if tested == progressInterval {
+			s.report(tested, 0)
+			tested = 0
+		}
+
+		if p := candidate(); p.ProbablyPrime(20) {
+			return p, nil
+		}
+	}
+}
+
+// report adds tested candidates and found primes to the counters of s and
+// calls s.Progress.
+func (s *PrimeSearch) report(tested int64, found int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tested += tested
+	s.found += found
+	if s.Progress != nil {
+		s.Progress(s.tested, s.found)
+	}
+}
+
+func containsInt(l []*big.Int, x *big.Int) bool {
+	for _, y := range l {
+		if y.Cmp(x) == 0 {
+			return true
+		}
+	}
+	return false
 }
 
 var smallPrimes = []uint8{
@@ -71,14 +263,16 @@ var smallPrimesProduct = new(big.Int).SetUint64(16294579238595022365)
 // https://github.com/golang/go/blob/master/src/crypto/rand/util.go
 // germainPrime returns a number, p, of the given size, such that p and 2*p+1 are primes
 // with high probability.
-// germainPrime will return error for any error returned by rand.Read or if bits < 2.
-func germainPrime(bits int, c chan *big.Int, quit chan int) (p *big.Int, err error) {
-	rand := rand.Reader
-
-	if bits < 2 {
-		err = fmt.Errorf("crypto/rand: prime size must be at least 2-bit")
-		return
-	}
+// germainPrime will return error for any error returned by rand.Read, or ctx.Err() when
+// ctx is done before a prime is found. Tested candidates are reported to s.
+func germainPrime(ctx context.Context, bits int, s *PrimeSearch) (p *big.Int, err error) {
+	rand := randReader
+	tested := int64(0)
+	defer func() {
+		if tested > 0 {
+			s.report(tested, 0)
+		}
+	}()
 
 	b := uint(bits % 8)
 	if b == 0 {
@@ -93,12 +287,18 @@ func germainPrime(bits int, c chan *big.Int, quit chan int) (p *big.Int, err err
 
 	for {
 		select {
-		case <-quit:
-			return
+		case <-ctx.Done():
+			return nil, ctx.Err()
 		default:
 			// this is to make it non-blocking
 		}
 
+		tested++
+		if tested == progressInterval {
+			s.report(tested, 0)
+			tested = 0
+		}
+
 		_, err = io.ReadFull(rand, bytes)
 		if err != nil {
 			return nil, err
@@ -162,20 +362,7 @@ func germainPrime(bits int, c chan *big.Int, quit chan int) (p *big.Int, err err
 		// here.
 		if p.ProbablyPrime(20) && p.BitLen() == bits {
 			if p1.ProbablyPrime(20) {
-				// waiting for a message about channel being closed is repeated here,
-				// because it might happen that channel is closed after waiting at the
-				// beginning of for loop above (but we want to have it there also,
-				// otherwise it this goroutine might be searching for a germain
-				// prime for some time after one was found by another goroutine
-				select {
-				case <-quit:
-					return
-				default:
-					// this is to make it non-blocking
-				}
-
-				c <- p
-				return
+				return p, nil
 			}
 		}
 	}
@@ -18,8 +18,10 @@
 package common
 
 import (
+	"context"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -45,3 +47,89 @@ func TestGetSafePrime(t *testing.T) {
 	assert.Equal(t, p.ProbablyPrime(20), true, "p should be prime")
 	assert.Equal(t, p1.ProbablyPrime(20), true, "p1 should be prime")
 }
+
+func TestGetRandomPrime(t *testing.T) {
+	for _, bits := range []int{2, 16, 512} {
+		p, err := GetRandomPrime(bits)
+		if err != nil {
+			t.Errorf("Error in GetRandomPrime: %v", err)
+		}
+		assert.Equal(t, bits, p.BitLen(), "p should be of the given length")
+		assert.Equal(t, p.ProbablyPrime(20), true, "p should be prime")
+	}
+
+	_, err := GetRandomPrime(1)
+	assert.Error(t, err)
+}
+
+func TestPrimeSearch(t *testing.T) {
+	calls := 0
+	var lastFound int
+	s := &PrimeSearch{
+		Workers: 2,
+		Progress: func(tested int64, found int) {
+			calls++
+			lastFound = found
+		},
+	}
+	primes, err := s.GermainPrimes(context.Background(), 128, 3)
+	if err != nil {
+		t.Errorf("Error in GermainPrimes: %v", err)
+	}
+	assert.Equal(t, 3, len(primes))
+	for i, p := range primes {
+		p1 := new(big.Int).Add(p, p)
+		p1.Add(p1, big.NewInt(1))
+		assert.Equal(t, 128, p.BitLen(), "p should be of the given length")
+		assert.Equal(t, p.ProbablyPrime(20), true, "p should be prime")
+		assert.Equal(t, p1.ProbablyPrime(20), true, "2*p+1 should be prime")
+		for _, q := range primes[:i] {
+			assert.NotEqual(t, q, p, "primes should be distinct")
+		}
+	}
+	assert.True(t, calls >= 3, "progress should be reported")
+	assert.Equal(t, 3, lastFound)
+
+	_, err = s.GermainPrimes(context.Background(), 1, 1)
+	assert.Error(t, err)
+}
+
+func TestPrimeSearchPrimes(t *testing.T) {
+	s := &PrimeSearch{Workers: 2}
+	primes, err := s.Primes(context.Background(), 256, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(primes))
+	assert.NotEqual(t, primes[0], primes[1], "primes should be distinct")
+	for _, p := range primes {
+		assert.Equal(t, 256, p.BitLen(), "p should be of the given length")
+		assert.True(t, p.ProbablyPrime(20), "p should be prime")
+	}
+
+	_, err = s.Primes(context.Background(), 1, 1)
+	assert.Error(t, err)
+}
+
+func TestPrimeSearchSchnorrPrime(t *testing.T) {
+	s := &PrimeSearch{Workers: 2}
+	q, err := GetRandomPrime(64)
+	assert.NoError(t, err)
+	p, err := s.SchnorrPrime(context.Background(), q, 512)
+	assert.NoError(t, err)
+	assert.Equal(t, 512, p.BitLen(), "p should be of the given length")
+	assert.True(t, p.ProbablyPrime(20), "p should be prime")
+	assert.Equal(t, 0, new(big.Int).Mod(new(big.Int).Sub(p, big.NewInt(1)), q).Sign(),
+		"q should divide p-1")
+
+	_, err = s.SchnorrPrime(context.Background(), q, 65)
+	assert.Error(t, err)
+}
+
+func TestPrimeSearchCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := new(PrimeSearch).GermainPrimes(ctx, 4096, 1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < 5*time.Second, "search should stop once ctx is done")
+}
@@ -20,13 +20,35 @@ package common
 import (
 	"crypto/rand"
 	"fmt"
+	"io"
 	"log"
 	"math/big"
 )
 
+// randReader is the source of randomness for all the functions of this package.
+var randReader io.Reader = rand.Reader
+
+// SetRandReader replaces the source of randomness used by the functions of this
+// package with r and returns the previous source. r can be a hardware RNG or a
+// DRBG, preferably wrapped with rng.New so that its output is health tested, or a
+// deterministic reader for generating test vectors - any r other than a
+// cryptographically secure reader breaks the security of all the protocols built on
+// this package. r needs to be safe for concurrent use.
+func SetRandReader(r io.Reader) io.Reader {
+	prev := randReader
+	randReader = r
+	return prev
+}
+
+// RandReader returns the source of randomness set by SetRandReader, so that random
+// values drawn outside this package, such as session keys, come from it as well.
+func RandReader() io.Reader {
+	return randReader
+}
+
 // GetRandomInt returns random integer from [0, max).
 func GetRandomInt(max *big.Int) *big.Int {
-	n, err := rand.Int(rand.Reader, max)
+	n, err := rand.Int(randReader, max)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -18,6 +18,7 @@
 package df
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -116,7 +117,14 @@ type Receiver struct {
 // the hiding property (commitment c = G^a * H^r where r is chosen randomly from (0, 2^(B+k)) - the distribution of
 // c is statistically close to uniform, 2^B is upper bound estimation for group order).
 func NewReceiver(safePrimeBitLength, k int) (*Receiver, error) {
-	qr, err := qr.NewRSASpecial(safePrimeBitLength)
+	return NewReceiverContext(context.Background(), safePrimeBitLength, k, nil)
+}
+
+// NewReceiverContext is like NewReceiver, but the search for primes of RSASpecial
+// group can be cancelled with ctx and reports progress to s (if not nil).
+func NewReceiverContext(ctx context.Context, safePrimeBitLength, k int,
+	s *common.PrimeSearch) (*Receiver, error) {
+	qr, err := qr.NewRSASpecialContext(ctx, safePrimeBitLength, s)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +167,17 @@ func NewReceiverFromParams(specialRSAPrimes *qr.RSASpecialPrimes, g, h *big.Int,
 	}, nil
 }
 
+// NewPublicReceiver returns a receiver in the group with modulus n, whose factorization
+// is not known. It can verify proofs about commitments, but not create them.
+func NewPublicReceiver(n, g, h *big.Int, k int) *Receiver {
+	return &Receiver{df: df{
+		QRSpecialRSA: qr.NewRSApecialPublic(n),
+		G:            g,
+		H:            h,
+		K:            k},
+	}
+}
+
 // When receiver receives a commitment, it stores the value using SetCommitment method.
 func (r *Receiver) SetCommitment(c *big.Int) {
 	r.Commitment = c
@@ -171,14 +171,9 @@ func NewPositiveVerifier(receiver *Receiver,
 
 	receivers := make([]*Receiver, nRoots)
 	for i, comm := range bigCommitments {
-		receiver, err := NewReceiverFromParams(
-			receiver.QRSpecialRSA.GetPrimes(),
-			receiver.G, receiver.H, receiver.K)
-		if err != nil {
-			return nil, fmt.Errorf("error when calling NewReceiverFromParams")
-		}
-		receiver.SetCommitment(comm)
-		receivers[i] = receiver
+		// verifiers only need the public parameters of the receiver
+		receivers[i] = &Receiver{df: receiver.df}
+		receivers[i].SetCommitment(comm)
 	}
 
 	squareVerifiers := make([]*SquareVerifier, nRoots)
@@ -211,7 +206,7 @@ func (v *PositiveVerifier) SetChallenges(challenges []*big.Int) {
 }
 
 func (v *PositiveVerifier) SetProofRandomData(proofRandomData []*big.Int) error {
-	if len(proofRandomData) != 8 {
+	if len(proofRandomData) != 2*len(v.squareVerifiers) {
 		return fmt.Errorf("the length of proofRandomData is not correct")
 	}
 	for i, verifier := range v.squareVerifiers {
@@ -221,7 +216,7 @@ func (v *PositiveVerifier) SetProofRandomData(proofRandomData []*big.Int) error
 }
 
 func (v *PositiveVerifier) Verify(proofData []*big.Int) bool {
-	if len(proofData) != 12 {
+	if len(proofData) != 3*len(v.squareVerifiers) {
 		return false
 	}
 	verified := true
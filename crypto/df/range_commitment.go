@@ -21,6 +21,8 @@ import (
 	"math/big"
 
 	"fmt"
+
+	"github.com/xlab-si/emmy/crypto/common"
 )
 
 // RangeProver proves that the commitment hides a number x such that a <= x <= b.
@@ -164,3 +166,111 @@ func (v *RangeVerifier) SetChallenges(challenges1, challenges2 []*big.Int) {
 func (v *RangeVerifier) Verify(proofData1, proofData2 []*big.Int) (bool, error) {
 	return v.verifier1.Verify(proofData1) && v.verifier2.Verify(proofData2), nil
 }
+
+// RangeProofNI is a non-interactive proof that the commitment hides a number x such
+// that a <= x <= b. Challenges are derived by the prover via Fiat-Shamir, so the proof
+// can be verified without interaction. It holds the data that the interactive
+// RangeVerifier obtains with GetVerifierInitializationData.
+type RangeProofNI struct {
+	*RangeProof
+	SmallCommitments1 []*big.Int
+	BigCommitments1   []*big.Int
+	SmallCommitments2 []*big.Int
+	BigCommitments2   []*big.Int
+}
+
+// ProveRange returns a non-interactive proof that the commitment held by committer hides
+// x such that a <= x <= b. Context (for example a nonce of the verifier) is bound to the
+// proof to prevent its reuse in another context.
+func ProveRange(committer *Committer, x, a, b, context *big.Int,
+	challengeSpaceSize int) (*RangeProofNI, error) {
+	prover, err := NewRangeProver(committer, x, a, b, challengeSpaceSize)
+	if err != nil {
+		return nil, err
+	}
+
+	small1, big1, small2, big2 := prover.GetVerifierInitializationData()
+	proofRandomData1, proofRandomData2 := prover.GetProofRandomData()
+	commitment := committer.ComputeCommit(committer.GetDecommitMsg())
+	challenges1, challenges2 := rangeChallenges(commitment, a, b, context, small1, big1,
+		small2, big2, proofRandomData1, proofRandomData2, challengeSpaceSize)
+	proofData1, proofData2, err := prover.GetProofData(challenges1, challenges2)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RangeProofNI{
+		RangeProof: NewRangeProof(proofRandomData1, proofRandomData2, challenges1, challenges2,
+			proofData1, proofData2),
+		SmallCommitments1: small1,
+		BigCommitments1:   big1,
+		SmallCommitments2: small2,
+		BigCommitments2:   big2,
+	}, nil
+}
+
+// VerifyRange verifies a proof created with ProveRange that the commitment set in
+// receiver hides x such that a <= x <= b.
+func VerifyRange(receiver *Receiver, proof *RangeProofNI, a, b, context *big.Int,
+	challengeSpaceSize int) (bool, error) {
+	if proof == nil || proof.RangeProof == nil {
+		return false, fmt.Errorf("missing range proof")
+	}
+	verifier, err := NewRangeVerifier(receiver, a, b, proof.SmallCommitments1,
+		proof.BigCommitments1, proof.SmallCommitments2, proof.BigCommitments2,
+		challengeSpaceSize)
+	if err != nil {
+		return false, err
+	}
+	if err := verifier.SetProofRandomData(proof.ProofRandomData1,
+		proof.ProofRandomData2); err != nil {
+		return false, err
+	}
+
+	challenges1, challenges2 := rangeChallenges(receiver.Commitment, a, b, context,
+		proof.SmallCommitments1, proof.BigCommitments1, proof.SmallCommitments2,
+		proof.BigCommitments2, proof.ProofRandomData1, proof.ProofRandomData2,
+		challengeSpaceSize)
+	if !equalInts(challenges1, proof.Challenges1) || !equalInts(challenges2, proof.Challenges2) {
+		return false, fmt.Errorf("challenges are not correct")
+	}
+	verifier.SetChallenges(challenges1, challenges2)
+
+	return verifier.Verify(proof.ProofData1, proof.ProofData2)
+}
+
+// rangeChallenges derives challenges of the range proof by hashing its first messages,
+// one challenge for each square proof.
+func rangeChallenges(commitment, a, b, context *big.Int,
+	small1, big1, small2, big2, proofRandomData1, proofRandomData2 []*big.Int,
+	challengeSpaceSize int) ([]*big.Int, []*big.Int) {
+	input := []*big.Int{commitment, a, b, context}
+	for _, ints := range [][]*big.Int{small1, big1, small2, big2, proofRandomData1,
+		proofRandomData2} {
+		input = append(input, ints...)
+	}
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(challengeSpaceSize))
+
+	challenges := func(n int, offset int) []*big.Int {
+		c := make([]*big.Int, n)
+		for i := range c {
+			h := common.Hash(append(input, big.NewInt(int64(offset+i)))...)
+			c[i] = h.Mod(h, bound)
+		}
+		return c
+	}
+
+	return challenges(len(small1), 0), challenges(len(small2), len(small1))
+}
+
+func equalInts(a, b []*big.Int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if b[i] == nil || a[i].Cmp(b[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -79,3 +79,35 @@ func TestDFCommitmentRange(t *testing.T) {
 	}
 	assert.Equal(t, true, proved, "DamgardFujisaki range proof failed.")
 }
+
+func TestDFCommitmentRangeNonInteractive(t *testing.T) {
+	receiver, err := NewReceiver(128, 80)
+	if err != nil {
+		t.Errorf("error in NewReceiver: %v", err)
+	}
+	T := new(big.Int).Mul(receiver.QRSpecialRSA.N, receiver.QRSpecialRSA.N)
+	committer := NewCommitter(receiver.QRSpecialRSA.N,
+		receiver.G, receiver.H, T, receiver.K)
+
+	x := big.NewInt(42)
+	c, err := committer.GetCommitMsg(x)
+	if err != nil {
+		t.Errorf("error in computing commit msg: %v", err)
+	}
+	receiver.SetCommitment(c)
+
+	context := big.NewInt(1234)
+	proof, err := ProveRange(committer, x, big.NewInt(18), big.NewInt(150), context, 80)
+	if err != nil {
+		t.Fatalf("error in ProveRange: %v", err)
+	}
+
+	proved, err := VerifyRange(receiver, proof, big.NewInt(18), big.NewInt(150), context, 80)
+	assert.NoError(t, err)
+	assert.True(t, proved, "non-interactive DamgardFujisaki range proof failed")
+
+	proved, _ = VerifyRange(receiver, proof, big.NewInt(50), big.NewInt(150), context, 80)
+	assert.False(t, proved, "proof should not be accepted for another range")
+	proved, _ = VerifyRange(receiver, proof, big.NewInt(18), big.NewInt(150), big.NewInt(1), 80)
+	assert.False(t, proved, "proof should not be accepted in another context")
+}
@@ -79,17 +79,20 @@ type SquareVerifier struct {
 func NewSquareVerifier(receiver *Receiver,
 	c1 *big.Int, challengeSpaceSize int) (*SquareVerifier, error) {
 
-	receiver1, err := NewReceiverFromParams(receiver.QRSpecialRSA.GetPrimes(),
-		receiver.G, receiver.H, receiver.K)
-	if err != nil {
-		return nil, fmt.Errorf("error when calling NewReceiverFromParams")
+	// verifiers only need the public parameters of the receiver
+	receiver1 := &Receiver{df: df{
+		QRSpecialRSA: receiver.QRSpecialRSA,
+		G:            receiver.G,
+		H:            receiver.H,
+		K:            receiver.K},
 	}
 	receiver1.SetCommitment(c1)
 
-	receiver2, err := NewReceiverFromParams(receiver.QRSpecialRSA.GetPrimes(),
-		c1, receiver.H, receiver.K)
-	if err != nil {
-		return nil, fmt.Errorf("error when calling NewReceiverFromParams")
+	receiver2 := &Receiver{df: df{
+		QRSpecialRSA: receiver.QRSpecialRSA,
+		G:            c1,
+		H:            receiver.H,
+		K:            receiver.K},
 	}
 	receiver2.SetCommitment(receiver.Commitment)
 
@@ -17,7 +17,10 @@
 
 package ec
 
-import "crypto/elliptic"
+import (
+	"crypto/elliptic"
+	"fmt"
+)
 
 type Curve int
 
@@ -26,6 +29,7 @@ const (
 	P256
 	P384
 	P521
+	Secp256k1
 )
 
 func GetCurve(c Curve) elliptic.Curve {
@@ -38,7 +42,44 @@ func GetCurve(c Curve) elliptic.Curve {
 		return elliptic.P384()
 	case P521:
 		return elliptic.P521()
+	case Secp256k1:
+		return Secp256k1Curve()
 	}
 
 	return elliptic.P256()
 }
+
+// Names of the available curve presets.
+const (
+	CurvePresetP256      = "p256"
+	CurvePresetP384      = "p384"
+	CurvePresetSecp256k1 = "secp256k1"
+)
+
+var curvePresets = map[string]Curve{
+	CurvePresetP256:      P256,
+	CurvePresetP384:      P384,
+	CurvePresetSecp256k1: Secp256k1,
+}
+
+// GetCurvePreset returns the curve of the preset with the given name.
+func GetCurvePreset(name string) (Curve, error) {
+	c, ok := curvePresets[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown curve preset: %s", name)
+	}
+
+	return c, nil
+}
+
+// CurvePresetName returns the name of the preset c corresponds to, or an empty string
+// when c is not one of the presets.
+func CurvePresetName(c Curve) string {
+	for name, preset := range curvePresets {
+		if preset == c {
+			return name
+		}
+	}
+
+	return ""
+}
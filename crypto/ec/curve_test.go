@@ -0,0 +1,120 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ec
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/common"
+)
+
+func TestSecp256k1(t *testing.T) {
+	group := NewGroup(Secp256k1)
+	params := group.Curve.Params()
+	assert.True(t, group.Curve.IsOnCurve(params.Gx, params.Gy))
+
+	// 2G from the test vectors of secp256k1
+	twoG := group.ExpBaseG(big.NewInt(2))
+	x, _ := new(big.Int).SetString(
+		"C6047F9441ED7D6D3045406E95C07CD85C778E4B8CEF3CA7ABAC09B95C709EE5", 16)
+	y, _ := new(big.Int).SetString(
+		"1AE168FEA63DC339A3C58419466CEAEEF7F632653266D0E1236431A950CFE52A", 16)
+	assert.True(t, twoG.Equals(NewGroupElement(x, y)))
+
+	a := common.GetRandomInt(group.Q)
+	b := common.GetRandomInt(group.Q)
+	ab := new(big.Int).Mul(a, b)
+	ab.Mod(ab, group.Q)
+	assert.True(t, group.Exp(group.ExpBaseG(a), b).Equals(group.ExpBaseG(ab)))
+	el := group.GetRandomElement()
+	assert.True(t, group.Curve.IsOnCurve(el.X, el.Y))
+	assert.True(t, group.Mul(el, group.Inv(el)).Equals(NewGroupElement(big.NewInt(0),
+		big.NewInt(0))))
+}
+
+func TestCurvePresets(t *testing.T) {
+	for _, name := range []string{CurvePresetP256, CurvePresetP384, CurvePresetSecp256k1} {
+		c, err := GetCurvePreset(name)
+		assert.NoError(t, err)
+		assert.Equal(t, name, CurvePresetName(c))
+	}
+	_, err := GetCurvePreset("p192")
+	assert.Error(t, err)
+	assert.Equal(t, "", CurvePresetName(P224))
+}
+
+func TestGroupCheckElement(t *testing.T) {
+	for _, curve := range []Curve{P256, Secp256k1} {
+		group := NewGroup(curve)
+		assert.NoError(t, group.CheckElement(group.GetRandomElement()))
+
+		el := group.GetRandomElement()
+		invalid := []*GroupElement{
+			nil,
+			NewGroupElement(el.X, nil),
+			NewGroupElement(el.X, new(big.Int).Add(el.Y, big.NewInt(1))),
+			NewGroupElement(big.NewInt(0), big.NewInt(0)),
+		}
+		for _, el := range invalid {
+			err := group.CheckElement(el)
+			assert.True(t, errors.Is(err, common.ErrNotInGroup), "%v", el)
+		}
+	}
+}
+
+func TestGroupEncode(t *testing.T) {
+	for _, curve := range []Curve{P256, P384, Secp256k1} {
+		group := NewGroup(curve)
+		for i := 0; i < 10; i++ {
+			el := group.GetRandomElement()
+			v := group.Encode(el)
+			assert.True(t, v.BitLen() <= group.Curve.Params().P.BitLen()+1)
+			decoded, err := group.Decode(v)
+			assert.NoError(t, err)
+			assert.True(t, el.Equals(decoded))
+		}
+
+		for _, v := range []*big.Int{nil, big.NewInt(0),
+			new(big.Int).Lsh(group.Curve.Params().P, 1)} {
+			_, err := group.Decode(v)
+			assert.True(t, errors.Is(err, common.ErrNotInGroup), "%v", v)
+		}
+	}
+}
+
+func TestGroupHashToElement(t *testing.T) {
+	for _, curve := range []Curve{P256, P384, Secp256k1} {
+		group := NewGroup(curve)
+		h := group.HashToElement([]byte("seed"))
+		assert.NoError(t, group.CheckElement(h))
+		assert.True(t, h.Equals(group.HashToElement([]byte("seed"))))
+		assert.False(t, h.Equals(group.HashToElement([]byte("another seed"))))
+	}
+}
+
+func TestGroupBlindedExp(t *testing.T) {
+	for _, curve := range []Curve{P256, Secp256k1} {
+		group := NewGroup(curve)
+		el := group.GetRandomElement()
+		s := common.GetRandomInt(group.Q)
+		assert.True(t, group.Exp(el, s).Equals(group.BlindedExp(el, s)))
+	}
+}
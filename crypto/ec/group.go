@@ -19,6 +19,9 @@ package ec
 
 import (
 	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
 	"math/big"
 
 	"github.com/xlab-si/emmy/crypto/common"
@@ -79,6 +82,13 @@ func (g *Group) Exp(base *GroupElement, exponent *big.Int) *GroupElement {
 	return NewGroupElement(hx, hy)
 }
 
+// BlindedExp computes base^exponent in Group like Exp, but with the exponent
+// blinded (see common.BlindExponent), as scalar multiplication of some curves is
+// not constant-time. It is meant for secret exponents.
+func (g *Group) BlindedExp(base *GroupElement, exponent *big.Int) *GroupElement {
+	return g.Exp(base, common.BlindExponent(new(big.Int), exponent, g.Q))
+}
+
 // Exp computes base^exponent in Group where base is the generator.
 // This actually means exponent * G as this is additive group.
 func (g *Group) ExpBaseG(exponent *big.Int) *GroupElement {
@@ -95,3 +105,95 @@ func (g *Group) Inv(x *GroupElement) *GroupElement {
 	inv := g.Exp(x, orderMinOne)
 	return inv
 }
+
+// CheckElement returns an error wrapping common.ErrNotInGroup if el, received from
+// another party, is not a point on the curve. As supported curves have cofactor 1,
+// all points on the curve are elements of the group. The point at infinity is
+// rejected, as it has no affine coordinates.
+func (g *Group) CheckElement(el *GroupElement) error {
+	if el == nil || el.X == nil || el.Y == nil {
+		return fmt.Errorf("%w: missing coordinates", common.ErrNotInGroup)
+	}
+	if !g.Curve.IsOnCurve(el.X, el.Y) {
+		return fmt.Errorf("%w: not a point on the curve", common.ErrNotInGroup)
+	}
+
+	return nil
+}
+
+// Encode returns el as an integer, which holds x coordinate of el shifted left by one
+// bit and the parity of y coordinate in the lowest bit. The integer is at most one bit
+// longer than the order of the field the curve is defined over.
+func (g *Group) Encode(el *GroupElement) *big.Int {
+	v := new(big.Int).Lsh(el.X, 1)
+	return v.SetBit(v, 0, el.Y.Bit(0))
+}
+
+// Decode returns the element that v is an encoding of (see Encode). An error
+// wrapping common.ErrNotInGroup is returned if v does not encode a point on the
+// curve.
+func (g *Group) Decode(v *big.Int) (*GroupElement, error) {
+	if v == nil || v.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: invalid encoding", common.ErrNotInGroup)
+	}
+	x := new(big.Int).Rsh(v, 1)
+	y := g.y(x)
+	if y == nil {
+		return nil, fmt.Errorf("%w: not a point on the curve", common.ErrNotInGroup)
+	}
+	if y.Bit(0) != v.Bit(0) {
+		y.Sub(g.Curve.Params().P, y)
+	}
+	el := NewGroupElement(x, y)
+	if err := g.CheckElement(el); err != nil {
+		return nil, err
+	}
+
+	return el, nil
+}
+
+// HashToElement returns the element of the group derived from data with the
+// try-and-increment method: x coordinate is taken from the hash of data and a counter,
+// which is incremented until x is a coordinate of a point on the curve. Nobody knows
+// the discrete logarithm of the element with respect to the generator, thus it can be
+// used, for example, as the second generator of Pedersen commitments that are binding
+// also for the party that set up the parameters.
+func (g *Group) HashToElement(data []byte) *GroupElement {
+	p := g.Curve.Params().P
+	ctr := make([]byte, 4)
+	for i := uint32(0); ; i++ {
+		binary.BigEndian.PutUint32(ctr, i)
+		x := new(big.Int)
+		// enough hash output for x to be close to uniform in Z_p
+		for j := 0; x.BitLen() < p.BitLen()+64; j++ {
+			h := sha256.Sum256(append(append([]byte{byte(j)}, ctr...), data...))
+			x.Lsh(x, 256)
+			x.Add(x, new(big.Int).SetBytes(h[:]))
+		}
+		x.Mod(x, p)
+		if y := g.y(x); y != nil && x.Sign() != 0 {
+			return NewGroupElement(x, y)
+		}
+	}
+}
+
+// y returns a square root of x^3 + a*x + b modulo p, that is y coordinate of one of
+// the points of the curve with x coordinate x, or nil if there is no such point.
+// The curves of crypto/elliptic have a = -3, while secp256k1 has a = 0.
+func (g *Group) y(x *big.Int) *big.Int {
+	params := g.Curve.Params()
+	if x.Sign() < 0 || x.Cmp(params.P) >= 0 {
+		return nil
+	}
+	y2 := new(big.Int).Mul(x, x)
+	y2.Mul(y2, x)
+	if _, ok := g.Curve.(*secp256k1Curve); !ok {
+		threeX := new(big.Int).Lsh(x, 1)
+		threeX.Add(threeX, x)
+		y2.Sub(y2, threeX)
+	}
+	y2.Add(y2, params.B)
+	y2.Mod(y2, params.P)
+
+	return new(big.Int).ModSqrt(y2, params.P)
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ec
+
+import (
+	"crypto/elliptic"
+	"math/big"
+	"sync"
+)
+
+// secp256k1Curve implements elliptic.Curve for secp256k1 (y^2 = x^3 + 7), the curve
+// used by Bitcoin. Since a = 0 for this curve, the generic implementation of
+// elliptic.CurveParams (which assumes a = -3) cannot be used. Points are kept in affine
+// coordinates, the point at infinity is represented by (0, 0), as in crypto/elliptic.
+type secp256k1Curve struct {
+	params *elliptic.CurveParams
+}
+
+var (
+	secp256k1     *secp256k1Curve
+	secp256k1Once sync.Once
+)
+
+func initSecp256k1() {
+	params := &elliptic.CurveParams{Name: "secp256k1", BitSize: 256}
+	params.P, _ = new(big.Int).SetString(
+		"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F", 16)
+	params.N, _ = new(big.Int).SetString(
+		"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+	params.B = big.NewInt(7)
+	params.Gx, _ = new(big.Int).SetString(
+		"79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798", 16)
+	params.Gy, _ = new(big.Int).SetString(
+		"483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8", 16)
+	secp256k1 = &secp256k1Curve{params: params}
+}
+
+// Secp256k1Curve returns an elliptic.Curve implementing secp256k1.
+func Secp256k1Curve() elliptic.Curve {
+	secp256k1Once.Do(initSecp256k1)
+	return secp256k1
+}
+
+func (c *secp256k1Curve) Params() *elliptic.CurveParams {
+	return c.params
+}
+
+func (c *secp256k1Curve) IsOnCurve(x, y *big.Int) bool {
+	p := c.params.P
+	if x.Sign() < 0 || x.Cmp(p) >= 0 || y.Sign() < 0 || y.Cmp(p) >= 0 {
+		return false
+	}
+	// y^2 = x^3 + 7
+	y2 := new(big.Int).Mul(y, y)
+	y2.Mod(y2, p)
+	x3 := new(big.Int).Mul(x, x)
+	x3.Mul(x3, x)
+	x3.Add(x3, c.params.B)
+	x3.Mod(x3, p)
+
+	return y2.Cmp(x3) == 0
+}
+
+func (c *secp256k1Curve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	if isInfinity(x1, y1) {
+		return new(big.Int).Set(x2), new(big.Int).Set(y2)
+	}
+	if isInfinity(x2, y2) {
+		return new(big.Int).Set(x1), new(big.Int).Set(y1)
+	}
+
+	p := c.params.P
+	if x1.Cmp(x2) == 0 {
+		if y1.Cmp(y2) == 0 {
+			return c.Double(x1, y1)
+		}
+		// P + (-P)
+		return new(big.Int), new(big.Int)
+	}
+
+	// lambda = (y2 - y1) / (x2 - x1)
+	num := new(big.Int).Sub(y2, y1)
+	den := new(big.Int).Sub(x2, x1)
+	den.Mod(den, p)
+	den.ModInverse(den, p)
+	lambda := num.Mul(num, den)
+	lambda.Mod(lambda, p)
+
+	return c.affine(lambda, x1, y1, x2)
+}
+
+func (c *secp256k1Curve) Double(x1, y1 *big.Int) (*big.Int, *big.Int) {
+	if isInfinity(x1, y1) || y1.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+
+	p := c.params.P
+	// lambda = 3 * x1^2 / (2 * y1)
+	num := new(big.Int).Mul(x1, x1)
+	num.Mul(num, big.NewInt(3))
+	den := new(big.Int).Lsh(y1, 1)
+	den.ModInverse(den.Mod(den, p), p)
+	lambda := num.Mul(num, den)
+	lambda.Mod(lambda, p)
+
+	return c.affine(lambda, x1, y1, x1)
+}
+
+// affine computes the sum of points (x1, y1) and (x2, .) on the line with slope lambda.
+func (c *secp256k1Curve) affine(lambda, x1, y1, x2 *big.Int) (*big.Int, *big.Int) {
+	p := c.params.P
+	// x3 = lambda^2 - x1 - x2, y3 = lambda * (x1 - x3) - y1
+	x3 := new(big.Int).Mul(lambda, lambda)
+	x3.Sub(x3, x1)
+	x3.Sub(x3, x2)
+	x3.Mod(x3, p)
+	y3 := new(big.Int).Sub(x1, x3)
+	y3.Mul(y3, lambda)
+	y3.Sub(y3, y1)
+	y3.Mod(y3, p)
+
+	return x3, y3
+}
+
+func (c *secp256k1Curve) ScalarMult(x1, y1 *big.Int, k []byte) (*big.Int, *big.Int) {
+	x, y := new(big.Int), new(big.Int)
+	for _, b := range k {
+		for i := 7; i >= 0; i-- {
+			x, y = c.Double(x, y)
+			if b>>uint(i)&1 == 1 {
+				x, y = c.Add(x, y, x1, y1)
+			}
+		}
+	}
+
+	return x, y
+}
+
+func (c *secp256k1Curve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	return c.ScalarMult(c.params.Gx, c.params.Gy, k)
+}
+
+func isInfinity(x, y *big.Int) bool {
+	return x.Sign() == 0 && y.Sign() == 0
+}
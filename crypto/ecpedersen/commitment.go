@@ -83,6 +83,27 @@ func (c *Committer) GetCommitMsg(val *big.Int) (*ec.GroupElement, error) {
 	return comm, nil
 }
 
+// GetCommitMsgWithGivenR outputs c = g^x * h^r for the given r, for example to
+// restore a committer from a previously saved opening of c.
+func (c *Committer) GetCommitMsgWithGivenR(val, r *big.Int) (*ec.GroupElement, error) {
+	if val.Cmp(c.Params.Group.Q) >= 0 || val.Cmp(big.NewInt(0)) == -1 {
+		err := fmt.Errorf("the committed value needs to be in Z_q (order of a base point)")
+		return nil, err
+	}
+	if r.Cmp(c.Params.Group.Q) >= 0 || r.Cmp(big.NewInt(0)) == -1 {
+		return nil, fmt.Errorf("the randomness needs to be in Z_q (order of a base point)")
+	}
+
+	c.r = r
+	c.committedValue = val
+	x1 := c.Params.Group.ExpBaseG(val)
+	x2 := c.Params.Group.Exp(c.Params.H, r)
+	comm := c.Params.Group.Mul(x1, x2)
+	c.Commitment = comm
+
+	return comm, nil
+}
+
 // It returns values x and r (commitment was c = g^x * g^r).
 func (c *Committer) GetDecommitMsg() (*big.Int, *big.Int) {
 	val := c.committedValue
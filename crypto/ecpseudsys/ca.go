@@ -18,8 +18,8 @@
 package ecpseudsys
 
 import (
+	gocrypto "crypto"
 	"crypto/ecdsa"
-	"crypto/rand"
 	"fmt"
 	"math/big"
 
@@ -30,11 +30,11 @@ import (
 )
 
 type CA struct {
-	group      *ec.Group
-	verifier   *ecschnorr.Verifier
-	a          *ec.GroupElement
-	b          *ec.GroupElement
-	privateKey *ecdsa.PrivateKey
+	group    *ec.Group
+	verifier *ecschnorr.Verifier
+	a        *ec.GroupElement
+	b        *ec.GroupElement
+	signer   gocrypto.Signer
 }
 
 type CACert struct {
@@ -58,9 +58,15 @@ func NewCA(d *big.Int, caPubKey *pseudsys.PubKey, curve ec.Curve) *CA {
 	pubKey := ecdsa.PublicKey{Curve: c, X: caPubKey.H1, Y: caPubKey.H2}
 	privateKey := ecdsa.PrivateKey{PublicKey: pubKey, D: d}
 
+	return NewCAWithSigner(&privateKey, curve)
+}
+
+// NewCAWithSigner returns a CA that signs certificates with signer, which holds an
+// ECDSA key, such as a key held by a crypto.KeyStore.
+func NewCAWithSigner(signer gocrypto.Signer, curve ec.Curve) *CA {
 	return &CA{
-		verifier:   ecschnorr.NewVerifier(curve),
-		privateKey: &privateKey,
+		verifier: ecschnorr.NewVerifier(curve),
+		signer:   signer,
 	}
 }
 
@@ -86,7 +92,7 @@ func (ca *CA) Verify(z *big.Int) (*CACert, error) {
 	// different organizations)
 
 	hashed := common.HashIntoBytes(blindedA.X, blindedA.Y, blindedB.X, blindedB.Y)
-	r, s, err := ecdsa.Sign(rand.Reader, ca.privateKey, hashed)
+	r, s, err := common.SignECDSA(ca.signer, hashed)
 	if err != nil {
 		return nil, err
 	}
@@ -90,9 +90,9 @@ func (i *CredIssuer) Verify(z *big.Int) (
 		return nil, nil, nil, nil, nil, nil, err
 	}
 
-	A := i.verifier.Group.Exp(i.b, i.secKey.S2)
+	A := i.verifier.Group.BlindedExp(i.b, i.secKey.S2)
 	aA := i.verifier.Group.Mul(i.a, A)
-	B := i.verifier.Group.Exp(aA, i.secKey.S1)
+	B := i.verifier.Group.BlindedExp(aA, i.secKey.S1)
 
 	g1 := ec.NewGroupElement(i.verifier.Group.Curve.Params().Gx,
 		i.verifier.Group.Curve.Params().Gy)
@@ -53,6 +53,12 @@ func (v *CredVerifier) GetChallenge(a, b, a1, b1,
 	return v.verifier.GetChallenge(a, a1, b, b1, x1, x2)
 }
 
+// VerifyPossession verifies a proof created with ProvePossession that the user knows
+// the secret of the nym that GetChallenge was called with.
+func (v *CredVerifier) VerifyPossession(proof *ecschnorr.Proof, context *big.Int) bool {
+	return ecschnorr.VerifyDLogKnowledge(v.curve, proof, v.a, v.b, context)
+}
+
 func (v *CredVerifier) Verify(z *big.Int,
 	credential *Cred, orgPubKeys *PubKey) bool {
 	verified := v.verifier.Verify(z)
@@ -63,12 +69,19 @@ func (v *CredVerifier) Verify(z *big.Int,
 	g := ec.NewGroupElement(v.verifier.Group.Curve.Params().Gx,
 		v.verifier.Group.Curve.Params().Gy)
 
-	valid1 := credential.T1.Verify(ec.P256, g, orgPubKeys.H2,
+	valid1 := credential.T1.Verify(v.curve, g, orgPubKeys.H2,
 		credential.SmallBToGamma, credential.AToGamma)
 
 	aAToGamma := v.verifier.Group.Mul(credential.SmallAToGamma, credential.AToGamma)
-	valid2 := credential.T2.Verify(ec.P256, g, orgPubKeys.H1,
+	valid2 := credential.T2.Verify(v.curve, g, orgPubKeys.H1,
 		aAToGamma, credential.BToGamma)
 
 	return valid1 && valid2
 }
+
+// ProvePossession proves that the user knows the secret of nym, bound to context, like
+// pseudsys.ProvePossession.
+func ProvePossession(curve ec.Curve, secret *big.Int, nym *Nym,
+	context *big.Int) *ecschnorr.Proof {
+	return ecschnorr.ProveDLogKnowledge(curve, secret, nym.A, nym.B, context)
+}
@@ -97,3 +97,62 @@ func (v *Verifier) Verify(z *big.Int) bool {
 	right := v.Group.Mul(r, v.x)
 	return left.Equals(right)
 }
+
+// Proof is a non-interactive proof of knowledge of log_a(b), with the challenge
+// derived by the prover via Fiat-Shamir.
+type Proof struct {
+	ProofRandomData *ec.GroupElement
+	Challenge       *big.Int
+	ProofData       *big.Int
+}
+
+func NewProof(proofRandomData *ec.GroupElement, challenge, proofData *big.Int) *Proof {
+	return &Proof{
+		ProofRandomData: proofRandomData,
+		Challenge:       challenge,
+		ProofData:       proofData,
+	}
+}
+
+// ProveDLogKnowledge creates a non-interactive proof of knowledge of secret such that
+// a^secret = b, bound to context (for example a nonce of the verifier), so that it
+// cannot be replayed in another context.
+func ProveDLogKnowledge(curve ec.Curve, secret *big.Int, a, b *ec.GroupElement,
+	context *big.Int) *Proof {
+	prover := NewProver(curve)
+	x := prover.GetProofRandomData(secret, a)
+	challenge := fiatShamirChallenge(prover.Group, context, a, b, x)
+
+	return NewProof(x, challenge, prover.GetProofData(challenge))
+}
+
+// VerifyDLogKnowledge verifies a proof created with ProveDLogKnowledge that the prover
+// knows log_a(b).
+func VerifyDLogKnowledge(curve ec.Curve, proof *Proof, a, b *ec.GroupElement,
+	context *big.Int) bool {
+	if proof == nil || proof.ProofRandomData == nil || proof.Challenge == nil ||
+		proof.ProofData == nil {
+		return false
+	}
+
+	verifier := NewVerifier(curve)
+	challenge := fiatShamirChallenge(verifier.Group, context, a, b, proof.ProofRandomData)
+	if challenge.Cmp(proof.Challenge) != 0 {
+		return false
+	}
+	verifier.SetProofRandomData(proof.ProofRandomData, a, b)
+	verifier.SetChallenge(challenge)
+	return verifier.Verify(proof.ProofData)
+}
+
+// fiatShamirChallenge derives a challenge from coordinates of elements of a proof and
+// context, by hashing them into an integer modulo the order of group.
+func fiatShamirChallenge(group *ec.Group, context *big.Int,
+	elements ...*ec.GroupElement) *big.Int {
+	values := make([]*big.Int, 0, 2*len(elements)+1)
+	for _, el := range elements {
+		values = append(values, el.X, el.Y)
+	}
+	h := common.Hash(append(values, context)...)
+	return h.Mod(h, group.Q)
+}
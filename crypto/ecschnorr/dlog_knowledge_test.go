@@ -18,6 +18,7 @@
 package ecschnorr
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -46,3 +47,21 @@ func TestECDLogKnowledge(t *testing.T) {
 
 	assert.Equal(t, verified, true, "dlog equality proof does not work")
 }
+
+func TestECDLogKnowledgeNI(t *testing.T) {
+	group := ec.NewGroup(ec.P256)
+	a := group.ExpBaseG(common.GetRandomInt(group.Q))
+	secret := common.GetRandomInt(group.Q)
+	b := group.Exp(a, secret)
+	context := big.NewInt(42)
+
+	proof := ProveDLogKnowledge(ec.P256, secret, a, b, context)
+	assert.True(t, VerifyDLogKnowledge(ec.P256, proof, a, b, context))
+	// proofs are bound to the context
+	assert.False(t, VerifyDLogKnowledge(ec.P256, proof, a, b, big.NewInt(43)))
+	assert.False(t, VerifyDLogKnowledge(ec.P256, proof, a, group.Mul(b, a), context))
+	assert.False(t, VerifyDLogKnowledge(ec.P256, nil, a, b, context))
+
+	proof.ProofData = new(big.Int).Add(proof.ProofData, big.NewInt(1))
+	assert.False(t, VerifyDLogKnowledge(ec.P256, proof, a, b, context))
+}
@@ -18,6 +18,7 @@
 package encryption
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math/big"
@@ -245,8 +246,9 @@ func (csp *CSPaillier) Abs(a *big.Int) (*big.Int, error) {
 }
 
 func (csp *CSPaillier) generateKey() {
-	p1 := common.GetGermainPrime(csp.SecParams.L)
-	q1 := common.GetGermainPrime(csp.SecParams.L)
+	germainPrimes, _ := new(common.PrimeSearch).GermainPrimes(context.Background(),
+		csp.SecParams.L, 2)
+	p1, q1 := germainPrimes[0], germainPrimes[1]
 
 	p := new(big.Int).Add(p1, p1)
 	p.Add(p, big.NewInt(1))
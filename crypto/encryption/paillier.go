@@ -38,6 +38,25 @@ type PaillierPubKey struct {
 	g  *big.Int
 }
 
+// NewPaillierPubKey returns the public key with modulus n and generator g.
+func NewPaillierPubKey(n, g *big.Int) *PaillierPubKey {
+	return &PaillierPubKey{
+		n:  n,
+		n2: new(big.Int).Mul(n, n),
+		g:  g,
+	}
+}
+
+// N returns the modulus of the public key.
+func (pubKey *PaillierPubKey) N() *big.Int {
+	return pubKey.n
+}
+
+// G returns the generator of the public key.
+func (pubKey *PaillierPubKey) G() *big.Int {
+	return pubKey.g
+}
+
 func NewPaillier(primeLength int) *Paillier {
 	paillier := Paillier{
 		primeLength: primeLength,
@@ -93,6 +112,19 @@ func (paillier *Paillier) Decrypt(c *big.Int) (*big.Int, error) {
 	return p, nil
 }
 
+// Add returns an encryption of the sum of plaintexts of ciphertexts c1 and c2
+// (modulo n).
+func (paillier *Paillier) Add(c1, c2 *big.Int) *big.Int {
+	c := new(big.Int).Mul(c1, c2)
+	return c.Mod(c, paillier.pubKey.n2)
+}
+
+// MulConst returns an encryption of the product of the plaintext of ciphertext c
+// and k (modulo n).
+func (paillier *Paillier) MulConst(c, k *big.Int) *big.Int {
+	return common.ExpMod(new(big.Int), c, k, paillier.pubKey.n2)
+}
+
 func (paillier *Paillier) GetPubKey() *PaillierPubKey {
 	return paillier.pubKey
 }
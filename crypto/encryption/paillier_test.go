@@ -36,3 +36,24 @@ func TestPaillier(t *testing.T) {
 
 	assert.Equal(t, m, p, "Paillier encryption/decryption does not work correctly")
 }
+
+func TestPaillierHomomorphism(t *testing.T) {
+	paillier := NewPaillier(512)
+	pubKey := paillier.GetPubKey()
+	pubPaillier := NewPubPaillier(NewPaillierPubKey(pubKey.N(), pubKey.G()))
+
+	m1 := common.GetRandomInt(big.NewInt(123412341234123))
+	m2 := common.GetRandomInt(big.NewInt(123412341234123))
+	k := big.NewInt(4321)
+	c1, err := pubPaillier.Encrypt(m1)
+	assert.NoError(t, err)
+	c2, err := pubPaillier.Encrypt(m2)
+	assert.NoError(t, err)
+
+	// (m1 * k) + m2
+	c := pubPaillier.Add(pubPaillier.MulConst(c1, k), c2)
+	p, err := paillier.Decrypt(c)
+	assert.NoError(t, err)
+	expected := new(big.Int).Mul(m1, k)
+	assert.Equal(t, expected.Add(expected, m2), p)
+}
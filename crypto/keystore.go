@@ -0,0 +1,126 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package crypto
+
+import (
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrKeyNotFound is returned by a KeyStore for labels it holds no key for.
+var ErrKeyNotFound = errors.New("key not found")
+
+// KeyStore holds secret keys of issuers, such as CL issuance keys and secret keys of
+// organizations in the pseudonym system, outside of the configuration. Keys are
+// identified by labels. Keys of schemes that key stores cannot operate with are held
+// in the serialized form of their scheme and loaded into memory when needed, while
+// signing with ECDSA keys (such as the key of the pseudonym system CA) is delegated
+// to the store, so that a store backed by a hardware token never exposes them.
+type KeyStore interface {
+	// Load returns the key with the given label, or ErrKeyNotFound.
+	Load(label string) ([]byte, error)
+	// Store stores key under label, replacing an existing key with the same label.
+	Store(label string, key []byte) error
+	// Signer returns a signer with the ECDSA key with the given label. Signatures
+	// are ASN.1 encoded, as those of ecdsa.PrivateKey.
+	Signer(label string) (gocrypto.Signer, error)
+	// StoreECDSAKey stores key under label, to be used by Signer.
+	StoreECDSAKey(label string, key *ecdsa.PrivateKey) error
+}
+
+// FileKeyStore is a KeyStore that keeps keys in files of a directory, named after
+// their labels (a label such as cl/seckey is stored in a subdirectory). ECDSA keys
+// are kept in PKCS#8 PEM files.
+type FileKeyStore struct {
+	dir string
+}
+
+// NewFileKeyStore returns a FileKeyStore keeping keys in dir.
+func NewFileKeyStore(dir string) *FileKeyStore {
+	return &FileKeyStore{dir: dir}
+}
+
+func (s *FileKeyStore) path(label string) (string, error) {
+	if label == "" || strings.Contains(label, "..") {
+		return "", fmt.Errorf("invalid key label %q", label)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(label)), nil
+}
+
+func (s *FileKeyStore) Load(label string) ([]byte, error) {
+	path, err := s.path(label)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrKeyNotFound
+	}
+
+	return key, err
+}
+
+func (s *FileKeyStore) Store(label string, key []byte) error {
+	path, err := s.path(label)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, key, 0600)
+}
+
+func (s *FileKeyStore) Signer(label string) (gocrypto.Signer, error) {
+	data, err := s.Load(label)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s is not in PEM format", label)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse key %s: %v", label, err)
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("key %s is not an ECDSA key", label)
+	}
+
+	return ecKey, nil
+}
+
+func (s *FileKeyStore) StoreECDSAKey(label string, key *ecdsa.PrivateKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	return s.Store(label, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
@@ -0,0 +1,351 @@
+//go:build pkcs11
+// +build pkcs11
+
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package crypto
+
+import (
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+)
+
+// PKCS11KeyStore is a KeyStore backed by a token (such as an HSM) accessed through
+// a PKCS#11 module. Keys loaded with Load are kept as private data objects of the
+// token, readable only after logging in, whereas ECDSA keys are kept as
+// non-extractable private keys that the token signs with.
+//
+// PKCS11KeyStore is only available when emmy is built with tag pkcs11, as it
+// requires cgo.
+type PKCS11KeyStore struct {
+	sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+}
+
+// NewPKCS11KeyStore opens a session with the token labelled tokenLabel of the PKCS#11
+// module (a shared library) at modulePath, and logs in as a user with pin.
+func NewPKCS11KeyStore(modulePath, tokenLabel, pin string) (*PKCS11KeyStore, error) {
+	ctx := pkcs11.New(modulePath)
+	if ctx == nil {
+		return nil, fmt.Errorf("cannot load PKCS#11 module %s", modulePath)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("cannot initialize PKCS#11 module: %v", err)
+	}
+
+	s, err := openSession(ctx, tokenLabel, pin)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+
+	return &PKCS11KeyStore{ctx: ctx, session: s}, nil
+}
+
+// openSession opens a read-write session with the token labelled tokenLabel and
+// logs in with pin.
+func openSession(ctx *pkcs11.Ctx, tokenLabel, pin string) (pkcs11.SessionHandle, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("cannot list PKCS#11 slots: %v", err)
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil || info.Label != tokenLabel {
+			continue
+		}
+		s, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+		if err != nil {
+			return 0, fmt.Errorf("cannot open PKCS#11 session: %v", err)
+		}
+		if err := ctx.Login(s, pkcs11.CKU_USER, pin); err != nil {
+			ctx.CloseSession(s)
+			return 0, fmt.Errorf("cannot log in to token %s: %v", tokenLabel, err)
+		}
+		return s, nil
+	}
+
+	return 0, fmt.Errorf("no PKCS#11 token labelled %s", tokenLabel)
+}
+
+// Close logs out of the token and unloads the PKCS#11 module.
+func (s *PKCS11KeyStore) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	s.ctx.Logout(s.session)
+	s.ctx.CloseSession(s.session)
+	err := s.ctx.Finalize()
+	s.ctx.Destroy()
+
+	return err
+}
+
+// find returns handles of objects of class with the given label.
+func (s *PKCS11KeyStore) find(class uint, label string) ([]pkcs11.ObjectHandle, error) {
+	template := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return nil, err
+	}
+	defer s.ctx.FindObjectsFinal(s.session)
+
+	var handles []pkcs11.ObjectHandle
+	for {
+		objs, _, err := s.ctx.FindObjects(s.session, 16)
+		if err != nil {
+			return nil, err
+		}
+		if len(objs) == 0 {
+			return handles, nil
+		}
+		handles = append(handles, objs...)
+	}
+}
+
+// findOne returns the handle of the object of class with the given label, or
+// ErrKeyNotFound.
+func (s *PKCS11KeyStore) findOne(class uint, label string) (pkcs11.ObjectHandle, error) {
+	handles, err := s.find(class, label)
+	if err != nil {
+		return 0, err
+	}
+	if len(handles) == 0 {
+		return 0, ErrKeyNotFound
+	}
+
+	return handles[0], nil
+}
+
+// destroy destroys objects of class with the given label.
+func (s *PKCS11KeyStore) destroy(class uint, label string) error {
+	handles, err := s.find(class, label)
+	if err != nil {
+		return err
+	}
+	for _, h := range handles {
+		if err := s.ctx.DestroyObject(s.session, h); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *PKCS11KeyStore) Load(label string) ([]byte, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	h, err := s.findOne(pkcs11.CKO_DATA, label)
+	if err != nil {
+		return nil, err
+	}
+	attrs, err := s.ctx.GetAttributeValue(s.session, h, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return attrs[0].Value, nil
+}
+
+func (s *PKCS11KeyStore) Store(label string, key []byte) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.destroy(pkcs11.CKO_DATA, label); err != nil {
+		return err
+	}
+	_, err := s.ctx.CreateObject(s.session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_DATA),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_MODIFIABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+		pkcs11.NewAttribute(pkcs11.CKA_APPLICATION, "emmy"),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, key),
+	})
+
+	return err
+}
+
+// Object identifiers of named curves supported for ECDSA keys.
+var curveOIDs = map[elliptic.Curve]asn1.ObjectIdentifier{
+	elliptic.P224(): {1, 3, 132, 0, 33},
+	elliptic.P256(): {1, 2, 840, 10045, 3, 1, 7},
+	elliptic.P384(): {1, 3, 132, 0, 34},
+	elliptic.P521(): {1, 3, 132, 0, 35},
+}
+
+func (s *PKCS11KeyStore) StoreECDSAKey(label string, key *ecdsa.PrivateKey) error {
+	oid, ok := curveOIDs[key.Curve]
+	if !ok {
+		return fmt.Errorf("unsupported curve %s", key.Params().Name)
+	}
+	params, err := asn1.Marshal(oid)
+	if err != nil {
+		return err
+	}
+	point, err := asn1.Marshal(elliptic.Marshal(key.Curve, key.X, key.Y))
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if err := s.destroy(pkcs11.CKO_PRIVATE_KEY, label); err != nil {
+		return err
+	}
+	if err := s.destroy(pkcs11.CKO_PUBLIC_KEY, label); err != nil {
+		return err
+	}
+	if _, err := s.ctx.CreateObject(s.session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, params),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, key.D.Bytes()),
+	}); err != nil {
+		return err
+	}
+	_, err = s.ctx.CreateObject(s.session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, params),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, point),
+	})
+
+	return err
+}
+
+func (s *PKCS11KeyStore) Signer(label string) (gocrypto.Signer, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	priv, err := s.findOne(pkcs11.CKO_PRIVATE_KEY, label)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := s.findOne(pkcs11.CKO_PUBLIC_KEY, label)
+	if err != nil {
+		return nil, fmt.Errorf("public key %s: %v", label, err)
+	}
+	attrs, err := s.ctx.GetAttributeValue(s.session, pub, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := parseECPublicKey(attrs[0].Value, attrs[1].Value)
+	if err != nil {
+		return nil, fmt.Errorf("public key %s: %v", label, err)
+	}
+
+	return &pkcs11Signer{store: s, key: priv, pub: pubKey}, nil
+}
+
+// parseECPublicKey parses an EC public key from values of attributes CKA_EC_PARAMS
+// and CKA_EC_POINT.
+func parseECPublicKey(params, point []byte) (*ecdsa.PublicKey, error) {
+	var oid asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(params, &oid); err != nil {
+		return nil, fmt.Errorf("invalid curve parameters: %v", err)
+	}
+	var curve elliptic.Curve
+	for c, id := range curveOIDs {
+		if id.Equal(oid) {
+			curve = c
+		}
+	}
+	if curve == nil {
+		return nil, fmt.Errorf("unsupported curve %v", oid)
+	}
+	var encoded []byte
+	if _, err := asn1.Unmarshal(point, &encoded); err != nil {
+		return nil, fmt.Errorf("invalid point: %v", err)
+	}
+	x, y := elliptic.Unmarshal(curve, encoded)
+	if x == nil {
+		return nil, fmt.Errorf("invalid point")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+// pkcs11Signer signs with an ECDSA key held by a token.
+type pkcs11Signer struct {
+	store *PKCS11KeyStore
+	key   pkcs11.ObjectHandle
+	pub   *ecdsa.PublicKey
+}
+
+func (s *pkcs11Signer) Public() gocrypto.PublicKey {
+	return s.pub
+}
+
+// Sign signs digest with mechanism CKM_ECDSA. As ecdsa.Sign, it uses only as many
+// leftmost bytes of digest as the size of the curve order, and the token truncates
+// them further to its bit length.
+func (s *pkcs11Signer) Sign(_ io.Reader, digest []byte,
+	_ gocrypto.SignerOpts) ([]byte, error) {
+	if n := (s.pub.Params().N.BitLen() + 7) / 8; len(digest) > n {
+		digest = digest[:n]
+	}
+
+	s.store.Lock()
+	defer s.store.Unlock()
+	ctx := s.store.ctx
+	mech := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}
+	if err := ctx.SignInit(s.store.session, mech, s.key); err != nil {
+		return nil, err
+	}
+	sig, err := ctx.Sign(s.store.session, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	// the token returns r and s concatenated
+	half := len(sig) / 2
+	return asn1.Marshal(struct{ R, S *big.Int }{
+		new(big.Int).SetBytes(sig[:half]),
+		new(big.Int).SetBytes(sig[half:]),
+	})
+}
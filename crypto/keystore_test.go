@@ -0,0 +1,73 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/common"
+)
+
+func TestFileKeyStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emmy-keystore")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	ks := NewFileKeyStore(dir)
+
+	_, err = ks.Load("cl/seckey")
+	assert.Equal(t, ErrKeyNotFound, err)
+
+	require.NoError(t, ks.Store("cl/seckey", []byte("secret")))
+	key, err := ks.Load("cl/seckey")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), key)
+
+	// keys are replaced
+	require.NoError(t, ks.Store("cl/seckey", []byte("other secret")))
+	key, err = ks.Load("cl/seckey")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("other secret"), key)
+
+	assert.Error(t, ks.Store("../seckey", []byte("secret")))
+	assert.Error(t, ks.Store("", []byte("secret")))
+
+	_, err = ks.Signer("cl/seckey")
+	assert.Error(t, err, "not an ECDSA key")
+	_, err = ks.Signer("ca")
+	assert.Equal(t, ErrKeyNotFound, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	require.NoError(t, ks.StoreECDSAKey("ca", ecKey))
+	signer, err := ks.Signer("ca")
+	require.NoError(t, err)
+	assert.Equal(t, &ecKey.PublicKey, signer.Public())
+
+	digest := sha256.Sum256([]byte("message"))
+	r, s, err := common.SignECDSA(signer, digest[:])
+	require.NoError(t, err)
+	assert.True(t, ecdsa.Verify(&ecKey.PublicKey, digest[:], r, s))
+}
@@ -0,0 +1,348 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package merkle implements commitments to large binary objects, such as
+// biometric templates or documents, that are split into chunks and committed to
+// with a Merkle tree. The root of the tree is small enough to be an attribute of a
+// credential (see cl.BlobAttr), while the object itself is stored and transferred
+// separately. Selected chunks can later be opened with proofs of their inclusion,
+// without revealing the rest of the object.
+//
+// Each chunk is hashed together with a salt derived from a secret key of the
+// committer, so that the root and inclusion proofs do not reveal anything about
+// unopened chunks, even when they have few possible values.
+package merkle
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/xlab-si/emmy/crypto/common"
+)
+
+// DefaultChunkSize is the chunk size, in bytes, objects are split into by default.
+const DefaultChunkSize = 64 * 1024
+
+// KeySize is the size, in bytes, of keys salts of chunks are derived from.
+const KeySize = 32
+
+// Prefixes of hashed values, separating hashes of leaves, inner nodes and roots.
+const (
+	leafPrefix byte = iota
+	nodePrefix
+	rootPrefix
+)
+
+// Committer computes a commitment to an object written to it, keeping only hashes
+// of its chunks in memory.
+type Committer struct {
+	chunkSize int
+	key       []byte
+	buf       []byte
+	size      int64
+	leaves    [][]byte
+}
+
+// NewCommitter returns a Committer splitting objects into chunks of chunkSize bytes,
+// with a random key.
+func NewCommitter(chunkSize int) (*Committer, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(common.RandReader(), key); err != nil {
+		return nil, err
+	}
+
+	return NewCommitterWithKey(chunkSize, key)
+}
+
+// NewCommitterWithKey returns a Committer splitting objects into chunks of chunkSize
+// bytes, with the given key. It is used to compute a commitment of an object again,
+// for example to open its chunks later.
+func NewCommitterWithKey(chunkSize int, key []byte) (*Committer, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive")
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must have %d bytes", KeySize)
+	}
+
+	return &Committer{
+		chunkSize: chunkSize,
+		key:       key,
+		buf:       make([]byte, 0, chunkSize),
+	}, nil
+}
+
+// Write adds p to the committed object.
+func (c *Committer) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		k := c.chunkSize - len(c.buf)
+		if k > len(p) {
+			k = len(p)
+		}
+		c.buf = append(c.buf, p[:k]...)
+		p = p[k:]
+		if len(c.buf) == c.chunkSize {
+			c.addLeaf()
+		}
+	}
+	c.size += int64(n)
+
+	return n, nil
+}
+
+func (c *Committer) addLeaf() {
+	i := len(c.leaves)
+	c.leaves = append(c.leaves, leafHash(salt(c.key, i), c.buf))
+	c.buf = c.buf[:0]
+}
+
+// Commitment returns the commitment to the object written so far.
+func (c *Committer) Commitment() (*Commitment, error) {
+	if len(c.buf) > 0 {
+		c.addLeaf()
+	}
+	if c.size == 0 {
+		return nil, fmt.Errorf("cannot commit to an empty object")
+	}
+
+	levels := [][][]byte{c.leaves}
+	for level := c.leaves; len(level) > 1; {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 < len(level) {
+				next = append(next, nodeHash(level[i], level[i+1]))
+			} else {
+				next = append(next, level[i]) // the last node is promoted
+			}
+		}
+		levels = append(levels, next)
+		level = next
+	}
+
+	return &Commitment{
+		ChunkSize: c.chunkSize,
+		Size:      c.size,
+		Key:       c.key,
+		Root:      rootHash(c.chunkSize, c.size, levels[len(levels)-1][0]),
+		levels:    levels,
+	}, nil
+}
+
+// Commitment is a commitment to an object. Key needs to be kept secret by the
+// committer, and Root is what the commitment is published as.
+type Commitment struct {
+	ChunkSize int
+	Size      int64
+	Key       []byte
+	Root      []byte
+	levels    [][][]byte // hashes of the tree, from leaves to the root
+}
+
+// Commit reads the object from r and returns a commitment to it with a random key.
+func Commit(r io.Reader, chunkSize int) (*Commitment, error) {
+	c, err := NewCommitter(chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(c, r); err != nil {
+		return nil, err
+	}
+
+	return c.Commitment()
+}
+
+// Chunks returns the number of chunks of the object.
+func (c *Commitment) Chunks() int {
+	return chunks(c.ChunkSize, c.Size)
+}
+
+// Prove returns a proof of inclusion of chunk with index i.
+func (c *Commitment) Prove(i int, chunk []byte) (*InclusionProof, error) {
+	if i < 0 || i >= c.Chunks() {
+		return nil, fmt.Errorf("no chunk with index %d", i)
+	}
+	s := salt(c.Key, i)
+	if !bytes.Equal(leafHash(s, chunk), c.levels[0][i]) {
+		return nil, fmt.Errorf("chunk %d is not a chunk of the committed object", i)
+	}
+
+	var path [][]byte
+	j := i
+	for _, level := range c.levels[:len(c.levels)-1] {
+		if sibling := j ^ 1; sibling < len(level) {
+			path = append(path, level[sibling])
+		}
+		j /= 2
+	}
+
+	return &InclusionProof{
+		Index:     i,
+		ChunkSize: c.ChunkSize,
+		Size:      c.Size,
+		Salt:      s,
+		Path:      path,
+	}, nil
+}
+
+// Opening is a chunk of an object along with the proof of its inclusion.
+type Opening struct {
+	Chunk []byte
+	Proof *InclusionProof
+}
+
+// Open reads chunks with indices from the committed object in r and returns their
+// openings.
+func (c *Commitment) Open(r io.ReaderAt, indices ...int) ([]*Opening, error) {
+	openings := make([]*Opening, len(indices))
+	for k, i := range indices {
+		if i < 0 || i >= c.Chunks() {
+			return nil, fmt.Errorf("no chunk with index %d", i)
+		}
+		chunk := make([]byte, chunkLen(c.ChunkSize, c.Size, i))
+		if _, err := r.ReadAt(chunk, int64(i)*int64(c.ChunkSize)); err != nil {
+			return nil, fmt.Errorf("cannot read chunk %d: %v", i, err)
+		}
+		proof, err := c.Prove(i, chunk)
+		if err != nil {
+			return nil, err
+		}
+		openings[k] = &Opening{
+			Chunk: chunk,
+			Proof: proof,
+		}
+	}
+
+	return openings, nil
+}
+
+// InclusionProof proves that a chunk with index Index is a chunk of the object
+// committed to by a root.
+type InclusionProof struct {
+	Index     int
+	ChunkSize int
+	Size      int64
+	Salt      []byte
+	Path      [][]byte // hashes of siblings on the path from the chunk to the root
+}
+
+// Verify checks that chunk is included in the object committed to by root.
+func (p *InclusionProof) Verify(root, chunk []byte) error {
+	if p.ChunkSize <= 0 || p.Size <= 0 {
+		return fmt.Errorf("invalid size of the object")
+	}
+	n := chunks(p.ChunkSize, p.Size)
+	if p.Index < 0 || p.Index >= n {
+		return fmt.Errorf("no chunk with index %d", p.Index)
+	}
+	if len(p.Salt) != sha256.Size {
+		return fmt.Errorf("invalid salt of chunk %d", p.Index)
+	}
+	if len(chunk) != chunkLen(p.ChunkSize, p.Size, p.Index) {
+		return fmt.Errorf("chunk %d has invalid length", p.Index)
+	}
+
+	h := leafHash(p.Salt, chunk)
+	k := 0
+	for i := p.Index; n > 1; i, n = i/2, (n+1)/2 {
+		if i%2 == 0 && i+1 == n { // promoted
+			continue
+		}
+		if k >= len(p.Path) {
+			return fmt.Errorf("path of chunk %d is too short", p.Index)
+		}
+		if i%2 == 0 {
+			h = nodeHash(h, p.Path[k])
+		} else {
+			h = nodeHash(p.Path[k], h)
+		}
+		k++
+	}
+	if k != len(p.Path) {
+		return fmt.Errorf("path of chunk %d is too long", p.Index)
+	}
+
+	if !hmac.Equal(rootHash(p.ChunkSize, p.Size, h), root) {
+		return fmt.Errorf("chunk %d is not included in the object", p.Index)
+	}
+
+	return nil
+}
+
+// VerifyOpenings verifies openings of chunks of the object committed to by root.
+func VerifyOpenings(root []byte, openings []*Opening) error {
+	for _, o := range openings {
+		if o == nil || o.Proof == nil {
+			return fmt.Errorf("incomplete opening")
+		}
+		if err := o.Proof.Verify(root, o.Chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func chunks(chunkSize int, size int64) int {
+	return int((size + int64(chunkSize) - 1) / int64(chunkSize))
+}
+
+// chunkLen returns the length of chunk i; all but the last chunk are full.
+func chunkLen(chunkSize int, size int64, i int) int {
+	if rest := size - int64(i)*int64(chunkSize); rest < int64(chunkSize) {
+		return int(rest)
+	}
+	return chunkSize
+}
+
+// salt returns the salt of chunk i derived from key.
+func salt(key []byte, i int) []byte {
+	mac := hmac.New(sha256.New, key)
+	binary.Write(mac, binary.BigEndian, uint64(i))
+	return mac.Sum(nil)
+}
+
+func leafHash(salt, chunk []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(salt)
+	h.Write(chunk)
+	return h.Sum(nil)
+}
+
+func nodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// rootHash binds the root of the tree to the layout of the object, so that
+// proofs cannot claim a different number of chunks.
+func rootHash(chunkSize int, size int64, treeRoot []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{rootPrefix})
+	binary.Write(h, binary.BigEndian, uint64(chunkSize))
+	binary.Write(h, binary.BigEndian, uint64(size))
+	h.Write(treeRoot)
+	return h.Sum(nil)
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package merkle
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitment(t *testing.T) {
+	for _, size := range []int{1, 100, 1000, 1024, 5000} {
+		obj := make([]byte, size)
+		_, err := rand.Read(obj)
+		require.NoError(t, err)
+
+		c, err := Commit(bytes.NewReader(obj), 100)
+		require.NoError(t, err)
+		assert.Equal(t, (size+99)/100, c.Chunks())
+
+		// the commitment does not depend on how the object is written
+		committer, err := NewCommitterWithKey(100, c.Key)
+		require.NoError(t, err)
+		for i := 0; i < size; i += 7 {
+			end := i + 7
+			if end > size {
+				end = size
+			}
+			_, err = committer.Write(obj[i:end])
+			require.NoError(t, err)
+		}
+		c1, err := committer.Commitment()
+		require.NoError(t, err)
+		assert.Equal(t, c.Root, c1.Root)
+
+		indices := make([]int, c.Chunks())
+		for i := range indices {
+			indices[i] = i
+		}
+		openings, err := c.Open(bytes.NewReader(obj), indices...)
+		require.NoError(t, err)
+		assert.NoError(t, VerifyOpenings(c.Root, openings), "size %d", size)
+
+		// openings of a modified object are not valid
+		last := openings[len(openings)-1]
+		last.Chunk[0] ^= 1
+		assert.Error(t, last.Proof.Verify(c.Root, last.Chunk))
+		last.Chunk[0] ^= 1
+		if len(openings) > 1 {
+			assert.Error(t, openings[0].Proof.Verify(c.Root, openings[1].Chunk))
+			openings[1].Proof.Index = 0
+			assert.Error(t, openings[1].Proof.Verify(c.Root, openings[1].Chunk))
+		}
+		last.Proof.Size++
+		assert.Error(t, last.Proof.Verify(c.Root, last.Chunk))
+	}
+}
+
+func TestCommitmentHiding(t *testing.T) {
+	obj := []byte("a small object")
+	c1, err := Commit(bytes.NewReader(obj), DefaultChunkSize)
+	require.NoError(t, err)
+	c2, err := Commit(bytes.NewReader(obj), DefaultChunkSize)
+	require.NoError(t, err)
+	assert.NotEqual(t, c1.Root, c2.Root, "commitments with different keys should differ")
+}
+
+func TestCommitmentErrors(t *testing.T) {
+	_, err := Commit(bytes.NewReader(nil), DefaultChunkSize)
+	assert.Error(t, err)
+	_, err = NewCommitter(0)
+	assert.Error(t, err)
+	_, err = NewCommitterWithKey(DefaultChunkSize, []byte("short key"))
+	assert.Error(t, err)
+
+	c, err := Commit(bytes.NewReader([]byte("object")), DefaultChunkSize)
+	require.NoError(t, err)
+	_, err = c.Prove(0, []byte("another"))
+	assert.Error(t, err)
+	_, err = c.Prove(1, []byte("object"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pairing
+
+import (
+	"fmt"
+	"math/big"
+
+	bls "github.com/kilic/bls12-381"
+	"github.com/xlab-si/emmy/crypto/common"
+)
+
+// G1Element is an element of G1.
+type G1Element struct {
+	p *bls.PointG1
+}
+
+// NewG1ElementFromBytes decodes an element of G1 from its compressed encoding (see
+// Bytes), checking that it is a point of the subgroup of order Q.
+func NewG1ElementFromBytes(data []byte) (*G1Element, error) {
+	p, err := bls.NewG1().FromCompressed(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid element of G1: %v", err)
+	}
+	return &G1Element{p: p}, nil
+}
+
+// Bytes returns the compressed encoding of the element (48 bytes).
+func (e *G1Element) Bytes() []byte {
+	return bls.NewG1().ToCompressed(e.p)
+}
+
+func (e *G1Element) Equals(b *G1Element) bool {
+	return bls.NewG1().Equal(e.p, b.p)
+}
+
+// IsIdentity reports whether e is the identity of G1.
+func (e *G1Element) IsIdentity() bool {
+	return bls.NewG1().IsZero(e.p)
+}
+
+// G1 is the group G1 of BLS12-381, generated by the standard generator of the curve
+// (see ExpBaseG).
+type G1 struct {
+	Q *big.Int
+}
+
+func NewG1() *G1 {
+	return &G1{
+		Q: Q,
+	}
+}
+
+// Identity returns the identity of G1.
+func (g *G1) Identity() *G1Element {
+	return &G1Element{p: bls.NewG1().Zero()}
+}
+
+// GetRandomElement returns a random element from G1.
+func (g *G1) GetRandomElement() *G1Element {
+	return g.ExpBaseG(common.GetRandomInt(g.Q))
+}
+
+// Mul computes a * b in G1.
+func (g *G1) Mul(a, b *G1Element) *G1Element {
+	c := bls.NewG1()
+	return &G1Element{p: c.Add(c.New(), a.p, b.p)}
+}
+
+// Exp computes base^exponent in G1. Exponents are taken modulo Q, thus they can be
+// negative.
+func (g *G1) Exp(base *G1Element, exponent *big.Int) *G1Element {
+	c := bls.NewG1()
+	return &G1Element{p: c.MulScalarBig(c.New(), base.p, reduce(exponent))}
+}
+
+// ExpBaseG computes base^exponent in G1 where base is the generator.
+func (g *G1) ExpBaseG(exponent *big.Int) *G1Element {
+	c := bls.NewG1()
+	return &G1Element{p: c.MulScalarBig(c.New(), c.One(), reduce(exponent))}
+}
+
+// MultiExp computes bases[0]^exponents[0] * ... * bases[n-1]^exponents[n-1] in G1.
+func (g *G1) MultiExp(bases []*G1Element, exponents []*big.Int) *G1Element {
+	c := bls.NewG1()
+	r := c.Zero()
+	for i, b := range bases {
+		c.Add(r, r, c.MulScalarBig(c.New(), b.p, reduce(exponents[i])))
+	}
+	return &G1Element{p: r}
+}
+
+// Inv computes the inverse of x in G1.
+func (g *G1) Inv(x *G1Element) *G1Element {
+	c := bls.NewG1()
+	return &G1Element{p: c.Neg(c.New(), x.p)}
+}
+
+// HashToElement hashes msg to an element of G1 nobody knows the discrete logarithm
+// of, following the hash-to-curve suite BLS12381G1_XMD:SHA-256_SSWU_RO_ with domain
+// separation tag domain.
+func (g *G1) HashToElement(msg, domain []byte) (*G1Element, error) {
+	p, err := bls.NewG1().HashToCurve(msg, domain)
+	if err != nil {
+		return nil, err
+	}
+	return &G1Element{p: p}, nil
+}
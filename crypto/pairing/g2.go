@@ -0,0 +1,123 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pairing
+
+import (
+	"fmt"
+	"math/big"
+
+	bls "github.com/kilic/bls12-381"
+	"github.com/xlab-si/emmy/crypto/common"
+)
+
+// G2Element is an element of G2.
+type G2Element struct {
+	p *bls.PointG2
+}
+
+// NewG2ElementFromBytes decodes an element of G2 from its compressed encoding (see
+// Bytes), checking that it is a point of the subgroup of order Q.
+func NewG2ElementFromBytes(data []byte) (*G2Element, error) {
+	p, err := bls.NewG2().FromCompressed(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid element of G2: %v", err)
+	}
+	return &G2Element{p: p}, nil
+}
+
+// Bytes returns the compressed encoding of the element (96 bytes).
+func (e *G2Element) Bytes() []byte {
+	return bls.NewG2().ToCompressed(e.p)
+}
+
+func (e *G2Element) Equals(b *G2Element) bool {
+	return bls.NewG2().Equal(e.p, b.p)
+}
+
+// IsIdentity reports whether e is the identity of G2.
+func (e *G2Element) IsIdentity() bool {
+	return bls.NewG2().IsZero(e.p)
+}
+
+// G2 is the group G2 of BLS12-381, generated by the standard generator of the curve
+// (see ExpBaseG).
+type G2 struct {
+	Q *big.Int
+}
+
+func NewG2() *G2 {
+	return &G2{
+		Q: Q,
+	}
+}
+
+// Identity returns the identity of G2.
+func (g *G2) Identity() *G2Element {
+	return &G2Element{p: bls.NewG2().Zero()}
+}
+
+// GetRandomElement returns a random element from G2.
+func (g *G2) GetRandomElement() *G2Element {
+	return g.ExpBaseG(common.GetRandomInt(g.Q))
+}
+
+// Mul computes a * b in G2.
+func (g *G2) Mul(a, b *G2Element) *G2Element {
+	c := bls.NewG2()
+	return &G2Element{p: c.Add(c.New(), a.p, b.p)}
+}
+
+// Exp computes base^exponent in G2. Exponents are taken modulo Q, thus they can be
+// negative.
+func (g *G2) Exp(base *G2Element, exponent *big.Int) *G2Element {
+	c := bls.NewG2()
+	return &G2Element{p: c.MulScalarBig(c.New(), base.p, reduce(exponent))}
+}
+
+// ExpBaseG computes base^exponent in G2 where base is the generator.
+func (g *G2) ExpBaseG(exponent *big.Int) *G2Element {
+	c := bls.NewG2()
+	return &G2Element{p: c.MulScalarBig(c.New(), c.One(), reduce(exponent))}
+}
+
+// MultiExp computes bases[0]^exponents[0] * ... * bases[n-1]^exponents[n-1] in G2.
+func (g *G2) MultiExp(bases []*G2Element, exponents []*big.Int) *G2Element {
+	c := bls.NewG2()
+	r := c.Zero()
+	for i, b := range bases {
+		c.Add(r, r, c.MulScalarBig(c.New(), b.p, reduce(exponents[i])))
+	}
+	return &G2Element{p: r}
+}
+
+// Inv computes the inverse of x in G2.
+func (g *G2) Inv(x *G2Element) *G2Element {
+	c := bls.NewG2()
+	return &G2Element{p: c.Neg(c.New(), x.p)}
+}
+
+// HashToElement hashes msg to an element of G2 nobody knows the discrete logarithm
+// of, following the hash-to-curve suite BLS12381G2_XMD:SHA-256_SSWU_RO_ with domain
+// separation tag domain.
+func (g *G2) HashToElement(msg, domain []byte) (*G2Element, error) {
+	p, err := bls.NewG2().HashToCurve(msg, domain)
+	if err != nil {
+		return nil, err
+	}
+	return &G2Element{p: p}, nil
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pairing
+
+import (
+	"fmt"
+	"math/big"
+
+	bls "github.com/kilic/bls12-381"
+)
+
+// GTElement is an element of the target group GT.
+type GTElement struct {
+	e *bls.E
+}
+
+// NewGTElementFromBytes decodes an element of GT from its encoding (see Bytes),
+// checking that it is in the subgroup of order Q.
+func NewGTElementFromBytes(data []byte) (*GTElement, error) {
+	e, err := bls.NewGT().FromBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid element of GT: %v", err)
+	}
+	return &GTElement{e: e}, nil
+}
+
+// Bytes returns the encoding of the element (576 bytes).
+func (e *GTElement) Bytes() []byte {
+	return bls.NewGT().ToBytes(e.e)
+}
+
+func (e *GTElement) Equals(b *GTElement) bool {
+	return e.e.Equal(b.e)
+}
+
+// IsIdentity reports whether e is the identity of GT.
+func (e *GTElement) IsIdentity() bool {
+	return e.e.IsOne()
+}
+
+// GT is the target group of the pairing, a subgroup of order Q of the multiplicative
+// group of an extension field, generated by the pairing of generators of G1 and G2.
+type GT struct {
+	Q *big.Int
+}
+
+func NewGT() *GT {
+	return &GT{
+		Q: Q,
+	}
+}
+
+// Identity returns the identity of GT.
+func (g *GT) Identity() *GTElement {
+	return &GTElement{e: bls.NewGT().New()}
+}
+
+// GetRandomElement returns a random element from GT.
+func (g *GT) GetRandomElement() *GTElement {
+	return Pair(NewG1().GetRandomElement(), NewG2().GetRandomElement())
+}
+
+// Mul computes a * b in GT.
+func (g *GT) Mul(a, b *GTElement) *GTElement {
+	c := bls.NewGT()
+	r := c.New()
+	c.Mul(r, a.e, b.e)
+	return &GTElement{e: r}
+}
+
+// Exp computes base^exponent in GT. Exponents are taken modulo Q, thus they can be
+// negative.
+func (g *GT) Exp(base *GTElement, exponent *big.Int) *GTElement {
+	c := bls.NewGT()
+	r := c.New()
+	c.Exp(r, base.e, reduce(exponent))
+	return &GTElement{e: r}
+}
+
+// Inv computes the inverse of x in GT.
+func (g *GT) Inv(x *GTElement) *GTElement {
+	c := bls.NewGT()
+	r := c.New()
+	c.Inverse(r, x.e)
+	return &GTElement{e: r}
+}
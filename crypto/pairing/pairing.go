@@ -0,0 +1,58 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package pairing provides groups G1, G2 and GT of the pairing-friendly curve
+// BLS12-381, along with the bilinear map e: G1 x G2 -> GT, for schemes based on
+// pairings (like BBS+ signatures, see package bbs). All groups are of the same prime
+// order Q and, as in package ec, use multiplicative notation.
+package pairing
+
+import (
+	"math/big"
+
+	bls "github.com/kilic/bls12-381"
+)
+
+// Q is the order of groups G1, G2 and GT.
+var Q = bls.NewG1().Q()
+
+// Pair computes e(a, b).
+func Pair(a *G1Element, b *G2Element) *GTElement {
+	return &GTElement{
+		e: bls.NewEngine().AddPair(a.p, b.p).Result(),
+	}
+}
+
+// PairingProductIsOne reports whether e(as[0], bs[0]) * ... * e(as[n-1], bs[n-1]) = 1.
+// This is cheaper than computing the pairings separately, as the final
+// exponentiation is shared among them. For example e(a, b) = e(c, d) is checked as
+// PairingProductIsOne([a, c^-1], [b, d]).
+func PairingProductIsOne(as []*G1Element, bs []*G2Element) bool {
+	if len(as) != len(bs) {
+		return false
+	}
+	engine := bls.NewEngine()
+	for i, a := range as {
+		engine.AddPair(a.p, bs[i].p)
+	}
+	return engine.Check()
+}
+
+// reduce returns x mod Q, such that negative exponents are supported.
+func reduce(x *big.Int) *big.Int {
+	return new(big.Int).Mod(x, Q)
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pairing
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/common"
+)
+
+func TestGroups(t *testing.T) {
+	g1 := NewG1()
+	a := g1.GetRandomElement()
+	x := common.GetRandomInt(Q)
+	assert.True(t, g1.Mul(a, g1.Inv(a)).IsIdentity())
+	assert.True(t, g1.Exp(a, Q).IsIdentity())
+	assert.True(t, g1.Exp(a, new(big.Int).Neg(x)).Equals(g1.Inv(g1.Exp(a, x))))
+	assert.True(t, g1.MultiExp([]*G1Element{a, g1.ExpBaseG(big.NewInt(1))},
+		[]*big.Int{x, big.NewInt(2)}).Equals(g1.Mul(g1.Exp(a, x), g1.ExpBaseG(big.NewInt(2)))))
+
+	g2 := NewG2()
+	b := g2.GetRandomElement()
+	assert.True(t, g2.Mul(b, g2.Inv(b)).IsIdentity())
+	assert.True(t, g2.Exp(b, big.NewInt(-1)).Equals(g2.Inv(b)))
+
+	gt := NewGT()
+	c := gt.GetRandomElement()
+	assert.True(t, gt.Mul(c, gt.Inv(c)).IsIdentity())
+	assert.True(t, gt.Exp(c, Q).IsIdentity())
+	assert.True(t, gt.Exp(c, big.NewInt(-1)).Equals(gt.Inv(c)))
+}
+
+func TestPairing(t *testing.T) {
+	g1, g2, gt := NewG1(), NewG2(), NewGT()
+	a, b := g1.GetRandomElement(), g2.GetRandomElement()
+	x, y := common.GetRandomInt(Q), common.GetRandomInt(Q)
+
+	// e(a^x, b^y) = e(a, b)^(xy)
+	xy := new(big.Int).Mul(x, y)
+	assert.True(t, Pair(g1.Exp(a, x), g2.Exp(b, y)).Equals(gt.Exp(Pair(a, b), xy)))
+	assert.False(t, Pair(a, b).IsIdentity())
+	assert.True(t, Pair(g1.Identity(), b).IsIdentity())
+
+	// e(a^x, b) = e(a, b^x)
+	assert.True(t, PairingProductIsOne([]*G1Element{g1.Exp(a, x), g1.Inv(a)},
+		[]*G2Element{b, g2.Exp(b, x)}))
+	assert.False(t, PairingProductIsOne([]*G1Element{g1.Exp(a, x), g1.Inv(a)},
+		[]*G2Element{b, g2.Exp(b, y)}))
+	assert.False(t, PairingProductIsOne([]*G1Element{a}, nil))
+}
+
+func TestEncoding(t *testing.T) {
+	a := NewG1().GetRandomElement()
+	decodedA, err := NewG1ElementFromBytes(a.Bytes())
+	require.NoError(t, err)
+	assert.True(t, a.Equals(decodedA))
+	_, err = NewG1ElementFromBytes(a.Bytes()[1:])
+	assert.Error(t, err)
+
+	b := NewG2().GetRandomElement()
+	decodedB, err := NewG2ElementFromBytes(b.Bytes())
+	require.NoError(t, err)
+	assert.True(t, b.Equals(decodedB))
+
+	c := NewGT().GetRandomElement()
+	decodedC, err := NewGTElementFromBytes(c.Bytes())
+	require.NoError(t, err)
+	assert.True(t, c.Equals(decodedC))
+}
+
+func TestHashToElement(t *testing.T) {
+	domain := []byte("EMMY-TEST")
+	h1, err := NewG1().HashToElement([]byte("msg"), domain)
+	require.NoError(t, err)
+	h2, err := NewG1().HashToElement([]byte("msg"), domain)
+	require.NoError(t, err)
+	assert.True(t, h1.Equals(h2))
+	h3, err := NewG1().HashToElement([]byte("msg"), []byte("EMMY-OTHER"))
+	require.NoError(t, err)
+	assert.False(t, h1.Equals(h3))
+
+	h4, err := NewG2().HashToElement([]byte("msg"), domain)
+	require.NoError(t, err)
+	assert.False(t, h4.IsIdentity())
+}
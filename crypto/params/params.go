@@ -0,0 +1,146 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package params generates fresh group parameters: moduli of CL keys (products of
+// two safe primes), Schnorr groups and RSA groups used by proofs of quadratic
+// residuosity, with configurable bit lengths. Primes are searched for on several
+// cores (see common.PrimeSearch), and the progress of the search can be followed
+// while it runs, since it can take minutes for large moduli.
+package params
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/qr"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+)
+
+// Kinds of parameters that can be generated.
+const (
+	// KindCL is a special RSA modulus of CL keys.
+	KindCL = "cl"
+	// KindSchnorr is a Schnorr group.
+	KindSchnorr = "schnorr"
+	// KindQR is an RSA group of proofs of quadratic residuosity.
+	KindQR = "qr"
+)
+
+// Progress describes how far the generation of parameters got.
+type Progress struct {
+	Kind   string // kind of parameters being generated
+	Tested int64  // number of prime candidates tested so far
+	Found  int    // number of primes found so far
+	Needed int    // number of primes needed for the parameters
+}
+
+// Generator generates group parameters. A zero Generator is ready to use.
+type Generator struct {
+	// Workers is the number of goroutines searching for primes. If it is not
+	// positive, runtime.NumCPU() goroutines are used.
+	Workers int
+	// Progress, if not nil, is called with the progress of generation every few
+	// hundred prime candidates and whenever a prime is found. Calls are
+	// serialized.
+	Progress func(Progress)
+}
+
+// CLModulus returns the group of quadratic residues modulo a fresh special RSA
+// modulus N = P * Q of the given bit length, where P = 2 * P1 + 1 and
+// Q = 2 * Q1 + 1 are safe primes, as used by CL keys. Generation is stopped
+// with ctx.Err() once ctx is done.
+func (g *Generator) CLModulus(ctx context.Context, bits int) (*qr.RSASpecial, error) {
+	return qr.NewRSASpecialContext(ctx, bits/2, g.search(KindCL, 2))
+}
+
+// SchnorrGroup returns a fresh Schnorr group with a modulus P of pBits bits and
+// a generator of prime order Q of qBits bits. If qBits is pBits - 1, P is a safe
+// prime (P = 2 * Q + 1), otherwise Q is a random prime and P is a random prime
+// of the form 2 * k * Q + 1. Generation is stopped with ctx.Err() once ctx is
+// done.
+func (g *Generator) SchnorrGroup(ctx context.Context, pBits, qBits int) (*schnorr.Group,
+	error) {
+	var p, q *big.Int
+	if qBits == pBits-1 {
+		qs, err := g.search(KindSchnorr, 1).GermainPrimes(ctx, qBits, 1)
+		if err != nil {
+			return nil, err
+		}
+		q = qs[0]
+		p = new(big.Int).Lsh(q, 1)
+		p.Add(p, big.NewInt(1))
+	} else {
+		s := g.search(KindSchnorr, 2)
+		qs, err := s.Primes(ctx, qBits, 1)
+		if err != nil {
+			return nil, err
+		}
+		q = qs[0]
+		if p, err = s.SchnorrPrime(ctx, q, pBits); err != nil {
+			return nil, err
+		}
+	}
+
+	group := schnorr.NewGroupFromParams(p, generator(p, q), q)
+	if err := group.Validate(); err != nil {
+		return nil, fmt.Errorf("generated group is not valid: %s", err)
+	}
+
+	return group, nil
+}
+
+// QRGroup returns a fresh RSA group with a modulus N = P * Q of the given bit
+// length, where P and Q are random primes, as used by proofs of quadratic
+// residuosity (configured in section qr). Generation is stopped with ctx.Err()
+// once ctx is done.
+func (g *Generator) QRGroup(ctx context.Context, bits int) (*qr.RSA, error) {
+	primes, err := g.search(KindQR, 2).Primes(ctx, bits/2, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	return qr.NewRSA(primes[0], primes[1])
+}
+
+// search returns a prime search that reports its progress to g.Progress as the
+// progress of generating parameters of the given kind, which need the given
+// number of primes.
+func (g *Generator) search(kind string, needed int) *common.PrimeSearch {
+	s := &common.PrimeSearch{Workers: g.Workers}
+	if g.Progress != nil {
+		s.Progress = func(tested int64, found int) {
+			g.Progress(Progress{Kind: kind, Tested: tested, Found: found, Needed: needed})
+		}
+	}
+
+	return s
+}
+
+// generator returns a random generator of the subgroup of prime order q of Z_p*.
+func generator(p, q *big.Int) *big.Int {
+	cofactor := new(big.Int).Sub(p, big.NewInt(1))
+	cofactor.Div(cofactor, q)
+	pMinusTwo := new(big.Int).Sub(p, big.NewInt(2))
+	for {
+		h, _ := common.GetRandomIntFromRange(big.NewInt(2), pMinusTwo)
+		if g := new(big.Int).Exp(h, cofactor, p); g.Cmp(big.NewInt(1)) != 0 {
+			return g
+		}
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package params
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLModulus(t *testing.T) {
+	var last Progress
+	g := &Generator{
+		Workers:  2,
+		Progress: func(p Progress) { last = p },
+	}
+	group, err := g.CLModulus(context.Background(), 256)
+	require.NoError(t, err)
+
+	assert.Equal(t, 256, group.N.BitLen())
+	for _, p := range []*big.Int{group.P, group.Q} {
+		p1 := new(big.Int).Rsh(p, 1)
+		assert.True(t, p.ProbablyPrime(20) && p1.ProbablyPrime(20), "primes should be safe")
+	}
+	assert.Equal(t, Progress{Kind: KindCL, Tested: last.Tested, Found: 2, Needed: 2}, last)
+	assert.True(t, last.Tested >= 2)
+}
+
+func TestSchnorrGroup(t *testing.T) {
+	g := &Generator{Workers: 2}
+	for _, bits := range [][2]int{{512, 160}, {256, 255}} {
+		group, err := g.SchnorrGroup(context.Background(), bits[0], bits[1])
+		require.NoError(t, err)
+
+		assert.Equal(t, bits[0], group.P.BitLen())
+		assert.Equal(t, bits[1], group.Q.BitLen())
+		assert.NoError(t, group.Validate())
+	}
+
+	_, err := g.SchnorrGroup(context.Background(), 160, 160)
+	assert.Error(t, err)
+}
+
+func TestQRGroup(t *testing.T) {
+	group, err := new(Generator).QRGroup(context.Background(), 512)
+	require.NoError(t, err)
+
+	assert.Equal(t, 256, group.P.BitLen())
+	assert.Equal(t, 256, group.Q.BitLen())
+	assert.Equal(t, new(big.Int).Mul(group.P, group.Q), group.N)
+}
+
+func TestGeneratorCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := new(Generator).CLModulus(ctx, 8192)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
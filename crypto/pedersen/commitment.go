@@ -86,8 +86,26 @@ func (c *Committer) GetCommitMsg(val *big.Int) (*big.Int, error) {
 
 	c.r = r
 	c.committedValue = val
-	t1 := c.Params.Group.Exp(c.Params.Group.G, val)
-	t2 := c.Params.Group.Exp(c.Params.H, r)
+	t1 := c.Params.Group.BlindedExp(c.Params.Group.G, val)
+	t2 := c.Params.Group.BlindedExp(c.Params.H, r)
+	comm := c.Params.Group.Mul(t1, t2)
+	c.Commitment = comm
+
+	return comm, nil
+}
+
+// GetCommitMsgWithGivenR outputs c = g^x * h^r for the given r, for example to
+// restore a committer from a previously saved opening of c.
+func (c *Committer) GetCommitMsgWithGivenR(val, r *big.Int) (*big.Int, error) {
+	if val.Cmp(c.Params.Group.Q) == 1 || val.Cmp(big.NewInt(0)) == -1 {
+		err := fmt.Errorf("committed value needs to be in Z_q (order of a base point)")
+		return nil, err
+	}
+
+	c.r = r
+	c.committedValue = val
+	t1 := c.Params.Group.BlindedExp(c.Params.Group.G, val)
+	t2 := c.Params.Group.BlindedExp(c.Params.H, r)
 	comm := c.Params.Group.Mul(t1, t2)
 	c.Commitment = comm
 
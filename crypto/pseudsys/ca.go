@@ -18,8 +18,8 @@
 package pseudsys
 
 import (
+	gocrypto "crypto"
 	"crypto/ecdsa"
-	"crypto/rand"
 	"fmt"
 	"math/big"
 
@@ -29,10 +29,10 @@ import (
 )
 
 type CA struct {
-	verifier   *schnorr.Verifier
-	a          *big.Int
-	b          *big.Int
-	privateKey *ecdsa.PrivateKey
+	verifier *schnorr.Verifier
+	a        *big.Int
+	b        *big.Int
+	signer   gocrypto.Signer
 }
 
 type CACert struct {
@@ -56,13 +56,16 @@ func NewCA(group *schnorr.Group, d *big.Int, caPubKey *PubKey) *CA {
 	pubKey := ecdsa.PublicKey{Curve: c, X: caPubKey.H1, Y: caPubKey.H2}
 	privateKey := ecdsa.PrivateKey{PublicKey: pubKey, D: d}
 
-	schnorrVerifier := schnorr.NewVerifier(group)
-	ca := CA{
-		verifier:   schnorrVerifier,
-		privateKey: &privateKey,
-	}
+	return NewCAWithSigner(group, &privateKey)
+}
 
-	return &ca
+// NewCAWithSigner returns a CA that signs certificates with signer, which holds an
+// ECDSA P-256 key, such as a key held by a crypto.KeyStore.
+func NewCAWithSigner(group *schnorr.Group, signer gocrypto.Signer) *CA {
+	return &CA{
+		verifier: schnorr.NewVerifier(group),
+		signer:   signer,
+	}
 }
 
 func (ca *CA) GetChallenge(a, b, x *big.Int) *big.Int {
@@ -78,21 +81,34 @@ func (ca *CA) GetChallenge(a, b, x *big.Int) *big.Int {
 
 func (ca *CA) Verify(z *big.Int) (*CACert, error) {
 	verified := ca.verifier.Verify([]*big.Int{z})
-	if verified {
-		r := common.GetRandomInt(ca.verifier.Group.Q)
-		blindedA := ca.verifier.Group.Exp(ca.a, r)
-		blindedB := ca.verifier.Group.Exp(ca.b, r)
-		// blindedA, blindedB must be used only once (never use the same pair for two
-		// different organizations)
+	if !verified {
+		return nil, fmt.Errorf("knowledge of secret was not verified")
+	}
+	return ca.certify(ca.a, ca.b)
+}
 
-		hashed := common.HashIntoBytes(blindedA, blindedB)
-		r, s, err := ecdsa.Sign(rand.Reader, ca.privateKey, hashed)
-		if err != nil {
-			return nil, err
-		} else {
-			return NewCACert(blindedA, blindedB, r, s), nil
-		}
-	} else {
+// VerifyNI verifies a non-interactive proof, bound to context, that the user knows
+// log_a(b), and returns a certificate of the master nym (a, b). It replaces
+// GetChallenge and Verify when the user derives the challenge via Fiat-Shamir.
+func (ca *CA) VerifyNI(a, b *big.Int, proof *schnorr.Proof, context *big.Int) (*CACert, error) {
+	if !schnorr.VerifyDLogKnowledge(ca.verifier.Group, proof, []*big.Int{a}, b, context) {
 		return nil, fmt.Errorf("knowledge of secret was not verified")
 	}
+	return ca.certify(a, b)
+}
+
+// certify blinds the master nym (a, b) and signs the blinded pair.
+func (ca *CA) certify(a, b *big.Int) (*CACert, error) {
+	r := common.GetRandomInt(ca.verifier.Group.Q)
+	blindedA := ca.verifier.Group.Exp(a, r)
+	blindedB := ca.verifier.Group.Exp(b, r)
+	// blindedA, blindedB must be used only once (never use the same pair for two
+	// different organizations)
+
+	hashed := common.HashIntoBytes(blindedA, blindedB)
+	sigR, sigS, err := common.SignECDSA(ca.signer, hashed)
+	if err != nil {
+		return nil, err
+	}
+	return NewCACert(blindedA, blindedB, sigR, sigS), nil
 }
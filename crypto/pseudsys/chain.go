@@ -0,0 +1,113 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pseudsys
+
+import (
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+)
+
+// ChainLink is a credential that another credential is derived from, along with the
+// name of the organization that issued it. An organization issues a derived
+// credential to a nym only when the user proves that the credentials of the chain
+// were issued to the secret of the nym. Transferring the derived credential along
+// with its chain then proves the whole chain of trust (for example
+// CA -> org1 -> org2 -> verifier) in a single session.
+type ChainLink struct {
+	Issuer string
+	Cred   *Cred
+}
+
+func NewChainLink(issuer string, cred *Cred) *ChainLink {
+	return &ChainLink{
+		Issuer: issuer,
+		Cred:   cred,
+	}
+}
+
+// ChainProver proves that log_g1(t1) = log_g2(t2) = ... = log_gk(tk) for any number of
+// bases, which is how the user proves that a nym and credentials of a chain belong
+// to the same secret.
+type ChainProver struct {
+	Group  *schnorr.Group
+	r      *big.Int
+	secret *big.Int
+}
+
+func NewChainProver(group *schnorr.Group) *ChainProver {
+	return &ChainProver{
+		Group: group,
+	}
+}
+
+// GetProofRandomData returns g_i^r for each of bases g_i.
+func (p *ChainProver) GetProofRandomData(secret *big.Int, bases ...*big.Int) []*big.Int {
+	p.secret = secret
+	p.r = common.GetRandomInt(p.Group.Q)
+	x := make([]*big.Int, len(bases))
+	for i, g := range bases {
+		x[i] = p.Group.Exp(g, p.r)
+	}
+	return x
+}
+
+func (p *ChainProver) GetProofData(challenge *big.Int) *big.Int {
+	// z = r + challenge * secret
+	z := new(big.Int)
+	z.Mul(challenge, p.secret)
+	z.Add(z, p.r)
+	z.Mod(z, p.Group.Q)
+	return z
+}
+
+// ChainBases returns the bases that credentials of chain are proven with, that is
+// a~ of each credential.
+func ChainBases(chain []*ChainLink) []*big.Int {
+	bases := make([]*big.Int, len(chain))
+	for i, link := range chain {
+		bases[i] = link.Cred.SmallAToGamma
+	}
+	return bases
+}
+
+// VerifyChain verifies that each credential of chain was issued by the organization
+// with the corresponding public key of pubKeys, to the secret whose knowledge was
+// proven with challenge and proof data z, where x are proof random data of the
+// credentials.
+func VerifyChain(group *schnorr.Group, chain []*ChainLink, pubKeys []*PubKey,
+	x []*big.Int, challenge, z *big.Int) bool {
+	if len(pubKeys) != len(chain) || len(x) != len(chain) {
+		return false
+	}
+
+	for i, link := range chain {
+		// (a~)^z = x * (b~)^challenge
+		left := group.Exp(link.Cred.SmallAToGamma, z)
+		right := group.Mul(x[i], group.Exp(link.Cred.SmallBToGamma, challenge))
+		if left.Cmp(right) != 0 {
+			return false
+		}
+		if !verifyCred(group, link.Cred, pubKeys[i]) {
+			return false
+		}
+	}
+
+	return true
+}
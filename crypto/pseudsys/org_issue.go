@@ -89,9 +89,9 @@ func (i *CredIssuer) Verify(z *big.Int) (
 		return nil, nil, nil, nil, nil, nil, err
 	}
 
-	A := i.group.Exp(i.b, i.secKey.S2)
+	A := i.group.BlindedExp(i.b, i.secKey.S2)
 	aA := i.group.Mul(i.a, A)
-	B := i.group.Exp(aA, i.secKey.S1)
+	B := i.group.BlindedExp(aA, i.secKey.S1)
 
 	x11, x12 := i.prover1.GetProofRandomData(i.secKey.S2, i.group.G, i.b)
 	x21, x22 := i.prover2.GetProofRandomData(i.secKey.S1, i.group.G, aA)
@@ -41,12 +41,14 @@ func NewNym(a, b *big.Int) *Nym {
 }
 
 type NymGenerator struct {
+	group    *schnorr.Group
 	verifier *schnorr.EqualityVerifier
 	caPubKey *PubKey
 }
 
 func NewNymGenerator(group *schnorr.Group, caPubKey *PubKey) *NymGenerator {
 	return &NymGenerator{
+		group:    group,
 		verifier: schnorr.NewEqualityVerifier(group),
 		caPubKey: caPubKey,
 	}
@@ -54,17 +56,40 @@ func NewNymGenerator(group *schnorr.Group, caPubKey *PubKey) *NymGenerator {
 
 func (g *NymGenerator) GetChallenge(nymA, blindedA, nymB, blindedB, x1, x2,
 	r, s *big.Int) (*big.Int, error) {
+	if err := g.verifyCACert(blindedA, blindedB, r, s); err != nil {
+		return nil, err
+	}
+
+	challenge := g.verifier.GetChallenge(nymA, blindedA, nymB, blindedB, x1, x2)
+	return challenge, nil
+}
+
+// VerifyNI verifies a non-interactive proof, bound to context, that
+// log_nymA(nymB) = log_blindedA(blindedB), where (blindedA, blindedB) is signed by the
+// CA with signature (r, s). It replaces GetChallenge and Verify when the user derives
+// the challenge via Fiat-Shamir.
+func (g *NymGenerator) VerifyNI(nymA, blindedA, nymB, blindedB, r, s *big.Int,
+	proof *schnorr.EqualityProof, context *big.Int) error {
+	if err := g.verifyCACert(blindedA, blindedB, r, s); err != nil {
+		return err
+	}
+	if !schnorr.VerifyDLogEquality(g.group, proof, nymA, blindedA, nymB, blindedB, context) {
+		return fmt.Errorf("proof is not valid")
+	}
+	return nil
+}
+
+// verifyCACert checks the CA's signature (r, s) of the blinded nym (blindedA, blindedB).
+func (g *NymGenerator) verifyCACert(blindedA, blindedB, r, s *big.Int) error {
 	c := ec.GetCurve(ec.P256)
 	pubKey := ecdsa.PublicKey{Curve: c, X: g.caPubKey.H1, Y: g.caPubKey.H2}
 
 	hashed := common.HashIntoBytes(blindedA, blindedB)
 	verified := ecdsa.Verify(&pubKey, hashed, r, s)
 	if !verified {
-		return nil, fmt.Errorf("signature is not valid")
+		return fmt.Errorf("signature is not valid")
 	}
-
-	challenge := g.verifier.GetChallenge(nymA, blindedA, nymB, blindedB, x1, x2)
-	return challenge, nil
+	return nil
 }
 
 // TODO: store (a, b) into a database if verified
@@ -49,17 +49,39 @@ func (v *CredVerifier) GetChallenge(a, b, a1, b1, x1, x2 *big.Int) *big.Int {
 	return challenge
 }
 
+// VerifyPossession verifies a proof created with ProvePossession that the user knows
+// the secret of the nym that GetChallenge was called with.
+func (v *CredVerifier) VerifyPossession(proof *schnorr.Proof, context *big.Int) bool {
+	return schnorr.VerifyDLogKnowledge(v.group, proof, []*big.Int{v.a}, v.b, context)
+}
+
 func (v *CredVerifier) Verify(z *big.Int, cred *Cred, orgPubKeys *PubKey) bool {
 	if !v.verifier.Verify(z) {
 		return false
 	}
 
-	valid1 := cred.T1.Verify(v.group, v.group.G, orgPubKeys.H2,
+	return verifyCred(v.group, cred, orgPubKeys)
+}
+
+// verifyCred verifies that cred was issued by the organization with orgPubKeys.
+func verifyCred(group *schnorr.Group, cred *Cred, orgPubKeys *PubKey) bool {
+	valid1 := cred.T1.Verify(group, group.G, orgPubKeys.H2,
 		cred.SmallBToGamma, cred.AToGamma)
 
-	aAToGamma := v.group.Mul(cred.SmallAToGamma, cred.AToGamma)
-	valid2 := cred.T2.Verify(v.group, v.group.G, orgPubKeys.H1,
+	aAToGamma := group.Mul(cred.SmallAToGamma, cred.AToGamma)
+	valid2 := cred.T2.Verify(group, group.G, orgPubKeys.H1,
 		aAToGamma, cred.BToGamma)
 
 	return valid1 && valid2
 }
+
+// ProvePossession proves the knowledge of the secret of nym, that is log_nym.A(nym.B),
+// with a non-interactive proof bound to context. When context identifies the verifier
+// and the time of a credential transfer, the proof shows that the user held the secret
+// when transferring the credential to that verifier, so that a captured transcript of
+// the transfer cannot be replayed to another verifier or later.
+func ProvePossession(group *schnorr.Group, secret *big.Int, nym *Nym,
+	context *big.Int) (*schnorr.Proof, error) {
+	return schnorr.ProveDLogKnowledge(group, []*big.Int{secret}, []*big.Int{nym.A}, nym.B,
+		context)
+}
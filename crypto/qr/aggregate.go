@@ -0,0 +1,166 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package qr
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/common"
+)
+
+// RepresentationStatement is a statement y = g_1^x_1 * ... * g_k^x_k in a RSASpecial
+// group, to be proved within an aggregate proof. Secrets x_i are given by their
+// indices among all secrets of the aggregate proof, so statements that refer to
+// the same secret share it.
+type RepresentationStatement struct {
+	Group   *RSASpecial
+	Bases   []*big.Int
+	Y       *big.Int
+	Secrets []int // indices of secrets, one for each base
+}
+
+// checkStatements checks that statements refer to secrets among nSecrets secrets.
+func checkStatements(statements []*RepresentationStatement, nSecrets int) error {
+	for i, s := range statements {
+		if len(s.Bases) != len(s.Secrets) {
+			return fmt.Errorf("statement %d: the number of bases and secrets differ", i)
+		}
+		for _, j := range s.Secrets {
+			if j < 0 || j >= nSecrets {
+				return fmt.Errorf("statement %d: no secret with index %d", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// AggregateProver proves knowledge of representations of several statements, possibly
+// in different groups, with a single challenge (an AND composition of
+// RepresentationProvers). A secret shared by statements has a single response, which
+// also proves that the statements share it. As in RepresentationProver, responses
+// are computed in Z.
+type AggregateProver struct {
+	statements []*RepresentationStatement
+	secrets    []*big.Int
+	randomVals []*big.Int
+}
+
+func NewAggregateProver(statements []*RepresentationStatement,
+	secrets []*big.Int) (*AggregateProver, error) {
+	if err := checkStatements(statements, len(secrets)); err != nil {
+		return nil, err
+	}
+
+	return &AggregateProver{
+		statements: statements,
+		secrets:    secrets,
+	}, nil
+}
+
+// GetProofRandomData returns t_j = g_1^r_1 * ... * g_k^r_k for each statement, where
+// r_i is a random value of secret i of boundariesBitLength[i] bit length. If alsoNeg
+// is true, values r_i can be negative as well.
+func (p *AggregateProver) GetProofRandomData(boundariesBitLength []int,
+	alsoNeg bool) ([]*big.Int, error) {
+	if len(boundariesBitLength) != len(p.secrets) {
+		return nil, fmt.Errorf("the length of boundariesBitLength should be the same as the number of secrets")
+	}
+
+	p.randomVals = make([]*big.Int, len(p.secrets))
+	b := new(big.Int)
+	for i := range p.randomVals {
+		b.Lsh(big.NewInt(1), uint(boundariesBitLength[i]))
+		if alsoNeg {
+			p.randomVals[i] = common.GetRandomIntAlsoNeg(b)
+		} else {
+			p.randomVals[i] = common.GetRandomInt(b)
+		}
+	}
+
+	proofRandomData := make([]*big.Int, len(p.statements))
+	for j, s := range p.statements {
+		vals := make([]*big.Int, len(s.Secrets))
+		for k, i := range s.Secrets {
+			vals[k] = p.randomVals[i]
+		}
+		proofRandomData[j] = common.MultiExp(new(big.Int), s.Bases, vals, s.Group.N)
+	}
+
+	return proofRandomData, nil
+}
+
+// GetProofData returns responses z_i = r_i + challenge * x_i (in Z), one for each secret.
+func (p *AggregateProver) GetProofData(challenge *big.Int) []*big.Int {
+	proofData := make([]*big.Int, len(p.secrets))
+	for i := range proofData {
+		z := new(big.Int).Mul(challenge, p.secrets[i])
+		proofData[i] = z.Add(z, p.randomVals[i])
+	}
+	return proofData
+}
+
+// AggregateProof is a proof of representation statements, where the challenge is
+// generated by the prover via Fiat-Shamir. Unlike RepresentationProof, it does not
+// hold proof random data, which the verifier recomputes from the challenge and the
+// responses, so the proof consists of a single challenge and a response for each
+// secret.
+type AggregateProof struct {
+	Challenge *big.Int
+	ProofData []*big.Int
+}
+
+func NewAggregateProof(challenge *big.Int, proofData []*big.Int) *AggregateProof {
+	return &AggregateProof{
+		Challenge: challenge,
+		ProofData: proofData,
+	}
+}
+
+// Verify verifies proof of statements. challenge computes the challenge from proof
+// random data of statements in the same way as the prover did.
+func (proof *AggregateProof) Verify(statements []*RepresentationStatement,
+	challenge func(proofRandomData []*big.Int) *big.Int) bool {
+	if proof.Challenge == nil || checkStatements(statements, len(proof.ProofData)) != nil {
+		return false
+	}
+	for _, z := range proof.ProofData {
+		if z == nil {
+			return false
+		}
+	}
+
+	// t_j = g_1^z_1 * ... * g_k^z_k * y^(-challenge)
+	negChallenge := new(big.Int).Neg(proof.Challenge)
+	proofRandomData := make([]*big.Int, len(statements))
+	yc := common.GetInt()
+	defer common.PutInt(yc)
+	for j, s := range statements {
+		zs := make([]*big.Int, len(s.Secrets))
+		for k, i := range s.Secrets {
+			zs[k] = proof.ProofData[i]
+		}
+		t := common.MultiExp(new(big.Int), s.Bases, zs, s.Group.N)
+		if t == nil || common.ExpMod(yc, s.Y, negChallenge, s.Group.N) == nil {
+			return false
+		}
+		proofRandomData[j] = common.MulMod(t, t, yc, s.Group.N)
+	}
+
+	return proof.Challenge.Cmp(challenge(proofRandomData)) == 0
+}
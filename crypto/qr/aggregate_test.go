@@ -0,0 +1,77 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package qr_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/qr"
+)
+
+// TestAggregateProof demonstrates how to prove representations of y_1 = g_1^x_1 * g_2^x_2
+// and y_2 = h_1^x_2 * h_2^x_3 in different groups with a single challenge, proving
+// that both share x_2.
+func TestAggregateProof(t *testing.T) {
+	var statements []*qr.RepresentationStatement
+	secrets := []*big.Int{big.NewInt(11), big.NewInt(22), big.NewInt(33)}
+	for _, indices := range [][]int{{0, 1}, {1, 2}} {
+		group, err := qr.NewRSASpecial(128)
+		require.NoError(t, err)
+		s := &qr.RepresentationStatement{
+			Group:   group,
+			Y:       big.NewInt(1),
+			Secrets: indices,
+		}
+		for _, i := range indices {
+			g, err := group.GetRandomGenerator()
+			require.NoError(t, err)
+			s.Bases = append(s.Bases, g)
+			s.Y = group.Mul(s.Y, group.Exp(g, secrets[i]))
+		}
+		statements = append(statements, s)
+	}
+
+	challenge := func(proofRandomData []*big.Int) *big.Int {
+		return common.Hash(proofRandomData...)
+	}
+
+	prover, err := qr.NewAggregateProver(statements, secrets)
+	require.NoError(t, err)
+	proofRandomData, err := prover.GetProofRandomData([]int{200, 200, 200}, true)
+	require.NoError(t, err)
+	c := challenge(proofRandomData)
+	proof := qr.NewAggregateProof(c, prover.GetProofData(c))
+	assert.True(t, proof.Verify(statements, challenge), "aggregate proof failed")
+
+	// the statements do not share a secret
+	statements[1].Secrets = []int{2, 1}
+	assert.False(t, proof.Verify(statements, challenge))
+	statements[1].Secrets = []int{1, 3}
+	assert.False(t, proof.Verify(statements, challenge))
+	statements[1].Secrets = []int{1, 2}
+
+	proof.ProofData[0].Add(proof.ProofData[0], big.NewInt(1))
+	assert.False(t, proof.Verify(statements, challenge))
+
+	_, err = qr.NewAggregateProver(statements, secrets[:2])
+	assert.Error(t, err)
+}
@@ -61,21 +61,16 @@ func (p *RepresentationProver) GetProofRandomData(alsoNeg bool) *big.Int {
 	nLen := p.group.N.BitLen()
 	exp := big.NewInt(int64(nLen + p.secParam))
 	b := new(big.Int).Exp(big.NewInt(2), exp, nil)
-	t := big.NewInt(1)
 	var randomVals = make([]*big.Int, len(p.bases))
 	for i, _ := range randomVals {
-		var r *big.Int
 		if alsoNeg {
-			r = common.GetRandomIntAlsoNeg(b)
+			randomVals[i] = common.GetRandomIntAlsoNeg(b)
 		} else {
-			r = common.GetRandomInt(b)
+			randomVals[i] = common.GetRandomInt(b)
 		}
-		randomVals[i] = r
-		f := p.group.Exp(p.bases[i], r)
-		t = p.group.Mul(t, f)
 	}
 	p.randomVals = randomVals
-	return t
+	return common.MultiExp(new(big.Int), p.bases, randomVals, p.group.N)
 }
 
 // GetProofRandomDataGivenBoundaries returns t = g_1^r_1 * ... * g_k^r_k where g_i are bases and each r_i is a
@@ -85,23 +80,18 @@ func (p *RepresentationProver) GetProofRandomDataGivenBoundaries(boundariesBitLe
 	if len(boundariesBitLength) != len(p.bases) {
 		return nil, fmt.Errorf("the length of boundariesBitLength should be the same as the number of bases")
 	}
-	t := big.NewInt(1)
 	var randomVals = make([]*big.Int, len(p.bases))
+	b := new(big.Int)
 	for i, _ := range randomVals {
-		exp := big.NewInt(int64(boundariesBitLength[i]))
-		b := new(big.Int).Exp(big.NewInt(2), exp, nil)
-		var r *big.Int
+		b.Lsh(big.NewInt(1), uint(boundariesBitLength[i])) // 2^boundariesBitLength[i]
 		if alsoNeg {
-			r = common.GetRandomIntAlsoNeg(b)
+			randomVals[i] = common.GetRandomIntAlsoNeg(b)
 		} else {
-			r = common.GetRandomInt(b)
+			randomVals[i] = common.GetRandomInt(b)
 		}
-		randomVals[i] = r
-		f := p.group.Exp(p.bases[i], r)
-		t = p.group.Mul(t, f)
 	}
 	p.randomVals = randomVals
-	return t, nil
+	return common.MultiExp(new(big.Int), p.bases, randomVals, p.group.N), nil
 }
 
 func (p *RepresentationProver) GetProofData(challenge *big.Int) []*big.Int {
@@ -174,14 +164,19 @@ func (v *RepresentationVerifier) SetChallenge(challenge *big.Int) {
 func (v *RepresentationVerifier) Verify(proofData []*big.Int) bool {
 	// check:
 	// g_1^z_1 * ... * g_k^z_k = (g_1^x_1 * ... * g_k^x_k)^challenge * (g_1^r_1 * ... * g_k^r_k)
-	left := big.NewInt(1)
-	for i := 0; i < len(v.bases); i++ {
-		t := v.group.Exp(v.bases[i], proofData[i])
-		left = v.group.Mul(left, t)
+	if len(proofData) != len(v.bases) {
+		return false
+	}
+	left, right := common.GetInt(), common.GetInt()
+	defer common.PutInt(left, right)
+	if common.MultiExp(left, v.bases, proofData, v.group.N) == nil { // base is not invertible
+		return false
 	}
 
-	right := v.group.Exp(v.y, v.challenge)
-	right = v.group.Mul(right, v.proofRandomData)
+	if common.ExpMod(right, v.y, v.challenge, v.group.N) == nil {
+		return false
+	}
+	common.MulMod(right, right, v.proofRandomData, v.group.N)
 
 	return left.Cmp(right) == 0
 }
@@ -18,9 +18,10 @@
 package qr
 
 import (
+	"fmt"
 	"math/big"
 
-	"fmt"
+	"github.com/xlab-si/emmy/crypto/common"
 )
 
 // RSA presents QR_N - group of quadratic residues modulo N where N is a product
@@ -65,9 +66,7 @@ func (g *RSA) Add(x, y *big.Int) *big.Int {
 
 // Mul computes x * y in QR_N. This means x * y mod N.
 func (g *RSA) Mul(x, y *big.Int) *big.Int {
-	r := new(big.Int)
-	r.Mul(x, y)
-	return r.Mod(r, g.N)
+	return common.MulMod(new(big.Int), x, y, g.N)
 }
 
 // Inv computes inverse of x in QR_N. This means xInv such that x * xInv = 1 mod N.
@@ -76,14 +75,9 @@ func (g *RSA) Inv(x *big.Int) *big.Int {
 }
 
 // Exp computes base^exponent in QR_N. This means base^exponent mod rsa.N.
+// For negative exponent and non-invertible base nil is returned.
 func (g *RSA) Exp(base, exponent *big.Int) *big.Int {
-	expAbs := new(big.Int).Abs(exponent)
-	if expAbs.Cmp(exponent) == 0 {
-		return new(big.Int).Exp(base, exponent, g.N)
-	} else {
-		t := new(big.Int).Exp(base, expAbs, g.N)
-		return g.Inv(t)
-	}
+	return common.ExpMod(new(big.Int), base, exponent, g.N)
 }
 
 // IsElementInGroup returns true if a is in QR_N and false otherwise.
@@ -105,3 +99,33 @@ func (g *RSA) IsElementInGroup(a *big.Int) (bool, error) {
 	}
 	return true, nil
 }
+
+// CheckElement returns an error wrapping common.ErrNotInGroup if a, received from
+// another party, is not an element of QR_N. When the factors of N are known, the
+// check is exact. Otherwise a needs to be invertible modulo N with Jacobi symbol 1,
+// which rejects half of Z_N^* - elements with Jacobi symbol 1 that are not
+// quadratic residues cannot be told apart without the factorization of N.
+func (g *RSA) CheckElement(a *big.Int) error {
+	if a == nil || a.Sign() <= 0 || a.Cmp(g.N) >= 0 {
+		return fmt.Errorf("%w: out of range of group elements", common.ErrNotInGroup)
+	}
+	if new(big.Int).GCD(nil, nil, a, g.N).Cmp(big.NewInt(1)) != 0 {
+		return fmt.Errorf("%w: not invertible modulo N", common.ErrNotInGroup)
+	}
+	if big.Jacobi(a, g.N) != 1 {
+		return fmt.Errorf("%w: Jacobi symbol is not 1", common.ErrNotInGroup)
+	}
+	if g.P == nil {
+		return nil
+	}
+
+	isQR, err := g.IsElementInGroup(a)
+	if err != nil {
+		return err
+	}
+	if !isQR {
+		return fmt.Errorf("%w: not a quadratic residue", common.ErrNotInGroup)
+	}
+
+	return nil
+}
@@ -18,6 +18,7 @@
 package qr
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -36,7 +37,14 @@ type RSASpecial struct {
 }
 
 func NewRSASpecial(safePrimeBitLength int) (*RSASpecial, error) {
-	specialRSAPrimes, err := GetRSASpecialPrimes(safePrimeBitLength)
+	return NewRSASpecialContext(context.Background(), safePrimeBitLength, nil)
+}
+
+// NewRSASpecialContext is like NewRSASpecial, but the search for primes can be
+// cancelled with ctx and reports progress to s (if not nil).
+func NewRSASpecialContext(ctx context.Context, safePrimeBitLength int,
+	s *common.PrimeSearch) (*RSASpecial, error) {
+	specialRSAPrimes, err := GetRSASpecialPrimesContext(ctx, safePrimeBitLength, s)
 	if err != nil {
 		return nil, err
 	}
@@ -138,20 +146,31 @@ func NewRSASpecialPrimes(P, Q, p, q *big.Int) *RSASpecialPrimes {
 
 // GetRSASpecialPrimes returns primes P, Q, p, q such that P = 2*p + 1 and Q = 2*q + 1.
 func GetRSASpecialPrimes(bits int) (*RSASpecialPrimes, error) {
-	p1 := common.GetGermainPrime(bits - 1)
-	p := big.NewInt(0)
-	p.Mul(p1, big.NewInt(2))
-	p.Add(p, big.NewInt(1))
+	return GetRSASpecialPrimesContext(context.Background(), bits, nil)
+}
 
-	q1 := common.GetGermainPrime(bits - 1)
-	q := big.NewInt(0)
-	q.Mul(q1, big.NewInt(2))
+// GetRSASpecialPrimesContext is like GetRSASpecialPrimes, but primes are searched for
+// with s (using all available cores if s is nil) and the search is stopped with
+// ctx.Err() once ctx is done.
+func GetRSASpecialPrimesContext(ctx context.Context, bits int,
+	s *common.PrimeSearch) (*RSASpecialPrimes, error) {
+	if s == nil {
+		s = new(common.PrimeSearch)
+	}
+	germainPrimes, err := s.GermainPrimes(ctx, bits-1, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	p1, q1 := germainPrimes[0], germainPrimes[1]
+	p := new(big.Int).Lsh(p1, 1)
+	p.Add(p, big.NewInt(1))
+	q := new(big.Int).Lsh(q1, 1)
 	q.Add(q, big.NewInt(1))
 
-	if p.BitLen() == bits && q.BitLen() == bits {
-		return NewRSASpecialPrimes(p, q, p1, q1), nil
-	} else {
-		err := fmt.Errorf("bit length not correct")
-		return NewRSASpecialPrimes(nil, nil, nil, nil), err
+	if p.BitLen() != bits || q.BitLen() != bits {
+		return nil, fmt.Errorf("bit length not correct")
 	}
+
+	return NewRSASpecialPrimes(p, q, p1, q1), nil
 }
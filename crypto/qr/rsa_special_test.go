@@ -18,10 +18,12 @@
 package qr_test
 
 import (
+	"errors"
 	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/qr"
 )
 
@@ -48,3 +50,30 @@ func TestGeneratorOfCompositeQR(t *testing.T) {
 	tmp = new(big.Int).Exp(g, rsa.Q1, rsa.N)
 	assert.NotEqual(t, tmp, big.NewInt(1), "g is not a generator")
 }
+
+func TestRSACheckElement(t *testing.T) {
+	group, err := qr.NewRSASpecial(256)
+	if err != nil {
+		t.Errorf("Error when instantiating RSASpecial: %v", err)
+	}
+	public := qr.NewRSApecialPublic(group.N)
+
+	el, err := group.GetRandomElement()
+	assert.NoError(t, err)
+	assert.NoError(t, group.CheckElement(el))
+	assert.NoError(t, public.CheckElement(el))
+
+	// N-1 has Jacobi symbol 1, but is not a quadratic residue, which can only be
+	// detected with the factors of N
+	nMinOne := new(big.Int).Sub(group.N, big.NewInt(1))
+	assert.True(t, errors.Is(group.CheckElement(nMinOne), common.ErrNotInGroup))
+	assert.NoError(t, public.CheckElement(nMinOne))
+
+	for _, a := range []*big.Int{nil, big.NewInt(0), group.N, group.P,
+		new(big.Int).Neg(el)} {
+		for _, g := range []*qr.RSASpecial{group, public} {
+			err := g.CheckElement(a)
+			assert.True(t, errors.Is(err, common.ErrNotInGroup), "%v", a)
+		}
+	}
+}
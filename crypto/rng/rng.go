@@ -0,0 +1,232 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package rng provides sources of randomness for the protocols of emmy, whose security
+// depends on the quality of the nonces and secrets drawn from them. Any source, such as
+// the random number generator of the operating system, a hardware RNG or a DRBG, can be
+// wrapped with Reader, which checks its output with the health tests of NIST SP 800-90B
+// (the repetition count test and the adaptive proportion test) when it is created and
+// continuously afterwards, and stops providing randomness once a test fails. Readers
+// can be made the source of randomness of emmy with common.SetRandReader.
+package rng
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+// ErrHealthTest is returned by readers whose source failed a health test.
+var ErrHealthTest = errors.New("source of randomness failed health tests")
+
+const (
+	// falsePositiveLog is -log2 of the probability that a health test fails for a
+	// source with the assessed entropy, per sample for the repetition count test and
+	// per window for the adaptive proportion test.
+	falsePositiveLog = 40
+	// aptWindow is the number of samples (bytes) in a window of the adaptive
+	// proportion test, as required for sources that are not binary.
+	aptWindow = 512
+	// startupSamples is the number of samples tested, and discarded, when a reader
+	// is created.
+	startupSamples = 1024
+)
+
+// Reader reads random bytes from a source and checks them with health tests. Once a
+// test fails, reads of the reader return ErrHealthTest (or panic, see
+// WithPanicOnFailure), and no longer return bytes of the source. Reader is safe for
+// concurrent use.
+type Reader struct {
+	src            io.Reader
+	minEntropy     float64 // assessed entropy of the source, in bits per byte
+	panicOnFailure bool
+
+	sync.Mutex
+	rct repetitionCountTest
+	apt adaptiveProportionTest
+	err error
+}
+
+// Option configures a Reader.
+type Option func(*Reader)
+
+// WithMinEntropy sets the assessed entropy of the source, in bits per byte, that the
+// health tests are calibrated with. It is 8 (full entropy) by default, which suits
+// conditioned sources such as the RNG of the operating system and DRBGs, while raw
+// noise of hardware RNGs may need a lower value.
+func WithMinEntropy(h float64) Option {
+	return func(r *Reader) {
+		r.minEntropy = h
+	}
+}
+
+// WithPanicOnFailure makes the reader panic once a health test fails, rather than
+// return ErrHealthTest, so that callers that do not check errors cannot continue
+// with bad randomness.
+func WithPanicOnFailure() Option {
+	return func(r *Reader) {
+		r.panicOnFailure = true
+	}
+}
+
+// New returns a Reader of src, after running the start-up health tests on its first
+// bytes, which are discarded. An error is returned if src fails them.
+func New(src io.Reader, opts ...Option) (*Reader, error) {
+	r := &Reader{
+		src:        src,
+		minEntropy: 8,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.minEntropy <= 0 || r.minEntropy > 8 {
+		return nil, fmt.Errorf("entropy of the source must be in (0, 8] bits per byte")
+	}
+	r.rct.cutoff = 1 + int(math.Ceil(falsePositiveLog/r.minEntropy))
+	r.apt.cutoff = aptCutoff(r.minEntropy)
+
+	buf := make([]byte, startupSamples)
+	if _, err := io.ReadFull(src, buf); err != nil {
+		return nil, fmt.Errorf("cannot read from the source of randomness: %v", err)
+	}
+	if err := r.test(buf); err != nil {
+		return nil, fmt.Errorf("%w: start-up %v", ErrHealthTest, err)
+	}
+
+	return r, nil
+}
+
+// Read reads random bytes from the source of r into p, once they passed the health
+// tests.
+func (r *Reader) Read(p []byte) (int, error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.err != nil {
+		return 0, r.fail()
+	}
+
+	n, err := r.src.Read(p)
+	if testErr := r.test(p[:n]); testErr != nil {
+		r.err = testErr
+		// bytes that failed the tests are not passed on
+		for i := range p[:n] {
+			p[i] = 0
+		}
+		return 0, r.fail()
+	}
+	return n, err
+}
+
+// Err returns the error of the health test the source of r failed, or nil if it
+// passed all the tests so far.
+func (r *Reader) Err() error {
+	r.Lock()
+	defer r.Unlock()
+	return r.err
+}
+
+// fail returns ErrHealthTest, or panics with it if r was configured to.
+func (r *Reader) fail() error {
+	err := fmt.Errorf("%w: %v", ErrHealthTest, r.err)
+	if r.panicOnFailure {
+		panic(err)
+	}
+	return err
+}
+
+// test runs the health tests on samples, returning the error of the first test that
+// fails.
+func (r *Reader) test(samples []byte) error {
+	for _, s := range samples {
+		if !r.rct.add(s) {
+			return fmt.Errorf("repetition count test failed: %d identical bytes",
+				r.rct.cutoff)
+		}
+		if !r.apt.add(s) {
+			return fmt.Errorf("adaptive proportion test failed: %d of %d bytes identical",
+				r.apt.cutoff, aptWindow)
+		}
+	}
+	return nil
+}
+
+// repetitionCountTest detects sources stuck at a single value (SP 800-90B, 4.4.1).
+type repetitionCountTest struct {
+	cutoff int
+	last   byte
+	count  int
+}
+
+// add adds sample s to the test and returns false if the test failed.
+func (t *repetitionCountTest) add(s byte) bool {
+	if t.count > 0 && s == t.last {
+		t.count++
+		return t.count < t.cutoff
+	}
+	t.last, t.count = s, 1
+	return true
+}
+
+// adaptiveProportionTest detects sources producing a value much more often than
+// expected (SP 800-90B, 4.4.2).
+type adaptiveProportionTest struct {
+	cutoff int
+	first  byte
+	count  int // occurrences of first in the current window
+	seen   int // samples of the current window
+}
+
+// add adds sample s to the test and returns false if the test failed.
+func (t *adaptiveProportionTest) add(s byte) bool {
+	if t.seen == 0 || t.seen == aptWindow {
+		t.first, t.count, t.seen = s, 1, 1
+		return true
+	}
+	t.seen++
+	if s == t.first {
+		t.count++
+	}
+	return t.count < t.cutoff
+}
+
+// aptCutoff returns the cutoff of the adaptive proportion test for a source with
+// entropy h in bits per byte: one more than the smallest k such that a value occurs
+// more than k times in a window with probability at most 2^-falsePositiveLog.
+func aptCutoff(h float64) int {
+	p := math.Exp2(-h)
+	logPMF := func(k int) float64 {
+		n := float64(aptWindow)
+		lgN, _ := math.Lgamma(n + 1)
+		lgK, _ := math.Lgamma(float64(k) + 1)
+		lgNK, _ := math.Lgamma(n - float64(k) + 1)
+		return lgN - lgK - lgNK + float64(k)*math.Log(p) + (n-float64(k))*math.Log1p(-p)
+	}
+
+	alpha := math.Exp2(-falsePositiveLog)
+	crit := aptWindow
+	tail := 0.0 // probability of more than crit occurrences
+	for k := aptWindow; k > 0; k-- {
+		tail += math.Exp(logPMF(k))
+		if tail > alpha {
+			break
+		}
+		crit = k - 1
+	}
+	return 1 + crit
+}
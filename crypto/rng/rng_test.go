@@ -0,0 +1,112 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package rng
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingSource returns random bytes until it has returned n of them, and then bytes
+// produced by next.
+type failingSource struct {
+	n    int
+	next func(i int) byte
+	i    int
+}
+
+func (s *failingSource) Read(p []byte) (int, error) {
+	for j := range p {
+		if s.n > 0 {
+			rand.Read(p[j : j+1])
+			s.n--
+		} else {
+			p[j] = s.next(s.i)
+			s.i++
+		}
+	}
+	return len(p), nil
+}
+
+func TestReader(t *testing.T) {
+	r, err := New(rand.Reader)
+	require.NoError(t, err)
+
+	buf := make([]byte, 1<<20)
+	_, err = io.ReadFull(r, buf)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Err())
+}
+
+func TestCutoffs(t *testing.T) {
+	// cutoffs of SP 800-90B for a false positive probability of 2^-40
+	for h, cutoffs := range map[float64][2]int{8: {6, 19}, 4: {11, 78}, 1: {41, 336}} {
+		r, err := New(rand.Reader, WithMinEntropy(h))
+		require.NoError(t, err)
+		assert.Equal(t, cutoffs[0], r.rct.cutoff)
+		assert.Equal(t, cutoffs[1], r.apt.cutoff)
+	}
+
+	_, err := New(rand.Reader, WithMinEntropy(9))
+	assert.Error(t, err)
+}
+
+func TestStartupTests(t *testing.T) {
+	stuck := &failingSource{next: func(int) byte { return 0x42 }}
+	_, err := New(stuck)
+	assert.True(t, errors.Is(err, ErrHealthTest), "unexpected error %v", err)
+
+	// every other byte is the same, which the repetition count test does not detect
+	biased := &failingSource{next: func(i int) byte {
+		if i%2 == 0 {
+			return 0
+		}
+		return byte(i%255 + 1)
+	}}
+	_, err = New(biased)
+	assert.True(t, errors.Is(err, ErrHealthTest), "unexpected error %v", err)
+}
+
+func TestContinuousTests(t *testing.T) {
+	src := &failingSource{n: startupSamples + 4096, next: func(int) byte { return 0x42 }}
+	r, err := New(src)
+	require.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	for err == nil {
+		_, err = r.Read(buf)
+	}
+	assert.True(t, errors.Is(err, ErrHealthTest), "unexpected error %v", err)
+	assert.Error(t, r.Err())
+	// bytes of the source are not returned once it failed
+	assert.Equal(t, make([]byte, len(buf)), buf)
+	_, err = r.Read(buf)
+	assert.True(t, errors.Is(err, ErrHealthTest))
+
+	src = &failingSource{n: startupSamples, next: func(int) byte { return 0 }}
+	r, err = New(src, WithPanicOnFailure())
+	require.NoError(t, err)
+	assert.Panics(t, func() {
+		r.Read(buf)
+	})
+}
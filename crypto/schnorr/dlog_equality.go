@@ -127,3 +127,57 @@ func (v *EqualityVerifier) Verify(z *big.Int) bool {
 
 	return left1.Cmp(right1) == 0 && left2.Cmp(right2) == 0
 }
+
+// EqualityProof presents all messages of the proof that log_g1(t1) = log_g2(t2), where the
+// challenge is generated by the prover via Fiat-Shamir.
+type EqualityProof struct {
+	ProofRandomData1 *big.Int
+	ProofRandomData2 *big.Int
+	Challenge        *big.Int
+	ProofData        *big.Int
+}
+
+// ProveDLogEquality returns a non-interactive proof of knowledge of secret such that
+// t1 = g1^secret and t2 = g2^secret. Context (for example a nonce of the verifier) is
+// bound to the proof to prevent its reuse in another context.
+func ProveDLogEquality(group *Group, secret, g1, g2, t1, t2,
+	context *big.Int) *EqualityProof {
+	prover := NewEqualityProver(group)
+	x1, x2 := prover.GetProofRandomData(secret, g1, g2)
+	challenge := fiatShamirChallenge(group, context, g1, g2, t1, t2, x1, x2)
+
+	return &EqualityProof{
+		ProofRandomData1: x1,
+		ProofRandomData2: x2,
+		Challenge:        challenge,
+		ProofData:        prover.GetProofData(challenge),
+	}
+}
+
+// VerifyDLogEquality verifies a proof created with ProveDLogEquality that
+// log_g1(t1) = log_g2(t2).
+func VerifyDLogEquality(group *Group, proof *EqualityProof, g1, g2, t1, t2,
+	context *big.Int) bool {
+	if proof == nil || !allSet([]*big.Int{proof.ProofRandomData1, proof.ProofRandomData2,
+		proof.Challenge, proof.ProofData}) {
+		return false
+	}
+
+	challenge := fiatShamirChallenge(group, context, g1, g2, t1, t2,
+		proof.ProofRandomData1, proof.ProofRandomData2)
+	if challenge.Cmp(proof.Challenge) != 0 {
+		return false
+	}
+
+	verifier := &EqualityVerifier{
+		Group:     group,
+		challenge: challenge,
+		g1:        g1,
+		g2:        g2,
+		x1:        proof.ProofRandomData1,
+		x2:        proof.ProofRandomData2,
+		t1:        t1,
+		t2:        t2,
+	}
+	return verifier.Verify(proof.ProofData)
+}
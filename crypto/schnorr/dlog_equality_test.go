@@ -18,6 +18,7 @@
 package schnorr
 
 import (
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -39,3 +40,25 @@ func TestDLogEquality(t *testing.T) {
 
 	assert.Equal(t, proved, true, "dlog equality proof does not work")
 }
+
+func TestDLogEqualityNI(t *testing.T) {
+	group, _ := NewGroup(256)
+	zp, _ := zn.NewGroupZp(group.P)
+
+	secret := common.GetRandomInt(group.Q)
+	g1, _ := zp.GetGeneratorOfSubgroup(group.Q)
+	g2, _ := zp.GetGeneratorOfSubgroup(group.Q)
+	t1 := group.Exp(g1, secret)
+	t2 := group.Exp(g2, secret)
+	context := common.GetRandomInt(group.Q)
+
+	proof := ProveDLogEquality(group, secret, g1, g2, t1, t2, context)
+	assert.True(t, VerifyDLogEquality(group, proof, g1, g2, t1, t2, context),
+		"non-interactive dlog equality proof does not work")
+
+	assert.False(t, VerifyDLogEquality(group, proof, g1, g2, t1, t2,
+		new(big.Int).Add(context, big.NewInt(1))), "proof should be bound to its context")
+	assert.False(t, VerifyDLogEquality(group, proof, g1, g2, t1, group.Mul(t2, g2), context),
+		"proof should not verify when dlogs differ")
+	assert.False(t, VerifyDLogEquality(group, &EqualityProof{}, g1, g2, t1, t2, context))
+}
@@ -130,9 +130,15 @@ func (v *Verifier) SetChallenge(challenge *big.Int) {
 func (v *Verifier) Verify(proofData []*big.Int) bool {
 	// check:
 	// g_1^z_1 * ... * g_k^z_k = (g_1^x_1 * ... * g_k^x_k)^challenge * (g_1^r_1 * ... * g_k^r_k)
+	if len(proofData) != len(v.bases) {
+		return false
+	}
 	left := big.NewInt(1)
 	for i := 0; i < len(v.bases); i++ {
 		t := v.Group.Exp(v.bases[i], proofData[i])
+		if t == nil { // base is not invertible
+			return false
+		}
 		left = v.Group.Mul(left, t)
 	}
 
@@ -141,3 +147,59 @@ func (v *Verifier) Verify(proofData []*big.Int) bool {
 
 	return left.Cmp(right) == 0
 }
+
+// ProveDLogKnowledge returns a non-interactive proof of knowledge of secrets x_1,...,x_k
+// such that y = g_1^x_1 * ... * g_k^x_k where g_i are bases. The challenge is derived by
+// the prover via Fiat-Shamir. Context (for example a nonce of the verifier) is bound
+// to the proof to prevent its reuse in another context.
+func ProveDLogKnowledge(group *Group, secrets, bases []*big.Int,
+	y, context *big.Int) (*Proof, error) {
+	prover, err := NewProver(group, secrets, bases, y)
+	if err != nil {
+		return nil, err
+	}
+
+	proofRandomData := prover.GetProofRandomData()
+	challenge := fiatShamirChallenge(group, context,
+		append([]*big.Int{y, proofRandomData}, bases...)...)
+
+	return NewProof(proofRandomData, challenge, prover.GetProofData(challenge)), nil
+}
+
+// VerifyDLogKnowledge verifies a proof created with ProveDLogKnowledge that the prover
+// knows a representation of y with respect to bases.
+func VerifyDLogKnowledge(group *Group, proof *Proof, bases []*big.Int,
+	y, context *big.Int) bool {
+	if proof == nil || proof.ProofRandomData == nil || proof.Challenge == nil ||
+		!allSet(proof.ProofData) {
+		return false
+	}
+
+	challenge := fiatShamirChallenge(group, context,
+		append([]*big.Int{y, proof.ProofRandomData}, bases...)...)
+	if challenge.Cmp(proof.Challenge) != 0 {
+		return false
+	}
+
+	verifier := NewVerifier(group)
+	verifier.SetProofRandomData(proof.ProofRandomData, bases, y)
+	verifier.SetChallenge(challenge)
+	return verifier.Verify(proof.ProofData)
+}
+
+// fiatShamirChallenge derives a challenge from values of a proof and context,
+// by hashing them into an integer modulo the order of group.
+func fiatShamirChallenge(group *Group, context *big.Int, values ...*big.Int) *big.Int {
+	h := common.Hash(append(values, context)...)
+	return h.Mod(h, group.Q)
+}
+
+// allSet reports whether none of ints is nil.
+func allSet(ints []*big.Int) bool {
+	for _, i := range ints {
+		if i == nil {
+			return false
+		}
+	}
+	return true
+}
@@ -66,3 +66,36 @@ func TestDLogKnowledge(t *testing.T) {
 
 	assert.Equal(t, verified, true, "dlog knowledge proof does not work")
 }
+
+func TestDLogKnowledgeNI(t *testing.T) {
+	group, err := NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+
+	bases := make([]*big.Int, 3)
+	secrets := make([]*big.Int, 3)
+	y := big.NewInt(1)
+	for i := range bases {
+		bases[i] = group.Exp(group.G, common.GetRandomInt(group.Q))
+		secrets[i] = common.GetRandomInt(group.Q)
+		y = group.Mul(y, group.Exp(bases[i], secrets[i]))
+	}
+	context := common.GetRandomInt(group.Q)
+
+	proof, err := ProveDLogKnowledge(group, secrets, bases, y, context)
+	assert.Nil(t, err)
+	assert.True(t, VerifyDLogKnowledge(group, proof, bases, y, context),
+		"non-interactive dlog knowledge proof does not work")
+
+	otherContext := new(big.Int).Add(context, big.NewInt(1))
+	assert.False(t, VerifyDLogKnowledge(group, proof, bases, y, otherContext),
+		"proof should be bound to its context")
+	assert.False(t, VerifyDLogKnowledge(group, proof, bases, group.Mul(y, group.G), context),
+		"proof should not verify for another statement")
+
+	proof.ProofData[0] = new(big.Int).Add(proof.ProofData[0], big.NewInt(1))
+	assert.False(t, VerifyDLogKnowledge(group, proof, bases, y, context),
+		"tampered proof should not verify")
+	assert.False(t, VerifyDLogKnowledge(group, &Proof{}, bases, y, context))
+}
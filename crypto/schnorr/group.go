@@ -93,20 +93,19 @@ func (g *Group) Add(x, y *big.Int) *big.Int {
 
 // Mul computes x * y in Group. This means x * y mod group.P.
 func (g *Group) Mul(x, y *big.Int) *big.Int {
-	r := new(big.Int)
-	r.Mul(x, y)
-	return r.Mod(r, g.P)
+	return common.MulMod(new(big.Int), x, y, g.P)
 }
 
 // Exp computes base^exponent in Group. This means base^exponent mod group.P.
 func (g *Group) Exp(base, exponent *big.Int) *big.Int {
-	if exponent.Sign() == -1 { // exponent is negative
-		expAbs := new(big.Int).Abs(exponent)
-		t := new(big.Int).Exp(base, expAbs, g.P)
-		return g.Inv(t)
-	}
+	return common.ExpMod(new(big.Int), base, exponent, g.P)
+}
 
-	return new(big.Int).Exp(base, exponent, g.P)
+// BlindedExp computes base^exponent in Group like Exp, but with the exponent
+// blinded (see common.BlindedExpMod). It is meant for secret exponents and base
+// needs to be from the subgroup of order Q.
+func (g *Group) BlindedExp(base, exponent *big.Int) *big.Int {
+	return common.BlindedExpMod(new(big.Int), base, exponent, g.Q, g.P)
 }
 
 // Inv computes inverse of x in Group. This means xInv such that x * xInv = 1 mod group.P.
@@ -120,3 +119,43 @@ func (g *Group) IsElementInGroup(x *big.Int) bool {
 	check := g.Exp(x, g.Q) // should be 1
 	return check.Cmp(big.NewInt(1)) == 0
 }
+
+// CheckElement returns an error wrapping common.ErrNotInGroup if x, received from
+// another party, is not a non-identity element of the group: 1 < x < P and
+// x^Q = 1 mod P.
+func (g *Group) CheckElement(x *big.Int) error {
+	if x == nil || x.Cmp(big.NewInt(1)) <= 0 || x.Cmp(g.P) >= 0 {
+		return fmt.Errorf("%w: out of range of group elements", common.ErrNotInGroup)
+	}
+	if !g.IsElementInGroup(x) {
+		return fmt.Errorf("%w: not in the subgroup of order Q", common.ErrNotInGroup)
+	}
+
+	return nil
+}
+
+// Equals returns true if g and other have the same parameters.
+func (g *Group) Equals(other *Group) bool {
+	return g.P.Cmp(other.P) == 0 && g.G.Cmp(other.G) == 0 && g.Q.Cmp(other.Q) == 0
+}
+
+// Validate checks whether group parameters are consistent: P and Q are primes, Q divides
+// P-1 and G is an element of order Q.
+func (g *Group) Validate() error {
+	one := big.NewInt(1)
+	if !g.P.ProbablyPrime(20) {
+		return fmt.Errorf("group modulus P is not prime")
+	}
+	if !g.Q.ProbablyPrime(20) {
+		return fmt.Errorf("group order Q is not prime")
+	}
+	pMinOne := new(big.Int).Sub(g.P, one)
+	if new(big.Int).Mod(pMinOne, g.Q).Sign() != 0 {
+		return fmt.Errorf("group order Q does not divide P-1")
+	}
+	if g.G.Cmp(one) <= 0 || g.G.Cmp(g.P) >= 0 || !g.IsElementInGroup(g.G) {
+		return fmt.Errorf("generator G is not an element of order Q")
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package schnorr
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/common"
+)
+
+func TestGroupPresets(t *testing.T) {
+	for _, name := range []string{GroupPreset2048, GroupPreset3072} {
+		group, err := GetGroupPreset(name)
+		assert.NoError(t, err)
+		assert.NoError(t, group.Validate(), "group %s", name)
+		assert.Equal(t, name, GroupPresetName(group))
+	}
+	_, err := GetGroupPreset("dlog-1024")
+	assert.Error(t, err)
+}
+
+func TestGroupValidate(t *testing.T) {
+	group, err := NewGroup(256)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+	assert.NoError(t, group.Validate())
+	assert.Equal(t, "", GroupPresetName(group))
+
+	invalid := []*Group{
+		NewGroupFromParams(new(big.Int).Add(group.P, big.NewInt(2)), group.G, group.Q),
+		NewGroupFromParams(group.P, group.G, new(big.Int).Add(group.Q, big.NewInt(2))),
+		NewGroupFromParams(group.P, big.NewInt(1), group.Q),
+		NewGroupFromParams(group.P, new(big.Int).Sub(group.P, big.NewInt(1)), group.Q),
+	}
+	for _, g := range invalid {
+		assert.Error(t, g.Validate())
+	}
+}
+
+func TestGroupCheckElement(t *testing.T) {
+	group, err := NewGroup(160)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+	assert.NoError(t, group.CheckElement(group.GetRandomElement()))
+
+	pMinOne := new(big.Int).Sub(group.P, big.NewInt(1)) // has order 2
+	for _, x := range []*big.Int{nil, big.NewInt(0), big.NewInt(1), group.P, pMinOne} {
+		err := group.CheckElement(x)
+		assert.True(t, errors.Is(err, common.ErrNotInGroup), "%v", x)
+	}
+}
+
+func TestGroupBlindedExp(t *testing.T) {
+	group, err := NewGroup(160)
+	if err != nil {
+		t.Errorf("error when creating Schnorr group: %v", err)
+	}
+	x := group.GetRandomElement()
+	s := common.GetRandomInt(group.Q)
+	assert.Equal(t, group.Exp(x, s), group.BlindedExp(x, s))
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package did supports identifying issuers and holders of credentials by
+// Decentralized Identifiers (https://www.w3.org/TR/did-core/). Issuers' CL public
+// keys and holders' signing keys are obtained from DID documents through a Resolver.
+package did
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/jose"
+)
+
+// ContextV1 is the JSON-LD context of DID documents.
+const ContextV1 = "https://www.w3.org/ns/did/v1"
+
+// Types of verification methods.
+const (
+	// TypeJSONWebKey identifies verification methods holding a key in JWK format.
+	TypeJSONWebKey = "JsonWebKey2020"
+	// TypeCLPublicKey identifies verification methods holding a CL public key.
+	TypeCLPublicKey = "CLPublicKey2019"
+)
+
+// DID is a parsed decentralized identifier of the form did:<method>:<id>.
+type DID struct {
+	Method string
+	ID     string
+}
+
+// Parse parses a DID. DID URL parts (path, query, fragment) are stripped.
+func Parse(s string) (*DID, error) {
+	if i := strings.IndexAny(s, "/?#"); i >= 0 {
+		s = s[:i]
+	}
+
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 || parts[0] != "did" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("invalid DID: %s", s)
+	}
+
+	return &DID{
+		Method: parts[1],
+		ID:     parts[2],
+	}, nil
+}
+
+func (d *DID) String() string {
+	return fmt.Sprintf("did:%s:%s", d.Method, d.ID)
+}
+
+// Document is a DID document.
+type Document struct {
+	Context            []string              `json:"@context"`
+	ID                 string                `json:"id"`
+	VerificationMethod []*VerificationMethod `json:"verificationMethod"`
+	AssertionMethod    []string              `json:"assertionMethod,omitempty"`
+	Authentication     []string              `json:"authentication,omitempty"`
+}
+
+// VerificationMethod is a public key listed in a DID document.
+type VerificationMethod struct {
+	ID           string          `json:"id"`
+	Type         string          `json:"type"`
+	Controller   string          `json:"controller"`
+	PublicKeyJwk *jose.JWK       `json:"publicKeyJwk,omitempty"`
+	PublicKeyCL  json.RawMessage `json:"publicKeyCL,omitempty"`
+}
+
+// NewIssuerDocument returns a DID document of the issuer identified by did, which
+// publishes the issuer's CL public key. The document is meant to be served by the
+// issuer, for example as did.json of a did:web identifier.
+func NewIssuerDocument(did string, pubKey *cl.PubKey) (*Document, error) {
+	if _, err := Parse(did); err != nil {
+		return nil, err
+	}
+	key, err := json.Marshal(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	id := did + "#cl-key-1"
+	return &Document{
+		Context: []string{ContextV1},
+		ID:      did,
+		VerificationMethod: []*VerificationMethod{{
+			ID:          id,
+			Type:        TypeCLPublicKey,
+			Controller:  did,
+			PublicKeyCL: key,
+		}},
+		AssertionMethod: []string{id},
+	}, nil
+}
+
+// CLPubKey returns the first CL public key listed in d.
+func (d *Document) CLPubKey() (*cl.PubKey, error) {
+	for _, m := range d.VerificationMethod {
+		if m.Type == TypeCLPublicKey {
+			pubKey := new(cl.PubKey)
+			if err := json.Unmarshal(m.PublicKeyCL, pubKey); err != nil {
+				return nil, fmt.Errorf("invalid CL public key in %s: %v", m.ID, err)
+			}
+			return pubKey, nil
+		}
+	}
+
+	return nil, fmt.Errorf("DID document %s lists no CL public key", d.ID)
+}
+
+// ECDSAPubKey returns the first ECDSA public key listed in d.
+func (d *Document) ECDSAPubKey() (*ecdsa.PublicKey, error) {
+	for _, m := range d.VerificationMethod {
+		if m.PublicKeyJwk != nil {
+			return m.PublicKeyJwk.ECDSAPublicKey()
+		}
+	}
+
+	return nil, fmt.Errorf("DID document %s lists no ECDSA public key", d.ID)
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package did
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+func TestParse(t *testing.T) {
+	d, err := Parse("did:web:example.com:user#key-1")
+	if err != nil {
+		t.Fatalf("error when parsing DID: %v", err)
+	}
+	assert.Equal(t, "web", d.Method)
+	assert.Equal(t, "example.com:user", d.ID)
+	assert.Equal(t, "did:web:example.com:user", d.String())
+
+	for _, s := range []string{"", "did:web", "web:example.com", "did::x"} {
+		_, err := Parse(s)
+		assert.Error(t, err, "%q should not be a valid DID", s)
+	}
+}
+
+func TestWebDocumentURL(t *testing.T) {
+	u, _ := webDocumentURL("did:web:example.com")
+	assert.Equal(t, "https://example.com/.well-known/did.json", u)
+	u, _ = webDocumentURL("did:web:example.com%3A8443:user:alice")
+	assert.Equal(t, "https://example.com:8443/user/alice/did.json", u)
+}
+
+func TestKeyDID(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		id, err := NewKeyDID(&key.PublicKey)
+		if err != nil {
+			t.Fatalf("error when creating did:key: %v", err)
+		}
+		assert.True(t, strings.HasPrefix(id, "did:key:zDn"), "unexpected did:key prefix")
+
+		doc, err := NewResolver().Resolve(id)
+		if err != nil {
+			t.Fatalf("error when resolving did:key: %v", err)
+		}
+		pub, err := doc.ECDSAPubKey()
+		if err != nil {
+			t.Fatalf("error when obtaining key from DID document: %v", err)
+		}
+		assert.Equal(t, key.PublicKey.X, pub.X)
+		assert.Equal(t, key.PublicKey.Y, pub.Y)
+	}
+}
+
+func TestWebResolver(t *testing.T) {
+	pubKey := &cl.PubKey{N: big.NewInt(7), S: big.NewInt(11), Z: big.NewInt(13)}
+
+	var doc *Document
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/issuer/did.json" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(doc)
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	host := strings.Replace(u.Host, ":", "%3A", 1)
+	id := "did:web:" + host + ":issuer"
+	var err error
+	if doc, err = NewIssuerDocument(id, pubKey); err != nil {
+		t.Fatalf("error when creating DID document: %v", err)
+	}
+
+	r := MethodResolver{"web": &WebResolver{Client: srv.Client()}}
+	resolved, err := r.Resolve(id)
+	if err != nil {
+		t.Fatalf("error when resolving did:web: %v", err)
+	}
+	resolvedKey, err := resolved.CLPubKey()
+	if err != nil {
+		t.Fatalf("error when obtaining CL key from DID document: %v", err)
+	}
+	assert.Equal(t, pubKey.N, resolvedKey.N)
+
+	_, err = r.Resolve("did:web:" + host + ":other")
+	assert.Error(t, err, "missing DID document should not be resolved")
+	_, err = r.Resolve("did:key:zDn")
+	assert.Error(t, err, "unregistered method should not be resolved")
+}
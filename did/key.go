@@ -0,0 +1,167 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package did
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/jose"
+)
+
+// p256PubKeyCodec is the multicodec prefix (varint of 0x1200) of compressed P-256 public keys.
+var p256PubKeyCodec = []byte{0x80, 0x24}
+
+// NewKeyDID returns a did:key identifier of the P-256 public key pub. Holders can use
+// it to identify themselves (for example in presentations) without registering a DID.
+func NewKeyDID(pub *ecdsa.PublicKey) (string, error) {
+	if pub.Curve != elliptic.P256() {
+		return "", fmt.Errorf("only P-256 keys are supported")
+	}
+
+	key := append(append([]byte{}, p256PubKeyCodec...), compress(pub)...)
+	// 'z' is the multibase prefix of base58btc encoding
+	return "did:key:z" + base58Encode(key), nil
+}
+
+// KeyResolver resolves did:key identifiers of P-256 keys. As the key is encoded in
+// the identifier itself, no network access is needed.
+type KeyResolver struct{}
+
+// Resolve returns a DID document listing the key encoded in did.
+func (KeyResolver) Resolve(did string) (*Document, error) {
+	d, err := Parse(did)
+	if err != nil {
+		return nil, err
+	}
+	if d.Method != "key" || len(d.ID) < 2 || d.ID[0] != 'z' {
+		return nil, fmt.Errorf("not a base58 encoded did:key: %s", did)
+	}
+
+	key, err := base58Decode(d.ID[1:])
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(key, p256PubKeyCodec) {
+		return nil, fmt.Errorf("only P-256 keys are supported")
+	}
+	pub, err := decompress(key[len(p256PubKeyCodec):])
+	if err != nil {
+		return nil, err
+	}
+	jwk, err := jose.NewJWK(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	id := d.String() + "#" + d.ID
+	return &Document{
+		Context: []string{ContextV1},
+		ID:      d.String(),
+		VerificationMethod: []*VerificationMethod{{
+			ID:           id,
+			Type:         TypeJSONWebKey,
+			Controller:   d.String(),
+			PublicKeyJwk: jwk,
+		}},
+		AssertionMethod: []string{id},
+		Authentication:  []string{id},
+	}, nil
+}
+
+// compress returns the compressed form (SEC 1) of the P-256 point pub.
+func compress(pub *ecdsa.PublicKey) []byte {
+	b := make([]byte, 33)
+	b[0] = 2 + byte(pub.Y.Bit(0))
+	xBytes := pub.X.Bytes()
+	copy(b[33-len(xBytes):], xBytes)
+	return b
+}
+
+// decompress returns the P-256 point from its compressed form (SEC 1).
+func decompress(b []byte) (*ecdsa.PublicKey, error) {
+	if len(b) != 33 || (b[0] != 2 && b[0] != 3) {
+		return nil, fmt.Errorf("invalid compressed point")
+	}
+
+	params := elliptic.P256().Params()
+	x := new(big.Int).SetBytes(b[1:])
+	// y^2 = x^3 - 3x + b
+	y2 := new(big.Int).Exp(x, big.NewInt(3), params.P)
+	y2.Sub(y2, new(big.Int).Mul(x, big.NewInt(3)))
+	y2.Add(y2, params.B)
+	y2.Mod(y2, params.P)
+	y := new(big.Int).ModSqrt(y2, params.P)
+	if y == nil {
+		return nil, fmt.Errorf("invalid compressed point")
+	}
+	if y.Bit(0) != uint(b[0]&1) {
+		y.Sub(params.P, y)
+	}
+
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func base58Encode(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	// leading zero bytes are encoded as '1'
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, c := range []byte(s) {
+		i := bytes.IndexByte([]byte(base58Alphabet), c)
+		if i < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", c)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(i)))
+	}
+
+	var zeros int
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package did
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Resolver resolves a DID to its DID document.
+type Resolver interface {
+	Resolve(did string) (*Document, error)
+}
+
+// MethodResolver resolves DIDs with the resolver registered for their method.
+type MethodResolver map[string]Resolver
+
+// NewResolver returns a resolver supporting did:web and did:key methods.
+func NewResolver() MethodResolver {
+	return MethodResolver{
+		"web": &WebResolver{Client: http.DefaultClient},
+		"key": KeyResolver{},
+	}
+}
+
+// Resolve resolves did with the resolver registered for its method.
+func (r MethodResolver) Resolve(did string) (*Document, error) {
+	d, err := Parse(did)
+	if err != nil {
+		return nil, err
+	}
+	resolver, ok := r[d.Method]
+	if !ok {
+		return nil, fmt.Errorf("unsupported DID method: %s", d.Method)
+	}
+
+	return resolver.Resolve(did)
+}
+
+// WebResolver resolves did:web identifiers by fetching DID documents over HTTPS.
+type WebResolver struct {
+	Client *http.Client
+}
+
+// Resolve fetches the DID document of did from the web server it identifies.
+func (r *WebResolver) Resolve(did string) (*Document, error) {
+	u, err := webDocumentURL(did)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.Client.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch DID document of %s: %v", did, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot fetch DID document of %s: %s", did, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	doc := new(Document)
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("invalid DID document of %s: %v", did, err)
+	}
+	if doc.ID != did {
+		return nil, fmt.Errorf("DID document id %s does not match %s", doc.ID, did)
+	}
+
+	return doc, nil
+}
+
+// webDocumentURL returns the URL of the DID document of a did:web identifier.
+// For example, did:web:example.com resolves to https://example.com/.well-known/did.json
+// and did:web:example.com:user:alice to https://example.com/user/alice/did.json.
+func webDocumentURL(did string) (string, error) {
+	d, err := Parse(did)
+	if err != nil {
+		return "", err
+	}
+	if d.Method != "web" {
+		return "", fmt.Errorf("not a did:web identifier: %s", did)
+	}
+
+	parts := strings.Split(d.ID, ":")
+	for i, p := range parts {
+		if parts[i], err = url.PathUnescape(p); err != nil {
+			return "", fmt.Errorf("invalid did:web identifier %s: %v", did, err)
+		}
+	}
+
+	path := "/.well-known"
+	if len(parts) > 1 {
+		path = "/" + strings.Join(parts[1:], "/")
+	}
+
+	return "https://" + parts[0] + path + "/did.json", nil
+}
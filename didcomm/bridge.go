@@ -0,0 +1,250 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package didcomm
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/xlab-si/emmy/did"
+	pb "github.com/xlab-si/emmy/proto"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var streamDesc = &grpc.StreamDesc{
+	ServerStreams: true,
+	ClientStreams: true,
+}
+
+// sessionIdleTimeout is the time after which streams of idle threads are closed.
+const sessionIdleTimeout = 5 * time.Minute
+
+// maxEnvelopeSize limits the size of received envelopes.
+const maxEnvelopeSize = 4 << 20
+
+// Bridge is a DIDComm endpoint of an emmy server. It decrypts messages sent by
+// holders, forwards their payloads to the emmy server over conn and sends the
+// server's responses back to the holders, encrypted for the DIDs of the holders.
+type Bridge struct {
+	key      *ecdsa.PrivateKey
+	did      string
+	kid      string
+	conn     *grpc.ClientConn
+	resolver did.Resolver
+
+	sync.Mutex
+	sessions map[string]*session
+}
+
+// session is an open gRPC stream of a thread.
+type session struct {
+	from     string
+	stream   grpc.ClientStream
+	cancel   context.CancelFunc
+	lastUsed time.Time
+}
+
+// NewBridge returns a bridge with the key agreement key key, which forwards messages
+// to the emmy server over conn. DIDs of holders are resolved with r.
+func NewBridge(key *ecdsa.PrivateKey, conn *grpc.ClientConn, r did.Resolver) (*Bridge, error) {
+	id, kid, err := newKeyIdentity(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bridge{
+		key:      key,
+		did:      id,
+		kid:      kid,
+		conn:     conn,
+		resolver: r,
+		sessions: make(map[string]*session),
+	}, nil
+}
+
+// DID returns the did:key identifier of the bridge, to which holders send messages.
+func (b *Bridge) DID() string {
+	return b.did
+}
+
+// ServeHTTP receives an encrypted message in the body of a POST request and
+// writes the encrypted reply to the response.
+func (b *Bridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxEnvelopeSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reply, err := b.Handle(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", MediaTypeEncrypted)
+	w.Write(reply)
+}
+
+// WebSocketHandler returns a handler that receives encrypted messages over a
+// WebSocket connection and sends back encrypted replies over the same connection.
+func (b *Bridge) WebSocketHandler() http.Handler {
+	return websocket.Handler(func(ws *websocket.Conn) {
+		ws.MaxPayloadBytes = maxEnvelopeSize
+		defer ws.Close()
+		for {
+			var data []byte
+			if err := websocket.Message.Receive(ws, &data); err != nil {
+				return
+			}
+			reply, err := b.Handle(data)
+			if err != nil {
+				return
+			}
+			if err := websocket.Message.Send(ws, reply); err != nil {
+				return
+			}
+		}
+	})
+}
+
+// Handle processes an encrypted message and returns the encrypted reply to it.
+// An error is returned only if the message cannot be decrypted or the reply cannot
+// be encrypted; errors of emmy protocols are sent back as problem reports.
+func (b *Bridge) Handle(data []byte) ([]byte, error) {
+	m, err := Unpack(data, b.kid, b.key)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply *Message
+	switch m.Type {
+	case TypeStreamMessage:
+		var resp *pb.Message
+		if resp, err = b.forward(m); err == nil {
+			reply, err = newStreamMessage(b.did, m.From, m.Thid, "", resp)
+		}
+	case TypeClose:
+		b.closeSession(m.Thid, m.From)
+		reply, err = newMessage(TypeClose, b.did, m.From, m.Thid, struct{}{})
+	default:
+		err = status.Errorf(codes.InvalidArgument, "unsupported message type %s", m.Type)
+	}
+	if err != nil {
+		if reply, err = newProblemReport(b.did, m.From, m.Thid, err); err != nil {
+			return nil, err
+		}
+	}
+
+	return Pack(reply, b.resolver)
+}
+
+// forward sends the payload of m to the stream of its thread, opening the stream
+// if needed, and returns the response of the emmy server.
+func (b *Bridge) forward(m *Message) (*pb.Message, error) {
+	method, msg, err := m.payload()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s, err := b.session(m.Thid, m.From, method)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.stream.SendMsg(msg); err != nil {
+		b.closeSession(m.Thid, m.From)
+		return nil, err
+	}
+	resp := new(pb.Message)
+	if err := s.stream.RecvMsg(resp); err != nil {
+		b.closeSession(m.Thid, m.From)
+		if err == io.EOF {
+			return nil, status.Error(codes.FailedPrecondition, "stream has ended")
+		}
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// session returns the stream of thread thid, opening a new stream of the emmy
+// protocol method if the thread has none.
+func (b *Bridge) session(thid, from, method string) (*session, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	now := time.Now()
+	for id, s := range b.sessions {
+		if now.Sub(s.lastUsed) > sessionIdleTimeout {
+			s.cancel()
+			delete(b.sessions, id)
+		}
+	}
+
+	if s, ok := b.sessions[thid]; ok {
+		if s.from != from {
+			return nil, status.Error(codes.PermissionDenied, "thread belongs to another sender")
+		}
+		s.lastUsed = now
+		return s, nil
+	}
+
+	fullMethod, ok := pb.StreamMethods[method]
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "unknown method %s", method)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := grpc.NewClientStream(ctx, streamDesc, b.conn, fullMethod)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	s := &session{
+		from:     from,
+		stream:   stream,
+		cancel:   cancel,
+		lastUsed: now,
+	}
+	b.sessions[thid] = s
+
+	return s, nil
+}
+
+// closeSession closes the stream of thread thid.
+func (b *Bridge) closeSession(thid, from string) {
+	b.Lock()
+	defer b.Unlock()
+
+	if s, ok := b.sessions[thid]; ok && s.from == from {
+		s.stream.CloseSend()
+		s.cancel()
+		delete(b.sessions, thid)
+	}
+}
@@ -0,0 +1,272 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package didcomm
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/xlab-si/emmy/did"
+	pb "github.com/xlab-si/emmy/proto"
+	"golang.org/x/net/websocket"
+	"google.golang.org/grpc/metadata"
+)
+
+// Transport delivers encrypted messages to a Bridge (directly or through a mediator)
+// and returns the encrypted replies.
+type Transport interface {
+	RoundTrip(ctx context.Context, envelope []byte) ([]byte, error)
+}
+
+// HTTPTransport posts encrypted messages to a DIDComm HTTP endpoint.
+type HTTPTransport struct {
+	URL    string
+	Client *http.Client
+}
+
+// RoundTrip posts envelope and returns the body of the response.
+func (t *HTTPTransport) RoundTrip(ctx context.Context, envelope []byte) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodPost, t.URL, bytes.NewReader(envelope))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", MediaTypeEncrypted)
+
+	c := t.Client
+	if c == nil {
+		c = http.DefaultClient
+	}
+	resp, err := c.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxEnvelopeSize))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DIDComm endpoint responded with %s: %s", resp.Status,
+			bytes.TrimSpace(body))
+	}
+
+	return body, nil
+}
+
+// WebSocketTransport sends encrypted messages over a WebSocket connection.
+type WebSocketTransport struct {
+	sync.Mutex
+	conn *websocket.Conn
+}
+
+// DialWebSocket opens a WebSocket connection to the DIDComm endpoint url.
+func DialWebSocket(url, origin string) (*WebSocketTransport, error) {
+	conn, err := websocket.Dial(url, "", origin)
+	if err != nil {
+		return nil, err
+	}
+	conn.MaxPayloadBytes = maxEnvelopeSize
+
+	return &WebSocketTransport{
+		conn: conn,
+	}, nil
+}
+
+// RoundTrip sends envelope and waits for the next message on the connection.
+func (t *WebSocketTransport) RoundTrip(ctx context.Context, envelope []byte) ([]byte, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	if err := websocket.Message.Send(t.conn, envelope); err != nil {
+		return nil, err
+	}
+	var reply []byte
+	if err := websocket.Message.Receive(t.conn, &reply); err != nil {
+		return nil, err
+	}
+
+	return reply, nil
+}
+
+// Close closes the WebSocket connection.
+func (t *WebSocketTransport) Close() error {
+	return t.conn.Close()
+}
+
+// Client runs emmy protocols with a Bridge identified by a DID. Its streams can
+// be used by emmy clients in place of gRPC streams.
+type Client struct {
+	key       *ecdsa.PrivateKey
+	did       string
+	kid       string
+	bridge    string
+	resolver  did.Resolver
+	transport Transport
+}
+
+// NewClient returns a client with the key agreement key key, which sends messages
+// to the bridge identified by bridgeDID over transport. DIDs are resolved with r.
+func NewClient(key *ecdsa.PrivateKey, bridgeDID string, r did.Resolver,
+	transport Transport) (*Client, error) {
+	id, kid, err := newKeyIdentity(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		key:       key,
+		did:       id,
+		kid:       kid,
+		bridge:    bridgeDID,
+		resolver:  r,
+		transport: transport,
+	}, nil
+}
+
+// DID returns the did:key identifier of the client.
+func (c *Client) DID() string {
+	return c.did
+}
+
+// OpenStream opens a stream of the emmy protocol method (for example
+// IssueCredential) in a new DIDComm thread.
+func (c *Client) OpenStream(ctx context.Context, method string) (pb.ClientStream, error) {
+	if _, ok := pb.StreamMethods[method]; !ok {
+		return nil, fmt.Errorf("unknown method %s", method)
+	}
+
+	return &stream{
+		client: c,
+		ctx:    ctx,
+		method: method,
+	}, nil
+}
+
+// exchange sends m to the bridge and returns the bridge's reply.
+func (c *Client) exchange(ctx context.Context, m *Message) (*Message, error) {
+	envelope, err := Pack(m, c.resolver)
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.transport.RoundTrip(ctx, envelope)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := Unpack(data, c.kid, c.key)
+	if err != nil {
+		return nil, err
+	}
+	if reply.From != c.bridge || reply.Thid != m.Thid {
+		return nil, fmt.Errorf("unexpected reply from %s in thread %s", reply.From, reply.Thid)
+	}
+
+	return reply, nil
+}
+
+// stream implements pb.ClientStream with a DIDComm thread. As emmy protocols
+// alternate messages of the client and the server, Send waits for the response
+// of the server, which is then returned by Recv.
+type stream struct {
+	client *Client
+	ctx    context.Context
+	method string
+	thid   string
+	resp   *Message
+	err    error
+}
+
+func (s *stream) Send(msg *pb.Message) error {
+	m, err := newStreamMessage(s.client.did, s.client.bridge, s.thid, s.method, msg)
+	if err != nil {
+		return err
+	}
+	if s.thid == "" {
+		s.thid = m.Thid
+	}
+	s.resp, s.err = s.client.exchange(s.ctx, m)
+
+	return s.err
+}
+
+func (s *stream) Recv() (*pb.Message, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.resp == nil {
+		return nil, io.EOF
+	}
+	resp := s.resp
+	s.resp = nil
+	_, msg, err := resp.payload()
+
+	return msg, err
+}
+
+func (s *stream) CloseSend() error {
+	if s.thid == "" {
+		return nil
+	}
+	m, err := newMessage(TypeClose, s.client.did, s.client.bridge, s.thid, struct{}{})
+	if err != nil {
+		return err
+	}
+	_, err = s.client.exchange(s.ctx, m)
+
+	return err
+}
+
+func (s *stream) Header() (metadata.MD, error) {
+	return metadata.MD{}, nil
+}
+
+func (s *stream) Trailer() metadata.MD {
+	return metadata.MD{}
+}
+
+func (s *stream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *stream) SendMsg(m interface{}) error {
+	msg, ok := m.(*pb.Message)
+	if !ok {
+		return fmt.Errorf("unsupported message type %T", m)
+	}
+	return s.Send(msg)
+}
+
+func (s *stream) RecvMsg(m interface{}) error {
+	msg, ok := m.(*pb.Message)
+	if !ok {
+		return fmt.Errorf("unsupported message type %T", m)
+	}
+	resp, err := s.Recv()
+	if err != nil {
+		return err
+	}
+	*msg = *resp
+
+	return nil
+}
@@ -0,0 +1,307 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package didcomm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/xlab-si/emmy/jose"
+)
+
+// Algorithms of encrypted messages. Messages are anonymously encrypted (anoncrypt)
+// with ECDH-ES key agreement on P-256, wrapping a random content encryption key.
+const (
+	algECDHESA256KW = "ECDH-ES+A256KW"
+	encA256GCM      = "A256GCM"
+)
+
+// envelope is an encrypted message in JWE general JSON serialization.
+type envelope struct {
+	Protected  string      `json:"protected"`
+	Recipients []recipient `json:"recipients"`
+	IV         string      `json:"iv"`
+	Ciphertext string      `json:"ciphertext"`
+	Tag        string      `json:"tag"`
+}
+
+type recipient struct {
+	Header       recipientHeader `json:"header"`
+	EncryptedKey string          `json:"encrypted_key"`
+}
+
+type recipientHeader struct {
+	KID string `json:"kid"`
+}
+
+type protectedHeader struct {
+	Typ string    `json:"typ"`
+	Alg string    `json:"alg"`
+	Enc string    `json:"enc"`
+	EPK *jose.JWK `json:"epk"`
+	APV string    `json:"apv"`
+}
+
+// encrypt encrypts plaintext for the recipient with key ID kid and public key pub.
+func encrypt(plaintext []byte, kid string, pub *ecdsa.PublicKey) ([]byte, error) {
+	ephemeral, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	epk, err := jose.NewJWK(&ephemeral.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	apv := sha256.Sum256([]byte(kid))
+	header, err := json.Marshal(&protectedHeader{
+		Typ: MediaTypeEncrypted,
+		Alg: algECDHESA256KW,
+		Enc: encA256GCM,
+		EPK: epk,
+		APV: encode(apv[:]),
+	})
+	if err != nil {
+		return nil, err
+	}
+	protected := encode(header)
+
+	kek, err := deriveKEK(ephemeral, pub, apv[:])
+	if err != nil {
+		return nil, err
+	}
+	cek := make([]byte, 32)
+	if _, err := rand.Read(cek); err != nil {
+		return nil, err
+	}
+	encryptedKey, err := wrapKey(kek, cek)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, []byte(protected))
+	tagStart := len(sealed) - gcm.Overhead()
+
+	return json.Marshal(&envelope{
+		Protected: protected,
+		Recipients: []recipient{{
+			Header:       recipientHeader{KID: kid},
+			EncryptedKey: encode(encryptedKey),
+		}},
+		IV:         encode(iv),
+		Ciphertext: encode(sealed[:tagStart]),
+		Tag:        encode(sealed[tagStart:]),
+	})
+}
+
+// decrypt decrypts data for the recipient with key ID kid and private key key.
+func decrypt(data []byte, kid string, key *ecdsa.PrivateKey) ([]byte, error) {
+	env := new(envelope)
+	if err := json.Unmarshal(data, env); err != nil {
+		return nil, fmt.Errorf("malformed message: %v", err)
+	}
+
+	var encryptedKey []byte
+	var err error
+	for _, r := range env.Recipients {
+		if r.Header.KID == kid {
+			if encryptedKey, err = decode(r.EncryptedKey); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if encryptedKey == nil {
+		return nil, fmt.Errorf("message is not encrypted for %s", kid)
+	}
+
+	headerBytes, err := decode(env.Protected)
+	if err != nil {
+		return nil, err
+	}
+	header := new(protectedHeader)
+	if err := json.Unmarshal(headerBytes, header); err != nil {
+		return nil, fmt.Errorf("malformed protected header: %v", err)
+	}
+	if header.Alg != algECDHESA256KW || header.Enc != encA256GCM || header.EPK == nil {
+		return nil, fmt.Errorf("unsupported algorithms %s, %s", header.Alg, header.Enc)
+	}
+	epk, err := header.EPK.ECDSAPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	apv, err := decode(header.APV)
+	if err != nil {
+		return nil, err
+	}
+
+	kek, err := deriveKEK(key, epk, apv)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := unwrapKey(kek, encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	iv, err := decode(env.IV)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := decode(env.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := decode(env.Tag)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid iv length")
+	}
+
+	return gcm.Open(nil, iv, append(ciphertext, tag...), []byte(env.Protected))
+}
+
+// deriveKEK derives the key encryption key from the ECDH shared secret of priv and pub,
+// using Concat KDF (NIST SP 800-56A) as specified for ECDH-ES in RFC 7518.
+func deriveKEK(priv *ecdsa.PrivateKey, pub *ecdsa.PublicKey, apv []byte) ([]byte, error) {
+	if !priv.Curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, fmt.Errorf("public key is not on curve")
+	}
+	x, _ := priv.Curve.ScalarMult(pub.X, pub.Y, priv.D.Bytes())
+	z := make([]byte, 32)
+	xBytes := x.Bytes()
+	copy(z[32-len(xBytes):], xBytes)
+
+	h := sha256.New()
+	h.Write([]byte{0, 0, 0, 1}) // round counter, a single round yields 256 bits
+	h.Write(z)
+	writeLengthPrefixed(h, []byte(algECDHESA256KW))
+	writeLengthPrefixed(h, nil) // apu
+	writeLengthPrefixed(h, apv)
+	binary.Write(h, binary.BigEndian, uint32(256)) // key length in bits
+
+	return h.Sum(nil), nil
+}
+
+func writeLengthPrefixed(h interface {
+	Write([]byte) (int, error)
+}, b []byte) {
+	binary.Write(h, binary.BigEndian, uint32(len(b)))
+	h.Write(b)
+}
+
+// kwIV is the default initial value of AES key wrap (RFC 3394).
+var kwIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// wrapKey wraps key with kek using AES key wrap (RFC 3394).
+func wrapKey(kek, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(key) / 8
+	r := make([]byte, len(key))
+	copy(r, key)
+	a := append([]byte{}, kwIV...)
+	buf := make([]byte, 16)
+
+	for j := 0; j < 6; j++ {
+		for i := 0; i < n; i++ {
+			copy(buf, a)
+			copy(buf[8:], r[i*8:i*8+8])
+			block.Encrypt(buf, buf)
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(buf[:8])^t)
+			copy(r[i*8:], buf[8:])
+		}
+	}
+
+	return append(a, r...), nil
+}
+
+// unwrapKey unwraps a key wrapped by wrapKey.
+func unwrapKey(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, fmt.Errorf("invalid wrapped key length")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(wrapped)/8 - 1
+	a := append([]byte{}, wrapped[:8]...)
+	r := append([]byte{}, wrapped[8:]...)
+	buf := make([]byte, 16)
+
+	for j := 5; j >= 0; j-- {
+		for i := n - 1; i >= 0; i-- {
+			t := uint64(n*j + i + 1)
+			binary.BigEndian.PutUint64(buf, binary.BigEndian.Uint64(a)^t)
+			copy(buf[8:], r[i*8:i*8+8])
+			block.Decrypt(buf, buf)
+			copy(a, buf[:8])
+			copy(r[i*8:], buf[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(a, kwIV) != 1 {
+		return nil, fmt.Errorf("key unwrapping failed")
+	}
+
+	return r, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed message: %v", err)
+	}
+	return b, nil
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package didcomm
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/did"
+	pb "github.com/xlab-si/emmy/proto"
+)
+
+// TestWrapKey checks AES key wrap against the test vector of RFC 3394, section 4.6.
+func TestWrapKey(t *testing.T) {
+	kek, _ := hex.DecodeString("000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F")
+	key, _ := hex.DecodeString("00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F")
+	expected, _ := hex.DecodeString("28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326" +
+		"CBC7F0E71A99F43BFB988B9B7A02DD21")
+
+	wrapped, err := wrapKey(kek, key)
+	require.NoError(t, err)
+	assert.Equal(t, expected, wrapped)
+
+	unwrapped, err := unwrapKey(kek, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, key, unwrapped)
+
+	wrapped[0] ^= 1
+	_, err = unwrapKey(kek, wrapped)
+	assert.Error(t, err)
+}
+
+func TestPackUnpack(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	to, kid, err := newKeyIdentity(key)
+	require.NoError(t, err)
+
+	msg := &pb.Message{ClientId: 42}
+	m, err := newStreamMessage("did:example:holder", to, "", "IssueCredential", msg)
+	require.NoError(t, err)
+	envelope, err := Pack(m, did.KeyResolver{})
+	require.NoError(t, err)
+
+	unpacked, err := Unpack(envelope, kid, key)
+	require.NoError(t, err)
+	assert.Equal(t, m.ID, unpacked.ID)
+	assert.Equal(t, m.ID, unpacked.Thid)
+	method, payload, err := unpacked.payload()
+	require.NoError(t, err)
+	assert.Equal(t, "IssueCredential", method)
+	assert.Equal(t, int32(42), payload.ClientId)
+
+	// only the recipient can decrypt the message
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = Unpack(envelope, kid, other)
+	assert.Error(t, err)
+
+	// the message cannot be tampered with
+	envelope[len(envelope)-5] ^= 1
+	_, err = Unpack(envelope, kid, key)
+	assert.Error(t, err)
+}
@@ -0,0 +1,243 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package didcomm carries emmy protocol messages inside encrypted DIDComm v2 envelopes.
+// This lets holders interact with emmy issuers and verifiers through DIDComm agents
+// and mediators (over HTTP or WebSocket) instead of connecting to the gRPC server
+// directly.
+//
+// Each gRPC stream of an emmy protocol is mapped to a DIDComm thread. Every
+// pb.Message sent by the holder is wrapped in a DIDComm message of type
+// TypeStreamMessage, and the Bridge answers it with the next pb.Message of the stream
+// (or with a problem report) in the same thread.
+package didcomm
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/xlab-si/emmy/did"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Media types of DIDComm messages.
+const (
+	MediaTypePlain     = "application/didcomm-plain+json"
+	MediaTypeEncrypted = "application/didcomm-encrypted+json"
+)
+
+// Types of DIDComm messages exchanged by Client and Bridge.
+const (
+	// TypeStreamMessage carries a pb.Message of an emmy protocol.
+	TypeStreamMessage = "https://github.com/xlab-si/emmy/didcomm/1.0/stream-message"
+	// TypeClose ends the emmy protocol stream of a thread.
+	TypeClose = "https://github.com/xlab-si/emmy/didcomm/1.0/close"
+	// TypeProblemReport reports an error of the emmy protocol of a thread.
+	TypeProblemReport = "https://didcomm.org/report-problem/2.0/problem-report"
+)
+
+// Message is a plaintext DIDComm v2 message.
+type Message struct {
+	ID      string          `json:"id"`
+	Typ     string          `json:"typ"`
+	Type    string          `json:"type"`
+	From    string          `json:"from"`
+	To      []string        `json:"to"`
+	Thid    string          `json:"thid,omitempty"`
+	Created int64           `json:"created_time"`
+	Body    json.RawMessage `json:"body"`
+}
+
+// StreamBody is the body of TypeStreamMessage messages.
+type StreamBody struct {
+	// Method is the name of the emmy protocol, that is of its gRPC stream
+	// (for example IssueCredential).
+	Method string `json:"method"`
+	// Payload is the protobuf encoding of a pb.Message.
+	Payload []byte `json:"payload"`
+}
+
+// ProblemReportBody is the body of TypeProblemReport messages.
+type ProblemReportBody struct {
+	Code    string `json:"code"`
+	Comment string `json:"comment"`
+	// Status is the gRPC status code of the error.
+	Status codes.Code `json:"status"`
+	// Cause is the name of the pb.ErrorCode of the error, if the server gave one.
+	Cause string `json:"cause,omitempty"`
+}
+
+// problemCode is the DIDComm problem code of emmy protocol errors, which
+// abandon the thread.
+const problemCode = "e.p.emmy"
+
+// newMessage returns a message of type typ from sender to recipient in thread thid,
+// with body encoded as JSON.
+func newMessage(typ, from, to, thid string, body interface{}) (*Message, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	id := newID()
+	if thid == "" {
+		thid = id
+	}
+
+	return &Message{
+		ID:      id,
+		Typ:     MediaTypePlain,
+		Type:    typ,
+		From:    from,
+		To:      []string{to},
+		Thid:    thid,
+		Created: time.Now().Unix(),
+		Body:    b,
+	}, nil
+}
+
+func newStreamMessage(from, to, thid, method string, msg *pb.Message) (*Message, error) {
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return newMessage(TypeStreamMessage, from, to, thid, &StreamBody{
+		Method:  method,
+		Payload: payload,
+	})
+}
+
+func newProblemReport(from, to, thid string, err error) (*Message, error) {
+	s, _ := status.FromError(err)
+	body := &ProblemReportBody{
+		Code:    problemCode,
+		Comment: s.Message(),
+		Status:  s.Code(),
+	}
+	if e := pb.ToProtocolError(err); e != nil {
+		body.Cause = e.Code.String()
+	}
+	return newMessage(TypeProblemReport, from, to, thid, body)
+}
+
+// payload returns the pb.Message carried by m along with the name of its emmy protocol,
+// or the error reported by m.
+func (m *Message) payload() (string, *pb.Message, error) {
+	switch m.Type {
+	case TypeStreamMessage:
+		body := new(StreamBody)
+		if err := json.Unmarshal(m.Body, body); err != nil {
+			return "", nil, fmt.Errorf("malformed message body: %v", err)
+		}
+		msg := new(pb.Message)
+		if err := proto.Unmarshal(body.Payload, msg); err != nil {
+			return "", nil, fmt.Errorf("malformed payload: %v", err)
+		}
+		return body.Method, msg, nil
+	case TypeProblemReport:
+		body := new(ProblemReportBody)
+		if err := json.Unmarshal(m.Body, body); err != nil {
+			return "", nil, fmt.Errorf("malformed message body: %v", err)
+		}
+		if cause, ok := pb.ErrorCode_value[body.Cause]; ok {
+			return "", nil, pb.NewStatusError(body.Status, pb.ErrorCode(cause), body.Comment)
+		}
+		return "", nil, status.Error(body.Status, body.Comment)
+	default:
+		return "", nil, fmt.Errorf("unexpected message type %s", m.Type)
+	}
+}
+
+// Pack encrypts m for the key agreement key of its (first) recipient, which is
+// obtained by resolving the recipient's DID with r.
+func Pack(m *Message, r did.Resolver) ([]byte, error) {
+	if len(m.To) == 0 {
+		return nil, fmt.Errorf("message has no recipient")
+	}
+	kid, pub, err := resolveKey(m.To[0], r)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return encrypt(plaintext, kid, pub)
+}
+
+// Unpack decrypts an encrypted message with key, the private key of the verification
+// method kid.
+func Unpack(data []byte, kid string, key *ecdsa.PrivateKey) (*Message, error) {
+	plaintext, err := decrypt(data, kid, key)
+	if err != nil {
+		return nil, err
+	}
+	m := new(Message)
+	if err := json.Unmarshal(plaintext, m); err != nil {
+		return nil, fmt.Errorf("malformed message: %v", err)
+	}
+	if m.From == "" {
+		return nil, fmt.Errorf("message does not identify its sender")
+	}
+
+	return m, nil
+}
+
+// resolveKey returns the first ECDSA verification method of the DID document of id.
+func resolveKey(id string, r did.Resolver) (string, *ecdsa.PublicKey, error) {
+	doc, err := r.Resolve(id)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, m := range doc.VerificationMethod {
+		if m.PublicKeyJwk != nil {
+			pub, err := m.PublicKeyJwk.ECDSAPublicKey()
+			return m.ID, pub, err
+		}
+	}
+
+	return "", nil, fmt.Errorf("DID document %s lists no ECDSA public key", id)
+}
+
+// newKeyIdentity returns the did:key identifier of key along with the ID of its
+// verification method.
+func newKeyIdentity(key *ecdsa.PrivateKey) (string, string, error) {
+	id, err := did.NewKeyDID(&key.PublicKey)
+	if err != nil {
+		return "", "", err
+	}
+	doc, err := did.KeyResolver{}.Resolve(id)
+	if err != nil {
+		return "", "", err
+	}
+
+	return id, doc.VerificationMethod[0].ID, nil
+}
+
+// newID returns a random message identifier.
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -39,7 +39,9 @@ func main() {
 	app.Version = version
 	app.Usage = `A CLI app for running emmy server, emmy clients 
 		and examples of proofs offered by the emmy library`
-	app.Commands = []cli.Command{emmy.ServerCmd, emmy.ClientCmd}
+	app.Commands = []cli.Command{emmy.ServerCmd, emmy.ClientCmd, emmy.KeygenCmd, emmy.SetupCmd,
+		emmy.SdkCmd, emmy.BenchCmd, emmy.VectorsCmd, emmy.ReplayCmd, emmy.VerifyCmd,
+		emmy.ParamsCmd, emmy.ExamplesCmd}
 
 	app.Run(os.Args)
 }
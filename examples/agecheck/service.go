@@ -0,0 +1,85 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package agecheck implements the age-verification web service of the example
+// applications (see package examples). It serves its content only to users with
+// session keys of scope examples.ScopeAdult, obtained by proving at the issuer
+// portal that they are over 18 - the service learns nothing else about them.
+package agecheck
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/xlab-si/emmy/examples"
+)
+
+// Content is the response of the service to adults.
+type Content struct {
+	Content string `json:"content"`
+}
+
+// Service is the age-verification web service.
+type Service struct {
+	validator examples.SessionValidator
+	content   string
+}
+
+// NewService returns a service serving content to users whose session keys are
+// valid according to validator.
+func NewService(validator examples.SessionValidator, content string) *Service {
+	return &Service{
+		validator: validator,
+		content:   content,
+	}
+}
+
+// ServeHTTP serves the content of s at / to requests with a session key of
+// scope examples.ScopeAdult as a bearer token.
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		examples.WriteError(w, http.StatusMethodNotAllowed,
+			fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	sessionKey := examples.Bearer(r)
+	if sessionKey == "" {
+		examples.WriteError(w, http.StatusUnauthorized, fmt.Errorf("session key required"))
+		return
+	}
+	status, err := s.validator.ValidateSession(sessionKey)
+	if err != nil {
+		examples.WriteError(w, http.StatusBadGateway, err)
+		return
+	}
+	if !status.Valid {
+		examples.WriteError(w, http.StatusUnauthorized, fmt.Errorf("%s", status.Reason))
+		return
+	}
+	if status.Scope != examples.ScopeAdult {
+		examples.WriteError(w, http.StatusForbidden,
+			fmt.Errorf("session key of scope %s required", examples.ScopeAdult))
+		return
+	}
+
+	examples.WriteJSON(w, http.StatusOK, &Content{s.content})
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package examples_test
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/df"
+)
+
+// Example_blindIssuance shows issuance of a credential with a committed attribute,
+// whose value the issuer never learns. A registry that knows the year of birth of
+// the holder commits to it and certifies the commitment, which is all the issuer
+// checks before issuing the credential. The holder later shows the credential to a
+// verifier, proving only that it was born before 2000.
+func Example_blindIssuance() {
+	params := cl.GetDefaultParamSizes()
+	org, err := cl.NewOrg(params, cl.NewAttrCount(1, 1, 0))
+	if err != nil {
+		panic(err)
+	}
+	pub := org.Keys.Pub
+
+	// the registry commits to the year of birth and hands the opening to the holder
+	registry := df.NewCommitter(pub.N1, pub.G, pub.H, pub.N1, params.SecParam)
+	certified, err := registry.GetCommitMsg(big.NewInt(1985))
+	if err != nil {
+		panic(err)
+	}
+	_, r := registry.GetDecommitMsg()
+
+	// the holder builds a credential request for the certified commitment
+	rc := cl.NewRawCred(cl.NewAttrCount(1, 1, 0))
+	_ = rc.AddStrAttr("Name", "Alice", true)
+	_ = rc.AddInt64Attr("BirthYear", 1985, false)
+	cm, err := cl.NewCredManager(params, pub, pub.GenerateUserMasterSecret(), rc)
+	if err != nil {
+		panic(err)
+	}
+	if _, err := cm.CommitAttr("BirthYear", r); err != nil {
+		panic(err)
+	}
+	credReq, err := cm.GetCredRequest(org.GetCredIssueNonce())
+	if err != nil {
+		panic(err)
+	}
+
+	// the issuer verifies that the holder can open the commitment, which needs to be
+	// the certified one
+	fmt.Println("certified commitment:", credReq.CommitmentsOfAttrs[0].Cmp(certified) == 0)
+	res, err := org.IssueCred(credReq)
+	if err != nil {
+		panic(err)
+	}
+	ok, err := cm.Verify(res.Cred, res.AProof)
+	fmt.Println("credential valid:", ok && err == nil)
+
+	// the holder reveals only the commitment, and proves the committed year is in range
+	verifier, err := cl.NewOrgFromParams(params, &cl.KeyPair{Pub: pub})
+	if err != nil {
+		panic(err)
+	}
+	nonce := verifier.GetProveCredNonce()
+	randCred, proof, err := cm.BuildProof(res.Cred, []int{}, []int{0}, nonce)
+	if err != nil {
+		panic(err)
+	}
+	known, committed := cm.FilterAttributes([]int{}, []int{0})
+	ok, err = verifier.ProveCred(randCred.A, proof, []int{}, []int{0}, known, committed)
+	fmt.Println("credential proved:", ok && err == nil)
+	rangeProof, err := cm.BuildAttrRangeProof(0, big.NewInt(1900), big.NewInt(1999), nonce)
+	if err != nil {
+		panic(err)
+	}
+	ok, err = verifier.VerifyAttrRangeProofs([]*cl.AttrRangeProof{rangeProof}, []int{0},
+		committed, nonce)
+	fmt.Println("born before 2000:", ok && err == nil)
+
+	// Output:
+	// certified commitment: true
+	// credential valid: true
+	// credential proved: true
+	// born before 2000: true
+}
@@ -0,0 +1,323 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package examples holds small applications built on the public APIs of emmy,
+// meant as references for integrating anonymous credentials and as smoke tests
+// of the whole stack:
+//
+//   - issuer: a portal of an organization that enrolls users, issues CL credentials
+//     to them, verifies proofs of credentials in exchange for session keys and
+//     revokes credentials,
+//   - agecheck: a web service that serves content only to adults, without learning
+//     anything else about them,
+//   - forum: a forum whose members log in and post anonymously.
+//
+// This package holds what the applications share: the structure of credentials,
+// messages exchanged over HTTP, a client of the portal and Wallet, which holds the
+// credential of a user. The services can be run with the emmy examples command.
+//
+// Credentials are revoked by epochs: each credential holds the epoch of the issuer
+// at the time of (re)issuance, which needs to be revealed in proofs. Revoking a
+// credential starts a new epoch, invalidating all sessions and credentials of the
+// previous one. Holders of credentials that were not revoked obtain updated
+// credentials with Wallet.Refresh, which is refused to holders of revoked ones.
+package examples
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/qr"
+)
+
+// Names of attributes of credentials issued by the portal. All of them are known
+// to the issuer.
+const (
+	AttrName      = "Name"
+	AttrAgeOver18 = "AgeOver18"
+	AttrEpoch     = "Epoch"
+)
+
+// Scopes of sessions, which determine the attributes that need to be revealed to
+// obtain a session key.
+const (
+	// ScopeMember requires only a valid credential, revealing no attributes
+	// besides the epoch.
+	ScopeMember = "member"
+	// ScopeAdult additionally requires revealing that the holder is over 18.
+	ScopeAdult = "age_over_18"
+)
+
+// AttrCount returns the number of attributes of credentials issued by the portal.
+func AttrCount() *cl.AttrCount {
+	return cl.NewAttrCount(3, 0, 0)
+}
+
+// NewRawCred returns a raw credential with the given attribute values.
+func NewRawCred(attrs *Attributes) (*cl.RawCred, error) {
+	rc := cl.NewRawCred(AttrCount())
+	if err := rc.AddStrAttr(AttrName, attrs.Name, true); err != nil {
+		return nil, err
+	}
+	if err := rc.AddStrAttr(AttrAgeOver18, fmt.Sprint(attrs.AgeOver18), true); err != nil {
+		return nil, err
+	}
+	if err := rc.AddInt64Attr(AttrEpoch, attrs.Epoch, true); err != nil {
+		return nil, err
+	}
+
+	return rc, nil
+}
+
+// Scope returns the names of attributes that need to be revealed in proofs
+// for sessions of scope.
+func Scope(scope string) ([]string, error) {
+	switch scope {
+	case ScopeMember:
+		return []string{AttrEpoch}, nil
+	case ScopeAdult:
+		return []string{AttrAgeOver18, AttrEpoch}, nil
+	default:
+		return nil, fmt.Errorf("unknown scope %s", scope)
+	}
+}
+
+// Messages exchanged with the portal over HTTP, in JSON.
+type (
+	// Attributes are values of attributes of a credential.
+	Attributes struct {
+		Name      string `json:"name"`
+		AgeOver18 bool   `json:"age_over_18"`
+		Epoch     int64  `json:"epoch"`
+	}
+
+	Enrollment struct {
+		Name      string `json:"name"`
+		AgeOver18 bool   `json:"age_over_18"`
+	}
+
+	RegistrationKey struct {
+		Key string `json:"registration_key"`
+	}
+
+	IssuanceOffer struct {
+		Nonce      *big.Int    `json:"nonce"`
+		Attributes *Attributes `json:"attributes"`
+	}
+
+	IssuanceRequest struct {
+		Key     string          `json:"registration_key"`
+		Request *cl.CredRequest `json:"request"`
+	}
+
+	RefreshRequest struct {
+		Nym   *big.Int `json:"nym"`
+		Nonce *big.Int `json:"nonce"` // nonce of the original credential request
+	}
+
+	IssuedCred struct {
+		Cred       *cl.Cred                `json:"cred"`
+		AProof     *qr.RepresentationProof `json:"a_proof"`
+		Attributes *Attributes             `json:"attributes"`
+	}
+
+	Nonce struct {
+		Nonce *big.Int `json:"nonce"`
+	}
+
+	// Presentation proves the possession of a credential to obtain a session
+	// of Scope.
+	Presentation struct {
+		Scope                     string             `json:"scope"`
+		Nonce                     *big.Int           `json:"nonce"`
+		RevealedKnownAttrsIndices []int              `json:"revealed_known_attrs_indices"`
+		RevealedKnownAttrs        []*big.Int         `json:"revealed_known_attrs"`
+		Proof                     *cl.AggregateProof `json:"proof"`
+	}
+
+	SessionKey struct {
+		SessionKey string `json:"session_key"`
+	}
+
+	// SessionStatus is the result of validation of a session key. ID identifies
+	// the session, but not its holder.
+	SessionStatus struct {
+		Valid     bool   `json:"valid"`
+		ID        string `json:"id,omitempty"`
+		Scope     string `json:"scope,omitempty"`
+		ExpiresAt int64  `json:"expires_at,omitempty"`
+		Reason    string `json:"reason,omitempty"`
+	}
+
+	Epoch struct {
+		Epoch int64 `json:"epoch"`
+	}
+
+	Error struct {
+		Error string `json:"error"`
+	}
+)
+
+// SessionValidator validates session keys issued by the portal. Services
+// relying on the portal use it to authorize requests.
+type SessionValidator interface {
+	ValidateSession(sessionKey string) (*SessionStatus, error)
+}
+
+// Portal is a client of the issuer portal at URL. AdminToken is only needed for
+// enrolling users and revoking their credentials.
+type Portal struct {
+	URL        string
+	AdminToken string
+	Client     *http.Client
+}
+
+// NewPortal returns a client of the portal at url.
+func NewPortal(url string) *Portal {
+	return &Portal{
+		URL: strings.TrimSuffix(url, "/"),
+		Client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// ValidateSession validates sessionKey at the portal.
+func (p *Portal) ValidateSession(sessionKey string) (*SessionStatus, error) {
+	status := new(SessionStatus)
+	if err := p.Post("/sessions/validate", &SessionKey{sessionKey}, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Enroll enrolls a user with the given attributes, returning the registration key
+// the user obtains a credential with.
+func (p *Portal) Enroll(e *Enrollment) (string, error) {
+	key := new(RegistrationKey)
+	if err := p.Post("/admin/enroll", e, key); err != nil {
+		return "", err
+	}
+	return key.Key, nil
+}
+
+// Revoke revokes the credential of the user with the given name, returning the new
+// epoch.
+func (p *Portal) Revoke(name string) (int64, error) {
+	epoch := new(Epoch)
+	if err := p.Post("/admin/revoke", &Enrollment{Name: name}, epoch); err != nil {
+		return 0, err
+	}
+	return epoch.Epoch, nil
+}
+
+// PubKey returns the public key and parameters of the issuer.
+func (p *Portal) PubKey() (*cl.PubKey, *cl.Params, error) {
+	r, err := p.Client.Get(p.URL + "/pubkey")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("%s", r.Status)
+	}
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	pubKey, err := cl.ParsePubKeyPEM(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params := new(cl.Params)
+	if err := p.Get("/params", params); err != nil {
+		return nil, nil, err
+	}
+	return pubKey, params, nil
+}
+
+// Get calls the endpoint at path of the portal and decodes its response into resp.
+func (p *Portal) Get(path string, resp interface{}) error {
+	r, err := p.Client.Get(p.URL + path)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(r, resp)
+}
+
+// Post calls the endpoint at path of the portal with v and decodes its response
+// into resp.
+func (p *Portal) Post(path string, v, resp interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.URL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.AdminToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.AdminToken)
+	}
+	r, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(r, resp)
+}
+
+func decodeResponse(r *http.Response, resp interface{}) error {
+	defer r.Body.Close()
+	if r.StatusCode != http.StatusOK {
+		e := new(Error)
+		if err := json.NewDecoder(r.Body).Decode(e); err != nil || e.Error == "" {
+			return fmt.Errorf("%s", r.Status)
+		}
+		return fmt.Errorf("%s", e.Error)
+	}
+	return json.NewDecoder(r.Body).Decode(resp)
+}
+
+// Bearer returns the bearer token of r, or an empty string when there is none.
+func Bearer(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, "Bearer ") {
+		return ""
+	}
+	return strings.TrimPrefix(h, "Bearer ")
+}
+
+// WriteJSON writes v as the JSON response to w with status code.
+func WriteJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// WriteError writes err as the JSON response to w with status code.
+func WriteError(w http.ResponseWriter, code int, err error) {
+	WriteJSON(w, code, &Error{err.Error()})
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package examples_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/examples"
+	"github.com/xlab-si/emmy/examples/agecheck"
+	"github.com/xlab-si/emmy/examples/forum"
+	"github.com/xlab-si/emmy/examples/issuer"
+)
+
+const testAdminToken = "testAdminToken"
+
+// get calls url with sessionKey as a bearer token, returning the status code.
+func get(t *testing.T, url, sessionKey string) int {
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Authorization", "Bearer "+sessionKey)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// post posts text to the forum at url with sessionKey, returning the status code.
+func post(t *testing.T, url, sessionKey, text string) int {
+	body, _ := json.Marshal(&forum.Post{Text: text})
+	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+sessionKey)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestExamples(t *testing.T) {
+	org, err := cl.NewOrg(cl.GetDefaultParamSizes(), examples.AttrCount())
+	require.NoError(t, err)
+	p, err := issuer.NewPortal(org, cl.NewMockRecordManager(), testAdminToken, time.Minute)
+	require.NoError(t, err)
+
+	portalSrv := httptest.NewServer(p)
+	defer portalSrv.Close()
+	portal := examples.NewPortal(portalSrv.URL)
+	ageSrv := httptest.NewServer(agecheck.NewService(portal, "adults only"))
+	defer ageSrv.Close()
+	f := forum.NewForum(portal)
+	forumSrv := httptest.NewServer(f)
+	defer forumSrv.Close()
+
+	// enrollment is reserved to admins
+	_, err = portal.Enroll(&examples.Enrollment{Name: "Alice", AgeOver18: true})
+	assert.Error(t, err)
+	admin := examples.NewPortal(portalSrv.URL)
+	admin.AdminToken = testAdminToken
+	aliceKey, err := admin.Enroll(&examples.Enrollment{Name: "Alice", AgeOver18: true})
+	require.NoError(t, err)
+	bobKey, err := admin.Enroll(&examples.Enrollment{Name: "Bob", AgeOver18: false})
+	require.NoError(t, err)
+
+	alice, err := examples.NewWallet(portal)
+	require.NoError(t, err)
+	require.NoError(t, alice.Obtain(aliceKey))
+	assert.Equal(t, &examples.Attributes{Name: "Alice", AgeOver18: true, Epoch: 1},
+		alice.Attributes())
+	bob, err := examples.NewWallet(portal)
+	require.NoError(t, err)
+	require.NoError(t, bob.Obtain(bobKey))
+	// registration keys can only be used once
+	assert.Error(t, bob.Obtain(bobKey))
+
+	// age verification
+	aliceAdult, err := alice.Login(examples.ScopeAdult)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, get(t, ageSrv.URL, aliceAdult))
+	_, err = bob.Login(examples.ScopeAdult)
+	assert.Error(t, err, "minors should not obtain sessions of adults")
+	bobMember, err := bob.Login(examples.ScopeMember)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, get(t, ageSrv.URL, bobMember))
+	assert.Equal(t, http.StatusUnauthorized, get(t, ageSrv.URL, "invalid"))
+
+	// anonymous forum
+	aliceMember, err := alice.Login(examples.ScopeMember)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, post(t, forumSrv.URL+"/posts", aliceMember, "hello"))
+	assert.Equal(t, http.StatusOK, post(t, forumSrv.URL+"/posts", bobMember, "hi"))
+	assert.Equal(t, http.StatusUnauthorized, post(t, forumSrv.URL+"/posts", "", "anyone?"))
+	posts := f.Posts()
+	require.Len(t, posts, 2)
+	assert.NotEqual(t, posts[0].Author, posts[1].Author)
+	assert.NotContains(t, posts[0].Author, "Alice")
+
+	// revocation invalidates sessions of the epoch, and only holders of credentials
+	// that were not revoked can refresh them
+	epoch, err := admin.Revoke("Bob")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), epoch)
+	assert.Equal(t, http.StatusUnauthorized, post(t, forumSrv.URL+"/posts", bobMember, "hi"))
+	assert.Equal(t, http.StatusUnauthorized, get(t, ageSrv.URL, aliceAdult))
+	_, err = bob.Login(examples.ScopeMember)
+	assert.Error(t, err, "credentials of previous epochs should not be accepted")
+	assert.Error(t, bob.Refresh(), "revoked credential should not be refreshed")
+
+	require.NoError(t, alice.Refresh())
+	assert.Equal(t, int64(2), alice.Attributes().Epoch)
+	aliceAdult, err = alice.Login(examples.ScopeAdult)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, get(t, ageSrv.URL, aliceAdult))
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package forum implements the anonymous forum of the example applications (see
+// package examples). Anyone can read posts, while posting requires a session key
+// obtained at the issuer portal by proving the possession of a valid credential.
+// Posts are signed with a pseudonym derived from the session, so posts of a session
+// can be told apart from those of other sessions, but not linked to their author.
+package forum
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/xlab-si/emmy/examples"
+)
+
+// Post is a post in the forum.
+type Post struct {
+	Author string    `json:"author"`
+	Text   string    `json:"text"`
+	Time   time.Time `json:"time"`
+}
+
+// Forum is the anonymous forum.
+type Forum struct {
+	validator examples.SessionValidator
+
+	mu    sync.Mutex
+	posts []*Post
+}
+
+// NewForum returns a forum admitting users whose session keys are valid according
+// to validator.
+func NewForum(validator examples.SessionValidator) *Forum {
+	return &Forum{
+		validator: validator,
+	}
+}
+
+// Posts returns posts in the forum, oldest first.
+func (f *Forum) Posts() []*Post {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*Post{}, f.posts...)
+}
+
+// ServeHTTP serves posts at /posts. Posting requires a valid session key as
+// a bearer token.
+func (f *Forum) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/posts" {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		examples.WriteJSON(w, http.StatusOK, f.Posts())
+	case http.MethodPost:
+		f.post(w, r)
+	default:
+		examples.WriteError(w, http.StatusMethodNotAllowed,
+			fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+func (f *Forum) post(w http.ResponseWriter, r *http.Request) {
+	sessionKey := examples.Bearer(r)
+	if sessionKey == "" {
+		examples.WriteError(w, http.StatusUnauthorized, fmt.Errorf("session key required"))
+		return
+	}
+	status, err := f.validator.ValidateSession(sessionKey)
+	if err != nil {
+		examples.WriteError(w, http.StatusBadGateway, err)
+		return
+	}
+	if !status.Valid {
+		examples.WriteError(w, http.StatusUnauthorized, fmt.Errorf("%s", status.Reason))
+		return
+	}
+
+	p := new(Post)
+	if err := json.NewDecoder(r.Body).Decode(p); err != nil || p.Text == "" {
+		examples.WriteError(w, http.StatusBadRequest, fmt.Errorf("text of the post is required"))
+		return
+	}
+	p.Author = pseudonym(status.ID)
+	p.Time = time.Now().UTC()
+
+	f.mu.Lock()
+	f.posts = append(f.posts, p)
+	f.mu.Unlock()
+
+	examples.WriteJSON(w, http.StatusOK, p)
+}
+
+// pseudonym returns the pseudonym of the author of posts in the session with
+// the given identifier.
+func pseudonym(sessionID string) string {
+	h := sha256.Sum256([]byte(sessionID))
+	return "anon-" + hex.EncodeToString(h[:4])
+}
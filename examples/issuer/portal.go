@@ -0,0 +1,558 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package issuer implements the issuer portal of the example applications (see
+// package examples). The portal enrolls users, issues them CL credentials,
+// exchanges proofs of credentials for session keys, which relying parties validate
+// at the portal, and revokes credentials.
+package issuer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/examples"
+	"github.com/xlab-si/emmy/jose"
+)
+
+// nonceTTL is the time a client has to use a nonce obtained from the portal.
+const nonceTTL = time.Minute
+
+// Portal is the issuer portal, serving its API over HTTP.
+type Portal struct {
+	org        *cl.Org
+	records    cl.ReceiverRecordManager
+	adminToken string
+	sessionTTL time.Duration
+	signer     *jose.Signer
+	mux        *http.ServeMux
+
+	mu       sync.Mutex
+	epoch    int64
+	enrolled map[string]*examples.Enrollment // by registration key
+	offers   map[string]*cl.Org              // pending issuance, by registration key
+	holders  map[string]*holder              // by nym
+	names    map[string]string               // nyms of holders, by name
+	nonces   map[string]time.Time            // unused nonces for proofs, with expiration
+}
+
+// holder is a user that was issued a credential.
+type holder struct {
+	*examples.Enrollment
+	credReqNonce *big.Int
+}
+
+// sessionClaims are claims of session keys issued by the portal.
+type sessionClaims struct {
+	ID        string `json:"jti"`
+	Scope     string `json:"scope"`
+	Epoch     int64  `json:"epoch"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// NewPortal returns a portal issuing credentials with the keys of org, storing
+// receiver records of credentials in records. Admin endpoints (enrollment and
+// revocation) require adminToken as a bearer token. Session keys are valid for
+// sessionTTL, unless the epoch they were issued in ends before.
+func NewPortal(org *cl.Org, records cl.ReceiverRecordManager, adminToken string,
+	sessionTTL time.Duration) (*Portal, error) {
+	if org.Keys == nil || org.Keys.Sec == nil {
+		return nil, fmt.Errorf("secret key of the organization is needed to issue credentials")
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := jose.NewSigner(key)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Portal{
+		org:        org,
+		records:    records,
+		adminToken: adminToken,
+		sessionTTL: sessionTTL,
+		signer:     signer,
+		mux:        http.NewServeMux(),
+		epoch:      1,
+		enrolled:   make(map[string]*examples.Enrollment),
+		offers:     make(map[string]*cl.Org),
+		holders:    make(map[string]*holder),
+		names:      make(map[string]string),
+		nonces:     make(map[string]time.Time),
+	}
+
+	p.mux.HandleFunc("/pubkey", p.pubKey)
+	p.handle("/params", http.MethodGet, false, p.params)
+	p.handle("/epoch", http.MethodGet, false, p.currentEpoch)
+	p.handle("/admin/enroll", http.MethodPost, true, p.enroll)
+	p.handle("/admin/revoke", http.MethodPost, true, p.revoke)
+	p.handle("/credentials/offer", http.MethodPost, false, p.offer)
+	p.handle("/credentials", http.MethodPost, false, p.issue)
+	p.handle("/credentials/refresh", http.MethodPost, false, p.refresh)
+	p.handle("/sessions/nonce", http.MethodGet, false, p.nonce)
+	p.handle("/sessions", http.MethodPost, false, p.login)
+	p.handle("/sessions/validate", http.MethodPost, false, p.validate)
+
+	return p, nil
+}
+
+// ServeHTTP serves the API of the portal.
+func (p *Portal) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mux.ServeHTTP(w, r)
+}
+
+// Epoch returns the current epoch of the portal.
+func (p *Portal) Epoch() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.epoch
+}
+
+// httpError is an error with the HTTP status code of the response.
+type httpError struct {
+	code int
+	msg  string
+}
+
+func (e *httpError) Error() string {
+	return e.msg
+}
+
+// handle registers a JSON endpoint at path, accepting requests with method.
+func (p *Portal) handle(path, method string, admin bool,
+	h func(r *http.Request) (interface{}, error)) {
+	p.mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			examples.WriteError(w, http.StatusMethodNotAllowed,
+				fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		if admin && !p.authorizeAdmin(r) {
+			examples.WriteError(w, http.StatusUnauthorized, fmt.Errorf("admin token required"))
+			return
+		}
+
+		resp, err := h(r)
+		if err != nil {
+			code := http.StatusInternalServerError
+			if e, ok := err.(*httpError); ok {
+				code = e.code
+			}
+			examples.WriteError(w, code, err)
+			return
+		}
+		examples.WriteJSON(w, http.StatusOK, resp)
+	})
+}
+
+func (p *Portal) authorizeAdmin(r *http.Request) bool {
+	token := examples.Bearer(r)
+	return p.adminToken != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(p.adminToken)) == 1
+}
+
+func (p *Portal) pubKey(w http.ResponseWriter, r *http.Request) {
+	data, err := p.org.Keys.Pub.MarshalPEM()
+	if err != nil {
+		examples.WriteError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	w.Write(data)
+}
+
+func (p *Portal) params(r *http.Request) (interface{}, error) {
+	return p.org.Params, nil
+}
+
+func (p *Portal) currentEpoch(r *http.Request) (interface{}, error) {
+	return &examples.Epoch{Epoch: p.Epoch()}, nil
+}
+
+func (p *Portal) enroll(r *http.Request) (interface{}, error) {
+	e := new(examples.Enrollment)
+	if err := decodeJSON(r, e); err != nil {
+		return nil, err
+	}
+	if e.Name == "" {
+		return nil, &httpError{http.StatusBadRequest, "name is required"}
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	key := hex.EncodeToString(b)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.names[e.Name]; ok {
+		return nil, &httpError{http.StatusConflict,
+			fmt.Sprintf("%s already holds a credential", e.Name)}
+	}
+	p.enrolled[key] = e
+
+	return &examples.RegistrationKey{Key: key}, nil
+}
+
+func (p *Portal) revoke(r *http.Request) (interface{}, error) {
+	e := new(examples.Enrollment)
+	if err := decodeJSON(r, e); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	nym, ok := p.names[e.Name]
+	if !ok {
+		return nil, &httpError{http.StatusNotFound,
+			fmt.Sprintf("%s holds no credential", e.Name)}
+	}
+	delete(p.names, e.Name)
+	delete(p.holders, nym)
+	p.epoch++
+
+	return &examples.Epoch{Epoch: p.epoch}, nil
+}
+
+// offer returns the nonce and attributes of a credential to the user with the given
+// registration key.
+func (p *Portal) offer(r *http.Request) (interface{}, error) {
+	req := new(examples.RegistrationKey)
+	if err := decodeJSON(r, req); err != nil {
+		return nil, err
+	}
+
+	org, err := cl.NewOrgFromParams(p.org.Params, p.org.Keys)
+	if err != nil {
+		return nil, err
+	}
+	nonce := org.GetCredIssueNonce()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.enrolled[req.Key]
+	if !ok {
+		return nil, &httpError{http.StatusForbidden, "registration key is not valid"}
+	}
+	p.offers[req.Key] = org
+
+	return &examples.IssuanceOffer{
+		Nonce:      nonce,
+		Attributes: p.attributes(e),
+	}, nil
+}
+
+func (p *Portal) issue(r *http.Request) (interface{}, error) {
+	req := new(examples.IssuanceRequest)
+	if err := decodeJSON(r, req); err != nil {
+		return nil, err
+	}
+	if req.Request == nil || req.Request.Nym == nil {
+		return nil, &httpError{http.StatusBadRequest, "credential request is required"}
+	}
+
+	// the registration key is used up whether issuance succeeds or not
+	p.mu.Lock()
+	e, ok := p.enrolled[req.Key]
+	org := p.offers[req.Key]
+	delete(p.enrolled, req.Key)
+	delete(p.offers, req.Key)
+	attrs := p.attributes(e)
+	p.mu.Unlock()
+	if !ok || org == nil {
+		return nil, &httpError{http.StatusForbidden, "registration key is not valid"}
+	}
+
+	if err := checkKnownAttrs(req.Request.KnownAttrs, attrs); err != nil {
+		return nil, &httpError{http.StatusBadRequest, err.Error()}
+	}
+	res, err := org.IssueCred(req.Request)
+	if err != nil {
+		return nil, &httpError{http.StatusBadRequest, err.Error()}
+	}
+	if err := p.records.Store(req.Request.Nym, res.Record); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.names[e.Name]; ok {
+		return nil, &httpError{http.StatusConflict,
+			fmt.Sprintf("%s already holds a credential", e.Name)}
+	}
+	nym := req.Request.Nym.String()
+	p.holders[nym] = &holder{
+		Enrollment:   e,
+		credReqNonce: req.Request.Nonce,
+	}
+	p.names[e.Name] = nym
+
+	return &examples.IssuedCred{
+		Cred:       res.Cred,
+		AProof:     res.AProof,
+		Attributes: attrs,
+	}, nil
+}
+
+// refresh updates the credential of a holder to the current epoch.
+func (p *Portal) refresh(r *http.Request) (interface{}, error) {
+	req := new(examples.RefreshRequest)
+	if err := decodeJSON(r, req); err != nil {
+		return nil, err
+	}
+	if req.Nym == nil || req.Nonce == nil {
+		return nil, &httpError{http.StatusBadRequest, "nym and nonce are required"}
+	}
+
+	p.mu.Lock()
+	h, ok := p.holders[req.Nym.String()]
+	var attrs *examples.Attributes
+	if ok {
+		attrs = p.attributes(h.Enrollment)
+	}
+	p.mu.Unlock()
+	if !ok {
+		return nil, &httpError{http.StatusForbidden, "credential was revoked"}
+	}
+	if h.credReqNonce.Cmp(req.Nonce) != 0 {
+		return nil, &httpError{http.StatusForbidden, "nonce does not match the credential"}
+	}
+
+	rec, err := p.records.Load(req.Nym)
+	if err != nil {
+		return nil, err
+	}
+	known, err := knownAttrs(attrs)
+	if err != nil {
+		return nil, err
+	}
+	org, err := cl.NewOrgFromParams(p.org.Params, p.org.Keys)
+	if err != nil {
+		return nil, err
+	}
+	res, err := org.UpdateCred(req.Nym, rec, req.Nonce, known)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.records.Store(req.Nym, res.Record); err != nil {
+		return nil, err
+	}
+
+	return &examples.IssuedCred{
+		Cred:       res.Cred,
+		AProof:     res.AProof,
+		Attributes: attrs,
+	}, nil
+}
+
+func (p *Portal) nonce(r *http.Request) (interface{}, error) {
+	nonce := p.org.GenNonce()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	for n, expires := range p.nonces {
+		if now.After(expires) {
+			delete(p.nonces, n)
+		}
+	}
+	p.nonces[nonce.String()] = now.Add(nonceTTL)
+
+	return &examples.Nonce{Nonce: nonce}, nil
+}
+
+// login verifies a presentation of a credential and returns a session key for
+// its scope.
+func (p *Portal) login(r *http.Request) (interface{}, error) {
+	pr := new(examples.Presentation)
+	if err := decodeJSON(r, pr); err != nil {
+		return nil, err
+	}
+	if pr.Nonce == nil || pr.Proof == nil {
+		return nil, &httpError{http.StatusBadRequest, "nonce and proof are required"}
+	}
+
+	p.mu.Lock()
+	expires, ok := p.nonces[pr.Nonce.String()]
+	delete(p.nonces, pr.Nonce.String())
+	epoch := p.epoch
+	p.mu.Unlock()
+	if !ok || time.Now().After(expires) {
+		return nil, &httpError{http.StatusForbidden, "nonce is not valid"}
+	}
+
+	if err := p.checkRevealed(pr, epoch); err != nil {
+		return nil, &httpError{http.StatusForbidden, err.Error()}
+	}
+	if len(pr.Proof.Links) != 0 {
+		return nil, &httpError{http.StatusBadRequest, "unexpected links of attributes"}
+	}
+	verified, err := cl.VerifyAggregateProof([]*cl.RevealedCred{{
+		PubKey:                    p.org.Keys.Pub,
+		RevealedKnownAttrsIndices: pr.RevealedKnownAttrsIndices,
+		RevealedKnownAttrs:        pr.RevealedKnownAttrs,
+	}}, pr.Proof, pr.Nonce)
+	if err != nil {
+		return nil, &httpError{http.StatusBadRequest, err.Error()}
+	}
+	if !verified {
+		return nil, &httpError{http.StatusForbidden, "proof of credential is not valid"}
+	}
+
+	id := make([]byte, 24)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	sessionKey, err := p.signer.Sign(&sessionClaims{
+		ID:        base64.RawURLEncoding.EncodeToString(id),
+		Scope:     pr.Scope,
+		Epoch:     epoch,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(p.sessionTTL).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &examples.SessionKey{SessionKey: sessionKey}, nil
+}
+
+// checkRevealed checks that pr reveals exactly the attributes required by its
+// scope, with acceptable values.
+func (p *Portal) checkRevealed(pr *examples.Presentation, epoch int64) error {
+	names, err := examples.Scope(pr.Scope)
+	if err != nil {
+		return err
+	}
+	if len(pr.RevealedKnownAttrsIndices) != len(names) ||
+		len(pr.RevealedKnownAttrs) != len(names) {
+		return fmt.Errorf("scope %s requires revealing %v", pr.Scope, names)
+	}
+
+	// revealed attributes need to hold the values of the template
+	template, err := examples.NewRawCred(&examples.Attributes{
+		Name:      "-",
+		AgeOver18: true,
+		Epoch:     epoch,
+	})
+	if err != nil {
+		return err
+	}
+	for i, name := range names {
+		ind, _ := template.GetAttrInternalIndex(name)
+		a, _ := template.GetAttr(name)
+		if pr.RevealedKnownAttrsIndices[i] != ind {
+			return fmt.Errorf("scope %s requires revealing %v", pr.Scope, names)
+		}
+		if pr.RevealedKnownAttrs[i] == nil ||
+			pr.RevealedKnownAttrs[i].Cmp(a.InternalValue()) != 0 {
+			if name == examples.AttrEpoch {
+				return fmt.Errorf("credential is not valid in the current epoch")
+			}
+			return fmt.Errorf("value of %s is not acceptable", name)
+		}
+	}
+
+	return nil
+}
+
+func (p *Portal) validate(r *http.Request) (interface{}, error) {
+	req := new(examples.SessionKey)
+	if err := decodeJSON(r, req); err != nil {
+		return nil, err
+	}
+
+	claims := new(sessionClaims)
+	if err := p.signer.Verify(req.SessionKey, claims); err != nil {
+		return &examples.SessionStatus{Reason: err.Error()}, nil
+	}
+	if time.Now().After(time.Unix(claims.ExpiresAt, 0)) {
+		return &examples.SessionStatus{Reason: "session key expired"}, nil
+	}
+	if claims.Epoch != p.Epoch() {
+		return &examples.SessionStatus{Reason: "session key was revoked"}, nil
+	}
+
+	return &examples.SessionStatus{
+		Valid:     true,
+		ID:        claims.ID,
+		Scope:     claims.Scope,
+		ExpiresAt: claims.ExpiresAt,
+	}, nil
+}
+
+// attributes returns attributes of the credential of the user enrolled with e in
+// the current epoch. The caller needs to hold p.mu.
+func (p *Portal) attributes(e *examples.Enrollment) *examples.Attributes {
+	if e == nil {
+		return nil
+	}
+	return &examples.Attributes{
+		Name:      e.Name,
+		AgeOver18: e.AgeOver18,
+		Epoch:     p.epoch,
+	}
+}
+
+// knownAttrs returns internal values of known attributes of a credential with attrs.
+func knownAttrs(attrs *examples.Attributes) ([]*big.Int, error) {
+	rc, err := examples.NewRawCred(attrs)
+	if err != nil {
+		return nil, err
+	}
+	return rc.GetKnownVals(), nil
+}
+
+// checkKnownAttrs checks that known attributes of a credential request hold attrs.
+func checkKnownAttrs(known []*big.Int, attrs *examples.Attributes) error {
+	expected, err := knownAttrs(attrs)
+	if err != nil {
+		return err
+	}
+	if len(known) != len(expected) {
+		return fmt.Errorf("expected %d known attributes, got %d", len(expected), len(known))
+	}
+	for i := range known {
+		if known[i] == nil || known[i].Cmp(expected[i]) != 0 {
+			return fmt.Errorf("known attributes do not match the enrollment")
+		}
+	}
+	return nil
+}
+
+func decodeJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return &httpError{http.StatusBadRequest, "malformed request body"}
+	}
+	return nil
+}
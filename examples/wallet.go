@@ -0,0 +1,177 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package examples
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+// Wallet holds the credential of a user issued by the portal, and proves its
+// possession to obtain session keys.
+type Wallet struct {
+	portal       *Portal
+	params       *cl.Params
+	pubKey       *cl.PubKey
+	masterSecret *big.Int
+	manager      *cl.CredManager
+	cred         *cl.Cred
+	attrs        *Attributes
+}
+
+// NewWallet returns an empty wallet of a user of portal.
+func NewWallet(portal *Portal) (*Wallet, error) {
+	pubKey, params, err := portal.PubKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Wallet{
+		portal:       portal,
+		params:       params,
+		pubKey:       pubKey,
+		masterSecret: pubKey.GenerateUserMasterSecret(),
+	}, nil
+}
+
+// Attributes returns attributes of the credential held by w, or nil if it holds
+// none.
+func (w *Wallet) Attributes() *Attributes {
+	return w.attrs
+}
+
+// Obtain obtains a credential from the portal with registration key regKey,
+// obtained by the user at enrollment.
+func (w *Wallet) Obtain(regKey string) error {
+	offer := new(IssuanceOffer)
+	if err := w.portal.Post("/credentials/offer", &RegistrationKey{regKey}, offer); err != nil {
+		return err
+	}
+
+	rc, err := NewRawCred(offer.Attributes)
+	if err != nil {
+		return err
+	}
+	manager, err := cl.NewCredManager(w.params, w.pubKey, w.masterSecret, rc)
+	if err != nil {
+		return err
+	}
+	credReq, err := manager.GetCredRequest(offer.Nonce)
+	if err != nil {
+		return err
+	}
+
+	issued := new(IssuedCred)
+	if err := w.portal.Post("/credentials", &IssuanceRequest{
+		Key:     regKey,
+		Request: credReq,
+	}, issued); err != nil {
+		return err
+	}
+
+	w.manager = manager
+	return w.accept(issued)
+}
+
+// Refresh obtains the credential of w for the current epoch of the portal. It
+// fails if the credential was revoked.
+func (w *Wallet) Refresh() error {
+	if w.manager == nil {
+		return fmt.Errorf("wallet holds no credential")
+	}
+
+	issued := new(IssuedCred)
+	if err := w.portal.Post("/credentials/refresh", &RefreshRequest{
+		Nym:   w.manager.Nym,
+		Nonce: w.manager.CredReqNonce,
+	}, issued); err != nil {
+		return err
+	}
+
+	rc, err := NewRawCred(issued.Attributes)
+	if err != nil {
+		return err
+	}
+	w.manager.Update(rc)
+	return w.accept(issued)
+}
+
+// accept verifies the credential issued to w and keeps it.
+func (w *Wallet) accept(issued *IssuedCred) error {
+	if issued.Cred == nil || issued.AProof == nil {
+		return fmt.Errorf("incomplete credential")
+	}
+	ok, err := w.manager.Verify(issued.Cred, issued.AProof)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("credential not valid")
+	}
+
+	w.cred = issued.Cred
+	w.attrs = issued.Attributes
+	return nil
+}
+
+// Login proves the possession of the credential of w to the portal, revealing
+// the attributes required by scope, and returns the session key obtained.
+func (w *Wallet) Login(scope string) (string, error) {
+	if w.cred == nil {
+		return "", fmt.Errorf("wallet holds no credential")
+	}
+	names, err := Scope(scope)
+	if err != nil {
+		return "", err
+	}
+	indices := make([]int, len(names))
+	for i, name := range names {
+		if indices[i], err = w.manager.RawCred.GetAttrInternalIndex(name); err != nil {
+			return "", err
+		}
+	}
+
+	nonce := new(Nonce)
+	if err := w.portal.Get("/sessions/nonce", nonce); err != nil {
+		return "", err
+	}
+	proof, err := cl.BuildAggregateProof([]*cl.AggregateCred{{
+		Manager:                   w.manager,
+		Cred:                      w.cred,
+		RevealedKnownAttrsIndices: indices,
+	}}, nil, nonce.Nonce)
+	if err != nil {
+		return "", err
+	}
+	revealed, _ := w.manager.FilterAttributes(indices, nil)
+
+	sessionKey := new(SessionKey)
+	if err := w.portal.Post("/sessions", &Presentation{
+		Scope:                     scope,
+		Nonce:                     nonce.Nonce,
+		RevealedKnownAttrsIndices: indices,
+		RevealedKnownAttrs:        revealed,
+		Proof:                     proof,
+	}, sessionKey); err != nil {
+		return "", err
+	}
+
+	return sessionKey.SessionKey, nil
+}
@@ -0,0 +1,279 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package fuzz holds fuzz targets of the parts of emmy that handle messages of
+// clients: decoding of wire formats, conversion of protobuf messages to native
+// types and verification of proofs in them. Targets follow the interface of
+// go-fuzz (github.com/dvyukov/go-fuzz), for example:
+//
+//	go-fuzz-build -func FuzzProveCredential github.com/xlab-si/emmy/fuzz
+//	go-fuzz -bin fuzz-fuzz.zip -workdir fuzz/corpus/prove
+//
+// Each target returns 1 when the input was well-formed enough to reach
+// verification, which makes go-fuzz prioritize it, and 0 otherwise.
+//
+// Targets that verify CL proofs need the keys of the organization, which are
+// read as configured (by default from testdata_dir).
+package fuzz
+
+import (
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+	"github.com/xlab-si/emmy/grpcweb"
+	pb "github.com/xlab-si/emmy/proto"
+)
+
+// FuzzMessage decodes a protocol message and converts its content, if it is
+// one of the CL or BBS messages, to native types.
+func FuzzMessage(data []byte) int {
+	msg := new(pb.Message)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return 0
+	}
+
+	var err error
+	switch c := msg.Content.(type) {
+	case *pb.Message_CLCredReq:
+		_, err = c.CLCredReq.GetNativeType()
+	case *pb.Message_CLCredential:
+		_, _, err = c.CLCredential.GetNativeType()
+	case *pb.Message_UpdateClCredential:
+		_, _, _, err = c.UpdateClCredential.GetNativeType()
+	case *pb.Message_ProveClCredential:
+		_, _, _, _, _, _, err = c.ProveClCredential.GetNativeType()
+	case *pb.Message_CLThresholdCommitment:
+		_, err = c.CLThresholdCommitment.GetNativeType()
+	case *pb.Message_CLThresholdCommitments:
+		_, err = c.CLThresholdCommitments.GetNativeType()
+	case *pb.Message_CLThresholdCiphertexts:
+		_, err = c.CLThresholdCiphertexts.GetNativeType()
+	case *pb.Message_CLPartialSignature:
+		_, err = c.CLPartialSignature.GetNativeType()
+	case *pb.Message_CLThresholdValue:
+		_, err = c.CLThresholdValue.GetNativeType()
+	case *pb.Message_BBSCredRequest:
+		_, err = c.BBSCredRequest.GetNativeType()
+	case *pb.Message_BBSSignature:
+		_, err = c.BBSSignature.GetNativeType()
+	case *pb.Message_BBSProof:
+		_, err = c.BBSProof.GetNativeType()
+	case *pb.Message_EcGroupElement:
+		c.EcGroupElement.GetNativeType()
+	}
+	if err != nil {
+		return 0
+	}
+
+	return 1
+}
+
+// FuzzGrpcWeb decodes a body of a gRPC-Web request, in both binary and text
+// variants.
+func FuzzGrpcWeb(data []byte) int {
+	ret := 0
+	for _, contentType := range []string{grpcweb.ContentType, grpcweb.ContentTypeText} {
+		if hasMsg, err := grpcweb.Decode(data, contentType, new(pb.Message)); err == nil && hasMsg {
+			ret = 1
+		}
+	}
+
+	return ret
+}
+
+// FuzzIssueCredential verifies a credential request, as the organization does
+// before issuing a credential.
+func FuzzIssueCredential(data []byte) int {
+	req := new(pb.CLCredReq)
+	if err := proto.Unmarshal(data, req); err != nil {
+		return 0
+	}
+	credReq, err := req.GetNativeType()
+	if err != nil {
+		return 0
+	}
+
+	org := loadOrg()
+	org.GetCredIssueNonce()
+	org.IssueCred(credReq)
+
+	return 1
+}
+
+// FuzzProveCredential verifies a proof of possession of a credential.
+func FuzzProveCredential(data []byte) int {
+	p := new(pb.ProveCLCredential)
+	if err := proto.Unmarshal(data, p); err != nil {
+		return 0
+	}
+	A, proof, knownAttrs, commitmentsOfAttrs, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, err := p.GetNativeType()
+	if err != nil {
+		return 0
+	}
+
+	org := loadOrg()
+	org.GetProveCredNonce()
+	org.ProveCred(A, proof, revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices,
+		knownAttrs, commitmentsOfAttrs)
+
+	return 1
+}
+
+// FuzzPseudonymsys decodes a message of the pseudonym system with the values
+// checked against the configured group or curve, as the server does. Registrations
+// of nyms are passed on to the verification of the CA certificate.
+func FuzzPseudonymsys(data []byte) int {
+	msg := new(pb.Message)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return 0
+	}
+
+	group, curve := loadPseudonymsys()
+	d := pb.NewSchnorrDecoder(group)
+	ecd := pb.NewECDecoder(curve)
+	var err error
+	switch c := msg.Content.(type) {
+	case *pb.Message_PseudonymsysNymGenProofRandomData:
+		p := c.PseudonymsysNymGenProofRandomData
+		x1, nymA, nymB := d.Element("X1", p.X1), d.Element("A1", p.A1), d.Element("B1", p.B1)
+		x2, blindedA, blindedB := d.Element("X2", p.X2), d.Element("A2", p.A2),
+			d.Element("B2", p.B2)
+		r, s := d.Int("R", p.R), d.Int("S", p.S)
+		if d.Err() != nil {
+			return 0
+		}
+		gen := pseudsys.NewNymGenerator(group, config.LoadPseudonymsysCAPubKey())
+		gen.GetChallenge(nymA, blindedA, nymB, blindedB, x1, x2, r, s)
+	case *pb.Message_PseudonymsysNymGenProofRandomDataEc:
+		p := c.PseudonymsysNymGenProofRandomDataEc
+		x1, nymA, nymB := ecd.ECElement("X1", p.X1), ecd.ECElement("A1", p.A1),
+			ecd.ECElement("B1", p.B1)
+		x2, blindedA, blindedB := ecd.ECElement("X2", p.X2), ecd.ECElement("A2", p.A2),
+			ecd.ECElement("B2", p.B2)
+		r, s := ecd.Int("R", p.R), ecd.Int("S", p.S)
+		if ecd.Err() != nil {
+			return 0
+		}
+		gen := ecpseudsys.NewNymGenerator(config.LoadPseudonymsysCAPubKey(), curve)
+		gen.GetChallenge(nymA, blindedA, nymB, blindedB, x1, x2, r, s)
+	case *pb.Message_SchnorrProofRandomData:
+		p := c.SchnorrProofRandomData
+		d.Element("X", p.X)
+		d.Element("A", p.A)
+		d.Element("B", p.B)
+	case *pb.Message_SchnorrEcProofRandomData:
+		p := c.SchnorrEcProofRandomData
+		ecd.ECElement("X", p.X)
+		ecd.ECElement("A", p.A)
+		ecd.ECElement("B", p.B)
+	case *pb.Message_SchnorrProofData:
+		d.Exponent("Z", c.SchnorrProofData.Z)
+	case *pb.Message_DoubleBigint:
+		d.Exponent("X1", c.DoubleBigint.X1)
+		d.Exponent("X2", c.DoubleBigint.X2)
+	case *pb.Message_PseudonymsysTransferCredentialData:
+		_, err = c.PseudonymsysTransferCredentialData.GetCredential().GetNativeType(group)
+	case *pb.Message_PseudonymsysTransferCredentialDataEc:
+		_, err = c.PseudonymsysTransferCredentialDataEc.GetCredential().GetNativeType(curve)
+	default:
+		return 0
+	}
+	if err != nil || d.Err() != nil || ecd.Err() != nil {
+		return 0
+	}
+
+	return 1
+}
+
+// FuzzGenerateNymNI verifies a non-interactive proof for registration of a nym.
+func FuzzGenerateNymNI(data []byte) int {
+	p := new(pb.PseudonymsysNymGenProofNI)
+	if err := proto.Unmarshal(data, p); err != nil {
+		return 0
+	}
+	proof, err := p.GetNativeType()
+	if err != nil {
+		return 0
+	}
+
+	group, _ := loadPseudonymsys()
+	d := pb.NewSchnorrDecoder(group)
+	nymA, blindedA := d.Element("A1", p.Data.A1), d.Element("A2", p.Data.A2)
+	nymB, blindedB := d.Element("B1", p.Data.B1), d.Element("B2", p.Data.B2)
+	r, s := d.Int("R", p.Data.R), d.Int("S", p.Data.S)
+	d.Element("X1", p.Data.X1)
+	d.Element("X2", p.Data.X2)
+	d.Exponent("Z", p.Z)
+	if d.Err() != nil {
+		return 0
+	}
+
+	gen := pseudsys.NewNymGenerator(group, config.LoadPseudonymsysCAPubKey())
+	gen.VerifyNI(nymA, blindedA, nymB, blindedB, r, s, proof,
+		p.Context.Value(pb.GenerateNymNIMethod))
+
+	return 1
+}
+
+var (
+	org     *cl.Org
+	orgOnce sync.Once
+
+	pseudonymsysGroup *schnorr.Group
+	pseudonymsysCurve ec.Curve
+	pseudonymsysOnce  sync.Once
+)
+
+// loadOrg returns the configured CL organization. It panics if the organization
+// cannot be loaded, as targets cannot run without it.
+func loadOrg() *cl.Org {
+	orgOnce.Do(func() {
+		params, err := cl.LoadParams()
+		if err != nil {
+			panic(err)
+		}
+		pubKeyPath, secKeyPath := config.LoadCLKeyPaths()
+		if org, err = cl.LoadOrg(params, pubKeyPath, secKeyPath); err != nil {
+			panic(err)
+		}
+	})
+
+	return org
+}
+
+// loadPseudonymsys returns the configured group and curve of the pseudonym system.
+// It panics if they cannot be loaded.
+func loadPseudonymsys() (*schnorr.Group, ec.Curve) {
+	pseudonymsysOnce.Do(func() {
+		var err error
+		if pseudonymsysGroup, err = config.LoadGroup("pseudonymsys"); err != nil {
+			panic(err)
+		}
+		if pseudonymsysCurve, err = config.LoadCurve("pseudonymsys"); err != nil {
+			panic(err)
+		}
+	})
+
+	return pseudonymsysGroup, pseudonymsysCurve
+}
@@ -0,0 +1,237 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package fuzz
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/grpcweb"
+	pb "github.com/xlab-si/emmy/proto"
+)
+
+// seeds returns well-formed messages of a credential request and a proof of the
+// credential issued on it, created by a user of the configured organization.
+func seeds(t *testing.T) (*pb.CLCredReq, *pb.ProveCLCredential) {
+	org := loadOrg()
+	rc := cl.NewRawCred(cl.NewAttrCount(5, 1, 0))
+	_ = rc.AddStrAttr("Name", "Jack", true)
+	_ = rc.AddStrAttr("Gender", "M", true)
+	_ = rc.AddStrAttr("Graduated", "true", true)
+	_ = rc.AddInt64Attr("DateMin", 1512643000, true)
+	_ = rc.AddInt64Attr("DateMax", 1592643000, true)
+	_ = rc.AddInt64Attr("Age", 50, false)
+
+	params, _ := cl.LoadParams()
+	cm, err := cl.NewCredManager(params, org.Keys.Pub, org.Keys.Pub.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+	credReq, err := cm.GetCredRequest(org.GetCredIssueNonce())
+	require.NoError(t, err)
+	res, err := org.IssueCred(credReq)
+	require.NoError(t, err)
+
+	nonce := org.GetProveCredNonce()
+	randCred, proof, err := cm.BuildProof(res.Cred, []int{0}, []int{}, nonce)
+	require.NoError(t, err)
+	knownAttrs, commitmentsOfAttrs := cm.FilterAttributes([]int{0}, []int{})
+	ok, err := org.ProveCred(randCred.A, proof, []int{0}, []int{}, knownAttrs, commitmentsOfAttrs)
+	require.True(t, ok, "seed proof not valid: %v", err)
+
+	return pb.ToPbCredRequest(credReq), pb.ToPbProveCLCredential(randCred.A, proof,
+		knownAttrs, commitmentsOfAttrs, []int{0}, []int{})
+}
+
+// mutations returns data along with some of its truncations and modifications.
+func mutations(data []byte) [][]byte {
+	m := [][]byte{data, nil, {0}, {0xff, 0xff, 0xff, 0xff}}
+	for i := 1; i < 8; i++ {
+		n := len(data) * i / 8
+		m = append(m, data[:n])
+
+		flipped := append([]byte{}, data...)
+		flipped[n] ^= 0xff
+		m = append(m, flipped)
+	}
+
+	return m
+}
+
+func marshal(t *testing.T, msg proto.Message) []byte {
+	data, err := proto.Marshal(msg)
+	require.NoError(t, err)
+	return data
+}
+
+// TestTargets runs targets on inputs that used to panic the server, and on
+// mutations of well-formed messages.
+func TestTargets(t *testing.T) {
+	credReq, proveCred := seeds(t)
+
+	// proofs of credentials with invalid or inconsistent attribute indices,
+	// non-invertible bases and missing parts
+	var malformedProofs []*pb.ProveCLCredential
+	for _, modify := range []func(p *pb.ProveCLCredential){
+		func(p *pb.ProveCLCredential) { p.RevealedKnownAttrs = []int32{99} },
+		func(p *pb.ProveCLCredential) { p.RevealedKnownAttrs = []int32{-1} },
+		func(p *pb.ProveCLCredential) { p.RevealedKnownAttrs = []int32{0, 1} },
+		func(p *pb.ProveCLCredential) { p.RevealedCommitmentsOfAttrs = []int32{5} },
+		func(p *pb.ProveCLCredential) { p.Proof.ProofData = p.Proof.ProofData[:1] },
+		func(p *pb.ProveCLCredential) { p.Proof.ProofData = []string{"x"} },
+		func(p *pb.ProveCLCredential) {
+			p.A = nil
+			for i := range p.Proof.ProofData {
+				p.Proof.ProofData[i] = "-1"
+			}
+		},
+		func(p *pb.ProveCLCredential) { p.Proof = nil },
+	} {
+		p := proto.Clone(proveCred).(*pb.ProveCLCredential)
+		modify(p)
+		malformedProofs = append(malformedProofs, p)
+	}
+
+	var malformedReqs []*pb.CLCredReq
+	for _, modify := range []func(r *pb.CLCredReq){
+		func(r *pb.CLCredReq) { r.UProof = nil },
+		func(r *pb.CLCredReq) { r.NymProof.ProofData = nil },
+		func(r *pb.CLCredReq) { r.UProof.ProofData = r.UProof.ProofData[:1] },
+		func(r *pb.CLCredReq) { r.CommitmentsOfAttrsProofs[0].ProofData = nil },
+		func(r *pb.CLCredReq) { r.CommitmentsOfAttrsProofs = nil },
+		func(r *pb.CLCredReq) { r.KnownAttrs = append(r.KnownAttrs, r.KnownAttrs...) },
+	} {
+		r := proto.Clone(credReq).(*pb.CLCredReq)
+		modify(r)
+		malformedReqs = append(malformedReqs, r)
+	}
+
+	for _, p := range malformedProofs {
+		data := marshal(t, p)
+		FuzzProveCredential(data)
+		FuzzMessage(marshal(t, &pb.Message{Content: &pb.Message_ProveClCredential{ProveClCredential: p}}))
+	}
+	for _, r := range malformedReqs {
+		FuzzIssueCredential(marshal(t, r))
+		FuzzMessage(marshal(t, &pb.Message{Content: &pb.Message_CLCredReq{CLCredReq: r}}))
+	}
+
+	for _, data := range mutations(marshal(t, proveCred)) {
+		FuzzProveCredential(data)
+	}
+	for _, data := range mutations(marshal(t, credReq)) {
+		FuzzIssueCredential(data)
+	}
+
+	body, err := grpcweb.EncodeRequest(&pb.Message{Content: &pb.Message_CLCredReq{CLCredReq: credReq}},
+		grpcweb.ContentType)
+	require.NoError(t, err)
+	assert.Equal(t, 1, FuzzGrpcWeb(body))
+	for _, data := range mutations(body) {
+		FuzzGrpcWeb(data)
+		FuzzMessage(data)
+	}
+}
+
+// TestProveCredRejectsMalformed checks that the organization rejects proofs with
+// attribute indices that do not match the credential structure.
+func TestProveCredRejectsMalformed(t *testing.T) {
+	org := loadOrg()
+	_, proveCred := seeds(t)
+	A, proof, knownAttrs, commitmentsOfAttrs, _, _, err := proveCred.GetNativeType()
+	require.NoError(t, err)
+
+	for _, indices := range [][]int{{99}, {-1}, {0, 0}, {}} {
+		ok, err := org.ProveCred(A, proof, indices, []int{}, knownAttrs, commitmentsOfAttrs)
+		assert.False(t, ok)
+		assert.Error(t, err, "indices %v", indices)
+	}
+
+	// A is not invertible
+	ok, _ := org.ProveCred(big.NewInt(0), proof, []int{0}, []int{}, knownAttrs,
+		commitmentsOfAttrs)
+	assert.False(t, ok)
+}
+
+// TestPseudonymsysTargets checks that values of messages of the pseudonym system
+// that do not belong to the configured group or curve are rejected.
+func TestPseudonymsysTargets(t *testing.T) {
+	group, curve := loadPseudonymsys()
+	ecGroup := ec.NewGroup(curve)
+	el := group.GetRandomElement().Bytes()
+	point := pb.ToPbECGroupElement(ecGroup.GetRandomElement())
+	pMinOne := new(big.Int).Sub(group.P, big.NewInt(1))
+
+	for _, invalid := range [][]byte{nil, {1}, group.P.Bytes(), pMinOne.Bytes(),
+		make([]byte, pb.MaxIntLen+1)} {
+		msg := &pb.Message{Content: &pb.Message_SchnorrProofRandomData{
+			SchnorrProofRandomData: &pb.SchnorrProofRandomData{X: el, A: el, B: invalid},
+		}}
+		assert.Equal(t, 0, FuzzPseudonymsys(marshal(t, msg)))
+	}
+	for _, invalid := range []*pb.ECGroupElement{nil, {}, {X: point.X, Y: point.X},
+		{X: point.X, Y: curve.Params().P.Bytes()}} {
+		msg := &pb.Message{Content: &pb.Message_SchnorrEcProofRandomData{
+			SchnorrEcProofRandomData: &pb.SchnorrECProofRandomData{X: point, A: point,
+				B: invalid},
+		}}
+		assert.Equal(t, 0, FuzzPseudonymsys(marshal(t, msg)))
+	}
+	msg := &pb.Message{Content: &pb.Message_SchnorrProofData{
+		SchnorrProofData: &pb.SchnorrProofData{Z: group.Q.Bytes()},
+	}}
+	assert.Equal(t, 0, FuzzPseudonymsys(marshal(t, msg)))
+
+	valid := []*pb.Message{
+		{Content: &pb.Message_SchnorrProofRandomData{
+			SchnorrProofRandomData: &pb.SchnorrProofRandomData{X: el, A: el, B: el},
+		}},
+		{Content: &pb.Message_SchnorrEcProofRandomData{
+			SchnorrEcProofRandomData: &pb.SchnorrECProofRandomData{X: point, A: point,
+				B: point},
+		}},
+		{Content: &pb.Message_PseudonymsysNymGenProofRandomData{
+			PseudonymsysNymGenProofRandomData: &pb.PseudonymsysNymGenProofRandomData{
+				X1: el, A1: el, B1: el, X2: el, A2: el, B2: el, R: el, S: el,
+			},
+		}},
+	}
+	for _, msg := range valid {
+		data := marshal(t, msg)
+		assert.Equal(t, 1, FuzzPseudonymsys(data))
+		for _, data := range mutations(data) {
+			FuzzPseudonymsys(data)
+			FuzzMessage(data)
+		}
+	}
+
+	proof := &pb.PseudonymsysNymGenProofNI{
+		Data: &pb.PseudonymsysNymGenProofRandomData{
+			X1: el, A1: el, B1: el, X2: el, A2: el, B2: el, R: el, S: el,
+		},
+		Challenge: el,
+		Z:         el,
+		Context:   pb.NewNIContext(),
+	}
+	for _, data := range mutations(marshal(t, proof)) {
+		FuzzGenerateNymNI(data)
+	}
+}
@@ -0,0 +1,243 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package grpcweb implements the framing of the gRPC-Web protocol, which lets browser
+// clients call gRPC services over HTTP/1.1 or HTTP/2 without a proxy.
+//
+// As browsers cannot stream requests, emmy's bidirectional protocol streams are
+// run over a sequence of requests: each request carries one message of the client,
+// and its response carries the next message of the server. Requests of the same
+// stream are tied together with the StreamIDHeader, which the server sets on the
+// response to the first request. A request without messages ends the client's
+// side of the stream, and its response carries the final status of the stream.
+package grpcweb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Content types of gRPC-Web requests and responses. Bodies of the text variant
+// are base64 encoded.
+const (
+	ContentType     = "application/grpc-web+proto"
+	ContentTypeText = "application/grpc-web-text+proto"
+)
+
+// StreamIDHeader identifies the stream a request belongs to.
+const StreamIDHeader = "X-Emmy-Stream-Id"
+
+// flagTrailer marks frames holding trailers rather than messages.
+const flagTrailer = 0x80
+
+// IsGrpcWeb reports whether contentType is a gRPC-Web content type.
+func IsGrpcWeb(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/grpc-web")
+}
+
+// IsText reports whether contentType is the text (base64) variant of gRPC-Web.
+func IsText(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/grpc-web-text")
+}
+
+// Decode decodes a body of the given content type into at most one message, stored
+// in msg, and trailers. It reports whether the body held a message. When the body
+// holds trailers with a non-OK status, the status is returned as an error.
+func Decode(body []byte, contentType string, msg proto.Message) (bool, error) {
+	if IsText(contentType) {
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(body)))
+		if err != nil {
+			return false, fmt.Errorf("malformed gRPC-Web text body: %v", err)
+		}
+		body = decoded
+	}
+
+	hasMsg := false
+	for len(body) > 0 {
+		if len(body) < 5 {
+			return false, fmt.Errorf("truncated gRPC-Web frame")
+		}
+		flags := body[0]
+		n := binary.BigEndian.Uint32(body[1:5])
+		if uint64(len(body)-5) < uint64(n) {
+			return false, fmt.Errorf("truncated gRPC-Web frame")
+		}
+		data := body[5 : 5+n]
+		body = body[5+n:]
+
+		if flags&flagTrailer != 0 {
+			if err := decodeTrailers(data); err != nil {
+				return hasMsg, err
+			}
+			continue
+		}
+		if hasMsg {
+			return false, fmt.Errorf("more than one message in gRPC-Web body")
+		}
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return false, fmt.Errorf("malformed message: %v", err)
+		}
+		hasMsg = true
+	}
+
+	return hasMsg, nil
+}
+
+// EncodeRequest encodes msg (if not nil) into a request body of the given content type.
+func EncodeRequest(msg proto.Message, contentType string) ([]byte, error) {
+	return encode(msg, nil, contentType)
+}
+
+// EncodeResponse encodes msg (if not nil) and trailers holding the status of err into
+// a response body of the given content type.
+func EncodeResponse(msg proto.Message, err error, contentType string) ([]byte, error) {
+	return encode(msg, encodeTrailers(err), contentType)
+}
+
+func encode(msg proto.Message, trailers []byte, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	if msg != nil {
+		data, err := proto.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+		writeFrame(&buf, 0, data)
+	}
+	if trailers != nil {
+		writeFrame(&buf, flagTrailer, trailers)
+	}
+
+	if IsText(contentType) {
+		return []byte(base64.StdEncoding.EncodeToString(buf.Bytes())), nil
+	}
+	return buf.Bytes(), nil
+}
+
+func writeFrame(w io.Writer, flags byte, data []byte) {
+	header := make([]byte, 5)
+	header[0] = flags
+	binary.BigEndian.PutUint32(header[1:], uint32(len(data)))
+	w.Write(header)
+	w.Write(data)
+}
+
+// encodeTrailers returns trailers with the status of err in HTTP/1 header format.
+// Details of the status are passed in the grpc-status-details-bin trailer.
+func encodeTrailers(err error) []byte {
+	s, _ := status.FromError(err)
+	trailers := fmt.Sprintf("grpc-status: %d\r\ngrpc-message: %s\r\n", s.Code(),
+		escape(s.Message()))
+	if details := EncodeStatusDetails(s); details != "" {
+		trailers += fmt.Sprintf("grpc-status-details-bin: %s\r\n", details)
+	}
+	return []byte(trailers)
+}
+
+// EncodeStatusDetails returns the value of the grpc-status-details-bin header (or
+// trailer) for status s, or an empty string if s has no details.
+func EncodeStatusDetails(s *status.Status) string {
+	p := s.Proto()
+	if p == nil || len(p.Details) == 0 {
+		return ""
+	}
+	data, err := proto.Marshal(p)
+	if err != nil {
+		return ""
+	}
+	return base64.RawStdEncoding.EncodeToString(data)
+}
+
+// decodeTrailers returns the status held by trailers as an error.
+func decodeTrailers(data []byte) error {
+	r := textproto.NewReader(bufio.NewReader(bytes.NewReader(append(data, '\r', '\n'))))
+	h, err := r.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("malformed gRPC-Web trailers: %v", err)
+	}
+
+	return StatusFromHeader(h.Get("Grpc-Status"), h.Get("Grpc-Message"),
+		h.Get("Grpc-Status-Details-Bin"))
+}
+
+// StatusFromHeader returns the status given by values of grpc-status, grpc-message
+// and grpc-status-details-bin headers (or trailers) as an error. Details are ignored
+// if they are malformed.
+func StatusFromHeader(code, msg, details string) error {
+	if code == "" {
+		return nil
+	}
+	c, err := strconv.Atoi(code)
+	if err != nil {
+		return fmt.Errorf("malformed grpc-status: %s", code)
+	}
+	if codes.Code(c) == codes.OK {
+		return nil
+	}
+
+	if details != "" {
+		// padding is optional in binary headers
+		data, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(details, "="))
+		p := new(spb.Status)
+		if err == nil && proto.Unmarshal(data, p) == nil && p.Code == int32(c) {
+			return status.ErrorProto(p)
+		}
+	}
+
+	return status.Error(codes.Code(c), unescape(msg))
+}
+
+// escape percent-encodes characters of status messages that are not allowed in
+// header values, as required by the gRPC protocol.
+func escape(msg string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func unescape(msg string) string {
+	var b bytes.Buffer
+	for i := 0; i < len(msg); i++ {
+		if msg[i] == '%' && i+2 < len(msg) {
+			if v, err := strconv.ParseUint(msg[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(msg[i])
+	}
+	return b.String()
+}
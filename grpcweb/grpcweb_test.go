@@ -0,0 +1,66 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package grpcweb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	for _, contentType := range []string{ContentType, ContentTypeText} {
+		body, err := EncodeResponse(&pb.Message{ClientId: 7}, nil, contentType)
+		require.NoError(t, err)
+		msg := new(pb.Message)
+		hasMsg, err := Decode(body, contentType, msg)
+		require.NoError(t, err)
+		assert.True(t, hasMsg)
+		assert.Equal(t, int32(7), msg.ClientId)
+
+		body, err = EncodeResponse(nil, status.Error(codes.PermissionDenied,
+			"invalid key: 100%\r\n"), contentType)
+		require.NoError(t, err)
+		hasMsg, err = Decode(body, contentType, msg)
+		assert.False(t, hasMsg)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Equal(t, "invalid key: 100%\r\n", status.Convert(err).Message())
+
+		// causes of protocol errors are passed in status details
+		body, err = EncodeResponse(nil, pb.NewStatusError(codes.Unauthenticated,
+			pb.ErrorCode_REVOKED, "credential revoked"), contentType)
+		require.NoError(t, err)
+		_, err = Decode(body, contentType, msg)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		require.NotNil(t, pb.ToProtocolError(err))
+		assert.Equal(t, pb.ErrorCode_REVOKED, pb.ToProtocolError(err).Code)
+
+		body, err = EncodeRequest(nil, contentType)
+		require.NoError(t, err)
+		hasMsg, err = Decode(body, contentType, msg)
+		require.NoError(t, err)
+		assert.False(t, hasMsg)
+	}
+
+	_, err := Decode([]byte{0, 0, 0, 0, 9, 1}, ContentType, new(pb.Message))
+	assert.Error(t, err)
+}
@@ -0,0 +1,396 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package interop relates emmy's CL credentials to credentials of other anonymous
+// credential systems. It currently covers Hyperledger Fabric Idemix.
+//
+// Idemix signatures are defined over the pairing-friendly curve FP256BN, whereas emmy's
+// CL signatures live in an RSA group, so signatures and proofs cannot be verified across
+// the two systems. What can be shared is the meaning of attributes: this package reads
+// Idemix issuer public keys and signer configurations (as written by idemixgen and
+// fabric-ca), encodes attribute values exactly as Idemix does, and builds emmy
+// credentials holding the same attribute values. Revealed attributes of an emmy proof
+// can thus be checked against an Idemix disclosure and vice versa.
+//
+// The encoding gap between the systems is that emmy encodes string attributes by their
+// bytes, hashing only those that do not fit into the attribute bit length (see
+// cl.AttrEncoder), while Idemix hashes all of them into the group order of FP256BN.
+// IdemixAttr closes it by encoding emmy attributes as Idemix does.
+package interop
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+// Names of attributes of credentials issued by Fabric certificate authorities.
+const (
+	IdemixAttrOU               = "OU"
+	IdemixAttrRole             = "Role"
+	IdemixAttrEnrollmentID     = "EnrollmentID"
+	IdemixAttrRevocationHandle = "RevocationHandle"
+)
+
+// idemixFieldBytes is the length of encoded FP256BN integers.
+const idemixFieldBytes = 32
+
+// IdemixGroupOrder is the order of the groups of FP256BN, which Idemix attribute
+// values are reduced modulo.
+var IdemixGroupOrder, _ = new(big.Int).SetString(
+	"fffffffffffcf0cd46e5f25eee71a49e0cdc65fb1299921af62d536cd10b500d", 16)
+
+// IdemixHashModOrder hashes data into an attribute value the way Idemix does.
+func IdemixHashModOrder(data []byte) *big.Int {
+	digest := sha256.Sum256(data)
+	return new(big.Int).Mod(new(big.Int).SetBytes(digest[:]), IdemixGroupOrder)
+}
+
+// IdemixEncode encodes val as an Idemix attribute value. Strings are hashed, while
+// integers are taken as they are.
+func IdemixEncode(val interface{}) (*big.Int, error) {
+	var x *big.Int
+	switch v := val.(type) {
+	case string:
+		return IdemixHashModOrder([]byte(v)), nil
+	case int:
+		x = big.NewInt(int64(v))
+	case int32:
+		x = big.NewInt(int64(v))
+	case int64:
+		x = big.NewInt(v)
+	case *big.Int:
+		x = v
+	default:
+		return nil, fmt.Errorf("values of type %T cannot be encoded", val)
+	}
+	if x.Sign() < 0 || x.Cmp(IdemixGroupOrder) >= 0 {
+		return nil, fmt.Errorf("integer %v is out of range", x)
+	}
+
+	return new(big.Int).Set(x), nil
+}
+
+// IdemixBigToBytes returns x encoded as an FP256BN integer.
+func IdemixBigToBytes(x *big.Int) []byte {
+	b := make([]byte, idemixFieldBytes)
+	xb := x.Bytes()
+	copy(b[idemixFieldBytes-len(xb):], xb)
+	return b
+}
+
+// IdemixBigFromBytes decodes an FP256BN integer.
+func IdemixBigFromBytes(b []byte) *big.Int {
+	return new(big.Int).SetBytes(b)
+}
+
+// IdemixIssuerPublicKey is an Idemix issuer public key, of which only attribute names
+// are decoded.
+type IdemixIssuerPublicKey struct {
+	AttributeNames []string `protobuf:"bytes,1,rep,name=attribute_names,json=attributeNames" json:"attribute_names,omitempty"`
+}
+
+func (m *IdemixIssuerPublicKey) Reset()         { *m = IdemixIssuerPublicKey{} }
+func (m *IdemixIssuerPublicKey) String() string { return proto.CompactTextString(m) }
+func (*IdemixIssuerPublicKey) ProtoMessage()    {}
+
+// IdemixCredential is an Idemix credential, of which the curve points are not decoded.
+type IdemixCredential struct {
+	E     []byte   `protobuf:"bytes,3,opt,name=e,proto3" json:"e,omitempty"`
+	S     []byte   `protobuf:"bytes,4,opt,name=s,proto3" json:"s,omitempty"`
+	Attrs [][]byte `protobuf:"bytes,5,rep,name=attrs,proto3" json:"attrs,omitempty"`
+}
+
+func (m *IdemixCredential) Reset()         { *m = IdemixCredential{} }
+func (m *IdemixCredential) String() string { return proto.CompactTextString(m) }
+func (*IdemixCredential) ProtoMessage()    {}
+
+// IdemixSignerConfig is the configuration of an Idemix signer (a Fabric user), holding
+// the credential and the values of its attributes.
+type IdemixSignerConfig struct {
+	Cred                            []byte `protobuf:"bytes,1,opt,name=cred,proto3" json:"cred,omitempty"`
+	Sk                              []byte `protobuf:"bytes,2,opt,name=sk,proto3" json:"sk,omitempty"`
+	OrganizationalUnitIdentifier    string `protobuf:"bytes,3,opt,name=organizational_unit_identifier,json=organizationalUnitIdentifier" json:"organizational_unit_identifier,omitempty"`
+	Role                            int32  `protobuf:"varint,4,opt,name=role" json:"role,omitempty"`
+	EnrollmentID                    string `protobuf:"bytes,5,opt,name=enrollment_id,json=enrollmentId" json:"enrollment_id,omitempty"`
+	CredentialRevocationInformation []byte `protobuf:"bytes,6,opt,name=credential_revocation_information,json=credentialRevocationInformation,proto3" json:"credential_revocation_information,omitempty"`
+	RevocationHandle                string `protobuf:"bytes,7,opt,name=revocation_handle,json=revocationHandle" json:"revocation_handle,omitempty"`
+}
+
+func (m *IdemixSignerConfig) Reset()         { *m = IdemixSignerConfig{} }
+func (m *IdemixSignerConfig) String() string { return proto.CompactTextString(m) }
+func (*IdemixSignerConfig) ProtoMessage()    {}
+
+// ReadIdemixIssuerPublicKey reads an issuer public key from the file at path
+// (IssuerPublicKey in the msp directory written by idemixgen).
+func ReadIdemixIssuerPublicKey(path string) (*IdemixIssuerPublicKey, error) {
+	ipk := new(IdemixIssuerPublicKey)
+	if err := readProto(path, ipk); err != nil {
+		return nil, err
+	}
+	if len(ipk.AttributeNames) == 0 {
+		return nil, fmt.Errorf("issuer public key has no attributes")
+	}
+
+	return ipk, nil
+}
+
+// ReadIdemixSignerConfig reads a signer configuration from the file at path
+// (SignerConfig in the user directory written by idemixgen).
+func ReadIdemixSignerConfig(path string) (*IdemixSignerConfig, error) {
+	sc := new(IdemixSignerConfig)
+	if err := readProto(path, sc); err != nil {
+		return nil, err
+	}
+
+	return sc, nil
+}
+
+func readProto(path string, m proto.Message) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := proto.Unmarshal(data, m); err != nil {
+		return fmt.Errorf("malformed %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// Credential decodes the credential of sc.
+func (sc *IdemixSignerConfig) Credential() (*IdemixCredential, error) {
+	cred := new(IdemixCredential)
+	if err := proto.Unmarshal(sc.Cred, cred); err != nil {
+		return nil, fmt.Errorf("malformed credential: %v", err)
+	}
+
+	return cred, nil
+}
+
+// Values returns values of the attributes of sc named in ipk, as set by Fabric
+// certificate authorities.
+func (sc *IdemixSignerConfig) Values(ipk *IdemixIssuerPublicKey) ([]interface{}, error) {
+	values := make([]interface{}, len(ipk.AttributeNames))
+	for i, name := range ipk.AttributeNames {
+		switch name {
+		case IdemixAttrOU:
+			values[i] = sc.OrganizationalUnitIdentifier
+		case IdemixAttrRole:
+			values[i] = int64(sc.Role)
+		case IdemixAttrEnrollmentID:
+			values[i] = sc.EnrollmentID
+		case IdemixAttrRevocationHandle:
+			rh, ok := new(big.Int).SetString(sc.RevocationHandle, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid revocation handle %s", sc.RevocationHandle)
+			}
+			values[i] = rh
+		default:
+			return nil, fmt.Errorf("unknown attribute %s", name)
+		}
+	}
+
+	return values, nil
+}
+
+// CheckCredential checks that the attribute values of the credential of sc are
+// the values of sc encoded as in emmy credentials built by NewIdemixRawCred.
+func (sc *IdemixSignerConfig) CheckCredential(ipk *IdemixIssuerPublicKey) error {
+	cred, err := sc.Credential()
+	if err != nil {
+		return err
+	}
+	rc, err := NewIdemixRawCred(ipk, sc)
+	if err != nil {
+		return err
+	}
+
+	known := rc.GetKnownVals()
+	if len(cred.Attrs) != len(known) {
+		return fmt.Errorf("credential has %d attributes, expected %d", len(cred.Attrs), len(known))
+	}
+	for i, a := range cred.Attrs {
+		if IdemixBigFromBytes(a).Cmp(known[i]) != 0 {
+			return fmt.Errorf("value of attribute %s does not match", ipk.AttributeNames[i])
+		}
+	}
+
+	return nil
+}
+
+// NewIdemixRawCred returns a raw emmy credential with the attributes of sc, named
+// and ordered as in ipk. All attributes are known and encoded as IdemixAttr.
+func NewIdemixRawCred(ipk *IdemixIssuerPublicKey,
+	sc *IdemixSignerConfig) (*cl.RawCred, error) {
+	values, err := sc.Values(ipk)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := cl.NewRawCred(cl.NewAttrCount(len(values), 0, 0))
+	for i, name := range ipk.AttributeNames {
+		a, err := NewIdemixAttr(name, values[i], true)
+		if err != nil {
+			return nil, err
+		}
+		if err := rc.AddAttr(a); err != nil {
+			return nil, err
+		}
+	}
+
+	return rc, nil
+}
+
+// IdemixDisclosure returns the Idemix disclosure of attributes named in revealed,
+// which marks disclosed attributes of ipk with 1.
+func IdemixDisclosure(ipk *IdemixIssuerPublicKey, revealed []string) ([]byte, error) {
+	disclosure := make([]byte, len(ipk.AttributeNames))
+	for _, r := range revealed {
+		i := indexOf(ipk.AttributeNames, r)
+		if i < 0 {
+			return nil, fmt.Errorf("unknown attribute %s", r)
+		}
+		disclosure[i] = 1
+	}
+
+	return disclosure, nil
+}
+
+// RevealedFromIdemix converts an Idemix disclosure and attribute values (as passed
+// to the verification of Idemix signatures) to indices and values of revealed known
+// attributes of emmy credentials built by NewIdemixRawCred.
+func RevealedFromIdemix(disclosure []byte, values [][]byte) ([]int, []*big.Int, error) {
+	if len(disclosure) != len(values) {
+		return nil, nil, fmt.Errorf("disclosure and values differ in length")
+	}
+
+	var indices []int
+	var revealed []*big.Int
+	for i, d := range disclosure {
+		if d == 1 {
+			indices = append(indices, i)
+			revealed = append(revealed, IdemixBigFromBytes(values[i]))
+		}
+	}
+
+	return indices, revealed, nil
+}
+
+// RevealedToIdemix converts indices and values of revealed known attributes of
+// an emmy credential built by NewIdemixRawCred with n attributes to an Idemix
+// disclosure and attribute values. Values of attributes that are not disclosed
+// are nil.
+func RevealedToIdemix(n int, indices []int, revealed []*big.Int) ([]byte, [][]byte, error) {
+	if len(indices) != len(revealed) {
+		return nil, nil, fmt.Errorf("indices and values differ in length")
+	}
+
+	disclosure := make([]byte, n)
+	values := make([][]byte, n)
+	for i, idx := range indices {
+		if idx < 0 || idx >= n {
+			return nil, nil, fmt.Errorf("attribute index %d out of range", idx)
+		}
+		disclosure[idx] = 1
+		values[idx] = IdemixBigToBytes(revealed[i])
+	}
+
+	return disclosure, values, nil
+}
+
+func indexOf(s []string, e string) int {
+	for i, a := range s {
+		if a == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// IdemixAttr is an attribute of emmy credentials whose value is encoded as in
+// Idemix credentials (see IdemixEncode).
+type IdemixAttr struct {
+	name  string
+	known bool
+	val   interface{}
+	enc   *big.Int
+}
+
+// NewIdemixAttr returns an IdemixAttr with the given name and value.
+func NewIdemixAttr(name string, val interface{}, known bool) (*IdemixAttr, error) {
+	a := &IdemixAttr{
+		name:  name,
+		known: known,
+	}
+	if err := a.UpdateValue(val); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (a *IdemixAttr) GetValue() interface{} {
+	return a.val
+}
+
+// FromInternalValue returns the encoded value itself, as hashed values cannot
+// be decoded.
+func (a *IdemixAttr) FromInternalValue(val *big.Int) (interface{}, error) {
+	return new(big.Int).Set(val), nil
+}
+
+func (a *IdemixAttr) UpdateValue(val interface{}) error {
+	enc, err := IdemixEncode(val)
+	if err != nil {
+		return fmt.Errorf("attribute %s: %v", a.name, err)
+	}
+	a.val = val
+	a.enc = enc
+
+	return nil
+}
+
+func (a *IdemixAttr) InternalValue() *big.Int {
+	return a.enc
+}
+
+func (a *IdemixAttr) SetInternalValue() error {
+	return a.UpdateValue(a.val)
+}
+
+func (a *IdemixAttr) IsKnown() bool {
+	return a.known
+}
+
+func (a *IdemixAttr) HasVal() bool {
+	return a.enc != nil
+}
+
+func (a *IdemixAttr) GetName() string {
+	return a.name
+}
+
+func (a *IdemixAttr) String() string {
+	return fmt.Sprintf("%s (Idemix), value = %v", a.name, a.val)
+}
+
+var _ cl.CredAttr = (*IdemixAttr)(nil)
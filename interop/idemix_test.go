@@ -0,0 +1,149 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package interop
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+// Fixtures in testdata/idemix follow the wire format of the IssuerPublicKey and
+// SignerConfig files written by idemixgen, for user user1 of organizational unit
+// org1.department1 with role member and revocation handle 1. Curve points,
+// which are not used, hold random bytes.
+const (
+	testIssuerPublicKey = "testdata/idemix/msp/IssuerPublicKey"
+	testSignerConfig    = "testdata/idemix/user/SignerConfig"
+)
+
+func TestIdemixEncode(t *testing.T) {
+	expected, _ := new(big.Int).SetString(
+		"3bd262355b81f9eac1e2ff027fb721fbc8ff6827f9f5181c0dde5febadaa01c5", 16)
+	enc, err := IdemixEncode("org1.department1")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, enc)
+
+	enc, err = IdemixEncode(int64(3))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(3), enc)
+	assert.Equal(t, 32, len(IdemixBigToBytes(enc)))
+	assert.Equal(t, enc, IdemixBigFromBytes(IdemixBigToBytes(enc)))
+
+	_, err = IdemixEncode(-1)
+	assert.Error(t, err, "negative integers should not be encoded")
+	_, err = IdemixEncode(IdemixGroupOrder)
+	assert.Error(t, err, "integers out of the group should not be encoded")
+	_, err = IdemixEncode(1.5)
+	assert.Error(t, err)
+}
+
+func TestIdemixFixtures(t *testing.T) {
+	ipk, err := ReadIdemixIssuerPublicKey(testIssuerPublicKey)
+	require.NoError(t, err)
+	assert.Equal(t, []string{IdemixAttrOU, IdemixAttrRole, IdemixAttrEnrollmentID,
+		IdemixAttrRevocationHandle}, ipk.AttributeNames)
+
+	sc, err := ReadIdemixSignerConfig(testSignerConfig)
+	require.NoError(t, err)
+	assert.Equal(t, "org1.department1", sc.OrganizationalUnitIdentifier)
+	assert.Equal(t, "user1", sc.EnrollmentID)
+	assert.NoError(t, sc.CheckCredential(ipk))
+
+	sc.EnrollmentID = "user2"
+	assert.Error(t, sc.CheckCredential(ipk), "credential of another user should not match")
+
+	_, err = ReadIdemixIssuerPublicKey("testdata/idemix/msp/missing")
+	assert.Error(t, err)
+}
+
+// TestIdemixCL issues an emmy credential with the attributes of an Idemix credential
+// and checks that a proof of it reveals the values an Idemix verifier expects.
+func TestIdemixCL(t *testing.T) {
+	ipk, err := ReadIdemixIssuerPublicKey(testIssuerPublicKey)
+	require.NoError(t, err)
+	sc, err := ReadIdemixSignerConfig(testSignerConfig)
+	require.NoError(t, err)
+	idemixCred, err := sc.Credential()
+	require.NoError(t, err)
+
+	rc, err := NewIdemixRawCred(ipk, sc)
+	require.NoError(t, err)
+
+	params := cl.GetDefaultParamSizes()
+	attrCount := cl.NewAttrCount(len(ipk.AttributeNames), 0, 0)
+	org, err := cl.NewOrg(params, attrCount)
+	require.NoError(t, err)
+	cm, err := cl.NewCredManager(params, org.Keys.Pub, org.Keys.Pub.GenerateUserMasterSecret(), rc)
+	require.NoError(t, err)
+
+	credReq, err := cm.GetCredRequest(org.GetCredIssueNonce())
+	require.NoError(t, err)
+	res, err := org.IssueCred(credReq)
+	require.NoError(t, err)
+	ok, err := cm.Verify(res.Cred, res.AProof)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// reveal OU and Role, as Fabric's Idemix MSP does
+	revealed := []string{IdemixAttrOU, IdemixAttrRole}
+	var indices []int
+	for _, name := range revealed {
+		i, err := rc.GetAttrInternalIndex(name)
+		require.NoError(t, err)
+		indices = append(indices, i)
+	}
+	nonce := org.GetProveCredNonce()
+	randCred, proof, err := cm.BuildProof(res.Cred, indices, []int{}, nonce)
+	require.NoError(t, err)
+	knownAttrs, _ := cm.FilterAttributes(indices, []int{})
+
+	// emmy -> Idemix
+	disclosure, values, err := RevealedToIdemix(len(ipk.AttributeNames), indices, knownAttrs)
+	require.NoError(t, err)
+	expectedDisclosure, err := IdemixDisclosure(ipk, revealed)
+	require.NoError(t, err)
+	assert.Equal(t, expectedDisclosure, disclosure)
+	for i, d := range disclosure {
+		if d == 1 {
+			assert.Equal(t, idemixCred.Attrs[i], values[i], ipk.AttributeNames[i])
+		}
+	}
+
+	// Idemix -> emmy: the values an Idemix verifier holds verify the emmy proof
+	idemixValues := make([][]byte, len(idemixCred.Attrs))
+	for i, d := range expectedDisclosure {
+		if d == 1 {
+			idemixValues[i] = idemixCred.Attrs[i]
+		}
+	}
+	indices, knownAttrs, err = RevealedFromIdemix(expectedDisclosure, idemixValues)
+	require.NoError(t, err)
+	verified, err := org.ProveCred(randCred.A, proof, indices, []int{}, knownAttrs, []*big.Int{})
+	require.NoError(t, err)
+	assert.True(t, verified, "proof should verify with Idemix attribute values")
+
+	// the proof does not verify against values of another user
+	otherOU := IdemixHashModOrder([]byte("org2.department1"))
+	verified, _ = org.ProveCred(randCred.A, proof, indices, []int{},
+		[]*big.Int{otherOU, knownAttrs[1]}, []*big.Int{})
+	assert.False(t, verified)
+}
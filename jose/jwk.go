@@ -0,0 +1,166 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package jose implements parts of the JSON Object Signing and Encryption
+// standards (JWK, JWT) needed to interoperate with web-based identity systems.
+package jose
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+)
+
+// JWK is a JSON Web Key (RFC 7517). Only elliptic curve keys on P-256 are supported.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	D   string `json:"d,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+}
+
+// NewJWK returns a JWK holding the public key pub.
+func NewJWK(pub *ecdsa.PublicKey) (*JWK, error) {
+	if pub.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("only P-256 keys are supported")
+	}
+
+	return &JWK{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   encodeCoordinate(pub.X),
+		Y:   encodeCoordinate(pub.Y),
+	}, nil
+}
+
+// ECDSAPublicKey returns the public key held in k.
+func (k *JWK) ECDSAPublicKey() (*ecdsa.PublicKey, error) {
+	if k.Kty != "EC" || k.Crv != "P-256" {
+		return nil, fmt.Errorf("unsupported key type %s (curve %s)", k.Kty, k.Crv)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x coordinate: %v", err)
+	}
+	y, err := base64.RawURLEncoding.DecodeString(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("invalid y coordinate: %v", err)
+	}
+
+	pub := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, fmt.Errorf("point is not on curve P-256")
+	}
+
+	return pub, nil
+}
+
+// NewPrivateJWK returns a JWK holding the private key key.
+func NewPrivateJWK(key *ecdsa.PrivateKey) (*JWK, error) {
+	jwk, err := NewJWK(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	jwk.D = encodeCoordinate(key.D)
+
+	return jwk, nil
+}
+
+// ECDSAPrivateKey returns the private key held in k.
+func (k *JWK) ECDSAPrivateKey() (*ecdsa.PrivateKey, error) {
+	if k.D == "" {
+		return nil, fmt.Errorf("JWK does not hold a private key")
+	}
+	pub, err := k.ECDSAPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	d, err := base64.RawURLEncoding.DecodeString(k.D)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+
+	key := &ecdsa.PrivateKey{
+		PublicKey: *pub,
+		D:         new(big.Int).SetBytes(d),
+	}
+	x, y := pub.Curve.ScalarBaseMult(d)
+	if x.Cmp(pub.X) != 0 || y.Cmp(pub.Y) != 0 {
+		return nil, fmt.Errorf("private key does not match public key")
+	}
+
+	return key, nil
+}
+
+// Public returns k without the private key.
+func (k *JWK) Public() *JWK {
+	pub := *k
+	pub.D = ""
+	return &pub
+}
+
+// Thumbprint returns the JWK thumbprint (RFC 7638) of k, which is suitable
+// as a key identifier.
+func (k *JWK) Thumbprint() string {
+	// members in lexicographic order, without whitespace
+	s := fmt.Sprintf(`{"crv":"%s","kty":"%s","x":"%s","y":"%s"}`, k.Crv, k.Kty, k.X, k.Y)
+	digest := sha256.Sum256([]byte(s))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// JWKSet is a set of JWKs, as published at JWKS endpoints.
+type JWKSet struct {
+	Keys []*JWK `json:"keys"`
+}
+
+// ServeHTTP publishes s in JSON form, making JWKSet usable as a JWKS endpoint.
+func (s *JWKSet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+// Key returns the key with the given key identifier, or nil if the set has no such key.
+func (s *JWKSet) Key(kid string) *JWK {
+	for _, k := range s.Keys {
+		if k.Kid == kid {
+			return k
+		}
+	}
+	return nil
+}
+
+// encodeCoordinate encodes a P-256 point coordinate or scalar, padded to 32 bytes.
+func encodeCoordinate(c *big.Int) string {
+	b := make([]byte, 32)
+	cBytes := c.Bytes()
+	copy(b[32-len(cBytes):], cBytes)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package jose
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// AlgES256 is the only supported JWS algorithm, ECDSA using P-256 and SHA-256.
+const AlgES256 = "ES256"
+
+// Header is the header of a JWT.
+type Header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// Sign returns a JWT holding claims, signed with key. When kid is not empty,
+// it is put in the header to identify the key.
+func Sign(claims interface{}, key *ecdsa.PrivateKey, kid string) (string, error) {
+	return SignWithType(claims, key, kid, "JWT")
+}
+
+// SignWithType is like Sign, but sets the typ header to typ, for JWTs of
+// specific applications.
+func SignWithType(claims interface{}, key *ecdsa.PrivateKey, kid, typ string) (string, error) {
+	if key.Curve != elliptic.P256() {
+		return "", fmt.Errorf("only P-256 keys are supported")
+	}
+
+	header, err := json.Marshal(&Header{
+		Alg: AlgES256,
+		Typ: typ,
+		Kid: kid,
+	})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := encodeSegment(header) + "." + encodeSegment(payload)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return "", err
+	}
+	// signature is a concatenation of r and s, each padded to 32 bytes
+	sig := make([]byte, 64)
+	rBytes, sBytes := r.Bytes(), s.Bytes()
+	copy(sig[32-len(rBytes):32], rBytes)
+	copy(sig[64-len(sBytes):], sBytes)
+
+	return signingInput + "." + encodeSegment(sig), nil
+}
+
+// Verify verifies the signature of token with key and decodes its claims into claims.
+// Numbers in claims decoded into interface{} values are represented as json.Number.
+func Verify(token string, key *ecdsa.PublicKey, claims interface{}) error {
+	parts, header, err := split(token)
+	if err != nil {
+		return err
+	}
+	if header.Alg != AlgES256 {
+		return fmt.Errorf("unsupported algorithm %s", header.Alg)
+	}
+
+	sig, err := decodeSegment(parts[2])
+	if err != nil {
+		return err
+	}
+	if len(sig) != 64 {
+		return fmt.Errorf("invalid signature length")
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !ecdsa.Verify(key, digest[:], r, s) {
+		return fmt.Errorf("invalid token signature")
+	}
+
+	return decodeClaims(parts[1], claims)
+}
+
+// Decode decodes the header and claims of token WITHOUT verifying its signature.
+// It is meant for finding out which key the token needs to be verified with.
+func Decode(token string, claims interface{}) (*Header, error) {
+	parts, header, err := split(token)
+	if err != nil {
+		return nil, err
+	}
+	if err := decodeClaims(parts[1], claims); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+// split splits token into its parts and decodes its header.
+func split(token string) ([]string, *Header, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, fmt.Errorf("malformed token")
+	}
+
+	headerBytes, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	header := new(Header)
+	if err := json.Unmarshal(headerBytes, header); err != nil {
+		return nil, nil, fmt.Errorf("malformed token header: %v", err)
+	}
+
+	return parts, header, nil
+}
+
+func decodeClaims(segment string, claims interface{}) error {
+	payload, err := decodeSegment(segment)
+	if err != nil {
+		return err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(payload))
+	d.UseNumber()
+	if err := d.Decode(claims); err != nil {
+		return fmt.Errorf("malformed token claims: %v", err)
+	}
+
+	return nil
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed token: %v", err)
+	}
+	return b, nil
+}
+
+// Signer signs JWTs with a private key, whose public key is published as a JWK
+// identified by its thumbprint.
+type Signer struct {
+	key *ecdsa.PrivateKey
+	jwk *JWK
+}
+
+// NewSigner returns a Signer signing with key.
+func NewSigner(key *ecdsa.PrivateKey) (*Signer, error) {
+	jwk, err := NewJWK(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	jwk.Kid = jwk.Thumbprint()
+	jwk.Use = "sig"
+	jwk.Alg = AlgES256
+
+	return &Signer{
+		key: key,
+		jwk: jwk,
+	}, nil
+}
+
+// Sign returns a JWT holding claims, with key identifier in its header.
+func (s *Signer) Sign(claims interface{}) (string, error) {
+	return Sign(claims, s.key, s.jwk.Kid)
+}
+
+// Verify verifies the signature of token, which was signed by s, and decodes its
+// claims into claims.
+func (s *Signer) Verify(token string, claims interface{}) error {
+	return Verify(token, &s.key.PublicKey, claims)
+}
+
+// JWKS returns the key set holding the public key of s.
+func (s *Signer) JWKS() *JWKSet {
+	return &JWKSet{
+		Keys: []*JWK{s.jwk},
+	}
+}
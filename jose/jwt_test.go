@@ -0,0 +1,71 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package jose
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s, err := NewSigner(key)
+	if err != nil {
+		t.Fatalf("error when creating signer: %v", err)
+	}
+
+	token, err := s.Sign(map[string]interface{}{"iss": "emmy", "exp": 1562643000})
+	if err != nil {
+		t.Fatalf("error when signing token: %v", err)
+	}
+
+	// verifier obtains the key from the JWKS endpoint
+	rec := httptest.NewRecorder()
+	s.JWKS().ServeHTTP(rec, httptest.NewRequest("GET", "/jwks", nil))
+	jwks := new(JWKSet)
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(jwks))
+
+	claims := make(map[string]interface{})
+	header, err := Decode(token, &claims)
+	assert.NoError(t, err)
+	jwk := jwks.Key(header.Kid)
+	if jwk == nil {
+		t.Fatalf("key %s is not published", header.Kid)
+	}
+	pub, err := jwk.ECDSAPublicKey()
+	assert.NoError(t, err)
+
+	claims = make(map[string]interface{})
+	assert.NoError(t, Verify(token, pub, &claims))
+	assert.Equal(t, "emmy", claims["iss"])
+	assert.Equal(t, json.Number("1562643000"), claims["exp"])
+
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Error(t, Verify(token, &other.PublicKey, &claims))
+
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "." + encodeSegment([]byte(`{"iss":"evil"}`)) + "." + parts[2]
+	assert.Error(t, Verify(tampered, pub, &claims))
+}
@@ -0,0 +1,326 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package keys serializes keys used by emmy to formats understood by existing PKI
+// tooling. ECDSA keys (such as the keys of the pseudonym system CA) are encoded in
+// PKCS#8 (private keys) or PKIX (public keys) PEM, or as JWKs. Keys of schemes that
+// have no standard representation are encoded as DER sequences of their components
+// in PEM blocks of emmy specific types, and, where keys are elliptic curve points,
+// also as JWKs.
+//
+// Decode functions detect the format of data, so that a key can be provided in
+// either of the supported formats.
+package keys
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	"github.com/xlab-si/emmy/jose"
+)
+
+// Format is a format of serialized keys.
+type Format string
+
+const (
+	FormatPEM Format = "pem"
+	FormatJWK Format = "jwk"
+)
+
+// PEM block types of keys without a standard representation.
+const (
+	PEMTypePseudonymsysSecKey   = "EMMY PSEUDONYMSYS SECRET KEY"
+	PEMTypePseudonymsysPubKey   = "EMMY PSEUDONYMSYS PUBLIC KEY"
+	PEMTypeECPseudonymsysPubKey = "EMMY EC PSEUDONYMSYS PUBLIC KEY"
+)
+
+// Key identifiers of the two points of an EC pseudonym system public key in JWK form.
+const (
+	kidH1 = "h1"
+	kidH2 = "h2"
+)
+
+// dlogPair is the DER representation of pseudonym system keys, which
+// consist of two integers.
+type dlogPair struct {
+	A, B *big.Int
+}
+
+// ecPair is the DER representation of EC pseudonym system public keys, which
+// consist of two points in uncompressed form.
+type ecPair struct {
+	H1, H2 []byte
+}
+
+// EncodeECDSAPrivateKey encodes key in the given format.
+func EncodeECDSAPrivateKey(key *ecdsa.PrivateKey, f Format) ([]byte, error) {
+	switch f {
+	case FormatPEM:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	case FormatJWK:
+		jwk, err := jose.NewPrivateJWK(key)
+		if err != nil {
+			return nil, err
+		}
+		return json.MarshalIndent(jwk, "", "  ")
+	}
+
+	return nil, fmt.Errorf("unsupported key format %s", f)
+}
+
+// DecodeECDSAPrivateKey decodes an ECDSA private key in PKCS#8 or SEC 1 PEM,
+// or JWK form.
+func DecodeECDSAPrivateKey(data []byte) (*ecdsa.PrivateKey, error) {
+	if isJSON(data) {
+		jwk := new(jose.JWK)
+		if err := json.Unmarshal(data, jwk); err != nil {
+			return nil, err
+		}
+		return jwk.ECDSAPrivateKey()
+	}
+
+	block, err := decodePEM(data)
+	if err != nil {
+		return nil, err
+	}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		ecKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("not an EC private key")
+		}
+		return ecKey, nil
+	}
+
+	return nil, fmt.Errorf("unexpected PEM block type %s", block.Type)
+}
+
+// EncodeECDSAPublicKey encodes pub in the given format.
+func EncodeECDSAPublicKey(pub *ecdsa.PublicKey, f Format) ([]byte, error) {
+	switch f {
+	case FormatPEM:
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+	case FormatJWK:
+		jwk, err := jose.NewJWK(pub)
+		if err != nil {
+			return nil, err
+		}
+		return json.MarshalIndent(jwk, "", "  ")
+	}
+
+	return nil, fmt.Errorf("unsupported key format %s", f)
+}
+
+// DecodeECDSAPublicKey decodes an ECDSA public key in PKIX PEM or JWK form.
+func DecodeECDSAPublicKey(data []byte) (*ecdsa.PublicKey, error) {
+	if isJSON(data) {
+		jwk := new(jose.JWK)
+		if err := json.Unmarshal(data, jwk); err != nil {
+			return nil, err
+		}
+		return jwk.ECDSAPublicKey()
+	}
+
+	block, err := decodePEM(data, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	ecPub, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an EC public key")
+	}
+
+	return ecPub, nil
+}
+
+// EncodePseudonymsysSecKey encodes the secret key of an organization in the
+// pseudonym system (either in modular or EC arithmetic) in PEM form.
+func EncodePseudonymsysSecKey(sk *pseudsys.SecKey) ([]byte, error) {
+	return encodeDER(PEMTypePseudonymsysSecKey, dlogPair{sk.S1, sk.S2})
+}
+
+// DecodePseudonymsysSecKey decodes the secret key of an organization in the
+// pseudonym system from PEM form.
+func DecodePseudonymsysSecKey(data []byte) (*pseudsys.SecKey, error) {
+	p := new(dlogPair)
+	if err := decodeDER(data, PEMTypePseudonymsysSecKey, p); err != nil {
+		return nil, err
+	}
+	return pseudsys.NewSecKey(p.A, p.B), nil
+}
+
+// EncodePseudonymsysPubKey encodes the public key of an organization in the
+// pseudonym system in PEM form.
+func EncodePseudonymsysPubKey(pk *pseudsys.PubKey) ([]byte, error) {
+	return encodeDER(PEMTypePseudonymsysPubKey, dlogPair{pk.H1, pk.H2})
+}
+
+// DecodePseudonymsysPubKey decodes the public key of an organization in the
+// pseudonym system from PEM form.
+func DecodePseudonymsysPubKey(data []byte) (*pseudsys.PubKey, error) {
+	p := new(dlogPair)
+	if err := decodeDER(data, PEMTypePseudonymsysPubKey, p); err != nil {
+		return nil, err
+	}
+	return pseudsys.NewPubKey(p.A, p.B), nil
+}
+
+// EncodeECPseudonymsysPubKey encodes the public key of an organization in the
+// pseudonym system in EC arithmetic (on P-256) in the given format. In JWK form,
+// the key is a JWK set holding both points, identified by key IDs h1 and h2.
+func EncodeECPseudonymsysPubKey(pk *ecpseudsys.PubKey, f Format) ([]byte, error) {
+	curve := elliptic.P256()
+	for _, h := range []*ec.GroupElement{pk.H1, pk.H2} {
+		if !curve.IsOnCurve(h.X, h.Y) {
+			return nil, fmt.Errorf("point is not on curve P-256")
+		}
+	}
+
+	switch f {
+	case FormatPEM:
+		return encodeDER(PEMTypeECPseudonymsysPubKey, ecPair{
+			H1: elliptic.Marshal(curve, pk.H1.X, pk.H1.Y),
+			H2: elliptic.Marshal(curve, pk.H2.X, pk.H2.Y),
+		})
+	case FormatJWK:
+		set := new(jose.JWKSet)
+		for i, h := range []*ec.GroupElement{pk.H1, pk.H2} {
+			jwk, err := jose.NewJWK(&ecdsa.PublicKey{Curve: curve, X: h.X, Y: h.Y})
+			if err != nil {
+				return nil, err
+			}
+			jwk.Kid = []string{kidH1, kidH2}[i]
+			set.Keys = append(set.Keys, jwk)
+		}
+		return json.MarshalIndent(set, "", "  ")
+	}
+
+	return nil, fmt.Errorf("unsupported key format %s", f)
+}
+
+// DecodeECPseudonymsysPubKey decodes the public key of an organization in the
+// pseudonym system in EC arithmetic from PEM or JWK set form.
+func DecodeECPseudonymsysPubKey(data []byte) (*ecpseudsys.PubKey, error) {
+	var h [2]*ec.GroupElement
+	if isJSON(data) {
+		set := new(jose.JWKSet)
+		if err := json.Unmarshal(data, set); err != nil {
+			return nil, err
+		}
+		for i, kid := range []string{kidH1, kidH2} {
+			jwk := set.Key(kid)
+			if jwk == nil {
+				return nil, fmt.Errorf("JWK set has no key %s", kid)
+			}
+			pub, err := jwk.ECDSAPublicKey()
+			if err != nil {
+				return nil, err
+			}
+			h[i] = ec.NewGroupElement(pub.X, pub.Y)
+		}
+	} else {
+		p := new(ecPair)
+		if err := decodeDER(data, PEMTypeECPseudonymsysPubKey, p); err != nil {
+			return nil, err
+		}
+		for i, b := range [][]byte{p.H1, p.H2} {
+			x, y := elliptic.Unmarshal(elliptic.P256(), b)
+			if x == nil {
+				return nil, fmt.Errorf("invalid point")
+			}
+			h[i] = ec.NewGroupElement(x, y)
+		}
+	}
+
+	return ecpseudsys.NewPubKey(h[0], h[1]), nil
+}
+
+func encodeDER(blockType string, v interface{}) ([]byte, error) {
+	der, err := asn1.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), nil
+}
+
+func decodeDER(data []byte, blockType string, v interface{}) error {
+	block, err := decodePEM(data, blockType)
+	if err != nil {
+		return err
+	}
+	rest, err := asn1.Unmarshal(block.Bytes, v)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("trailing data after %s", blockType)
+	}
+	return nil
+}
+
+// decodePEM decodes the first PEM block in data, which must be of one of
+// the given types (if any).
+func decodePEM(data []byte, types ...string) (*pem.Block, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	if len(types) == 0 {
+		return block, nil
+	}
+	for _, t := range types {
+		if block.Type == t {
+			return block, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected PEM block type %s", block.Type)
+}
+
+// isJSON reports whether data holds a JSON object.
+func isJSON(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+)
+
+func TestECDSAKeys(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	for _, f := range []Format{FormatPEM, FormatJWK} {
+		sk, err := EncodeECDSAPrivateKey(key, f)
+		assert.NoError(t, err)
+		decodedKey, err := DecodeECDSAPrivateKey(sk)
+		assert.NoError(t, err)
+		assert.Equal(t, key.D, decodedKey.D, "format %s", f)
+
+		pk, err := EncodeECDSAPublicKey(&key.PublicKey, f)
+		assert.NoError(t, err)
+		decodedPub, err := DecodeECDSAPublicKey(pk)
+		assert.NoError(t, err)
+		assert.Equal(t, key.X, decodedPub.X, "format %s", f)
+		assert.Equal(t, key.Y, decodedPub.Y, "format %s", f)
+	}
+
+	_, err := DecodeECDSAPrivateKey([]byte("not a key"))
+	assert.Error(t, err)
+}
+
+func TestPseudonymsysKeys(t *testing.T) {
+	group, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Fatalf("error when generating group: %v", err)
+	}
+	sk, pk := pseudsys.GenerateKeyPair(group)
+
+	data, err := EncodePseudonymsysSecKey(sk)
+	assert.NoError(t, err)
+	decodedSk, err := DecodePseudonymsysSecKey(data)
+	assert.NoError(t, err)
+	assert.Equal(t, sk, decodedSk)
+
+	// secret key must not be accepted as a public key
+	_, err = DecodePseudonymsysPubKey(data)
+	assert.Error(t, err)
+
+	data, err = EncodePseudonymsysPubKey(pk)
+	assert.NoError(t, err)
+	decodedPk, err := DecodePseudonymsysPubKey(data)
+	assert.NoError(t, err)
+	assert.Equal(t, pk, decodedPk)
+}
+
+func TestECPseudonymsysPubKey(t *testing.T) {
+	_, pk := ecpseudsys.GenerateKeyPair(ec.NewGroup(ec.P256))
+
+	for _, f := range []Format{FormatPEM, FormatJWK} {
+		data, err := EncodeECPseudonymsysPubKey(pk, f)
+		assert.NoError(t, err)
+		decoded, err := DecodeECPseudonymsysPubKey(data)
+		assert.NoError(t, err)
+		assert.Equal(t, pk, decoded, "format %s", f)
+	}
+}
@@ -0,0 +1,86 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Sampling limits the output of repeated records. Within each interval Tick, the
+// first Initial records with the same level and message are output, and after
+// that only every Thereafter-th one. Records at level ERROR and above are never
+// dropped.
+type Sampling struct {
+	Tick       time.Duration
+	Initial    int
+	Thereafter int
+}
+
+// sampler counts records with the same level and message in the current tick.
+type sampler struct {
+	*Sampling
+	sync.Mutex
+	start  time.Time
+	counts map[sampleKey]int
+}
+
+type sampleKey struct {
+	level slog.Level
+	msg   string
+}
+
+// sample reports whether record r should be output.
+func (s *sampler) sample(r slog.Record) bool {
+	if r.Level >= levelError {
+		return true
+	}
+	s.Lock()
+	defer s.Unlock()
+	if r.Time.Sub(s.start) >= s.Tick {
+		s.start = r.Time
+		s.counts = make(map[sampleKey]int)
+	}
+	k := sampleKey{r.Level, r.Message}
+	s.counts[k]++
+	n := s.counts[k]
+	if n <= s.Initial {
+		return true
+	}
+	return s.Thereafter > 0 && (n-s.Initial)%s.Thereafter == 0
+}
+
+// samplingHandler is a slog.Handler that drops records not chosen by its sampler.
+type samplingHandler struct {
+	slog.Handler
+	s *sampler
+}
+
+func newSamplingHandler(h slog.Handler, s *Sampling) *samplingHandler {
+	conf := *s
+	if conf.Tick <= 0 {
+		conf.Tick = time.Second
+	}
+	return &samplingHandler{
+		Handler: h,
+		s: &sampler{
+			Sampling: &conf,
+			counts:   make(map[sampleKey]int),
+		},
+	}
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.s.sample(r) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{h.Handler.WithAttrs(attrs), h.s}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{h.Handler.WithGroup(name), h.s}
+}
@@ -0,0 +1,110 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// multiSink writes each record to all of its sinks, so that a failing sink does
+// not prevent output to the others.
+type multiSink struct {
+	sinks []io.Writer
+}
+
+func (m *multiSink) Write(p []byte) (int, error) {
+	var err error
+	for _, s := range m.sinks {
+		if _, e := s.Write(p); e != nil && err == nil {
+			err = e
+		}
+	}
+	return len(p), err
+}
+
+// RotatingFile is a sink that writes to a file and rotates it once it grows over
+// MaxSize bytes. Rotated files are named after the file with suffixes .1 (the most
+// recent) to .MaxBackups, older ones are removed.
+type RotatingFile struct {
+	Path       string
+	MaxSize    int64
+	MaxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewRotatingFile opens or creates the file at path for appending. A maxSize
+// of 0 disables rotation.
+func NewRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{
+		Path:       path,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.MaxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate shifts backups of the file by one, removing the oldest, renames the file
+// to the first backup and reopens it.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	if f.MaxBackups > 0 {
+		os.Remove(backupName(f.Path, f.MaxBackups))
+		for i := f.MaxBackups - 1; i > 0; i-- {
+			os.Rename(backupName(f.Path, i), backupName(f.Path, i+1))
+		}
+		if err := os.Rename(f.Path, backupName(f.Path, 1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(f.Path); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+// Close closes the underlying file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+func backupName(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
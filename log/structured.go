@@ -0,0 +1,248 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// Output formats of StructuredLogger.
+const (
+	FORMAT_TEXT = "text"
+	FORMAT_JSON = "json"
+)
+
+// Levels of slog records corresponding to levels of emmy loggers.
+const (
+	levelDebug    = slog.LevelDebug
+	levelInfo     = slog.LevelInfo
+	levelNotice   = slog.LevelInfo + 2
+	levelWarning  = slog.LevelWarn
+	levelError    = slog.LevelError
+	levelCritical = slog.LevelError + 4
+)
+
+var levelNames = map[slog.Level]string{
+	levelDebug:    DEBUG,
+	levelInfo:     INFO,
+	levelNotice:   NOTICE,
+	levelWarning:  WARNING,
+	levelError:    ERROR,
+	levelCritical: CRITICAL,
+}
+
+// parseLevel returns the slog level corresponding to one of emmy log levels,
+// regardless of case.
+func parseLevel(levelStr string) (slog.Level, error) {
+	for l, name := range levelNames {
+		if name == strings.ToUpper(levelStr) {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid log level: %s", levelStr)
+}
+
+// Options configure a StructuredLogger.
+type Options struct {
+	// Format is either FORMAT_TEXT (logfmt-like key=value pairs) or FORMAT_JSON.
+	Format string
+	// Level is the log level of the logger and of components without their own level.
+	Level string
+	// Levels maps names of components to their log levels, see StructuredLogger.Component.
+	Levels map[string]string
+	// Sampling, when set, limits the number of repeated messages, see Sampling.
+	Sampling *Sampling
+	// Sinks receive formatted records. Standard error is used when no sinks are given.
+	Sinks []io.Writer
+}
+
+// StructuredLogger outputs records with key-value fields, formatted as text or JSON,
+// to one or more sinks. Loggers of components, derived with Component, share sinks
+// and sampling with their parent but have their own log level.
+type StructuredLogger struct {
+	handler slog.Handler
+	level   *slog.LevelVar
+	levels  map[string]string
+}
+
+var _ Logger = (*StructuredLogger)(nil)
+
+// NewStructuredLogger creates a StructuredLogger with the given options.
+func NewStructuredLogger(opts *Options) (*StructuredLogger, error) {
+	level, err := parseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	for c, l := range opts.Levels {
+		if _, err := parseLevel(l); err != nil {
+			return nil, fmt.Errorf("component %s: %v", c, err)
+		}
+	}
+
+	var w io.Writer = os.Stderr
+	if len(opts.Sinks) > 0 {
+		w = &multiSink{opts.Sinks}
+	}
+	// levels are checked by StructuredLogger, so the handler accepts all records
+	hOpts := &slog.HandlerOptions{
+		Level:       slog.Level(-1 << 10),
+		ReplaceAttr: replaceLevel,
+	}
+
+	var h slog.Handler
+	switch opts.Format {
+	case FORMAT_TEXT, "":
+		h = slog.NewTextHandler(w, hOpts)
+	case FORMAT_JSON:
+		h = slog.NewJSONHandler(w, hOpts)
+	default:
+		return nil, fmt.Errorf("invalid log format: %s", opts.Format)
+	}
+	if opts.Sampling != nil {
+		h = newSamplingHandler(h, opts.Sampling)
+	}
+
+	lv := new(slog.LevelVar)
+	lv.Set(level)
+	return &StructuredLogger{
+		handler: h,
+		level:   lv,
+		levels:  opts.Levels,
+	}, nil
+}
+
+// replaceLevel renders levels of records with names of emmy log levels.
+func replaceLevel(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if name, ok := levelNames[a.Value.Any().(slog.Level)]; ok {
+			a.Value = slog.StringValue(name)
+		}
+	}
+	return a
+}
+
+// SetLevel sets the log level of the logger. Loggers of components without their
+// own configured level are not affected.
+func (l *StructuredLogger) SetLevel(levelStr string) error {
+	level, err := parseLevel(levelStr)
+	if err != nil {
+		return err
+	}
+	l.level.Set(level)
+	return nil
+}
+
+// With returns a logger that adds the given key-value pairs to all of its records.
+func (l *StructuredLogger) With(keysAndValues ...interface{}) Logger {
+	return &StructuredLogger{
+		handler: slog.New(l.handler).With(keysAndValues...).Handler(),
+		level:   l.level,
+		levels:  l.levels,
+	}
+}
+
+// Component returns a logger for the named component of emmy. Its records carry
+// field component, and are output at the level configured for the component in
+// Options.Levels, or at the current level of l.
+func (l *StructuredLogger) Component(name string) Logger {
+	lv := new(slog.LevelVar)
+	lv.Set(l.level.Level())
+	if levelStr, ok := l.levels[name]; ok {
+		level, _ := parseLevel(levelStr) // validated in NewStructuredLogger
+		lv.Set(level)
+	}
+	return &StructuredLogger{
+		handler: l.handler.WithAttrs([]slog.Attr{slog.String("component", name)}),
+		level:   lv,
+		levels:  l.levels,
+	}
+}
+
+func (l *StructuredLogger) log(level slog.Level, msg string) {
+	if level < l.level.Level() {
+		return
+	}
+	ctx := context.Background()
+	if !l.handler.Enabled(ctx, level) {
+		return
+	}
+	l.handler.Handle(ctx, slog.NewRecord(time.Now(), level, msg, 0))
+}
+
+func (l *StructuredLogger) Debug(args ...interface{}) {
+	l.log(levelDebug, fmt.Sprint(args...))
+}
+
+func (l *StructuredLogger) Debugf(format string, args ...interface{}) {
+	l.log(levelDebug, fmt.Sprintf(format, args...))
+}
+
+func (l *StructuredLogger) Info(args ...interface{}) {
+	l.log(levelInfo, fmt.Sprint(args...))
+}
+
+func (l *StructuredLogger) Infof(format string, args ...interface{}) {
+	l.log(levelInfo, fmt.Sprintf(format, args...))
+}
+
+func (l *StructuredLogger) Notice(args ...interface{}) {
+	l.log(levelNotice, fmt.Sprint(args...))
+}
+
+func (l *StructuredLogger) Noticef(format string, args ...interface{}) {
+	l.log(levelNotice, fmt.Sprintf(format, args...))
+}
+
+func (l *StructuredLogger) Warning(args ...interface{}) {
+	l.log(levelWarning, fmt.Sprint(args...))
+}
+
+func (l *StructuredLogger) Warningf(format string, args ...interface{}) {
+	l.log(levelWarning, fmt.Sprintf(format, args...))
+}
+
+func (l *StructuredLogger) Error(args ...interface{}) {
+	l.log(levelError, fmt.Sprint(args...))
+}
+
+func (l *StructuredLogger) Errorf(format string, args ...interface{}) {
+	l.log(levelError, fmt.Sprintf(format, args...))
+}
+
+func (l *StructuredLogger) Critical(args ...interface{}) {
+	l.log(levelCritical, fmt.Sprint(args...))
+}
+
+func (l *StructuredLogger) Criticalf(format string, args ...interface{}) {
+	l.log(levelCritical, fmt.Sprintf(format, args...))
+}
+
+// fieldLogger is implemented by loggers that support key-value fields and
+// per-component levels, such as StructuredLogger.
+type fieldLogger interface {
+	With(keysAndValues ...interface{}) Logger
+	Component(name string) Logger
+}
+
+// With returns a logger that adds the given key-value pairs to records of l. Loggers
+// without support for fields, such as StdoutLogger, are returned unchanged.
+func With(l Logger, keysAndValues ...interface{}) Logger {
+	if fl, ok := l.(fieldLogger); ok {
+		return fl.With(keysAndValues...)
+	}
+	return l
+}
+
+// Component returns a logger for the named component derived from l, see
+// StructuredLogger.Component. Loggers without support for components are
+// returned unchanged.
+func Component(l Logger, name string) Logger {
+	if fl, ok := l.(fieldLogger); ok {
+		return fl.Component(name)
+	}
+	return l
+}
@@ -0,0 +1,144 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// records decodes records written by a StructuredLogger in JSON format.
+func records(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var recs []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]interface{}
+		assert.Nil(t, json.Unmarshal([]byte(line), &rec), "record should be valid JSON")
+		recs = append(recs, rec)
+	}
+	return recs
+}
+
+func TestStructuredLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewStructuredLogger(&Options{
+		Format: FORMAT_JSON,
+		Level:  NOTICE,
+		Levels: map[string]string{"cl": DEBUG},
+		Sinks:  []io.Writer{&buf},
+	})
+	assert.Nil(t, err)
+
+	logger.Info("not logged")
+	logger.Noticef("listening on port %d", 7007)
+	With(logger, "type", "Message_Empty").Warning("dropped")
+	cl := Component(logger, "cl")
+	cl.Debug("attribute received")
+	Component(logger, "server").Info("not logged")
+
+	recs := records(t, &buf)
+	assert.Len(t, recs, 3)
+	assert.Equal(t, NOTICE, recs[0]["level"])
+	assert.Equal(t, "listening on port 7007", recs[0]["msg"])
+	assert.Equal(t, WARNING, recs[1]["level"])
+	assert.Equal(t, "Message_Empty", recs[1]["type"])
+	assert.Equal(t, DEBUG, recs[2]["level"])
+	assert.Equal(t, "cl", recs[2]["component"])
+
+	buf.Reset()
+	assert.Nil(t, logger.SetLevel(INFO))
+	logger.Info("logged")
+	cl.Debug("still logged")
+	assert.Len(t, records(t, &buf), 2)
+	assert.NotNil(t, logger.SetLevel("Invalid log level"))
+}
+
+func TestStructuredLoggerText(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewStructuredLogger(&Options{
+		Level: INFO,
+		Sinks: []io.Writer{&buf},
+	})
+	assert.Nil(t, err)
+	With(logger, "port", 7007).Critical("cannot listen")
+	assert.Contains(t, buf.String(), `level=CRITICAL msg="cannot listen" port=7007`)
+}
+
+func TestStructuredLoggerInvalidOptions(t *testing.T) {
+	_, err := NewStructuredLogger(&Options{Level: "Invalid log level"})
+	assert.NotNil(t, err, "should produce an error due to invalid log level")
+	_, err = NewStructuredLogger(&Options{Level: INFO, Format: "Invalid format"})
+	assert.NotNil(t, err, "should produce an error due to invalid log format")
+	_, err = NewStructuredLogger(&Options{Level: INFO,
+		Levels: map[string]string{"server": "Invalid log level"}})
+	assert.NotNil(t, err, "should produce an error due to invalid component log level")
+}
+
+func TestUnstructuredLoggerFields(t *testing.T) {
+	logger := NewNullLogger()
+	assert.Equal(t, logger, With(logger, "key", "value"))
+	assert.Equal(t, logger, Component(logger, "server"))
+}
+
+func TestSampling(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewStructuredLogger(&Options{
+		Format: FORMAT_JSON,
+		Level:  INFO,
+		Sampling: &Sampling{
+			Tick:       time.Hour,
+			Initial:    2,
+			Thereafter: 3,
+		},
+		Sinks: []io.Writer{&buf},
+	})
+	assert.Nil(t, err)
+
+	for i := 0; i < 10; i++ {
+		logger.Info("received request")
+		logger.Error("cannot process request")
+	}
+	logger.Info("other message")
+
+	counts := make(map[string]int)
+	for _, rec := range records(t, &buf) {
+		counts[rec["msg"].(string)]++
+	}
+	// records 1, 2, 5 and 8
+	assert.Equal(t, 4, counts["received request"])
+	assert.Equal(t, 10, counts["cannot process request"])
+	assert.Equal(t, 1, counts["other message"])
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "emmy.log")
+	f, err := NewRotatingFile(path, 10, 2)
+	assert.Nil(t, err)
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := f.Write([]byte(line))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, f.Close())
+
+	for name, content := range map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	} {
+		data, err := os.ReadFile(name)
+		assert.Nil(t, err)
+		assert.Equal(t, content, string(data))
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err), "only two backups should be kept")
+}
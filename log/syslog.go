@@ -0,0 +1,16 @@
+//go:build !windows && !plan9 && !js
+// +build !windows,!plan9,!js
+
+package log
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// NewSyslogSink returns a sink that sends records to the syslog daemon at address
+// addr using network (for example "udp" or "tcp"), or to the local daemon if
+// network is empty. Records are sent with facility daemon and the given tag.
+func NewSyslogSink(network, addr, tag string) (io.Writer, error) {
+	return syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+}
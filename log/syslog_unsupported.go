@@ -0,0 +1,15 @@
+//go:build windows || plan9 || js
+// +build windows plan9 js
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// NewSyslogSink is not supported on this platform and always returns an error.
+func NewSyslogSink(network, addr, tag string) (io.Writer, error) {
+	return nil, fmt.Errorf("syslog is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package mdl
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/df"
+)
+
+// AgeKind tells how an attribute holds the age of the holder.
+type AgeKind int
+
+const (
+	// AgeInYears is an int64 attribute holding the age in years.
+	AgeInYears AgeKind = iota
+	// BirthDate is an int64 attribute holding the birth date as unix time.
+	BirthDate
+)
+
+// maxAsOfSkew is how far the time an age_over_NN proof was built at can be from
+// the time it is verified at.
+const maxAsOfSkew = 24 * time.Hour
+
+var (
+	minInt64 = big.NewInt(-1 << 63)
+	maxInt64 = big.NewInt(1<<63 - 1)
+)
+
+var ageOverRegexp = regexp.MustCompile(`^age_over_(\d{2})$`)
+
+// AgeOverIdentifier returns the identifier of data element age_over_NN.
+func AgeOverIdentifier(nn int) string {
+	return fmt.Sprintf("age_over_%02d", nn)
+}
+
+// ParseAgeOver returns NN of data element identifier age_over_NN.
+func ParseAgeOver(identifier string) (int, error) {
+	m := ageOverRegexp.FindStringSubmatch(identifier)
+	if m == nil {
+		return 0, fmt.Errorf("%s is not an age_over_NN data element", identifier)
+	}
+
+	return strconv.Atoi(m[1])
+}
+
+// AgeOverProof proves the value of data element age_over_NN, that is whether the
+// holder is at least NN years old, without revealing the age.
+type AgeOverProof struct {
+	NN    int
+	Over  bool
+	AsOf  int64 // unix time the proof was built at
+	Proof *df.RangeProofNI
+}
+
+// SetAgeSource sets the committed attribute attr, holding the age of the holder
+// as given by kind, as the source of age_over_NN data elements.
+func (m *Mapping) SetAgeSource(attr string, kind AgeKind) {
+	m.ageAttr = attr
+	m.ageKind = kind
+}
+
+// ageRange returns the range the source attribute lies in when age_over_NN is over
+// at time asOf.
+func (m *Mapping) ageRange(nn int, over bool, asOf time.Time) (*big.Int, *big.Int,
+	error) {
+	if nn < 0 || nn > 99 {
+		return nil, nil, fmt.Errorf("NN needs to have two digits")
+	}
+
+	switch m.ageKind {
+	case AgeInYears:
+		if over {
+			return big.NewInt(int64(nn)), maxInt64, nil
+		}
+		return minInt64, big.NewInt(int64(nn) - 1), nil
+	case BirthDate:
+		// born on or before the cutoff is at least NN years old
+		cutoff := asOf.UTC().AddDate(-nn, 0, 0).Unix()
+		if over {
+			return minInt64, big.NewInt(cutoff), nil
+		}
+		return big.NewInt(cutoff + 1), maxInt64, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown age kind %d", m.ageKind)
+}
+
+// ProveAgeOver returns a proof of age_over_NN for the credential managed by cm.
+// The commitment of the age source attribute needs to be revealed to the verifier
+// along with the proof. The nonce is obtained from the verifier.
+func (m *Mapping) ProveAgeOver(cm *cl.CredManager, nn int, nonce *big.Int) (*AgeOverProof,
+	error) {
+	if m.ageAttr == "" {
+		return nil, fmt.Errorf("no age source attribute")
+	}
+	a, err := cm.RawCred.GetAttr(m.ageAttr)
+	if err != nil {
+		return nil, err
+	}
+	age, ok := a.GetValue().(int64)
+	if !ok {
+		return nil, fmt.Errorf("age source attribute %s is not an int64 attribute", m.ageAttr)
+	}
+	i, err := committedIndex(cm.RawCred, m.ageAttr)
+	if err != nil {
+		return nil, err
+	}
+
+	asOf := time.Now()
+	a1, b1, err := m.ageRange(nn, true, asOf)
+	if err != nil {
+		return nil, err
+	}
+	over := age >= a1.Int64() && age <= b1.Int64()
+	lower, upper, err := m.ageRange(nn, over, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := cm.BuildRangeProof(i, lower, upper, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AgeOverProof{
+		NN:    nn,
+		Over:  over,
+		AsOf:  asOf.Unix(),
+		Proof: proof,
+	}, nil
+}
+
+// VerifyAgeOver verifies proof p of age_over_NN, given the commitment of the age
+// source attribute. It returns the identifier and the value of the data element.
+func (m *Mapping) VerifyAgeOver(org *cl.Org, commitment *big.Int, p *AgeOverProof,
+	nonce *big.Int) (string, bool, error) {
+	asOf := time.Unix(p.AsOf, 0)
+	if m.ageKind == BirthDate {
+		if d := time.Since(asOf); d > maxAsOfSkew || d < -maxAsOfSkew {
+			return "", false, fmt.Errorf("proof was not built at the current time")
+		}
+	}
+	lower, upper, err := m.ageRange(p.NN, p.Over, asOf)
+	if err != nil {
+		return "", false, err
+	}
+
+	ok, err := org.VerifyRangeProof(commitment, p.Proof, lower, upper, nonce)
+	if err != nil {
+		return "", false, err
+	}
+	if !ok {
+		return "", false, fmt.Errorf("proof of %s is not valid", AgeOverIdentifier(p.NN))
+	}
+
+	return AgeOverIdentifier(p.NN), p.Over, nil
+}
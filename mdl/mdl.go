@@ -0,0 +1,294 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package mdl maps attributes of emmy's CL credentials to data elements of the
+// ISO/IEC 18013-5 mobile driving licence (mDL), so that credentials can be used
+// by identity wallets and readers built around mDL namespaces.
+//
+// Besides attributes mapped one-to-one, the mapping can derive age_over_NN elements
+// from an attribute holding the age or the birth date of the holder. The attribute
+// is committed rather than revealed, and the holder proves age_over_NN with a range
+// proof on its commitment.
+package mdl
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+const (
+	// Namespace is the namespace of mDL data elements.
+	Namespace = "org.iso.18013.5.1"
+	// DocType is the document type of mDL.
+	DocType = "org.iso.18013.5.1.mDL"
+)
+
+// ElementType is the CBOR type of a data element value.
+type ElementType string
+
+// Types of data element values.
+const (
+	TypeTstr     ElementType = "tstr"
+	TypeUint     ElementType = "uint"
+	TypeFullDate ElementType = "full-date"
+	TypeBool     ElementType = "bool"
+)
+
+// Elements lists data elements of the mDL namespace that can be mapped to string
+// or int64 attributes, along with the types of their values.
+var Elements = map[string]ElementType{
+	"family_name":                    TypeTstr,
+	"given_name":                     TypeTstr,
+	"birth_date":                     TypeFullDate,
+	"issue_date":                     TypeFullDate,
+	"expiry_date":                    TypeFullDate,
+	"issuing_country":                TypeTstr,
+	"issuing_authority":              TypeTstr,
+	"document_number":                TypeTstr,
+	"administrative_number":          TypeTstr,
+	"un_distinguishing_sign":         TypeTstr,
+	"sex":                            TypeUint,
+	"height":                         TypeUint,
+	"weight":                         TypeUint,
+	"eye_colour":                     TypeTstr,
+	"hair_colour":                    TypeTstr,
+	"birth_place":                    TypeTstr,
+	"resident_address":               TypeTstr,
+	"resident_city":                  TypeTstr,
+	"resident_state":                 TypeTstr,
+	"resident_postal_code":           TypeTstr,
+	"resident_country":               TypeTstr,
+	"portrait_capture_date":          TypeFullDate,
+	"age_in_years":                   TypeUint,
+	"age_birth_year":                 TypeUint,
+	"issuing_jurisdiction":           TypeTstr,
+	"nationality":                    TypeTstr,
+	"family_name_national_character": TypeTstr,
+	"given_name_national_character":  TypeTstr,
+}
+
+// fullDate is the format of full-date values.
+const fullDate = "2006-01-02"
+
+var fullDateRegexp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// Element identifies a data element.
+type Element struct {
+	Namespace  string
+	Identifier string
+	Type       ElementType
+}
+
+// Mapping maps attributes of CL credentials to data elements.
+type Mapping struct {
+	elements map[string]Element // by attribute name
+	ageAttr  string
+	ageKind  AgeKind
+}
+
+// NewMapping returns a mapping of attributes to data elements of the mDL namespace,
+// given by a map of attribute names to element identifiers.
+func NewMapping(identifiers map[string]string) (*Mapping, error) {
+	m := &Mapping{
+		elements: make(map[string]Element),
+	}
+	for attr, id := range identifiers {
+		t, ok := Elements[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown mDL data element %s", id)
+		}
+		m.Add(attr, Element{
+			Namespace:  Namespace,
+			Identifier: id,
+			Type:       t,
+		})
+	}
+
+	return m, nil
+}
+
+// Add maps attribute attr to data element e, which can belong to any namespace.
+func (m *Mapping) Add(attr string, e Element) {
+	m.elements[attr] = e
+}
+
+// Element returns the data element attribute attr is mapped to.
+func (m *Mapping) Element(attr string) (Element, bool) {
+	e, ok := m.elements[attr]
+	return e, ok
+}
+
+// Attribute returns the name of the attribute mapped to data element identifier
+// in namespace.
+func (m *Mapping) Attribute(namespace, identifier string) (string, bool) {
+	for attr, e := range m.elements {
+		if e.Namespace == namespace && e.Identifier == identifier {
+			return attr, true
+		}
+	}
+	return "", false
+}
+
+// ToElements returns values of data elements, grouped by namespace, of attributes of
+// rawCred that are mapped and for which include returns true (nil includes all).
+func (m *Mapping) ToElements(rawCred *cl.RawCred,
+	include func(cl.CredAttr) bool) (map[string]map[string]interface{}, error) {
+	namespaces := make(map[string]map[string]interface{})
+	for _, a := range rawCred.GetAttrs() {
+		e, ok := m.elements[a.GetName()]
+		if !ok || !a.HasVal() || (include != nil && !include(a)) {
+			continue
+		}
+		val, err := toElementValue(e.Type, a.GetValue())
+		if err != nil {
+			return nil, fmt.Errorf("attribute %s: %v", a.GetName(), err)
+		}
+		if namespaces[e.Namespace] == nil {
+			namespaces[e.Namespace] = make(map[string]interface{})
+		}
+		namespaces[e.Namespace][e.Identifier] = val
+	}
+
+	return namespaces, nil
+}
+
+// FromElements sets values of mapped attributes of rawCred from values of data
+// elements grouped by namespace. Elements that are not mapped are ignored.
+func (m *Mapping) FromElements(rawCred *cl.RawCred,
+	namespaces map[string]map[string]interface{}) error {
+	for attr, e := range m.elements {
+		val, ok := namespaces[e.Namespace][e.Identifier]
+		if !ok {
+			continue
+		}
+		a, err := rawCred.GetAttr(attr)
+		if err != nil {
+			return err
+		}
+		attrVal, err := fromElementValue(e.Type, a, val)
+		if err != nil {
+			return fmt.Errorf("data element %s: %v", e.Identifier, err)
+		}
+		if err := a.UpdateValue(attrVal); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// toElementValue converts a value of a string or int64 attribute to the value of
+// a data element of type t.
+func toElementValue(t ElementType, val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case string:
+		switch t {
+		case TypeTstr:
+			return v, nil
+		case TypeFullDate:
+			if _, err := time.Parse(fullDate, v); err != nil || !fullDateRegexp.MatchString(v) {
+				return nil, fmt.Errorf("%s is not a full-date", v)
+			}
+			return v, nil
+		case TypeBool:
+			switch v {
+			case "true":
+				return true, nil
+			case "false":
+				return false, nil
+			}
+			return nil, fmt.Errorf("%s is not a boolean", v)
+		}
+	case int64:
+		switch t {
+		case TypeUint:
+			if v < 0 {
+				return nil, fmt.Errorf("%d is negative", v)
+			}
+			return uint64(v), nil
+		case TypeFullDate:
+			return time.Unix(v, 0).UTC().Format(fullDate), nil
+		case TypeBool:
+			return v != 0, nil
+		}
+	}
+
+	return nil, fmt.Errorf("value %v cannot be converted to %s", val, t)
+}
+
+// fromElementValue converts the value of a data element of type t to the value of
+// attribute a.
+func fromElementValue(t ElementType, a cl.CredAttr, val interface{}) (interface{}, error) {
+	switch a.(type) {
+	case *cl.StrAttr:
+		switch v := val.(type) {
+		case string:
+			if t == TypeTstr || t == TypeFullDate {
+				return v, nil
+			}
+		case bool:
+			if v {
+				return "true", nil
+			}
+			return "false", nil
+		}
+	case *cl.Int64Attr:
+		switch v := val.(type) {
+		case uint64:
+			if v > 1<<63-1 {
+				return nil, fmt.Errorf("%d is too large", v)
+			}
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case int:
+			return int64(v), nil
+		case string:
+			if t == TypeFullDate {
+				d, err := time.Parse(fullDate, v)
+				if err != nil {
+					return nil, err
+				}
+				return d.Unix(), nil
+			}
+		case bool:
+			if v {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		}
+	}
+
+	return nil, fmt.Errorf("value %v cannot be converted to %T", val, a)
+}
+
+// committedIndex returns the index of the committed attribute attr among committed
+// attributes of rawCred.
+func committedIndex(rawCred *cl.RawCred, attr string) (int, error) {
+	a, err := rawCred.GetAttr(attr)
+	if err != nil {
+		return 0, err
+	}
+	if a.IsKnown() {
+		return 0, fmt.Errorf("%s is not a committed attribute", attr)
+	}
+
+	return rawCred.GetAttrInternalIndex(attr)
+}
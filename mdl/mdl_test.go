@@ -0,0 +1,157 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package mdl
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+func TestMapping(t *testing.T) {
+	_, err := NewMapping(map[string]string{"Name": "nickname"})
+	assert.Error(t, err, "unknown data elements should not be mapped")
+
+	m, err := NewMapping(map[string]string{
+		"Name":      "given_name",
+		"IssueDate": "issue_date",
+		"Country":   "issuing_country",
+		"Height":    "height",
+	})
+	if err != nil {
+		t.Fatalf("error when creating mapping: %v", err)
+	}
+	m.Add("Student", Element{
+		Namespace:  "org.example.1",
+		Identifier: "student",
+		Type:       TypeBool,
+	})
+
+	cred := cl.NewRawCred(cl.NewAttrCount(5, 0, 0))
+	_ = cred.AddStrAttr("Name", "Jack", true)
+	_ = cred.AddInt64Attr("IssueDate", 1514764800, true) // 2018-01-01
+	_ = cred.AddStrAttr("Country", "SI", true)
+	_ = cred.AddInt64Attr("Height", 182, true)
+	_ = cred.AddStrAttr("Student", "true", true)
+
+	namespaces, err := m.ToElements(cred, nil)
+	if err != nil {
+		t.Fatalf("error when converting attributes: %v", err)
+	}
+	assert.Equal(t, map[string]map[string]interface{}{
+		Namespace: {
+			"given_name":      "Jack",
+			"issue_date":      "2018-01-01",
+			"issuing_country": "SI",
+			"height":          uint64(182),
+		},
+		"org.example.1": {
+			"student": true,
+		},
+	}, namespaces)
+
+	attr, ok := m.Attribute(Namespace, "issue_date")
+	assert.True(t, ok)
+	assert.Equal(t, "IssueDate", attr)
+
+	empty := cl.NewRawCred(cl.NewAttrCount(5, 0, 0))
+	_ = empty.AddEmptyStrAttr("Name", true)
+	_ = empty.AddEmptyInt64Attr("IssueDate", true)
+	_ = empty.AddEmptyStrAttr("Country", true)
+	_ = empty.AddEmptyInt64Attr("Height", true)
+	_ = empty.AddEmptyStrAttr("Student", true)
+	if err := m.FromElements(empty, namespaces); err != nil {
+		t.Fatalf("error when converting data elements: %v", err)
+	}
+	for _, name := range []string{"Name", "IssueDate", "Country", "Height", "Student"} {
+		a, _ := cred.GetAttr(name)
+		b, _ := empty.GetAttr(name)
+		assert.Equal(t, a.GetValue(), b.GetValue(), name)
+	}
+
+	namespaces[Namespace]["issue_date"] = "1 Jan 2018"
+	assert.Error(t, m.FromElements(empty, namespaces), "malformed date should fail")
+}
+
+func TestAgeOver(t *testing.T) {
+	nn, err := ParseAgeOver(AgeOverIdentifier(18))
+	assert.NoError(t, err)
+	assert.Equal(t, 18, nn)
+	_, err = ParseAgeOver("age_over_1")
+	assert.Error(t, err)
+
+	params := cl.GetDefaultParamSizes()
+	attrCount := cl.NewAttrCount(1, 2, 0)
+	org, err := cl.NewOrg(params, attrCount)
+	if err != nil {
+		t.Fatalf("error when generating CL org: %v", err)
+	}
+
+	cred := cl.NewRawCred(attrCount)
+	_ = cred.AddStrAttr("Name", "Jack", true)
+	_ = cred.AddInt64Attr("Age", 25, false)
+	_ = cred.AddInt64Attr("BirthDate", time.Now().AddDate(-25, 0, 0).Unix(), false)
+
+	credMgr, err := cl.NewCredManager(params, org.Keys.Pub,
+		org.Keys.Pub.GenerateUserMasterSecret(), cred)
+	if err != nil {
+		t.Fatalf("error when creating credential manager: %v", err)
+	}
+
+	m, err := NewMapping(map[string]string{
+		"Name":      "given_name",
+		"Age":       "age_in_years",
+		"BirthDate": "birth_date",
+	})
+	if err != nil {
+		t.Fatalf("error when creating mapping: %v", err)
+	}
+
+	tests := []struct {
+		attr string
+		kind AgeKind
+		i    int
+	}{
+		{"Age", AgeInYears, 0},
+		{"BirthDate", BirthDate, 1},
+	}
+	nonce := big.NewInt(42)
+	for _, test := range tests {
+		m.SetAgeSource(test.attr, test.kind)
+		for nn, over := range map[int]bool{18: true, 30: false} {
+			proof, err := m.ProveAgeOver(credMgr, nn, nonce)
+			if err != nil {
+				t.Fatalf("error when proving age_over_%d: %v", nn, err)
+			}
+			id, val, err := m.VerifyAgeOver(org, credMgr.CommitmentsOfAttrs[test.i],
+				proof, nonce)
+			assert.NoError(t, err, test.attr)
+			assert.Equal(t, AgeOverIdentifier(nn), id)
+			assert.Equal(t, over, val, test.attr)
+
+			// claiming the opposite value should fail
+			proof.Over = !proof.Over
+			_, _, err = m.VerifyAgeOver(org, credMgr.CommitmentsOfAttrs[test.i],
+				proof, nonce)
+			assert.Error(t, err, test.attr)
+		}
+	}
+}
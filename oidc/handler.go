@@ -0,0 +1,135 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package oidc
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Paths of endpoints served by Handler.
+const (
+	DiscoveryPath = "/.well-known/openid-configuration"
+	JWKSPath      = "/jwks"
+	TokenPath     = "/token"
+	UserInfoPath  = "/userinfo"
+)
+
+// discovery is the provider metadata published at the discovery endpoint.
+type discovery struct {
+	Issuer                 string   `json:"issuer"`
+	TokenEndpoint          string   `json:"token_endpoint"`
+	UserInfoEndpoint       string   `json:"userinfo_endpoint"`
+	JWKSURI                string   `json:"jwks_uri"`
+	ResponseTypes          []string `json:"response_types_supported"`
+	GrantTypes             []string `json:"grant_types_supported"`
+	SubjectTypes           []string `json:"subject_types_supported"`
+	IDTokenSigningAlgs     []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMetas []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// tokenError is an error response of the token endpoint (RFC 6749, section 5.2).
+type tokenError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// Handler returns an HTTP handler serving the discovery, JWKS, token and userinfo
+// endpoints of p.
+func (p *Provider) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(DiscoveryPath, p.serveDiscovery)
+	mux.Handle(JWKSPath, p.JWKS())
+	mux.HandleFunc(TokenPath, p.serveToken)
+	mux.HandleFunc(UserInfoPath, p.serveUserInfo)
+
+	return mux
+}
+
+func (p *Provider) serveDiscovery(w http.ResponseWriter, r *http.Request) {
+	issuer := strings.TrimSuffix(p.issuer, "/")
+	writeJSON(w, http.StatusOK, &discovery{
+		Issuer:                 p.issuer,
+		TokenEndpoint:          issuer + TokenPath,
+		UserInfoEndpoint:       issuer + UserInfoPath,
+		JWKSURI:                issuer + JWKSPath,
+		ResponseTypes:          []string{"code"},
+		GrantTypes:             []string{"authorization_code"},
+		SubjectTypes:           []string{"public"},
+		IDTokenSigningAlgs:     []string{"ES256"},
+		TokenEndpointAuthMetas: []string{"none"},
+	})
+}
+
+// serveToken handles token requests of the authorization code grant, where
+// the session key of the user is the authorization code.
+func (p *Provider) serveToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.PostFormValue("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, &tokenError{Error: "unsupported_grant_type"})
+		return
+	}
+	clientID := r.PostFormValue("client_id")
+	if clientID == "" {
+		writeJSON(w, http.StatusBadRequest, &tokenError{
+			Error:       "invalid_request",
+			Description: "client_id is required",
+		})
+		return
+	}
+
+	res, err := p.Exchange(r.PostFormValue("code"), clientID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, &tokenError{
+			Error:       "invalid_grant",
+			Description: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (p *Provider) serveUserInfo(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := p.UserInfo(strings.TrimPrefix(auth, "Bearer "))
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, claims)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
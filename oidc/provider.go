@@ -0,0 +1,205 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package oidc bridges emmy authentication to OpenID Connect. Once a user proves
+// possession of a credential to emmy server and obtains a session key, a relying
+// party (e.g. a web application the user passes the session key to) can exchange
+// the session key for an ID token and an access token at the token endpoint,
+// as it would an authorization code. Tokens hold the revealed attributes as claims.
+//
+// As emmy authentication is anonymous, the subject of tokens is not stable across
+// logins of the same user.
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xlab-si/emmy/jose"
+)
+
+// TokenResponse is the response of the token endpoint.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+}
+
+// grant holds claims about an authenticated user until they are exchanged for tokens.
+type grant struct {
+	claims  map[string]interface{}
+	expires time.Time
+}
+
+// Provider issues tokens for users authenticated by emmy server.
+type Provider struct {
+	issuer string
+	signer *jose.Signer
+	ttl    time.Duration
+
+	sync.Mutex
+	grants map[string]*grant
+}
+
+// NewProvider creates a provider with the given issuer identifier, which signs tokens
+// with key. Tokens, as well as session keys not yet exchanged for tokens, are valid for ttl.
+func NewProvider(issuer string, key *ecdsa.PrivateKey, ttl time.Duration) (*Provider, error) {
+	signer, err := jose.NewSigner(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Provider{
+		issuer: issuer,
+		signer: signer,
+		ttl:    ttl,
+		grants: make(map[string]*grant),
+	}, nil
+}
+
+// Issuer returns the issuer identifier of p.
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+// JWKS returns the set of keys that tokens issued by p can be verified with.
+func (p *Provider) JWKS() *jose.JWKSet {
+	return p.signer.JWKS()
+}
+
+// Authorize records that the user holding sessionKey was authenticated, with claims
+// about the user. Claims that clash with registered claims of tokens are ignored.
+func (p *Provider) Authorize(sessionKey string, claims map[string]interface{}) {
+	p.Lock()
+	defer p.Unlock()
+
+	// drop grants that were never exchanged
+	now := time.Now()
+	for k, g := range p.grants {
+		if now.After(g.expires) {
+			delete(p.grants, k)
+		}
+	}
+
+	p.grants[sessionKey] = &grant{
+		claims:  claims,
+		expires: now.Add(p.ttl),
+	}
+}
+
+// Exchange exchanges sessionKey for tokens issued to the client (relying party)
+// with identifier clientID. Each session key can only be exchanged once.
+func (p *Provider) Exchange(sessionKey, clientID string) (*TokenResponse, error) {
+	p.Lock()
+	g, ok := p.grants[sessionKey]
+	delete(p.grants, sessionKey)
+	p.Unlock()
+
+	if !ok || time.Now().After(g.expires) {
+		return nil, fmt.Errorf("invalid or expired session key")
+	}
+
+	idToken, err := p.IDToken(sessionKey, g.claims, clientID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	accessToken, err := p.sign(g.claims, map[string]interface{}{
+		"iss":       p.issuer,
+		"sub":       subject(sessionKey),
+		"aud":       p.issuer,
+		"client_id": clientID,
+		"scope":     "openid",
+		"iat":       now.Unix(),
+		"exp":       now.Add(p.ttl).Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(p.ttl / time.Second),
+		IDToken:     idToken,
+	}, nil
+}
+
+// IDToken returns an ID token issued to audience for the user holding sessionKey,
+// with claims about the user. Unlike Exchange, it does not require sessionKey
+// to be authorized first, so that emmy server can hand out the token directly
+// along with the session key.
+func (p *Provider) IDToken(sessionKey string, claims map[string]interface{},
+	audience string) (string, error) {
+	now := time.Now()
+	return p.sign(claims, map[string]interface{}{
+		"iss": p.issuer,
+		"sub": subject(sessionKey),
+		"aud": audience,
+		"iat": now.Unix(),
+		"exp": now.Add(p.ttl).Unix(),
+	})
+}
+
+// UserInfo verifies accessToken and returns claims about the user it was issued for.
+func (p *Provider) UserInfo(accessToken string) (map[string]interface{}, error) {
+	claims := make(map[string]interface{})
+	if err := p.signer.Verify(accessToken, &claims); err != nil {
+		return nil, err
+	}
+	if claims["iss"] != p.issuer || claims["aud"] != p.issuer {
+		return nil, fmt.Errorf("token was not issued by this provider")
+	}
+	exp, ok := claims["exp"].(json.Number)
+	if !ok {
+		return nil, fmt.Errorf("token has no exp claim")
+	}
+	if t, err := exp.Int64(); err != nil || time.Now().Unix() > t {
+		return nil, fmt.Errorf("token expired")
+	}
+
+	for _, c := range []string{"iss", "aud", "client_id", "scope", "iat", "exp"} {
+		delete(claims, c)
+	}
+
+	return claims, nil
+}
+
+// sign returns a token holding claims and registered claims, signed by p.
+func (p *Provider) sign(claims, registered map[string]interface{}) (string, error) {
+	all := make(map[string]interface{}, len(claims)+len(registered))
+	for k, v := range claims {
+		all[k] = v
+	}
+	for k, v := range registered {
+		all[k] = v
+	}
+
+	return p.signer.Sign(all)
+}
+
+// subject returns the subject of tokens issued for the user holding sessionKey.
+func subject(sessionKey string) string {
+	digest := sha256.Sum256([]byte(sessionKey))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
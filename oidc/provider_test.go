@@ -0,0 +1,143 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/jose"
+)
+
+func TestProvider(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	srv := httptest.NewServer(nil)
+	defer srv.Close()
+
+	p, err := NewProvider(srv.URL, key, time.Minute)
+	if err != nil {
+		t.Fatalf("error when creating provider: %v", err)
+	}
+	srv.Config.Handler = p.Handler()
+
+	p.Authorize("sessionKey", map[string]interface{}{
+		"Name": "Jack",
+		"iss":  "https://evil.example.com",
+	})
+
+	// relying party discovers the provider
+	res, err := http.Get(srv.URL + DiscoveryPath)
+	if err != nil {
+		t.Fatalf("discovery failed: %v", err)
+	}
+	d := new(discovery)
+	json.NewDecoder(res.Body).Decode(d)
+	res.Body.Close()
+	assert.Equal(t, srv.URL+TokenPath, d.TokenEndpoint)
+
+	res, err = http.Get(d.JWKSURI)
+	if err != nil {
+		t.Fatalf("fetching keys failed: %v", err)
+	}
+	jwks := new(jose.JWKSet)
+	json.NewDecoder(res.Body).Decode(jwks)
+	res.Body.Close()
+
+	// relying party exchanges session key for tokens
+	form := url.Values{
+		"grant_type": {"authorization_code"},
+		"code":       {"sessionKey"},
+		"client_id":  {"webapp"},
+	}
+	res, err = http.PostForm(d.TokenEndpoint, form)
+	if err != nil {
+		t.Fatalf("token request failed: %v", err)
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	tokens := new(TokenResponse)
+	json.NewDecoder(res.Body).Decode(tokens)
+	res.Body.Close()
+
+	claims := make(map[string]interface{})
+	h, err := jose.Decode(tokens.IDToken, &claims)
+	assert.NoError(t, err)
+	jwk := jwks.Key(h.Kid)
+	if jwk == nil {
+		t.Fatalf("token signing key is not published")
+	}
+	pub, _ := jwk.ECDSAPublicKey()
+	assert.NoError(t, jose.Verify(tokens.IDToken, pub, &claims))
+	assert.Equal(t, "Jack", claims["Name"])
+	assert.Equal(t, srv.URL, claims["iss"])
+	assert.Equal(t, "webapp", claims["aud"])
+
+	// session key can only be exchanged once
+	res, _ = http.PostForm(d.TokenEndpoint, form)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	res.Body.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, d.UserInfoEndpoint, nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	res, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("userinfo request failed: %v", err)
+	}
+	info := make(map[string]interface{})
+	json.NewDecoder(res.Body).Decode(&info)
+	res.Body.Close()
+	assert.Equal(t, "Jack", info["Name"])
+	assert.Equal(t, claims["sub"], info["sub"])
+
+	req.Header.Set("Authorization", "Bearer "+tokens.IDToken)
+	res, _ = http.DefaultClient.Do(req)
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode, "ID token is not an access token")
+	res.Body.Close()
+}
+
+func TestProviderIDToken(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	p, err := NewProvider("https://emmy.example.com", key, time.Minute)
+	if err != nil {
+		t.Fatalf("error when creating provider: %v", err)
+	}
+
+	token, err := p.IDToken("sessionKey", map[string]interface{}{
+		"Name": "Jack",
+		"aud":  "evil",
+	}, "webapp")
+	assert.NoError(t, err)
+
+	claims := make(map[string]interface{})
+	assert.NoError(t, jose.Verify(token, &key.PublicKey, &claims))
+	assert.Equal(t, "Jack", claims["Name"])
+	assert.Equal(t, "webapp", claims["aud"])
+	assert.Equal(t, subject("sessionKey"), claims["sub"])
+	assert.Equal(t, "https://emmy.example.com", claims["iss"])
+
+	// directly issued tokens do not authorize the session key for exchange
+	_, err = p.Exchange("sessionKey", "webapp")
+	assert.Error(t, err)
+}
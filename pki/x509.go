@@ -0,0 +1,242 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package pki bridges emmy keys and X.509 public key infrastructure. Public keys
+// of organizations are embedded in custom extensions of X.509 certificates, so
+// that trust in issuers and verifiers can be managed with existing certificate
+// authorities: a key is trusted when the certificate holding it chains to a
+// trusted root.
+package pki
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	"github.com/xlab-si/emmy/keys"
+)
+
+// Object identifiers of X.509 certificate extensions holding public keys of
+// organizations. Extension values are DER encodings of keys, the same as in
+// PEM blocks of emmy specific types. The extensions are never marked critical,
+// so that certificates remain usable by software that does not know them.
+var (
+	OIDExtensionCLPubKey             = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 51539, 1, 1}
+	OIDExtensionPseudonymsysPubKey   = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 51539, 1, 2}
+	OIDExtensionECPseudonymsysPubKey = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 51539, 1, 3}
+)
+
+// PEMTypeCertificate is the type of PEM blocks holding X.509 certificates.
+const PEMTypeCertificate = "CERTIFICATE"
+
+// OrgKeys holds public keys of an organization that are embedded in its
+// X.509 certificate. Keys of schemes the organization does not take part
+// in are nil.
+type OrgKeys struct {
+	CL             *cl.PubKey
+	Pseudonymsys   *pseudsys.PubKey
+	ECPseudonymsys *ecpseudsys.PubKey
+}
+
+// Extensions returns certificate extensions holding keys in k.
+func (k *OrgKeys) Extensions() ([]pkix.Extension, error) {
+	var exts []pkix.Extension
+	add := func(id asn1.ObjectIdentifier, data []byte, err error) error {
+		if err != nil {
+			return err
+		}
+		block, _ := pem.Decode(data)
+		exts = append(exts, pkix.Extension{Id: id, Value: block.Bytes})
+		return nil
+	}
+
+	if k.CL != nil {
+		data, err := k.CL.MarshalPEM()
+		if err := add(OIDExtensionCLPubKey, data, err); err != nil {
+			return nil, err
+		}
+	}
+	if k.Pseudonymsys != nil {
+		data, err := keys.EncodePseudonymsysPubKey(k.Pseudonymsys)
+		if err := add(OIDExtensionPseudonymsysPubKey, data, err); err != nil {
+			return nil, err
+		}
+	}
+	if k.ECPseudonymsys != nil {
+		data, err := keys.EncodeECPseudonymsysPubKey(k.ECPseudonymsys, keys.FormatPEM)
+		if err := add(OIDExtensionECPseudonymsysPubKey, data, err); err != nil {
+			return nil, err
+		}
+	}
+	if len(exts) == 0 {
+		return nil, fmt.Errorf("no keys to embed")
+	}
+
+	return exts, nil
+}
+
+// CreateOrgCertificate returns a certificate in PEM form based on template,
+// holding the subject's public key pub and organization keys k, signed by the
+// issuer with certificate parent and private key priv. When parent is template,
+// the certificate is self-signed.
+func CreateOrgCertificate(template, parent *x509.Certificate, pub crypto.PublicKey,
+	priv crypto.Signer, k *OrgKeys) ([]byte, error) {
+	exts, err := k.Extensions()
+	if err != nil {
+		return nil, err
+	}
+	tmpl := *template
+	tmpl.ExtraExtensions = append(append([]pkix.Extension{}, template.ExtraExtensions...), exts...)
+
+	der, err := x509.CreateCertificate(rand.Reader, &tmpl, parent, pub, priv)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: PEMTypeCertificate, Bytes: der}), nil
+}
+
+// ParseOrgKeys returns organization keys embedded in cert, WITHOUT verifying
+// cert. An error is returned if cert holds no such keys.
+func ParseOrgKeys(cert *x509.Certificate) (*OrgKeys, error) {
+	k := new(OrgKeys)
+	found := false
+	for _, ext := range cert.Extensions {
+		var err error
+		switch {
+		case ext.Id.Equal(OIDExtensionCLPubKey):
+			k.CL, err = cl.ParsePubKeyPEM(toPEM(cl.PEMTypePubKey, ext.Value))
+		case ext.Id.Equal(OIDExtensionPseudonymsysPubKey):
+			k.Pseudonymsys, err = keys.DecodePseudonymsysPubKey(
+				toPEM(keys.PEMTypePseudonymsysPubKey, ext.Value))
+		case ext.Id.Equal(OIDExtensionECPseudonymsysPubKey):
+			k.ECPseudonymsys, err = keys.DecodeECPseudonymsysPubKey(
+				toPEM(keys.PEMTypeECPseudonymsysPubKey, ext.Value))
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid extension %v: %v", ext.Id, err)
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("certificate of %s holds no organization keys",
+			cert.Subject.CommonName)
+	}
+
+	return k, nil
+}
+
+// ParseCertificates parses all certificates in PEM data, in the order they
+// appear. Blocks of other types are skipped.
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != PEMTypeCertificate {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+
+	return certs, nil
+}
+
+// VerifyOrgKeys verifies chain, whose first element is the certificate of an
+// organization followed by any intermediate certificates, against roots and
+// returns the organization keys embedded in the first certificate.
+func VerifyOrgKeys(chain []*x509.Certificate, roots *x509.CertPool) (*OrgKeys, error) {
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("empty certificate chain")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := chain[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return nil, err
+	}
+
+	return ParseOrgKeys(chain[0])
+}
+
+// LoadOrgKeys is like VerifyOrgKeys, but takes the certificate chain and
+// root certificates in PEM form.
+func LoadOrgKeys(chainPEM, rootsPEM []byte) (*OrgKeys, error) {
+	chain, err := ParseCertificates(chainPEM)
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(rootsPEM) {
+		return nil, fmt.Errorf("no root certificates found")
+	}
+
+	return VerifyOrgKeys(chain, roots)
+}
+
+// LoadTrustedOrgKeys reads the certificate chain of organization orgName and
+// the root certificates from files set in c, and returns the keys of the
+// organization if the chain verifies.
+func LoadTrustedOrgKeys(c *config.PKIConfig, orgName string) (*OrgKeys, error) {
+	chainPath, ok := c.Certs[orgName]
+	if !ok {
+		return nil, fmt.Errorf("no certificate configured for organization %s", orgName)
+	}
+	chainPEM, err := ioutil.ReadFile(chainPath)
+	if err != nil {
+		return nil, err
+	}
+	rootsPEM, err := ioutil.ReadFile(c.Roots)
+	if err != nil {
+		return nil, err
+	}
+
+	return LoadOrgKeys(chainPEM, rootsPEM)
+}
+
+// toPEM wraps the DER encoding of a key in a PEM block of type blockType,
+// as expected by decoding functions of keys.
+func toPEM(blockType string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
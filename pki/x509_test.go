@@ -0,0 +1,129 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package pki
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+)
+
+// newCA returns a certificate and key of a CA named name, issued by parent with
+// key parentKey, or self-signed if parent is nil.
+func newCA(t *testing.T, name string, parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := newTemplate(name)
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("error when creating certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return cert, key
+}
+
+func newTemplate(name string) *x509.Certificate {
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+}
+
+func TestOrgKeys(t *testing.T) {
+	clPubKey, err := cl.ReadPubKey("../client/testdata/clPubKey.gob")
+	if err != nil {
+		t.Fatalf("error when reading CL public key: %v", err)
+	}
+	group, err := schnorr.NewGroup(256)
+	if err != nil {
+		t.Fatalf("error when generating group: %v", err)
+	}
+	_, pk := pseudsys.GenerateKeyPair(group)
+	_, ecPk := ecpseudsys.GenerateKeyPair(ec.NewGroup(ec.P256))
+	orgKeys := &OrgKeys{
+		CL:             clPubKey,
+		Pseudonymsys:   pk,
+		ECPseudonymsys: ecPk,
+	}
+
+	root, rootKey := newCA(t, "root", nil, nil)
+	intermediate, intermediateKey := newCA(t, "intermediate", root, rootKey)
+	orgKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	orgCert, err := CreateOrgCertificate(newTemplate("org1"), intermediate,
+		&orgKey.PublicKey, intermediateKey, orgKeys)
+	if err != nil {
+		t.Fatalf("error when creating certificate: %v", err)
+	}
+
+	chain := append(orgCert, pem.EncodeToMemory(&pem.Block{
+		Type:  PEMTypeCertificate,
+		Bytes: intermediate.Raw,
+	})...)
+	rootPEM := pem.EncodeToMemory(&pem.Block{Type: PEMTypeCertificate, Bytes: root.Raw})
+
+	trusted, err := LoadOrgKeys(chain, rootPEM)
+	if err != nil {
+		t.Fatalf("error when loading organization keys: %v", err)
+	}
+	assert.Equal(t, clPubKey.GetContext(), trusted.CL.GetContext())
+	assert.Equal(t, pk, trusted.Pseudonymsys)
+	assert.Equal(t, ecPk, trusted.ECPseudonymsys)
+
+	// without the intermediate certificate, the chain cannot be verified
+	_, err = LoadOrgKeys(orgCert, rootPEM)
+	assert.Error(t, err)
+
+	// keys signed by an untrusted CA must not be accepted
+	otherRoot, _ := newCA(t, "other", nil, nil)
+	_, err = LoadOrgKeys(chain, pem.EncodeToMemory(&pem.Block{
+		Type:  PEMTypeCertificate,
+		Bytes: otherRoot.Raw,
+	}))
+	assert.Error(t, err)
+
+	// certificates without organization keys are rejected
+	_, err = ParseOrgKeys(intermediate)
+	assert.Error(t, err)
+	_, err = CreateOrgCertificate(newTemplate("org2"), root, &orgKey.PublicKey, rootKey,
+		new(OrgKeys))
+	assert.Error(t, err)
+}
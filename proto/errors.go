@@ -0,0 +1,66 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proto
+
+import (
+	"errors"
+
+	"github.com/xlab-si/emmy/crypto/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewStatusError returns a gRPC status error with code c and message msg, whose
+// details hold a ProtocolError with the cause e of the failure.
+func NewStatusError(c codes.Code, e ErrorCode, msg string) error {
+	s, err := status.New(c, msg).WithDetails(&ProtocolError{
+		Code:    e,
+		Message: msg,
+	})
+	if err != nil {
+		return status.Error(c, msg)
+	}
+	return s.Err()
+}
+
+// NewInvalidValueError returns a gRPC status error for err, which reports an invalid
+// value received from the client (for example by Decoder). Group elements that are
+// not in the expected group are reported with INVALID_GROUP_ELEMENT, other values with
+// INVALID_REQUEST.
+func NewInvalidValueError(err error) error {
+	e := ErrorCode_INVALID_REQUEST
+	if errors.Is(err, common.ErrNotInGroup) {
+		e = ErrorCode_INVALID_GROUP_ELEMENT
+	}
+	return NewStatusError(codes.InvalidArgument, e, err.Error())
+}
+
+// ToProtocolError returns the ProtocolError in the details of the gRPC status of
+// err. It returns nil if err is not a status error with such details.
+func ToProtocolError(err error) *ProtocolError {
+	s, ok := status.FromError(err)
+	if !ok || s == nil {
+		return nil
+	}
+	for _, d := range s.Details() {
+		if e, ok := d.(*ProtocolError); ok {
+			return e
+		}
+	}
+	return nil
+}
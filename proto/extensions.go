@@ -36,3 +36,44 @@ type ClientStream interface {
 	Recv() (*Message, error)
 	grpc.ClientStream
 }
+
+// StreamMethods maps names of emmy protocols to the full names of their gRPC streams,
+// for transports that carry protocol messages outside of gRPC.
+var StreamMethods = map[string]string{
+	"GenerateCertificate":    "/proto.PseudonymSystemCA/GenerateCertificate",
+	"GenerateCertificate_EC": "/proto.PseudonymSystemCA/GenerateCertificate_EC",
+	"GenerateNym":            "/proto.PseudonymSystem/GenerateNym",
+	"GenerateNym_EC":         "/proto.PseudonymSystem/GenerateNym_EC",
+	"ObtainCredential":       "/proto.PseudonymSystem/ObtainCredential",
+	"ObtainCredential_EC":    "/proto.PseudonymSystem/ObtainCredential_EC",
+	"TransferCredential":     "/proto.PseudonymSystem/TransferCredential",
+	"TransferCredential_EC":  "/proto.PseudonymSystem/TransferCredential_EC",
+	"IssueCredential":        "/proto.CL/IssueCredential",
+	"UpdateCredential":       "/proto.CL/UpdateCredential",
+	"ProveCredential":        "/proto.CL/ProveCredential",
+	"IssueBBSCredential":     "/proto.BBS/IssueBBSCredential",
+	"ProveBBSCredential":     "/proto.BBS/ProveBBSCredential",
+}
+
+// Full names of RPCs that verify non-interactive proofs, which the contexts of the
+// proofs are bound to (see NIContext.Value).
+const (
+	GenerateCertificateNIMethod = "/proto.PseudonymSystemCA/GenerateCertificateNI"
+	GenerateNymNIMethod         = "/proto.PseudonymSystem/GenerateNymNI"
+	ProveCredentialNIMethod     = "/proto.CL/ProveCredentialNI"
+	TransferCredentialMethod    = "/proto.PseudonymSystem/TransferCredential"
+	TransferCredentialECMethod  = "/proto.PseudonymSystem/TransferCredential_EC"
+)
+
+// StepMethod is the full name of the RPC that runs protocols with unary calls, one
+// for each of their messages.
+const StepMethod = "/proto.Steps/Step"
+
+// TenantMetadataKey is the key of gRPC metadata (and of the corresponding HTTP header
+// of the gateway and gRPC-Web requests) that selects the tenant of emmy server.
+const TenantMetadataKey = "emmy-tenant"
+
+// RequestIDMetadataKey is the key of gRPC metadata that holds the ID of a request. IDs
+// given by clients are kept, otherwise emmy server generates them, and in both cases
+// returns them in the header of the response.
+const RequestIDMetadataKey = "x-request-id"
@@ -16,9 +16,16 @@ It has these top-level messages:
 	Attribute
 	IntAttribute
 	StringAttribute
+	BlobAttribute
+	DateAttribute
+	BoolAttribute
+	EnumAttribute
+	BytesAttribute
 	CredAttribute
+	CredStructureRequest
 	CredStructure
 	Status
+	ProtocolError
 	BigInt
 	DoubleBigInt
 	PedersenFirst
@@ -31,6 +38,7 @@ It has these top-level messages:
 	FiatShamirAlsoNeg
 	SchnorrECProofRandomData
 	PseudonymsysNymGenProofRandomData
+	PseudonymsysNymGenProofNI
 	PseudonymsysNymGenProofRandomDataEC
 	PseudonymsysCACertificate
 	PseudonymsysCACertificateEC
@@ -48,8 +56,40 @@ It has these top-level messages:
 	RegKey
 	CLCredReq
 	CLCredential
+	CLCredBatchItem
+	CLCredBatchResult
+	CLThresholdInit
+	CLThresholdCommitment
+	CLThresholdCommitments
+	CLThresholdCiphertext
+	CLThresholdCiphertexts
+	CLPartialSignature
+	CLThresholdValue
+	BBSCredRequest
+	BBSSignature
+	BBSProof
 	UpdateCLCredential
 	ProveCLCredential
+	ProveCLCredentialNI
+	NIContext
+	ProtocolStep
+	CLPredicate
+	CLRangeProof
+	Accumulator
+	AccumulatorVersion
+	AccumulatorUpdate
+	NonRevocationWitness
+	NonRevocationProof
+	WebAuthnRegistration
+	WebAuthnAssertion
+	CLPubKey
+	CLParams
+	PublicParameters
+	SignedPublicParameters
+	FiatShamirEC
+	PseudonymsysChainLink
+	PseudonymsysCACertificateRequestNI
+	SessionStatus
 */
 package proto
 
@@ -69,6 +109,97 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto1.ProtoPackageIsVersion2 // please upgrade the proto package
 
+// ErrorCode identifies the cause of a failed protocol.
+type ErrorCode int32
+
+const (
+	ErrorCode_UNKNOWN_ERROR ErrorCode = 0
+	// a proof of the client is not valid
+	ErrorCode_INVALID_PROOF ErrorCode = 1
+	// the protocol was not completed before its nonce expired
+	ErrorCode_EXPIRED_NONCE ErrorCode = 2
+	// the organization requested by the client is not known to the server
+	ErrorCode_UNKNOWN_ORG ErrorCode = 3
+	// the credential of the client is revoked
+	ErrorCode_REVOKED ErrorCode = 4
+	// the registration key of the client is not valid or was already used
+	ErrorCode_INVALID_REG_KEY ErrorCode = 5
+	// the device the credential is bound to could not be registered or authenticated
+	ErrorCode_DEVICE_AUTH_FAILED ErrorCode = 6
+	// a message of the client is malformed or unexpected
+	ErrorCode_INVALID_REQUEST ErrorCode = 7
+	// the server failed to complete the protocol
+	ErrorCode_INTERNAL ErrorCode = 8
+	// the credential structure requested by the client is not known to the server
+	ErrorCode_UNKNOWN_SCHEMA ErrorCode = 9
+	// the credential of the client expired, or would be valid for longer than allowed
+	ErrorCode_EXPIRED_CREDENTIAL ErrorCode = 10
+	// the tenant requested by the client is not known to the server
+	ErrorCode_UNKNOWN_TENANT ErrorCode = 11
+	// the parameter profile requested by the client is not the one used by the server
+	ErrorCode_UNSUPPORTED_PROFILE ErrorCode = 12
+	// the client started too many protocols, or too many at the same time
+	ErrorCode_RATE_LIMITED ErrorCode = 13
+	// the proof was built with a key of the issuer the server does not (or no longer) accept
+	ErrorCode_UNKNOWN_KEY ErrorCode = 14
+	// a group element of the client is not in the group it is expected to belong to
+	ErrorCode_INVALID_GROUP_ELEMENT ErrorCode = 15
+	// the proof does not satisfy any policy of the server
+	ErrorCode_POLICY_NOT_SATISFIED ErrorCode = 16
+	// the session key is not valid, has expired or was revoked
+	ErrorCode_INVALID_SESSION ErrorCode = 17
+	// the client does not support any version of the protocol messages the server supports
+	ErrorCode_UNSUPPORTED_PROTOCOL_VERSION ErrorCode = 18
+)
+
+var ErrorCode_name = map[int32]string{
+	0:  "UNKNOWN_ERROR",
+	1:  "INVALID_PROOF",
+	2:  "EXPIRED_NONCE",
+	3:  "UNKNOWN_ORG",
+	4:  "REVOKED",
+	5:  "INVALID_REG_KEY",
+	6:  "DEVICE_AUTH_FAILED",
+	7:  "INVALID_REQUEST",
+	8:  "INTERNAL",
+	9:  "UNKNOWN_SCHEMA",
+	10: "EXPIRED_CREDENTIAL",
+	11: "UNKNOWN_TENANT",
+	12: "UNSUPPORTED_PROFILE",
+	13: "RATE_LIMITED",
+	14: "UNKNOWN_KEY",
+	15: "INVALID_GROUP_ELEMENT",
+	16: "POLICY_NOT_SATISFIED",
+	17: "INVALID_SESSION",
+	18: "UNSUPPORTED_PROTOCOL_VERSION",
+}
+var ErrorCode_value = map[string]int32{
+	"UNKNOWN_ERROR":                0,
+	"INVALID_PROOF":                1,
+	"EXPIRED_NONCE":                2,
+	"UNKNOWN_ORG":                  3,
+	"REVOKED":                      4,
+	"INVALID_REG_KEY":              5,
+	"DEVICE_AUTH_FAILED":           6,
+	"INVALID_REQUEST":              7,
+	"INTERNAL":                     8,
+	"UNKNOWN_SCHEMA":               9,
+	"EXPIRED_CREDENTIAL":           10,
+	"UNKNOWN_TENANT":               11,
+	"UNSUPPORTED_PROFILE":          12,
+	"RATE_LIMITED":                 13,
+	"UNKNOWN_KEY":                  14,
+	"INVALID_GROUP_ELEMENT":        15,
+	"POLICY_NOT_SATISFIED":         16,
+	"INVALID_SESSION":              17,
+	"UNSUPPORTED_PROTOCOL_VERSION": 18,
+}
+
+func (x ErrorCode) String() string {
+	return proto1.EnumName(ErrorCode_name, int32(x))
+}
+func (ErrorCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
+
 // A generic message
 type Message struct {
 	// Types that are valid to be assigned to Content:
@@ -95,8 +226,29 @@ type Message struct {
 	//	*Message_UpdateClCredential
 	//	*Message_ProveClCredential
 	//	*Message_RegKey
+	//	*Message_CLCredBatchItem
+	//	*Message_CLCredBatchResult
+	//	*Message_CLThresholdInit
+	//	*Message_CLThresholdCommitment
+	//	*Message_CLThresholdCommitments
+	//	*Message_CLThresholdCiphertexts
+	//	*Message_CLPartialSignature
+	//	*Message_CLThresholdValue
+	//	*Message_BBSCredRequest
+	//	*Message_BBSSignature
+	//	*Message_BBSProof
 	Content  isMessage_Content `protobuf_oneof:"content"`
 	ClientId int32             `protobuf:"varint,28,opt,name=clientId" json:"clientId,omitempty"`
+	// tenant selects the tenant of the server the protocol is run with, when it is not
+	// given in gRPC metadata (see TenantMetadataKey); it is read from the first message
+	Tenant string `protobuf:"bytes,47,opt,name=tenant" json:"tenant,omitempty"`
+	// profile names the group or curve parameters the client uses (see
+	// schnorr.GetGroupPreset and ec.GetCurvePreset); it is read from the first message
+	Profile string `protobuf:"bytes,48,opt,name=profile" json:"profile,omitempty"`
+	// protocolVersion is the version of the protocol messages (see ProtocolVersion): in
+	// the first message the highest version the client supports, and afterwards the
+	// version negotiated for the stream
+	ProtocolVersion int32 `protobuf:"varint,49,opt,name=protocolVersion" json:"protocolVersion,omitempty"`
 }
 
 func (m *Message) Reset()                    { *m = Message{} }
@@ -177,6 +329,39 @@ type Message_ProveClCredential struct {
 type Message_RegKey struct {
 	RegKey *RegKey `protobuf:"bytes,35,opt,name=RegKey,oneof"`
 }
+type Message_CLCredBatchItem struct {
+	CLCredBatchItem *CLCredBatchItem `protobuf:"bytes,36,opt,name=CLCredBatchItem,oneof"`
+}
+type Message_CLCredBatchResult struct {
+	CLCredBatchResult *CLCredBatchResult `protobuf:"bytes,37,opt,name=CLCredBatchResult,oneof"`
+}
+type Message_CLThresholdInit struct {
+	CLThresholdInit *CLThresholdInit `protobuf:"bytes,38,opt,name=CLThresholdInit,oneof"`
+}
+type Message_CLThresholdCommitment struct {
+	CLThresholdCommitment *CLThresholdCommitment `protobuf:"bytes,39,opt,name=CLThresholdCommitment,oneof"`
+}
+type Message_CLThresholdCommitments struct {
+	CLThresholdCommitments *CLThresholdCommitments `protobuf:"bytes,40,opt,name=CLThresholdCommitments,oneof"`
+}
+type Message_CLThresholdCiphertexts struct {
+	CLThresholdCiphertexts *CLThresholdCiphertexts `protobuf:"bytes,41,opt,name=CLThresholdCiphertexts,oneof"`
+}
+type Message_CLPartialSignature struct {
+	CLPartialSignature *CLPartialSignature `protobuf:"bytes,42,opt,name=CLPartialSignature,oneof"`
+}
+type Message_CLThresholdValue struct {
+	CLThresholdValue *CLThresholdValue `protobuf:"bytes,43,opt,name=CLThresholdValue,oneof"`
+}
+type Message_BBSCredRequest struct {
+	BBSCredRequest *BBSCredRequest `protobuf:"bytes,44,opt,name=BBSCredRequest,oneof"`
+}
+type Message_BBSSignature struct {
+	BBSSignature *BBSSignature `protobuf:"bytes,45,opt,name=BBSSignature,oneof"`
+}
+type Message_BBSProof struct {
+	BBSProof *BBSProof `protobuf:"bytes,46,opt,name=BBSProof,oneof"`
+}
 
 func (*Message_Bigint) isMessage_Content()                               {}
 func (*Message_EcGroupElement) isMessage_Content()                       {}
@@ -201,6 +386,17 @@ func (*Message_CLCredential) isMessage_Content()                         {}
 func (*Message_UpdateClCredential) isMessage_Content()                   {}
 func (*Message_ProveClCredential) isMessage_Content()                    {}
 func (*Message_RegKey) isMessage_Content()                               {}
+func (*Message_CLCredBatchItem) isMessage_Content()                      {}
+func (*Message_CLCredBatchResult) isMessage_Content()                    {}
+func (*Message_CLThresholdInit) isMessage_Content()                      {}
+func (*Message_CLThresholdCommitment) isMessage_Content()                {}
+func (*Message_CLThresholdCommitments) isMessage_Content()               {}
+func (*Message_CLThresholdCiphertexts) isMessage_Content()               {}
+func (*Message_CLPartialSignature) isMessage_Content()                   {}
+func (*Message_CLThresholdValue) isMessage_Content()                     {}
+func (*Message_BBSCredRequest) isMessage_Content()                       {}
+func (*Message_BBSSignature) isMessage_Content()                         {}
+func (*Message_BBSProof) isMessage_Content()                             {}
 
 func (m *Message) GetContent() isMessage_Content {
 	if m != nil {
@@ -370,6 +566,83 @@ func (m *Message) GetRegKey() *RegKey {
 	return nil
 }
 
+func (m *Message) GetCLCredBatchItem() *CLCredBatchItem {
+	if x, ok := m.GetContent().(*Message_CLCredBatchItem); ok {
+		return x.CLCredBatchItem
+	}
+	return nil
+}
+
+func (m *Message) GetCLCredBatchResult() *CLCredBatchResult {
+	if x, ok := m.GetContent().(*Message_CLCredBatchResult); ok {
+		return x.CLCredBatchResult
+	}
+	return nil
+}
+
+func (m *Message) GetCLThresholdInit() *CLThresholdInit {
+	if x, ok := m.GetContent().(*Message_CLThresholdInit); ok {
+		return x.CLThresholdInit
+	}
+	return nil
+}
+
+func (m *Message) GetCLThresholdCommitment() *CLThresholdCommitment {
+	if x, ok := m.GetContent().(*Message_CLThresholdCommitment); ok {
+		return x.CLThresholdCommitment
+	}
+	return nil
+}
+
+func (m *Message) GetCLThresholdCommitments() *CLThresholdCommitments {
+	if x, ok := m.GetContent().(*Message_CLThresholdCommitments); ok {
+		return x.CLThresholdCommitments
+	}
+	return nil
+}
+
+func (m *Message) GetCLThresholdCiphertexts() *CLThresholdCiphertexts {
+	if x, ok := m.GetContent().(*Message_CLThresholdCiphertexts); ok {
+		return x.CLThresholdCiphertexts
+	}
+	return nil
+}
+
+func (m *Message) GetCLPartialSignature() *CLPartialSignature {
+	if x, ok := m.GetContent().(*Message_CLPartialSignature); ok {
+		return x.CLPartialSignature
+	}
+	return nil
+}
+
+func (m *Message) GetCLThresholdValue() *CLThresholdValue {
+	if x, ok := m.GetContent().(*Message_CLThresholdValue); ok {
+		return x.CLThresholdValue
+	}
+	return nil
+}
+
+func (m *Message) GetBBSCredRequest() *BBSCredRequest {
+	if x, ok := m.GetContent().(*Message_BBSCredRequest); ok {
+		return x.BBSCredRequest
+	}
+	return nil
+}
+
+func (m *Message) GetBBSSignature() *BBSSignature {
+	if x, ok := m.GetContent().(*Message_BBSSignature); ok {
+		return x.BBSSignature
+	}
+	return nil
+}
+
+func (m *Message) GetBBSProof() *BBSProof {
+	if x, ok := m.GetContent().(*Message_BBSProof); ok {
+		return x.BBSProof
+	}
+	return nil
+}
+
 func (m *Message) GetClientId() int32 {
 	if m != nil {
 		return m.ClientId
@@ -377,6 +650,27 @@ func (m *Message) GetClientId() int32 {
 	return 0
 }
 
+func (m *Message) GetTenant() string {
+	if m != nil {
+		return m.Tenant
+	}
+	return ""
+}
+
+func (m *Message) GetProfile() string {
+	if m != nil {
+		return m.Profile
+	}
+	return ""
+}
+
+func (m *Message) GetProtocolVersion() int32 {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return 0
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*Message) XXX_OneofFuncs() (func(msg proto1.Message, b *proto1.Buffer) error, func(msg proto1.Message, tag, wire int, b *proto1.Buffer) (bool, error), func(msg proto1.Message) (n int), []interface{}) {
 	return _Message_OneofMarshaler, _Message_OneofUnmarshaler, _Message_OneofSizer, []interface{}{
@@ -403,6 +697,17 @@ func (*Message) XXX_OneofFuncs() (func(msg proto1.Message, b *proto1.Buffer) err
 		(*Message_UpdateClCredential)(nil),
 		(*Message_ProveClCredential)(nil),
 		(*Message_RegKey)(nil),
+		(*Message_CLCredBatchItem)(nil),
+		(*Message_CLCredBatchResult)(nil),
+		(*Message_CLThresholdInit)(nil),
+		(*Message_CLThresholdCommitment)(nil),
+		(*Message_CLThresholdCommitments)(nil),
+		(*Message_CLThresholdCiphertexts)(nil),
+		(*Message_CLPartialSignature)(nil),
+		(*Message_CLThresholdValue)(nil),
+		(*Message_BBSCredRequest)(nil),
+		(*Message_BBSSignature)(nil),
+		(*Message_BBSProof)(nil),
 	}
 }
 
@@ -525,6 +830,61 @@ func _Message_OneofMarshaler(msg proto1.Message, b *proto1.Buffer) error {
 		if err := b.EncodeMessage(x.RegKey); err != nil {
 			return err
 		}
+	case *Message_CLCredBatchItem:
+		b.EncodeVarint(36<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.CLCredBatchItem); err != nil {
+			return err
+		}
+	case *Message_CLCredBatchResult:
+		b.EncodeVarint(37<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.CLCredBatchResult); err != nil {
+			return err
+		}
+	case *Message_CLThresholdInit:
+		b.EncodeVarint(38<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.CLThresholdInit); err != nil {
+			return err
+		}
+	case *Message_CLThresholdCommitment:
+		b.EncodeVarint(39<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.CLThresholdCommitment); err != nil {
+			return err
+		}
+	case *Message_CLThresholdCommitments:
+		b.EncodeVarint(40<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.CLThresholdCommitments); err != nil {
+			return err
+		}
+	case *Message_CLThresholdCiphertexts:
+		b.EncodeVarint(41<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.CLThresholdCiphertexts); err != nil {
+			return err
+		}
+	case *Message_CLPartialSignature:
+		b.EncodeVarint(42<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.CLPartialSignature); err != nil {
+			return err
+		}
+	case *Message_CLThresholdValue:
+		b.EncodeVarint(43<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.CLThresholdValue); err != nil {
+			return err
+		}
+	case *Message_BBSCredRequest:
+		b.EncodeVarint(44<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.BBSCredRequest); err != nil {
+			return err
+		}
+	case *Message_BBSSignature:
+		b.EncodeVarint(45<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.BBSSignature); err != nil {
+			return err
+		}
+	case *Message_BBSProof:
+		b.EncodeVarint(46<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.BBSProof); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("Message.Content has unexpected type %T", x)
@@ -719,6 +1079,94 @@ func _Message_OneofUnmarshaler(msg proto1.Message, tag, wire int, b *proto1.Buff
 		err := b.DecodeMessage(msg)
 		m.Content = &Message_RegKey{msg}
 		return true, err
+	case 36: // content.CLCredBatchItem
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(CLCredBatchItem)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_CLCredBatchItem{msg}
+		return true, err
+	case 37: // content.CLCredBatchResult
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(CLCredBatchResult)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_CLCredBatchResult{msg}
+		return true, err
+	case 38: // content.CLThresholdInit
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(CLThresholdInit)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_CLThresholdInit{msg}
+		return true, err
+	case 39: // content.CLThresholdCommitment
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(CLThresholdCommitment)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_CLThresholdCommitment{msg}
+		return true, err
+	case 40: // content.CLThresholdCommitments
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(CLThresholdCommitments)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_CLThresholdCommitments{msg}
+		return true, err
+	case 41: // content.CLThresholdCiphertexts
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(CLThresholdCiphertexts)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_CLThresholdCiphertexts{msg}
+		return true, err
+	case 42: // content.CLPartialSignature
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(CLPartialSignature)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_CLPartialSignature{msg}
+		return true, err
+	case 43: // content.CLThresholdValue
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(CLThresholdValue)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_CLThresholdValue{msg}
+		return true, err
+	case 44: // content.BBSCredRequest
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(BBSCredRequest)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_BBSCredRequest{msg}
+		return true, err
+	case 45: // content.BBSSignature
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(BBSSignature)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_BBSSignature{msg}
+		return true, err
+	case 46: // content.BBSProof
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(BBSProof)
+		err := b.DecodeMessage(msg)
+		m.Content = &Message_BBSProof{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -843,6 +1291,61 @@ func _Message_OneofSizer(msg proto1.Message) (n int) {
 		n += proto1.SizeVarint(35<<3 | proto1.WireBytes)
 		n += proto1.SizeVarint(uint64(s))
 		n += s
+	case *Message_CLCredBatchItem:
+		s := proto1.Size(x.CLCredBatchItem)
+		n += proto1.SizeVarint(36<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *Message_CLCredBatchResult:
+		s := proto1.Size(x.CLCredBatchResult)
+		n += proto1.SizeVarint(37<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *Message_CLThresholdInit:
+		s := proto1.Size(x.CLThresholdInit)
+		n += proto1.SizeVarint(38<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *Message_CLThresholdCommitment:
+		s := proto1.Size(x.CLThresholdCommitment)
+		n += proto1.SizeVarint(39<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *Message_CLThresholdCommitments:
+		s := proto1.Size(x.CLThresholdCommitments)
+		n += proto1.SizeVarint(40<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *Message_CLThresholdCiphertexts:
+		s := proto1.Size(x.CLThresholdCiphertexts)
+		n += proto1.SizeVarint(41<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *Message_CLPartialSignature:
+		s := proto1.Size(x.CLPartialSignature)
+		n += proto1.SizeVarint(42<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *Message_CLThresholdValue:
+		s := proto1.Size(x.CLThresholdValue)
+		n += proto1.SizeVarint(43<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *Message_BBSCredRequest:
+		s := proto1.Size(x.BBSCredRequest)
+		n += proto1.SizeVarint(44<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *Message_BBSSignature:
+		s := proto1.Size(x.BBSSignature)
+		n += proto1.SizeVarint(45<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *Message_BBSProof:
+		s := proto1.Size(x.BBSProof)
+		n += proto1.SizeVarint(46<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -854,6 +1357,11 @@ type ServiceInfo struct {
 	Name        string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 	Description string `protobuf:"bytes,2,opt,name=description" json:"description,omitempty"`
 	Provider    string `protobuf:"bytes,3,opt,name=provider" json:"provider,omitempty"`
+	// clPubKeys are the CL public keys proofs of credentials are verified with: the
+	// current key, followed by previous keys accepted until the end of their grace period
+	ClPubKeys []*CLPubKey `protobuf:"bytes,4,rep,name=clPubKeys" json:"clPubKeys,omitempty"`
+	// protocolVersions are the versions of the protocol messages the server supports
+	ProtocolVersions []int32 `protobuf:"varint,5,rep,packed,name=protocolVersions" json:"protocolVersions,omitempty"`
 }
 
 func (m *ServiceInfo) Reset()                    { *m = ServiceInfo{} }
@@ -882,9 +1390,32 @@ func (m *ServiceInfo) GetProvider() string {
 	return ""
 }
 
+func (m *ServiceInfo) GetClPubKeys() []*CLPubKey {
+	if m != nil {
+		return m.ClPubKeys
+	}
+	return nil
+}
+
+func (m *ServiceInfo) GetProtocolVersions() []int32 {
+	if m != nil {
+		return m.ProtocolVersions
+	}
+	return nil
+}
+
+// AcceptableCred describes credentials the server accepts. When the server has
+// policies configured, each of them is described with orgName holding its name.
 type AcceptableCred struct {
 	OrgName       string   `protobuf:"bytes,1,opt,name=orgName" json:"orgName,omitempty"`
 	RevealedAttrs []string `protobuf:"bytes,2,rep,name=revealedAttrs" json:"revealedAttrs,omitempty"`
+	// IDs of CL keys of accepted issuers, any when empty
+	Issuers    []string       `protobuf:"bytes,3,rep,name=issuers" json:"issuers,omitempty"`
+	Predicates []*CLPredicate `protobuf:"bytes,4,rep,name=predicates" json:"predicates,omitempty"`
+	// maximum time since issuance in seconds, not limited when 0
+	MaxAge int64 `protobuf:"varint,5,opt,name=maxAge" json:"maxAge,omitempty"`
+	// backend of range proofs that need to be used, any when empty
+	RangeProofs string `protobuf:"bytes,6,opt,name=rangeProofs" json:"rangeProofs,omitempty"`
 }
 
 func (m *AcceptableCred) Reset()                    { *m = AcceptableCred{} }
@@ -906,6 +1437,34 @@ func (m *AcceptableCred) GetRevealedAttrs() []string {
 	return nil
 }
 
+func (m *AcceptableCred) GetIssuers() []string {
+	if m != nil {
+		return m.Issuers
+	}
+	return nil
+}
+
+func (m *AcceptableCred) GetPredicates() []*CLPredicate {
+	if m != nil {
+		return m.Predicates
+	}
+	return nil
+}
+
+func (m *AcceptableCred) GetMaxAge() int64 {
+	if m != nil {
+		return m.MaxAge
+	}
+	return 0
+}
+
+func (m *AcceptableCred) GetRangeProofs() string {
+	if m != nil {
+		return m.RangeProofs
+	}
+	return ""
+}
+
 type AcceptableCreds struct {
 	Creds []*AcceptableCred `protobuf:"bytes,1,rep,name=creds" json:"creds,omitempty"`
 }
@@ -986,67 +1545,230 @@ func (m *StringAttribute) GetAttr() *Attribute {
 	return nil
 }
 
-type CredAttribute struct {
-	// Types that are valid to be assigned to Type:
-	//	*CredAttribute_StringAttr
-	//	*CredAttribute_IntAttr
-	Type isCredAttribute_Type `protobuf_oneof:"type"`
+type BlobAttribute struct {
+	Attr *Attribute `protobuf:"bytes,1,opt,name=attr" json:"attr,omitempty"`
 }
 
-func (m *CredAttribute) Reset()                    { *m = CredAttribute{} }
-func (m *CredAttribute) String() string            { return proto1.CompactTextString(m) }
-func (*CredAttribute) ProtoMessage()               {}
-func (*CredAttribute) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (m *BlobAttribute) Reset()                    { *m = BlobAttribute{} }
+func (m *BlobAttribute) String() string            { return proto1.CompactTextString(m) }
+func (*BlobAttribute) ProtoMessage()               {}
+func (*BlobAttribute) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
-type isCredAttribute_Type interface {
-	isCredAttribute_Type()
+func (m *BlobAttribute) GetAttr() *Attribute {
+	if m != nil {
+		return m.Attr
+	}
+	return nil
 }
 
-type CredAttribute_StringAttr struct {
-	StringAttr *StringAttribute `protobuf:"bytes,1,opt,name=stringAttr,oneof"`
-}
-type CredAttribute_IntAttr struct {
-	IntAttr *IntAttribute `protobuf:"bytes,2,opt,name=intAttr,oneof"`
+type DateAttribute struct {
+	Attr *Attribute `protobuf:"bytes,1,opt,name=attr" json:"attr,omitempty"`
 }
 
-func (*CredAttribute_StringAttr) isCredAttribute_Type() {}
-func (*CredAttribute_IntAttr) isCredAttribute_Type()    {}
+func (m *DateAttribute) Reset()                    { *m = DateAttribute{} }
+func (m *DateAttribute) String() string            { return proto1.CompactTextString(m) }
+func (*DateAttribute) ProtoMessage()               {}
+func (*DateAttribute) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
-func (m *CredAttribute) GetType() isCredAttribute_Type {
+func (m *DateAttribute) GetAttr() *Attribute {
 	if m != nil {
-		return m.Type
+		return m.Attr
 	}
 	return nil
 }
 
-func (m *CredAttribute) GetStringAttr() *StringAttribute {
-	if x, ok := m.GetType().(*CredAttribute_StringAttr); ok {
-		return x.StringAttr
+type BoolAttribute struct {
+	Attr *Attribute `protobuf:"bytes,1,opt,name=attr" json:"attr,omitempty"`
+}
+
+func (m *BoolAttribute) Reset()                    { *m = BoolAttribute{} }
+func (m *BoolAttribute) String() string            { return proto1.CompactTextString(m) }
+func (*BoolAttribute) ProtoMessage()               {}
+func (*BoolAttribute) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *BoolAttribute) GetAttr() *Attribute {
+	if m != nil {
+		return m.Attr
 	}
 	return nil
 }
 
-func (m *CredAttribute) GetIntAttr() *IntAttribute {
-	if x, ok := m.GetType().(*CredAttribute_IntAttr); ok {
-		return x.IntAttr
+// EnumAttribute holds one of values, encoded as its position among them.
+type EnumAttribute struct {
+	Attr   *Attribute `protobuf:"bytes,1,opt,name=attr" json:"attr,omitempty"`
+	Values []string   `protobuf:"bytes,2,rep,name=values" json:"values,omitempty"`
+}
+
+func (m *EnumAttribute) Reset()                    { *m = EnumAttribute{} }
+func (m *EnumAttribute) String() string            { return proto1.CompactTextString(m) }
+func (*EnumAttribute) ProtoMessage()               {}
+func (*EnumAttribute) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *EnumAttribute) GetAttr() *Attribute {
+	if m != nil {
+		return m.Attr
 	}
 	return nil
 }
 
-// XXX_OneofFuncs is for the internal use of the proto package.
-func (*CredAttribute) XXX_OneofFuncs() (func(msg proto1.Message, b *proto1.Buffer) error, func(msg proto1.Message, tag, wire int, b *proto1.Buffer) (bool, error), func(msg proto1.Message) (n int), []interface{}) {
-	return _CredAttribute_OneofMarshaler, _CredAttribute_OneofUnmarshaler, _CredAttribute_OneofSizer, []interface{}{
-		(*CredAttribute_StringAttr)(nil),
-		(*CredAttribute_IntAttr)(nil),
+func (m *EnumAttribute) GetValues() []string {
+	if m != nil {
+		return m.Values
 	}
+	return nil
 }
 
-func _CredAttribute_OneofMarshaler(msg proto1.Message, b *proto1.Buffer) error {
-	m := msg.(*CredAttribute)
-	// type
-	switch x := m.Type.(type) {
-	case *CredAttribute_StringAttr:
-		b.EncodeVarint(1<<3 | proto1.WireBytes)
+// BytesAttribute holds a byte slice of the given size.
+type BytesAttribute struct {
+	Attr *Attribute `protobuf:"bytes,1,opt,name=attr" json:"attr,omitempty"`
+	Size int32      `protobuf:"varint,2,opt,name=size" json:"size,omitempty"`
+}
+
+func (m *BytesAttribute) Reset()                    { *m = BytesAttribute{} }
+func (m *BytesAttribute) String() string            { return proto1.CompactTextString(m) }
+func (*BytesAttribute) ProtoMessage()               {}
+func (*BytesAttribute) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *BytesAttribute) GetAttr() *Attribute {
+	if m != nil {
+		return m.Attr
+	}
+	return nil
+}
+
+func (m *BytesAttribute) GetSize() int32 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type CredAttribute struct {
+	// Types that are valid to be assigned to Type:
+	//	*CredAttribute_StringAttr
+	//	*CredAttribute_IntAttr
+	//	*CredAttribute_BlobAttr
+	//	*CredAttribute_DateAttr
+	//	*CredAttribute_BoolAttr
+	//	*CredAttribute_EnumAttr
+	//	*CredAttribute_BytesAttr
+	Type isCredAttribute_Type `protobuf_oneof:"type"`
+}
+
+func (m *CredAttribute) Reset()                    { *m = CredAttribute{} }
+func (m *CredAttribute) String() string            { return proto1.CompactTextString(m) }
+func (*CredAttribute) ProtoMessage()               {}
+func (*CredAttribute) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+type isCredAttribute_Type interface {
+	isCredAttribute_Type()
+}
+
+type CredAttribute_StringAttr struct {
+	StringAttr *StringAttribute `protobuf:"bytes,1,opt,name=stringAttr,oneof"`
+}
+type CredAttribute_IntAttr struct {
+	IntAttr *IntAttribute `protobuf:"bytes,2,opt,name=intAttr,oneof"`
+}
+type CredAttribute_BlobAttr struct {
+	BlobAttr *BlobAttribute `protobuf:"bytes,3,opt,name=blobAttr,oneof"`
+}
+type CredAttribute_DateAttr struct {
+	DateAttr *DateAttribute `protobuf:"bytes,4,opt,name=dateAttr,oneof"`
+}
+type CredAttribute_BoolAttr struct {
+	BoolAttr *BoolAttribute `protobuf:"bytes,5,opt,name=boolAttr,oneof"`
+}
+type CredAttribute_EnumAttr struct {
+	EnumAttr *EnumAttribute `protobuf:"bytes,6,opt,name=enumAttr,oneof"`
+}
+type CredAttribute_BytesAttr struct {
+	BytesAttr *BytesAttribute `protobuf:"bytes,7,opt,name=bytesAttr,oneof"`
+}
+
+func (*CredAttribute_StringAttr) isCredAttribute_Type() {}
+func (*CredAttribute_IntAttr) isCredAttribute_Type()    {}
+func (*CredAttribute_BlobAttr) isCredAttribute_Type()   {}
+func (*CredAttribute_DateAttr) isCredAttribute_Type()   {}
+func (*CredAttribute_BoolAttr) isCredAttribute_Type()   {}
+func (*CredAttribute_EnumAttr) isCredAttribute_Type()   {}
+func (*CredAttribute_BytesAttr) isCredAttribute_Type()  {}
+
+func (m *CredAttribute) GetType() isCredAttribute_Type {
+	if m != nil {
+		return m.Type
+	}
+	return nil
+}
+
+func (m *CredAttribute) GetStringAttr() *StringAttribute {
+	if x, ok := m.GetType().(*CredAttribute_StringAttr); ok {
+		return x.StringAttr
+	}
+	return nil
+}
+
+func (m *CredAttribute) GetIntAttr() *IntAttribute {
+	if x, ok := m.GetType().(*CredAttribute_IntAttr); ok {
+		return x.IntAttr
+	}
+	return nil
+}
+
+func (m *CredAttribute) GetBlobAttr() *BlobAttribute {
+	if x, ok := m.GetType().(*CredAttribute_BlobAttr); ok {
+		return x.BlobAttr
+	}
+	return nil
+}
+
+func (m *CredAttribute) GetDateAttr() *DateAttribute {
+	if x, ok := m.GetType().(*CredAttribute_DateAttr); ok {
+		return x.DateAttr
+	}
+	return nil
+}
+
+func (m *CredAttribute) GetBoolAttr() *BoolAttribute {
+	if x, ok := m.GetType().(*CredAttribute_BoolAttr); ok {
+		return x.BoolAttr
+	}
+	return nil
+}
+
+func (m *CredAttribute) GetEnumAttr() *EnumAttribute {
+	if x, ok := m.GetType().(*CredAttribute_EnumAttr); ok {
+		return x.EnumAttr
+	}
+	return nil
+}
+
+func (m *CredAttribute) GetBytesAttr() *BytesAttribute {
+	if x, ok := m.GetType().(*CredAttribute_BytesAttr); ok {
+		return x.BytesAttr
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*CredAttribute) XXX_OneofFuncs() (func(msg proto1.Message, b *proto1.Buffer) error, func(msg proto1.Message, tag, wire int, b *proto1.Buffer) (bool, error), func(msg proto1.Message) (n int), []interface{}) {
+	return _CredAttribute_OneofMarshaler, _CredAttribute_OneofUnmarshaler, _CredAttribute_OneofSizer, []interface{}{
+		(*CredAttribute_StringAttr)(nil),
+		(*CredAttribute_IntAttr)(nil),
+		(*CredAttribute_BlobAttr)(nil),
+		(*CredAttribute_DateAttr)(nil),
+		(*CredAttribute_BoolAttr)(nil),
+		(*CredAttribute_EnumAttr)(nil),
+		(*CredAttribute_BytesAttr)(nil),
+	}
+}
+
+func _CredAttribute_OneofMarshaler(msg proto1.Message, b *proto1.Buffer) error {
+	m := msg.(*CredAttribute)
+	// type
+	switch x := m.Type.(type) {
+	case *CredAttribute_StringAttr:
+		b.EncodeVarint(1<<3 | proto1.WireBytes)
 		if err := b.EncodeMessage(x.StringAttr); err != nil {
 			return err
 		}
@@ -1055,6 +1777,31 @@ func _CredAttribute_OneofMarshaler(msg proto1.Message, b *proto1.Buffer) error {
 		if err := b.EncodeMessage(x.IntAttr); err != nil {
 			return err
 		}
+	case *CredAttribute_BlobAttr:
+		b.EncodeVarint(3<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.BlobAttr); err != nil {
+			return err
+		}
+	case *CredAttribute_DateAttr:
+		b.EncodeVarint(4<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.DateAttr); err != nil {
+			return err
+		}
+	case *CredAttribute_BoolAttr:
+		b.EncodeVarint(5<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.BoolAttr); err != nil {
+			return err
+		}
+	case *CredAttribute_EnumAttr:
+		b.EncodeVarint(6<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.EnumAttr); err != nil {
+			return err
+		}
+	case *CredAttribute_BytesAttr:
+		b.EncodeVarint(7<<3 | proto1.WireBytes)
+		if err := b.EncodeMessage(x.BytesAttr); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("CredAttribute.Type has unexpected type %T", x)
@@ -1081,6 +1828,46 @@ func _CredAttribute_OneofUnmarshaler(msg proto1.Message, tag, wire int, b *proto
 		err := b.DecodeMessage(msg)
 		m.Type = &CredAttribute_IntAttr{msg}
 		return true, err
+	case 3: // type.blobAttr
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(BlobAttribute)
+		err := b.DecodeMessage(msg)
+		m.Type = &CredAttribute_BlobAttr{msg}
+		return true, err
+	case 4: // type.dateAttr
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(DateAttribute)
+		err := b.DecodeMessage(msg)
+		m.Type = &CredAttribute_DateAttr{msg}
+		return true, err
+	case 5: // type.boolAttr
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(BoolAttribute)
+		err := b.DecodeMessage(msg)
+		m.Type = &CredAttribute_BoolAttr{msg}
+		return true, err
+	case 6: // type.enumAttr
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(EnumAttribute)
+		err := b.DecodeMessage(msg)
+		m.Type = &CredAttribute_EnumAttr{msg}
+		return true, err
+	case 7: // type.bytesAttr
+		if wire != proto1.WireBytes {
+			return true, proto1.ErrInternalBadWireType
+		}
+		msg := new(BytesAttribute)
+		err := b.DecodeMessage(msg)
+		m.Type = &CredAttribute_BytesAttr{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -1100,6 +1887,31 @@ func _CredAttribute_OneofSizer(msg proto1.Message) (n int) {
 		n += proto1.SizeVarint(2<<3 | proto1.WireBytes)
 		n += proto1.SizeVarint(uint64(s))
 		n += s
+	case *CredAttribute_BlobAttr:
+		s := proto1.Size(x.BlobAttr)
+		n += proto1.SizeVarint(3<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *CredAttribute_DateAttr:
+		s := proto1.Size(x.DateAttr)
+		n += proto1.SizeVarint(4<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *CredAttribute_BoolAttr:
+		s := proto1.Size(x.BoolAttr)
+		n += proto1.SizeVarint(5<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *CredAttribute_EnumAttr:
+		s := proto1.Size(x.EnumAttr)
+		n += proto1.SizeVarint(6<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
+	case *CredAttribute_BytesAttr:
+		s := proto1.Size(x.BytesAttr)
+		n += proto1.SizeVarint(7<<3 | proto1.WireBytes)
+		n += proto1.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -1107,17 +1919,46 @@ func _CredAttribute_OneofSizer(msg proto1.Message) (n int) {
 	return n
 }
 
+// CredStructureRequest selects a credential structure by its name and version.
+// The default structure is selected when the name is empty, and the latest version
+// of the structure when the version is empty.
+type CredStructureRequest struct {
+	Name    string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Version string `protobuf:"bytes,2,opt,name=version" json:"version,omitempty"`
+}
+
+func (m *CredStructureRequest) Reset()                    { *m = CredStructureRequest{} }
+func (m *CredStructureRequest) String() string            { return proto1.CompactTextString(m) }
+func (*CredStructureRequest) ProtoMessage()               {}
+func (*CredStructureRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *CredStructureRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CredStructureRequest) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
 type CredStructure struct {
 	NKnown     int32            `protobuf:"varint,1,opt,name=nKnown" json:"nKnown,omitempty"`
 	NCommitted int32            `protobuf:"varint,2,opt,name=nCommitted" json:"nCommitted,omitempty"`
 	NHidden    int32            `protobuf:"varint,3,opt,name=nHidden" json:"nHidden,omitempty"`
 	Attributes []*CredAttribute `protobuf:"bytes,4,rep,name=attributes" json:"attributes,omitempty"`
+	Name       string           `protobuf:"bytes,5,opt,name=name" json:"name,omitempty"`
+	Version    string           `protobuf:"bytes,6,opt,name=version" json:"version,omitempty"`
 }
 
 func (m *CredStructure) Reset()                    { *m = CredStructure{} }
 func (m *CredStructure) String() string            { return proto1.CompactTextString(m) }
 func (*CredStructure) ProtoMessage()               {}
-func (*CredStructure) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (*CredStructure) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
 
 func (m *CredStructure) GetNKnown() int32 {
 	if m != nil {
@@ -1147,6 +1988,20 @@ func (m *CredStructure) GetAttributes() []*CredAttribute {
 	return nil
 }
 
+func (m *CredStructure) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CredStructure) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
 type Status struct {
 	Success bool `protobuf:"varint,1,opt,name=Success" json:"Success,omitempty"`
 }
@@ -1154,7 +2009,7 @@ type Status struct {
 func (m *Status) Reset()                    { *m = Status{} }
 func (m *Status) String() string            { return proto1.CompactTextString(m) }
 func (*Status) ProtoMessage()               {}
-func (*Status) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (*Status) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
 
 func (m *Status) GetSuccess() bool {
 	if m != nil {
@@ -1163,6 +2018,32 @@ func (m *Status) GetSuccess() bool {
 	return false
 }
 
+// ProtocolError describes why a protocol failed. It is attached to the details of
+// the gRPC status the protocol ends with.
+type ProtocolError struct {
+	Code    ErrorCode `protobuf:"varint,1,opt,name=Code,enum=proto.ErrorCode" json:"Code,omitempty"`
+	Message string    `protobuf:"bytes,2,opt,name=Message" json:"Message,omitempty"`
+}
+
+func (m *ProtocolError) Reset()                    { *m = ProtocolError{} }
+func (m *ProtocolError) String() string            { return proto1.CompactTextString(m) }
+func (*ProtocolError) ProtoMessage()               {}
+func (*ProtocolError) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *ProtocolError) GetCode() ErrorCode {
+	if m != nil {
+		return m.Code
+	}
+	return ErrorCode_UNKNOWN_ERROR
+}
+
+func (m *ProtocolError) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
 type BigInt struct {
 	X1 []byte `protobuf:"bytes,1,opt,name=X1,proto3" json:"X1,omitempty"`
 }
@@ -1170,7 +2051,7 @@ type BigInt struct {
 func (m *BigInt) Reset()                    { *m = BigInt{} }
 func (m *BigInt) String() string            { return proto1.CompactTextString(m) }
 func (*BigInt) ProtoMessage()               {}
-func (*BigInt) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+func (*BigInt) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
 
 func (m *BigInt) GetX1() []byte {
 	if m != nil {
@@ -1187,7 +2068,7 @@ type DoubleBigInt struct {
 func (m *DoubleBigInt) Reset()                    { *m = DoubleBigInt{} }
 func (m *DoubleBigInt) String() string            { return proto1.CompactTextString(m) }
 func (*DoubleBigInt) ProtoMessage()               {}
-func (*DoubleBigInt) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+func (*DoubleBigInt) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
 
 func (m *DoubleBigInt) GetX1() []byte {
 	if m != nil {
@@ -1210,7 +2091,7 @@ type PedersenFirst struct {
 func (m *PedersenFirst) Reset()                    { *m = PedersenFirst{} }
 func (m *PedersenFirst) String() string            { return proto1.CompactTextString(m) }
 func (*PedersenFirst) ProtoMessage()               {}
-func (*PedersenFirst) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+func (*PedersenFirst) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 func (m *PedersenFirst) GetH() []byte {
 	if m != nil {
@@ -1227,7 +2108,7 @@ type PedersenDecommitment struct {
 func (m *PedersenDecommitment) Reset()                    { *m = PedersenDecommitment{} }
 func (m *PedersenDecommitment) String() string            { return proto1.CompactTextString(m) }
 func (*PedersenDecommitment) ProtoMessage()               {}
-func (*PedersenDecommitment) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+func (*PedersenDecommitment) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
 
 func (m *PedersenDecommitment) GetX() []byte {
 	if m != nil {
@@ -1251,7 +2132,7 @@ type ECGroupElement struct {
 func (m *ECGroupElement) Reset()                    { *m = ECGroupElement{} }
 func (m *ECGroupElement) String() string            { return proto1.CompactTextString(m) }
 func (*ECGroupElement) ProtoMessage()               {}
-func (*ECGroupElement) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+func (*ECGroupElement) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
 func (m *ECGroupElement) GetX() []byte {
 	if m != nil {
@@ -1275,7 +2156,7 @@ type Pair struct {
 func (m *Pair) Reset()                    { *m = Pair{} }
 func (m *Pair) String() string            { return proto1.CompactTextString(m) }
 func (*Pair) ProtoMessage()               {}
-func (*Pair) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+func (*Pair) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
 
 func (m *Pair) GetA() []byte {
 	if m != nil {
@@ -1295,12 +2176,17 @@ type SchnorrProofRandomData struct {
 	X []byte `protobuf:"bytes,1,opt,name=X,proto3" json:"X,omitempty"`
 	A []byte `protobuf:"bytes,2,opt,name=A,proto3" json:"A,omitempty"`
 	B []byte `protobuf:"bytes,3,opt,name=B,proto3" json:"B,omitempty"`
+	// organization a pseudonym system credential is obtained from (default when empty)
+	OrgName string `protobuf:"bytes,4,opt,name=OrgName" json:"OrgName,omitempty"`
+	// credentials that the credential to be obtained is derived from, the first one
+	// issued first
+	Chain []*PseudonymsysChainLink `protobuf:"bytes,5,rep,name=Chain" json:"Chain,omitempty"`
 }
 
 func (m *SchnorrProofRandomData) Reset()                    { *m = SchnorrProofRandomData{} }
 func (m *SchnorrProofRandomData) String() string            { return proto1.CompactTextString(m) }
 func (*SchnorrProofRandomData) ProtoMessage()               {}
-func (*SchnorrProofRandomData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+func (*SchnorrProofRandomData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
 
 func (m *SchnorrProofRandomData) GetX() []byte {
 	if m != nil {
@@ -1323,6 +2209,20 @@ func (m *SchnorrProofRandomData) GetB() []byte {
 	return nil
 }
 
+func (m *SchnorrProofRandomData) GetOrgName() string {
+	if m != nil {
+		return m.OrgName
+	}
+	return ""
+}
+
+func (m *SchnorrProofRandomData) GetChain() []*PseudonymsysChainLink {
+	if m != nil {
+		return m.Chain
+	}
+	return nil
+}
+
 type SchnorrProofData struct {
 	Z []byte `protobuf:"bytes,1,opt,name=Z,proto3" json:"Z,omitempty"`
 }
@@ -1330,7 +2230,7 @@ type SchnorrProofData struct {
 func (m *SchnorrProofData) Reset()                    { *m = SchnorrProofData{} }
 func (m *SchnorrProofData) String() string            { return proto1.CompactTextString(m) }
 func (*SchnorrProofData) ProtoMessage()               {}
-func (*SchnorrProofData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+func (*SchnorrProofData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
 
 func (m *SchnorrProofData) GetZ() []byte {
 	if m != nil {
@@ -1353,7 +2253,7 @@ type FiatShamir struct {
 func (m *FiatShamir) Reset()                    { *m = FiatShamir{} }
 func (m *FiatShamir) String() string            { return proto1.CompactTextString(m) }
 func (*FiatShamir) ProtoMessage()               {}
-func (*FiatShamir) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+func (*FiatShamir) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
 
 func (m *FiatShamir) GetProofRandomData() []byte {
 	if m != nil {
@@ -1386,7 +2286,7 @@ type FiatShamirAlsoNeg struct {
 func (m *FiatShamirAlsoNeg) Reset()                    { *m = FiatShamirAlsoNeg{} }
 func (m *FiatShamirAlsoNeg) String() string            { return proto1.CompactTextString(m) }
 func (*FiatShamirAlsoNeg) ProtoMessage()               {}
-func (*FiatShamirAlsoNeg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+func (*FiatShamirAlsoNeg) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
 
 func (m *FiatShamirAlsoNeg) GetProofRandomData() []byte {
 	if m != nil {
@@ -1413,12 +2313,14 @@ type SchnorrECProofRandomData struct {
 	X *ECGroupElement `protobuf:"bytes,1,opt,name=X" json:"X,omitempty"`
 	A *ECGroupElement `protobuf:"bytes,2,opt,name=A" json:"A,omitempty"`
 	B *ECGroupElement `protobuf:"bytes,3,opt,name=B" json:"B,omitempty"`
+	// organization a pseudonym system credential is obtained from (default when empty)
+	OrgName string `protobuf:"bytes,4,opt,name=OrgName" json:"OrgName,omitempty"`
 }
 
 func (m *SchnorrECProofRandomData) Reset()                    { *m = SchnorrECProofRandomData{} }
 func (m *SchnorrECProofRandomData) String() string            { return proto1.CompactTextString(m) }
 func (*SchnorrECProofRandomData) ProtoMessage()               {}
-func (*SchnorrECProofRandomData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+func (*SchnorrECProofRandomData) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
 
 func (m *SchnorrECProofRandomData) GetX() *ECGroupElement {
 	if m != nil {
@@ -1441,6 +2343,13 @@ func (m *SchnorrECProofRandomData) GetB() *ECGroupElement {
 	return nil
 }
 
+func (m *SchnorrECProofRandomData) GetOrgName() string {
+	if m != nil {
+		return m.OrgName
+	}
+	return ""
+}
+
 type PseudonymsysNymGenProofRandomData struct {
 	X1     []byte `protobuf:"bytes,1,opt,name=X1,proto3" json:"X1,omitempty"`
 	A1     []byte `protobuf:"bytes,2,opt,name=A1,proto3" json:"A1,omitempty"`
@@ -1457,7 +2366,7 @@ func (m *PseudonymsysNymGenProofRandomData) Reset()         { *m = PseudonymsysN
 func (m *PseudonymsysNymGenProofRandomData) String() string { return proto1.CompactTextString(m) }
 func (*PseudonymsysNymGenProofRandomData) ProtoMessage()    {}
 func (*PseudonymsysNymGenProofRandomData) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{21}
+	return fileDescriptor0, []int{28}
 }
 
 func (m *PseudonymsysNymGenProofRandomData) GetX1() []byte {
@@ -1523,6 +2432,48 @@ func (m *PseudonymsysNymGenProofRandomData) GetRegKey() string {
 	return ""
 }
 
+// PseudonymsysNymGenProofNI holds a non-interactive proof for registration of a nym,
+// with the challenge derived via Fiat-Shamir from Data and Context.
+type PseudonymsysNymGenProofNI struct {
+	Data      *PseudonymsysNymGenProofRandomData `protobuf:"bytes,1,opt,name=Data" json:"Data,omitempty"`
+	Challenge []byte                             `protobuf:"bytes,2,opt,name=Challenge,proto3" json:"Challenge,omitempty"`
+	Z         []byte                             `protobuf:"bytes,3,opt,name=Z,proto3" json:"Z,omitempty"`
+	Context   *NIContext                         `protobuf:"bytes,4,opt,name=Context" json:"Context,omitempty"`
+}
+
+func (m *PseudonymsysNymGenProofNI) Reset()                    { *m = PseudonymsysNymGenProofNI{} }
+func (m *PseudonymsysNymGenProofNI) String() string            { return proto1.CompactTextString(m) }
+func (*PseudonymsysNymGenProofNI) ProtoMessage()               {}
+func (*PseudonymsysNymGenProofNI) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *PseudonymsysNymGenProofNI) GetData() *PseudonymsysNymGenProofRandomData {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *PseudonymsysNymGenProofNI) GetChallenge() []byte {
+	if m != nil {
+		return m.Challenge
+	}
+	return nil
+}
+
+func (m *PseudonymsysNymGenProofNI) GetZ() []byte {
+	if m != nil {
+		return m.Z
+	}
+	return nil
+}
+
+func (m *PseudonymsysNymGenProofNI) GetContext() *NIContext {
+	if m != nil {
+		return m.Context
+	}
+	return nil
+}
+
 type PseudonymsysNymGenProofRandomDataEC struct {
 	X1     *ECGroupElement `protobuf:"bytes,1,opt,name=X1" json:"X1,omitempty"`
 	A1     *ECGroupElement `protobuf:"bytes,2,opt,name=A1" json:"A1,omitempty"`
@@ -1539,7 +2490,7 @@ func (m *PseudonymsysNymGenProofRandomDataEC) Reset()         { *m = Pseudonymsy
 func (m *PseudonymsysNymGenProofRandomDataEC) String() string { return proto1.CompactTextString(m) }
 func (*PseudonymsysNymGenProofRandomDataEC) ProtoMessage()    {}
 func (*PseudonymsysNymGenProofRandomDataEC) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{22}
+	return fileDescriptor0, []int{30}
 }
 
 func (m *PseudonymsysNymGenProofRandomDataEC) GetX1() *ECGroupElement {
@@ -1615,7 +2566,7 @@ type PseudonymsysCACertificate struct {
 func (m *PseudonymsysCACertificate) Reset()                    { *m = PseudonymsysCACertificate{} }
 func (m *PseudonymsysCACertificate) String() string            { return proto1.CompactTextString(m) }
 func (*PseudonymsysCACertificate) ProtoMessage()               {}
-func (*PseudonymsysCACertificate) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+func (*PseudonymsysCACertificate) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
 
 func (m *PseudonymsysCACertificate) GetBlindedA() []byte {
 	if m != nil {
@@ -1655,7 +2606,7 @@ type PseudonymsysCACertificateEC struct {
 func (m *PseudonymsysCACertificateEC) Reset()                    { *m = PseudonymsysCACertificateEC{} }
 func (m *PseudonymsysCACertificateEC) String() string            { return proto1.CompactTextString(m) }
 func (*PseudonymsysCACertificateEC) ProtoMessage()               {}
-func (*PseudonymsysCACertificateEC) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+func (*PseudonymsysCACertificateEC) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
 
 func (m *PseudonymsysCACertificateEC) GetBlindedA() *ECGroupElement {
 	if m != nil {
@@ -1698,7 +2649,7 @@ func (m *PseudonymsysIssueProofRandomData) Reset()         { *m = PseudonymsysIs
 func (m *PseudonymsysIssueProofRandomData) String() string { return proto1.CompactTextString(m) }
 func (*PseudonymsysIssueProofRandomData) ProtoMessage()    {}
 func (*PseudonymsysIssueProofRandomData) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{25}
+	return fileDescriptor0, []int{33}
 }
 
 func (m *PseudonymsysIssueProofRandomData) GetX11() []byte {
@@ -1756,7 +2707,7 @@ func (m *PseudonymsysIssueProofRandomDataEC) Reset()         { *m = Pseudonymsys
 func (m *PseudonymsysIssueProofRandomDataEC) String() string { return proto1.CompactTextString(m) }
 func (*PseudonymsysIssueProofRandomDataEC) ProtoMessage()    {}
 func (*PseudonymsysIssueProofRandomDataEC) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{26}
+	return fileDescriptor0, []int{34}
 }
 
 func (m *PseudonymsysIssueProofRandomDataEC) GetX11() *ECGroupElement {
@@ -1811,7 +2762,7 @@ type PseudonymsysTranscript struct {
 func (m *PseudonymsysTranscript) Reset()                    { *m = PseudonymsysTranscript{} }
 func (m *PseudonymsysTranscript) String() string            { return proto1.CompactTextString(m) }
 func (*PseudonymsysTranscript) ProtoMessage()               {}
-func (*PseudonymsysTranscript) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+func (*PseudonymsysTranscript) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
 
 func (m *PseudonymsysTranscript) GetA() []byte {
 	if m != nil {
@@ -1851,7 +2802,7 @@ type PseudonymsysTranscriptEC struct {
 func (m *PseudonymsysTranscriptEC) Reset()                    { *m = PseudonymsysTranscriptEC{} }
 func (m *PseudonymsysTranscriptEC) String() string            { return proto1.CompactTextString(m) }
 func (*PseudonymsysTranscriptEC) ProtoMessage()               {}
-func (*PseudonymsysTranscriptEC) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+func (*PseudonymsysTranscriptEC) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
 
 func (m *PseudonymsysTranscriptEC) GetA() *ECGroupElement {
 	if m != nil {
@@ -1893,7 +2844,7 @@ type PseudonymsysCredential struct {
 func (m *PseudonymsysCredential) Reset()                    { *m = PseudonymsysCredential{} }
 func (m *PseudonymsysCredential) String() string            { return proto1.CompactTextString(m) }
 func (*PseudonymsysCredential) ProtoMessage()               {}
-func (*PseudonymsysCredential) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+func (*PseudonymsysCredential) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
 
 func (m *PseudonymsysCredential) GetSmallAToGamma() []byte {
 	if m != nil {
@@ -1949,7 +2900,7 @@ type PseudonymsysCredentialEC struct {
 func (m *PseudonymsysCredentialEC) Reset()                    { *m = PseudonymsysCredentialEC{} }
 func (m *PseudonymsysCredentialEC) String() string            { return proto1.CompactTextString(m) }
 func (*PseudonymsysCredentialEC) ProtoMessage()               {}
-func (*PseudonymsysCredentialEC) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+func (*PseudonymsysCredentialEC) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
 
 func (m *PseudonymsysCredentialEC) GetSmallAToGamma() *ECGroupElement {
 	if m != nil {
@@ -2000,13 +2951,21 @@ type PseudonymsysTransferCredentialData struct {
 	NymA       []byte                  `protobuf:"bytes,4,opt,name=NymA,proto3" json:"NymA,omitempty"`
 	NymB       []byte                  `protobuf:"bytes,5,opt,name=NymB,proto3" json:"NymB,omitempty"`
 	Credential *PseudonymsysCredential `protobuf:"bytes,6,opt,name=Credential" json:"Credential,omitempty"`
+	// organization the credential is transferred to (default when empty)
+	TargetOrgName string `protobuf:"bytes,7,opt,name=TargetOrgName" json:"TargetOrgName,omitempty"`
+	// proof of possession of the secret of the nym, bound to Context, which names the
+	// organization the credential is transferred to as its verifier
+	Possession *FiatShamir `protobuf:"bytes,8,opt,name=Possession" json:"Possession,omitempty"`
+	Context    *NIContext  `protobuf:"bytes,9,opt,name=Context" json:"Context,omitempty"`
+	// credentials that Credential is derived from, the first one issued first
+	Chain []*PseudonymsysChainLink `protobuf:"bytes,10,rep,name=Chain" json:"Chain,omitempty"`
 }
 
 func (m *PseudonymsysTransferCredentialData) Reset()         { *m = PseudonymsysTransferCredentialData{} }
 func (m *PseudonymsysTransferCredentialData) String() string { return proto1.CompactTextString(m) }
 func (*PseudonymsysTransferCredentialData) ProtoMessage()    {}
 func (*PseudonymsysTransferCredentialData) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{31}
+	return fileDescriptor0, []int{39}
 }
 
 func (m *PseudonymsysTransferCredentialData) GetOrgName() string {
@@ -2051,6 +3010,27 @@ func (m *PseudonymsysTransferCredentialData) GetCredential() *PseudonymsysCreden
 	return nil
 }
 
+func (m *PseudonymsysTransferCredentialData) GetTargetOrgName() string {
+	if m != nil {
+		return m.TargetOrgName
+	}
+	return ""
+}
+
+func (m *PseudonymsysTransferCredentialData) GetPossession() *FiatShamir {
+	if m != nil {
+		return m.Possession
+	}
+	return nil
+}
+
+func (m *PseudonymsysTransferCredentialData) GetContext() *NIContext {
+	if m != nil {
+		return m.Context
+	}
+	return nil
+}
+
 type PseudonymsysTransferCredentialDataEC struct {
 	OrgName    string                    `protobuf:"bytes,1,opt,name=OrgName" json:"OrgName,omitempty"`
 	X1         *ECGroupElement           `protobuf:"bytes,2,opt,name=X1" json:"X1,omitempty"`
@@ -2058,13 +3038,25 @@ type PseudonymsysTransferCredentialDataEC struct {
 	NymA       *ECGroupElement           `protobuf:"bytes,4,opt,name=NymA" json:"NymA,omitempty"`
 	NymB       *ECGroupElement           `protobuf:"bytes,5,opt,name=NymB" json:"NymB,omitempty"`
 	Credential *PseudonymsysCredentialEC `protobuf:"bytes,6,opt,name=Credential" json:"Credential,omitempty"`
+	// organization the credential is transferred to (default when empty)
+	TargetOrgName string `protobuf:"bytes,7,opt,name=TargetOrgName" json:"TargetOrgName,omitempty"`
+	// proof of possession of the secret of the nym, like in PseudonymsysTransferCredentialData
+	Possession *FiatShamirEC `protobuf:"bytes,8,opt,name=Possession" json:"Possession,omitempty"`
+	Context    *NIContext    `protobuf:"bytes,9,opt,name=Context" json:"Context,omitempty"`
 }
 
 func (m *PseudonymsysTransferCredentialDataEC) Reset()         { *m = PseudonymsysTransferCredentialDataEC{} }
 func (m *PseudonymsysTransferCredentialDataEC) String() string { return proto1.CompactTextString(m) }
 func (*PseudonymsysTransferCredentialDataEC) ProtoMessage()    {}
 func (*PseudonymsysTransferCredentialDataEC) Descriptor() ([]byte, []int) {
-	return fileDescriptor0, []int{32}
+	return fileDescriptor0, []int{40}
+}
+
+func (m *PseudonymsysTransferCredentialData) GetChain() []*PseudonymsysChainLink {
+	if m != nil {
+		return m.Chain
+	}
+	return nil
 }
 
 func (m *PseudonymsysTransferCredentialDataEC) GetOrgName() string {
@@ -2109,10 +3101,31 @@ func (m *PseudonymsysTransferCredentialDataEC) GetCredential() *PseudonymsysCred
 	return nil
 }
 
-type CSPaillierSecretKey struct {
-	N                    []byte `protobuf:"bytes,1,opt,name=N,proto3" json:"N,omitempty"`
-	G                    []byte `protobuf:"bytes,2,opt,name=G,proto3" json:"G,omitempty"`
-	X1                   []byte `protobuf:"bytes,3,opt,name=X1,proto3" json:"X1,omitempty"`
+func (m *PseudonymsysTransferCredentialDataEC) GetTargetOrgName() string {
+	if m != nil {
+		return m.TargetOrgName
+	}
+	return ""
+}
+
+func (m *PseudonymsysTransferCredentialDataEC) GetPossession() *FiatShamirEC {
+	if m != nil {
+		return m.Possession
+	}
+	return nil
+}
+
+func (m *PseudonymsysTransferCredentialDataEC) GetContext() *NIContext {
+	if m != nil {
+		return m.Context
+	}
+	return nil
+}
+
+type CSPaillierSecretKey struct {
+	N                    []byte `protobuf:"bytes,1,opt,name=N,proto3" json:"N,omitempty"`
+	G                    []byte `protobuf:"bytes,2,opt,name=G,proto3" json:"G,omitempty"`
+	X1                   []byte `protobuf:"bytes,3,opt,name=X1,proto3" json:"X1,omitempty"`
 	X2                   []byte `protobuf:"bytes,4,opt,name=X2,proto3" json:"X2,omitempty"`
 	X3                   []byte `protobuf:"bytes,5,opt,name=X3,proto3" json:"X3,omitempty"`
 	DLogP                []byte `protobuf:"bytes,6,opt,name=DLogP,proto3" json:"DLogP,omitempty"`
@@ -2128,7 +3141,7 @@ type CSPaillierSecretKey struct {
 func (m *CSPaillierSecretKey) Reset()                    { *m = CSPaillierSecretKey{} }
 func (m *CSPaillierSecretKey) String() string            { return proto1.CompactTextString(m) }
 func (*CSPaillierSecretKey) ProtoMessage()               {}
-func (*CSPaillierSecretKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
+func (*CSPaillierSecretKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
 
 func (m *CSPaillierSecretKey) GetN() []byte {
 	if m != nil {
@@ -2240,7 +3253,7 @@ type CSPaillierPubKey struct {
 func (m *CSPaillierPubKey) Reset()                    { *m = CSPaillierPubKey{} }
 func (m *CSPaillierPubKey) String() string            { return proto1.CompactTextString(m) }
 func (*CSPaillierPubKey) ProtoMessage()               {}
-func (*CSPaillierPubKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+func (*CSPaillierPubKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
 
 func (m *CSPaillierPubKey) GetN() []byte {
 	if m != nil {
@@ -2335,12 +3348,14 @@ func (m *CSPaillierPubKey) GetK1() int32 {
 
 type SessionKey struct {
 	Value string `protobuf:"bytes,1,opt,name=value" json:"value,omitempty"`
+	// ID token of the OpenID Connect bridge, if the server issues them directly
+	IdToken string `protobuf:"bytes,2,opt,name=idToken" json:"idToken,omitempty"`
 }
 
 func (m *SessionKey) Reset()                    { *m = SessionKey{} }
 func (m *SessionKey) String() string            { return proto1.CompactTextString(m) }
 func (*SessionKey) ProtoMessage()               {}
-func (*SessionKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+func (*SessionKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
 
 func (m *SessionKey) GetValue() string {
 	if m != nil {
@@ -2349,6 +3364,13 @@ func (m *SessionKey) GetValue() string {
 	return ""
 }
 
+func (m *SessionKey) GetIdToken() string {
+	if m != nil {
+		return m.IdToken
+	}
+	return ""
+}
+
 type RegKey struct {
 	RegKey string `protobuf:"bytes,1,opt,name=RegKey" json:"RegKey,omitempty"`
 }
@@ -2356,7 +3378,7 @@ type RegKey struct {
 func (m *RegKey) Reset()                    { *m = RegKey{} }
 func (m *RegKey) String() string            { return proto1.CompactTextString(m) }
 func (*RegKey) ProtoMessage()               {}
-func (*RegKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+func (*RegKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
 
 func (m *RegKey) GetRegKey() string {
 	if m != nil {
@@ -2366,20 +3388,21 @@ func (m *RegKey) GetRegKey() string {
 }
 
 type CLCredReq struct {
-	Nym                      []byte             `protobuf:"bytes,1,opt,name=Nym,proto3" json:"Nym,omitempty"`
-	KnownAttrs               [][]byte           `protobuf:"bytes,2,rep,name=KnownAttrs,proto3" json:"KnownAttrs,omitempty"`
-	CommitmentsOfAttrs       [][]byte           `protobuf:"bytes,3,rep,name=CommitmentsOfAttrs,proto3" json:"CommitmentsOfAttrs,omitempty"`
-	NymProof                 *FiatShamir        `protobuf:"bytes,4,opt,name=NymProof" json:"NymProof,omitempty"`
-	U                        []byte             `protobuf:"bytes,5,opt,name=U,proto3" json:"U,omitempty"`
-	UProof                   *FiatShamirAlsoNeg `protobuf:"bytes,6,opt,name=UProof" json:"UProof,omitempty"`
-	CommitmentsOfAttrsProofs []*FiatShamir      `protobuf:"bytes,7,rep,name=CommitmentsOfAttrsProofs" json:"CommitmentsOfAttrsProofs,omitempty"`
-	Nonce                    []byte             `protobuf:"bytes,8,opt,name=Nonce,proto3" json:"Nonce,omitempty"`
+	Nym                      []byte                `protobuf:"bytes,1,opt,name=Nym,proto3" json:"Nym,omitempty"`
+	KnownAttrs               [][]byte              `protobuf:"bytes,2,rep,name=KnownAttrs,proto3" json:"KnownAttrs,omitempty"`
+	CommitmentsOfAttrs       [][]byte              `protobuf:"bytes,3,rep,name=CommitmentsOfAttrs,proto3" json:"CommitmentsOfAttrs,omitempty"`
+	NymProof                 *FiatShamir           `protobuf:"bytes,4,opt,name=NymProof" json:"NymProof,omitempty"`
+	U                        []byte                `protobuf:"bytes,5,opt,name=U,proto3" json:"U,omitempty"`
+	UProof                   *FiatShamirAlsoNeg    `protobuf:"bytes,6,opt,name=UProof" json:"UProof,omitempty"`
+	CommitmentsOfAttrsProofs []*FiatShamir         `protobuf:"bytes,7,rep,name=CommitmentsOfAttrsProofs" json:"CommitmentsOfAttrsProofs,omitempty"`
+	Nonce                    []byte                `protobuf:"bytes,8,opt,name=Nonce,proto3" json:"Nonce,omitempty"`
+	DeviceRegistration       *WebAuthnRegistration `protobuf:"bytes,9,opt,name=DeviceRegistration" json:"DeviceRegistration,omitempty"`
 }
 
 func (m *CLCredReq) Reset()                    { *m = CLCredReq{} }
 func (m *CLCredReq) String() string            { return proto1.CompactTextString(m) }
 func (*CLCredReq) ProtoMessage()               {}
-func (*CLCredReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+func (*CLCredReq) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
 
 func (m *CLCredReq) GetNym() []byte {
 	if m != nil {
@@ -2437,17 +3460,27 @@ func (m *CLCredReq) GetNonce() []byte {
 	return nil
 }
 
+func (m *CLCredReq) GetDeviceRegistration() *WebAuthnRegistration {
+	if m != nil {
+		return m.DeviceRegistration
+	}
+	return nil
+}
+
 type CLCredential struct {
-	A      []byte             `protobuf:"bytes,1,opt,name=A,proto3" json:"A,omitempty"`
-	E      []byte             `protobuf:"bytes,2,opt,name=E,proto3" json:"E,omitempty"`
-	V11    []byte             `protobuf:"bytes,3,opt,name=V11,proto3" json:"V11,omitempty"`
-	AProof *FiatShamirAlsoNeg `protobuf:"bytes,4,opt,name=AProof" json:"AProof,omitempty"`
+	A       []byte                `protobuf:"bytes,1,opt,name=A,proto3" json:"A,omitempty"`
+	E       []byte                `protobuf:"bytes,2,opt,name=E,proto3" json:"E,omitempty"`
+	V11     []byte                `protobuf:"bytes,3,opt,name=V11,proto3" json:"V11,omitempty"`
+	AProof  *FiatShamirAlsoNeg    `protobuf:"bytes,4,opt,name=AProof" json:"AProof,omitempty"`
+	Witness *NonRevocationWitness `protobuf:"bytes,5,opt,name=Witness" json:"Witness,omitempty"`
+	// KeyID is the ID of the public key the credential was issued under (see cl.PubKey.ID)
+	KeyID string `protobuf:"bytes,6,opt,name=KeyID" json:"KeyID,omitempty"`
 }
 
 func (m *CLCredential) Reset()                    { *m = CLCredential{} }
 func (m *CLCredential) String() string            { return proto1.CompactTextString(m) }
 func (*CLCredential) ProtoMessage()               {}
-func (*CLCredential) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+func (*CLCredential) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
 
 func (m *CLCredential) GetA() []byte {
 	if m != nil {
@@ -2477,90 +3510,1650 @@ func (m *CLCredential) GetAProof() *FiatShamirAlsoNeg {
 	return nil
 }
 
-type UpdateCLCredential struct {
-	Nym           []byte   `protobuf:"bytes,1,opt,name=Nym,proto3" json:"Nym,omitempty"`
-	Nonce         []byte   `protobuf:"bytes,2,opt,name=Nonce,proto3" json:"Nonce,omitempty"`
-	NewKnownAttrs [][]byte `protobuf:"bytes,3,rep,name=NewKnownAttrs,proto3" json:"NewKnownAttrs,omitempty"`
+func (m *CLCredential) GetWitness() *NonRevocationWitness {
+	if m != nil {
+		return m.Witness
+	}
+	return nil
 }
 
-func (m *UpdateCLCredential) Reset()                    { *m = UpdateCLCredential{} }
-func (m *UpdateCLCredential) String() string            { return proto1.CompactTextString(m) }
-func (*UpdateCLCredential) ProtoMessage()               {}
-func (*UpdateCLCredential) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+func (m *CLCredential) GetKeyID() string {
+	if m != nil {
+		return m.KeyID
+	}
+	return ""
+}
 
-func (m *UpdateCLCredential) GetNym() []byte {
+// CLCredBatchItem is a credential request of a batch, identified by Id.
+type CLCredBatchItem struct {
+	Id      int64      `protobuf:"varint,1,opt,name=Id" json:"Id,omitempty"`
+	RegKey  string     `protobuf:"bytes,2,opt,name=RegKey" json:"RegKey,omitempty"`
+	CredReq *CLCredReq `protobuf:"bytes,3,opt,name=CredReq" json:"CredReq,omitempty"`
+}
+
+func (m *CLCredBatchItem) Reset()                    { *m = CLCredBatchItem{} }
+func (m *CLCredBatchItem) String() string            { return proto1.CompactTextString(m) }
+func (*CLCredBatchItem) ProtoMessage()               {}
+func (*CLCredBatchItem) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+
+func (m *CLCredBatchItem) GetId() int64 {
 	if m != nil {
-		return m.Nym
+		return m.Id
+	}
+	return 0
+}
+
+func (m *CLCredBatchItem) GetRegKey() string {
+	if m != nil {
+		return m.RegKey
+	}
+	return ""
+}
+
+func (m *CLCredBatchItem) GetCredReq() *CLCredReq {
+	if m != nil {
+		return m.CredReq
 	}
 	return nil
 }
 
-func (m *UpdateCLCredential) GetNonce() []byte {
+// CLCredBatchResult holds the credential issued for the batch item with Id, or the
+// error that occurred when issuing it.
+type CLCredBatchResult struct {
+	Id         int64         `protobuf:"varint,1,opt,name=Id" json:"Id,omitempty"`
+	Credential *CLCredential `protobuf:"bytes,2,opt,name=Credential" json:"Credential,omitempty"`
+	Error      string        `protobuf:"bytes,3,opt,name=Error" json:"Error,omitempty"`
+}
+
+func (m *CLCredBatchResult) Reset()                    { *m = CLCredBatchResult{} }
+func (m *CLCredBatchResult) String() string            { return proto1.CompactTextString(m) }
+func (*CLCredBatchResult) ProtoMessage()               {}
+func (*CLCredBatchResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
+
+func (m *CLCredBatchResult) GetId() int64 {
 	if m != nil {
-		return m.Nonce
+		return m.Id
+	}
+	return 0
+}
+
+func (m *CLCredBatchResult) GetCredential() *CLCredential {
+	if m != nil {
+		return m.Credential
 	}
 	return nil
 }
 
-func (m *UpdateCLCredential) GetNewKnownAttrs() [][]byte {
+func (m *CLCredBatchResult) GetError() string {
 	if m != nil {
-		return m.NewKnownAttrs
+		return m.Error
+	}
+	return ""
+}
+
+// CLThresholdInit starts threshold signing of a CL credential with prime E by
+// parties with indices Signers.
+type CLThresholdInit struct {
+	E       []byte  `protobuf:"bytes,1,opt,name=E,proto3" json:"E,omitempty"`
+	Signers []int32 `protobuf:"varint,2,rep,packed,name=Signers" json:"Signers,omitempty"`
+}
+
+func (m *CLThresholdInit) Reset()                    { *m = CLThresholdInit{} }
+func (m *CLThresholdInit) String() string            { return proto1.CompactTextString(m) }
+func (*CLThresholdInit) ProtoMessage()               {}
+func (*CLThresholdInit) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{49} }
+
+func (m *CLThresholdInit) GetE() []byte {
+	if m != nil {
+		return m.E
 	}
 	return nil
 }
 
-type ProveCLCredential struct {
-	A                          []byte             `protobuf:"bytes,1,opt,name=A,proto3" json:"A,omitempty"`
-	Proof                      *FiatShamirAlsoNeg `protobuf:"bytes,2,opt,name=Proof" json:"Proof,omitempty"`
-	KnownAttrs                 [][]byte           `protobuf:"bytes,3,rep,name=KnownAttrs,proto3" json:"KnownAttrs,omitempty"`
-	CommitmentsOfAttrs         [][]byte           `protobuf:"bytes,4,rep,name=CommitmentsOfAttrs,proto3" json:"CommitmentsOfAttrs,omitempty"`
-	RevealedKnownAttrs         []int32            `protobuf:"varint,5,rep,packed,name=RevealedKnownAttrs" json:"RevealedKnownAttrs,omitempty"`
-	RevealedCommitmentsOfAttrs []int32            `protobuf:"varint,6,rep,packed,name=RevealedCommitmentsOfAttrs" json:"RevealedCommitmentsOfAttrs,omitempty"`
+func (m *CLThresholdInit) GetSigners() []int32 {
+	if m != nil {
+		return m.Signers
+	}
+	return nil
 }
 
-func (m *ProveCLCredential) Reset()                    { *m = ProveCLCredential{} }
-func (m *ProveCLCredential) String() string            { return proto1.CompactTextString(m) }
-func (*ProveCLCredential) ProtoMessage()               {}
-func (*ProveCLCredential) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+type CLThresholdCommitment struct {
+	Index     int32  `protobuf:"varint,1,opt,name=Index" json:"Index,omitempty"`
+	PaillierN []byte `protobuf:"bytes,2,opt,name=PaillierN,proto3" json:"PaillierN,omitempty"`
+	PaillierG []byte `protobuf:"bytes,3,opt,name=PaillierG,proto3" json:"PaillierG,omitempty"`
+	EncShare  []byte `protobuf:"bytes,4,opt,name=EncShare,proto3" json:"EncShare,omitempty"`
+}
 
-func (m *ProveCLCredential) GetA() []byte {
+func (m *CLThresholdCommitment) Reset()                    { *m = CLThresholdCommitment{} }
+func (m *CLThresholdCommitment) String() string            { return proto1.CompactTextString(m) }
+func (*CLThresholdCommitment) ProtoMessage()               {}
+func (*CLThresholdCommitment) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{50} }
+
+func (m *CLThresholdCommitment) GetIndex() int32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *CLThresholdCommitment) GetPaillierN() []byte {
+	if m != nil {
+		return m.PaillierN
+	}
+	return nil
+}
+
+func (m *CLThresholdCommitment) GetPaillierG() []byte {
+	if m != nil {
+		return m.PaillierG
+	}
+	return nil
+}
+
+func (m *CLThresholdCommitment) GetEncShare() []byte {
+	if m != nil {
+		return m.EncShare
+	}
+	return nil
+}
+
+type CLThresholdCommitments struct {
+	Commitments []*CLThresholdCommitment `protobuf:"bytes,1,rep,name=Commitments" json:"Commitments,omitempty"`
+}
+
+func (m *CLThresholdCommitments) Reset()                    { *m = CLThresholdCommitments{} }
+func (m *CLThresholdCommitments) String() string            { return proto1.CompactTextString(m) }
+func (*CLThresholdCommitments) ProtoMessage()               {}
+func (*CLThresholdCommitments) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{51} }
+
+func (m *CLThresholdCommitments) GetCommitments() []*CLThresholdCommitment {
+	if m != nil {
+		return m.Commitments
+	}
+	return nil
+}
+
+type CLThresholdCiphertext struct {
+	From  int32  `protobuf:"varint,1,opt,name=From" json:"From,omitempty"`
+	To    int32  `protobuf:"varint,2,opt,name=To" json:"To,omitempty"`
+	Value []byte `protobuf:"bytes,3,opt,name=Value,proto3" json:"Value,omitempty"`
+}
+
+func (m *CLThresholdCiphertext) Reset()                    { *m = CLThresholdCiphertext{} }
+func (m *CLThresholdCiphertext) String() string            { return proto1.CompactTextString(m) }
+func (*CLThresholdCiphertext) ProtoMessage()               {}
+func (*CLThresholdCiphertext) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{52} }
+
+func (m *CLThresholdCiphertext) GetFrom() int32 {
+	if m != nil {
+		return m.From
+	}
+	return 0
+}
+
+func (m *CLThresholdCiphertext) GetTo() int32 {
+	if m != nil {
+		return m.To
+	}
+	return 0
+}
+
+func (m *CLThresholdCiphertext) GetValue() []byte {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+type CLThresholdCiphertexts struct {
+	Ciphertexts []*CLThresholdCiphertext `protobuf:"bytes,1,rep,name=Ciphertexts" json:"Ciphertexts,omitempty"`
+}
+
+func (m *CLThresholdCiphertexts) Reset()                    { *m = CLThresholdCiphertexts{} }
+func (m *CLThresholdCiphertexts) String() string            { return proto1.CompactTextString(m) }
+func (*CLThresholdCiphertexts) ProtoMessage()               {}
+func (*CLThresholdCiphertexts) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{53} }
+
+func (m *CLThresholdCiphertexts) GetCiphertexts() []*CLThresholdCiphertext {
+	if m != nil {
+		return m.Ciphertexts
+	}
+	return nil
+}
+
+type CLPartialSignature struct {
+	A []byte `protobuf:"bytes,1,opt,name=A,proto3" json:"A,omitempty"`
+	T []byte `protobuf:"bytes,2,opt,name=T,proto3" json:"T,omitempty"`
+}
+
+func (m *CLPartialSignature) Reset()                    { *m = CLPartialSignature{} }
+func (m *CLPartialSignature) String() string            { return proto1.CompactTextString(m) }
+func (*CLPartialSignature) ProtoMessage()               {}
+func (*CLPartialSignature) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{54} }
+
+func (m *CLPartialSignature) GetA() []byte {
 	if m != nil {
 		return m.A
 	}
 	return nil
 }
 
-func (m *ProveCLCredential) GetProof() *FiatShamirAlsoNeg {
+func (m *CLPartialSignature) GetT() []byte {
 	if m != nil {
-		return m.Proof
+		return m.T
 	}
 	return nil
 }
 
-func (m *ProveCLCredential) GetKnownAttrs() [][]byte {
+// CLThresholdValue is a share of a value computed in threshold signing, in decimal,
+// as it can be negative.
+type CLThresholdValue struct {
+	Value string `protobuf:"bytes,1,opt,name=Value" json:"Value,omitempty"`
+}
+
+func (m *CLThresholdValue) Reset()                    { *m = CLThresholdValue{} }
+func (m *CLThresholdValue) String() string            { return proto1.CompactTextString(m) }
+func (*CLThresholdValue) ProtoMessage()               {}
+func (*CLThresholdValue) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{55} }
+
+func (m *CLThresholdValue) GetValue() string {
 	if m != nil {
-		return m.KnownAttrs
+		return m.Value
+	}
+	return ""
+}
+
+// BBSCredRequest is a request for a BBS+ credential. Points of BLS12-381 are
+// compressed.
+type BBSCredRequest struct {
+	KnownMsgs    [][]byte `protobuf:"bytes,1,rep,name=KnownMsgs,proto3" json:"KnownMsgs,omitempty"`
+	U            []byte   `protobuf:"bytes,2,opt,name=U,proto3" json:"U,omitempty"`
+	Challenge    []byte   `protobuf:"bytes,3,opt,name=Challenge,proto3" json:"Challenge,omitempty"`
+	SResponse    []byte   `protobuf:"bytes,4,opt,name=SResponse,proto3" json:"SResponse,omitempty"`
+	MsgResponses [][]byte `protobuf:"bytes,5,rep,name=MsgResponses,proto3" json:"MsgResponses,omitempty"`
+}
+
+func (m *BBSCredRequest) Reset()                    { *m = BBSCredRequest{} }
+func (m *BBSCredRequest) String() string            { return proto1.CompactTextString(m) }
+func (*BBSCredRequest) ProtoMessage()               {}
+func (*BBSCredRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{56} }
+
+func (m *BBSCredRequest) GetKnownMsgs() [][]byte {
+	if m != nil {
+		return m.KnownMsgs
 	}
 	return nil
 }
 
-func (m *ProveCLCredential) GetCommitmentsOfAttrs() [][]byte {
+func (m *BBSCredRequest) GetU() []byte {
 	if m != nil {
-		return m.CommitmentsOfAttrs
+		return m.U
 	}
 	return nil
 }
 
-func (m *ProveCLCredential) GetRevealedKnownAttrs() []int32 {
+func (m *BBSCredRequest) GetChallenge() []byte {
 	if m != nil {
-		return m.RevealedKnownAttrs
+		return m.Challenge
 	}
 	return nil
 }
 
-func (m *ProveCLCredential) GetRevealedCommitmentsOfAttrs() []int32 {
+func (m *BBSCredRequest) GetSResponse() []byte {
 	if m != nil {
-		return m.RevealedCommitmentsOfAttrs
+		return m.SResponse
+	}
+	return nil
+}
+
+func (m *BBSCredRequest) GetMsgResponses() [][]byte {
+	if m != nil {
+		return m.MsgResponses
+	}
+	return nil
+}
+
+type BBSSignature struct {
+	A []byte `protobuf:"bytes,1,opt,name=A,proto3" json:"A,omitempty"`
+	E []byte `protobuf:"bytes,2,opt,name=E,proto3" json:"E,omitempty"`
+	S []byte `protobuf:"bytes,3,opt,name=S,proto3" json:"S,omitempty"`
+}
+
+func (m *BBSSignature) Reset()                    { *m = BBSSignature{} }
+func (m *BBSSignature) String() string            { return proto1.CompactTextString(m) }
+func (*BBSSignature) ProtoMessage()               {}
+func (*BBSSignature) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{57} }
+
+func (m *BBSSignature) GetA() []byte {
+	if m != nil {
+		return m.A
+	}
+	return nil
+}
+
+func (m *BBSSignature) GetE() []byte {
+	if m != nil {
+		return m.E
+	}
+	return nil
+}
+
+func (m *BBSSignature) GetS() []byte {
+	if m != nil {
+		return m.S
+	}
+	return nil
+}
+
+type BBSProof struct {
+	APrime       []byte   `protobuf:"bytes,1,opt,name=APrime,proto3" json:"APrime,omitempty"`
+	ABar         []byte   `protobuf:"bytes,2,opt,name=ABar,proto3" json:"ABar,omitempty"`
+	D            []byte   `protobuf:"bytes,3,opt,name=D,proto3" json:"D,omitempty"`
+	Challenge    []byte   `protobuf:"bytes,4,opt,name=Challenge,proto3" json:"Challenge,omitempty"`
+	EResponse    []byte   `protobuf:"bytes,5,opt,name=EResponse,proto3" json:"EResponse,omitempty"`
+	R2Response   []byte   `protobuf:"bytes,6,opt,name=R2Response,proto3" json:"R2Response,omitempty"`
+	R3Response   []byte   `protobuf:"bytes,7,opt,name=R3Response,proto3" json:"R3Response,omitempty"`
+	SResponse    []byte   `protobuf:"bytes,8,opt,name=SResponse,proto3" json:"SResponse,omitempty"`
+	MsgResponses [][]byte `protobuf:"bytes,9,rep,name=MsgResponses,proto3" json:"MsgResponses,omitempty"`
+	Revealed     []int32  `protobuf:"varint,10,rep,packed,name=Revealed" json:"Revealed,omitempty"`
+	RevealedMsgs [][]byte `protobuf:"bytes,11,rep,name=RevealedMsgs,proto3" json:"RevealedMsgs,omitempty"`
+}
+
+func (m *BBSProof) Reset()                    { *m = BBSProof{} }
+func (m *BBSProof) String() string            { return proto1.CompactTextString(m) }
+func (*BBSProof) ProtoMessage()               {}
+func (*BBSProof) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{58} }
+
+func (m *BBSProof) GetAPrime() []byte {
+	if m != nil {
+		return m.APrime
+	}
+	return nil
+}
+
+func (m *BBSProof) GetABar() []byte {
+	if m != nil {
+		return m.ABar
+	}
+	return nil
+}
+
+func (m *BBSProof) GetD() []byte {
+	if m != nil {
+		return m.D
+	}
+	return nil
+}
+
+func (m *BBSProof) GetChallenge() []byte {
+	if m != nil {
+		return m.Challenge
+	}
+	return nil
+}
+
+func (m *BBSProof) GetEResponse() []byte {
+	if m != nil {
+		return m.EResponse
+	}
+	return nil
+}
+
+func (m *BBSProof) GetR2Response() []byte {
+	if m != nil {
+		return m.R2Response
+	}
+	return nil
+}
+
+func (m *BBSProof) GetR3Response() []byte {
+	if m != nil {
+		return m.R3Response
+	}
+	return nil
+}
+
+func (m *BBSProof) GetSResponse() []byte {
+	if m != nil {
+		return m.SResponse
+	}
+	return nil
+}
+
+func (m *BBSProof) GetMsgResponses() [][]byte {
+	if m != nil {
+		return m.MsgResponses
+	}
+	return nil
+}
+
+func (m *BBSProof) GetRevealed() []int32 {
+	if m != nil {
+		return m.Revealed
+	}
+	return nil
+}
+
+func (m *BBSProof) GetRevealedMsgs() [][]byte {
+	if m != nil {
+		return m.RevealedMsgs
+	}
+	return nil
+}
+
+type UpdateCLCredential struct {
+	Nym           []byte   `protobuf:"bytes,1,opt,name=Nym,proto3" json:"Nym,omitempty"`
+	Nonce         []byte   `protobuf:"bytes,2,opt,name=Nonce,proto3" json:"Nonce,omitempty"`
+	NewKnownAttrs [][]byte `protobuf:"bytes,3,rep,name=NewKnownAttrs,proto3" json:"NewKnownAttrs,omitempty"`
+	// CommitmentsOfAttrs are new commitments of committed attributes, along with proofs
+	// that the receiver can open them; committed attributes are not updated when empty
+	CommitmentsOfAttrs       [][]byte      `protobuf:"bytes,4,rep,name=CommitmentsOfAttrs,proto3" json:"CommitmentsOfAttrs,omitempty"`
+	CommitmentsOfAttrsProofs []*FiatShamir `protobuf:"bytes,5,rep,name=CommitmentsOfAttrsProofs" json:"CommitmentsOfAttrsProofs,omitempty"`
+}
+
+func (m *UpdateCLCredential) Reset()                    { *m = UpdateCLCredential{} }
+func (m *UpdateCLCredential) String() string            { return proto1.CompactTextString(m) }
+func (*UpdateCLCredential) ProtoMessage()               {}
+func (*UpdateCLCredential) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{59} }
+
+func (m *UpdateCLCredential) GetNym() []byte {
+	if m != nil {
+		return m.Nym
+	}
+	return nil
+}
+
+func (m *UpdateCLCredential) GetNonce() []byte {
+	if m != nil {
+		return m.Nonce
+	}
+	return nil
+}
+
+func (m *UpdateCLCredential) GetNewKnownAttrs() [][]byte {
+	if m != nil {
+		return m.NewKnownAttrs
+	}
+	return nil
+}
+
+func (m *UpdateCLCredential) GetCommitmentsOfAttrs() [][]byte {
+	if m != nil {
+		return m.CommitmentsOfAttrs
+	}
+	return nil
+}
+
+func (m *UpdateCLCredential) GetCommitmentsOfAttrsProofs() []*FiatShamir {
+	if m != nil {
+		return m.CommitmentsOfAttrsProofs
+	}
+	return nil
+}
+
+type ProveCLCredential struct {
+	A                          []byte              `protobuf:"bytes,1,opt,name=A,proto3" json:"A,omitempty"`
+	Proof                      *FiatShamirAlsoNeg  `protobuf:"bytes,2,opt,name=Proof" json:"Proof,omitempty"`
+	KnownAttrs                 [][]byte            `protobuf:"bytes,3,rep,name=KnownAttrs,proto3" json:"KnownAttrs,omitempty"`
+	CommitmentsOfAttrs         [][]byte            `protobuf:"bytes,4,rep,name=CommitmentsOfAttrs,proto3" json:"CommitmentsOfAttrs,omitempty"`
+	RevealedKnownAttrs         []int32             `protobuf:"varint,5,rep,packed,name=RevealedKnownAttrs" json:"RevealedKnownAttrs,omitempty"`
+	RevealedCommitmentsOfAttrs []int32             `protobuf:"varint,6,rep,packed,name=RevealedCommitmentsOfAttrs" json:"RevealedCommitmentsOfAttrs,omitempty"`
+	DeviceAssertion            *WebAuthnAssertion  `protobuf:"bytes,7,opt,name=DeviceAssertion" json:"DeviceAssertion,omitempty"`
+	NonRevocationProof         *NonRevocationProof `protobuf:"bytes,8,opt,name=NonRevocationProof" json:"NonRevocationProof,omitempty"`
+	RangeProofs                []*CLRangeProof     `protobuf:"bytes,9,rep,name=RangeProofs" json:"RangeProofs,omitempty"`
+	Predicates                 []*CLPredicate      `protobuf:"bytes,10,rep,name=Predicates" json:"Predicates,omitempty"`
+	// KeyID selects the public key of the issuer the proof is verified with; the
+	// current key is used when empty
+	KeyID string `protobuf:"bytes,11,opt,name=KeyID" json:"KeyID,omitempty"`
+}
+
+func (m *ProveCLCredential) Reset()                    { *m = ProveCLCredential{} }
+func (m *ProveCLCredential) String() string            { return proto1.CompactTextString(m) }
+func (*ProveCLCredential) ProtoMessage()               {}
+func (*ProveCLCredential) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{60} }
+
+func (m *ProveCLCredential) GetA() []byte {
+	if m != nil {
+		return m.A
+	}
+	return nil
+}
+
+func (m *ProveCLCredential) GetProof() *FiatShamirAlsoNeg {
+	if m != nil {
+		return m.Proof
+	}
+	return nil
+}
+
+func (m *ProveCLCredential) GetKnownAttrs() [][]byte {
+	if m != nil {
+		return m.KnownAttrs
+	}
+	return nil
+}
+
+func (m *ProveCLCredential) GetCommitmentsOfAttrs() [][]byte {
+	if m != nil {
+		return m.CommitmentsOfAttrs
+	}
+	return nil
+}
+
+func (m *ProveCLCredential) GetRevealedKnownAttrs() []int32 {
+	if m != nil {
+		return m.RevealedKnownAttrs
+	}
+	return nil
+}
+
+func (m *ProveCLCredential) GetRevealedCommitmentsOfAttrs() []int32 {
+	if m != nil {
+		return m.RevealedCommitmentsOfAttrs
+	}
+	return nil
+}
+
+func (m *ProveCLCredential) GetDeviceAssertion() *WebAuthnAssertion {
+	if m != nil {
+		return m.DeviceAssertion
+	}
+	return nil
+}
+
+func (m *ProveCLCredential) GetNonRevocationProof() *NonRevocationProof {
+	if m != nil {
+		return m.NonRevocationProof
+	}
+	return nil
+}
+
+func (m *ProveCLCredential) GetRangeProofs() []*CLRangeProof {
+	if m != nil {
+		return m.RangeProofs
+	}
+	return nil
+}
+
+func (m *ProveCLCredential) GetPredicates() []*CLPredicate {
+	if m != nil {
+		return m.Predicates
+	}
+	return nil
+}
+
+func (m *ProveCLCredential) GetKeyID() string {
+	if m != nil {
+		return m.KeyID
+	}
+	return ""
+}
+
+// ProveCLCredentialNI holds a non-interactive proof of a CL credential, built with the
+// nonce derived from Context (see cl.ContextNonce) instead of one issued by the server.
+type ProveCLCredentialNI struct {
+	Proof   *ProveCLCredential `protobuf:"bytes,1,opt,name=Proof" json:"Proof,omitempty"`
+	Context *NIContext         `protobuf:"bytes,2,opt,name=Context" json:"Context,omitempty"`
+}
+
+func (m *ProveCLCredentialNI) Reset()                    { *m = ProveCLCredentialNI{} }
+func (m *ProveCLCredentialNI) String() string            { return proto1.CompactTextString(m) }
+func (*ProveCLCredentialNI) ProtoMessage()               {}
+func (*ProveCLCredentialNI) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{61} }
+
+func (m *ProveCLCredentialNI) GetProof() *ProveCLCredential {
+	if m != nil {
+		return m.Proof
+	}
+	return nil
+}
+
+func (m *ProveCLCredentialNI) GetContext() *NIContext {
+	if m != nil {
+		return m.Context
+	}
+	return nil
+}
+
+// NIContext is the context a non-interactive proof is bound to: the time the proof
+// was created (in seconds since the Unix epoch) and a random nonce of the prover.
+type NIContext struct {
+	Timestamp int64  `protobuf:"varint,1,opt,name=Timestamp" json:"Timestamp,omitempty"`
+	Nonce     []byte `protobuf:"bytes,2,opt,name=Nonce,proto3" json:"Nonce,omitempty"`
+	// identity of the verifier the proof is meant for, when it is bound to one
+	Verifier string `protobuf:"bytes,3,opt,name=Verifier" json:"Verifier,omitempty"`
+}
+
+func (m *NIContext) Reset()                    { *m = NIContext{} }
+func (m *NIContext) String() string            { return proto1.CompactTextString(m) }
+func (*NIContext) ProtoMessage()               {}
+func (*NIContext) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{62} }
+
+func (m *NIContext) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *NIContext) GetNonce() []byte {
+	if m != nil {
+		return m.Nonce
+	}
+	return nil
+}
+
+func (m *NIContext) GetVerifier() string {
+	if m != nil {
+		return m.Verifier
+	}
+	return ""
+}
+
+// ProtocolStep carries a message of a protocol run over unary RPCs of service Steps.
+// Method is the name of the protocol (for example IssueCredential), and Session ties
+// the steps of the same run together. Done is set in the response that ends the run.
+type ProtocolStep struct {
+	Method  string   `protobuf:"bytes,1,opt,name=Method" json:"Method,omitempty"`
+	Session string   `protobuf:"bytes,2,opt,name=Session" json:"Session,omitempty"`
+	Message *Message `protobuf:"bytes,3,opt,name=Message" json:"Message,omitempty"`
+	Done    bool     `protobuf:"varint,4,opt,name=Done" json:"Done,omitempty"`
+}
+
+func (m *ProtocolStep) Reset()                    { *m = ProtocolStep{} }
+func (m *ProtocolStep) String() string            { return proto1.CompactTextString(m) }
+func (*ProtocolStep) ProtoMessage()               {}
+func (*ProtocolStep) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{63} }
+
+func (m *ProtocolStep) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *ProtocolStep) GetSession() string {
+	if m != nil {
+		return m.Session
+	}
+	return ""
+}
+
+func (m *ProtocolStep) GetMessage() *Message {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+func (m *ProtocolStep) GetDone() bool {
+	if m != nil {
+		return m.Done
+	}
+	return false
+}
+
+// CLPredicate is a predicate about an attribute, shown by the proof of a credential
+// (see cl.Predicate). Values are internal values of the attribute in decimal.
+type CLPredicate struct {
+	Type   string   `protobuf:"bytes,1,opt,name=Type" json:"Type,omitempty"`
+	Attr   string   `protobuf:"bytes,2,opt,name=Attr" json:"Attr,omitempty"`
+	Values []string `protobuf:"bytes,3,rep,name=Values" json:"Values,omitempty"`
+}
+
+func (m *CLPredicate) Reset()                    { *m = CLPredicate{} }
+func (m *CLPredicate) String() string            { return proto1.CompactTextString(m) }
+func (*CLPredicate) ProtoMessage()               {}
+func (*CLPredicate) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{64} }
+
+func (m *CLPredicate) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *CLPredicate) GetAttr() string {
+	if m != nil {
+		return m.Attr
+	}
+	return ""
+}
+
+func (m *CLPredicate) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+// CLRangeProof is a proof that a committed attribute (with index Index among committed
+// attributes) lies in [A, B]. Values are in decimal, as they can be negative.
+type CLRangeProof struct {
+	Index             int32    `protobuf:"varint,1,opt,name=Index" json:"Index,omitempty"`
+	A                 string   `protobuf:"bytes,2,opt,name=A" json:"A,omitempty"`
+	B                 string   `protobuf:"bytes,3,opt,name=B" json:"B,omitempty"`
+	ProofRandomData1  []string `protobuf:"bytes,4,rep,name=ProofRandomData1" json:"ProofRandomData1,omitempty"`
+	ProofRandomData2  []string `protobuf:"bytes,5,rep,name=ProofRandomData2" json:"ProofRandomData2,omitempty"`
+	Challenges1       []string `protobuf:"bytes,6,rep,name=Challenges1" json:"Challenges1,omitempty"`
+	Challenges2       []string `protobuf:"bytes,7,rep,name=Challenges2" json:"Challenges2,omitempty"`
+	ProofData1        []string `protobuf:"bytes,8,rep,name=ProofData1" json:"ProofData1,omitempty"`
+	ProofData2        []string `protobuf:"bytes,9,rep,name=ProofData2" json:"ProofData2,omitempty"`
+	SmallCommitments1 []string `protobuf:"bytes,10,rep,name=SmallCommitments1" json:"SmallCommitments1,omitempty"`
+	BigCommitments1   []string `protobuf:"bytes,11,rep,name=BigCommitments1" json:"BigCommitments1,omitempty"`
+	SmallCommitments2 []string `protobuf:"bytes,12,rep,name=SmallCommitments2" json:"SmallCommitments2,omitempty"`
+	BigCommitments2   []string `protobuf:"bytes,13,rep,name=BigCommitments2" json:"BigCommitments2,omitempty"`
+	// range proof with Bulletproofs, in place of the fields above
+	Bulletproof *CLBulletproof `protobuf:"bytes,14,opt,name=Bulletproof" json:"Bulletproof,omitempty"`
+}
+
+func (m *CLRangeProof) Reset()                    { *m = CLRangeProof{} }
+func (m *CLRangeProof) String() string            { return proto1.CompactTextString(m) }
+func (*CLRangeProof) ProtoMessage()               {}
+func (*CLRangeProof) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{65} }
+
+func (m *CLRangeProof) GetIndex() int32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *CLRangeProof) GetA() string {
+	if m != nil {
+		return m.A
+	}
+	return ""
+}
+
+func (m *CLRangeProof) GetB() string {
+	if m != nil {
+		return m.B
+	}
+	return ""
+}
+
+func (m *CLRangeProof) GetProofRandomData1() []string {
+	if m != nil {
+		return m.ProofRandomData1
+	}
+	return nil
+}
+
+func (m *CLRangeProof) GetProofRandomData2() []string {
+	if m != nil {
+		return m.ProofRandomData2
+	}
+	return nil
+}
+
+func (m *CLRangeProof) GetChallenges1() []string {
+	if m != nil {
+		return m.Challenges1
+	}
+	return nil
+}
+
+func (m *CLRangeProof) GetChallenges2() []string {
+	if m != nil {
+		return m.Challenges2
+	}
+	return nil
+}
+
+func (m *CLRangeProof) GetProofData1() []string {
+	if m != nil {
+		return m.ProofData1
+	}
+	return nil
+}
+
+func (m *CLRangeProof) GetProofData2() []string {
+	if m != nil {
+		return m.ProofData2
+	}
+	return nil
+}
+
+func (m *CLRangeProof) GetSmallCommitments1() []string {
+	if m != nil {
+		return m.SmallCommitments1
+	}
+	return nil
+}
+
+func (m *CLRangeProof) GetBigCommitments1() []string {
+	if m != nil {
+		return m.BigCommitments1
+	}
+	return nil
+}
+
+func (m *CLRangeProof) GetSmallCommitments2() []string {
+	if m != nil {
+		return m.SmallCommitments2
+	}
+	return nil
+}
+
+func (m *CLRangeProof) GetBigCommitments2() []string {
+	if m != nil {
+		return m.BigCommitments2
+	}
+	return nil
+}
+
+func (m *CLRangeProof) GetBulletproof() *CLBulletproof {
+	if m != nil {
+		return m.Bulletproof
+	}
+	return nil
+}
+
+type Accumulator struct {
+	N       []byte `protobuf:"bytes,1,opt,name=N,proto3" json:"N,omitempty"`
+	G       []byte `protobuf:"bytes,2,opt,name=G,proto3" json:"G,omitempty"`
+	H       []byte `protobuf:"bytes,3,opt,name=H,proto3" json:"H,omitempty"`
+	V       []byte `protobuf:"bytes,4,opt,name=V,proto3" json:"V,omitempty"`
+	Version int32  `protobuf:"varint,5,opt,name=Version" json:"Version,omitempty"`
+}
+
+func (m *Accumulator) Reset()                    { *m = Accumulator{} }
+func (m *Accumulator) String() string            { return proto1.CompactTextString(m) }
+func (*Accumulator) ProtoMessage()               {}
+func (*Accumulator) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{66} }
+
+func (m *Accumulator) GetN() []byte {
+	if m != nil {
+		return m.N
+	}
+	return nil
+}
+
+func (m *Accumulator) GetG() []byte {
+	if m != nil {
+		return m.G
+	}
+	return nil
+}
+
+func (m *Accumulator) GetH() []byte {
+	if m != nil {
+		return m.H
+	}
+	return nil
+}
+
+func (m *Accumulator) GetV() []byte {
+	if m != nil {
+		return m.V
+	}
+	return nil
+}
+
+func (m *Accumulator) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type AccumulatorVersion struct {
+	Version int32 `protobuf:"varint,1,opt,name=Version" json:"Version,omitempty"`
+}
+
+func (m *AccumulatorVersion) Reset()                    { *m = AccumulatorVersion{} }
+func (m *AccumulatorVersion) String() string            { return proto1.CompactTextString(m) }
+func (*AccumulatorVersion) ProtoMessage()               {}
+func (*AccumulatorVersion) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{67} }
+
+func (m *AccumulatorVersion) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type AccumulatorUpdate struct {
+	Accumulator *Accumulator `protobuf:"bytes,1,opt,name=Accumulator" json:"Accumulator,omitempty"`
+	Revoked     [][]byte     `protobuf:"bytes,2,rep,name=Revoked,proto3" json:"Revoked,omitempty"`
+}
+
+func (m *AccumulatorUpdate) Reset()                    { *m = AccumulatorUpdate{} }
+func (m *AccumulatorUpdate) String() string            { return proto1.CompactTextString(m) }
+func (*AccumulatorUpdate) ProtoMessage()               {}
+func (*AccumulatorUpdate) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{68} }
+
+func (m *AccumulatorUpdate) GetAccumulator() *Accumulator {
+	if m != nil {
+		return m.Accumulator
+	}
+	return nil
+}
+
+func (m *AccumulatorUpdate) GetRevoked() [][]byte {
+	if m != nil {
+		return m.Revoked
+	}
+	return nil
+}
+
+type NonRevocationWitness struct {
+	W       []byte `protobuf:"bytes,1,opt,name=W,proto3" json:"W,omitempty"`
+	Version int32  `protobuf:"varint,2,opt,name=Version" json:"Version,omitempty"`
+}
+
+func (m *NonRevocationWitness) Reset()                    { *m = NonRevocationWitness{} }
+func (m *NonRevocationWitness) String() string            { return proto1.CompactTextString(m) }
+func (*NonRevocationWitness) ProtoMessage()               {}
+func (*NonRevocationWitness) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{69} }
+
+func (m *NonRevocationWitness) GetW() []byte {
+	if m != nil {
+		return m.W
+	}
+	return nil
+}
+
+func (m *NonRevocationWitness) GetVersion() int32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type NonRevocationProof struct {
+	CU        []byte   `protobuf:"bytes,1,opt,name=CU,proto3" json:"CU,omitempty"`
+	CR        []byte   `protobuf:"bytes,2,opt,name=CR,proto3" json:"CR,omitempty"`
+	Challenge []byte   `protobuf:"bytes,3,opt,name=Challenge,proto3" json:"Challenge,omitempty"`
+	ProofData []string `protobuf:"bytes,4,rep,name=ProofData" json:"ProofData,omitempty"`
+}
+
+func (m *NonRevocationProof) Reset()                    { *m = NonRevocationProof{} }
+func (m *NonRevocationProof) String() string            { return proto1.CompactTextString(m) }
+func (*NonRevocationProof) ProtoMessage()               {}
+func (*NonRevocationProof) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{70} }
+
+func (m *NonRevocationProof) GetCU() []byte {
+	if m != nil {
+		return m.CU
+	}
+	return nil
+}
+
+func (m *NonRevocationProof) GetCR() []byte {
+	if m != nil {
+		return m.CR
+	}
+	return nil
+}
+
+func (m *NonRevocationProof) GetChallenge() []byte {
+	if m != nil {
+		return m.Challenge
+	}
+	return nil
+}
+
+func (m *NonRevocationProof) GetProofData() []string {
+	if m != nil {
+		return m.ProofData
+	}
+	return nil
+}
+
+type WebAuthnRegistration struct {
+	CredentialID      []byte `protobuf:"bytes,1,opt,name=CredentialID,proto3" json:"CredentialID,omitempty"`
+	AttestationObject []byte `protobuf:"bytes,2,opt,name=AttestationObject,proto3" json:"AttestationObject,omitempty"`
+	ClientDataJSON    []byte `protobuf:"bytes,3,opt,name=ClientDataJSON,proto3" json:"ClientDataJSON,omitempty"`
+}
+
+func (m *WebAuthnRegistration) Reset()                    { *m = WebAuthnRegistration{} }
+func (m *WebAuthnRegistration) String() string            { return proto1.CompactTextString(m) }
+func (*WebAuthnRegistration) ProtoMessage()               {}
+func (*WebAuthnRegistration) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{71} }
+
+func (m *WebAuthnRegistration) GetCredentialID() []byte {
+	if m != nil {
+		return m.CredentialID
+	}
+	return nil
+}
+
+func (m *WebAuthnRegistration) GetAttestationObject() []byte {
+	if m != nil {
+		return m.AttestationObject
+	}
+	return nil
+}
+
+func (m *WebAuthnRegistration) GetClientDataJSON() []byte {
+	if m != nil {
+		return m.ClientDataJSON
+	}
+	return nil
+}
+
+type WebAuthnAssertion struct {
+	CredentialID      []byte `protobuf:"bytes,1,opt,name=CredentialID,proto3" json:"CredentialID,omitempty"`
+	AuthenticatorData []byte `protobuf:"bytes,2,opt,name=AuthenticatorData,proto3" json:"AuthenticatorData,omitempty"`
+	ClientDataJSON    []byte `protobuf:"bytes,3,opt,name=ClientDataJSON,proto3" json:"ClientDataJSON,omitempty"`
+	Signature         []byte `protobuf:"bytes,4,opt,name=Signature,proto3" json:"Signature,omitempty"`
+}
+
+func (m *WebAuthnAssertion) Reset()                    { *m = WebAuthnAssertion{} }
+func (m *WebAuthnAssertion) String() string            { return proto1.CompactTextString(m) }
+func (*WebAuthnAssertion) ProtoMessage()               {}
+func (*WebAuthnAssertion) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{72} }
+
+func (m *WebAuthnAssertion) GetCredentialID() []byte {
+	if m != nil {
+		return m.CredentialID
+	}
+	return nil
+}
+
+func (m *WebAuthnAssertion) GetAuthenticatorData() []byte {
+	if m != nil {
+		return m.AuthenticatorData
+	}
+	return nil
+}
+
+func (m *WebAuthnAssertion) GetClientDataJSON() []byte {
+	if m != nil {
+		return m.ClientDataJSON
+	}
+	return nil
+}
+
+func (m *WebAuthnAssertion) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// CLPubKey is a CL public key of the issuer in PEM form, along with its ID. Until is
+// the Unix time in seconds after which proofs built with the key are no longer
+// accepted, or 0 for the current key.
+type CLPubKey struct {
+	KeyID  string `protobuf:"bytes,1,opt,name=KeyID" json:"KeyID,omitempty"`
+	PubKey []byte `protobuf:"bytes,2,opt,name=PubKey,proto3" json:"PubKey,omitempty"`
+	Until  int64  `protobuf:"varint,3,opt,name=Until" json:"Until,omitempty"`
+}
+
+func (m *CLPubKey) Reset()                    { *m = CLPubKey{} }
+func (m *CLPubKey) String() string            { return proto1.CompactTextString(m) }
+func (*CLPubKey) ProtoMessage()               {}
+func (*CLPubKey) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{73} }
+
+func (m *CLPubKey) GetKeyID() string {
+	if m != nil {
+		return m.KeyID
+	}
+	return ""
+}
+
+func (m *CLPubKey) GetPubKey() []byte {
+	if m != nil {
+		return m.PubKey
+	}
+	return nil
+}
+
+func (m *CLPubKey) GetUntil() int64 {
+	if m != nil {
+		return m.Until
+	}
+	return 0
+}
+
+// CLParams are the parameters of CL credentials of the issuer (see cl.Params). Preset
+// names the preset they were taken from (see cl.GetParamsPreset).
+type CLParams struct {
+	Preset            string `protobuf:"bytes,1,opt,name=Preset" json:"Preset,omitempty"`
+	RhoBitLen         int32  `protobuf:"varint,2,opt,name=RhoBitLen" json:"RhoBitLen,omitempty"`
+	NLength           int32  `protobuf:"varint,3,opt,name=NLength" json:"NLength,omitempty"`
+	KnownAttrsNum     int32  `protobuf:"varint,4,opt,name=KnownAttrsNum" json:"KnownAttrsNum,omitempty"`
+	CommittedAttrsNum int32  `protobuf:"varint,5,opt,name=CommittedAttrsNum" json:"CommittedAttrsNum,omitempty"`
+	HiddenAttrsNum    int32  `protobuf:"varint,6,opt,name=HiddenAttrsNum" json:"HiddenAttrsNum,omitempty"`
+	AttrBitLen        int32  `protobuf:"varint,7,opt,name=AttrBitLen" json:"AttrBitLen,omitempty"`
+	HashBitLen        int32  `protobuf:"varint,8,opt,name=HashBitLen" json:"HashBitLen,omitempty"`
+	SecParam          int32  `protobuf:"varint,9,opt,name=SecParam" json:"SecParam,omitempty"`
+	EBitLen           int32  `protobuf:"varint,10,opt,name=EBitLen" json:"EBitLen,omitempty"`
+	E1BitLen          int32  `protobuf:"varint,11,opt,name=E1BitLen" json:"E1BitLen,omitempty"`
+	VBitLen           int32  `protobuf:"varint,12,opt,name=VBitLen" json:"VBitLen,omitempty"`
+	ChallengeSpace    int32  `protobuf:"varint,13,opt,name=ChallengeSpace" json:"ChallengeSpace,omitempty"`
+	CommitmentScheme  string `protobuf:"bytes,14,opt,name=CommitmentScheme" json:"CommitmentScheme,omitempty"`
+}
+
+func (m *CLParams) Reset()                    { *m = CLParams{} }
+func (m *CLParams) String() string            { return proto1.CompactTextString(m) }
+func (*CLParams) ProtoMessage()               {}
+func (*CLParams) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{74} }
+
+func (m *CLParams) GetPreset() string {
+	if m != nil {
+		return m.Preset
+	}
+	return ""
+}
+
+func (m *CLParams) GetRhoBitLen() int32 {
+	if m != nil {
+		return m.RhoBitLen
+	}
+	return 0
+}
+
+func (m *CLParams) GetNLength() int32 {
+	if m != nil {
+		return m.NLength
+	}
+	return 0
+}
+
+func (m *CLParams) GetKnownAttrsNum() int32 {
+	if m != nil {
+		return m.KnownAttrsNum
+	}
+	return 0
+}
+
+func (m *CLParams) GetCommittedAttrsNum() int32 {
+	if m != nil {
+		return m.CommittedAttrsNum
+	}
+	return 0
+}
+
+func (m *CLParams) GetHiddenAttrsNum() int32 {
+	if m != nil {
+		return m.HiddenAttrsNum
+	}
+	return 0
+}
+
+func (m *CLParams) GetAttrBitLen() int32 {
+	if m != nil {
+		return m.AttrBitLen
+	}
+	return 0
+}
+
+func (m *CLParams) GetHashBitLen() int32 {
+	if m != nil {
+		return m.HashBitLen
+	}
+	return 0
+}
+
+func (m *CLParams) GetSecParam() int32 {
+	if m != nil {
+		return m.SecParam
+	}
+	return 0
+}
+
+func (m *CLParams) GetEBitLen() int32 {
+	if m != nil {
+		return m.EBitLen
+	}
+	return 0
+}
+
+func (m *CLParams) GetE1BitLen() int32 {
+	if m != nil {
+		return m.E1BitLen
+	}
+	return 0
+}
+
+func (m *CLParams) GetVBitLen() int32 {
+	if m != nil {
+		return m.VBitLen
+	}
+	return 0
+}
+
+func (m *CLParams) GetChallengeSpace() int32 {
+	if m != nil {
+		return m.ChallengeSpace
+	}
+	return 0
+}
+
+func (m *CLParams) GetCommitmentScheme() string {
+	if m != nil {
+		return m.CommitmentScheme
+	}
+	return ""
+}
+
+// PublicParameters hold everything clients need to obtain and verify credentials of
+// the issuer. Accumulator is only set when revocation is enabled, and IssuedAt is the
+// Unix time in seconds when the parameters were assembled.
+type PublicParameters struct {
+	ClPubKeys   []*CLPubKey      `protobuf:"bytes,1,rep,name=clPubKeys" json:"clPubKeys,omitempty"`
+	ClParams    *CLParams        `protobuf:"bytes,2,opt,name=clParams" json:"clParams,omitempty"`
+	Accumulator *Accumulator     `protobuf:"bytes,3,opt,name=accumulator" json:"accumulator,omitempty"`
+	Schemas     []*CredStructure `protobuf:"bytes,4,rep,name=schemas" json:"schemas,omitempty"`
+	IssuedAt    int64            `protobuf:"varint,5,opt,name=IssuedAt" json:"IssuedAt,omitempty"`
+}
+
+func (m *PublicParameters) Reset()                    { *m = PublicParameters{} }
+func (m *PublicParameters) String() string            { return proto1.CompactTextString(m) }
+func (*PublicParameters) ProtoMessage()               {}
+func (*PublicParameters) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{75} }
+
+func (m *PublicParameters) GetClPubKeys() []*CLPubKey {
+	if m != nil {
+		return m.ClPubKeys
+	}
+	return nil
+}
+
+func (m *PublicParameters) GetClParams() *CLParams {
+	if m != nil {
+		return m.ClParams
+	}
+	return nil
+}
+
+func (m *PublicParameters) GetAccumulator() *Accumulator {
+	if m != nil {
+		return m.Accumulator
+	}
+	return nil
+}
+
+func (m *PublicParameters) GetSchemas() []*CredStructure {
+	if m != nil {
+		return m.Schemas
+	}
+	return nil
+}
+
+func (m *PublicParameters) GetIssuedAt() int64 {
+	if m != nil {
+		return m.IssuedAt
+	}
+	return 0
+}
+
+// SignedPublicParameters carry serialized PublicParameters along with an ECDSA P-256
+// signature over their SHA-256 hash, made with the key with thumbprint KeyID (see
+// jose.JWK.Thumbprint). Signature is a concatenation of r and s, each padded to 32
+// bytes.
+type SignedPublicParameters struct {
+	Parameters []byte `protobuf:"bytes,1,opt,name=Parameters,proto3" json:"Parameters,omitempty"`
+	Signature  []byte `protobuf:"bytes,2,opt,name=Signature,proto3" json:"Signature,omitempty"`
+	KeyID      string `protobuf:"bytes,3,opt,name=KeyID" json:"KeyID,omitempty"`
+}
+
+func (m *SignedPublicParameters) Reset()                    { *m = SignedPublicParameters{} }
+func (m *SignedPublicParameters) String() string            { return proto1.CompactTextString(m) }
+func (*SignedPublicParameters) ProtoMessage()               {}
+func (*SignedPublicParameters) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{76} }
+
+func (m *SignedPublicParameters) GetParameters() []byte {
+	if m != nil {
+		return m.Parameters
+	}
+	return nil
+}
+
+func (m *SignedPublicParameters) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *SignedPublicParameters) GetKeyID() string {
+	if m != nil {
+		return m.KeyID
+	}
+	return ""
+}
+
+// FiatShamirEC is a non-interactive proof of knowledge of a discrete logarithm in EC
+// group, like FiatShamir.
+type FiatShamirEC struct {
+	ProofRandomData *ECGroupElement `protobuf:"bytes,1,opt,name=ProofRandomData" json:"ProofRandomData,omitempty"`
+	Challenge       []byte          `protobuf:"bytes,2,opt,name=Challenge,proto3" json:"Challenge,omitempty"`
+	ProofData       []byte          `protobuf:"bytes,3,opt,name=ProofData,proto3" json:"ProofData,omitempty"`
+}
+
+func (m *FiatShamirEC) Reset()                    { *m = FiatShamirEC{} }
+func (m *FiatShamirEC) String() string            { return proto1.CompactTextString(m) }
+func (*FiatShamirEC) ProtoMessage()               {}
+func (*FiatShamirEC) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{77} }
+
+func (m *FiatShamirEC) GetProofRandomData() *ECGroupElement {
+	if m != nil {
+		return m.ProofRandomData
+	}
+	return nil
+}
+
+func (m *FiatShamirEC) GetChallenge() []byte {
+	if m != nil {
+		return m.Challenge
+	}
+	return nil
+}
+
+func (m *FiatShamirEC) GetProofData() []byte {
+	if m != nil {
+		return m.ProofData
+	}
+	return nil
+}
+
+// PseudonymsysChainLink is a credential of the pseudonym system that another
+// credential is derived from, along with the organization that issued it.
+type PseudonymsysChainLink struct {
+	Issuer     string                  `protobuf:"bytes,1,opt,name=Issuer" json:"Issuer,omitempty"`
+	Credential *PseudonymsysCredential `protobuf:"bytes,2,opt,name=Credential" json:"Credential,omitempty"`
+	// proof random data of the proof that the credential was issued to the secret of the nym
+	X []byte `protobuf:"bytes,3,opt,name=X,proto3" json:"X,omitempty"`
+}
+
+func (m *PseudonymsysChainLink) Reset()                    { *m = PseudonymsysChainLink{} }
+func (m *PseudonymsysChainLink) String() string            { return proto1.CompactTextString(m) }
+func (*PseudonymsysChainLink) ProtoMessage()               {}
+func (*PseudonymsysChainLink) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{78} }
+
+func (m *PseudonymsysChainLink) GetIssuer() string {
+	if m != nil {
+		return m.Issuer
+	}
+	return ""
+}
+
+func (m *PseudonymsysChainLink) GetCredential() *PseudonymsysCredential {
+	if m != nil {
+		return m.Credential
+	}
+	return nil
+}
+
+func (m *PseudonymsysChainLink) GetX() []byte {
+	if m != nil {
+		return m.X
+	}
+	return nil
+}
+
+// PseudonymsysCACertificateRequestNI requests a certificate of the master nym (A, B)
+// from the CA, with a non-interactive proof of knowledge of log_A(B) bound to Context.
+type PseudonymsysCACertificateRequestNI struct {
+	A       []byte      `protobuf:"bytes,1,opt,name=A,proto3" json:"A,omitempty"`
+	B       []byte      `protobuf:"bytes,2,opt,name=B,proto3" json:"B,omitempty"`
+	Proof   *FiatShamir `protobuf:"bytes,3,opt,name=Proof" json:"Proof,omitempty"`
+	Context *NIContext  `protobuf:"bytes,4,opt,name=Context" json:"Context,omitempty"`
+}
+
+func (m *PseudonymsysCACertificateRequestNI) Reset() {
+	*m = PseudonymsysCACertificateRequestNI{}
+}
+func (m *PseudonymsysCACertificateRequestNI) String() string { return proto1.CompactTextString(m) }
+func (*PseudonymsysCACertificateRequestNI) ProtoMessage()    {}
+func (*PseudonymsysCACertificateRequestNI) Descriptor() ([]byte, []int) {
+	return fileDescriptor0, []int{79}
+}
+
+func (m *PseudonymsysCACertificateRequestNI) GetA() []byte {
+	if m != nil {
+		return m.A
+	}
+	return nil
+}
+
+func (m *PseudonymsysCACertificateRequestNI) GetB() []byte {
+	if m != nil {
+		return m.B
+	}
+	return nil
+}
+
+func (m *PseudonymsysCACertificateRequestNI) GetProof() *FiatShamir {
+	if m != nil {
+		return m.Proof
+	}
+	return nil
+}
+
+func (m *PseudonymsysCACertificateRequestNI) GetContext() *NIContext {
+	if m != nil {
+		return m.Context
+	}
+	return nil
+}
+
+// SessionStatus describes the session of a session key. Claims hold what the server
+// learned about the client (e.g. revealed attributes) as a JSON object. For invalid
+// session keys, Reason tells why they are not valid.
+type SessionStatus struct {
+	Valid     bool   `protobuf:"varint,1,opt,name=Valid" json:"Valid,omitempty"`
+	ExpiresAt int64  `protobuf:"varint,2,opt,name=ExpiresAt" json:"ExpiresAt,omitempty"`
+	AuthTime  int64  `protobuf:"varint,3,opt,name=AuthTime" json:"AuthTime,omitempty"`
+	Claims    []byte `protobuf:"bytes,4,opt,name=Claims,proto3" json:"Claims,omitempty"`
+	Reason    string `protobuf:"bytes,5,opt,name=Reason" json:"Reason,omitempty"`
+}
+
+func (m *SessionStatus) Reset()                    { *m = SessionStatus{} }
+func (m *SessionStatus) String() string            { return proto1.CompactTextString(m) }
+func (*SessionStatus) ProtoMessage()               {}
+func (*SessionStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{80} }
+
+func (m *SessionStatus) GetValid() bool {
+	if m != nil {
+		return m.Valid
+	}
+	return false
+}
+
+func (m *SessionStatus) GetExpiresAt() int64 {
+	if m != nil {
+		return m.ExpiresAt
+	}
+	return 0
+}
+
+func (m *SessionStatus) GetAuthTime() int64 {
+	if m != nil {
+		return m.AuthTime
+	}
+	return 0
+}
+
+func (m *SessionStatus) GetClaims() []byte {
+	if m != nil {
+		return m.Claims
+	}
+	return nil
+}
+
+func (m *SessionStatus) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+// CLBulletproof is a range proof of a committed attribute with Bulletproofs, where
+// elements of the elliptic curve are encoded as integers (see ec.Group.Encode).
+type CLBulletproof struct {
+	A      []byte   `protobuf:"bytes,1,opt,name=A,proto3" json:"A,omitempty"`
+	S      []byte   `protobuf:"bytes,2,opt,name=S,proto3" json:"S,omitempty"`
+	T1     []byte   `protobuf:"bytes,3,opt,name=T1,proto3" json:"T1,omitempty"`
+	T2     []byte   `protobuf:"bytes,4,opt,name=T2,proto3" json:"T2,omitempty"`
+	TauX   []byte   `protobuf:"bytes,5,opt,name=TauX,proto3" json:"TauX,omitempty"`
+	Mu     []byte   `protobuf:"bytes,6,opt,name=Mu,proto3" json:"Mu,omitempty"`
+	T      []byte   `protobuf:"bytes,7,opt,name=T,proto3" json:"T,omitempty"`
+	L      [][]byte `protobuf:"bytes,8,rep,name=L,proto3" json:"L,omitempty"`
+	R      [][]byte `protobuf:"bytes,9,rep,name=R,proto3" json:"R,omitempty"`
+	InnerA []byte   `protobuf:"bytes,10,opt,name=InnerA,proto3" json:"InnerA,omitempty"`
+	InnerB []byte   `protobuf:"bytes,11,opt,name=InnerB,proto3" json:"InnerB,omitempty"`
+	// commitment over the elliptic curve and the proof of its equality with the
+	// commitment of the attribute, for commitments of attributes in QR_N
+	Commitment []byte             `protobuf:"bytes,12,opt,name=Commitment,proto3" json:"Commitment,omitempty"`
+	Equality   *CLDFEqualityProof `protobuf:"bytes,13,opt,name=Equality" json:"Equality,omitempty"`
+}
+
+func (m *CLBulletproof) Reset()                    { *m = CLBulletproof{} }
+func (m *CLBulletproof) String() string            { return proto1.CompactTextString(m) }
+func (*CLBulletproof) ProtoMessage()               {}
+func (*CLBulletproof) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{81} }
+
+func (m *CLBulletproof) GetA() []byte {
+	if m != nil {
+		return m.A
+	}
+	return nil
+}
+
+func (m *CLBulletproof) GetS() []byte {
+	if m != nil {
+		return m.S
+	}
+	return nil
+}
+
+func (m *CLBulletproof) GetT1() []byte {
+	if m != nil {
+		return m.T1
+	}
+	return nil
+}
+
+func (m *CLBulletproof) GetT2() []byte {
+	if m != nil {
+		return m.T2
+	}
+	return nil
+}
+
+func (m *CLBulletproof) GetTauX() []byte {
+	if m != nil {
+		return m.TauX
+	}
+	return nil
+}
+
+func (m *CLBulletproof) GetMu() []byte {
+	if m != nil {
+		return m.Mu
+	}
+	return nil
+}
+
+func (m *CLBulletproof) GetT() []byte {
+	if m != nil {
+		return m.T
+	}
+	return nil
+}
+
+func (m *CLBulletproof) GetL() [][]byte {
+	if m != nil {
+		return m.L
+	}
+	return nil
+}
+
+func (m *CLBulletproof) GetR() [][]byte {
+	if m != nil {
+		return m.R
+	}
+	return nil
+}
+
+func (m *CLBulletproof) GetInnerA() []byte {
+	if m != nil {
+		return m.InnerA
+	}
+	return nil
+}
+
+func (m *CLBulletproof) GetInnerB() []byte {
+	if m != nil {
+		return m.InnerB
+	}
+	return nil
+}
+
+func (m *CLBulletproof) GetCommitment() []byte {
+	if m != nil {
+		return m.Commitment
+	}
+	return nil
+}
+
+func (m *CLBulletproof) GetEquality() *CLDFEqualityProof {
+	if m != nil {
+		return m.Equality
+	}
+	return nil
+}
+
+// CLDFEqualityProof proves that a commitment in QR_N and a commitment over an elliptic
+// curve hide the same integer. ProofData1 is in decimal, as it can be negative.
+type CLDFEqualityProof struct {
+	ProofRandomData1 []byte `protobuf:"bytes,1,opt,name=ProofRandomData1,proto3" json:"ProofRandomData1,omitempty"`
+	ProofRandomData2 []byte `protobuf:"bytes,2,opt,name=ProofRandomData2,proto3" json:"ProofRandomData2,omitempty"`
+	Challenge        []byte `protobuf:"bytes,3,opt,name=Challenge,proto3" json:"Challenge,omitempty"`
+	ProofData1       string `protobuf:"bytes,4,opt,name=ProofData1" json:"ProofData1,omitempty"`
+	ProofData21      []byte `protobuf:"bytes,5,opt,name=ProofData21,proto3" json:"ProofData21,omitempty"`
+	ProofData22      []byte `protobuf:"bytes,6,opt,name=ProofData22,proto3" json:"ProofData22,omitempty"`
+}
+
+func (m *CLDFEqualityProof) Reset()                    { *m = CLDFEqualityProof{} }
+func (m *CLDFEqualityProof) String() string            { return proto1.CompactTextString(m) }
+func (*CLDFEqualityProof) ProtoMessage()               {}
+func (*CLDFEqualityProof) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{82} }
+
+func (m *CLDFEqualityProof) GetProofRandomData1() []byte {
+	if m != nil {
+		return m.ProofRandomData1
+	}
+	return nil
+}
+
+func (m *CLDFEqualityProof) GetProofRandomData2() []byte {
+	if m != nil {
+		return m.ProofRandomData2
+	}
+	return nil
+}
+
+func (m *CLDFEqualityProof) GetChallenge() []byte {
+	if m != nil {
+		return m.Challenge
+	}
+	return nil
+}
+
+func (m *CLDFEqualityProof) GetProofData1() string {
+	if m != nil {
+		return m.ProofData1
+	}
+	return ""
+}
+
+func (m *CLDFEqualityProof) GetProofData21() []byte {
+	if m != nil {
+		return m.ProofData21
+	}
+	return nil
+}
+
+func (m *CLDFEqualityProof) GetProofData22() []byte {
+	if m != nil {
+		return m.ProofData22
 	}
 	return nil
 }
@@ -2573,9 +5166,16 @@ func init() {
 	proto1.RegisterType((*Attribute)(nil), "proto.Attribute")
 	proto1.RegisterType((*IntAttribute)(nil), "proto.IntAttribute")
 	proto1.RegisterType((*StringAttribute)(nil), "proto.StringAttribute")
+	proto1.RegisterType((*BlobAttribute)(nil), "proto.BlobAttribute")
+	proto1.RegisterType((*DateAttribute)(nil), "proto.DateAttribute")
+	proto1.RegisterType((*BoolAttribute)(nil), "proto.BoolAttribute")
+	proto1.RegisterType((*EnumAttribute)(nil), "proto.EnumAttribute")
+	proto1.RegisterType((*BytesAttribute)(nil), "proto.BytesAttribute")
 	proto1.RegisterType((*CredAttribute)(nil), "proto.CredAttribute")
+	proto1.RegisterType((*CredStructureRequest)(nil), "proto.CredStructureRequest")
 	proto1.RegisterType((*CredStructure)(nil), "proto.CredStructure")
 	proto1.RegisterType((*Status)(nil), "proto.Status")
+	proto1.RegisterType((*ProtocolError)(nil), "proto.ProtocolError")
 	proto1.RegisterType((*BigInt)(nil), "proto.BigInt")
 	proto1.RegisterType((*DoubleBigInt)(nil), "proto.DoubleBigInt")
 	proto1.RegisterType((*PedersenFirst)(nil), "proto.PedersenFirst")
@@ -2588,6 +5188,7 @@ func init() {
 	proto1.RegisterType((*FiatShamirAlsoNeg)(nil), "proto.FiatShamirAlsoNeg")
 	proto1.RegisterType((*SchnorrECProofRandomData)(nil), "proto.SchnorrECProofRandomData")
 	proto1.RegisterType((*PseudonymsysNymGenProofRandomData)(nil), "proto.PseudonymsysNymGenProofRandomData")
+	proto1.RegisterType((*PseudonymsysNymGenProofNI)(nil), "proto.PseudonymsysNymGenProofNI")
 	proto1.RegisterType((*PseudonymsysNymGenProofRandomDataEC)(nil), "proto.PseudonymsysNymGenProofRandomDataEC")
 	proto1.RegisterType((*PseudonymsysCACertificate)(nil), "proto.PseudonymsysCACertificate")
 	proto1.RegisterType((*PseudonymsysCACertificateEC)(nil), "proto.PseudonymsysCACertificateEC")
@@ -2605,153 +5206,352 @@ func init() {
 	proto1.RegisterType((*RegKey)(nil), "proto.RegKey")
 	proto1.RegisterType((*CLCredReq)(nil), "proto.CLCredReq")
 	proto1.RegisterType((*CLCredential)(nil), "proto.CLCredential")
+	proto1.RegisterType((*CLCredBatchItem)(nil), "proto.CLCredBatchItem")
+	proto1.RegisterType((*CLCredBatchResult)(nil), "proto.CLCredBatchResult")
+	proto1.RegisterType((*CLThresholdInit)(nil), "proto.CLThresholdInit")
+	proto1.RegisterType((*CLThresholdCommitment)(nil), "proto.CLThresholdCommitment")
+	proto1.RegisterType((*CLThresholdCommitments)(nil), "proto.CLThresholdCommitments")
+	proto1.RegisterType((*CLThresholdCiphertext)(nil), "proto.CLThresholdCiphertext")
+	proto1.RegisterType((*CLThresholdCiphertexts)(nil), "proto.CLThresholdCiphertexts")
+	proto1.RegisterType((*CLPartialSignature)(nil), "proto.CLPartialSignature")
+	proto1.RegisterType((*CLThresholdValue)(nil), "proto.CLThresholdValue")
+	proto1.RegisterType((*BBSCredRequest)(nil), "proto.BBSCredRequest")
+	proto1.RegisterType((*BBSSignature)(nil), "proto.BBSSignature")
+	proto1.RegisterType((*BBSProof)(nil), "proto.BBSProof")
 	proto1.RegisterType((*UpdateCLCredential)(nil), "proto.UpdateCLCredential")
 	proto1.RegisterType((*ProveCLCredential)(nil), "proto.ProveCLCredential")
+	proto1.RegisterType((*ProveCLCredentialNI)(nil), "proto.ProveCLCredentialNI")
+	proto1.RegisterType((*NIContext)(nil), "proto.NIContext")
+	proto1.RegisterType((*ProtocolStep)(nil), "proto.ProtocolStep")
+	proto1.RegisterType((*CLPredicate)(nil), "proto.CLPredicate")
+	proto1.RegisterType((*CLRangeProof)(nil), "proto.CLRangeProof")
+	proto1.RegisterType((*Accumulator)(nil), "proto.Accumulator")
+	proto1.RegisterType((*AccumulatorVersion)(nil), "proto.AccumulatorVersion")
+	proto1.RegisterType((*AccumulatorUpdate)(nil), "proto.AccumulatorUpdate")
+	proto1.RegisterType((*NonRevocationWitness)(nil), "proto.NonRevocationWitness")
+	proto1.RegisterType((*NonRevocationProof)(nil), "proto.NonRevocationProof")
+	proto1.RegisterType((*WebAuthnRegistration)(nil), "proto.WebAuthnRegistration")
+	proto1.RegisterType((*WebAuthnAssertion)(nil), "proto.WebAuthnAssertion")
+	proto1.RegisterType((*CLPubKey)(nil), "proto.CLPubKey")
+	proto1.RegisterType((*CLParams)(nil), "proto.CLParams")
+	proto1.RegisterType((*PublicParameters)(nil), "proto.PublicParameters")
+	proto1.RegisterType((*SignedPublicParameters)(nil), "proto.SignedPublicParameters")
+	proto1.RegisterType((*FiatShamirEC)(nil), "proto.FiatShamirEC")
+	proto1.RegisterType((*PseudonymsysChainLink)(nil), "proto.PseudonymsysChainLink")
+	proto1.RegisterType((*PseudonymsysCACertificateRequestNI)(nil), "proto.PseudonymsysCACertificateRequestNI")
+	proto1.RegisterType((*SessionStatus)(nil), "proto.SessionStatus")
+	proto1.RegisterType((*CLBulletproof)(nil), "proto.CLBulletproof")
+	proto1.RegisterType((*CLDFEqualityProof)(nil), "proto.CLDFEqualityProof")
+	proto1.RegisterEnum("proto.ErrorCode", ErrorCode_name, ErrorCode_value)
 }
 
 func init() { proto1.RegisterFile("messages.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 2249 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe4, 0x58, 0xcd, 0x73, 0x1b, 0x49,
-	0x15, 0xd7, 0x8c, 0x3e, 0x1c, 0xbf, 0xc8, 0x8e, 0xd3, 0x76, 0xbc, 0x93, 0x38, 0x1f, 0xca, 0xd8,
-	0xc6, 0x0e, 0xcb, 0xda, 0x91, 0xb2, 0x55, 0x7c, 0x6c, 0xed, 0x52, 0x92, 0xa2, 0xb5, 0x8c, 0x37,
-	0x5a, 0x33, 0x4a, 0x52, 0x76, 0x2e, 0x62, 0x3c, 0x6a, 0xcb, 0x53, 0x48, 0x33, 0x62, 0x66, 0x94,
-	0x45, 0x07, 0x28, 0x8a, 0x02, 0xaa, 0xb8, 0x01, 0x17, 0x8e, 0x9c, 0xf8, 0x1b, 0x38, 0x53, 0x14,
-	0xff, 0x03, 0x55, 0xc0, 0x3f, 0xc2, 0x89, 0xea, 0xd7, 0xdd, 0xa3, 0x19, 0x69, 0x46, 0x12, 0x54,
-	0x71, 0xe2, 0x24, 0xbd, 0xf7, 0x7e, 0xef, 0xb3, 0x5f, 0xf7, 0xbc, 0x6e, 0x58, 0x1f, 0x50, 0xdf,
-	0x37, 0x7b, 0xd4, 0x3f, 0x1a, 0x7a, 0x6e, 0xe0, 0x92, 0x3c, 0xfe, 0x3c, 0xd8, 0xe9, 0xb9, 0x6e,
-	0xaf, 0x4f, 0x8f, 0x91, 0xba, 0x1a, 0x5d, 0x1f, 0xd3, 0xc1, 0x30, 0x18, 0x73, 0x8c, 0xfe, 0xcf,
-	0x3b, 0xb0, 0xf2, 0x8a, 0xab, 0x91, 0x03, 0x28, 0x5c, 0xd9, 0x3d, 0xdb, 0x09, 0xb4, 0x5c, 0x49,
-	0x39, 0xbc, 0x5d, 0x59, 0xe3, 0x98, 0xa3, 0x9a, 0xdd, 0x3b, 0x75, 0x82, 0x66, 0xc6, 0x10, 0x62,
-	0x52, 0x85, 0x0d, 0x6a, 0x75, 0x7a, 0x9e, 0x3b, 0x1a, 0x76, 0x68, 0x9f, 0x0e, 0xa8, 0x13, 0x68,
-	0x79, 0x54, 0xb9, 0x27, 0x54, 0x1a, 0xf5, 0x13, 0x26, 0x6d, 0x70, 0x61, 0x33, 0x63, 0xac, 0x53,
-	0x2b, 0xca, 0x61, 0xbe, 0xfc, 0xc0, 0x0c, 0x46, 0xbe, 0x56, 0x88, 0xf9, 0x6a, 0x23, 0x93, 0xf9,
-	0xe2, 0x62, 0xf2, 0x29, 0xac, 0x0f, 0x69, 0x97, 0x7a, 0x3e, 0x75, 0x3a, 0xd7, 0xb6, 0xe7, 0x07,
-	0xda, 0x0a, 0x2a, 0x6c, 0x09, 0x85, 0x73, 0x21, 0xfc, 0x9c, 0xc9, 0x9a, 0x19, 0x63, 0x6d, 0x18,
-	0x65, 0x10, 0x03, 0xee, 0x85, 0xea, 0x5d, 0x6a, 0xb9, 0x83, 0x81, 0x1d, 0x60, 0xbc, 0xb7, 0xd0,
-	0xca, 0xce, 0x94, 0x95, 0x97, 0x11, 0x48, 0x33, 0x63, 0x6c, 0x0d, 0x13, 0xf8, 0xe4, 0x04, 0x88,
-	0x6f, 0xdd, 0x38, 0xae, 0xe7, 0x75, 0x86, 0x9e, 0xeb, 0x5e, 0x77, 0xba, 0x66, 0x60, 0x6a, 0xab,
-	0x68, 0xf0, 0x03, 0x99, 0x07, 0x07, 0x9c, 0x33, 0xf9, 0x4b, 0x33, 0x30, 0x9b, 0x19, 0x63, 0xc3,
-	0x9f, 0xe2, 0x91, 0x77, 0x70, 0x3f, 0x6e, 0xc8, 0x33, 0x9d, 0xae, 0x3b, 0xe0, 0xf6, 0x00, 0xed,
-	0x3d, 0x4a, 0xb0, 0x67, 0x20, 0x4a, 0x58, 0xdd, 0xf6, 0x13, 0x25, 0xc4, 0x84, 0x87, 0xd2, 0x36,
-	0xb5, 0x12, 0xcc, 0xdf, 0x46, 0xf3, 0x4f, 0xe2, 0xe6, 0x1b, 0xf5, 0x59, 0x07, 0x9a, 0x30, 0xd3,
-	0xb0, 0xa6, 0x5d, 0x5c, 0xc1, 0xce, 0xd0, 0xa7, 0xa3, 0xae, 0xeb, 0x8c, 0x07, 0xfe, 0xd8, 0xef,
-	0x58, 0x66, 0xc7, 0xa2, 0x5e, 0x60, 0x5f, 0xdb, 0x96, 0x19, 0x50, 0xed, 0x0e, 0x7a, 0x28, 0xc9,
-	0x0a, 0x47, 0x90, 0xf5, 0x6a, 0x7d, 0x82, 0x6b, 0x66, 0x8c, 0xfb, 0x51, 0x33, 0x75, 0x33, 0x22,
-	0x24, 0x3f, 0x81, 0xaf, 0xc5, 0x7c, 0x38, 0xe3, 0x41, 0xa7, 0x47, 0x9d, 0x84, 0x84, 0x36, 0xd0,
-	0xdd, 0x61, 0x82, 0xbb, 0xd6, 0x78, 0x70, 0x42, 0x9d, 0xd9, 0xcc, 0x9e, 0x0e, 0x17, 0x81, 0xc8,
-	0x18, 0xf6, 0x62, 0xee, 0x6d, 0xdf, 0x1f, 0xd1, 0x04, 0xe7, 0x77, 0xd1, 0xf9, 0x41, 0x82, 0xf3,
-	0x53, 0xa6, 0x31, 0xeb, 0xbb, 0x34, 0x5c, 0x80, 0x21, 0xdf, 0x81, 0xb5, 0xae, 0x3b, 0xba, 0xea,
-	0xd3, 0x8e, 0xd8, 0x94, 0x04, 0x7d, 0x6c, 0x0a, 0x1f, 0x2f, 0x51, 0x16, 0x6e, 0xcd, 0x62, 0x57,
-	0xd2, 0x6c, 0x83, 0xfe, 0x14, 0xf6, 0x63, 0x61, 0x07, 0x9e, 0xe9, 0xf8, 0xd7, 0xd4, 0xeb, 0x58,
-	0x1e, 0xed, 0x52, 0x27, 0xb0, 0xcd, 0x3e, 0x8f, 0x7b, 0x13, 0x6d, 0x3e, 0x4b, 0x88, 0xfb, 0xb5,
-	0x50, 0xa9, 0x87, 0x1a, 0x22, 0x72, 0x7d, 0xb8, 0x10, 0x45, 0x6c, 0x78, 0x3c, 0xa7, 0x33, 0x3a,
-	0xd4, 0xd2, 0xb6, 0xd0, 0xb1, 0xbe, 0xa8, 0x39, 0x1a, 0xf5, 0x66, 0xc6, 0xd8, 0x49, 0x6d, 0x8f,
-	0x86, 0x45, 0x7e, 0xa1, 0xc0, 0xb3, 0xe5, 0x3a, 0x84, 0xb9, 0xbd, 0x87, 0x6e, 0xbf, 0xbe, 0x6c,
-	0x93, 0xa0, 0xfb, 0xdd, 0x85, 0x6d, 0xd2, 0xb0, 0xc8, 0xcf, 0x14, 0x38, 0x58, 0xa6, 0x53, 0x58,
-	0x10, 0xdb, 0xa9, 0x45, 0x4f, 0x6a, 0x04, 0x8c, 0x41, 0x5f, 0xd4, 0x2e, 0x0d, 0x8b, 0xfc, 0x52,
-	0x81, 0xc3, 0xa5, 0x56, 0x9d, 0xc5, 0xf0, 0x01, 0xc6, 0xf0, 0xe1, 0xd2, 0x0b, 0x8f, 0x51, 0xec,
-	0x2d, 0x5e, 0xfa, 0x86, 0x45, 0x5e, 0x00, 0xb4, 0xa9, 0xef, 0xdb, 0xae, 0x73, 0x46, 0xc7, 0xda,
-	0x63, 0x74, 0x74, 0x57, 0x9e, 0x33, 0xa1, 0xa0, 0x99, 0x31, 0x22, 0x30, 0xf2, 0x1c, 0x56, 0xeb,
-	0x5f, 0x30, 0x53, 0x06, 0xfd, 0x91, 0xf6, 0x04, 0x75, 0x36, 0x84, 0x4e, 0xc8, 0x6f, 0x66, 0x8c,
-	0x09, 0x88, 0x7c, 0x1b, 0x8a, 0x9c, 0xe0, 0xce, 0xb5, 0x52, 0x6c, 0x7b, 0x44, 0x45, 0x6c, 0x7b,
-	0x44, 0x69, 0xf2, 0x0a, 0xb6, 0x46, 0xc3, 0x2e, 0xeb, 0x44, 0xab, 0x1f, 0x29, 0x8e, 0xf6, 0x14,
-	0x4d, 0xdc, 0x17, 0x26, 0xde, 0x20, 0x64, 0xca, 0x10, 0xe1, 0x8a, 0xf5, 0x7e, 0xc4, 0xdc, 0xf7,
-	0x60, 0x73, 0xe8, 0xb9, 0xef, 0xa7, 0xad, 0xe9, 0x68, 0x4d, 0x93, 0x25, 0x66, 0x88, 0x29, 0x63,
-	0x77, 0x51, 0x2d, 0x66, 0xeb, 0x00, 0x0a, 0x06, 0xed, 0xb1, 0xc2, 0xed, 0xc6, 0xbe, 0x8b, 0x9c,
-	0xc9, 0xbe, 0x8b, 0xfc, 0x1f, 0x79, 0x00, 0xb7, 0xac, 0xbe, 0x4d, 0x9d, 0xe0, 0xb4, 0xab, 0x3d,
-	0x2c, 0x29, 0x87, 0x79, 0x23, 0xa4, 0x6b, 0xab, 0xb0, 0x62, 0xb9, 0x4e, 0x40, 0x9d, 0x40, 0xef,
-	0xc0, 0xed, 0x36, 0xf5, 0xde, 0xdb, 0x16, 0x3d, 0x75, 0xae, 0x5d, 0x42, 0x20, 0xe7, 0x98, 0x03,
-	0xaa, 0x29, 0x25, 0xe5, 0x70, 0xd5, 0xc0, 0xff, 0xa4, 0x04, 0xb7, 0xbb, 0xd4, 0xb7, 0x3c, 0x7b,
-	0x18, 0xd8, 0xae, 0xa3, 0xa9, 0x28, 0x8a, 0xb2, 0x98, 0x2f, 0x16, 0xa9, 0xdd, 0xa5, 0x9e, 0x96,
-	0x45, 0x71, 0x48, 0xeb, 0xe7, 0xb0, 0x5e, 0xb5, 0x2c, 0x3a, 0x0c, 0xcc, 0xab, 0x3e, 0x65, 0x89,
-	0x10, 0x0d, 0x56, 0x5c, 0xaf, 0xd7, 0x9a, 0xb8, 0x91, 0x24, 0xd9, 0x83, 0x35, 0x8f, 0xbe, 0xa7,
-	0x66, 0x9f, 0x76, 0xab, 0x41, 0xe0, 0xf9, 0x9a, 0x5a, 0xca, 0x1e, 0xae, 0x1a, 0x71, 0xa6, 0xfe,
-	0x19, 0xdc, 0x89, 0x5b, 0xf4, 0xc9, 0x87, 0x90, 0x67, 0x85, 0xf5, 0x35, 0xa5, 0x94, 0x8d, 0x4c,
-	0x19, 0x71, 0x98, 0xc1, 0x31, 0xfa, 0x19, 0xac, 0x32, 0x43, 0xf6, 0xd5, 0x28, 0xa0, 0x64, 0x0b,
-	0xf2, 0xb6, 0xd3, 0xa5, 0x3f, 0xc6, 0x50, 0xf2, 0x06, 0x27, 0xc2, 0x32, 0xa8, 0x91, 0x32, 0x6c,
-	0x41, 0xfe, 0x87, 0x8e, 0xfb, 0x95, 0x83, 0xc3, 0xcf, 0x2d, 0x83, 0x13, 0xfa, 0xc7, 0x50, 0x3c,
-	0x75, 0x82, 0x89, 0xbd, 0x3d, 0xc8, 0x99, 0x41, 0xe0, 0xa1, 0xb9, 0x49, 0x8b, 0x86, 0x72, 0x03,
-	0xa5, 0xfa, 0x37, 0xe1, 0x4e, 0x3b, 0xf0, 0x6c, 0xa7, 0x37, 0xab, 0xa8, 0xce, 0x55, 0xfc, 0xb9,
-	0x02, 0x6b, 0x2c, 0x97, 0x89, 0xde, 0xb7, 0x00, 0xfc, 0xd0, 0x94, 0x70, 0xbb, 0x1d, 0x0e, 0x4b,
-	0x31, 0x1f, 0x6c, 0x4b, 0x4d, 0xb0, 0xe4, 0x18, 0x56, 0x6c, 0x1e, 0xba, 0x70, 0x2a, 0xf7, 0x46,
-	0x34, 0xa1, 0x66, 0xc6, 0x90, 0xa8, 0x5a, 0x01, 0x72, 0xc1, 0x78, 0x48, 0xf5, 0xdf, 0x8b, 0x20,
-	0xda, 0x81, 0x37, 0xb2, 0x82, 0x91, 0x47, 0xc9, 0x36, 0x14, 0x9c, 0x33, 0x2c, 0x0e, 0x2f, 0xa3,
-	0xa0, 0xc8, 0x63, 0x00, 0xa7, 0x8e, 0x83, 0x51, 0x40, 0xbb, 0xe8, 0x25, 0x6f, 0x44, 0x38, 0xac,
-	0x15, 0x9c, 0xa6, 0xdd, 0xed, 0x52, 0x07, 0xfb, 0x26, 0x6f, 0x48, 0x92, 0x7c, 0x0c, 0x60, 0xca,
-	0x18, 0x7c, 0x2d, 0x87, 0xcb, 0x2a, 0x47, 0xba, 0x58, 0x01, 0x8c, 0x08, 0x4e, 0xd7, 0xa1, 0xc0,
-	0x07, 0x44, 0x66, 0xb9, 0x3d, 0xb2, 0x2c, 0xea, 0xfb, 0x18, 0xd2, 0x2d, 0x43, 0x92, 0xba, 0x06,
-	0x05, 0xfe, 0x55, 0x24, 0xeb, 0xa0, 0x5e, 0x94, 0x51, 0x5c, 0x34, 0xd4, 0x8b, 0xb2, 0x7e, 0x04,
-	0xc5, 0xe8, 0x57, 0x73, 0x5a, 0x8e, 0x74, 0x05, 0xb3, 0x60, 0x74, 0x45, 0x7f, 0x04, 0x6b, 0xb1,
-	0xe9, 0x92, 0x14, 0x41, 0x69, 0x0a, 0xbc, 0xd2, 0xd4, 0x2b, 0xb0, 0x95, 0x34, 0x36, 0x32, 0xd4,
-	0x85, 0x44, 0x5d, 0x30, 0xca, 0x10, 0x36, 0x15, 0x43, 0xff, 0x06, 0xac, 0xc7, 0x47, 0xe3, 0x59,
-	0xf4, 0xa5, 0x44, 0x5f, 0xea, 0x3a, 0xe4, 0xce, 0x4d, 0xdb, 0x63, 0xdc, 0xaa, 0xc4, 0x54, 0x19,
-	0x55, 0x93, 0x98, 0x9a, 0x5e, 0x83, 0xed, 0xe4, 0xd9, 0x70, 0xd6, 0x72, 0x55, 0x6a, 0x09, 0x1b,
-	0x59, 0x69, 0xa3, 0x04, 0x1b, 0xd3, 0xf3, 0x2a, 0x43, 0xbc, 0x93, 0xda, 0xef, 0x74, 0x0f, 0xe0,
-	0x73, 0xdb, 0x0c, 0xda, 0x37, 0xe6, 0xc0, 0xf6, 0xc8, 0x21, 0xdc, 0x99, 0x72, 0x26, 0x90, 0xd3,
-	0x6c, 0xf2, 0x10, 0x56, 0xeb, 0x37, 0x66, 0xbf, 0x4f, 0x9d, 0x1e, 0x15, 0xde, 0x27, 0x0c, 0x26,
-	0x0d, 0x1d, 0x6a, 0xd9, 0x52, 0x96, 0x49, 0x43, 0x86, 0x3e, 0x86, 0xbb, 0x13, 0x9f, 0xd5, 0xbe,
-	0xef, 0xb6, 0x68, 0xef, 0x7f, 0xe7, 0x7a, 0x35, 0xea, 0xfa, 0xd7, 0x0a, 0x68, 0x69, 0x23, 0x31,
-	0xd9, 0x95, 0x75, 0x4d, 0xbb, 0xee, 0xb0, 0x72, 0xef, 0xca, 0x72, 0xa7, 0x83, 0xaa, 0x0c, 0xc4,
-	0x57, 0x21, 0x1d, 0x54, 0xd3, 0xff, 0xa4, 0xc0, 0xd3, 0x85, 0x83, 0x4a, 0x52, 0x2f, 0x57, 0xcb,
-	0xb2, 0x97, 0xab, 0x48, 0xd7, 0xca, 0x62, 0xc5, 0xd5, 0x9a, 0xec, 0xf5, 0x9c, 0xec, 0x75, 0xc4,
-	0x57, 0xf0, 0x12, 0xc7, 0xf0, 0x48, 0xd7, 0x2a, 0x78, 0x37, 0x63, 0xf8, 0x0a, 0x6f, 0xe3, 0x15,
-	0xd1, 0xc6, 0x8c, 0x6a, 0xe3, 0x0d, 0xaa, 0x68, 0x28, 0x6d, 0x76, 0x3a, 0x88, 0x6f, 0xd6, 0x2a,
-	0x9e, 0xa7, 0x82, 0xd2, 0xff, 0xa2, 0xc2, 0xee, 0x12, 0x23, 0x16, 0xd9, 0x0f, 0x63, 0x4f, 0xad,
-	0x03, 0x4b, 0x69, 0x3f, 0x4c, 0x29, 0x1d, 0x56, 0x45, 0x98, 0xc8, 0x34, 0x1d, 0x56, 0x43, 0x98,
-	0x28, 0xc0, 0x1c, 0xa7, 0x15, 0x74, 0x5a, 0x99, 0x7b, 0xb9, 0xc5, 0x72, 0xed, 0x87, 0xe5, 0x9a,
-	0xe3, 0xf4, 0xbf, 0xab, 0xa2, 0x0b, 0xf7, 0x53, 0xc7, 0x63, 0xf6, 0x65, 0xae, 0xf5, 0xd9, 0x37,
-	0xad, 0x2b, 0x0f, 0x88, 0x90, 0x8e, 0xc8, 0xe4, 0x71, 0x11, 0xd2, 0x3c, 0x90, 0x6c, 0x2c, 0x90,
-	0x9c, 0x08, 0x44, 0xff, 0x83, 0x02, 0x3b, 0x73, 0x06, 0x72, 0x52, 0x9e, 0xf2, 0x99, 0x9a, 0xf1,
-	0x24, 0x94, 0xf2, 0x54, 0x28, 0x0b, 0x55, 0xe6, 0x47, 0xf8, 0x2b, 0x05, 0x4a, 0x8b, 0xc6, 0x66,
-	0xb2, 0x01, 0xd9, 0x8b, 0xb2, 0xdc, 0x12, 0xec, 0x2f, 0xe7, 0xc8, 0x03, 0x9e, 0xfd, 0x45, 0x4e,
-	0x45, 0x6e, 0x0b, 0xf6, 0x97, 0x73, 0xe4, 0xc6, 0x60, 0x7f, 0xf9, 0xc1, 0x99, 0x8f, 0x1d, 0x9c,
-	0x05, 0x79, 0x70, 0xfe, 0x4e, 0x05, 0x7d, 0xf1, 0xfc, 0x4e, 0x0e, 0x26, 0xa1, 0xa4, 0x66, 0x8e,
-	0x11, 0x1e, 0x4c, 0x22, 0x9c, 0x07, 0xac, 0x20, 0xb0, 0xb2, 0xa0, 0xcb, 0x31, 0x9f, 0x83, 0x49,
-	0x3e, 0xf3, 0x80, 0x15, 0x7e, 0x60, 0xe5, 0x97, 0x39, 0xb0, 0x0a, 0x0b, 0x0e, 0xac, 0x1f, 0xc0,
-	0xf6, 0xcc, 0x7d, 0x02, 0x47, 0xc9, 0x79, 0xdf, 0x31, 0x36, 0x92, 0x35, 0x4d, 0xff, 0x46, 0xac,
-	0x05, 0xfe, 0x67, 0x5b, 0xe2, 0x5d, 0xb5, 0x3f, 0xbc, 0x31, 0xc5, 0x7a, 0x08, 0x4a, 0xff, 0x8d,
-	0x02, 0x5a, 0xb2, 0x8b, 0x46, 0x9d, 0x27, 0xa2, 0x2c, 0x93, 0xc8, 0xfc, 0xe3, 0xf9, 0x3f, 0x0b,
-	0xe9, 0x5f, 0x4a, 0x3c, 0xeb, 0xc8, 0x48, 0xbf, 0x07, 0x6b, 0xed, 0x81, 0xd9, 0xef, 0x57, 0x5f,
-	0xbb, 0x27, 0xe6, 0x60, 0x20, 0x3f, 0x58, 0x71, 0x66, 0x88, 0xaa, 0x49, 0x94, 0x1a, 0x41, 0x49,
-	0x26, 0xdb, 0xd3, 0xa1, 0x19, 0x1e, 0x56, 0x48, 0xe3, 0x7e, 0x97, 0xb2, 0x9c, 0xd8, 0xef, 0x52,
-	0xf6, 0x11, 0xa8, 0xaf, 0xcb, 0x62, 0x79, 0x1f, 0xa5, 0x5d, 0xfa, 0xb0, 0x82, 0x86, 0xfa, 0xba,
-	0x8c, 0x70, 0x79, 0x9c, 0x2d, 0x84, 0x57, 0xf4, 0x7f, 0xa8, 0xf1, 0xf5, 0x98, 0x24, 0xdf, 0xa8,
-	0x93, 0x4f, 0x92, 0xd2, 0x4f, 0x2d, 0xfb, 0x54, 0x55, 0x3e, 0x49, 0xaa, 0xca, 0x02, 0xe5, 0x30,
-	0xe9, 0xf2, 0x54, 0xb1, 0xd2, 0x4f, 0x9d, 0x6a, 0x44, 0x25, 0x56, 0xc3, 0x39, 0x07, 0x95, 0x54,
-	0x39, 0x8e, 0x94, 0xf6, 0xc9, 0xdc, 0x5a, 0x35, 0xea, 0x58, 0xdc, 0xe3, 0x48, 0x71, 0x97, 0x50,
-	0xa8, 0xe8, 0x7f, 0x55, 0xe2, 0xa7, 0x4c, 0xca, 0xa3, 0x8b, 0x06, 0x2b, 0x5f, 0xc6, 0xef, 0x5d,
-	0x82, 0x14, 0xc3, 0x81, 0x3a, 0x35, 0xe8, 0x66, 0xc3, 0x8f, 0x3f, 0x81, 0x5c, 0x6b, 0x3c, 0xa8,
-	0x8a, 0xae, 0xc1, 0xff, 0x82, 0x57, 0x13, 0x27, 0x1f, 0xfe, 0x27, 0x9f, 0x02, 0x44, 0x2e, 0xdc,
-	0xe9, 0xed, 0x31, 0x01, 0x19, 0x11, 0x05, 0xfd, 0x8f, 0x2a, 0xec, 0x2d, 0xf3, 0xd2, 0x30, 0x27,
-	0x93, 0xfd, 0x30, 0x93, 0x45, 0xa3, 0x82, 0x48, 0x70, 0xee, 0xc7, 0xfd, 0x59, 0x24, 0xef, 0x54,
-	0x20, 0x2f, 0xc7, 0xb3, 0x48, 0x39, 0xe6, 0x42, 0x6b, 0xe4, 0xbb, 0x09, 0x55, 0x7a, 0x32, 0xb7,
-	0x4a, 0x8d, 0x7a, 0xac, 0x4e, 0x7f, 0x57, 0x61, 0xb3, 0xde, 0x3e, 0x37, 0xed, 0x7e, 0xdf, 0xa6,
-	0x5e, 0x9b, 0x5a, 0x1e, 0x0d, 0xd8, 0x95, 0xbf, 0x08, 0x4a, 0x4b, 0x1e, 0x9f, 0x2d, 0x46, 0x9d,
-	0xc8, 0xe3, 0xf3, 0x44, 0x2c, 0x71, 0x76, 0x6a, 0x89, 0x63, 0xf3, 0xdd, 0xc5, 0x0b, 0x39, 0xdf,
-	0x5d, 0xbc, 0x60, 0xb7, 0xdd, 0x97, 0x5f, 0xb8, 0xbd, 0x73, 0xf1, 0x2d, 0xe3, 0x84, 0xe4, 0x9e,
-	0x88, 0x19, 0x85, 0x13, 0x92, 0xfb, 0x7d, 0x31, 0xab, 0x70, 0x82, 0x3c, 0x87, 0xcd, 0xb7, 0xd4,
-	0xb3, 0xaf, 0x6d, 0x76, 0xff, 0x6e, 0x38, 0xfc, 0x79, 0xbf, 0x85, 0xc3, 0x4b, 0xd1, 0x48, 0x12,
-	0x91, 0x0a, 0x6c, 0xcd, 0xb2, 0x4f, 0xca, 0xf8, 0xd2, 0x5d, 0x34, 0x12, 0x65, 0xc9, 0x3a, 0xcd,
-	0x32, 0x3e, 0x5f, 0x27, 0xea, 0x34, 0xcb, 0xac, 0x32, 0x67, 0x5a, 0x11, 0xef, 0x9b, 0xca, 0x19,
-	0xcb, 0xfc, 0xac, 0xac, 0xad, 0x21, 0xa9, 0x9e, 0x95, 0xf5, 0xbf, 0xa9, 0xb0, 0x31, 0xa9, 0xee,
-	0xf9, 0xe8, 0x6a, 0x89, 0xd2, 0x5e, 0x86, 0xa5, 0xbd, 0xc4, 0xd2, 0x5e, 0x86, 0xa5, 0xbd, 0xc4,
-	0xd2, 0x5e, 0x86, 0xa5, 0xbd, 0xfc, 0x7f, 0x2e, 0xad, 0x1e, 0x7d, 0xf9, 0x63, 0xb9, 0xbd, 0x37,
-	0xfb, 0x23, 0xb9, 0x87, 0x39, 0xa1, 0x97, 0xe4, 0x98, 0x1b, 0x19, 0x78, 0x95, 0xd8, 0xc0, 0xfb,
-	0x67, 0x35, 0xf2, 0x16, 0xc8, 0x06, 0xb2, 0xd6, 0x78, 0x20, 0xc7, 0xb8, 0xd6, 0x78, 0x40, 0x1e,
-	0x03, 0xe0, 0xeb, 0xc3, 0xe4, 0x09, 0xa9, 0x68, 0x44, 0x38, 0xe4, 0x08, 0x48, 0x3d, 0xbc, 0x8d,
-	0xfb, 0x5f, 0x5e, 0x73, 0x1c, 0xbf, 0x5e, 0x26, 0x48, 0xc8, 0x47, 0x70, 0xab, 0x35, 0x1e, 0xe0,
-	0xd4, 0x26, 0x4e, 0x02, 0xf9, 0x5a, 0x39, 0xb9, 0x7e, 0x1a, 0x21, 0x84, 0x95, 0xe0, 0x8d, 0x9c,
-	0x07, 0xdf, 0x90, 0xe7, 0x50, 0x78, 0xc3, 0x55, 0x0b, 0xb1, 0xe7, 0xbe, 0x99, 0x9b, 0xab, 0x21,
-	0x70, 0xe4, 0x15, 0x68, 0xb3, 0x41, 0xa0, 0xc8, 0xd7, 0x56, 0xf0, 0x1d, 0x24, 0xc1, 0x7d, 0xaa,
-	0x0a, 0xab, 0x72, 0xcb, 0x75, 0x2c, 0x2a, 0x3b, 0x08, 0x09, 0xdd, 0x89, 0x3f, 0x8e, 0xce, 0x4e,
-	0x5e, 0x0d, 0xd9, 0xdf, 0x0d, 0x56, 0xe1, 0xb7, 0xe5, 0x70, 0x08, 0x7e, 0x5b, 0x2e, 0xb3, 0xa4,
-	0xaa, 0xd1, 0x7a, 0xcc, 0x49, 0x8a, 0xe3, 0xf4, 0x2b, 0x20, 0xb3, 0xcf, 0xa5, 0x09, 0x6b, 0x17,
-	0x46, 0xab, 0x46, 0xa2, 0x65, 0xb3, 0x4f, 0x8b, 0x7e, 0x15, 0x59, 0x54, 0xbe, 0x58, 0x71, 0xa6,
-	0xfe, 0x5b, 0x15, 0xee, 0xce, 0xbc, 0xa2, 0x4e, 0x65, 0x76, 0x04, 0x79, 0x1e, 0xb8, 0xba, 0x20,
-	0x70, 0x0e, 0x9b, 0xea, 0xa5, 0xec, 0x92, 0xbd, 0x94, 0x4b, 0xed, 0xa5, 0x23, 0x20, 0x86, 0x78,
-	0xcc, 0x8c, 0xd8, 0xcd, 0x97, 0xb2, 0x87, 0x79, 0x23, 0x41, 0x42, 0x3e, 0x83, 0x07, 0x92, 0x9b,
-	0xe0, 0xa7, 0x80, 0x7a, 0x73, 0x10, 0x57, 0x05, 0xcc, 0xef, 0xc5, 0xbf, 0x03, 0x00, 0x00, 0xff,
-	0xff, 0x1e, 0x90, 0xe0, 0x69, 0xfb, 0x1d, 0x00, 0x00,
+	// 4872 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe5, 0x3b, 0x4d, 0x6f, 0x23, 0x47,
+	0x76, 0xdb, 0xa4, 0x3e, 0x4b, 0x5f, 0x54, 0x8f, 0x46, 0xe6, 0x78, 0xc6, 0xf6, 0xb8, 0x67, 0xc6,
+	0xf3, 0x61, 0x7b, 0xc6, 0xe2, 0xd8, 0xc9, 0x6e, 0xbc, 0x6b, 0x83, 0xa4, 0x38, 0x12, 0x2d, 0x89,
+	0x92, 0x9b, 0x94, 0x46, 0x9a, 0x1c, 0x98, 0x16, 0xd9, 0x43, 0x31, 0xe6, 0x97, 0xd9, 0xcd, 0xd9,
+	0x51, 0x90, 0x2c, 0x72, 0xd8, 0x0d, 0x10, 0x2c, 0x90, 0x2c, 0x02, 0xe4, 0x12, 0x04, 0x58, 0x04,
+	0x39, 0x26, 0x97, 0x9c, 0x02, 0x24, 0xb7, 0x04, 0xf9, 0x03, 0x39, 0x04, 0x0b, 0x24, 0x97, 0xfc,
+	0x80, 0x00, 0x39, 0xe7, 0x10, 0xe4, 0xbd, 0x57, 0x55, 0xdd, 0x55, 0xdd, 0x4d, 0x52, 0xe3, 0x20,
+	0xa7, 0x5c, 0xc4, 0x7e, 0x9f, 0xf5, 0xf1, 0xaa, 0xde, 0x7b, 0x55, 0xaf, 0xc4, 0x56, 0xbb, 0xae,
+	0xe7, 0x39, 0x2d, 0xd7, 0x7b, 0x3c, 0x18, 0xf6, 0xfd, 0xbe, 0x39, 0x4b, 0x3f, 0x6f, 0xdf, 0x6c,
+	0xf5, 0xfb, 0xad, 0x8e, 0xfb, 0x84, 0xa0, 0xf3, 0xd1, 0xcb, 0x27, 0x6e, 0x77, 0xe0, 0x5f, 0x72,
+	0x1e, 0xeb, 0x9f, 0x37, 0xd9, 0xfc, 0x01, 0x17, 0x33, 0xef, 0xb3, 0xb9, 0xf3, 0x76, 0xab, 0xdd,
+	0xf3, 0xb3, 0x33, 0xb7, 0x8d, 0x07, 0x4b, 0xb9, 0x15, 0xce, 0xf3, 0xb8, 0xd0, 0x6e, 0x95, 0x7b,
+	0xfe, 0xee, 0xf7, 0x6c, 0x41, 0x36, 0xf3, 0x2c, 0xe3, 0x36, 0xea, 0xad, 0x61, 0x7f, 0x34, 0xa8,
+	0xbb, 0x1d, 0xb7, 0xeb, 0x82, 0xc8, 0x2c, 0x89, 0x5c, 0x17, 0x22, 0xa5, 0xe2, 0x0e, 0x52, 0x4b,
+	0x9c, 0x08, 0xa2, 0xab, 0x6e, 0x43, 0xc5, 0x60, 0x5b, 0x9e, 0xef, 0xf8, 0x23, 0x2f, 0x3b, 0xa7,
+	0xb5, 0x55, 0x25, 0x24, 0xb6, 0xc5, 0xc9, 0xe6, 0x8f, 0xd8, 0xea, 0xc0, 0x6d, 0xba, 0x43, 0xcf,
+	0xed, 0xd5, 0x5f, 0xb6, 0x87, 0x9e, 0x9f, 0x9d, 0x27, 0x81, 0x0d, 0x21, 0x70, 0x24, 0x88, 0xcf,
+	0x90, 0x06, 0x72, 0x2b, 0x03, 0x15, 0x61, 0xda, 0xec, 0x7a, 0x20, 0xde, 0x74, 0x1b, 0xfd, 0x6e,
+	0xb7, 0xed, 0x53, 0x7f, 0x17, 0x48, 0xcb, 0xcd, 0x88, 0x96, 0x6d, 0x85, 0x05, 0x94, 0x6d, 0x0c,
+	0x12, 0xf0, 0xe6, 0x0e, 0x33, 0xbd, 0xc6, 0x45, 0xaf, 0x3f, 0x1c, 0xd6, 0x41, 0xba, 0xff, 0xb2,
+	0xde, 0x74, 0x7c, 0x27, 0xbb, 0x48, 0x0a, 0xdf, 0x92, 0xe3, 0xe0, 0x0c, 0x47, 0x48, 0xdf, 0x06,
+	0x32, 0x28, 0xcb, 0x78, 0x11, 0x9c, 0xf9, 0x82, 0xdd, 0xd0, 0x15, 0x0d, 0x9d, 0x5e, 0xb3, 0xdf,
+	0xe5, 0xfa, 0x18, 0xe9, 0x7b, 0x27, 0x41, 0x9f, 0x4d, 0x5c, 0x42, 0xeb, 0xa6, 0x97, 0x48, 0x31,
+	0x1d, 0x76, 0x4b, 0xea, 0x06, 0x5b, 0xc5, 0xd5, 0x2f, 0x91, 0xfa, 0xf7, 0x74, 0xf5, 0xa5, 0x62,
+	0xbc, 0x81, 0xac, 0x50, 0x53, 0x6a, 0x44, 0x9b, 0x38, 0x67, 0x37, 0x07, 0x9e, 0x3b, 0x6a, 0xf6,
+	0x7b, 0x97, 0x5d, 0xef, 0xd2, 0xab, 0x37, 0x9c, 0x7a, 0xc3, 0x1d, 0xfa, 0xed, 0x97, 0xed, 0x86,
+	0xe3, 0xbb, 0xd9, 0x35, 0x6a, 0xe1, 0xb6, 0x9c, 0x61, 0x85, 0xb3, 0x98, 0x2f, 0x86, 0x7c, 0xd0,
+	0xc4, 0x0d, 0x55, 0x4d, 0xd1, 0x51, 0x88, 0xe6, 0xef, 0xb1, 0x0f, 0xb4, 0x36, 0xe0, 0xa7, 0xde,
+	0x02, 0x5b, 0xc6, 0x07, 0x94, 0xa1, 0xe6, 0x1e, 0x24, 0x34, 0x57, 0xb9, 0xec, 0xee, 0xb8, 0xbd,
+	0xf8, 0xc8, 0xde, 0x1f, 0x4c, 0x63, 0x32, 0x2f, 0xd9, 0x5d, 0xad, 0xf9, 0xb6, 0xe7, 0x8d, 0xdc,
+	0x84, 0xc6, 0xd7, 0xa9, 0xf1, 0xfb, 0x09, 0x8d, 0x97, 0x51, 0x22, 0xde, 0xf6, 0xed, 0xc1, 0x14,
+	0x1e, 0xf3, 0x37, 0xd8, 0x4a, 0xb3, 0x3f, 0x3a, 0xef, 0xb8, 0x75, 0xb1, 0x29, 0x4d, 0x6a, 0xe3,
+	0x9a, 0x68, 0x63, 0x9b, 0x68, 0xc1, 0xd6, 0x5c, 0x6e, 0x4a, 0x18, 0x37, 0xe8, 0x4f, 0xd8, 0x3d,
+	0xad, 0xdb, 0x3e, 0xf4, 0xd5, 0x7b, 0xe9, 0x0e, 0xeb, 0x8d, 0x21, 0x2c, 0xe8, 0x9e, 0xdf, 0x76,
+	0x3a, 0xbc, 0xdf, 0xd7, 0x48, 0xe7, 0xc3, 0x84, 0x7e, 0xd7, 0x84, 0x48, 0x31, 0x90, 0x10, 0x3d,
+	0xb7, 0x06, 0x53, 0xb9, 0xcc, 0x36, 0x7b, 0x77, 0xc2, 0xca, 0x80, 0x05, 0x99, 0xdd, 0xa0, 0x86,
+	0xad, 0x69, 0x8b, 0xa3, 0x54, 0x84, 0x16, 0x6f, 0x8e, 0x5d, 0x1e, 0xa5, 0x86, 0xf9, 0x53, 0x83,
+	0x3d, 0xbc, 0xda, 0x0a, 0xc1, 0x66, 0xaf, 0x53, 0xb3, 0x8f, 0xae, 0xba, 0x48, 0xa8, 0xf9, 0x3b,
+	0x53, 0x97, 0x09, 0x74, 0xe3, 0xf7, 0x0d, 0x76, 0xff, 0x2a, 0x2b, 0x05, 0x3b, 0xb1, 0x39, 0x76,
+	0xd2, 0x93, 0x16, 0x02, 0xf5, 0xc1, 0x9a, 0xb6, 0x5c, 0xa0, 0x0b, 0x3f, 0x33, 0xd8, 0x83, 0x2b,
+	0x59, 0x1d, 0xfb, 0xf0, 0x16, 0xf5, 0xe1, 0xc3, 0x2b, 0x1b, 0x9e, 0x7a, 0x71, 0x77, 0xba, 0xe9,
+	0xa1, 0x1f, 0x4f, 0x19, 0xab, 0x42, 0x44, 0x69, 0xf7, 0x7b, 0x7b, 0xee, 0x65, 0xf6, 0x5d, 0x6a,
+	0x68, 0x5d, 0xfa, 0x99, 0x80, 0x00, 0xea, 0x14, 0x36, 0xf3, 0x13, 0xb6, 0x58, 0xdc, 0x47, 0x55,
+	0xb6, 0xfb, 0x6d, 0xf6, 0x3d, 0x92, 0xc9, 0x08, 0x99, 0x00, 0x0f, 0x22, 0x21, 0x93, 0xf9, 0x03,
+	0xb6, 0xcc, 0x01, 0xde, 0x78, 0xf6, 0xb6, 0xb6, 0x3d, 0x54, 0x12, 0x6e, 0x0f, 0x15, 0x36, 0x0f,
+	0xd8, 0xc6, 0x68, 0xd0, 0xc4, 0x95, 0xd8, 0xe8, 0x28, 0x93, 0x93, 0x7d, 0x9f, 0x54, 0xdc, 0x10,
+	0x2a, 0x8e, 0x89, 0x25, 0xa2, 0xc8, 0xe4, 0x82, 0xc5, 0x8e, 0xa2, 0xee, 0x2b, 0x76, 0x0d, 0x24,
+	0x5e, 0x45, 0xb5, 0x59, 0xa4, 0x2d, 0x2b, 0xa7, 0x18, 0x39, 0x22, 0xca, 0xd6, 0x49, 0x4c, 0xd3,
+	0x05, 0x71, 0xd1, 0x76, 0x5b, 0x38, 0x71, 0x77, 0xb4, 0xb8, 0xc8, 0x91, 0x18, 0x17, 0xf9, 0x97,
+	0x59, 0x60, 0x6b, 0x5c, 0x5b, 0xc1, 0xf1, 0x1b, 0x17, 0x65, 0xdf, 0xed, 0x66, 0xef, 0x92, 0xc4,
+	0xa6, 0x36, 0x03, 0x01, 0x15, 0x44, 0xa3, 0x02, 0xe6, 0x2e, 0x5b, 0x57, 0x50, 0xb6, 0xeb, 0x8d,
+	0x3a, 0x7e, 0xf6, 0x9e, 0xd6, 0xed, 0x18, 0x1d, 0xbb, 0x1d, 0x43, 0xf2, 0xde, 0xd4, 0x2e, 0x86,
+	0xae, 0x77, 0xd1, 0xef, 0x34, 0xcb, 0xbd, 0xb6, 0x9f, 0xfd, 0x20, 0xd2, 0x1b, 0x8d, 0xca, 0x7b,
+	0xa3, 0xa1, 0xcc, 0x1a, 0xbb, 0xae, 0xa0, 0x8a, 0x61, 0xa8, 0xbe, 0x4f, 0x9a, 0x6e, 0xc5, 0x35,
+	0x15, 0xd5, 0x58, 0x9d, 0x2c, 0x6c, 0x3e, 0x67, 0x9b, 0x89, 0x04, 0x2f, 0xfb, 0x40, 0x0b, 0xb0,
+	0xc9, 0x4c, 0x18, 0x60, 0x93, 0x29, 0x51, 0xc5, 0xed, 0xc1, 0x05, 0xf8, 0x25, 0xf7, 0x35, 0x28,
+	0x7e, 0x38, 0x56, 0x71, 0xc8, 0x14, 0x55, 0x1c, 0x52, 0xcc, 0x3d, 0x66, 0x16, 0xf7, 0x8f, 0x9c,
+	0x21, 0xae, 0x87, 0x6a, 0xbb, 0xd5, 0x83, 0x34, 0x68, 0xe8, 0x66, 0x1f, 0x69, 0x6b, 0x33, 0xce,
+	0x80, 0x6b, 0x33, 0x8e, 0x35, 0x4b, 0x2c, 0xa3, 0x34, 0x73, 0xe2, 0x74, 0x46, 0x6e, 0xf6, 0x43,
+	0x2d, 0x53, 0x89, 0x92, 0x31, 0x53, 0x89, 0xe2, 0xcc, 0x2f, 0xd9, 0x6a, 0xa1, 0x50, 0x15, 0x5b,
+	0x6f, 0xe4, 0x42, 0x16, 0xf6, 0x91, 0x96, 0xef, 0xe9, 0x44, 0xcc, 0xf7, 0x74, 0x0c, 0xee, 0x56,
+	0xc0, 0x84, 0xc3, 0xf9, 0x58, 0xdb, 0xad, 0x2a, 0x09, 0x77, 0xab, 0x0a, 0x9b, 0x1f, 0xb3, 0x05,
+	0x80, 0xc9, 0xdf, 0x65, 0x1f, 0x93, 0xd8, 0x5a, 0x28, 0x46, 0x68, 0x10, 0x09, 0x58, 0xcc, 0xb7,
+	0xd9, 0x42, 0xa3, 0xd3, 0x06, 0x13, 0x95, 0x9b, 0xd9, 0x5b, 0xc0, 0x3e, 0x6b, 0x07, 0xb0, 0xb9,
+	0xc9, 0xe6, 0x7c, 0xb7, 0xe7, 0xc0, 0x9a, 0x7a, 0x02, 0x94, 0x45, 0x5b, 0x40, 0x66, 0x96, 0xcd,
+	0x83, 0xc6, 0x97, 0xed, 0x8e, 0x9b, 0xfd, 0x84, 0x08, 0x12, 0x34, 0x1f, 0xb0, 0x35, 0x6a, 0xab,
+	0xd1, 0xef, 0x9c, 0x40, 0x26, 0x08, 0xde, 0x2a, 0xbb, 0x45, 0x4a, 0xa3, 0xe8, 0xc2, 0x22, 0x9b,
+	0x6f, 0xf4, 0x7b, 0xa0, 0xd0, 0xb7, 0xfe, 0xce, 0x60, 0x4b, 0x55, 0x77, 0xf8, 0xaa, 0xdd, 0x70,
+	0xcb, 0xbd, 0x97, 0x7d, 0xd3, 0x64, 0x33, 0x3d, 0xa7, 0xeb, 0x66, 0x0d, 0xd2, 0x4d, 0xdf, 0xe6,
+	0x6d, 0xb6, 0xd4, 0x74, 0xbd, 0xc6, 0xb0, 0x3d, 0xf0, 0x51, 0x69, 0x8a, 0x48, 0x2a, 0x0a, 0x07,
+	0x82, 0xfe, 0xa1, 0x0d, 0x19, 0x68, 0x36, 0x4d, 0xe4, 0x00, 0x86, 0x39, 0x59, 0x6c, 0x74, 0x8e,
+	0x46, 0xe7, 0xe0, 0x09, 0x3c, 0xc8, 0xd6, 0xd3, 0xca, 0xa4, 0xc0, 0x22, 0x20, 0xbc, 0x1d, 0x72,
+	0x98, 0x8f, 0x58, 0x26, 0xd2, 0x5d, 0x0f, 0x12, 0xf6, 0x34, 0x0c, 0x23, 0x86, 0xb7, 0x7e, 0x65,
+	0xb0, 0xd5, 0x7c, 0xa3, 0xe1, 0x0e, 0x7c, 0x07, 0x12, 0x0a, 0xb4, 0x21, 0x4e, 0x4f, 0x7f, 0xd8,
+	0xaa, 0x84, 0x43, 0x90, 0xa0, 0x79, 0x97, 0xad, 0x0c, 0xdd, 0x57, 0xae, 0xd3, 0x71, 0x9b, 0x79,
+	0xdf, 0x1f, 0x7a, 0x30, 0x8e, 0x34, 0xd0, 0x75, 0x24, 0xca, 0x53, 0x38, 0x04, 0x7a, 0x9a, 0xe8,
+	0x12, 0x34, 0x73, 0x8c, 0x0d, 0xa0, 0x05, 0x0a, 0xe6, 0x72, 0x20, 0x66, 0x38, 0x10, 0x49, 0xb2,
+	0x15, 0x2e, 0x34, 0x62, 0xd7, 0x79, 0x9d, 0x6f, 0xb9, 0x74, 0xe6, 0x48, 0xdb, 0x02, 0xc2, 0x19,
+	0x85, 0x90, 0xd4, 0xe2, 0x91, 0x91, 0x9f, 0x2b, 0x60, 0x46, 0x15, 0x94, 0xf5, 0x05, 0x5b, 0xd3,
+	0x47, 0xe6, 0x99, 0x1f, 0xb2, 0x59, 0x74, 0xd9, 0x1e, 0x0c, 0x2c, 0xad, 0xac, 0x67, 0x9d, 0xcd,
+	0xe6, 0x3c, 0xd6, 0x1e, 0x5b, 0xc4, 0x01, 0xb5, 0xcf, 0x47, 0x90, 0x99, 0x6e, 0xb0, 0xd9, 0x76,
+	0xaf, 0xe9, 0xbe, 0xa6, 0x29, 0x99, 0xb5, 0x39, 0x10, 0x98, 0x3a, 0xa5, 0x98, 0x1a, 0x38, 0xbf,
+	0xe9, 0xf5, 0x7f, 0xdc, 0xa3, 0x63, 0xd5, 0x82, 0xcd, 0x01, 0xeb, 0x53, 0xb6, 0x0c, 0xa9, 0x5b,
+	0xa8, 0xef, 0x2e, 0x9b, 0x71, 0x00, 0x20, 0x75, 0x61, 0xf0, 0x0b, 0xe8, 0x36, 0x51, 0xad, 0x5f,
+	0x67, 0x6b, 0x55, 0xc0, 0xf4, 0x5a, 0x71, 0xc1, 0xd4, 0x44, 0xc1, 0xcf, 0xd8, 0x4a, 0xa1, 0xd3,
+	0x3f, 0x7f, 0xd3, 0xf6, 0x40, 0x0c, 0xc2, 0xba, 0xfb, 0x1d, 0xc4, 0x0a, 0xfd, 0x7e, 0xe7, 0x4d,
+	0xc5, 0x0e, 0xd8, 0x4a, 0xa9, 0x37, 0xea, 0xbe, 0xa1, 0x18, 0xae, 0x88, 0x57, 0xe8, 0xa6, 0xe4,
+	0xf2, 0x13, 0x90, 0xf5, 0x15, 0x78, 0xad, 0x4b, 0x58, 0x32, 0x6f, 0xaa, 0x0f, 0x8c, 0xe8, 0xb5,
+	0x7f, 0x87, 0x1b, 0x71, 0xd6, 0xa6, 0x6f, 0xeb, 0x0f, 0xd3, 0x6c, 0x05, 0xd7, 0x42, 0xa8, 0xeb,
+	0xfb, 0x8c, 0x79, 0x81, 0x29, 0x84, 0xc6, 0xcd, 0xe0, 0x18, 0xab, 0xd9, 0x08, 0x93, 0x9d, 0x90,
+	0xd7, 0x7c, 0x02, 0xfb, 0x81, 0x9b, 0x5e, 0x18, 0x4d, 0xfa, 0x41, 0x75, 0x41, 0x80, 0x8c, 0xe4,
+	0x82, 0x6d, 0xb2, 0x70, 0x2e, 0x8c, 0x47, 0xae, 0x20, 0x3c, 0xfe, 0x6a, 0x36, 0x45, 0x3f, 0x28,
+	0xf9, 0x50, 0xa6, 0x29, 0x2c, 0x27, 0xce, 0xf3, 0x52, 0x46, 0x33, 0x28, 0xca, 0x48, 0x3e, 0x6a,
+	0x47, 0x98, 0x4d, 0x1c, 0xe8, 0x83, 0x76, 0x54, 0x6b, 0x52, 0x3b, 0x02, 0x81, 0x32, 0xae, 0xb0,
+	0x99, 0x38, 0xcb, 0x4b, 0x19, 0xcd, 0x94, 0x28, 0x23, 0xf9, 0xcc, 0xcf, 0xd8, 0xe2, 0xb9, 0x34,
+	0x8c, 0x38, 0xcf, 0x07, 0x91, 0x44, 0x33, 0x18, 0xa6, 0x7c, 0x01, 0x67, 0x61, 0x8e, 0xcd, 0xf8,
+	0x97, 0x03, 0xd7, 0xda, 0x66, 0x1b, 0x68, 0x0a, 0x98, 0xe4, 0x51, 0x03, 0x43, 0x84, 0x0c, 0x32,
+	0x49, 0x7e, 0x16, 0x7c, 0xcf, 0x2b, 0xe1, 0xb8, 0xf9, 0x9e, 0x94, 0xa0, 0xf5, 0x4f, 0x06, 0xb7,
+	0x68, 0xa0, 0x06, 0xd7, 0x51, 0x6f, 0x8f, 0x76, 0x2a, 0xdf, 0xd3, 0x02, 0x32, 0xdf, 0x65, 0xac,
+	0xc7, 0x43, 0xbf, 0xef, 0x36, 0xc5, 0xaa, 0x50, 0x30, 0xd8, 0x46, 0x6f, 0xb7, 0xdd, 0x84, 0x1c,
+	0x8e, 0xac, 0x33, 0x6b, 0x4b, 0xd0, 0xfc, 0x94, 0x31, 0x47, 0x8e, 0x45, 0xfa, 0x37, 0x39, 0x3d,
+	0xda, 0x6a, 0xb2, 0x15, 0xbe, 0x60, 0x1c, 0xb3, 0xc9, 0xe3, 0x98, 0xd3, 0xc7, 0x61, 0xb1, 0x39,
+	0x7e, 0x6b, 0x82, 0x3c, 0xd5, 0x11, 0x78, 0x2e, 0xcf, 0xa3, 0x01, 0x2c, 0xd8, 0x12, 0xb4, 0x0e,
+	0xd9, 0xca, 0x91, 0x70, 0xf4, 0xa5, 0xe1, 0xb0, 0x3f, 0xc4, 0x8d, 0x50, 0xec, 0x37, 0xf9, 0x54,
+	0xad, 0x06, 0x1b, 0x81, 0x68, 0x88, 0xb7, 0x89, 0x8a, 0x0a, 0xc5, 0xe5, 0x90, 0x9c, 0x3c, 0x01,
+	0x5a, 0x59, 0x36, 0xc7, 0xcf, 0x9e, 0xe6, 0x2a, 0x4b, 0x9d, 0x6e, 0x91, 0x9e, 0x65, 0x1b, 0xbe,
+	0xac, 0xc7, 0x6c, 0x59, 0x3d, 0x9b, 0x46, 0xe9, 0x04, 0xe7, 0x48, 0x1d, 0xc2, 0x39, 0xeb, 0x1d,
+	0xe8, 0x9a, 0x76, 0x65, 0xb3, 0xcc, 0x8c, 0x5d, 0xc1, 0x6f, 0xec, 0x5a, 0x39, 0xb6, 0x91, 0x74,
+	0x39, 0x83, 0x5c, 0xa7, 0x92, 0xeb, 0x14, 0x21, 0x5b, 0xe8, 0x34, 0x6c, 0xeb, 0x23, 0xb6, 0xaa,
+	0x5f, 0x40, 0xc5, 0xb9, 0xcf, 0x24, 0xf7, 0x19, 0xcc, 0xdf, 0xcc, 0x91, 0xd3, 0x1e, 0x22, 0x36,
+	0x2f, 0x79, 0xf2, 0x08, 0x15, 0x24, 0x4f, 0xc1, 0xfa, 0x85, 0xc1, 0x36, 0x93, 0xaf, 0x60, 0xe2,
+	0xaa, 0xf3, 0x52, 0x4c, 0x28, 0x49, 0x0b, 0x25, 0x38, 0x9b, 0x87, 0x22, 0x8c, 0xce, 0xf0, 0xd9,
+	0x14, 0x20, 0xec, 0xa1, 0xd9, 0xe2, 0x85, 0xd3, 0xee, 0x51, 0x50, 0x0e, 0x53, 0x5d, 0xed, 0x58,
+	0x8c, 0xf4, 0xfd, 0x76, 0xef, 0x1b, 0x9b, 0xb3, 0x5a, 0xb7, 0x59, 0x26, 0x7a, 0xc9, 0x84, 0xed,
+	0xbd, 0x90, 0x7d, 0x79, 0x61, 0x0d, 0x19, 0x7b, 0xd6, 0x76, 0xfc, 0xea, 0x85, 0xd3, 0x85, 0xe1,
+	0x41, 0x26, 0x13, 0xe9, 0xba, 0xe0, 0x8c, 0xa2, 0xcd, 0x5b, 0x70, 0x16, 0xbb, 0x70, 0x3a, 0x1d,
+	0xb7, 0x27, 0xec, 0xbe, 0x6c, 0x87, 0x08, 0xa4, 0x06, 0x0d, 0x52, 0x38, 0x07, 0x6a, 0x80, 0xb0,
+	0x2e, 0xd9, 0x7a, 0xd8, 0x66, 0xbe, 0xe3, 0xf5, 0x2b, 0x6e, 0xeb, 0xff, 0xae, 0xe9, 0x45, 0xb5,
+	0xe9, 0xbf, 0x34, 0x58, 0x76, 0xdc, 0x3d, 0x96, 0x79, 0x47, 0x5a, 0x69, 0xdc, 0x1d, 0x25, 0x1a,
+	0xef, 0x8e, 0x34, 0xde, 0x78, 0xa6, 0x3c, 0x32, 0x15, 0x84, 0x13, 0x1e, 0xc7, 0x34, 0xc1, 0xd4,
+	0xd6, 0xdf, 0x1a, 0xec, 0xfd, 0xa9, 0xf7, 0x0e, 0x49, 0x9b, 0x26, 0xbf, 0x25, 0x37, 0x4d, 0x9e,
+	0xe0, 0xc2, 0x96, 0x58, 0x59, 0xf0, 0x25, 0x36, 0xd5, 0x8c, 0xdc, 0x54, 0xc4, 0x9f, 0x23, 0xff,
+	0x81, 0xfc, 0x04, 0x17, 0x72, 0xe4, 0x38, 0x90, 0x3f, 0xc7, 0xf7, 0xcb, 0xbc, 0xd8, 0x2f, 0x08,
+	0x55, 0xe9, 0x42, 0x14, 0xa0, 0x2a, 0x7a, 0x41, 0x71, 0x04, 0x5d, 0xe4, 0x49, 0x32, 0x87, 0xac,
+	0xbf, 0x31, 0xd8, 0x8d, 0x31, 0x3d, 0xaf, 0x94, 0xcd, 0x1f, 0xb2, 0x99, 0xc0, 0xb0, 0x6f, 0x70,
+	0x0d, 0x67, 0xcf, 0x5c, 0xc1, 0xee, 0xb4, 0xac, 0xc5, 0x36, 0x7a, 0x01, 0xc9, 0xec, 0x7c, 0x11,
+	0x13, 0xed, 0xd7, 0xf2, 0x9e, 0x5a, 0x7a, 0xaf, 0x4a, 0x59, 0xe0, 0x6d, 0xc9, 0x60, 0xfd, 0x63,
+	0x8a, 0xdd, 0xb9, 0xc2, 0x2d, 0x8f, 0x79, 0x2f, 0x98, 0xef, 0xb1, 0x56, 0x45, 0x33, 0xdc, 0x0b,
+	0xcc, 0x30, 0x9e, 0x2d, 0x4f, 0x6c, 0xc2, 0x3a, 0xe3, 0xd9, 0x0a, 0xc4, 0x26, 0x8c, 0x36, 0xa1,
+	0xd1, 0x1c, 0x35, 0x9a, 0x9b, 0x78, 0xbf, 0x4e, 0x26, 0xbe, 0x17, 0x98, 0x78, 0x42, 0xa3, 0xdf,
+	0xcd, 0xf2, 0x7d, 0xdd, 0xf0, 0xda, 0x0d, 0x1d, 0x1e, 0x53, 0x0a, 0x1d, 0x4c, 0x7e, 0x9b, 0xd2,
+	0x7b, 0x06, 0xb0, 0x42, 0x93, 0xbe, 0x34, 0x80, 0x79, 0x47, 0xd2, 0x5a, 0x47, 0x66, 0x44, 0x47,
+	0xac, 0x5f, 0x1a, 0xec, 0xe6, 0x84, 0x3b, 0x41, 0x73, 0x2b, 0xd2, 0xe6, 0xd8, 0x11, 0x87, 0x5d,
+	0xd9, 0x8a, 0x74, 0x65, 0xaa, 0xc8, 0xe4, 0x1e, 0xfe, 0x81, 0xc1, 0x6e, 0x4f, 0xbb, 0xb9, 0x33,
+	0x33, 0x2c, 0x7d, 0xba, 0x25, 0xb7, 0x31, 0x7e, 0x72, 0x8c, 0x8c, 0x7e, 0xf8, 0x49, 0x98, 0x9c,
+	0xdc, 0xca, 0xf8, 0xc9, 0x31, 0x72, 0x33, 0xe3, 0x27, 0x0f, 0x2a, 0xb3, 0x5a, 0x50, 0x99, 0x93,
+	0x91, 0xe9, 0x4f, 0x52, 0xcc, 0x9a, 0x7e, 0x85, 0x68, 0xde, 0x0f, 0xbb, 0x32, 0x76, 0xe4, 0xd4,
+	0xc3, 0xfb, 0x61, 0x0f, 0x27, 0x31, 0xe6, 0x88, 0x31, 0x37, 0x65, 0x95, 0xd3, 0x78, 0xee, 0x87,
+	0xe3, 0x99, 0xc4, 0x98, 0xe3, 0xee, 0x77, 0xf6, 0x2a, 0xee, 0x77, 0x6e, 0xb2, 0xfb, 0xb5, 0x7e,
+	0x8b, 0x6d, 0xc6, 0xae, 0x34, 0xe9, 0x5c, 0x3d, 0x29, 0xc8, 0x63, 0xda, 0xb5, 0xeb, 0x78, 0x17,
+	0xc2, 0x16, 0xf4, 0x8d, 0x5b, 0xe2, 0x45, 0xbe, 0x33, 0xb8, 0x70, 0x84, 0x3d, 0x04, 0x84, 0x09,
+	0x41, 0x36, 0xb9, 0x09, 0x98, 0xec, 0x3b, 0xb2, 0x91, 0xa9, 0x03, 0x49, 0x4d, 0x89, 0x23, 0x6f,
+	0xd2, 0xa5, 0xff, 0x32, 0xf4, 0x51, 0x2b, 0xb7, 0x8a, 0x70, 0x4c, 0xaf, 0x76, 0xc1, 0x9b, 0xe6,
+	0x6b, 0xfd, 0x1d, 0xa7, 0xdb, 0x95, 0xe1, 0x57, 0x47, 0x06, 0x5c, 0x05, 0xc9, 0x95, 0x52, 0xb8,
+	0x24, 0x12, 0xf7, 0x74, 0xa0, 0x86, 0x77, 0x2b, 0x80, 0x69, 0xbf, 0x4b, 0xda, 0x8c, 0xd8, 0xef,
+	0x92, 0xf6, 0x31, 0x4b, 0xd5, 0xb6, 0x84, 0x79, 0xdf, 0x19, 0x77, 0xef, 0x4c, 0x33, 0x68, 0x03,
+	0x23, 0xb1, 0x4b, 0x77, 0x36, 0x95, 0x3d, 0x67, 0xfd, 0x5b, 0x4a, 0xb7, 0x47, 0x38, 0x78, 0xb0,
+	0xc7, 0xe7, 0x49, 0xc3, 0x1f, 0x3b, 0xed, 0x91, 0x59, 0xf9, 0x3c, 0x69, 0x56, 0xa6, 0x08, 0x07,
+	0x83, 0xde, 0x8a, 0x4c, 0xd6, 0x78, 0xaf, 0x93, 0x57, 0x44, 0xb4, 0x39, 0x9c, 0xe0, 0xa8, 0xa4,
+	0xc8, 0x13, 0x65, 0x6a, 0xdf, 0x9b, 0x38, 0x57, 0xa5, 0x22, 0x4d, 0xee, 0x13, 0x65, 0x72, 0xaf,
+	0x20, 0x90, 0xb3, 0xfe, 0x3b, 0xe2, 0x65, 0xc6, 0xd4, 0x7d, 0x94, 0xb4, 0xc7, 0xd0, 0x33, 0x5c,
+	0x9e, 0xd0, 0xa4, 0x22, 0xa7, 0x80, 0x74, 0x90, 0xb0, 0xc0, 0x42, 0x87, 0xd8, 0x9c, 0x17, 0xab,
+	0x86, 0xbe, 0x05, 0xae, 0x20, 0x3c, 0x1f, 0x7d, 0x9b, 0x3f, 0x62, 0x4c, 0xb9, 0xf3, 0x1f, 0xbf,
+	0x3c, 0x42, 0x26, 0x9b, 0xe9, 0x1b, 0xa1, 0xe6, 0x0c, 0x5b, 0xae, 0x2f, 0xbb, 0x39, 0x4f, 0xdd,
+	0xd4, 0x91, 0x60, 0x02, 0x76, 0xd4, 0xf7, 0x3c, 0x5e, 0x9d, 0x10, 0x95, 0x62, 0x59, 0xc1, 0x08,
+	0xb3, 0x5b, 0x5b, 0x61, 0x52, 0x93, 0x92, 0xc5, 0x29, 0x49, 0x49, 0x98, 0xed, 0xb3, 0xab, 0x67,
+	0xfb, 0x7f, 0x9d, 0x66, 0x77, 0xaf, 0x52, 0xa5, 0x99, 0x60, 0x82, 0x7b, 0x81, 0x09, 0xa6, 0xe5,
+	0x38, 0xc2, 0x32, 0x13, 0xb3, 0x92, 0x87, 0x8a, 0xc1, 0xc6, 0x32, 0x72, 0x3b, 0x3e, 0x54, 0xec,
+	0x38, 0x91, 0xb5, 0x60, 0x7e, 0x99, 0x60, 0xde, 0xf7, 0x26, 0x9a, 0x17, 0x16, 0xe8, 0x9b, 0x1b,
+	0xf8, 0x69, 0x82, 0x81, 0xaf, 0xc5, 0x0c, 0x8c, 0xaa, 0xbf, 0x9b, 0x89, 0xad, 0x7f, 0x4d, 0xb1,
+	0x6b, 0xc5, 0x2a, 0x1c, 0x2b, 0x3b, 0x9d, 0xb6, 0x3b, 0xac, 0xba, 0x8d, 0xa1, 0xeb, 0x63, 0xd5,
+	0x06, 0x02, 0x4e, 0x45, 0x86, 0x9f, 0x0a, 0x42, 0x3b, 0x32, 0xfc, 0xec, 0x88, 0x2d, 0x92, 0x8e,
+	0x6c, 0x11, 0x2d, 0xa7, 0x3f, 0x7d, 0x2a, 0x73, 0xfa, 0xd3, 0xa7, 0x78, 0xad, 0xb8, 0xbd, 0xdf,
+	0x6f, 0x1d, 0x89, 0x5c, 0x80, 0x03, 0x12, 0xbb, 0x23, 0x72, 0x3c, 0x0e, 0x48, 0xec, 0xd7, 0x22,
+	0xd7, 0xe3, 0x80, 0xf9, 0x09, 0xbb, 0x76, 0xe2, 0x0e, 0x21, 0xad, 0xc2, 0x8b, 0xce, 0x52, 0x8f,
+	0xbf, 0xd0, 0xa8, 0xd0, 0xe8, 0x96, 0xed, 0x24, 0x12, 0x2c, 0xdd, 0x8d, 0x38, 0x7a, 0x67, 0x8b,
+	0x1e, 0x2b, 0x2c, 0xdb, 0x89, 0xb4, 0x64, 0x99, 0xdd, 0x2d, 0x7a, 0x81, 0x90, 0x28, 0xb3, 0xbb,
+	0x85, 0x33, 0xb3, 0x97, 0x5d, 0xa6, 0xbb, 0x14, 0x63, 0x0f, 0x47, 0xbe, 0xb7, 0x95, 0x5d, 0x21,
+	0x10, 0xbe, 0xac, 0x5f, 0xa5, 0x58, 0x26, 0x9c, 0x5d, 0x7e, 0xc9, 0x3d, 0x6d, 0x6a, 0xcf, 0x82,
+	0xa9, 0x3d, 0xa3, 0xa9, 0x3d, 0x0b, 0xa6, 0xf6, 0x8c, 0xa6, 0xf6, 0x2c, 0x98, 0xda, 0xb3, 0xff,
+	0xcf, 0x53, 0xfb, 0x43, 0xb5, 0x78, 0x8b, 0x63, 0xa3, 0xab, 0x54, 0xe1, 0x4a, 0x38, 0x40, 0xd7,
+	0xf9, 0xcd, 0x5a, 0xff, 0x1b, 0x37, 0xb8, 0x52, 0x13, 0xa0, 0x75, 0x5b, 0x1e, 0x20, 0x94, 0xa3,
+	0x84, 0xa1, 0x1d, 0x25, 0xfe, 0x28, 0xad, 0x14, 0x7a, 0x31, 0xd5, 0x85, 0x6d, 0x2f, 0x13, 0x64,
+	0xf8, 0xc4, 0xab, 0x36, 0xba, 0x73, 0x0b, 0xab, 0x09, 0xcb, 0xb6, 0x82, 0x31, 0x1f, 0x33, 0x53,
+	0x29, 0xc2, 0x1d, 0xbe, 0xe4, 0x7c, 0xfc, 0x1a, 0x22, 0x81, 0x82, 0xc5, 0x23, 0x50, 0xcb, 0x8b,
+	0x47, 0x33, 0xe3, 0x1c, 0x79, 0xc0, 0x82, 0x93, 0x73, 0x2c, 0x33, 0xed, 0x63, 0x30, 0xe2, 0xdc,
+	0x31, 0x17, 0x9d, 0xd3, 0x8a, 0xa2, 0xb1, 0x1b, 0x0e, 0x5b, 0xf0, 0x99, 0x07, 0x2c, 0x1b, 0xef,
+	0x84, 0x28, 0x48, 0xcc, 0x93, 0xb7, 0x4f, 0x68, 0x7e, 0xac, 0x08, 0xce, 0x7f, 0xa5, 0xdf, 0x6b,
+	0xb8, 0x72, 0x6d, 0x11, 0x80, 0x05, 0xc2, 0x6d, 0x17, 0x8b, 0x4b, 0x30, 0xa7, 0x6d, 0xcf, 0x1f,
+	0x3a, 0x54, 0x41, 0x5a, 0xd4, 0x1e, 0x34, 0x3d, 0x77, 0xcf, 0xf3, 0x23, 0xff, 0xa2, 0xa7, 0xb2,
+	0xd8, 0x09, 0x62, 0xd6, 0xdf, 0x1b, 0x7a, 0x1d, 0x3d, 0x9e, 0x21, 0x97, 0xe4, 0x3e, 0x2a, 0xa1,
+	0xbd, 0x4e, 0xb6, 0x82, 0xc3, 0x0a, 0x7c, 0xe2, 0x14, 0xe5, 0xd5, 0xd9, 0x9d, 0x30, 0x45, 0x9c,
+	0xcf, 0xfc, 0x8c, 0xcd, 0x3f, 0x6f, 0xfb, 0x3d, 0xbc, 0xa4, 0x9c, 0xd5, 0xba, 0x0c, 0x83, 0xb3,
+	0xdd, 0x57, 0xfd, 0x06, 0xf5, 0x4b, 0xb0, 0xd8, 0x92, 0x17, 0xa7, 0x02, 0xd6, 0x4f, 0x79, 0x5b,
+	0xdc, 0x7e, 0x72, 0xc0, 0x72, 0x63, 0x55, 0x70, 0x5c, 0xd1, 0xe5, 0x26, 0x0d, 0x20, 0x6d, 0xa7,
+	0x78, 0xcd, 0x4f, 0xac, 0xc4, 0x94, 0xba, 0x12, 0xc9, 0x9d, 0x8b, 0xf7, 0x06, 0xe9, 0xe4, 0xf7,
+	0x06, 0xb6, 0x64, 0xb0, 0x7a, 0x09, 0x85, 0xf2, 0x58, 0x43, 0x4f, 0xb5, 0xd8, 0x95, 0x1a, 0xfb,
+	0x1c, 0x41, 0x8b, 0x57, 0x30, 0x2c, 0xba, 0x74, 0x15, 0x15, 0x3e, 0x0e, 0x58, 0x3f, 0x88, 0x95,
+	0xd3, 0xb9, 0x21, 0x0c, 0x69, 0x08, 0xbc, 0xe9, 0x6d, 0xb7, 0x7a, 0xae, 0xd8, 0x23, 0xb3, 0xb6,
+	0x04, 0xad, 0x9f, 0x19, 0x63, 0xca, 0xe8, 0xd8, 0x54, 0x59, 0x2d, 0x58, 0x11, 0x40, 0x77, 0x6a,
+	0xc2, 0x91, 0x56, 0xe4, 0xcd, 0x4b, 0x80, 0x50, 0xa9, 0x3b, 0xc2, 0xec, 0x21, 0x02, 0xd3, 0x7d,
+	0x70, 0x2c, 0x60, 0xe6, 0xa1, 0x2b, 0xd3, 0x7d, 0x09, 0x5b, 0xa7, 0xe3, 0xea, 0xee, 0xe6, 0x17,
+	0x6c, 0x49, 0x2d, 0xc3, 0x1b, 0x5a, 0x12, 0x94, 0x28, 0x63, 0xab, 0x02, 0xd6, 0xd7, 0xfa, 0x00,
+	0x83, 0xca, 0x39, 0xe6, 0x8b, 0xcf, 0x86, 0xfd, 0xae, 0x18, 0x1f, 0x7d, 0xa3, 0x91, 0x6a, 0x7d,
+	0x71, 0x65, 0x0f, 0x5f, 0x38, 0x09, 0xbc, 0x08, 0xce, 0x07, 0xc3, 0x81, 0x68, 0x67, 0x95, 0x62,
+	0x3c, 0x76, 0x56, 0x29, 0xed, 0x8f, 0xef, 0x6c, 0xc0, 0x64, 0xab, 0x02, 0xd6, 0x27, 0x49, 0xc5,
+	0xfc, 0xf8, 0x1e, 0xab, 0xc9, 0x3d, 0x56, 0xb3, 0x1e, 0xc4, 0x2b, 0xf6, 0x61, 0xaf, 0x85, 0x1f,
+	0xe6, 0xbd, 0xfe, 0x73, 0x23, 0x5a, 0x95, 0x47, 0x7b, 0x91, 0xb3, 0x3c, 0xf0, 0x5a, 0xbc, 0xb3,
+	0x60, 0xaf, 0x00, 0xc1, 0xbd, 0x5b, 0x4a, 0x7a, 0x37, 0xed, 0xce, 0x2d, 0x9d, 0x70, 0xd7, 0x5a,
+	0x85, 0x85, 0x3e, 0xe8, 0xf7, 0x3c, 0x69, 0xdc, 0x10, 0x61, 0x5a, 0x6c, 0x19, 0x34, 0x4a, 0x90,
+	0x17, 0x93, 0x97, 0x6d, 0x0d, 0x67, 0x7d, 0x5f, 0x2f, 0xf9, 0x4f, 0x74, 0x2c, 0x74, 0xb9, 0x92,
+	0x96, 0x97, 0x2b, 0xff, 0x90, 0x0a, 0x4b, 0xfe, 0xb8, 0x7f, 0xc1, 0x73, 0xb4, 0x45, 0x3e, 0xbb,
+	0x6c, 0x0b, 0x08, 0xad, 0x9d, 0x2f, 0x38, 0x43, 0xa1, 0x83, 0xbe, 0x51, 0xcd, 0xb6, 0x54, 0xb3,
+	0xad, 0x0f, 0x70, 0x26, 0x61, 0x80, 0xa5, 0x60, 0x80, 0xdc, 0xe5, 0x87, 0x08, 0x8c, 0x43, 0x76,
+	0x2e, 0x20, 0xf3, 0x34, 0x40, 0xc1, 0x10, 0xfd, 0x69, 0x40, 0x9f, 0x17, 0xf4, 0x00, 0xa3, 0x4f,
+	0xdf, 0xc2, 0xb4, 0xe9, 0x5b, 0x8c, 0x4f, 0x1f, 0x6e, 0x2e, 0x5b, 0x54, 0xd1, 0xe9, 0xa0, 0x30,
+	0x6b, 0x07, 0x30, 0xca, 0xcb, 0x6f, 0xb2, 0xf4, 0x12, 0x97, 0x57, 0x71, 0xd6, 0xbf, 0x1b, 0xcc,
+	0x8c, 0x3f, 0x61, 0x4a, 0x08, 0xb9, 0x41, 0x90, 0x49, 0xa9, 0x41, 0x06, 0x12, 0xe9, 0x8a, 0xfb,
+	0x63, 0x25, 0x16, 0xf3, 0x18, 0xab, 0x23, 0xc7, 0x84, 0xe3, 0x99, 0xb1, 0xe1, 0x78, 0x52, 0x7c,
+	0x9c, 0x7d, 0xe3, 0xf8, 0x68, 0xfd, 0xc5, 0x0c, 0x5b, 0x8f, 0x3d, 0xac, 0x8a, 0x2c, 0xb4, 0xc7,
+	0x6c, 0x96, 0x07, 0xa8, 0xd4, 0x94, 0x00, 0xc5, 0xd9, 0x22, 0x19, 0x48, 0xfa, 0x8a, 0x19, 0xc8,
+	0xf8, 0x21, 0x03, 0xbf, 0xb4, 0x8b, 0xa2, 0x97, 0xbf, 0xbe, 0x48, 0xa0, 0x80, 0xc7, 0x79, 0x5b,
+	0x62, 0x13, 0xda, 0x99, 0x23, 0xb9, 0x09, 0x1c, 0xf8, 0x14, 0x8b, 0x87, 0xf9, 0x3c, 0x9c, 0x5c,
+	0x86, 0x94, 0x1a, 0xcc, 0x6b, 0x23, 0x97, 0xa9, 0x41, 0x40, 0xb7, 0xa3, 0x02, 0x66, 0x99, 0x99,
+	0x5a, 0x34, 0xe6, 0x13, 0xb8, 0xa0, 0x3d, 0x41, 0x8a, 0x33, 0xd8, 0x09, 0x42, 0x10, 0xee, 0x97,
+	0x6c, 0xe5, 0x55, 0xc6, 0x22, 0x19, 0x39, 0x0c, 0x8b, 0x21, 0xcd, 0x56, 0xf9, 0xf0, 0x61, 0xc8,
+	0x51, 0xf8, 0x30, 0x84, 0x8d, 0x7f, 0x18, 0x12, 0x72, 0x85, 0x29, 0xc2, 0x92, 0x9a, 0x22, 0x7c,
+	0xcb, 0xae, 0xc5, 0x96, 0x48, 0xa5, 0x1c, 0x2e, 0x0b, 0x63, 0xf2, 0x33, 0x3d, 0xb9, 0x2c, 0x94,
+	0xd3, 0x5f, 0x6a, 0xda, 0xe9, 0xef, 0x37, 0xd9, 0x62, 0x80, 0x45, 0x4f, 0x50, 0x03, 0x7f, 0xe5,
+	0xf9, 0x4e, 0x77, 0x20, 0xb2, 0x85, 0x10, 0x31, 0x66, 0xf3, 0xc1, 0xde, 0xe7, 0xb9, 0x7b, 0xf8,
+	0xf4, 0x47, 0xc2, 0xd6, 0x4f, 0xd8, 0xb2, 0x2c, 0xe5, 0x56, 0x7d, 0x77, 0x80, 0xfe, 0xf1, 0xc0,
+	0xf5, 0x2f, 0xfa, 0x4d, 0x99, 0x69, 0x73, 0x88, 0x52, 0x04, 0x71, 0xc0, 0x15, 0x59, 0xba, 0x00,
+	0xcd, 0x07, 0x61, 0x55, 0x97, 0x67, 0x3e, 0xab, 0x62, 0x28, 0x02, 0x1b, 0x54, 0x79, 0xd1, 0xc7,
+	0x6e, 0xf7, 0x7b, 0xae, 0x78, 0xb8, 0x42, 0xdf, 0xd6, 0x01, 0x44, 0xc4, 0xd0, 0x00, 0xc8, 0x52,
+	0xbb, 0x1c, 0x04, 0x35, 0x77, 0xfc, 0x26, 0xd7, 0x2c, 0x1f, 0x37, 0x00, 0x2e, 0x2f, 0xde, 0x68,
+	0x9c, 0xf0, 0x37, 0x1a, 0xbc, 0x70, 0x27, 0x20, 0xeb, 0xa7, 0x33, 0x98, 0x7f, 0x86, 0xa6, 0x1f,
+	0x93, 0xa6, 0x04, 0x75, 0xd5, 0x45, 0xad, 0xae, 0xba, 0x88, 0x97, 0xa4, 0x8f, 0x58, 0x26, 0x72,
+	0xe1, 0xbd, 0x45, 0xfb, 0x71, 0xd1, 0x8e, 0xe1, 0x13, 0x78, 0x73, 0xb4, 0x17, 0xe3, 0xbc, 0x39,
+	0x7c, 0x50, 0x14, 0x84, 0x0b, 0x6f, 0x8b, 0xb6, 0xde, 0xa2, 0xad, 0xa2, 0x74, 0x8e, 0x1c, 0x65,
+	0xf8, 0x1a, 0x47, 0x0e, 0xbd, 0x49, 0x50, 0xa1, 0xdc, 0x82, 0x1d, 0x84, 0x0c, 0x0a, 0x46, 0xa3,
+	0xe7, 0x68, 0x77, 0xa8, 0xf4, 0x9c, 0xf9, 0x11, 0x5b, 0xa7, 0x1b, 0x45, 0x65, 0xa3, 0x6f, 0xd1,
+	0x76, 0x58, 0xb4, 0xe3, 0x04, 0x2c, 0xb4, 0x16, 0xda, 0x2d, 0x8d, 0x77, 0x89, 0x78, 0xa3, 0xe8,
+	0x24, 0xbd, 0x39, 0x38, 0x15, 0x26, 0xea, 0xcd, 0xc5, 0xf5, 0xe6, 0xe0, 0xc8, 0x98, 0xa0, 0x37,
+	0x67, 0xfe, 0x1a, 0x5b, 0x2a, 0x8c, 0x60, 0xf4, 0x3e, 0xbd, 0x7c, 0xce, 0xae, 0x6a, 0x0f, 0x42,
+	0x8a, 0xfb, 0x0a, 0xcd, 0x56, 0x19, 0xad, 0x3a, 0x5b, 0xca, 0x37, 0x1a, 0xa3, 0xee, 0xa8, 0xe3,
+	0xf8, 0xfd, 0xe1, 0xc4, 0xc3, 0x3c, 0xbd, 0x0f, 0x10, 0x41, 0x7e, 0x17, 0xa1, 0x13, 0x59, 0x96,
+	0x39, 0xc1, 0x45, 0x2f, 0x9f, 0xe9, 0xcd, 0xf2, 0x97, 0x18, 0x02, 0xb4, 0xc0, 0x0d, 0x2b, 0x0d,
+	0x08, 0xac, 0xca, 0x6f, 0xe8, 0xfc, 0x0d, 0xb6, 0xae, 0xf0, 0xf3, 0x40, 0x6a, 0x7e, 0xaa, 0xf5,
+	0x52, 0xb8, 0x0e, 0x33, 0x7c, 0x33, 0x26, 0x29, 0xb6, 0x36, 0x18, 0x68, 0x04, 0xbd, 0xe2, 0x37,
+	0xf4, 0x76, 0x04, 0xc3, 0x84, 0x04, 0xad, 0x2f, 0xd8, 0x46, 0xd2, 0xa9, 0x07, 0x07, 0xf5, 0x5c,
+	0x0e, 0xff, 0xb9, 0xda, 0xc9, 0x94, 0xde, 0xc9, 0x41, 0x92, 0x9f, 0xc6, 0x9c, 0xb7, 0x78, 0x2c,
+	0x8b, 0xc7, 0xc5, 0x63, 0x82, 0xe5, 0xeb, 0x08, 0xf8, 0x9a, 0x9e, 0xf8, 0x85, 0x45, 0xf6, 0x99,
+	0x68, 0x91, 0xfd, 0x17, 0x06, 0xdb, 0x48, 0x3a, 0x5b, 0x62, 0x4a, 0x12, 0x3a, 0x4d, 0xf0, 0xc1,
+	0xbc, 0x79, 0x0d, 0x87, 0x8b, 0x0e, 0x7c, 0x01, 0x7a, 0x3e, 0x14, 0x39, 0x3c, 0xff, 0x6d, 0xb7,
+	0xe1, 0x8b, 0x7e, 0xc5, 0x09, 0xe6, 0x07, 0x6c, 0xb5, 0x48, 0x0f, 0x37, 0xb1, 0xe1, 0xaf, 0xaa,
+	0x87, 0x15, 0xd1, 0xd7, 0x08, 0xd6, 0xfa, 0x2b, 0x83, 0xad, 0xc7, 0x62, 0xda, 0x95, 0xfb, 0x03,
+	0x52, 0x08, 0x37, 0xd0, 0x52, 0x34, 0x64, 0xd9, 0x9f, 0x28, 0xe1, 0xaa, 0xfd, 0xa1, 0xd4, 0x2f,
+	0x78, 0xe7, 0x2a, 0x33, 0x67, 0x89, 0xb0, 0x2a, 0x6c, 0x41, 0xbe, 0xd0, 0x0c, 0x03, 0x96, 0xa1,
+	0x04, 0x2c, 0xf4, 0x94, 0x9c, 0x2e, 0xba, 0x32, 0x17, 0x72, 0x1f, 0x43, 0x87, 0x3a, 0xd4, 0x6c,
+	0xda, 0xe6, 0x80, 0xf5, 0x2f, 0x69, 0x52, 0xe8, 0x0c, 0x9d, 0x2e, 0x3d, 0x8d, 0x04, 0xcf, 0xec,
+	0xb9, 0xbe, 0x8c, 0x05, 0x1c, 0xc2, 0x2e, 0xd9, 0x17, 0xfd, 0x42, 0xdb, 0xdf, 0x77, 0xe5, 0x1a,
+	0x0a, 0x11, 0xb8, 0xbe, 0x2a, 0xf0, 0xdb, 0xf2, 0x2f, 0xe4, 0xf3, 0x25, 0x01, 0x62, 0x12, 0x18,
+	0x66, 0x26, 0x95, 0x51, 0x97, 0x86, 0x33, 0x6b, 0xeb, 0x48, 0x9c, 0xc6, 0xe0, 0x2d, 0x54, 0xc0,
+	0xc9, 0xb7, 0x5f, 0x9c, 0x80, 0xd3, 0xc8, 0x1f, 0x47, 0x05, 0xac, 0x73, 0xc4, 0x1a, 0xc1, 0xa2,
+	0x67, 0xa4, 0x47, 0x5f, 0xbc, 0xd3, 0xf3, 0xfc, 0xd1, 0x55, 0x88, 0x41, 0x3a, 0x96, 0xc3, 0x04,
+	0x7d, 0x81, 0xd3, 0x43, 0x0c, 0xc6, 0xd0, 0xaa, 0xdb, 0xa0, 0x89, 0xa1, 0xbb, 0x11, 0xc8, 0x9f,
+	0x25, 0x8c, 0x23, 0x2e, 0x09, 0x41, 0xc6, 0x47, 0x5c, 0x0a, 0xa5, 0x4a, 0x5b, 0x82, 0xb4, 0xc4,
+	0xa5, 0x24, 0x4c, 0xfb, 0x50, 0x90, 0x96, 0xc5, 0x3e, 0x14, 0x14, 0x5c, 0x1a, 0x72, 0x03, 0x55,
+	0x07, 0x0e, 0x84, 0x73, 0x7e, 0xa3, 0x16, 0xc1, 0x62, 0xf4, 0x09, 0xbd, 0x65, 0xb5, 0x71, 0xe1,
+	0xc2, 0xa9, 0x66, 0x95, 0x2c, 0x15, 0xc3, 0x5b, 0xff, 0x69, 0x40, 0xa8, 0x1a, 0x9d, 0x77, 0xda,
+	0xbc, 0xcf, 0xae, 0xef, 0xd2, 0x75, 0x96, 0xf2, 0xee, 0xd7, 0x98, 0xfa, 0xee, 0xf7, 0x43, 0x7c,
+	0x0b, 0xcd, 0xd7, 0x86, 0xc8, 0x5a, 0xd6, 0xd4, 0x07, 0xe4, 0x80, 0xb6, 0x03, 0x06, 0x74, 0x6e,
+	0x8e, 0xe2, 0xdc, 0xd2, 0xe3, 0x9d, 0x9b, 0xc2, 0x06, 0x79, 0xd4, 0xbc, 0x87, 0x1d, 0x76, 0x92,
+	0x9e, 0xb7, 0x85, 0x2f, 0xf4, 0x24, 0x13, 0x4e, 0x30, 0x95, 0xa8, 0x61, 0x41, 0x88, 0xf7, 0xbb,
+	0x01, 0x6c, 0x75, 0xd8, 0x26, 0x5d, 0x63, 0x34, 0x63, 0xe3, 0xc6, 0x30, 0x19, 0x40, 0x62, 0x2f,
+	0x2b, 0x18, 0x7d, 0xcf, 0xa5, 0x22, 0x7b, 0x2e, 0xdc, 0x67, 0x69, 0x35, 0x31, 0xfc, 0xb9, 0xc1,
+	0x96, 0xd5, 0xcb, 0x7e, 0xf3, 0xcb, 0xe4, 0x67, 0x4a, 0x63, 0x4b, 0x16, 0xff, 0xbb, 0xd7, 0x4b,
+	0x86, 0xfe, 0x70, 0xea, 0x77, 0xd9, 0xf5, 0xc4, 0x02, 0x10, 0xee, 0x69, 0x9a, 0xa0, 0xa1, 0xdc,
+	0xd3, 0x1c, 0x8a, 0x54, 0xc2, 0x52, 0x6f, 0x5a, 0x09, 0xa3, 0x67, 0x6b, 0x22, 0x8a, 0x9e, 0x5a,
+	0x7f, 0x6a, 0xe8, 0xf5, 0x3d, 0xed, 0xc1, 0x85, 0xb8, 0x5c, 0x80, 0xa4, 0x79, 0x52, 0xf5, 0xfc,
+	0xbe, 0x4c, 0xa8, 0xd3, 0xe3, 0xae, 0x59, 0xe3, 0x99, 0xf4, 0xd4, 0xf7, 0x3b, 0x7f, 0x6c, 0xb0,
+	0x15, 0x91, 0xb6, 0x8a, 0x37, 0x8e, 0xfc, 0x2a, 0xa4, 0xdd, 0x14, 0x2f, 0x1c, 0x39, 0x40, 0x87,
+	0xf9, 0xd7, 0x83, 0xf6, 0x10, 0x1f, 0x8a, 0x52, 0x97, 0x20, 0xc9, 0x0e, 0x10, 0x54, 0xb2, 0x06,
+	0x77, 0x8e, 0x59, 0xb7, 0x70, 0x9e, 0x01, 0x8c, 0xd3, 0x5b, 0xec, 0x38, 0xed, 0xae, 0x27, 0xab,
+	0xe9, 0x1c, 0xe2, 0xd7, 0x86, 0x8e, 0x27, 0x12, 0x09, 0xba, 0x36, 0x44, 0xc8, 0xfa, 0x65, 0x8a,
+	0xad, 0x68, 0x79, 0x4c, 0x7c, 0x52, 0xaa, 0x72, 0x52, 0xaa, 0x74, 0xfd, 0x14, 0x14, 0x1e, 0x6a,
+	0x54, 0x78, 0xa8, 0x05, 0x85, 0x87, 0x1a, 0x95, 0x3d, 0x6b, 0xce, 0xe8, 0x54, 0x96, 0x38, 0xf1,
+	0x1b, 0x79, 0x0e, 0x46, 0xf2, 0xad, 0xd6, 0xc1, 0x88, 0x5f, 0x0f, 0x89, 0x17, 0x3b, 0x35, 0x84,
+	0xf6, 0x29, 0x8f, 0x04, 0x68, 0x9f, 0x3f, 0x51, 0xe1, 0xb7, 0x07, 0x86, 0x4d, 0x4b, 0xa5, 0xd7,
+	0x73, 0x87, 0x79, 0x51, 0x34, 0x10, 0x50, 0x80, 0x2f, 0x88, 0xc2, 0x80, 0x80, 0x70, 0x57, 0x29,
+	0xff, 0x66, 0xb3, 0xcc, 0x77, 0x95, 0x72, 0x63, 0xf8, 0x29, 0x38, 0xc3, 0x6f, 0x47, 0x30, 0xc1,
+	0xfe, 0x25, 0x39, 0x34, 0xf5, 0xdf, 0x82, 0xb6, 0x9f, 0x49, 0x12, 0xdf, 0x05, 0x01, 0xa7, 0xf5,
+	0x1f, 0x06, 0xde, 0x96, 0x46, 0xe8, 0x89, 0x49, 0x3a, 0x9f, 0xb4, 0xab, 0x25, 0xe9, 0xa9, 0x44,
+	0xde, 0xdc, 0x94, 0x64, 0x46, 0x4f, 0xbf, 0xf9, 0x53, 0x3c, 0x35, 0xfd, 0x86, 0x04, 0x3e, 0x4c,
+	0xb6, 0xb7, 0x84, 0x19, 0x54, 0x94, 0xce, 0x21, 0x9f, 0xd0, 0xa9, 0xa8, 0x47, 0x7f, 0x96, 0x86,
+	0xc5, 0x27, 0x1f, 0xce, 0x9a, 0xeb, 0x6c, 0xe5, 0xb8, 0xb2, 0x57, 0x39, 0x7c, 0x5e, 0xa9, 0x97,
+	0x6c, 0xfb, 0xd0, 0xce, 0x7c, 0x0f, 0x51, 0xe5, 0xca, 0x49, 0x7e, 0xbf, 0xbc, 0x5d, 0x3f, 0xb2,
+	0x0f, 0x0f, 0x9f, 0x65, 0x0c, 0x44, 0x95, 0x4e, 0x8f, 0xca, 0x76, 0x69, 0xbb, 0x5e, 0x39, 0xac,
+	0x14, 0x4b, 0x99, 0x94, 0xb9, 0xc6, 0x96, 0xa4, 0xe0, 0xa1, 0xbd, 0x93, 0x49, 0x9b, 0x4b, 0x90,
+	0x36, 0x96, 0x4e, 0x0e, 0xf7, 0x4a, 0xdb, 0x99, 0x19, 0xf3, 0x1a, 0x5b, 0x93, 0x3a, 0xec, 0xd2,
+	0x4e, 0x7d, 0xaf, 0x74, 0x96, 0x99, 0x05, 0xbb, 0x9a, 0xdb, 0xa5, 0x93, 0x72, 0xb1, 0x54, 0xcf,
+	0x1f, 0xd7, 0x76, 0xeb, 0xcf, 0xf2, 0xe5, 0x7d, 0x60, 0x9e, 0xd3, 0x99, 0xbf, 0x3e, 0x2e, 0x55,
+	0x6b, 0x99, 0x79, 0x58, 0x2a, 0x0b, 0xe5, 0x4a, 0xad, 0x64, 0x57, 0xf2, 0xfb, 0x99, 0x05, 0x58,
+	0x78, 0xab, 0xb2, 0xb5, 0x6a, 0x71, 0xb7, 0x74, 0x90, 0xcf, 0x2c, 0xa2, 0x3a, 0xd9, 0xa9, 0x22,
+	0xfc, 0x29, 0x55, 0x6a, 0x65, 0xe0, 0x65, 0x2a, 0x6f, 0xad, 0x54, 0xc9, 0x57, 0x6a, 0x99, 0x25,
+	0xf3, 0x2d, 0x76, 0xed, 0xb8, 0x52, 0x3d, 0x3e, 0x3a, 0x3a, 0xb4, 0x6b, 0x25, 0x1a, 0xd7, 0x33,
+	0x68, 0x3c, 0xb3, 0x6c, 0x66, 0xd8, 0xb2, 0x9d, 0xaf, 0x95, 0xea, 0xfb, 0xe5, 0x83, 0x32, 0x50,
+	0x32, 0x2b, 0xea, 0xc0, 0xb0, 0xdb, 0xab, 0xe6, 0x0d, 0x76, 0x5d, 0x76, 0x6f, 0xc7, 0x3e, 0x3c,
+	0x3e, 0xaa, 0x97, 0xf6, 0x4b, 0x07, 0xd0, 0x5a, 0x66, 0x0d, 0x42, 0xec, 0xc6, 0xd1, 0xe1, 0x7e,
+	0xb9, 0x78, 0x06, 0xd3, 0x52, 0xab, 0x57, 0xf3, 0xb5, 0x72, 0xf5, 0x59, 0x19, 0xb4, 0x64, 0xd4,
+	0x31, 0x55, 0x4b, 0xd5, 0x6a, 0xf9, 0xb0, 0x92, 0x59, 0x07, 0xe3, 0xdc, 0x8a, 0xf4, 0xa2, 0x76,
+	0x58, 0x3c, 0xdc, 0xaf, 0x9f, 0x94, 0x6c, 0xe2, 0x30, 0x0b, 0xf7, 0x5e, 0xdc, 0x69, 0xb5, 0xfd,
+	0x8b, 0xd1, 0xf9, 0xe3, 0x46, 0xbf, 0xfb, 0xe4, 0x75, 0xc7, 0x39, 0xff, 0xd8, 0x6b, 0x3f, 0x71,
+	0xbb, 0xdd, 0x4b, 0xfe, 0xff, 0xf0, 0x9f, 0xf3, 0xff, 0x8a, 0x9f, 0xa3, 0x9f, 0xa7, 0xff, 0x03,
+	0x11, 0x77, 0x03, 0x5f, 0x43, 0x3f, 0x00, 0x00,
 }
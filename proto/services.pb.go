@@ -31,6 +31,7 @@ const _ = grpc.SupportPackageIsVersion4
 type PseudonymSystemCAClient interface {
 	GenerateCertificate(ctx context.Context, opts ...grpc.CallOption) (PseudonymSystemCA_GenerateCertificateClient, error)
 	GenerateCertificate_EC(ctx context.Context, opts ...grpc.CallOption) (PseudonymSystemCA_GenerateCertificate_ECClient, error)
+	GenerateCertificateNI(ctx context.Context, in *PseudonymsysCACertificateRequestNI, opts ...grpc.CallOption) (*PseudonymsysCACertificate, error)
 }
 
 type pseudonymSystemCAClient struct {
@@ -103,11 +104,21 @@ func (x *pseudonymSystemCAGenerateCertificate_ECClient) Recv() (*Message, error)
 	return m, nil
 }
 
+func (c *pseudonymSystemCAClient) GenerateCertificateNI(ctx context.Context, in *PseudonymsysCACertificateRequestNI, opts ...grpc.CallOption) (*PseudonymsysCACertificate, error) {
+	out := new(PseudonymsysCACertificate)
+	err := grpc.Invoke(ctx, "/proto.PseudonymSystemCA/GenerateCertificateNI", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for PseudonymSystemCA service
 
 type PseudonymSystemCAServer interface {
 	GenerateCertificate(PseudonymSystemCA_GenerateCertificateServer) error
 	GenerateCertificate_EC(PseudonymSystemCA_GenerateCertificate_ECServer) error
+	GenerateCertificateNI(context.Context, *PseudonymsysCACertificateRequestNI) (*PseudonymsysCACertificate, error)
 }
 
 func RegisterPseudonymSystemCAServer(s *grpc.Server, srv PseudonymSystemCAServer) {
@@ -166,10 +177,33 @@ func (x *pseudonymSystemCAGenerateCertificate_ECServer) Recv() (*Message, error)
 	return m, nil
 }
 
+func _PseudonymSystemCA_GenerateCertificateNI_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PseudonymsysCACertificateRequestNI)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PseudonymSystemCAServer).GenerateCertificateNI(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.PseudonymSystemCA/GenerateCertificateNI",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PseudonymSystemCAServer).GenerateCertificateNI(ctx, req.(*PseudonymsysCACertificateRequestNI))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _PseudonymSystemCA_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.PseudonymSystemCA",
 	HandlerType: (*PseudonymSystemCAServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GenerateCertificateNI",
+			Handler:    _PseudonymSystemCA_GenerateCertificateNI_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "GenerateCertificate",
@@ -196,6 +230,7 @@ type PseudonymSystemClient interface {
 	ObtainCredential_EC(ctx context.Context, opts ...grpc.CallOption) (PseudonymSystem_ObtainCredential_ECClient, error)
 	TransferCredential(ctx context.Context, opts ...grpc.CallOption) (PseudonymSystem_TransferCredentialClient, error)
 	TransferCredential_EC(ctx context.Context, opts ...grpc.CallOption) (PseudonymSystem_TransferCredential_ECClient, error)
+	GenerateNymNI(ctx context.Context, in *PseudonymsysNymGenProofNI, opts ...grpc.CallOption) (*Status, error)
 }
 
 type pseudonymSystemClient struct {
@@ -392,6 +427,15 @@ func (x *pseudonymSystemTransferCredential_ECClient) Recv() (*Message, error) {
 	return m, nil
 }
 
+func (c *pseudonymSystemClient) GenerateNymNI(ctx context.Context, in *PseudonymsysNymGenProofNI, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := grpc.Invoke(ctx, "/proto.PseudonymSystem/GenerateNymNI", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for PseudonymSystem service
 
 type PseudonymSystemServer interface {
@@ -401,6 +445,7 @@ type PseudonymSystemServer interface {
 	ObtainCredential_EC(PseudonymSystem_ObtainCredential_ECServer) error
 	TransferCredential(PseudonymSystem_TransferCredentialServer) error
 	TransferCredential_EC(PseudonymSystem_TransferCredential_ECServer) error
+	GenerateNymNI(context.Context, *PseudonymsysNymGenProofNI) (*Status, error)
 }
 
 func RegisterPseudonymSystemServer(s *grpc.Server, srv PseudonymSystemServer) {
@@ -563,10 +608,33 @@ func (x *pseudonymSystemTransferCredential_ECServer) Recv() (*Message, error) {
 	return m, nil
 }
 
+func _PseudonymSystem_GenerateNymNI_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PseudonymsysNymGenProofNI)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PseudonymSystemServer).GenerateNymNI(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.PseudonymSystem/GenerateNymNI",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PseudonymSystemServer).GenerateNymNI(ctx, req.(*PseudonymsysNymGenProofNI))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _PseudonymSystem_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.PseudonymSystem",
 	HandlerType: (*PseudonymSystemServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GenerateNymNI",
+			Handler:    _PseudonymSystem_GenerateNymNI_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "GenerateNym",
@@ -611,11 +679,13 @@ var _PseudonymSystem_serviceDesc = grpc.ServiceDesc{
 // Client API for CL service
 
 type CLClient interface {
-	GetCredentialStructure(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*CredStructure, error)
+	GetCredentialStructure(ctx context.Context, in *CredStructureRequest, opts ...grpc.CallOption) (*CredStructure, error)
 	GetAcceptableCredentials(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*AcceptableCreds, error)
 	IssueCredential(ctx context.Context, opts ...grpc.CallOption) (CL_IssueCredentialClient, error)
+	IssueCredentialBatch(ctx context.Context, opts ...grpc.CallOption) (CL_IssueCredentialBatchClient, error)
 	UpdateCredential(ctx context.Context, opts ...grpc.CallOption) (CL_UpdateCredentialClient, error)
 	ProveCredential(ctx context.Context, opts ...grpc.CallOption) (CL_ProveCredentialClient, error)
+	ProveCredentialNI(ctx context.Context, in *ProveCLCredentialNI, opts ...grpc.CallOption) (*SessionKey, error)
 }
 
 type cLClient struct {
@@ -626,7 +696,7 @@ func NewCLClient(cc *grpc.ClientConn) CLClient {
 	return &cLClient{cc}
 }
 
-func (c *cLClient) GetCredentialStructure(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*CredStructure, error) {
+func (c *cLClient) GetCredentialStructure(ctx context.Context, in *CredStructureRequest, opts ...grpc.CallOption) (*CredStructure, error) {
 	out := new(CredStructure)
 	err := grpc.Invoke(ctx, "/proto.CL/GetCredentialStructure", in, out, c.cc, opts...)
 	if err != nil {
@@ -675,8 +745,39 @@ func (x *cLIssueCredentialClient) Recv() (*Message, error) {
 	return m, nil
 }
 
+func (c *cLClient) IssueCredentialBatch(ctx context.Context, opts ...grpc.CallOption) (CL_IssueCredentialBatchClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_CL_serviceDesc.Streams[1], c.cc, "/proto.CL/IssueCredentialBatch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cLIssueCredentialBatchClient{stream}
+	return x, nil
+}
+
+type CL_IssueCredentialBatchClient interface {
+	Send(*Message) error
+	Recv() (*Message, error)
+	grpc.ClientStream
+}
+
+type cLIssueCredentialBatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *cLIssueCredentialBatchClient) Send(m *Message) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *cLIssueCredentialBatchClient) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *cLClient) UpdateCredential(ctx context.Context, opts ...grpc.CallOption) (CL_UpdateCredentialClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_CL_serviceDesc.Streams[1], c.cc, "/proto.CL/UpdateCredential", opts...)
+	stream, err := grpc.NewClientStream(ctx, &_CL_serviceDesc.Streams[2], c.cc, "/proto.CL/UpdateCredential", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -707,7 +808,7 @@ func (x *cLUpdateCredentialClient) Recv() (*Message, error) {
 }
 
 func (c *cLClient) ProveCredential(ctx context.Context, opts ...grpc.CallOption) (CL_ProveCredentialClient, error) {
-	stream, err := grpc.NewClientStream(ctx, &_CL_serviceDesc.Streams[2], c.cc, "/proto.CL/ProveCredential", opts...)
+	stream, err := grpc.NewClientStream(ctx, &_CL_serviceDesc.Streams[3], c.cc, "/proto.CL/ProveCredential", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -737,14 +838,25 @@ func (x *cLProveCredentialClient) Recv() (*Message, error) {
 	return m, nil
 }
 
+func (c *cLClient) ProveCredentialNI(ctx context.Context, in *ProveCLCredentialNI, opts ...grpc.CallOption) (*SessionKey, error) {
+	out := new(SessionKey)
+	err := grpc.Invoke(ctx, "/proto.CL/ProveCredentialNI", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for CL service
 
 type CLServer interface {
-	GetCredentialStructure(context.Context, *google_protobuf.Empty) (*CredStructure, error)
+	GetCredentialStructure(context.Context, *CredStructureRequest) (*CredStructure, error)
 	GetAcceptableCredentials(context.Context, *google_protobuf.Empty) (*AcceptableCreds, error)
 	IssueCredential(CL_IssueCredentialServer) error
+	IssueCredentialBatch(CL_IssueCredentialBatchServer) error
 	UpdateCredential(CL_UpdateCredentialServer) error
 	ProveCredential(CL_ProveCredentialServer) error
+	ProveCredentialNI(context.Context, *ProveCLCredentialNI) (*SessionKey, error)
 }
 
 func RegisterCLServer(s *grpc.Server, srv CLServer) {
@@ -752,7 +864,7 @@ func RegisterCLServer(s *grpc.Server, srv CLServer) {
 }
 
 func _CL_GetCredentialStructure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(google_protobuf.Empty)
+	in := new(CredStructureRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -764,7 +876,7 @@ func _CL_GetCredentialStructure_Handler(srv interface{}, ctx context.Context, de
 		FullMethod: "/proto.CL/GetCredentialStructure",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(CLServer).GetCredentialStructure(ctx, req.(*google_protobuf.Empty))
+		return srv.(CLServer).GetCredentialStructure(ctx, req.(*CredStructureRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -813,6 +925,32 @@ func (x *cLIssueCredentialServer) Recv() (*Message, error) {
 	return m, nil
 }
 
+func _CL_IssueCredentialBatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CLServer).IssueCredentialBatch(&cLIssueCredentialBatchServer{stream})
+}
+
+type CL_IssueCredentialBatchServer interface {
+	Send(*Message) error
+	Recv() (*Message, error)
+	grpc.ServerStream
+}
+
+type cLIssueCredentialBatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *cLIssueCredentialBatchServer) Send(m *Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *cLIssueCredentialBatchServer) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _CL_UpdateCredential_Handler(srv interface{}, stream grpc.ServerStream) error {
 	return srv.(CLServer).UpdateCredential(&cLUpdateCredentialServer{stream})
 }
@@ -865,6 +1003,24 @@ func (x *cLProveCredentialServer) Recv() (*Message, error) {
 	return m, nil
 }
 
+func _CL_ProveCredentialNI_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProveCLCredentialNI)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CLServer).ProveCredentialNI(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.CL/ProveCredentialNI",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CLServer).ProveCredentialNI(ctx, req.(*ProveCLCredentialNI))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _CL_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.CL",
 	HandlerType: (*CLServer)(nil),
@@ -877,6 +1033,10 @@ var _CL_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetAcceptableCredentials",
 			Handler:    _CL_GetAcceptableCredentials_Handler,
 		},
+		{
+			MethodName: "ProveCredentialNI",
+			Handler:    _CL_ProveCredentialNI_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -885,6 +1045,12 @@ var _CL_serviceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "IssueCredentialBatch",
+			Handler:       _CL_IssueCredentialBatch_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "UpdateCredential",
 			Handler:       _CL_UpdateCredential_Handler,
@@ -901,10 +1067,365 @@ var _CL_serviceDesc = grpc.ServiceDesc{
 	Metadata: "services.proto",
 }
 
+// Client API for CLThreshold service
+
+type CLThresholdClient interface {
+	ThresholdSign(ctx context.Context, opts ...grpc.CallOption) (CLThreshold_ThresholdSignClient, error)
+}
+
+type cLThresholdClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewCLThresholdClient(cc *grpc.ClientConn) CLThresholdClient {
+	return &cLThresholdClient{cc}
+}
+
+func (c *cLThresholdClient) ThresholdSign(ctx context.Context, opts ...grpc.CallOption) (CLThreshold_ThresholdSignClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_CLThreshold_serviceDesc.Streams[0], c.cc, "/proto.CLThreshold/ThresholdSign", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &cLThresholdThresholdSignClient{stream}
+	return x, nil
+}
+
+type CLThreshold_ThresholdSignClient interface {
+	Send(*Message) error
+	Recv() (*Message, error)
+	grpc.ClientStream
+}
+
+type cLThresholdThresholdSignClient struct {
+	grpc.ClientStream
+}
+
+func (x *cLThresholdThresholdSignClient) Send(m *Message) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *cLThresholdThresholdSignClient) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for CLThreshold service
+
+type CLThresholdServer interface {
+	ThresholdSign(CLThreshold_ThresholdSignServer) error
+}
+
+func RegisterCLThresholdServer(s *grpc.Server, srv CLThresholdServer) {
+	s.RegisterService(&_CLThreshold_serviceDesc, srv)
+}
+
+func _CLThreshold_ThresholdSign_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CLThresholdServer).ThresholdSign(&cLThresholdThresholdSignServer{stream})
+}
+
+type CLThreshold_ThresholdSignServer interface {
+	Send(*Message) error
+	Recv() (*Message, error)
+	grpc.ServerStream
+}
+
+type cLThresholdThresholdSignServer struct {
+	grpc.ServerStream
+}
+
+func (x *cLThresholdThresholdSignServer) Send(m *Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *cLThresholdThresholdSignServer) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _CLThreshold_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.CLThreshold",
+	HandlerType: (*CLThresholdServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ThresholdSign",
+			Handler:       _CLThreshold_ThresholdSign_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "services.proto",
+}
+
+// Client API for BBS service
+
+type BBSClient interface {
+	IssueBBSCredential(ctx context.Context, opts ...grpc.CallOption) (BBS_IssueBBSCredentialClient, error)
+	ProveBBSCredential(ctx context.Context, opts ...grpc.CallOption) (BBS_ProveBBSCredentialClient, error)
+}
+
+type bBSClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewBBSClient(cc *grpc.ClientConn) BBSClient {
+	return &bBSClient{cc}
+}
+
+func (c *bBSClient) IssueBBSCredential(ctx context.Context, opts ...grpc.CallOption) (BBS_IssueBBSCredentialClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_BBS_serviceDesc.Streams[0], c.cc, "/proto.BBS/IssueBBSCredential", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &bBSIssueBBSCredentialClient{stream}
+	return x, nil
+}
+
+type BBS_IssueBBSCredentialClient interface {
+	Send(*Message) error
+	Recv() (*Message, error)
+	grpc.ClientStream
+}
+
+type bBSIssueBBSCredentialClient struct {
+	grpc.ClientStream
+}
+
+func (x *bBSIssueBBSCredentialClient) Send(m *Message) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *bBSIssueBBSCredentialClient) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *bBSClient) ProveBBSCredential(ctx context.Context, opts ...grpc.CallOption) (BBS_ProveBBSCredentialClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_BBS_serviceDesc.Streams[1], c.cc, "/proto.BBS/ProveBBSCredential", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &bBSProveBBSCredentialClient{stream}
+	return x, nil
+}
+
+type BBS_ProveBBSCredentialClient interface {
+	Send(*Message) error
+	Recv() (*Message, error)
+	grpc.ClientStream
+}
+
+type bBSProveBBSCredentialClient struct {
+	grpc.ClientStream
+}
+
+func (x *bBSProveBBSCredentialClient) Send(m *Message) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *bBSProveBBSCredentialClient) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Server API for BBS service
+
+type BBSServer interface {
+	IssueBBSCredential(BBS_IssueBBSCredentialServer) error
+	ProveBBSCredential(BBS_ProveBBSCredentialServer) error
+}
+
+func RegisterBBSServer(s *grpc.Server, srv BBSServer) {
+	s.RegisterService(&_BBS_serviceDesc, srv)
+}
+
+func _BBS_IssueBBSCredential_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BBSServer).IssueBBSCredential(&bBSIssueBBSCredentialServer{stream})
+}
+
+type BBS_IssueBBSCredentialServer interface {
+	Send(*Message) error
+	Recv() (*Message, error)
+	grpc.ServerStream
+}
+
+type bBSIssueBBSCredentialServer struct {
+	grpc.ServerStream
+}
+
+func (x *bBSIssueBBSCredentialServer) Send(m *Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *bBSIssueBBSCredentialServer) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _BBS_ProveBBSCredential_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BBSServer).ProveBBSCredential(&bBSProveBBSCredentialServer{stream})
+}
+
+type BBS_ProveBBSCredentialServer interface {
+	Send(*Message) error
+	Recv() (*Message, error)
+	grpc.ServerStream
+}
+
+type bBSProveBBSCredentialServer struct {
+	grpc.ServerStream
+}
+
+func (x *bBSProveBBSCredentialServer) Send(m *Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *bBSProveBBSCredentialServer) Recv() (*Message, error) {
+	m := new(Message)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _BBS_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.BBS",
+	HandlerType: (*BBSServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "IssueBBSCredential",
+			Handler:       _BBS_IssueBBSCredential_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ProveBBSCredential",
+			Handler:       _BBS_ProveBBSCredential_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "services.proto",
+}
+
+// Client API for Revocation service
+
+type RevocationClient interface {
+	GetAccumulator(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*Accumulator, error)
+	GetAccumulatorUpdate(ctx context.Context, in *AccumulatorVersion, opts ...grpc.CallOption) (*AccumulatorUpdate, error)
+}
+
+type revocationClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewRevocationClient(cc *grpc.ClientConn) RevocationClient {
+	return &revocationClient{cc}
+}
+
+func (c *revocationClient) GetAccumulator(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*Accumulator, error) {
+	out := new(Accumulator)
+	err := grpc.Invoke(ctx, "/proto.Revocation/GetAccumulator", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *revocationClient) GetAccumulatorUpdate(ctx context.Context, in *AccumulatorVersion, opts ...grpc.CallOption) (*AccumulatorUpdate, error) {
+	out := new(AccumulatorUpdate)
+	err := grpc.Invoke(ctx, "/proto.Revocation/GetAccumulatorUpdate", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Revocation service
+
+type RevocationServer interface {
+	GetAccumulator(context.Context, *google_protobuf.Empty) (*Accumulator, error)
+	GetAccumulatorUpdate(context.Context, *AccumulatorVersion) (*AccumulatorUpdate, error)
+}
+
+func RegisterRevocationServer(s *grpc.Server, srv RevocationServer) {
+	s.RegisterService(&_Revocation_serviceDesc, srv)
+}
+
+func _Revocation_GetAccumulator_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RevocationServer).GetAccumulator(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Revocation/GetAccumulator",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RevocationServer).GetAccumulator(ctx, req.(*google_protobuf.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Revocation_GetAccumulatorUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccumulatorVersion)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RevocationServer).GetAccumulatorUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Revocation/GetAccumulatorUpdate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RevocationServer).GetAccumulatorUpdate(ctx, req.(*AccumulatorVersion))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Revocation_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Revocation",
+	HandlerType: (*RevocationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAccumulator",
+			Handler:    _Revocation_GetAccumulator_Handler,
+		},
+		{
+			MethodName: "GetAccumulatorUpdate",
+			Handler:    _Revocation_GetAccumulatorUpdate_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "services.proto",
+}
+
 // Client API for Info service
 
 type InfoClient interface {
 	GetServiceInfo(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*ServiceInfo, error)
+	GetPublicParameters(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*SignedPublicParameters, error)
 }
 
 type infoClient struct {
@@ -924,10 +1445,20 @@ func (c *infoClient) GetServiceInfo(ctx context.Context, in *google_protobuf.Emp
 	return out, nil
 }
 
+func (c *infoClient) GetPublicParameters(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*SignedPublicParameters, error) {
+	out := new(SignedPublicParameters)
+	err := grpc.Invoke(ctx, "/proto.Info/GetPublicParameters", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Info service
 
 type InfoServer interface {
 	GetServiceInfo(context.Context, *google_protobuf.Empty) (*ServiceInfo, error)
+	GetPublicParameters(context.Context, *google_protobuf.Empty) (*SignedPublicParameters, error)
 }
 
 func RegisterInfoServer(s *grpc.Server, srv InfoServer) {
@@ -952,6 +1483,24 @@ func _Info_GetServiceInfo_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Info_GetPublicParameters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InfoServer).GetPublicParameters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Info/GetPublicParameters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InfoServer).GetPublicParameters(ctx, req.(*google_protobuf.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Info_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.Info",
 	HandlerType: (*InfoServer)(nil),
@@ -960,6 +1509,204 @@ var _Info_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetServiceInfo",
 			Handler:    _Info_GetServiceInfo_Handler,
 		},
+		{
+			MethodName: "GetPublicParameters",
+			Handler:    _Info_GetPublicParameters_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "services.proto",
+}
+
+// Client API for Steps service
+
+type StepsClient interface {
+	Step(ctx context.Context, in *ProtocolStep, opts ...grpc.CallOption) (*ProtocolStep, error)
+}
+
+type stepsClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewStepsClient(cc *grpc.ClientConn) StepsClient {
+	return &stepsClient{cc}
+}
+
+func (c *stepsClient) Step(ctx context.Context, in *ProtocolStep, opts ...grpc.CallOption) (*ProtocolStep, error) {
+	out := new(ProtocolStep)
+	err := grpc.Invoke(ctx, "/proto.Steps/Step", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Steps service
+
+type StepsServer interface {
+	Step(context.Context, *ProtocolStep) (*ProtocolStep, error)
+}
+
+func RegisterStepsServer(s *grpc.Server, srv StepsServer) {
+	s.RegisterService(&_Steps_serviceDesc, srv)
+}
+
+func _Steps_Step_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProtocolStep)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StepsServer).Step(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Steps/Step",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StepsServer).Step(ctx, req.(*ProtocolStep))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Steps_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Steps",
+	HandlerType: (*StepsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Step",
+			Handler:    _Steps_Step_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "services.proto",
+}
+
+// Client API for Session service
+
+type SessionClient interface {
+	ValidateSession(ctx context.Context, in *SessionKey, opts ...grpc.CallOption) (*SessionStatus, error)
+	RefreshSession(ctx context.Context, in *SessionKey, opts ...grpc.CallOption) (*SessionKey, error)
+	RevokeSession(ctx context.Context, in *SessionKey, opts ...grpc.CallOption) (*google_protobuf.Empty, error)
+}
+
+type sessionClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewSessionClient(cc *grpc.ClientConn) SessionClient {
+	return &sessionClient{cc}
+}
+
+func (c *sessionClient) ValidateSession(ctx context.Context, in *SessionKey, opts ...grpc.CallOption) (*SessionStatus, error) {
+	out := new(SessionStatus)
+	err := grpc.Invoke(ctx, "/proto.Session/ValidateSession", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionClient) RefreshSession(ctx context.Context, in *SessionKey, opts ...grpc.CallOption) (*SessionKey, error) {
+	out := new(SessionKey)
+	err := grpc.Invoke(ctx, "/proto.Session/RefreshSession", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sessionClient) RevokeSession(ctx context.Context, in *SessionKey, opts ...grpc.CallOption) (*google_protobuf.Empty, error) {
+	out := new(google_protobuf.Empty)
+	err := grpc.Invoke(ctx, "/proto.Session/RevokeSession", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Session service
+
+type SessionServer interface {
+	ValidateSession(context.Context, *SessionKey) (*SessionStatus, error)
+	RefreshSession(context.Context, *SessionKey) (*SessionKey, error)
+	RevokeSession(context.Context, *SessionKey) (*google_protobuf.Empty, error)
+}
+
+func RegisterSessionServer(s *grpc.Server, srv SessionServer) {
+	s.RegisterService(&_Session_serviceDesc, srv)
+}
+
+func _Session_ValidateSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServer).ValidateSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Session/ValidateSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServer).ValidateSession(ctx, req.(*SessionKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Session_RefreshSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServer).RefreshSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Session/RefreshSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServer).RefreshSession(ctx, req.(*SessionKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Session_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SessionKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SessionServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Session/RevokeSession",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SessionServer).RevokeSession(ctx, req.(*SessionKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Session_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "proto.Session",
+	HandlerType: (*SessionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ValidateSession",
+			Handler:    _Session_ValidateSession_Handler,
+		},
+		{
+			MethodName: "RefreshSession",
+			Handler:    _Session_RefreshSession_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _Session_RevokeSession_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "services.proto",
@@ -968,28 +1715,48 @@ var _Info_serviceDesc = grpc.ServiceDesc{
 func init() { proto1.RegisterFile("services.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 357 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x93, 0xd1, 0x6a, 0xea, 0x40,
-	0x10, 0x86, 0xa3, 0x9c, 0x73, 0x2e, 0xe6, 0x40, 0xb4, 0x6b, 0x2b, 0x25, 0xbd, 0xcb, 0x55, 0xaf,
-	0x62, 0x51, 0xa8, 0x52, 0xa9, 0x20, 0xc1, 0x5a, 0xc1, 0xb6, 0x82, 0xed, 0x75, 0xd9, 0xc4, 0x89,
-	0x04, 0x4c, 0x36, 0xec, 0x4e, 0x84, 0xbc, 0x45, 0x1f, 0xab, 0x8f, 0xd3, 0x47, 0x28, 0x49, 0xb4,
-	0x5a, 0x5b, 0x21, 0xb9, 0x0a, 0x33, 0x99, 0xef, 0x9f, 0xd9, 0xd9, 0x7f, 0x41, 0x57, 0x28, 0xd7,
-	0xbe, 0x8b, 0xca, 0x8a, 0xa4, 0x20, 0xc1, 0xfe, 0x66, 0x1f, 0x43, 0x0f, 0x50, 0x29, 0xbe, 0xdc,
-	0xa6, 0x8d, 0x8b, 0xa5, 0x10, 0xcb, 0x15, 0xb6, 0xb2, 0xc8, 0x89, 0xbd, 0x16, 0x06, 0x11, 0x25,
-	0xf9, 0xcf, 0xf6, 0x5b, 0x05, 0x4e, 0x66, 0x0a, 0xe3, 0x85, 0x08, 0x93, 0x60, 0x9e, 0x28, 0xc2,
-	0xc0, 0x1e, 0xb2, 0x3e, 0x34, 0xc6, 0x18, 0xa2, 0xe4, 0x84, 0x36, 0x4a, 0xf2, 0x3d, 0xdf, 0xe5,
-	0x84, 0x4c, 0xcf, 0x21, 0xeb, 0x21, 0x6f, 0x60, 0x1c, 0xc4, 0xa6, 0x76, 0x59, 0xb9, 0xaa, 0xb0,
-	0x01, 0x34, 0x7f, 0x81, 0x5f, 0x47, 0x76, 0x31, 0xbe, 0xfd, 0x51, 0x85, 0xda, 0xc1, 0x48, 0xac,
-	0x03, 0xff, 0xb7, 0x9a, 0x8f, 0x49, 0x50, 0x70, 0x90, 0x6b, 0xd0, 0xf7, 0xa0, 0xc2, 0x03, 0xb0,
-	0x1e, 0xd4, 0x9f, 0x1c, 0xe2, 0x7e, 0x68, 0x4b, 0x5c, 0x60, 0x48, 0x3e, 0x5f, 0x15, 0x24, 0xfb,
-	0xd0, 0x38, 0x24, 0x8b, 0xb7, 0xbd, 0x01, 0xf6, 0x2c, 0x79, 0xa8, 0x3c, 0x94, 0xa5, 0x1b, 0xdf,
-	0xc2, 0xd9, 0x4f, 0xb6, 0xf8, 0xca, 0xdf, 0xab, 0x50, 0xb5, 0xa7, 0xec, 0x3e, 0xbd, 0x39, 0xda,
-	0x09, 0xcc, 0x49, 0xc6, 0x2e, 0xc5, 0x12, 0x59, 0xd3, 0xca, 0x4d, 0x64, 0x6d, 0x4d, 0x64, 0x8d,
-	0x52, 0x13, 0x19, 0xa7, 0x1b, 0xb9, 0x94, 0xf9, 0xaa, 0x36, 0x35, 0x36, 0x85, 0xf3, 0x31, 0xd2,
-	0xd0, 0x75, 0x31, 0x22, 0xee, 0xac, 0x70, 0xa7, 0xa9, 0x8e, 0x6a, 0x35, 0x37, 0x5a, 0xdf, 0x29,
-	0x65, 0x6a, 0xac, 0x0b, 0xb5, 0x89, 0x52, 0x31, 0x96, 0x5e, 0x4b, 0x0f, 0xea, 0x2f, 0xd1, 0x22,
-	0x35, 0x62, 0x59, 0xb2, 0x0b, 0xb5, 0x99, 0x14, 0xeb, 0xd2, 0x60, 0xfb, 0x0e, 0xfe, 0x4c, 0x42,
-	0x4f, 0xb0, 0x41, 0x6a, 0x3e, 0x9a, 0xe7, 0x2f, 0x34, 0xcb, 0x1c, 0x3b, 0x37, 0xdb, 0xe8, 0xec,
-	0xd5, 0x9a, 0x9a, 0xf3, 0x2f, 0x4b, 0x76, 0x3e, 0x03, 0x00, 0x00, 0xff, 0xff, 0x91, 0x0d, 0x5e,
-	0x79, 0xe5, 0x03, 0x00, 0x00,
+	// 684 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9d, 0x55, 0xcd, 0x6e, 0xd3, 0x40,
+	0x10, 0x4e, 0x68, 0x0b, 0xd2, 0x54, 0x49, 0xe9, 0xf6, 0x47, 0xc5, 0x15, 0x12, 0x0a, 0x42, 0x82,
+	0x03, 0x09, 0x4a, 0xc5, 0x8f, 0x28, 0x20, 0x35, 0x6e, 0x85, 0x22, 0xd2, 0x10, 0xd5, 0xa5, 0x87,
+	0x5e, 0x90, 0xe3, 0x8c, 0x13, 0x0b, 0xdb, 0x1b, 0x76, 0xd7, 0x15, 0x3e, 0xf0, 0x1a, 0x5c, 0x78,
+	0x0d, 0xee, 0x3c, 0x03, 0x6f, 0xc3, 0x91, 0xf1, 0x5f, 0x92, 0x26, 0x69, 0xeb, 0x70, 0xf2, 0x7a,
+	0x66, 0xbe, 0x9d, 0xd9, 0xf9, 0xbe, 0xd9, 0x85, 0xb2, 0x44, 0x71, 0xe1, 0x58, 0x28, 0xab, 0x43,
+	0xc1, 0x15, 0x67, 0x2b, 0xf1, 0x47, 0x2b, 0x7b, 0x28, 0xa5, 0xd9, 0xcf, 0xcc, 0xda, 0x6e, 0x9f,
+	0xf3, 0xbe, 0x8b, 0xb5, 0xf8, 0xaf, 0x1b, 0xd8, 0x35, 0xf4, 0x86, 0x2a, 0x4c, 0x9c, 0xf5, 0xbf,
+	0x45, 0x58, 0xef, 0x48, 0x0c, 0x7a, 0xdc, 0x0f, 0x3d, 0x23, 0x94, 0x0a, 0x3d, 0xfd, 0x80, 0xed,
+	0xc3, 0xc6, 0x7b, 0xf4, 0x51, 0x98, 0x0a, 0x75, 0x14, 0xca, 0xb1, 0x1d, 0x8b, 0x96, 0xac, 0x9c,
+	0x80, 0xaa, 0xc7, 0x49, 0x02, 0x6d, 0xea, 0xbf, 0x52, 0x78, 0x5c, 0x7c, 0x56, 0x64, 0xef, 0x60,
+	0x7b, 0x0e, 0xf8, 0xf3, 0x91, 0x9e, 0x13, 0x6f, 0xc3, 0xd6, 0x1c, 0x7c, 0xbb, 0xc9, 0x9e, 0xa4,
+	0xe1, 0xa3, 0x7a, 0x65, 0x28, 0xf5, 0x83, 0x89, 0x98, 0x13, 0xfc, 0x1a, 0xa0, 0x54, 0xed, 0xa6,
+	0xf6, 0xe0, 0xa6, 0xd0, 0x4a, 0xa1, 0xfe, 0x6b, 0x09, 0xd6, 0xa6, 0x8e, 0xce, 0xf6, 0x60, 0x35,
+	0xcb, 0xdd, 0x0e, 0xbd, 0x9c, 0x05, 0xbf, 0x80, 0xf2, 0x04, 0x28, 0xff, 0x41, 0x5f, 0xc1, 0xdd,
+	0x8f, 0x5d, 0x65, 0x3a, 0xbe, 0x2e, 0xb0, 0x87, 0xbe, 0x72, 0x4c, 0x37, 0x27, 0x92, 0xf8, 0x99,
+	0x46, 0xe6, 0x4f, 0xfb, 0x1a, 0xd8, 0xa9, 0x30, 0x7d, 0x69, 0xa3, 0x58, 0x38, 0xf1, 0x5b, 0xd8,
+	0x9a, 0xc5, 0xe6, 0x4f, 0xdd, 0x80, 0xd2, 0x44, 0xa7, 0x88, 0xd2, 0x79, 0x3c, 0x91, 0x87, 0x82,
+	0x3a, 0x82, 0x73, 0x9b, 0x98, 0x2c, 0xa5, 0x11, 0x86, 0x32, 0x55, 0x20, 0x89, 0xb6, 0x3f, 0x4b,
+	0x70, 0x4b, 0x6f, 0xb1, 0xe3, 0x48, 0x65, 0x6a, 0x5c, 0x84, 0xa1, 0x44, 0x60, 0xa9, 0x40, 0x20,
+	0xdb, 0x4d, 0x11, 0x91, 0x6f, 0x64, 0x4d, 0xa5, 0xa1, 0x6d, 0xce, 0x73, 0x56, 0x0a, 0xac, 0x05,
+	0x3b, 0xb4, 0xdd, 0x81, 0x65, 0xe1, 0x50, 0x99, 0x5d, 0x17, 0xc7, 0x1b, 0x4b, 0xb6, 0x5d, 0x4d,
+	0x26, 0xa8, 0x9a, 0x4d, 0x50, 0xf5, 0x28, 0x9a, 0x20, 0x6d, 0x3b, 0xdd, 0xeb, 0x32, 0x8a, 0x6a,
+	0x64, 0x2f, 0x61, 0xad, 0x29, 0x65, 0x80, 0x0b, 0xf7, 0xf7, 0x0d, 0x6c, 0x4e, 0x01, 0x1b, 0xa6,
+	0xb2, 0x06, 0xf9, 0x05, 0xf5, 0x69, 0xd8, 0x8b, 0xe6, 0x66, 0xd1, 0xbc, 0x54, 0x30, 0x35, 0xfc,
+	0x62, 0x71, 0xe0, 0x21, 0x5d, 0x1f, 0x97, 0x81, 0xc4, 0xaa, 0x96, 0xb1, 0x1a, 0x7b, 0x5a, 0x93,
+	0x3e, 0x6d, 0x3d, 0xe3, 0x93, 0xb6, 0x71, 0xb8, 0xff, 0x01, 0x43, 0xe2, 0xf4, 0x10, 0x56, 0xf5,
+	0xd6, 0xe9, 0x40, 0xa0, 0x1c, 0x70, 0xb7, 0xc7, 0x9e, 0x43, 0x69, 0xf4, 0x63, 0x38, 0x7d, 0x3f,
+	0x5f, 0x2d, 0xf5, 0xef, 0xb0, 0xd4, 0x68, 0x18, 0x91, 0xbe, 0xe3, 0x1e, 0xd2, 0x7a, 0xe1, 0xe3,
+	0x10, 0x36, 0x2e, 0xfa, 0x3f, 0xb0, 0xf5, 0x9f, 0x45, 0x80, 0x13, 0xbc, 0xe0, 0x74, 0xbd, 0xd0,
+	0xc1, 0xe8, 0x1a, 0x2c, 0x27, 0x8a, 0x0a, 0xbc, 0xc0, 0x35, 0x15, 0x17, 0x57, 0xea, 0x88, 0x8d,
+	0x75, 0x94, 0xc5, 0x92, 0x86, 0x8e, 0x61, 0xf3, 0x32, 0x3e, 0xa1, 0x96, 0xdd, 0x9b, 0x8d, 0x3e,
+	0x43, 0x11, 0xf5, 0x52, 0xdb, 0x99, 0x75, 0x25, 0x20, 0x6a, 0xf1, 0x8f, 0x22, 0x2c, 0x37, 0x7d,
+	0x9b, 0xa7, 0x75, 0x19, 0xc9, 0xd3, 0x11, 0x5b, 0x6e, 0xaa, 0x6b, 0x22, 0x96, 0xea, 0x6a, 0x47,
+	0x6f, 0x83, 0xea, 0x04, 0x5d, 0xd7, 0xb1, 0x3a, 0xa6, 0x30, 0x3d, 0x54, 0x94, 0xfe, 0xca, 0x4d,
+	0xee, 0x67, 0x9b, 0x10, 0x8f, 0xd8, 0x9b, 0x86, 0x51, 0x61, 0xfb, 0xb0, 0x62, 0x28, 0x1c, 0x4a,
+	0x56, 0x87, 0xe5, 0x68, 0xc1, 0x36, 0xc6, 0xea, 0x51, 0xdc, 0xe2, 0x6e, 0x64, 0xd4, 0xe6, 0x19,
+	0x09, 0xfc, 0xbb, 0x08, 0x77, 0x52, 0x25, 0xd1, 0xec, 0xac, 0x9d, 0x99, 0xae, 0x13, 0x9d, 0x37,
+	0x33, 0xcd, 0x8a, 0x6d, 0x74, 0x01, 0xa4, 0xa6, 0xec, 0x5a, 0xa1, 0xd9, 0x29, 0x9f, 0xa0, 0x1d,
+	0x89, 0xee, 0x1a, 0xf0, 0x3c, 0xf1, 0x52, 0xde, 0x52, 0x44, 0xfb, 0x97, 0xeb, 0xb2, 0x5e, 0xd1,
+	0x9d, 0x4a, 0xa1, 0xf1, 0xe8, 0xfc, 0x61, 0xdf, 0x51, 0x83, 0xa0, 0x5b, 0xb5, 0xb8, 0x57, 0xfb,
+	0xe6, 0x9a, 0xdd, 0xa7, 0xd2, 0xa1, 0x27, 0xda, 0x0b, 0x93, 0x07, 0x7b, 0x3f, 0x41, 0xdc, 0x8e,
+	0x3f, 0x7b, 0xff, 0x00, 0xbf, 0xba, 0x75, 0x26, 0xf4, 0x07, 0x00, 0x00,
 }
@@ -20,13 +20,22 @@ package proto
 import (
 	"fmt"
 	"math/big"
+	"time"
 
+	"github.com/xlab-si/emmy/crypto/bbs"
+	"github.com/xlab-si/emmy/crypto/bulletproofs"
 	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/commitments"
 	"github.com/xlab-si/emmy/crypto/common"
 	"github.com/xlab-si/emmy/crypto/df"
 	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/ecschnorr"
+	"github.com/xlab-si/emmy/crypto/pairing"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
 	"github.com/xlab-si/emmy/crypto/qr"
 	"github.com/xlab-si/emmy/crypto/schnorr"
+	"github.com/xlab-si/emmy/webauthn"
 )
 
 type PbConvertibleType interface {
@@ -35,8 +44,8 @@ type PbConvertibleType interface {
 
 func (el *ECGroupElement) GetNativeType() *ec.GroupElement {
 	return &ec.GroupElement{
-		X: new(big.Int).SetBytes(el.X),
-		Y: new(big.Int).SetBytes(el.Y),
+		X: new(big.Int).SetBytes(el.GetX()),
+		Y: new(big.Int).SetBytes(el.GetY()),
 	}
 }
 
@@ -47,8 +56,8 @@ func ToPbECGroupElement(el *ec.GroupElement) *ECGroupElement {
 
 func (el *Pair) GetNativeType() *common.Pair {
 	return &common.Pair{
-		A: new(big.Int).SetBytes(el.A),
-		B: new(big.Int).SetBytes(el.B),
+		A: new(big.Int).SetBytes(el.GetA()),
+		B: new(big.Int).SetBytes(el.GetB()),
 	}
 }
 
@@ -89,18 +98,7 @@ func ToPbCredRequest(r *cl.CredRequest) *CLCredReq {
 		ProofData:       uData,
 	}
 
-	proofs := make([]*FiatShamir, len(r.CommitmentsOfAttrsProofs))
-	for i, proof := range r.CommitmentsOfAttrsProofs {
-		pData = make([][]byte, 2)
-		pData[0] = proof.ProofData1.Bytes()
-		pData[1] = proof.ProofData2.Bytes()
-		fs := &FiatShamir{
-			ProofRandomData: proof.ProofRandomData.Bytes(),
-			Challenge:       proof.Challenge.Bytes(),
-			ProofData:       pData,
-		}
-		proofs[i] = fs
-	}
+	proofs := toPbOpeningProofs(r.CommitmentsOfAttrsProofs)
 
 	return &CLCredReq{
 		Nym:                      r.Nym.Bytes(),
@@ -115,46 +113,68 @@ func ToPbCredRequest(r *cl.CredRequest) *CLCredReq {
 }
 
 func (r *CLCredReq) GetNativeType() (*cl.CredRequest, error) {
-	nym := new(big.Int).SetBytes(r.Nym)
-	knownAttrs := make([]*big.Int, len(r.KnownAttrs))
-	for i, a := range r.KnownAttrs {
-		knownAttrs[i] = new(big.Int).SetBytes(a)
+	if r == nil || r.NymProof == nil || r.UProof == nil {
+		return nil, fmt.Errorf("incomplete credential request")
 	}
-	commitmentsOfAttrs := make([]*big.Int, len(r.CommitmentsOfAttrs))
-	for i, a := range r.CommitmentsOfAttrs {
-		commitmentsOfAttrs[i] = new(big.Int).SetBytes(a)
+
+	d := NewDecoder()
+	nym := d.Int("nym", r.Nym)
+	knownAttrs := d.Ints("known attribute", r.KnownAttrs)
+	commitmentsOfAttrs := d.Ints("commitment of attribute", r.CommitmentsOfAttrs)
+	nymProof := schnorr.NewProof(d.Int("nym proof random data", r.NymProof.ProofRandomData),
+		d.Int("nym proof challenge", r.NymProof.Challenge),
+		d.Ints("nym proof data", r.NymProof.ProofData))
+	U := d.Int("U", r.U)
+	UProof := qr.NewRepresentationProof(d.Int("U proof random data", r.UProof.ProofRandomData),
+		d.Int("U proof challenge", r.UProof.Challenge),
+		d.Decimals("U proof data", r.UProof.ProofData))
+	nonce := d.Int("nonce", r.Nonce)
+	if err := d.Err(); err != nil {
+		return nil, err
 	}
 
-	pData := make([]*big.Int, len(r.NymProof.ProofData))
-	for i, p := range r.NymProof.ProofData {
-		pData[i] = new(big.Int).SetBytes(p)
+	commitmentsOfAttrsProofs, err := openingProofs(r.CommitmentsOfAttrsProofs)
+	if err != nil {
+		return nil, err
 	}
-	nymProof := schnorr.NewProof(new(big.Int).SetBytes(r.NymProof.ProofRandomData),
-		new(big.Int).SetBytes(r.NymProof.Challenge), pData)
 
-	U := new(big.Int).SetBytes(r.U)
+	return cl.NewCredRequest(nym, knownAttrs, commitmentsOfAttrs, nymProof, U, UProof,
+		commitmentsOfAttrsProofs, nonce), nil
+}
 
-	pData = make([]*big.Int, len(r.UProof.ProofData))
-	for i, p := range r.UProof.ProofData {
-		si, success := new(big.Int).SetString(p, 10)
-		if !success {
-			return nil, fmt.Errorf("error when initializing big.Int from string")
+// toPbOpeningProofs converts proofs of opening of commitments of attributes.
+func toPbOpeningProofs(proofs []*commitments.OpeningProof) []*FiatShamir {
+	pbProofs := make([]*FiatShamir, len(proofs))
+	for i, proof := range proofs {
+		pbProofs[i] = &FiatShamir{
+			ProofRandomData: proof.ProofRandomData.Bytes(),
+			Challenge:       proof.Challenge.Bytes(),
+			ProofData:       [][]byte{proof.ProofData1.Bytes(), proof.ProofData2.Bytes()},
 		}
-		pData[i] = si
 	}
-	UProof := qr.NewRepresentationProof(new(big.Int).SetBytes(r.UProof.ProofRandomData),
-		new(big.Int).SetBytes(r.UProof.Challenge), pData)
 
-	commitmentsOfAttrsProofs := make([]*df.OpeningProof, len(r.CommitmentsOfAttrsProofs))
-	for i, proof := range r.CommitmentsOfAttrsProofs {
-		openingProof := df.NewOpeningProof(new(big.Int).SetBytes(proof.ProofRandomData),
-			new(big.Int).SetBytes(proof.Challenge), new(big.Int).SetBytes(proof.ProofData[0]),
-			new(big.Int).SetBytes(proof.ProofData[1]))
-		commitmentsOfAttrsProofs[i] = openingProof
+	return pbProofs
+}
+
+// openingProofs converts proofs of opening of commitments of attributes, received
+// from the other party.
+func openingProofs(pbProofs []*FiatShamir) ([]*commitments.OpeningProof, error) {
+	d := NewDecoder()
+	proofs := make([]*commitments.OpeningProof, len(pbProofs))
+	for i, proof := range pbProofs {
+		if proof == nil || len(proof.ProofData) != 2 {
+			return nil, fmt.Errorf("malformed proof of commitment of attribute %d", i)
+		}
+		proofs[i] = commitments.NewOpeningProof(d.Int("opening proof random data", proof.ProofRandomData),
+			d.Int("opening proof challenge", proof.Challenge),
+			d.Int("opening proof data", proof.ProofData[0]),
+			d.Int("opening proof data", proof.ProofData[1]))
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
 	}
 
-	return cl.NewCredRequest(nym, knownAttrs, commitmentsOfAttrs, nymProof, U, UProof,
-		commitmentsOfAttrsProofs, new(big.Int).SetBytes(r.Nonce)), nil
+	return proofs, nil
 }
 
 func ToPbCLCredential(c *cl.Cred, AProof *qr.RepresentationProof) *CLCredential {
@@ -169,20 +189,29 @@ func ToPbCLCredential(c *cl.Cred, AProof *qr.RepresentationProof) *CLCredential
 		E:      c.E.Bytes(),
 		V11:    c.V11.Bytes(),
 		AProof: AProofFS,
+		KeyID:  c.KeyID,
 	}
 }
 
 func (c *CLCredential) GetNativeType() (*cl.Cred, *qr.RepresentationProof, error) {
-	si, success := new(big.Int).SetString(c.AProof.ProofData[0], 10)
-	if !success {
-		return nil, nil, fmt.Errorf("error when initializing big.Int from string")
+	if c == nil || c.AProof == nil || len(c.AProof.ProofData) != 1 {
+		return nil, nil, fmt.Errorf("incomplete credential")
 	}
 
-	AProof := qr.NewRepresentationProof(new(big.Int).SetBytes(c.AProof.ProofRandomData),
-		new(big.Int).SetBytes(c.AProof.Challenge), []*big.Int{si})
+	d := NewDecoder()
+	AProof := qr.NewRepresentationProof(d.Int("A proof random data", c.AProof.ProofRandomData),
+		d.Int("A proof challenge", c.AProof.Challenge),
+		[]*big.Int{d.Decimal("A proof data", c.AProof.ProofData[0])})
+	cred := cl.NewCred(d.Int("A", c.A), d.Int("e", c.E), d.Int("v11", c.V11))
+	if err := d.Err(); err != nil {
+		return nil, nil, err
+	}
+	if c.Witness != nil {
+		cred.Witness = c.Witness.GetNativeType()
+	}
+	cred.KeyID = c.KeyID
 
-	return cl.NewCred(new(big.Int).SetBytes(c.A), new(big.Int).SetBytes(c.E),
-		new(big.Int).SetBytes(c.V11)), AProof, nil
+	return cred, AProof, nil
 }
 
 func ToPbUpdateCLCredential(nym, nonce *big.Int, newKnownAttrs []*big.Int) *UpdateCLCredential {
@@ -198,13 +227,51 @@ func ToPbUpdateCLCredential(nym, nonce *big.Int, newKnownAttrs []*big.Int) *Upda
 	}
 }
 
-func (u *UpdateCLCredential) GetNativeType() (*big.Int, *big.Int, []*big.Int) {
-	attrs := make([]*big.Int, len(u.NewKnownAttrs))
-	for i, a := range u.NewKnownAttrs {
-		attrs[i] = new(big.Int).SetBytes(a)
+func (u *UpdateCLCredential) GetNativeType() (*big.Int, *big.Int, []*big.Int, error) {
+	if u == nil {
+		return nil, nil, nil, fmt.Errorf("missing credential update")
+	}
+
+	d := NewDecoder()
+	nym := d.Int("nym", u.Nym)
+	nonce := d.Int("nonce", u.Nonce)
+	attrs := d.Ints("known attribute", u.NewKnownAttrs)
+	if err := d.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	return nym, nonce, attrs, nil
+}
+
+func ToPbCLCredUpdate(u *cl.CredUpdate) *UpdateCLCredential {
+	pbUpdate := ToPbUpdateCLCredential(u.Nym, u.Nonce, u.KnownAttrs)
+	pbUpdate.CommitmentsOfAttrs = bigIntsToBytes(u.CommitmentsOfAttrs)
+	pbUpdate.CommitmentsOfAttrsProofs = toPbOpeningProofs(u.CommitmentsOfAttrsProofs)
+
+	return pbUpdate
+}
+
+func (u *UpdateCLCredential) GetCredUpdate() (*cl.CredUpdate, error) {
+	nym, nonce, knownAttrs, err := u.GetNativeType()
+	if err != nil {
+		return nil, err
+	}
+	commitmentsOfAttrs, err := bytesToBigInts(u.CommitmentsOfAttrs)
+	if err != nil {
+		return nil, err
+	}
+	proofs, err := openingProofs(u.CommitmentsOfAttrsProofs)
+	if err != nil {
+		return nil, err
 	}
 
-	return new(big.Int).SetBytes(u.Nym), new(big.Int).SetBytes(u.Nonce), attrs
+	return &cl.CredUpdate{
+		Nym:                      nym,
+		Nonce:                    nonce,
+		KnownAttrs:               knownAttrs,
+		CommitmentsOfAttrs:       commitmentsOfAttrs,
+		CommitmentsOfAttrsProofs: proofs,
+	}, nil
 }
 
 func ToPbProveCLCredential(A *big.Int, proof *qr.RepresentationProof,
@@ -253,26 +320,19 @@ func ToPbProveCLCredential(A *big.Int, proof *qr.RepresentationProof,
 
 func (p *ProveCLCredential) GetNativeType() (*big.Int, *qr.RepresentationProof, []*big.Int,
 	[]*big.Int, []int, []int, error) {
-	attrs := make([]*big.Int, len(p.KnownAttrs))
-	for i, a := range p.KnownAttrs {
-		attrs[i] = new(big.Int).SetBytes(a)
-	}
-
-	cAttrs := make([]*big.Int, len(p.CommitmentsOfAttrs))
-	for i, a := range p.CommitmentsOfAttrs {
-		cAttrs[i] = new(big.Int).SetBytes(a)
+	if p == nil || p.Proof == nil {
+		return nil, nil, nil, nil, nil, nil, fmt.Errorf("incomplete credential proof")
 	}
 
-	pData := make([]*big.Int, len(p.Proof.ProofData))
-	for i, p := range p.Proof.ProofData {
-		si, success := new(big.Int).SetString(p, 10)
-		if !success {
-			return nil, nil, nil, nil, nil, nil, fmt.Errorf("error when initializing big.Int from string")
-		}
-		pData[i] = si
+	d := NewDecoder()
+	A := d.Int("A", p.A)
+	attrs := d.Ints("known attribute", p.KnownAttrs)
+	cAttrs := d.Ints("commitment of attribute", p.CommitmentsOfAttrs)
+	proof := qr.NewRepresentationProof(d.Int("proof random data", p.Proof.ProofRandomData),
+		d.Int("proof challenge", p.Proof.Challenge), d.Decimals("proof data", p.Proof.ProofData))
+	if err := d.Err(); err != nil {
+		return nil, nil, nil, nil, nil, nil, err
 	}
-	proof := qr.NewRepresentationProof(new(big.Int).SetBytes(p.Proof.ProofRandomData),
-		new(big.Int).SetBytes(p.Proof.Challenge), pData)
 
 	revealedKnownAttrsIndices := make([]int, len(p.RevealedKnownAttrs))
 	for i, a := range p.RevealedKnownAttrs {
@@ -284,6 +344,901 @@ func (p *ProveCLCredential) GetNativeType() (*big.Int, *qr.RepresentationProof,
 		revealedCommitmentsOfAttrsIndices[i] = int(a)
 	}
 
-	return new(big.Int).SetBytes(p.A), proof, attrs, cAttrs, revealedKnownAttrsIndices,
+	return A, proof, attrs, cAttrs, revealedKnownAttrsIndices,
 		revealedCommitmentsOfAttrsIndices, nil
 }
+
+func ToPbWebAuthnRegistration(r *webauthn.Registration) *WebAuthnRegistration {
+	return &WebAuthnRegistration{
+		CredentialID:      r.CredentialID,
+		AttestationObject: r.AttestationObject,
+		ClientDataJSON:    r.ClientDataJSON,
+	}
+}
+
+func (r *WebAuthnRegistration) GetNativeType() *webauthn.Registration {
+	return &webauthn.Registration{
+		CredentialID:      r.CredentialID,
+		AttestationObject: r.AttestationObject,
+		ClientDataJSON:    r.ClientDataJSON,
+	}
+}
+
+func ToPbWebAuthnAssertion(a *webauthn.Assertion) *WebAuthnAssertion {
+	return &WebAuthnAssertion{
+		CredentialID:      a.CredentialID,
+		AuthenticatorData: a.AuthenticatorData,
+		ClientDataJSON:    a.ClientDataJSON,
+		Signature:         a.Signature,
+	}
+}
+
+func (a *WebAuthnAssertion) GetNativeType() *webauthn.Assertion {
+	return &webauthn.Assertion{
+		CredentialID:      a.CredentialID,
+		AuthenticatorData: a.AuthenticatorData,
+		ClientDataJSON:    a.ClientDataJSON,
+		Signature:         a.Signature,
+	}
+}
+
+func ToPbAccumulator(acc *cl.Accumulator) *Accumulator {
+	return &Accumulator{
+		N:       acc.N.Bytes(),
+		G:       acc.G.Bytes(),
+		H:       acc.H.Bytes(),
+		V:       acc.V.Bytes(),
+		Version: int32(acc.Version),
+	}
+}
+
+func (a *Accumulator) GetNativeType() (*cl.Accumulator, error) {
+	if a == nil {
+		return nil, fmt.Errorf("missing accumulator")
+	}
+
+	d := NewDecoder()
+	acc := &cl.Accumulator{
+		N:       d.Int("N", a.N),
+		G:       d.Int("G", a.G),
+		H:       d.Int("H", a.H),
+		V:       d.Int("V", a.V),
+		Version: int(a.Version),
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+
+	return acc, nil
+}
+
+func ToPbCLParams(preset string, p *cl.Params) *CLParams {
+	return &CLParams{
+		Preset:            preset,
+		RhoBitLen:         int32(p.RhoBitLen),
+		NLength:           int32(p.NLength),
+		KnownAttrsNum:     int32(p.KnownAttrsNum),
+		CommittedAttrsNum: int32(p.CommittedAttrsNum),
+		HiddenAttrsNum:    int32(p.HiddenAttrsNum),
+		AttrBitLen:        int32(p.AttrBitLen),
+		HashBitLen:        int32(p.HashBitLen),
+		SecParam:          int32(p.SecParam),
+		EBitLen:           int32(p.EBitLen),
+		E1BitLen:          int32(p.E1BitLen),
+		VBitLen:           int32(p.VBitLen),
+		ChallengeSpace:    int32(p.ChallengeSpace),
+		CommitmentScheme:  p.CommitmentScheme,
+	}
+}
+
+func (p *CLParams) GetNativeType() (*cl.Params, error) {
+	if p == nil {
+		return nil, fmt.Errorf("missing CL parameters")
+	}
+	params := &cl.Params{
+		RhoBitLen:         int(p.RhoBitLen),
+		NLength:           int(p.NLength),
+		KnownAttrsNum:     int(p.KnownAttrsNum),
+		CommittedAttrsNum: int(p.CommittedAttrsNum),
+		HiddenAttrsNum:    int(p.HiddenAttrsNum),
+		AttrBitLen:        int(p.AttrBitLen),
+		HashBitLen:        int(p.HashBitLen),
+		SecParam:          int(p.SecParam),
+		EBitLen:           int(p.EBitLen),
+		E1BitLen:          int(p.E1BitLen),
+		VBitLen:           int(p.VBitLen),
+		ChallengeSpace:    int(p.ChallengeSpace),
+		CommitmentScheme:  p.CommitmentScheme,
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+func ToPbAccumulatorUpdate(acc *cl.Accumulator, revoked []*big.Int) *AccumulatorUpdate {
+	r := make([][]byte, len(revoked))
+	for i, e := range revoked {
+		r[i] = e.Bytes()
+	}
+
+	return &AccumulatorUpdate{
+		Accumulator: ToPbAccumulator(acc),
+		Revoked:     r,
+	}
+}
+
+func (u *AccumulatorUpdate) GetNativeType() (*cl.Accumulator, []*big.Int, error) {
+	if u == nil {
+		return nil, nil, fmt.Errorf("missing accumulator update")
+	}
+	acc, err := u.Accumulator.GetNativeType()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	revoked, err := bytesToBigInts(u.Revoked)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return acc, revoked, nil
+}
+
+func ToPbNonRevocationWitness(w *cl.Witness) *NonRevocationWitness {
+	return &NonRevocationWitness{
+		W:       w.W.Bytes(),
+		Version: int32(w.Version),
+	}
+}
+
+func (w *NonRevocationWitness) GetNativeType() *cl.Witness {
+	return &cl.Witness{
+		W:       new(big.Int).SetBytes(w.W),
+		Version: int(w.Version),
+	}
+}
+
+func ToPbNonRevocationProof(proof *cl.NonRevocationProof) *NonRevocationProof {
+	pData := make([]string, len(proof.ProofData))
+	for i, p := range proof.ProofData {
+		pData[i] = p.String()
+	}
+
+	return &NonRevocationProof{
+		CU:        proof.CU.Bytes(),
+		CR:        proof.CR.Bytes(),
+		Challenge: proof.Challenge.Bytes(),
+		ProofData: pData,
+	}
+}
+
+func (p *NonRevocationProof) GetNativeType() (*cl.NonRevocationProof, error) {
+	if p == nil {
+		return nil, fmt.Errorf("missing non-revocation proof")
+	}
+
+	d := NewDecoder()
+	proof := &cl.NonRevocationProof{
+		CU: d.Int("CU", p.CU),
+		CR: d.Int("CR", p.CR),
+		AggregateProof: qr.NewAggregateProof(d.Int("challenge", p.Challenge),
+			d.Decimals("proof data", p.ProofData)),
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+func ToPbCLRangeProof(p *cl.AttrRangeProof) *CLRangeProof {
+	if p.Bulletproof != nil {
+		return &CLRangeProof{
+			Index:       int32(p.Index),
+			A:           p.A.String(),
+			B:           p.B.String(),
+			Bulletproof: ToPbCLBulletproof(p.Bulletproof),
+		}
+	}
+	return &CLRangeProof{
+		Index:             int32(p.Index),
+		A:                 p.A.String(),
+		B:                 p.B.String(),
+		ProofRandomData1:  bigIntsToStrings(p.ProofRandomData1),
+		ProofRandomData2:  bigIntsToStrings(p.ProofRandomData2),
+		Challenges1:       bigIntsToStrings(p.Challenges1),
+		Challenges2:       bigIntsToStrings(p.Challenges2),
+		ProofData1:        bigIntsToStrings(p.ProofData1),
+		ProofData2:        bigIntsToStrings(p.ProofData2),
+		SmallCommitments1: bigIntsToStrings(p.SmallCommitments1),
+		BigCommitments1:   bigIntsToStrings(p.BigCommitments1),
+		SmallCommitments2: bigIntsToStrings(p.SmallCommitments2),
+		BigCommitments2:   bigIntsToStrings(p.BigCommitments2),
+	}
+}
+
+func (p *CLRangeProof) GetNativeType() (*cl.AttrRangeProof, error) {
+	ints := make([][]*big.Int, 12)
+	for i, s := range [][]string{{p.A}, {p.B}, p.ProofRandomData1, p.ProofRandomData2,
+		p.Challenges1, p.Challenges2, p.ProofData1, p.ProofData2, p.SmallCommitments1,
+		p.BigCommitments1, p.SmallCommitments2, p.BigCommitments2} {
+		var err error
+		if ints[i], err = stringsToBigInts(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.Bulletproof != nil {
+		proof, err := p.Bulletproof.GetNativeType()
+		if err != nil {
+			return nil, err
+		}
+		return &cl.AttrRangeProof{
+			Index:       int(p.Index),
+			A:           ints[0][0],
+			B:           ints[1][0],
+			Bulletproof: proof,
+		}, nil
+	}
+
+	return &cl.AttrRangeProof{
+		Index: int(p.Index),
+		A:     ints[0][0],
+		B:     ints[1][0],
+		RangeProofNI: &df.RangeProofNI{
+			RangeProof:        df.NewRangeProof(ints[2], ints[3], ints[4], ints[5], ints[6], ints[7]),
+			SmallCommitments1: ints[8],
+			BigCommitments1:   ints[9],
+			SmallCommitments2: ints[10],
+			BigCommitments2:   ints[11],
+		},
+	}, nil
+}
+
+func ToPbCLBulletproof(p *cl.Bulletproof) *CLBulletproof {
+	proof := &CLBulletproof{
+		A:      p.A.Bytes(),
+		S:      p.S.Bytes(),
+		T1:     p.T1.Bytes(),
+		T2:     p.T2.Bytes(),
+		TauX:   p.TauX.Bytes(),
+		Mu:     p.Mu.Bytes(),
+		T:      p.T.Bytes(),
+		L:      bigIntsToBytes(p.L),
+		R:      bigIntsToBytes(p.R),
+		InnerA: p.InnerA.Bytes(),
+		InnerB: p.InnerB.Bytes(),
+	}
+	if p.Equality != nil {
+		proof.Commitment = p.Commitment.Bytes()
+		proof.Equality = &CLDFEqualityProof{
+			ProofRandomData1: p.Equality.ProofRandomData1.Bytes(),
+			ProofRandomData2: p.Equality.ProofRandomData2.Bytes(),
+			Challenge:        p.Equality.Challenge.Bytes(),
+			ProofData1:       p.Equality.ProofData1.String(),
+			ProofData21:      p.Equality.ProofData21.Bytes(),
+			ProofData22:      p.Equality.ProofData22.Bytes(),
+		}
+	}
+	return proof
+}
+
+func (p *CLBulletproof) GetNativeType() (*cl.Bulletproof, error) {
+	d := NewDecoder()
+	proof := &cl.Bulletproof{
+		Proof: &bulletproofs.Proof{
+			A:      d.Int("A", p.A),
+			S:      d.Int("S", p.S),
+			T1:     d.Int("T1", p.T1),
+			T2:     d.Int("T2", p.T2),
+			TauX:   d.Int("tau x", p.TauX),
+			Mu:     d.Int("mu", p.Mu),
+			T:      d.Int("t", p.T),
+			L:      d.Ints("L", p.L),
+			R:      d.Ints("R", p.R),
+			InnerA: d.Int("inner product a", p.InnerA),
+			InnerB: d.Int("inner product b", p.InnerB),
+		},
+	}
+	if eq := p.Equality; eq != nil {
+		proof.Commitment = d.Int("commitment", p.Commitment)
+		proof.Equality = &bulletproofs.DFEqualityProof{
+			ProofRandomData1: d.Int("proof random data 1", eq.ProofRandomData1),
+			ProofRandomData2: d.Int("proof random data 2", eq.ProofRandomData2),
+			Challenge:        d.Int("challenge", eq.Challenge),
+			ProofData1:       d.Decimal("proof data 1", eq.ProofData1),
+			ProofData21:      d.Int("proof data 21", eq.ProofData21),
+			ProofData22:      d.Int("proof data 22", eq.ProofData22),
+		}
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+// bigIntsToStrings returns decimal representations of ints.
+func bigIntsToStrings(ints []*big.Int) []string {
+	s := make([]string, len(ints))
+	for i, n := range ints {
+		s[i] = n.String()
+	}
+	return s
+}
+
+// stringsToBigInts parses decimal representations of integers.
+func stringsToBigInts(s []string) ([]*big.Int, error) {
+	d := NewDecoder()
+	ints := d.Decimals("integer", s)
+	return ints, d.Err()
+}
+
+func ToPbCLPredicate(p *cl.Predicate) *CLPredicate {
+	return &CLPredicate{
+		Type:   string(p.Type),
+		Attr:   p.Attr,
+		Values: bigIntsToStrings(p.Values),
+	}
+}
+
+func (p *CLPredicate) GetNativeType() (*cl.Predicate, error) {
+	values, err := stringsToBigInts(p.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cl.Predicate{
+		Type:   cl.PredicateType(p.Type),
+		Attr:   p.Attr,
+		Values: values,
+	}, nil
+}
+
+// ToPbAcceptableCred describes policy p as an acceptable credential.
+func ToPbAcceptableCred(p *cl.Policy) *AcceptableCred {
+	preds := make([]*CLPredicate, len(p.Predicates))
+	for i, pred := range p.Predicates {
+		preds[i] = ToPbCLPredicate(pred)
+	}
+	return &AcceptableCred{
+		OrgName:       p.Name,
+		RevealedAttrs: p.Revealed,
+		Issuers:       p.Issuers,
+		Predicates:    preds,
+		MaxAge:        int64(p.MaxAge / time.Second),
+		RangeProofs:   string(p.RangeProofs),
+	}
+}
+
+func (c *AcceptableCred) GetNativeType() (*cl.Policy, error) {
+	preds := make([]*cl.Predicate, len(c.Predicates))
+	for i, p := range c.Predicates {
+		pred, err := p.GetNativeType()
+		if err != nil {
+			return nil, err
+		}
+		preds[i] = pred
+	}
+	return &cl.Policy{
+		Name:        c.OrgName,
+		Issuers:     c.Issuers,
+		Revealed:    c.RevealedAttrs,
+		Predicates:  preds,
+		MaxAge:      time.Duration(c.MaxAge) * time.Second,
+		RangeProofs: cl.RangeProofBackend(c.RangeProofs),
+	}, nil
+}
+
+func ToPbCLThresholdCommitment(c *cl.ThresholdCommitment) *CLThresholdCommitment {
+	return &CLThresholdCommitment{
+		Index:     int32(c.Index),
+		PaillierN: c.PaillierN.Bytes(),
+		PaillierG: c.PaillierG.Bytes(),
+		EncShare:  c.EncShare.Bytes(),
+	}
+}
+
+func (c *CLThresholdCommitment) GetNativeType() (*cl.ThresholdCommitment, error) {
+	if c == nil {
+		return nil, fmt.Errorf("missing commitment")
+	}
+	d := NewDecoder()
+	commitment := &cl.ThresholdCommitment{
+		Index:     int(c.Index),
+		PaillierN: d.Int("Paillier N", c.PaillierN),
+		PaillierG: d.Int("Paillier g", c.PaillierG),
+		EncShare:  d.Int("encrypted share", c.EncShare),
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+
+	return commitment, nil
+}
+
+func ToPbCLThresholdCommitments(commitments []*cl.ThresholdCommitment) *CLThresholdCommitments {
+	pbCommitments := make([]*CLThresholdCommitment, len(commitments))
+	for i, c := range commitments {
+		pbCommitments[i] = ToPbCLThresholdCommitment(c)
+	}
+	return &CLThresholdCommitments{
+		Commitments: pbCommitments,
+	}
+}
+
+func (c *CLThresholdCommitments) GetNativeType() ([]*cl.ThresholdCommitment, error) {
+	commitments := make([]*cl.ThresholdCommitment, len(c.Commitments))
+	for i, pbC := range c.Commitments {
+		var err error
+		if commitments[i], err = pbC.GetNativeType(); err != nil {
+			return nil, err
+		}
+	}
+	return commitments, nil
+}
+
+func ToPbCLThresholdCiphertexts(cts []*cl.ThresholdCiphertext) *CLThresholdCiphertexts {
+	pbCts := make([]*CLThresholdCiphertext, len(cts))
+	for i, ct := range cts {
+		pbCts[i] = &CLThresholdCiphertext{
+			From:  int32(ct.From),
+			To:    int32(ct.To),
+			Value: ct.Value.Bytes(),
+		}
+	}
+	return &CLThresholdCiphertexts{
+		Ciphertexts: pbCts,
+	}
+}
+
+func (c *CLThresholdCiphertexts) GetNativeType() ([]*cl.ThresholdCiphertext, error) {
+	d := NewDecoder()
+	cts := make([]*cl.ThresholdCiphertext, len(c.Ciphertexts))
+	for i, pbCt := range c.Ciphertexts {
+		if pbCt == nil {
+			return nil, fmt.Errorf("missing ciphertext %d", i)
+		}
+		cts[i] = &cl.ThresholdCiphertext{
+			From:  int(pbCt.From),
+			To:    int(pbCt.To),
+			Value: d.Int("ciphertext", pbCt.Value),
+		}
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return cts, nil
+}
+
+func ToPbCLPartialSignature(s *cl.PartialSignature) *CLPartialSignature {
+	return &CLPartialSignature{
+		A: s.A.Bytes(),
+		T: s.T.Bytes(),
+	}
+}
+
+func (s *CLPartialSignature) GetNativeType() (*cl.PartialSignature, error) {
+	d := NewDecoder()
+	sig := &cl.PartialSignature{
+		A: d.Int("A", s.A),
+		T: d.Int("T", s.T),
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+func ToPbCLThresholdValue(v *big.Int) *CLThresholdValue {
+	return &CLThresholdValue{
+		Value: v.String(),
+	}
+}
+
+func (v *CLThresholdValue) GetNativeType() (*big.Int, error) {
+	d := NewDecoder()
+	n := d.Decimal("value", v.Value)
+	return n, d.Err()
+}
+
+// niNonceBound is the bound of random nonces of contexts of non-interactive proofs.
+var niNonceBound = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// NewNIContext returns the context of a non-interactive proof created now, with a
+// random nonce.
+func NewNIContext() *NIContext {
+	return &NIContext{
+		Timestamp: time.Now().Unix(),
+		Nonce:     common.GetRandomInt(niNonceBound).Bytes(),
+	}
+}
+
+// Value returns the value that a non-interactive proof with context c, verified by
+// the RPC with the given full name, is bound to. The verifier is only bound when set.
+func (c *NIContext) Value(method string) *big.Int {
+	values := []*big.Int{new(big.Int).SetBytes([]byte(method)), big.NewInt(c.Timestamp),
+		new(big.Int).SetBytes(c.Nonce)}
+	if c.Verifier != "" {
+		values = append(values, new(big.Int).SetBytes([]byte(c.Verifier)))
+	}
+	return common.Hash(values...)
+}
+
+// ToPbPseudonymsysNymGenProofNI converts a non-interactive proof for registration of
+// nym (nymA, nymB) with the CA certificate (blindedA, blindedB, r, s).
+func ToPbPseudonymsysNymGenProofNI(nymA, nymB, blindedA, blindedB, r, s *big.Int,
+	regKey string, proof *schnorr.EqualityProof, context *NIContext) *PseudonymsysNymGenProofNI {
+	return &PseudonymsysNymGenProofNI{
+		Data: &PseudonymsysNymGenProofRandomData{
+			X1:     proof.ProofRandomData1.Bytes(),
+			A1:     nymA.Bytes(),
+			B1:     nymB.Bytes(),
+			X2:     proof.ProofRandomData2.Bytes(),
+			A2:     blindedA.Bytes(),
+			B2:     blindedB.Bytes(),
+			R:      r.Bytes(),
+			S:      s.Bytes(),
+			RegKey: regKey,
+		},
+		Challenge: proof.Challenge.Bytes(),
+		Z:         proof.ProofData.Bytes(),
+		Context:   context,
+	}
+}
+
+// GetNativeType returns the equality proof held by p.
+func (p *PseudonymsysNymGenProofNI) GetNativeType() (*schnorr.EqualityProof, error) {
+	if p.Data == nil || p.Context == nil {
+		return nil, fmt.Errorf("incomplete proof")
+	}
+	d := NewDecoder()
+	proof := &schnorr.EqualityProof{
+		ProofRandomData1: d.Int("X1", p.Data.X1),
+		ProofRandomData2: d.Int("X2", p.Data.X2),
+		Challenge:        d.Int("challenge", p.Challenge),
+		ProofData:        d.Int("Z", p.Z),
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+// ToPbPseudonymsysCACertificateRequestNI converts a request for a certificate of the
+// master nym (a, b), with a non-interactive proof of knowledge of log_a(b).
+func ToPbPseudonymsysCACertificateRequestNI(a, b *big.Int, proof *schnorr.Proof,
+	context *NIContext) *PseudonymsysCACertificateRequestNI {
+	return &PseudonymsysCACertificateRequestNI{
+		A:       a.Bytes(),
+		B:       b.Bytes(),
+		Proof:   ToPbFiatShamir(proof),
+		Context: context,
+	}
+}
+
+// ToPbPseudonymsysCACertificate converts a certificate of the pseudonym system CA.
+func ToPbPseudonymsysCACertificate(cert *pseudsys.CACert) *PseudonymsysCACertificate {
+	return &PseudonymsysCACertificate{
+		BlindedA: cert.BlindedA.Bytes(),
+		BlindedB: cert.BlindedB.Bytes(),
+		R:        cert.R.Bytes(),
+		S:        cert.S.Bytes(),
+	}
+}
+
+// GetNativeType returns the certificate held by c, checking that the blinded nym
+// belongs to group.
+func (c *PseudonymsysCACertificate) GetNativeType(group *schnorr.Group) (*pseudsys.CACert,
+	error) {
+	d := NewSchnorrDecoder(group)
+	cert := pseudsys.NewCACert(d.Element("BlindedA", c.GetBlindedA()),
+		d.Element("BlindedB", c.GetBlindedB()), d.Int("R", c.GetR()), d.Int("S", c.GetS()))
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+
+	return cert, nil
+}
+
+// ToPbPseudonymsysCredential converts a credential of the pseudonym system to its
+// protobuf representation.
+func ToPbPseudonymsysCredential(cred *pseudsys.Cred) *PseudonymsysCredential {
+	return &PseudonymsysCredential{
+		SmallAToGamma: cred.SmallAToGamma.Bytes(),
+		SmallBToGamma: cred.SmallBToGamma.Bytes(),
+		AToGamma:      cred.AToGamma.Bytes(),
+		BToGamma:      cred.BToGamma.Bytes(),
+		T1: &PseudonymsysTranscript{
+			A:      cred.T1.A.Bytes(),
+			B:      cred.T1.B.Bytes(),
+			Hash:   cred.T1.Hash.Bytes(),
+			ZAlpha: cred.T1.ZAlpha.Bytes(),
+		},
+		T2: &PseudonymsysTranscript{
+			A:      cred.T2.A.Bytes(),
+			B:      cred.T2.B.Bytes(),
+			Hash:   cred.T2.Hash.Bytes(),
+			ZAlpha: cred.T2.ZAlpha.Bytes(),
+		},
+	}
+}
+
+// ToPbPseudonymsysChain converts chain to its protobuf representation, where x
+// holds proof random data for each of its credentials.
+func ToPbPseudonymsysChain(chain []*pseudsys.ChainLink, x []*big.Int) []*PseudonymsysChainLink {
+	links := make([]*PseudonymsysChainLink, len(chain))
+	for i, link := range chain {
+		links[i] = &PseudonymsysChainLink{
+			Issuer:     link.Issuer,
+			Credential: ToPbPseudonymsysCredential(link.Cred),
+			X:          x[i].Bytes(),
+		}
+	}
+	return links
+}
+
+// GetNativeType returns the chain link held by l and its proof random data, checking
+// that its values belong to group.
+func (l *PseudonymsysChainLink) GetNativeType(group *schnorr.Group) (*pseudsys.ChainLink,
+	*big.Int, error) {
+	if l.GetCredential() == nil {
+		return nil, nil, fmt.Errorf("missing credential of %s", l.GetIssuer())
+	}
+	cred, err := l.GetCredential().GetNativeType(group)
+	if err != nil {
+		return nil, nil, err
+	}
+	d := NewSchnorrDecoder(group)
+	x := d.Element("X", l.GetX())
+	if err := d.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return pseudsys.NewChainLink(l.GetIssuer(), cred), x, nil
+}
+
+// GetNativeType returns the credential of the pseudonym system held by c, checking
+// that its values belong to group.
+func (c *PseudonymsysCredential) GetNativeType(group *schnorr.Group) (*pseudsys.Cred, error) {
+	d := NewSchnorrDecoder(group)
+	t1 := schnorr.NewBlindedTrans(
+		d.Element("T1.A", c.GetT1().GetA()),
+		d.Element("T1.B", c.GetT1().GetB()),
+		d.Int("T1.Hash", c.GetT1().GetHash()),
+		d.Int("T1.ZAlpha", c.GetT1().GetZAlpha()))
+	t2 := schnorr.NewBlindedTrans(
+		d.Element("T2.A", c.GetT2().GetA()),
+		d.Element("T2.B", c.GetT2().GetB()),
+		d.Int("T2.Hash", c.GetT2().GetHash()),
+		d.Int("T2.ZAlpha", c.GetT2().GetZAlpha()))
+	cred := pseudsys.NewCred(
+		d.Element("SmallAToGamma", c.GetSmallAToGamma()),
+		d.Element("SmallBToGamma", c.GetSmallBToGamma()),
+		d.Element("AToGamma", c.GetAToGamma()),
+		d.Element("BToGamma", c.GetBToGamma()),
+		t1, t2)
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+
+	return cred, nil
+}
+
+// GetNativeType returns the credential of the pseudonym system in EC arithmetic held
+// by c, checking that its points lie on curve.
+func (c *PseudonymsysCredentialEC) GetNativeType(curve ec.Curve) (*ecpseudsys.Cred, error) {
+	d := NewECDecoder(curve)
+	transcripts := make([]*ecschnorr.BlindedTrans, 2)
+	for i, t := range []*PseudonymsysTranscriptEC{c.GetT1(), c.GetT2()} {
+		name := fmt.Sprintf("T%d", i+1)
+		a := d.ECElement(name+".A", t.GetA())
+		b := d.ECElement(name+".B", t.GetB())
+		hash := d.Int(name+".Hash", t.GetHash())
+		zAlpha := d.Int(name+".ZAlpha", t.GetZAlpha())
+		if err := d.Err(); err != nil {
+			return nil, err
+		}
+		transcripts[i] = ecschnorr.NewBlindedTrans(a.X, a.Y, b.X, b.Y, hash, zAlpha)
+	}
+	cred := ecpseudsys.NewCred(
+		d.ECElement("SmallAToGamma", c.GetSmallAToGamma()),
+		d.ECElement("SmallBToGamma", c.GetSmallBToGamma()),
+		d.ECElement("AToGamma", c.GetAToGamma()),
+		d.ECElement("BToGamma", c.GetBToGamma()),
+		transcripts[0], transcripts[1])
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+
+	return cred, nil
+}
+
+// ToPbFiatShamir converts a non-interactive proof of knowledge of a representation
+// in a Schnorr group.
+func ToPbFiatShamir(proof *schnorr.Proof) *FiatShamir {
+	return &FiatShamir{
+		ProofRandomData: proof.ProofRandomData.Bytes(),
+		Challenge:       proof.Challenge.Bytes(),
+		ProofData:       bigIntsToBytes(proof.ProofData),
+	}
+}
+
+// GetNativeType returns the proof of knowledge of a representation held by f.
+func (f *FiatShamir) GetNativeType() (*schnorr.Proof, error) {
+	if f == nil || len(f.ProofData) == 0 {
+		return nil, fmt.Errorf("incomplete proof")
+	}
+	d := NewDecoder()
+	proof := schnorr.NewProof(d.Int("proof random data", f.ProofRandomData),
+		d.Int("challenge", f.Challenge), d.Ints("proof data", f.ProofData))
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+func ToPbFiatShamirEC(proof *ecschnorr.Proof) *FiatShamirEC {
+	return &FiatShamirEC{
+		ProofRandomData: ToPbECGroupElement(proof.ProofRandomData),
+		Challenge:       proof.Challenge.Bytes(),
+		ProofData:       proof.ProofData.Bytes(),
+	}
+}
+
+// GetNativeType returns the proof of knowledge of a discrete logarithm in EC group of
+// curve held by f.
+func (f *FiatShamirEC) GetNativeType(curve ec.Curve) (*ecschnorr.Proof, error) {
+	if f == nil {
+		return nil, fmt.Errorf("incomplete proof")
+	}
+	d := NewECDecoder(curve)
+	proof := ecschnorr.NewProof(d.ECElement("proof random data", f.ProofRandomData),
+		d.Exponent("challenge", f.Challenge), d.Exponent("proof data", f.ProofData))
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+func ToPbBBSCredRequest(r *bbs.CredRequest) *BBSCredRequest {
+	return &BBSCredRequest{
+		KnownMsgs:    bigIntsToBytes(r.KnownMsgs),
+		U:            r.U.Bytes(),
+		Challenge:    r.Challenge.Bytes(),
+		SResponse:    r.SResponse.Bytes(),
+		MsgResponses: bigIntsToBytes(r.MsgResponses),
+	}
+}
+
+func (r *BBSCredRequest) GetNativeType() (*bbs.CredRequest, error) {
+	if r == nil {
+		return nil, fmt.Errorf("incomplete credential request")
+	}
+	U, err := pairing.NewG1ElementFromBytes(r.U)
+	if err != nil {
+		return nil, err
+	}
+	d := NewDecoder()
+	req := &bbs.CredRequest{
+		KnownMsgs:    d.Ints("known message", r.KnownMsgs),
+		U:            U,
+		Challenge:    d.Int("challenge", r.Challenge),
+		SResponse:    d.Int("s response", r.SResponse),
+		MsgResponses: d.Ints("message response", r.MsgResponses),
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func ToPbBBSSignature(s *bbs.Signature) *BBSSignature {
+	return &BBSSignature{
+		A: s.A.Bytes(),
+		E: s.E.Bytes(),
+		S: s.S.Bytes(),
+	}
+}
+
+func (s *BBSSignature) GetNativeType() (*bbs.Signature, error) {
+	if s == nil {
+		return nil, fmt.Errorf("missing signature")
+	}
+	A, err := pairing.NewG1ElementFromBytes(s.A)
+	if err != nil {
+		return nil, err
+	}
+	d := NewDecoder()
+	sig := &bbs.Signature{
+		A: A,
+		E: d.Int("e", s.E),
+		S: d.Int("s", s.S),
+	}
+	if err := d.Err(); err != nil {
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+func ToPbBBSProof(p *bbs.Proof) *BBSProof {
+	revealed := make([]int32, len(p.Revealed))
+	for i, ind := range p.Revealed {
+		revealed[i] = int32(ind)
+	}
+	return &BBSProof{
+		APrime:       p.APrime.Bytes(),
+		ABar:         p.ABar.Bytes(),
+		D:            p.D.Bytes(),
+		Challenge:    p.Challenge.Bytes(),
+		EResponse:    p.EResponse.Bytes(),
+		R2Response:   p.R2Response.Bytes(),
+		R3Response:   p.R3Response.Bytes(),
+		SResponse:    p.SResponse.Bytes(),
+		MsgResponses: bigIntsToBytes(p.MsgResponses),
+		Revealed:     revealed,
+		RevealedMsgs: bigIntsToBytes(p.RevealedMsgs),
+	}
+}
+
+func (p *BBSProof) GetNativeType() (*bbs.Proof, error) {
+	if p == nil {
+		return nil, fmt.Errorf("missing proof")
+	}
+	aPrime, err := pairing.NewG1ElementFromBytes(p.APrime)
+	if err != nil {
+		return nil, err
+	}
+	aBar, err := pairing.NewG1ElementFromBytes(p.ABar)
+	if err != nil {
+		return nil, err
+	}
+	d, err := pairing.NewG1ElementFromBytes(p.D)
+	if err != nil {
+		return nil, err
+	}
+	revealed := make([]int, len(p.Revealed))
+	for i, ind := range p.Revealed {
+		revealed[i] = int(ind)
+	}
+	dec := NewDecoder()
+	proof := &bbs.Proof{
+		APrime:       aPrime,
+		ABar:         aBar,
+		D:            d,
+		Challenge:    dec.Int("challenge", p.Challenge),
+		EResponse:    dec.Int("e response", p.EResponse),
+		R2Response:   dec.Int("r2 response", p.R2Response),
+		R3Response:   dec.Int("r3 response", p.R3Response),
+		SResponse:    dec.Int("s response", p.SResponse),
+		MsgResponses: dec.Ints("message response", p.MsgResponses),
+		Revealed:     revealed,
+		RevealedMsgs: dec.Ints("revealed message", p.RevealedMsgs),
+	}
+	if err := dec.Err(); err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+func bigIntsToBytes(ints []*big.Int) [][]byte {
+	b := make([][]byte, len(ints))
+	for i, n := range ints {
+		b[i] = n.Bytes()
+	}
+	return b
+}
+
+func bytesToBigInts(b [][]byte) ([]*big.Int, error) {
+	d := NewDecoder()
+	ints := d.Ints("integer", b)
+	return ints, d.Err()
+}
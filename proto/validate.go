@@ -0,0 +1,261 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proto
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/qr"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+)
+
+// MaxIntLen is the maximal length in bytes of integers converted from messages.
+// It exceeds the length of integers of all supported schemes (the longest being
+// responses of CL proofs), but prevents senders from making the receiver operate
+// on arbitrarily large integers.
+const MaxIntLen = 2048
+
+// maxDecimalLen is the maximal length of decimal representations of integers, which
+// covers signed integers of MaxIntLen bytes.
+const maxDecimalLen = MaxIntLen*8*30103/100000 + 2
+
+// Causes of invalid values, besides common.ErrNotInGroup for group elements that
+// do not belong to the expected group.
+var (
+	ErrTooLong    = errors.New("too long")
+	ErrMalformed  = errors.New("malformed")
+	ErrMissing    = errors.New("missing")
+	ErrOutOfRange = errors.New("not smaller than group order")
+)
+
+// ValueError is returned by Decoder for an invalid value of a message. Err is the
+// cause, and can be checked with errors.Is.
+type ValueError struct {
+	Name string
+	Err  error
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Name, e.Err)
+}
+
+func (e *ValueError) Unwrap() error {
+	return e.Err
+}
+
+// Decoder converts integers and group elements of messages to native types, checking
+// that they are valid, as they are controlled by the sender of the message.
+// Integers need to be at most MaxIntLen bytes long. Decoders of a group additionally
+// check that exponents are smaller than the order of the group and that elements
+// belong to the group, see CheckElement methods of the groups.
+//
+// Conversions stop at the first invalid value, which is reported as *ValueError.
+// The values returned from then on are nil, so Err needs to be checked before they
+// are used.
+type Decoder struct {
+	group   *schnorr.Group
+	ecGroup *ec.Group
+	qrGroup *qr.RSA
+	err     error
+}
+
+// NewDecoder returns a decoder of integers that do not belong to a group.
+func NewDecoder() *Decoder {
+	return &Decoder{}
+}
+
+// NewSchnorrDecoder returns a decoder of values of a protocol in Schnorr group.
+func NewSchnorrDecoder(group *schnorr.Group) *Decoder {
+	return &Decoder{group: group}
+}
+
+// NewECDecoder returns a decoder of values of a protocol in EC group of curve.
+func NewECDecoder(curve ec.Curve) *Decoder {
+	return &Decoder{ecGroup: ec.NewGroup(curve)}
+}
+
+// NewQRDecoder returns a decoder of values of a protocol in group of quadratic
+// residues modulo N. Without the factors of N, elements are only checked to have
+// Jacobi symbol 1 (see qr.RSA.CheckElement).
+func NewQRDecoder(group *qr.RSA) *Decoder {
+	return &Decoder{qrGroup: group}
+}
+
+// Err returns the error of the first invalid value, or nil if all values were valid.
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+func (d *Decoder) fail(name string, err error) {
+	d.err = &ValueError{Name: name, Err: err}
+}
+
+// Int converts b to a non-negative integer named name.
+func (d *Decoder) Int(name string, b []byte) *big.Int {
+	if d.err != nil {
+		return nil
+	}
+	if len(b) > MaxIntLen {
+		d.fail(name, fmt.Errorf("%w, longer than %d bytes", ErrTooLong, MaxIntLen))
+		return nil
+	}
+
+	return new(big.Int).SetBytes(b)
+}
+
+// Ints converts each of b to a non-negative integer, named name[i].
+func (d *Decoder) Ints(name string, b [][]byte) []*big.Int {
+	ints := make([]*big.Int, len(b))
+	for i, data := range b {
+		ints[i] = d.Int(fmt.Sprintf("%s[%d]", name, i), data)
+	}
+	if d.err != nil {
+		return nil
+	}
+
+	return ints
+}
+
+// Decimal converts decimal representation s to an integer named name.
+func (d *Decoder) Decimal(name, s string) *big.Int {
+	if d.err != nil {
+		return nil
+	}
+	if len(s) > maxDecimalLen {
+		d.fail(name, fmt.Errorf("%w, longer than %d digits", ErrTooLong, maxDecimalLen))
+		return nil
+	}
+	n, success := new(big.Int).SetString(s, 10)
+	if !success {
+		d.fail(name, fmt.Errorf("%w, not a decimal integer", ErrMalformed))
+		return nil
+	}
+
+	return n
+}
+
+// Decimals converts each of decimal representations s to an integer, named name[i].
+func (d *Decoder) Decimals(name string, s []string) []*big.Int {
+	ints := make([]*big.Int, len(s))
+	for i, data := range s {
+		ints[i] = d.Decimal(fmt.Sprintf("%s[%d]", name, i), data)
+	}
+	if d.err != nil {
+		return nil
+	}
+
+	return ints
+}
+
+// Exponent converts b to an exponent named name, which needs to be smaller than the
+// order of the group, like challenges and responses of sigma protocols.
+func (d *Decoder) Exponent(name string, b []byte) *big.Int {
+	n := d.Int(name, b)
+	if n == nil {
+		return nil
+	}
+
+	var order *big.Int
+	switch {
+	case d.group != nil:
+		order = d.group.Q
+	case d.ecGroup != nil:
+		order = d.ecGroup.Q
+	default:
+		d.fail(name, fmt.Errorf("no group of known order to check exponent against"))
+		return nil
+	}
+	if n.Cmp(order) >= 0 {
+		d.fail(name, ErrOutOfRange)
+		return nil
+	}
+
+	return n
+}
+
+// Element converts b to an element of Schnorr group named name. The element needs
+// to belong to the subgroup of order Q and must not be the identity, which would
+// make proofs about it trivial.
+func (d *Decoder) Element(name string, b []byte) *big.Int {
+	n := d.Int(name, b)
+	if n == nil {
+		return nil
+	}
+
+	if d.group == nil {
+		d.fail(name, fmt.Errorf("no Schnorr group to check element against"))
+		return nil
+	}
+	if err := d.group.CheckElement(n); err != nil {
+		d.fail(name, err)
+		return nil
+	}
+
+	return n
+}
+
+// QRElement converts b to an element of the group of quadratic residues named name.
+func (d *Decoder) QRElement(name string, b []byte) *big.Int {
+	n := d.Int(name, b)
+	if n == nil {
+		return nil
+	}
+
+	if d.qrGroup == nil {
+		d.fail(name, fmt.Errorf("no QR group to check element against"))
+		return nil
+	}
+	if err := d.qrGroup.CheckElement(n); err != nil {
+		d.fail(name, err)
+		return nil
+	}
+
+	return n
+}
+
+// ECElement converts el to a point named name, which needs to be an element of the
+// EC group of the decoder.
+func (d *Decoder) ECElement(name string, el *ECGroupElement) *ec.GroupElement {
+	if d.err != nil {
+		return nil
+	}
+	if el == nil {
+		d.fail(name, ErrMissing)
+		return nil
+	}
+
+	x := d.Int(name+".X", el.X)
+	y := d.Int(name+".Y", el.Y)
+	if d.err != nil {
+		return nil
+	}
+	if d.ecGroup == nil {
+		d.fail(name, fmt.Errorf("no curve to check point against"))
+		return nil
+	}
+	p := ec.NewGroupElement(x, y)
+	if err := d.ecGroup.CheckElement(p); err != nil {
+		d.fail(name, err)
+		return nil
+	}
+
+	return p
+}
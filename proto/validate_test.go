@@ -0,0 +1,137 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proto
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/qr"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+)
+
+func TestDecoderInts(t *testing.T) {
+	d := NewDecoder()
+	assert.Equal(t, big.NewInt(0), d.Int("x", nil))
+	assert.Equal(t, big.NewInt(258), d.Int("x", []byte{1, 2}))
+	assert.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(2)}, d.Ints("x", [][]byte{{1}, {2}}))
+	assert.Equal(t, big.NewInt(-12), d.Decimal("x", "-12"))
+	assert.NoError(t, d.Err())
+
+	for _, decode := range []func(d *Decoder){
+		func(d *Decoder) { d.Int("x", make([]byte, MaxIntLen+1)) },
+		func(d *Decoder) { d.Ints("x", [][]byte{{1}, make([]byte, MaxIntLen+1)}) },
+		func(d *Decoder) { d.Decimal("x", "0x12") },
+		func(d *Decoder) { d.Decimals("x", []string{"1", ""}) },
+		func(d *Decoder) { d.Decimal("x", string(make([]byte, maxDecimalLen+1))) },
+		func(d *Decoder) { d.Exponent("x", []byte{1}) },
+		func(d *Decoder) { d.Element("x", []byte{2}) },
+		func(d *Decoder) { d.ECElement("x", &ECGroupElement{}) },
+	} {
+		d := NewDecoder()
+		decode(d)
+		assert.Error(t, d.Err())
+		// values are not converted after the first error
+		assert.Nil(t, d.Int("y", []byte{1}))
+	}
+}
+
+func TestSchnorrDecoder(t *testing.T) {
+	group, err := schnorr.NewGroup(160)
+	if err != nil {
+		t.Fatal(err)
+	}
+	el := group.GetRandomElement()
+	one := big.NewInt(1)
+	qMinOne := new(big.Int).Sub(group.Q, one)
+
+	d := NewSchnorrDecoder(group)
+	assert.Equal(t, el, d.Element("x", el.Bytes()))
+	assert.Equal(t, qMinOne, d.Exponent("x", qMinOne.Bytes()))
+	assert.NoError(t, d.Err())
+
+	pMinOne := new(big.Int).Sub(group.P, one)
+	for _, invalid := range []*big.Int{big.NewInt(0), one, group.P,
+		new(big.Int).Add(el, group.P), pMinOne} {
+		d := NewSchnorrDecoder(group)
+		assert.Nil(t, d.Element("x", invalid.Bytes()))
+		assert.True(t, errors.Is(d.Err(), common.ErrNotInGroup), "%v", invalid)
+	}
+	d = NewSchnorrDecoder(group)
+	assert.Nil(t, d.Exponent("x", group.Q.Bytes()))
+	assert.True(t, errors.Is(d.Err(), ErrOutOfRange))
+}
+
+func TestECDecoder(t *testing.T) {
+	group := ec.NewGroup(ec.P256)
+	el := group.GetRandomElement()
+
+	d := NewECDecoder(ec.P256)
+	assert.Equal(t, el, d.ECElement("x", ToPbECGroupElement(el)))
+	assert.NoError(t, d.Err())
+
+	for _, invalid := range []*ECGroupElement{nil, {}, {X: el.X.Bytes(), Y: el.X.Bytes()},
+		{X: el.X.Bytes(), Y: new(big.Int).Add(el.Y, group.Curve.Params().P).Bytes()}} {
+		d := NewECDecoder(ec.P256)
+		assert.Nil(t, d.ECElement("x", invalid))
+		assert.Error(t, d.Err())
+		assert.False(t, errors.Is(d.Err(), ErrTooLong))
+	}
+	d = NewECDecoder(ec.P256)
+	assert.Nil(t, d.Exponent("x", group.Q.Bytes()))
+	assert.Error(t, d.Err())
+}
+
+func TestQRDecoder(t *testing.T) {
+	group, err := qr.NewRSASpecial(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	el, err := group.GetRandomElement()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, g := range []*qr.RSASpecial{group, qr.NewRSApecialPublic(group.N)} {
+		d := NewQRDecoder(&g.RSA)
+		assert.Equal(t, el, d.QRElement("x", el.Bytes()))
+		assert.NoError(t, d.Err())
+
+		d = NewQRDecoder(&g.RSA)
+		assert.Nil(t, d.QRElement("x", group.P.Bytes()))
+		assert.True(t, errors.Is(d.Err(), common.ErrNotInGroup))
+	}
+}
+
+func TestValueError(t *testing.T) {
+	d := NewDecoder()
+	d.Int("x", make([]byte, MaxIntLen+1))
+	var e *ValueError
+	assert.True(t, errors.As(d.Err(), &e))
+	assert.Equal(t, "x", e.Name)
+	assert.True(t, errors.Is(d.Err(), ErrTooLong))
+
+	d = NewDecoder()
+	d.Decimal("y", "z")
+	assert.True(t, errors.Is(d.Err(), ErrMalformed))
+	assert.Equal(t, "invalid y: malformed, not a decimal integer", d.Err().Error())
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proto
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Versions of protocol messages. The version is increased when the wire format of
+// messages changes, for example when proofs get new fields or values new encodings.
+// Clients and the server support all the versions from MinProtocolVersion to
+// ProtocolVersion, and run each protocol with the highest version supported by both,
+// negotiated when its stream is opened: the client gives the highest version it
+// supports in its first message, and the server the negotiated version in all of its
+// messages (see Message.ProtocolVersion).
+const (
+	MinProtocolVersion int32 = 1
+	ProtocolVersion    int32 = 1
+)
+
+// legacyProtocolVersion is the version of peers that do not give the version of
+// their messages, which precede version negotiation.
+const legacyProtocolVersion int32 = 1
+
+// ProtocolVersions returns the supported versions of protocol messages, from the
+// lowest to the highest.
+func ProtocolVersions() []int32 {
+	versions := make([]int32, 0, ProtocolVersion-MinProtocolVersion+1)
+	for v := MinProtocolVersion; v <= ProtocolVersion; v++ {
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// IsSupportedProtocolVersion reports whether version v of protocol messages, given by
+// a peer, is supported.
+func IsSupportedProtocolVersion(v int32) bool {
+	if v == 0 {
+		v = legacyProtocolVersion
+	}
+	return v >= MinProtocolVersion && v <= ProtocolVersion
+}
+
+// NegotiateProtocolVersion returns the version of protocol messages to be used with
+// a client that supports versions up to max, or a gRPC status error with
+// UNSUPPORTED_PROTOCOL_VERSION if the client only supports older versions.
+func NegotiateProtocolVersion(max int32) (int32, error) {
+	if max == 0 {
+		max = legacyProtocolVersion
+	}
+	if max < MinProtocolVersion {
+		return 0, NewStatusError(codes.FailedPrecondition,
+			ErrorCode_UNSUPPORTED_PROTOCOL_VERSION,
+			fmt.Sprintf("protocol version %d is not supported, the server supports versions %v",
+				max, ProtocolVersions()))
+	}
+	if max > ProtocolVersion {
+		return ProtocolVersion, nil
+	}
+	return max, nil
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	assert.Equal(t, MinProtocolVersion, ProtocolVersions()[0])
+	assert.Equal(t, ProtocolVersion, ProtocolVersions()[len(ProtocolVersions())-1])
+
+	for _, max := range []int32{0, legacyProtocolVersion, ProtocolVersion} {
+		v, err := NegotiateProtocolVersion(max)
+		assert.NoError(t, err)
+		assert.True(t, IsSupportedProtocolVersion(v))
+	}
+	// newer clients fall back to the highest version of the server
+	v, err := NegotiateProtocolVersion(ProtocolVersion + 1)
+	assert.NoError(t, err)
+	assert.Equal(t, ProtocolVersion, v)
+	assert.False(t, IsSupportedProtocolVersion(ProtocolVersion+1))
+
+	_, err = NegotiateProtocolVersion(-1)
+	assert.Equal(t, ErrorCode_UNSUPPORTED_PROTOCOL_VERSION, ToProtocolError(err).GetCode())
+}
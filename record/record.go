@@ -0,0 +1,246 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package record records sessions of emmy protocols - messages exchanged over a
+// stream, their timings and snapshots of internal state at round boundaries - and
+// replays them step by step through client or server logic, which helps finding
+// out why a proof failed in the field.
+//
+// Sessions are recorded by wrapping streams with NewServerStream or NewClientStream.
+// Protocol logic adds snapshots of its state with Snapshot, which is a no-op for
+// streams that are not recorded. A Replay stream feeds the messages of the other
+// side of a recorded session to the logic, reporting each step and whether the
+// messages the logic sends match the recorded ones. Note that values the logic
+// derives from fresh randomness (nonces, commitments) differ between the recording
+// and the replay, as do the messages that depend on them.
+//
+// Transcripts of verifications of credential proofs, exported by the server, are
+// verified again offline with VerifyTranscript.
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/xlab-si/emmy/proto"
+)
+
+// FormatVersion is the version of the format of recorded sessions.
+const FormatVersion = 1
+
+// Sides of a protocol a session can be recorded on.
+const (
+	Server = "server"
+	Client = "client"
+)
+
+// Kind is the kind of an event of a session.
+type Kind string
+
+// Kinds of events.
+const (
+	KindRecv  Kind = "recv"  // message received by the recording side
+	KindSend  Kind = "send"  // message sent by the recording side
+	KindState Kind = "state" // snapshot of internal state
+	KindError Kind = "error" // error that ended the session
+)
+
+// Event is a single event of a session.
+type Event struct {
+	Kind    Kind            `json:"kind"`
+	Elapsed time.Duration   `json:"elapsed"`           // since the start of the session
+	Message []byte          `json:"message,omitempty"` // protobuf encoded pb.Message
+	Name    string          `json:"name,omitempty"`    // name of the snapshot
+	State   json.RawMessage `json:"state,omitempty"`   // JSON encoded snapshot
+	Error   string          `json:"error,omitempty"`
+}
+
+// Msg decodes the message of e.
+func (e *Event) Msg() (*pb.Message, error) {
+	msg := new(pb.Message)
+	if err := proto.Unmarshal(e.Message, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// String returns a single line description of e.
+func (e *Event) String() string {
+	desc := ""
+	switch e.Kind {
+	case KindRecv, KindSend:
+		msg, err := e.Msg()
+		if err != nil {
+			desc = fmt.Sprintf("malformed message: %v", err)
+		} else {
+			desc = proto.CompactTextString(msg)
+		}
+	case KindState:
+		desc = fmt.Sprintf("%s = %s", e.Name, e.State)
+	case KindError:
+		desc = e.Error
+	}
+
+	return fmt.Sprintf("+%v %s %s", e.Elapsed, e.Kind, desc)
+}
+
+// Session is a recorded session of a protocol.
+type Session struct {
+	Version int       `json:"version"`
+	Method  string    `json:"method"` // name of the protocol's stream, e.g. IssueCredential
+	Side    string    `json:"side"`   // Server or Client
+	Start   time.Time `json:"start"`
+	Events  []Event   `json:"events"`
+}
+
+// Failed returns whether s ended with an error.
+func (s *Session) Failed() bool {
+	return len(s.Events) > 0 && s.Events[len(s.Events)-1].Kind == KindError
+}
+
+// WriteJSON writes s to w as indented JSON.
+func (s *Session) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Load reads a session from file path.
+func Load(path string) (*Session, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	s := new(Session)
+	if err := json.NewDecoder(f).Decode(s); err != nil {
+		return nil, fmt.Errorf("malformed session %s: %v", path, err)
+	}
+	if s.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported version of session %s: %d", path, s.Version)
+	}
+
+	return s, nil
+}
+
+// Recorder records a session. It is safe for concurrent use.
+type Recorder struct {
+	mu sync.Mutex
+	s  *Session
+}
+
+// NewRecorder starts recording a session of method on side.
+func NewRecorder(method, side string) *Recorder {
+	return &Recorder{
+		s: &Session{
+			Version: FormatVersion,
+			Method:  method,
+			Side:    side,
+			Start:   time.Now(),
+		},
+	}
+}
+
+func (r *Recorder) add(e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e.Elapsed = time.Since(r.s.Start)
+	r.s.Events = append(r.s.Events, e)
+}
+
+// Message records msg, received or sent as given by kind.
+func (r *Recorder) Message(kind Kind, msg *pb.Message) {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		r.Error(fmt.Errorf("cannot record message: %v", err))
+		return
+	}
+	r.add(Event{Kind: kind, Message: data})
+}
+
+// State records a snapshot of state under name. state is encoded as JSON.
+func (r *Recorder) State(name string, state interface{}) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("cannot encode state: %v", err))
+	}
+	r.add(Event{Kind: KindState, Name: name, State: data})
+}
+
+// Error records err, which ended the session.
+func (r *Recorder) Error(err error) {
+	r.add(Event{Kind: KindError, Error: err.Error()})
+}
+
+// Session returns a copy of the session recorded so far.
+func (r *Recorder) Session() *Session {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := *r.s
+	s.Events = append([]Event(nil), r.s.Events...)
+	return &s
+}
+
+// Save writes the session recorded so far to a new file in dir, named by the
+// start of the session, side and method, and returns its path.
+func (r *Recorder) Save(dir string) (string, error) {
+	s := r.Session()
+	name := fmt.Sprintf("%s-%s-%s.json", s.Start.UTC().Format("20060102T150405.000000000"),
+		s.Side, strings.Replace(s.Method, "/", "_", -1))
+	path := filepath.Join(dir, name)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return path, s.WriteJSON(f)
+}
+
+type recorderKey struct{}
+
+// NewContext returns a copy of ctx carrying r.
+func NewContext(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// FromContext returns the recorder carried by ctx, or nil.
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// Snapshot records state under name with the recorder carried by ctx, if any.
+// Protocol logic calls it with the context of its stream at round boundaries.
+func Snapshot(ctx context.Context, name string, state interface{}) {
+	if r := FromContext(ctx); r != nil {
+		r.State(name, state)
+	}
+}
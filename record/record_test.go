@@ -0,0 +1,151 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package record
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/xlab-si/emmy/proto"
+)
+
+func bigintMsg(x byte) *pb.Message {
+	return &pb.Message{
+		Content: &pb.Message_Bigint{
+			Bigint: &pb.BigInt{X1: []byte{x}},
+		},
+	}
+}
+
+func testSession() *Session {
+	r := NewRecorder("IssueCredential", Server)
+	r.Message(KindRecv, bigintMsg(1))
+	r.State("nonce", 42)
+	r.Message(KindSend, bigintMsg(2))
+	r.Message(KindRecv, bigintMsg(3))
+	r.Message(KindSend, bigintMsg(4))
+	return r.Session()
+}
+
+func TestRecorderSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emmy-sessions")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r := NewRecorder("ProveCredential", Client)
+	r.Message(KindSend, bigintMsg(1))
+	r.State("verified", false)
+	r.Error(fmt.Errorf("user authentication failed"))
+
+	path, err := r.Save(dir)
+	require.NoError(t, err)
+	s, err := Load(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ProveCredential", s.Method)
+	assert.Equal(t, Client, s.Side)
+	assert.True(t, s.Failed())
+	require.Len(t, s.Events, 3)
+	msg, err := s.Events[0].Msg()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, msg.GetBigint().X1)
+	assert.Equal(t, "verified", s.Events[1].Name)
+	assert.JSONEq(t, "false", string(s.Events[1].State))
+	assert.Equal(t, "user authentication failed", s.Events[2].Error)
+	for i := 1; i < len(s.Events); i++ {
+		assert.True(t, s.Events[i].Elapsed >= s.Events[i-1].Elapsed)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	// snapshots without a recorder are ignored
+	Snapshot(context.Background(), "nonce", 1)
+
+	r := NewRecorder("IssueCredential", Server)
+	Snapshot(NewContext(context.Background(), r), "nonce", 1)
+	s := r.Session()
+	require.Len(t, s.Events, 1)
+	assert.Equal(t, KindState, s.Events[0].Kind)
+}
+
+func TestReplay(t *testing.T) {
+	var steps []*Step
+	replay := NewReplay(testSession(), func(st *Step) error {
+		steps = append(steps, st)
+		return nil
+	})
+
+	msg, err := replay.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, msg.GetBigint().X1)
+	Snapshot(replay.Context(), "nonce", 43)
+	require.NoError(t, replay.Send(bigintMsg(2)))
+	_, err = replay.Recv()
+	require.NoError(t, err)
+	require.NoError(t, replay.Send(bigintMsg(5)))
+	_, err = replay.Recv()
+	assert.Equal(t, io.EOF, err)
+	require.NoError(t, replay.Finish(nil))
+
+	require.Len(t, steps, 5)
+	for i, st := range steps {
+		assert.Equal(t, i+1, st.Index)
+	}
+	assert.Equal(t, KindState, steps[1].Event.Kind)
+	assert.False(t, steps[2].Diverged)
+	assert.True(t, steps[4].Diverged)
+	assert.Equal(t, []byte{5}, steps[4].Message.GetBigint().X1)
+
+	// snapshots of the replayed logic are recorded
+	replayed := replay.Replayed()
+	require.Len(t, replayed.Events, 5)
+	assert.JSONEq(t, "43", string(replayed.Events[1].State))
+}
+
+func TestReplayAbort(t *testing.T) {
+	replay := NewReplay(testSession(), func(st *Step) error {
+		if st.Event.Kind == KindState {
+			return fmt.Errorf("aborted")
+		}
+		return nil
+	})
+
+	_, err := replay.Recv()
+	require.NoError(t, err)
+	assert.Error(t, replay.Send(bigintMsg(2)))
+}
+
+func TestReplayOpenStream(t *testing.T) {
+	_, err := NewReplay(testSession(), nil).OpenStream(context.Background(), "IssueCredential")
+	assert.Error(t, err, "server session should not be replayed through client logic")
+
+	s := testSession()
+	s.Side = Client
+	replay := NewReplay(s, nil)
+	_, err = replay.OpenStream(context.Background(), "ProveCredential")
+	assert.Error(t, err)
+	stream, err := replay.OpenStream(context.Background(), "IssueCredential")
+	require.NoError(t, err)
+	assert.Equal(t, replay, stream)
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package record
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/metadata"
+)
+
+// Step is a step of a replayed session.
+type Step struct {
+	Index int
+	// Event is the recorded event of the step, nil when the replayed logic sent a
+	// message that was not recorded.
+	Event *Event
+	// Message is the message the replayed logic sent in place of the recorded one,
+	// nil for events other than sent messages.
+	Message *pb.Message
+	// Diverged is set when Message differs from the recorded message.
+	Diverged bool
+}
+
+// Replay replays a recorded session through the logic of the side it was recorded
+// on. It is a stream of emmy protocols: Recv returns messages the recording side
+// received, and messages passed to Send are compared with those the recording
+// side sent. Each step is reported to the callback passed to NewReplay, which can
+// abort the replay by returning an error.
+//
+// Replay can be passed to server handlers, or to clients with their
+// UseStreamOpener method.
+type Replay struct {
+	s      *Session
+	next   int
+	steps  int
+	onStep func(*Step) error
+	ctx    context.Context
+	r      *Recorder
+}
+
+// NewReplay returns a Replay of s reporting steps to onStep, which may be nil.
+func NewReplay(s *Session, onStep func(*Step) error) *Replay {
+	if onStep == nil {
+		onStep = func(*Step) error { return nil }
+	}
+	r := NewRecorder(s.Method, s.Side)
+
+	return &Replay{
+		s:      s,
+		onStep: onStep,
+		ctx:    NewContext(context.Background(), r),
+		r:      r,
+	}
+}
+
+// Replayed returns the session recorded during the replay, including snapshots
+// made by the replayed logic, to be compared with the recorded one.
+func (r *Replay) Replayed() *Session {
+	return r.r.Session()
+}
+
+func (r *Replay) step(e *Event, msg *pb.Message, diverged bool) error {
+	r.steps++
+	return r.onStep(&Step{
+		Index:    r.steps,
+		Event:    e,
+		Message:  msg,
+		Diverged: diverged,
+	})
+}
+
+// advance reports recorded events up to the next event of kind, and returns it
+// without reporting it. It returns nil if there is no such event.
+func (r *Replay) advance(kind Kind) (*Event, error) {
+	for r.next < len(r.s.Events) {
+		e := &r.s.Events[r.next]
+		r.next++
+		if e.Kind == kind {
+			return e, nil
+		}
+		if err := r.step(e, nil, false); err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// Send compares msg with the next message sent in the recorded session.
+func (r *Replay) Send(msg *pb.Message) error {
+	r.r.Message(KindSend, msg)
+	e, err := r.advance(KindSend)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return r.step(nil, msg, true)
+	}
+
+	recorded, err := e.Msg()
+	if err != nil {
+		return err
+	}
+
+	return r.step(e, msg, !proto.Equal(recorded, msg))
+}
+
+// Recv returns the next message received in the recorded session, or io.EOF when
+// there are none left.
+func (r *Replay) Recv() (*pb.Message, error) {
+	e, err := r.advance(KindRecv)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, io.EOF
+	}
+
+	msg, err := e.Msg()
+	if err != nil {
+		return nil, err
+	}
+	r.r.Message(KindRecv, msg)
+	if err := r.step(e, nil, false); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// Finish reports the recorded events that were not replayed. err is the error
+// the replayed logic ended with, if any.
+func (r *Replay) Finish(err error) error {
+	if err != nil {
+		r.r.Error(err)
+	}
+	for r.next < len(r.s.Events) {
+		e := &r.s.Events[r.next]
+		r.next++
+		if err := r.step(e, nil, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OpenStream returns r for the method of the recorded session. It makes Replay a
+// client.StreamOpener.
+func (r *Replay) OpenStream(ctx context.Context, method string) (pb.ClientStream, error) {
+	if r.s.Side != Client {
+		return nil, fmt.Errorf("session was recorded on the %s side", r.s.Side)
+	}
+	if method != r.s.Method {
+		return nil, fmt.Errorf("session was recorded for %s, not %s", r.s.Method, method)
+	}
+
+	return r, nil
+}
+
+// Context returns a context carrying the recorder of the replay, so that
+// snapshots made by the replayed logic end up in Replayed.
+func (r *Replay) Context() context.Context {
+	return r.ctx
+}
+
+// Methods below make Replay fit gRPC stream interfaces.
+
+func (r *Replay) SetHeader(metadata.MD) error  { return nil }
+func (r *Replay) SendHeader(metadata.MD) error { return nil }
+func (r *Replay) SetTrailer(metadata.MD)       {}
+func (r *Replay) Header() (metadata.MD, error) { return nil, nil }
+func (r *Replay) Trailer() metadata.MD         { return nil }
+func (r *Replay) CloseSend() error             { return nil }
+
+func (r *Replay) SendMsg(m interface{}) error {
+	msg, ok := m.(*pb.Message)
+	if !ok {
+		return fmt.Errorf("unexpected message of type %T", m)
+	}
+	return r.Send(msg)
+}
+
+func (r *Replay) RecvMsg(m interface{}) error {
+	msg, ok := m.(*pb.Message)
+	if !ok {
+		return fmt.Errorf("unexpected message of type %T", m)
+	}
+	recvd, err := r.Recv()
+	if err != nil {
+		return err
+	}
+	proto.Merge(msg, recvd)
+	return nil
+}
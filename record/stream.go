@@ -0,0 +1,103 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package record
+
+import (
+	"context"
+
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc"
+)
+
+// serverStream records messages of a gRPC server stream.
+type serverStream struct {
+	grpc.ServerStream
+	r *Recorder
+}
+
+// NewServerStream returns a server stream that records messages exchanged over ss
+// with r. Its context carries r, so that handlers can add snapshots to the session.
+func NewServerStream(ss grpc.ServerStream, r *Recorder) grpc.ServerStream {
+	return &serverStream{
+		ServerStream: ss,
+		r:            r,
+	}
+}
+
+// Context derives the context from the context of the underlying stream on each
+// call, as that may change during the stream (see tracing.NewServerStream).
+func (s *serverStream) Context() context.Context {
+	return NewContext(s.ServerStream.Context(), s.r)
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	if msg, ok := m.(*pb.Message); ok {
+		s.r.Message(KindSend, msg)
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if msg, ok := m.(*pb.Message); ok {
+		s.r.Message(KindRecv, msg)
+	}
+	return nil
+}
+
+// ClientStream records messages of a client stream of an emmy protocol.
+type ClientStream struct {
+	pb.ClientStream
+	ctx      context.Context
+	Recorder *Recorder
+}
+
+// NewClientStream returns a client stream that records messages exchanged over cs
+// with r.
+func NewClientStream(cs pb.ClientStream, r *Recorder) *ClientStream {
+	return &ClientStream{
+		ClientStream: cs,
+		ctx:          NewContext(cs.Context(), r),
+		Recorder:     r,
+	}
+}
+
+func (s *ClientStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *ClientStream) Send(msg *pb.Message) error {
+	s.Recorder.Message(KindSend, msg)
+	if err := s.ClientStream.Send(msg); err != nil {
+		s.Recorder.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (s *ClientStream) Recv() (*pb.Message, error) {
+	msg, err := s.ClientStream.Recv()
+	if err != nil {
+		s.Recorder.Error(err)
+		return nil, err
+	}
+	s.Recorder.Message(KindRecv, msg)
+	return msg, nil
+}
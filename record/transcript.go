@@ -0,0 +1,174 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+)
+
+// TranscriptVersion is the version of the format of transcripts.
+const TranscriptVersion = 1
+
+// Transcript is a transcript of the verification of a proof of a CL credential by
+// the server. It holds everything needed to verify the proof again offline with
+// VerifyTranscript, for auditing.
+type Transcript struct {
+	Version  int        `json:"version"`
+	Protocol string     `json:"protocol"` // e.g. ProveCredential or ProveCredentialNI
+	Time     time.Time  `json:"time"`
+	Tenant   string     `json:"tenant,omitempty"`
+	Params   *cl.Params `json:"params"`
+	PubKey   []byte     `json:"pub_key"` // PEM encoded public key of the issuer
+	Nonce    []byte     `json:"nonce"`   // nonce the proof was built with
+	Proof    []byte     `json:"proof"`   // protobuf encoded pb.ProveCLCredential
+	Verified bool       `json:"verified"`
+	Reason   string     `json:"reason,omitempty"` // why the server rejected the proof
+}
+
+// NewTranscript returns a transcript of the verification of proof, built with nonce
+// for the issuer with pubKey, which the server accepted when reason is empty.
+func NewTranscript(protocol, tenant string, params *cl.Params, pubKey *cl.PubKey,
+	nonce *big.Int, proof *pb.ProveCLCredential, reason string) (*Transcript, error) {
+	pubKeyPEM, err := pubKey.MarshalPEM()
+	if err != nil {
+		return nil, err
+	}
+	proofData, err := proto.Marshal(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Transcript{
+		Version:  TranscriptVersion,
+		Protocol: protocol,
+		Time:     time.Now().UTC(),
+		Tenant:   tenant,
+		Params:   params,
+		PubKey:   pubKeyPEM,
+		Nonce:    nonce.Bytes(),
+		Proof:    proofData,
+		Verified: reason == "",
+		Reason:   reason,
+	}, nil
+}
+
+// Save writes t as indented JSON to a new file in dir, named by the time of the
+// verification and protocol, and returns its path.
+func (t *Transcript) Save(dir string) (string, error) {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s.json", t.Time.UTC().Format("20060102T150405.000000000"),
+		t.Protocol)
+	path := filepath.Join(dir, name)
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return path, err
+}
+
+// LoadTranscript reads a transcript from file path.
+func LoadTranscript(path string) (*Transcript, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	t := new(Transcript)
+	if err := json.NewDecoder(f).Decode(t); err != nil {
+		return nil, fmt.Errorf("malformed transcript %s: %v", path, err)
+	}
+	if t.Version != TranscriptVersion {
+		return nil, fmt.Errorf("unsupported version of transcript %s: %d", path, t.Version)
+	}
+
+	return t, nil
+}
+
+// VerifyTranscript checks all equations of the proof in t - the proof of the
+// credential and range proofs of committed attributes - with the public key and nonce
+// in t, and returns an error describing the first one that does not hold. It does not
+// check whether revealed attributes were acceptable to the server. Non-revocation
+// proofs and device assertions depend on the state of the server at the time of the
+// verification, and are not checked either.
+func VerifyTranscript(t *Transcript) error {
+	if t.Params == nil {
+		return fmt.Errorf("transcript has no parameters")
+	}
+	pubKey, err := cl.ParsePubKeyPEM(t.PubKey)
+	if err != nil {
+		return fmt.Errorf("invalid public key: %v", err)
+	}
+	pReq := new(pb.ProveCLCredential)
+	if err := proto.Unmarshal(t.Proof, pReq); err != nil {
+		return fmt.Errorf("malformed proof: %v", err)
+	}
+	A, proof, knownAttrs, commitmentsOfAttrs, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, err := pReq.GetNativeType()
+	if err != nil {
+		return fmt.Errorf("malformed proof: %v", err)
+	}
+
+	org, err := cl.NewOrgFromParams(t.Params, &cl.KeyPair{Pub: pubKey})
+	if err != nil {
+		return err
+	}
+	nonce := new(big.Int).SetBytes(t.Nonce)
+	org.SetProveCredNonce(nonce)
+	ok, err := org.VerifyCredProof(A, proof, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, knownAttrs, commitmentsOfAttrs)
+	if err != nil {
+		return fmt.Errorf("credential proof: %v", err)
+	}
+	if !ok {
+		return fmt.Errorf("credential proof does not verify")
+	}
+
+	for i, p := range pReq.RangeProofs {
+		rangeProof, err := p.GetNativeType()
+		if err != nil {
+			return fmt.Errorf("malformed range proof %d: %v", i, err)
+		}
+		ok, err := org.VerifyAttrRangeProofs([]*cl.AttrRangeProof{rangeProof},
+			revealedCommitmentsOfAttrsIndices, commitmentsOfAttrs, nonce)
+		if err != nil {
+			return fmt.Errorf("range proof of attribute %d: %v", rangeProof.Index, err)
+		}
+		if !ok {
+			return fmt.Errorf("range proof of attribute %d does not verify", rangeProof.Index)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,294 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package sdk
+
+// helpers holds templates of helper files of each language, by their paths
+// relative to the directory of stubs.
+var helpers = map[Language]map[string]string{
+	Python: {
+		"../__init__.py": "",
+		"__init__.py":    pythonInit,
+		"helpers.py":     pythonHelpers,
+	},
+	TypeScript: {
+		"helpers.ts": typeScriptHelpers,
+	},
+}
+
+const pythonInit = `# Code generated by emmy sdk. DO NOT EDIT.
+"""Client stubs of emmy API {{.Version}}."""
+`
+
+const pythonHelpers = `# Code generated by emmy sdk. DO NOT EDIT.
+"""Helpers for clients of emmy API {{.Version}}."""
+
+import hashlib
+import random
+
+import grpc
+from google.protobuf import empty_pb2
+
+from . import messages_pb2, services_pb2_grpc
+
+API_VERSION = "{{.Version}}"
+
+# Header tying gRPC-Web requests of a protocol stream together.
+STREAM_ID_HEADER = "{{.StreamIDHeader}}"
+
+
+def encode_bigint(n):
+    """Encodes a non-negative integer as unsigned big-endian bytes, as emmy does."""
+    if n < 0:
+        raise ValueError("negative integers cannot be encoded")
+    return n.to_bytes((n.bit_length() + 7) // 8, "big")
+
+
+def decode_bigint(b):
+    """Decodes unsigned big-endian bytes into an integer."""
+    return int.from_bytes(b, "big")
+
+
+# Version of the encoding of attribute values of CL credentials (cl.AttrEncoder).
+ATTR_ENCODING_VERSION = {{.AttrEncodingVersion}}
+
+_ATTR_HASH_DOMAIN = b"{{.AttrHashDomain}}"
+
+
+def encode_attr_string(s, bit_len=256):
+    """Encodes a string attribute of bit length bit_len as cl.AttrEncoder does:
+    by its UTF-8 bytes if they fit, otherwise by their hash."""
+    b = s.encode("utf-8")
+    v = int.from_bytes(b, "big")
+    if not b or (b[0] != 0 and v.bit_length() < bit_len):
+        return v
+    n = (bit_len + 7) // 8
+    digest = b""
+    counter = 0
+    while len(digest) < n:
+        digest += hashlib.sha256(_ATTR_HASH_DOMAIN + counter.to_bytes(4, "big") + b).digest()
+        counter += 1
+    top = 1 << (bit_len - 1)
+    return int.from_bytes(digest[:n], "big") % top + top
+
+
+def encode_attr_date(d):
+    """Encodes a datetime.date attribute as the number of days since 1 January of year 1."""
+    return d.toordinal() - 1
+
+
+def encode_attr_enum(values, v):
+    """Encodes an enum attribute as the position of v in values."""
+    return values.index(v)
+
+
+def encode_attr_bool(b):
+    return 1 if b else 0
+
+
+def new_client_id():
+    """Returns a random identifier of the client in a protocol stream."""
+    return random.randint(0, 2 ** 31 - 1)
+
+
+def new_message(client_id, **content):
+    """Returns a Message of the client with the given content, for example
+    new_message(client_id, bigint=messages_pb2.BigInt(X1=encode_bigint(42)))."""
+    return messages_pb2.Message(clientId=client_id, **content)
+
+
+class Client(object):
+    """Calls non-streaming APIs of an emmy server over a gRPC channel."""
+
+    def __init__(self, channel):
+        self.info = services_pb2_grpc.InfoStub(channel)
+        self.cl = services_pb2_grpc.CLStub(channel)
+
+    def service_info(self):
+        return self.info.GetServiceInfo(empty_pb2.Empty())
+
+    def credential_structure(self, name="", version=""):
+        """Returns version of credential schema name, the default schema when name
+        is empty and its latest version when version is empty."""
+        return self.cl.GetCredentialStructure(
+            messages_pb2.CredStructureRequest(name=name, version=version))
+
+    def acceptable_credentials(self):
+        return self.cl.GetAcceptableCredentials(empty_pb2.Empty())
+
+
+def connect(endpoint, root_certificates=None, server_name=None):
+    """Returns a Client of the emmy server at endpoint, connected over TLS.
+    root_certificates (PEM bytes) and server_name are needed for servers with
+    self-signed certificates."""
+    options = []
+    if server_name:
+        options.append(("grpc.ssl_target_name_override", server_name))
+    channel = grpc.secure_channel(endpoint,
+                                  grpc.ssl_channel_credentials(root_certificates),
+                                  options)
+    return Client(channel)
+`
+
+const typeScriptHelpers = `// Code generated by emmy sdk. DO NOT EDIT.
+// Helpers for clients of emmy API {{.Version}}.
+
+import {Empty} from 'google-protobuf/google/protobuf/empty_pb';
+
+import {AcceptableCreds, CredStructure, CredStructureRequest, Message, ServiceInfo} from './messages_pb';
+import {CLClient, InfoClient} from './ServicesServiceClientPb';
+
+export const API_VERSION = '{{.Version}}';
+
+// Header tying gRPC-Web requests of a protocol stream together.
+export const STREAM_ID_HEADER = '{{.StreamIDHeader}}';
+
+// encodeBigInt encodes a non-negative integer as unsigned big-endian bytes, as emmy does.
+export function encodeBigInt(n: bigint): Uint8Array {
+  if (n < BigInt(0)) {
+    throw new Error('negative integers cannot be encoded');
+  }
+  let hex = n === BigInt(0) ? '' : n.toString(16);
+  if (hex.length % 2 === 1) {
+    hex = '0' + hex;
+  }
+  const b = new Uint8Array(hex.length / 2);
+  for (let i = 0; i < b.length; i++) {
+    b[i] = parseInt(hex.substr(2 * i, 2), 16);
+  }
+  return b;
+}
+
+// decodeBigInt decodes unsigned big-endian bytes into an integer.
+export function decodeBigInt(b: Uint8Array): bigint {
+  let n = BigInt(0);
+  b.forEach((x) => {
+    n = (n << BigInt(8)) | BigInt(x);
+  });
+  return n;
+}
+
+// Version of the encoding of attribute values of CL credentials (cl.AttrEncoder).
+export const ATTR_ENCODING_VERSION = {{.AttrEncodingVersion}};
+
+const ATTR_HASH_DOMAIN = '{{.AttrHashDomain}}';
+
+// encodeAttrString encodes a string attribute of bit length bitLen as cl.AttrEncoder
+// does: by its UTF-8 bytes if they fit, otherwise by their hash.
+export async function encodeAttrString(s: string, bitLen = 256): Promise<bigint> {
+  const b = new TextEncoder().encode(s);
+  const v = decodeBigInt(b);
+  if (b.length === 0 || (b[0] !== 0 && v < (BigInt(1) << BigInt(bitLen - 1)))) {
+    return v;
+  }
+  const domain = new TextEncoder().encode(ATTR_HASH_DOMAIN);
+  const n = Math.ceil(bitLen / 8);
+  let digest = new Uint8Array(0);
+  for (let counter = 0; digest.length < n; counter++) {
+    const input = new Uint8Array(domain.length + 4 + b.length);
+    input.set(domain);
+    new DataView(input.buffer).setUint32(domain.length, counter);
+    input.set(b, domain.length + 4);
+    const h = new Uint8Array(await crypto.subtle.digest('SHA-256', input));
+    const next = new Uint8Array(digest.length + h.length);
+    next.set(digest);
+    next.set(h, digest.length);
+    digest = next;
+  }
+  const top = BigInt(1) << BigInt(bitLen - 1);
+  return (decodeBigInt(digest.subarray(0, n)) % top) + top;
+}
+
+// encodeAttrDate encodes a date attribute (its UTC calendar date) as the number of
+// days since 1 January of year 1.
+export function encodeAttrDate(d: Date): bigint {
+  const days = Date.UTC(d.getUTCFullYear(), d.getUTCMonth(), d.getUTCDate()) / 86400000;
+  return BigInt(days + 719162);
+}
+
+// encodeAttrEnum encodes an enum attribute as the position of v in values.
+export function encodeAttrEnum(values: string[], v: string): bigint {
+  const i = values.indexOf(v);
+  if (i < 0) {
+    throw new Error(v + ' is not one of the values');
+  }
+  return BigInt(i);
+}
+
+export function encodeAttrBool(b: boolean): bigint {
+  return BigInt(b ? 1 : 0);
+}
+
+// newClientId returns a random identifier of the client in a protocol stream.
+export function newClientId(): number {
+  return Math.floor(Math.random() * 0x7fffffff);
+}
+
+// newMessage returns a Message of the client, whose content is to be set by the caller.
+export function newMessage(clientId: number): Message {
+  const msg = new Message();
+  msg.setClientid(clientId);
+  return msg;
+}
+
+// StreamSession keeps the identifier of a protocol stream run over gRPC-Web, which
+// the server returns in response headers of the first request of the stream.
+export class StreamSession {
+  private id = '';
+
+  // headers returns headers to be sent with the next request of the stream.
+  headers(): {[key: string]: string} {
+    return this.id ? {[STREAM_ID_HEADER]: this.id} : {};
+  }
+
+  // update records the stream identifier from response headers.
+  update(responseHeaders: {[key: string]: string}): void {
+    const id = responseHeaders[STREAM_ID_HEADER] || responseHeaders[STREAM_ID_HEADER.toLowerCase()];
+    if (id) {
+      this.id = id;
+    }
+  }
+}
+
+// Client calls non-streaming APIs of the emmy server at hostname (e.g. https://localhost:8884).
+export class Client {
+  readonly info: InfoClient;
+  readonly cl: CLClient;
+
+  constructor(hostname: string) {
+    this.info = new InfoClient(hostname);
+    this.cl = new CLClient(hostname);
+  }
+
+  serviceInfo(): Promise<ServiceInfo> {
+    return this.info.getServiceInfo(new Empty(), null);
+  }
+
+  // credentialStructure returns version of credential schema name, the default schema
+  // when name is empty and its latest version when version is empty.
+  credentialStructure(name = '', version = ''): Promise<CredStructure> {
+    const req = new CredStructureRequest();
+    req.setName(name);
+    req.setVersion(version);
+    return this.cl.getCredentialStructure(req, null);
+  }
+
+  acceptableCredentials(): Promise<AcceptableCreds> {
+    return this.cl.getAcceptableCredentials(new Empty(), null);
+  }
+}
+`
@@ -0,0 +1,213 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package sdk generates client stubs of emmy's gRPC services for other languages,
+// together with thin helpers for talking to emmy servers, so that relying parties
+// not written in Go can at least call the non-streaming APIs (such as
+// Info.GetServiceInfo and CL.GetCredentialStructure).
+//
+// Stubs are generated with protoc (or grpc_tools.protoc for Python) and the
+// gRPC plugins of each language, which need to be installed separately. They are
+// written to a directory named by the API version, so that clients can depend on
+// a particular version of the API while the definitions in proto evolve.
+//
+// Helpers cover what generated code cannot know about emmy: big integers are
+// transmitted as unsigned big-endian bytes (as returned by big.Int.Bytes), and
+// messages of a protocol stream carry the random identifier of the client. Over
+// gRPC-Web, requests of the same stream are tied together with a stream
+// identifier header (see package grpcweb). Values of attributes of CL credentials
+// are encoded as cl.AttrEncoder does.
+package sdk
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"text/template"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/grpcweb"
+)
+
+// APIVersion is the version of emmy's gRPC API, as defined in package proto.
+const APIVersion = "v1"
+
+// Language is a target language of generated stubs.
+type Language string
+
+const (
+	Python     Language = "python"
+	TypeScript Language = "typescript"
+)
+
+// Languages lists all supported target languages.
+var Languages = []Language{Python, TypeScript}
+
+// ProtoFiles are the definitions stubs are generated from, relative to the
+// proto directory.
+var ProtoFiles = []string{"messages.proto", "services.proto"}
+
+// Generator generates stubs and helpers of a given API version.
+type Generator struct {
+	ProtoDir string // directory holding ProtoFiles
+	OutDir   string // root directory of generated code
+	Version  string // API version, naming the directory stubs are written to
+
+	// Protoc overrides the command invoking the protocol buffer compiler,
+	// by default protoc (or python3 -m grpc_tools.protoc for Python).
+	Protoc []string
+}
+
+// NewGenerator returns a Generator of the current API version, reading
+// definitions from protoDir and writing code to outDir.
+func NewGenerator(protoDir, outDir string) *Generator {
+	return &Generator{
+		ProtoDir: protoDir,
+		OutDir:   outDir,
+		Version:  APIVersion,
+	}
+}
+
+// Dir returns the directory code for lang is written to.
+func (g *Generator) Dir(lang Language) string {
+	if lang == Python {
+		// a Python package, importable as emmy.<version>
+		return filepath.Join(g.OutDir, string(lang), "emmy", g.Version)
+	}
+	return filepath.Join(g.OutDir, string(lang), g.Version)
+}
+
+// Command returns the protoc command line generating stubs for lang.
+func (g *Generator) Command(lang Language) ([]string, error) {
+	dir := g.Dir(lang)
+	var protoc, opts []string
+	switch lang {
+	case Python:
+		protoc = []string{"python3", "-m", "grpc_tools.protoc"}
+		opts = []string{
+			"--python_out=" + dir,
+			"--grpc_python_out=" + dir,
+		}
+	case TypeScript:
+		// emmy servers speak gRPC-Web directly, in both content type variants
+		protoc = []string{"protoc"}
+		opts = []string{
+			"--js_out=import_style=commonjs,binary:" + dir,
+			"--grpc-web_out=import_style=typescript,mode=grpcwebtext:" + dir,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported language %s", lang)
+	}
+	if g.Protoc != nil {
+		protoc = g.Protoc
+	}
+
+	cmd := append(append([]string{}, protoc...), "-I", g.ProtoDir)
+	cmd = append(cmd, opts...)
+	return append(cmd, ProtoFiles...), nil
+}
+
+// Generate runs protoc to generate stubs for lang and writes helpers next to them.
+func (g *Generator) Generate(lang Language) error {
+	cmdLine, err := g.Command(lang)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(g.Dir(lang), 0755); err != nil {
+		return err
+	}
+
+	cmd := exec.Command(cmdLine[0], cmdLine[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("error when running %s: %v", cmdLine[0], err)
+	}
+
+	if lang == Python {
+		if err := g.fixPythonImports(); err != nil {
+			return err
+		}
+	}
+	return g.WriteHelpers(lang)
+}
+
+// WriteHelpers writes helpers for lang (and package files where the language
+// needs them) to the directory of stubs.
+func (g *Generator) WriteHelpers(lang Language) error {
+	files, ok := helpers[lang]
+	if !ok {
+		return fmt.Errorf("unsupported language %s", lang)
+	}
+	if err := os.MkdirAll(g.Dir(lang), 0755); err != nil {
+		return err
+	}
+
+	data := struct {
+		Version             string
+		StreamIDHeader      string
+		AttrEncodingVersion int
+		AttrHashDomain      string
+	}{g.Version, grpcweb.StreamIDHeader, cl.AttrEncodingVersion, cl.AttrHashDomain}
+	for name, src := range files {
+		t, err := template.New(name).Parse(src)
+		if err != nil {
+			return err
+		}
+		f, err := os.Create(filepath.Join(g.Dir(lang), filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+		err = t.Execute(f, data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pythonImport matches absolute imports of generated modules, which grpc_tools
+// emits as if the modules were on the top level.
+var pythonImport = regexp.MustCompile(`(?m)^import (\w+_pb2) as (\w+)$`)
+
+// fixPythonImports makes imports between generated Python modules relative,
+// so that they work from within the versioned package.
+func (g *Generator) fixPythonImports() error {
+	files, err := filepath.Glob(filepath.Join(g.Dir(Python), "*_pb2*.py"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		fixed := pythonImport.ReplaceAll(src, []byte("from . import $1 as $2"))
+		if err := ioutil.WriteFile(file, fixed, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
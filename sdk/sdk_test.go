@@ -0,0 +1,90 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package sdk
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+func TestCommand(t *testing.T) {
+	g := NewGenerator("proto", "out")
+
+	cmd, err := g.Command(Python)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"python3", "-m", "grpc_tools.protoc", "-I", "proto",
+		"--python_out=" + filepath.Join("out", "python", "emmy", APIVersion),
+		"--grpc_python_out=" + filepath.Join("out", "python", "emmy", APIVersion),
+		"messages.proto", "services.proto"}, cmd)
+
+	g.Protoc = []string{"/usr/local/bin/protoc"}
+	g.Version = "v2"
+	cmd, err = g.Command(TypeScript)
+	assert.NoError(t, err)
+	assert.Equal(t, "/usr/local/bin/protoc", cmd[0])
+	assert.Contains(t, cmd, "--grpc-web_out=import_style=typescript,mode=grpcwebtext:"+
+		filepath.Join("out", "typescript", "v2"))
+
+	_, err = g.Command(Language("cobol"))
+	assert.Error(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "emmy-sdk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// a stand-in for protoc, leaving stubs as grpc_tools would
+	g := NewGenerator("proto", dir)
+	g.Protoc = []string{"true"}
+	stubs := g.Dir(Python)
+	assert.NoError(t, os.MkdirAll(stubs, 0755))
+	stub := filepath.Join(stubs, "services_pb2_grpc.py")
+	assert.NoError(t, ioutil.WriteFile(stub, []byte("import grpc\n\n"+
+		"import messages_pb2 as messages__pb2\n"+
+		"from google.protobuf import empty_pb2 as google_dot_protobuf_dot_empty__pb2\n"), 0644))
+
+	assert.NoError(t, g.Generate(Python))
+	fixed, _ := ioutil.ReadFile(stub)
+	assert.Equal(t, "import grpc\n\n"+
+		"from . import messages_pb2 as messages__pb2\n"+
+		"from google.protobuf import empty_pb2 as google_dot_protobuf_dot_empty__pb2\n",
+		string(fixed))
+
+	helpers, err := ioutil.ReadFile(filepath.Join(stubs, "helpers.py"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(helpers), `API_VERSION = "`+APIVersion+`"`)
+	assert.Contains(t, string(helpers), `STREAM_ID_HEADER = "X-Emmy-Stream-Id"`)
+	assert.Contains(t, string(helpers), `_ATTR_HASH_DOMAIN = b"`+cl.AttrHashDomain+`"`)
+	_, err = os.Stat(filepath.Join(dir, "python", "emmy", "__init__.py"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, g.WriteHelpers(TypeScript))
+	_, err = os.Stat(filepath.Join(dir, "typescript", APIVersion, "helpers.ts"))
+	assert.NoError(t, err)
+
+	g.Protoc = []string{"false"}
+	assert.Error(t, g.Generate(TypeScript))
+}
@@ -0,0 +1,328 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/xlab-si/emmy/crypto/common"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Types of audit events.
+const (
+	AuditIssuance     = "issuance"
+	AuditVerification = "verification"
+)
+
+// Outcomes of audited protocol executions.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditEvent records an issuance or verification of a credential. It identifies the
+// client only by a pseudonym, and never holds values of attributes.
+type AuditEvent struct {
+	Time     time.Time `json:"time"`
+	Type     string    `json:"type"`     // AuditIssuance or AuditVerification
+	Protocol string    `json:"protocol"` // e.g. IssueCredential or TransferCredential_EC
+	Tenant   string    `json:"tenant,omitempty"`
+	// Schema is the name and version of the CL credential schema, empty for
+	// credentials of the pseudonym system.
+	Schema  string `json:"schema,omitempty"`
+	Outcome string `json:"outcome"` // AuditSuccess or AuditFailure
+	// Reason is the emmy error code (or gRPC code) of a failure.
+	Reason string `json:"reason,omitempty"`
+	// Client is a keyed hash of the client's certificate or network address,
+	// empty when neither is known.
+	Client string `json:"client,omitempty"`
+}
+
+// AuditLog is an append-only log of audit events. Implementations appending to a
+// file (FileAuditLog), a table of an SQL database (SQLAuditLog) and a Kafka topic
+// (KafkaAuditLog) are provided.
+type AuditLog interface {
+	Append(e *AuditEvent) error
+}
+
+// FileAuditLog appends audit events to a file as lines of JSON.
+type FileAuditLog struct {
+	sync.Mutex
+	f *os.File
+}
+
+// NewFileAuditLog returns a FileAuditLog appending to the file at path, which is
+// created if it does not exist.
+func NewFileAuditLog(path string) (*FileAuditLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open audit log: %v", err)
+	}
+	return &FileAuditLog{
+		f: f,
+	}, nil
+}
+
+// Append writes e to the file and syncs it to storage.
+func (l *FileAuditLog) Append(e *AuditEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	l.Lock()
+	defer l.Unlock()
+	if _, err := l.f.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return l.f.Sync()
+}
+
+// Close closes the file of l.
+func (l *FileAuditLog) Close() error {
+	return l.f.Close()
+}
+
+// SQLAuditTable is the name of the table SQLAuditLog appends events to.
+const SQLAuditTable = "emmy_audit"
+
+// SQLAuditLog appends audit events to a table of an SQL database (for example
+// PostgreSQL), accessed through database/sql. As with SQLRegistrationManager,
+// programs need to import the driver of their database. Rows are only ever
+// inserted, so the user of the database can be restricted to INSERT.
+type SQLAuditLog struct {
+	db       *sql.DB
+	postgres bool // whether query parameters are $1, $2, ... rather than ?
+}
+
+// NewSQLAuditLog returns a SQLAuditLog appending events to db, which was opened with
+// the driver with name driverName. The table for events is created if it does not
+// exist yet.
+func NewSQLAuditLog(db *sql.DB, driverName string) (*SQLAuditLog, error) {
+	l := &SQLAuditLog{
+		db: db,
+	}
+	switch driverName {
+	case "postgres", "pgx", "cloudsqlpostgres":
+		l.postgres = true
+	}
+
+	_, err := db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s ("+
+		"time BIGINT NOT NULL, type VARCHAR(32) NOT NULL, protocol VARCHAR(64) NOT NULL, "+
+		"tenant VARCHAR(256) NOT NULL, schema_name VARCHAR(512) NOT NULL, "+
+		"outcome VARCHAR(32) NOT NULL, reason VARCHAR(64) NOT NULL, client VARCHAR(64) NOT NULL)",
+		SQLAuditTable))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create table for audit events: %v", err)
+	}
+
+	return l, nil
+}
+
+// Close closes the database of l.
+func (l *SQLAuditLog) Close() error {
+	return l.db.Close()
+}
+
+// param returns the placeholder of the i-th (starting with 1) query parameter.
+func (l *SQLAuditLog) param(i int) string {
+	if l.postgres {
+		return fmt.Sprintf("$%d", i)
+	}
+	return "?"
+}
+
+// Append inserts e into the table of events, with its time in nanoseconds since
+// the Unix epoch.
+func (l *SQLAuditLog) Append(e *AuditEvent) error {
+	_, err := l.db.Exec(fmt.Sprintf("INSERT INTO %s "+
+		"(time, type, protocol, tenant, schema_name, outcome, reason, client) "+
+		"VALUES (%s, %s, %s, %s, %s, %s, %s, %s)", SQLAuditTable, l.param(1), l.param(2),
+		l.param(3), l.param(4), l.param(5), l.param(6), l.param(7), l.param(8)),
+		e.Time.UnixNano(), e.Type, e.Protocol, e.Tenant, e.Schema, e.Outcome, e.Reason, e.Client)
+	return err
+}
+
+// KafkaProducer publishes messages to topics of Kafka. emmy does not depend on a Kafka
+// client, programs using KafkaAuditLog provide a producer of the client they use.
+type KafkaProducer interface {
+	Produce(topic string, key, value []byte) error
+}
+
+// KafkaAuditLog publishes audit events as JSON to a Kafka topic, keyed by the
+// pseudonym of the client so that events of a client stay in order.
+type KafkaAuditLog struct {
+	producer KafkaProducer
+	topic    string
+}
+
+// NewKafkaAuditLog returns a KafkaAuditLog publishing events to topic with producer.
+func NewKafkaAuditLog(producer KafkaProducer, topic string) *KafkaAuditLog {
+	return &KafkaAuditLog{
+		producer: producer,
+		topic:    topic,
+	}
+}
+
+// Append publishes e to the topic of l.
+func (l *KafkaAuditLog) Append(e *AuditEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return l.producer.Produce(l.topic, []byte(e.Client), data)
+}
+
+// auditedProtocol describes a protocol whose executions are audited.
+type auditedProtocol struct {
+	eventType string
+	schema    bool // whether credentials of the protocol have a CL credential schema
+}
+
+// auditedProtocols are protocols that issue or verify credentials, by their names.
+var auditedProtocols = map[string]auditedProtocol{
+	"IssueCredential":        {AuditIssuance, true},
+	"IssueCredentialBatch":   {AuditIssuance, true},
+	"UpdateCredential":       {AuditIssuance, true},
+	"IssueBBSCredential":     {AuditIssuance, true},
+	"GenerateCertificate":    {AuditIssuance, false},
+	"GenerateCertificate_EC": {AuditIssuance, false},
+	"ObtainCredential":       {AuditIssuance, false},
+	"ObtainCredential_EC":    {AuditIssuance, false},
+	"ProveCredential":        {AuditVerification, true},
+	"ProveCredentialNI":      {AuditVerification, true},
+	"ProveBBSCredential":     {AuditVerification, true},
+	"TransferCredential":     {AuditVerification, false},
+	"TransferCredential_EC":  {AuditVerification, false},
+}
+
+// auditor appends events of audited protocols to an audit log.
+type auditor struct {
+	log AuditLog
+	key []byte // key of pseudonyms of clients
+}
+
+// UseAuditLog makes the server append events of issuance and verification of
+// credentials to l. Clients are identified in events by a hash of their certificate
+// or address keyed with key, so that events of a client can be linked only while the
+// key stays the same. When key is empty, a random one is used.
+func (s *Server) UseAuditLog(l AuditLog, key []byte) error {
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := io.ReadFull(common.RandReader(), key); err != nil {
+			return err
+		}
+	}
+	s.audit = &auditor{
+		log: l,
+		key: key,
+	}
+	s.Logger.Noticef("Appending audit events to %T", l)
+	return nil
+}
+
+// auditEvent appends an event of the execution of protocol with context ctx, which
+// ended with err, to the audit log if the server has one and the protocol is audited.
+func (s *Server) auditEvent(ctx context.Context, protocol string, err error) {
+	p, ok := auditedProtocols[protocol]
+	if s.audit == nil || !ok {
+		return
+	}
+
+	e := &AuditEvent{
+		Time:     time.Now().UTC(),
+		Type:     p.eventType,
+		Protocol: protocol,
+		Outcome:  AuditSuccess,
+		Client:   s.audit.clientPseudonym(ctx),
+	}
+	t, tErr := s.tenant(ctx)
+	if t != nil {
+		e.Tenant = t.ID
+	}
+	if p.schema && tErr == nil {
+		name, version := t.config().LoadCredentialSchema()
+		e.Schema = name + " " + version
+	}
+	if err != nil {
+		e.Outcome = AuditFailure
+		if pe := pb.ToProtocolError(err); pe != nil {
+			e.Reason = pe.Code.String()
+		} else {
+			e.Reason = status.Code(err).String()
+		}
+	}
+
+	if err := s.audit.log.Append(e); err != nil {
+		s.Logger.Errorf("cannot append audit event of %s: %v", protocol, err)
+	}
+}
+
+// clientPseudonym returns the pseudonym of the client of a request with context ctx,
+// derived from its certificate or, without one, from its network address.
+func (a *auditor) clientPseudonym(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	mac := hmac.New(sha256.New, a.key)
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok &&
+		len(info.State.PeerCertificates) > 0 {
+		mac.Write([]byte("cert:"))
+		mac.Write(info.State.PeerCertificates[0].Raw)
+	} else if p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		mac.Write([]byte("addr:" + host))
+	} else {
+		return ""
+	}
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// streamAuditInterceptor returns a stream interceptor that appends events of audited
+// protocols to the audit log of the server. Runs kept in the session store of a
+// cluster are audited when they end or fail.
+func streamAuditInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err != nil || endsRun(ss.Context()) {
+			srv.(*Server).auditEvent(ss.Context(), path.Base(info.FullMethod), err)
+		}
+		return err
+	}
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/bbs"
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/record"
+	"github.com/xlab-si/emmy/tracing"
+	"google.golang.org/grpc/codes"
+)
+
+// IssueBBSCredential issues a BBS+ credential, an alternative to CL credentials
+// (see IssueCredential), on the attributes of the configured credential structure.
+// Known attributes are the first messages of the signed block, while committed ones
+// are signed hidden from the server.
+func (s *Server) IssueBBSCredential(stream pb.BBS_IssueBBSCredentialServer) error {
+	req, err := s.receive(stream)
+	if err != nil {
+		return err
+	}
+
+	initReq := req.GetRegKey()
+	regKeyOk, err := s.RegistrationManager.CheckRegistrationKey(initReq.GetRegKey())
+	if !regKeyOk || err != nil {
+		s.Logger.Debugf("registration key %s ok=%t, error=%v",
+			initReq.GetRegKey(), regKeyOk, err)
+		return pb.NewStatusError(codes.NotFound, pb.ErrorCode_INVALID_REG_KEY,
+			"registration key verification failed")
+	}
+
+	org, err := s.loadBBSOrg()
+	if err != nil {
+		return err
+	}
+
+	nonce := org.GetCredIssueNonce()
+	record.Snapshot(stream.Context(), "nonce", nonce)
+	if err := s.issueNonce(nonce); err != nil {
+		return err
+	}
+	resp := &pb.Message{
+		Content: &pb.Message_Bigint{
+			Bigint: &pb.BigInt{
+				X1: nonce.Bytes(),
+			},
+		},
+	}
+
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	if err := s.useNonce(nonce); err != nil {
+		return err
+	}
+
+	credReq, err := req.GetBBSCredRequest().GetNativeType()
+	if err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+	if err := s.checkRequestedValidity(config.Default(), credReq.KnownMsgs); err != nil {
+		return err
+	}
+
+	_, span := tracing.StartSpan(stream.Context(), "bbs.Org.IssueCred")
+	sig, err := org.IssueCred(credReq)
+	tracing.End(span, err)
+	if err != nil {
+		return pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_PROOF,
+			fmt.Sprintf("error when issuing credential: %v", err))
+	}
+
+	resp = &pb.Message{
+		Content: &pb.Message_BBSSignature{BBSSignature: pb.ToPbBBSSignature(sig)},
+	}
+
+	return s.send(resp, stream)
+}
+
+// ProveBBSCredential verifies the proof of possession of a BBS+ credential and
+// starts a session with the attributes it reveals.
+func (s *Server) ProveBBSCredential(stream pb.BBS_ProveBBSCredentialServer) error {
+	if _, err := s.receive(stream); err != nil {
+		return err
+	}
+
+	org, err := s.loadBBSOrg()
+	if err != nil {
+		return err
+	}
+
+	nonce := org.GetProveCredNonce()
+	record.Snapshot(stream.Context(), "nonce", nonce)
+	if err := s.issueNonce(nonce); err != nil {
+		return err
+	}
+	resp := &pb.Message{
+		Content: &pb.Message_Bigint{
+			Bigint: &pb.BigInt{
+				X1: nonce.Bytes(),
+			},
+		},
+	}
+
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err := s.receive(stream)
+	if err != nil {
+		return err
+	}
+	if err := s.useNonce(nonce); err != nil {
+		return err
+	}
+
+	proof, err := req.GetBBSProof().GetNativeType()
+	if err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+
+	_, span := tracing.StartSpan(stream.Context(), "bbs.Org.ProveCred")
+	verified, err := org.ProveCred(proof)
+	tracing.End(span, err)
+	if err != nil {
+		s.Logger.Debug(err)
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"error when proving credential")
+	}
+	record.Snapshot(stream.Context(), "verified", verified)
+
+	if !verified {
+		s.Logger.Debug("User authentication failed")
+		return pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_PROOF,
+			"user authentication failed")
+	}
+
+	revealedKnownAttrsIndices, revealedKnownAttrs, err := revealedKnownMsgs(proof)
+	if err != nil {
+		return err
+	}
+	if err := s.checkCredExpiration(config.Default(), revealedKnownAttrsIndices, revealedKnownAttrs); err != nil {
+		s.Logger.Debugf("credential not valid: %v", err)
+		return err
+	}
+
+	var claims map[string]interface{}
+	if s.keepsClaims() {
+		claims, err = revealedAttrsClaims(revealedKnownAttrsIndices, revealedKnownAttrs, nil)
+		if err != nil {
+			s.Logger.Debug(err)
+			return pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+				"failed to obtain revealed attributes")
+		}
+	}
+
+	sessionKey, err := s.startSession(nil, claims)
+	if err != nil {
+		s.Logger.Debug(err)
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"failed to obtain session key")
+	}
+
+	resp = &pb.Message{
+		Content: &pb.Message_SessionKey{
+			SessionKey: sessionKey,
+		},
+	}
+
+	return s.send(resp, stream)
+}
+
+// loadBBSOrg loads the BBS+ organization from the configured key files, for
+// credentials with the configured structure. Keys are generated on first start if
+// they do not exist yet.
+func (s *Server) loadBBSOrg() (*bbs.Org, error) {
+	structure, err := config.LoadCredentialStructure()
+	if err != nil {
+		return nil, err
+	}
+
+	_, attrCount, err := cl.ParseAttrs(structure)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKeyPath, secKeyPath := config.LoadBBSKeyPaths()
+	return bbs.LoadOrCreateOrg(pubKeyPath, secKeyPath,
+		attrCount.Known+attrCount.Committed+attrCount.Hidden)
+}
+
+// revealedKnownMsgs returns indices and values of known attributes of the configured
+// credential structure among messages revealed by proof. As with CL credentials, only
+// known attributes are checked and turned into claims, thus revealed messages of
+// committed attributes are ignored.
+func revealedKnownMsgs(proof *bbs.Proof) ([]int, []*big.Int, error) {
+	structure, err := config.LoadCredentialStructure()
+	if err != nil {
+		return nil, nil, err
+	}
+	_, attrCount, err := cl.ParseAttrs(structure)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var indices []int
+	var msgs []*big.Int
+	for i, ind := range proof.Revealed {
+		if ind < attrCount.Known {
+			indices = append(indices, ind)
+			msgs = append(msgs, proof.RevealedMsgs[i])
+		}
+	}
+	return indices, msgs, nil
+}
@@ -19,63 +19,57 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/golang/protobuf/ptypes/empty"
-	"github.com/xlab-si/emmy/config"
 	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/common"
 	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/record"
+	"github.com/xlab-si/emmy/tracing"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
-func (s *Server) GetCredentialStructure(ctx context.Context, _ *empty.Empty) (*pb.CredStructure, error) {
-	s.Logger.Info("Client requested credential structure information")
+func (s *Server) GetCredentialStructure(ctx context.Context,
+	req *pb.CredStructureRequest) (*pb.CredStructure, error) {
+	s.Logger.Infof("Client requested credential structure information (schema %s %s)",
+		req.Name, req.Version)
 
-	structure, err := config.LoadCredentialStructure()
+	t, err := s.tenant(ctx)
 	if err != nil {
 		return nil, err
 	}
-
-	attrs, attrCount, err := cl.ParseAttrs(structure)
-	credAttrs := make([]*pb.CredAttribute, len(attrs))
-
-	for i, a := range attrs {
-		attr := &pb.Attribute{
-			Name:  a.GetName(),
-			Known: a.IsKnown(),
-		}
-		switch a.(type) {
-		case *cl.StrAttr:
-			credAttrs[i] = &pb.CredAttribute{
-				Type: &pb.CredAttribute_StringAttr{
-					StringAttr: &pb.StringAttribute{
-						Attr: attr,
-					},
-				},
-			}
-		case *cl.Int64Attr:
-			credAttrs[i] = &pb.CredAttribute{
-				Type: &pb.CredAttribute_IntAttr{
-					IntAttr: &pb.IntAttribute{
-						Attr: attr,
-					},
-				},
-			}
-		}
+	schema, err := s.credSchema(t, req.Name, req.Version)
+	if err != nil {
+		return nil, err
 	}
 
-	return &pb.CredStructure{
-		NKnown:     int32(attrCount.Known),
-		NCommitted: int32(attrCount.Committed),
-		NHidden:    int32(attrCount.Hidden),
-		Attributes: credAttrs,
-	}, nil
+	return credStructure(schema), nil
 }
 
 func (s *Server) GetAcceptableCredentials(ctx context.Context, _ *empty.Empty) (*pb.AcceptableCreds, error) {
 	s.Logger.Info("Client requested acceptable credentials information")
-	accCreds, err := config.LoadAcceptableCredentials()
+	t, err := s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := loadPolicies(t.config())
+	if err != nil {
+		return nil, err
+	}
+	if len(policies) > 0 {
+		credentials := make([]*pb.AcceptableCred, len(policies))
+		for i, p := range policies {
+			credentials[i] = pb.ToPbAcceptableCred(p)
+		}
+		return &pb.AcceptableCreds{
+			Creds: credentials,
+		}, nil
+	}
+
+	accCreds, err := t.config().LoadAcceptableCredentials()
 	if err != nil {
 		return nil, err
 	}
@@ -100,6 +94,40 @@ func (s *Server) IssueCredential(stream pb.CL_IssueCredentialServer) error {
 		return err
 	}
 
+	t, err := s.tenant(stream.Context())
+	if err != nil {
+		return err
+	}
+	org, err := s.loadCLOrg(t)
+	if err != nil {
+		return err
+	}
+
+	nonce, resp, err := s.startIssueCred(stream.Context(), t, org, req)
+	if err != nil {
+		return err
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	resp, err = s.finishIssueCred(stream.Context(), t, org, nonce, req)
+	if err != nil {
+		return err
+	}
+
+	return s.send(resp, stream)
+}
+
+// startIssueCred checks the registration key in the first message of the client, req,
+// and returns the nonce that org issues for the credential request along with the
+// response holding it.
+func (s *Server) startIssueCred(ctx context.Context, t *Tenant, org *cl.Org,
+	req *pb.Message) (*big.Int, *pb.Message, error) {
 	// TOD0: for known attributes IssueCredential should fill the values - attributes
 	// are stored (under registration key) in the DB and then obtained by Org.
 
@@ -108,15 +136,15 @@ func (s *Server) IssueCredential(stream pb.CL_IssueCredentialServer) error {
 	if !regKeyOk || err != nil {
 		s.Logger.Debugf("registration key %s ok=%t, error=%v",
 			initReq.RegKey, regKeyOk, err)
-		return status.Error(codes.NotFound, "registration key verification failed")
-	}
-
-	org, err := cl.LoadOrg("../client/testdata/clPubKey.gob", "../client/testdata/clSecKey.gob")
-	if err != nil {
-		return err
+		return nil, nil, pb.NewStatusError(codes.NotFound, pb.ErrorCode_INVALID_REG_KEY,
+			"registration key verification failed")
 	}
 
 	nonce := org.GetCredIssueNonce()
+	record.Snapshot(ctx, "nonce", nonce)
+	if err := s.issueNonce(nonce); err != nil {
+		return nil, nil, err
+	}
 	resp := &pb.Message{
 		Content: &pb.Message_Bigint{
 			&pb.BigInt{
@@ -125,41 +153,57 @@ func (s *Server) IssueCredential(stream pb.CL_IssueCredentialServer) error {
 		},
 	}
 
-	if err := s.send(resp, stream); err != nil {
-		return err
-	}
+	return nonce, resp, nil
+}
 
-	req, err = s.receive(stream)
-	if err != nil {
-		return err
+// finishIssueCred issues a credential of tenant t for the credential request in req,
+// built with nonce, and returns the response holding it.
+func (s *Server) finishIssueCred(ctx context.Context, t *Tenant, org *cl.Org,
+	nonce *big.Int, req *pb.Message) (*pb.Message, error) {
+	if err := s.useNonce(nonce); err != nil {
+		return nil, err
 	}
+	org.SetCredIssueNonce(nonce)
 
 	cReq := req.GetCLCredReq()
 	credReq, err := cReq.GetNativeType()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := s.checkRequestedValidity(t.config(), credReq.KnownAttrs); err != nil {
+		return nil, err
+	}
+
+	if s.deviceBinding != nil {
+		if err := s.deviceBinding.register(t.config(), cReq.DeviceRegistration, nonce,
+			credReq); err != nil {
+			s.Logger.Debugf("device registration failed: %v", err)
+			return nil, pb.NewStatusError(codes.PermissionDenied, pb.ErrorCode_DEVICE_AUTH_FAILED,
+				"device registration failed")
+		}
 	}
 
 	// Issue the credential
+	record.Snapshot(ctx, "nym", credReq.Nym)
+	_, span := tracing.StartSpan(ctx, "cl.Org.IssueCred")
 	res, err := org.IssueCred(credReq)
+	tracing.End(span, err)
+	if errors.Is(err, common.ErrNotInGroup) {
+		return nil, pb.NewInvalidValueError(err)
+	}
 	if err != nil {
-		return fmt.Errorf("error when issuing credential: %v", err)
+		return nil, pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_PROOF,
+			fmt.Sprintf("error when issuing credential: %v", err))
 	}
 	// Store the newly obtained receiver record to the database
-	if err = s.clRecordManager.Store(credReq.Nym, res.Record); err != nil {
-		return err
+	pbCred, err := s.storeIssuedCred(t, credReq.Nym, res)
+	if err != nil {
+		return nil, err
 	}
 
-	pbCred := pb.ToPbCLCredential(res.Cred, res.AProof)
-	resp = &pb.Message{
+	return &pb.Message{
 		Content: &pb.Message_CLCredential{pbCred},
-	}
-
-	if err := s.send(resp, stream); err != nil {
-		return err
-	}
-
-	return nil
+	}, nil
 }
 
 func (s *Server) UpdateCredential(stream pb.CL_UpdateCredentialServer) error {
@@ -168,30 +212,66 @@ func (s *Server) UpdateCredential(stream pb.CL_UpdateCredentialServer) error {
 		return err
 	}
 
-	org, err := cl.LoadOrg("../client/testdata/clPubKey.gob", "../client/testdata/clSecKey.gob")
+	t, err := s.tenant(stream.Context())
+	if err != nil {
+		return err
+	}
+	org, err := s.loadCLOrg(t)
 	if err != nil {
 		return err
 	}
 
-	u := req.GetUpdateClCredential()
-	nym, nonce, newKnownAttrs := u.GetNativeType()
+	u, err := req.GetUpdateClCredential().GetCredUpdate()
+	if err != nil {
+		return err
+	}
+	nym := u.Nym
+	if err := s.checkRequestedValidity(t.config(), u.KnownAttrs); err != nil {
+		return err
+	}
 
 	// Retrieve the receiver record from the database
 	rec, err := s.clRecordManager.Load(nym)
 	if err != nil {
 		return err
 	}
+	// credentials issued under previous keys cannot be updated, only issued again
+	if rec.Context != nil && rec.Context.Cmp(org.Keys.Pub.GetContext()) != 0 {
+		return pb.NewStatusError(codes.FailedPrecondition, pb.ErrorCode_UNKNOWN_KEY,
+			"credential was issued under a previous key")
+	}
+	if s.deviceBinding != nil {
+		if err := s.deviceBinding.checkUpdate(t.config(), rec, u.KnownAttrs); err != nil {
+			return pb.NewStatusError(codes.PermissionDenied, pb.ErrorCode_DEVICE_AUTH_FAILED,
+				err.Error())
+		}
+	}
 	// Do credential update
-	res, err := org.UpdateCred(nym, rec, nonce, newKnownAttrs)
+	record.Snapshot(stream.Context(), "nym", nym)
+	_, span := tracing.StartSpan(stream.Context(), "cl.Org.UpdateCred")
+	res, err := org.UpdateCredAttrs(rec, u)
+	tracing.End(span, err)
 	if err != nil {
 		return fmt.Errorf("error when updating credential: %v", err)
 	}
+	// The updated credential replaces the previous one, which is revoked
+	rev := s.revocationOf(t)
+	if rev != nil && rec.E != nil {
+		if err := rev.revoke(rec.E); err != nil {
+			return fmt.Errorf("error when revoking previous credential: %v", err)
+		}
+	}
 	// Store the updated receiver record to the database
 	if err = s.clRecordManager.Store(nym, res.Record); err != nil {
 		return err
 	}
 
 	pbCred := pb.ToPbCLCredential(res.Cred, res.AProof)
+	if rev != nil {
+		if pbCred.Witness, err = rev.witness(res.Cred.E); err != nil {
+			return err
+		}
+	}
 	resp := &pb.Message{
 		Content: &pb.Message_CLCredential{pbCred},
 	}
@@ -209,12 +289,44 @@ func (s *Server) ProveCredential(stream pb.CL_ProveCredentialServer) error {
 		return err
 	}
 
-	org, err := cl.LoadOrg("../client/testdata/clPubKey.gob", "../client/testdata/clSecKey.gob")
+	t, err := s.tenant(stream.Context())
+	if err != nil {
+		return err
+	}
+	org, err := s.loadCLOrg(t)
 	if err != nil {
 		return err
 	}
 
+	nonce, resp, err := s.startProveCred(stream.Context(), t, org, req)
+	if err != nil {
+		return err
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	req, err = s.receive(stream)
+	if err != nil {
+		return err
+	}
+	resp, err = s.finishProveCred(stream.Context(), t, org, nonce, req)
+	if err != nil {
+		return err
+	}
+
+	return s.send(resp, stream)
+}
+
+// startProveCred returns the nonce that org issues for a proof of a credential along
+// with the response holding it.
+func (s *Server) startProveCred(ctx context.Context, t *Tenant, org *cl.Org,
+	req *pb.Message) (*big.Int, *pb.Message, error) {
 	nonce := org.GetProveCredNonce()
+	record.Snapshot(ctx, "nonce", nonce)
+	if err := s.issueNonce(nonce); err != nil {
+		return nil, nil, err
+	}
 	resp := &pb.Message{
 		Content: &pb.Message_Bigint{
 			&pb.BigInt{
@@ -223,53 +335,214 @@ func (s *Server) ProveCredential(stream pb.CL_ProveCredentialServer) error {
 		},
 	}
 
-	if err := s.send(resp, stream); err != nil {
-		return err
+	return nonce, resp, nil
+}
+
+// finishProveCred verifies the proof of a credential in req, built with nonce, and
+// returns the response holding the session key of the client.
+func (s *Server) finishProveCred(ctx context.Context, t *Tenant, org *cl.Org,
+	nonce *big.Int, req *pb.Message) (*pb.Message, error) {
+	if err := s.useNonce(nonce); err != nil {
+		return nil, err
 	}
+	org.SetProveCredNonce(nonce)
 
-	req, err = s.receive(stream)
+	sessionKey, err := s.proveCred(ctx, "ProveCredential", t, org,
+		req.GetProveClCredential(), nonce)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	return &pb.Message{
+		Content: &pb.Message_SessionKey{
+			SessionKey: sessionKey,
+		},
+	}, nil
+}
+
+// ProveCredentialNI verifies a non-interactive proof of a CL credential, built with the
+// nonce derived from the context of the proof instead of one issued by the server,
+// and returns a session key like ProveCredential.
+func (s *Server) ProveCredentialNI(ctx context.Context,
+	req *pb.ProveCLCredentialNI) (_ *pb.SessionKey, err error) {
+	defer func() {
+		s.auditEvent(ctx, "ProveCredentialNI", err)
+	}()
+
+	if req.Proof == nil {
+		return nil, pb.NewStatusError(codes.InvalidArgument, pb.ErrorCode_INVALID_REQUEST,
+			"missing proof")
+	}
+	v, err := s.useNIContext(req.Context, pb.ProveCredentialNIMethod)
+	if err != nil {
+		return nil, err
 	}
 
-	pReq := req.GetProveClCredential()
+	t, err := s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	org, err := s.loadCLOrg(t)
+	if err != nil {
+		return nil, err
+	}
+	nonce := cl.ContextNonce(org.Params, v)
+	org.SetProveCredNonce(nonce)
+
+	return s.proveCred(ctx, "ProveCredentialNI", t, org, req.Proof, nonce)
+}
+
+// proveCred verifies proof pReq of a CL credential of tenant t, built with nonce, along
+// with the range, non-revocation and device proofs it holds, and starts a session with
+// claims about revealed attributes. Its transcript is exported as run by protocol.
+func (s *Server) proveCred(ctx context.Context, protocol string, t *Tenant, org *cl.Org,
+	pReq *pb.ProveCLCredential, nonce *big.Int) (_ *pb.SessionKey, err error) {
+	defer func() {
+		s.exportTranscript(protocol, t, org, pReq, nonce, err)
+	}()
+
 	A, proof, knownAttrs, commitmentsOfAttrs, revealedKnownAttrsIndices,
 		revealedCommitmentsOfAttrsIndices, err := pReq.GetNativeType()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	// credentials issued before keys were rotated are verified with previous keys
+	keyOrg, err := s.clOrgForKey(t, org, pReq.KeyID, nonce)
+	if err != nil {
+		return nil, err
 	}
+	org = keyOrg
 
+	_, span := tracing.StartSpan(ctx, "cl.Org.ProveCred")
 	verified, err := org.ProveCred(A, proof, revealedKnownAttrsIndices,
 		revealedCommitmentsOfAttrsIndices, knownAttrs, commitmentsOfAttrs)
+	tracing.End(span, err)
+	if errors.Is(err, common.ErrNotInGroup) {
+		record.Snapshot(ctx, "proveError", err.Error())
+		return nil, pb.NewInvalidValueError(err)
+	}
 	if err != nil {
 		s.Logger.Debug(err)
-		return status.Error(codes.Internal, "error when proving credential")
+		record.Snapshot(ctx, "proveError", err.Error())
+		return nil, pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"error when proving credential")
 	}
+	record.Snapshot(ctx, "verified", verified)
 
 	if !verified {
 		s.Logger.Debug("User authentication failed")
-		return status.Error(codes.Unauthenticated, "user authentication failed")
+		return nil, pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_PROOF,
+			"user authentication failed")
+	}
+
+	if err := s.checkCredExpiration(t.config(), revealedKnownAttrsIndices, knownAttrs); err != nil {
+		s.Logger.Debugf("credential not valid: %v", err)
+		return nil, err
+	}
+
+	rangeProofs := make([]*cl.AttrRangeProof, len(pReq.RangeProofs))
+	for i, p := range pReq.RangeProofs {
+		if rangeProofs[i], err = p.GetNativeType(); err != nil {
+			return nil, err
+		}
+	}
+	if len(rangeProofs) > 0 {
+		_, span := tracing.StartSpan(ctx, "cl.Org.VerifyAttrRangeProofs")
+		ok, err := org.VerifyAttrRangeProofs(rangeProofs, revealedCommitmentsOfAttrsIndices,
+			commitmentsOfAttrs, nonce)
+		tracing.End(span, err)
+		if err != nil || !ok {
+			s.Logger.Debugf("range proof failed: %v", err)
+			return nil, pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_PROOF,
+				"range proof failed")
+		}
+	}
+
+	if err := s.checkRequestedPredicates(t.config(), pReq.Predicates); err != nil {
+		return nil, err
+	}
+	if err := s.checkPolicies(t.config(), org.Keys.Pub.ID(), revealedKnownAttrsIndices,
+		knownAttrs, rangeProofs); err != nil {
+		return nil, err
+	}
+
+	if rev := s.revocationOf(t); rev != nil {
+		if err := rev.verify(pReq.NonRevocationProof, org, A, nonce,
+			revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices, knownAttrs,
+			commitmentsOfAttrs); err != nil {
+			s.Logger.Debugf("non-revocation proof failed: %v", err)
+			return nil, pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_REVOKED,
+				"credential revoked")
+		}
+	}
+
+	if s.deviceBinding != nil {
+		if err := s.deviceBinding.verify(t.config(), pReq.DeviceAssertion, nonce, A,
+			revealedKnownAttrsIndices, knownAttrs); err != nil {
+			s.Logger.Debugf("device assertion failed: %v", err)
+			return nil, pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_DEVICE_AUTH_FAILED,
+				"device assertion failed")
+		}
+	}
+
+	var claims map[string]interface{}
+	if s.keepsClaims() {
+		claims, err = revealedAttrsClaims(revealedKnownAttrsIndices, knownAttrs,
+			rangeProofs)
+		if err != nil {
+			s.Logger.Debug(err)
+			return nil, pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+				"failed to obtain revealed attributes")
+		}
 	}
 
-	sessionKey, err := s.GenerateSessionKey()
+	sessionKey, err := s.startSession(t, claims)
 	if err != nil {
 		s.Logger.Debug(err)
-		return status.Error(codes.Internal, "failed to obtain session key")
+		return nil, pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"failed to obtain session key")
 	}
 
-	// TODO: here session key needs to be stored to enable validation
+	return sessionKey, nil
+}
 
-	resp = &pb.Message{
-		Content: &pb.Message_SessionKey{
-			SessionKey: &pb.SessionKey{
-				Value: *sessionKey,
-			},
-		},
+// loadCLOrg loads the CL organization of tenant t from its configured key files. Keys
+// are generated on first start if they do not exist yet. Threshold issuance and the
+// key store of the server, if used, only hold keys of the default tenant.
+func (s *Server) loadCLOrg(t *Tenant) (*cl.Org, error) {
+	params, err := cl.LoadParams()
+	if err != nil {
+		return nil, err
 	}
 
-	if err = s.send(resp, stream); err != nil {
-		return err
+	structure, err := t.config().LoadCredentialStructure()
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	_, attrCount, err := cl.ParseAttrs(structure)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKeyPath, secKeyPath := t.config().LoadCLKeyPaths()
+	if t != nil {
+		return cl.LoadOrCreateOrg(params, pubKeyPath, secKeyPath, attrCount)
+	}
+	if s.thresholdCoordinator != nil {
+		pubKey, err := cl.ReadPubKey(pubKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		org, err := cl.NewOrgFromParams(params, &cl.KeyPair{Pub: pubKey})
+		if err != nil {
+			return nil, err
+		}
+		org.UseSigner(s.thresholdCoordinator)
+		return org, nil
+	}
+	if s.keyStore != nil {
+		return cl.LoadOrgFromKeyStore(params, pubKeyPath, s.keyStore, KeyLabelCLSecKey)
+	}
+	return cl.LoadOrCreateOrg(params, pubKeyPath, secKeyPath, attrCount)
 }
@@ -0,0 +1,201 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/tracing"
+	"google.golang.org/grpc/codes"
+)
+
+// defaultBatchConcurrency is the number of credential requests of a batch processed
+// at once, unless set otherwise with SetBatchIssuanceConcurrency.
+const defaultBatchConcurrency = 8
+
+// SetBatchIssuanceConcurrency sets the number of credential requests of a batch
+// that IssueCredentialBatch processes at once.
+func (s *Server) SetBatchIssuanceConcurrency(n int) {
+	s.batchConcurrency = n
+}
+
+// IssueCredentialBatch issues CL credentials for a stream of credential requests.
+// The server first sends a nonce that all the requests of the batch need to be built
+// with. Then it issues credentials for the requests, several at once, and sends a
+// result holding either the credential or the error for each request as it
+// completes. Results are matched to requests by ids, as they may arrive out of order.
+// The stream ends when the client closes it and all the results have been sent.
+func (s *Server) IssueCredentialBatch(stream pb.CL_IssueCredentialBatchServer) error {
+	if _, err := s.receive(stream); err != nil {
+		return err
+	}
+	t, err := s.tenant(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	org, err := s.loadCLOrg(t)
+	if err != nil {
+		return err
+	}
+	nonce := org.GetCredIssueNonce()
+	resp := &pb.Message{
+		Content: &pb.Message_Bigint{
+			Bigint: &pb.BigInt{
+				X1: nonce.Bytes(),
+			},
+		},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	concurrency := s.batchConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	// mu guards storage of records and sending of results, sendErr holds the first
+	// error that occurred when sending a result
+	var mu sync.Mutex
+	var sendErr error
+
+	finish := func(id int64, nym *big.Int, res *cl.CredResult, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		result := &pb.CLCredBatchResult{Id: id}
+		if err == nil {
+			result.Credential, err = s.storeIssuedCred(t, nym, res)
+		}
+		if err != nil {
+			s.Logger.Debugf("batch item %d failed: %v", id, err)
+			result.Error = err.Error()
+		}
+		if sendErr == nil {
+			sendErr = s.send(&pb.Message{
+				Content: &pb.Message_CLCredBatchResult{CLCredBatchResult: result},
+			}, stream)
+		}
+	}
+
+	for {
+		req, err := s.receive(stream)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			wg.Wait()
+			return err
+		}
+		item := req.GetCLCredBatchItem()
+		if item == nil {
+			wg.Wait()
+			return pb.NewStatusError(codes.InvalidArgument, pb.ErrorCode_INVALID_REQUEST,
+				"expected a credential batch item")
+		}
+
+		// requests are checked one by one, as registration keys are consumed
+		credReq, err := s.checkBatchItem(t, item, nonce)
+		if err != nil {
+			finish(item.Id, nil, nil, err)
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(id int64) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			// organizations keep state of the issuance, so each request needs its own
+			org, err := s.loadCLOrg(t)
+			if err != nil {
+				finish(id, nil, nil, err)
+				return
+			}
+			org.SetCredIssueNonce(nonce)
+			_, span := tracing.StartSpan(stream.Context(), "cl.Org.IssueCred")
+			res, err := org.IssueCred(credReq)
+			tracing.End(span, err)
+			if err != nil {
+				err = fmt.Errorf("error when issuing credential: %v", err)
+			}
+			finish(id, credReq.Nym, res, err)
+		}(item.Id)
+	}
+	wg.Wait()
+
+	return sendErr
+}
+
+// checkBatchItem checks the registration key of item, requesting a credential of
+// tenant t, and returns its credential request.
+func (s *Server) checkBatchItem(t *Tenant, item *pb.CLCredBatchItem, nonce *big.Int) (*cl.CredRequest,
+	error) {
+	if item.CredReq == nil {
+		return nil, fmt.Errorf("no credential request")
+	}
+	credReq, err := item.CredReq.GetNativeType()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkRequestedValidity(t.config(), credReq.KnownAttrs); err != nil {
+		return nil, err
+	}
+	regKeyOk, err := s.RegistrationManager.CheckRegistrationKey(item.RegKey)
+	if !regKeyOk || err != nil {
+		s.Logger.Debugf("registration key %s ok=%t, error=%v", item.RegKey, regKeyOk, err)
+		return nil, fmt.Errorf("registration key verification failed")
+	}
+
+	if s.deviceBinding != nil {
+		if err := s.deviceBinding.register(t.config(), item.CredReq.DeviceRegistration, nonce,
+			credReq); err != nil {
+			s.Logger.Debugf("device registration failed: %v", err)
+			return nil, fmt.Errorf("device registration failed")
+		}
+	}
+
+	return credReq, nil
+}
+
+// storeIssuedCred stores the receiver record of credential res of tenant t issued to
+// nym and returns the credential to be sent to the client.
+func (s *Server) storeIssuedCred(t *Tenant, nym *big.Int, res *cl.CredResult) (*pb.CLCredential,
+	error) {
+	if err := s.clRecordManager.Store(nym, res.Record); err != nil {
+		return nil, err
+	}
+
+	pbCred := pb.ToPbCLCredential(res.Cred, res.AProof)
+	if rev := s.revocationOf(t); rev != nil {
+		var err error
+		if pbCred.Witness, err = rev.witness(res.Cred.E); err != nil {
+			return nil, err
+		}
+	}
+
+	return pbCred, nil
+}
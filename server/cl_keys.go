@@ -0,0 +1,114 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+)
+
+// clOrgForKey returns the CL organization of tenant t that verifies proofs built with
+// nonce and the key with ID keyID. This is org, holding the current key, when keyID is
+// empty or the ID of its key, and otherwise an organization holding the previous key
+// keyID (see config.LoadCLPreviousKeys), as long as its grace period has not ended.
+func (s *Server) clOrgForKey(t *Tenant, org *cl.Org, keyID string,
+	nonce *big.Int) (*cl.Org, error) {
+	if keyID == "" || keyID == org.Keys.Pub.ID() {
+		return org, nil
+	}
+
+	prevKeys, err := t.config().LoadCLPreviousKeys()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range prevKeys {
+		if k.ID != keyID {
+			continue
+		}
+		if !k.Until.IsZero() && time.Now().After(k.Until) {
+			s.Logger.Debugf("grace period of CL key %s ended at %v", keyID, k.Until)
+			break
+		}
+		pubKey, err := cl.ReadPubKey(k.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		if pubKey.ID() != keyID {
+			return nil, fmt.Errorf("file of CL key %s holds key %s", keyID, pubKey.ID())
+		}
+		prev, err := cl.NewOrgFromParams(org.Params, &cl.KeyPair{Pub: pubKey})
+		if err != nil {
+			return nil, err
+		}
+		prev.SetProveCredNonce(nonce)
+		return prev, nil
+	}
+
+	return nil, pb.NewStatusError(codes.NotFound, pb.ErrorCode_UNKNOWN_KEY,
+		fmt.Sprintf("unknown key %s", keyID))
+}
+
+// clPubKeys returns the CL public keys of tenant t that proofs are verified with: its
+// current key, if it was already generated, and previous keys whose grace period has
+// not ended.
+func (s *Server) clPubKeys(t *Tenant) ([]*pb.CLPubKey, error) {
+	var keys []*pb.CLPubKey
+	add := func(path string, until time.Time) error {
+		pubKey, err := cl.ReadPubKey(path)
+		if err != nil {
+			return err
+		}
+		data, err := pubKey.MarshalPEM()
+		if err != nil {
+			return err
+		}
+		k := &pb.CLPubKey{
+			KeyID:  pubKey.ID(),
+			PubKey: data,
+		}
+		if !until.IsZero() {
+			k.Until = until.Unix()
+		}
+		keys = append(keys, k)
+		return nil
+	}
+
+	pubKeyPath, _ := t.config().LoadCLKeyPaths()
+	if err := add(pubKeyPath, time.Time{}); err != nil {
+		s.Logger.Debugf("CL public key not available: %v", err)
+	}
+	prevKeys, err := t.config().LoadCLPreviousKeys()
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range prevKeys {
+		if !k.Until.IsZero() && time.Now().After(k.Until) {
+			continue
+		}
+		if err := add(k.PubKey, k.Until); err != nil {
+			return nil, fmt.Errorf("previous CL key %s: %v", k.ID, err)
+		}
+	}
+
+	return keys, nil
+}
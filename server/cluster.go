@@ -0,0 +1,391 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/common"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// sharedRunPrefix separates states of protocol runs from sessions of clients in the
+// session store.
+const sharedRunPrefix = "run:"
+
+// sharedRun is the state of a protocol run that servers of a cluster keep in the shared
+// session store between the rounds of the protocol (see UseCluster).
+type sharedRun struct {
+	Method  string `json:"method"`
+	Tenant  string `json:"tenant,omitempty"`
+	Version int32  `json:"version"`
+	Nonce   []byte `json:"nonce"`
+	Done    bool   `json:"done,omitempty"` // whether the server sent its last message
+}
+
+// resumableProtocol is a CL protocol of two rounds whose state between them is only the
+// nonce the server issued in the first round, so that its rounds can be run by
+// different servers of a cluster. The same functions run its rounds over gRPC streams.
+type resumableProtocol struct {
+	start func(s *Server, ctx context.Context, t *Tenant, org *cl.Org,
+		req *pb.Message) (*big.Int, *pb.Message, error)
+	finish func(s *Server, ctx context.Context, t *Tenant, org *cl.Org, nonce *big.Int,
+		req *pb.Message) (*pb.Message, error)
+}
+
+// resumableProtocols maps full names of the streams of resumable protocols to them.
+var resumableProtocols = map[string]resumableProtocol{
+	"/proto.CL/IssueCredential": {(*Server).startIssueCred, (*Server).finishIssueCred},
+	"/proto.CL/ProveCredential": {(*Server).startProveCred, (*Server).finishProveCred},
+}
+
+// UseCluster makes the server one of a cluster of servers behind a load balancer, which
+// share the session store (see UseSessionStore), the nonce store (see UseNonceStore) and
+// the keys of the issuer. Runs of the CL protocols IssueCredential and ProveCredential
+// over the Steps service, gRPC-Web and the gateway are then kept in the session store
+// between their rounds, so that each request of a run can be handled by any server of
+// the cluster. Each request of such a run passes through the interceptors of the server
+// as a stream of its own, so limits, draining and interceptors given by
+// WithStreamInterceptors apply to it. Other protocols, and protocols run over gRPC
+// streams, are handled by the server they were started with.
+func (s *Server) UseCluster() error {
+	if s.sessionStore == nil {
+		return fmt.Errorf("servers of a cluster need a shared session store")
+	}
+	s.clustered = true
+	s.Logger.Notice("Keeping protocol runs in the session store of the cluster")
+	return nil
+}
+
+// exchangeShared is like GrpcWebHandler.exchange for runs of protocol p, kept in the
+// session store until they are idle for longer than ttl. Each round of a run passes
+// through the interceptors of the server's streams as a stream of its own (see
+// runRound).
+func (s *Server) exchangeShared(ctx context.Context, id, method string, p resumableProtocol,
+	msg *pb.Message, ttl time.Duration) (string, *pb.Message, error) {
+	if id == "" {
+		return s.startShared(ctx, method, p, msg, ttl)
+	}
+
+	run, err := s.loadRun(id)
+	if err != nil {
+		return "", nil, err
+	}
+	if run == nil || run.Method != method {
+		return "", nil, status.Error(codes.NotFound, "unknown stream")
+	}
+	if msg == nil {
+		if err := s.deleteRun(id); err != nil {
+			return id, nil, err
+		}
+		if !run.Done {
+			return id, nil, status.Error(codes.Canceled, "stream closed by the client")
+		}
+		return id, nil, nil
+	}
+	if run.Done {
+		return id, nil, pb.NewStatusError(codes.InvalidArgument, pb.ErrorCode_INVALID_REQUEST,
+			"unexpected message")
+	}
+
+	// the tenant and the version of messages were selected in the first round
+	tenant := metadataTenant(ctx)
+	if (tenant != "" && tenant != run.Tenant) || (msg.Tenant != "" && msg.Tenant != run.Tenant) {
+		s.deleteRun(id)
+		return id, nil, pb.NewStatusError(codes.InvalidArgument, pb.ErrorCode_INVALID_REQUEST,
+			"tenant cannot be changed during the protocol")
+	}
+	if msg.ProtocolVersion != 0 && msg.ProtocolVersion != run.Version {
+		s.deleteRun(id)
+		return id, nil, pb.NewStatusError(codes.InvalidArgument, pb.ErrorCode_INVALID_REQUEST,
+			"protocol version cannot be changed during the protocol")
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+	md.Set(pb.TenantMetadataKey, run.Tenant)
+	ctx = metadata.NewIncomingContext(ctx, md)
+	msg.ProtocolVersion = run.Version
+
+	resp, err := s.runRound(ctx, method, finishRound, msg,
+		func(ctx context.Context, t *Tenant, req *pb.Message) (*pb.Message, error) {
+			org, err := s.loadCLOrg(t)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := p.finish(s, ctx, t, org, new(big.Int).SetBytes(run.Nonce), req)
+			if err != nil {
+				return nil, err
+			}
+			run.Done = true
+			return resp, s.updateRun(id, run, ttl)
+		})
+	if err != nil {
+		s.deleteRun(id)
+		return id, nil, err
+	}
+
+	return id, resp, nil
+}
+
+// startShared starts a run of protocol p with msg, the first message of the client.
+// Its tenant and the version of its messages are selected by the interceptors of the
+// server's streams, as for streams.
+func (s *Server) startShared(ctx context.Context, method string, p resumableProtocol,
+	msg *pb.Message, ttl time.Duration) (string, *pb.Message, error) {
+	if msg == nil {
+		return "", nil, pb.NewStatusError(codes.InvalidArgument, pb.ErrorCode_INVALID_REQUEST,
+			"missing message")
+	}
+
+	var id string
+	resp, err := s.runRound(ctx, method, startRound, msg,
+		func(ctx context.Context, t *Tenant, req *pb.Message) (*pb.Message, error) {
+			org, err := s.loadCLOrg(t)
+			if err != nil {
+				return nil, err
+			}
+			nonce, resp, err := p.start(s, ctx, t, org, req)
+			if err != nil {
+				return nil, err
+			}
+
+			run := &sharedRun{
+				Method:  method,
+				Version: protocolVersion(ctx),
+				Nonce:   nonce.Bytes(),
+			}
+			if ref, ok := ctx.Value(tenantKey{}).(*tenantRef); ok {
+				run.Tenant = ref.id
+			}
+			b := make([]byte, 16)
+			if _, err := io.ReadFull(common.RandReader(), b); err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			id = hex.EncodeToString(b)
+			return resp, s.storeRun(id, run, ttl)
+		})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return id, resp, nil
+}
+
+// sharedRoundKey is the key of the sharedRound of streams that run a single round of
+// a protocol run kept in the session store.
+type sharedRoundKey struct{}
+
+// sharedRound is a round of a run of a resumable protocol.
+type sharedRound int
+
+const (
+	startRound sharedRound = iota + 1
+	finishRound
+)
+
+// startsRun returns true unless the stream with context ctx continues a run that
+// another stream started, so that interceptors count and limit runs only once.
+func startsRun(ctx context.Context) bool {
+	r, _ := ctx.Value(sharedRoundKey{}).(sharedRound)
+	return r != finishRound
+}
+
+// endsRun returns true unless the stream with context ctx only starts a run, whose
+// outcome is known once another stream ends it.
+func endsRun(ctx context.Context) bool {
+	r, _ := ctx.Value(sharedRoundKey{}).(sharedRound)
+	return r != startRound
+}
+
+// runRound runs round of a run of method through the interceptors of the server's
+// streams (limits, draining at shutdown, interceptors of WithStreamInterceptors,
+// tenants, versions, metrics, audit, tracing, deadlines, recording and recovery from
+// panics), as a stream receiving msg and sending the response of the round. handle
+// computes the response to the message for the tenant selected by the interceptors.
+func (s *Server) runRound(ctx context.Context, method string, round sharedRound,
+	msg *pb.Message, handle func(ctx context.Context, t *Tenant,
+		req *pb.Message) (*pb.Message, error)) (*pb.Message, error) {
+	ctx, cancel := context.WithCancel(context.WithValue(ctx, sharedRoundKey{}, round))
+	st := &roundStream{ctx: ctx, in: msg}
+	info := &grpc.StreamServerInfo{
+		FullMethod:     method,
+		IsClientStream: true,
+		IsServerStream: true,
+	}
+	err := s.streamInterceptor(s, st, info, func(srv interface{}, ss grpc.ServerStream) error {
+		stream := &serverStream{ss}
+		req, err := s.receive(stream)
+		if err != nil {
+			return err
+		}
+		t, err := s.tenant(stream.Context())
+		if err != nil {
+			return err
+		}
+		resp, err := handle(stream.Context(), t, req)
+		if err != nil {
+			return err
+		}
+		return s.send(resp, stream)
+	})
+	// unblock the handler if an interceptor returned before it
+	cancel()
+	resp := st.close()
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, status.Error(codes.Unavailable, "no response to the message")
+	}
+
+	return resp, nil
+}
+
+// roundStream is a server stream that receives a single message of the client and
+// keeps the response of the server.
+type roundStream struct {
+	ctx context.Context
+	in  *pb.Message
+
+	sync.Mutex
+	out    *pb.Message
+	closed bool
+}
+
+// close closes st and returns the response of the server, if any.
+func (st *roundStream) close() *pb.Message {
+	st.Lock()
+	defer st.Unlock()
+	st.closed = true
+	return st.out
+}
+
+func (st *roundStream) SetHeader(metadata.MD) error {
+	return nil
+}
+
+func (st *roundStream) SendHeader(metadata.MD) error {
+	return nil
+}
+
+func (st *roundStream) SetTrailer(metadata.MD) {}
+
+func (st *roundStream) Context() context.Context {
+	return st.ctx
+}
+
+func (st *roundStream) SendMsg(m interface{}) error {
+	st.Lock()
+	defer st.Unlock()
+	if st.closed {
+		return status.Error(codes.Canceled, "stream aborted")
+	}
+	if st.out != nil {
+		return status.Error(codes.Internal, "more than one response in a round")
+	}
+	st.out = m.(*pb.Message)
+	return nil
+}
+
+func (st *roundStream) RecvMsg(m interface{}) error {
+	if st.in == nil {
+		return io.EOF
+	}
+	*m.(*pb.Message) = *st.in
+	st.in = nil
+	return nil
+}
+
+// storeRun keeps run with id in the session store for ttl, as claims of a session.
+func (s *Server) storeRun(id string, run *sharedRun, ttl time.Duration) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	var claims map[string]interface{}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return err
+	}
+
+	err = s.sessionStore.Put(sharedRunPrefix+id, &Session{
+		Claims:    claims,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		s.Logger.Errorf("cannot store protocol run: %v", err)
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"cannot store protocol run")
+	}
+	return nil
+}
+
+// updateRun replaces the run with id in the session store with run, kept for ttl. The
+// previous state is deleted first, so that stores which do not replace sessions stored
+// under the same key work as well.
+func (s *Server) updateRun(id string, run *sharedRun, ttl time.Duration) error {
+	if err := s.deleteRun(id); err != nil {
+		return err
+	}
+	return s.storeRun(id, run, ttl)
+}
+
+// loadRun returns the run with id from the session store, or nil if there is no such
+// run or it expired.
+func (s *Server) loadRun(id string) (*sharedRun, error) {
+	session, err := s.sessionStore.Get(sharedRunPrefix + id)
+	if err == ErrSessionNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		s.Logger.Errorf("cannot load protocol run: %v", err)
+		return nil, pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"cannot load protocol run")
+	}
+
+	data, err := json.Marshal(session.Claims)
+	if err != nil {
+		return nil, err
+	}
+	run := new(sharedRun)
+	if err := json.Unmarshal(data, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// deleteRun removes the run with id from the session store.
+func (s *Server) deleteRun(id string) error {
+	if err := s.sessionStore.Delete(sharedRunPrefix + id); err != nil {
+		s.Logger.Errorf("cannot delete protocol run: %v", err)
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"cannot delete protocol run")
+	}
+	return nil
+}
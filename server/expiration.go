@@ -0,0 +1,122 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+)
+
+// SetMaxCredValidity limits how long after issuance or update CL credentials with
+// the expiration attribute (see cl.ExpirationAttr) can remain valid. Requests
+// for credentials expiring later are rejected. Validity is not limited when d
+// is 0.
+func (s *Server) SetMaxCredValidity(d time.Duration) {
+	s.maxCredValidity = d
+}
+
+// configuredRawCred returns an empty credential with the structure configured in conf.
+func configuredRawCred(conf *config.Config) (*cl.RawCred, error) {
+	structure, err := conf.LoadCredentialStructure()
+	if err != nil {
+		return nil, err
+	}
+	attrs, attrCount, err := cl.ParseAttrs(structure)
+	if err != nil {
+		return nil, err
+	}
+	rc := cl.NewRawCred(attrCount)
+	for _, a := range attrs {
+		if err := rc.AddAttr(a); err != nil {
+			return nil, err
+		}
+	}
+	return rc, nil
+}
+
+// maxIssuedAtSkew is how far the issuance time of a credential to be issued or
+// updated (see cl.IssuedAtAttr) can be from the time of the request.
+const maxIssuedAtSkew = 5 * time.Minute
+
+// checkRequestedValidity checks the expiration and issuance time among known
+// attributes of a credential to be issued or updated. The credential cannot be
+// expired already, nor expire later than the maximum validity allows, and it needs
+// to be issued now. The structure of the credential is given by conf.
+func (s *Server) checkRequestedValidity(conf *config.Config, knownAttrs []*big.Int) error {
+	rc, err := configuredRawCred(conf)
+	if err != nil {
+		return err
+	}
+	indices := make([]int, len(knownAttrs))
+	for i := range indices {
+		indices[i] = i
+	}
+	now := time.Now()
+
+	issuedAt, ok, err := cl.RevealedIssuedAt(rc, indices, knownAttrs)
+	if err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+	if skew := issuedAt.Sub(now); ok && (skew < -maxIssuedAtSkew || skew > maxIssuedAtSkew) {
+		return pb.NewStatusError(codes.InvalidArgument, pb.ErrorCode_INVALID_REQUEST,
+			"requested credential is not issued now")
+	}
+
+	expiresAt, ok, err := cl.RevealedExpiration(rc, indices, knownAttrs)
+	if err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+	if !ok {
+		return nil
+	}
+	if !now.Before(expiresAt) {
+		return pb.NewStatusError(codes.FailedPrecondition, pb.ErrorCode_EXPIRED_CREDENTIAL,
+			"requested credential is already expired")
+	}
+	if s.maxCredValidity > 0 && expiresAt.Sub(now) > s.maxCredValidity {
+		return pb.NewStatusError(codes.FailedPrecondition, pb.ErrorCode_EXPIRED_CREDENTIAL,
+			fmt.Sprintf("requested credential is valid for longer than %v", s.maxCredValidity))
+	}
+	return nil
+}
+
+// checkCredExpiration checks that a proved credential has not expired, given its
+// revealed known attributes and its structure given by conf.
+func (s *Server) checkCredExpiration(conf *config.Config, revealedKnownAttrsIndices []int,
+	revealedKnownAttrs []*big.Int) error {
+	rc, err := configuredRawCred(conf)
+	if err != nil {
+		return err
+	}
+	err = cl.CheckExpiration(rc, revealedKnownAttrsIndices, revealedKnownAttrs, time.Now())
+	if err == cl.ErrCredExpired {
+		return pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_EXPIRED_CREDENTIAL,
+			"credential expired")
+	}
+	if err != nil {
+		return pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_PROOF,
+			err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+// ErrInjectedFault is returned by storage operations that fail due to injected faults.
+var ErrInjectedFault = errors.New("injected storage fault")
+
+// faultInjector decides which messages and storage operations of the server fail.
+type faultInjector struct {
+	conf *config.FaultsConfig
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func newFaultInjector(conf *config.FaultsConfig) *faultInjector {
+	seed := conf.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &faultInjector{
+		conf: conf,
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// hit returns true with probability rate.
+func (f *faultInjector) hit(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rand.Float64() < rate
+}
+
+func (f *faultInjector) delay() {
+	if f.hit(f.conf.DelayRate) {
+		time.Sleep(f.conf.Delay)
+	}
+}
+
+// dropMessage delays a message about to be sent or received, and returns whether
+// it is to be dropped.
+func (f *faultInjector) dropMessage() bool {
+	f.delay()
+	return f.hit(f.conf.DropRate)
+}
+
+// storageError delays a storage operation, and returns ErrInjectedFault if it is
+// to fail.
+func (f *faultInjector) storageError() error {
+	f.delay()
+	if f.hit(f.conf.StorageErrorRate) {
+		return ErrInjectedFault
+	}
+	return nil
+}
+
+// InjectFaults makes the server delay and drop messages of protocol streams, and
+// fail operations of its registration and record storage, as set by conf. It is
+// meant for testing how clients handle failures, and has to be called before the
+// server starts. New calls it when faults are enabled in the configuration.
+func (s *Server) InjectFaults(conf *config.FaultsConfig) {
+	f := newFaultInjector(conf)
+	s.faults = f
+	s.RegistrationManager = &faultyRegistrationManager{s.RegistrationManager, f}
+	s.clRecordManager = &faultyRecordManager{s.clRecordManager, f}
+	s.Logger.Warning("######## Injecting faults, do not use in production ########")
+}
+
+type faultyRegistrationManager struct {
+	RegistrationManager
+	f *faultInjector
+}
+
+func (m *faultyRegistrationManager) CheckRegistrationKey(key string) (bool, error) {
+	if err := m.f.storageError(); err != nil {
+		return false, err
+	}
+	return m.RegistrationManager.CheckRegistrationKey(key)
+}
+
+type faultyRecordManager struct {
+	cl.ReceiverRecordManager
+	f *faultInjector
+}
+
+func (m *faultyRecordManager) Store(nym *big.Int, rec *cl.ReceiverRecord) error {
+	if err := m.f.storageError(); err != nil {
+		return err
+	}
+	return m.ReceiverRecordManager.Store(nym, rec)
+}
+
+func (m *faultyRecordManager) Load(nym *big.Int) (*cl.ReceiverRecord, error) {
+	if err := m.f.storageError(); err != nil {
+		return nil, err
+	}
+	return m.ReceiverRecordManager.Load(nym)
+}
@@ -0,0 +1,823 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/xlab-si/emmy/crypto/common"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OpenAPIPath is the path where the gateway serves its OpenAPI specification.
+const OpenAPIPath = "/openapi.json"
+
+// Gateway exposes parts of emmy server's API over HTTP/JSON, for clients that
+// cannot use gRPC, and describes it with an OpenAPI specification that can be
+// used to generate clients in other languages.
+type Gateway struct {
+	server     *Server
+	adminToken string
+	routes     []*route
+	mux        *http.ServeMux
+	streams    *GrpcWebHandler // runs protocol streams as the gRPC-Web handler does
+}
+
+// route is an endpoint of the gateway. Request and response are zero values of
+// request and response body types (nil if there is no body), from which the
+// specification is generated.
+type route struct {
+	method   string
+	path     string
+	id       string
+	summary  string
+	tag      string
+	admin    bool
+	query    map[string]string // descriptions of query parameters by their names
+	request  interface{}
+	response interface{}
+	handler  func(r *http.Request) (interface{}, error)
+}
+
+// httpError is an error with the HTTP status code to respond with.
+type httpError struct {
+	code  int
+	msg   string
+	cause string
+}
+
+func (e *httpError) Error() string {
+	return e.msg
+}
+
+// Bodies of requests and responses of the gateway.
+type (
+	ErrorResponse struct {
+		Error string `json:"error"`
+		// Cause is the name of the proto.ErrorCode of protocol errors.
+		Cause string `json:"cause,omitempty"`
+	}
+
+	ServiceInfo struct {
+		Name        string `json:"name"`
+		Provider    string `json:"provider"`
+		Description string `json:"description"`
+	}
+
+	// CredAttribute describes an attribute of credentials of type string, int64,
+	// blob, date, bool, enum (one of Values) or bytes (of the given Size).
+	CredAttribute struct {
+		Name   string   `json:"name"`
+		Type   string   `json:"type"`
+		Known  bool     `json:"known"`
+		Values []string `json:"values,omitempty"`
+		Size   int      `json:"size,omitempty"`
+	}
+
+	CredStructure struct {
+		Name       string          `json:"name,omitempty"`
+		Version    string          `json:"version,omitempty"`
+		Known      int             `json:"known"`
+		Committed  int             `json:"committed"`
+		Hidden     int             `json:"hidden"`
+		Attributes []CredAttribute `json:"attributes"`
+	}
+
+	AcceptableCred struct {
+		OrgName       string      `json:"org_name"`
+		RevealedAttrs []string    `json:"revealed_attrs"`
+		Issuers       []string    `json:"issuers,omitempty"`
+		Predicates    []Predicate `json:"predicates,omitempty"`
+		MaxAge        int64       `json:"max_age,omitempty"`
+	}
+
+	// Predicate is a predicate about an attribute (see cl.Predicate), with
+	// internal values of the attribute in decimal.
+	Predicate struct {
+		Type   string   `json:"type"`
+		Attr   string   `json:"attr"`
+		Values []string `json:"values,omitempty"`
+	}
+
+	SessionKey struct {
+		SessionKey string `json:"session_key"`
+		IDToken    string `json:"id_token,omitempty"`
+	}
+
+	// CredProof carries a non-interactive proof of a CL credential, a
+	// protobuf-encoded proto.ProveCLCredentialNI.
+	CredProof struct {
+		Proof []byte `json:"proof"`
+	}
+
+	SessionStatus struct {
+		Valid     bool                   `json:"valid"`
+		ExpiresAt int64                  `json:"expires_at,omitempty"`
+		AuthTime  int64                  `json:"auth_time,omitempty"`
+		Claims    map[string]interface{} `json:"claims,omitempty"`
+		Reason    string                 `json:"reason,omitempty"`
+	}
+
+	RegistrationKey struct {
+		Key string `json:"key,omitempty"`
+	}
+
+	Revocation struct {
+		Nym     string `json:"nym"`
+		Version int    `json:"version,omitempty"`
+	}
+
+	// StreamRequest carries a message of the client in a protocol run, a
+	// protobuf-encoded proto.Message. The first request of a run has no session,
+	// and a request without a message ends the client's side of the run.
+	StreamRequest struct {
+		Session string `json:"session,omitempty"`
+		Message []byte `json:"message,omitempty"`
+	}
+
+	// StreamResponse carries the next message of the server in a protocol run, along
+	// with the session token to be passed in subsequent requests of the run. Done is
+	// set once the run completed, when there is no message.
+	StreamResponse struct {
+		Session string `json:"session"`
+		Message []byte `json:"message,omitempty"`
+		Done    bool   `json:"done,omitempty"`
+	}
+)
+
+// NewGateway returns a gateway in front of server s. Admin endpoints require
+// adminToken as a bearer token, and are disabled when adminToken is empty.
+func NewGateway(s *Server, adminToken string) *Gateway {
+	g := &Gateway{
+		server:     s,
+		adminToken: adminToken,
+		mux:        http.NewServeMux(),
+		streams:    NewGrpcWebHandler(s, nil),
+	}
+
+	g.routes = []*route{
+		{
+			method:   http.MethodGet,
+			path:     "/v1/info",
+			id:       "getServiceInfo",
+			summary:  "Returns information about the service",
+			tag:      "info",
+			response: ServiceInfo{},
+			handler:  g.serviceInfo,
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/v1/cl/structure",
+			id:       "getCredentialStructure",
+			summary:  "Returns the structure of CL credentials issued by the organization",
+			tag:      "cl",
+			response: CredStructure{},
+			handler:  g.credStructure,
+			query: map[string]string{
+				"name":    "Name of the credential schema, the default one if not given",
+				"version": "Version of the credential schema, the latest one if not given",
+			},
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/v1/cl/schemas",
+			id:       "getCredentialSchemas",
+			summary:  "Returns all versions of structures of CL credentials issued by the organization",
+			tag:      "cl",
+			response: []CredStructure{},
+			handler:  g.credSchemas,
+		},
+		{
+			method:   http.MethodGet,
+			path:     "/v1/cl/acceptable-creds",
+			id:       "getAcceptableCredentials",
+			summary:  "Returns credentials accepted by the service and attributes that need to be revealed",
+			tag:      "cl",
+			response: []AcceptableCred{},
+			handler:  g.acceptableCreds,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/v1/cl/proofs",
+			id:       "proveCredential",
+			summary:  "Verifies a non-interactive proof of a CL credential, returning a session key",
+			tag:      "cl",
+			request:  CredProof{},
+			response: SessionKey{},
+			handler:  g.proveCred,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/v1/sessions/validate",
+			id:       "validateSessionKey",
+			summary:  "Validates a session key obtained by proving possession of a credential",
+			tag:      "sessions",
+			request:  SessionKey{},
+			response: SessionStatus{},
+			handler:  g.validateSession,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/v1/sessions/refresh",
+			id:       "refreshSession",
+			summary:  "Replaces a valid session key with a new one for the same session",
+			tag:      "sessions",
+			request:  SessionKey{},
+			response: SessionKey{},
+			handler:  g.refreshSession,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/v1/sessions/end",
+			id:       "endSession",
+			summary:  "Ends the session of a session key before it expires",
+			tag:      "sessions",
+			request:  SessionKey{},
+			response: SessionStatus{},
+			handler:  g.endSession,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/v1/admin/registration-keys",
+			id:       "addRegistrationKey",
+			summary:  "Adds a registration key (a random one if not given) for credential issuance",
+			tag:      "admin",
+			admin:    true,
+			request:  RegistrationKey{},
+			response: RegistrationKey{},
+			handler:  g.addRegistrationKey,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/v1/admin/schemas",
+			id:       "registerCredentialSchema",
+			summary:  "Registers a new version of a credential schema with the given name and attributes",
+			tag:      "admin",
+			admin:    true,
+			request:  CredStructure{},
+			response: CredStructure{},
+			handler:  g.registerCredSchema,
+		},
+		{
+			method:   http.MethodPost,
+			path:     "/v1/admin/revocations",
+			id:       "revokeCredential",
+			summary:  "Revokes the CL credential issued to a nym (in decimal), returning the new accumulator version",
+			tag:      "admin",
+			admin:    true,
+			request:  Revocation{},
+			response: Revocation{},
+			handler:  g.revokeCredential,
+		},
+	}
+
+	g.routes = append(g.routes, g.streamRoutes()...)
+
+	for _, r := range g.routes {
+		g.mux.HandleFunc(r.path, g.handle(r))
+	}
+	spec := g.OpenAPI()
+	g.mux.HandleFunc(OpenAPIPath, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, spec)
+	})
+
+	return g
+}
+
+// ServeHTTP dispatches requests to gateway endpoints.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// OpenAPI generates the OpenAPI specification of the gateway.
+func (g *Gateway) OpenAPI() *OpenAPI {
+	gen := &schemaGenerator{components: make(map[string]*Schema)}
+	errResponse := Response{
+		Description: "Error",
+		Content: map[string]MediaType{
+			"application/json": {Schema: gen.schemaOf(reflect.TypeOf(ErrorResponse{}))},
+		},
+	}
+
+	spec := &OpenAPI{
+		OpenAPI: "3.0.0",
+		Info: OpenAPIInfo{
+			Title:   "emmy gateway",
+			Version: "1.0.0",
+		},
+		Paths: make(map[string]map[string]Operation),
+		Components: Components{
+			Schemas: gen.components,
+			SecuritySchemes: map[string]SecurityScheme{
+				"admin": {Type: "http", Scheme: "bearer"},
+			},
+		},
+	}
+
+	for _, r := range g.routes {
+		op := Operation{
+			OperationID: r.id,
+			Summary:     r.summary,
+			Tags:        []string{r.tag},
+			Responses: map[string]Response{
+				"200": {
+					Description: "OK",
+					Content: map[string]MediaType{
+						"application/json": {Schema: gen.schemaOf(reflect.TypeOf(r.response))},
+					},
+				},
+				"default": errResponse,
+			},
+		}
+		names := make([]string, 0, len(r.query))
+		for name := range r.query {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:        name,
+				In:          "query",
+				Description: r.query[name],
+				Schema:      &Schema{Type: "string"},
+			})
+		}
+		if r.request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					"application/json": {Schema: gen.schemaOf(reflect.TypeOf(r.request))},
+				},
+			}
+		}
+		if r.admin {
+			op.Security = []map[string][]string{{"admin": {}}}
+		}
+		if spec.Paths[r.path] == nil {
+			spec.Paths[r.path] = make(map[string]Operation)
+		}
+		spec.Paths[r.path][strings.ToLower(r.method)] = op
+	}
+
+	return spec
+}
+
+// handle returns an HTTP handler of route r, which checks the request method and
+// authorization, and writes the result of r's handler in JSON form.
+func (g *Gateway) handle(r *route) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != r.method {
+			writeJSON(w, http.StatusMethodNotAllowed,
+				&ErrorResponse{Error: "method not allowed"})
+			return
+		}
+		if r.admin && !g.authorizeAdmin(req) {
+			writeJSON(w, http.StatusUnauthorized, &ErrorResponse{Error: "unauthorized"})
+			return
+		}
+
+		res, err := r.handler(req)
+		if err != nil {
+			code := http.StatusInternalServerError
+			if e, ok := err.(*httpError); ok {
+				code = e.code
+			} else {
+				g.server.Logger.Debug(err)
+				err = &httpError{msg: "internal error"}
+			}
+			resp := &ErrorResponse{Error: err.Error()}
+			if e, ok := err.(*httpError); ok {
+				resp.Cause = e.cause
+			}
+			writeJSON(w, code, resp)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+func (g *Gateway) authorizeAdmin(r *http.Request) bool {
+	return g.adminToken != "" &&
+		r.Header.Get("Authorization") == "Bearer "+g.adminToken
+}
+
+func (g *Gateway) serviceInfo(r *http.Request) (interface{}, error) {
+	info, err := g.server.GetServiceInfo(context.Background(), &empty.Empty{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ServiceInfo{
+		Name:        info.Name,
+		Provider:    info.Provider,
+		Description: info.Description,
+	}, nil
+}
+
+func (g *Gateway) credStructure(r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	s, err := g.server.GetCredentialStructure(tenantContext(r), &pb.CredStructureRequest{
+		Name:    q.Get("name"),
+		Version: q.Get("version"),
+	})
+	if err != nil {
+		return nil, statusToHTTPError(err)
+	}
+
+	return toCredStructure(s), nil
+}
+
+func (g *Gateway) credSchemas(r *http.Request) (interface{}, error) {
+	t, err := g.server.tenant(tenantContext(r))
+	if err != nil {
+		return nil, statusToHTTPError(err)
+	}
+	var schemas []*CredSchema
+	if reg := g.server.schemaRegistry(t); reg != nil {
+		schemas = reg.Schemas()
+	}
+	structures := make([]*CredStructure, len(schemas))
+	for i, s := range schemas {
+		structures[i] = toCredStructure(credStructure(s))
+	}
+
+	return structures, nil
+}
+
+func (g *Gateway) registerCredSchema(r *http.Request) (interface{}, error) {
+	req := new(CredStructure)
+	if err := decodeJSON(r, req); err != nil {
+		return nil, err
+	}
+	structure := make(map[string]interface{}, len(req.Attributes))
+	for i, a := range req.Attributes {
+		t := a.Type
+		switch t {
+		case "enum":
+			t = fmt.Sprintf("enum(%s)", strings.Join(a.Values, "|"))
+		case "bytes":
+			t = fmt.Sprintf("bytes(%d)", a.Size)
+		}
+		structure[a.Name] = map[string]interface{}{
+			"index": strconv.Itoa(i),
+			"type":  t,
+			"known": strconv.FormatBool(a.Known),
+		}
+	}
+	if len(structure) != len(req.Attributes) {
+		return nil, &httpError{code: http.StatusBadRequest, msg: "duplicated attribute names"}
+	}
+	schema, err := g.server.RegisterCredentialSchema(req.Name, req.Version, structure)
+	if err != nil {
+		return nil, &httpError{code: http.StatusBadRequest, msg: err.Error()}
+	}
+
+	return toCredStructure(credStructure(schema)), nil
+}
+
+// toCredStructure converts the structure of credentials of the gRPC API to the
+// body of the gateway's response.
+func toCredStructure(s *pb.CredStructure) *CredStructure {
+	structure := &CredStructure{
+		Name:       s.Name,
+		Version:    s.Version,
+		Known:      int(s.NKnown),
+		Committed:  int(s.NCommitted),
+		Hidden:     int(s.NHidden),
+		Attributes: make([]CredAttribute, len(s.Attributes)),
+	}
+	for i, a := range s.Attributes {
+		var attr *pb.Attribute
+		switch t := a.Type.(type) {
+		case *pb.CredAttribute_StringAttr:
+			attr = t.StringAttr.Attr
+			structure.Attributes[i].Type = "string"
+		case *pb.CredAttribute_IntAttr:
+			attr = t.IntAttr.Attr
+			structure.Attributes[i].Type = "int64"
+		case *pb.CredAttribute_BlobAttr:
+			attr = t.BlobAttr.Attr
+			structure.Attributes[i].Type = "blob"
+		case *pb.CredAttribute_DateAttr:
+			attr = t.DateAttr.Attr
+			structure.Attributes[i].Type = "date"
+		case *pb.CredAttribute_BoolAttr:
+			attr = t.BoolAttr.Attr
+			structure.Attributes[i].Type = "bool"
+		case *pb.CredAttribute_EnumAttr:
+			attr = t.EnumAttr.Attr
+			structure.Attributes[i].Type = "enum"
+			structure.Attributes[i].Values = t.EnumAttr.Values
+		case *pb.CredAttribute_BytesAttr:
+			attr = t.BytesAttr.Attr
+			structure.Attributes[i].Type = "bytes"
+			structure.Attributes[i].Size = int(t.BytesAttr.Size)
+		default:
+			continue
+		}
+		structure.Attributes[i].Name = attr.Name
+		structure.Attributes[i].Known = attr.Known
+	}
+
+	return structure
+}
+
+func (g *Gateway) acceptableCreds(r *http.Request) (interface{}, error) {
+	accCreds, err := g.server.GetAcceptableCredentials(tenantContext(r), &empty.Empty{})
+	if err != nil {
+		return nil, statusToHTTPError(err)
+	}
+
+	creds := make([]AcceptableCred, len(accCreds.Creds))
+	for i, c := range accCreds.Creds {
+		creds[i] = AcceptableCred{
+			OrgName:       c.OrgName,
+			RevealedAttrs: c.RevealedAttrs,
+			Issuers:       c.Issuers,
+			MaxAge:        c.MaxAge,
+		}
+		for _, p := range c.Predicates {
+			creds[i].Predicates = append(creds[i].Predicates, Predicate{
+				Type:   p.Type,
+				Attr:   p.Attr,
+				Values: p.Values,
+			})
+		}
+	}
+
+	return creds, nil
+}
+
+func (g *Gateway) proveCred(r *http.Request) (interface{}, error) {
+	req := new(CredProof)
+	if err := decodeJSON(r, req); err != nil {
+		return nil, err
+	}
+	proof := new(pb.ProveCLCredentialNI)
+	if err := proto.Unmarshal(req.Proof, proof); err != nil {
+		return nil, &httpError{code: http.StatusBadRequest, msg: "malformed proof"}
+	}
+
+	key, err := g.server.ProveCredentialNI(tenantContext(r), proof)
+	if err != nil {
+		return nil, statusToHTTPError(err)
+	}
+
+	return &SessionKey{
+		SessionKey: key.Value,
+		IDToken:    key.IdToken,
+	}, nil
+}
+
+func (g *Gateway) validateSession(r *http.Request) (interface{}, error) {
+	req := new(SessionKey)
+	if err := decodeJSON(r, req); err != nil {
+		return nil, err
+	}
+
+	t, err := g.server.tenant(tenantContext(r))
+	if err != nil {
+		return nil, statusToHTTPError(err)
+	}
+	session, err := g.server.validateSession(t, req.SessionKey)
+	if err == errSessionsNotValidated {
+		return nil, &httpError{code: http.StatusNotImplemented, msg: err.Error()}
+	}
+	if err != nil {
+		return &SessionStatus{Reason: err.Error()}, nil
+	}
+
+	status := &SessionStatus{
+		Valid:     true,
+		ExpiresAt: session.ExpiresAt.Unix(),
+		Claims:    session.Claims,
+	}
+	if !session.AuthTime.IsZero() {
+		status.AuthTime = session.AuthTime.Unix()
+	}
+	return status, nil
+}
+
+func (g *Gateway) refreshSession(r *http.Request) (interface{}, error) {
+	req := new(SessionKey)
+	if err := decodeJSON(r, req); err != nil {
+		return nil, err
+	}
+
+	key, err := (&sessionService{g.server}).RefreshSession(tenantContext(r),
+		&pb.SessionKey{Value: req.SessionKey})
+	if err != nil {
+		return nil, statusToHTTPError(err)
+	}
+
+	return &SessionKey{SessionKey: key.Value}, nil
+}
+
+func (g *Gateway) endSession(r *http.Request) (interface{}, error) {
+	req := new(SessionKey)
+	if err := decodeJSON(r, req); err != nil {
+		return nil, err
+	}
+	if g.server.sessionStore == nil {
+		return nil, &httpError{code: http.StatusNotImplemented, msg: "sessions are not stored"}
+	}
+	t, err := g.server.tenant(tenantContext(r))
+	if err != nil {
+		return nil, statusToHTTPError(err)
+	}
+	if err := g.server.endSession(t, req.SessionKey); err != nil {
+		return nil, err
+	}
+
+	return &SessionStatus{Reason: "session ended"}, nil
+}
+
+func (g *Gateway) addRegistrationKey(r *http.Request) (interface{}, error) {
+	req := new(RegistrationKey)
+	if err := decodeJSON(r, req); err != nil {
+		return nil, err
+	}
+	if req.Key == "" {
+		b := make([]byte, 16)
+		if _, err := io.ReadFull(common.RandReader(), b); err != nil {
+			return nil, err
+		}
+		req.Key = hex.EncodeToString(b)
+	}
+	if err := g.server.AddRegistrationKey(req.Key); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (g *Gateway) revokeCredential(r *http.Request) (interface{}, error) {
+	req := new(Revocation)
+	if err := decodeJSON(r, req); err != nil {
+		return nil, err
+	}
+	if g.server.revocation == nil {
+		return nil, &httpError{code: http.StatusNotImplemented, msg: "revocation is not enabled"}
+	}
+	nym, ok := new(big.Int).SetString(req.Nym, 10)
+	if !ok {
+		return nil, &httpError{code: http.StatusBadRequest, msg: "malformed nym"}
+	}
+	if err := g.server.RevokeCredential(nym); err != nil {
+		return nil, &httpError{code: http.StatusBadRequest, msg: err.Error()}
+	}
+
+	g.server.revocation.Lock()
+	req.Version = g.server.revocation.authority.Acc.Version
+	g.server.revocation.Unlock()
+	return req, nil
+}
+
+// streamRoutes returns routes of protocols of the server, one for each gRPC stream
+// at /v1/streams/<service>/<method>. Each request of a protocol run carries a
+// message of the client and its response the next message of the server, while
+// the session token of the response ties subsequent requests to the same run.
+func (g *Gateway) streamRoutes() []*route {
+	methods := make([]string, 0, len(grpcWebStreamHandlers))
+	for m := range grpcWebStreamHandlers {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+
+	routes := make([]*route, len(methods))
+	for i, m := range methods {
+		service, method := path.Split(strings.TrimPrefix(m, "/proto."))
+		service = strings.TrimSuffix(service, "/")
+		fullMethod := m
+		routes[i] = &route{
+			method:   http.MethodPost,
+			path:     "/v1/streams/" + service + "/" + method,
+			id:       "stream" + service + method,
+			summary:  fmt.Sprintf("Exchanges a message of the %s protocol of the %s service", method, service),
+			tag:      "streams",
+			request:  StreamRequest{},
+			response: StreamResponse{},
+			handler: func(r *http.Request) (interface{}, error) {
+				return g.stream(r, fullMethod)
+			},
+		}
+	}
+
+	return routes
+}
+
+// stream passes the message of the request to the protocol run of method the request
+// belongs to, and returns the next message of the server.
+func (g *Gateway) stream(r *http.Request, method string) (*StreamResponse, error) {
+	req := new(StreamRequest)
+	if err := decodeJSON(r, req); err != nil {
+		return nil, err
+	}
+	var msg *pb.Message
+	if len(req.Message) > 0 {
+		msg = new(pb.Message)
+		if err := proto.Unmarshal(req.Message, msg); err != nil {
+			return nil, &httpError{code: http.StatusBadRequest, msg: "malformed message"}
+		}
+	}
+
+	session, resp, err := g.streams.exchange(tenantContext(r), req.Session, method, msg)
+	if err != nil {
+		return nil, statusToHTTPError(err)
+	}
+	if resp == nil {
+		return &StreamResponse{Session: session, Done: true}, nil
+	}
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamResponse{
+		Session: session,
+		Message: data,
+	}, nil
+}
+
+// statusToHTTPError converts gRPC status errors of protocols to errors with the
+// corresponding HTTP status code. Other errors are returned unchanged, so that
+// their details are not exposed.
+func statusToHTTPError(err error) error {
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	code := http.StatusInternalServerError
+	switch s.Code() {
+	case codes.InvalidArgument:
+		code = http.StatusBadRequest
+	case codes.Unauthenticated:
+		code = http.StatusUnauthorized
+	case codes.PermissionDenied:
+		code = http.StatusForbidden
+	case codes.NotFound:
+		code = http.StatusNotFound
+	case codes.FailedPrecondition:
+		code = http.StatusPreconditionFailed
+	case codes.Unimplemented:
+		code = http.StatusNotImplemented
+	case codes.DeadlineExceeded:
+		code = http.StatusGatewayTimeout
+	case codes.Unavailable:
+		code = http.StatusServiceUnavailable
+	case codes.ResourceExhausted:
+		code = http.StatusTooManyRequests
+	case codes.Canceled:
+		code = http.StatusRequestTimeout
+	}
+	e := &httpError{code: code, msg: s.Message()}
+	if pe := pb.ToProtocolError(err); pe != nil {
+		e.cause = pe.Code.String()
+	}
+	return e
+}
+
+func decodeJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return &httpError{code: http.StatusBadRequest, msg: "malformed request body"}
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
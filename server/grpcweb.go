@@ -0,0 +1,531 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/grpcweb"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// grpcWebStreamHandlers map full names of gRPC streams to the handlers of the server.
+var grpcWebStreamHandlers = map[string]func(*Server, pb.ServerStream) error{
+	"/proto.PseudonymSystemCA/GenerateCertificate": func(s *Server, st pb.ServerStream) error {
+		return s.GenerateCertificate(st)
+	},
+	"/proto.PseudonymSystemCA/GenerateCertificate_EC": func(s *Server, st pb.ServerStream) error {
+		return s.GenerateCertificate_EC(st)
+	},
+	"/proto.PseudonymSystem/GenerateNym": func(s *Server, st pb.ServerStream) error {
+		return s.GenerateNym(st)
+	},
+	"/proto.PseudonymSystem/GenerateNym_EC": func(s *Server, st pb.ServerStream) error {
+		return s.GenerateNym_EC(st)
+	},
+	"/proto.PseudonymSystem/ObtainCredential": func(s *Server, st pb.ServerStream) error {
+		return s.ObtainCredential(st)
+	},
+	"/proto.PseudonymSystem/ObtainCredential_EC": func(s *Server, st pb.ServerStream) error {
+		return s.ObtainCredential_EC(st)
+	},
+	"/proto.PseudonymSystem/TransferCredential": func(s *Server, st pb.ServerStream) error {
+		return s.TransferCredential(st)
+	},
+	"/proto.PseudonymSystem/TransferCredential_EC": func(s *Server, st pb.ServerStream) error {
+		return s.TransferCredential_EC(st)
+	},
+	"/proto.CL/IssueCredential": func(s *Server, st pb.ServerStream) error {
+		return s.IssueCredential(st)
+	},
+	"/proto.CL/UpdateCredential": func(s *Server, st pb.ServerStream) error {
+		return s.UpdateCredential(st)
+	},
+	"/proto.CL/ProveCredential": func(s *Server, st pb.ServerStream) error {
+		return s.ProveCredential(st)
+	},
+	"/proto.BBS/IssueBBSCredential": func(s *Server, st pb.ServerStream) error {
+		return s.IssueBBSCredential(st)
+	},
+	"/proto.BBS/ProveBBSCredential": func(s *Server, st pb.ServerStream) error {
+		return s.ProveBBSCredential(st)
+	},
+}
+
+// grpcWebUnaryHandlers map full names of unary RPCs to the handlers of the server,
+// which read requests with decode.
+var grpcWebUnaryHandlers = map[string]func(s *Server, ctx context.Context,
+	decode func(proto.Message) error) (proto.Message, error){
+	"/proto.Info/GetServiceInfo": func(s *Server, ctx context.Context,
+		_ func(proto.Message) error) (proto.Message, error) {
+		return s.GetServiceInfo(ctx, &empty.Empty{})
+	},
+	"/proto.Info/GetPublicParameters": func(s *Server, ctx context.Context,
+		_ func(proto.Message) error) (proto.Message, error) {
+		return s.GetPublicParameters(ctx, &empty.Empty{})
+	},
+	"/proto.CL/GetCredentialStructure": func(s *Server, ctx context.Context,
+		decode func(proto.Message) error) (proto.Message, error) {
+		req := new(pb.CredStructureRequest)
+		if err := decode(req); err != nil {
+			return nil, err
+		}
+		return s.GetCredentialStructure(ctx, req)
+	},
+	"/proto.CL/GetAcceptableCredentials": func(s *Server, ctx context.Context,
+		_ func(proto.Message) error) (proto.Message, error) {
+		return s.GetAcceptableCredentials(ctx, &empty.Empty{})
+	},
+	"/proto.Revocation/GetAccumulator": func(s *Server, ctx context.Context,
+		_ func(proto.Message) error) (proto.Message, error) {
+		return s.GetAccumulator(ctx, &empty.Empty{})
+	},
+	"/proto.Session/ValidateSession": func(s *Server, ctx context.Context,
+		decode func(proto.Message) error) (proto.Message, error) {
+		req := new(pb.SessionKey)
+		if err := decode(req); err != nil {
+			return nil, err
+		}
+		return (&sessionService{s}).ValidateSession(ctx, req)
+	},
+	"/proto.Session/RefreshSession": func(s *Server, ctx context.Context,
+		decode func(proto.Message) error) (proto.Message, error) {
+		req := new(pb.SessionKey)
+		if err := decode(req); err != nil {
+			return nil, err
+		}
+		return (&sessionService{s}).RefreshSession(ctx, req)
+	},
+	"/proto.Session/RevokeSession": func(s *Server, ctx context.Context,
+		decode func(proto.Message) error) (proto.Message, error) {
+		req := new(pb.SessionKey)
+		if err := decode(req); err != nil {
+			return nil, err
+		}
+		return (&sessionService{s}).RevokeSession(ctx, req)
+	},
+	pb.GenerateCertificateNIMethod: func(s *Server, ctx context.Context,
+		decode func(proto.Message) error) (proto.Message, error) {
+		req := new(pb.PseudonymsysCACertificateRequestNI)
+		if err := decode(req); err != nil {
+			return nil, err
+		}
+		return s.GenerateCertificateNI(ctx, req)
+	},
+	pb.GenerateNymNIMethod: func(s *Server, ctx context.Context,
+		decode func(proto.Message) error) (proto.Message, error) {
+		req := new(pb.PseudonymsysNymGenProofNI)
+		if err := decode(req); err != nil {
+			return nil, err
+		}
+		return s.GenerateNymNI(ctx, req)
+	},
+	pb.ProveCredentialNIMethod: func(s *Server, ctx context.Context,
+		decode func(proto.Message) error) (proto.Message, error) {
+		req := new(pb.ProveCLCredentialNI)
+		if err := decode(req); err != nil {
+			return nil, err
+		}
+		return s.ProveCredentialNI(ctx, req)
+	},
+	pb.StepMethod: func(s *Server, ctx context.Context,
+		decode func(proto.Message) error) (proto.Message, error) {
+		req := new(pb.ProtocolStep)
+		if err := decode(req); err != nil {
+			return nil, err
+		}
+		return s.Step(ctx, req)
+	},
+}
+
+// grpcWebIdleTimeout is the time after which idle gRPC-Web streams are aborted, unless
+// the server limits the time it waits for messages of clients.
+const grpcWebIdleTimeout = 5 * time.Minute
+
+// GrpcWebHandler serves emmy's gRPC services to browser clients with the gRPC-Web
+// protocol, without the need for a proxy. Bidirectional protocol streams are run
+// over a sequence of requests, as described in package grpcweb.
+type GrpcWebHandler struct {
+	server         *Server
+	allowedOrigins []string
+
+	sync.Mutex
+	streams map[string]*grpcWebStream
+	reaping bool // whether idle streams are being reaped
+}
+
+// NewGrpcWebHandler returns a gRPC-Web handler of s. Cross-origin requests are
+// allowed from allowedOrigins, where "*" allows any origin.
+func NewGrpcWebHandler(s *Server, allowedOrigins []string) *GrpcWebHandler {
+	return &GrpcWebHandler{
+		server:         s,
+		allowedOrigins: allowedOrigins,
+		streams:        make(map[string]*grpcWebStream),
+	}
+}
+
+func (h *GrpcWebHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.setCORSHeaders(w, r)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	contentType := r.Header.Get("Content-Type")
+	if r.Method != http.MethodPost || !grpcweb.IsGrpcWeb(contentType) {
+		http.Error(w, "expected gRPC-Web request", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var resp proto.Message
+	var err error
+	maxSize := h.maxBodySize(contentType)
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, int64(maxSize)+1))
+	if err == nil && len(body) > maxSize {
+		err = status.Errorf(codes.ResourceExhausted,
+			"request larger than max (%d bytes)", maxSize)
+	} else if err == nil {
+		if !h.server.serves(r.URL.Path) {
+			err = status.Errorf(codes.Unimplemented, "unknown method %s", r.URL.Path)
+		} else if unary, ok := grpcWebUnaryHandlers[r.URL.Path]; ok {
+			resp, err = unary(h.server, tenantContext(r), func(m proto.Message) error {
+				if _, err := grpcweb.Decode(body, contentType, m); err != nil {
+					return status.Error(codes.InvalidArgument, err.Error())
+				}
+				return nil
+			})
+		} else if _, ok := grpcWebStreamHandlers[r.URL.Path]; ok {
+			resp, err = h.handleStream(w, r, body, contentType)
+		} else {
+			err = status.Errorf(codes.Unimplemented, "unknown method %s", r.URL.Path)
+		}
+	}
+
+	data, err := grpcweb.EncodeResponse(resp, err, contentType)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(data)
+}
+
+// handleStream passes the message in body to the stream the request belongs to
+// (starting a new stream if the request carries no StreamIDHeader) and returns
+// the next message of the server. If body holds no message, the client's side of
+// the stream is closed and the final status of the stream is returned.
+func (h *GrpcWebHandler) handleStream(w http.ResponseWriter, r *http.Request, body []byte,
+	contentType string) (proto.Message, error) {
+	msg := new(pb.Message)
+	hasMsg, err := grpcweb.Decode(body, contentType, msg)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !hasMsg {
+		msg = nil
+	}
+
+	id, resp, err := h.exchange(tenantContext(r), r.Header.Get(grpcweb.StreamIDHeader),
+		r.URL.Path, msg)
+	if id != "" {
+		w.Header().Set(grpcweb.StreamIDHeader, id)
+	}
+	if resp == nil {
+		return nil, err
+	}
+	return resp, err
+}
+
+// exchange passes msg to the stream identified by id (starting a new stream of
+// method, with the incoming metadata of ctx, if id is empty) and returns the id of
+// the stream along with the next message of the server. A nil msg closes the client's side of the stream. Once
+// the stream ends, no message is returned, only the final status of the stream. Servers
+// of a cluster keep runs of resumable protocols in the session store instead (see
+// UseCluster).
+func (h *GrpcWebHandler) exchange(ctx context.Context, id, method string,
+	msg *pb.Message) (string, *pb.Message, error) {
+	if p, ok := resumableProtocols[method]; ok && h.server.clustered {
+		return h.server.exchangeShared(ctx, id, method, p, msg, h.idleTimeout())
+	}
+
+	st, err := h.stream(ctx, id, method)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if msg == nil {
+		st.closeSend()
+	} else {
+		select {
+		case st.in <- msg:
+		case <-st.ctx.Done():
+		case <-ctx.Done():
+			return st.id, nil, status.Error(codes.Canceled, "request canceled")
+		}
+	}
+
+	select {
+	case resp := <-st.out:
+		return st.id, resp, nil
+	case err := <-st.done:
+		h.removeStream(st.id)
+		return st.id, nil, err
+	case <-ctx.Done():
+		return st.id, nil, status.Error(codes.Canceled, "request canceled")
+	}
+}
+
+// stream returns the stream identified by id, or starts a new stream of method if
+// id is empty. The new stream outlives reqCtx, the context of the request starting
+// it, but receives its incoming metadata and peer.
+func (h *GrpcWebHandler) stream(reqCtx context.Context, id,
+	method string) (*grpcWebStream, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	now := time.Now()
+	h.abortIdle(now)
+
+	if id != "" {
+		st, ok := h.streams[id]
+		if !ok || st.method != method {
+			return nil, status.Error(codes.NotFound, "unknown stream")
+		}
+		st.lastUsed = now
+		return st, nil
+	}
+
+	b := make([]byte, 16)
+	if _, err := io.ReadFull(common.RandReader(), b); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	ctx := context.Background()
+	if md, ok := metadata.FromIncomingContext(reqCtx); ok {
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+	if p, ok := peer.FromContext(reqCtx); ok {
+		ctx = peer.NewContext(ctx, p)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	st := &grpcWebStream{
+		id:       hex.EncodeToString(b),
+		method:   method,
+		ctx:      ctx,
+		cancel:   cancel,
+		in:       make(chan *pb.Message),
+		out:      make(chan *pb.Message),
+		done:     make(chan error, 1),
+		lastUsed: now,
+	}
+	h.streams[st.id] = st
+	if !h.reaping {
+		h.reaping = true
+		go h.reap()
+	}
+
+	handler := grpcWebStreamHandlers[method]
+	info := &grpc.StreamServerInfo{
+		FullMethod:     method,
+		IsClientStream: true,
+		IsServerStream: true,
+	}
+	go func() {
+		err := h.server.streamInterceptor(h.server, st, info,
+			func(srv interface{}, ss grpc.ServerStream) error {
+				return handler(srv.(*Server), &serverStream{ss})
+			})
+		// unblock the handler if an interceptor returned before it
+		cancel()
+		st.done <- err
+	}()
+
+	return st, nil
+}
+
+// idleTimeout returns the time after which idle streams of h are aborted.
+func (h *GrpcWebHandler) idleTimeout() time.Duration {
+	if h.server.idleTimeout > 0 {
+		return h.server.idleTimeout
+	}
+	return grpcWebIdleTimeout
+}
+
+// abortIdle aborts streams that have not been used for longer than the idle timeout
+// at time now. It needs to be called with h locked.
+func (h *GrpcWebHandler) abortIdle(now time.Time) {
+	for id, st := range h.streams {
+		if now.Sub(st.lastUsed) > h.idleTimeout() {
+			st.cancel()
+			delete(h.streams, id)
+		}
+	}
+}
+
+// reap periodically aborts idle streams, so that streams abandoned by clients do not
+// hold goroutines of their handlers. It returns once h has no streams left.
+func (h *GrpcWebHandler) reap() {
+	ticker := time.NewTicker(h.idleTimeout() / 2)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		h.Lock()
+		h.abortIdle(now)
+		done := len(h.streams) == 0
+		if done {
+			h.reaping = false
+		}
+		h.Unlock()
+		if done {
+			return
+		}
+	}
+}
+
+// maxBodySize returns the largest size of request bodies of the given content type
+// that hold a message of the largest size the server receives.
+func (h *GrpcWebHandler) maxBodySize(contentType string) int {
+	size := h.server.maxRecvMsgSize + 5 // with the header of the frame
+	if grpcweb.IsText(contentType) {
+		size = (size + 2) / 3 * 4 // base64 encoded
+	}
+	return size
+}
+
+func (h *GrpcWebHandler) removeStream(id string) {
+	h.Lock()
+	defer h.Unlock()
+	delete(h.streams, id)
+}
+
+// abort aborts all the streams of h.
+func (h *GrpcWebHandler) abort() {
+	h.Lock()
+	defer h.Unlock()
+	for id, st := range h.streams {
+		st.cancel()
+		delete(h.streams, id)
+	}
+}
+
+func (h *GrpcWebHandler) setCORSHeaders(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return
+	}
+	for _, allowed := range h.allowedOrigins {
+		if allowed == "*" || allowed == origin {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join([]string{
+				"Content-Type", "X-Grpc-Web", "X-User-Agent", grpcweb.StreamIDHeader,
+				pb.TenantMetadataKey}, ", "))
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join([]string{
+				"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin",
+				grpcweb.StreamIDHeader}, ", "))
+			w.Header().Add("Vary", "Origin")
+			return
+		}
+	}
+}
+
+// grpcWebStream is a server stream whose messages are exchanged with the
+// requests of a gRPC-Web client.
+type grpcWebStream struct {
+	id       string
+	method   string
+	ctx      context.Context
+	cancel   context.CancelFunc
+	in       chan *pb.Message
+	out      chan *pb.Message
+	done     chan error
+	lastUsed time.Time
+	close    sync.Once
+}
+
+func (st *grpcWebStream) closeSend() {
+	st.close.Do(func() {
+		close(st.in)
+	})
+}
+
+func (st *grpcWebStream) SetHeader(metadata.MD) error {
+	return nil
+}
+
+func (st *grpcWebStream) SendHeader(metadata.MD) error {
+	return nil
+}
+
+func (st *grpcWebStream) SetTrailer(metadata.MD) {}
+
+func (st *grpcWebStream) Context() context.Context {
+	return st.ctx
+}
+
+func (st *grpcWebStream) SendMsg(m interface{}) error {
+	select {
+	case st.out <- m.(*pb.Message):
+		return nil
+	case <-st.ctx.Done():
+		return status.Error(codes.Canceled, "stream aborted")
+	}
+}
+
+func (st *grpcWebStream) RecvMsg(m interface{}) error {
+	select {
+	case msg, ok := <-st.in:
+		if !ok {
+			return io.EOF
+		}
+		*m.(*pb.Message) = *msg
+		return nil
+	case <-st.ctx.Done():
+		return status.Error(codes.Canceled, "stream aborted")
+	}
+}
+
+// serverStream adapts a grpc.ServerStream, possibly wrapped by interceptors, to
+// the pb.ServerStream interface expected by handlers.
+type serverStream struct {
+	grpc.ServerStream
+}
+
+func (s *serverStream) Send(msg *pb.Message) error {
+	return s.SendMsg(msg)
+}
+
+func (s *serverStream) Recv() (*pb.Message, error) {
+	msg := new(pb.Message)
+	if err := s.RecvMsg(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -28,11 +28,22 @@ import (
 func (s *Server) GetServiceInfo(ctx context.Context, _ *empty.Empty) (*pb.ServiceInfo, error) {
 	s.Logger.Info("Client requested service information")
 
+	t, err := s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	clPubKeys, err := s.clPubKeys(t)
+	if err != nil {
+		s.Logger.Warningf("CL public keys not available: %v", err)
+	}
+
 	name, provider, description := config.LoadServiceInfo()
 	info := &pb.ServiceInfo{
-		Name:        name,
-		Provider:    provider,
-		Description: description,
+		Name:             name,
+		Provider:         provider,
+		Description:      description,
+		ClPubKeys:        clPubKeys,
+		ProtocolVersions: pb.ProtocolVersions(),
 	}
 
 	return info, nil
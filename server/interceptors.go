@@ -0,0 +1,304 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"runtime/debug"
+	"time"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/log"
+	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/record"
+	"github.com/xlab-si/emmy/tracing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// chainStreamInterceptors returns a stream interceptor that invokes interceptors
+// in the given order, the first one being the outermost.
+func chainStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, next)
+			}
+		}
+		return chained(srv, ss)
+	}
+}
+
+// chainUnaryInterceptors returns a unary interceptor that invokes interceptors in
+// the given order, the first one being the outermost.
+func chainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return chained(ctx, req)
+	}
+}
+
+// contextStream is a server stream with a context other than the one of the
+// stream it wraps.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (st *contextStream) Context() context.Context {
+	return st.ctx
+}
+
+// requestIDKey is the key of the ID of a request in its context.
+type requestIDKey struct{}
+
+// RequestID returns the ID of the request with context ctx, which clients can give in
+// metadata pb.RequestIDMetadataKey. It returns an empty string for requests that were
+// not served by emmy server.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withRequestID returns ctx with the ID of its request, given by the client or a
+// new random one.
+func withRequestID(ctx context.Context) (context.Context, string) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md[pb.RequestIDMetadataKey]; len(v) > 0 && len(v[0]) <= 128 {
+			id = v[0]
+		}
+	}
+	if id == "" {
+		b := make([]byte, 16)
+		rand.Read(b)
+		id = hex.EncodeToString(b)
+	}
+	return context.WithValue(ctx, requestIDKey{}, id), id
+}
+
+// streamRequestIDInterceptor returns a stream interceptor that assigns IDs to
+// requests (see RequestID) and returns them in the header of responses.
+func streamRequestIDInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		ctx, id := withRequestID(ss.Context())
+		ss.SetHeader(metadata.Pairs(pb.RequestIDMetadataKey, id))
+		return handler(srv, &contextStream{
+			ServerStream: ss,
+			ctx:          ctx,
+		})
+	}
+}
+
+// unaryRequestIDInterceptor is like streamRequestIDInterceptor, but for unary RPCs.
+func unaryRequestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, id := withRequestID(ctx)
+		grpc.SetHeader(ctx, metadata.Pairs(pb.RequestIDMetadataKey, id))
+		return handler(ctx, req)
+	}
+}
+
+// streamDeadlineInterceptor returns a stream interceptor that aborts streams which are
+// not completed within the given duration. Once the interceptor returns, gRPC closes the
+// stream, so a handler still waiting for the client's message gets an error.
+func streamDeadlineInterceptor(d time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		done := make(chan error, 1)
+		go func() {
+			done <- handler(srv, ss)
+		}()
+
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case err := <-done:
+			return err
+		case <-timer.C:
+			// nonces of the protocol are no longer valid
+			return pb.NewStatusError(codes.DeadlineExceeded, pb.ErrorCode_EXPIRED_NONCE,
+				fmt.Sprintf("%s not completed within %v", info.FullMethod, d))
+		}
+	}
+}
+
+// streamIdleInterceptor returns a stream interceptor that aborts streams whose client
+// does not send the next message within the given duration while the handler waits
+// for it. Time the server spends computing its responses is not counted.
+func streamIdleInterceptor(d time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		stop := make(chan struct{})
+		defer close(stop)
+		st := &idleStream{
+			ServerStream: ss,
+			waiting:      make(chan bool),
+			stop:         stop,
+		}
+		done := make(chan error, 1)
+		go func() {
+			done <- handler(srv, st)
+		}()
+
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		for {
+			select {
+			case err := <-done:
+				if timer != nil {
+					timer.Stop()
+				}
+				return err
+			case waiting := <-st.waiting:
+				if timer != nil {
+					timer.Stop()
+					timer, timeout = nil, nil
+				}
+				if waiting {
+					timer = time.NewTimer(d)
+					timeout = timer.C
+				}
+			case <-timeout:
+				return pb.NewStatusError(codes.DeadlineExceeded, pb.ErrorCode_EXPIRED_NONCE,
+					fmt.Sprintf("no message of %s received within %v", info.FullMethod, d))
+			}
+		}
+	}
+}
+
+// idleStream reports when the handler starts and stops waiting for a message of the
+// client, until stop is closed.
+type idleStream struct {
+	grpc.ServerStream
+	waiting chan bool
+	stop    chan struct{}
+}
+
+func (s *idleStream) RecvMsg(m interface{}) error {
+	s.notify(true)
+	err := s.ServerStream.RecvMsg(m)
+	s.notify(false)
+	return err
+}
+
+func (s *idleStream) notify(waiting bool) {
+	select {
+	case s.waiting <- waiting:
+	case <-s.stop:
+	}
+}
+
+// streamRecordInterceptor returns a stream interceptor that records sessions of streams
+// selected by conf and saves them to conf.Dir, to be replayed with emmy replay.
+func streamRecordInterceptor(conf *config.RecordingConfig, logger log.Logger) grpc.StreamServerInterceptor {
+	methods := make(map[string]bool, len(conf.Methods))
+	for _, m := range conf.Methods {
+		methods[m] = true
+	}
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		method := path.Base(info.FullMethod)
+		if len(methods) > 0 && !methods[method] {
+			return handler(srv, ss)
+		}
+
+		r := record.NewRecorder(method, record.Server)
+		err := handler(srv, record.NewServerStream(ss, r))
+		if err != nil {
+			r.Error(err)
+		} else if conf.FailedOnly {
+			return nil
+		}
+
+		if file, saveErr := r.Save(conf.Dir); saveErr != nil {
+			logger.Warningf("cannot save session of %s: %v", info.FullMethod, saveErr)
+		} else {
+			logger.Debugf("saved session of %s to %s", info.FullMethod, file)
+		}
+
+		return err
+	}
+}
+
+// streamTracingInterceptor returns a stream interceptor that traces protocol executions
+// and their rounds with OpenTelemetry, continuing traces started by clients.
+func streamTracingInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		ts, end := tracing.NewServerStream(ss, path.Base(info.FullMethod))
+		err := handler(srv, ts)
+		end(err)
+		return err
+	}
+}
+
+// streamRecoveryInterceptor returns a stream interceptor that turns panics of handlers,
+// such as those caused by malformed messages of clients, into errors with code Internal,
+// so that a single stream cannot bring the server down.
+func streamRecoveryInterceptor(logger log.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Errorf("panic in %s (request %s): %v\n%s", info.FullMethod,
+					RequestID(ss.Context()), r, debug.Stack())
+				err = status.Errorf(codes.Internal, "error when handling %s", info.FullMethod)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+// unaryRecoveryInterceptor is like streamRecoveryInterceptor, but for unary RPCs.
+func unaryRecoveryInterceptor(logger log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Errorf("panic in %s (request %s): %v\n%s", info.FullMethod,
+					RequestID(ctx), r, debug.Stack())
+				err = status.Errorf(codes.Internal, "error when handling %s", info.FullMethod)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	gocrypto "crypto"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+	"github.com/xlab-si/emmy/keys"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Labels of keys that the server takes from a crypto.KeyStore (see UseKeyStore).
+const (
+	KeyLabelCLSecKey       = "cl/seckey"
+	KeyLabelPseudonymsysCA = "pseudonymsys/ca"
+)
+
+// PseudonymsysOrgKeyLabel returns the label of the secret key of organization org in
+// the pseudonym system, of type dlogType (dlog or ecdlog).
+func PseudonymsysOrgKeyLabel(org, dlogType string) string {
+	return "pseudonymsys/" + org + "/" + dlogType
+}
+
+// UseKeyStore makes the server take secret keys of issuers from ks instead of the
+// configuration: the CL secret key, secret keys of organizations in the pseudonym
+// system and the key of the pseudonym system CA, whose certificates are signed by ks.
+// Public keys are still read from the configuration.
+func (s *Server) UseKeyStore(ks crypto.KeyStore) {
+	s.keyStore = ks
+	orgs, err := NewOrgRegistry(LoadOrgKeysFromKeyStore(ks), config.LoadPseudonymsysOrgNames()...)
+	if err != nil {
+		s.Logger.Warningf("Organizations of the pseudonym system not available: %v", err)
+	}
+	s.orgs = orgs
+	s.Logger.Notice("Using key store for secret keys of issuers")
+}
+
+// LoadOrgKeysFromKeyStore returns an OrgKeyLoader that reads secret keys of
+// organizations from ks (see PseudonymsysOrgKeyLabel) and their public keys from the
+// configuration.
+func LoadOrgKeysFromKeyStore(ks crypto.KeyStore) OrgKeyLoader {
+	return func(name string) (*OrgKeys, error) {
+		return loadOrgKeys(config.Default(), name, func(dlogType string) (*pseudsys.SecKey, error) {
+			data, err := ks.Load(PseudonymsysOrgKeyLabel(name, dlogType))
+			if err != nil {
+				return nil, err
+			}
+			return keys.DecodePseudonymsysSecKey(data)
+		})
+	}
+}
+
+// UseCAKeyStore makes the server sign certificates of the pseudonym system CA with
+// the key from ks, instead of the key store given by UseKeyStore or the configuration.
+// It lets a server that only runs the CA (see WithServices) keep its key apart from
+// keys of issuers.
+func (s *Server) UseCAKeyStore(ks crypto.KeyStore) {
+	s.caKeyStore = ks
+	s.Logger.Notice("Using key store for the key of the pseudonym system CA")
+}
+
+// caSigner returns the signer of the pseudonym system CA from the key store of the
+// CA or the key store of the server, or nil when tenant t reads the key of the CA
+// from its configuration.
+func (s *Server) caSigner(t *Tenant) (gocrypto.Signer, error) {
+	ks := s.caKeyStore
+	if ks == nil {
+		ks = s.keyStore
+	}
+	if t != nil || ks == nil {
+		return nil, nil
+	}
+	signer, err := ks.Signer(KeyLabelPseudonymsysCA)
+	if err != nil {
+		s.Logger.Errorf("cannot load key of the CA: %v", err)
+		return nil, status.Error(codes.FailedPrecondition, "key of the CA not available")
+	}
+
+	return signer, nil
+}
+
+// pseudonymsysCA returns the CA of the pseudonym system of tenant t, signing with the
+// key from a key store if the server uses one for the default tenant.
+func (s *Server) pseudonymsysCA(t *Tenant, group *schnorr.Group) (*pseudsys.CA, error) {
+	signer, err := s.caSigner(t)
+	if err != nil {
+		return nil, err
+	}
+	if signer == nil {
+		d := t.config().LoadPseudonymsysCASecret()
+		pubKey := t.config().LoadPseudonymsysCAPubKey()
+		return pseudsys.NewCA(group, d, pubKey), nil
+	}
+
+	return pseudsys.NewCAWithSigner(group, signer), nil
+}
+
+// pseudonymsysCAEC is like pseudonymsysCA, but returns the CA of the pseudonym system
+// in EC arithmetic.
+func (s *Server) pseudonymsysCAEC(t *Tenant, curve ec.Curve) (*ecpseudsys.CA, error) {
+	signer, err := s.caSigner(t)
+	if err != nil {
+		return nil, err
+	}
+	if signer == nil {
+		d := t.config().LoadPseudonymsysCASecret()
+		pubKey := t.config().LoadPseudonymsysCAPubKey()
+		return ecpseudsys.NewCA(d, pubKey, curve), nil
+	}
+
+	return ecpseudsys.NewCAWithSigner(signer, curve), nil
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"path"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics of protocol executions, labelled by the protocol (the name of its stream,
+// e.g. IssueCredential or GenerateNym_EC). They complement gRPC metrics of
+// go-grpc-prometheus with protocol specific ones, and cover protocols run over
+// gRPC-Web as well.
+var (
+	protocolRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "emmy",
+		Name:      "protocol_runs_total",
+		Help:      "Number of completed protocol executions by result (success or failure).",
+	}, []string{"protocol", "result"})
+	protocolVerificationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "emmy",
+		Name:      "protocol_verification_failures_total",
+		Help:      "Number of protocol executions in which verification of a proof failed.",
+	}, []string{"protocol"})
+	protocolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "emmy",
+		Name:      "protocol_duration_seconds",
+		Help:      "Duration of protocol executions.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"protocol"})
+	protocolRoundTrip = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "emmy",
+		Name:      "protocol_round_trip_seconds",
+		Help:      "Time between a message of the server and the client's reply to it.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"protocol"})
+	protocolActiveStreams = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "emmy",
+		Name:      "protocol_active_streams",
+		Help:      "Number of protocol executions in progress.",
+	}, []string{"protocol"})
+)
+
+func init() {
+	prometheus.MustRegister(protocolRuns, protocolVerificationFailures, protocolDuration,
+		protocolRoundTrip, protocolActiveStreams)
+}
+
+// streamMetricsInterceptor returns a stream interceptor that collects metrics of
+// protocol executions. Executions that end with code Unauthenticated are counted as
+// verification failures, as handlers report failed proofs with it. Runs kept in the
+// session store of a cluster are counted when they end or fail.
+func streamMetricsInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		protocol := path.Base(info.FullMethod)
+		active := protocolActiveStreams.WithLabelValues(protocol)
+		active.Inc()
+		defer active.Dec()
+
+		start := time.Now()
+		err := handler(srv, &metricsServerStream{
+			ServerStream: ss,
+			roundTrip:    protocolRoundTrip.WithLabelValues(protocol),
+		})
+		protocolDuration.WithLabelValues(protocol).Observe(time.Since(start).Seconds())
+
+		result := "success"
+		if err != nil {
+			result = "failure"
+			if s, ok := status.FromError(err); ok && s.Code() == codes.Unauthenticated {
+				protocolVerificationFailures.WithLabelValues(protocol).Inc()
+			}
+		}
+		if err != nil || endsRun(ss.Context()) {
+			protocolRuns.WithLabelValues(protocol, result).Inc()
+		}
+
+		return err
+	}
+}
+
+// metricsServerStream wraps a grpc.ServerStream, observing the time between messages
+// sent by the server and the replies of the client.
+type metricsServerStream struct {
+	grpc.ServerStream
+	roundTrip prometheus.Observer
+
+	sync.Mutex
+	sent time.Time // when the last message the client did not reply to yet was sent
+}
+
+func (s *metricsServerStream) SendMsg(m interface{}) error {
+	if err := s.ServerStream.SendMsg(m); err != nil {
+		return err
+	}
+	s.Lock()
+	s.sent = time.Now()
+	s.Unlock()
+
+	return nil
+}
+
+func (s *metricsServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.Lock()
+	if !s.sent.IsZero() {
+		s.roundTrip.Observe(time.Since(s.sent).Seconds())
+		s.sent = time.Time{}
+	}
+	s.Unlock()
+
+	return nil
+}
@@ -0,0 +1,230 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+)
+
+// ErrNonceUsed is returned by nonce stores for nonces that were never issued,
+// have expired or were already used.
+var ErrNonceUsed = errors.New("nonce expired or already used")
+
+// NonceStore keeps nonces that the server issued to clients until they are used
+// or expire. A nonce can be used only once, which prevents clients from replaying
+// messages of earlier protocol executions. Implementations backed by memory
+// (MemNonceStore) and redis (RedisNonceStore) are provided.
+type NonceStore interface {
+	// Put stores nonce n, which can be used until expiresAt.
+	Put(n *big.Int, expiresAt time.Time) error
+
+	// Use removes nonce n from the store, or returns ErrNonceUsed if n is not
+	// in the store or has expired.
+	Use(n *big.Int) error
+
+	// PutNew stores nonce n like Put, unless n is already in the store and has not
+	// expired, in which case it returns ErrNonceUsed. It keeps nonces chosen by
+	// clients, which must not be accepted twice.
+	PutNew(n *big.Int, expiresAt time.Time) error
+}
+
+// MemNonceStore keeps nonces in memory. It is the default nonce store of the
+// server, which suffices unless protocol executions are spread across several
+// servers.
+type MemNonceStore struct {
+	sync.Mutex
+	nonces map[string]time.Time
+}
+
+// NewMemNonceStore returns an empty MemNonceStore.
+func NewMemNonceStore() *MemNonceStore {
+	return &MemNonceStore{
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// Put stores nonce n. Expired nonces are removed at the same time.
+func (m *MemNonceStore) Put(n *big.Int, expiresAt time.Time) error {
+	m.Lock()
+	defer m.Unlock()
+	m.put(n, expiresAt)
+	return nil
+}
+
+// PutNew stores nonce n unless it is already in the store.
+func (m *MemNonceStore) PutNew(n *big.Int, expiresAt time.Time) error {
+	m.Lock()
+	defer m.Unlock()
+	if exp, ok := m.nonces[string(n.Bytes())]; ok && time.Now().Before(exp) {
+		return ErrNonceUsed
+	}
+	m.put(n, expiresAt)
+	return nil
+}
+
+// put removes expired nonces and stores n. It must be called with m locked.
+func (m *MemNonceStore) put(n *big.Int, expiresAt time.Time) {
+	now := time.Now()
+	for k, exp := range m.nonces {
+		if now.After(exp) {
+			delete(m.nonces, k)
+		}
+	}
+	m.nonces[string(n.Bytes())] = expiresAt
+}
+
+// Use removes nonce n from the store.
+func (m *MemNonceStore) Use(n *big.Int) error {
+	m.Lock()
+	defer m.Unlock()
+	key := string(n.Bytes())
+	exp, ok := m.nonces[key]
+	if !ok {
+		return ErrNonceUsed
+	}
+	delete(m.nonces, key)
+	if time.Now().After(exp) {
+		return ErrNonceUsed
+	}
+	return nil
+}
+
+// redisNoncePrefix separates nonces from other data in the database.
+const redisNoncePrefix = "nonce:"
+
+// RedisNonceStore keeps nonces in a redis database, which expires them.
+type RedisNonceStore struct {
+	*redis.Client
+}
+
+func NewRedisNonceStore(c *redis.Client) *RedisNonceStore {
+	return &RedisNonceStore{
+		Client: c,
+	}
+}
+
+// Put stores nonce n.
+func (c *RedisNonceStore) Put(n *big.Int, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return fmt.Errorf("nonce already expired")
+	}
+	return c.Set(redisNoncePrefix+n.Text(16), 1, ttl).Err()
+}
+
+// PutNew stores nonce n unless it is already in the database. Since redis sets keys
+// atomically, n is accepted only once even when the store is shared by several servers.
+func (c *RedisNonceStore) PutNew(n *big.Int, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return fmt.Errorf("nonce already expired")
+	}
+	set, err := c.SetNX(redisNoncePrefix+n.Text(16), 1, ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !set {
+		return ErrNonceUsed
+	}
+	return nil
+}
+
+// Use removes nonce n from the store. Since redis deletes keys atomically, a nonce
+// is used only once even when the store is shared by several servers.
+func (c *RedisNonceStore) Use(n *big.Int) error {
+	deleted, err := c.Del(redisNoncePrefix + n.Text(16)).Result()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNonceUsed
+	}
+	return nil
+}
+
+// UseNonceStore makes the server keep nonces it issues to clients in store, where
+// they are valid for ttl. The server keeps nonces in a MemNonceStore by default.
+func (s *Server) UseNonceStore(store NonceStore, ttl time.Duration) {
+	s.nonces = store
+	s.nonceTTL = ttl
+	s.Logger.Noticef("Nonces are kept in %T", store)
+}
+
+// issueNonce records nonce n before it is sent to a client.
+func (s *Server) issueNonce(n *big.Int) error {
+	if err := s.nonces.Put(n, time.Now().Add(s.nonceTTL)); err != nil {
+		s.Logger.Errorf("cannot store nonce: %v", err)
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"cannot issue nonce")
+	}
+	return nil
+}
+
+// useNIContext checks that context c of a non-interactive proof, verified by the RPC
+// with the given full name, is fresh and was not used before, and returns the value
+// the proof is bound to. Contexts are fresh if created within the nonce TTL of the
+// server, and are kept in the nonce store until they are no longer fresh.
+func (s *Server) useNIContext(c *pb.NIContext, method string) (*big.Int, error) {
+	if c == nil || len(c.Nonce) == 0 {
+		return nil, pb.NewStatusError(codes.InvalidArgument, pb.ErrorCode_INVALID_REQUEST,
+			"missing context of the proof")
+	}
+	created := time.Unix(c.Timestamp, 0)
+	if age := time.Since(created); age > s.nonceTTL || age < -s.nonceTTL {
+		return nil, pb.NewStatusError(codes.DeadlineExceeded, pb.ErrorCode_EXPIRED_NONCE,
+			"proof expired or created in the future")
+	}
+
+	// contexts created up to the TTL in the future are fresh for twice the TTL
+	v := c.Value(method)
+	err := s.nonces.PutNew(v, time.Now().Add(2*s.nonceTTL))
+	if err == ErrNonceUsed {
+		return nil, pb.NewStatusError(codes.DeadlineExceeded, pb.ErrorCode_EXPIRED_NONCE,
+			"proof already used")
+	}
+	if err != nil {
+		s.Logger.Errorf("cannot store context of proof: %v", err)
+		return nil, pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"cannot verify context of proof")
+	}
+	return v, nil
+}
+
+// useNonce consumes nonce n once the client responded to it, so that the
+// response cannot be replayed. It fails if n expired or was already used.
+func (s *Server) useNonce(n *big.Int) error {
+	err := s.nonces.Use(n)
+	if err == ErrNonceUsed {
+		return pb.NewStatusError(codes.DeadlineExceeded, pb.ErrorCode_EXPIRED_NONCE,
+			"nonce expired or already used")
+	}
+	if err != nil {
+		s.Logger.Errorf("cannot use nonce: %v", err)
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"cannot verify nonce")
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/oidc"
+)
+
+// EnableOIDC makes the server authorize relying parties to exchange session keys
+// of successfully authenticated users for tokens issued by p.
+func (s *Server) EnableOIDC(p *oidc.Provider) {
+	s.oidcProvider = p
+	s.Logger.Noticef("Enabled OpenID Connect bridge with issuer %s", p.Issuer())
+}
+
+// IssueIDTokens makes the server issue ID tokens for audience along with session keys
+// of successfully authenticated users, so that services relying on the OpenID Connect
+// bridge (see EnableOIDC) can authorize users without exchanging session keys. Tokens
+// can be verified with keys published at the JWKS endpoint of the bridge.
+func (s *Server) IssueIDTokens(audience string) {
+	s.idTokenAudience = audience
+	s.Logger.Noticef("Issuing ID tokens for audience %s along with session keys", audience)
+}
+
+// issueIDToken returns an ID token with claims about the user holding sessionKey, or
+// an empty string when the server does not issue ID tokens directly.
+func (s *Server) issueIDToken(sessionKey string, claims map[string]interface{}) (string,
+	error) {
+	if s.oidcProvider == nil || s.idTokenAudience == "" {
+		return "", nil
+	}
+
+	return s.oidcProvider.IDToken(sessionKey, claims, s.idTokenAudience)
+}
+
+// authorizeOIDC passes claims about the user holding sessionKey to the OpenID Connect
+// bridge, if it is enabled.
+func (s *Server) authorizeOIDC(sessionKey string, claims map[string]interface{}) {
+	if s.oidcProvider != nil {
+		s.oidcProvider.Authorize(sessionKey, claims)
+	}
+}
+
+// revealedAttrsClaims returns names and values of revealed known attributes of a CL
+// credential, according to the configured credential structure. Committed attributes
+// proved to lie in a range are claimed with bounds of the range, as
+// {"min": A, "max": B}, and strings that were hashed (see cl.AttrEncoder) with their
+// encoding in hexadecimal, as {"hash": H}.
+func revealedAttrsClaims(revealedKnownAttrsIndices []int, revealedKnownAttrs []*big.Int,
+	rangeProofs []*cl.AttrRangeProof) (map[string]interface{}, error) {
+	structure, err := config.LoadCredentialStructure()
+	if err != nil {
+		return nil, err
+	}
+	attrs, _, err := cl.ParseAttrs(structure)
+	if err != nil {
+		return nil, err
+	}
+
+	var known, committed []cl.CredAttr
+	for _, a := range attrs {
+		if a.IsKnown() {
+			known = append(known, a)
+		} else {
+			committed = append(committed, a)
+		}
+	}
+
+	claims := make(map[string]interface{}, len(revealedKnownAttrsIndices))
+	for i, idx := range revealedKnownAttrsIndices {
+		if idx < 0 || idx >= len(known) || i >= len(revealedKnownAttrs) {
+			return nil, fmt.Errorf("revealed attribute %d is not in credential structure", idx)
+		}
+		val, err := known[idx].FromInternalValue(revealedKnownAttrs[i])
+		if err == cl.ErrHashedAttr {
+			val = map[string]interface{}{"hash": revealedKnownAttrs[i].Text(16)}
+		} else if err != nil {
+			return nil, err
+		}
+		claims[known[idx].GetName()] = val
+	}
+	for _, p := range rangeProofs {
+		if p.Index < 0 || p.Index >= len(committed) {
+			return nil, fmt.Errorf("committed attribute %d is not in credential structure",
+				p.Index)
+		}
+		claims[committed[p.Index].GetName()] = map[string]interface{}{
+			"min": p.A.Int64(),
+			"max": p.B.Int64(),
+		}
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/log"
+	"google.golang.org/grpc"
+)
+
+// ServerOption configures a Server created by New.
+type ServerOption func(*serverOptions)
+
+// serverOptions holds settings of a Server given by options.
+type serverOptions struct {
+	certFile, keyFile  string
+	clientCAFile       string
+	tlsConfig          *tls.Config
+	insecure           bool
+	regMgr             RegistrationManager
+	recMgr             cl.ReceiverRecordManager
+	sessionStore       SessionStore
+	sessionTTL         time.Duration
+	logger             log.Logger
+	maxRecvMsgSize     int
+	maxSendMsgSize     int
+	streamInterceptors []grpc.StreamServerInterceptor
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	services           []string
+}
+
+// newServerOptions returns settings given by opts, with defaults from the network
+// section of the configuration for those that are not given.
+func newServerOptions(opts []ServerOption) (*serverOptions, error) {
+	netConf := config.LoadNetworkConfig()
+	o := &serverOptions{
+		certFile:       netConf.TLS.CertFile,
+		keyFile:        netConf.TLS.KeyFile,
+		clientCAFile:   netConf.TLS.ClientCAFile,
+		maxRecvMsgSize: netConf.Limits.MaxRecvMsgSize,
+		maxSendMsgSize: netConf.Limits.MaxSendMsgSize,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.logger == nil {
+		logger, err := log.NewStdoutLogger("server", log.NOTICE, log.FORMAT_LONG)
+		if err != nil {
+			return nil, err
+		}
+		o.logger = logger
+	}
+	if o.regMgr == nil {
+		o.regMgr = NewMemRegistrationManager()
+	}
+	if o.recMgr == nil {
+		o.recMgr = cl.NewMockRecordManager()
+	}
+	return o, nil
+}
+
+// WithTLS makes the server authenticate with certificate certFile and private key
+// keyFile, both in PEM format. By default, those of section network.tls of the
+// configuration are used.
+func WithTLS(certFile, keyFile string) ServerOption {
+	return func(o *serverOptions) {
+		o.certFile, o.keyFile = certFile, keyFile
+	}
+}
+
+// WithClientCAs makes the server require clients to authenticate with a certificate
+// issued by one of the CAs in clientCAFile, a bundle of certificates in PEM format.
+// When clientCAFile is empty, client certificates are not requested.
+func WithClientCAs(clientCAFile string) ServerOption {
+	return func(o *serverOptions) {
+		o.clientCAFile = clientCAFile
+	}
+}
+
+// WithTLSConfig makes the server use TLS configuration c, which takes precedence
+// over WithTLS and WithClientCAs.
+func WithTLSConfig(c *tls.Config) ServerOption {
+	return func(o *serverOptions) {
+		o.tlsConfig = c
+	}
+}
+
+// WithInsecure makes the server communicate with clients without TLS, which is only
+// meant for development and tests. Such servers only listen on the local host (see
+// Start) or on UNIX domain sockets (see StartUnix).
+func WithInsecure() ServerOption {
+	return func(o *serverOptions) {
+		o.insecure = true
+	}
+}
+
+// WithRegistrationManager makes the server check registration keys of clients with
+// m. By default, keys are kept in memory (see MemRegistrationManager).
+func WithRegistrationManager(m RegistrationManager) ServerOption {
+	return func(o *serverOptions) {
+		o.regMgr = m
+	}
+}
+
+// WithRecordManager makes the server keep receiver records of CL credentials with m.
+// By default, records are kept in memory.
+func WithRecordManager(m cl.ReceiverRecordManager) ServerOption {
+	return func(o *serverOptions) {
+		o.recMgr = m
+	}
+}
+
+// WithSessionStore makes the server keep sessions in store (see UseSessionStore).
+func WithSessionStore(store SessionStore, ttl time.Duration) ServerOption {
+	return func(o *serverOptions) {
+		o.sessionStore, o.sessionTTL = store, ttl
+	}
+}
+
+// WithLogger makes the server log with logger. By default, the server logs to
+// standard output at level NOTICE.
+func WithLogger(logger log.Logger) ServerOption {
+	return func(o *serverOptions) {
+		o.logger = logger
+	}
+}
+
+// WithMaxMsgSize sets the maximum sizes in bytes of messages that the server receives
+// and sends, which are otherwise read from section network.limits of the configuration.
+func WithMaxMsgSize(recv, send int) ServerOption {
+	return func(o *serverOptions) {
+		o.maxRecvMsgSize, o.maxSendMsgSize = recv, send
+	}
+}
+
+// WithStreamInterceptors adds interceptors (e.g. for authentication or logging) to
+// protocol streams of the server, in the given order. They run after the server
+// assigned request IDs and enforced limits, and before it selects the tenant and
+// runs the protocol. Streams of gRPC-Web, the gateway and the Steps service are
+// intercepted as well.
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) ServerOption {
+	return func(o *serverOptions) {
+		o.streamInterceptors = append(o.streamInterceptors, interceptors...)
+	}
+}
+
+// WithUnaryInterceptors adds interceptors to unary RPCs of the server, in the given
+// order. They run after the server assigned request IDs.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) ServerOption {
+	return func(o *serverOptions) {
+		o.unaryInterceptors = append(o.unaryInterceptors, interceptors...)
+	}
+}
+
+// WithServices makes the server provide only the given gRPC services of emmy, named
+// as in services.proto (for example PseudonymSystemCA), along with service Info.
+// This way, the pseudonym system CA can be run on a host of its own, apart from the
+// organizations. By default, all services are provided.
+func WithServices(services ...string) ServerOption {
+	return func(o *serverOptions) {
+		o.services = append(o.services, services...)
+	}
+}
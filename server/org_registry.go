@@ -0,0 +1,235 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/ecpseudsys"
+	"github.com/xlab-si/emmy/crypto/pseudsys"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrUnknownOrg is returned for organizations that are not held by an OrgRegistry.
+var ErrUnknownOrg = errors.New("unknown organization")
+
+// OrgKeys holds keys of an organization in the pseudonym system. Keys of a type
+// (modular or EC arithmetic) the organization does not use are nil.
+type OrgKeys struct {
+	SecKey   *pseudsys.SecKey
+	PubKey   *pseudsys.PubKey
+	SecKeyEC *pseudsys.SecKey
+	PubKeyEC *ecpseudsys.PubKey
+	// Accepts holds names of organizations whose credentials the organization derives
+	// its credentials from (see pseudsys.ChainLink). When set, the organization issues
+	// credentials in modular arithmetic only to users proving a credential of one of
+	// them.
+	Accepts []string
+}
+
+// OrgKeyLoader loads keys of organization name.
+type OrgKeyLoader func(name string) (*OrgKeys, error)
+
+// OrgRegistry holds keys of organizations in the pseudonym system that the server
+// issues and verifies credentials for. Requests select an organization by its name,
+// and the first organization the registry was created with is used when they do not
+// name one. Keys can be reloaded while the server is running.
+type OrgRegistry struct {
+	load       OrgKeyLoader
+	names      []string
+	defaultOrg string
+	keys       map[string]*OrgKeys
+
+	sync.RWMutex
+}
+
+// NewOrgRegistry creates a registry of organizations names, loading their keys
+// with load.
+func NewOrgRegistry(load OrgKeyLoader, names ...string) (*OrgRegistry, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no organizations")
+	}
+	r := &OrgRegistry{
+		load:       load,
+		names:      names,
+		defaultOrg: names[0],
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// NewOrgRegistryFromConfig creates a registry of organizations in the pseudonym
+// system of the configuration, loading their keys from the configuration.
+func NewOrgRegistryFromConfig() (*OrgRegistry, error) {
+	return newOrgRegistry(config.Default())
+}
+
+// newOrgRegistry creates a registry of organizations in the pseudonym system of conf,
+// loading their keys from conf.
+func newOrgRegistry(conf *config.Config) (*OrgRegistry, error) {
+	return NewOrgRegistry(orgKeysFromConfig(conf), conf.LoadPseudonymsysOrgNames()...)
+}
+
+// LoadOrgKeysFromConfig is an OrgKeyLoader reading keys of organizations from the
+// configuration. Keys held in files (see config.LoadPseudonymsysOrgSecrets) are read
+// again on each call.
+func LoadOrgKeysFromConfig(name string) (*OrgKeys, error) {
+	return orgKeysFromConfig(config.Default())(name)
+}
+
+// orgKeysFromConfig returns an OrgKeyLoader reading keys of organizations from conf.
+func orgKeysFromConfig(conf *config.Config) OrgKeyLoader {
+	return func(name string) (*OrgKeys, error) {
+		return loadOrgKeys(conf, name, func(dlogType string) (*pseudsys.SecKey, error) {
+			return conf.LoadPseudonymsysOrgSecrets(name, dlogType), nil
+		})
+	}
+}
+
+// loadOrgKeys loads keys of organization name, reading its secret keys of each type
+// (dlog or ecdlog) with secKey and its public keys from conf.
+func loadOrgKeys(conf *config.Config, name string,
+	secKey func(dlogType string) (*pseudsys.SecKey, error)) (keys *OrgKeys, err error) {
+	// the configuration panics on missing or malformed keys
+	defer func() {
+		if r := recover(); r != nil {
+			keys, err = nil, fmt.Errorf("error when loading keys of %s: %v", name, r)
+		}
+	}()
+
+	keys = new(OrgKeys)
+	if conf.HasPseudonymsysOrgKeys(name, "dlog") {
+		if keys.SecKey, err = secKey("dlog"); err != nil {
+			return nil, fmt.Errorf("error when loading secret key of %s: %v", name, err)
+		}
+		keys.PubKey = conf.LoadPseudonymsysOrgPubKeys(name)
+	}
+	if conf.HasPseudonymsysOrgKeys(name, "ecdlog") {
+		if keys.SecKeyEC, err = secKey("ecdlog"); err != nil {
+			return nil, fmt.Errorf("error when loading secret key of %s: %v", name, err)
+		}
+		keys.PubKeyEC = conf.LoadPseudonymsysOrgPubKeysEC(name)
+	}
+	if keys.SecKey == nil && keys.SecKeyEC == nil {
+		return nil, fmt.Errorf("no keys of %s", name)
+	}
+	keys.Accepts = conf.LoadPseudonymsysOrgAccepts(name)
+
+	return keys, nil
+}
+
+// Reload loads keys of all the organizations again. If loading of any keys fails,
+// the registry keeps the previous keys.
+func (r *OrgRegistry) Reload() error {
+	return r.ReloadNames(r.Names()...)
+}
+
+// ReloadNames replaces organizations of the registry with names, the first of which
+// becomes the default one, and loads their keys. If loading of any keys fails, the
+// registry keeps the previous organizations and keys.
+func (r *OrgRegistry) ReloadNames(names ...string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("no organizations")
+	}
+	keys := make(map[string]*OrgKeys, len(names))
+	for _, name := range names {
+		k, err := r.load(name)
+		if err != nil {
+			return err
+		}
+		keys[name] = k
+	}
+
+	r.Lock()
+	r.names = names
+	r.defaultOrg = names[0]
+	r.keys = keys
+	r.Unlock()
+
+	return nil
+}
+
+// Names returns names of the organizations of the registry.
+func (r *OrgRegistry) Names() []string {
+	r.RLock()
+	defer r.RUnlock()
+	return append([]string{}, r.names...)
+}
+
+// Keys returns keys of organization name, or of the default organization when name
+// is empty.
+func (r *OrgRegistry) Keys(name string) (*OrgKeys, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if name == "" {
+		name = r.defaultOrg
+	}
+	k, ok := r.keys[name]
+	if !ok {
+		return nil, ErrUnknownOrg
+	}
+
+	return k, nil
+}
+
+// UseOrgRegistry sets the registry of organizations the server issues and verifies
+// pseudonym system credentials for, replacing the one read from the configuration.
+func (s *Server) UseOrgRegistry(r *OrgRegistry) {
+	s.orgs = r
+}
+
+// ReloadOrgs reloads keys of organizations of the pseudonym system, for example after
+// their key files were replaced.
+func (s *Server) ReloadOrgs() error {
+	if s.orgs == nil {
+		return fmt.Errorf("no organizations")
+	}
+	return s.orgs.Reload()
+}
+
+// orgKeys returns keys of organization name (the default one when empty) of tenant t
+// for modular or EC arithmetic, or an error to be returned to the client.
+func (s *Server) orgKeys(t *Tenant, name string, ec bool) (*OrgKeys, error) {
+	orgs := s.orgs
+	if t != nil {
+		orgs = t.orgs
+	}
+	if orgs == nil {
+		return nil, pb.NewStatusError(codes.FailedPrecondition, pb.ErrorCode_UNKNOWN_ORG,
+			"no organizations")
+	}
+	keys, err := orgs.Keys(name)
+	if err != nil {
+		return nil, pb.NewStatusError(codes.NotFound, pb.ErrorCode_UNKNOWN_ORG,
+			fmt.Sprintf("unknown organization %s", name))
+	}
+	if (!ec && keys.PubKey == nil) || (ec && keys.PubKeyEC == nil) {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"organization %s has no keys for this arithmetic", name)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+)
+
+// loadPolicies returns policies configured in conf (see config.LoadPolicies), with
+// predicates about attributes of the credential structure configured there.
+func loadPolicies(conf *config.Config) ([]*cl.Policy, error) {
+	configured, err := conf.LoadPolicies()
+	if err != nil || len(configured) == 0 {
+		return nil, err
+	}
+	rc, err := configuredRawCred(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]*cl.Policy, len(configured))
+	for i, c := range configured {
+		backend, err := cl.ParseRangeProofBackend(c.RangeProofs)
+		if err != nil {
+			return nil, fmt.Errorf("policy %s: %v", c.Name, err)
+		}
+		p := &cl.Policy{
+			Name:        c.Name,
+			Issuers:     c.Issuers,
+			Revealed:    c.Revealed,
+			MaxAge:      c.MaxAge,
+			RangeProofs: backend,
+		}
+		for _, pc := range c.Predicates {
+			pred, err := cl.ParsePredicate(rc, cl.PredicateType(pc.Type), pc.Attr, pc.Values)
+			if err != nil {
+				return nil, fmt.Errorf("policy %s: %v", c.Name, err)
+			}
+			p.Predicates = append(p.Predicates, pred)
+		}
+		if err := cl.ResolvePredicates(rc, p.AllPredicates()); err != nil {
+			return nil, fmt.Errorf("policy %s: %v", c.Name, err)
+		}
+		policies[i] = p
+	}
+
+	return policies, nil
+}
+
+// checkPolicies checks that a proved credential, issued under the CL key with ID
+// keyID, satisfies at least one of the policies configured in conf, given its
+// revealed known attributes and verified range proofs. All proofs are accepted
+// when no policies are configured.
+func (s *Server) checkPolicies(conf *config.Config, keyID string, revealedKnownAttrsIndices []int,
+	revealedKnownAttrs []*big.Int, rangeProofs []*cl.AttrRangeProof) error {
+	policies, err := loadPolicies(conf)
+	if err != nil {
+		s.Logger.Debug(err)
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"failed to load policies")
+	}
+	if len(policies) == 0 {
+		return nil
+	}
+	rc, err := configuredRawCred(conf)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, p := range policies {
+		err := p.Check(rc, keyID, revealedKnownAttrsIndices, revealedKnownAttrs,
+			rangeProofs, now)
+		if err == nil {
+			return nil
+		}
+		s.Logger.Debugf("policy %s not satisfied: %v", p.Name, err)
+	}
+	return pb.NewStatusError(codes.PermissionDenied, pb.ErrorCode_POLICY_NOT_SATISFIED,
+		"credential does not satisfy any policy")
+}
+
+// checkRequestedPredicates checks that predicates, which the prover claims to show,
+// are those requested by one of the policies configured in conf. Predicates are only
+// checked against proofs as parts of policies (see checkPolicies), so provers cannot
+// choose predicates that their credentials satisfy.
+func (s *Server) checkRequestedPredicates(conf *config.Config, predicates []*pb.CLPredicate) error {
+	if len(predicates) == 0 {
+		return nil
+	}
+	policies, err := loadPolicies(conf)
+	if err != nil {
+		s.Logger.Debug(err)
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"failed to load policies")
+	}
+
+	preds := make([]*cl.Predicate, len(predicates))
+	for i, p := range predicates {
+		if preds[i], err = p.GetNativeType(); err != nil {
+			return pb.NewInvalidValueError(err)
+		}
+	}
+	for _, p := range policies {
+		if p.Requests(preds) {
+			return nil
+		}
+	}
+	return pb.NewStatusError(codes.PermissionDenied, pb.ErrorCode_POLICY_NOT_SATISFIED,
+		"predicates are not requested by any policy")
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+)
+
+// validatePseudonymsysParams checks that the group and the curve of the pseudonym
+// system can be loaded from conf, so that invalid parameters are reported when the
+// configuration is loaded rather than when clients run protocols.
+func validatePseudonymsysParams(conf *config.Config) error {
+	if _, err := conf.LoadGroup("pseudonymsys"); err != nil {
+		return fmt.Errorf("group of the pseudonym system: %v", err)
+	}
+	if _, err := conf.LoadCurve("pseudonymsys"); err != nil {
+		return fmt.Errorf("curve of the pseudonym system: %v", err)
+	}
+
+	return nil
+}
+
+// pseudonymsysGroup returns the group of the pseudonym system configured for tenant t,
+// after checking that it is the one the client uses according to the profile in the
+// first message of the protocol, req.
+func pseudonymsysGroup(t *Tenant, req *pb.Message) (*schnorr.Group, error) {
+	group, err := t.config().LoadGroup("pseudonymsys")
+	if err != nil {
+		return nil, err
+	}
+	if err := checkProfile(req.Profile, schnorr.GroupPresetName(group)); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// pseudonymsysCurve is like pseudonymsysGroup, but returns the curve of the pseudonym
+// system in EC arithmetic.
+func pseudonymsysCurve(t *Tenant, req *pb.Message) (ec.Curve, error) {
+	curve, err := t.config().LoadCurve("pseudonymsys")
+	if err != nil {
+		return 0, err
+	}
+	if err := checkProfile(req.Profile, ec.CurvePresetName(curve)); err != nil {
+		return 0, err
+	}
+
+	return curve, nil
+}
+
+// checkProfile returns an error if the profile requested by the client is not the
+// profile used by the server. Clients that do not request a profile, such as clients
+// using group parameters that do not belong to a profile, are not checked.
+func checkProfile(requested, profile string) error {
+	if requested == "" || requested == profile {
+		return nil
+	}
+	if profile == "" {
+		profile = "custom parameters"
+	}
+
+	return pb.NewStatusError(codes.FailedPrecondition, pb.ErrorCode_UNSUPPORTED_PROFILE,
+		fmt.Sprintf("parameter profile %s is not supported, the server uses %s",
+			requested, profile))
+}
@@ -18,14 +18,15 @@
 package server
 
 import (
+	"context"
+	"fmt"
 	"math/big"
 
-	"github.com/xlab-si/emmy/config"
 	"github.com/xlab-si/emmy/crypto/pseudsys"
 	"github.com/xlab-si/emmy/crypto/schnorr"
 	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/tracing"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 func (s *Server) GenerateNym(stream pb.PseudonymSystem_GenerateNymServer) error {
@@ -33,35 +34,47 @@ func (s *Server) GenerateNym(stream pb.PseudonymSystem_GenerateNymServer) error
 	if err != nil {
 		return err
 	}
+	t, err := s.tenant(stream.Context())
+	if err != nil {
+		return err
+	}
 
-	group := config.LoadSchnorrGroup()
-	caPubKey := config.LoadPseudonymsysCAPubKey()
+	group, err := pseudonymsysGroup(t, req)
+	if err != nil {
+		return err
+	}
+	caPubKey := t.config().LoadPseudonymsysCAPubKey()
 	org := pseudsys.NewNymGenerator(group, caPubKey)
 
 	proofRandData := req.GetPseudonymsysNymGenProofRandomData()
-	x1 := new(big.Int).SetBytes(proofRandData.X1)
-	nymA := new(big.Int).SetBytes(proofRandData.A1)
-	nymB := new(big.Int).SetBytes(proofRandData.B1)
-	x2 := new(big.Int).SetBytes(proofRandData.X2)
-	blindedA := new(big.Int).SetBytes(proofRandData.A2)
-	blindedB := new(big.Int).SetBytes(proofRandData.B2)
-	signatureR := new(big.Int).SetBytes(proofRandData.R)
-	signatureS := new(big.Int).SetBytes(proofRandData.S)
+	d := pb.NewSchnorrDecoder(group)
+	x1 := d.Element("X1", proofRandData.GetX1())
+	nymA := d.Element("A1", proofRandData.GetA1())
+	nymB := d.Element("B1", proofRandData.GetB1())
+	x2 := d.Element("X2", proofRandData.GetX2())
+	blindedA := d.Element("A2", proofRandData.GetA2())
+	blindedB := d.Element("B2", proofRandData.GetB2())
+	signatureR := d.Int("R", proofRandData.GetR())
+	signatureS := d.Int("S", proofRandData.GetS())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
 
-	regKeyOk, err := s.RegistrationManager.CheckRegistrationKey(proofRandData.RegKey)
+	regKeyOk, err := s.RegistrationManager.CheckRegistrationKey(proofRandData.GetRegKey())
 
 	var resp *pb.Message
 
 	if !regKeyOk || err != nil {
 		s.Logger.Debugf("registration key %s ok=%t, error=%v",
-			proofRandData.RegKey, regKeyOk, err)
-		return status.Error(codes.NotFound, "registration key verification failed")
+			proofRandData.GetRegKey(), regKeyOk, err)
+		return pb.NewStatusError(codes.NotFound, pb.ErrorCode_INVALID_REG_KEY,
+			"registration key verification failed")
 	}
 
 	challenge, err := org.GetChallenge(nymA, blindedA, nymB, blindedB, x1, x2, signatureR, signatureS)
 	if err != nil {
 		s.Logger.Debug(err)
-		return status.Error(codes.Internal, err.Error())
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INVALID_PROOF, err.Error())
 
 	}
 	resp = &pb.Message{
@@ -82,8 +95,13 @@ func (s *Server) GenerateNym(stream pb.PseudonymSystem_GenerateNymServer) error
 	}
 
 	proofData := req.GetSchnorrProofData() // SchnorrProofData is used in DLog equality proof as well
-	z := new(big.Int).SetBytes(proofData.Z)
+	z := d.Exponent("Z", proofData.GetZ())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+	_, span := tracing.StartSpan(stream.Context(), "pseudsys.NymGenerator.Verify")
 	valid := org.Verify(z)
+	span.End()
 
 	resp = &pb.Message{
 		Content: &pb.Message_Status{&pb.Status{Success: valid}},
@@ -96,20 +114,100 @@ func (s *Server) GenerateNym(stream pb.PseudonymSystem_GenerateNymServer) error
 	return nil
 }
 
+// GenerateNymNI registers a nym with a non-interactive proof, in place of the
+// GenerateNym stream.
+func (s *Server) GenerateNymNI(ctx context.Context,
+	req *pb.PseudonymsysNymGenProofNI) (*pb.Status, error) {
+	proof, err := req.GetNativeType()
+	if err != nil {
+		return nil, pb.NewInvalidValueError(err)
+	}
+	t, err := s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	niContext, err := s.useNIContext(req.Context, pb.GenerateNymNIMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := t.config().LoadGroup("pseudonymsys")
+	if err != nil {
+		return nil, err
+	}
+	caPubKey := t.config().LoadPseudonymsysCAPubKey()
+	org := pseudsys.NewNymGenerator(group, caPubKey)
+
+	data := req.Data
+	d := pb.NewSchnorrDecoder(group)
+	nymA := d.Element("A1", data.A1)
+	blindedA := d.Element("A2", data.A2)
+	nymB := d.Element("B1", data.B1)
+	blindedB := d.Element("B2", data.B2)
+	signatureR := d.Int("R", data.R)
+	signatureS := d.Int("S", data.S)
+	// values of the proof itself need to belong to the group as well
+	d.Element("X1", data.X1)
+	d.Element("X2", data.X2)
+	d.Exponent("Z", req.Z)
+	if err := d.Err(); err != nil {
+		return nil, pb.NewInvalidValueError(err)
+	}
+
+	// the proof is verified first, so that invalid proofs do not consume
+	// registration keys
+	_, span := tracing.StartSpan(ctx, "pseudsys.NymGenerator.VerifyNI")
+	err = org.VerifyNI(nymA, blindedA, nymB, blindedB, signatureR, signatureS, proof,
+		niContext)
+	tracing.End(span, err)
+	if err != nil {
+		s.Logger.Debug(err)
+		return nil, pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_PROOF,
+			err.Error())
+	}
+
+	regKeyOk, err := s.RegistrationManager.CheckRegistrationKey(data.RegKey)
+	if !regKeyOk || err != nil {
+		s.Logger.Debugf("registration key %s ok=%t, error=%v", data.RegKey, regKeyOk, err)
+		return nil, pb.NewStatusError(codes.NotFound, pb.ErrorCode_INVALID_REG_KEY,
+			"registration key verification failed")
+	}
+
+	return &pb.Status{Success: true}, nil
+}
+
 func (s *Server) ObtainCredential(stream pb.PseudonymSystem_ObtainCredentialServer) error {
 	req, err := s.receive(stream)
 	if err != nil {
 		return err
 	}
+	t, err := s.tenant(stream.Context())
+	if err != nil {
+		return err
+	}
 
-	group := config.LoadSchnorrGroup()
-	secKey := config.LoadPseudonymsysOrgSecrets("org1", "dlog")
-	org := pseudsys.NewCredIssuer(group, secKey)
-
+	group, err := pseudonymsysGroup(t, req)
+	if err != nil {
+		return err
+	}
 	sProofRandData := req.GetSchnorrProofRandomData()
-	x := new(big.Int).SetBytes(sProofRandData.X)
-	a := new(big.Int).SetBytes(sProofRandData.A)
-	b := new(big.Int).SetBytes(sProofRandData.B)
+	keys, err := s.orgKeys(t, sProofRandData.GetOrgName(), false)
+	if err != nil {
+		return err
+	}
+	org := pseudsys.NewCredIssuer(group, keys.SecKey)
+
+	d := pb.NewSchnorrDecoder(group)
+	x := d.Element("X", sProofRandData.GetX())
+	a := d.Element("A", sProofRandData.GetA())
+	b := d.Element("B", sProofRandData.GetB())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+	chain, err := s.decodeChain(t, group, keys, sProofRandData.GetChain())
+	if err != nil {
+		return err
+	}
 	challenge := org.GetChallenge(a, b, x)
 
 	resp := &pb.Message{
@@ -129,13 +227,22 @@ func (s *Server) ObtainCredential(stream pb.PseudonymSystem_ObtainCredentialServ
 		return err
 	}
 
-	proofData := req.GetBigint()
-	z := new(big.Int).SetBytes(proofData.X1)
+	z := d.Exponent("Z", req.GetBigint().GetX1())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+
+	if !chain.verify(group, challenge, z) {
+		return pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_PROOF,
+			"credentials the credential is derived from were not verified")
+	}
 
+	_, span := tracing.StartSpan(stream.Context(), "pseudsys.CredIssuer.Verify")
 	x11, x12, x21, x22, A, B, err := org.Verify(z)
+	tracing.End(span, err)
 	if err != nil {
 		s.Logger.Debug(err)
-		return status.Error(codes.Internal, err.Error())
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INVALID_PROOF, err.Error())
 	}
 	resp = &pb.Message{
 		Content: &pb.Message_PseudonymsysIssueProofRandomData{
@@ -160,8 +267,11 @@ func (s *Server) ObtainCredential(stream pb.PseudonymSystem_ObtainCredentialServ
 	}
 
 	challenges := req.GetDoubleBigint()
-	challenge1 := new(big.Int).SetBytes(challenges.X1)
-	challenge2 := new(big.Int).SetBytes(challenges.X2)
+	challenge1 := d.Exponent("challenge1", challenges.GetX1())
+	challenge2 := d.Exponent("challenge2", challenges.GetX2())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
 
 	z1, z2 := org.GetProofData(challenge1, challenge2)
 	resp = &pb.Message{
@@ -185,42 +295,52 @@ func (s *Server) TransferCredential(stream pb.PseudonymSystem_TransferCredential
 	if err != nil {
 		return err
 	}
+	t, err := s.tenant(stream.Context())
+	if err != nil {
+		return err
+	}
 
-	group := config.LoadSchnorrGroup()
-	secKey := config.LoadPseudonymsysOrgSecrets("org1", "dlog")
-	org := pseudsys.NewCredVerifier(group, secKey)
-
+	group, err := pseudonymsysGroup(t, req)
+	if err != nil {
+		return err
+	}
 	data := req.GetPseudonymsysTransferCredentialData()
-	orgName := data.OrgName
-	x1 := new(big.Int).SetBytes(data.X1)
-	x2 := new(big.Int).SetBytes(data.X2)
-	nymA := new(big.Int).SetBytes(data.NymA)
-	nymB := new(big.Int).SetBytes(data.NymB)
-
-	t1 := schnorr.NewBlindedTrans(
-		new(big.Int).SetBytes(data.Credential.T1.A),
-		new(big.Int).SetBytes(data.Credential.T1.B),
-		new(big.Int).SetBytes(data.Credential.T1.Hash),
-		new(big.Int).SetBytes(data.Credential.T1.ZAlpha),
-	)
-
-	t2 := schnorr.NewBlindedTrans(
-		new(big.Int).SetBytes(data.Credential.T2.A),
-		new(big.Int).SetBytes(data.Credential.T2.B),
-		new(big.Int).SetBytes(data.Credential.T2.Hash),
-		new(big.Int).SetBytes(data.Credential.T2.ZAlpha),
-	)
-
-	credential := pseudsys.NewCred(
-		new(big.Int).SetBytes(data.Credential.SmallAToGamma),
-		new(big.Int).SetBytes(data.Credential.SmallBToGamma),
-		new(big.Int).SetBytes(data.Credential.AToGamma),
-		new(big.Int).SetBytes(data.Credential.BToGamma),
-		t1, t2,
-	)
+	orgName := data.GetOrgName()
+	keys, err := s.orgKeys(t, data.GetTargetOrgName(), false)
+	if err != nil {
+		return err
+	}
+	org := pseudsys.NewCredVerifier(group, keys.SecKey)
+	d := pb.NewSchnorrDecoder(group)
+	x1 := d.Element("X1", data.GetX1())
+	x2 := d.Element("X2", data.GetX2())
+	nymA := d.Element("nym A", data.GetNymA())
+	nymB := d.Element("nym B", data.GetNymB())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+	credential, err := data.GetCredential().GetNativeType(group)
+	if err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+	// PubKeys of the organization that issue a credential:
+	issuerKeys, err := s.orgKeys(t, orgName, false)
+	if err != nil {
+		return err
+	}
+	orgPubKeys := issuerKeys.PubKey
+	chain, err := s.decodeChain(t, group, issuerKeys, data.GetChain())
+	if err != nil {
+		return err
+	}
 
 	challenge := org.GetChallenge(nymA, nymB,
 		credential.SmallAToGamma, credential.SmallBToGamma, x1, x2)
+	if t.config().LoadPseudonymsysRequirePossession() {
+		if err := s.verifyPossession(org, data); err != nil {
+			return err
+		}
+	}
 
 	resp := &pb.Message{
 		Content: &pb.Message_Bigint{
@@ -239,28 +359,34 @@ func (s *Server) TransferCredential(stream pb.PseudonymSystem_TransferCredential
 		return err
 	}
 
-	// PubKeys of the organization that issue a credential:
-	orgPubKeys := config.LoadPseudonymsysOrgPubKeys(orgName)
-
-	proofData := req.GetBigint()
-	z := new(big.Int).SetBytes(proofData.X1)
+	z := d.Exponent("Z", req.GetBigint().GetX1())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
 
-	if verified := org.Verify(z, credential, orgPubKeys); !verified {
+	_, span := tracing.StartSpan(stream.Context(), "pseudsys.CredVerifier.Verify")
+	verified := org.Verify(z, credential, orgPubKeys) && chain.verify(group, challenge, z)
+	span.End()
+	if !verified {
 		s.Logger.Debug("User authentication failed")
-		return status.Error(codes.Unauthenticated, "user authentication failed")
+		return pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_PROOF,
+			"user authentication failed")
 	}
 
-	sessionKey, err := s.GenerateSessionKey()
+	attrs := map[string]interface{}{"org": orgName}
+	if len(chain.links) > 0 {
+		attrs["chain"] = chain.issuers()
+	}
+	sessionKey, err := s.startSession(t, attrs)
 	if err != nil {
 		s.Logger.Debug(err)
-		return status.Error(codes.Internal, "failed to obtain session key")
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"failed to obtain session key")
 	}
 
 	resp = &pb.Message{
 		Content: &pb.Message_SessionKey{
-			SessionKey: &pb.SessionKey{
-				Value: *sessionKey,
-			},
+			SessionKey: sessionKey,
 		},
 	}
 
@@ -270,3 +396,112 @@ func (s *Server) TransferCredential(stream pb.PseudonymSystem_TransferCredential
 
 	return nil
 }
+
+// verifyPossession verifies the proof of possession of the secret of the nym that a
+// credential is transferred with, which needs to be bound to a fresh context naming
+// the organization the credential is transferred to.
+func (s *Server) verifyPossession(org *pseudsys.CredVerifier,
+	data *pb.PseudonymsysTransferCredentialData) error {
+	proof, err := data.Possession.GetNativeType()
+	if err != nil {
+		return pb.NewStatusError(codes.InvalidArgument, pb.ErrorCode_INVALID_REQUEST,
+			"missing proof of possession of the secret of the nym")
+	}
+	context, err := s.possessionContext(data.TargetOrgName, data.Context,
+		pb.TransferCredentialMethod)
+	if err != nil {
+		return err
+	}
+	if !org.VerifyPossession(proof, context) {
+		return pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_PROOF,
+			"invalid proof of possession of the secret of the nym")
+	}
+
+	return nil
+}
+
+// possessionContext checks that context c of a proof of possession names the
+// organization targetOrg the credential is transferred to, and returns the value of
+// the context for RPC method.
+func (s *Server) possessionContext(targetOrg string, c *pb.NIContext,
+	method string) (*big.Int, error) {
+	if targetOrg == "" || c.GetVerifier() != targetOrg {
+		return nil, pb.NewStatusError(codes.InvalidArgument, pb.ErrorCode_INVALID_REQUEST,
+			"proof of possession is not bound to the organization")
+	}
+
+	return s.useNIContext(c, method)
+}
+
+// credChain is a chain of credentials that a credential of the pseudonym system is
+// derived from, as sent by the user.
+type credChain struct {
+	links   []*pseudsys.ChainLink
+	pubKeys []*pseudsys.PubKey
+	// proof random data of credentials of the chain
+	x []*big.Int
+}
+
+// decodeChain decodes links of the chain of credentials that a credential of the
+// organization with keys is derived from, checking that each organization of the
+// chain accepts credentials of the previous one.
+func (s *Server) decodeChain(t *Tenant, group *schnorr.Group, keys *OrgKeys,
+	links []*pb.PseudonymsysChainLink) (*credChain, error) {
+	if len(links) == 0 && len(keys.Accepts) > 0 {
+		return nil, pb.NewStatusError(codes.PermissionDenied, pb.ErrorCode_INVALID_REQUEST,
+			"credentials of the organization are derived from credentials of other organizations")
+	}
+
+	c := new(credChain)
+	linkKeys := make([]*OrgKeys, len(links))
+	for i, l := range links {
+		link, x, err := l.GetNativeType(group)
+		if err != nil {
+			return nil, pb.NewInvalidValueError(err)
+		}
+		if linkKeys[i], err = s.orgKeys(t, link.Issuer, false); err != nil {
+			return nil, err
+		}
+		c.links = append(c.links, link)
+		c.pubKeys = append(c.pubKeys, linkKeys[i].PubKey)
+		c.x = append(c.x, x)
+	}
+	for i, link := range c.links {
+		next := keys
+		if i+1 < len(linkKeys) {
+			next = linkKeys[i+1]
+		}
+		if !accepts(next, link.Issuer) {
+			return nil, pb.NewStatusError(codes.PermissionDenied, pb.ErrorCode_INVALID_REQUEST,
+				fmt.Sprintf("credentials of %s are not accepted in the chain", link.Issuer))
+		}
+	}
+
+	return c, nil
+}
+
+// verify verifies that credentials of the chain were issued to the secret whose
+// knowledge the user proved with challenge and proof data z.
+func (c *credChain) verify(group *schnorr.Group, challenge, z *big.Int) bool {
+	return pseudsys.VerifyChain(group, c.links, c.pubKeys, c.x, challenge, z)
+}
+
+// issuers returns names of organizations that issued credentials of the chain.
+func (c *credChain) issuers() []string {
+	issuers := make([]string, len(c.links))
+	for i, link := range c.links {
+		issuers[i] = link.Issuer
+	}
+	return issuers
+}
+
+// accepts reports whether the organization with keys derives its credentials from
+// credentials of organization name.
+func accepts(keys *OrgKeys, name string) bool {
+	for _, n := range keys.Accepts {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
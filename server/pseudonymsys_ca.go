@@ -18,13 +18,11 @@
 package server
 
 import (
-	"math/big"
+	"context"
 
-	"github.com/xlab-si/emmy/config"
-	"github.com/xlab-si/emmy/crypto/pseudsys"
 	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/tracing"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 func (s *Server) GenerateCertificate(stream pb.PseudonymSystemCA_GenerateCertificateServer) error {
@@ -34,16 +32,28 @@ func (s *Server) GenerateCertificate(stream pb.PseudonymSystemCA_GenerateCertifi
 	if err != nil {
 		return err
 	}
+	t, err := s.tenant(stream.Context())
+	if err != nil {
+		return err
+	}
 
-	group := config.LoadSchnorrGroup()
-	d := config.LoadPseudonymsysCASecret()
-	pubKey := config.LoadPseudonymsysCAPubKey()
-	ca := pseudsys.NewCA(group, d, pubKey)
+	group, err := pseudonymsysGroup(t, req)
+	if err != nil {
+		return err
+	}
+	ca, err := s.pseudonymsysCA(t, group)
+	if err != nil {
+		return err
+	}
 
 	sProofRandData := req.GetSchnorrProofRandomData()
-	x := new(big.Int).SetBytes(sProofRandData.X)
-	a := new(big.Int).SetBytes(sProofRandData.A)
-	b := new(big.Int).SetBytes(sProofRandData.B)
+	d := pb.NewSchnorrDecoder(group)
+	x := d.Element("X", sProofRandData.GetX())
+	a := d.Element("A", sProofRandData.GetA())
+	b := d.Element("B", sProofRandData.GetB())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
 
 	challenge := ca.GetChallenge(a, b, x)
 	resp := &pb.Message{
@@ -63,23 +73,22 @@ func (s *Server) GenerateCertificate(stream pb.PseudonymSystemCA_GenerateCertifi
 		return err
 	}
 
-	sProofData := req.GetSchnorrProofData()
-	z := new(big.Int).SetBytes(sProofData.Z)
+	z := d.Exponent("Z", req.GetSchnorrProofData().GetZ())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+	_, span := tracing.StartSpan(stream.Context(), "pseudsys.CA.Verify")
 	cert, err := ca.Verify(z)
+	tracing.End(span, err)
 
 	if err != nil {
 		s.Logger.Debug(err)
-		return status.Error(codes.Internal, err.Error())
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INVALID_PROOF, err.Error())
 	}
 
 	resp = &pb.Message{
 		Content: &pb.Message_PseudonymsysCaCertificate{
-			&pb.PseudonymsysCACertificate{
-				BlindedA: cert.BlindedA.Bytes(),
-				BlindedB: cert.BlindedB.Bytes(),
-				R:        cert.R.Bytes(),
-				S:        cert.S.Bytes(),
-			},
+			pb.ToPbPseudonymsysCACertificate(cert),
 		},
 	}
 
@@ -89,3 +98,50 @@ func (s *Server) GenerateCertificate(stream pb.PseudonymSystemCA_GenerateCertifi
 
 	return nil
 }
+
+// GenerateCertificateNI issues a certificate of the master nym in the request, in
+// place of the GenerateCertificate stream, to users that prove knowledge of its
+// secret with a non-interactive proof.
+func (s *Server) GenerateCertificateNI(ctx context.Context,
+	req *pb.PseudonymsysCACertificateRequestNI) (*pb.PseudonymsysCACertificate, error) {
+	proof, err := req.GetProof().GetNativeType()
+	if err != nil {
+		return nil, pb.NewInvalidValueError(err)
+	}
+	t, err := s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	niContext, err := s.useNIContext(req.Context, pb.GenerateCertificateNIMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	group, err := t.config().LoadGroup("pseudonymsys")
+	if err != nil {
+		return nil, err
+	}
+	ca, err := s.pseudonymsysCA(t, group)
+	if err != nil {
+		return nil, err
+	}
+
+	d := pb.NewSchnorrDecoder(group)
+	a := d.Element("A", req.A)
+	b := d.Element("B", req.B)
+	d.Element("X", req.GetProof().GetProofRandomData())
+	if err := d.Err(); err != nil {
+		return nil, pb.NewInvalidValueError(err)
+	}
+
+	_, span := tracing.StartSpan(ctx, "pseudsys.CA.VerifyNI")
+	cert, err := ca.VerifyNI(a, b, proof, niContext)
+	tracing.End(span, err)
+	if err != nil {
+		s.Logger.Debug(err)
+		return nil, pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_PROOF,
+			err.Error())
+	}
+
+	return pb.ToPbPseudonymsysCACertificate(cert), nil
+}
@@ -18,13 +18,9 @@
 package server
 
 import (
-	"math/big"
-
-	"github.com/xlab-si/emmy/config"
-	"github.com/xlab-si/emmy/crypto/ecpseudsys"
 	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/tracing"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 func (s *Server) GenerateCertificate_EC(stream pb.PseudonymSystemCA_GenerateCertificate_ECServer) error {
@@ -32,15 +28,28 @@ func (s *Server) GenerateCertificate_EC(stream pb.PseudonymSystemCA_GenerateCert
 	if err != nil {
 		return err
 	}
+	t, err := s.tenant(stream.Context())
+	if err != nil {
+		return err
+	}
 
-	d := config.LoadPseudonymsysCASecret()
-	pubKey := config.LoadPseudonymsysCAPubKey()
-	ca := ecpseudsys.NewCA(d, pubKey, curve)
+	curve, err := pseudonymsysCurve(t, req)
+	if err != nil {
+		return err
+	}
+	ca, err := s.pseudonymsysCAEC(t, curve)
+	if err != nil {
+		return err
+	}
 
 	sProofRandData := req.GetSchnorrEcProofRandomData()
-	x := sProofRandData.X.GetNativeType()
-	a := sProofRandData.A.GetNativeType()
-	b := sProofRandData.B.GetNativeType()
+	d := pb.NewECDecoder(curve)
+	x := d.ECElement("X", sProofRandData.GetX())
+	a := d.ECElement("A", sProofRandData.GetA())
+	b := d.ECElement("B", sProofRandData.GetB())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
 
 	challenge := ca.GetChallenge(a, b, x)
 	resp := &pb.Message{
@@ -60,13 +69,17 @@ func (s *Server) GenerateCertificate_EC(stream pb.PseudonymSystemCA_GenerateCert
 		return err
 	}
 
-	sProofData := req.GetSchnorrProofData()
-	z := new(big.Int).SetBytes(sProofData.Z)
+	z := d.Exponent("Z", req.GetSchnorrProofData().GetZ())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+	_, span := tracing.StartSpan(stream.Context(), "ecpseudsys.CA.Verify")
 	cert, err := ca.Verify(z)
+	tracing.End(span, err)
 
 	if err != nil {
 		s.Logger.Debug(err)
-		return status.Error(codes.Internal, err.Error())
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INVALID_PROOF, err.Error())
 	}
 
 	resp = &pb.Message{
@@ -18,14 +18,11 @@
 package server
 
 import (
-	"math/big"
-
-	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/ec"
 	"github.com/xlab-si/emmy/crypto/ecpseudsys"
-	"github.com/xlab-si/emmy/crypto/ecschnorr"
 	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/tracing"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 func (s *Server) GenerateNym_EC(stream pb.PseudonymSystem_GenerateNym_ECServer) error {
@@ -33,34 +30,47 @@ func (s *Server) GenerateNym_EC(stream pb.PseudonymSystem_GenerateNym_ECServer)
 	if err != nil {
 		return err
 	}
+	t, err := s.tenant(stream.Context())
+	if err != nil {
+		return err
+	}
 
-	caPubKey := config.LoadPseudonymsysCAPubKey()
+	curve, err := pseudonymsysCurve(t, req)
+	if err != nil {
+		return err
+	}
+	caPubKey := t.config().LoadPseudonymsysCAPubKey()
 	org := ecpseudsys.NewNymGenerator(caPubKey, curve)
 
 	proofRandData := req.GetPseudonymsysNymGenProofRandomDataEc()
-	x1 := proofRandData.X1.GetNativeType()
-	nymA := proofRandData.A1.GetNativeType()
-	nymB := proofRandData.B1.GetNativeType()
-	x2 := proofRandData.X2.GetNativeType()
-	blindedA := proofRandData.A2.GetNativeType()
-	blindedB := proofRandData.B2.GetNativeType()
-	signatureR := new(big.Int).SetBytes(proofRandData.R)
-	signatureS := new(big.Int).SetBytes(proofRandData.S)
+	d := pb.NewECDecoder(curve)
+	x1 := d.ECElement("X1", proofRandData.GetX1())
+	nymA := d.ECElement("A1", proofRandData.GetA1())
+	nymB := d.ECElement("B1", proofRandData.GetB1())
+	x2 := d.ECElement("X2", proofRandData.GetX2())
+	blindedA := d.ECElement("A2", proofRandData.GetA2())
+	blindedB := d.ECElement("B2", proofRandData.GetB2())
+	signatureR := d.Int("R", proofRandData.GetR())
+	signatureS := d.Int("S", proofRandData.GetS())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
 
-	regKeyOk, err := s.RegistrationManager.CheckRegistrationKey(proofRandData.RegKey)
+	regKeyOk, err := s.RegistrationManager.CheckRegistrationKey(proofRandData.GetRegKey())
 
 	var resp *pb.Message
 
 	if !regKeyOk || err != nil {
 		s.Logger.Debugf("Registration key %s ok=%t, error=%v",
-			proofRandData.RegKey, regKeyOk, err)
-		return status.Error(codes.NotFound, "registration key verification failed")
+			proofRandData.GetRegKey(), regKeyOk, err)
+		return pb.NewStatusError(codes.NotFound, pb.ErrorCode_INVALID_REG_KEY,
+			"registration key verification failed")
 
 	}
 	challenge, err := org.GetChallenge(nymA, blindedA, nymB, blindedB, x1, x2, signatureR, signatureS)
 	if err != nil {
 		s.Logger.Debug(err)
-		return status.Error(codes.Internal, err.Error())
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INVALID_PROOF, err.Error())
 	}
 	resp = &pb.Message{
 		Content: &pb.Message_PedersenDecommitment{
@@ -80,8 +90,13 @@ func (s *Server) GenerateNym_EC(stream pb.PseudonymSystem_GenerateNym_ECServer)
 	}
 
 	proofData := req.GetSchnorrProofData() // SchnorrProofData is used in DLog equality proof as well
-	z := new(big.Int).SetBytes(proofData.Z)
+	z := d.Exponent("Z", proofData.GetZ())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+	_, span := tracing.StartSpan(stream.Context(), "ecpseudsys.NymGenerator.Verify")
 	valid := org.Verify(z)
+	span.End()
 
 	resp = &pb.Message{
 		Content: &pb.Message_Status{&pb.Status{Success: valid}},
@@ -99,14 +114,30 @@ func (s *Server) ObtainCredential_EC(stream pb.PseudonymSystem_ObtainCredential_
 	if err != nil {
 		return err
 	}
+	t, err := s.tenant(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	curve, err := pseudonymsysCurve(t, req)
+	if err != nil {
+		return err
+	}
 
 	proofRandData := req.GetSchnorrEcProofRandomData()
-	x := proofRandData.X.GetNativeType()
-	a := proofRandData.A.GetNativeType()
-	b := proofRandData.B.GetNativeType()
+	d := pb.NewECDecoder(curve)
+	x := d.ECElement("X", proofRandData.GetX())
+	a := d.ECElement("A", proofRandData.GetA())
+	b := d.ECElement("B", proofRandData.GetB())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
 
-	secKey := config.LoadPseudonymsysOrgSecrets("org1", "ecdlog")
-	org := ecpseudsys.NewCredIssuer(secKey, curve)
+	keys, err := s.orgKeys(t, proofRandData.GetOrgName(), true)
+	if err != nil {
+		return err
+	}
+	org := ecpseudsys.NewCredIssuer(keys.SecKeyEC, curve)
 	challenge := org.GetChallenge(a, b, x)
 
 	resp := &pb.Message{
@@ -126,14 +157,18 @@ func (s *Server) ObtainCredential_EC(stream pb.PseudonymSystem_ObtainCredential_
 		return err
 	}
 
-	proofData := req.GetBigint()
-	z := new(big.Int).SetBytes(proofData.X1)
+	z := d.Exponent("Z", req.GetBigint().GetX1())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
 
+	_, span := tracing.StartSpan(stream.Context(), "ecpseudsys.CredIssuer.Verify")
 	x11, x12, x21, x22, A, B, err := org.Verify(z)
+	tracing.End(span, err)
 
 	if err != nil {
 		s.Logger.Debug(err)
-		return status.Error(codes.Internal, err.Error())
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INVALID_PROOF, err.Error())
 	}
 	resp = &pb.Message{
 		Content: &pb.Message_PseudonymsysIssueProofRandomDataEc{
@@ -158,8 +193,11 @@ func (s *Server) ObtainCredential_EC(stream pb.PseudonymSystem_ObtainCredential_
 	}
 
 	challenges := req.GetDoubleBigint()
-	challenge1 := new(big.Int).SetBytes(challenges.X1)
-	challenge2 := new(big.Int).SetBytes(challenges.X2)
+	challenge1 := d.Exponent("challenge1", challenges.GetX1())
+	challenge2 := d.Exponent("challenge2", challenges.GetX2())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
 
 	z1, z2 := org.GetProofData(challenge1, challenge2)
 	resp = &pb.Message{
@@ -183,43 +221,43 @@ func (s *Server) TransferCredential_EC(stream pb.PseudonymSystem_TransferCredent
 	if err != nil {
 		return err
 	}
+	t, err := s.tenant(stream.Context())
+	if err != nil {
+		return err
+	}
 
-	secKey := config.LoadPseudonymsysOrgSecrets("org1", "ecdlog")
-	org := ecpseudsys.NewCredVerifier(secKey, curve)
+	curve, err := pseudonymsysCurve(t, req)
+	if err != nil {
+		return err
+	}
 
 	data := req.GetPseudonymsysTransferCredentialDataEc()
-	orgName := data.OrgName
-	x1 := data.X1.GetNativeType()
-	x2 := data.X2.GetNativeType()
-	nymA := data.NymA.GetNativeType()
-	nymB := data.NymB.GetNativeType()
-
-	t1 := ecschnorr.NewBlindedTrans(
-		new(big.Int).SetBytes(data.Credential.T1.A.X),
-		new(big.Int).SetBytes(data.Credential.T1.A.Y),
-		new(big.Int).SetBytes(data.Credential.T1.B.X),
-		new(big.Int).SetBytes(data.Credential.T1.B.Y),
-		new(big.Int).SetBytes(data.Credential.T1.Hash),
-		new(big.Int).SetBytes(data.Credential.T1.ZAlpha))
-
-	t2 := ecschnorr.NewBlindedTrans(
-		new(big.Int).SetBytes(data.Credential.T2.A.X),
-		new(big.Int).SetBytes(data.Credential.T2.A.Y),
-		new(big.Int).SetBytes(data.Credential.T2.B.X),
-		new(big.Int).SetBytes(data.Credential.T2.B.Y),
-		new(big.Int).SetBytes(data.Credential.T2.Hash),
-		new(big.Int).SetBytes(data.Credential.T2.ZAlpha))
-
-	credential := ecpseudsys.NewCred(
-		data.Credential.SmallAToGamma.GetNativeType(),
-		data.Credential.SmallBToGamma.GetNativeType(),
-		data.Credential.AToGamma.GetNativeType(),
-		data.Credential.BToGamma.GetNativeType(),
-		t1, t2,
-	)
+	orgName := data.GetOrgName()
+	keys, err := s.orgKeys(t, data.GetTargetOrgName(), true)
+	if err != nil {
+		return err
+	}
+	org := ecpseudsys.NewCredVerifier(keys.SecKeyEC, curve)
+	d := pb.NewECDecoder(curve)
+	x1 := d.ECElement("X1", data.GetX1())
+	x2 := d.ECElement("X2", data.GetX2())
+	nymA := d.ECElement("nym A", data.GetNymA())
+	nymB := d.ECElement("nym B", data.GetNymB())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+	credential, err := data.GetCredential().GetNativeType(curve)
+	if err != nil {
+		return pb.NewInvalidValueError(err)
+	}
 
 	challenge := org.GetChallenge(nymA, nymB,
 		credential.SmallAToGamma, credential.SmallBToGamma, x1, x2)
+	if t.config().LoadPseudonymsysRequirePossession() {
+		if err := s.verifyPossessionEC(org, curve, data); err != nil {
+			return err
+		}
+	}
 
 	resp := &pb.Message{
 		Content: &pb.Message_Bigint{
@@ -239,27 +277,36 @@ func (s *Server) TransferCredential_EC(stream pb.PseudonymSystem_TransferCredent
 	}
 
 	// PubKeys of the organization that issue a credential:
-	orgPubKeys := config.LoadPseudonymsysOrgPubKeysEC(orgName)
+	issuerKeys, err := s.orgKeys(t, orgName, true)
+	if err != nil {
+		return err
+	}
+	orgPubKeys := issuerKeys.PubKeyEC
 
-	proofData := req.GetBigint()
-	z := new(big.Int).SetBytes(proofData.X1)
+	z := d.Exponent("Z", req.GetBigint().GetX1())
+	if err := d.Err(); err != nil {
+		return pb.NewInvalidValueError(err)
+	}
 
-	if verified := org.Verify(z, credential, orgPubKeys); !verified {
+	_, span := tracing.StartSpan(stream.Context(), "ecpseudsys.CredVerifier.Verify")
+	verified := org.Verify(z, credential, orgPubKeys)
+	span.End()
+	if !verified {
 		s.Logger.Debug("User authentication failed")
-		return status.Error(codes.Unauthenticated, "user authentication failed")
+		return pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_PROOF,
+			"user authentication failed")
 	}
 
-	sessionKey, err := s.GenerateSessionKey()
+	sessionKey, err := s.startSession(t, map[string]interface{}{"org": orgName})
 	if err != nil {
 		s.Logger.Debug(err)
-		return status.Error(codes.Internal, "failed to obtain session key")
+		return pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"failed to obtain session key")
 	}
 
 	resp = &pb.Message{
 		Content: &pb.Message_SessionKey{
-			SessionKey: &pb.SessionKey{
-				Value: *sessionKey,
-			},
+			SessionKey: sessionKey,
 		},
 	}
 
@@ -269,3 +316,28 @@ func (s *Server) TransferCredential_EC(stream pb.PseudonymSystem_TransferCredent
 
 	return nil
 }
+
+// verifyPossessionEC is like verifyPossession, but for transfers of credentials in EC
+// arithmetic.
+func (s *Server) verifyPossessionEC(org *ecpseudsys.CredVerifier, curve ec.Curve,
+	data *pb.PseudonymsysTransferCredentialDataEC) error {
+	if data.Possession == nil {
+		return pb.NewStatusError(codes.InvalidArgument, pb.ErrorCode_INVALID_REQUEST,
+			"missing proof of possession of the secret of the nym")
+	}
+	proof, err := data.Possession.GetNativeType(curve)
+	if err != nil {
+		return pb.NewInvalidValueError(err)
+	}
+	context, err := s.possessionContext(data.TargetOrgName, data.Context,
+		pb.TransferCredentialECMethod)
+	if err != nil {
+		return err
+	}
+	if !org.VerifyPossession(proof, context) {
+		return pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_PROOF,
+			"invalid proof of possession of the secret of the nym")
+	}
+
+	return nil
+}
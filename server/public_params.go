@@ -0,0 +1,134 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/jose"
+	pb "github.com/xlab-si/emmy/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// paramsSigner signs public parameters the server gives to clients.
+type paramsSigner struct {
+	key   *ecdsa.PrivateKey
+	keyID string // thumbprint of the public key (see jose.JWK.Thumbprint)
+}
+
+// SignPublicParameters makes the server give clients its public parameters (see
+// GetPublicParameters), signed with key. Clients verify them with the public key,
+// which is the only thing they need to obtain out of band.
+func (s *Server) SignPublicParameters(key *ecdsa.PrivateKey) error {
+	if key.Curve != elliptic.P256() {
+		return fmt.Errorf("only P-256 keys are supported")
+	}
+	jwk, err := jose.NewJWK(&key.PublicKey)
+	if err != nil {
+		return err
+	}
+	s.paramsSigner = &paramsSigner{
+		key:   key,
+		keyID: jwk.Thumbprint(),
+	}
+
+	return nil
+}
+
+// GetPublicParameters returns the public parameters of the tenant of the client in a
+// single bundle signed by the server: CL public keys proofs are verified with, CL
+// parameters, the accumulator of revocation (when it is enabled) and structures of
+// all the credential schemas.
+func (s *Server) GetPublicParameters(ctx context.Context,
+	_ *empty.Empty) (*pb.SignedPublicParameters, error) {
+	s.Logger.Info("Client requested public parameters")
+	if s.paramsSigner == nil {
+		return nil, status.Error(codes.FailedPrecondition, "public parameters are not published")
+	}
+
+	t, err := s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+	params, err := s.publicParameters(t)
+	if err != nil {
+		s.Logger.Error(err)
+		return nil, status.Error(codes.Internal, "cannot assemble public parameters")
+	}
+	data, err := proto.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(data)
+	r, ss, err := ecdsa.Sign(common.RandReader(), s.paramsSigner.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	sig := make([]byte, 64)
+	rBytes, sBytes := r.Bytes(), ss.Bytes()
+	copy(sig[32-len(rBytes):32], rBytes)
+	copy(sig[64-len(sBytes):], sBytes)
+
+	return &pb.SignedPublicParameters{
+		Parameters: data,
+		Signature:  sig,
+		KeyID:      s.paramsSigner.keyID,
+	}, nil
+}
+
+// publicParameters assembles the public parameters of tenant t.
+func (s *Server) publicParameters(t *Tenant) (*pb.PublicParameters, error) {
+	clPubKeys, err := s.clPubKeys(t)
+	if err != nil {
+		return nil, err
+	}
+	clParams, err := cl.LoadParams()
+	if err != nil {
+		return nil, err
+	}
+	params := &pb.PublicParameters{
+		ClPubKeys: clPubKeys,
+		ClParams:  pb.ToPbCLParams(config.LoadCLParamsPreset(), clParams),
+		IssuedAt:  time.Now().Unix(),
+	}
+
+	if r := s.revocationOf(t); r != nil {
+		r.Lock()
+		params.Accumulator = pb.ToPbAccumulator(r.authority.Acc)
+		r.Unlock()
+	}
+	if schemas := s.schemaRegistry(t); schemas != nil {
+		for _, schema := range schemas.Schemas() {
+			params.Schemas = append(params.Schemas, credStructure(schema))
+		}
+	}
+
+	return params, nil
+}
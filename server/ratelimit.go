@@ -0,0 +1,235 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/xlab-si/emmy/config"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+)
+
+// tokenBucket allows events at a rate, with bursts of up to burst events.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take takes a token from b if there is one, after refilling b with tokens that
+// accumulated at rate since the last event, up to burst.
+func (b *tokenBucket) take(rate float64, burst int, now time.Time) bool {
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// limiterSweepInterval is how often buckets that refilled completely are removed.
+const limiterSweepInterval = time.Minute
+
+// limiter limits the rate at which peers (by their IP address) and clients (by the
+// client ID of protocol messages) start protocols, and the number of concurrent
+// streams of each peer.
+type limiter struct {
+	rate, clientRate   float64
+	burst, clientBurst int
+	maxStreams         int
+
+	sync.Mutex
+	peers     map[string]*tokenBucket
+	clients   map[int32]*tokenBucket
+	streams   map[string]int // streams in progress by peer
+	lastSweep time.Time
+}
+
+// newLimiter returns a limiter enforcing limits of conf, or nil if conf sets none.
+func newLimiter(conf *config.LimitsConfig) *limiter {
+	if conf.RateLimit <= 0 && conf.ClientRateLimit <= 0 && conf.MaxStreamsPerPeer <= 0 {
+		return nil
+	}
+	l := &limiter{
+		rate:        conf.RateLimit,
+		burst:       conf.RateBurst,
+		clientRate:  conf.ClientRateLimit,
+		clientBurst: conf.ClientRateBurst,
+		maxStreams:  conf.MaxStreamsPerPeer,
+		peers:       make(map[string]*tokenBucket),
+		clients:     make(map[int32]*tokenBucket),
+		streams:     make(map[string]int),
+		lastSweep:   time.Now(),
+	}
+	// a burst of at least one protocol is needed for any protocol to start
+	if l.burst < 1 {
+		l.burst = 1
+	}
+	if l.clientBurst < 1 {
+		l.clientBurst = 1
+	}
+	return l
+}
+
+// start registers the start of a stream of peer addr, or returns an error if the
+// peer exceeded its number of concurrent streams or, if rated, its rate. Registered
+// streams need to be ended with end.
+func (l *limiter) start(addr string, rated bool) error {
+	l.Lock()
+	defer l.Unlock()
+	now := time.Now()
+	l.sweep(now)
+
+	if l.maxStreams > 0 && l.streams[addr] >= l.maxStreams {
+		return pb.NewStatusError(codes.ResourceExhausted, pb.ErrorCode_RATE_LIMITED,
+			fmt.Sprintf("more than %d concurrent protocols", l.maxStreams))
+	}
+	if rated && l.rate > 0 {
+		b, ok := l.peers[addr]
+		if !ok {
+			b = &tokenBucket{tokens: float64(l.burst), last: now}
+			l.peers[addr] = b
+		}
+		if !b.take(l.rate, l.burst, now) {
+			return pb.NewStatusError(codes.ResourceExhausted, pb.ErrorCode_RATE_LIMITED,
+				"too many protocols started")
+		}
+	}
+	l.streams[addr]++
+	return nil
+}
+
+// end registers the end of a stream of peer addr.
+func (l *limiter) end(addr string) {
+	l.Lock()
+	defer l.Unlock()
+	if l.streams[addr]--; l.streams[addr] <= 0 {
+		delete(l.streams, addr)
+	}
+}
+
+// startClient returns an error if client id exceeded its rate of protocol starts.
+func (l *limiter) startClient(id int32) error {
+	if l.clientRate <= 0 {
+		return nil
+	}
+	l.Lock()
+	defer l.Unlock()
+	now := time.Now()
+	b, ok := l.clients[id]
+	if !ok {
+		b = &tokenBucket{tokens: float64(l.clientBurst), last: now}
+		l.clients[id] = b
+	}
+	if !b.take(l.clientRate, l.clientBurst, now) {
+		return pb.NewStatusError(codes.ResourceExhausted, pb.ErrorCode_RATE_LIMITED,
+			"too many protocols started by client")
+	}
+	return nil
+}
+
+// sweep removes buckets that refilled completely, as they are the same as new ones.
+// It needs to be called with l locked.
+func (l *limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < limiterSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for addr, b := range l.peers {
+		if now.Sub(b.last).Seconds()*l.rate+b.tokens >= float64(l.burst) {
+			delete(l.peers, addr)
+		}
+	}
+	for id, b := range l.clients {
+		if now.Sub(b.last).Seconds()*l.clientRate+b.tokens >= float64(l.clientBurst) {
+			delete(l.clients, id)
+		}
+	}
+}
+
+// peerHost returns the IP address of the peer of a request with context ctx, or an
+// empty string if it is not known.
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// streamLimitInterceptor returns a stream interceptor that rejects streams of peers
+// and clients exceeding the limits of l with code ResourceExhausted. Limits of
+// clients are checked once the first message of a stream names its client. Rates
+// of runs kept in the session store of a cluster are only checked by their first
+// round.
+func streamLimitInterceptor(l *limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		addr := peerHost(ss.Context())
+		if addr == "" {
+			return handler(srv, ss)
+		}
+		rated := startsRun(ss.Context())
+		if err := l.start(addr, rated); err != nil {
+			return err
+		}
+		defer l.end(addr)
+
+		return handler(srv, &limitStream{
+			ServerStream: ss,
+			limiter:      l,
+			received:     !rated, // the client was checked by the first round
+		})
+	}
+}
+
+// limitStream is a server stream that checks the rate of its client when the first
+// message is received.
+type limitStream struct {
+	grpc.ServerStream
+	limiter  *limiter
+	received bool
+}
+
+func (st *limitStream) RecvMsg(m interface{}) error {
+	if err := st.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if st.received {
+		return nil
+	}
+	st.received = true
+	if msg, ok := m.(*pb.Message); ok && msg.ClientId != 0 {
+		return st.limiter.startClient(msg.ClientId)
+	}
+	return nil
+}
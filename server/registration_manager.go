@@ -18,15 +18,24 @@
 package server
 
 import (
+	"sync"
+
 	"github.com/go-redis/redis"
 )
 
-// RegistrationManager checks for the presence of a registration key,
-// removing it in case it exists.
-// The bolean return argument indicates success (registration key
-// present and subsequently deleted) or failure (absence of registration
-// key).
+// RegistrationManager stores registration keys, which allow users to register
+// with the server (for example to obtain a CL credential) once.
+// Implementations backed by redis (RedisClient), memory (MemRegistrationManager)
+// and SQL databases (SQLRegistrationManager) are provided.
 type RegistrationManager interface {
+	// AddRegistrationKey stores a new registration key.
+	AddRegistrationKey(string) error
+
+	// CheckRegistrationKey checks for the presence of a registration key,
+	// removing it in case it exists.
+	// The bolean return argument indicates success (registration key
+	// present and subsequently deleted) or failure (absence of registration
+	// key).
 	CheckRegistrationKey(string) (bool, error)
 }
 
@@ -40,6 +49,11 @@ func NewRedisClient(c *redis.Client) *RedisClient {
 	}
 }
 
+// AddRegistrationKey stores a new registration key, which does not expire.
+func (c *RedisClient) AddRegistrationKey(key string) error {
+	return c.Set(key, key, 0).Err()
+}
+
 // CheckRegistrationKey checks whether provided key is present in registration database and deletes it,
 // preventing another registration with the same key.
 // Returns true if key was present (registration allowed), false otherwise.
@@ -54,3 +68,45 @@ func (c *RedisClient) CheckRegistrationKey(key string) (bool, error) {
 
 	return resp.Val() == 1, nil // one deleted entry indicates that the key was present in the DB
 }
+
+// MemRegistrationManager keeps registration keys in memory. It is meant
+// for development and testing, where running a redis database is not
+// desirable. Keys do not survive server restarts.
+type MemRegistrationManager struct {
+	sync.Mutex
+	keys map[string]bool
+}
+
+// NewMemRegistrationManager returns a MemRegistrationManager holding the
+// provided registration keys.
+func NewMemRegistrationManager(keys ...string) *MemRegistrationManager {
+	m := &MemRegistrationManager{
+		keys: make(map[string]bool),
+	}
+	for _, key := range keys {
+		m.keys[key] = true
+	}
+
+	return m
+}
+
+// AddRegistrationKey stores a new registration key.
+func (m *MemRegistrationManager) AddRegistrationKey(key string) error {
+	m.Lock()
+	defer m.Unlock()
+	m.keys[key] = true
+	return nil
+}
+
+// CheckRegistrationKey checks whether provided key is present and deletes it,
+// preventing another registration with the same key.
+func (m *MemRegistrationManager) CheckRegistrationKey(key string) (bool, error) {
+	m.Lock()
+	defer m.Unlock()
+	if !m.keys[key] {
+		return false, nil
+	}
+	delete(m.keys, key)
+
+	return true, nil
+}
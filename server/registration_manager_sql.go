@@ -0,0 +1,92 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SQLRegistrationTable is the name of the table SQLRegistrationManager keeps
+// registration keys in.
+const SQLRegistrationTable = "emmy_registration_keys"
+
+// SQLRegistrationManager keeps registration keys in a table of an SQL database,
+// accessed through database/sql. emmy does not import any SQL drivers - programs
+// using SQLRegistrationManager need to import the driver of their database
+// (for example github.com/lib/pq for PostgreSQL).
+type SQLRegistrationManager struct {
+	db *sql.DB
+	// placeholders of query parameters, $1 for PostgreSQL and ? for most
+	// other databases
+	placeholder string
+}
+
+// NewSQLRegistrationManager returns a SQLRegistrationManager storing keys in db,
+// which was opened with the driver with name driverName. The table for keys is
+// created if it does not exist yet.
+func NewSQLRegistrationManager(db *sql.DB, driverName string) (*SQLRegistrationManager, error) {
+	m := &SQLRegistrationManager{
+		db:          db,
+		placeholder: "?",
+	}
+	switch driverName {
+	case "postgres", "pgx", "cloudsqlpostgres":
+		m.placeholder = "$1"
+	}
+
+	_, err := db.Exec(fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (reg_key VARCHAR(255) PRIMARY KEY)",
+		SQLRegistrationTable))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create table for registration keys: %v", err)
+	}
+
+	return m, nil
+}
+
+// AddRegistrationKey stores a new registration key. Adding a key that is already
+// present fails.
+func (m *SQLRegistrationManager) AddRegistrationKey(key string) error {
+	_, err := m.db.Exec(fmt.Sprintf("INSERT INTO %s (reg_key) VALUES (%s)",
+		SQLRegistrationTable, m.placeholder), key)
+	return err
+}
+
+// CheckRegistrationKey checks whether provided key is present and deletes it,
+// preventing another registration with the same key. As the key is checked and
+// deleted with a single statement, it can only be used once even when several
+// servers share the database.
+func (m *SQLRegistrationManager) CheckRegistrationKey(key string) (bool, error) {
+	res, err := m.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE reg_key = %s",
+		SQLRegistrationTable, m.placeholder), key)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// Close closes the database of m.
+func (m *SQLRegistrationManager) Close() error {
+	return m.db.Close()
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+package server
+
+import (
+	"fmt"
+
+	"github.com/xlab-si/emmy/config"
+)
+
+// ReloadConfig reloads the configuration of the server and of its tenants from their
+// files without a restart. Keys of organizations of the pseudonym system, credential
+// schemas and acceptable credentials take effect for protocols started afterwards,
+// while protocols in progress and sessions are not affected. Tenants added to the
+// configuration are served as well, tenants removed from it are served until restart.
+//
+// Each configuration is replaced atomically: when it cannot be read, or keys or
+// schemas it refers to cannot be loaded, the previous one is kept and an error is
+// returned. Credential schemas are added to the ones the server holds, since
+// registered versions of schemas cannot be changed. Organizations of the pseudonym
+// system are replaced with those of the configuration, also when the registry of
+// organizations was set with UseOrgRegistry.
+func (s *Server) ReloadConfig() error {
+	if err := s.reloadTenant(nil); err != nil {
+		return err
+	}
+
+	s.tenantsLock.RLock()
+	tenants := make([]*Tenant, 0, len(s.tenants))
+	for _, t := range s.tenants {
+		tenants = append(tenants, t)
+	}
+	s.tenantsLock.RUnlock()
+	for _, t := range tenants {
+		if err := s.reloadTenant(t); err != nil {
+			return fmt.Errorf("tenant %s: %v", t.ID, err)
+		}
+	}
+
+	for _, tc := range config.LoadTenants() {
+		if _, err := s.lookupTenant(tc.ID); err == nil {
+			continue
+		}
+		conf, err := config.NewFromFile(tc.File)
+		if err != nil {
+			return fmt.Errorf("tenant %s: %v", tc.ID, err)
+		}
+		t, err := NewTenant(tc.ID, conf)
+		if err != nil {
+			return err
+		}
+		s.AddTenant(t)
+	}
+
+	s.Logger.Notice("Reloaded configuration")
+	return nil
+}
+
+// reloadTenant reloads the configuration of tenant t (the default one when nil), along
+// with keys of its organizations and its credential schemas.
+func (s *Server) reloadTenant(t *Tenant) error {
+	conf := t.config()
+	next, err := conf.Reloaded()
+	if err != nil {
+		return err
+	}
+	schemas, err := newSchemaRegistry(next)
+	if err != nil {
+		return fmt.Errorf("credential schemas: %v", err)
+	}
+	if err := validatePseudonymsysParams(next); err != nil {
+		return err
+	}
+
+	prev := conf.Swap(next)
+	orgs := s.orgs
+	if t != nil {
+		orgs = t.orgs
+	}
+	// keys of organizations are loaded from the new configuration
+	if orgs != nil {
+		if err := orgs.ReloadNames(conf.LoadPseudonymsysOrgNames()...); err != nil {
+			conf.Swap(prev)
+			return fmt.Errorf("organizations: %v", err)
+		}
+	}
+	if reg := s.schemaRegistry(t); reg != nil {
+		reg.merge(schemas)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/xlab-si/emmy/record"
+)
+
+// Replay runs the handler of the protocol of session s, which was recorded on the
+// server side, with a stream that replays messages of the recorded client. Steps
+// of the replay are reported to onStep. It returns the error the handler ended with.
+func (s *Server) Replay(sess *record.Session, onStep func(*record.Step) error) error {
+	if sess.Side != record.Server {
+		return fmt.Errorf("session was recorded on the %s side", sess.Side)
+	}
+
+	replay := record.NewReplay(sess, onStep)
+	handler := reflect.ValueOf(s).MethodByName(sess.Method)
+	if !handler.IsValid() || handler.Type().NumIn() != 1 || handler.Type().NumOut() != 1 ||
+		!reflect.TypeOf(replay).AssignableTo(handler.Type().In(0)) {
+		return fmt.Errorf("no stream handler for %s", sess.Method)
+	}
+
+	var err error
+	if v := handler.Call([]reflect.Value{reflect.ValueOf(replay)})[0].Interface(); v != nil {
+		err = v.(error)
+	}
+	if finishErr := replay.Finish(err); finishErr != nil {
+		return finishErr
+	}
+
+	return err
+}
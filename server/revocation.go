@@ -0,0 +1,163 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// revocation keeps the accumulator of CL credentials that were not revoked, which
+// is saved to path on each change.
+type revocation struct {
+	sync.Mutex
+	authority *cl.RevocationAuthority
+	path      string
+}
+
+// EnableRevocation enables revocation of CL credentials. Issued credentials come with
+// a witness of non-revocation, and proofs of credentials need to include a
+// non-revocation proof. The accumulator is loaded from path, or created there if the
+// file does not exist.
+func (s *Server) EnableRevocation(path string) error {
+	if s.thresholdCoordinator != nil {
+		return fmt.Errorf("revocation cannot be used with threshold issuance")
+	}
+	org, err := s.loadCLOrg(nil)
+	if err != nil {
+		return err
+	}
+	a, err := cl.LoadOrCreateRevocationAuthority(org.Keys, path)
+	if err != nil {
+		return err
+	}
+
+	s.revocation = &revocation{
+		authority: a,
+		path:      path,
+	}
+	s.Logger.Noticef("Enabled revocation of CL credentials (%d revoked so far)",
+		a.Acc.Version)
+	return nil
+}
+
+// RevokeCredential revokes the CL credential issued to nym, under which the receiver
+// record of the credential is stored.
+func (s *Server) RevokeCredential(nym *big.Int) error {
+	if s.revocation == nil {
+		return fmt.Errorf("revocation is not enabled")
+	}
+	rec, err := s.clRecordManager.Load(nym)
+	if err != nil {
+		return err
+	}
+	if rec.E == nil {
+		return fmt.Errorf("credential has no revocation handle")
+	}
+
+	if err := s.revocation.revoke(rec.E); err != nil {
+		return err
+	}
+	s.Logger.Noticef("Revoked CL credential of nym %s", nym)
+	return nil
+}
+
+func (s *Server) GetAccumulator(ctx context.Context, _ *empty.Empty) (*pb.Accumulator, error) {
+	s.Logger.Info("Client requested the accumulator")
+	if s.revocation == nil {
+		return nil, status.Error(codes.FailedPrecondition, "revocation is not enabled")
+	}
+
+	s.revocation.Lock()
+	defer s.revocation.Unlock()
+	return pb.ToPbAccumulator(s.revocation.authority.Acc), nil
+}
+
+func (s *Server) GetAccumulatorUpdate(ctx context.Context,
+	v *pb.AccumulatorVersion) (*pb.AccumulatorUpdate, error) {
+	s.Logger.Info("Client requested an update of the accumulator")
+	if s.revocation == nil {
+		return nil, status.Error(codes.FailedPrecondition, "revocation is not enabled")
+	}
+
+	s.revocation.Lock()
+	defer s.revocation.Unlock()
+	revoked, err := s.revocation.authority.RevokedSince(int(v.Version))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return pb.ToPbAccumulatorUpdate(s.revocation.authority.Acc, revoked), nil
+}
+
+// witness returns the witness of non-revocation of the credential with prime e.
+func (r *revocation) witness(e *big.Int) (*pb.NonRevocationWitness, error) {
+	r.Lock()
+	defer r.Unlock()
+	w, err := r.authority.Witness(e)
+	if err != nil {
+		return nil, err
+	}
+	return pb.ToPbNonRevocationWitness(w), nil
+}
+
+// revoke revokes the credential with prime e and saves the accumulator.
+func (r *revocation) revoke(e *big.Int) error {
+	r.Lock()
+	defer r.Unlock()
+	if err := r.authority.Revoke(e); err != nil {
+		return err
+	}
+	return r.authority.Save(r.path)
+}
+
+// verify verifies the non-revocation proof p of the credential randomized into A
+// against the current accumulator.
+func (r *revocation) verify(p *pb.NonRevocationProof, org *cl.Org, A, nonce *big.Int,
+	revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices []int,
+	revealedKnownAttrs, revealedCommitmentsOfAttrs []*big.Int) error {
+	proof, err := p.GetNativeType()
+	if err != nil {
+		return err
+	}
+
+	r.Lock()
+	acc := *r.authority.Acc
+	r.Unlock()
+	ok, err := cl.VerifyNonRevocationProof(org.Params, &cl.RevealedCred{
+		PubKey:                            org.Keys.Pub,
+		RevealedKnownAttrsIndices:         revealedKnownAttrsIndices,
+		RevealedCommitmentsOfAttrsIndices: revealedCommitmentsOfAttrsIndices,
+		RevealedKnownAttrs:                revealedKnownAttrs,
+		RevealedCommitmentsOfAttrs:        revealedCommitmentsOfAttrs,
+	}, A, &acc, proof, nonce)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("non-revocation proof not valid")
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+)
+
+// ErrUnknownSchema is returned for credential schemas that are not held by a
+// SchemaRegistry.
+var ErrUnknownSchema = errors.New("unknown credential schema")
+
+// CredSchema is a named and versioned structure of CL credentials.
+type CredSchema struct {
+	Name    string
+	Version string
+	Attrs   []cl.CredAttr
+	Count   *cl.AttrCount
+}
+
+// SchemaRegistry holds structures of CL credentials that clients can request by
+// name and version. The first schema registered is the default one, returned when
+// requests do not name a schema, and the latest version of a schema is returned
+// when requests do not name a version. Versions of schemas cannot be changed once
+// registered, new versions have to be registered instead.
+type SchemaRegistry struct {
+	defaultName string
+	schemas     map[string][]*CredSchema // versions of each schema, oldest first
+
+	sync.RWMutex
+}
+
+// NewSchemaRegistry creates an empty registry of credential schemas.
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{
+		schemas: make(map[string][]*CredSchema),
+	}
+}
+
+// NewSchemaRegistryFromConfig creates a registry holding the credential structure of
+// the configuration as the default schema, and schemas from its credential_schemas.
+func NewSchemaRegistryFromConfig() (*SchemaRegistry, error) {
+	return newSchemaRegistry(config.Default())
+}
+
+// newSchemaRegistry creates a registry holding the credential structure of conf as the
+// default schema, and schemas from its credential_schemas.
+func newSchemaRegistry(conf *config.Config) (*SchemaRegistry, error) {
+	structure, err := conf.LoadCredentialStructure()
+	if err != nil {
+		return nil, err
+	}
+	r := NewSchemaRegistry()
+	name, version := conf.LoadCredentialSchema()
+	if _, err := r.Register(name, version, structure); err != nil {
+		return nil, err
+	}
+
+	schemas, err := conf.LoadCredentialSchemas()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range schemas {
+		if _, err := r.Register(s.Name, s.Version, s.Structure); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// Register adds version of schema name with attributes given by structure (see
+// cl.ParseAttrs).
+func (r *SchemaRegistry) Register(name, version string,
+	structure map[string]interface{}) (*CredSchema, error) {
+	if name == "" || version == "" {
+		return nil, fmt.Errorf("credential schema needs a name and a version")
+	}
+	attrs, count, err := cl.ParseAttrs(structure)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential schema %s %s: %v", name, version, err)
+	}
+	if len(attrs) == 0 {
+		return nil, fmt.Errorf("credential schema %s %s has no attributes", name, version)
+	}
+	schema := &CredSchema{
+		Name:    name,
+		Version: version,
+		Attrs:   attrs,
+		Count:   count,
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	if !r.add(schema) {
+		return nil, fmt.Errorf("credential schema %s %s already exists", name, version)
+	}
+	if r.defaultName == "" {
+		r.defaultName = name
+	}
+
+	return schema, nil
+}
+
+// add inserts schema among versions of its name, unless the version already exists,
+// and reports whether it did. r needs to be locked.
+func (r *SchemaRegistry) add(schema *CredSchema) bool {
+	versions := r.schemas[schema.Name]
+	i := sort.Search(len(versions), func(i int) bool {
+		return compareVersions(versions[i].Version, schema.Version) >= 0
+	})
+	if i < len(versions) && compareVersions(versions[i].Version, schema.Version) == 0 {
+		return false
+	}
+	versions = append(versions, nil)
+	copy(versions[i+1:], versions[i:])
+	versions[i] = schema
+	r.schemas[schema.Name] = versions
+
+	return true
+}
+
+// merge adds schemas of other that r does not hold yet and makes the default schema
+// of other the default one of r. Versions that r already holds are kept as they
+// are, since registered versions cannot be changed.
+func (r *SchemaRegistry) merge(other *SchemaRegistry) {
+	schemas := other.Schemas()
+	other.RLock()
+	defaultName := other.defaultName
+	other.RUnlock()
+
+	r.Lock()
+	defer r.Unlock()
+	for _, schema := range schemas {
+		r.add(schema)
+	}
+	if defaultName != "" {
+		r.defaultName = defaultName
+	}
+}
+
+// Schema returns version of schema name. The default schema is returned when name is
+// empty, and the latest version of the schema when version is empty.
+func (r *SchemaRegistry) Schema(name, version string) (*CredSchema, error) {
+	r.RLock()
+	defer r.RUnlock()
+	if name == "" {
+		name = r.defaultName
+	}
+	versions := r.schemas[name]
+	if len(versions) == 0 {
+		return nil, ErrUnknownSchema
+	}
+	if version == "" {
+		return versions[len(versions)-1], nil
+	}
+	for _, s := range versions {
+		if compareVersions(s.Version, version) == 0 {
+			return s, nil
+		}
+	}
+
+	return nil, ErrUnknownSchema
+}
+
+// Schemas returns all the schemas of the registry, ordered by name and version.
+func (r *SchemaRegistry) Schemas() []*CredSchema {
+	r.RLock()
+	defer r.RUnlock()
+	names := make([]string, 0, len(r.schemas))
+	for name := range r.schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var schemas []*CredSchema
+	for _, name := range names {
+		schemas = append(schemas, r.schemas[name]...)
+	}
+
+	return schemas
+}
+
+// credStructure returns the structure of credentials of schema as sent to clients.
+func credStructure(schema *CredSchema) *pb.CredStructure {
+	credAttrs := make([]*pb.CredAttribute, len(schema.Attrs))
+	for i, a := range schema.Attrs {
+		attr := &pb.Attribute{
+			Name:  a.GetName(),
+			Known: a.IsKnown(),
+		}
+		switch t := a.(type) {
+		case *cl.StrAttr:
+			credAttrs[i] = &pb.CredAttribute{
+				Type: &pb.CredAttribute_StringAttr{
+					StringAttr: &pb.StringAttribute{
+						Attr: attr,
+					},
+				},
+			}
+		case *cl.Int64Attr:
+			credAttrs[i] = &pb.CredAttribute{
+				Type: &pb.CredAttribute_IntAttr{
+					IntAttr: &pb.IntAttribute{
+						Attr: attr,
+					},
+				},
+			}
+		case *cl.BlobAttr:
+			credAttrs[i] = &pb.CredAttribute{
+				Type: &pb.CredAttribute_BlobAttr{
+					BlobAttr: &pb.BlobAttribute{
+						Attr: attr,
+					},
+				},
+			}
+		case *cl.DateAttr:
+			credAttrs[i] = &pb.CredAttribute{
+				Type: &pb.CredAttribute_DateAttr{
+					DateAttr: &pb.DateAttribute{
+						Attr: attr,
+					},
+				},
+			}
+		case *cl.BoolAttr:
+			credAttrs[i] = &pb.CredAttribute{
+				Type: &pb.CredAttribute_BoolAttr{
+					BoolAttr: &pb.BoolAttribute{
+						Attr: attr,
+					},
+				},
+			}
+		case *cl.EnumAttr:
+			credAttrs[i] = &pb.CredAttribute{
+				Type: &pb.CredAttribute_EnumAttr{
+					EnumAttr: &pb.EnumAttribute{
+						Attr:   attr,
+						Values: t.Values,
+					},
+				},
+			}
+		case *cl.BytesAttr:
+			credAttrs[i] = &pb.CredAttribute{
+				Type: &pb.CredAttribute_BytesAttr{
+					BytesAttr: &pb.BytesAttribute{
+						Attr: attr,
+						Size: int32(t.Size),
+					},
+				},
+			}
+		}
+	}
+
+	return &pb.CredStructure{
+		NKnown:     int32(schema.Count.Known),
+		NCommitted: int32(schema.Count.Committed),
+		NHidden:    int32(schema.Count.Hidden),
+		Attributes: credAttrs,
+		Name:       schema.Name,
+		Version:    schema.Version,
+	}
+}
+
+// compareVersions compares versions made of dot-separated components, which are
+// compared as numbers when both are numeric, and as strings otherwise. It returns
+// -1, 0 or 1 when v1 is older, the same or newer than v2.
+func compareVersions(v1, v2 string) int {
+	c1, c2 := strings.Split(v1, "."), strings.Split(v2, ".")
+	for i := 0; i < len(c1) || i < len(c2); i++ {
+		var s1, s2 string
+		if i < len(c1) {
+			s1 = c1[i]
+		}
+		if i < len(c2) {
+			s2 = c2[i]
+		}
+		n1, err1 := strconv.Atoi(orZero(s1))
+		n2, err2 := strconv.Atoi(orZero(s2))
+		switch {
+		case err1 == nil && err2 == nil && n1 != n2:
+			if n1 < n2 {
+				return -1
+			}
+			return 1
+		case (err1 != nil || err2 != nil) && s1 != s2:
+			if s1 < s2 {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// orZero returns s, or "0" when s is empty.
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+// UseSchemaRegistry sets the registry of credential schemas the server gives to
+// clients, replacing the one read from the configuration.
+func (s *Server) UseSchemaRegistry(r *SchemaRegistry) {
+	s.schemas = r
+}
+
+// RegisterCredentialSchema registers version of credential schema name with
+// attributes given by structure (see cl.ParseAttrs).
+func (s *Server) RegisterCredentialSchema(name, version string,
+	structure map[string]interface{}) (*CredSchema, error) {
+	if s.schemas == nil {
+		return nil, fmt.Errorf("no credential schemas")
+	}
+	schema, err := s.schemas.Register(name, version, structure)
+	if err != nil {
+		return nil, err
+	}
+	s.Logger.Noticef("Registered credential schema %s %s", name, version)
+
+	return schema, nil
+}
+
+// CredentialSchemas returns credential schemas the server gives to clients.
+func (s *Server) CredentialSchemas() []*CredSchema {
+	if s.schemas == nil {
+		return nil
+	}
+	return s.schemas.Schemas()
+}
+
+// schemaRegistry returns the registry of credential schemas of tenant t.
+func (s *Server) schemaRegistry(t *Tenant) *SchemaRegistry {
+	if t != nil {
+		return t.schemas
+	}
+	return s.schemas
+}
+
+// credSchema returns version of credential schema name (see SchemaRegistry.Schema) of
+// tenant t, or an error to be returned to the client.
+func (s *Server) credSchema(t *Tenant, name, version string) (*CredSchema, error) {
+	schemas := s.schemaRegistry(t)
+	if schemas == nil {
+		return nil, pb.NewStatusError(codes.FailedPrecondition, pb.ErrorCode_UNKNOWN_SCHEMA,
+			"no credential schemas")
+	}
+	schema, err := schemas.Schema(name, version)
+	if err != nil {
+		return nil, pb.NewStatusError(codes.NotFound, pb.ErrorCode_UNKNOWN_SCHEMA,
+			fmt.Sprintf("unknown credential schema %s %s", name, version))
+	}
+
+	return schema, nil
+}
@@ -22,23 +22,28 @@ import (
 	"io"
 	"math"
 	"net"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
 
 	"net/http"
 
 	"github.com/grpc-ecosystem/go-grpc-prometheus"
-        "github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto"
 	"github.com/xlab-si/emmy/crypto/cl"
-	"github.com/xlab-si/emmy/crypto/ec"
 	"github.com/xlab-si/emmy/log"
+	"github.com/xlab-si/emmy/oidc"
 	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/record"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
-)
-
-const (
-	// Curve to be used in all schemes using elliptic curve arithmetic.
-	curve = ec.P256
+	_ "google.golang.org/grpc/encoding/gzip" // lets clients send gzip compressed messages
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 )
 
 // EmmyServer is an interface composed of all the auto-generated server interfaces that
@@ -57,46 +62,186 @@ type Server struct {
 	Logger     log.Logger
 	SessionManager
 	RegistrationManager
-	clRecordManager cl.ReceiverRecordManager
+	clRecordManager      cl.ReceiverRecordManager
+	oidcProvider         *oidc.Provider
+	idTokenAudience      string
+	deviceBinding        *deviceBinding
+	revocation           *revocation
+	sessionStore         SessionStore
+	clustered            bool // whether protocol runs are kept in the session store
+	audit                *auditor
+	transcripts          func(*record.Transcript) error // exports transcripts of verifications
+	paramsSigner         *paramsSigner                  // signs public parameters
+	sessionTTL           time.Duration
+	maxSessionLifetime   time.Duration // how long sessions can be refreshed for
+	nonces               NonceStore
+	nonceTTL             time.Duration
+	maxRecvMsgSize       int           // also limits messages of gRPC-Web requests
+	idleTimeout          time.Duration // for the next message of a client, 0 means no limit
+	streamInterceptor    grpc.StreamServerInterceptor
+	steps                *GrpcWebHandler // runs protocols of the Steps service
+	drain                *drainer
+	faults               *faultInjector
+	batchConcurrency     int
+	maxCredValidity      time.Duration
+	orgs                 *OrgRegistry
+	schemas              *SchemaRegistry
+	keyStore             crypto.KeyStore
+	caKeyStore           crypto.KeyStore // holds the key of the pseudonym system CA
+	services             map[string]bool // names of provided services, nil if all are
+	thresholdParty       *cl.ThresholdParty
+	thresholdCoordinator *ThresholdCoordinator
+	tenants              map[string]*Tenant
+	tenantsLock          sync.RWMutex
+	insecure             bool // whether clients connect without TLS
 }
 
-// NewServer initializes an instance of the Server struct and returns a pointer.
-// It performs some default configuration (tracing of gRPC communication and interceptors)
-// and registers RPC server handlers with gRPC server. It requires TLS cert and keyfile
-// in order to establish a secure channel with clients.
-func NewServer(certFile, keyFile string, regMgr RegistrationManager,
-	recMgr cl.ReceiverRecordManager, logger log.Logger) (*Server, error) {
-	logger.Info("Instantiating new server")
-
-	// Obtain TLS credentials
-	creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+// New initializes an instance of the Server struct and returns a pointer. It performs
+// some default configuration (tracing of gRPC communication and interceptors) and
+// registers RPC server handlers with gRPC server. Settings of the server, such as its
+// TLS certificate and key, storage of registration keys or logger, are given by
+// options and default to those of the configuration (see ServerOption).
+func New(opts ...ServerOption) (*Server, error) {
+	options, err := newServerOptions(opts)
 	if err != nil {
 		return nil, err
 	}
+	logger := options.logger
+	logger.Info("Instantiating new server")
 
-	logger.Infof("Successfully read certificate [%s] and key [%s]", certFile, keyFile)
+	// Obtain TLS credentials
+	var creds credentials.TransportCredentials
+	switch {
+	case options.insecure:
+		logger.Warning("Serving without TLS, do not use in production")
+	case options.tlsConfig != nil:
+		creds = credentials.NewTLS(options.tlsConfig)
+	default:
+		creds, err = serverTLSCredentials(options.certFile, options.keyFile,
+			options.clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		logger.Infof("Successfully read certificate [%s] and key [%s]", options.certFile,
+			options.keyFile)
+		if options.clientCAFile != "" {
+			logger.Noticef("Requiring client certificates issued by CAs in [%s]",
+				options.clientCAFile)
+		}
+	}
 
 	sessionManager, err := NewRandSessionKeyGen(config.LoadSessionKeyMinByteLen())
 	if err != nil {
 		logger.Warning(err)
 	}
 
-	// Allow as much concurrent streams as possible and register a gRPC stream interceptor
-	// for logging and monitoring purposes.
+	// Apply configured limits (by default, allow as much concurrent streams as possible)
+	// and register gRPC stream interceptors for monitoring purposes, enforcing
+	// stream deadlines and recovering from panics of handlers.
+	netConf := config.LoadNetworkConfig()
+	maxStreams := netConf.Limits.MaxConcurrentStreams
+	if maxStreams == 0 {
+		maxStreams = math.MaxUint32
+	}
+	// streams are rejected while the server shuts down, before they are counted
+	drain := new(drainer)
+	interceptors := []grpc.StreamServerInterceptor{streamDrainInterceptor(drain),
+		streamRequestIDInterceptor()}
+	// streams of clients exceeding limits are rejected before any work is done for them
+	if l := newLimiter(&netConf.Limits); l != nil {
+		interceptors = append(interceptors, streamLimitInterceptor(l))
+	}
+	interceptors = append(interceptors, options.streamInterceptors...)
+	interceptors = append(interceptors, streamTenantInterceptor(), streamVersionInterceptor(),
+		grpc_prometheus.StreamServerInterceptor, streamMetricsInterceptor(),
+		streamAuditInterceptor(), streamTracingInterceptor())
+	if netConf.Timeouts.Stream > 0 {
+		interceptors = append(interceptors, streamDeadlineInterceptor(netConf.Timeouts.Stream))
+	}
+	if netConf.Timeouts.Idle > 0 {
+		interceptors = append(interceptors, streamIdleInterceptor(netConf.Timeouts.Idle))
+	}
+	// sessions are recorded within the deadline, so that the handler's error ends up
+	// in the session once it completes
+	if recConf := config.LoadRecordingConfig(); recConf.Enabled {
+		if err := os.MkdirAll(recConf.Dir, 0700); err != nil {
+			return nil, fmt.Errorf("cannot create directory for recorded sessions: %v", err)
+		}
+		interceptors = append(interceptors, streamRecordInterceptor(recConf, logger))
+		logger.Warningf("Recording protocol sessions to %s", recConf.Dir)
+	}
+	// the deadline and idle interceptors run handlers in their own goroutines, so
+	// recovery needs to be the innermost interceptor
+	interceptors = append(interceptors, streamRecoveryInterceptor(logger))
+
+	streamInterceptor := chainStreamInterceptors(interceptors...)
+
+	unaryInterceptors := append([]grpc.UnaryServerInterceptor{unaryRequestIDInterceptor()},
+		options.unaryInterceptors...)
+	unaryInterceptors = append(unaryInterceptors, unaryRecoveryInterceptor(logger))
+
+	grpcOpts := []grpc.ServerOption{
+		grpc.MaxConcurrentStreams(maxStreams),
+		grpc.MaxRecvMsgSize(options.maxRecvMsgSize),
+		grpc.MaxSendMsgSize(options.maxSendMsgSize),
+		grpc.StreamInterceptor(streamInterceptor),
+		grpc.UnaryInterceptor(chainUnaryInterceptors(unaryInterceptors...)),
+		// allow clients to detect broken connections with keepalive pings
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             netConf.Timeouts.MinKeepalive,
+			PermitWithoutStream: true,
+		}),
+	}
+	if creds != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(creds))
+	}
+
 	server := &Server{
-		GrpcServer: grpc.NewServer(
-			grpc.Creds(creds),
-			grpc.MaxConcurrentStreams(math.MaxUint32),
-			grpc.StreamInterceptor(grpc_prometheus.StreamServerInterceptor),
-		),
+		GrpcServer:          grpc.NewServer(grpcOpts...),
 		Logger:              logger,
 		SessionManager:      sessionManager,
-		RegistrationManager: regMgr,
-		clRecordManager:     recMgr,
+		RegistrationManager: options.regMgr,
+		clRecordManager:     options.recMgr,
+		insecure:            options.insecure,
+		streamInterceptor:   streamInterceptor,
+		drain:               drain,
+		nonces:              NewMemNonceStore(),
+		nonceTTL:            config.LoadNonceConfig().TTL,
+		maxRecvMsgSize:      options.maxRecvMsgSize,
+		idleTimeout:         netConf.Timeouts.Idle,
+	}
+	if len(options.services) > 0 {
+		server.services = map[string]bool{"Info": true}
+		for _, name := range options.services {
+			if _, ok := services[name]; !ok {
+				return nil, fmt.Errorf("unknown service %s", name)
+			}
+			server.services[name] = true
+		}
+	}
+	server.steps = NewGrpcWebHandler(server, nil)
+	if options.sessionStore != nil {
+		server.UseSessionStore(options.sessionStore, options.sessionTTL)
+	}
+
+	if server.orgs, err = NewOrgRegistryFromConfig(); err != nil {
+		logger.Warningf("Organizations of the pseudonym system not available: %v", err)
 	}
 
-	// Disable tracing by default, as is used for debugging purposes.
-	// The user will be able to turn it on via Server's EnableTracing function.
+	if server.schemas, err = NewSchemaRegistryFromConfig(); err != nil {
+		logger.Warningf("Credential schemas not available: %v", err)
+	}
+
+	if err := validatePseudonymsysParams(config.Default()); err != nil {
+		logger.Warningf("Pseudonym system not available: %v", err)
+	}
+
+	if faultsConf := config.LoadFaultsConfig(); faultsConf.Enabled {
+		server.InjectFaults(faultsConf)
+	}
+
+	// Protocol executions are traced with OpenTelemetry (see package tracing),
+	// so gRPC's own tracing is disabled.
 	grpc.EnableTracing = false
 
 	// Register our services with the supporting gRPC server
@@ -108,25 +253,84 @@ func NewServer(certFile, keyFile string, regMgr RegistrationManager,
 	return server, nil
 }
 
-// Start configures and starts the protocol server at the requested port.
+// NewServer creates a server with TLS certificate certFile and private key keyFile,
+// which checks registration keys with regMgr, keeps receiver records of CL credentials
+// with recMgr and logs with logger. Further options apply as in New.
+//
+// Deprecated: use New with options WithTLS, WithRegistrationManager, WithRecordManager
+// and WithLogger.
+func NewServer(certFile, keyFile string, regMgr RegistrationManager,
+	recMgr cl.ReceiverRecordManager, logger log.Logger, opts ...ServerOption) (*Server, error) {
+	return NewMutualTLSServer(certFile, keyFile, "", regMgr, recMgr, logger, opts...)
+}
+
+// NewMutualTLSServer is like NewServer, but it also requires clients to authenticate
+// with a certificate issued by one of the CAs in clientCAFile, a bundle of certificates
+// in PEM format. When clientCAFile is empty, client certificates are not requested.
+//
+// Deprecated: use New with options WithTLS, WithClientCAs, WithRegistrationManager,
+// WithRecordManager and WithLogger.
+func NewMutualTLSServer(certFile, keyFile, clientCAFile string, regMgr RegistrationManager,
+	recMgr cl.ReceiverRecordManager, logger log.Logger, opts ...ServerOption) (*Server, error) {
+	return New(append([]ServerOption{WithTLS(certFile, keyFile), WithClientCAs(clientCAFile),
+		WithRegistrationManager(regMgr), WithRecordManager(recMgr), WithLogger(logger)},
+		opts...)...)
+}
+
+// Start configures and starts the protocol server at the requested port. Servers
+// without TLS (see WithInsecure) only accept connections from the local host.
 func (s *Server) Start(port int) error {
 	connStr := fmt.Sprintf(":%d", port)
+	if s.insecure {
+		connStr = fmt.Sprintf("localhost:%d", port)
+	}
 	listener, err := net.Listen("tcp", connStr)
 	if err != nil {
 		return fmt.Errorf("could not connect: %v", err)
 	}
 
+	return s.serve(listener, fmt.Sprintf("port %d", port))
+}
+
+// StartUnix is like Start, but the server listens on a UNIX domain socket at
+// socketPath, which only the user running the server can connect to. A socket left
+// at socketPath by a previous server is replaced.
+func (s *Server) StartUnix(socketPath string) error {
+	if fi, err := os.Stat(socketPath); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(socketPath); err != nil {
+			return fmt.Errorf("could not remove previous socket: %v", err)
+		}
+	}
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("could not connect: %v", err)
+	}
+	if err := os.Chmod(socketPath, 0600); err != nil {
+		listener.Close()
+		return err
+	}
+
+	return s.serve(listener, "socket "+socketPath)
+}
+
+// serve serves metrics, if enabled, and protocols on listener, described by addr
+// in logs.
+func (s *Server) serve(listener net.Listener, addr string) error {
 	// Register Prometheus metrics handler and serve metrics page on the desired endpoint.
 	// Metrics are handled via HTTP in a separate goroutine as gRPC requests,
 	// as grpc server's performance over HTTP (GrpcServer.ServeHTTP) is much worse.
-	http.Handle("/metrics", promhttp.Handler())
+	if metricsConf := config.LoadMetricsConfig(); metricsConf.Enabled {
+		http.Handle("/metrics", promhttp.Handler())
 
-	// After this, /metrics will be available, along with /debug/requests, /debug/events in
-	// case server's EnableTracing function is called.
-	go http.ListenAndServe(":8881", nil)
+		go func() {
+			err := http.ListenAndServe(metricsConf.Address, nil)
+			s.Logger.Errorf("metrics endpoint stopped: %v", err)
+		}()
+		s.Logger.Noticef("Serving metrics at %s/metrics", metricsConf.Address)
+	}
 
 	// From here on, gRPC server will accept connections
-	s.Logger.Noticef("emmy server listening for connections on port %d", port)
+	s.Logger.Noticef("emmy server listening for connections on %s", addr)
 	s.GrpcServer.Serve(listener)
 	return nil
 }
@@ -137,33 +341,52 @@ func (s *Server) Teardown() {
 	s.GrpcServer.GracefulStop()
 }
 
-// EnableTracing instructs the gRPC framework to enable its tracing capability, which
-// is mainly used for debugging purposes.
-// Although this function does not explicitly affect the Server struct, it is wired to Server
-// in order to provide a nicer API when setting up the server.
-func (s *Server) EnableTracing() {
-	grpc.EnableTracing = true
-	s.Logger.Notice("Enabled gRPC tracing")
+// services map names of emmy's gRPC services to functions that register them with
+// the gRPC server of s.
+var services = map[string]func(s *Server){
+	"Info":              func(s *Server) { pb.RegisterInfoServer(s.GrpcServer, s) },
+	"PseudonymSystem":   func(s *Server) { pb.RegisterPseudonymSystemServer(s.GrpcServer, s) },
+	"PseudonymSystemCA": func(s *Server) { pb.RegisterPseudonymSystemCAServer(s.GrpcServer, s) },
+	"CL":                func(s *Server) { pb.RegisterCLServer(s.GrpcServer, s) },
+	"Revocation":        func(s *Server) { pb.RegisterRevocationServer(s.GrpcServer, s) },
+	"CLThreshold":       func(s *Server) { pb.RegisterCLThresholdServer(s.GrpcServer, s) },
+	"BBS":               func(s *Server) { pb.RegisterBBSServer(s.GrpcServer, s) },
+	"Steps":             func(s *Server) { pb.RegisterStepsServer(s.GrpcServer, s) },
+	"Session":           func(s *Server) { pb.RegisterSessionServer(s.GrpcServer, &sessionService{s}) },
 }
 
 // registerServices binds gRPC server interfaces to the server instance itself, as the server
-// provides implementations of these interfaces.
+// provides implementations of these interfaces. Only services given by WithServices are
+// registered, if any.
 func (s *Server) registerServices() {
-	pb.RegisterInfoServer(s.GrpcServer, s)
-	pb.RegisterPseudonymSystemServer(s.GrpcServer, s)
-	pb.RegisterPseudonymSystemCAServer(s.GrpcServer, s)
-	pb.RegisterCLServer(s.GrpcServer, s)
+	for name, register := range services {
+		if s.services == nil || s.services[name] {
+			register(s)
+		}
+	}
 
 	s.Logger.Notice("Registered gRPC Services")
 }
 
+// serves reports whether the server provides the RPC with the given full name, such
+// as /proto.PseudonymSystemCA/GenerateCertificate (see WithServices).
+func (s *Server) serves(method string) bool {
+	return s.services == nil || s.services[strings.TrimPrefix(path.Dir(method), "/proto.")]
+}
+
 func (s *Server) send(msg *pb.Message, stream pb.ServerStream) error {
+	if s.faults != nil && s.faults.dropMessage() {
+		log.With(s.Logger, "type", fmt.Sprintf("%T", msg.Content)).
+			Debug("Dropping response (injected fault)")
+		return nil
+	}
 	if err := stream.Send(msg); err != nil {
 		return fmt.Errorf("error sending message: %v", err)
 	}
 
-	s.Logger.Infof("Successfully sent response of type %T", msg.Content)
-	s.Logger.Debugf("%+v", msg)
+	logger := log.With(s.Logger, "type", fmt.Sprintf("%T", msg.Content))
+	logger.Info("Successfully sent response")
+	logger.Debugf("%+v", msg)
 
 	return nil
 }
@@ -172,11 +395,20 @@ func (s *Server) receive(stream pb.ServerStream) (*pb.Message, error) {
 	resp, err := stream.Recv()
 	if err == io.EOF {
 		return nil, err
+	} else if _, ok := status.FromError(err); ok && err != nil {
+		// errors of interceptors (e.g. exceeded limits) are passed to the client
+		return nil, err
 	} else if err != nil {
 		return nil, fmt.Errorf("an error occurred: %v", err)
 	}
-	s.Logger.Infof("Received request of type %T from the stream", resp.Content)
-	s.Logger.Debugf("%+v", resp)
+	if s.faults != nil && s.faults.dropMessage() {
+		log.With(s.Logger, "type", fmt.Sprintf("%T", resp.Content)).
+			Debug("Dropping request (injected fault)")
+		return s.receive(stream)
+	}
+	logger := log.With(s.Logger, "type", fmt.Sprintf("%T", resp.Content))
+	logger.Info("Received request from the stream")
+	logger.Debugf("%+v", resp)
 
 	return resp, nil
 }
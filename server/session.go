@@ -1,9 +1,14 @@
 package server
 
 import (
-	"crypto/rand"
+	"crypto/ecdsa"
 	"encoding/base64"
 	"fmt"
+	"io"
+	"time"
+
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/jose"
 )
 
 // SessionManager generates a new session key.
@@ -17,6 +22,24 @@ type SessionManager interface {
 // of the session key in bytes, for security reasons.
 const MIN_SESSION_KEY_BYTE_LEN = 24
 
+// SessionValidator validates session keys, returning their expiration time.
+// It is implemented by session managers whose keys can be validated without
+// storing them.
+type SessionValidator interface {
+	ValidateSessionKey(string) (time.Time, error)
+}
+
+// SessionEncoder generates session keys that carry their sessions, so that sessions
+// can be validated and refreshed from their keys alone, without a session store.
+type SessionEncoder interface {
+	// EncodeSession returns a session key for session s, and sets the expiration
+	// of s to that of the key.
+	EncodeSession(s *Session) (*string, error)
+
+	// DecodeSession validates sessionKey and returns its session.
+	DecodeSession(sessionKey string) (*Session, error)
+}
+
 // RandSessionKeyGen generates session keys of the desired byte
 // length from random bytes.
 type RandSessionKeyGen struct {
@@ -49,7 +72,7 @@ func (m *RandSessionKeyGen) GenerateSessionKey() (*string, error) {
 	randBytes := make([]byte, m.byteLen)
 
 	// reads m.byteLen random bytes (e.g. len(randBytes)) to randBytes array
-	_, err := rand.Read(randBytes)
+	_, err := io.ReadFull(common.RandReader(), randBytes)
 
 	// an error may occur if the system's secure RNG doesn't function properly, in which case
 	// we can't generate a secure session key
@@ -60,3 +83,128 @@ func (m *RandSessionKeyGen) GenerateSessionKey() (*string, error) {
 	sessionKey := base64.URLEncoding.EncodeToString(randBytes)
 	return &sessionKey, nil
 }
+
+// JWTSessionKeyGen generates session keys in the form of JWTs signed with
+// a configured key, so that relying parties can validate sessions with any JWT
+// library, using keys published at the JWKS endpoint (see JWKS).
+type JWTSessionKeyGen struct {
+	issuer     string
+	audience   string
+	ttl        time.Duration
+	signer     *jose.Signer
+	withClaims bool
+}
+
+// sessionClaims are claims of a session key in JWT form.
+type sessionClaims struct {
+	Issuer    string `json:"iss"`
+	Audience  string `json:"aud,omitempty"`
+	ID        string `json:"jti"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+	AuthTime  int64  `json:"auth_time,omitempty"`
+	// claims of the session, such as revealed attributes
+	Claims map[string]interface{} `json:"claims,omitempty"`
+}
+
+// NewJWTSessionKeyGen creates a new JWTSessionKeyGen instance, generating session
+// keys issued by issuer for audience (optional), valid for ttl and signed with key.
+func NewJWTSessionKeyGen(issuer, audience string, ttl time.Duration,
+	key *ecdsa.PrivateKey) (*JWTSessionKeyGen, error) {
+	signer, err := jose.NewSigner(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWTSessionKeyGen{
+		issuer:   issuer,
+		audience: audience,
+		ttl:      ttl,
+		signer:   signer,
+	}, nil
+}
+
+// IncludeClaims makes m put claims of sessions (for example revealed attributes of
+// credentials) into the session keys it encodes (see EncodeSession), under the
+// claims claim, so that relying parties obtain them from session keys alone.
+func (m *JWTSessionKeyGen) IncludeClaims() {
+	m.withClaims = true
+}
+
+// GenerateSessionKey produces a signed JWT with a random identifier.
+func (m *JWTSessionKeyGen) GenerateSessionKey() (*string, error) {
+	return m.EncodeSession(&Session{})
+}
+
+// EncodeSession produces a signed JWT with a random identifier for session s,
+// holding the time s was authenticated at and, if m includes them, its claims.
+func (m *JWTSessionKeyGen) EncodeSession(s *Session) (*string, error) {
+	id := make([]byte, MIN_SESSION_KEY_BYTE_LEN)
+	if _, err := io.ReadFull(common.RandReader(), id); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	claims := &sessionClaims{
+		Issuer:    m.issuer,
+		Audience:  m.audience,
+		ID:        base64.RawURLEncoding.EncodeToString(id),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(m.ttl).Unix(),
+	}
+	if !s.AuthTime.IsZero() {
+		claims.AuthTime = s.AuthTime.Unix()
+	}
+	if m.withClaims {
+		claims.Claims = s.Claims
+	}
+	sessionKey, err := m.signer.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+	s.ExpiresAt = time.Unix(claims.ExpiresAt, 0)
+
+	return &sessionKey, nil
+}
+
+// JWKS returns the set of keys that session keys can be verified with. It can be
+// served as a JWKS endpoint.
+func (m *JWTSessionKeyGen) JWKS() *jose.JWKSet {
+	return m.signer.JWKS()
+}
+
+// ValidateSessionKey verifies the signature and claims of sessionKey and returns
+// its expiration time.
+func (m *JWTSessionKeyGen) ValidateSessionKey(sessionKey string) (time.Time, error) {
+	s, err := m.DecodeSession(sessionKey)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return s.ExpiresAt, nil
+}
+
+// DecodeSession verifies the signature and claims of sessionKey and returns its
+// session.
+func (m *JWTSessionKeyGen) DecodeSession(sessionKey string) (*Session, error) {
+	claims := new(sessionClaims)
+	if err := m.signer.Verify(sessionKey, claims); err != nil {
+		return nil, err
+	}
+	if claims.Issuer != m.issuer || claims.Audience != m.audience {
+		return nil, fmt.Errorf("session key was not issued by this server")
+	}
+	expires := time.Unix(claims.ExpiresAt, 0)
+	if time.Now().After(expires) {
+		return nil, fmt.Errorf("session key expired")
+	}
+
+	s := &Session{
+		Claims:    claims.Claims,
+		ExpiresAt: expires,
+	}
+	if claims.AuthTime != 0 {
+		s.AuthTime = time.Unix(claims.AuthTime, 0)
+	}
+	return s, nil
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+)
+
+// sessionService serves the Session service of s. It is not implemented by Server
+// itself, whose ValidateSession and EndSession take session keys of the default
+// tenant directly.
+type sessionService struct {
+	s *Server
+}
+
+func (v *sessionService) ValidateSession(ctx context.Context, req *pb.SessionKey) (*pb.SessionStatus, error) {
+	v.s.Logger.Info("Client requested validation of a session key")
+	t, err := v.s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := v.s.validateSession(t, req.Value)
+	if err == errSessionsNotValidated {
+		return nil, pb.NewStatusError(codes.FailedPrecondition, pb.ErrorCode_INVALID_REQUEST,
+			err.Error())
+	}
+	if err != nil {
+		return &pb.SessionStatus{Reason: err.Error()}, nil
+	}
+
+	status := &pb.SessionStatus{
+		Valid:     true,
+		ExpiresAt: session.ExpiresAt.Unix(),
+	}
+	if !session.AuthTime.IsZero() {
+		status.AuthTime = session.AuthTime.Unix()
+	}
+	if session.Claims != nil {
+		if status.Claims, err = json.Marshal(session.Claims); err != nil {
+			v.s.Logger.Debug(err)
+			return nil, pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+				"failed to encode claims of the session")
+		}
+	}
+	return status, nil
+}
+
+func (v *sessionService) RefreshSession(ctx context.Context, req *pb.SessionKey) (*pb.SessionKey, error) {
+	v.s.Logger.Info("Client requested refresh of a session key")
+	t, err := v.s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := v.s.validateSession(t, req.Value); err != nil {
+		if err == errSessionsNotValidated {
+			return nil, pb.NewStatusError(codes.FailedPrecondition,
+				pb.ErrorCode_INVALID_REQUEST, err.Error())
+		}
+		return nil, pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_SESSION,
+			err.Error())
+	}
+	sessionKey, err := v.s.refreshSession(t, req.Value)
+	if err == errSessionLifetimeExceeded {
+		return nil, pb.NewStatusError(codes.Unauthenticated, pb.ErrorCode_INVALID_SESSION,
+			err.Error())
+	}
+	if err != nil {
+		v.s.Logger.Debug(err)
+		return nil, pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"failed to refresh session")
+	}
+
+	return &pb.SessionKey{Value: *sessionKey}, nil
+}
+
+func (v *sessionService) RevokeSession(ctx context.Context, req *pb.SessionKey) (*empty.Empty, error) {
+	v.s.Logger.Info("Client requested revocation of a session key")
+	t, err := v.s.tenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.s.sessionStore == nil {
+		return nil, pb.NewStatusError(codes.FailedPrecondition, pb.ErrorCode_INVALID_REQUEST,
+			"sessions are not stored")
+	}
+	if err := v.s.endSession(t, req.Value); err != nil {
+		v.s.Logger.Debug(err)
+		return nil, pb.NewStatusError(codes.Internal, pb.ErrorCode_INTERNAL,
+			"failed to revoke session")
+	}
+
+	return &empty.Empty{}, nil
+}
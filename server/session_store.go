@@ -0,0 +1,421 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+	pb "github.com/xlab-si/emmy/proto"
+)
+
+// ErrSessionNotFound is returned by session stores for session keys that were
+// never stored, have expired or were deleted.
+var ErrSessionNotFound = errors.New("session not found")
+
+// Session is a session of a client that successfully authenticated with the server.
+type Session struct {
+	// Claims hold what the server learned about the client (for example revealed
+	// attributes of its credential).
+	Claims    map[string]interface{} `json:"claims,omitempty"`
+	ExpiresAt time.Time              `json:"expires_at"`
+	// AuthTime is when the client authenticated, which refreshed sessions keep.
+	AuthTime time.Time `json:"auth_time"`
+}
+
+// SessionStore keeps sessions by their session keys until they expire, so that
+// session keys can be validated after a restart of the server, and by all the
+// servers sharing the store. Implementations backed by memory (MemSessionStore),
+// redis (RedisSessionStore) and SQL databases (SQLSessionStore) are provided.
+type SessionStore interface {
+	// Put stores session s under key until s expires, replacing the session
+	// stored under key, if any.
+	Put(key string, s *Session) error
+
+	// Get returns the session stored under key, or ErrSessionNotFound.
+	Get(key string) (*Session, error)
+
+	// Delete ends the session stored under key before it expires.
+	Delete(key string) error
+}
+
+// MemSessionStore keeps sessions in memory. Sessions do not survive server
+// restarts.
+type MemSessionStore struct {
+	sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewMemSessionStore returns an empty MemSessionStore.
+func NewMemSessionStore() *MemSessionStore {
+	return &MemSessionStore{
+		sessions: make(map[string]*Session),
+	}
+}
+
+// Put stores session s under key. Expired sessions are removed at the same time.
+func (m *MemSessionStore) Put(key string, s *Session) error {
+	m.Lock()
+	defer m.Unlock()
+	now := time.Now()
+	for k, sess := range m.sessions {
+		if now.After(sess.ExpiresAt) {
+			delete(m.sessions, k)
+		}
+	}
+	m.sessions[key] = s
+	return nil
+}
+
+// Get returns the session stored under key.
+func (m *MemSessionStore) Get(key string) (*Session, error) {
+	m.Lock()
+	defer m.Unlock()
+	s, ok := m.sessions[key]
+	if !ok || time.Now().After(s.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return s, nil
+}
+
+// Delete removes the session stored under key.
+func (m *MemSessionStore) Delete(key string) error {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.sessions, key)
+	return nil
+}
+
+// redisSessionPrefix separates sessions from other data in the database.
+const redisSessionPrefix = "session:"
+
+// RedisSessionStore keeps sessions in a redis database, which expires them.
+type RedisSessionStore struct {
+	*redis.Client
+}
+
+func NewRedisSessionStore(c *redis.Client) *RedisSessionStore {
+	return &RedisSessionStore{
+		Client: c,
+	}
+}
+
+// Put stores session s under key.
+func (c *RedisSessionStore) Put(key string, s *Session) error {
+	ttl := time.Until(s.ExpiresAt)
+	if ttl <= 0 {
+		return fmt.Errorf("session already expired")
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return c.Set(redisSessionPrefix+key, data, ttl).Err()
+}
+
+// Get returns the session stored under key.
+func (c *RedisSessionStore) Get(key string) (*Session, error) {
+	data, err := c.Client.Get(redisSessionPrefix + key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := new(Session)
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Delete removes the session stored under key.
+func (c *RedisSessionStore) Delete(key string) error {
+	return c.Del(redisSessionPrefix + key).Err()
+}
+
+// SQLSessionTable is the name of the table SQLSessionStore keeps sessions in.
+const SQLSessionTable = "emmy_sessions"
+
+// SQLSessionStore keeps sessions in a table of an SQL database (for example
+// PostgreSQL), accessed through database/sql. As with SQLRegistrationManager,
+// programs need to import the driver of their database.
+type SQLSessionStore struct {
+	db       *sql.DB
+	postgres bool // whether query parameters are $1, $2, ... rather than ?
+}
+
+// NewSQLSessionStore returns a SQLSessionStore keeping sessions in db, which was
+// opened with the driver with name driverName. The table for sessions is created if
+// it does not exist yet.
+func NewSQLSessionStore(db *sql.DB, driverName string) (*SQLSessionStore, error) {
+	m := &SQLSessionStore{
+		db: db,
+	}
+	switch driverName {
+	case "postgres", "pgx", "cloudsqlpostgres":
+		m.postgres = true
+	}
+
+	_, err := db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s ("+
+		"session_key VARCHAR(1024) PRIMARY KEY, data TEXT NOT NULL, expires_at BIGINT NOT NULL)",
+		SQLSessionTable))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create table for sessions: %v", err)
+	}
+
+	return m, nil
+}
+
+// Close closes the database of m.
+func (m *SQLSessionStore) Close() error {
+	return m.db.Close()
+}
+
+// param returns the placeholder of the i-th (starting with 1) query parameter.
+func (m *SQLSessionStore) param(i int) string {
+	if m.postgres {
+		return fmt.Sprintf("$%d", i)
+	}
+	return "?"
+}
+
+// Put stores session s under key, replacing the session stored under key, if any.
+// Expired sessions are removed at the same time.
+func (m *SQLSessionStore) Put(key string, s *Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	// upserts differ among databases, so the session is replaced in a transaction
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE expires_at < %s OR session_key = %s",
+		SQLSessionTable, m.param(1), m.param(2)), time.Now().Unix(), key); err != nil {
+		return err
+	}
+	_, err = tx.Exec(fmt.Sprintf(
+		"INSERT INTO %s (session_key, data, expires_at) VALUES (%s, %s, %s)",
+		SQLSessionTable, m.param(1), m.param(2), m.param(3)),
+		key, string(data), s.ExpiresAt.Unix())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Get returns the session stored under key.
+func (m *SQLSessionStore) Get(key string) (*Session, error) {
+	var data string
+	var expiresAt int64
+	err := m.db.QueryRow(fmt.Sprintf(
+		"SELECT data, expires_at FROM %s WHERE session_key = %s",
+		SQLSessionTable, m.param(1)), key).Scan(&data, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().Unix() > expiresAt {
+		return nil, ErrSessionNotFound
+	}
+
+	s := new(Session)
+	if err := json.Unmarshal([]byte(data), s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Delete removes the session stored under key.
+func (m *SQLSessionStore) Delete(key string) error {
+	_, err := m.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE session_key = %s",
+		SQLSessionTable, m.param(1)), key)
+	return err
+}
+
+// UseSessionStore makes the server keep sessions of the session keys it issues in
+// store, so that they can be validated (see ValidateSession), refreshed (see
+// RefreshSession) and ended (see EndSession). Sessions of random session keys are valid for ttl, while those of
+// session keys in JWT form expire along with their keys.
+func (s *Server) UseSessionStore(store SessionStore, ttl time.Duration) {
+	s.sessionStore = store
+	s.sessionTTL = ttl
+	s.Logger.Noticef("Sessions are kept in %T", store)
+}
+
+// SetMaxSessionLifetime limits how long after clients authenticated their sessions
+// can be refreshed (see RefreshSession). Sessions can be refreshed as long as they
+// are valid when d is 0.
+func (s *Server) SetMaxSessionLifetime(d time.Duration) {
+	s.maxSessionLifetime = d
+}
+
+// keepsClaims returns true if claims about clients are kept with their sessions, by
+// the OpenID Connect provider, the session store or in session keys.
+func (s *Server) keepsClaims() bool {
+	_, ok := s.SessionManager.(SessionEncoder)
+	return ok || s.oidcProvider != nil || s.sessionStore != nil
+}
+
+// startSession returns a new session key for a client that authenticated with tenant t
+// of the server, and the server learned claims about. It holds an ID token as well
+// when the server issues them directly (see IssueIDTokens).
+func (s *Server) startSession(t *Tenant, claims map[string]interface{}) (*pb.SessionKey,
+	error) {
+	sessionKey, err := s.issueSessionKey(t, &Session{
+		Claims:   claims,
+		AuthTime: time.Now(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	idToken, err := s.issueIDToken(*sessionKey, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SessionKey{
+		Value:   *sessionKey,
+		IdToken: idToken,
+	}, nil
+}
+
+// issueSessionKey returns a new session key for session of tenant t. The session is
+// kept in the session store, if there is one, and passed to the OpenID Connect
+// provider.
+func (s *Server) issueSessionKey(t *Tenant, session *Session) (*string, error) {
+	var sessionKey *string
+	var err error
+	if e, ok := s.SessionManager.(SessionEncoder); ok {
+		sessionKey, err = e.EncodeSession(session)
+	} else {
+		sessionKey, err = s.GenerateSessionKey()
+		session.ExpiresAt = time.Now().Add(s.sessionTTL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.sessionStore != nil {
+		if err := s.sessionStore.Put(t.sessionKey(*sessionKey), session); err != nil {
+			return nil, err
+		}
+	}
+	s.authorizeOIDC(*sessionKey, session.Claims)
+
+	return sessionKey, nil
+}
+
+// ValidateSession returns the session of sessionKey. Without a session store, only
+// session keys that can be validated on their own (see SessionValidator) can be
+// validated, and their sessions hold no claims. Sessions of tenants other than the
+// default one are not found.
+func (s *Server) ValidateSession(sessionKey string) (*Session, error) {
+	return s.validateSession(nil, sessionKey)
+}
+
+// validateSession is like ValidateSession, but for sessions of tenant t.
+func (s *Server) validateSession(t *Tenant, sessionKey string) (*Session, error) {
+	if s.sessionStore != nil {
+		return s.sessionStore.Get(t.sessionKey(sessionKey))
+	}
+	if e, ok := s.SessionManager.(SessionEncoder); ok {
+		return e.DecodeSession(sessionKey)
+	}
+	v, ok := s.SessionManager.(SessionValidator)
+	if !ok {
+		return nil, errSessionsNotValidated
+	}
+	expires, err := v.ValidateSessionKey(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{ExpiresAt: expires}, nil
+}
+
+// RefreshSession returns a new session key for the session of sessionKey, which
+// is valid for as long as new session keys are, and ends the session of sessionKey.
+// Only valid sessions can be refreshed, and only until the maximum session lifetime
+// (see SetMaxSessionLifetime) after the client authenticated. Without a session
+// store, sessionKey remains valid until it expires.
+func (s *Server) RefreshSession(sessionKey string) (*string, error) {
+	return s.refreshSession(nil, sessionKey)
+}
+
+// refreshSession is like RefreshSession, but for sessions of tenant t.
+func (s *Server) refreshSession(t *Tenant, sessionKey string) (*string, error) {
+	session, err := s.validateSession(t, sessionKey)
+	if err != nil {
+		return nil, err
+	}
+	if s.maxSessionLifetime > 0 &&
+		(session.AuthTime.IsZero() || time.Since(session.AuthTime) > s.maxSessionLifetime) {
+		return nil, errSessionLifetimeExceeded
+	}
+
+	newKey, err := s.issueSessionKey(t, &Session{
+		Claims:   session.Claims,
+		AuthTime: session.AuthTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.sessionStore != nil {
+		if err := s.sessionStore.Delete(t.sessionKey(sessionKey)); err != nil {
+			return nil, err
+		}
+	}
+
+	return newKey, nil
+}
+
+// EndSession ends the session of sessionKey before it expires. It requires a
+// session store.
+func (s *Server) EndSession(sessionKey string) error {
+	return s.endSession(nil, sessionKey)
+}
+
+// endSession is like EndSession, but for sessions of tenant t.
+func (s *Server) endSession(t *Tenant, sessionKey string) error {
+	if s.sessionStore == nil {
+		return fmt.Errorf("sessions are not stored")
+	}
+	return s.sessionStore.Delete(t.sessionKey(sessionKey))
+}
+
+// errSessionsNotValidated is returned by ValidateSession when sessions can not be
+// validated at all.
+var errSessionsNotValidated = errors.New("session keys of this server cannot be validated")
+
+// errSessionLifetimeExceeded is returned by RefreshSession for sessions that were
+// authenticated longer than the maximum session lifetime ago.
+var errSessionLifetimeExceeded = errors.New("session cannot be refreshed anymore")
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// drainer keeps track of protocol streams in progress, so that the server can wait
+// for them to finish when it shuts down.
+type drainer struct {
+	sync.Mutex
+	closing bool
+	streams sync.WaitGroup
+}
+
+// start registers a new stream, unless the server is shutting down.
+func (d *drainer) start() bool {
+	d.Lock()
+	defer d.Unlock()
+	if d.closing {
+		return false
+	}
+	d.streams.Add(1)
+	return true
+}
+
+// close makes the drainer reject new streams.
+func (d *drainer) close() {
+	d.Lock()
+	d.closing = true
+	d.Unlock()
+}
+
+// streamDrainInterceptor rejects protocol streams once the server is shutting down,
+// and tracks the others with d. As streams of the gRPC-Web handler, the gateway and
+// the Steps service are run through the interceptors of the server as well, they
+// are tracked too.
+func streamDrainInterceptor(d *drainer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		if !d.start() {
+			return status.Error(codes.Unavailable, "server is shutting down")
+		}
+		defer d.streams.Done()
+		return handler(srv, ss)
+	}
+}
+
+// Shutdown gracefully shuts the server down. It stops accepting new connections and
+// protocol streams, and waits for streams in progress to finish. If ctx is done before
+// they finish, they are aborted and ctx.Err() is returned. Finally, the stores of the
+// server (registration keys, CL records, sessions, nonces and devices) that can be
+// closed are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.Logger.Notice("Shutting down, waiting for protocol sessions in progress to finish")
+	s.drain.close()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.GrpcServer.GracefulStop()
+		s.drain.streams.Wait()
+		close(stopped)
+	}()
+
+	var err error
+	select {
+	case <-stopped:
+		s.Logger.Notice("All protocol sessions finished")
+	case <-ctx.Done():
+		s.Logger.Warning("Aborting protocol sessions in progress")
+		s.GrpcServer.Stop()
+		s.steps.abort()
+		err = ctx.Err()
+	}
+
+	if closeErr := s.closeStores(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// closeStores closes the stores of the server that implement io.Closer, and returns
+// the first error.
+func (s *Server) closeStores() error {
+	stores := []interface{}{s.RegistrationManager, s.clRecordManager, s.sessionStore, s.nonces}
+	if s.deviceBinding != nil {
+		stores = append(stores, s.deviceBinding.store)
+	}
+
+	var err error
+	for _, store := range stores {
+		c, ok := store.(io.Closer)
+		if !ok {
+			continue
+		}
+		if closeErr := c.Close(); closeErr != nil {
+			s.Logger.Warningf("Cannot close %T: %v", store, closeErr)
+			if err == nil {
+				err = closeErr
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Step passes the message of req to the protocol run the request belongs to (starting
+// a new run of req.Method if req carries no session), and returns the next message of
+// the server. Runs are kept by the server between steps, as streams of the gRPC-Web
+// handler are, so clients that cannot keep streams open can run protocols with a
+// sequence of unary calls. A step without a message ends the client's side of the run.
+func (s *Server) Step(ctx context.Context, req *pb.ProtocolStep) (*pb.ProtocolStep, error) {
+	method, ok := pb.StreamMethods[req.Method]
+	if !ok || !s.serves(method) {
+		return nil, status.Errorf(codes.Unimplemented, "unknown method %s", req.Method)
+	}
+
+	session, resp, err := s.steps.exchange(ctx, req.Session, method, req.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ProtocolStep{
+		Method:  req.Method,
+		Session: session,
+		Message: resp,
+		Done:    resp == nil,
+	}, nil
+}
@@ -0,0 +1,232 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/xlab-si/emmy/config"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// Tenant is an independent issuer and verifier served by the server besides its
+// default one. It has its own CL keys, credential schemas, acceptable credentials,
+// keys of organizations and of the CA of the pseudonym system, and namespace of
+// sessions, all given by its configuration. Other settings of the server (for example
+// registration keys, revocation or device binding) are shared by all the tenants,
+// and key stores and threshold issuance are only used by the default tenant.
+type Tenant struct {
+	ID      string
+	conf    *config.Config
+	orgs    *OrgRegistry
+	schemas *SchemaRegistry
+}
+
+// NewTenant creates tenant id, reading its keys and schemas from conf.
+func NewTenant(id string, conf *config.Config) (*Tenant, error) {
+	if id == "" {
+		return nil, fmt.Errorf("tenant needs an ID")
+	}
+	schemas, err := newSchemaRegistry(conf)
+	if err != nil {
+		return nil, fmt.Errorf("credential schemas of tenant %s: %v", id, err)
+	}
+	if err := validatePseudonymsysParams(conf); err != nil {
+		return nil, fmt.Errorf("tenant %s: %v", id, err)
+	}
+	t := &Tenant{
+		ID:      id,
+		conf:    conf,
+		schemas: schemas,
+	}
+	// tenants may only issue CL credentials, without organizations of the
+	// pseudonym system
+	if len(conf.LoadPseudonymsysOrgNames()) > 0 {
+		if t.orgs, err = newOrgRegistry(conf); err != nil {
+			return nil, fmt.Errorf("organizations of tenant %s: %v", id, err)
+		}
+	}
+
+	return t, nil
+}
+
+// config returns the configuration of t, which is the default configuration for the
+// default tenant (nil).
+func (t *Tenant) config() *config.Config {
+	if t == nil {
+		return config.Default()
+	}
+	return t.conf
+}
+
+// sessionKey returns the key under which the session of sessionKey of t is stored,
+// so that tenants cannot validate each other's sessions.
+func (t *Tenant) sessionKey(sessionKey string) string {
+	if t == nil {
+		return sessionKey
+	}
+	return t.ID + "/" + sessionKey
+}
+
+// revocationOf returns revocation of CL credentials of tenant t, which is only
+// available to the default tenant.
+func (s *Server) revocationOf(t *Tenant) *revocation {
+	if t != nil {
+		return nil
+	}
+	return s.revocation
+}
+
+// AddTenant makes the server serve tenant t, selected by clients with its ID (see
+// pb.TenantMetadataKey). A tenant with the same ID is replaced.
+func (s *Server) AddTenant(t *Tenant) {
+	s.tenantsLock.Lock()
+	defer s.tenantsLock.Unlock()
+	if s.tenants == nil {
+		s.tenants = make(map[string]*Tenant)
+	}
+	s.tenants[t.ID] = t
+	s.Logger.Noticef("Serving tenant %s", t.ID)
+}
+
+// lookupTenant returns tenant id, or nil (the default tenant) when id is empty.
+func (s *Server) lookupTenant(id string) (*Tenant, error) {
+	if id == "" {
+		return nil, nil
+	}
+	s.tenantsLock.RLock()
+	defer s.tenantsLock.RUnlock()
+	t, ok := s.tenants[id]
+	if !ok {
+		return nil, pb.NewStatusError(codes.NotFound, pb.ErrorCode_UNKNOWN_TENANT,
+			fmt.Sprintf("unknown tenant %s", id))
+	}
+	return t, nil
+}
+
+// tenantKey is the key of the *tenantRef of protocol streams in their context.
+type tenantKey struct{}
+
+// tenantRef holds the tenant of a protocol stream, which can also be selected by the
+// first message of the stream.
+type tenantRef struct {
+	id     string
+	tenant *Tenant
+}
+
+// tenant returns the tenant that a request with context ctx is served for, given in
+// the metadata of the request or in the first message of a protocol stream. The
+// default tenant is nil.
+func (s *Server) tenant(ctx context.Context) (*Tenant, error) {
+	if ref, ok := ctx.Value(tenantKey{}).(*tenantRef); ok {
+		return ref.tenant, nil
+	}
+	return s.lookupTenant(metadataTenant(ctx))
+}
+
+// metadataTenant returns the ID of the tenant in the incoming metadata of ctx.
+func metadataTenant(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if v := md[pb.TenantMetadataKey]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// tenantContext returns the context of HTTP request r, holding the tenant given by
+// its header as incoming metadata and the address of the client as its peer, as gRPC
+// requests do.
+func tenantContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+	}
+	id := r.Header.Get(pb.TenantMetadataKey)
+	if id == "" {
+		return ctx
+	}
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(pb.TenantMetadataKey, id))
+}
+
+// streamTenantInterceptor selects the tenant of protocol streams by their metadata,
+// or by the tenant of their first message, and rejects streams of unknown tenants.
+func streamTenantInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		s := srv.(*Server)
+		id := metadataTenant(ss.Context())
+		t, err := s.lookupTenant(id)
+		if err != nil {
+			return err
+		}
+		ref := &tenantRef{id: id, tenant: t}
+		return handler(srv, &tenantStream{
+			ServerStream: ss,
+			server:       s,
+			ctx:          context.WithValue(ss.Context(), tenantKey{}, ref),
+			ref:          ref,
+		})
+	}
+}
+
+// tenantStream is a server stream that selects its tenant by the first message
+// received, if the tenant was not given in metadata.
+type tenantStream struct {
+	grpc.ServerStream
+	server   *Server
+	ctx      context.Context
+	ref      *tenantRef
+	received bool
+}
+
+func (st *tenantStream) Context() context.Context {
+	return st.ctx
+}
+
+func (st *tenantStream) RecvMsg(m interface{}) error {
+	if err := st.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	first := !st.received
+	st.received = true
+	msg, ok := m.(*pb.Message)
+	if !ok || msg.Tenant == "" || msg.Tenant == st.ref.id {
+		return nil
+	}
+	if !first || st.ref.id != "" {
+		return pb.NewStatusError(codes.InvalidArgument, pb.ErrorCode_INVALID_REQUEST,
+			"tenant cannot be changed during the protocol")
+	}
+
+	t, err := st.server.lookupTenant(msg.Tenant)
+	if err != nil {
+		return err
+	}
+	st.ref.id, st.ref.tenant = msg.Tenant, t
+	return nil
+}
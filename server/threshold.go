@@ -0,0 +1,339 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/qr"
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UseThresholdShare makes the server one of the parties of threshold issuance of CL
+// credentials, taking part in signing with its share of the secret key when the
+// coordinator of issuance calls ThresholdSign. As the protocol assumes parties are
+// only reached by the coordinator, such servers should require client certificates
+// (see WithClientCAs).
+func (s *Server) UseThresholdShare(p *cl.ThresholdParty) {
+	s.thresholdParty = p
+	s.Logger.Noticef("Taking part in threshold issuance of CL credentials as party %d",
+		p.Index())
+}
+
+// UseThresholdCoordinator makes the server issue CL credentials without the secret
+// key, by coordinating threshold signing with parties holding its shares. Revocation
+// needs the secret key, thus it cannot be used along with threshold issuance.
+func (s *Server) UseThresholdCoordinator(c *ThresholdCoordinator) error {
+	if s.revocation != nil {
+		return fmt.Errorf("threshold issuance cannot be used with revocation")
+	}
+	s.thresholdCoordinator = c
+	s.Logger.Noticef("Issuing CL credentials with %d of %d parties", c.threshold,
+		len(c.parties))
+	return nil
+}
+
+// ThresholdSign runs a signing session of the party with the coordinator, who sends
+// inputs of rounds of the protocol (see cl.ThresholdSession) and receives the
+// party's outputs: the commitment, ciphertexts for other signers, the share of z,
+// the partial signature and the share of the response of the proof.
+func (s *Server) ThresholdSign(stream pb.CLThreshold_ThresholdSignServer) error {
+	if s.thresholdParty == nil {
+		return status.Error(codes.FailedPrecondition,
+			"server holds no share of the CL secret key")
+	}
+	session := s.thresholdParty.NewSession()
+
+	req, err := s.receive(stream)
+	if err != nil {
+		return err
+	}
+	init := req.GetCLThresholdInit()
+	if init == nil {
+		return status.Error(codes.InvalidArgument, "threshold signing not initialized")
+	}
+	signers := make([]int, len(init.Signers))
+	for i, signer := range init.Signers {
+		signers[i] = int(signer)
+	}
+	commitment, err := session.Commit(new(big.Int).SetBytes(init.E), signers)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp := &pb.Message{
+		Content: &pb.Message_CLThresholdCommitment{
+			CLThresholdCommitment: pb.ToPbCLThresholdCommitment(commitment),
+		},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	if req, err = s.receive(stream); err != nil {
+		return err
+	}
+	commitments := req.GetCLThresholdCommitments()
+	if commitments == nil {
+		return status.Error(codes.InvalidArgument, "commitments of signers expected")
+	}
+	nativeCommitments, err := commitments.GetNativeType()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	cts, err := session.MtA(nativeCommitments)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp = &pb.Message{
+		Content: &pb.Message_CLThresholdCiphertexts{
+			CLThresholdCiphertexts: pb.ToPbCLThresholdCiphertexts(cts),
+		},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	if req, err = s.receive(stream); err != nil {
+		return err
+	}
+	received := req.GetCLThresholdCiphertexts()
+	if received == nil {
+		return status.Error(codes.InvalidArgument, "ciphertexts of signers expected")
+	}
+	nativeCts, err := received.GetNativeType()
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	w, err := session.Mask(nativeCts)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp = &pb.Message{
+		Content: &pb.Message_CLThresholdValue{
+			CLThresholdValue: pb.ToPbCLThresholdValue(w),
+		},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	if req, err = s.receive(stream); err != nil {
+		return err
+	}
+	partial, err := session.PartialSign(new(big.Int).SetBytes(req.GetBigint().GetX1()))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp = &pb.Message{
+		Content: &pb.Message_CLPartialSignature{
+			CLPartialSignature: pb.ToPbCLPartialSignature(partial),
+		},
+	}
+	if err := s.send(resp, stream); err != nil {
+		return err
+	}
+
+	if req, err = s.receive(stream); err != nil {
+		return err
+	}
+	sResp, err := session.Respond(new(big.Int).SetBytes(req.GetBigint().GetX1()))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp = &pb.Message{
+		Content: &pb.Message_CLThresholdValue{
+			CLThresholdValue: pb.ToPbCLThresholdValue(sResp),
+		},
+	}
+	return s.send(resp, stream)
+}
+
+// ThresholdCoordinator signs CL credentials jointly with parties holding shares of
+// the secret key of the organization, which it reaches over gRPC connections.
+type ThresholdCoordinator struct {
+	pub       *cl.PubKey
+	threshold int
+	parties   map[int]*grpc.ClientConn
+}
+
+// NewThresholdCoordinator returns a coordinator of threshold signing with the public
+// key pub, where threshold of parties (given by connections to them, indexed by
+// indices of their shares) are needed to sign.
+func NewThresholdCoordinator(pub *cl.PubKey, threshold int,
+	parties map[int]*grpc.ClientConn) (*ThresholdCoordinator, error) {
+	if threshold < 2 || threshold > len(parties) {
+		return nil, fmt.Errorf("threshold %d not possible with %d parties", threshold,
+			len(parties))
+	}
+	return &ThresholdCoordinator{
+		pub:       pub,
+		threshold: threshold,
+		parties:   parties,
+	}, nil
+}
+
+// Sign computes the CL signature of Q with prime e and the proof that it is correctly
+// formed for nonceUser with the first threshold of parties that can be reached.
+func (c *ThresholdCoordinator) Sign(Q, e, nonceUser *big.Int) (*big.Int,
+	*qr.RepresentationProof, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	indices := make([]int, 0, len(c.parties))
+	for i := range c.parties {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+
+	sessions := make(map[int]cl.ThresholdSession, c.threshold)
+	for _, i := range indices {
+		if len(sessions) == c.threshold {
+			break
+		}
+		stream, err := pb.NewCLThresholdClient(c.parties[i]).ThresholdSign(ctx)
+		if err != nil {
+			continue
+		}
+		sessions[i] = &remoteThresholdSession{
+			stream: stream,
+		}
+	}
+	if len(sessions) < c.threshold {
+		return nil, nil, fmt.Errorf("only %d parties reachable, %d needed for signing",
+			len(sessions), c.threshold)
+	}
+
+	return cl.ThresholdSign(c.pub, Q, e, nonceUser, sessions)
+}
+
+// remoteThresholdSession is a signing session with a party reached over a stream.
+type remoteThresholdSession struct {
+	stream pb.CLThreshold_ThresholdSignClient
+}
+
+func (r *remoteThresholdSession) roundTrip(msg *pb.Message) (*pb.Message, error) {
+	if err := r.stream.Send(msg); err != nil {
+		return nil, err
+	}
+	return r.stream.Recv()
+}
+
+func (r *remoteThresholdSession) Commit(e *big.Int, signers []int) (*cl.ThresholdCommitment,
+	error) {
+	pbSigners := make([]int32, len(signers))
+	for i, signer := range signers {
+		pbSigners[i] = int32(signer)
+	}
+	resp, err := r.roundTrip(&pb.Message{
+		Content: &pb.Message_CLThresholdInit{
+			CLThresholdInit: &pb.CLThresholdInit{
+				E:       e.Bytes(),
+				Signers: pbSigners,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	commitment := resp.GetCLThresholdCommitment()
+	if commitment == nil {
+		return nil, fmt.Errorf("commitment expected")
+	}
+	return commitment.GetNativeType()
+}
+
+func (r *remoteThresholdSession) MtA(commitments []*cl.ThresholdCommitment) (
+	[]*cl.ThresholdCiphertext, error) {
+	resp, err := r.roundTrip(&pb.Message{
+		Content: &pb.Message_CLThresholdCommitments{
+			CLThresholdCommitments: pb.ToPbCLThresholdCommitments(commitments),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	cts := resp.GetCLThresholdCiphertexts()
+	if cts == nil {
+		return nil, fmt.Errorf("ciphertexts expected")
+	}
+	return cts.GetNativeType()
+}
+
+func (r *remoteThresholdSession) Mask(ciphertexts []*cl.ThresholdCiphertext) (*big.Int,
+	error) {
+	resp, err := r.roundTrip(&pb.Message{
+		Content: &pb.Message_CLThresholdCiphertexts{
+			CLThresholdCiphertexts: pb.ToPbCLThresholdCiphertexts(ciphertexts),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return thresholdValue(resp)
+}
+
+func (r *remoteThresholdSession) PartialSign(B *big.Int) (*cl.PartialSignature, error) {
+	resp, err := r.roundTrip(&pb.Message{
+		Content: &pb.Message_Bigint{
+			Bigint: &pb.BigInt{
+				X1: B.Bytes(),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	partial := resp.GetCLPartialSignature()
+	if partial == nil {
+		return nil, fmt.Errorf("partial signature expected")
+	}
+	return partial.GetNativeType()
+}
+
+func (r *remoteThresholdSession) Respond(challenge *big.Int) (*big.Int, error) {
+	resp, err := r.roundTrip(&pb.Message{
+		Content: &pb.Message_Bigint{
+			Bigint: &pb.BigInt{
+				X1: challenge.Bytes(),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := r.stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return thresholdValue(resp)
+}
+
+// thresholdValue returns the share of a value held by resp.
+func thresholdValue(resp *pb.Message) (*big.Int, error) {
+	v := resp.GetCLThresholdValue()
+	if v == nil {
+		return nil, fmt.Errorf("share of value expected")
+	}
+	return v.GetNativeType()
+}
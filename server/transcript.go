@@ -0,0 +1,62 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"math/big"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/record"
+)
+
+// ExportTranscripts makes the server pass a transcript of each verification of a
+// proof of a CL credential to export, whether the proof was accepted or not. The
+// transcripts can be verified again offline with record.VerifyTranscript.
+func (s *Server) ExportTranscripts(export func(*record.Transcript) error) {
+	s.transcripts = export
+}
+
+// exportTranscript exports the transcript of the verification of proof pReq of a CL
+// credential of tenant t, built with nonce, which ended with err.
+func (s *Server) exportTranscript(protocol string, t *Tenant, org *cl.Org,
+	pReq *pb.ProveCLCredential, nonce *big.Int, err error) {
+	if s.transcripts == nil || pReq == nil || pReq.Proof == nil {
+		return
+	}
+
+	var tenant, reason string
+	if t != nil {
+		tenant = t.ID
+	}
+	if err != nil {
+		if pe := pb.ToProtocolError(err); pe != nil {
+			reason = pe.Code.String()
+		} else {
+			reason = err.Error()
+		}
+	}
+	tr, err := record.NewTranscript(protocol, tenant, org.Params, org.Keys.Pub, nonce, pReq,
+		reason)
+	if err == nil {
+		err = s.transcripts(tr)
+	}
+	if err != nil {
+		s.Logger.Errorf("cannot export transcript of %s: %v", protocol, err)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"context"
+
+	pb "github.com/xlab-si/emmy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// versionKey is the key of the *versionStream of protocol streams in their context.
+type versionKey struct{}
+
+// protocolVersion returns the version of protocol messages negotiated for the stream
+// with context ctx, which handlers use to encode and decode messages whose wire
+// format depends on it. It is 0 before the first message of the client is received.
+func protocolVersion(ctx context.Context) int32 {
+	if st, ok := ctx.Value(versionKey{}).(*versionStream); ok {
+		return st.version
+	}
+	return 0
+}
+
+// streamVersionInterceptor negotiates the version of protocol messages of streams with
+// the version given in the first message of the client (see
+// pb.NegotiateProtocolVersion), rejecting clients that only support versions the
+// server no longer supports, and gives the negotiated version in all the messages
+// sent to the client.
+func streamVersionInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		st := &versionStream{ServerStream: ss}
+		st.ctx = context.WithValue(ss.Context(), versionKey{}, st)
+		return handler(srv, st)
+	}
+}
+
+// versionStream is a server stream that negotiates the version of protocol messages
+// by the first message received.
+type versionStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	version int32
+}
+
+func (st *versionStream) Context() context.Context {
+	return st.ctx
+}
+
+func (st *versionStream) RecvMsg(m interface{}) error {
+	if err := st.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	msg, ok := m.(*pb.Message)
+	if !ok {
+		return nil
+	}
+	if st.version == 0 {
+		v, err := pb.NegotiateProtocolVersion(msg.ProtocolVersion)
+		if err != nil {
+			return err
+		}
+		st.version = v
+		return nil
+	}
+	if msg.ProtocolVersion != 0 && msg.ProtocolVersion != st.version {
+		return pb.NewStatusError(codes.InvalidArgument, pb.ErrorCode_INVALID_REQUEST,
+			"protocol version cannot be changed during the protocol")
+	}
+
+	return nil
+}
+
+func (st *versionStream) SendMsg(m interface{}) error {
+	if msg, ok := m.(*pb.Message); ok && st.version != 0 {
+		msg.ProtocolVersion = st.version
+	}
+	return st.ServerStream.SendMsg(m)
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package server
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	pb "github.com/xlab-si/emmy/proto"
+	"github.com/xlab-si/emmy/webauthn"
+)
+
+// deviceBinding verifies that CL credentials are bound to WebAuthn authenticators.
+type deviceBinding struct {
+	rp    *webauthn.RelyingParty
+	store webauthn.CredentialStore
+	attr  string // name of the binding attribute
+}
+
+// EnableDeviceBinding requires CL credentials to be bound to WebAuthn authenticators
+// registered with relying party rp. The binding is held by the known string attribute
+// attr of the credential structure of each tenant. Credentials registered at issuance
+// are kept in store, and proofs of credentials need to reveal attr and include a fresh
+// assertion of the registered authenticator.
+func (s *Server) EnableDeviceBinding(rp *webauthn.RelyingParty, store webauthn.CredentialStore,
+	attr string) error {
+	b := &deviceBinding{
+		rp:    rp,
+		store: store,
+		attr:  attr,
+	}
+	// tenants are checked when their credentials are issued or proved, as they can be
+	// added later
+	if _, err := b.index(config.Default()); err != nil {
+		return err
+	}
+
+	s.deviceBinding = b
+	s.Logger.Noticef("Enabled WebAuthn device binding with relying party %s", rp.ID)
+	return nil
+}
+
+// index returns the index of the binding attribute among known attributes of
+// credentials with the structure configured in conf.
+func (b *deviceBinding) index(conf *config.Config) (int, error) {
+	structure, err := conf.LoadCredentialStructure()
+	if err != nil {
+		return 0, err
+	}
+	attrs, _, err := cl.ParseAttrs(structure)
+	if err != nil {
+		return 0, err
+	}
+
+	index := 0
+	for _, a := range attrs {
+		if !a.IsKnown() {
+			continue
+		}
+		if a.GetName() == b.attr {
+			if _, ok := a.(*cl.StrAttr); !ok {
+				return 0, fmt.Errorf("device binding attribute %s is not a string attribute",
+					b.attr)
+			}
+			return index, nil
+		}
+		index++
+	}
+
+	return 0, fmt.Errorf("device binding attribute %s is not a known attribute", b.attr)
+}
+
+// register verifies registration r, made with a challenge of the issuance session with
+// the given nonce, checks that credReq, for a credential with the structure configured
+// in conf, holds the binding of the registered credential and stores the credential.
+func (b *deviceBinding) register(conf *config.Config, r *pb.WebAuthnRegistration,
+	nonce *big.Int, credReq *cl.CredRequest) error {
+	if r == nil {
+		return fmt.Errorf("no device registration")
+	}
+	index, err := b.index(conf)
+	if err != nil {
+		return err
+	}
+	cred, err := b.rp.VerifyRegistration(r.GetNativeType(), webauthn.Challenge(nonce, credReq.Nym))
+	if err != nil {
+		return err
+	}
+	if index >= len(credReq.KnownAttrs) ||
+		credReq.KnownAttrs[index].Cmp(bindingValue(cred.Binding())) != 0 {
+		return fmt.Errorf("credential request does not hold the device binding")
+	}
+
+	return b.store.Store(cred)
+}
+
+// verify verifies assertion a, made with a challenge of the proving session with
+// the given nonce, by the authenticator the proved credential, with the structure
+// configured in conf, is bound to.
+func (b *deviceBinding) verify(conf *config.Config, a *pb.WebAuthnAssertion, nonce, A *big.Int,
+	revealedKnownAttrsIndices []int, revealedKnownAttrs []*big.Int) error {
+	if a == nil {
+		return fmt.Errorf("no device assertion")
+	}
+	index, err := b.index(conf)
+	if err != nil {
+		return err
+	}
+
+	var binding *big.Int
+	for i, idx := range revealedKnownAttrsIndices {
+		if idx == index && i < len(revealedKnownAttrs) {
+			binding = revealedKnownAttrs[i]
+		}
+	}
+	if binding == nil {
+		return fmt.Errorf("device binding attribute is not revealed")
+	}
+	id, err := cl.DefaultAttrEncoder.DecodeString(binding)
+	if err != nil {
+		return fmt.Errorf("invalid device binding: %v", err)
+	}
+
+	cred, err := b.store.Load(id)
+	if err != nil {
+		return err
+	}
+	if err := b.rp.VerifyAssertion(cred, a.GetNativeType(), webauthn.Challenge(nonce, A)); err != nil {
+		return err
+	}
+
+	// store the updated signature counter
+	return b.store.Store(cred)
+}
+
+// checkUpdate makes sure that an update of known attributes of a credential with
+// record rec and the structure configured in conf keeps its device binding.
+func (b *deviceBinding) checkUpdate(conf *config.Config, rec *cl.ReceiverRecord,
+	newKnownAttrs []*big.Int) error {
+	index, err := b.index(conf)
+	if err != nil {
+		return err
+	}
+	if index >= len(rec.KnownAttrs) || index >= len(newKnownAttrs) ||
+		rec.KnownAttrs[index].Cmp(newKnownAttrs[index]) != 0 {
+		return fmt.Errorf("device binding attribute cannot be updated")
+	}
+
+	return nil
+}
+
+// bindingValue returns the internal value of the binding attribute holding binding.
+func bindingValue(binding string) *big.Int {
+	attr, _ := cl.NewStrAttr("", binding, true)
+	return attr.InternalValue()
+}
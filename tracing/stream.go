@@ -0,0 +1,185 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	pb "github.com/xlab-si/emmy/proto"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// rounds traces rounds of a protocol execution in children of the protocol span.
+type rounds struct {
+	sync.Mutex
+	protocol string
+	ctx      context.Context // carries the span of the protocol
+	roundCtx context.Context // carries the span of the current round
+	round    trace.Span
+	kind     trace.SpanKind
+}
+
+// start ends the current round and starts a new one, named after the protocol and
+// the type of msg that opened it.
+func (r *rounds) start(msg interface{}) {
+	r.Lock()
+	defer r.Unlock()
+	if r.round != nil {
+		r.round.End()
+	}
+	r.roundCtx, r.round = StartSpan(r.ctx, r.protocol+"/"+messageName(msg),
+		trace.WithSpanKind(r.kind))
+}
+
+// end ends the current round, if any, recording err.
+func (r *rounds) end(err error) {
+	r.Lock()
+	defer r.Unlock()
+	if r.round != nil {
+		End(r.round, err)
+		r.round = nil
+	}
+}
+
+// context returns the context of the current round, or the context of the
+// protocol between rounds.
+func (r *rounds) context() context.Context {
+	r.Lock()
+	defer r.Unlock()
+	if r.round != nil {
+		return r.roundCtx
+	}
+	return r.ctx
+}
+
+// messageName returns the name of the content of msg, e.g. CLCredReq.
+func messageName(msg interface{}) string {
+	m, ok := msg.(*pb.Message)
+	if !ok || m.Content == nil {
+		return "Message"
+	}
+	name := fmt.Sprintf("%T", m.Content)
+	return name[strings.LastIndex(name, "_")+1:]
+}
+
+// serverStream traces a protocol execution over a gRPC server stream.
+type serverStream struct {
+	grpc.ServerStream
+	rounds *rounds
+}
+
+// NewServerStream starts a span of the execution of protocol over ss, continuing
+// the trace propagated by the client, and returns a stream that traces each round
+// of the protocol, from a message of the client to the server's response to it.
+// The context of the returned stream carries the span of the current round, so
+// that handlers can trace their steps with StartSpan. The returned function ends
+// the span of the execution, recording the error the handler returned.
+func NewServerStream(ss grpc.ServerStream, protocol string) (grpc.ServerStream, func(error)) {
+	ctx, span := StartSpan(Extract(ss.Context()), protocol,
+		trace.WithSpanKind(trace.SpanKindServer))
+	r := &rounds{
+		protocol: protocol,
+		ctx:      ctx,
+		kind:     trace.SpanKindServer,
+	}
+	end := func(err error) {
+		r.end(err)
+		End(span, err)
+	}
+	return &serverStream{ServerStream: ss, rounds: r}, end
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.rounds.context()
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.rounds.start(m)
+	return nil
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	s.rounds.end(err)
+	return err
+}
+
+// ClientStream traces a protocol execution over a client stream of an emmy
+// protocol.
+type ClientStream struct {
+	pb.ClientStream
+	rounds *rounds
+	span   trace.Span
+}
+
+// StartClient starts a span of the execution of protocol and returns a copy of ctx
+// that carries it, both in-process and in outgoing gRPC metadata. The stream of the
+// protocol should be opened with the returned context and wrapped with
+// NewClientStream.
+func StartClient(ctx context.Context, protocol string) (context.Context, trace.Span) {
+	ctx, span := StartSpan(ctx, protocol, trace.WithSpanKind(trace.SpanKindClient))
+	return Inject(ctx), span
+}
+
+// NewClientStream returns a client stream that traces each round of the protocol
+// run over cs, from a message of the client to the server's response to it, in
+// children of the span in ctx, as returned by StartClient.
+func NewClientStream(ctx context.Context, cs pb.ClientStream, protocol string) *ClientStream {
+	return &ClientStream{
+		ClientStream: cs,
+		rounds: &rounds{
+			protocol: protocol,
+			ctx:      ctx,
+			kind:     trace.SpanKindClient,
+		},
+		span: trace.SpanFromContext(ctx),
+	}
+}
+
+func (s *ClientStream) Send(msg *pb.Message) error {
+	s.rounds.start(msg)
+	if err := s.ClientStream.Send(msg); err != nil {
+		s.rounds.end(err)
+		return err
+	}
+	return nil
+}
+
+func (s *ClientStream) Recv() (*pb.Message, error) {
+	msg, err := s.ClientStream.Recv()
+	if err == io.EOF {
+		s.rounds.end(nil)
+	} else {
+		s.rounds.end(err)
+	}
+	return msg, err
+}
+
+// End ends the span of the protocol execution, recording err.
+func (s *ClientStream) End(err error) {
+	s.rounds.end(err)
+	End(s.span, err)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package tracing traces executions of emmy protocols with OpenTelemetry.
+//
+// Each execution is traced in a span of the protocol (named after its stream, e.g.
+// IssueCredential), with a child span for every round of the protocol. Trace context
+// is propagated from clients to the server in gRPC metadata, so that the spans of
+// both sides of an execution end up in the same trace. Spans are exported by the
+// global TracerProvider (see otel.SetTracerProvider), so nothing is recorded unless
+// an application sets one up.
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// TracerName is the name of the tracer that emmy creates its spans with.
+const TracerName = "github.com/xlab-si/emmy"
+
+// propagator propagates trace context in W3C Trace Context format.
+var propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+// StartSpan starts a span named name as a child of the span in ctx. Handlers of
+// protocols call it with the context of their stream to trace steps of a round,
+// such as verification of a proof.
+func StartSpan(ctx context.Context, name string,
+	opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, opts...)
+}
+
+// End ends span, setting its status to error if err is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Inject returns a copy of ctx whose outgoing gRPC metadata carries the trace
+// context of the span in ctx.
+func Inject(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	propagator.Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// Extract returns a copy of ctx that carries the remote span propagated in its
+// incoming gRPC metadata, if any.
+func Extract(ctx context.Context) context.Context {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return propagator.Extract(ctx, metadataCarrier(md))
+}
+
+// metadataCarrier adapts gRPC metadata to propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c)[strings.ToLower(key)]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c)[strings.ToLower(key)] = []string{value}
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
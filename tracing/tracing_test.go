@@ -0,0 +1,65 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	pb "github.com/xlab-si/emmy/proto"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestPropagation(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	defer otel.SetTracerProvider(prev)
+
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("key", "value"))
+	ctx, span := StartClient(ctx, "Protocol")
+	span.End()
+
+	out, ok := metadata.FromOutgoingContext(ctx)
+	assert.True(t, ok)
+	// existing metadata is kept
+	assert.Equal(t, []string{"value"}, out["key"])
+	assert.Len(t, out["traceparent"], 1)
+
+	remote := trace.SpanContextFromContext(Extract(metadata.NewIncomingContext(
+		context.Background(), out)))
+	assert.True(t, remote.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), remote.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), remote.SpanID())
+
+	// without metadata, no span is extracted
+	assert.False(t, trace.SpanContextFromContext(Extract(context.Background())).IsValid())
+}
+
+func TestMessageName(t *testing.T) {
+	assert.Equal(t, "SchnorrProofData", messageName(&pb.Message{
+		Content: &pb.Message_SchnorrProofData{SchnorrProofData: &pb.SchnorrProofData{}},
+	}))
+	assert.Equal(t, "Message", messageName(&pb.Message{}))
+	assert.Equal(t, "Message", messageName(nil))
+}
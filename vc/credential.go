@@ -0,0 +1,339 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package vc converts emmy's CL credentials and presentations to and from the
+// W3C Verifiable Credentials Data Model (https://www.w3.org/TR/vc-data-model/),
+// both in JSON-LD and JWT form, so they can be handled by VC-aware wallets and verifiers.
+// Known attributes of CL credentials can also be issued as SD-JWT VCs.
+package vc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+)
+
+const (
+	// ContextV1 is the base JSON-LD context of the VC data model.
+	ContextV1 = "https://www.w3.org/2018/credentials/v1"
+	// TypeCredential is the type every verifiable credential has.
+	TypeCredential = "VerifiableCredential"
+	// TypePresentation is the type every verifiable presentation has.
+	TypePresentation = "VerifiablePresentation"
+	// ProofTypeCLSignature is the type of proof holding a CL signature on the attributes.
+	ProofTypeCLSignature = "CLSignature2019"
+	// ProofTypeCLProof is the type of proof holding a zero-knowledge proof of
+	// possession of a CL credential.
+	ProofTypeCLProof = "CLProof2019"
+)
+
+// Types of attribute values.
+const (
+	AttrTypeString = "string"
+	AttrTypeInt64  = "int64"
+)
+
+// Credential is a verifiable credential as defined by the VC data model.
+// Attributes of the credential are the fields of CredentialSubject.
+type Credential struct {
+	Context           []string               `json:"@context"`
+	ID                string                 `json:"id,omitempty"`
+	Type              []string               `json:"type"`
+	Issuer            string                 `json:"issuer"`
+	IssuanceDate      string                 `json:"issuanceDate"`
+	ExpirationDate    string                 `json:"expirationDate,omitempty"`
+	CredentialSubject map[string]interface{} `json:"credentialSubject"`
+	Proof             *Proof                 `json:"proof,omitempty"`
+}
+
+// Attribute describes an attribute of a CL credential. Attributes are listed in
+// the proof, as their order and kind are not part of the VC data model.
+type Attribute struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Known bool   `json:"known"`
+}
+
+// Proof holds either a CL signature (ProofTypeCLSignature) or a proof of possession
+// of a CL credential (ProofTypeCLProof). Big integers are encoded as decimal strings.
+type Proof struct {
+	Type       string      `json:"type"`
+	Created    string      `json:"created,omitempty"`
+	Attributes []Attribute `json:"attributes"`
+	A          string      `json:"A"`
+
+	// fields of CL signature
+	E     string `json:"e,omitempty"`
+	V11   string `json:"v11,omitempty"`
+	KeyID string `json:"keyId,omitempty"` // ID of the issuer's key (see cl.PubKey.ID)
+
+	// fields of proof of possession
+	ProofRandomData            string   `json:"proofRandomData,omitempty"`
+	Challenge                  string   `json:"challenge,omitempty"`
+	ProofData                  []string `json:"proofData,omitempty"`
+	RevealedKnownAttrsIndices  []int    `json:"revealedKnownAttrsIndices,omitempty"`
+	RevealedCommitmentsIndices []int    `json:"revealedCommitmentsIndices,omitempty"`
+	RevealedCommitments        []string `json:"revealedCommitments,omitempty"`
+}
+
+// NewCredential returns a verifiable credential holding the CL credential cred
+// issued by issuer, with attribute values from rawCred.
+func NewCredential(issuer string, cred *cl.Cred, rawCred *cl.RawCred) (*Credential, error) {
+	subject, attrs, err := exportAttrs(rawCred, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		Context:           []string{ContextV1},
+		Type:              []string{TypeCredential},
+		Issuer:            issuer,
+		IssuanceDate:      time.Now().UTC().Format(time.RFC3339),
+		CredentialSubject: subject,
+		Proof: &Proof{
+			Type:       ProofTypeCLSignature,
+			Created:    time.Now().UTC().Format(time.RFC3339),
+			Attributes: attrs,
+			A:          cred.A.String(),
+			E:          cred.E.String(),
+			V11:        cred.V11.String(),
+			KeyID:      cred.KeyID,
+		},
+	}, nil
+}
+
+// ParseCredential parses a verifiable credential in JSON-LD form.
+func ParseCredential(data []byte) (*Credential, error) {
+	c := new(Credential)
+	if err := unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Cred returns the CL credential held in the proof of c.
+func (c *Credential) Cred() (*cl.Cred, error) {
+	if c.Proof == nil || c.Proof.Type != ProofTypeCLSignature {
+		return nil, fmt.Errorf("credential has no proof of type %s", ProofTypeCLSignature)
+	}
+
+	ints, err := parseInts(c.Proof.A, c.Proof.E, c.Proof.V11)
+	if err != nil {
+		return nil, err
+	}
+
+	cred := cl.NewCred(ints[0], ints[1], ints[2])
+	cred.KeyID = c.Proof.KeyID
+	return cred, nil
+}
+
+// RawCred returns a raw credential holding the attributes of c.
+func (c *Credential) RawCred() (*cl.RawCred, error) {
+	if c.Proof == nil {
+		return nil, fmt.Errorf("credential has no proof")
+	}
+
+	var nKnown, nCommitted int
+	for _, a := range c.Proof.Attributes {
+		if a.Known {
+			nKnown++
+		} else {
+			nCommitted++
+		}
+	}
+
+	rawCred := cl.NewRawCred(cl.NewAttrCount(nKnown, nCommitted, 0))
+	for _, a := range c.Proof.Attributes {
+		val, ok := c.CredentialSubject[a.Name]
+		if !ok {
+			return nil, fmt.Errorf("attribute %s missing in credentialSubject", a.Name)
+		}
+		if err := addAttr(rawCred, a, val); err != nil {
+			return nil, err
+		}
+	}
+
+	return rawCred, nil
+}
+
+// JSON returns the JSON-LD form of c.
+func (c *Credential) JSON() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+func (c *Credential) validate() error {
+	if len(c.Context) == 0 || c.Context[0] != ContextV1 {
+		return fmt.Errorf("first @context must be %s", ContextV1)
+	}
+	if !contains(c.Type, TypeCredential) {
+		return fmt.Errorf("credential must be of type %s", TypeCredential)
+	}
+	if c.Issuer == "" {
+		return fmt.Errorf("credential must have an issuer")
+	}
+
+	return nil
+}
+
+// exportAttrs returns attribute values from rawCred, mapped by attribute name, and
+// descriptions of the attributes, ordered by their index. When include is not nil,
+// only attributes for which include returns true are exported.
+func exportAttrs(rawCred *cl.RawCred,
+	include func(cl.CredAttr) bool) (map[string]interface{}, []Attribute, error) {
+	subject := make(map[string]interface{})
+	attrs := make([]Attribute, 0)
+
+	credAttrs := rawCred.GetAttrs()
+	for i := 0; i < len(credAttrs); i++ { // avoid range to have attributes in proper order
+		a := credAttrs[i]
+		if include != nil && !include(a) {
+			continue
+		}
+
+		var t string
+		switch a.(type) {
+		case *cl.StrAttr:
+			t = AttrTypeString
+		case *cl.Int64Attr:
+			t = AttrTypeInt64
+		default:
+			return nil, nil, fmt.Errorf("unsupported attribute type %T", a)
+		}
+
+		subject[a.GetName()] = a.GetValue()
+		attrs = append(attrs, Attribute{
+			Name:  a.GetName(),
+			Type:  t,
+			Known: a.IsKnown(),
+		})
+	}
+
+	return subject, attrs, nil
+}
+
+// addAttr adds attribute a with value val to rawCred.
+func addAttr(rawCred *cl.RawCred, a Attribute, val interface{}) error {
+	switch a.Type {
+	case AttrTypeString:
+		s, ok := val.(string)
+		if !ok {
+			return fmt.Errorf("value of attribute %s must be a string", a.Name)
+		}
+		return rawCred.AddStrAttr(a.Name, s, a.Known)
+	case AttrTypeInt64:
+		n, err := toInt64(val)
+		if err != nil {
+			return fmt.Errorf("value of attribute %s: %v", a.Name, err)
+		}
+		return rawCred.AddInt64Attr(a.Name, n, a.Known)
+	}
+
+	return fmt.Errorf("unsupported type %s of attribute %s", a.Type, a.Name)
+}
+
+// internalValue returns the value of attribute a, as it is used by the CL scheme.
+func internalValue(a Attribute, val interface{}) (*big.Int, error) {
+	var attr cl.CredAttr
+	var err error
+	switch a.Type {
+	case AttrTypeString:
+		s, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("value of attribute %s must be a string", a.Name)
+		}
+		attr, err = cl.NewStrAttr(a.Name, s, a.Known)
+	case AttrTypeInt64:
+		n, e := toInt64(val)
+		if e != nil {
+			return nil, fmt.Errorf("value of attribute %s: %v", a.Name, e)
+		}
+		attr, err = cl.NewInt64Attr(a.Name, n, a.Known)
+	default:
+		return nil, fmt.Errorf("unsupported type %s of attribute %s", a.Type, a.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return attr.InternalValue(), nil
+}
+
+func toInt64(val interface{}) (int64, error) {
+	switch v := val.(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case json.Number:
+		return v.Int64()
+	case float64:
+		if v != float64(int64(v)) {
+			return 0, fmt.Errorf("%v is not an integer", v)
+		}
+		return int64(v), nil
+	}
+
+	return 0, fmt.Errorf("%v is not an integer", val)
+}
+
+// unmarshal decodes JSON data into v, keeping numbers as json.Number
+// to avoid losing precision of int64 attributes.
+func unmarshal(data []byte, v interface{}) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	return d.Decode(v)
+}
+
+func parseInts(vals ...string) ([]*big.Int, error) {
+	ints := make([]*big.Int, len(vals))
+	for i, v := range vals {
+		n, ok := new(big.Int).SetString(v, 10)
+		if !ok {
+			return nil, fmt.Errorf("%q is not a decimal integer", v)
+		}
+		ints[i] = n
+	}
+
+	return ints, nil
+}
+
+func formatInts(ints []*big.Int) []string {
+	vals := make([]string, len(ints))
+	for i, n := range ints {
+		vals[i] = n.String()
+	}
+
+	return vals
+}
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+
+	return false
+}
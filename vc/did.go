@@ -0,0 +1,70 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package vc
+
+import (
+	"fmt"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/did"
+	"github.com/xlab-si/emmy/jose"
+)
+
+// IssuerPubKey returns the CL public key of the issuer of c, which is identified
+// by a DID, from the issuer's DID document obtained with r.
+func (c *Credential) IssuerPubKey(r did.Resolver) (*cl.PubKey, error) {
+	doc, err := r.Resolve(c.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.CLPubKey()
+}
+
+// ResolvePresentationJWT verifies the signature of a presentation in JWT form with the
+// key of its holder, which is identified by a DID (in the iss claim) and resolved with r.
+// It returns the presentation along with its audience and nonce.
+func ResolvePresentationJWT(token string, r did.Resolver) (*Presentation, string, string,
+	error) {
+	unverified := new(presentationClaims)
+	if _, err := jose.Decode(token, unverified); err != nil {
+		return nil, "", "", err
+	}
+	if unverified.Issuer == "" {
+		return nil, "", "", fmt.Errorf("presentation does not identify its holder")
+	}
+
+	doc, err := r.Resolve(unverified.Issuer)
+	if err != nil {
+		return nil, "", "", err
+	}
+	key, err := doc.ECDSAPubKey()
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	p, aud, nonce, err := ParsePresentationJWT(token, key)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if p.Holder != unverified.Issuer {
+		return nil, "", "", fmt.Errorf("iss claim does not match presentation's holder")
+	}
+
+	return p, aud, nonce, nil
+}
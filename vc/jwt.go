@@ -0,0 +1,128 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package vc
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"time"
+
+	"github.com/xlab-si/emmy/jose"
+)
+
+// credentialClaims are JWT claims of a credential in JWT form. Registered claims
+// duplicate some of the properties of the credential, as required by the VC data model.
+type credentialClaims struct {
+	Issuer    string      `json:"iss"`
+	ID        string      `json:"jti,omitempty"`
+	NotBefore int64       `json:"nbf,omitempty"`
+	Expires   int64       `json:"exp,omitempty"`
+	VC        *Credential `json:"vc"`
+}
+
+// presentationClaims are JWT claims of a presentation in JWT form.
+type presentationClaims struct {
+	Issuer   string        `json:"iss,omitempty"`
+	ID       string        `json:"jti,omitempty"`
+	Audience string        `json:"aud,omitempty"`
+	Nonce    string        `json:"nonce,omitempty"`
+	VP       *Presentation `json:"vp"`
+}
+
+// JWT returns c in JWT form, signed by the issuer's key.
+func (c *Credential) JWT(key *ecdsa.PrivateKey) (string, error) {
+	claims := &credentialClaims{
+		Issuer: c.Issuer,
+		ID:     c.ID,
+		VC:     c,
+	}
+	var err error
+	if claims.NotBefore, err = unixTime(c.IssuanceDate); err != nil {
+		return "", err
+	}
+	if claims.Expires, err = unixTime(c.ExpirationDate); err != nil {
+		return "", err
+	}
+
+	return jose.Sign(claims, key, "")
+}
+
+// ParseCredentialJWT verifies the signature of a credential in JWT form with
+// the issuer's key, and returns the credential.
+func ParseCredentialJWT(token string, key *ecdsa.PublicKey) (*Credential, error) {
+	claims := new(credentialClaims)
+	if err := jose.Verify(token, key, claims); err != nil {
+		return nil, err
+	}
+	if claims.VC == nil {
+		return nil, fmt.Errorf("token has no vc claim")
+	}
+	if claims.Expires != 0 && time.Now().Unix() > claims.Expires {
+		return nil, fmt.Errorf("credential expired")
+	}
+	if claims.Issuer != claims.VC.Issuer {
+		return nil, fmt.Errorf("iss claim does not match credential's issuer")
+	}
+	if err := claims.VC.validate(); err != nil {
+		return nil, err
+	}
+
+	return claims.VC, nil
+}
+
+// JWT returns p in JWT form for the given audience, signed by the holder's key.
+// The nonce, typically obtained from the verifier, prevents replays of the presentation.
+func (p *Presentation) JWT(audience, nonce string, key *ecdsa.PrivateKey) (string, error) {
+	return jose.Sign(&presentationClaims{
+		Issuer:   p.Holder,
+		ID:       p.ID,
+		Audience: audience,
+		Nonce:    nonce,
+		VP:       p,
+	}, key, "")
+}
+
+// ParsePresentationJWT verifies the signature of a presentation in JWT form with the
+// holder's key, and returns the presentation along with its audience and nonce.
+func ParsePresentationJWT(token string, key *ecdsa.PublicKey) (*Presentation, string, string,
+	error) {
+	claims := new(presentationClaims)
+	if err := jose.Verify(token, key, claims); err != nil {
+		return nil, "", "", err
+	}
+	if claims.VP == nil {
+		return nil, "", "", fmt.Errorf("token has no vp claim")
+	}
+	if err := claims.VP.validate(); err != nil {
+		return nil, "", "", err
+	}
+
+	return claims.VP, claims.Audience, claims.Nonce, nil
+}
+
+// unixTime converts an RFC 3339 date to Unix time. Empty date is converted to 0.
+func unixTime(date string) (int64, error) {
+	if date == "" {
+		return 0, nil
+	}
+	t, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return 0, err
+	}
+	return t.Unix(), nil
+}
@@ -0,0 +1,180 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package vc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/qr"
+)
+
+// Presentation is a verifiable presentation as defined by the VC data model. It holds
+// a credential derived from a CL credential, which contains only revealed attributes
+// and a proof of possession of the CL credential.
+type Presentation struct {
+	Context              []string      `json:"@context"`
+	ID                   string        `json:"id,omitempty"`
+	Type                 []string      `json:"type"`
+	Holder               string        `json:"holder,omitempty"`
+	VerifiableCredential []*Credential `json:"verifiableCredential"`
+}
+
+// CredProof holds the arguments of cl.Org's ProveCred, which verifies the proof
+// of possession of a CL credential. Presentations do not hold range proofs.
+type CredProof = cl.CredProof
+
+// NewPresentation returns a verifiable presentation of a CL credential issued by issuer,
+// where A and proof are obtained from cl.CredManager's BuildProof. Values of revealed
+// known attributes are taken from rawCred, and revealed commitments of attributes
+// are as returned by cl.CredManager's FilterAttributes.
+func NewPresentation(issuer string, A *big.Int, proof *qr.RepresentationProof,
+	revealedKnownAttrsIndices, revealedCommitmentsOfAttrsIndices []int,
+	revealedCommitmentsOfAttrs []*big.Int, rawCred *cl.RawCred) (*Presentation, error) {
+	if len(revealedCommitmentsOfAttrs) != len(revealedCommitmentsOfAttrsIndices) {
+		return nil, fmt.Errorf("number of revealed commitments does not match their indices")
+	}
+
+	knownIndex := 0
+	subject, attrs, err := exportAttrs(rawCred, func(a cl.CredAttr) bool {
+		if !a.IsKnown() {
+			return false
+		}
+		revealed := common.Contains(revealedKnownAttrsIndices, knownIndex)
+		knownIndex++
+		return revealed
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(attrs) != len(revealedKnownAttrsIndices) {
+		return nil, fmt.Errorf("invalid indices of revealed known attributes")
+	}
+
+	cred := &Credential{
+		Context:           []string{ContextV1},
+		Type:              []string{TypeCredential},
+		Issuer:            issuer,
+		IssuanceDate:      time.Now().UTC().Format(time.RFC3339),
+		CredentialSubject: subject,
+		Proof: &Proof{
+			Type:                       ProofTypeCLProof,
+			Created:                    time.Now().UTC().Format(time.RFC3339),
+			Attributes:                 attrs,
+			A:                          A.String(),
+			ProofRandomData:            proof.ProofRandomData.String(),
+			Challenge:                  proof.Challenge.String(),
+			ProofData:                  formatInts(proof.ProofData),
+			RevealedKnownAttrsIndices:  revealedKnownAttrsIndices,
+			RevealedCommitmentsIndices: revealedCommitmentsOfAttrsIndices,
+			RevealedCommitments:        formatInts(revealedCommitmentsOfAttrs),
+		},
+	}
+
+	return &Presentation{
+		Context:              []string{ContextV1},
+		Type:                 []string{TypePresentation},
+		VerifiableCredential: []*Credential{cred},
+	}, nil
+}
+
+// ParsePresentation parses a verifiable presentation in JSON-LD form.
+func ParsePresentation(data []byte) (*Presentation, error) {
+	p := new(Presentation)
+	if err := unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// CredProof extracts the proof of possession of a CL credential from p, along
+// with the revealed attributes.
+func (p *Presentation) CredProof() (*CredProof, error) {
+	if len(p.VerifiableCredential) != 1 {
+		return nil, fmt.Errorf("presentation must hold exactly one credential")
+	}
+	c := p.VerifiableCredential[0]
+	if c.Proof == nil || c.Proof.Type != ProofTypeCLProof {
+		return nil, fmt.Errorf("credential has no proof of type %s", ProofTypeCLProof)
+	}
+	if len(c.Proof.Attributes) != len(c.Proof.RevealedKnownAttrsIndices) {
+		return nil, fmt.Errorf("invalid indices of revealed known attributes")
+	}
+
+	ints, err := parseInts(c.Proof.A, c.Proof.ProofRandomData, c.Proof.Challenge)
+	if err != nil {
+		return nil, err
+	}
+	proofData, err := parseInts(c.Proof.ProofData...)
+	if err != nil {
+		return nil, err
+	}
+	commitments, err := parseInts(c.Proof.RevealedCommitments...)
+	if err != nil {
+		return nil, err
+	}
+
+	knownAttrs := make([]*big.Int, len(c.Proof.Attributes))
+	for i, a := range c.Proof.Attributes {
+		val, ok := c.CredentialSubject[a.Name]
+		if !ok {
+			return nil, fmt.Errorf("attribute %s missing in credentialSubject", a.Name)
+		}
+		if knownAttrs[i], err = internalValue(a, val); err != nil {
+			return nil, err
+		}
+	}
+
+	return &CredProof{
+		A:                                 ints[0],
+		Proof:                             qr.NewRepresentationProof(ints[1], ints[2], proofData),
+		RevealedKnownAttrsIndices:         c.Proof.RevealedKnownAttrsIndices,
+		RevealedCommitmentsOfAttrsIndices: c.Proof.RevealedCommitmentsIndices,
+		RevealedKnownAttrs:                knownAttrs,
+		RevealedCommitmentsOfAttrs:        commitments,
+	}, nil
+}
+
+// JSON returns the JSON-LD form of p.
+func (p *Presentation) JSON() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+func (p *Presentation) validate() error {
+	if len(p.Context) == 0 || p.Context[0] != ContextV1 {
+		return fmt.Errorf("first @context must be %s", ContextV1)
+	}
+	if !contains(p.Type, TypePresentation) {
+		return fmt.Errorf("presentation must be of type %s", TypePresentation)
+	}
+	for _, c := range p.VerifiableCredential {
+		if err := c.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,378 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package vc
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/jose"
+)
+
+// SD-JWT VC (https://datatracker.ietf.org/doc/draft-ietf-oauth-sd-jwt-vc/) lets relying
+// parties that standardize on selective disclosure JWTs accept credentials issued by
+// emmy organizations. Known attributes of a CL credential are issued as selectively
+// disclosable claims, and the holder chooses which of them to disclose to a verifier.
+
+const (
+	// TypSDJWTVC is the typ header of SD-JWT VCs.
+	TypSDJWTVC = "vc+sd-jwt"
+	// TypKeyBinding is the typ header of key binding JWTs, which bind presentations of
+	// SD-JWT VCs to the holder's key.
+	TypKeyBinding = "kb+jwt"
+	// sdAlg is the hash algorithm of disclosure digests.
+	sdAlg = "sha-256"
+	// keyBindingMaxAge limits the age of key binding JWTs.
+	keyBindingMaxAge = 5 * time.Minute
+)
+
+// registeredSDClaims are claims of SD-JWT VCs that cannot be selectively disclosed.
+var registeredSDClaims = []string{"iss", "iat", "nbf", "exp", "vct", "cnf", "_sd", "_sd_alg",
+	"..."}
+
+// SDJWTIssuer issues SD-JWT VCs holding known attributes of CL credentials.
+type SDJWTIssuer struct {
+	issuer string
+	vct    string
+	key    *ecdsa.PrivateKey
+	kid    string
+	ttl    time.Duration
+}
+
+// NewSDJWTIssuer returns an issuer of SD-JWT VCs of type vct, identified by issuer and
+// signing them with key. Credentials are valid for ttl, or indefinitely when ttl is 0.
+func NewSDJWTIssuer(issuer, vct string, key *ecdsa.PrivateKey, ttl time.Duration) (*SDJWTIssuer,
+	error) {
+	jwk, err := jose.NewJWK(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SDJWTIssuer{
+		issuer: issuer,
+		vct:    vct,
+		key:    key,
+		kid:    jwk.Thumbprint(),
+		ttl:    ttl,
+	}, nil
+}
+
+// Issue returns an SD-JWT VC holding known attributes of rawCred as selectively
+// disclosable claims, along with all their disclosures. When holderKey is not nil,
+// the credential is bound to it, and presentations need to be signed by the holder.
+func (i *SDJWTIssuer) Issue(rawCred *cl.RawCred, holderKey *ecdsa.PublicKey) (string, error) {
+	subject, _, err := exportAttrs(rawCred, func(a cl.CredAttr) bool {
+		return a.IsKnown()
+	})
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":     i.issuer,
+		"iat":     now.Unix(),
+		"vct":     i.vct,
+		"_sd_alg": sdAlg,
+	}
+	if i.ttl > 0 {
+		claims["exp"] = now.Add(i.ttl).Unix()
+	}
+	if holderKey != nil {
+		jwk, err := jose.NewJWK(holderKey)
+		if err != nil {
+			return "", err
+		}
+		claims["cnf"] = map[string]interface{}{"jwk": jwk}
+	}
+
+	names := make([]string, 0, len(subject))
+	for name := range subject {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	disclosures := make([]string, len(names))
+	digests := make([]string, len(names))
+	for j, name := range names {
+		if disclosures[j], err = newDisclosure(name, subject[name]); err != nil {
+			return "", err
+		}
+		digests[j] = disclosureDigest(disclosures[j])
+	}
+	// digests are sorted so that their order does not reveal names of claims
+	sort.Strings(digests)
+	claims["_sd"] = digests
+
+	token, err := jose.SignWithType(claims, i.key, i.kid, TypSDJWTVC)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(append([]string{token}, disclosures...), "~") + "~", nil
+}
+
+// PresentSDJWT returns a presentation of the SD-JWT VC sdJWT that discloses only the
+// claims named in disclose. When holderKey is not nil, the presentation is bound to
+// the verifier's audience and nonce with a key binding JWT signed by holderKey.
+func PresentSDJWT(sdJWT string, disclose []string, holderKey *ecdsa.PrivateKey, audience,
+	nonce string) (string, error) {
+	token, disclosures, _, err := splitSDJWT(sdJWT)
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{token}
+	for _, d := range disclosures {
+		name, _, err := decodeDisclosure(d)
+		if err != nil {
+			return "", err
+		}
+		if contains(disclose, name) {
+			parts = append(parts, d)
+		}
+	}
+	presentation := strings.Join(parts, "~") + "~"
+	if holderKey == nil {
+		return presentation, nil
+	}
+
+	kb, err := jose.SignWithType(map[string]interface{}{
+		"iat":     time.Now().Unix(),
+		"aud":     audience,
+		"nonce":   nonce,
+		"sd_hash": disclosureDigest(presentation),
+	}, holderKey, "", TypKeyBinding)
+	if err != nil {
+		return "", err
+	}
+
+	return presentation + kb, nil
+}
+
+// SDJWTCredential is a verified SD-JWT VC with its disclosed claims.
+type SDJWTCredential struct {
+	Issuer   string
+	Type     string
+	IssuedAt time.Time
+	Claims   map[string]interface{}
+}
+
+// VerifySDJWT verifies the signature of the presentation of an SD-JWT VC with the
+// issuer's key issuerKey, and returns the disclosed claims. If the credential is
+// bound to the holder's key, the presentation must hold a key binding JWT for
+// the given audience and nonce.
+func VerifySDJWT(presentation string, issuerKey *ecdsa.PublicKey, audience,
+	nonce string) (*SDJWTCredential, error) {
+	token, disclosures, kb, err := splitSDJWT(presentation)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := jose.Decode(token, &struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	if header.Typ != TypSDJWTVC {
+		return nil, fmt.Errorf("unexpected token type %s", header.Typ)
+	}
+	claims := make(map[string]interface{})
+	if err := jose.Verify(token, issuerKey, &claims); err != nil {
+		return nil, err
+	}
+	if claims["_sd_alg"] != sdAlg {
+		return nil, fmt.Errorf("unsupported digest algorithm %v", claims["_sd_alg"])
+	}
+	if exp, err := numericClaim(claims, "exp"); err != nil {
+		return nil, err
+	} else if exp != 0 && time.Now().Unix() > exp {
+		return nil, fmt.Errorf("credential expired")
+	}
+
+	digests := make(map[string]bool)
+	if sd, ok := claims["_sd"].([]interface{}); ok {
+		for _, d := range sd {
+			if s, ok := d.(string); ok {
+				digests[s] = true
+			}
+		}
+	}
+	disclosed := make(map[string]interface{})
+	for _, d := range disclosures {
+		digest := disclosureDigest(d)
+		if !digests[digest] {
+			return nil, fmt.Errorf("disclosure is not part of the credential")
+		}
+		delete(digests, digest) // every disclosure can be used only once
+		name, val, err := decodeDisclosure(d)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := disclosed[name]; ok || contains(registeredSDClaims, name) {
+			return nil, fmt.Errorf("invalid disclosure of claim %s", name)
+		}
+		disclosed[name] = val
+	}
+
+	if cnf, ok := claims["cnf"].(map[string]interface{}); ok {
+		if err := verifyKeyBinding(cnf, presentation[:len(presentation)-len(kb)], kb,
+			audience, nonce); err != nil {
+			return nil, err
+		}
+	} else if kb != "" {
+		return nil, fmt.Errorf("credential is not bound to a key")
+	}
+
+	iat, err := numericClaim(claims, "iat")
+	if err != nil {
+		return nil, err
+	}
+	iss, _ := claims["iss"].(string)
+	vct, _ := claims["vct"].(string)
+
+	return &SDJWTCredential{
+		Issuer:   iss,
+		Type:     vct,
+		IssuedAt: time.Unix(iat, 0),
+		Claims:   disclosed,
+	}, nil
+}
+
+// verifyKeyBinding verifies the key binding JWT kb of presentation with the key in
+// the cnf claim.
+func verifyKeyBinding(cnf map[string]interface{}, presentation, kb, audience,
+	nonce string) error {
+	if kb == "" {
+		return fmt.Errorf("presentation has no key binding")
+	}
+	jwkJSON, err := json.Marshal(cnf["jwk"])
+	if err != nil {
+		return err
+	}
+	jwk := new(jose.JWK)
+	if err := json.Unmarshal(jwkJSON, jwk); err != nil {
+		return fmt.Errorf("invalid cnf claim: %v", err)
+	}
+	holderKey, err := jwk.ECDSAPublicKey()
+	if err != nil {
+		return err
+	}
+
+	header, err := jose.Decode(kb, &struct{}{})
+	if err != nil {
+		return err
+	}
+	if header.Typ != TypKeyBinding {
+		return fmt.Errorf("unexpected key binding type %s", header.Typ)
+	}
+	claims := make(map[string]interface{})
+	if err := jose.Verify(kb, holderKey, &claims); err != nil {
+		return err
+	}
+	if claims["aud"] != audience || claims["nonce"] != nonce {
+		return fmt.Errorf("key binding is not meant for this verifier")
+	}
+	if claims["sd_hash"] != disclosureDigest(presentation) {
+		return fmt.Errorf("key binding does not match the presentation")
+	}
+	iat, err := numericClaim(claims, "iat")
+	if err != nil {
+		return err
+	}
+	if age := time.Since(time.Unix(iat, 0)); age > keyBindingMaxAge || age < -keyBindingMaxAge {
+		return fmt.Errorf("key binding is not fresh")
+	}
+
+	return nil
+}
+
+// splitSDJWT splits an SD-JWT (or its presentation) into the issuer-signed JWT,
+// disclosures and the key binding JWT (empty if there is none).
+func splitSDJWT(sdJWT string) (string, []string, string, error) {
+	parts := strings.Split(sdJWT, "~")
+	if len(parts) < 2 || parts[0] == "" {
+		return "", nil, "", fmt.Errorf("malformed SD-JWT")
+	}
+
+	return parts[0], parts[1 : len(parts)-1], parts[len(parts)-1], nil
+}
+
+// newDisclosure returns a disclosure of claim name with value val.
+func newDisclosure(name string, val interface{}) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal([]interface{}{
+		base64.RawURLEncoding.EncodeToString(salt), name, val,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeDisclosure returns the name and value of the claim disclosed by d.
+func decodeDisclosure(d string) (string, interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(d)
+	if err != nil {
+		return "", nil, fmt.Errorf("malformed disclosure: %v", err)
+	}
+	var fields []interface{}
+	if err := unmarshal(data, &fields); err != nil {
+		return "", nil, fmt.Errorf("malformed disclosure: %v", err)
+	}
+	if len(fields) != 3 {
+		return "", nil, fmt.Errorf("malformed disclosure")
+	}
+	name, ok := fields[1].(string)
+	if !ok {
+		return "", nil, fmt.Errorf("malformed disclosure")
+	}
+
+	return name, fields[2], nil
+}
+
+// disclosureDigest returns the base64url encoded SHA-256 digest of s.
+func disclosureDigest(s string) string {
+	digest := sha256.Sum256([]byte(s))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// numericClaim returns the value of numeric claim name, or 0 if it is not present.
+func numericClaim(claims map[string]interface{}, name string) (int64, error) {
+	v, ok := claims[name]
+	if !ok {
+		return 0, nil
+	}
+	n, err := toInt64(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s claim: %v", name, err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,278 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package vc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/did"
+)
+
+const testIssuer = "did:example:org1"
+
+// issueTestCred issues a CL credential and returns the credential manager holding it.
+func issueTestCred(t *testing.T) (*cl.Org, *cl.CredManager, *cl.RawCred, *cl.Cred) {
+	params := cl.GetDefaultParamSizes()
+	attrCount := cl.NewAttrCount(5, 1, 0)
+	org, err := cl.NewOrg(params, attrCount)
+	if err != nil {
+		t.Fatalf("error when generating CL org: %v", err)
+	}
+
+	rawCred := cl.NewRawCred(attrCount)
+	_ = rawCred.AddStrAttr("Name", "Jack", true)
+	_ = rawCred.AddStrAttr("Gender", "M", true)
+	_ = rawCred.AddStrAttr("Graduated", "true", true)
+	_ = rawCred.AddInt64Attr("DateMin", 22342345, true)
+	_ = rawCred.AddInt64Attr("DateMax", 32342345, true)
+	_ = rawCred.AddInt64Attr("Age", 25, false)
+
+	credMgr, err := cl.NewCredManager(params, org.Keys.Pub,
+		org.Keys.Pub.GenerateUserMasterSecret(), rawCred)
+	if err != nil {
+		t.Fatalf("error when creating credential manager: %v", err)
+	}
+	credReq, err := credMgr.GetCredRequest(org.GetCredIssueNonce())
+	if err != nil {
+		t.Fatalf("error when generating credential request: %v", err)
+	}
+	res, err := org.IssueCred(credReq)
+	if err != nil {
+		t.Fatalf("error when issuing credential: %v", err)
+	}
+
+	return org, credMgr, rawCred, res.Cred
+}
+
+func TestCredential(t *testing.T) {
+	_, _, rawCred, cred := issueTestCred(t)
+
+	c, err := NewCredential(testIssuer, cred, rawCred)
+	if err != nil {
+		t.Fatalf("error when exporting credential: %v", err)
+	}
+	data, err := c.JSON()
+	if err != nil {
+		t.Fatalf("error when encoding credential: %v", err)
+	}
+
+	parsed, err := ParseCredential(data)
+	if err != nil {
+		t.Fatalf("error when parsing credential: %v", err)
+	}
+	assert.Equal(t, "Jack", parsed.CredentialSubject["Name"])
+
+	parsedCred, err := parsed.Cred()
+	if err != nil {
+		t.Fatalf("error when importing credential: %v", err)
+	}
+	assert.Equal(t, cred, parsedCred, "imported credential differs from exported one")
+
+	parsedRawCred, err := parsed.RawCred()
+	if err != nil {
+		t.Fatalf("error when importing attributes: %v", err)
+	}
+	assert.Equal(t, rawCred.GetKnownVals(), parsedRawCred.GetKnownVals())
+	assert.Equal(t, rawCred.GetCommittedVals(), parsedRawCred.GetCommittedVals())
+
+	_, err = ParseCredential([]byte(strings.Replace(string(data), ContextV1, "x", 1)))
+	assert.Error(t, err, "credential without base context should not be accepted")
+}
+
+func TestPresentation(t *testing.T) {
+	org, credMgr, rawCred, cred := issueTestCred(t)
+
+	revealedKnownAttrsIndices := []int{0}
+	revealedCommitmentsOfAttrsIndices := []int{0}
+	nonce := org.GetProveCredNonce()
+	randCred, proof, err := credMgr.BuildProof(cred, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, nonce)
+	if err != nil {
+		t.Fatalf("error when building credential proof: %v", err)
+	}
+	_, revealedCommitmentsOfAttrs := credMgr.FilterAttributes(revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices)
+
+	p, err := NewPresentation(testIssuer, randCred.A, proof, revealedKnownAttrsIndices,
+		revealedCommitmentsOfAttrsIndices, revealedCommitmentsOfAttrs, rawCred)
+	if err != nil {
+		t.Fatalf("error when exporting presentation: %v", err)
+	}
+	assert.Equal(t, 1, len(p.VerifiableCredential[0].CredentialSubject),
+		"only revealed attributes should be presented")
+
+	data, err := p.JSON()
+	if err != nil {
+		t.Fatalf("error when encoding presentation: %v", err)
+	}
+	parsed, err := ParsePresentation(data)
+	if err != nil {
+		t.Fatalf("error when parsing presentation: %v", err)
+	}
+	cp, err := parsed.CredProof()
+	if err != nil {
+		t.Fatalf("error when importing presentation: %v", err)
+	}
+
+	verified, err := org.ProveCred(cp.A, cp.Proof, cp.RevealedKnownAttrsIndices,
+		cp.RevealedCommitmentsOfAttrsIndices, cp.RevealedKnownAttrs,
+		cp.RevealedCommitmentsOfAttrs)
+	if err != nil {
+		t.Errorf("error when verifying presentation: %v", err)
+	}
+	assert.True(t, verified, "presentation should be verified")
+
+	// proofs imported from presentations can be passed on in emmy's own encodings
+	encoded, err := cl.EncodeCBOR(cp)
+	assert.NoError(t, err)
+	decoded := new(cl.CredProof)
+	assert.NoError(t, cl.DecodeCBOR(encoded, decoded))
+	assert.Equal(t, 0, cp.A.Cmp(decoded.A))
+}
+
+func TestJWT(t *testing.T) {
+	_, _, rawCred, cred := issueTestCred(t)
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	c, err := NewCredential(testIssuer, cred, rawCred)
+	if err != nil {
+		t.Fatalf("error when exporting credential: %v", err)
+	}
+	token, err := c.JWT(key)
+	if err != nil {
+		t.Fatalf("error when encoding credential as JWT: %v", err)
+	}
+
+	parsed, err := ParseCredentialJWT(token, &key.PublicKey)
+	if err != nil {
+		t.Fatalf("error when parsing credential JWT: %v", err)
+	}
+	parsedCred, _ := parsed.Cred()
+	assert.Equal(t, cred, parsedCred)
+
+	_, err = ParseCredentialJWT(token, &otherKey.PublicKey)
+	assert.Error(t, err, "token signed by another key should not be accepted")
+
+	p := &Presentation{
+		Context:              []string{ContextV1},
+		Type:                 []string{TypePresentation},
+		Holder:               "did:example:holder",
+		VerifiableCredential: []*Credential{c},
+	}
+	token, err = p.JWT("did:example:verifier", "123", key)
+	if err != nil {
+		t.Fatalf("error when encoding presentation as JWT: %v", err)
+	}
+	_, aud, nonce, err := ParsePresentationJWT(token, &key.PublicKey)
+	if err != nil {
+		t.Fatalf("error when parsing presentation JWT: %v", err)
+	}
+	assert.Equal(t, "did:example:verifier", aud)
+	assert.Equal(t, "123", nonce)
+}
+
+func TestResolvePresentationJWT(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	holder, err := did.NewKeyDID(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("error when creating holder's DID: %v", err)
+	}
+
+	p := &Presentation{
+		Context:              []string{ContextV1},
+		Type:                 []string{TypePresentation},
+		Holder:               holder,
+		VerifiableCredential: []*Credential{},
+	}
+	token, err := p.JWT("did:example:verifier", "123", key)
+	if err != nil {
+		t.Fatalf("error when encoding presentation as JWT: %v", err)
+	}
+
+	parsed, _, _, err := ResolvePresentationJWT(token, did.NewResolver())
+	if err != nil {
+		t.Fatalf("error when verifying presentation JWT: %v", err)
+	}
+	assert.Equal(t, holder, parsed.Holder)
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	token, _ = p.JWT("did:example:verifier", "123", otherKey)
+	_, _, _, err = ResolvePresentationJWT(token, did.NewResolver())
+	assert.Error(t, err, "presentation signed by another key should not be accepted")
+}
+
+func TestSDJWT(t *testing.T) {
+	_, _, rawCred, _ := issueTestCred(t)
+	issuerKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	holderKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	issuer, err := NewSDJWTIssuer(testIssuer, "https://example.com/student", issuerKey,
+		time.Hour)
+	if err != nil {
+		t.Fatalf("error when creating SD-JWT issuer: %v", err)
+	}
+	sdJWT, err := issuer.Issue(rawCred, &holderKey.PublicKey)
+	if err != nil {
+		t.Fatalf("error when issuing SD-JWT VC: %v", err)
+	}
+	assert.Equal(t, 7, len(strings.Split(sdJWT, "~")),
+		"known attributes should be disclosable")
+
+	p, err := PresentSDJWT(sdJWT, []string{"Name", "DateMin"}, holderKey, "verifier", "123")
+	if err != nil {
+		t.Fatalf("error when presenting SD-JWT VC: %v", err)
+	}
+	c, err := VerifySDJWT(p, &issuerKey.PublicKey, "verifier", "123")
+	if err != nil {
+		t.Fatalf("error when verifying SD-JWT VC: %v", err)
+	}
+	assert.Equal(t, testIssuer, c.Issuer)
+	assert.Equal(t, "https://example.com/student", c.Type)
+	assert.Equal(t, map[string]interface{}{
+		"Name":    "Jack",
+		"DateMin": json.Number("22342345"),
+	}, c.Claims)
+
+	_, err = VerifySDJWT(p, &issuerKey.PublicKey, "verifier", "456")
+	assert.Error(t, err, "presentation for another nonce should not be accepted")
+	_, err = VerifySDJWT(p, &holderKey.PublicKey, "verifier", "123")
+	assert.Error(t, err, "credential signed by another key should not be accepted")
+
+	unbound, err := PresentSDJWT(sdJWT, []string{"Name"}, nil, "", "")
+	if err != nil {
+		t.Fatalf("error when presenting SD-JWT VC: %v", err)
+	}
+	_, err = VerifySDJWT(unbound, &issuerKey.PublicKey, "verifier", "123")
+	assert.Error(t, err, "presentation without key binding should not be accepted")
+
+	// disclosures cannot be added to a bound presentation
+	parts := strings.Split(p, "~")
+	tampered := strings.Join(append(parts[:3:3], append([]string{
+		strings.Split(sdJWT, "~")[3]}, parts[3:]...)...), "~")
+	_, err = VerifySDJWT(tampered, &issuerKey.PublicKey, "verifier", "123")
+	assert.Error(t, err, "tampered presentation should not be accepted")
+}
@@ -0,0 +1,443 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package vectors
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/config"
+	"github.com/xlab-si/emmy/crypto/cl"
+	"github.com/xlab-si/emmy/crypto/common"
+	"github.com/xlab-si/emmy/crypto/df"
+	"github.com/xlab-si/emmy/crypto/ec"
+	"github.com/xlab-si/emmy/crypto/ecpedersen"
+	"github.com/xlab-si/emmy/crypto/ecschnorr"
+	"github.com/xlab-si/emmy/crypto/pedersen"
+	"github.com/xlab-si/emmy/crypto/qr"
+	"github.com/xlab-si/emmy/crypto/schnorr"
+)
+
+// challengeSpaceSize is the bit length of challenges in protocols over groups
+// of unknown order.
+const challengeSpaceSize = 80
+
+// schemes lists the protocols vectors are generated for, in the order they
+// appear in a suite.
+var schemes = []struct {
+	name string
+	run  func(r *recorder) error
+}{
+	{"schnorr/dlog_knowledge", runSchnorr},
+	{"schnorr/dlog_equality", runSchnorrEquality},
+	{"ecschnorr/dlog_knowledge", runECSchnorr},
+	{"ecschnorr/dlog_equality", runECSchnorrEquality},
+	{"ecschnorr/partial_dlog_knowledge", runECSchnorrPartial},
+	{"pedersen/commitment", runPedersen},
+	{"ecpedersen/commitment", runECPedersen},
+	{"qr/representation", runQRRepresentation},
+	{"df/opening", runDFOpening},
+	{"cl/issue_prove", runCL},
+}
+
+// loadGroup returns the configured Schnorr group, recording its parameters.
+func loadGroup(r *recorder) (*schnorr.Group, error) {
+	group, err := config.LoadGroup("pseudonymsys")
+	if err != nil {
+		return nil, err
+	}
+	r.input("p", group.P)
+	r.input("q", group.Q)
+	r.input("g", group.G)
+
+	return group, nil
+}
+
+// loadOrg returns the configured CL organization.
+func loadOrg() (*cl.Params, *cl.Org, error) {
+	params, err := cl.LoadParams()
+	if err != nil {
+		return nil, nil, err
+	}
+	pubKeyPath, secKeyPath := config.LoadCLKeyPaths()
+	org, err := cl.LoadOrg(params, pubKeyPath, secKeyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return params, org, nil
+}
+
+// loadSpecialRSA returns the special RSA group of commitments to CL attributes,
+// recording its modulus.
+func loadSpecialRSA(r *recorder) (*qr.RSASpecial, *cl.PubKey, error) {
+	_, org, err := loadOrg()
+	if err != nil {
+		return nil, nil, err
+	}
+	group, err := qr.NewRSASpecialFromParams(org.Keys.Sec.AttributesSpecialRSAPrimes)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.input("n", group.N)
+
+	return group, org.Keys.Pub, nil
+}
+
+func runSchnorr(r *recorder) error {
+	group, err := loadGroup(r)
+	if err != nil {
+		return err
+	}
+	secret := common.GetRandomInt(group.Q)
+	y := group.Exp(group.G, secret)
+	r.input("secret", secret)
+	r.input("y", y)
+
+	prover, err := schnorr.NewProver(group, []*big.Int{secret}, []*big.Int{group.G}, y)
+	if err != nil {
+		return err
+	}
+	verifier := schnorr.NewVerifier(group)
+
+	t := prover.GetProofRandomData()
+	r.message("t", t)
+	verifier.SetProofRandomData(t, []*big.Int{group.G}, y)
+	challenge := verifier.GetChallenge()
+	r.message("challenge", challenge)
+	z := prover.GetProofData(challenge)
+	r.message("z", z...)
+
+	return r.verified(verifier.Verify(z))
+}
+
+func runSchnorrEquality(r *recorder) error {
+	group, err := loadGroup(r)
+	if err != nil {
+		return err
+	}
+	g1 := group.G
+	g2 := group.GetRandomElement()
+	secret := common.GetRandomInt(group.Q)
+	t1 := group.Exp(g1, secret)
+	t2 := group.Exp(g2, secret)
+	r.input("g2", g2)
+	r.input("secret", secret)
+	r.input("t1", t1)
+	r.input("t2", t2)
+
+	prover := schnorr.NewEqualityProver(group)
+	verifier := schnorr.NewEqualityVerifier(group)
+
+	x1, x2 := prover.GetProofRandomData(secret, g1, g2)
+	r.message("x1", x1)
+	r.message("x2", x2)
+	challenge := verifier.GetChallenge(g1, g2, t1, t2, x1, x2)
+	r.message("challenge", challenge)
+	z := prover.GetProofData(challenge)
+	r.message("z", z)
+
+	return r.verified(verifier.Verify(z))
+}
+
+func runECSchnorr(r *recorder) error {
+	prover := ecschnorr.NewProver(ec.P256)
+	verifier := ecschnorr.NewVerifier(ec.P256)
+	group := prover.Group
+
+	a := group.ExpBaseG(big.NewInt(1))
+	secret := common.GetRandomInt(group.Q)
+	b := group.Exp(a, secret)
+	r.input("a", a.X, a.Y)
+	r.input("secret", secret)
+	r.input("b", b.X, b.Y)
+
+	x := prover.GetProofRandomData(secret, a)
+	r.message("x", x.X, x.Y)
+	verifier.SetProofRandomData(x, a, b)
+	challenge := verifier.GetChallenge()
+	r.message("challenge", challenge)
+	z := prover.GetProofData(challenge)
+	r.message("z", z)
+
+	return r.verified(verifier.Verify(z))
+}
+
+func runECSchnorrEquality(r *recorder) error {
+	prover := ecschnorr.NewEqualityProver(ec.P256)
+	verifier := ecschnorr.NewEqualityVerifier(ec.P256)
+	group := prover.Group
+
+	g1 := group.ExpBaseG(big.NewInt(1))
+	g2 := group.GetRandomElement()
+	secret := common.GetRandomInt(group.Q)
+	t1 := group.Exp(g1, secret)
+	t2 := group.Exp(g2, secret)
+	r.input("g2", g2.X, g2.Y)
+	r.input("secret", secret)
+	r.input("t1", t1.X, t1.Y)
+	r.input("t2", t2.X, t2.Y)
+
+	x1, x2 := prover.GetProofRandomData(secret, g1, g2)
+	r.message("x1", x1.X, x1.Y)
+	r.message("x2", x2.X, x2.Y)
+	challenge := verifier.GetChallenge(g1, g2, t1, t2, x1, x2)
+	r.message("challenge", challenge)
+	z := prover.GetProofData(challenge)
+	r.message("z", z)
+
+	return r.verified(verifier.Verify(z))
+}
+
+func runECSchnorrPartial(r *recorder) error {
+	group := ec.NewGroup(ec.P256)
+	prover := ecschnorr.NewPartialProver(group)
+	verifier := ecschnorr.NewPartialVerifier(group)
+
+	// the prover knows the logarithm of b1, but not of b2
+	a1, a2 := group.GetRandomElement(), group.GetRandomElement()
+	secret := common.GetRandomInt(group.Q)
+	b1 := group.Exp(a1, secret)
+	b2 := group.GetRandomElement()
+	r.input("a1", a1.X, a1.Y)
+	r.input("a2", a2.X, a2.Y)
+	r.input("secret", secret)
+	r.input("b1", b1.X, b1.Y)
+	r.input("b2", b2.X, b2.Y)
+
+	triple1, triple2 := prover.GetProofRandomData(secret, a1, b1, a2, b2)
+	for i, t := range []*ecschnorr.ECTriple{triple1, triple2} {
+		name := fmt.Sprintf("triple%d", i+1)
+		r.message(name, t.A.X, t.A.Y, t.B.X, t.B.Y, t.C.X, t.C.Y)
+	}
+	verifier.SetProofRandomData(triple1, triple2)
+	challenge := verifier.GetChallenge()
+	r.message("challenge", challenge)
+	c1, z1, c2, z2 := prover.GetProofData(challenge)
+	r.message("z", c1, z1, c2, z2)
+
+	return r.verified(verifier.Verify(c1, z1, c2, z2))
+}
+
+func runPedersen(r *recorder) error {
+	group, err := loadGroup(r)
+	if err != nil {
+		return err
+	}
+	trapdoor := common.GetRandomInt(group.Q)
+	params := pedersen.NewParams(group, group.Exp(group.G, trapdoor), trapdoor)
+	val := common.GetRandomInt(group.Q)
+	r.input("h", params.H)
+	r.input("val", val)
+
+	committer := pedersen.NewCommitter(params)
+	receiver := pedersen.NewReceiverFromParams(params)
+
+	c, err := committer.GetCommitMsg(val)
+	if err != nil {
+		return err
+	}
+	r.message("commitment", c)
+	receiver.SetCommitment(c)
+	_, rand := committer.GetDecommitMsg()
+	r.message("r", rand)
+
+	return r.verified(receiver.CheckDecommitment(rand, val))
+}
+
+func runECPedersen(r *recorder) error {
+	params := ecpedersen.GenerateParams(ec.P256)
+	val := common.GetRandomInt(params.Group.Q)
+	r.input("h", params.H.X, params.H.Y)
+	r.input("val", val)
+
+	committer := ecpedersen.NewCommitter(params)
+	receiver := ecpedersen.NewReceiverFromParams(params)
+
+	c, err := committer.GetCommitMsg(val)
+	if err != nil {
+		return err
+	}
+	r.message("commitment", c.X, c.Y)
+	receiver.SetCommitment(c)
+	_, rand := committer.GetDecommitMsg()
+	r.message("r", rand)
+
+	return r.verified(receiver.CheckDecommitment(rand, val))
+}
+
+func runQRRepresentation(r *recorder) error {
+	group, _, err := loadSpecialRSA(r)
+	if err != nil {
+		return err
+	}
+	bases := make([]*big.Int, 3)
+	secrets := make([]*big.Int, len(bases))
+	y := big.NewInt(1)
+	for i := range bases {
+		if bases[i], err = group.GetRandomGenerator(); err != nil {
+			return err
+		}
+		secrets[i] = common.GetRandomInt(group.Order)
+		y = group.Mul(y, group.Exp(bases[i], secrets[i]))
+	}
+	r.input("bases", bases...)
+	r.input("secrets", secrets...)
+	r.input("y", y)
+
+	prover := qr.NewRepresentationProver(group, challengeSpaceSize, secrets, bases, y)
+	verifier := qr.NewRepresentationVerifier(group, challengeSpaceSize)
+
+	t := prover.GetProofRandomData(false)
+	r.message("t", t)
+	verifier.SetProofRandomData(t, bases, y)
+	challenge := verifier.GetChallenge()
+	r.message("challenge", challenge)
+	z := prover.GetProofData(challenge)
+	r.message("z", z...)
+
+	return r.verified(verifier.Verify(z))
+}
+
+func runDFOpening(r *recorder) error {
+	group, pubKey, err := loadSpecialRSA(r)
+	if err != nil {
+		return err
+	}
+	receiver, err := df.NewReceiverFromParams(group.GetPrimes(), pubKey.G, pubKey.H,
+		challengeSpaceSize)
+	if err != nil {
+		return err
+	}
+	t := new(big.Int).Mul(group.N, group.N)
+	committer := df.NewCommitter(group.N, receiver.G, receiver.H, t, receiver.K)
+	val := common.GetRandomInt(t)
+	r.input("g", receiver.G)
+	r.input("h", receiver.H)
+	r.input("val", val)
+
+	c, err := committer.GetCommitMsg(val)
+	if err != nil {
+		return err
+	}
+	r.message("commitment", c)
+	receiver.SetCommitment(c)
+
+	prover := df.NewOpeningProver(committer, challengeSpaceSize)
+	verifier := df.NewOpeningVerifier(receiver, challengeSpaceSize)
+
+	proofRandomData := prover.GetProofRandomData()
+	r.message("t", proofRandomData)
+	verifier.SetProofRandomData(proofRandomData)
+	challenge := verifier.GetChallenge()
+	r.message("challenge", challenge)
+	s1, s2 := prover.GetProofData(challenge)
+	r.message("s1", s1)
+	r.message("s2", s2)
+
+	return r.verified(verifier.Verify(s1, s2))
+}
+
+func runCL(r *recorder) error {
+	params, org, err := loadOrg()
+	if err != nil {
+		return err
+	}
+	pubKey := org.Keys.Pub
+	r.input("n", pubKey.N)
+	r.input("s", pubKey.S)
+	r.input("z", pubKey.Z)
+
+	cred := cl.NewRawCred(cl.NewAttrCount(5, 1, 0))
+	_ = cred.AddStrAttr("Name", "Jack", true)
+	_ = cred.AddStrAttr("Gender", "M", true)
+	_ = cred.AddStrAttr("Graduated", "true", true)
+	_ = cred.AddInt64Attr("DateMin", 1512643000, true)
+	_ = cred.AddInt64Attr("DateMax", 1592643000, true)
+	_ = cred.AddInt64Attr("Age", 50, false)
+	masterSecret := pubKey.GenerateUserMasterSecret()
+	r.input("known_attrs", cred.GetKnownVals()...)
+	r.input("committed_attrs", cred.GetCommittedVals()...)
+	r.input("master_secret", masterSecret)
+
+	credMgr, err := cl.NewCredManager(params, pubKey, masterSecret, cred)
+	if err != nil {
+		return err
+	}
+
+	// issuance
+	nonce := org.GetCredIssueNonce()
+	r.message("issue_nonce", nonce)
+	credReq, err := credMgr.GetCredRequest(nonce)
+	if err != nil {
+		return err
+	}
+	r.message("nym", credReq.Nym)
+	r.message("u", credReq.U)
+	r.message("commitments_of_attrs", credReq.CommitmentsOfAttrs...)
+	recordProof(r, "nym_proof", credReq.NymProof.ProofRandomData, credReq.NymProof.Challenge,
+		credReq.NymProof.ProofData)
+	recordProof(r, "u_proof", credReq.UProof.ProofRandomData, credReq.UProof.Challenge,
+		credReq.UProof.ProofData)
+
+	res, err := org.IssueCred(credReq)
+	if err != nil {
+		return err
+	}
+	r.message("a", res.Cred.A)
+	r.message("e", res.Cred.E)
+	r.message("v11", res.Cred.V11)
+	recordProof(r, "a_proof", res.AProof.ProofRandomData, res.AProof.Challenge,
+		res.AProof.ProofData)
+	ok, err := credMgr.Verify(res.Cred, res.AProof)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("issued credential not valid")
+	}
+
+	// proof of possession, revealing the first known attribute and the
+	// commitment of the first committed attribute
+	knownIndices, committedIndices := []int{0}, []int{0}
+	nonce = org.GetProveCredNonce()
+	r.message("prove_nonce", nonce)
+	randCred, proof, err := credMgr.BuildProof(res.Cred, knownIndices, committedIndices, nonce)
+	if err != nil {
+		return err
+	}
+	r.message("randomized_a", randCred.A)
+	recordProof(r, "cred_proof", proof.ProofRandomData, proof.Challenge, proof.ProofData)
+
+	knownAttrs, commitmentsOfAttrs := credMgr.FilterAttributes(knownIndices, committedIndices)
+	ok, err = org.ProveCred(randCred.A, proof, knownIndices, committedIndices, knownAttrs,
+		commitmentsOfAttrs)
+	if err != nil {
+		return err
+	}
+
+	return r.verified(ok)
+}
+
+// recordProof records the messages of a non-interactive sigma protocol.
+func recordProof(r *recorder, name string, proofRandomData, challenge *big.Int,
+	proofData []*big.Int) {
+	r.message(name+".t", proofRandomData)
+	r.message(name+".challenge", challenge)
+	r.message(name+".z", proofData...)
+}
@@ -0,0 +1,268 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package vectors generates deterministic test vectors for emmy's proof systems
+// and verifies the implementation against stored vectors, which enables
+// conformance testing of other implementations of the same protocols.
+//
+// A vector records inputs, the messages exchanged between the parties
+// (transcript) and the outputs of a single run of a protocol. All randomness of
+// the run, including challenges of verifiers, is drawn from a stream derived
+// from the seed of the vector, thus running a protocol with the same seed and
+// inputs reproduces the same vector. Big integers are encoded as hexadecimal
+// strings.
+//
+// Vectors are generated with the group parameters and CL keys of the
+// configuration, which are included in inputs. Protocols relying on ECDSA
+// signatures (pseudonym systems) are not covered, as the standard library
+// ignores custom sources of randomness for signing.
+package vectors
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/xlab-si/emmy/crypto/common"
+)
+
+// FormatVersion is the version of the format of vectors, increased with each
+// incompatible change.
+const FormatVersion = 1
+
+// Entry is a named value of a vector.
+type Entry struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Vector holds a single run of a protocol.
+type Vector struct {
+	Scheme     string  `json:"scheme"`
+	Seed       string  `json:"seed"` // hex encoded
+	Inputs     []Entry `json:"inputs"`
+	Transcript []Entry `json:"transcript"` // in the order messages are sent
+	Outputs    []Entry `json:"outputs"`
+}
+
+// Suite holds vectors of all schemes.
+type Suite struct {
+	Version int       `json:"version"`
+	Vectors []*Vector `json:"vectors"`
+}
+
+// WriteJSON writes s to w as indented JSON.
+func (s *Suite) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// ReadSuite reads vectors written by Suite.WriteJSON.
+func ReadSuite(rd io.Reader) (*Suite, error) {
+	s := new(Suite)
+	if err := json.NewDecoder(rd).Decode(s); err != nil {
+		return nil, err
+	}
+	if s.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported version of vectors: %d", s.Version)
+	}
+
+	return s, nil
+}
+
+// Schemes returns names of schemes vectors can be generated for.
+func Schemes() []string {
+	names := make([]string, len(schemes))
+	for i, s := range schemes {
+		names[i] = s.name
+	}
+	return names
+}
+
+// mu serializes runs, as they replace the source of randomness of the whole
+// crypto layer.
+var mu sync.Mutex
+
+// Generate runs the protocol of scheme with randomness derived from seed and
+// returns the resulting vector.
+func Generate(scheme string, seed []byte) (*Vector, error) {
+	for _, s := range schemes {
+		if s.name != scheme {
+			continue
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		prev := common.SetRandReader(newStream(seed))
+		defer common.SetRandReader(prev)
+
+		r := &recorder{v: &Vector{
+			Scheme: scheme,
+			Seed:   hex.EncodeToString(seed),
+		}}
+		if err := s.run(r); err != nil {
+			return nil, fmt.Errorf("%s: %s", scheme, err)
+		}
+		return r.v, nil
+	}
+
+	return nil, fmt.Errorf("unknown scheme %s", scheme)
+}
+
+// GenerateAll returns vectors of all schemes, generated with seed.
+func GenerateAll(seed []byte) (*Suite, error) {
+	s := &Suite{Version: FormatVersion}
+	for _, name := range Schemes() {
+		v, err := Generate(name, seed)
+		if err != nil {
+			return nil, err
+		}
+		s.Vectors = append(s.Vectors, v)
+	}
+
+	return s, nil
+}
+
+// Verify reruns the protocol of v with its seed and returns an error
+// describing the first value that differs from the one in v.
+func Verify(v *Vector) error {
+	seed, err := hex.DecodeString(v.Seed)
+	if err != nil {
+		return fmt.Errorf("%s: invalid seed: %s", v.Scheme, err)
+	}
+	got, err := Generate(v.Scheme, seed)
+	if err != nil {
+		return err
+	}
+
+	sections := []struct {
+		name      string
+		got, want []Entry
+	}{
+		{"input", got.Inputs, v.Inputs},
+		{"transcript", got.Transcript, v.Transcript},
+		{"output", got.Outputs, v.Outputs},
+	}
+	for _, s := range sections {
+		for i := 0; i < len(s.got) || i < len(s.want); i++ {
+			switch {
+			case i >= len(s.want):
+				return fmt.Errorf("%s: unexpected %s %s", v.Scheme, s.name, s.got[i].Name)
+			case i >= len(s.got):
+				return fmt.Errorf("%s: missing %s %s", v.Scheme, s.name, s.want[i].Name)
+			case s.got[i].Name != s.want[i].Name:
+				return fmt.Errorf("%s: %s %s found where %s is expected", v.Scheme, s.name,
+					s.got[i].Name, s.want[i].Name)
+			case s.got[i].Value != s.want[i].Value:
+				return fmt.Errorf("%s: %s %s is %s, expected %s", v.Scheme, s.name,
+					s.got[i].Name, s.got[i].Value, s.want[i].Value)
+			}
+		}
+	}
+
+	return nil
+}
+
+// recorder collects values of a run into a vector.
+type recorder struct {
+	v *Vector
+}
+
+func (r *recorder) input(name string, vals ...*big.Int) {
+	r.v.Inputs = appendEntries(r.v.Inputs, name, vals)
+}
+
+func (r *recorder) message(name string, vals ...*big.Int) {
+	r.v.Transcript = appendEntries(r.v.Transcript, name, vals)
+}
+
+func (r *recorder) output(name string, vals ...*big.Int) {
+	r.v.Outputs = appendEntries(r.v.Outputs, name, vals)
+}
+
+// verified records the outcome of a verification as output verified (0 or 1),
+// returning an error if it failed.
+func (r *recorder) verified(ok bool) error {
+	res := big.NewInt(0)
+	if ok {
+		res.SetInt64(1)
+	}
+	r.output("verified", res)
+	if !ok {
+		return fmt.Errorf("verification failed")
+	}
+
+	return nil
+}
+
+// appendEntries appends vals to entries under name, or under name[i] if there
+// are several of them.
+func appendEntries(entries []Entry, name string, vals []*big.Int) []Entry {
+	for i, val := range vals {
+		n := name
+		if len(vals) > 1 {
+			n = fmt.Sprintf("%s[%d]", name, i)
+		}
+		entries = append(entries, Entry{Name: n, Value: val.Text(16)})
+	}
+	return entries
+}
+
+// stream is a deterministic source of randomness, which outputs the
+// concatenation of SHA-256(seed || counter) for counter = 0, 1, ...,
+// with counter encoded as 8 big-endian bytes.
+type stream struct {
+	sync.Mutex
+	seed    []byte
+	counter uint64
+	buf     []byte
+}
+
+func newStream(seed []byte) *stream {
+	return &stream{seed: seed}
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	n := 0
+	for n < len(p) {
+		if len(s.buf) == 0 {
+			block := make([]byte, len(s.seed)+8)
+			copy(block, s.seed)
+			binary.BigEndian.PutUint64(block[len(s.seed):], s.counter)
+			sum := sha256.Sum256(block)
+			s.buf = sum[:]
+			s.counter++
+		}
+		c := copy(p[n:], s.buf)
+		s.buf = s.buf[c:]
+		n += c
+	}
+
+	return n, nil
+}
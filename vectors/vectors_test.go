@@ -0,0 +1,116 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package vectors
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// update regenerates the stored vectors, for example when a new scheme is added:
+//
+//	go test ./vectors -update
+var update = flag.Bool("update", false, "regenerate vectors in testdata")
+
+const vectorsPath = "testdata/vectors.json"
+
+func TestGenerateDeterministic(t *testing.T) {
+	for _, scheme := range Schemes() {
+		v1, err := Generate(scheme, []byte("seed"))
+		if err != nil {
+			t.Fatalf("error when generating vector: %v", err)
+		}
+		assert.Equal(t, []Entry{{Name: "verified", Value: "1"}},
+			v1.Outputs[len(v1.Outputs)-1:], scheme)
+
+		v2, err := Generate(scheme, []byte("seed"))
+		assert.NoError(t, err)
+		assert.Equal(t, v1, v2, scheme)
+
+		v3, err := Generate(scheme, []byte("another seed"))
+		assert.NoError(t, err)
+		assert.NotEqual(t, v1.Transcript, v3.Transcript, scheme)
+	}
+
+	_, err := Generate("none", []byte("seed"))
+	assert.Error(t, err)
+}
+
+func TestStoredVectors(t *testing.T) {
+	if *update {
+		s, err := GenerateAll([]byte("emmy test vectors"))
+		if err != nil {
+			t.Fatalf("error when generating vectors: %v", err)
+		}
+		f, err := os.Create(vectorsPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NoError(t, s.WriteJSON(f))
+		f.Close()
+	}
+
+	f, err := os.Open(vectorsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	s, err := ReadSuite(f)
+	if err != nil {
+		t.Fatalf("error when reading vectors: %v", err)
+	}
+
+	var schemes []string
+	for _, v := range s.Vectors {
+		schemes = append(schemes, v.Scheme)
+		assert.NoError(t, Verify(v))
+	}
+	assert.Equal(t, Schemes(), schemes)
+}
+
+func TestVerifyDetectsMismatch(t *testing.T) {
+	v, err := Generate("schnorr/dlog_knowledge", []byte("seed"))
+	if err != nil {
+		t.Fatalf("error when generating vector: %v", err)
+	}
+
+	v.Transcript[0].Value = "1"
+	assert.Error(t, Verify(v))
+	v.Transcript[0].Name = "x"
+	assert.Error(t, Verify(v))
+	v.Transcript = v.Transcript[:1]
+	assert.Error(t, Verify(v))
+	v.Seed = "not hex"
+	assert.Error(t, Verify(v))
+}
+
+func TestReadSuite(t *testing.T) {
+	var buf bytes.Buffer
+	s := &Suite{Version: FormatVersion, Vectors: []*Vector{{Scheme: "x", Seed: "00"}}}
+	assert.NoError(t, s.WriteJSON(&buf))
+	decoded, err := ReadSuite(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, s, decoded)
+
+	_, err = ReadSuite(bytes.NewBufferString(`{"version": 0}`))
+	assert.Error(t, err)
+}
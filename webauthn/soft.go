@@ -0,0 +1,146 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/xlab-si/emmy/cbor"
+)
+
+// SoftAuthenticator is an Authenticator holding a single credential whose key is kept
+// in memory. It provides no protection of the key and is meant for testing.
+type SoftAuthenticator struct {
+	rpID      string
+	origin    string
+	id        []byte
+	key       *ecdsa.PrivateKey
+	signCount uint32
+	sync.Mutex
+}
+
+// NewSoftAuthenticator returns a SoftAuthenticator for relying party rpID, acting
+// as a client at origin.
+func NewSoftAuthenticator(rpID, origin string) (*SoftAuthenticator, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	return &SoftAuthenticator{
+		rpID:   rpID,
+		origin: origin,
+		id:     id,
+		key:    key,
+	}, nil
+}
+
+// MakeCredential returns a registration of the credential of a with attestation
+// format "none".
+func (a *SoftAuthenticator) MakeCredential(challenge []byte) (*Registration, error) {
+	a.Lock()
+	defer a.Unlock()
+
+	key, err := coseKey(&a.key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	credData := make([]byte, 18, 18+len(a.id)+len(key))
+	binary.BigEndian.PutUint16(credData[16:], uint16(len(a.id)))
+	credData = append(append(credData, a.id...), key...)
+
+	authData := a.authData(flagAttestedCredentialData, credData)
+	attObj, err := cbor.Encode(map[interface{}]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[interface{}]interface{}{},
+		"authData": authData,
+	})
+	if err != nil {
+		return nil, err
+	}
+	clientDataJSON, err := a.clientData(clientDataCreate, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Registration{
+		CredentialID:      a.id,
+		AttestationObject: attObj,
+		ClientDataJSON:    clientDataJSON,
+	}, nil
+}
+
+// GetAssertion returns an assertion made with the credential of a.
+func (a *SoftAuthenticator) GetAssertion(challenge []byte) (*Assertion, error) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.signCount++
+	authData := a.authData(0, nil)
+	clientDataJSON, err := a.clientData(clientDataGet, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, a.key, signedDigest(authData, clientDataJSON))
+	if err != nil {
+		return nil, err
+	}
+	sig, err := asn1.Marshal(ecdsaSignature{r, s})
+	if err != nil {
+		return nil, fmt.Errorf("error when encoding signature: %v", err)
+	}
+
+	return &Assertion{
+		CredentialID:      a.id,
+		AuthenticatorData: authData,
+		ClientDataJSON:    clientDataJSON,
+		Signature:         sig,
+	}, nil
+}
+
+func (a *SoftAuthenticator) authData(flags byte, credData []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	data := make([]byte, 37, 37+len(credData))
+	copy(data, rpIDHash[:])
+	data[32] = flags | flagUserPresent | flagUserVerified
+	binary.BigEndian.PutUint32(data[33:], a.signCount)
+
+	return append(data, credData...)
+}
+
+func (a *SoftAuthenticator) clientData(typ string, challenge []byte) ([]byte, error) {
+	return json.Marshal(&ClientData{
+		Type:      typ,
+		Challenge: base64.RawURLEncoding.EncodeToString(challenge),
+		Origin:    a.origin,
+	})
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/go-redis/redis"
+)
+
+// CredentialStore stores WebAuthn credentials under their bindings.
+type CredentialStore interface {
+	// Store stores credential c, replacing the credential with the same binding.
+	Store(c *Credential) error
+
+	// Load loads the credential with the given binding, returning an error
+	// in case no credential was found.
+	Load(binding string) (*Credential, error)
+}
+
+// MarshalBinary encodes c for storage.
+func (c *Credential) MarshalBinary() ([]byte, error) {
+	return json.Marshal(&storedCredential{
+		ID:        c.ID,
+		X:         c.PublicKey.X.Bytes(),
+		Y:         c.PublicKey.Y.Bytes(),
+		SignCount: c.SignCount,
+	})
+}
+
+// UnmarshalBinary decodes c encoded with MarshalBinary.
+func (c *Credential) UnmarshalBinary(data []byte) error {
+	var s storedCredential
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	c.ID = s.ID
+	c.PublicKey = &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(s.X),
+		Y:     new(big.Int).SetBytes(s.Y),
+	}
+	c.SignCount = s.SignCount
+
+	return nil
+}
+
+type storedCredential struct {
+	ID        []byte `json:"id"`
+	X         []byte `json:"x"`
+	Y         []byte `json:"y"`
+	SignCount uint32 `json:"sign_count"`
+}
+
+// RedisCredentialStore stores credentials in a redis database.
+type RedisCredentialStore struct {
+	*redis.Client
+}
+
+// NewRedisCredentialStore accepts an instance of redis.Client and returns
+// an instance of RedisCredentialStore.
+func NewRedisCredentialStore(c *redis.Client) *RedisCredentialStore {
+	return &RedisCredentialStore{
+		Client: c,
+	}
+}
+
+// redisKeyPrefix separates credentials from other data in the database.
+const redisKeyPrefix = "webauthn:"
+
+func (s *RedisCredentialStore) Store(c *Credential) error {
+	return s.Set(redisKeyPrefix+c.Binding(), c, 0).Err()
+}
+
+func (s *RedisCredentialStore) Load(binding string) (*Credential, error) {
+	data, err := s.Get(redisKeyPrefix + binding).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var c Credential
+	if err := c.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// MemCredentialStore stores credentials in memory.
+type MemCredentialStore struct {
+	data map[string]Credential
+	sync.Mutex
+}
+
+// NewMemCredentialStore returns an empty MemCredentialStore.
+func NewMemCredentialStore() *MemCredentialStore {
+	return &MemCredentialStore{
+		data: make(map[string]Credential),
+	}
+}
+
+func (s *MemCredentialStore) Store(c *Credential) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.data[c.Binding()] = *c
+	return nil
+}
+
+func (s *MemCredentialStore) Load(binding string) (*Credential, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	c, ok := s.data[binding]
+	if !ok {
+		return nil, fmt.Errorf("credential does not exist")
+	}
+	return &c, nil
+}
@@ -0,0 +1,419 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package webauthn verifies WebAuthn (FIDO2) registrations and assertions, so that
+// the use of emmy credentials can be bound to keys that never leave authenticators
+// of users' devices.
+//
+// A credential is bound to an authenticator by holding the binding of a WebAuthn
+// credential (see Binding) as the value of a known attribute. The binding is checked
+// by the issuer against a registration made with a challenge of the issuance session,
+// and a proof of the credential needs to be accompanied by a fresh assertion made
+// with a challenge of the proving session. Note that revealing the binding attribute
+// makes proofs of the same credential linkable.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/xlab-si/emmy/cbor"
+)
+
+// Flags of authenticator data.
+const (
+	flagUserPresent            = 0x01
+	flagUserVerified           = 0x04
+	flagAttestedCredentialData = 0x40
+)
+
+// Values of the type member of client data.
+const (
+	clientDataCreate = "webauthn.create"
+	clientDataGet    = "webauthn.get"
+)
+
+// COSE key parameters of ES256 keys, the only ones supported.
+const (
+	coseKeyType    = 1
+	coseKeyAlg     = 3
+	coseKeyCurve   = -1
+	coseKeyX       = -2
+	coseKeyY       = -3
+	coseKeyTypeEC2 = 2
+	coseAlgES256   = -7
+	coseCurveP256  = 1
+)
+
+// bindingLen is the number of bytes of the credential ID digest used as binding.
+// Encoded bindings fit into attributes of 256 bits.
+const bindingLen = 16
+
+// ClientData is the client data collected by a WebAuthn client (e.g. a browser)
+// and signed by the authenticator.
+type ClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// Credential is a WebAuthn credential registered with the relying party.
+type Credential struct {
+	ID        []byte
+	PublicKey *ecdsa.PublicKey
+	SignCount uint32
+}
+
+// Binding returns the binding of the credential, see Binding.
+func (c *Credential) Binding() string {
+	return Binding(c.ID)
+}
+
+// Binding returns the value that binds an emmy credential to the WebAuthn credential
+// with the given ID.
+func Binding(credentialID []byte) string {
+	digest := sha256.Sum256(credentialID)
+	return base64.RawURLEncoding.EncodeToString(digest[:bindingLen])
+}
+
+// Registration holds the response of an authenticator to a request for creating
+// a credential.
+type Registration struct {
+	CredentialID      []byte
+	AttestationObject []byte
+	ClientDataJSON    []byte
+}
+
+// Assertion holds the response of an authenticator to a request for an assertion.
+type Assertion struct {
+	CredentialID      []byte
+	AuthenticatorData []byte
+	ClientDataJSON    []byte
+	Signature         []byte
+}
+
+// Authenticator creates WebAuthn credentials and assertions with a given challenge.
+type Authenticator interface {
+	MakeCredential(challenge []byte) (*Registration, error)
+	GetAssertion(challenge []byte) (*Assertion, error)
+}
+
+// Challenge derives a WebAuthn challenge from values of an emmy protocol session,
+// such as the nonce of the verifier.
+func Challenge(values ...*big.Int) []byte {
+	h := sha256.New()
+	for _, v := range values {
+		b := v.Bytes()
+		binary.Write(h, binary.BigEndian, uint32(len(b)))
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// RelyingParty verifies registrations and assertions made for the relying party
+// with identifier ID by clients at Origin.
+type RelyingParty struct {
+	ID     string
+	Origin string
+	// RequireUserVerification requires authenticators to verify the user
+	// (e.g. with a PIN or biometrics), not only their presence.
+	RequireUserVerification bool
+}
+
+// NewRelyingParty returns a RelyingParty with identifier id (e.g. a domain name)
+// accepting clients at origin.
+func NewRelyingParty(id, origin string) *RelyingParty {
+	return &RelyingParty{
+		ID:     id,
+		Origin: origin,
+	}
+}
+
+// VerifyRegistration verifies registration r made with challenge and returns the
+// registered credential. Attestation formats "none" and "packed" are supported.
+// Packed attestations with certificates are checked against the certificate key,
+// but the certificate chain is not verified.
+func (rp *RelyingParty) VerifyRegistration(r *Registration, challenge []byte) (*Credential,
+	error) {
+	if err := rp.verifyClientData(r.ClientDataJSON, clientDataCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	item, _, err := cbor.Decode(r.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("malformed attestation object: %v", err)
+	}
+	att, ok := item.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("malformed attestation object")
+	}
+	format, _ := att["fmt"].(string)
+	attStmt, _ := att["attStmt"].(map[interface{}]interface{})
+	authData, _ := att["authData"].([]byte)
+	if attStmt == nil || authData == nil {
+		return nil, fmt.Errorf("malformed attestation object")
+	}
+
+	ad, err := parseAuthData(authData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthData(ad); err != nil {
+		return nil, err
+	}
+	if ad.cred == nil {
+		return nil, fmt.Errorf("no attested credential data")
+	}
+	if !bytes.Equal(ad.cred.ID, r.CredentialID) {
+		return nil, fmt.Errorf("credential ID does not match attested credential")
+	}
+
+	switch format {
+	case "none":
+	case "packed":
+		if err := verifyPackedAttestation(attStmt, authData, r.ClientDataJSON,
+			ad.cred.PublicKey); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported attestation format %s", format)
+	}
+
+	return ad.cred, nil
+}
+
+// VerifyAssertion verifies assertion a made with challenge by the authenticator
+// holding credential c. On success, the signature counter of c is updated.
+func (rp *RelyingParty) VerifyAssertion(c *Credential, a *Assertion, challenge []byte) error {
+	if !bytes.Equal(c.ID, a.CredentialID) {
+		return fmt.Errorf("assertion is made with another credential")
+	}
+	if err := rp.verifyClientData(a.ClientDataJSON, clientDataGet, challenge); err != nil {
+		return err
+	}
+
+	ad, err := parseAuthData(a.AuthenticatorData)
+	if err != nil {
+		return err
+	}
+	if err := rp.verifyAuthData(ad); err != nil {
+		return err
+	}
+
+	if !verifySignature(c.PublicKey, a.AuthenticatorData, a.ClientDataJSON, a.Signature) {
+		return fmt.Errorf("invalid assertion signature")
+	}
+
+	// authenticators that implement signature counters increase them with every
+	// assertion; a counter that did not increase indicates a cloned authenticator
+	if ad.signCount != 0 || c.SignCount != 0 {
+		if ad.signCount <= c.SignCount {
+			return fmt.Errorf("signature counter did not increase, authenticator might be cloned")
+		}
+	}
+	c.SignCount = ad.signCount
+
+	return nil
+}
+
+func (rp *RelyingParty) verifyClientData(clientDataJSON []byte, typ string,
+	challenge []byte) error {
+	var cd ClientData
+	if err := json.Unmarshal(clientDataJSON, &cd); err != nil {
+		return fmt.Errorf("malformed client data: %v", err)
+	}
+	if cd.Type != typ {
+		return fmt.Errorf("client data type is %s, expected %s", cd.Type, typ)
+	}
+	if cd.Challenge != base64.RawURLEncoding.EncodeToString(challenge) {
+		return fmt.Errorf("client data challenge does not match")
+	}
+	if cd.Origin != rp.Origin {
+		return fmt.Errorf("client data origin %s is not allowed", cd.Origin)
+	}
+
+	return nil
+}
+
+func (rp *RelyingParty) verifyAuthData(ad *authData) error {
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(ad.rpIDHash, rpIDHash[:]) {
+		return fmt.Errorf("authenticator data is not made for relying party %s", rp.ID)
+	}
+	if ad.flags&flagUserPresent == 0 {
+		return fmt.Errorf("user is not present")
+	}
+	if rp.RequireUserVerification && ad.flags&flagUserVerified == 0 {
+		return fmt.Errorf("user is not verified")
+	}
+
+	return nil
+}
+
+// authData is parsed authenticator data.
+type authData struct {
+	rpIDHash  []byte
+	flags     byte
+	signCount uint32
+	cred      *Credential // attested credential, if present
+}
+
+func parseAuthData(data []byte) (*authData, error) {
+	if len(data) < 37 {
+		return nil, fmt.Errorf("authenticator data too short")
+	}
+	ad := &authData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if ad.flags&flagAttestedCredentialData == 0 {
+		return ad, nil
+	}
+
+	// attested credential data: AAGUID (16 bytes), credential ID length (2 bytes),
+	// credential ID and COSE public key
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, fmt.Errorf("attested credential data too short")
+	}
+	idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < idLen {
+		return nil, fmt.Errorf("attested credential data too short")
+	}
+	id := append([]byte{}, rest[:idLen]...)
+	key, _, err := cbor.Decode(rest[idLen:])
+	if err != nil {
+		return nil, fmt.Errorf("malformed credential public key: %v", err)
+	}
+	pub, err := parseCOSEKey(key)
+	if err != nil {
+		return nil, err
+	}
+	ad.cred = &Credential{
+		ID:        id,
+		PublicKey: pub,
+		SignCount: ad.signCount,
+	}
+
+	return ad, nil
+}
+
+func parseCOSEKey(item interface{}) (*ecdsa.PublicKey, error) {
+	m, ok := item.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("malformed credential public key")
+	}
+	if m[int64(coseKeyType)] != int64(coseKeyTypeEC2) ||
+		m[int64(coseKeyAlg)] != int64(coseAlgES256) ||
+		m[int64(coseKeyCurve)] != int64(coseCurveP256) {
+		return nil, fmt.Errorf("only ES256 credential keys are supported")
+	}
+	x, _ := m[int64(coseKeyX)].([]byte)
+	y, _ := m[int64(coseKeyY)].([]byte)
+	if len(x) != 32 || len(y) != 32 {
+		return nil, fmt.Errorf("malformed credential public key")
+	}
+
+	pub := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, fmt.Errorf("credential public key is not on the curve")
+	}
+
+	return pub, nil
+}
+
+// coseKey returns the COSE encoding of pub.
+func coseKey(pub *ecdsa.PublicKey) ([]byte, error) {
+	x, y := make([]byte, 32), make([]byte, 32)
+	xb, yb := pub.X.Bytes(), pub.Y.Bytes()
+	copy(x[32-len(xb):], xb)
+	copy(y[32-len(yb):], yb)
+
+	return cbor.Encode(map[interface{}]interface{}{
+		int64(coseKeyType):  int64(coseKeyTypeEC2),
+		int64(coseKeyAlg):   int64(coseAlgES256),
+		int64(coseKeyCurve): int64(coseCurveP256),
+		int64(coseKeyX):     x,
+		int64(coseKeyY):     y,
+	})
+}
+
+func verifyPackedAttestation(attStmt map[interface{}]interface{}, authData,
+	clientDataJSON []byte, credKey *ecdsa.PublicKey) error {
+	if attStmt["alg"] != int64(coseAlgES256) {
+		return fmt.Errorf("unsupported attestation algorithm")
+	}
+	sig, _ := attStmt["sig"].([]byte)
+
+	key := credKey // self attestation
+	if x5c, ok := attStmt["x5c"].([]interface{}); ok {
+		if len(x5c) == 0 {
+			return fmt.Errorf("malformed attestation statement")
+		}
+		der, _ := x5c[0].([]byte)
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return fmt.Errorf("malformed attestation certificate: %v", err)
+		}
+		if key, ok = cert.PublicKey.(*ecdsa.PublicKey); !ok {
+			return fmt.Errorf("unsupported attestation certificate key")
+		}
+	}
+
+	if !verifySignature(key, authData, clientDataJSON, sig) {
+		return fmt.Errorf("invalid attestation signature")
+	}
+
+	return nil
+}
+
+// ecdsaSignature is an ASN.1 encoded ECDSA signature.
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// verifySignature verifies sig over authData and the hash of clientDataJSON,
+// as signed by authenticators.
+func verifySignature(key *ecdsa.PublicKey, authData, clientDataJSON, sig []byte) bool {
+	var s ecdsaSignature
+	if rest, err := asn1.Unmarshal(sig, &s); err != nil || len(rest) != 0 {
+		return false
+	}
+
+	return ecdsa.Verify(key, signedDigest(authData, clientDataJSON), s.R, s.S)
+}
+
+func signedDigest(authData, clientDataJSON []byte) []byte {
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	return digest[:]
+}
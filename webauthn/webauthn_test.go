@@ -0,0 +1,83 @@
+/*
+ * Copyright 2017 XLAB d.o.o.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package webauthn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	rp := NewRelyingParty("emmy.example", "https://emmy.example")
+	rp.RequireUserVerification = true
+	a, err := NewSoftAuthenticator(rp.ID, rp.Origin)
+	if err != nil {
+		t.Fatalf("error when creating authenticator: %v", err)
+	}
+
+	challenge := Challenge(big.NewInt(42), big.NewInt(7))
+	reg, err := a.MakeCredential(challenge)
+	if err != nil {
+		t.Fatalf("error when creating credential: %v", err)
+	}
+
+	_, err = rp.VerifyRegistration(reg, Challenge(big.NewInt(43), big.NewInt(7)))
+	assert.Error(t, err, "registration with another challenge should fail")
+	_, err = NewRelyingParty("other.example", rp.Origin).VerifyRegistration(reg, challenge)
+	assert.Error(t, err, "registration for another relying party should fail")
+
+	cred, err := rp.VerifyRegistration(reg, challenge)
+	if err != nil {
+		t.Fatalf("error when verifying registration: %v", err)
+	}
+	assert.Equal(t, Binding(reg.CredentialID), cred.Binding())
+	assert.Len(t, cred.Binding(), 22)
+
+	// credentials survive storage
+	store := NewMemCredentialStore()
+	assert.NoError(t, store.Store(cred))
+	data, err := cred.MarshalBinary()
+	assert.NoError(t, err)
+	var decoded Credential
+	assert.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, cred.PublicKey.X, decoded.PublicKey.X)
+	assert.Equal(t, cred.ID, decoded.ID)
+
+	challenge = Challenge(big.NewInt(100))
+	assertion, err := a.GetAssertion(challenge)
+	if err != nil {
+		t.Fatalf("error when making assertion: %v", err)
+	}
+	stored, err := store.Load(cred.Binding())
+	assert.NoError(t, err)
+	assert.Error(t, rp.VerifyAssertion(stored, assertion, Challenge(big.NewInt(101))),
+		"assertion with another challenge should fail")
+	assert.NoError(t, rp.VerifyAssertion(stored, assertion, challenge))
+	assert.Error(t, rp.VerifyAssertion(stored, assertion, challenge),
+		"replayed assertion should fail")
+
+	assertion, _ = a.GetAssertion(challenge)
+	assertion.Signature[len(assertion.Signature)-1] ^= 1
+	assert.Error(t, rp.VerifyAssertion(stored, assertion, challenge),
+		"assertion with invalid signature should fail")
+
+	_, err = store.Load("unknown")
+	assert.Error(t, err)
+}